@@ -0,0 +1,411 @@
+package alert
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/tidepool-org/platform/data"
+	dataBloodGlucose "github.com/tidepool-org/platform/data/blood/glucose"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/id"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/structure"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+	"github.com/tidepool-org/platform/user"
+)
+
+const (
+	RuleTypeHigh          = "high"
+	RuleTypeLow           = "low"
+	RuleTypeNoData        = "noData"
+	RuleTypeRapidFall     = "rapidFall"
+	RuleTypeRapidRise     = "rapidRise"
+	RuleTypeSustainedHigh = "sustainedHigh"
+	RuleTypeUrgentLow     = "urgentLow"
+
+	RuleStateArmed     = "armed"
+	RuleStateTriggered = "triggered"
+
+	RuleDurationMinimum       = 15      // minutes
+	RuleDurationMaximum       = 48 * 60 // minutes
+	RuleSnoozeDurationMaximum = 24 * 60 // minutes
+	RuleRateMgdLMaximum       = 10.0    // mg/dL per minute
+	RuleRateMmolLMaximum      = 10.0 / dataBloodGlucose.MmolLToMgdLConversionFactor
+)
+
+func RuleTypes() []string {
+	return []string{
+		RuleTypeHigh,
+		RuleTypeLow,
+		RuleTypeNoData,
+		RuleTypeRapidFall,
+		RuleTypeRapidRise,
+		RuleTypeSustainedHigh,
+		RuleTypeUrgentLow,
+	}
+}
+
+func RuleStates() []string {
+	return []string{
+		RuleStateArmed,
+		RuleStateTriggered,
+	}
+}
+
+type Client interface {
+	RuleAccessor
+}
+
+type RuleAccessor interface {
+	ListRules(ctx context.Context, filter *RuleFilter, pagination *page.Pagination) (Rules, error)
+	CreateUserRule(ctx context.Context, userID string, create *RuleCreate) (*Rule, error)
+	GetRule(ctx context.Context, id string) (*Rule, error)
+	UpdateRule(ctx context.Context, id string, update *RuleUpdate) (*Rule, error)
+	DeleteRule(ctx context.Context, id string) error
+}
+
+// Evaluator evaluates the rules of the user against newly ingested data
+type Evaluator interface {
+	EvaluateUserData(ctx context.Context, userID string, datumArray []data.Datum) error
+}
+
+type RuleFilter struct {
+	UserID  *string `json:"userId,omitempty"`
+	OwnerID *string `json:"ownerId,omitempty"`
+	Type    *string `json:"type,omitempty"`
+}
+
+func NewRuleFilter() *RuleFilter {
+	return &RuleFilter{}
+}
+
+func (r *RuleFilter) Parse(parser structure.ObjectParser) {
+	r.UserID = parser.String("userId")
+	r.OwnerID = parser.String("ownerId")
+	r.Type = parser.String("type")
+}
+
+func (r *RuleFilter) Validate(validator structure.Validator) {
+	validator.String("userId", r.UserID).Using(user.IDValidator)
+	validator.String("ownerId", r.OwnerID).Using(user.IDValidator)
+	validator.String("type", r.Type).OneOf(RuleTypes()...)
+}
+
+func (r *RuleFilter) MutateRequest(req *http.Request) error {
+	parameters := map[string]string{}
+	if r.UserID != nil {
+		parameters["userId"] = *r.UserID
+	}
+	if r.OwnerID != nil {
+		parameters["ownerId"] = *r.OwnerID
+	}
+	if r.Type != nil {
+		parameters["type"] = *r.Type
+	}
+	return request.NewParametersMutator(parameters).MutateRequest(req)
+}
+
+// The threshold and rate are in the specified units (rate is per minute). The duration, in minutes, is the
+// length of time the value must remain high (sustained high) or the length of time without data (no data).
+// If the owner is not specified, then the rule is owned by the user whose data is evaluated.
+type RuleCreate struct {
+	OwnerID   *string  `json:"ownerId,omitempty"`
+	Type      string   `json:"type"`
+	Enabled   *bool    `json:"enabled,omitempty"`
+	Units     *string  `json:"units,omitempty"`
+	Threshold *float64 `json:"threshold,omitempty"`
+	Rate      *float64 `json:"rate,omitempty"`
+	Duration  *int     `json:"duration,omitempty"`
+}
+
+func NewRuleCreate() *RuleCreate {
+	return &RuleCreate{}
+}
+
+func (r *RuleCreate) Parse(parser structure.ObjectParser) {
+	r.OwnerID = parser.String("ownerId")
+	if ptr := parser.String("type"); ptr != nil {
+		r.Type = *ptr
+	}
+	r.Enabled = parser.Bool("enabled")
+	r.Units = parser.String("units")
+	r.Threshold = parser.Float64("threshold")
+	r.Rate = parser.Float64("rate")
+	r.Duration = parser.Int("duration")
+}
+
+func (r *RuleCreate) Validate(validator structure.Validator) {
+	validator.String("ownerId", r.OwnerID).Using(user.IDValidator)
+	validator.String("type", &r.Type).OneOf(RuleTypes()...)
+	validateRuleConfiguration(validator, r.Type, r.Units, r.Threshold, r.Rate, r.Duration)
+}
+
+// Evaluation tracks the state of a rule as data is evaluated. A rule is triggered, and the owner notified, when
+// the data first matches the rule. The rule is re-armed once the data no longer matches.
+type Evaluation struct {
+	State              string     `json:"state" bson:"state"`
+	TriggeredTime      *time.Time `json:"triggeredTime,omitempty" bson:"triggeredTime,omitempty"`
+	ConditionStartTime *time.Time `json:"conditionStartTime,omitempty" bson:"conditionStartTime,omitempty"`
+	LastDataTime       *time.Time `json:"lastDataTime,omitempty" bson:"lastDataTime,omitempty"`
+}
+
+func NewEvaluation() *Evaluation {
+	return &Evaluation{
+		State: RuleStateArmed,
+	}
+}
+
+func (e *Evaluation) IsTriggered() bool {
+	return e.State == RuleStateTriggered
+}
+
+func (e *Evaluation) Parse(parser structure.ObjectParser) {
+	if ptr := parser.String("state"); ptr != nil {
+		e.State = *ptr
+	}
+	e.TriggeredTime = parser.Time("triggeredTime", time.RFC3339)
+	e.ConditionStartTime = parser.Time("conditionStartTime", time.RFC3339)
+	e.LastDataTime = parser.Time("lastDataTime", time.RFC3339)
+}
+
+func (e *Evaluation) Validate(validator structure.Validator) {
+	validator.String("state", &e.State).OneOf(RuleStates()...)
+	if e.State == RuleStateTriggered {
+		validator.Time("triggeredTime", e.TriggeredTime).Exists().NotZero()
+	} else {
+		validator.Time("triggeredTime", e.TriggeredTime).NotExists()
+	}
+	validator.Time("conditionStartTime", e.ConditionStartTime).NotZero()
+	validator.Time("lastDataTime", e.LastDataTime).NotZero()
+}
+
+// The rule type and configuration may not be updated; delete and create a new rule instead. Snooze, in minutes,
+// suppresses notifications for the duration from now; zero cancels the snooze. Evaluation, if specified,
+// replaces the existing evaluation and may only be updated by services.
+type RuleUpdate struct {
+	Enabled    *bool       `json:"enabled,omitempty"`
+	Snooze     *int        `json:"snooze,omitempty"`
+	Evaluation *Evaluation `json:"evaluation,omitempty"`
+}
+
+func NewRuleUpdate() *RuleUpdate {
+	return &RuleUpdate{}
+}
+
+func (r *RuleUpdate) HasUpdates() bool {
+	return r.Enabled != nil || r.Snooze != nil || r.Evaluation != nil
+}
+
+func (r *RuleUpdate) Parse(parser structure.ObjectParser) {
+	r.Enabled = parser.Bool("enabled")
+	r.Snooze = parser.Int("snooze")
+	if evaluationParser := parser.WithReferenceObjectParser("evaluation"); evaluationParser.Exists() {
+		r.Evaluation = &Evaluation{}
+		r.Evaluation.Parse(evaluationParser)
+		evaluationParser.NotParsed()
+	}
+}
+
+func (r *RuleUpdate) Validate(validator structure.Validator) {
+	validator.Int("snooze", r.Snooze).InRange(0, RuleSnoozeDurationMaximum)
+	if r.Evaluation != nil {
+		r.Evaluation.Validate(validator.WithReference("evaluation"))
+	}
+}
+
+type Rule struct {
+	ID               string     `json:"id" bson:"id"`
+	UserID           string     `json:"userId" bson:"userId"`
+	OwnerID          string     `json:"ownerId" bson:"ownerId"`
+	Type             string     `json:"type" bson:"type"`
+	Enabled          bool       `json:"enabled" bson:"enabled"`
+	Units            *string    `json:"units,omitempty" bson:"units,omitempty"`
+	Threshold        *float64   `json:"threshold,omitempty" bson:"threshold,omitempty"`
+	Rate             *float64   `json:"rate,omitempty" bson:"rate,omitempty"`
+	Duration         *int       `json:"duration,omitempty" bson:"duration,omitempty"`
+	SnoozedUntilTime *time.Time `json:"snoozedUntilTime,omitempty" bson:"snoozedUntilTime,omitempty"`
+	Evaluation       Evaluation `json:"evaluation" bson:"evaluation"`
+	CreatedTime      time.Time  `json:"createdTime" bson:"createdTime"`
+	ModifiedTime     *time.Time `json:"modifiedTime,omitempty" bson:"modifiedTime,omitempty"`
+}
+
+func NewRule(userID string, create *RuleCreate) (*Rule, error) {
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+	if create == nil {
+		return nil, errors.New("create is missing")
+	} else if err := structureValidator.New().Validate(create); err != nil {
+		return nil, errors.Wrap(err, "create is invalid")
+	}
+
+	ownerID := userID
+	if create.OwnerID != nil {
+		ownerID = *create.OwnerID
+	}
+	enabled := true
+	if create.Enabled != nil {
+		enabled = *create.Enabled
+	}
+
+	return &Rule{
+		ID:          NewRuleID(),
+		UserID:      userID,
+		OwnerID:     ownerID,
+		Type:        create.Type,
+		Enabled:     enabled,
+		Units:       create.Units,
+		Threshold:   create.Threshold,
+		Rate:        create.Rate,
+		Duration:    create.Duration,
+		Evaluation:  *NewEvaluation(),
+		CreatedTime: time.Now().Truncate(time.Second),
+	}, nil
+}
+
+func (r *Rule) IsSnoozed(now time.Time) bool {
+	return r.SnoozedUntilTime != nil && r.SnoozedUntilTime.After(now)
+}
+
+// ThresholdMmolL returns the threshold normalized to mmol/L
+func (r *Rule) ThresholdMmolL() *float64 {
+	return dataBloodGlucose.NormalizeValueForUnits(r.Threshold, r.Units)
+}
+
+// RateMmolL returns the rate normalized to mmol/L per minute
+func (r *Rule) RateMmolL() *float64 {
+	return dataBloodGlucose.NormalizeValueForUnits(r.Rate, r.Units)
+}
+
+func (r *Rule) DurationValue() time.Duration {
+	if r.Duration == nil {
+		return 0
+	}
+	return time.Duration(*r.Duration) * time.Minute
+}
+
+func (r *Rule) Parse(parser structure.ObjectParser) {
+	if ptr := parser.String("id"); ptr != nil {
+		r.ID = *ptr
+	}
+	if ptr := parser.String("userId"); ptr != nil {
+		r.UserID = *ptr
+	}
+	if ptr := parser.String("ownerId"); ptr != nil {
+		r.OwnerID = *ptr
+	}
+	if ptr := parser.String("type"); ptr != nil {
+		r.Type = *ptr
+	}
+	if ptr := parser.Bool("enabled"); ptr != nil {
+		r.Enabled = *ptr
+	}
+	r.Units = parser.String("units")
+	r.Threshold = parser.Float64("threshold")
+	r.Rate = parser.Float64("rate")
+	r.Duration = parser.Int("duration")
+	r.SnoozedUntilTime = parser.Time("snoozedUntilTime", time.RFC3339)
+	if evaluationParser := parser.WithReferenceObjectParser("evaluation"); evaluationParser.Exists() {
+		r.Evaluation.Parse(evaluationParser)
+		evaluationParser.NotParsed()
+	}
+	if ptr := parser.Time("createdTime", time.RFC3339); ptr != nil {
+		r.CreatedTime = *ptr
+	}
+	r.ModifiedTime = parser.Time("modifiedTime", time.RFC3339)
+}
+
+func (r *Rule) Validate(validator structure.Validator) {
+	validator.String("id", &r.ID).Using(RuleIDValidator)
+	validator.String("userId", &r.UserID).Using(user.IDValidator)
+	validator.String("ownerId", &r.OwnerID).Using(user.IDValidator)
+	validator.String("type", &r.Type).OneOf(RuleTypes()...)
+	validateRuleConfiguration(validator, r.Type, r.Units, r.Threshold, r.Rate, r.Duration)
+	validator.Time("snoozedUntilTime", r.SnoozedUntilTime).NotZero()
+	r.Evaluation.Validate(validator.WithReference("evaluation"))
+	validator.Time("createdTime", &r.CreatedTime).NotZero().BeforeNow(time.Second)
+	validator.Time("modifiedTime", r.ModifiedTime).After(r.CreatedTime).BeforeNow(time.Second)
+}
+
+func (r *Rule) Sanitize(details request.Details) error {
+	if details == nil {
+		return errors.New("unable to sanitize")
+	}
+	return nil
+}
+
+type Rules []*Rule
+
+func (r Rules) Sanitize(details request.Details) error {
+	for _, rule := range r {
+		if err := rule.Sanitize(details); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateRuleConfiguration(validator structure.Validator, typ string, units *string, threshold *float64, rate *float64, duration *int) {
+	switch typ {
+	case RuleTypeHigh, RuleTypeLow, RuleTypeUrgentLow:
+		validator.String("units", units).Exists().OneOf(dataBloodGlucose.Units()...)
+		validator.Float64("threshold", threshold).Exists().InRange(dataBloodGlucose.ValueRangeForUnits(units))
+		validator.Float64("rate", rate).NotExists()
+		validator.Int("duration", duration).NotExists()
+	case RuleTypeSustainedHigh:
+		validator.String("units", units).Exists().OneOf(dataBloodGlucose.Units()...)
+		validator.Float64("threshold", threshold).Exists().InRange(dataBloodGlucose.ValueRangeForUnits(units))
+		validator.Float64("rate", rate).NotExists()
+		validator.Int("duration", duration).Exists().InRange(RuleDurationMinimum, RuleDurationMaximum)
+	case RuleTypeRapidFall, RuleTypeRapidRise:
+		validator.String("units", units).Exists().OneOf(dataBloodGlucose.Units()...)
+		validator.Float64("threshold", threshold).NotExists()
+		validator.Float64("rate", rate).Exists().GreaterThan(0).LessThanOrEqualTo(rateMaximumForUnits(units))
+		validator.Int("duration", duration).NotExists()
+	case RuleTypeNoData:
+		validator.String("units", units).NotExists()
+		validator.Float64("threshold", threshold).NotExists()
+		validator.Float64("rate", rate).NotExists()
+		validator.Int("duration", duration).Exists().InRange(RuleDurationMinimum, RuleDurationMaximum)
+	}
+}
+
+func rateMaximumForUnits(units *string) float64 {
+	if units != nil {
+		switch *units {
+		case dataBloodGlucose.MmolL, dataBloodGlucose.Mmoll:
+			return RuleRateMmolLMaximum
+		}
+	}
+	return RuleRateMgdLMaximum
+}
+
+func NewRuleID() string {
+	return id.Must(id.New(16))
+}
+
+func IsValidRuleID(value string) bool {
+	return ValidateRuleID(value) == nil
+}
+
+func RuleIDValidator(value string, errorReporter structure.ErrorReporter) {
+	errorReporter.ReportError(ValidateRuleID(value))
+}
+
+func ValidateRuleID(value string) error {
+	if value == "" {
+		return structureValidator.ErrorValueEmpty()
+	} else if !ruleIDExpression.MatchString(value) {
+		return ErrorValueStringAsRuleIDNotValid(value)
+	}
+	return nil
+}
+
+func ErrorValueStringAsRuleIDNotValid(value string) error {
+	return errors.Preparedf(structureValidator.ErrorCodeValueNotValid, "value is not valid", "value %q is not valid as alert rule id", value)
+}
+
+var ruleIDExpression = regexp.MustCompile("^[0-9a-f]{32}$")
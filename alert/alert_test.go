@@ -0,0 +1,311 @@
+package alert_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"net/http/httptest"
+	"time"
+
+	"github.com/tidepool-org/platform/alert"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/pointer"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+	"github.com/tidepool-org/platform/user"
+)
+
+var _ = Describe("Alert", func() {
+	It("RuleTypes returns expected", func() {
+		Expect(alert.RuleTypes()).To(Equal([]string{"high", "low", "noData", "rapidFall", "rapidRise", "sustainedHigh", "urgentLow"}))
+	})
+
+	It("RuleStates returns expected", func() {
+		Expect(alert.RuleStates()).To(Equal([]string{"armed", "triggered"}))
+	})
+
+	Context("RuleFilter", func() {
+		Context("Validate", func() {
+			DescribeTable("validates the rule filter",
+				func(mutator func(filter *alert.RuleFilter), expectedErrors ...error) {
+					filter := alert.NewRuleFilter()
+					mutator(filter)
+					errorsTest.ExpectEqual(structureValidator.New().Validate(filter), expectedErrors...)
+				},
+				Entry("succeeds",
+					func(filter *alert.RuleFilter) {},
+				),
+				Entry("all valid",
+					func(filter *alert.RuleFilter) {
+						filter.UserID = pointer.FromString(user.NewID())
+						filter.OwnerID = pointer.FromString(user.NewID())
+						filter.Type = pointer.FromString(alert.RuleTypeHigh)
+					},
+				),
+				Entry("type invalid",
+					func(filter *alert.RuleFilter) { filter.Type = pointer.FromString("invalid") },
+					errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("invalid", alert.RuleTypes()), "/type"),
+				),
+			)
+		})
+
+		Context("MutateRequest", func() {
+			It("adds the query parameters", func() {
+				filter := alert.NewRuleFilter()
+				filter.UserID = pointer.FromString("1234567890")
+				filter.Type = pointer.FromString(alert.RuleTypeNoData)
+				req := httptest.NewRequest("GET", "http://localhost/v1/alert_rules", nil)
+				Expect(filter.MutateRequest(req)).To(Succeed())
+				Expect(req.URL.Query().Get("userId")).To(Equal("1234567890"))
+				Expect(req.URL.Query().Get("ownerId")).To(BeEmpty())
+				Expect(req.URL.Query().Get("type")).To(Equal("noData"))
+			})
+		})
+	})
+
+	Context("RuleCreate", func() {
+		Context("Validate", func() {
+			DescribeTable("validates the rule create",
+				func(typ string, mutator func(create *alert.RuleCreate), expectedErrors ...error) {
+					create := alert.NewRuleCreate()
+					create.Type = typ
+					mutator(create)
+					errorsTest.ExpectEqual(structureValidator.New().Validate(create), expectedErrors...)
+				},
+				Entry("low succeeds", alert.RuleTypeLow,
+					func(create *alert.RuleCreate) {
+						create.Units = pointer.FromString("mg/dL")
+						create.Threshold = pointer.FromFloat64(70)
+					},
+				),
+				Entry("urgent low threshold missing", alert.RuleTypeUrgentLow,
+					func(create *alert.RuleCreate) { create.Units = pointer.FromString("mmol/L") },
+					errorsTest.WithPointerSource(structureValidator.ErrorValueNotExists(), "/threshold"),
+				),
+				Entry("high threshold out of range", alert.RuleTypeHigh,
+					func(create *alert.RuleCreate) {
+						create.Units = pointer.FromString("mmol/L")
+						create.Threshold = pointer.FromFloat64(55.1)
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueNotInRange(55.1, 0.0, 55.0), "/threshold"),
+				),
+				Entry("high units invalid", alert.RuleTypeHigh,
+					func(create *alert.RuleCreate) {
+						create.Units = pointer.FromString("invalid")
+						create.Threshold = pointer.FromFloat64(10)
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("invalid", []string{"mmol/L", "mmol/l", "mg/dL", "mg/dl"}), "/units"),
+				),
+				Entry("high duration exists", alert.RuleTypeHigh,
+					func(create *alert.RuleCreate) {
+						create.Units = pointer.FromString("mg/dL")
+						create.Threshold = pointer.FromFloat64(250)
+						create.Duration = pointer.FromInt(60)
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueExists(), "/duration"),
+				),
+				Entry("sustained high succeeds", alert.RuleTypeSustainedHigh,
+					func(create *alert.RuleCreate) {
+						create.Units = pointer.FromString("mg/dL")
+						create.Threshold = pointer.FromFloat64(250)
+						create.Duration = pointer.FromInt(120)
+					},
+				),
+				Entry("sustained high duration out of range", alert.RuleTypeSustainedHigh,
+					func(create *alert.RuleCreate) {
+						create.Units = pointer.FromString("mg/dL")
+						create.Threshold = pointer.FromFloat64(250)
+						create.Duration = pointer.FromInt(5)
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueNotInRange(5, 15, 2880), "/duration"),
+				),
+				Entry("rapid rise succeeds", alert.RuleTypeRapidRise,
+					func(create *alert.RuleCreate) {
+						create.Units = pointer.FromString("mg/dL")
+						create.Rate = pointer.FromFloat64(3)
+					},
+				),
+				Entry("rapid fall rate out of range", alert.RuleTypeRapidFall,
+					func(create *alert.RuleCreate) {
+						create.Units = pointer.FromString("mg/dL")
+						create.Rate = pointer.FromFloat64(0)
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueNotGreaterThan(0.0, 0.0), "/rate"),
+				),
+				Entry("rapid fall threshold exists", alert.RuleTypeRapidFall,
+					func(create *alert.RuleCreate) {
+						create.Units = pointer.FromString("mg/dL")
+						create.Threshold = pointer.FromFloat64(70)
+						create.Rate = pointer.FromFloat64(2)
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueExists(), "/threshold"),
+				),
+				Entry("no data succeeds", alert.RuleTypeNoData,
+					func(create *alert.RuleCreate) { create.Duration = pointer.FromInt(180) },
+				),
+				Entry("no data units exists", alert.RuleTypeNoData,
+					func(create *alert.RuleCreate) {
+						create.Units = pointer.FromString("mg/dL")
+						create.Duration = pointer.FromInt(180)
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueExists(), "/units"),
+				),
+				Entry("type invalid", "invalid",
+					func(create *alert.RuleCreate) {},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("invalid", alert.RuleTypes()), "/type"),
+				),
+			)
+		})
+	})
+
+	Context("RuleUpdate", func() {
+		Context("Validate", func() {
+			DescribeTable("validates the rule update",
+				func(mutator func(update *alert.RuleUpdate), expectedErrors ...error) {
+					update := alert.NewRuleUpdate()
+					mutator(update)
+					errorsTest.ExpectEqual(structureValidator.New().Validate(update), expectedErrors...)
+				},
+				Entry("succeeds",
+					func(update *alert.RuleUpdate) {},
+				),
+				Entry("snooze valid",
+					func(update *alert.RuleUpdate) { update.Snooze = pointer.FromInt(0) },
+				),
+				Entry("snooze out of range",
+					func(update *alert.RuleUpdate) { update.Snooze = pointer.FromInt(1441) },
+					errorsTest.WithPointerSource(structureValidator.ErrorValueNotInRange(1441, 0, 1440), "/snooze"),
+				),
+				Entry("evaluation triggered valid",
+					func(update *alert.RuleUpdate) {
+						update.Evaluation = &alert.Evaluation{State: alert.RuleStateTriggered, TriggeredTime: pointer.FromTime(time.Now())}
+					},
+				),
+				Entry("evaluation triggered time missing",
+					func(update *alert.RuleUpdate) {
+						update.Evaluation = &alert.Evaluation{State: alert.RuleStateTriggered}
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueNotExists(), "/evaluation/triggeredTime"),
+				),
+				Entry("evaluation armed triggered time exists",
+					func(update *alert.RuleUpdate) {
+						update.Evaluation = &alert.Evaluation{State: alert.RuleStateArmed, TriggeredTime: pointer.FromTime(time.Now())}
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueExists(), "/evaluation/triggeredTime"),
+				),
+			)
+		})
+
+		It("HasUpdates returns false if there are no updates", func() {
+			Expect(alert.NewRuleUpdate().HasUpdates()).To(BeFalse())
+		})
+
+		It("HasUpdates returns true if snooze is specified", func() {
+			update := alert.NewRuleUpdate()
+			update.Snooze = pointer.FromInt(30)
+			Expect(update.HasUpdates()).To(BeTrue())
+		})
+	})
+
+	Context("NewRule", func() {
+		var userID string
+		var create *alert.RuleCreate
+
+		BeforeEach(func() {
+			userID = user.NewID()
+			create = alert.NewRuleCreate()
+			create.Type = alert.RuleTypeLow
+			create.Units = pointer.FromString("mg/dL")
+			create.Threshold = pointer.FromFloat64(70)
+		})
+
+		It("returns an error if the user id is missing", func() {
+			rule, err := alert.NewRule("", create)
+			Expect(err).To(MatchError("user id is missing"))
+			Expect(rule).To(BeNil())
+		})
+
+		It("returns an error if the create is missing", func() {
+			rule, err := alert.NewRule(userID, nil)
+			Expect(err).To(MatchError("create is missing"))
+			Expect(rule).To(BeNil())
+		})
+
+		It("returns an error if the create is invalid", func() {
+			create.Threshold = nil
+			rule, err := alert.NewRule(userID, create)
+			Expect(err).To(MatchError(ContainSubstring("create is invalid")))
+			Expect(rule).To(BeNil())
+		})
+
+		It("returns successfully owned by the user", func() {
+			rule, err := alert.NewRule(userID, create)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rule).ToNot(BeNil())
+			Expect(alert.IsValidRuleID(rule.ID)).To(BeTrue())
+			Expect(rule.UserID).To(Equal(userID))
+			Expect(rule.OwnerID).To(Equal(userID))
+			Expect(rule.Enabled).To(BeTrue())
+			Expect(rule.Evaluation).To(Equal(alert.Evaluation{State: alert.RuleStateArmed}))
+			Expect(rule.CreatedTime).To(BeTemporally("~", time.Now(), time.Second))
+			Expect(structureValidator.New().Validate(rule)).To(Succeed())
+		})
+
+		It("returns successfully owned by another user", func() {
+			ownerID := user.NewID()
+			create.OwnerID = pointer.FromString(ownerID)
+			create.Enabled = pointer.FromBool(false)
+			rule, err := alert.NewRule(userID, create)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rule.OwnerID).To(Equal(ownerID))
+			Expect(rule.Enabled).To(BeFalse())
+		})
+	})
+
+	Context("Rule", func() {
+		var rule *alert.Rule
+
+		BeforeEach(func() {
+			create := alert.NewRuleCreate()
+			create.Type = alert.RuleTypeRapidFall
+			create.Units = pointer.FromString("mg/dL")
+			create.Rate = pointer.FromFloat64(2)
+			var err error
+			rule, err = alert.NewRule(user.NewID(), create)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("IsSnoozed returns false if not snoozed", func() {
+			Expect(rule.IsSnoozed(time.Now())).To(BeFalse())
+		})
+
+		It("IsSnoozed returns true before the snoozed until time", func() {
+			now := time.Now()
+			rule.SnoozedUntilTime = pointer.FromTime(now.Add(time.Minute))
+			Expect(rule.IsSnoozed(now)).To(BeTrue())
+			Expect(rule.IsSnoozed(now.Add(time.Minute))).To(BeFalse())
+		})
+
+		It("RateMmolL returns the rate normalized to mmol/L", func() {
+			Expect(rule.RateMmolL()).To(Equal(pointer.FromFloat64(0.11101)))
+		})
+
+		It("ThresholdMmolL returns nil if there is no threshold", func() {
+			Expect(rule.ThresholdMmolL()).To(BeNil())
+		})
+	})
+
+	Context("ValidateRuleID", func() {
+		It("returns an error if the id is empty", func() {
+			Expect(alert.ValidateRuleID("")).To(MatchError("value is empty"))
+		})
+
+		It("returns an error if the id is invalid", func() {
+			Expect(alert.ValidateRuleID("invalid")).To(MatchError(`value "invalid" is not valid as alert rule id`))
+		})
+
+		It("returns successfully if the id is valid", func() {
+			Expect(alert.ValidateRuleID(alert.NewRuleID())).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,346 @@
+package evaluate
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/tidepool-org/platform/alert"
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/data"
+	dataBloodGlucose "github.com/tidepool-org/platform/data/blood/glucose"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/user"
+)
+
+const (
+	PageSize        = 100
+	ValueAgeMaximum = time.Hour
+)
+
+// Engine evaluates alert rules and notifies the owner of a rule when it is triggered. A rule owned by a user other
+// than the user whose data is evaluated only notifies the owner while the owner has view permission for the user and
+// the restrictions of that permission allow the triggering value.
+type Engine struct {
+	authClient         auth.Client
+	notificationClient notification.Client
+	userClient         user.Client
+}
+
+func NewEngine(authClient auth.Client, notificationClient notification.Client, userClient user.Client) (*Engine, error) {
+	if authClient == nil {
+		return nil, errors.New("auth client is missing")
+	}
+	if notificationClient == nil {
+		return nil, errors.New("notification client is missing")
+	}
+	if userClient == nil {
+		return nil, errors.New("user client is missing")
+	}
+
+	return &Engine{
+		authClient:         authClient,
+		notificationClient: notificationClient,
+		userClient:         userClient,
+	}, nil
+}
+
+// EvaluateUserData evaluates the enabled rules for the user against the glucose values in the data
+func (e *Engine) EvaluateUserData(ctx context.Context, userID string, datumArray []data.Datum) error {
+	return e.EvaluateUserValues(ctx, userID, NewValues(datumArray))
+}
+
+// EvaluateUserValues evaluates the enabled rules for the user against the sorted glucose values. Values at or
+// before the last data time of a rule were already evaluated and are ignored. Values older than the maximum age,
+// such as historical uploads, only update the last data time.
+func (e *Engine) EvaluateUserValues(ctx context.Context, userID string, values Values) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if userID == "" {
+		return errors.New("user id is missing")
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	ctx, err := e.contextWithServerSessionToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	filter := alert.NewRuleFilter()
+	filter.UserID = &userID
+	rules, err := e.listRules(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+
+		evaluation := rule.Evaluation
+		triggerValue := EvaluateRule(rule, &evaluation, values, now)
+		e.completeEvaluation(ctx, rule, evaluation, triggerValue)
+	}
+
+	return nil
+}
+
+// EvaluateNoData evaluates all enabled no data rules, triggering those rules where no data was received for the
+// duration of the rule
+func (e *Engine) EvaluateNoData(ctx context.Context) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+
+	ctx, err := e.contextWithServerSessionToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	filter := alert.NewRuleFilter()
+	filter.Type = pointer.FromString(alert.RuleTypeNoData)
+	rules, err := e.listRules(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+
+		evaluation := rule.Evaluation
+		var triggerValue *Value
+		if EvaluateNoDataRule(rule, &evaluation, now) {
+			triggerValue = &Value{Time: now}
+		}
+		e.completeEvaluation(ctx, rule, evaluation, triggerValue)
+	}
+
+	return nil
+}
+
+// EvaluateRule updates the evaluation with the values and returns the value that triggered the rule, if any. A
+// matching value only triggers an armed rule that is not snoozed. A value that does not match re-arms the rule.
+func EvaluateRule(rule *alert.Rule, evaluation *alert.Evaluation, values Values, now time.Time) *Value {
+	var triggerValue *Value
+	for _, value := range values {
+		if evaluation.LastDataTime != nil && !value.Time.After(*evaluation.LastDataTime) {
+			continue
+		}
+		evaluation.LastDataTime = pointer.FromTime(value.Time)
+
+		if now.Sub(value.Time) > ValueAgeMaximum {
+			continue
+		}
+
+		matches, known := matchRule(rule, evaluation, value)
+		if !known {
+			continue
+		}
+
+		if !matches {
+			rearm(evaluation)
+		} else if !evaluation.IsTriggered() && !rule.IsSnoozed(now) {
+			evaluation.State = alert.RuleStateTriggered
+			evaluation.TriggeredTime = pointer.FromTime(value.Time)
+			triggerValue = value
+		}
+	}
+	return triggerValue
+}
+
+// EvaluateNoDataRule triggers an armed no data rule that is not snoozed if no data was received for the duration
+// of the rule. The duration is measured from the creation of the rule if no data was ever received. The rule is
+// re-armed when data is next received.
+func EvaluateNoDataRule(rule *alert.Rule, evaluation *alert.Evaluation, now time.Time) bool {
+	if rule.Type != alert.RuleTypeNoData || evaluation.IsTriggered() || rule.IsSnoozed(now) {
+		return false
+	}
+
+	lastDataTime := rule.CreatedTime
+	if evaluation.LastDataTime != nil {
+		lastDataTime = *evaluation.LastDataTime
+	}
+	if now.Sub(lastDataTime) < rule.DurationValue() {
+		return false
+	}
+
+	evaluation.State = alert.RuleStateTriggered
+	evaluation.TriggeredTime = pointer.FromTime(now)
+	return true
+}
+
+// Returns whether the value matches the rule and whether the match is known (a rate rule cannot be evaluated
+// without a rate)
+func matchRule(rule *alert.Rule, evaluation *alert.Evaluation, value *Value) (bool, bool) {
+	switch rule.Type {
+	case alert.RuleTypeUrgentLow, alert.RuleTypeLow:
+		if threshold := rule.ThresholdMmolL(); threshold != nil {
+			return value.Value < *threshold, true
+		}
+	case alert.RuleTypeHigh:
+		if threshold := rule.ThresholdMmolL(); threshold != nil {
+			return value.Value > *threshold, true
+		}
+	case alert.RuleTypeSustainedHigh:
+		if threshold := rule.ThresholdMmolL(); threshold != nil {
+			if value.Value <= *threshold {
+				evaluation.ConditionStartTime = nil
+				return false, true
+			}
+			if evaluation.ConditionStartTime == nil {
+				evaluation.ConditionStartTime = pointer.FromTime(value.Time)
+			}
+			return value.Time.Sub(*evaluation.ConditionStartTime) >= rule.DurationValue(), true
+		}
+	case alert.RuleTypeRapidRise:
+		if rate := rule.RateMmolL(); rate != nil && value.Rate != nil {
+			return *value.Rate >= *rate, true
+		}
+	case alert.RuleTypeRapidFall:
+		if rate := rule.RateMmolL(); rate != nil && value.Rate != nil {
+			return *value.Rate <= -*rate, true
+		}
+	case alert.RuleTypeNoData:
+		return false, true
+	}
+	return false, false
+}
+
+func rearm(evaluation *alert.Evaluation) {
+	evaluation.State = alert.RuleStateArmed
+	evaluation.TriggeredTime = nil
+}
+
+// Notify the owner, if triggered, and update the rule, if the evaluation changed. Errors are logged so that the
+// remaining rules are still evaluated.
+func (e *Engine) completeEvaluation(ctx context.Context, rule *alert.Rule, evaluation alert.Evaluation, triggerValue *Value) {
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"ruleId": rule.ID, "type": rule.Type})
+
+	if triggerValue != nil {
+		if err := e.notify(ctx, rule, triggerValue); err != nil {
+			logger.WithError(err).Error("Unable to notify alert rule owner")
+		}
+	}
+
+	if !evaluationEqual(&rule.Evaluation, &evaluation) {
+		update := alert.NewRuleUpdate()
+		update.Evaluation = &evaluation
+		if _, err := e.notificationClient.UpdateRule(ctx, rule.ID, update); err != nil {
+			logger.WithError(err).Error("Unable to update alert rule evaluation")
+		}
+	}
+}
+
+func (e *Engine) notify(ctx context.Context, rule *alert.Rule, value *Value) error {
+	remote := rule.OwnerID != rule.UserID
+	if remote {
+		permissions, err := e.userClient.GetUserPermissions(ctx, rule.OwnerID, rule.UserID)
+		if err != nil && !request.IsErrorUnauthorized(err) {
+			return errors.Wrap(err, "unable to get user permissions")
+		} else if !isValueAllowed(permissions, value) {
+			log.LoggerFromContext(ctx).WithField("ruleId", rule.ID).Debug("Alert rule owner is not authorized to view value")
+			return nil
+		}
+	}
+
+	create := notification.NewNotificationCreate()
+	create.Type = notification.TypeGlucoseAlert
+	create.Payload = map[string]interface{}{
+		"ruleId":   rule.ID,
+		"ruleType": rule.Type,
+		"userId":   rule.UserID,
+		"time":     value.Time.Format(time.RFC3339),
+		"remote":   remote,
+	}
+	if rule.Type != alert.RuleTypeNoData {
+		create.Payload["value"] = valueForUnits(value.Value, rule.Units)
+		create.Payload["units"] = *rule.Units
+	}
+	if _, err := e.notificationClient.CreateUserNotification(ctx, rule.OwnerID, create); err != nil {
+		return errors.Wrap(err, "unable to create user notification")
+	}
+
+	return nil
+}
+
+// Returns whether the view permission, if granted, allows the value. A no data value has no type, so only the time
+// of the value is restricted.
+func isValueAllowed(permissions user.Permissions, value *Value) bool {
+	permission, ok := permissions[user.ViewPermission]
+	if !ok {
+		return false
+	}
+
+	restrictions, err := user.ParsePermissionRestrictions(permission)
+	if err != nil || restrictions.IsExpired(time.Now()) || !restrictions.IsTimeAllowed(value.Time) {
+		return false
+	}
+	return value.Type == "" || restrictions.IsDataTypeAllowed(value.Type)
+}
+
+func (e *Engine) contextWithServerSessionToken(ctx context.Context) (context.Context, error) {
+	serverSessionToken, err := e.authClient.ServerSessionToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get server session token")
+	}
+	return auth.NewContextWithServerSessionToken(ctx, serverSessionToken), nil
+}
+
+func (e *Engine) listRules(ctx context.Context, filter *alert.RuleFilter) (alert.Rules, error) {
+	rules := alert.Rules{}
+
+	pagination := page.NewPagination()
+	pagination.Size = PageSize
+	for {
+		pageRules, err := e.notificationClient.ListRules(ctx, filter, pagination)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to list rules")
+		}
+
+		rules = append(rules, pageRules...)
+
+		if len(pageRules) < pagination.Size {
+			return rules, nil
+		}
+		pagination.Page++
+	}
+}
+
+// Converts the value from mmol/L to the units of the rule, rounded to the precision typically displayed
+func valueForUnits(value float64, units *string) float64 {
+	if units != nil {
+		switch *units {
+		case dataBloodGlucose.MgdL, dataBloodGlucose.Mgdl:
+			return math.Round(value * dataBloodGlucose.MmolLToMgdLConversionFactor)
+		}
+	}
+	return math.Round(value*10) / 10
+}
+
+func evaluationEqual(a *alert.Evaluation, b *alert.Evaluation) bool {
+	return a.State == b.State &&
+		timeEqual(a.TriggeredTime, b.TriggeredTime) &&
+		timeEqual(a.ConditionStartTime, b.ConditionStartTime) &&
+		timeEqual(a.LastDataTime, b.LastDataTime)
+}
+
+func timeEqual(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
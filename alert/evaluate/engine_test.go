@@ -0,0 +1,433 @@
+package evaluate_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"time"
+
+	"github.com/tidepool-org/platform/alert"
+	alertEvaluate "github.com/tidepool-org/platform/alert/evaluate"
+	alertTest "github.com/tidepool-org/platform/alert/test"
+	"github.com/tidepool-org/platform/auth"
+	authTest "github.com/tidepool-org/platform/auth/test"
+	"github.com/tidepool-org/platform/data"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/log"
+	logNull "github.com/tidepool-org/platform/log/null"
+	"github.com/tidepool-org/platform/notification"
+	notificationTest "github.com/tidepool-org/platform/notification/test"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/user"
+	userTest "github.com/tidepool-org/platform/user/test"
+)
+
+func newRule(userID string, typ string, mutator func(create *alert.RuleCreate)) *alert.Rule {
+	create := alert.NewRuleCreate()
+	create.Type = typ
+	mutator(create)
+	rule, err := alert.NewRule(userID, create)
+	Expect(err).ToNot(HaveOccurred())
+	return rule
+}
+
+func newThresholdRule(userID string, typ string, threshold float64) *alert.Rule {
+	return newRule(userID, typ, func(create *alert.RuleCreate) {
+		create.Units = pointer.FromString("mg/dL")
+		create.Threshold = pointer.FromFloat64(threshold)
+	})
+}
+
+var _ = Describe("Engine", func() {
+	var authClient *authTest.Client
+	var notificationClient *notificationTest.Client
+	var userClient *userTest.Client
+
+	BeforeEach(func() {
+		authClient = authTest.NewClient()
+		notificationClient = notificationTest.NewClient()
+		userClient = userTest.NewClient()
+	})
+
+	AfterEach(func() {
+		userClient.AssertOutputsEmpty()
+		notificationClient.Expectations()
+		authClient.Expectations()
+	})
+
+	Context("NewEngine", func() {
+		It("returns an error if the auth client is missing", func() {
+			engine, err := alertEvaluate.NewEngine(nil, notificationClient, userClient)
+			Expect(err).To(MatchError("auth client is missing"))
+			Expect(engine).To(BeNil())
+		})
+
+		It("returns an error if the notification client is missing", func() {
+			engine, err := alertEvaluate.NewEngine(authClient, nil, userClient)
+			Expect(err).To(MatchError("notification client is missing"))
+			Expect(engine).To(BeNil())
+		})
+
+		It("returns an error if the user client is missing", func() {
+			engine, err := alertEvaluate.NewEngine(authClient, notificationClient, nil)
+			Expect(err).To(MatchError("user client is missing"))
+			Expect(engine).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			Expect(alertEvaluate.NewEngine(authClient, notificationClient, userClient)).ToNot(BeNil())
+		})
+	})
+
+	Context("EvaluateRule", func() {
+		var userID string
+		var now time.Time
+
+		BeforeEach(func() {
+			userID = user.NewID()
+			now = time.Now().UTC().Truncate(time.Second)
+		})
+
+		It("triggers an armed rule on the first matching value only", func() {
+			rule := newThresholdRule(userID, alert.RuleTypeLow, 70)
+			evaluation := rule.Evaluation
+			values := alertEvaluate.NewValues([]data.Datum{
+				newContinuous(now.Add(-10*time.Minute), "mg/dL", 80, nil),
+				newContinuous(now.Add(-5*time.Minute), "mg/dL", 65, nil),
+				newContinuous(now, "mg/dL", 60, nil),
+			})
+			triggerValue := alertEvaluate.EvaluateRule(rule, &evaluation, values, now)
+			Expect(triggerValue).To(Equal(values[1]))
+			Expect(evaluation.State).To(Equal(alert.RuleStateTriggered))
+			Expect(evaluation.TriggeredTime).To(Equal(pointer.FromTime(now.Add(-5 * time.Minute))))
+			Expect(evaluation.LastDataTime).To(Equal(pointer.FromTime(now)))
+		})
+
+		It("does not trigger a rule that is already triggered", func() {
+			rule := newThresholdRule(userID, alert.RuleTypeUrgentLow, 55)
+			evaluation := alert.Evaluation{State: alert.RuleStateTriggered, TriggeredTime: pointer.FromTime(now.Add(-time.Hour))}
+			values := alertEvaluate.NewValues([]data.Datum{newContinuous(now, "mg/dL", 50, nil)})
+			Expect(alertEvaluate.EvaluateRule(rule, &evaluation, values, now)).To(BeNil())
+			Expect(evaluation.State).To(Equal(alert.RuleStateTriggered))
+			Expect(evaluation.TriggeredTime).To(Equal(pointer.FromTime(now.Add(-time.Hour))))
+		})
+
+		It("re-arms a triggered rule once a value no longer matches and triggers again", func() {
+			rule := newThresholdRule(userID, alert.RuleTypeHigh, 250)
+			evaluation := alert.Evaluation{State: alert.RuleStateTriggered, TriggeredTime: pointer.FromTime(now.Add(-time.Hour))}
+			values := alertEvaluate.NewValues([]data.Datum{
+				newSelfMonitored(now.Add(-10*time.Minute), "mg/dL", 200),
+				newSelfMonitored(now, "mg/dL", 300),
+			})
+			Expect(alertEvaluate.EvaluateRule(rule, &evaluation, values, now)).To(Equal(values[1]))
+			Expect(evaluation.State).To(Equal(alert.RuleStateTriggered))
+			Expect(evaluation.TriggeredTime).To(Equal(pointer.FromTime(now)))
+		})
+
+		It("does not trigger a snoozed rule, but still re-arms it", func() {
+			rule := newThresholdRule(userID, alert.RuleTypeLow, 70)
+			rule.SnoozedUntilTime = pointer.FromTime(now.Add(30 * time.Minute))
+			evaluation := alert.Evaluation{State: alert.RuleStateTriggered, TriggeredTime: pointer.FromTime(now.Add(-time.Hour))}
+			values := alertEvaluate.NewValues([]data.Datum{
+				newContinuous(now.Add(-10*time.Minute), "mg/dL", 100, nil),
+				newContinuous(now, "mg/dL", 60, nil),
+			})
+			Expect(alertEvaluate.EvaluateRule(rule, &evaluation, values, now)).To(BeNil())
+			Expect(evaluation.State).To(Equal(alert.RuleStateArmed))
+			Expect(evaluation.TriggeredTime).To(BeNil())
+		})
+
+		It("ignores values already evaluated and only records the time of old values", func() {
+			rule := newThresholdRule(userID, alert.RuleTypeLow, 70)
+			evaluation := alert.Evaluation{State: alert.RuleStateArmed, LastDataTime: pointer.FromTime(now.Add(-2 * time.Hour))}
+			values := alertEvaluate.NewValues([]data.Datum{
+				newContinuous(now.Add(-3*time.Hour), "mg/dL", 40, nil),
+				newContinuous(now.Add(-90*time.Minute), "mg/dL", 40, nil),
+			})
+			Expect(alertEvaluate.EvaluateRule(rule, &evaluation, values, now)).To(BeNil())
+			Expect(evaluation.State).To(Equal(alert.RuleStateArmed))
+			Expect(evaluation.LastDataTime).To(Equal(pointer.FromTime(now.Add(-90 * time.Minute))))
+		})
+
+		It("triggers a sustained high rule once high for the duration", func() {
+			rule := newRule(userID, alert.RuleTypeSustainedHigh, func(create *alert.RuleCreate) {
+				create.Units = pointer.FromString("mg/dL")
+				create.Threshold = pointer.FromFloat64(250)
+				create.Duration = pointer.FromInt(30)
+			})
+			evaluation := rule.Evaluation
+			values := alertEvaluate.NewValues([]data.Datum{
+				newContinuous(now.Add(-50*time.Minute), "mg/dL", 260, nil),
+				newContinuous(now.Add(-40*time.Minute), "mg/dL", 240, nil),
+				newContinuous(now.Add(-30*time.Minute), "mg/dL", 260, nil),
+				newContinuous(now.Add(-5*time.Minute), "mg/dL", 270, nil),
+			})
+			Expect(alertEvaluate.EvaluateRule(rule, &evaluation, values, now)).To(BeNil())
+			Expect(evaluation.State).To(Equal(alert.RuleStateArmed))
+			Expect(evaluation.ConditionStartTime).To(Equal(pointer.FromTime(now.Add(-30 * time.Minute))))
+			values = alertEvaluate.NewValues([]data.Datum{newContinuous(now, "mg/dL", 265, nil)})
+			Expect(alertEvaluate.EvaluateRule(rule, &evaluation, values, now)).To(Equal(values[0]))
+			Expect(evaluation.State).To(Equal(alert.RuleStateTriggered))
+		})
+
+		It("triggers a rapid fall rule using the rate and ignores values without a rate", func() {
+			rule := newRule(userID, alert.RuleTypeRapidFall, func(create *alert.RuleCreate) {
+				create.Units = pointer.FromString("mg/dL")
+				create.Rate = pointer.FromFloat64(2)
+			})
+			evaluation := alert.Evaluation{State: alert.RuleStateTriggered, TriggeredTime: pointer.FromTime(now.Add(-time.Hour))}
+			values := alertEvaluate.NewValues([]data.Datum{
+				newContinuous(now.Add(-40*time.Minute), "mg/dL", 120, nil),
+				newContinuous(now.Add(-10*time.Minute), "mg/dL", 110, map[string]interface{}{"trend": "flat"}),
+				newContinuous(now, "mg/dL", 90, map[string]interface{}{"trend": "singleDown"}),
+			})
+			Expect(alertEvaluate.EvaluateRule(rule, &evaluation, values, now)).To(Equal(values[2]))
+			Expect(evaluation.State).To(Equal(alert.RuleStateTriggered))
+		})
+
+		It("re-arms a no data rule when data is received", func() {
+			rule := newRule(userID, alert.RuleTypeNoData, func(create *alert.RuleCreate) { create.Duration = pointer.FromInt(60) })
+			evaluation := alert.Evaluation{State: alert.RuleStateTriggered, TriggeredTime: pointer.FromTime(now.Add(-time.Hour))}
+			values := alertEvaluate.NewValues([]data.Datum{newContinuous(now, "mg/dL", 100, nil)})
+			Expect(alertEvaluate.EvaluateRule(rule, &evaluation, values, now)).To(BeNil())
+			Expect(evaluation.State).To(Equal(alert.RuleStateArmed))
+			Expect(evaluation.LastDataTime).To(Equal(pointer.FromTime(now)))
+		})
+	})
+
+	Context("EvaluateNoDataRule", func() {
+		var rule *alert.Rule
+		var now time.Time
+
+		BeforeEach(func() {
+			rule = newRule(user.NewID(), alert.RuleTypeNoData, func(create *alert.RuleCreate) { create.Duration = pointer.FromInt(60) })
+			now = time.Now().UTC().Truncate(time.Second)
+		})
+
+		It("does not trigger if data was received within the duration", func() {
+			evaluation := alert.Evaluation{State: alert.RuleStateArmed, LastDataTime: pointer.FromTime(now.Add(-59 * time.Minute))}
+			Expect(alertEvaluate.EvaluateNoDataRule(rule, &evaluation, now)).To(BeFalse())
+			Expect(evaluation.State).To(Equal(alert.RuleStateArmed))
+		})
+
+		It("does not trigger within the duration of creation if data was never received", func() {
+			evaluation := rule.Evaluation
+			Expect(alertEvaluate.EvaluateNoDataRule(rule, &evaluation, now)).To(BeFalse())
+		})
+
+		It("does not trigger if snoozed", func() {
+			rule.SnoozedUntilTime = pointer.FromTime(now.Add(time.Minute))
+			evaluation := alert.Evaluation{State: alert.RuleStateArmed, LastDataTime: pointer.FromTime(now.Add(-2 * time.Hour))}
+			Expect(alertEvaluate.EvaluateNoDataRule(rule, &evaluation, now)).To(BeFalse())
+		})
+
+		It("does not trigger if already triggered", func() {
+			evaluation := alert.Evaluation{State: alert.RuleStateTriggered, TriggeredTime: pointer.FromTime(now.Add(-time.Hour)), LastDataTime: pointer.FromTime(now.Add(-2 * time.Hour))}
+			Expect(alertEvaluate.EvaluateNoDataRule(rule, &evaluation, now)).To(BeFalse())
+		})
+
+		It("triggers if no data was received for the duration", func() {
+			evaluation := alert.Evaluation{State: alert.RuleStateArmed, LastDataTime: pointer.FromTime(now.Add(-time.Hour))}
+			Expect(alertEvaluate.EvaluateNoDataRule(rule, &evaluation, now)).To(BeTrue())
+			Expect(evaluation.State).To(Equal(alert.RuleStateTriggered))
+			Expect(evaluation.TriggeredTime).To(Equal(pointer.FromTime(now)))
+		})
+	})
+
+	Context("with new engine", func() {
+		var engine *alertEvaluate.Engine
+		var ctx context.Context
+		var serverSessionToken string
+		var userID string
+		var now time.Time
+
+		BeforeEach(func() {
+			var err error
+			engine, err = alertEvaluate.NewEngine(authClient, notificationClient, userClient)
+			Expect(err).ToNot(HaveOccurred())
+			ctx = log.NewContextWithLogger(context.Background(), logNull.NewLogger())
+			serverSessionToken = authTest.NewSessionToken()
+			userID = user.NewID()
+			now = time.Now().UTC().Truncate(time.Second)
+		})
+
+		Context("EvaluateUserData", func() {
+			It("returns an error if the user id is missing", func() {
+				Expect(engine.EvaluateUserData(ctx, "", nil)).To(MatchError("user id is missing"))
+			})
+
+			It("returns successfully without requests if there are no glucose values", func() {
+				Expect(engine.EvaluateUserData(ctx, userID, []data.Datum{})).To(Succeed())
+			})
+
+			It("returns an error if the server session token returns an error", func() {
+				authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: "", Error: errorsTest.NewError()}}
+				Expect(engine.EvaluateUserData(ctx, userID, []data.Datum{newContinuous(now, "mg/dL", 60, nil)})).To(MatchError(ContainSubstring("unable to get server session token")))
+			})
+
+			Context("with server session token", func() {
+				BeforeEach(func() {
+					authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: serverSessionToken, Error: nil}}
+				})
+
+				It("returns an error if list rules returns an error", func() {
+					notificationClient.ListRulesOutputs = []alertTest.ListRulesOutput{{Rules: nil, Error: errorsTest.NewError()}}
+					Expect(engine.EvaluateUserData(ctx, userID, []data.Datum{newContinuous(now, "mg/dL", 60, nil)})).To(MatchError(ContainSubstring("unable to list rules")))
+				})
+
+				It("notifies the user and updates the evaluation of triggered rules, skipping disabled rules", func() {
+					lowRule := newThresholdRule(userID, alert.RuleTypeLow, 70)
+					highRule := newThresholdRule(userID, alert.RuleTypeHigh, 250)
+					disabledRule := newThresholdRule(userID, alert.RuleTypeUrgentLow, 55)
+					disabledRule.Enabled = false
+					notificationClient.ListRulesOutputs = []alertTest.ListRulesOutput{{Rules: alert.Rules{lowRule, highRule, disabledRule}, Error: nil}}
+					notificationClient.CreateUserNotificationOutputs = []notificationTest.CreateUserNotificationOutput{{Notification: nil, Error: nil}}
+					notificationClient.UpdateRuleOutputs = []alertTest.UpdateRuleOutput{{Rule: lowRule, Error: nil}, {Rule: highRule, Error: errorsTest.NewError()}}
+					Expect(engine.EvaluateUserData(ctx, userID, []data.Datum{newContinuous(now, "mg/dL", 50, nil)})).To(Succeed())
+					Expect(notificationClient.ListRulesInputs).To(HaveLen(1))
+					Expect(auth.ServerSessionTokenFromContext(notificationClient.ListRulesInputs[0].Context)).To(Equal(serverSessionToken))
+					Expect(notificationClient.ListRulesInputs[0].Filter.UserID).To(Equal(pointer.FromString(userID)))
+					Expect(notificationClient.CreateUserNotificationInputs).To(HaveLen(1))
+					Expect(notificationClient.CreateUserNotificationInputs[0].UserID).To(Equal(userID))
+					Expect(notificationClient.CreateUserNotificationInputs[0].Create.Type).To(Equal(notification.TypeGlucoseAlert))
+					Expect(notificationClient.CreateUserNotificationInputs[0].Create.Payload).To(Equal(map[string]interface{}{
+						"ruleId":   lowRule.ID,
+						"ruleType": alert.RuleTypeLow,
+						"userId":   userID,
+						"time":     now.Format(time.RFC3339),
+						"remote":   false,
+						"value":    50.0,
+						"units":    "mg/dL",
+					}))
+					Expect(notificationClient.UpdateRuleInputs).To(HaveLen(2))
+					Expect(notificationClient.UpdateRuleInputs[0].ID).To(Equal(lowRule.ID))
+					Expect(notificationClient.UpdateRuleInputs[0].Update.Evaluation.State).To(Equal(alert.RuleStateTriggered))
+					Expect(notificationClient.UpdateRuleInputs[1].ID).To(Equal(highRule.ID))
+					Expect(notificationClient.UpdateRuleInputs[1].Update.Evaluation.State).To(Equal(alert.RuleStateArmed))
+					Expect(notificationClient.UpdateRuleInputs[1].Update.Evaluation.LastDataTime).To(Equal(pointer.FromTime(now)))
+				})
+
+				It("notifies a caregiver with view permission", func() {
+					ownerID := user.NewID()
+					rule := newRule(userID, alert.RuleTypeUrgentLow, func(create *alert.RuleCreate) {
+						create.OwnerID = pointer.FromString(ownerID)
+						create.Units = pointer.FromString("mmol/L")
+						create.Threshold = pointer.FromFloat64(3.0)
+					})
+					notificationClient.ListRulesOutputs = []alertTest.ListRulesOutput{{Rules: alert.Rules{rule}, Error: nil}}
+					userClient.GetUserPermissionsOutputs = []userTest.GetUserPermissionsOutput{{Permissions: user.Permissions{user.ViewPermission: user.Permission{}}, Error: nil}}
+					notificationClient.CreateUserNotificationOutputs = []notificationTest.CreateUserNotificationOutput{{Notification: nil, Error: nil}}
+					notificationClient.UpdateRuleOutputs = []alertTest.UpdateRuleOutput{{Rule: rule, Error: nil}}
+					Expect(engine.EvaluateUserData(ctx, userID, []data.Datum{newContinuous(now, "mmol/L", 2.84, nil)})).To(Succeed())
+					Expect(userClient.GetUserPermissionsInputs).To(Equal([]userTest.GetUserPermissionsInput{{Context: userClient.GetUserPermissionsInputs[0].Context, RequestUserID: ownerID, TargetUserID: userID}}))
+					Expect(notificationClient.CreateUserNotificationInputs).To(HaveLen(1))
+					Expect(notificationClient.CreateUserNotificationInputs[0].UserID).To(Equal(ownerID))
+					Expect(notificationClient.CreateUserNotificationInputs[0].Create.Payload["remote"]).To(BeTrue())
+					Expect(notificationClient.CreateUserNotificationInputs[0].Create.Payload["value"]).To(Equal(2.8))
+				})
+
+				It("does not notify a caregiver without view permission, but still updates the evaluation", func() {
+					rule := newRule(userID, alert.RuleTypeLow, func(create *alert.RuleCreate) {
+						create.OwnerID = pointer.FromString(user.NewID())
+						create.Units = pointer.FromString("mg/dL")
+						create.Threshold = pointer.FromFloat64(70)
+					})
+					notificationClient.ListRulesOutputs = []alertTest.ListRulesOutput{{Rules: alert.Rules{rule}, Error: nil}}
+					userClient.GetUserPermissionsOutputs = []userTest.GetUserPermissionsOutput{{Permissions: nil, Error: request.ErrorUnauthorized()}}
+					notificationClient.UpdateRuleOutputs = []alertTest.UpdateRuleOutput{{Rule: rule, Error: nil}}
+					Expect(engine.EvaluateUserData(ctx, userID, []data.Datum{newContinuous(now, "mg/dL", 50, nil)})).To(Succeed())
+					Expect(notificationClient.CreateUserNotificationInputs).To(BeEmpty())
+					Expect(notificationClient.UpdateRuleInputs).To(HaveLen(1))
+				})
+
+				Context("with a caregiver with restricted view permission", func() {
+					var ownerID string
+					var rule *alert.Rule
+
+					BeforeEach(func() {
+						ownerID = user.NewID()
+						rule = newRule(userID, alert.RuleTypeLow, func(create *alert.RuleCreate) {
+							create.OwnerID = pointer.FromString(ownerID)
+							create.Units = pointer.FromString("mg/dL")
+							create.Threshold = pointer.FromFloat64(70)
+						})
+						notificationClient.ListRulesOutputs = []alertTest.ListRulesOutput{{Rules: alert.Rules{rule}, Error: nil}}
+						notificationClient.UpdateRuleOutputs = []alertTest.UpdateRuleOutput{{Rule: rule, Error: nil}}
+					})
+
+					It("notifies if the restrictions allow the value", func() {
+						permission := user.Permission{"startTime": now.Add(-time.Hour).Format(user.PermissionRestrictionsTimeFormat), "dataTypes": []interface{}{"cbg"}}
+						userClient.GetUserPermissionsOutputs = []userTest.GetUserPermissionsOutput{{Permissions: user.Permissions{user.ViewPermission: permission}, Error: nil}}
+						notificationClient.CreateUserNotificationOutputs = []notificationTest.CreateUserNotificationOutput{{Notification: nil, Error: nil}}
+						Expect(engine.EvaluateUserData(ctx, userID, []data.Datum{newContinuous(now, "mg/dL", 50, nil)})).To(Succeed())
+						Expect(notificationClient.CreateUserNotificationInputs).To(HaveLen(1))
+						Expect(notificationClient.CreateUserNotificationInputs[0].UserID).To(Equal(ownerID))
+					})
+
+					It("does not notify if the data type is not allowed", func() {
+						permission := user.Permission{"dataTypes": []interface{}{"smbg"}}
+						userClient.GetUserPermissionsOutputs = []userTest.GetUserPermissionsOutput{{Permissions: user.Permissions{user.ViewPermission: permission}, Error: nil}}
+						Expect(engine.EvaluateUserData(ctx, userID, []data.Datum{newContinuous(now, "mg/dL", 50, nil)})).To(Succeed())
+						Expect(notificationClient.CreateUserNotificationInputs).To(BeEmpty())
+						Expect(notificationClient.UpdateRuleInputs).To(HaveLen(1))
+					})
+
+					It("does not notify if the value is outside of the time window", func() {
+						permission := user.Permission{"endTime": now.Add(-time.Minute).Format(user.PermissionRestrictionsTimeFormat)}
+						userClient.GetUserPermissionsOutputs = []userTest.GetUserPermissionsOutput{{Permissions: user.Permissions{user.ViewPermission: permission}, Error: nil}}
+						Expect(engine.EvaluateUserData(ctx, userID, []data.Datum{newContinuous(now, "mg/dL", 50, nil)})).To(Succeed())
+						Expect(notificationClient.CreateUserNotificationInputs).To(BeEmpty())
+						Expect(notificationClient.UpdateRuleInputs).To(HaveLen(1))
+					})
+
+					It("does not notify if the permission is expired", func() {
+						permission := user.Permission{"expirationTime": now.Add(-time.Minute).Format(user.PermissionRestrictionsTimeFormat)}
+						userClient.GetUserPermissionsOutputs = []userTest.GetUserPermissionsOutput{{Permissions: user.Permissions{user.ViewPermission: permission}, Error: nil}}
+						Expect(engine.EvaluateUserData(ctx, userID, []data.Datum{newContinuous(now, "mg/dL", 50, nil)})).To(Succeed())
+						Expect(notificationClient.CreateUserNotificationInputs).To(BeEmpty())
+					})
+				})
+
+				It("does not update rules whose evaluation did not change", func() {
+					rule := newThresholdRule(userID, alert.RuleTypeLow, 70)
+					rule.Evaluation.LastDataTime = pointer.FromTime(now)
+					notificationClient.ListRulesOutputs = []alertTest.ListRulesOutput{{Rules: alert.Rules{rule}, Error: nil}}
+					Expect(engine.EvaluateUserData(ctx, userID, []data.Datum{newContinuous(now, "mg/dL", 50, nil)})).To(Succeed())
+				})
+			})
+		})
+
+		Context("EvaluateNoData", func() {
+			It("returns an error if the server session token returns an error", func() {
+				authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: "", Error: errorsTest.NewError()}}
+				Expect(engine.EvaluateNoData(ctx)).To(MatchError(ContainSubstring("unable to get server session token")))
+			})
+
+			It("lists no data rules across pages and triggers those without recent data", func() {
+				authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: serverSessionToken, Error: nil}}
+				firstRules := alert.Rules{}
+				for index := 0; index < alertEvaluate.PageSize; index++ {
+					rule := newRule(user.NewID(), alert.RuleTypeNoData, func(create *alert.RuleCreate) { create.Duration = pointer.FromInt(60) })
+					rule.Evaluation.LastDataTime = pointer.FromTime(now.Add(-30 * time.Minute))
+					firstRules = append(firstRules, rule)
+				}
+				rule := newRule(userID, alert.RuleTypeNoData, func(create *alert.RuleCreate) { create.Duration = pointer.FromInt(60) })
+				rule.Evaluation.LastDataTime = pointer.FromTime(now.Add(-2 * time.Hour))
+				notificationClient.ListRulesOutputs = []alertTest.ListRulesOutput{{Rules: firstRules, Error: nil}, {Rules: alert.Rules{rule}, Error: nil}}
+				notificationClient.CreateUserNotificationOutputs = []notificationTest.CreateUserNotificationOutput{{Notification: nil, Error: nil}}
+				notificationClient.UpdateRuleOutputs = []alertTest.UpdateRuleOutput{{Rule: rule, Error: nil}}
+				Expect(engine.EvaluateNoData(ctx)).To(Succeed())
+				Expect(notificationClient.ListRulesInputs).To(HaveLen(2))
+				Expect(notificationClient.ListRulesInputs[0].Filter.Type).To(Equal(pointer.FromString(alert.RuleTypeNoData)))
+				Expect(notificationClient.ListRulesInputs[1].Pagination.Page).To(Equal(1))
+				Expect(notificationClient.CreateUserNotificationInputs).To(HaveLen(1))
+				Expect(notificationClient.CreateUserNotificationInputs[0].UserID).To(Equal(userID))
+				Expect(notificationClient.CreateUserNotificationInputs[0].Create.Payload).ToNot(HaveKey("value"))
+				Expect(notificationClient.UpdateRuleInputs).To(HaveLen(1))
+				Expect(notificationClient.UpdateRuleInputs[0].ID).To(Equal(rule.ID))
+			})
+		})
+	})
+})
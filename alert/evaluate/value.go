@@ -0,0 +1,134 @@
+package evaluate
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/tidepool-org/platform/data"
+	dataBloodGlucose "github.com/tidepool-org/platform/data/blood/glucose"
+	"github.com/tidepool-org/platform/data/types"
+	"github.com/tidepool-org/platform/data/types/blood/glucose/continuous"
+	"github.com/tidepool-org/platform/data/types/blood/glucose/selfmonitored"
+)
+
+const RateIntervalMaximum = 15 * time.Minute
+
+// Representative rates, in mg/dL per minute, for each trend if the payload does not include the trend rate
+var trendRatesMgdL = map[string]float64{
+	continuous.TrendDoubleUp:      3.0,
+	continuous.TrendSingleUp:      2.0,
+	continuous.TrendFortyFiveUp:   1.0,
+	continuous.TrendFlat:          0.0,
+	continuous.TrendFortyFiveDown: -1.0,
+	continuous.TrendSingleDown:    -2.0,
+	continuous.TrendDoubleDown:    -3.0,
+}
+
+// Value is a glucose value, in mmol/L, and, if known, the rate of change, in mmol/L per minute
+type Value struct {
+	Type  string    `json:"type"`
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+	Rate  *float64  `json:"rate,omitempty"`
+}
+
+type Values []*Value
+
+// Evaluable returns the sorted values that may still trigger a rule, those within the maximum age, along with the
+// latest value, which updates the last data time of a rule regardless of age
+func (v Values) Evaluable(now time.Time) Values {
+	for index, value := range v {
+		if now.Sub(value.Time) <= ValueAgeMaximum {
+			return v[index:]
+		}
+	}
+	if len(v) > 0 {
+		return v[len(v)-1:]
+	}
+	return v
+}
+
+// NewValues returns the continuous and self-monitored glucose values, sorted by time. The rate of change of a
+// continuous glucose value is taken from the trend rate or trend in the payload, if present, or calculated from
+// the preceding continuous glucose value, if recent enough.
+func NewValues(datumArray []data.Datum) Values {
+	values := Values{}
+	for _, datum := range datumArray {
+		switch typedDatum := datum.(type) {
+		case *continuous.Continuous:
+			if value := newValue(&typedDatum.Base, typedDatum.Units, typedDatum.Value); value != nil {
+				value.Rate = payloadRate(typedDatum.Payload)
+				values = append(values, value)
+			}
+		case *selfmonitored.SelfMonitored:
+			if value := newValue(&typedDatum.Base, typedDatum.Units, typedDatum.Value); value != nil {
+				values = append(values, value)
+			}
+		}
+	}
+
+	sort.SliceStable(values, func(i int, j int) bool { return values[i].Time.Before(values[j].Time) })
+
+	var previous *Value
+	for _, value := range values {
+		if value.Type != continuous.Type {
+			continue
+		}
+		if value.Rate == nil && previous != nil {
+			if interval := value.Time.Sub(previous.Time); interval > 0 && interval <= RateIntervalMaximum {
+				rate := (value.Value - previous.Value) / interval.Minutes()
+				value.Rate = &rate
+			}
+		}
+		previous = value
+	}
+
+	return values
+}
+
+func newValue(base *types.Base, units *string, value *float64) *Value {
+	if base.Time == nil || value == nil {
+		return nil
+	}
+
+	tm, err := time.Parse(types.TimeFormat, *base.Time)
+	if err != nil {
+		return nil
+	}
+
+	return &Value{
+		Type:  base.Type,
+		Time:  tm,
+		Value: *dataBloodGlucose.NormalizeValueForUnits(value, units),
+	}
+}
+
+func payloadRate(payload *data.Blob) *float64 {
+	if payload == nil {
+		return nil
+	}
+
+	if trendRate, ok := (*payload)["trendRate"].(float64); ok {
+		if trendRateUnits, ok := (*payload)["trendRateUnits"].(string); ok && trendRateUnits == continuous.TrendRateUnitsMmolLMinute {
+			return &trendRate
+		}
+		return mgdLToMmolL(trendRate)
+	}
+
+	if trend, ok := (*payload)["trend"].(string); ok {
+		if trendRate, ok := trendRatesMgdL[trend]; ok {
+			return mgdLToMmolL(trendRate)
+		}
+	}
+
+	return nil
+}
+
+// Normalizes the magnitude so that a rate is rounded the same as the rate of a rule, regardless of direction
+func mgdLToMmolL(value float64) *float64 {
+	units := dataBloodGlucose.MgdL
+	magnitude := math.Abs(value)
+	rate := math.Copysign(*dataBloodGlucose.NormalizeValueForUnits(&magnitude, &units), value)
+	return &rate
+}
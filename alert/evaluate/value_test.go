@@ -0,0 +1,132 @@
+package evaluate_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"time"
+
+	alertEvaluate "github.com/tidepool-org/platform/alert/evaluate"
+	"github.com/tidepool-org/platform/data"
+	"github.com/tidepool-org/platform/data/types/blood/glucose/continuous"
+	"github.com/tidepool-org/platform/data/types/blood/glucose/selfmonitored"
+	"github.com/tidepool-org/platform/data/types/food"
+	"github.com/tidepool-org/platform/pointer"
+)
+
+func newContinuous(tm time.Time, units string, value float64, payload map[string]interface{}) *continuous.Continuous {
+	datum := continuous.New()
+	datum.Time = pointer.FromString(tm.Format(time.RFC3339))
+	datum.Units = pointer.FromString(units)
+	datum.Value = pointer.FromFloat64(value)
+	if payload != nil {
+		blob := data.Blob(payload)
+		datum.Payload = &blob
+	}
+	return datum
+}
+
+func newSelfMonitored(tm time.Time, units string, value float64) *selfmonitored.SelfMonitored {
+	datum := selfmonitored.New()
+	datum.Time = pointer.FromString(tm.Format(time.RFC3339))
+	datum.Units = pointer.FromString(units)
+	datum.Value = pointer.FromFloat64(value)
+	return datum
+}
+
+var _ = Describe("Value", func() {
+	Context("NewValues", func() {
+		var now time.Time
+
+		BeforeEach(func() {
+			now = time.Now().UTC().Truncate(time.Second)
+		})
+
+		It("returns empty if there are no glucose values", func() {
+			Expect(alertEvaluate.NewValues([]data.Datum{food.New()})).To(BeEmpty())
+		})
+
+		It("ignores values without a time", func() {
+			datum := newContinuous(now, "mmol/L", 5.5, nil)
+			datum.Time = nil
+			Expect(alertEvaluate.NewValues([]data.Datum{datum})).To(BeEmpty())
+		})
+
+		It("returns the values sorted by time and normalized to mmol/L", func() {
+			values := alertEvaluate.NewValues([]data.Datum{
+				newSelfMonitored(now, "mg/dL", 180.1559),
+				newContinuous(now.Add(-time.Minute), "mmol/L", 5.5, nil),
+			})
+			Expect(values).To(HaveLen(2))
+			Expect(values[0].Type).To(Equal(continuous.Type))
+			Expect(values[0].Time).To(Equal(now.Add(-time.Minute)))
+			Expect(values[0].Value).To(Equal(5.5))
+			Expect(values[0].Rate).To(BeNil())
+			Expect(values[1].Type).To(Equal(selfmonitored.Type))
+			Expect(values[1].Value).To(Equal(10.0))
+			Expect(values[1].Rate).To(BeNil())
+		})
+
+		It("uses the trend rate from the payload", func() {
+			values := alertEvaluate.NewValues([]data.Datum{
+				newContinuous(now, "mmol/L", 5.5, map[string]interface{}{"trendRate": -3.6031, "trendRateUnits": "mg/dL/min"}),
+				newContinuous(now.Add(time.Minute), "mmol/L", 5.5, map[string]interface{}{"trendRate": 0.1, "trendRateUnits": "mmol/L/min"}),
+			})
+			Expect(values).To(HaveLen(2))
+			Expect(values[0].Rate).To(Equal(pointer.FromFloat64(-0.2)))
+			Expect(values[1].Rate).To(Equal(pointer.FromFloat64(0.1)))
+		})
+
+		It("uses the trend from the payload if there is no trend rate", func() {
+			values := alertEvaluate.NewValues([]data.Datum{
+				newContinuous(now, "mmol/L", 5.5, map[string]interface{}{"trend": "doubleUp"}),
+			})
+			Expect(values).To(HaveLen(1))
+			Expect(values[0].Rate).To(Equal(pointer.FromFloat64(0.16652)))
+		})
+
+		It("calculates the rate from the preceding continuous value if recent enough", func() {
+			values := alertEvaluate.NewValues([]data.Datum{
+				newContinuous(now.Add(-30*time.Minute), "mmol/L", 4.0, nil),
+				newContinuous(now.Add(-10*time.Minute), "mmol/L", 5.0, nil),
+				newSelfMonitored(now.Add(-5*time.Minute), "mmol/L", 9.0),
+				newContinuous(now, "mmol/L", 6.0, nil),
+			})
+			Expect(values).To(HaveLen(4))
+			Expect(values[0].Rate).To(BeNil())
+			Expect(values[1].Rate).To(BeNil())
+			Expect(values[2].Rate).To(BeNil())
+			Expect(values[3].Rate).ToNot(BeNil())
+			Expect(*values[3].Rate).To(BeNumerically("~", 0.1, 0.0001))
+		})
+	})
+
+	Context("Evaluable", func() {
+		var now time.Time
+
+		BeforeEach(func() {
+			now = time.Now().UTC().Truncate(time.Second)
+		})
+
+		It("returns empty if there are no values", func() {
+			Expect(alertEvaluate.Values{}.Evaluable(now)).To(BeEmpty())
+		})
+
+		It("returns the values within the maximum age", func() {
+			values := alertEvaluate.Values{
+				{Time: now.Add(-2 * alertEvaluate.ValueAgeMaximum)},
+				{Time: now.Add(-alertEvaluate.ValueAgeMaximum)},
+				{Time: now},
+			}
+			Expect(values.Evaluable(now)).To(Equal(values[1:]))
+		})
+
+		It("returns the latest value if all values are older than the maximum age", func() {
+			values := alertEvaluate.Values{
+				{Time: now.Add(-3 * alertEvaluate.ValueAgeMaximum)},
+				{Time: now.Add(-2 * alertEvaluate.ValueAgeMaximum)},
+			}
+			Expect(values.Evaluable(now)).To(Equal(values[1:]))
+		})
+	})
+})
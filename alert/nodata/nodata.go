@@ -0,0 +1,3 @@
+package nodata
+
+const Type = "org.tidepool.alert.nodata"
@@ -0,0 +1,61 @@
+package nodata
+
+import (
+	"context"
+	"time"
+
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/task"
+)
+
+const AvailableAfterDuration = 5 * time.Minute
+
+type Evaluator interface {
+	EvaluateNoData(ctx context.Context) error
+}
+
+type Runner struct {
+	logger    log.Logger
+	evaluator Evaluator
+}
+
+func NewRunner(logger log.Logger, evaluator Evaluator) (*Runner, error) {
+	if logger == nil {
+		return nil, errors.New("logger is missing")
+	}
+	if evaluator == nil {
+		return nil, errors.New("evaluator is missing")
+	}
+
+	return &Runner{
+		logger:    logger,
+		evaluator: evaluator,
+	}, nil
+}
+
+func (r *Runner) Logger() log.Logger {
+	return r.logger
+}
+
+func (r *Runner) Evaluator() Evaluator {
+	return r.evaluator
+}
+
+func (r *Runner) CanRunTask(tsk *task.Task) bool {
+	return tsk != nil && tsk.Type == Type
+}
+
+func (r *Runner) Run(ctx context.Context, tsk *task.Task) {
+	ctx = log.NewContextWithLogger(ctx, r.Logger())
+
+	tsk.ClearError()
+
+	if err := r.Evaluator().EvaluateNoData(ctx); err != nil {
+		tsk.AppendError(errors.Wrap(err, "unable to evaluate no data alert rules"))
+	}
+
+	if !tsk.IsFailed() {
+		tsk.RepeatAvailableAfter(AvailableAfterDuration)
+	}
+}
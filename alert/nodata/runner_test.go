@@ -0,0 +1,119 @@
+package nodata_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"time"
+
+	alertNoData "github.com/tidepool-org/platform/alert/nodata"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/log"
+	logTest "github.com/tidepool-org/platform/log/test"
+	"github.com/tidepool-org/platform/task"
+	"github.com/tidepool-org/platform/test"
+)
+
+type evaluator struct {
+	Contexts []context.Context
+	Outputs  []error
+}
+
+func (e *evaluator) EvaluateNoData(ctx context.Context) error {
+	e.Contexts = append(e.Contexts, ctx)
+
+	Expect(e.Outputs).ToNot(BeEmpty())
+
+	output := e.Outputs[0]
+	e.Outputs = e.Outputs[1:]
+	return output
+}
+
+var _ = Describe("Runner", func() {
+	var logger *logTest.Logger
+	var evltr *evaluator
+
+	BeforeEach(func() {
+		logger = logTest.NewLogger()
+		evltr = &evaluator{}
+	})
+
+	AfterEach(func() {
+		Expect(evltr.Outputs).To(BeEmpty())
+	})
+
+	Context("NewRunner", func() {
+		It("returns an error if the logger is missing", func() {
+			rnnr, err := alertNoData.NewRunner(nil, evltr)
+			Expect(err).To(MatchError("logger is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the evaluator is missing", func() {
+			rnnr, err := alertNoData.NewRunner(logger, nil)
+			Expect(err).To(MatchError("evaluator is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			Expect(alertNoData.NewRunner(logger, evltr)).ToNot(BeNil())
+		})
+	})
+
+	Context("with new runner", func() {
+		var rnnr *alertNoData.Runner
+
+		BeforeEach(func() {
+			var err error
+			rnnr, err = alertNoData.NewRunner(logger, evltr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rnnr).ToNot(BeNil())
+		})
+
+		Context("CanRunTask", func() {
+			It("returns false if the task is missing", func() {
+				Expect(rnnr.CanRunTask(nil)).To(BeFalse())
+			})
+
+			It("returns false if the task type does not match", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: test.RandomString()})).To(BeFalse())
+			})
+
+			It("returns true if the task type matches", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: alertNoData.Type})).To(BeTrue())
+			})
+		})
+
+		Context("Run", func() {
+			var ctx context.Context
+			var tsk *task.Task
+
+			BeforeEach(func() {
+				var err error
+				ctx = context.Background()
+				tsk, err = task.NewTask(alertNoData.NewTaskCreate())
+				Expect(err).ToNot(HaveOccurred())
+				tsk.State = task.TaskStateRunning
+			})
+
+			It("records the error if the evaluator returns an error", func() {
+				evltr.Outputs = []error{errorsTest.NewError()}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.State).To(Equal(task.TaskStatePending))
+			})
+
+			It("repeats the task if successful", func() {
+				evltr.Outputs = []error{nil}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeFalse())
+				Expect(tsk.State).To(Equal(task.TaskStatePending))
+				Expect(tsk.AvailableTime).ToNot(BeNil())
+				Expect(*tsk.AvailableTime).To(BeTemporally("~", time.Now().Add(alertNoData.AvailableAfterDuration), time.Second))
+				Expect(evltr.Contexts).To(HaveLen(1))
+				Expect(log.LoggerFromContext(evltr.Contexts[0])).To(Equal(logger))
+			})
+		})
+	})
+})
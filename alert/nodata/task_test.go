@@ -0,0 +1,27 @@
+package nodata_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	alertNoData "github.com/tidepool-org/platform/alert/nodata"
+)
+
+var _ = Describe("Task", func() {
+	Context("TaskName", func() {
+		It("returns the type", func() {
+			Expect(alertNoData.TaskName()).To(Equal(alertNoData.Type))
+		})
+	})
+
+	Context("NewTaskCreate", func() {
+		It("returns successfully", func() {
+			taskCreate := alertNoData.NewTaskCreate()
+			Expect(taskCreate).ToNot(BeNil())
+			Expect(taskCreate.Name).ToNot(BeNil())
+			Expect(*taskCreate.Name).To(Equal(alertNoData.TaskName()))
+			Expect(taskCreate.Type).To(Equal(alertNoData.Type))
+			Expect(taskCreate.Data).To(BeEmpty())
+		})
+	})
+})
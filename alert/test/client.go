@@ -0,0 +1,15 @@
+package test
+
+type Client struct {
+	*RuleAccessor
+}
+
+func NewClient() *Client {
+	return &Client{
+		RuleAccessor: NewRuleAccessor(),
+	}
+}
+
+func (c *Client) Expectations() {
+	c.RuleAccessor.Expectations()
+}
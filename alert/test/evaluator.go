@@ -0,0 +1,46 @@
+package test
+
+import (
+	"context"
+
+	"github.com/onsi/gomega"
+
+	"github.com/tidepool-org/platform/data"
+	"github.com/tidepool-org/platform/test"
+)
+
+type EvaluateUserDataInput struct {
+	Context    context.Context
+	UserID     string
+	DatumArray []data.Datum
+}
+
+type Evaluator struct {
+	*test.Mock
+	EvaluateUserDataInvocations int
+	EvaluateUserDataInputs      []EvaluateUserDataInput
+	EvaluateUserDataOutputs     []error
+}
+
+func NewEvaluator() *Evaluator {
+	return &Evaluator{
+		Mock: test.NewMock(),
+	}
+}
+
+func (e *Evaluator) EvaluateUserData(ctx context.Context, userID string, datumArray []data.Datum) error {
+	e.EvaluateUserDataInvocations++
+
+	e.EvaluateUserDataInputs = append(e.EvaluateUserDataInputs, EvaluateUserDataInput{Context: ctx, UserID: userID, DatumArray: datumArray})
+
+	gomega.Expect(e.EvaluateUserDataOutputs).ToNot(gomega.BeEmpty())
+
+	output := e.EvaluateUserDataOutputs[0]
+	e.EvaluateUserDataOutputs = e.EvaluateUserDataOutputs[1:]
+	return output
+}
+
+func (e *Evaluator) Expectations() {
+	e.Mock.Expectations()
+	gomega.Expect(e.EvaluateUserDataOutputs).To(gomega.BeEmpty())
+}
@@ -0,0 +1,153 @@
+package test
+
+import (
+	"context"
+
+	"github.com/onsi/gomega"
+
+	"github.com/tidepool-org/platform/alert"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/test"
+)
+
+type ListRulesInput struct {
+	Context    context.Context
+	Filter     *alert.RuleFilter
+	Pagination *page.Pagination
+}
+
+type ListRulesOutput struct {
+	Rules alert.Rules
+	Error error
+}
+
+type CreateUserRuleInput struct {
+	Context context.Context
+	UserID  string
+	Create  *alert.RuleCreate
+}
+
+type CreateUserRuleOutput struct {
+	Rule  *alert.Rule
+	Error error
+}
+
+type GetRuleInput struct {
+	Context context.Context
+	ID      string
+}
+
+type GetRuleOutput struct {
+	Rule  *alert.Rule
+	Error error
+}
+
+type UpdateRuleInput struct {
+	Context context.Context
+	ID      string
+	Update  *alert.RuleUpdate
+}
+
+type UpdateRuleOutput struct {
+	Rule  *alert.Rule
+	Error error
+}
+
+type DeleteRuleInput struct {
+	Context context.Context
+	ID      string
+}
+
+type RuleAccessor struct {
+	*test.Mock
+	ListRulesInvocations      int
+	ListRulesInputs           []ListRulesInput
+	ListRulesOutputs          []ListRulesOutput
+	CreateUserRuleInvocations int
+	CreateUserRuleInputs      []CreateUserRuleInput
+	CreateUserRuleOutputs     []CreateUserRuleOutput
+	GetRuleInvocations        int
+	GetRuleInputs             []GetRuleInput
+	GetRuleOutputs            []GetRuleOutput
+	UpdateRuleInvocations     int
+	UpdateRuleInputs          []UpdateRuleInput
+	UpdateRuleOutputs         []UpdateRuleOutput
+	DeleteRuleInvocations     int
+	DeleteRuleInputs          []DeleteRuleInput
+	DeleteRuleOutputs         []error
+}
+
+func NewRuleAccessor() *RuleAccessor {
+	return &RuleAccessor{
+		Mock: test.NewMock(),
+	}
+}
+
+func (r *RuleAccessor) ListRules(ctx context.Context, filter *alert.RuleFilter, pagination *page.Pagination) (alert.Rules, error) {
+	r.ListRulesInvocations++
+
+	r.ListRulesInputs = append(r.ListRulesInputs, ListRulesInput{Context: ctx, Filter: filter, Pagination: pagination})
+
+	gomega.Expect(r.ListRulesOutputs).ToNot(gomega.BeEmpty())
+
+	output := r.ListRulesOutputs[0]
+	r.ListRulesOutputs = r.ListRulesOutputs[1:]
+	return output.Rules, output.Error
+}
+
+func (r *RuleAccessor) CreateUserRule(ctx context.Context, userID string, create *alert.RuleCreate) (*alert.Rule, error) {
+	r.CreateUserRuleInvocations++
+
+	r.CreateUserRuleInputs = append(r.CreateUserRuleInputs, CreateUserRuleInput{Context: ctx, UserID: userID, Create: create})
+
+	gomega.Expect(r.CreateUserRuleOutputs).ToNot(gomega.BeEmpty())
+
+	output := r.CreateUserRuleOutputs[0]
+	r.CreateUserRuleOutputs = r.CreateUserRuleOutputs[1:]
+	return output.Rule, output.Error
+}
+
+func (r *RuleAccessor) GetRule(ctx context.Context, id string) (*alert.Rule, error) {
+	r.GetRuleInvocations++
+
+	r.GetRuleInputs = append(r.GetRuleInputs, GetRuleInput{Context: ctx, ID: id})
+
+	gomega.Expect(r.GetRuleOutputs).ToNot(gomega.BeEmpty())
+
+	output := r.GetRuleOutputs[0]
+	r.GetRuleOutputs = r.GetRuleOutputs[1:]
+	return output.Rule, output.Error
+}
+
+func (r *RuleAccessor) UpdateRule(ctx context.Context, id string, update *alert.RuleUpdate) (*alert.Rule, error) {
+	r.UpdateRuleInvocations++
+
+	r.UpdateRuleInputs = append(r.UpdateRuleInputs, UpdateRuleInput{Context: ctx, ID: id, Update: update})
+
+	gomega.Expect(r.UpdateRuleOutputs).ToNot(gomega.BeEmpty())
+
+	output := r.UpdateRuleOutputs[0]
+	r.UpdateRuleOutputs = r.UpdateRuleOutputs[1:]
+	return output.Rule, output.Error
+}
+
+func (r *RuleAccessor) DeleteRule(ctx context.Context, id string) error {
+	r.DeleteRuleInvocations++
+
+	r.DeleteRuleInputs = append(r.DeleteRuleInputs, DeleteRuleInput{Context: ctx, ID: id})
+
+	gomega.Expect(r.DeleteRuleOutputs).ToNot(gomega.BeEmpty())
+
+	output := r.DeleteRuleOutputs[0]
+	r.DeleteRuleOutputs = r.DeleteRuleOutputs[1:]
+	return output
+}
+
+func (r *RuleAccessor) Expectations() {
+	r.Mock.Expectations()
+	gomega.Expect(r.ListRulesOutputs).To(gomega.BeEmpty())
+	gomega.Expect(r.CreateUserRuleOutputs).To(gomega.BeEmpty())
+	gomega.Expect(r.GetRuleOutputs).To(gomega.BeEmpty())
+	gomega.Expect(r.UpdateRuleOutputs).To(gomega.BeEmpty())
+	gomega.Expect(r.DeleteRuleOutputs).To(gomega.BeEmpty())
+}
@@ -0,0 +1,62 @@
+package userdata
+
+import (
+	"context"
+
+	"github.com/tidepool-org/platform/alert/evaluate"
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/data"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/task"
+)
+
+// Enqueuer evaluates user data by creating a task to evaluate the glucose values in the data so that the alert
+// rules are evaluated, and their owners notified, outside of the request that added the data
+type Enqueuer struct {
+	authClient auth.Client
+	taskClient task.Client
+}
+
+func NewEnqueuer(authClient auth.Client, taskClient task.Client) (*Enqueuer, error) {
+	if authClient == nil {
+		return nil, errors.New("auth client is missing")
+	}
+	if taskClient == nil {
+		return nil, errors.New("task client is missing")
+	}
+
+	return &Enqueuer{
+		authClient: authClient,
+		taskClient: taskClient,
+	}, nil
+}
+
+func (e *Enqueuer) EvaluateUserData(ctx context.Context, userID string, datumArray []data.Datum) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if userID == "" {
+		return errors.New("user id is missing")
+	}
+
+	values := evaluate.NewValues(datumArray)
+	if len(values) == 0 {
+		return nil
+	}
+
+	taskCreate, err := NewTaskCreate(userID, values)
+	if err != nil {
+		return err
+	}
+
+	serverSessionToken, err := e.authClient.ServerSessionToken()
+	if err != nil {
+		return errors.Wrap(err, "unable to get server session token")
+	}
+
+	if _, err = e.taskClient.CreateTask(auth.NewContextWithServerSessionToken(ctx, serverSessionToken), taskCreate); err != nil {
+		return errors.Wrap(err, "unable to create task")
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package userdata_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"time"
+
+	alertUserData "github.com/tidepool-org/platform/alert/userdata"
+	"github.com/tidepool-org/platform/auth"
+	authTest "github.com/tidepool-org/platform/auth/test"
+	"github.com/tidepool-org/platform/data"
+	"github.com/tidepool-org/platform/data/types/blood/glucose/continuous"
+	"github.com/tidepool-org/platform/data/types/food"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+	taskTest "github.com/tidepool-org/platform/task/test"
+	"github.com/tidepool-org/platform/user"
+)
+
+var _ = Describe("Enqueuer", func() {
+	var authClient *authTest.Client
+	var taskClient *taskTest.Client
+
+	BeforeEach(func() {
+		authClient = authTest.NewClient()
+		taskClient = taskTest.NewClient()
+	})
+
+	AfterEach(func() {
+		taskClient.Expectations()
+		authClient.Expectations()
+	})
+
+	Context("NewEnqueuer", func() {
+		It("returns an error if the auth client is missing", func() {
+			enqueuer, err := alertUserData.NewEnqueuer(nil, taskClient)
+			Expect(err).To(MatchError("auth client is missing"))
+			Expect(enqueuer).To(BeNil())
+		})
+
+		It("returns an error if the task client is missing", func() {
+			enqueuer, err := alertUserData.NewEnqueuer(authClient, nil)
+			Expect(err).To(MatchError("task client is missing"))
+			Expect(enqueuer).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			Expect(alertUserData.NewEnqueuer(authClient, taskClient)).ToNot(BeNil())
+		})
+	})
+
+	Context("EvaluateUserData", func() {
+		var enqueuer *alertUserData.Enqueuer
+		var ctx context.Context
+		var userID string
+		var datumArray []data.Datum
+
+		BeforeEach(func() {
+			var err error
+			enqueuer, err = alertUserData.NewEnqueuer(authClient, taskClient)
+			Expect(err).ToNot(HaveOccurred())
+			ctx = context.Background()
+			userID = user.NewID()
+			datum := continuous.New()
+			datum.Time = pointer.FromString(time.Now().UTC().Format(time.RFC3339))
+			datum.Units = pointer.FromString("mmol/L")
+			datum.Value = pointer.FromFloat64(5.5)
+			datumArray = []data.Datum{datum}
+		})
+
+		It("returns an error if the context is missing", func() {
+			Expect(enqueuer.EvaluateUserData(nil, userID, datumArray)).To(MatchError("context is missing"))
+		})
+
+		It("returns an error if the user id is missing", func() {
+			Expect(enqueuer.EvaluateUserData(ctx, "", datumArray)).To(MatchError("user id is missing"))
+		})
+
+		It("does not create a task if there are no glucose values", func() {
+			Expect(enqueuer.EvaluateUserData(ctx, userID, []data.Datum{food.New()})).To(Succeed())
+		})
+
+		It("returns an error if the server session token returns an error", func() {
+			authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: "", Error: errorsTest.NewError()}}
+			Expect(enqueuer.EvaluateUserData(ctx, userID, datumArray)).To(MatchError(HavePrefix("unable to get server session token")))
+		})
+
+		Context("with server session token", func() {
+			var serverSessionToken string
+
+			BeforeEach(func() {
+				serverSessionToken = authTest.NewSessionToken()
+				authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: serverSessionToken, Error: nil}}
+			})
+
+			It("returns an error if create task returns an error", func() {
+				taskClient.CreateTaskOutputs = []taskTest.CreateTaskOutput{{Task: nil, Error: errorsTest.NewError()}}
+				Expect(enqueuer.EvaluateUserData(ctx, userID, datumArray)).To(MatchError(HavePrefix("unable to create task")))
+			})
+
+			It("creates a task to evaluate the values", func() {
+				taskClient.CreateTaskOutputs = []taskTest.CreateTaskOutput{{Task: &task.Task{}, Error: nil}}
+				Expect(enqueuer.EvaluateUserData(ctx, userID, datumArray)).To(Succeed())
+				Expect(taskClient.CreateTaskInputs).To(HaveLen(1))
+				Expect(auth.ServerSessionTokenFromContext(taskClient.CreateTaskInputs[0].Context)).To(Equal(serverSessionToken))
+				Expect(taskClient.CreateTaskInputs[0].Create.Type).To(Equal(alertUserData.Type))
+				Expect(taskClient.CreateTaskInputs[0].Create.Data["userId"]).To(Equal(userID))
+				Expect(taskClient.CreateTaskInputs[0].Create.Data["values"]).To(HaveLen(1))
+			})
+		})
+	})
+})
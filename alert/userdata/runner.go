@@ -0,0 +1,89 @@
+package userdata
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/tidepool-org/platform/alert/evaluate"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/task"
+)
+
+type Evaluator interface {
+	EvaluateUserValues(ctx context.Context, userID string, values evaluate.Values) error
+}
+
+type Runner struct {
+	logger    log.Logger
+	evaluator Evaluator
+}
+
+func NewRunner(logger log.Logger, evaluator Evaluator) (*Runner, error) {
+	if logger == nil {
+		return nil, errors.New("logger is missing")
+	}
+	if evaluator == nil {
+		return nil, errors.New("evaluator is missing")
+	}
+
+	return &Runner{
+		logger:    logger,
+		evaluator: evaluator,
+	}, nil
+}
+
+func (r *Runner) Logger() log.Logger {
+	return r.logger
+}
+
+func (r *Runner) Evaluator() Evaluator {
+	return r.evaluator
+}
+
+func (r *Runner) CanRunTask(tsk *task.Task) bool {
+	return tsk != nil && tsk.Type == Type
+}
+
+// The task is not retried as the rules would be evaluated against values that continue to age
+func (r *Runner) Run(ctx context.Context, tsk *task.Task) {
+	logger := r.Logger().WithField("taskId", tsk.ID)
+	ctx = log.NewContextWithLogger(ctx, logger)
+
+	tsk.ClearError()
+
+	userID, ok := tsk.Data["userId"].(string)
+	if !ok || userID == "" {
+		tsk.AppendError(errors.New("user id is missing"))
+		return
+	}
+
+	values, err := parseValues(tsk.Data["values"])
+	if err != nil {
+		tsk.AppendError(errors.Wrap(err, "unable to parse values"))
+		return
+	}
+
+	if err = r.Evaluator().EvaluateUserValues(ctx, userID, values); err != nil {
+		tsk.AppendError(errors.Wrap(err, "unable to evaluate user values"))
+	}
+}
+
+// The values are decoded from the task data as generic maps, so round trip them through json
+func parseValues(raw interface{}) (evaluate.Values, error) {
+	if raw == nil {
+		return nil, errors.New("values are missing")
+	}
+
+	bytes, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	values := evaluate.Values{}
+	if err = json.Unmarshal(bytes, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
@@ -0,0 +1,165 @@
+package userdata_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"encoding/json"
+	"time"
+
+	alertEvaluate "github.com/tidepool-org/platform/alert/evaluate"
+	alertUserData "github.com/tidepool-org/platform/alert/userdata"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/log"
+	logTest "github.com/tidepool-org/platform/log/test"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+	"github.com/tidepool-org/platform/test"
+	"github.com/tidepool-org/platform/user"
+)
+
+type evaluator struct {
+	Contexts []context.Context
+	UserIDs  []string
+	Values   []alertEvaluate.Values
+	Outputs  []error
+}
+
+func (e *evaluator) EvaluateUserValues(ctx context.Context, userID string, values alertEvaluate.Values) error {
+	e.Contexts = append(e.Contexts, ctx)
+	e.UserIDs = append(e.UserIDs, userID)
+	e.Values = append(e.Values, values)
+
+	Expect(e.Outputs).ToNot(BeEmpty())
+
+	output := e.Outputs[0]
+	e.Outputs = e.Outputs[1:]
+	return output
+}
+
+var _ = Describe("Runner", func() {
+	var logger *logTest.Logger
+	var evltr *evaluator
+
+	BeforeEach(func() {
+		logger = logTest.NewLogger()
+		evltr = &evaluator{}
+	})
+
+	AfterEach(func() {
+		Expect(evltr.Outputs).To(BeEmpty())
+	})
+
+	Context("NewRunner", func() {
+		It("returns an error if the logger is missing", func() {
+			rnnr, err := alertUserData.NewRunner(nil, evltr)
+			Expect(err).To(MatchError("logger is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the evaluator is missing", func() {
+			rnnr, err := alertUserData.NewRunner(logger, nil)
+			Expect(err).To(MatchError("evaluator is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			Expect(alertUserData.NewRunner(logger, evltr)).ToNot(BeNil())
+		})
+	})
+
+	Context("with new runner", func() {
+		var rnnr *alertUserData.Runner
+
+		BeforeEach(func() {
+			var err error
+			rnnr, err = alertUserData.NewRunner(logger, evltr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rnnr).ToNot(BeNil())
+		})
+
+		Context("CanRunTask", func() {
+			It("returns false if the task is missing", func() {
+				Expect(rnnr.CanRunTask(nil)).To(BeFalse())
+			})
+
+			It("returns false if the task type does not match", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: test.RandomString()})).To(BeFalse())
+			})
+
+			It("returns true if the task type matches", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: alertUserData.Type})).To(BeTrue())
+			})
+		})
+
+		Context("Run", func() {
+			var ctx context.Context
+			var userID string
+			var values alertEvaluate.Values
+			var tsk *task.Task
+
+			BeforeEach(func() {
+				ctx = context.Background()
+				userID = user.NewID()
+				now := time.Now().UTC().Truncate(time.Second)
+				values = alertEvaluate.Values{
+					{Type: "cbg", Time: now.Add(-5 * time.Minute), Value: 5.5},
+					{Type: "cbg", Time: now, Value: 6.5, Rate: pointer.FromFloat64(0.2)},
+				}
+				taskCreate, err := alertUserData.NewTaskCreate(userID, values)
+				Expect(err).ToNot(HaveOccurred())
+
+				// The task data is stored and retrieved as generic maps
+				bytes, err := json.Marshal(taskCreate)
+				Expect(err).ToNot(HaveOccurred())
+				taskCreate = task.NewTaskCreate()
+				Expect(json.Unmarshal(bytes, taskCreate)).To(Succeed())
+
+				tsk, err = task.NewTask(taskCreate)
+				Expect(err).ToNot(HaveOccurred())
+				tsk.State = task.TaskStateRunning
+			})
+
+			It("records an error if the user id is missing", func() {
+				delete(tsk.Data, "userId")
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.Error.Error).To(MatchError("user id is missing"))
+			})
+
+			It("records an error if the values are missing", func() {
+				delete(tsk.Data, "values")
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.Error.Error).To(MatchError("unable to parse values; values are missing"))
+			})
+
+			It("records an error if the values are invalid", func() {
+				tsk.Data["values"] = "invalid"
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.Error.Error).To(MatchError(HavePrefix("unable to parse values")))
+			})
+
+			It("records an error if the evaluator returns an error", func() {
+				evltr.Outputs = []error{errorsTest.NewError()}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.Error.Error).To(MatchError(HavePrefix("unable to evaluate user values")))
+				Expect(tsk.State).To(Equal(task.TaskStateRunning))
+			})
+
+			It("evaluates the values", func() {
+				evltr.Outputs = []error{nil}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeFalse())
+				Expect(tsk.State).To(Equal(task.TaskStateRunning))
+				Expect(evltr.Contexts).To(HaveLen(1))
+				Expect(log.LoggerFromContext(evltr.Contexts[0])).ToNot(BeNil())
+				Expect(evltr.UserIDs).To(Equal([]string{userID}))
+				Expect(evltr.Values).To(Equal([]alertEvaluate.Values{values}))
+			})
+		})
+	})
+})
@@ -0,0 +1,27 @@
+package userdata
+
+import (
+	"time"
+
+	"github.com/tidepool-org/platform/alert/evaluate"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/task"
+)
+
+// Only the evaluable values are included to bound the size of the task for historical uploads
+func NewTaskCreate(userID string, values evaluate.Values) (*task.TaskCreate, error) {
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+	if len(values) == 0 {
+		return nil, errors.New("values are missing")
+	}
+
+	return &task.TaskCreate{
+		Type: Type,
+		Data: map[string]interface{}{
+			"userId": userID,
+			"values": values.Evaluable(time.Now()),
+		},
+	}, nil
+}
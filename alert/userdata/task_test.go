@@ -0,0 +1,49 @@
+package userdata_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"time"
+
+	alertEvaluate "github.com/tidepool-org/platform/alert/evaluate"
+	alertUserData "github.com/tidepool-org/platform/alert/userdata"
+	"github.com/tidepool-org/platform/user"
+)
+
+var _ = Describe("Task", func() {
+	Context("NewTaskCreate", func() {
+		var userID string
+		var values alertEvaluate.Values
+
+		BeforeEach(func() {
+			userID = user.NewID()
+			now := time.Now()
+			values = alertEvaluate.Values{
+				{Type: "cbg", Time: now.Add(-2 * alertEvaluate.ValueAgeMaximum), Value: 5.5},
+				{Type: "cbg", Time: now, Value: 6.5},
+			}
+		})
+
+		It("returns an error if the user id is missing", func() {
+			taskCreate, err := alertUserData.NewTaskCreate("", values)
+			Expect(err).To(MatchError("user id is missing"))
+			Expect(taskCreate).To(BeNil())
+		})
+
+		It("returns an error if the values are missing", func() {
+			taskCreate, err := alertUserData.NewTaskCreate(userID, nil)
+			Expect(err).To(MatchError("values are missing"))
+			Expect(taskCreate).To(BeNil())
+		})
+
+		It("returns successfully with only the evaluable values", func() {
+			taskCreate, err := alertUserData.NewTaskCreate(userID, values)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(taskCreate).ToNot(BeNil())
+			Expect(taskCreate.Name).To(BeNil())
+			Expect(taskCreate.Type).To(Equal(alertUserData.Type))
+			Expect(taskCreate.Data).To(Equal(map[string]interface{}{"userId": userID, "values": values[1:]}))
+		})
+	})
+})
@@ -0,0 +1,3 @@
+package userdata
+
+const Type = "org.tidepool.alert.userdata"
@@ -0,0 +1,255 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/id"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/structure"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+	"github.com/tidepool-org/platform/user"
+)
+
+const (
+	AuditEventRestrictedTokenCreate  = "restricted-token-create"
+	AuditEventRestrictedTokenUse     = "restricted-token-use"
+	AuditEventRestrictedTokenUpdate  = "restricted-token-update"
+	AuditEventRestrictedTokenDelete  = "restricted-token-delete"
+	AuditEventProviderSessionCreate  = "provider-session-create"
+	AuditEventProviderSessionUpdate  = "provider-session-update"
+	AuditEventProviderSessionDelete  = "provider-session-delete"
+	AuditEventOAuthProviderAuthorize = "oauth-provider-authorize"
+	AuditEventOAuthProviderRedirect  = "oauth-provider-redirect"
+	AuditEventOAuthClientAuthorize   = "oauth-client-authorize"
+)
+
+// Audit entries are expired by the store after the retention duration
+const AuditEntryRetentionDuration = 2 * 365 * 24 * time.Hour
+
+// Restricted tokens may be used on every request, so successful uses are sampled
+const AuditRestrictedTokenUseInterval = 100
+
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeDenied  = "denied"
+	AuditOutcomeFailure = "failure"
+)
+
+const (
+	AuditTargetTypeRestrictedToken = "restricted-token"
+	AuditTargetTypeProviderSession = "provider-session"
+	AuditTargetTypeOAuthClient     = "oauth-client"
+)
+
+func AuditEvents() []string {
+	return []string{
+		AuditEventRestrictedTokenCreate,
+		AuditEventRestrictedTokenUse,
+		AuditEventRestrictedTokenUpdate,
+		AuditEventRestrictedTokenDelete,
+		AuditEventProviderSessionCreate,
+		AuditEventProviderSessionUpdate,
+		AuditEventProviderSessionDelete,
+		AuditEventOAuthProviderAuthorize,
+		AuditEventOAuthProviderRedirect,
+		AuditEventOAuthClientAuthorize,
+	}
+}
+
+// IsRestrictedTokenUseAudited returns true if the successful use, identified by the usage count after the use, is
+// sampled; the first use, the last allowed use, and every interval of uses in between are sampled
+func IsRestrictedTokenUseAudited(restrictedToken *RestrictedToken) bool {
+	if restrictedToken == nil {
+		return false
+	}
+	if restrictedToken.MaximumUses != nil && restrictedToken.UsageCount >= *restrictedToken.MaximumUses {
+		return true
+	}
+	return restrictedToken.UsageCount == 1 || restrictedToken.UsageCount%AuditRestrictedTokenUseInterval == 0
+}
+
+func AuditOutcomes() []string {
+	return []string{
+		AuditOutcomeSuccess,
+		AuditOutcomeDenied,
+		AuditOutcomeFailure,
+	}
+}
+
+// Audit entries are append-only; there is intentionally no update or delete
+type AuditEntryAccessor interface {
+	ListUserAuditEntries(ctx context.Context, userID string, filter *AuditEntryFilter, pagination *page.Pagination) (AuditEntries, error)
+	CreateAuditEntry(ctx context.Context, auditEntry *AuditEntry) error
+}
+
+// A user matches an audit entry if the user is either the actor or the target
+type AuditEntryFilter struct {
+	Event     *string    `json:"event,omitempty"`
+	StartTime *time.Time `json:"startTime,omitempty"`
+	EndTime   *time.Time `json:"endTime,omitempty"`
+}
+
+func NewAuditEntryFilter() *AuditEntryFilter {
+	return &AuditEntryFilter{}
+}
+
+func (a *AuditEntryFilter) Parse(parser structure.ObjectParser) {
+	a.Event = parser.String("event")
+	a.StartTime = parser.Time("startTime", time.RFC3339)
+	a.EndTime = parser.Time("endTime", time.RFC3339)
+}
+
+func (a *AuditEntryFilter) Validate(validator structure.Validator) {
+	validator.String("event", a.Event).OneOf(AuditEvents()...)
+	validator.Time("startTime", a.StartTime).NotZero()
+	if a.StartTime != nil {
+		validator.Time("endTime", a.EndTime).After(*a.StartTime)
+	} else {
+		validator.Time("endTime", a.EndTime).NotZero()
+	}
+}
+
+func (a *AuditEntryFilter) MutateRequest(req *http.Request) error {
+	parameters := map[string]string{}
+	if a.Event != nil {
+		parameters["event"] = *a.Event
+	}
+	if a.StartTime != nil {
+		parameters["startTime"] = a.StartTime.Format(time.RFC3339)
+	}
+	if a.EndTime != nil {
+		parameters["endTime"] = a.EndTime.Format(time.RFC3339)
+	}
+	return request.NewParametersMutator(parameters).MutateRequest(req)
+}
+
+func NewAuditEntryID() string {
+	return id.Must(id.New(16))
+}
+
+func IsValidAuditEntryID(value string) bool {
+	return ValidateAuditEntryID(value) == nil
+}
+
+func AuditEntryIDValidator(value string, errorReporter structure.ErrorReporter) {
+	errorReporter.ReportError(ValidateAuditEntryID(value))
+}
+
+func ValidateAuditEntryID(value string) error {
+	if value == "" {
+		return structureValidator.ErrorValueEmpty()
+	} else if !auditEntryIDExpression.MatchString(value) {
+		return ErrorValueStringAsAuditEntryIDNotValid(value)
+	}
+	return nil
+}
+
+func ErrorValueStringAsAuditEntryIDNotValid(value string) error {
+	return errors.Preparedf(structureValidator.ErrorCodeValueNotValid, "value is not valid", "value %q is not valid as audit entry id", value)
+}
+
+var auditEntryIDExpression = regexp.MustCompile("^[0-9a-f]{32}$")
+
+// AuditEntry records who (actor) did what (event) to whose (target user) restricted token,
+// provider session, or OAuth authorization, from where (IP address), and in which request (trace)
+type AuditEntry struct {
+	ID           string                 `json:"id" bson:"id"`
+	Event        string                 `json:"event" bson:"event"`
+	Outcome      string                 `json:"outcome" bson:"outcome"`
+	ActorMethod  *string                `json:"actorMethod,omitempty" bson:"actorMethod,omitempty"`
+	ActorUserID  *string                `json:"actorUserId,omitempty" bson:"actorUserId,omitempty"`
+	TargetUserID *string                `json:"targetUserId,omitempty" bson:"targetUserId,omitempty"`
+	TargetType   *string                `json:"targetType,omitempty" bson:"targetType,omitempty"`
+	TargetID     *string                `json:"targetId,omitempty" bson:"targetId,omitempty"`
+	ProviderName *string                `json:"providerName,omitempty" bson:"providerName,omitempty"`
+	IPAddress    *string                `json:"ipAddress,omitempty" bson:"ipAddress,omitempty"`
+	TraceRequest *string                `json:"traceRequest,omitempty" bson:"traceRequest,omitempty"`
+	TraceSession *string                `json:"traceSession,omitempty" bson:"traceSession,omitempty"`
+	Error        *errors.Serializable   `json:"error,omitempty" bson:"error,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty" bson:"metadata,omitempty"`
+	CreatedTime  time.Time              `json:"createdTime" bson:"createdTime"`
+}
+
+// NewAuditEntry captures the actor from the request details, and the IP address and trace from
+// the request context as populated by the trace middleware
+func NewAuditEntry(ctx context.Context, event string, outcome string) *AuditEntry {
+	auditEntry := &AuditEntry{
+		ID:          NewAuditEntryID(),
+		Event:       event,
+		Outcome:     outcome,
+		CreatedTime: time.Now().Truncate(time.Second),
+	}
+	if details := request.DetailsFromContext(ctx); details != nil {
+		auditEntry.ActorMethod = pointer.FromString(string(details.Method()))
+		if details.IsUser() {
+			auditEntry.ActorUserID = pointer.FromString(details.UserID())
+		}
+	}
+	if remoteAddress := request.RemoteAddressFromContext(ctx); remoteAddress != "" {
+		auditEntry.IPAddress = pointer.FromString(remoteAddress)
+	}
+	if traceRequest := request.TraceRequestFromContext(ctx); traceRequest != "" {
+		auditEntry.TraceRequest = pointer.FromString(traceRequest)
+	}
+	if traceSession := request.TraceSessionFromContext(ctx); traceSession != "" {
+		auditEntry.TraceSession = pointer.FromString(traceSession)
+	}
+	return auditEntry
+}
+
+func (a *AuditEntry) WithTarget(userID string, typ string, id string) *AuditEntry {
+	if userID != "" {
+		a.TargetUserID = pointer.FromString(userID)
+	}
+	if typ != "" {
+		a.TargetType = pointer.FromString(typ)
+	}
+	if id != "" {
+		a.TargetID = pointer.FromString(id)
+	}
+	return a
+}
+
+func (a *AuditEntry) WithError(err error) *AuditEntry {
+	if err != nil {
+		a.Error = &errors.Serializable{Error: err}
+	}
+	return a
+}
+
+func (a *AuditEntry) Validate(validator structure.Validator) {
+	validator.String("id", &a.ID).Using(AuditEntryIDValidator)
+	validator.String("event", &a.Event).OneOf(AuditEvents()...)
+	validator.String("outcome", &a.Outcome).OneOf(AuditOutcomes()...)
+	validator.String("actorUserId", a.ActorUserID).Using(user.IDValidator)
+	validator.String("targetUserId", a.TargetUserID).Using(user.IDValidator)
+	validator.String("targetType", a.TargetType).OneOf(AuditTargetTypeRestrictedToken, AuditTargetTypeProviderSession, AuditTargetTypeOAuthClient)
+	validator.String("targetId", a.TargetID).NotEmpty()
+	validator.String("providerName", a.ProviderName).NotEmpty()
+	validator.String("ipAddress", a.IPAddress).NotEmpty()
+	validator.Time("createdTime", &a.CreatedTime).NotZero().BeforeNow(time.Second)
+}
+
+func (a *AuditEntry) Sanitize(details request.Details) error {
+	if details != nil && details.IsService() {
+		return nil
+	}
+	return errors.New("unable to sanitize")
+}
+
+type AuditEntries []*AuditEntry
+
+func (a AuditEntries) Sanitize(details request.Details) error {
+	for _, auditEntry := range a {
+		if err := auditEntry.Sanitize(details); err != nil {
+			return err
+		}
+	}
+	return nil
+}
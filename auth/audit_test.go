@@ -0,0 +1,193 @@
+package auth_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"time"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/errors"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/request"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+	"github.com/tidepool-org/platform/user"
+)
+
+var _ = Describe("Audit", func() {
+	It("AuditEvents returns expected", func() {
+		Expect(auth.AuditEvents()).To(Equal([]string{
+			"restricted-token-create",
+			"restricted-token-use",
+			"restricted-token-update",
+			"restricted-token-delete",
+			"provider-session-create",
+			"provider-session-update",
+			"provider-session-delete",
+			"oauth-provider-authorize",
+			"oauth-provider-redirect",
+			"oauth-client-authorize",
+		}))
+	})
+
+	DescribeTable("IsRestrictedTokenUseAudited returns expected",
+		func(restrictedToken *auth.RestrictedToken, expected bool) {
+			Expect(auth.IsRestrictedTokenUseAudited(restrictedToken)).To(Equal(expected))
+		},
+		Entry("missing", nil, false),
+		Entry("first use", &auth.RestrictedToken{UsageCount: 1}, true),
+		Entry("second use", &auth.RestrictedToken{UsageCount: 2}, false),
+		Entry("interval use", &auth.RestrictedToken{UsageCount: 2 * auth.AuditRestrictedTokenUseInterval}, true),
+		Entry("after interval use", &auth.RestrictedToken{UsageCount: auth.AuditRestrictedTokenUseInterval + 1}, false),
+		Entry("last allowed use", &auth.RestrictedToken{UsageCount: 7, MaximumUses: pointer.FromInt(7)}, true),
+		Entry("before last allowed use", &auth.RestrictedToken{UsageCount: 6, MaximumUses: pointer.FromInt(7)}, false),
+	)
+
+	It("AuditOutcomes returns expected", func() {
+		Expect(auth.AuditOutcomes()).To(Equal([]string{"success", "denied", "failure"}))
+	})
+
+	Context("AuditEntryFilter", func() {
+		Context("Validate", func() {
+			startTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+			DescribeTable("validates the audit entry filter",
+				func(mutator func(filter *auth.AuditEntryFilter), expectedErrors ...error) {
+					filter := auth.NewAuditEntryFilter()
+					mutator(filter)
+					errorsTest.ExpectEqual(structureValidator.New().Validate(filter), expectedErrors...)
+				},
+				Entry("succeeds",
+					func(filter *auth.AuditEntryFilter) {},
+				),
+				Entry("event valid",
+					func(filter *auth.AuditEntryFilter) {
+						filter.Event = pointer.FromString(auth.AuditEventRestrictedTokenUse)
+					},
+				),
+				Entry("event invalid",
+					func(filter *auth.AuditEntryFilter) { filter.Event = pointer.FromString("invalid") },
+					errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("invalid", auth.AuditEvents()), "/event"),
+				),
+				Entry("start time and end time valid",
+					func(filter *auth.AuditEntryFilter) {
+						filter.StartTime = pointer.FromTime(startTime)
+						filter.EndTime = pointer.FromTime(startTime.Add(time.Minute))
+					},
+				),
+				Entry("end time before start time",
+					func(filter *auth.AuditEntryFilter) {
+						filter.StartTime = pointer.FromTime(startTime)
+						filter.EndTime = pointer.FromTime(startTime.Add(-time.Minute))
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueTimeNotAfter(startTime.Add(-time.Minute), startTime), "/endTime"),
+				),
+			)
+		})
+	})
+
+	Context("NewAuditEntry", func() {
+		It("returns successfully with empty context", func() {
+			auditEntry := auth.NewAuditEntry(context.Background(), auth.AuditEventProviderSessionCreate, auth.AuditOutcomeSuccess)
+			Expect(auditEntry).ToNot(BeNil())
+			Expect(auth.IsValidAuditEntryID(auditEntry.ID)).To(BeTrue())
+			Expect(auditEntry.Event).To(Equal(auth.AuditEventProviderSessionCreate))
+			Expect(auditEntry.Outcome).To(Equal(auth.AuditOutcomeSuccess))
+			Expect(auditEntry.ActorMethod).To(BeNil())
+			Expect(auditEntry.ActorUserID).To(BeNil())
+			Expect(auditEntry.IPAddress).To(BeNil())
+			Expect(auditEntry.TraceRequest).To(BeNil())
+			Expect(auditEntry.TraceSession).To(BeNil())
+			Expect(auditEntry.CreatedTime).ToNot(BeZero())
+		})
+
+		It("returns successfully with actor, ip address, and trace from context", func() {
+			userID := user.NewID()
+			ctx := request.NewContextWithDetails(context.Background(), request.NewDetails(request.MethodSessionToken, userID, "token"))
+			ctx = request.NewContextWithRemoteAddress(ctx, "127.0.0.1")
+			ctx = request.NewContextWithTraceRequest(ctx, "trace-request")
+			ctx = request.NewContextWithTraceSession(ctx, "trace-session")
+			auditEntry := auth.NewAuditEntry(ctx, auth.AuditEventRestrictedTokenUse, auth.AuditOutcomeDenied)
+			Expect(auditEntry.ActorMethod).To(Equal(pointer.FromString("session token")))
+			Expect(auditEntry.ActorUserID).To(Equal(pointer.FromString(userID)))
+			Expect(auditEntry.IPAddress).To(Equal(pointer.FromString("127.0.0.1")))
+			Expect(auditEntry.TraceRequest).To(Equal(pointer.FromString("trace-request")))
+			Expect(auditEntry.TraceSession).To(Equal(pointer.FromString("trace-session")))
+		})
+
+		It("does not set actor user id for service", func() {
+			ctx := request.NewContextWithDetails(context.Background(), request.NewDetails(request.MethodServiceSecret, "", ""))
+			auditEntry := auth.NewAuditEntry(ctx, auth.AuditEventRestrictedTokenDelete, auth.AuditOutcomeSuccess)
+			Expect(auditEntry.ActorMethod).To(Equal(pointer.FromString("service secret")))
+			Expect(auditEntry.ActorUserID).To(BeNil())
+		})
+	})
+
+	Context("AuditEntry", func() {
+		var auditEntry *auth.AuditEntry
+
+		BeforeEach(func() {
+			auditEntry = auth.NewAuditEntry(context.Background(), auth.AuditEventRestrictedTokenCreate, auth.AuditOutcomeSuccess)
+		})
+
+		It("WithTarget sets only non-empty values", func() {
+			userID := user.NewID()
+			Expect(auditEntry.WithTarget(userID, auth.AuditTargetTypeRestrictedToken, "")).To(BeIdenticalTo(auditEntry))
+			Expect(auditEntry.TargetUserID).To(Equal(pointer.FromString(userID)))
+			Expect(auditEntry.TargetType).To(Equal(pointer.FromString(auth.AuditTargetTypeRestrictedToken)))
+			Expect(auditEntry.TargetID).To(BeNil())
+		})
+
+		It("WithError sets only non-nil error", func() {
+			Expect(auditEntry.WithError(nil).Error).To(BeNil())
+			err := errors.New("test error")
+			Expect(auditEntry.WithError(err).Error).To(Equal(&errors.Serializable{Error: err}))
+		})
+
+		Context("Validate", func() {
+			DescribeTable("validates the audit entry",
+				func(mutator func(auditEntry *auth.AuditEntry), expectedErrors ...error) {
+					auditEntry := auth.NewAuditEntry(context.Background(), auth.AuditEventRestrictedTokenCreate, auth.AuditOutcomeSuccess)
+					mutator(auditEntry)
+					errorsTest.ExpectEqual(structureValidator.New().Validate(auditEntry), expectedErrors...)
+				},
+				Entry("succeeds",
+					func(auditEntry *auth.AuditEntry) {},
+				),
+				Entry("id invalid",
+					func(auditEntry *auth.AuditEntry) { auditEntry.ID = "invalid" },
+					errorsTest.WithPointerSource(auth.ErrorValueStringAsAuditEntryIDNotValid("invalid"), "/id"),
+				),
+				Entry("event invalid",
+					func(auditEntry *auth.AuditEntry) { auditEntry.Event = "invalid" },
+					errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("invalid", auth.AuditEvents()), "/event"),
+				),
+				Entry("outcome invalid",
+					func(auditEntry *auth.AuditEntry) { auditEntry.Outcome = "invalid" },
+					errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("invalid", auth.AuditOutcomes()), "/outcome"),
+				),
+				Entry("target user id invalid",
+					func(auditEntry *auth.AuditEntry) { auditEntry.TargetUserID = pointer.FromString("invalid") },
+					errorsTest.WithPointerSource(user.ErrorValueStringAsIDNotValid("invalid"), "/targetUserId"),
+				),
+				Entry("ip address empty",
+					func(auditEntry *auth.AuditEntry) { auditEntry.IPAddress = pointer.FromString("") },
+					errorsTest.WithPointerSource(structureValidator.ErrorValueEmpty(), "/ipAddress"),
+				),
+			)
+		})
+
+		Context("Sanitize", func() {
+			It("succeeds for service", func() {
+				Expect(auditEntry.Sanitize(request.NewDetails(request.MethodServiceSecret, "", ""))).To(Succeed())
+			})
+
+			It("returns an error for user", func() {
+				Expect(auditEntry.Sanitize(request.NewDetails(request.MethodSessionToken, user.NewID(), "token"))).To(MatchError("unable to sanitize"))
+			})
+		})
+	})
+})
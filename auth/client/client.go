@@ -76,6 +76,30 @@ func NewClient(cfg *Config, authorizeAs platform.AuthorizeAs, name string, lgr l
 	}, nil
 }
 
+func (c *Client) ListProviderSessions(ctx context.Context, filter *auth.ProviderSessionFilter, pagination *page.Pagination) (auth.ProviderSessions, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if filter == nil {
+		filter = auth.NewProviderSessionFilter()
+	} else if err := structureValidator.New().Validate(filter); err != nil {
+		return nil, errors.Wrap(err, "filter is invalid")
+	}
+	if pagination == nil {
+		pagination = page.NewPagination()
+	} else if err := structureValidator.New().Validate(pagination); err != nil {
+		return nil, errors.Wrap(err, "pagination is invalid")
+	}
+
+	url := c.client.ConstructURL("v1", "provider_sessions")
+	providerSessions := auth.ProviderSessions{}
+	if err := c.client.RequestData(ctx, http.MethodGet, url, []request.RequestMutator{filter, pagination}, nil, &providerSessions); err != nil {
+		return nil, err
+	}
+
+	return providerSessions, nil
+}
+
 func (c *Client) ListUserProviderSessions(ctx context.Context, userID string, filter *auth.ProviderSessionFilter, pagination *page.Pagination) (auth.ProviderSessions, error) {
 	if ctx == nil {
 		return nil, errors.New("context is missing")
@@ -182,6 +206,46 @@ func (c *Client) DeleteProviderSession(ctx context.Context, id string) error {
 	return c.client.RequestData(ctx, http.MethodDelete, url, nil, nil, nil)
 }
 
+func (c *Client) LockProviderSession(ctx context.Context, id string, lock *auth.ProviderSessionLock) (*auth.ProviderSession, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if id == "" {
+		return nil, errors.New("id is missing")
+	}
+	if lock == nil {
+		return nil, errors.New("lock is missing")
+	} else if err := structureValidator.New().Validate(lock); err != nil {
+		return nil, errors.Wrap(err, "lock is invalid")
+	}
+
+	url := c.client.ConstructURL("v1", "provider_sessions", id, "locks")
+	providerSession := &auth.ProviderSession{}
+	if err := c.client.RequestData(ctx, http.MethodPost, url, nil, lock, providerSession); err != nil {
+		if request.IsErrorResourceNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return providerSession, nil
+}
+
+func (c *Client) UnlockProviderSession(ctx context.Context, id string, lockID string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if id == "" {
+		return errors.New("id is missing")
+	}
+	if lockID == "" {
+		return errors.New("lock id is missing")
+	}
+
+	url := c.client.ConstructURL("v1", "provider_sessions", id, "locks", lockID)
+	return c.client.RequestData(ctx, http.MethodDelete, url, nil, nil, nil)
+}
+
 func (c *Client) ListUserRestrictedTokens(ctx context.Context, userID string, filter *auth.RestrictedTokenFilter, pagination *page.Pagination) (auth.RestrictedTokens, error) {
 	if ctx == nil {
 		return nil, errors.New("context is missing")
@@ -287,3 +351,131 @@ func (c *Client) DeleteRestrictedToken(ctx context.Context, id string) error {
 	url := c.client.ConstructURL("v1", "restricted_tokens", id)
 	return c.client.RequestData(ctx, http.MethodDelete, url, nil, nil, nil)
 }
+
+func (c *Client) UseRestrictedToken(ctx context.Context, id string, use *auth.RestrictedTokenUse) (*auth.RestrictedToken, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if id == "" {
+		return nil, errors.New("id is missing")
+	}
+	if use == nil {
+		return nil, errors.New("use is missing")
+	} else if err := structureValidator.New().Validate(use); err != nil {
+		return nil, errors.Wrap(err, "use is invalid")
+	}
+
+	url := c.client.ConstructURL("v1", "restricted_tokens", id, "use")
+	restrictedToken := &auth.RestrictedToken{}
+	if err := c.client.RequestData(ctx, http.MethodPost, url, nil, use, restrictedToken); err != nil {
+		if request.IsErrorResourceNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return restrictedToken, nil
+}
+
+func (c *Client) CreateOAuthClient(ctx context.Context, create *auth.OAuthClientCreate) (*auth.OAuthClient, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if create == nil {
+		return nil, errors.New("create is missing")
+	} else if err := structureValidator.New().Validate(create); err != nil {
+		return nil, errors.Wrap(err, "create is invalid")
+	}
+
+	url := c.client.ConstructURL("v1", "oauth", "clients")
+	oauthClient := &auth.OAuthClient{}
+	if err := c.client.RequestData(ctx, http.MethodPost, url, nil, create, oauthClient); err != nil {
+		return nil, err
+	}
+
+	return oauthClient, nil
+}
+
+func (c *Client) GetOAuthClient(ctx context.Context, id string) (*auth.OAuthClient, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if id == "" {
+		return nil, errors.New("id is missing")
+	}
+
+	url := c.client.ConstructURL("v1", "oauth", "clients", id)
+	oauthClient := &auth.OAuthClient{}
+	if err := c.client.RequestData(ctx, http.MethodGet, url, nil, nil, oauthClient); err != nil {
+		if request.IsErrorResourceNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return oauthClient, nil
+}
+
+func (c *Client) DeleteOAuthClient(ctx context.Context, id string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if id == "" {
+		return errors.New("id is missing")
+	}
+
+	url := c.client.ConstructURL("v1", "oauth", "clients", id)
+	return c.client.RequestData(ctx, http.MethodDelete, url, nil, nil, nil)
+}
+
+func (c *Client) ListUserOAuthGrants(ctx context.Context, userID string) ([]*auth.OAuthToken, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+
+	url := c.client.ConstructURL("v1", "users", userID, "oauth", "grants")
+	oauthTokens := []*auth.OAuthToken{}
+	if err := c.client.RequestData(ctx, http.MethodGet, url, nil, nil, &oauthTokens); err != nil {
+		return nil, err
+	}
+
+	return oauthTokens, nil
+}
+
+func (c *Client) DeleteUserOAuthGrants(ctx context.Context, userID string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if userID == "" {
+		return errors.New("user id is missing")
+	}
+
+	url := c.client.ConstructURL("v1", "users", userID, "oauth", "grants")
+	return c.client.RequestData(ctx, http.MethodDelete, url, nil, nil, nil)
+}
+
+func (c *Client) ValidateOAuthAccessToken(ctx context.Context, accessToken string) (*auth.OAuthToken, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if accessToken == "" {
+		return nil, errors.New("access token is missing")
+	}
+
+	validate := auth.NewOAuthAccessTokenValidate()
+	validate.AccessToken = accessToken
+
+	url := c.client.ConstructURL("v1", "oauth", "access_tokens", "validate")
+	oauthToken := &auth.OAuthToken{}
+	if err := c.client.RequestData(ctx, http.MethodPost, url, nil, validate, oauthToken); err != nil {
+		if request.IsErrorResourceNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return oauthToken, nil
+}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"regexp"
+	"time"
+
+	"github.com/tidepool-org/platform/crypto"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/id"
+	"github.com/tidepool-org/platform/net"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/structure"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
+type OAuthAccessor interface {
+	CreateOAuthClient(ctx context.Context, create *OAuthClientCreate) (*OAuthClient, error)
+	GetOAuthClient(ctx context.Context, id string) (*OAuthClient, error)
+	DeleteOAuthClient(ctx context.Context, id string) error
+
+	ValidateOAuthAccessToken(ctx context.Context, accessToken string) (*OAuthToken, error)
+
+	// ListUserOAuthGrants lists the tokens granted by the user to any client, without the tokens themselves
+	ListUserOAuthGrants(ctx context.Context, userID string) ([]*OAuthToken, error)
+	// DeleteUserOAuthGrants deletes the authorization codes and tokens granted by the user to any client
+	DeleteUserOAuthGrants(ctx context.Context, userID string) error
+}
+
+type OAuthClientCreate struct {
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirectUris"`
+	Scopes       []string `json:"scopes"`
+	Confidential bool     `json:"confidential"`
+}
+
+func NewOAuthClientCreate() *OAuthClientCreate {
+	return &OAuthClientCreate{}
+}
+
+func (o *OAuthClientCreate) Parse(parser structure.ObjectParser) {
+	if ptr := parser.String("name"); ptr != nil {
+		o.Name = *ptr
+	}
+	if ptr := parser.StringArray("redirectUris"); ptr != nil {
+		o.RedirectURIs = *ptr
+	}
+	if ptr := parser.StringArray("scopes"); ptr != nil {
+		o.Scopes = *ptr
+	}
+	if ptr := parser.Bool("confidential"); ptr != nil {
+		o.Confidential = *ptr
+	}
+}
+
+func (o *OAuthClientCreate) Validate(validator structure.Validator) {
+	validator.String("name", &o.Name).NotEmpty().LengthLessThanOrEqualTo(100)
+	validator.StringArray("redirectUris", &o.RedirectURIs).LengthInRange(1, 10).Each(func(stringValidator structure.String) {
+		stringValidator.Using(net.URLValidator)
+	}).EachUnique()
+	validator.StringArray("scopes", &o.Scopes).NotEmpty().EachOneOf(OAuthScopes()...).EachUnique()
+}
+
+func NewOAuthClientID() string {
+	return id.Must(id.New(16))
+}
+
+func IsValidOAuthClientID(value string) bool {
+	return ValidateOAuthClientID(value) == nil
+}
+
+func OAuthClientIDValidator(value string, errorReporter structure.ErrorReporter) {
+	errorReporter.ReportError(ValidateOAuthClientID(value))
+}
+
+func ValidateOAuthClientID(value string) error {
+	if value == "" {
+		return structureValidator.ErrorValueEmpty()
+	} else if !oauthClientIDExpression.MatchString(value) {
+		return ErrorValueStringAsOAuthClientIDNotValid(value)
+	}
+	return nil
+}
+
+func ErrorValueStringAsOAuthClientIDNotValid(value string) error {
+	return errors.Preparedf(structureValidator.ErrorCodeValueNotValid, "value is not valid", "value %q is not valid as oauth client id", value)
+}
+
+var oauthClientIDExpression = regexp.MustCompile("^[0-9a-z]{32}$")
+
+func NewOAuthClientSecret() string {
+	return id.Must(id.New(32))
+}
+
+type OAuthClient struct {
+	ID           string    `json:"id" bson:"id"`
+	Secret       *string   `json:"secret,omitempty" bson:"-"`
+	SecretHash   *string   `json:"-" bson:"secretHash,omitempty"`
+	Name         string    `json:"name" bson:"name"`
+	RedirectURIs []string  `json:"redirectUris" bson:"redirectUris"`
+	Scopes       []string  `json:"scopes" bson:"scopes"`
+	Confidential bool      `json:"confidential" bson:"confidential"`
+	CreatedTime  time.Time `json:"createdTime" bson:"createdTime"`
+}
+
+// The secret is only available on the newly created client; only its hash is persisted
+func NewOAuthClient(create *OAuthClientCreate) (*OAuthClient, error) {
+	if create == nil {
+		return nil, errors.New("create is missing")
+	} else if err := structureValidator.New().Validate(create); err != nil {
+		return nil, errors.Wrap(err, "create is invalid")
+	}
+
+	oauthClient := &OAuthClient{
+		ID:           NewOAuthClientID(),
+		Name:         create.Name,
+		RedirectURIs: create.RedirectURIs,
+		Scopes:       create.Scopes,
+		Confidential: create.Confidential,
+		CreatedTime:  time.Now().Truncate(time.Second),
+	}
+	if create.Confidential {
+		secret := NewOAuthClientSecret()
+		secretHash := crypto.HexEncodedSHA256Hash(secret)
+		oauthClient.Secret = &secret
+		oauthClient.SecretHash = &secretHash
+	}
+
+	return oauthClient, nil
+}
+
+func (o *OAuthClient) Parse(parser structure.ObjectParser) {
+	if ptr := parser.String("id"); ptr != nil {
+		o.ID = *ptr
+	}
+	o.Secret = parser.String("secret")
+	if ptr := parser.String("name"); ptr != nil {
+		o.Name = *ptr
+	}
+	if ptr := parser.StringArray("redirectUris"); ptr != nil {
+		o.RedirectURIs = *ptr
+	}
+	if ptr := parser.StringArray("scopes"); ptr != nil {
+		o.Scopes = *ptr
+	}
+	if ptr := parser.Bool("confidential"); ptr != nil {
+		o.Confidential = *ptr
+	}
+	if ptr := parser.Time("createdTime", time.RFC3339); ptr != nil {
+		o.CreatedTime = *ptr
+	}
+}
+
+func (o *OAuthClient) Validate(validator structure.Validator) {
+	validator.String("id", &o.ID).Using(OAuthClientIDValidator)
+	validator.String("name", &o.Name).NotEmpty().LengthLessThanOrEqualTo(100)
+	validator.StringArray("redirectUris", &o.RedirectURIs).LengthInRange(1, 10).Each(func(stringValidator structure.String) {
+		stringValidator.Using(net.URLValidator)
+	}).EachUnique()
+	validator.StringArray("scopes", &o.Scopes).NotEmpty().EachOneOf(OAuthScopes()...).EachUnique()
+	validator.Time("createdTime", &o.CreatedTime).NotZero().BeforeNow(time.Second)
+}
+
+func (o *OAuthClient) AuthenticatesSecret(secret string) bool {
+	if !o.Confidential {
+		return secret == ""
+	}
+	if o.SecretHash == nil || secret == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(crypto.HexEncodedSHA256Hash(secret)), []byte(*o.SecretHash)) == 1
+}
+
+// Redirect URIs must match exactly, per RFC 6749, Section 3.1.2.3
+func (o *OAuthClient) HasRedirectURI(redirectURI string) bool {
+	return containsString(o.RedirectURIs, redirectURI)
+}
+
+func (o *OAuthClient) AllowsScopes(scopes []string) bool {
+	for _, scope := range scopes {
+		if !containsString(o.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+func (o *OAuthClient) Sanitize(details request.Details) error {
+	if details != nil && details.IsService() {
+		return nil
+	}
+	return errors.New("unable to sanitize")
+}
@@ -0,0 +1,134 @@
+package auth_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/crypto"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/request"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
+var _ = Describe("OAuthClient", func() {
+	newOAuthClientCreate := func() *auth.OAuthClientCreate {
+		create := auth.NewOAuthClientCreate()
+		create.Name = "Example"
+		create.RedirectURIs = []string{"https://example.com/callback"}
+		create.Scopes = []string{auth.OAuthScopeDataRead}
+		return create
+	}
+
+	Context("OAuthClientCreate", func() {
+		DescribeTable("validates the oauth client create",
+			func(mutator func(create *auth.OAuthClientCreate), expectedErrors ...error) {
+				create := newOAuthClientCreate()
+				mutator(create)
+				errorsTest.ExpectEqual(structureValidator.New().Validate(create), expectedErrors...)
+			},
+			Entry("succeeds",
+				func(create *auth.OAuthClientCreate) {},
+			),
+			Entry("name empty",
+				func(create *auth.OAuthClientCreate) { create.Name = "" },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueEmpty(), "/name"),
+			),
+			Entry("redirect uris empty",
+				func(create *auth.OAuthClientCreate) { create.RedirectURIs = []string{} },
+				errorsTest.WithPointerSource(structureValidator.ErrorLengthNotInRange(0, 1, 10), "/redirectUris"),
+			),
+			Entry("redirect uris duplicate",
+				func(create *auth.OAuthClientCreate) {
+					create.RedirectURIs = []string{"https://example.com/callback", "https://example.com/callback"}
+				},
+				errorsTest.WithPointerSource(structureValidator.ErrorValueDuplicate(), "/redirectUris/1"),
+			),
+			Entry("scopes empty",
+				func(create *auth.OAuthClientCreate) { create.Scopes = []string{} },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueEmpty(), "/scopes"),
+			),
+			Entry("scopes invalid",
+				func(create *auth.OAuthClientCreate) { create.Scopes = []string{"invalid"} },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("invalid", auth.OAuthScopes()), "/scopes/0"),
+			),
+		)
+	})
+
+	Context("OAuthClientID", func() {
+		It("NewOAuthClientID returns a valid id", func() {
+			Expect(auth.IsValidOAuthClientID(auth.NewOAuthClientID())).To(BeTrue())
+		})
+
+		It("ValidateOAuthClientID returns an error if empty", func() {
+			errorsTest.ExpectEqual(auth.ValidateOAuthClientID(""), structureValidator.ErrorValueEmpty())
+		})
+
+		It("ValidateOAuthClientID returns an error if invalid", func() {
+			errorsTest.ExpectEqual(auth.ValidateOAuthClientID("invalid"), auth.ErrorValueStringAsOAuthClientIDNotValid("invalid"))
+		})
+	})
+
+	Context("NewOAuthClient", func() {
+		It("returns an error if create is missing", func() {
+			oauthClient, err := auth.NewOAuthClient(nil)
+			Expect(err).To(MatchError("create is missing"))
+			Expect(oauthClient).To(BeNil())
+		})
+
+		It("returns a public client without a secret", func() {
+			oauthClient, err := auth.NewOAuthClient(newOAuthClientCreate())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(oauthClient).ToNot(BeNil())
+			Expect(auth.IsValidOAuthClientID(oauthClient.ID)).To(BeTrue())
+			Expect(oauthClient.Secret).To(BeNil())
+			Expect(oauthClient.SecretHash).To(BeNil())
+			Expect(oauthClient.AuthenticatesSecret("")).To(BeTrue())
+			Expect(oauthClient.AuthenticatesSecret("secret")).To(BeFalse())
+		})
+
+		It("returns a confidential client with a hashed secret", func() {
+			create := newOAuthClientCreate()
+			create.Confidential = true
+			oauthClient, err := auth.NewOAuthClient(create)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(oauthClient).ToNot(BeNil())
+			Expect(oauthClient.Secret).ToNot(BeNil())
+			Expect(oauthClient.SecretHash).To(Equal(pointer.FromString(crypto.HexEncodedSHA256Hash(*oauthClient.Secret))))
+			Expect(oauthClient.AuthenticatesSecret(*oauthClient.Secret)).To(BeTrue())
+			Expect(oauthClient.AuthenticatesSecret("")).To(BeFalse())
+			Expect(oauthClient.AuthenticatesSecret(auth.NewOAuthClientSecret())).To(BeFalse())
+		})
+	})
+
+	Context("with oauth client", func() {
+		var oauthClient *auth.OAuthClient
+
+		BeforeEach(func() {
+			var err error
+			oauthClient, err = auth.NewOAuthClient(newOAuthClientCreate())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("HasRedirectURI requires an exact match", func() {
+			Expect(oauthClient.HasRedirectURI("https://example.com/callback")).To(BeTrue())
+			Expect(oauthClient.HasRedirectURI("https://example.com/callback/")).To(BeFalse())
+			Expect(oauthClient.HasRedirectURI("https://example.com/callback?x=1")).To(BeFalse())
+		})
+
+		It("AllowsScopes returns true only if all scopes are registered", func() {
+			Expect(oauthClient.AllowsScopes([]string{auth.OAuthScopeDataRead})).To(BeTrue())
+			Expect(oauthClient.AllowsScopes([]string{auth.OAuthScopeDataRead, auth.OAuthScopeDataWrite})).To(BeFalse())
+		})
+
+		It("Sanitize returns an error if not service", func() {
+			Expect(oauthClient.Sanitize(request.NewDetails(request.MethodSessionToken, "1234567890", "token"))).To(HaveOccurred())
+		})
+
+		It("Sanitize succeeds if service", func() {
+			Expect(oauthClient.Sanitize(request.NewDetails(request.MethodServiceSecret, "", ""))).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,457 @@
+package auth
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tidepool-org/platform/crypto"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/id"
+	"github.com/tidepool-org/platform/oauth"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/structure"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+	"github.com/tidepool-org/platform/user"
+)
+
+const (
+	OAuthAuthorizationCodeExpirationDuration = 10 * time.Minute
+	OAuthAccessTokenExpirationDuration       = time.Hour
+	OAuthRefreshTokenExpirationDuration      = 30 * 24 * time.Hour
+
+	OAuthScopeBlobRead  = "blob:read"
+	OAuthScopeBlobWrite = "blob:write"
+	OAuthScopeDataRead  = "data:read"
+	OAuthScopeDataWrite = "data:write"
+	OAuthScopeTaskRead  = "task:read"
+	OAuthScopeTaskWrite = "task:write"
+)
+
+func OAuthScopes() []string {
+	return []string{
+		OAuthScopeBlobRead,
+		OAuthScopeBlobWrite,
+		OAuthScopeDataRead,
+		OAuthScopeDataWrite,
+		OAuthScopeTaskRead,
+		OAuthScopeTaskWrite,
+	}
+}
+
+// Path prefixes for each scope resource; "*" matches any single path segment
+var oauthScopeResourcePaths = map[string][]string{
+	"blob": {
+		"/v1/users/*/blobs",
+		"/v1/blobs",
+	},
+	"data": {
+		"/v1/users/*/data",
+		"/v1/users/*/datasets",
+		"/v1/users/*/data_sets",
+		"/v1/users/*/data_sources",
+		"/v1/datasets",
+		"/v1/data_sets",
+		"/v1/data_sources",
+	},
+	"task": {
+		"/v1/tasks",
+	},
+}
+
+func OAuthScopeAuthenticates(scope string, req *http.Request) bool {
+	if req == nil || req.URL == nil {
+		return false
+	}
+
+	parts := strings.SplitN(scope, ":", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	switch parts[1] {
+	case "read":
+		if !containsString(ReadOnlyMethods(), method) {
+			return false
+		}
+	case "write":
+		if containsString(ReadOnlyMethods(), method) {
+			return false
+		}
+	default:
+		return false
+	}
+
+	escapedPath := req.URL.EscapedPath()
+	for _, path := range oauthScopeResourcePaths[parts[0]] {
+		if matchesPathPrefix(path, escapedPath) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesPathPrefix(prefix string, path string) bool {
+	prefixSegments := strings.Split(prefix, "/")
+	pathSegments := strings.Split(path, "/")
+	if len(pathSegments) < len(prefixSegments) {
+		return false
+	}
+	for index, prefixSegment := range prefixSegments {
+		if prefixSegment != "*" && prefixSegment != pathSegments[index] {
+			return false
+		}
+	}
+	return true
+}
+
+type OAuthAuthorize struct {
+	ResponseType        string  `json:"response_type"`
+	ClientID            string  `json:"client_id"`
+	RedirectURI         string  `json:"redirect_uri"`
+	Scope               string  `json:"scope"`
+	State               *string `json:"state,omitempty"`
+	CodeChallenge       string  `json:"code_challenge"`
+	CodeChallengeMethod string  `json:"code_challenge_method"`
+}
+
+func NewOAuthAuthorize() *OAuthAuthorize {
+	return &OAuthAuthorize{}
+}
+
+func (o *OAuthAuthorize) Parse(parser structure.ObjectParser) {
+	if ptr := parser.String("response_type"); ptr != nil {
+		o.ResponseType = *ptr
+	}
+	if ptr := parser.String("client_id"); ptr != nil {
+		o.ClientID = *ptr
+	}
+	if ptr := parser.String("redirect_uri"); ptr != nil {
+		o.RedirectURI = *ptr
+	}
+	if ptr := parser.String("scope"); ptr != nil {
+		o.Scope = *ptr
+	}
+	o.State = parser.String("state")
+	if ptr := parser.String("code_challenge"); ptr != nil {
+		o.CodeChallenge = *ptr
+	}
+	if ptr := parser.String("code_challenge_method"); ptr != nil {
+		o.CodeChallengeMethod = *ptr
+	}
+}
+
+func (o *OAuthAuthorize) Validate(validator structure.Validator) {
+	validator.String("response_type", &o.ResponseType).EqualTo(oauth.ResponseTypeCode)
+	validator.String("client_id", &o.ClientID).Using(OAuthClientIDValidator)
+	validator.String("redirect_uri", &o.RedirectURI).NotEmpty()
+	validator.String("scope", &o.Scope).NotEmpty()
+	validator.String("state", o.State).NotEmpty().LengthLessThanOrEqualTo(1024)
+	validator.String("code_challenge", &o.CodeChallenge).Matches(oauth.CodeChallengeExpression)
+	validator.String("code_challenge_method", &o.CodeChallengeMethod).OneOf(oauth.CodeChallengeMethods()...)
+}
+
+func (o *OAuthAuthorize) Scopes() []string {
+	return oauth.ParseScope(o.Scope)
+}
+
+func NewOAuthAuthorizationCodeCode() string {
+	return id.Must(id.New(32))
+}
+
+type OAuthAuthorizationCode struct {
+	Code                string    `json:"-" bson:"-"`
+	CodeHash            string    `json:"-" bson:"codeHash"`
+	ClientID            string    `json:"clientId" bson:"clientId"`
+	UserID              string    `json:"userId" bson:"userId"`
+	RedirectURI         string    `json:"redirectUri" bson:"redirectUri"`
+	Scopes              []string  `json:"scopes" bson:"scopes"`
+	CodeChallenge       string    `json:"codeChallenge" bson:"codeChallenge"`
+	CodeChallengeMethod string    `json:"codeChallengeMethod" bson:"codeChallengeMethod"`
+	ExpirationTime      time.Time `json:"expirationTime" bson:"expirationTime"`
+	CreatedTime         time.Time `json:"createdTime" bson:"createdTime"`
+}
+
+// The code is only available on the newly created authorization code; only its hash is persisted
+func NewOAuthAuthorizationCode(userID string, authorize *OAuthAuthorize) (*OAuthAuthorizationCode, error) {
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+	if authorize == nil {
+		return nil, errors.New("authorize is missing")
+	} else if err := structureValidator.New().Validate(authorize); err != nil {
+		return nil, errors.Wrap(err, "authorize is invalid")
+	}
+
+	now := time.Now().Truncate(time.Second)
+	code := NewOAuthAuthorizationCodeCode()
+	return &OAuthAuthorizationCode{
+		Code:                code,
+		CodeHash:            crypto.HexEncodedSHA256Hash(code),
+		ClientID:            authorize.ClientID,
+		UserID:              userID,
+		RedirectURI:         authorize.RedirectURI,
+		Scopes:              authorize.Scopes(),
+		CodeChallenge:       authorize.CodeChallenge,
+		CodeChallengeMethod: authorize.CodeChallengeMethod,
+		ExpirationTime:      now.Add(OAuthAuthorizationCodeExpirationDuration),
+		CreatedTime:         now,
+	}, nil
+}
+
+func (o *OAuthAuthorizationCode) IsExpired() bool {
+	return time.Now().After(o.ExpirationTime)
+}
+
+func (o *OAuthAuthorizationCode) VerifyCodeVerifier(codeVerifier string) bool {
+	return oauth.VerifyCodeChallenge(o.CodeChallenge, o.CodeChallengeMethod, codeVerifier)
+}
+
+type OAuthTokenRequest struct {
+	GrantType    string  `json:"grant_type"`
+	Code         *string `json:"code,omitempty"`
+	RedirectURI  *string `json:"redirect_uri,omitempty"`
+	CodeVerifier *string `json:"code_verifier,omitempty"`
+	RefreshToken *string `json:"refresh_token,omitempty"`
+	Scope        *string `json:"scope,omitempty"`
+	ClientID     *string `json:"client_id,omitempty"`
+	ClientSecret *string `json:"client_secret,omitempty"`
+}
+
+func IsValidOAuthGrantType(value string) bool {
+	return containsString(oauth.GrantTypes(), value)
+}
+
+func NewOAuthTokenRequest() *OAuthTokenRequest {
+	return &OAuthTokenRequest{}
+}
+
+func (o *OAuthTokenRequest) Parse(parser structure.ObjectParser) {
+	if ptr := parser.String("grant_type"); ptr != nil {
+		o.GrantType = *ptr
+	}
+	o.Code = parser.String("code")
+	o.RedirectURI = parser.String("redirect_uri")
+	o.CodeVerifier = parser.String("code_verifier")
+	o.RefreshToken = parser.String("refresh_token")
+	o.Scope = parser.String("scope")
+	o.ClientID = parser.String("client_id")
+	o.ClientSecret = parser.String("client_secret")
+}
+
+func (o *OAuthTokenRequest) Validate(validator structure.Validator) {
+	validator.String("grant_type", &o.GrantType).OneOf(oauth.GrantTypes()...)
+	switch o.GrantType {
+	case oauth.GrantTypeAuthorizationCode:
+		validator.String("code", o.Code).Exists().NotEmpty()
+		validator.String("redirect_uri", o.RedirectURI).Exists().NotEmpty()
+		validator.String("code_verifier", o.CodeVerifier).Exists().Matches(oauth.CodeVerifierExpression)
+		validator.String("refresh_token", o.RefreshToken).NotExists()
+		validator.String("scope", o.Scope).NotExists()
+	case oauth.GrantTypeRefreshToken:
+		validator.String("code", o.Code).NotExists()
+		validator.String("redirect_uri", o.RedirectURI).NotExists()
+		validator.String("code_verifier", o.CodeVerifier).NotExists()
+		validator.String("refresh_token", o.RefreshToken).Exists().NotEmpty()
+		validator.String("scope", o.Scope).NotEmpty()
+	}
+	validator.String("client_id", o.ClientID).Using(OAuthClientIDValidator)
+	validator.String("client_secret", o.ClientSecret).NotEmpty()
+}
+
+func NewOAuthAccessToken() string {
+	return id.Must(id.New(32))
+}
+
+func NewOAuthRefreshToken() string {
+	return id.Must(id.New(32))
+}
+
+func IsValidOAuthAccessToken(value string) bool {
+	return ValidateOAuthAccessToken(value) == nil
+}
+
+func OAuthAccessTokenValidator(value string, errorReporter structure.ErrorReporter) {
+	errorReporter.ReportError(ValidateOAuthAccessToken(value))
+}
+
+func ValidateOAuthAccessToken(value string) error {
+	if value == "" {
+		return structureValidator.ErrorValueEmpty()
+	} else if !oauthAccessTokenExpression.MatchString(value) {
+		return ErrorValueStringAsOAuthAccessTokenNotValid(value)
+	}
+	return nil
+}
+
+func ErrorValueStringAsOAuthAccessTokenNotValid(value string) error {
+	return errors.Preparedf(structureValidator.ErrorCodeValueNotValid, "value is not valid", "value %q is not valid as oauth access token", value)
+}
+
+var oauthAccessTokenExpression = regexp.MustCompile("^[0-9a-z]{64}$")
+
+type OAuthAccessTokenValidate struct {
+	AccessToken string `json:"accessToken"`
+}
+
+func NewOAuthAccessTokenValidate() *OAuthAccessTokenValidate {
+	return &OAuthAccessTokenValidate{}
+}
+
+func (o *OAuthAccessTokenValidate) Parse(parser structure.ObjectParser) {
+	if ptr := parser.String("accessToken"); ptr != nil {
+		o.AccessToken = *ptr
+	}
+}
+
+func (o *OAuthAccessTokenValidate) Validate(validator structure.Validator) {
+	validator.String("accessToken", &o.AccessToken).Using(OAuthAccessTokenValidator)
+}
+
+type OAuthToken struct {
+	AccessToken           string    `json:"-" bson:"-"`
+	AccessTokenHash       string    `json:"-" bson:"accessTokenHash"`
+	RefreshToken          string    `json:"-" bson:"-"`
+	RefreshTokenHash      string    `json:"-" bson:"refreshTokenHash"`
+	ClientID              string    `json:"clientId" bson:"clientId"`
+	UserID                string    `json:"userId" bson:"userId"`
+	Scopes                []string  `json:"scopes" bson:"scopes"`
+	ExpirationTime        time.Time `json:"expirationTime" bson:"expirationTime"`
+	RefreshExpirationTime time.Time `json:"refreshExpirationTime" bson:"refreshExpirationTime"`
+	CreatedTime           time.Time `json:"createdTime" bson:"createdTime"`
+}
+
+// The access and refresh tokens are only available on the newly created token; only their hashes are persisted
+func NewOAuthToken(clientID string, userID string, scopes []string) (*OAuthToken, error) {
+	if clientID == "" {
+		return nil, errors.New("client id is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+	if len(scopes) == 0 {
+		return nil, errors.New("scopes is missing")
+	}
+
+	now := time.Now().Truncate(time.Second)
+	accessToken := NewOAuthAccessToken()
+	refreshToken := NewOAuthRefreshToken()
+	return &OAuthToken{
+		AccessToken:           accessToken,
+		AccessTokenHash:       crypto.HexEncodedSHA256Hash(accessToken),
+		RefreshToken:          refreshToken,
+		RefreshTokenHash:      crypto.HexEncodedSHA256Hash(refreshToken),
+		ClientID:              clientID,
+		UserID:                userID,
+		Scopes:                scopes,
+		ExpirationTime:        now.Add(OAuthAccessTokenExpirationDuration),
+		RefreshExpirationTime: now.Add(OAuthRefreshTokenExpirationDuration),
+		CreatedTime:           now,
+	}, nil
+}
+
+func (o *OAuthToken) Parse(parser structure.ObjectParser) {
+	if ptr := parser.String("clientId"); ptr != nil {
+		o.ClientID = *ptr
+	}
+	if ptr := parser.String("userId"); ptr != nil {
+		o.UserID = *ptr
+	}
+	if ptr := parser.StringArray("scopes"); ptr != nil {
+		o.Scopes = *ptr
+	}
+	if ptr := parser.Time("expirationTime", time.RFC3339); ptr != nil {
+		o.ExpirationTime = *ptr
+	}
+	if ptr := parser.Time("refreshExpirationTime", time.RFC3339); ptr != nil {
+		o.RefreshExpirationTime = *ptr
+	}
+	if ptr := parser.Time("createdTime", time.RFC3339); ptr != nil {
+		o.CreatedTime = *ptr
+	}
+}
+
+func (o *OAuthToken) Validate(validator structure.Validator) {
+	validator.String("clientId", &o.ClientID).Using(OAuthClientIDValidator)
+	validator.String("userId", &o.UserID).Using(user.IDValidator)
+	validator.StringArray("scopes", &o.Scopes).NotEmpty().EachOneOf(OAuthScopes()...).EachUnique()
+	validator.Time("expirationTime", &o.ExpirationTime).After(o.CreatedTime)
+	validator.Time("refreshExpirationTime", &o.RefreshExpirationTime).After(o.ExpirationTime)
+	validator.Time("createdTime", &o.CreatedTime).NotZero().BeforeNow(time.Second)
+}
+
+func (o *OAuthToken) IsExpired() bool {
+	return time.Now().After(o.ExpirationTime)
+}
+
+func (o *OAuthToken) IsRefreshExpired() bool {
+	return time.Now().After(o.RefreshExpirationTime)
+}
+
+func (o *OAuthToken) HasScopes(scopes []string) bool {
+	for _, scope := range scopes {
+		if !containsString(o.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+func (o *OAuthToken) Authenticates(req *http.Request) bool {
+	if req == nil || req.URL == nil {
+		return false
+	}
+	if o.IsExpired() {
+		return false
+	}
+	for _, scope := range o.Scopes {
+		if OAuthScopeAuthenticates(scope, req) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *OAuthToken) Sanitize(details request.Details) error {
+	if details != nil && details.IsService() {
+		return nil
+	}
+	return errors.New("unable to sanitize")
+}
+
+// RFC 6749, Section 5.1
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+func NewOAuthTokenResponse(oauthToken *OAuthToken) *OAuthTokenResponse {
+	return &OAuthTokenResponse{
+		AccessToken:  oauthToken.AccessToken,
+		TokenType:    oauth.TokenTypeBearer,
+		ExpiresIn:    int64(time.Until(oauthToken.ExpirationTime) / time.Second),
+		RefreshToken: oauthToken.RefreshToken,
+		Scope:        oauth.FormatScope(oauthToken.Scopes),
+	}
+}
+
+// RFC 6749, Section 5.2
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func NewOAuthErrorResponse(code string, description string) *OAuthErrorResponse {
+	return &OAuthErrorResponse{
+		Error:            code,
+		ErrorDescription: description,
+	}
+}
@@ -0,0 +1,227 @@
+package auth_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"net/http/httptest"
+	"time"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/crypto"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/oauth"
+	"github.com/tidepool-org/platform/pointer"
+	serviceTest "github.com/tidepool-org/platform/service/test"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
+var _ = Describe("OAuthToken", func() {
+	codeVerifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	newOAuthAuthorize := func() *auth.OAuthAuthorize {
+		authorize := auth.NewOAuthAuthorize()
+		authorize.ResponseType = oauth.ResponseTypeCode
+		authorize.ClientID = auth.NewOAuthClientID()
+		authorize.RedirectURI = "https://example.com/callback"
+		authorize.Scope = "data:read blob:read"
+		authorize.State = pointer.FromString("state")
+		authorize.CodeChallenge = oauth.CalculateCodeChallengeS256(codeVerifier)
+		authorize.CodeChallengeMethod = oauth.CodeChallengeMethodS256
+		return authorize
+	}
+
+	It("OAuthScopes returns expected", func() {
+		Expect(auth.OAuthScopes()).To(Equal([]string{"blob:read", "blob:write", "data:read", "data:write", "task:read", "task:write"}))
+	})
+
+	DescribeTable("OAuthScopeAuthenticates",
+		func(scope string, method string, path string, expected bool) {
+			Expect(auth.OAuthScopeAuthenticates(scope, httptest.NewRequest(method, path, nil))).To(Equal(expected))
+		},
+		Entry("data read with get on user data", auth.OAuthScopeDataRead, "GET", "/v1/users/1234567890/data", true),
+		Entry("data read with get on user data sets", auth.OAuthScopeDataRead, "GET", "/v1/users/1234567890/data_sets", true),
+		Entry("data read with post on user data", auth.OAuthScopeDataRead, "POST", "/v1/users/1234567890/data", false),
+		Entry("data write with post on data sets", auth.OAuthScopeDataWrite, "POST", "/v1/data_sets/abc/data", true),
+		Entry("data write with get on user data", auth.OAuthScopeDataWrite, "GET", "/v1/users/1234567890/data", false),
+		Entry("data read with get on user blobs", auth.OAuthScopeDataRead, "GET", "/v1/users/1234567890/blobs", false),
+		Entry("blob read with get on blob", auth.OAuthScopeBlobRead, "GET", "/v1/blobs/abc/content", true),
+		Entry("task write with delete on task", auth.OAuthScopeTaskWrite, "DELETE", "/v1/tasks/abc", true),
+		Entry("data read with partial segment", auth.OAuthScopeDataRead, "GET", "/v1/users/1234567890/dataextra", false),
+		Entry("invalid scope", "invalid", "GET", "/v1/users/1234567890/data", false),
+		Entry("invalid access", "data:admin", "GET", "/v1/users/1234567890/data", false),
+	)
+
+	Context("OAuthAuthorize", func() {
+		DescribeTable("validates the oauth authorize",
+			func(mutator func(authorize *auth.OAuthAuthorize), expectedErrors ...error) {
+				authorize := newOAuthAuthorize()
+				mutator(authorize)
+				errorsTest.ExpectEqual(structureValidator.New().Validate(authorize), expectedErrors...)
+			},
+			Entry("succeeds",
+				func(authorize *auth.OAuthAuthorize) {},
+			),
+			Entry("response type invalid",
+				func(authorize *auth.OAuthAuthorize) { authorize.ResponseType = "token" },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueNotEqualTo("token", oauth.ResponseTypeCode), "/response_type"),
+			),
+			Entry("client id invalid",
+				func(authorize *auth.OAuthAuthorize) { authorize.ClientID = "invalid" },
+				errorsTest.WithPointerSource(auth.ErrorValueStringAsOAuthClientIDNotValid("invalid"), "/client_id"),
+			),
+			Entry("code challenge invalid",
+				func(authorize *auth.OAuthAuthorize) { authorize.CodeChallenge = "invalid" },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotMatches("invalid", oauth.CodeChallengeExpression), "/code_challenge"),
+			),
+			Entry("code challenge method invalid",
+				func(authorize *auth.OAuthAuthorize) { authorize.CodeChallengeMethod = "plain" },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("plain", oauth.CodeChallengeMethods()), "/code_challenge_method"),
+			),
+		)
+
+		It("Scopes returns the parsed scopes", func() {
+			Expect(newOAuthAuthorize().Scopes()).To(Equal([]string{auth.OAuthScopeDataRead, auth.OAuthScopeBlobRead}))
+		})
+	})
+
+	Context("NewOAuthAuthorizationCode", func() {
+		It("returns an error if user id is missing", func() {
+			oauthAuthorizationCode, err := auth.NewOAuthAuthorizationCode("", newOAuthAuthorize())
+			Expect(err).To(MatchError("user id is missing"))
+			Expect(oauthAuthorizationCode).To(BeNil())
+		})
+
+		It("returns an error if authorize is missing", func() {
+			oauthAuthorizationCode, err := auth.NewOAuthAuthorizationCode(serviceTest.NewUserID(), nil)
+			Expect(err).To(MatchError("authorize is missing"))
+			Expect(oauthAuthorizationCode).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			userID := serviceTest.NewUserID()
+			authorize := newOAuthAuthorize()
+			oauthAuthorizationCode, err := auth.NewOAuthAuthorizationCode(userID, authorize)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(oauthAuthorizationCode).ToNot(BeNil())
+			Expect(oauthAuthorizationCode.CodeHash).To(Equal(crypto.HexEncodedSHA256Hash(oauthAuthorizationCode.Code)))
+			Expect(oauthAuthorizationCode.ClientID).To(Equal(authorize.ClientID))
+			Expect(oauthAuthorizationCode.UserID).To(Equal(userID))
+			Expect(oauthAuthorizationCode.Scopes).To(Equal(authorize.Scopes()))
+			Expect(oauthAuthorizationCode.IsExpired()).To(BeFalse())
+			Expect(oauthAuthorizationCode.VerifyCodeVerifier(codeVerifier)).To(BeTrue())
+			Expect(oauthAuthorizationCode.VerifyCodeVerifier(codeVerifier + "x")).To(BeFalse())
+		})
+	})
+
+	It("IsValidOAuthGrantType returns true for supported grant types", func() {
+		Expect(auth.IsValidOAuthGrantType(oauth.GrantTypeAuthorizationCode)).To(BeTrue())
+		Expect(auth.IsValidOAuthGrantType(oauth.GrantTypeRefreshToken)).To(BeTrue())
+		Expect(auth.IsValidOAuthGrantType("client_credentials")).To(BeFalse())
+	})
+
+	Context("OAuthTokenRequest", func() {
+		DescribeTable("validates the oauth token request",
+			func(mutator func(tokenRequest *auth.OAuthTokenRequest), expectedErrors ...error) {
+				tokenRequest := auth.NewOAuthTokenRequest()
+				tokenRequest.GrantType = oauth.GrantTypeAuthorizationCode
+				tokenRequest.Code = pointer.FromString("code")
+				tokenRequest.RedirectURI = pointer.FromString("https://example.com/callback")
+				tokenRequest.CodeVerifier = pointer.FromString(codeVerifier)
+				mutator(tokenRequest)
+				errorsTest.ExpectEqual(structureValidator.New().Validate(tokenRequest), expectedErrors...)
+			},
+			Entry("succeeds",
+				func(tokenRequest *auth.OAuthTokenRequest) {},
+			),
+			Entry("authorization code without code verifier",
+				func(tokenRequest *auth.OAuthTokenRequest) { tokenRequest.CodeVerifier = nil },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueNotExists(), "/code_verifier"),
+			),
+			Entry("authorization code with refresh token",
+				func(tokenRequest *auth.OAuthTokenRequest) { tokenRequest.RefreshToken = pointer.FromString("refresh") },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueExists(), "/refresh_token"),
+			),
+			Entry("refresh token succeeds",
+				func(tokenRequest *auth.OAuthTokenRequest) {
+					tokenRequest.GrantType = oauth.GrantTypeRefreshToken
+					tokenRequest.Code = nil
+					tokenRequest.RedirectURI = nil
+					tokenRequest.CodeVerifier = nil
+					tokenRequest.RefreshToken = pointer.FromString("refresh")
+				},
+			),
+			Entry("refresh token without refresh token",
+				func(tokenRequest *auth.OAuthTokenRequest) {
+					tokenRequest.GrantType = oauth.GrantTypeRefreshToken
+					tokenRequest.Code = nil
+					tokenRequest.RedirectURI = nil
+					tokenRequest.CodeVerifier = nil
+				},
+				errorsTest.WithPointerSource(structureValidator.ErrorValueNotExists(), "/refresh_token"),
+			),
+		)
+	})
+
+	Context("NewOAuthToken", func() {
+		It("returns an error if scopes is missing", func() {
+			oauthToken, err := auth.NewOAuthToken(auth.NewOAuthClientID(), serviceTest.NewUserID(), nil)
+			Expect(err).To(MatchError("scopes is missing"))
+			Expect(oauthToken).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			oauthToken, err := auth.NewOAuthToken(auth.NewOAuthClientID(), serviceTest.NewUserID(), []string{auth.OAuthScopeDataRead})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(oauthToken).ToNot(BeNil())
+			Expect(auth.IsValidOAuthAccessToken(oauthToken.AccessToken)).To(BeTrue())
+			Expect(oauthToken.AccessTokenHash).To(Equal(crypto.HexEncodedSHA256Hash(oauthToken.AccessToken)))
+			Expect(oauthToken.RefreshTokenHash).To(Equal(crypto.HexEncodedSHA256Hash(oauthToken.RefreshToken)))
+			Expect(oauthToken.AccessToken).ToNot(Equal(oauthToken.RefreshToken))
+			Expect(oauthToken.IsExpired()).To(BeFalse())
+			Expect(oauthToken.IsRefreshExpired()).To(BeFalse())
+			Expect(structureValidator.New().Validate(oauthToken)).To(Succeed())
+		})
+	})
+
+	Context("with oauth token", func() {
+		var oauthToken *auth.OAuthToken
+
+		BeforeEach(func() {
+			var err error
+			oauthToken, err = auth.NewOAuthToken(auth.NewOAuthClientID(), serviceTest.NewUserID(), []string{auth.OAuthScopeDataRead})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Authenticates returns true if a scope authenticates the request", func() {
+			Expect(oauthToken.Authenticates(httptest.NewRequest("GET", "/v1/users/1234567890/data", nil))).To(BeTrue())
+		})
+
+		It("Authenticates returns false if no scope authenticates the request", func() {
+			Expect(oauthToken.Authenticates(httptest.NewRequest("POST", "/v1/users/1234567890/data", nil))).To(BeFalse())
+		})
+
+		It("Authenticates returns false if expired", func() {
+			oauthToken.ExpirationTime = time.Now().Add(-time.Second)
+			Expect(oauthToken.Authenticates(httptest.NewRequest("GET", "/v1/users/1234567890/data", nil))).To(BeFalse())
+		})
+
+		It("HasScopes returns true if all scopes are granted", func() {
+			Expect(oauthToken.HasScopes([]string{auth.OAuthScopeDataRead})).To(BeTrue())
+		})
+
+		It("HasScopes returns false if any scope is not granted", func() {
+			Expect(oauthToken.HasScopes([]string{auth.OAuthScopeDataRead, auth.OAuthScopeDataWrite})).To(BeFalse())
+		})
+
+		It("NewOAuthTokenResponse returns expected", func() {
+			response := auth.NewOAuthTokenResponse(oauthToken)
+			Expect(response.AccessToken).To(Equal(oauthToken.AccessToken))
+			Expect(response.RefreshToken).To(Equal(oauthToken.RefreshToken))
+			Expect(response.TokenType).To(Equal(oauth.TokenTypeBearer))
+			Expect(response.ExpiresIn).To(BeNumerically("~", int64(auth.OAuthAccessTokenExpirationDuration/time.Second), 2))
+			Expect(response.Scope).To(Equal(auth.OAuthScopeDataRead))
+		})
+	})
+})
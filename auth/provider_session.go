@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/tidepool-org/platform/errors"
@@ -18,6 +19,9 @@ import (
 
 const (
 	ProviderTypeOAuth = "oauth"
+
+	ErrorCodeProviderSessionRefreshTokenRevoked = "provider-session-refresh-token-revoked"
+	ErrorCodeProviderSessionRefreshFailed       = "provider-session-refresh-failed"
 )
 
 func ProviderTypes() []string {
@@ -27,16 +31,26 @@ func ProviderTypes() []string {
 }
 
 type ProviderSessionAccessor interface {
+	ListProviderSessions(ctx context.Context, filter *ProviderSessionFilter, pagination *page.Pagination) (ProviderSessions, error)
 	ListUserProviderSessions(ctx context.Context, userID string, filter *ProviderSessionFilter, pagination *page.Pagination) (ProviderSessions, error)
 	CreateUserProviderSession(ctx context.Context, userID string, create *ProviderSessionCreate) (*ProviderSession, error)
 	GetProviderSession(ctx context.Context, id string) (*ProviderSession, error)
 	UpdateProviderSession(ctx context.Context, id string, update *ProviderSessionUpdate) (*ProviderSession, error)
 	DeleteProviderSession(ctx context.Context, id string) error
+
+	// LockProviderSession returns the provider session if the lock is acquired, or nil if the provider session does not
+	// exist or is locked by another lock that has not yet expired
+	LockProviderSession(ctx context.Context, id string, lock *ProviderSessionLock) (*ProviderSession, error)
+	UnlockProviderSession(ctx context.Context, id string, lockID string) error
 }
 
 type ProviderSessionFilter struct {
-	Type *string `json:"type,omitempty" bson:"type,omitempty"`
-	Name *string `json:"name,omitempty" bson:"name,omitempty"`
+	Type                     *string    `json:"type,omitempty" bson:"type,omitempty"`
+	Name                     *string    `json:"name,omitempty" bson:"name,omitempty"`
+	ExpirationTimeBefore     *time.Time `json:"expirationTimeBefore,omitempty" bson:"expirationTimeBefore,omitempty"`
+	Unhealthy                *bool      `json:"unhealthy,omitempty" bson:"unhealthy,omitempty"`
+	RefreshTokenRevoked      *bool      `json:"refreshTokenRevoked,omitempty" bson:"refreshTokenRevoked,omitempty"`
+	RefreshFailureCountBelow *int       `json:"refreshFailureCountBelow,omitempty" bson:"refreshFailureCountBelow,omitempty"`
 }
 
 func NewProviderSessionFilter() *ProviderSessionFilter {
@@ -46,11 +60,17 @@ func NewProviderSessionFilter() *ProviderSessionFilter {
 func (p *ProviderSessionFilter) Parse(parser structure.ObjectParser) {
 	p.Type = parser.String("type")
 	p.Name = parser.String("name")
+	p.ExpirationTimeBefore = parser.Time("expirationTimeBefore", time.RFC3339)
+	p.Unhealthy = parser.Bool("unhealthy")
+	p.RefreshTokenRevoked = parser.Bool("refreshTokenRevoked")
+	p.RefreshFailureCountBelow = parser.Int("refreshFailureCountBelow")
 }
 
 func (p *ProviderSessionFilter) Validate(validator structure.Validator) {
 	validator.String("type", p.Type).OneOf(ProviderTypes()...)
 	validator.String("name", p.Name).NotEmpty()
+	validator.Time("expirationTimeBefore", p.ExpirationTimeBefore).NotZero()
+	validator.Int("refreshFailureCountBelow", p.RefreshFailureCountBelow).GreaterThan(0)
 }
 
 func (p *ProviderSessionFilter) MutateRequest(req *http.Request) error {
@@ -61,6 +81,18 @@ func (p *ProviderSessionFilter) MutateRequest(req *http.Request) error {
 	if p.Name != nil {
 		parameters["name"] = *p.Name
 	}
+	if p.ExpirationTimeBefore != nil {
+		parameters["expirationTimeBefore"] = p.ExpirationTimeBefore.Format(time.RFC3339)
+	}
+	if p.Unhealthy != nil {
+		parameters["unhealthy"] = strconv.FormatBool(*p.Unhealthy)
+	}
+	if p.RefreshTokenRevoked != nil {
+		parameters["refreshTokenRevoked"] = strconv.FormatBool(*p.RefreshTokenRevoked)
+	}
+	if p.RefreshFailureCountBelow != nil {
+		parameters["refreshFailureCountBelow"] = strconv.Itoa(*p.RefreshFailureCountBelow)
+	}
 	return request.NewParametersMutator(parameters).MutateRequest(req)
 }
 
@@ -101,8 +133,10 @@ func (p *ProviderSessionCreate) Validate(validator structure.Validator) {
 	}
 }
 
+// A successful token update clears any previously recorded refresh error; a refresh error is recorded without modifying the token
 type ProviderSessionUpdate struct {
-	OAuthToken *oauth.Token `json:"oauthToken,omitempty" bson:"oauthToken,omitempty"`
+	OAuthToken   *oauth.Token         `json:"oauthToken,omitempty" bson:"oauthToken,omitempty"`
+	RefreshError *errors.Serializable `json:"refreshError,omitempty" bson:"refreshError,omitempty"`
 }
 
 func NewProviderSessionUpdate() *ProviderSessionUpdate {
@@ -110,7 +144,7 @@ func NewProviderSessionUpdate() *ProviderSessionUpdate {
 }
 
 func (p *ProviderSessionUpdate) HasUpdates() bool {
-	return p.OAuthToken != nil
+	return p.OAuthToken != nil || p.RefreshError != nil
 }
 
 func (p *ProviderSessionUpdate) Parse(parser structure.ObjectParser) {
@@ -119,14 +153,59 @@ func (p *ProviderSessionUpdate) Parse(parser structure.ObjectParser) {
 		p.OAuthToken.Parse(oauthTokenParser)
 		oauthTokenParser.NotParsed()
 	}
+	if parser.ReferenceExists("refreshError") {
+		p.RefreshError = &errors.Serializable{}
+		p.RefreshError.Parse("refreshError", parser)
+	}
 }
 
 func (p *ProviderSessionUpdate) Validate(validator structure.Validator) {
 	if p.OAuthToken != nil {
 		p.OAuthToken.Validate(validator.WithReference("oauthToken"))
+		if p.RefreshError != nil {
+			validator.WithReference("refreshError").ReportError(structureValidator.ErrorValueExists())
+		}
+	} else if p.RefreshError != nil {
+		p.RefreshError.Validate(validator.WithReference("refreshError"))
+	}
+}
+
+// A provider session lock serializes use of the provider session refresh token across processes; providers that
+// rotate refresh tokens invalidate the previous refresh token on use, so concurrent refreshes would otherwise fail
+type ProviderSessionLock struct {
+	ID             string    `json:"id" bson:"id"`
+	ExpirationTime time.Time `json:"expirationTime" bson:"expirationTime"`
+}
+
+func NewProviderSessionLock(duration time.Duration) *ProviderSessionLock {
+	return &ProviderSessionLock{
+		ID:             NewProviderSessionLockID(),
+		ExpirationTime: time.Now().Add(duration).Truncate(time.Second),
+	}
+}
+
+func (p *ProviderSessionLock) Parse(parser structure.ObjectParser) {
+	if ptr := parser.String("id"); ptr != nil {
+		p.ID = *ptr
+	}
+	if ptr := parser.Time("expirationTime", time.RFC3339); ptr != nil {
+		p.ExpirationTime = *ptr
 	}
 }
 
+func (p *ProviderSessionLock) Validate(validator structure.Validator) {
+	validator.String("id", &p.ID).NotEmpty()
+	validator.Time("expirationTime", &p.ExpirationTime).NotZero()
+}
+
+func (p *ProviderSessionLock) IsExpired() bool {
+	return time.Now().After(p.ExpirationTime)
+}
+
+func NewProviderSessionLockID() string {
+	return id.Must(id.New(16))
+}
+
 func NewProviderSessionID() string {
 	return id.Must(id.New(16))
 }
@@ -155,13 +234,17 @@ func ErrorValueStringAsProviderSessionIDNotValid(value string) error {
 var providerSessionIDExpression = regexp.MustCompile("^[0-9a-z]{32}$")
 
 type ProviderSession struct {
-	ID           string       `json:"id" bson:"id"`
-	UserID       string       `json:"userId" bson:"userId"`
-	Type         string       `json:"type" bson:"type"`
-	Name         string       `json:"name" bson:"name"`
-	OAuthToken   *oauth.Token `json:"oauthToken,omitempty" bson:"oauthToken,omitempty"`
-	CreatedTime  time.Time    `json:"createdTime" bson:"createdTime"`
-	ModifiedTime *time.Time   `json:"modifiedTime,omitempty" bson:"modifiedTime,omitempty"`
+	ID                  string               `json:"id" bson:"id"`
+	UserID              string               `json:"userId" bson:"userId"`
+	Type                string               `json:"type" bson:"type"`
+	Name                string               `json:"name" bson:"name"`
+	OAuthToken          *oauth.Token         `json:"oauthToken,omitempty" bson:"oauthToken,omitempty"`
+	RefreshError        *errors.Serializable `json:"refreshError,omitempty" bson:"refreshError,omitempty"`
+	RefreshErrorTime    *time.Time           `json:"refreshErrorTime,omitempty" bson:"refreshErrorTime,omitempty"`
+	RefreshFailureCount int                  `json:"refreshFailureCount,omitempty" bson:"refreshFailureCount,omitempty"`
+	Lock                *ProviderSessionLock `json:"lock,omitempty" bson:"lock,omitempty"`
+	CreatedTime         time.Time            `json:"createdTime" bson:"createdTime"`
+	ModifiedTime        *time.Time           `json:"modifiedTime,omitempty" bson:"modifiedTime,omitempty"`
 }
 
 func NewProviderSession(userID string, create *ProviderSessionCreate) (*ProviderSession, error) {
@@ -202,6 +285,19 @@ func (p *ProviderSession) Parse(parser structure.ObjectParser) {
 		p.OAuthToken.Parse(oauthTokenParser)
 		oauthTokenParser.NotParsed()
 	}
+	if parser.ReferenceExists("refreshError") {
+		p.RefreshError = &errors.Serializable{}
+		p.RefreshError.Parse("refreshError", parser)
+	}
+	p.RefreshErrorTime = parser.Time("refreshErrorTime", time.RFC3339)
+	if ptr := parser.Int("refreshFailureCount"); ptr != nil {
+		p.RefreshFailureCount = *ptr
+	}
+	if lockParser := parser.WithReferenceObjectParser("lock"); lockParser.Exists() {
+		p.Lock = &ProviderSessionLock{}
+		p.Lock.Parse(lockParser)
+		lockParser.NotParsed()
+	}
 	if ptr := parser.Time("createdTime", time.RFC3339); ptr != nil {
 		p.CreatedTime = *ptr
 	}
@@ -221,10 +317,26 @@ func (p *ProviderSession) Validate(validator structure.Validator) {
 			oauthTokenValidator.ReportError(structureValidator.ErrorValueNotExists())
 		}
 	}
+	if p.RefreshError != nil {
+		p.RefreshError.Validate(validator.WithReference("refreshError"))
+	}
+	validator.Time("refreshErrorTime", p.RefreshErrorTime).After(p.CreatedTime).BeforeNow(time.Second)
+	validator.Int("refreshFailureCount", &p.RefreshFailureCount).GreaterThanOrEqualTo(0)
+	if p.Lock != nil {
+		p.Lock.Validate(validator.WithReference("lock"))
+	}
 	validator.Time("createdTime", &p.CreatedTime).NotZero().BeforeNow(time.Second)
 	validator.Time("modifiedTime", p.ModifiedTime).After(p.CreatedTime).BeforeNow(time.Second)
 }
 
+func (p *ProviderSession) IsHealthy() bool {
+	return p.RefreshFailureCount == 0
+}
+
+func (p *ProviderSession) IsRefreshTokenRevoked() bool {
+	return p.RefreshError != nil && errors.Code(p.RefreshError.Error) == ErrorCodeProviderSessionRefreshTokenRevoked
+}
+
 func (p *ProviderSession) Sanitize(details request.Details) error {
 	if details != nil && details.IsService() {
 		return nil
@@ -242,3 +354,11 @@ func (p ProviderSessions) Sanitize(details request.Details) error {
 	}
 	return nil
 }
+
+func ErrorProviderSessionRefreshTokenRevoked() error {
+	return errors.Prepared(ErrorCodeProviderSessionRefreshTokenRevoked, "provider session refresh token revoked", "provider session refresh token has been revoked")
+}
+
+func ErrorProviderSessionRefreshFailed(err error) error {
+	return errors.WrapPrepared(err, ErrorCodeProviderSessionRefreshFailed, "provider session refresh failed", "provider session refresh failed")
+}
@@ -5,8 +5,15 @@ import (
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
+	"net/http"
+	"net/http/httptest"
+	"time"
+
 	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/errors"
 	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/oauth"
+	"github.com/tidepool-org/platform/pointer"
 	structureTest "github.com/tidepool-org/platform/structure/test"
 	structureValidator "github.com/tidepool-org/platform/structure/validator"
 	"github.com/tidepool-org/platform/test"
@@ -47,6 +54,120 @@ var _ = Describe("ProviderSession", func() {
 			errorsTest.ExpectErrorDetails,
 			Entry("is ErrorValueStringAsProviderSessionIDNotValid with empty string", auth.ErrorValueStringAsProviderSessionIDNotValid(""), "value-not-valid", "value is not valid", `value "" is not valid as provider session id`),
 			Entry("is ErrorValueStringAsProviderSessionIDNotValid with non-empty string", auth.ErrorValueStringAsProviderSessionIDNotValid("0123456789abcdef0123456789abcdef"), "value-not-valid", "value is not valid", `value "0123456789abcdef0123456789abcdef" is not valid as provider session id`),
+			Entry("is ErrorProviderSessionRefreshTokenRevoked", auth.ErrorProviderSessionRefreshTokenRevoked(), "provider-session-refresh-token-revoked", "provider session refresh token revoked", "provider session refresh token has been revoked"),
 		)
+
+		It("ErrorProviderSessionRefreshFailed wraps the error with the expected code", func() {
+			err := errorsTest.NewError()
+			refreshErr := auth.ErrorProviderSessionRefreshFailed(err)
+			Expect(errors.Code(refreshErr)).To(Equal(auth.ErrorCodeProviderSessionRefreshFailed))
+			Expect(errors.Cause(refreshErr)).To(Equal(err))
+		})
+	})
+
+	Context("ProviderSessionFilter", func() {
+		DescribeTable("validates the provider session filter",
+			func(mutator func(filter *auth.ProviderSessionFilter), expectedErrors ...error) {
+				filter := auth.NewProviderSessionFilter()
+				mutator(filter)
+				errorsTest.ExpectEqual(structureValidator.New().Validate(filter), expectedErrors...)
+			},
+			Entry("succeeds",
+				func(filter *auth.ProviderSessionFilter) {},
+			),
+			Entry("expiration time before and unhealthy valid",
+				func(filter *auth.ProviderSessionFilter) {
+					filter.ExpirationTimeBefore = pointer.FromTime(time.Now())
+					filter.Unhealthy = pointer.FromBool(true)
+				},
+			),
+			Entry("expiration time before zero",
+				func(filter *auth.ProviderSessionFilter) { filter.ExpirationTimeBefore = pointer.FromTime(time.Time{}) },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueEmpty(), "/expirationTimeBefore"),
+			),
+			Entry("refresh failure count below not positive",
+				func(filter *auth.ProviderSessionFilter) { filter.RefreshFailureCountBelow = pointer.FromInt(0) },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueNotGreaterThan(0, 0), "/refreshFailureCountBelow"),
+			),
+		)
+
+		It("MutateRequest adds the expiration time before and unhealthy parameters", func() {
+			expirationTimeBefore := time.Now().Truncate(time.Second)
+			filter := auth.NewProviderSessionFilter()
+			filter.ExpirationTimeBefore = pointer.FromTime(expirationTimeBefore)
+			filter.Unhealthy = pointer.FromBool(true)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			Expect(filter.MutateRequest(req)).To(Succeed())
+			Expect(req.URL.Query().Get("expirationTimeBefore")).To(Equal(expirationTimeBefore.Format(time.RFC3339)))
+			Expect(req.URL.Query().Get("unhealthy")).To(Equal("true"))
+		})
+
+		It("MutateRequest adds the refresh token revoked and refresh failure count below parameters", func() {
+			filter := auth.NewProviderSessionFilter()
+			filter.RefreshTokenRevoked = pointer.FromBool(false)
+			filter.RefreshFailureCountBelow = pointer.FromInt(3)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			Expect(filter.MutateRequest(req)).To(Succeed())
+			Expect(req.URL.Query().Get("refreshTokenRevoked")).To(Equal("false"))
+			Expect(req.URL.Query().Get("refreshFailureCountBelow")).To(Equal("3"))
+		})
+	})
+
+	Context("ProviderSessionLock", func() {
+		It("NewProviderSessionLock returns a lock expiring after the duration", func() {
+			lock := auth.NewProviderSessionLock(time.Minute)
+			Expect(lock.ID).ToNot(BeEmpty())
+			Expect(lock.ExpirationTime).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+			Expect(lock.IsExpired()).To(BeFalse())
+			Expect(structureValidator.New().Validate(lock)).To(Succeed())
+		})
+
+		It("NewProviderSessionLock returns a different id for each invocation", func() {
+			Expect(auth.NewProviderSessionLock(time.Minute).ID).ToNot(Equal(auth.NewProviderSessionLock(time.Minute).ID))
+		})
+
+		It("Validate reports an error if the id is missing", func() {
+			lock := auth.NewProviderSessionLock(time.Minute)
+			lock.ID = ""
+			errorsTest.ExpectEqual(structureValidator.New().Validate(lock), errorsTest.WithPointerSource(structureValidator.ErrorValueEmpty(), "/id"))
+		})
+	})
+
+	Context("ProviderSessionUpdate", func() {
+		It("HasUpdates returns false if there are no updates", func() {
+			Expect(auth.NewProviderSessionUpdate().HasUpdates()).To(BeFalse())
+		})
+
+		It("HasUpdates returns true if there is a refresh error", func() {
+			update := auth.NewProviderSessionUpdate()
+			update.RefreshError = &errors.Serializable{Error: auth.ErrorProviderSessionRefreshTokenRevoked()}
+			Expect(update.HasUpdates()).To(BeTrue())
+		})
+
+		It("Validate reports an error if both oauth token and refresh error are specified", func() {
+			update := auth.NewProviderSessionUpdate()
+			update.OAuthToken = &oauth.Token{AccessToken: test.RandomString()}
+			update.RefreshError = &errors.Serializable{Error: auth.ErrorProviderSessionRefreshTokenRevoked()}
+			errorsTest.ExpectEqual(structureValidator.New().Validate(update), errorsTest.WithPointerSource(structureValidator.ErrorValueExists(), "/refreshError"))
+		})
+	})
+
+	Context("ProviderSession", func() {
+		It("IsHealthy returns true if there are no refresh failures", func() {
+			Expect((&auth.ProviderSession{}).IsHealthy()).To(BeTrue())
+		})
+
+		It("IsHealthy returns false if there are refresh failures", func() {
+			Expect((&auth.ProviderSession{RefreshFailureCount: 1}).IsHealthy()).To(BeFalse())
+		})
+
+		It("IsRefreshTokenRevoked returns true if the refresh error is revoked", func() {
+			Expect((&auth.ProviderSession{RefreshError: &errors.Serializable{Error: auth.ErrorProviderSessionRefreshTokenRevoked()}}).IsRefreshTokenRevoked()).To(BeTrue())
+		})
+
+		It("IsRefreshTokenRevoked returns false if the refresh error is not revoked", func() {
+			Expect((&auth.ProviderSession{RefreshError: &errors.Serializable{Error: auth.ErrorProviderSessionRefreshFailed(errorsTest.NewError())}}).IsRefreshTokenRevoked()).To(BeFalse())
+			Expect((&auth.ProviderSession{}).IsRefreshTokenRevoked()).To(BeFalse())
+		})
 	})
 })
@@ -0,0 +1,3 @@
+package refresh
+
+const Type = "org.tidepool.auth.provider_session.refresh"
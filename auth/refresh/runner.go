@@ -0,0 +1,272 @@
+package refresh
+
+import (
+	"context"
+	"time"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/data"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/oauth"
+	oauthToken "github.com/tidepool-org/platform/oauth/token"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/provider"
+	"github.com/tidepool-org/platform/task"
+)
+
+const (
+	AvailableAfterDuration     = 15 * time.Minute
+	ExpirationWindowDuration   = time.Hour
+	LockDuration               = 5 * time.Minute
+	RefreshFailureCountMaximum = 3
+	PageCountMaximum           = 10
+	PageSize                   = 100
+)
+
+type Runner struct {
+	logger             log.Logger
+	authClient         auth.Client
+	dataSourceAccessor data.DataSourceAccessor
+	providerFactory    provider.Factory
+}
+
+func NewRunner(logger log.Logger, authClient auth.Client, dataSourceAccessor data.DataSourceAccessor, providerFactory provider.Factory) (*Runner, error) {
+	if logger == nil {
+		return nil, errors.New("logger is missing")
+	}
+	if authClient == nil {
+		return nil, errors.New("auth client is missing")
+	}
+	if dataSourceAccessor == nil {
+		return nil, errors.New("data source accessor is missing")
+	}
+	if providerFactory == nil {
+		return nil, errors.New("provider factory is missing")
+	}
+
+	return &Runner{
+		logger:             logger,
+		authClient:         authClient,
+		dataSourceAccessor: dataSourceAccessor,
+		providerFactory:    providerFactory,
+	}, nil
+}
+
+func (r *Runner) Logger() log.Logger {
+	return r.logger
+}
+
+func (r *Runner) AuthClient() auth.Client {
+	return r.authClient
+}
+
+func (r *Runner) DataSourceAccessor() data.DataSourceAccessor {
+	return r.dataSourceAccessor
+}
+
+func (r *Runner) ProviderFactory() provider.Factory {
+	return r.providerFactory
+}
+
+func (r *Runner) CanRunTask(tsk *task.Task) bool {
+	return tsk != nil && tsk.Type == Type
+}
+
+func (r *Runner) Run(ctx context.Context, tsk *task.Task) {
+	ctx = log.NewContextWithLogger(ctx, r.Logger())
+
+	tsk.ClearError()
+
+	if serverSessionToken, err := r.AuthClient().ServerSessionToken(); err != nil {
+		tsk.AppendError(errors.Wrap(err, "unable to get server session token"))
+	} else if err = r.refresh(auth.NewContextWithServerSessionToken(ctx, serverSessionToken)); err != nil {
+		tsk.AppendError(errors.Wrap(err, "unable to refresh provider sessions"))
+	}
+
+	if !tsk.IsFailed() {
+		tsk.RepeatAvailableAfter(AvailableAfterDuration)
+	}
+}
+
+func (r *Runner) refresh(ctx context.Context) error {
+	providerSessions, err := r.listExpiringProviderSessions(ctx)
+	if err != nil {
+		return err
+	}
+
+	var refreshed int
+	for _, providerSession := range providerSessions {
+		if r.refreshProviderSession(ctx, providerSession) {
+			refreshed++
+		}
+	}
+
+	r.Logger().WithFields(log.Fields{"expiring": len(providerSessions), "refreshed": refreshed}).Debug("Refreshed expiring provider sessions")
+	return nil
+}
+
+// Collect all provider sessions before refreshing any as refreshing would otherwise shift subsequent pages
+func (r *Runner) listExpiringProviderSessions(ctx context.Context) (auth.ProviderSessions, error) {
+	providerSessions := auth.ProviderSessions{}
+
+	filter := auth.NewProviderSessionFilter()
+	filter.Type = pointer.FromString(auth.ProviderTypeOAuth)
+	filter.ExpirationTimeBefore = pointer.FromTime(time.Now().Add(ExpirationWindowDuration))
+	filter.RefreshTokenRevoked = pointer.FromBool(false)
+	filter.RefreshFailureCountBelow = pointer.FromInt(RefreshFailureCountMaximum)
+	pagination := page.NewPagination()
+	pagination.Size = PageSize
+	for ; pagination.Page < PageCountMaximum; pagination.Page++ {
+		pageProviderSessions, err := r.AuthClient().ListProviderSessions(ctx, filter, pagination)
+		if err != nil {
+			return nil, err
+		}
+
+		providerSessions = append(providerSessions, pageProviderSessions...)
+
+		if len(pageProviderSessions) < pagination.Size {
+			break
+		}
+	}
+
+	return providerSessions, nil
+}
+
+func (r *Runner) refreshProviderSession(ctx context.Context, providerSession *auth.ProviderSession) bool {
+	logger := r.Logger().WithFields(log.Fields{"providerSessionId": providerSession.ID, "type": providerSession.Type, "name": providerSession.Name})
+
+	if providerSession.OAuthToken == nil || providerSession.OAuthToken.RefreshToken == "" {
+		return false
+	}
+
+	prvdr, err := r.ProviderFactory().Get(providerSession.Type, providerSession.Name)
+	if err != nil {
+		logger.WithError(err).Warn("Unable to get provider for provider session")
+		return false
+	}
+	tokenSourceSource, ok := prvdr.(oauth.TokenSourceSource)
+	if !ok {
+		logger.Warn("Provider for provider session is not an oauth token source source")
+		return false
+	}
+	if maintenanceWindowsReporter, ok := prvdr.(provider.MaintenanceWindowsReporter); ok {
+		if maintenanceWindow := maintenanceWindowsReporter.MaintenanceWindows().Active(time.Now()); maintenanceWindow != nil {
+			logger.WithField("maintenanceWindow", maintenanceWindow.String()).Debug("Skipping provider session during maintenance window")
+			return false
+		}
+	}
+
+	// Lock the provider session so the refresh does not race a fetch task rotating the same refresh token
+	lock := auth.NewProviderSessionLock(LockDuration)
+	lockedProviderSession, err := r.AuthClient().LockProviderSession(ctx, providerSession.ID, lock)
+	if err != nil {
+		logger.WithError(err).Warn("Unable to lock provider session")
+		return false
+	} else if lockedProviderSession == nil {
+		logger.Debug("Skipping locked provider session")
+		return false
+	}
+	defer func() {
+		if unlockErr := r.AuthClient().UnlockProviderSession(ctx, providerSession.ID, lock.ID); unlockErr != nil {
+			logger.WithError(unlockErr).Warn("Unable to unlock provider session")
+		}
+	}()
+
+	// The token may have been refreshed by another since the provider sessions were listed
+	providerSession = lockedProviderSession
+	if providerSession.OAuthToken == nil || providerSession.OAuthToken.RefreshToken == "" || providerSession.OAuthToken.ExpirationTime.After(time.Now().Add(ExpirationWindowDuration)) {
+		return false
+	}
+
+	refreshedToken, err := r.refreshToken(ctx, tokenSourceSource, providerSession.OAuthToken)
+	if err != nil {
+		logger.WithError(err).Warn("Unable to refresh provider session token")
+		r.recordRefreshFailure(ctx, providerSession, err)
+		return false
+	} else if refreshedToken == nil {
+		return false
+	}
+
+	providerSessionUpdate := auth.NewProviderSessionUpdate()
+	providerSessionUpdate.OAuthToken = refreshedToken
+	if _, err = r.AuthClient().UpdateProviderSession(ctx, providerSession.ID, providerSessionUpdate); err != nil {
+		logger.WithError(err).Error("Unable to update provider session with refreshed token")
+		return false
+	}
+
+	return true
+}
+
+// The token is copied and expired to force the token source to refresh ahead of the actual expiration time
+func (r *Runner) refreshToken(ctx context.Context, tokenSourceSource oauth.TokenSourceSource, token *oauth.Token) (*oauth.Token, error) {
+	expiredToken := *token
+	expiredToken.Expire()
+
+	tokenSource, err := oauthToken.NewSourceWithToken(&expiredToken)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = tokenSource.HTTPClient(ctx, tokenSourceSource); err != nil {
+		return nil, err
+	}
+
+	return tokenSource.RefreshedToken()
+}
+
+// A revoked refresh token immediately moves the data sources to error; other failures do so once the maximum is reached
+func (r *Runner) recordRefreshFailure(ctx context.Context, providerSession *auth.ProviderSession, err error) {
+	logger := r.Logger().WithField("providerSessionId", providerSession.ID)
+
+	var refreshErr error
+	if oauth.IsAccessTokenError(err) {
+		refreshErr = auth.ErrorProviderSessionRefreshTokenRevoked()
+	} else {
+		refreshErr = auth.ErrorProviderSessionRefreshFailed(err)
+	}
+
+	providerSessionUpdate := auth.NewProviderSessionUpdate()
+	providerSessionUpdate.RefreshError = &errors.Serializable{Error: refreshErr}
+	updatedProviderSession, updateErr := r.AuthClient().UpdateProviderSession(ctx, providerSession.ID, providerSessionUpdate)
+	if updateErr != nil {
+		logger.WithError(updateErr).Error("Unable to update provider session with refresh error")
+		return
+	} else if updatedProviderSession == nil {
+		return
+	}
+
+	if !updatedProviderSession.IsRefreshTokenRevoked() && updatedProviderSession.RefreshFailureCount < RefreshFailureCountMaximum {
+		return
+	}
+
+	if updateErr = r.updateDataSourcesWithError(ctx, updatedProviderSession, refreshErr); updateErr != nil {
+		logger.WithError(updateErr).Error("Unable to update data sources with refresh error")
+	}
+}
+
+func (r *Runner) updateDataSourcesWithError(ctx context.Context, providerSession *auth.ProviderSession, err error) error {
+	filter := data.NewDataSourceFilter()
+	filter.ProviderType = pointer.FromString(providerSession.Type)
+	filter.ProviderName = pointer.FromString(providerSession.Name)
+	filter.ProviderSessionID = pointer.FromString(providerSession.ID)
+	dataSources, listErr := r.DataSourceAccessor().ListUserDataSources(ctx, providerSession.UserID, filter, nil)
+	if listErr != nil {
+		return listErr
+	}
+
+	for _, dataSource := range dataSources {
+		if dataSource.State == data.DataSourceStateError {
+			continue
+		}
+
+		dataSourceUpdate := data.NewDataSourceUpdate()
+		dataSourceUpdate.State = pointer.FromString(data.DataSourceStateError)
+		dataSourceUpdate.Error = &errors.Serializable{Error: err}
+		if _, updateErr := r.DataSourceAccessor().UpdateDataSource(ctx, dataSource.ID, dataSourceUpdate); updateErr != nil {
+			return updateErr
+		}
+	}
+
+	return nil
+}
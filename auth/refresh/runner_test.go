@@ -0,0 +1,369 @@
+package refresh_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	stdErrors "errors"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/tidepool-org/platform/auth"
+	authRefresh "github.com/tidepool-org/platform/auth/refresh"
+	authTest "github.com/tidepool-org/platform/auth/test"
+	"github.com/tidepool-org/platform/data"
+	dataTest "github.com/tidepool-org/platform/data/test"
+	"github.com/tidepool-org/platform/errors"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	logTest "github.com/tidepool-org/platform/log/test"
+	"github.com/tidepool-org/platform/oauth"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/provider"
+	providerTest "github.com/tidepool-org/platform/provider/test"
+	serviceTest "github.com/tidepool-org/platform/service/test"
+	"github.com/tidepool-org/platform/task"
+	"github.com/tidepool-org/platform/test"
+)
+
+type tokenSourceFunc func() (*oauth2.Token, error)
+
+func (t tokenSourceFunc) Token() (*oauth2.Token, error) {
+	return t()
+}
+
+type oauthProvider struct {
+	tokenSource oauth2.TokenSource
+}
+
+func (o *oauthProvider) Type() string {
+	return auth.ProviderTypeOAuth
+}
+
+func (o *oauthProvider) Name() string {
+	return "test"
+}
+
+func (o *oauthProvider) OnCreate(ctx context.Context, userID string, providerSessionID string) error {
+	return nil
+}
+
+func (o *oauthProvider) OnDelete(ctx context.Context, userID string, providerSessionID string) error {
+	return nil
+}
+
+func (o *oauthProvider) TokenSource(ctx context.Context, token *oauth.Token) (oauth2.TokenSource, error) {
+	return o.tokenSource, nil
+}
+
+type maintenanceOAuthProvider struct {
+	*oauthProvider
+	maintenanceWindows provider.MaintenanceWindows
+}
+
+func (m *maintenanceOAuthProvider) MaintenanceWindows() provider.MaintenanceWindows {
+	return m.maintenanceWindows
+}
+
+type nonOAuthProvider struct{}
+
+func (n *nonOAuthProvider) Type() string {
+	return auth.ProviderTypeOAuth
+}
+
+func (n *nonOAuthProvider) Name() string {
+	return "test"
+}
+
+func (n *nonOAuthProvider) OnCreate(ctx context.Context, userID string, providerSessionID string) error {
+	return nil
+}
+
+func (n *nonOAuthProvider) OnDelete(ctx context.Context, userID string, providerSessionID string) error {
+	return nil
+}
+
+var _ = Describe("Runner", func() {
+	var logger *logTest.Logger
+	var authClient *authTest.Client
+	var dataSourceAccessor *dataTest.DataSourceAccessor
+	var providerFactory *providerTest.Factory
+
+	BeforeEach(func() {
+		logger = logTest.NewLogger()
+		authClient = authTest.NewClient()
+		dataSourceAccessor = dataTest.NewDataSourceAccessor()
+		providerFactory = providerTest.NewFactory()
+	})
+
+	AfterEach(func() {
+		providerFactory.Expectations()
+		dataSourceAccessor.AssertOutputsEmpty()
+		authClient.Expectations()
+	})
+
+	Context("NewRunner", func() {
+		It("returns an error if the logger is missing", func() {
+			rnnr, err := authRefresh.NewRunner(nil, authClient, dataSourceAccessor, providerFactory)
+			Expect(err).To(MatchError("logger is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the auth client is missing", func() {
+			rnnr, err := authRefresh.NewRunner(logger, nil, dataSourceAccessor, providerFactory)
+			Expect(err).To(MatchError("auth client is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the data source accessor is missing", func() {
+			rnnr, err := authRefresh.NewRunner(logger, authClient, nil, providerFactory)
+			Expect(err).To(MatchError("data source accessor is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the provider factory is missing", func() {
+			rnnr, err := authRefresh.NewRunner(logger, authClient, dataSourceAccessor, nil)
+			Expect(err).To(MatchError("provider factory is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			Expect(authRefresh.NewRunner(logger, authClient, dataSourceAccessor, providerFactory)).ToNot(BeNil())
+		})
+	})
+
+	Context("with new runner", func() {
+		var rnnr *authRefresh.Runner
+
+		BeforeEach(func() {
+			var err error
+			rnnr, err = authRefresh.NewRunner(logger, authClient, dataSourceAccessor, providerFactory)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rnnr).ToNot(BeNil())
+		})
+
+		Context("CanRunTask", func() {
+			It("returns false if the task is missing", func() {
+				Expect(rnnr.CanRunTask(nil)).To(BeFalse())
+			})
+
+			It("returns false if the task type does not match", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: test.RandomString()})).To(BeFalse())
+			})
+
+			It("returns true if the task type matches", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: authRefresh.Type})).To(BeTrue())
+			})
+		})
+
+		Context("Run", func() {
+			var ctx context.Context
+			var tsk *task.Task
+			var serverSessionToken string
+
+			BeforeEach(func() {
+				var err error
+				ctx = context.Background()
+				tsk, err = task.NewTask(authRefresh.NewTaskCreate())
+				Expect(err).ToNot(HaveOccurred())
+				tsk.State = task.TaskStateRunning
+				serverSessionToken = authTest.NewSessionToken()
+			})
+
+			It("records the error if the server session token returns an error", func() {
+				authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: "", Error: errorsTest.NewError()}}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.State).To(Equal(task.TaskStatePending))
+			})
+
+			Context("with server session token", func() {
+				var providerSession *auth.ProviderSession
+				var prvdr *oauthProvider
+
+				BeforeEach(func() {
+					authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: serverSessionToken, Error: nil}}
+					providerSession = &auth.ProviderSession{
+						ID:     auth.NewProviderSessionID(),
+						UserID: serviceTest.NewUserID(),
+						Type:   auth.ProviderTypeOAuth,
+						Name:   "test",
+						OAuthToken: &oauth.Token{
+							AccessToken:    test.RandomString(),
+							TokenType:      "Bearer",
+							RefreshToken:   test.RandomString(),
+							ExpirationTime: time.Now().Add(30 * time.Minute),
+						},
+						CreatedTime: time.Now().Add(-time.Hour),
+					}
+					prvdr = &oauthProvider{}
+				})
+
+				It("records the error if list provider sessions returns an error", func() {
+					authClient.ListProviderSessionsOutputs = []authTest.ListProviderSessionsOutput{{ProviderSessions: nil, Error: errorsTest.NewError()}}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeTrue())
+				})
+
+				It("returns successfully when there are no expiring provider sessions", func() {
+					authClient.ListProviderSessionsOutputs = []authTest.ListProviderSessionsOutput{{ProviderSessions: auth.ProviderSessions{}, Error: nil}}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeFalse())
+					Expect(tsk.State).To(Equal(task.TaskStatePending))
+					Expect(tsk.AvailableTime).ToNot(BeNil())
+					Expect(*tsk.AvailableTime).To(BeTemporally("~", time.Now().Add(authRefresh.AvailableAfterDuration), time.Second))
+					Expect(authClient.ListProviderSessionsInputs).To(HaveLen(1))
+					Expect(auth.ServerSessionTokenFromContext(authClient.ListProviderSessionsInputs[0].Context)).To(Equal(serverSessionToken))
+					filter := authClient.ListProviderSessionsInputs[0].Filter
+					Expect(filter.Type).To(Equal(&providerSession.Type))
+					Expect(filter.ExpirationTimeBefore).ToNot(BeNil())
+					Expect(*filter.ExpirationTimeBefore).To(BeTemporally("~", time.Now().Add(authRefresh.ExpirationWindowDuration), time.Second))
+					Expect(filter.RefreshTokenRevoked).To(Equal(pointer.FromBool(false)))
+					Expect(filter.RefreshFailureCountBelow).To(Equal(pointer.FromInt(authRefresh.RefreshFailureCountMaximum)))
+					Expect(authClient.ListProviderSessionsInputs[0].Pagination).To(Equal(&page.Pagination{Page: 0, Size: authRefresh.PageSize}))
+				})
+
+				It("skips provider sessions with an unknown provider", func() {
+					authClient.ListProviderSessionsOutputs = []authTest.ListProviderSessionsOutput{{ProviderSessions: auth.ProviderSessions{providerSession}, Error: nil}}
+					providerFactory.GetOutputs = []providerTest.GetOutput{{Provider: nil, Error: errorsTest.NewError()}}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeFalse())
+				})
+
+				It("skips provider sessions whose provider is not an oauth provider", func() {
+					authClient.ListProviderSessionsOutputs = []authTest.ListProviderSessionsOutput{{ProviderSessions: auth.ProviderSessions{providerSession}, Error: nil}}
+					providerFactory.GetOutputs = []providerTest.GetOutput{{Provider: &nonOAuthProvider{}, Error: nil}}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeFalse())
+				})
+
+				It("skips provider sessions whose provider is within a maintenance window", func() {
+					hour, minute, _ := time.Now().UTC().Clock()
+					offset := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute
+					maintenanceWindow := &provider.MaintenanceWindow{Location: time.UTC, StartOffset: (offset + 23*time.Hour) % (24 * time.Hour), EndOffset: (offset + time.Hour) % (24 * time.Hour)}
+					authClient.ListProviderSessionsOutputs = []authTest.ListProviderSessionsOutput{{ProviderSessions: auth.ProviderSessions{providerSession}, Error: nil}}
+					providerFactory.GetOutputs = []providerTest.GetOutput{{Provider: &maintenanceOAuthProvider{oauthProvider: prvdr, maintenanceWindows: provider.MaintenanceWindows{maintenanceWindow}}, Error: nil}}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeFalse())
+				})
+
+				It("skips provider sessions that are locked by another", func() {
+					authClient.ListProviderSessionsOutputs = []authTest.ListProviderSessionsOutput{{ProviderSessions: auth.ProviderSessions{providerSession}, Error: nil}}
+					providerFactory.GetOutputs = []providerTest.GetOutput{{Provider: prvdr, Error: nil}}
+					authClient.LockProviderSessionOutputs = []authTest.LockProviderSessionOutput{{ProviderSession: nil, Error: nil}}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeFalse())
+					Expect(authClient.LockProviderSessionInputs).To(HaveLen(1))
+					Expect(authClient.LockProviderSessionInputs[0].ID).To(Equal(providerSession.ID))
+					Expect(authClient.LockProviderSessionInputs[0].Lock.ExpirationTime).To(BeTemporally("~", time.Now().Add(authRefresh.LockDuration), time.Second))
+				})
+
+				It("skips provider sessions whose token was refreshed by another since listed", func() {
+					refreshedProviderSession := *providerSession
+					refreshedProviderSession.OAuthToken = &oauth.Token{
+						AccessToken:    test.RandomString(),
+						TokenType:      "Bearer",
+						RefreshToken:   test.RandomString(),
+						ExpirationTime: time.Now().Add(2 * time.Hour),
+					}
+					authClient.ListProviderSessionsOutputs = []authTest.ListProviderSessionsOutput{{ProviderSessions: auth.ProviderSessions{providerSession}, Error: nil}}
+					providerFactory.GetOutputs = []providerTest.GetOutput{{Provider: prvdr, Error: nil}}
+					authClient.LockProviderSessionOutputs = []authTest.LockProviderSessionOutput{{ProviderSession: &refreshedProviderSession, Error: nil}}
+					authClient.UnlockProviderSessionOutputs = []error{nil}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeFalse())
+					Expect(authClient.UnlockProviderSessionInputs).To(Equal([]authTest.UnlockProviderSessionInput{{Context: authClient.LockProviderSessionInputs[0].Context, ID: providerSession.ID, LockID: authClient.LockProviderSessionInputs[0].Lock.ID}}))
+				})
+
+				Context("with oauth provider", func() {
+					BeforeEach(func() {
+						authClient.ListProviderSessionsOutputs = []authTest.ListProviderSessionsOutput{{ProviderSessions: auth.ProviderSessions{providerSession}, Error: nil}}
+						providerFactory.GetOutputs = []providerTest.GetOutput{{Provider: prvdr, Error: nil}}
+						authClient.LockProviderSessionOutputs = []authTest.LockProviderSessionOutput{{ProviderSession: providerSession, Error: nil}}
+						authClient.UnlockProviderSessionOutputs = []error{nil}
+					})
+
+					AfterEach(func() {
+						Expect(authClient.UnlockProviderSessionInputs).To(HaveLen(1))
+						Expect(authClient.UnlockProviderSessionInputs[0].LockID).To(Equal(authClient.LockProviderSessionInputs[0].Lock.ID))
+					})
+
+					It("updates the provider session with the refreshed token", func() {
+						refreshedToken := &oauth2.Token{AccessToken: test.RandomString(), TokenType: "Bearer", RefreshToken: test.RandomString(), Expiry: time.Now().Add(2 * time.Hour).Truncate(time.Second)}
+						prvdr.tokenSource = oauth2.StaticTokenSource(refreshedToken)
+						authClient.UpdateProviderSessionOutputs = []authTest.UpdateProviderSessionOutput{{ProviderSession: providerSession, Error: nil}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeFalse())
+						Expect(providerFactory.GetInputs).To(Equal([]providerTest.GetInput{{Type: providerSession.Type, Name: providerSession.Name}}))
+						Expect(authClient.UpdateProviderSessionInputs).To(HaveLen(1))
+						Expect(authClient.UpdateProviderSessionInputs[0].ID).To(Equal(providerSession.ID))
+						Expect(authClient.UpdateProviderSessionInputs[0].Update.OAuthToken).To(Equal(&oauth.Token{
+							AccessToken:    refreshedToken.AccessToken,
+							TokenType:      refreshedToken.TokenType,
+							RefreshToken:   refreshedToken.RefreshToken,
+							ExpirationTime: refreshedToken.Expiry,
+						}))
+						Expect(authClient.UpdateProviderSessionInputs[0].Update.RefreshError).To(BeNil())
+						Expect(providerSession.OAuthToken.ExpirationTime).To(BeTemporally(">", time.Now()))
+					})
+
+					It("records a refresh failure without updating data sources below the maximum", func() {
+						prvdr.tokenSource = tokenSourceFunc(func() (*oauth2.Token, error) { return nil, stdErrors.New("connection refused") })
+						updatedProviderSession := *providerSession
+						updatedProviderSession.RefreshFailureCount = 1
+						authClient.UpdateProviderSessionOutputs = []authTest.UpdateProviderSessionOutput{{ProviderSession: &updatedProviderSession, Error: nil}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeFalse())
+						Expect(authClient.UpdateProviderSessionInputs).To(HaveLen(1))
+						Expect(authClient.UpdateProviderSessionInputs[0].Update.OAuthToken).To(BeNil())
+						Expect(authClient.UpdateProviderSessionInputs[0].Update.RefreshError).ToNot(BeNil())
+						Expect(errors.Code(authClient.UpdateProviderSessionInputs[0].Update.RefreshError.Error)).To(Equal(auth.ErrorCodeProviderSessionRefreshFailed))
+						Expect(dataSourceAccessor.ListUserDataSourcesInvocations).To(Equal(0))
+					})
+
+					It("updates data sources to error once the refresh failure maximum is reached", func() {
+						prvdr.tokenSource = tokenSourceFunc(func() (*oauth2.Token, error) { return nil, stdErrors.New("connection refused") })
+						updatedProviderSession := *providerSession
+						updatedProviderSession.RefreshFailureCount = authRefresh.RefreshFailureCountMaximum
+						authClient.UpdateProviderSessionOutputs = []authTest.UpdateProviderSessionOutput{{ProviderSession: &updatedProviderSession, Error: nil}}
+						dataSources := data.DataSources{
+							{ID: test.RandomString(), State: data.DataSourceStateConnected},
+							{ID: test.RandomString(), State: data.DataSourceStateError},
+						}
+						dataSourceAccessor.ListUserDataSourcesOutputs = []dataTest.ListUserDataSourcesOutput{{DataSources: dataSources, Error: nil}}
+						dataSourceAccessor.UpdateDataSourceOutputs = []dataTest.UpdateDataSourceOutput{{DataSource: dataSources[0], Error: nil}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeFalse())
+						Expect(dataSourceAccessor.ListUserDataSourcesInputs).To(HaveLen(1))
+						Expect(dataSourceAccessor.ListUserDataSourcesInputs[0].UserID).To(Equal(providerSession.UserID))
+						Expect(dataSourceAccessor.ListUserDataSourcesInputs[0].Filter.ProviderSessionID).To(Equal(&providerSession.ID))
+						Expect(dataSourceAccessor.UpdateDataSourceInputs).To(HaveLen(1))
+						Expect(dataSourceAccessor.UpdateDataSourceInputs[0].ID).To(Equal(dataSources[0].ID))
+						Expect(dataSourceAccessor.UpdateDataSourceInputs[0].Update.State).To(Equal(pointer.FromString(data.DataSourceStateError)))
+						Expect(errors.Code(dataSourceAccessor.UpdateDataSourceInputs[0].Update.Error.Error)).To(Equal(auth.ErrorCodeProviderSessionRefreshFailed))
+					})
+
+					It("updates data sources to error immediately if the refresh token was revoked", func() {
+						prvdr.tokenSource = tokenSourceFunc(func() (*oauth2.Token, error) {
+							return nil, stdErrors.New("oauth2: cannot fetch token: 400 Bad Request")
+						})
+						updatedProviderSession := *providerSession
+						updatedProviderSession.RefreshError = &errors.Serializable{Error: auth.ErrorProviderSessionRefreshTokenRevoked()}
+						updatedProviderSession.RefreshFailureCount = 1
+						authClient.UpdateProviderSessionOutputs = []authTest.UpdateProviderSessionOutput{{ProviderSession: &updatedProviderSession, Error: nil}}
+						dataSources := data.DataSources{{ID: test.RandomString(), State: data.DataSourceStateConnected}}
+						dataSourceAccessor.ListUserDataSourcesOutputs = []dataTest.ListUserDataSourcesOutput{{DataSources: dataSources, Error: nil}}
+						dataSourceAccessor.UpdateDataSourceOutputs = []dataTest.UpdateDataSourceOutput{{DataSource: dataSources[0], Error: nil}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeFalse())
+						Expect(errors.Code(authClient.UpdateProviderSessionInputs[0].Update.RefreshError.Error)).To(Equal(auth.ErrorCodeProviderSessionRefreshTokenRevoked))
+						Expect(dataSourceAccessor.UpdateDataSourceInputs).To(HaveLen(1))
+						Expect(errors.Code(dataSourceAccessor.UpdateDataSourceInputs[0].Update.Error.Error)).To(Equal(auth.ErrorCodeProviderSessionRefreshTokenRevoked))
+					})
+				})
+			})
+		})
+	})
+})
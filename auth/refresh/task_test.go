@@ -0,0 +1,27 @@
+package refresh_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	authRefresh "github.com/tidepool-org/platform/auth/refresh"
+)
+
+var _ = Describe("Task", func() {
+	Context("TaskName", func() {
+		It("returns the type", func() {
+			Expect(authRefresh.TaskName()).To(Equal(authRefresh.Type))
+		})
+	})
+
+	Context("NewTaskCreate", func() {
+		It("returns successfully", func() {
+			taskCreate := authRefresh.NewTaskCreate()
+			Expect(taskCreate).ToNot(BeNil())
+			Expect(taskCreate.Name).ToNot(BeNil())
+			Expect(*taskCreate.Name).To(Equal(authRefresh.TaskName()))
+			Expect(taskCreate.Type).To(Equal(authRefresh.Type))
+			Expect(taskCreate.Data).To(BeEmpty())
+		})
+	})
+})
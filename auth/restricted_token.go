@@ -17,16 +17,58 @@ import (
 	"github.com/tidepool-org/platform/user"
 )
 
-const MaximumExpirationDuration = time.Hour
+const (
+	MaximumExpirationDuration = time.Hour
+	MaximumUsesMaximum        = 1000000
+)
 
 var pathExpression = regexp.MustCompile("^/.*$")
 
+func Methods() []string {
+	return []string{
+		http.MethodGet,
+		http.MethodHead,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+		http.MethodOptions,
+	}
+}
+
+func ReadOnlyMethods() []string {
+	return []string{
+		http.MethodGet,
+		http.MethodHead,
+		http.MethodOptions,
+	}
+}
+
+const (
+	RestrictionPaths        = "paths"
+	RestrictionMethods      = "methods"
+	RestrictionReadOnly     = "readOnly"
+	RestrictionTargetUserID = "targetUserId"
+	RestrictionMaximumUses  = "maximumUses"
+)
+
+func Restrictions() []string {
+	return []string{
+		RestrictionPaths,
+		RestrictionMethods,
+		RestrictionReadOnly,
+		RestrictionTargetUserID,
+		RestrictionMaximumUses,
+	}
+}
+
 type RestrictedTokenAccessor interface {
 	ListUserRestrictedTokens(ctx context.Context, userID string, filter *RestrictedTokenFilter, pagination *page.Pagination) (RestrictedTokens, error)
 	CreateUserRestrictedToken(ctx context.Context, userID string, create *RestrictedTokenCreate) (*RestrictedToken, error)
 	GetRestrictedToken(ctx context.Context, id string) (*RestrictedToken, error)
 	UpdateRestrictedToken(ctx context.Context, id string, update *RestrictedTokenUpdate) (*RestrictedToken, error)
 	DeleteRestrictedToken(ctx context.Context, id string) error
+	UseRestrictedToken(ctx context.Context, id string, use *RestrictedTokenUse) (*RestrictedToken, error)
 }
 
 type RestrictedTokenFilter struct{}
@@ -47,6 +89,10 @@ func (r *RestrictedTokenFilter) MutateRequest(req *http.Request) error {
 
 type RestrictedTokenCreate struct {
 	Paths          *[]string  `json:"paths,omitempty"`
+	Methods        *[]string  `json:"methods,omitempty"`
+	ReadOnly       *bool      `json:"readOnly,omitempty"`
+	TargetUserID   *string    `json:"targetUserId,omitempty"`
+	MaximumUses    *int       `json:"maximumUses,omitempty"`
 	ExpirationTime *time.Time `json:"expirationTime,omitempty"`
 }
 
@@ -58,11 +104,18 @@ func NewRestrictedTokenCreate() *RestrictedTokenCreate {
 
 func (r *RestrictedTokenCreate) Parse(parser structure.ObjectParser) {
 	r.Paths = parser.StringArray("paths")
+	r.Methods = parser.StringArray("methods")
+	r.ReadOnly = parser.Bool("readOnly")
+	r.TargetUserID = parser.String("targetUserId")
+	r.MaximumUses = parser.Int("maximumUses")
 	r.ExpirationTime = parser.Time("expirationTime", time.RFC3339)
 }
 
 func (r *RestrictedTokenCreate) Validate(validator structure.Validator) {
 	validator.StringArray("paths", r.Paths).LengthInRange(1, 10).EachMatches(pathExpression)
+	validator.StringArray("methods", r.Methods).NotEmpty().EachOneOf(Methods()...).EachUnique()
+	validator.String("targetUserId", r.TargetUserID).Using(user.IDValidator)
+	validator.Int("maximumUses", r.MaximumUses).InRange(1, MaximumUsesMaximum)
 	validator.Time("expirationTime", r.ExpirationTime).Before(time.Now().Add(MaximumExpirationDuration))
 }
 
@@ -72,9 +125,15 @@ func (r *RestrictedTokenCreate) Normalize(normalizer structure.Normalizer) {
 	}
 }
 
+// RestrictedTokenUpdate updates the specified restrictions and removes the restrictions named in clear
 type RestrictedTokenUpdate struct {
 	Paths          *[]string  `json:"paths,omitempty"`
+	Methods        *[]string  `json:"methods,omitempty"`
+	ReadOnly       *bool      `json:"readOnly,omitempty"`
+	TargetUserID   *string    `json:"targetUserId,omitempty"`
+	MaximumUses    *int       `json:"maximumUses,omitempty"`
 	ExpirationTime *time.Time `json:"expirationTime,omitempty"`
+	Clear          *[]string  `json:"clear,omitempty"`
 }
 
 func NewRestrictedTokenUpdate() *RestrictedTokenUpdate {
@@ -82,17 +141,47 @@ func NewRestrictedTokenUpdate() *RestrictedTokenUpdate {
 }
 
 func (r *RestrictedTokenUpdate) HasUpdates() bool {
-	return r.Paths != nil || r.ExpirationTime != nil
+	return r.Paths != nil || r.Methods != nil || r.ReadOnly != nil || r.TargetUserID != nil || r.MaximumUses != nil || r.ExpirationTime != nil || r.Clear != nil
+}
+
+func (r *RestrictedTokenUpdate) IsCleared(restriction string) bool {
+	return r.Clear != nil && containsString(*r.Clear, restriction)
 }
 
 func (r *RestrictedTokenUpdate) Parse(parser structure.ObjectParser) {
 	r.Paths = parser.StringArray("paths")
+	r.Methods = parser.StringArray("methods")
+	r.ReadOnly = parser.Bool("readOnly")
+	r.TargetUserID = parser.String("targetUserId")
+	r.MaximumUses = parser.Int("maximumUses")
 	r.ExpirationTime = parser.Time("expirationTime", time.RFC3339)
+	r.Clear = parser.StringArray("clear")
 }
 
 func (r *RestrictedTokenUpdate) Validate(validator structure.Validator) {
 	validator.StringArray("paths", r.Paths).LengthInRange(1, 10).EachMatches(pathExpression)
+	validator.StringArray("methods", r.Methods).NotEmpty().EachOneOf(Methods()...).EachUnique()
+	validator.String("targetUserId", r.TargetUserID).Using(user.IDValidator)
+	validator.Int("maximumUses", r.MaximumUses).InRange(1, MaximumUsesMaximum)
 	validator.Time("expirationTime", r.ExpirationTime).Before(time.Now().Add(MaximumExpirationDuration))
+	validator.StringArray("clear", r.Clear).NotEmpty().EachOneOf(Restrictions()...).EachUnique()
+
+	// A restriction may not be both updated and cleared
+	if r.IsCleared(RestrictionPaths) {
+		validator.StringArray("paths", r.Paths).NotExists()
+	}
+	if r.IsCleared(RestrictionMethods) {
+		validator.StringArray("methods", r.Methods).NotExists()
+	}
+	if r.IsCleared(RestrictionReadOnly) {
+		validator.Bool("readOnly", r.ReadOnly).NotExists()
+	}
+	if r.IsCleared(RestrictionTargetUserID) {
+		validator.String("targetUserId", r.TargetUserID).NotExists()
+	}
+	if r.IsCleared(RestrictionMaximumUses) {
+		validator.Int("maximumUses", r.MaximumUses).NotExists()
+	}
 }
 
 func (r *RestrictedTokenUpdate) Normalize(normalizer structure.Normalizer) {
@@ -128,13 +217,54 @@ func ErrorValueStringAsRestrictedTokenIDNotValid(value string) error {
 
 var restrictedTokenIDExpression = regexp.MustCompile("^[0-9a-z]{32}$")
 
+// RestrictedTokenUse is a single use of a restricted token for a request with the specified method and escaped path
+type RestrictedTokenUse struct {
+	Time      time.Time `json:"time"`
+	Method    *string   `json:"method,omitempty"`
+	Path      *string   `json:"path,omitempty"`
+	IPAddress *string   `json:"ipAddress,omitempty"`
+}
+
+func NewRestrictedTokenUse() *RestrictedTokenUse {
+	return &RestrictedTokenUse{
+		Time: time.Now().Truncate(time.Second),
+	}
+}
+
+func (r *RestrictedTokenUse) Parse(parser structure.ObjectParser) {
+	if ptr := parser.Time("time", time.RFC3339); ptr != nil {
+		r.Time = *ptr
+	}
+	r.Method = parser.String("method")
+	r.Path = parser.String("path")
+	r.IPAddress = parser.String("ipAddress")
+}
+
+func (r *RestrictedTokenUse) Validate(validator structure.Validator) {
+	validator.Time("time", &r.Time).NotZero().BeforeNow(time.Minute)
+	validator.String("method", r.Method).OneOf(Methods()...)
+	validator.String("path", r.Path).Matches(pathExpression)
+	validator.String("ipAddress", r.IPAddress).NotEmpty().LengthLessThanOrEqualTo(45)
+}
+
+func (r *RestrictedTokenUse) Normalize(normalizer structure.Normalizer) {
+	r.Time = r.Time.Truncate(time.Second)
+}
+
 type RestrictedToken struct {
-	ID             string     `json:"id" bson:"id"`
-	UserID         string     `json:"userId" bson:"userId"`
-	Paths          *[]string  `json:"paths,omitempty" bson:"paths,omitempty"`
-	ExpirationTime time.Time  `json:"expirationTime" bson:"expirationTime"`
-	CreatedTime    time.Time  `json:"createdTime" bson:"createdTime"`
-	ModifiedTime   *time.Time `json:"modifiedTime,omitempty" bson:"modifiedTime,omitempty"`
+	ID              string     `json:"id" bson:"id"`
+	UserID          string     `json:"userId" bson:"userId"`
+	Paths           *[]string  `json:"paths,omitempty" bson:"paths,omitempty"`
+	Methods         *[]string  `json:"methods,omitempty" bson:"methods,omitempty"`
+	ReadOnly        *bool      `json:"readOnly,omitempty" bson:"readOnly,omitempty"`
+	TargetUserID    *string    `json:"targetUserId,omitempty" bson:"targetUserId,omitempty"`
+	MaximumUses     *int       `json:"maximumUses,omitempty" bson:"maximumUses,omitempty"`
+	UsageCount      int        `json:"usageCount" bson:"usageCount"`
+	LastUsedTime    *time.Time `json:"lastUsedTime,omitempty" bson:"lastUsedTime,omitempty"`
+	LastUsedAddress *string    `json:"lastUsedAddress,omitempty" bson:"lastUsedAddress,omitempty"`
+	ExpirationTime  time.Time  `json:"expirationTime" bson:"expirationTime"`
+	CreatedTime     time.Time  `json:"createdTime" bson:"createdTime"`
+	ModifiedTime    *time.Time `json:"modifiedTime,omitempty" bson:"modifiedTime,omitempty"`
 }
 
 func NewRestrictedToken(userID string, create *RestrictedTokenCreate) (*RestrictedToken, error) {
@@ -148,10 +278,14 @@ func NewRestrictedToken(userID string, create *RestrictedTokenCreate) (*Restrict
 	}
 
 	restrictedToken := &RestrictedToken{
-		ID:          NewRestrictedTokenID(),
-		UserID:      userID,
-		Paths:       create.Paths,
-		CreatedTime: time.Now().Truncate(time.Second),
+		ID:           NewRestrictedTokenID(),
+		UserID:       userID,
+		Paths:        create.Paths,
+		Methods:      create.Methods,
+		ReadOnly:     create.ReadOnly,
+		TargetUserID: create.TargetUserID,
+		MaximumUses:  create.MaximumUses,
+		CreatedTime:  time.Now().Truncate(time.Second),
 	}
 	if create.ExpirationTime != nil {
 		restrictedToken.ExpirationTime = (*create.ExpirationTime).Truncate(time.Second)
@@ -170,6 +304,15 @@ func (r *RestrictedToken) Parse(parser structure.ObjectParser) {
 		r.UserID = *ptr
 	}
 	r.Paths = parser.StringArray("paths")
+	r.Methods = parser.StringArray("methods")
+	r.ReadOnly = parser.Bool("readOnly")
+	r.TargetUserID = parser.String("targetUserId")
+	r.MaximumUses = parser.Int("maximumUses")
+	if ptr := parser.Int("usageCount"); ptr != nil {
+		r.UsageCount = *ptr
+	}
+	r.LastUsedTime = parser.Time("lastUsedTime", time.RFC3339)
+	r.LastUsedAddress = parser.String("lastUsedAddress")
 	if ptr := parser.Time("expirationTime", time.RFC3339); ptr != nil {
 		r.ExpirationTime = *ptr
 	}
@@ -183,6 +326,12 @@ func (r *RestrictedToken) Validate(validator structure.Validator) {
 	validator.String("id", &r.ID).Using(RestrictedTokenIDValidator)
 	validator.String("userId", &r.UserID).Using(user.IDValidator)
 	validator.StringArray("paths", r.Paths).LengthInRange(1, 10).EachMatches(pathExpression)
+	validator.StringArray("methods", r.Methods).NotEmpty().EachOneOf(Methods()...).EachUnique()
+	validator.String("targetUserId", r.TargetUserID).Using(user.IDValidator)
+	validator.Int("maximumUses", r.MaximumUses).InRange(1, MaximumUsesMaximum)
+	validator.Int("usageCount", &r.UsageCount).GreaterThanOrEqualTo(0)
+	validator.Time("lastUsedTime", r.LastUsedTime).After(r.CreatedTime).BeforeNow(time.Minute)
+	validator.String("lastUsedAddress", r.LastUsedAddress).NotEmpty()
 	validator.Time("expirationTime", &r.ExpirationTime).Before(time.Now().Add(MaximumExpirationDuration))
 	validator.Time("createdTime", &r.CreatedTime).NotZero().BeforeNow(time.Second)
 	validator.Time("modifiedTime", r.ModifiedTime).After(r.CreatedTime).BeforeNow(time.Second)
@@ -192,11 +341,31 @@ func (r *RestrictedToken) Authenticates(req *http.Request) bool {
 	if req == nil || req.URL == nil {
 		return false
 	}
+	return r.authenticates(req.Method, req.URL.EscapedPath())
+}
+
+// AuthenticatesUse reports whether the restricted token authenticates the request described by the use
+func (r *RestrictedToken) AuthenticatesUse(use *RestrictedTokenUse) bool {
+	if use == nil {
+		return false
+	}
+	return r.authenticates(pointer.ToString(use.Method), pointer.ToString(use.Path))
+}
+
+func (r *RestrictedToken) authenticates(method string, escapedPath string) bool {
 	if time.Now().After(r.ExpirationTime) {
 		return false
 	}
+	if r.IsExhausted() {
+		return false
+	}
+	if !r.authenticatesMethod(method) {
+		return false
+	}
+	if r.TargetUserID != nil && !r.authenticatesTargetUserID(escapedPath) {
+		return false
+	}
 	if r.Paths != nil {
-		escapedPath := req.URL.EscapedPath()
 		for _, path := range *r.Paths {
 			if path == escapedPath || strings.HasPrefix(escapedPath, strings.TrimSuffix(path, "/")+"/") {
 				return true
@@ -207,6 +376,34 @@ func (r *RestrictedToken) Authenticates(req *http.Request) bool {
 	return true
 }
 
+func (r *RestrictedToken) IsExhausted() bool {
+	return r.MaximumUses != nil && r.UsageCount >= *r.MaximumUses
+}
+
+func (r *RestrictedToken) authenticatesMethod(method string) bool {
+	if method == "" {
+		method = http.MethodGet
+	}
+	if r.ReadOnly != nil && *r.ReadOnly && !containsString(ReadOnlyMethods(), method) {
+		return false
+	}
+	if r.Methods != nil && !containsString(*r.Methods, method) {
+		return false
+	}
+	return true
+}
+
+// The target user must appear as a complete segment of the request path, so requests for resources
+// not addressed by user (e.g. /v1/blobs/:id) are rejected
+func (r *RestrictedToken) authenticatesTargetUserID(escapedPath string) bool {
+	for _, segment := range strings.Split(escapedPath, "/") {
+		if segment == *r.TargetUserID {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *RestrictedToken) Sanitize(details request.Details) error {
 	if details != nil && (details.IsService() || details.UserID() == r.UserID) {
 		return nil
@@ -224,3 +421,12 @@ func (r RestrictedTokens) Sanitize(details request.Details) error {
 	}
 	return nil
 }
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
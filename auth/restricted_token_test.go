@@ -5,14 +5,328 @@ import (
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"time"
+
 	"github.com/tidepool-org/platform/auth"
 	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/pointer"
+	serviceTest "github.com/tidepool-org/platform/service/test"
 	structureTest "github.com/tidepool-org/platform/structure/test"
 	structureValidator "github.com/tidepool-org/platform/structure/validator"
 	"github.com/tidepool-org/platform/test"
+	"github.com/tidepool-org/platform/user"
 )
 
 var _ = Describe("RestrictedToken", func() {
+	It("MaximumUsesMaximum is expected", func() {
+		Expect(auth.MaximumUsesMaximum).To(Equal(1000000))
+	})
+
+	It("Methods returns expected", func() {
+		Expect(auth.Methods()).To(Equal([]string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}))
+	})
+
+	It("ReadOnlyMethods returns expected", func() {
+		Expect(auth.ReadOnlyMethods()).To(Equal([]string{"GET", "HEAD", "OPTIONS"}))
+	})
+
+	Context("RestrictedTokenCreate", func() {
+		Context("Validate", func() {
+			DescribeTable("validates the restricted token create",
+				func(mutator func(create *auth.RestrictedTokenCreate), expectedErrors ...error) {
+					create := auth.NewRestrictedTokenCreate()
+					mutator(create)
+					errorsTest.ExpectEqual(structureValidator.New().Validate(create), expectedErrors...)
+				},
+				Entry("succeeds",
+					func(create *auth.RestrictedTokenCreate) {},
+				),
+				Entry("methods valid",
+					func(create *auth.RestrictedTokenCreate) {
+						create.Methods = pointer.FromStringArray([]string{"GET", "DELETE"})
+					},
+				),
+				Entry("methods empty",
+					func(create *auth.RestrictedTokenCreate) { create.Methods = pointer.FromStringArray([]string{}) },
+					errorsTest.WithPointerSource(structureValidator.ErrorValueEmpty(), "/methods"),
+				),
+				Entry("methods element invalid",
+					func(create *auth.RestrictedTokenCreate) {
+						create.Methods = pointer.FromStringArray([]string{"GET", "TRACE"})
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("TRACE", auth.Methods()), "/methods/1"),
+				),
+				Entry("methods element duplicate",
+					func(create *auth.RestrictedTokenCreate) {
+						create.Methods = pointer.FromStringArray([]string{"GET", "GET"})
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueDuplicate(), "/methods/1"),
+				),
+				Entry("target user id invalid",
+					func(create *auth.RestrictedTokenCreate) { create.TargetUserID = pointer.FromString("invalid") },
+					errorsTest.WithPointerSource(user.ErrorValueStringAsIDNotValid("invalid"), "/targetUserId"),
+				),
+				Entry("target user id valid",
+					func(create *auth.RestrictedTokenCreate) {
+						create.TargetUserID = pointer.FromString(serviceTest.NewUserID())
+					},
+				),
+				Entry("maximum uses out of range (lower)",
+					func(create *auth.RestrictedTokenCreate) { create.MaximumUses = pointer.FromInt(0) },
+					errorsTest.WithPointerSource(structureValidator.ErrorValueNotInRange(0, 1, auth.MaximumUsesMaximum), "/maximumUses"),
+				),
+				Entry("maximum uses in range",
+					func(create *auth.RestrictedTokenCreate) { create.MaximumUses = pointer.FromInt(1) },
+				),
+				Entry("maximum uses out of range (upper)",
+					func(create *auth.RestrictedTokenCreate) {
+						create.MaximumUses = pointer.FromInt(auth.MaximumUsesMaximum + 1)
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueNotInRange(auth.MaximumUsesMaximum+1, 1, auth.MaximumUsesMaximum), "/maximumUses"),
+				),
+			)
+		})
+	})
+
+	Context("RestrictedTokenUpdate", func() {
+		Context("HasUpdates", func() {
+			It("returns false if there are no updates", func() {
+				Expect(auth.NewRestrictedTokenUpdate().HasUpdates()).To(BeFalse())
+			})
+
+			DescribeTable("returns true if",
+				func(mutator func(update *auth.RestrictedTokenUpdate)) {
+					update := auth.NewRestrictedTokenUpdate()
+					mutator(update)
+					Expect(update.HasUpdates()).To(BeTrue())
+				},
+				Entry("paths is set", func(update *auth.RestrictedTokenUpdate) { update.Paths = pointer.FromStringArray([]string{"/"}) }),
+				Entry("methods is set", func(update *auth.RestrictedTokenUpdate) { update.Methods = pointer.FromStringArray([]string{"GET"}) }),
+				Entry("read only is set", func(update *auth.RestrictedTokenUpdate) { update.ReadOnly = pointer.FromBool(true) }),
+				Entry("target user id is set", func(update *auth.RestrictedTokenUpdate) {
+					update.TargetUserID = pointer.FromString(serviceTest.NewUserID())
+				}),
+				Entry("maximum uses is set", func(update *auth.RestrictedTokenUpdate) { update.MaximumUses = pointer.FromInt(1) }),
+				Entry("expiration time is set", func(update *auth.RestrictedTokenUpdate) { update.ExpirationTime = pointer.FromTime(time.Now()) }),
+				Entry("clear is set", func(update *auth.RestrictedTokenUpdate) { update.Clear = pointer.FromStringArray([]string{"paths"}) }),
+			)
+		})
+
+		Context("Validate", func() {
+			It("returns successfully if restrictions are cleared", func() {
+				update := auth.NewRestrictedTokenUpdate()
+				update.Clear = pointer.FromStringArray(auth.Restrictions())
+				Expect(structureValidator.New().Validate(update)).ToNot(HaveOccurred())
+			})
+
+			It("returns an error if the cleared restriction is not known", func() {
+				update := auth.NewRestrictedTokenUpdate()
+				update.Clear = pointer.FromStringArray([]string{"expirationTime"})
+				errorsTest.ExpectEqual(structureValidator.New().Validate(update), errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("expirationTime", auth.Restrictions()), "/clear/0"))
+			})
+
+			DescribeTable("returns an error if a restriction is both updated and cleared",
+				func(mutator func(update *auth.RestrictedTokenUpdate), restriction string) {
+					update := auth.NewRestrictedTokenUpdate()
+					mutator(update)
+					update.Clear = pointer.FromStringArray([]string{restriction})
+					errorsTest.ExpectEqual(structureValidator.New().Validate(update), errorsTest.WithPointerSource(structureValidator.ErrorValueExists(), "/"+restriction))
+				},
+				Entry("paths", func(update *auth.RestrictedTokenUpdate) { update.Paths = pointer.FromStringArray([]string{"/"}) }, "paths"),
+				Entry("methods", func(update *auth.RestrictedTokenUpdate) { update.Methods = pointer.FromStringArray([]string{"GET"}) }, "methods"),
+				Entry("read only", func(update *auth.RestrictedTokenUpdate) { update.ReadOnly = pointer.FromBool(true) }, "readOnly"),
+				Entry("target user id", func(update *auth.RestrictedTokenUpdate) {
+					update.TargetUserID = pointer.FromString(serviceTest.NewUserID())
+				}, "targetUserId"),
+				Entry("maximum uses", func(update *auth.RestrictedTokenUpdate) { update.MaximumUses = pointer.FromInt(1) }, "maximumUses"),
+			)
+		})
+	})
+
+	Context("RestrictedTokenUse", func() {
+		Context("NewRestrictedTokenUse", func() {
+			It("returns successfully with the current time", func() {
+				use := auth.NewRestrictedTokenUse()
+				Expect(use).ToNot(BeNil())
+				Expect(use.Time).To(BeTemporally("~", time.Now(), time.Second))
+				Expect(use.IPAddress).To(BeNil())
+			})
+		})
+
+		Context("Validate", func() {
+			It("returns successfully", func() {
+				use := auth.NewRestrictedTokenUse()
+				use.IPAddress = pointer.FromString("127.0.0.1")
+				Expect(structureValidator.New().Validate(use)).ToNot(HaveOccurred())
+			})
+
+			It("returns an error if the time is zero", func() {
+				use := auth.NewRestrictedTokenUse()
+				use.Time = time.Time{}
+				errorsTest.ExpectEqual(structureValidator.New().Validate(use), errorsTest.WithPointerSource(structureValidator.ErrorValueEmpty(), "/time"))
+			})
+
+			It("returns an error if the method is not valid", func() {
+				use := auth.NewRestrictedTokenUse()
+				use.Method = pointer.FromString("TRACE")
+				errorsTest.ExpectEqual(structureValidator.New().Validate(use), errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("TRACE", auth.Methods()), "/method"))
+			})
+
+			It("returns an error if the path is not valid", func() {
+				use := auth.NewRestrictedTokenUse()
+				use.Path = pointer.FromString("v1/blobs")
+				errorsTest.ExpectEqual(structureValidator.New().Validate(use), errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotMatches("v1/blobs", regexp.MustCompile("^/.*$")), "/path"))
+			})
+
+			It("returns an error if the ip address is empty", func() {
+				use := auth.NewRestrictedTokenUse()
+				use.IPAddress = pointer.FromString("")
+				errorsTest.ExpectEqual(structureValidator.New().Validate(use), errorsTest.WithPointerSource(structureValidator.ErrorValueEmpty(), "/ipAddress"))
+			})
+		})
+	})
+
+	Context("RestrictedToken", func() {
+		Context("NewRestrictedToken", func() {
+			It("returns successfully with the restrictions from create", func() {
+				userID := serviceTest.NewUserID()
+				targetUserID := serviceTest.NewUserID()
+				create := auth.NewRestrictedTokenCreate()
+				create.Paths = pointer.FromStringArray([]string{"/v1/users"})
+				create.Methods = pointer.FromStringArray([]string{"GET"})
+				create.ReadOnly = pointer.FromBool(true)
+				create.TargetUserID = pointer.FromString(targetUserID)
+				create.MaximumUses = pointer.FromInt(3)
+				restrictedToken, err := auth.NewRestrictedToken(userID, create)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(restrictedToken).ToNot(BeNil())
+				Expect(restrictedToken.UserID).To(Equal(userID))
+				Expect(restrictedToken.Paths).To(Equal(create.Paths))
+				Expect(restrictedToken.Methods).To(Equal(create.Methods))
+				Expect(restrictedToken.ReadOnly).To(Equal(create.ReadOnly))
+				Expect(restrictedToken.TargetUserID).To(Equal(create.TargetUserID))
+				Expect(restrictedToken.MaximumUses).To(Equal(create.MaximumUses))
+				Expect(restrictedToken.UsageCount).To(Equal(0))
+				Expect(restrictedToken.LastUsedTime).To(BeNil())
+				Expect(restrictedToken.LastUsedAddress).To(BeNil())
+			})
+		})
+
+		Context("IsExhausted", func() {
+			It("returns false if maximum uses is not set", func() {
+				Expect((&auth.RestrictedToken{UsageCount: 1000}).IsExhausted()).To(BeFalse())
+			})
+
+			It("returns false if usage count is less than maximum uses", func() {
+				Expect((&auth.RestrictedToken{MaximumUses: pointer.FromInt(2), UsageCount: 1}).IsExhausted()).To(BeFalse())
+			})
+
+			It("returns true if usage count equals maximum uses", func() {
+				Expect((&auth.RestrictedToken{MaximumUses: pointer.FromInt(2), UsageCount: 2}).IsExhausted()).To(BeTrue())
+			})
+		})
+
+		Context("Authenticates", func() {
+			var targetUserID string
+			var restrictedToken *auth.RestrictedToken
+
+			BeforeEach(func() {
+				targetUserID = serviceTest.NewUserID()
+				restrictedToken = &auth.RestrictedToken{
+					ID:             auth.NewRestrictedTokenID(),
+					UserID:         serviceTest.NewUserID(),
+					ExpirationTime: time.Now().Add(time.Hour),
+				}
+			})
+
+			It("returns false if the request is missing", func() {
+				Expect(restrictedToken.Authenticates(nil)).To(BeFalse())
+			})
+
+			It("returns false if the use is missing", func() {
+				Expect(restrictedToken.AuthenticatesUse(nil)).To(BeFalse())
+			})
+
+			DescribeTable("returns expected result when",
+				func(mutator func(restrictedToken *auth.RestrictedToken), method string, path string, expected bool) {
+					mutator(restrictedToken)
+					req := httptest.NewRequest(method, strings.Replace(path, "{targetUserId}", targetUserID, -1), nil)
+					Expect(restrictedToken.Authenticates(req)).To(Equal(expected))
+					use := auth.NewRestrictedTokenUse()
+					use.Method = pointer.FromString(req.Method)
+					use.Path = pointer.FromString(req.URL.EscapedPath())
+					Expect(restrictedToken.AuthenticatesUse(use)).To(Equal(expected))
+				},
+				Entry("unrestricted",
+					func(restrictedToken *auth.RestrictedToken) {}, "DELETE", "/v1/blobs/123", true),
+				Entry("expired",
+					func(restrictedToken *auth.RestrictedToken) {
+						restrictedToken.ExpirationTime = time.Now().Add(-time.Second)
+					}, "GET", "/v1/blobs", false),
+				Entry("exhausted",
+					func(restrictedToken *auth.RestrictedToken) {
+						restrictedToken.MaximumUses = pointer.FromInt(1)
+						restrictedToken.UsageCount = 1
+					}, "GET", "/v1/blobs", false),
+				Entry("not exhausted",
+					func(restrictedToken *auth.RestrictedToken) {
+						restrictedToken.MaximumUses = pointer.FromInt(2)
+						restrictedToken.UsageCount = 1
+					}, "GET", "/v1/blobs", true),
+				Entry("path matches",
+					func(restrictedToken *auth.RestrictedToken) {
+						restrictedToken.Paths = pointer.FromStringArray([]string{"/v1/blobs"})
+					}, "GET", "/v1/blobs/123", true),
+				Entry("path does not match",
+					func(restrictedToken *auth.RestrictedToken) {
+						restrictedToken.Paths = pointer.FromStringArray([]string{"/v1/blobs"})
+					}, "GET", "/v1/blobsx", false),
+				Entry("method allowed",
+					func(restrictedToken *auth.RestrictedToken) {
+						restrictedToken.Methods = pointer.FromStringArray([]string{"GET", "POST"})
+					}, "POST", "/v1/blobs", true),
+				Entry("method not allowed",
+					func(restrictedToken *auth.RestrictedToken) {
+						restrictedToken.Methods = pointer.FromStringArray([]string{"GET", "POST"})
+					}, "DELETE", "/v1/blobs/123", false),
+				Entry("read only with read method",
+					func(restrictedToken *auth.RestrictedToken) { restrictedToken.ReadOnly = pointer.FromBool(true) }, "HEAD", "/v1/blobs/123", true),
+				Entry("read only with write method",
+					func(restrictedToken *auth.RestrictedToken) { restrictedToken.ReadOnly = pointer.FromBool(true) }, "PUT", "/v1/blobs/123", false),
+				Entry("read only with write method allowed by methods",
+					func(restrictedToken *auth.RestrictedToken) {
+						restrictedToken.ReadOnly = pointer.FromBool(true)
+						restrictedToken.Methods = pointer.FromStringArray([]string{"GET", "PUT"})
+					}, "PUT", "/v1/blobs/123", false),
+				Entry("not read only with write method",
+					func(restrictedToken *auth.RestrictedToken) { restrictedToken.ReadOnly = pointer.FromBool(false) }, "PUT", "/v1/blobs/123", true),
+				Entry("target user in path",
+					func(restrictedToken *auth.RestrictedToken) {
+						restrictedToken.TargetUserID = pointer.FromString(targetUserID)
+					}, "GET", "/v1/users/{targetUserId}/blobs", true),
+				Entry("target user at end of path",
+					func(restrictedToken *auth.RestrictedToken) {
+						restrictedToken.TargetUserID = pointer.FromString(targetUserID)
+					}, "GET", "/v1/users/{targetUserId}", true),
+				Entry("target user not in path",
+					func(restrictedToken *auth.RestrictedToken) {
+						restrictedToken.TargetUserID = pointer.FromString(targetUserID)
+					}, "GET", "/v1/users/0123456789/blobs", false),
+				Entry("target user as partial segment of path",
+					func(restrictedToken *auth.RestrictedToken) {
+						restrictedToken.TargetUserID = pointer.FromString(targetUserID)
+					}, "GET", "/v1/users/{targetUserId}0/blobs", false),
+				Entry("target user with resource not addressed by user",
+					func(restrictedToken *auth.RestrictedToken) {
+						restrictedToken.TargetUserID = pointer.FromString(targetUserID)
+					}, "GET", "/v1/blobs/123", false),
+			)
+		})
+	})
+
 	Context("NewRestrictedTokenID", func() {
 		It("returns a string of 32 lowercase hexidecimal characters", func() {
 			Expect(auth.NewRestrictedTokenID()).To(MatchRegexp("^[0-9a-f]{32}$"))
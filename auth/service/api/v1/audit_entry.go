@@ -0,0 +1,47 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/service/api"
+)
+
+func (r *Router) AuditEntriesRoutes() []*rest.Route {
+	return []*rest.Route{
+		rest.Get("/v1/users/:userId/audit_entries", api.RequireServer(r.ListUserAuditEntries)),
+	}
+}
+
+// Lists audit entries where the user is either the actor or the target, most recent first
+func (r *Router) ListUserAuditEntries(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	userID := req.PathParam("userId")
+	if userID == "" {
+		responder.Error(http.StatusBadRequest, request.ErrorParameterMissing("userId"))
+		return
+	}
+
+	filter := auth.NewAuditEntryFilter()
+	pagination := page.NewPagination()
+	if err := request.DecodeRequestQuery(req.Request, filter, pagination); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	ssn := r.AuthStore().NewAuditEntrySession()
+	defer ssn.Close()
+
+	auditEntries, err := ssn.ListUserAuditEntries(req.Context(), userID, filter, pagination)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Data(http.StatusOK, auditEntries)
+}
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -9,6 +10,8 @@ import (
 	"github.com/ant0ine/go-json-rest/rest"
 
 	"github.com/tidepool-org/platform/auth"
+	authStore "github.com/tidepool-org/platform/auth/store"
+	"github.com/tidepool-org/platform/data"
 	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/log"
 	"github.com/tidepool-org/platform/oauth"
@@ -45,20 +48,55 @@ func (r *Router) OAuthProviderAuthorizeGet(res rest.ResponseWriter, req *rest.Re
 
 	restrictedToken, err := r.AuthClient().GetRestrictedToken(ctx, details.Token())
 	if err != nil {
+		r.createOAuthProviderAuditEntry(ctx, auth.AuditEventOAuthProviderAuthorize, auth.AuditOutcomeFailure, details.UserID(), prvdr, err)
 		r.htmlOnError(res, req, err)
 		return
 	}
 
 	maxAge := restrictedToken.ExpirationTime.Sub(time.Now()) / time.Second
 	if maxAge <= 0 {
+		r.createOAuthProviderAuditEntry(ctx, auth.AuditEventOAuthProviderAuthorize, auth.AuditOutcomeDenied, restrictedToken.UserID, prvdr, nil)
 		r.htmlOnError(res, req, request.ErrorUnauthenticated())
 		return
 	}
 
+	if err = r.deleteErroredProviderSessions(ctx, restrictedToken.UserID, prvdr); err != nil {
+		r.htmlOnError(res, req, err)
+		return
+	}
+
+	if err = r.createOAuthProviderAuditEntry(ctx, auth.AuditEventOAuthProviderAuthorize, auth.AuditOutcomeSuccess, restrictedToken.UserID, prvdr, nil); err != nil {
+		r.htmlOnError(res, req, err)
+		return
+	}
+
 	responder.SetCookie(r.providerCookie(prvdr, details.Token(), int(maxAge)))
 	responder.Redirect(http.StatusTemporaryRedirect, prvdr.GetAuthorizationCodeURLWithState(prvdr.CalculateStateForRestrictedToken(details.Token())))
 }
 
+// An errored provider session would otherwise reject the redirect as already connected, so delete it to allow reconnecting
+func (r *Router) deleteErroredProviderSessions(ctx context.Context, userID string, prvdr oauth.Provider) error {
+	filter := data.NewDataSourceFilter()
+	filter.ProviderType = pointer.FromString(prvdr.Type())
+	filter.ProviderName = pointer.FromString(prvdr.Name())
+	filter.State = pointer.FromString(data.DataSourceStateError)
+	dataSources, err := r.DataClient().ListUserDataSources(ctx, userID, filter, nil)
+	if err != nil {
+		return errors.Wrap(err, "unable to list user data sources")
+	}
+
+	for _, dataSource := range dataSources {
+		if dataSource.ProviderSessionID == nil {
+			continue
+		}
+		if err = r.AuthClient().DeleteProviderSession(ctx, *dataSource.ProviderSessionID); err != nil {
+			return errors.Wrap(err, "unable to delete provider session")
+		}
+	}
+
+	return nil
+}
+
 func (r *Router) OAuthProviderAuthorizeDelete(res rest.ResponseWriter, req *rest.Request) {
 	responder := request.MustNewResponder(res, req)
 	ctx := req.Context()
@@ -117,10 +155,13 @@ func (r *Router) OAuthProviderRedirectGet(res rest.ResponseWriter, req *rest.Req
 	}
 
 	if errorCode := query.Get("error"); errorCode == oauth.ErrorAccessDenied {
+		r.createOAuthProviderAuditEntry(ctx, auth.AuditEventOAuthProviderRedirect, auth.AuditOutcomeDenied, restrictedToken.UserID, prvdr, nil)
 		r.htmlOnRedirect(res, req)
 		return
 	} else if errorCode != "" {
-		r.htmlOnError(res, req, errors.Newf("oauth provider return unexpected error %q", errorCode))
+		err = errors.Newf("oauth provider return unexpected error %q", errorCode)
+		r.createOAuthProviderAuditEntry(ctx, auth.AuditEventOAuthProviderRedirect, auth.AuditOutcomeFailure, restrictedToken.UserID, prvdr, err)
+		r.htmlOnError(res, req, err)
 		return
 	}
 
@@ -129,15 +170,19 @@ func (r *Router) OAuthProviderRedirectGet(res rest.ResponseWriter, req *rest.Req
 	filter.Name = pointer.FromString(prvdr.Name())
 	providerSessions, err := r.AuthClient().ListUserProviderSessions(ctx, restrictedToken.UserID, filter, nil)
 	if err != nil {
+		r.createOAuthProviderAuditEntry(ctx, auth.AuditEventOAuthProviderRedirect, auth.AuditOutcomeFailure, restrictedToken.UserID, prvdr, err)
 		r.htmlOnError(res, req, err)
 		return
 	} else if len(providerSessions) > 0 {
-		r.htmlOnError(res, req, errors.Newf("provider session already exists for user, type, and name"), alreadyConnectedError)
+		err = errors.Newf("provider session already exists for user, type, and name")
+		r.createOAuthProviderAuditEntry(ctx, auth.AuditEventOAuthProviderRedirect, auth.AuditOutcomeDenied, restrictedToken.UserID, prvdr, err)
+		r.htmlOnError(res, req, err, alreadyConnectedError)
 		return
 	}
 
 	oauthToken, err := prvdr.ExchangeAuthorizationCodeForToken(ctx, query.Get("code"))
 	if err != nil {
+		r.createOAuthProviderAuditEntry(ctx, auth.AuditEventOAuthProviderRedirect, auth.AuditOutcomeFailure, restrictedToken.UserID, prvdr, err)
 		r.htmlOnError(res, req, err)
 		return
 	}
@@ -148,6 +193,12 @@ func (r *Router) OAuthProviderRedirectGet(res rest.ResponseWriter, req *rest.Req
 	providerSessionCreate.OAuthToken = oauthToken
 	_, err = r.AuthClient().CreateUserProviderSession(ctx, restrictedToken.UserID, providerSessionCreate)
 	if err != nil {
+		r.createOAuthProviderAuditEntry(ctx, auth.AuditEventOAuthProviderRedirect, auth.AuditOutcomeFailure, restrictedToken.UserID, prvdr, err)
+		r.htmlOnError(res, req, err)
+		return
+	}
+
+	if err = r.createOAuthProviderAuditEntry(ctx, auth.AuditEventOAuthProviderRedirect, auth.AuditOutcomeSuccess, restrictedToken.UserID, prvdr, nil); err != nil {
 		r.htmlOnError(res, req, err)
 		return
 	}
@@ -155,6 +206,12 @@ func (r *Router) OAuthProviderRedirectGet(res rest.ResponseWriter, req *rest.Req
 	r.htmlOnRedirect(res, req)
 }
 
+func (r *Router) createOAuthProviderAuditEntry(ctx context.Context, event string, outcome string, userID string, prvdr oauth.Provider, err error) error {
+	auditEntry := auth.NewAuditEntry(ctx, event, outcome).WithTarget(userID, "", "").WithError(err)
+	auditEntry.ProviderName = pointer.FromString(prvdr.Name())
+	return authStore.CreateAuditEntry(ctx, r.AuthStore(), auditEntry)
+}
+
 func (r *Router) oauthProvider(req *rest.Request) (oauth.Provider, error) {
 	name := req.PathParams["name"]
 	if name == "" {
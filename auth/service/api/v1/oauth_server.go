@@ -0,0 +1,439 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"github.com/tidepool-org/platform/auth"
+	authStore "github.com/tidepool-org/platform/auth/store"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/oauth"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/service/api"
+)
+
+func (r *Router) OAuthServerRoutes() []*rest.Route {
+	return []*rest.Route{
+		rest.Post("/v1/oauth/clients", api.RequireServer(r.CreateOAuthClient)),
+		rest.Get("/v1/oauth/clients/:id", api.RequireServer(r.GetOAuthClient)),
+		rest.Delete("/v1/oauth/clients/:id", api.RequireServer(r.DeleteOAuthClient)),
+		rest.Post("/v1/oauth/authorize", api.RequireUser(r.OAuthAuthorize)),
+		rest.Post("/v1/oauth/token", r.OAuthToken),
+		rest.Post("/v1/oauth/revoke", r.OAuthRevoke),
+		rest.Post("/v1/oauth/access_tokens/validate", api.RequireServer(r.ValidateOAuthAccessToken)),
+		rest.Get("/v1/users/:userId/oauth/grants", api.RequireServer(r.ListUserOAuthGrants)),
+		rest.Delete("/v1/users/:userId/oauth/grants", api.RequireServer(r.DeleteUserOAuthGrants)),
+	}
+}
+
+func (r *Router) CreateOAuthClient(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	create := auth.NewOAuthClientCreate()
+	if err := request.DecodeRequestBody(req.Request, create); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	oauthClient, err := r.AuthClient().CreateOAuthClient(req.Context(), create)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Data(http.StatusCreated, oauthClient)
+}
+
+func (r *Router) GetOAuthClient(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	id := req.PathParam("id")
+	if id == "" {
+		responder.Error(http.StatusBadRequest, request.ErrorParameterMissing("id"))
+		return
+	}
+
+	oauthClient, err := r.AuthClient().GetOAuthClient(req.Context(), id)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	} else if oauthClient == nil {
+		responder.Error(http.StatusNotFound, request.ErrorResourceNotFoundWithID(id))
+		return
+	}
+
+	responder.Data(http.StatusOK, oauthClient)
+}
+
+func (r *Router) DeleteOAuthClient(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	id := req.PathParam("id")
+	if id == "" {
+		responder.Error(http.StatusBadRequest, request.ErrorParameterMissing("id"))
+		return
+	}
+
+	if err := r.AuthClient().DeleteOAuthClient(req.Context(), id); err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Empty(http.StatusOK)
+}
+
+func (r *Router) ListUserOAuthGrants(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	userID := req.PathParam("userId")
+	if userID == "" {
+		responder.Error(http.StatusBadRequest, request.ErrorParameterMissing("userId"))
+		return
+	}
+
+	oauthTokens, err := r.AuthClient().ListUserOAuthGrants(req.Context(), userID)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Data(http.StatusOK, oauthTokens)
+}
+
+func (r *Router) DeleteUserOAuthGrants(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	userID := req.PathParam("userId")
+	if userID == "" {
+		responder.Error(http.StatusBadRequest, request.ErrorParameterMissing("userId"))
+		return
+	}
+
+	if err := r.AuthClient().DeleteUserOAuthGrants(req.Context(), userID); err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Empty(http.StatusOK)
+}
+
+type oauthAuthorizeResponse struct {
+	RedirectURI string `json:"redirectUri"`
+}
+
+// The user has already consented in the front end; respond with the client redirect rather than redirecting
+func (r *Router) OAuthAuthorize(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+	ctx := req.Context()
+	details := request.DetailsFromContext(ctx)
+
+	values, err := url.ParseQuery(req.URL.RawQuery)
+	if err != nil {
+		r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorInvalidRequest, "unable to parse query")
+		return
+	}
+
+	authorize := auth.NewOAuthAuthorize()
+	_ = request.ParseValuesObjects(values, authorize)
+
+	if !auth.IsValidOAuthClientID(authorize.ClientID) {
+		r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorInvalidClient, "client id is invalid")
+		return
+	}
+
+	oauthClient, err := r.AuthClient().GetOAuthClient(ctx, authorize.ClientID)
+	if err != nil {
+		log.LoggerFromContext(ctx).WithError(err).Error("Unable to get oauth client")
+		r.oauthErrorResponse(responder, http.StatusInternalServerError, oauth.ErrorServerError, "")
+		return
+	} else if oauthClient == nil {
+		r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorInvalidClient, "client not found")
+		return
+	} else if !oauthClient.HasRedirectURI(authorize.RedirectURI) {
+		r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorInvalidRequest, "redirect uri is invalid")
+		return
+	}
+
+	// Once the client and redirect uri are verified, all errors are returned to the client via the redirect uri
+	if err = request.DecodeValues(values, authorize); err != nil {
+		if authorize.ResponseType != oauth.ResponseTypeCode {
+			r.oauthAuthorizeRedirect(responder, authorize, map[string]string{"error": oauth.ErrorUnsupportedResponseType})
+		} else {
+			r.oauthAuthorizeRedirect(responder, authorize, map[string]string{"error": oauth.ErrorInvalidRequest})
+		}
+		return
+	}
+
+	if scopes := authorize.Scopes(); len(scopes) == 0 || !oauthClient.AllowsScopes(scopes) {
+		r.createOAuthClientAuditEntry(ctx, auth.AuditOutcomeDenied, details.UserID(), authorize, nil)
+		r.oauthAuthorizeRedirect(responder, authorize, map[string]string{"error": oauth.ErrorInvalidScope})
+		return
+	}
+
+	oauthAuthorizationCode, err := auth.NewOAuthAuthorizationCode(details.UserID(), authorize)
+	if err != nil {
+		log.LoggerFromContext(ctx).WithError(err).Error("Unable to create oauth authorization code")
+		r.createOAuthClientAuditEntry(ctx, auth.AuditOutcomeFailure, details.UserID(), authorize, err)
+		r.oauthAuthorizeRedirect(responder, authorize, map[string]string{"error": oauth.ErrorServerError})
+		return
+	}
+
+	ssn := r.AuthStore().NewOAuthAuthorizationCodeSession()
+	defer ssn.Close()
+
+	if err = ssn.CreateOAuthAuthorizationCode(ctx, oauthAuthorizationCode); err != nil {
+		log.LoggerFromContext(ctx).WithError(err).Error("Unable to create oauth authorization code")
+		r.createOAuthClientAuditEntry(ctx, auth.AuditOutcomeFailure, details.UserID(), authorize, err)
+		r.oauthAuthorizeRedirect(responder, authorize, map[string]string{"error": oauth.ErrorServerError})
+		return
+	}
+
+	// The authorization code is never returned, and so expires unused, if the authorization cannot be audited
+	if err = r.createOAuthClientAuditEntry(ctx, auth.AuditOutcomeSuccess, details.UserID(), authorize, nil); err != nil {
+		r.oauthAuthorizeRedirect(responder, authorize, map[string]string{"error": oauth.ErrorServerError})
+		return
+	}
+
+	r.oauthAuthorizeRedirect(responder, authorize, map[string]string{"code": oauthAuthorizationCode.Code})
+}
+
+func (r *Router) OAuthToken(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+	ctx := req.Context()
+
+	if err := req.ParseForm(); err != nil {
+		r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorInvalidRequest, "unable to parse form")
+		return
+	}
+
+	if grantType := req.PostForm.Get("grant_type"); grantType == "" {
+		r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorInvalidRequest, "grant type is missing")
+		return
+	} else if !auth.IsValidOAuthGrantType(grantType) {
+		r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorUnsupportedGrantType, "")
+		return
+	}
+
+	tokenRequest := auth.NewOAuthTokenRequest()
+	if err := request.DecodeValues(req.PostForm, tokenRequest); err != nil {
+		r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorInvalidRequest, "request is invalid")
+		return
+	}
+
+	oauthClient, ok := r.oauthAuthenticateClient(ctx, responder, req)
+	if !ok {
+		return
+	}
+
+	var scopes []string
+	var userID string
+	switch tokenRequest.GrantType {
+	case oauth.GrantTypeAuthorizationCode:
+		ssn := r.AuthStore().NewOAuthAuthorizationCodeSession()
+		defer ssn.Close()
+
+		// Verify the client and code verifier before consuming the code, so an invalid request cannot burn a valid code
+		oauthAuthorizationCode, err := ssn.GetOAuthAuthorizationCode(ctx, *tokenRequest.Code)
+		if err != nil {
+			log.LoggerFromContext(ctx).WithError(err).Error("Unable to get oauth authorization code")
+			r.oauthErrorResponse(responder, http.StatusInternalServerError, oauth.ErrorServerError, "")
+			return
+		} else if oauthAuthorizationCode == nil || oauthAuthorizationCode.IsExpired() || oauthAuthorizationCode.ClientID != oauthClient.ID ||
+			oauthAuthorizationCode.RedirectURI != *tokenRequest.RedirectURI || !oauthAuthorizationCode.VerifyCodeVerifier(*tokenRequest.CodeVerifier) {
+			r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorInvalidGrant, "")
+			return
+		}
+
+		if oauthAuthorizationCode, err = ssn.ConsumeOAuthAuthorizationCode(ctx, *tokenRequest.Code); err != nil {
+			log.LoggerFromContext(ctx).WithError(err).Error("Unable to consume oauth authorization code")
+			r.oauthErrorResponse(responder, http.StatusInternalServerError, oauth.ErrorServerError, "")
+			return
+		} else if oauthAuthorizationCode == nil {
+			r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorInvalidGrant, "")
+			return
+		}
+
+		scopes = oauthAuthorizationCode.Scopes
+		userID = oauthAuthorizationCode.UserID
+	case oauth.GrantTypeRefreshToken:
+		ssn := r.AuthStore().NewOAuthTokenSession()
+		defer ssn.Close()
+
+		// Verify the client and requested scopes before consuming the refresh token, so an invalid request cannot burn a valid token
+		oauthToken, err := ssn.GetOAuthTokenByRefreshToken(ctx, *tokenRequest.RefreshToken)
+		if err != nil {
+			log.LoggerFromContext(ctx).WithError(err).Error("Unable to get oauth token")
+			r.oauthErrorResponse(responder, http.StatusInternalServerError, oauth.ErrorServerError, "")
+			return
+		} else if oauthToken == nil || oauthToken.IsRefreshExpired() || oauthToken.ClientID != oauthClient.ID {
+			r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorInvalidGrant, "")
+			return
+		}
+
+		scopes = oauthToken.Scopes
+		if tokenRequest.Scope != nil {
+			scopes = oauth.ParseScope(*tokenRequest.Scope)
+		}
+		if !oauthToken.HasScopes(scopes) || !oauthClient.AllowsScopes(scopes) {
+			r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorInvalidScope, "")
+			return
+		}
+
+		if oauthToken, err = ssn.ConsumeOAuthTokenByRefreshToken(ctx, *tokenRequest.RefreshToken); err != nil {
+			log.LoggerFromContext(ctx).WithError(err).Error("Unable to consume oauth token")
+			r.oauthErrorResponse(responder, http.StatusInternalServerError, oauth.ErrorServerError, "")
+			return
+		} else if oauthToken == nil {
+			r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorInvalidGrant, "")
+			return
+		}
+
+		userID = oauthToken.UserID
+	}
+
+	if !oauthClient.AllowsScopes(scopes) {
+		r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorInvalidScope, "")
+		return
+	}
+
+	oauthToken, err := auth.NewOAuthToken(oauthClient.ID, userID, scopes)
+	if err != nil {
+		log.LoggerFromContext(ctx).WithError(err).Error("Unable to create oauth token")
+		r.oauthErrorResponse(responder, http.StatusInternalServerError, oauth.ErrorServerError, "")
+		return
+	}
+
+	ssn := r.AuthStore().NewOAuthTokenSession()
+	defer ssn.Close()
+
+	if err = ssn.CreateOAuthToken(ctx, oauthToken); err != nil {
+		log.LoggerFromContext(ctx).WithError(err).Error("Unable to create oauth token")
+		r.oauthErrorResponse(responder, http.StatusInternalServerError, oauth.ErrorServerError, "")
+		return
+	}
+
+	responder.Data(http.StatusOK, auth.NewOAuthTokenResponse(oauthToken), oauthNoStoreMutators()...)
+}
+
+// RFC 7009; the response does not reveal whether the token was valid
+func (r *Router) OAuthRevoke(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+	ctx := req.Context()
+
+	if err := req.ParseForm(); err != nil {
+		r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorInvalidRequest, "unable to parse form")
+		return
+	}
+
+	token := req.PostForm.Get("token")
+	if token == "" {
+		r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorInvalidRequest, "token is missing")
+		return
+	}
+
+	oauthClient, ok := r.oauthAuthenticateClient(ctx, responder, req)
+	if !ok {
+		return
+	}
+
+	ssn := r.AuthStore().NewOAuthTokenSession()
+	defer ssn.Close()
+
+	if err := ssn.DeleteOAuthToken(ctx, oauthClient.ID, token); err != nil {
+		log.LoggerFromContext(ctx).WithError(err).Error("Unable to delete oauth token")
+		r.oauthErrorResponse(responder, http.StatusInternalServerError, oauth.ErrorServerError, "")
+		return
+	}
+
+	responder.Empty(http.StatusOK, oauthNoStoreMutators()...)
+}
+
+func (r *Router) ValidateOAuthAccessToken(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	validate := auth.NewOAuthAccessTokenValidate()
+	if err := request.DecodeRequestBody(req.Request, validate); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	oauthToken, err := r.AuthClient().ValidateOAuthAccessToken(req.Context(), validate.AccessToken)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	} else if oauthToken == nil {
+		responder.Error(http.StatusNotFound, request.ErrorResourceNotFound())
+		return
+	}
+
+	responder.Data(http.StatusOK, oauthToken)
+}
+
+// Client credentials may be provided via HTTP basic authentication or form parameters, per RFC 6749, Section 2.3.1
+func (r *Router) oauthAuthenticateClient(ctx context.Context, responder *request.Responder, req *rest.Request) (*auth.OAuthClient, bool) {
+	clientID, clientSecret, ok := req.BasicAuth()
+	if !ok {
+		clientID = req.PostForm.Get("client_id")
+		clientSecret = req.PostForm.Get("client_secret")
+	}
+
+	if !auth.IsValidOAuthClientID(clientID) {
+		r.oauthErrorResponse(responder, http.StatusUnauthorized, oauth.ErrorInvalidClient, "")
+		return nil, false
+	}
+
+	oauthClient, err := r.AuthClient().GetOAuthClient(ctx, clientID)
+	if err != nil {
+		log.LoggerFromContext(ctx).WithError(err).Error("Unable to get oauth client")
+		r.oauthErrorResponse(responder, http.StatusInternalServerError, oauth.ErrorServerError, "")
+		return nil, false
+	} else if oauthClient == nil || !oauthClient.AuthenticatesSecret(clientSecret) {
+		r.oauthErrorResponse(responder, http.StatusUnauthorized, oauth.ErrorInvalidClient, "")
+		return nil, false
+	}
+
+	return oauthClient, true
+}
+
+func (r *Router) oauthAuthorizeRedirect(responder *request.Responder, authorize *auth.OAuthAuthorize, parameters map[string]string) {
+	redirectURL, err := url.Parse(authorize.RedirectURI)
+	if err != nil {
+		r.oauthErrorResponse(responder, http.StatusBadRequest, oauth.ErrorInvalidRequest, "redirect uri is invalid")
+		return
+	}
+
+	query := redirectURL.Query()
+	for key, value := range parameters {
+		query.Set(key, value)
+	}
+	if authorize.State != nil {
+		query.Set("state", *authorize.State)
+	}
+	redirectURL.RawQuery = query.Encode()
+
+	responder.Data(http.StatusOK, &oauthAuthorizeResponse{RedirectURI: redirectURL.String()}, oauthNoStoreMutators()...)
+}
+
+func (r *Router) oauthErrorResponse(responder *request.Responder, statusCode int, code string, description string) {
+	mutators := oauthNoStoreMutators()
+	if statusCode == http.StatusUnauthorized {
+		mutators = append(mutators, request.NewHeaderMutator("WWW-Authenticate", "Basic"))
+	}
+	responder.Data(statusCode, auth.NewOAuthErrorResponse(code, description), mutators...)
+}
+
+func oauthNoStoreMutators() []request.ResponseMutator {
+	return []request.ResponseMutator{
+		request.NewHeaderMutator("Cache-Control", "no-store"),
+		request.NewHeaderMutator("Pragma", "no-cache"),
+	}
+}
+
+func (r *Router) createOAuthClientAuditEntry(ctx context.Context, outcome string, userID string, authorize *auth.OAuthAuthorize, err error) error {
+	auditEntry := auth.NewAuditEntry(ctx, auth.AuditEventOAuthClientAuthorize, outcome).WithTarget(userID, auth.AuditTargetTypeOAuthClient, authorize.ClientID).WithError(err)
+	auditEntry.Metadata = map[string]interface{}{"scope": authorize.Scope}
+	return authStore.CreateAuditEntry(ctx, r.AuthStore(), auditEntry)
+}
@@ -0,0 +1,284 @@
+package v1_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/auth/service/api/v1"
+	testService "github.com/tidepool-org/platform/auth/service/test"
+	authStoreTest "github.com/tidepool-org/platform/auth/store/test"
+	authTest "github.com/tidepool-org/platform/auth/test"
+	"github.com/tidepool-org/platform/log"
+	logTest "github.com/tidepool-org/platform/log/test"
+	"github.com/tidepool-org/platform/oauth"
+	"github.com/tidepool-org/platform/test"
+	testRest "github.com/tidepool-org/platform/test/rest"
+	"github.com/tidepool-org/platform/user"
+)
+
+var _ = Describe("OAuthServer", func() {
+	var svc *testService.Service
+	var authClient *authTest.Client
+	var codeSsn *authStoreTest.OAuthAuthorizationCodeSession
+	var tokenSsn *authStoreTest.OAuthTokenSession
+	var rtr *v1.Router
+	var res *testRest.ResponseWriter
+	var req *rest.Request
+	var ctx context.Context
+	var body []byte
+	var oauthClient *auth.OAuthClient
+	var secret string
+	var userID string
+
+	BeforeEach(func() {
+		var err error
+		svc = testService.NewService()
+		authClient = svc.AuthClientImpl
+		codeSsn = svc.AuthStoreImpl.NewOAuthAuthorizationCodeSessionImpl
+		codeSsn.CloseStub = func() error { return nil }
+		tokenSsn = svc.AuthStoreImpl.NewOAuthTokenSessionImpl
+		tokenSsn.CloseStub = func() error { return nil }
+		rtr, err = v1.NewRouter(svc)
+		Expect(err).ToNot(HaveOccurred())
+
+		body = nil
+		res = testRest.NewResponseWriter()
+		res.HeaderOutput = &http.Header{}
+		res.WriteStub = func(bytes []byte) (int, error) {
+			body = append(body, bytes...)
+			return len(bytes), nil
+		}
+		req = testRest.NewRequest()
+		ctx = log.NewContextWithLogger(req.Context(), logTest.NewLogger())
+		req.Request = req.WithContext(ctx)
+
+		oauthClient, err = auth.NewOAuthClient(&auth.OAuthClientCreate{
+			Name:         "Test",
+			RedirectURIs: []string{"https://example.com/callback"},
+			Scopes:       []string{auth.OAuthScopeDataRead, auth.OAuthScopeDataWrite},
+			Confidential: true,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		secret = *oauthClient.Secret
+		userID = user.NewID()
+	})
+
+	AfterEach(func() {
+		svc.Expectations()
+		res.AssertOutputsEmpty()
+	})
+
+	post := func(form url.Values) {
+		req.Method = http.MethodPost
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Body = ioutil.NopCloser(strings.NewReader(form.Encode()))
+	}
+
+	expectOAuthError := func(statusCode int, code string) {
+		Expect(res.WriteHeaderInputs).To(Equal([]int{statusCode}))
+		errorResponse := &auth.OAuthErrorResponse{}
+		Expect(json.Unmarshal(body, errorResponse)).To(Succeed())
+		Expect(errorResponse.Error).To(Equal(code))
+	}
+
+	expectOAuthToken := func() *auth.OAuthTokenResponse {
+		Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusOK}))
+		Expect(res.HeaderOutput.Get("Cache-Control")).To(Equal("no-store"))
+		tokenResponse := &auth.OAuthTokenResponse{}
+		Expect(json.Unmarshal(body, tokenResponse)).To(Succeed())
+		Expect(tokenResponse.AccessToken).ToNot(BeEmpty())
+		Expect(tokenResponse.RefreshToken).ToNot(BeEmpty())
+		return tokenResponse
+	}
+
+	Context("OAuthToken", func() {
+		Context("with authorization code grant", func() {
+			var codeVerifier string
+			var oauthAuthorizationCode *auth.OAuthAuthorizationCode
+			var form url.Values
+
+			BeforeEach(func() {
+				var err error
+				codeVerifier = test.NewString(64, test.CharsetAlphaNumeric)
+				oauthAuthorizationCode, err = auth.NewOAuthAuthorizationCode(userID, &auth.OAuthAuthorize{
+					ResponseType:        oauth.ResponseTypeCode,
+					ClientID:            oauthClient.ID,
+					RedirectURI:         "https://example.com/callback",
+					Scope:               auth.OAuthScopeDataRead,
+					CodeChallenge:       oauth.CalculateCodeChallengeS256(codeVerifier),
+					CodeChallengeMethod: oauth.CodeChallengeMethodS256,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				form = url.Values{
+					"grant_type":    []string{oauth.GrantTypeAuthorizationCode},
+					"code":          []string{oauthAuthorizationCode.Code},
+					"redirect_uri":  []string{"https://example.com/callback"},
+					"code_verifier": []string{codeVerifier},
+					"client_id":     []string{oauthClient.ID},
+					"client_secret": []string{secret},
+				}
+			})
+
+			It("responds with invalid client, without getting or consuming the code, if the client secret does not match", func() {
+				form.Set("client_secret", "invalid")
+				post(form)
+				authClient.GetOAuthClientOutputs = []authTest.GetOAuthClientOutput{{OAuthClient: oauthClient, Error: nil}}
+				rtr.OAuthToken(res, req)
+				expectOAuthError(http.StatusUnauthorized, oauth.ErrorInvalidClient)
+				Expect(res.HeaderOutput.Get("WWW-Authenticate")).To(Equal("Basic"))
+				Expect(codeSsn.GetOAuthAuthorizationCodeInvocations).To(Equal(0))
+				Expect(codeSsn.ConsumeOAuthAuthorizationCodeInvocations).To(Equal(0))
+			})
+
+			It("responds with invalid grant, without consuming the code, if the code verifier does not match", func() {
+				form.Set("code_verifier", test.NewString(64, test.CharsetAlphaNumeric))
+				post(form)
+				authClient.GetOAuthClientOutputs = []authTest.GetOAuthClientOutput{{OAuthClient: oauthClient, Error: nil}}
+				codeSsn.GetOAuthAuthorizationCodeOutputs = []authStoreTest.GetOAuthAuthorizationCodeOutput{{OAuthAuthorizationCode: oauthAuthorizationCode, Error: nil}}
+				rtr.OAuthToken(res, req)
+				expectOAuthError(http.StatusBadRequest, oauth.ErrorInvalidGrant)
+				Expect(codeSsn.ConsumeOAuthAuthorizationCodeInvocations).To(Equal(0))
+			})
+
+			It("responds with invalid grant, without consuming the code, if the code was issued to another client", func() {
+				oauthAuthorizationCode.ClientID = auth.NewOAuthClientID()
+				post(form)
+				authClient.GetOAuthClientOutputs = []authTest.GetOAuthClientOutput{{OAuthClient: oauthClient, Error: nil}}
+				codeSsn.GetOAuthAuthorizationCodeOutputs = []authStoreTest.GetOAuthAuthorizationCodeOutput{{OAuthAuthorizationCode: oauthAuthorizationCode, Error: nil}}
+				rtr.OAuthToken(res, req)
+				expectOAuthError(http.StatusBadRequest, oauth.ErrorInvalidGrant)
+				Expect(codeSsn.ConsumeOAuthAuthorizationCodeInvocations).To(Equal(0))
+			})
+
+			It("responds with invalid grant, without creating a token, if the code was already consumed", func() {
+				post(form)
+				authClient.GetOAuthClientOutputs = []authTest.GetOAuthClientOutput{{OAuthClient: oauthClient, Error: nil}}
+				codeSsn.GetOAuthAuthorizationCodeOutputs = []authStoreTest.GetOAuthAuthorizationCodeOutput{{OAuthAuthorizationCode: oauthAuthorizationCode, Error: nil}}
+				codeSsn.ConsumeOAuthAuthorizationCodeOutputs = []authStoreTest.ConsumeOAuthAuthorizationCodeOutput{{OAuthAuthorizationCode: nil, Error: nil}}
+				rtr.OAuthToken(res, req)
+				expectOAuthError(http.StatusBadRequest, oauth.ErrorInvalidGrant)
+				Expect(tokenSsn.CreateOAuthTokenInvocations).To(Equal(0))
+			})
+
+			It("consumes the code once and responds with a token for the user", func() {
+				req.SetBasicAuth(oauthClient.ID, secret)
+				form.Del("client_id")
+				form.Del("client_secret")
+				post(form)
+				authClient.GetOAuthClientOutputs = []authTest.GetOAuthClientOutput{{OAuthClient: oauthClient, Error: nil}}
+				codeSsn.GetOAuthAuthorizationCodeOutputs = []authStoreTest.GetOAuthAuthorizationCodeOutput{{OAuthAuthorizationCode: oauthAuthorizationCode, Error: nil}}
+				codeSsn.ConsumeOAuthAuthorizationCodeOutputs = []authStoreTest.ConsumeOAuthAuthorizationCodeOutput{{OAuthAuthorizationCode: oauthAuthorizationCode, Error: nil}}
+				tokenSsn.CreateOAuthTokenOutputs = []error{nil}
+				rtr.OAuthToken(res, req)
+				tokenResponse := expectOAuthToken()
+				Expect(tokenResponse.Scope).To(Equal(auth.OAuthScopeDataRead))
+				Expect(codeSsn.ConsumeOAuthAuthorizationCodeInputs).To(Equal([]authStoreTest.ConsumeOAuthAuthorizationCodeInput{{Context: ctx, Code: oauthAuthorizationCode.Code}}))
+				Expect(tokenSsn.CreateOAuthTokenInputs).To(HaveLen(1))
+				Expect(tokenSsn.CreateOAuthTokenInputs[0].OAuthToken.ClientID).To(Equal(oauthClient.ID))
+				Expect(tokenSsn.CreateOAuthTokenInputs[0].OAuthToken.UserID).To(Equal(userID))
+			})
+		})
+
+		Context("with refresh token grant", func() {
+			var oauthToken *auth.OAuthToken
+			var form url.Values
+
+			BeforeEach(func() {
+				var err error
+				oauthToken, err = auth.NewOAuthToken(oauthClient.ID, userID, []string{auth.OAuthScopeDataRead})
+				Expect(err).ToNot(HaveOccurred())
+				form = url.Values{
+					"grant_type":    []string{oauth.GrantTypeRefreshToken},
+					"refresh_token": []string{oauthToken.RefreshToken},
+					"client_id":     []string{oauthClient.ID},
+					"client_secret": []string{secret},
+				}
+			})
+
+			It("responds with invalid grant, without consuming the refresh token, if the token was issued to another client", func() {
+				oauthToken.ClientID = auth.NewOAuthClientID()
+				post(form)
+				authClient.GetOAuthClientOutputs = []authTest.GetOAuthClientOutput{{OAuthClient: oauthClient, Error: nil}}
+				tokenSsn.GetOAuthTokenByRefreshTokenOutputs = []authStoreTest.GetOAuthTokenByRefreshTokenOutput{{OAuthToken: oauthToken, Error: nil}}
+				rtr.OAuthToken(res, req)
+				expectOAuthError(http.StatusBadRequest, oauth.ErrorInvalidGrant)
+				Expect(tokenSsn.ConsumeOAuthTokenByRefreshTokenInvocations).To(Equal(0))
+			})
+
+			It("responds with invalid scope, without consuming the refresh token, if the scope is broadened", func() {
+				form.Set("scope", oauth.FormatScope([]string{auth.OAuthScopeDataRead, auth.OAuthScopeDataWrite}))
+				post(form)
+				authClient.GetOAuthClientOutputs = []authTest.GetOAuthClientOutput{{OAuthClient: oauthClient, Error: nil}}
+				tokenSsn.GetOAuthTokenByRefreshTokenOutputs = []authStoreTest.GetOAuthTokenByRefreshTokenOutput{{OAuthToken: oauthToken, Error: nil}}
+				rtr.OAuthToken(res, req)
+				expectOAuthError(http.StatusBadRequest, oauth.ErrorInvalidScope)
+				Expect(tokenSsn.ConsumeOAuthTokenByRefreshTokenInvocations).To(Equal(0))
+			})
+
+			It("responds with invalid grant if the refresh token was already consumed", func() {
+				post(form)
+				authClient.GetOAuthClientOutputs = []authTest.GetOAuthClientOutput{{OAuthClient: oauthClient, Error: nil}}
+				tokenSsn.GetOAuthTokenByRefreshTokenOutputs = []authStoreTest.GetOAuthTokenByRefreshTokenOutput{{OAuthToken: oauthToken, Error: nil}}
+				tokenSsn.ConsumeOAuthTokenByRefreshTokenOutputs = []authStoreTest.ConsumeOAuthTokenByRefreshTokenOutput{{OAuthToken: nil, Error: nil}}
+				rtr.OAuthToken(res, req)
+				expectOAuthError(http.StatusBadRequest, oauth.ErrorInvalidGrant)
+				Expect(tokenSsn.CreateOAuthTokenInvocations).To(Equal(0))
+			})
+
+			It("consumes the refresh token and responds with a rotated token", func() {
+				post(form)
+				authClient.GetOAuthClientOutputs = []authTest.GetOAuthClientOutput{{OAuthClient: oauthClient, Error: nil}}
+				tokenSsn.GetOAuthTokenByRefreshTokenOutputs = []authStoreTest.GetOAuthTokenByRefreshTokenOutput{{OAuthToken: oauthToken, Error: nil}}
+				tokenSsn.ConsumeOAuthTokenByRefreshTokenOutputs = []authStoreTest.ConsumeOAuthTokenByRefreshTokenOutput{{OAuthToken: oauthToken, Error: nil}}
+				tokenSsn.CreateOAuthTokenOutputs = []error{nil}
+				rtr.OAuthToken(res, req)
+				tokenResponse := expectOAuthToken()
+				Expect(tokenResponse.AccessToken).ToNot(Equal(oauthToken.AccessToken))
+				Expect(tokenResponse.RefreshToken).ToNot(Equal(oauthToken.RefreshToken))
+				Expect(tokenResponse.Scope).To(Equal(auth.OAuthScopeDataRead))
+				Expect(tokenSsn.ConsumeOAuthTokenByRefreshTokenInputs).To(Equal([]authStoreTest.ConsumeOAuthTokenByRefreshTokenInput{{Context: ctx, RefreshToken: oauthToken.RefreshToken}}))
+			})
+		})
+	})
+
+	Context("OAuthRevoke", func() {
+		var token string
+
+		BeforeEach(func() {
+			token = auth.NewOAuthRefreshToken()
+		})
+
+		It("responds with invalid request if the token is missing", func() {
+			post(url.Values{"client_id": []string{oauthClient.ID}, "client_secret": []string{secret}})
+			rtr.OAuthRevoke(res, req)
+			expectOAuthError(http.StatusBadRequest, oauth.ErrorInvalidRequest)
+		})
+
+		It("responds with invalid client, without deleting the token, if the client secret does not match", func() {
+			post(url.Values{"token": []string{token}, "client_id": []string{oauthClient.ID}, "client_secret": []string{"invalid"}})
+			authClient.GetOAuthClientOutputs = []authTest.GetOAuthClientOutput{{OAuthClient: oauthClient, Error: nil}}
+			rtr.OAuthRevoke(res, req)
+			expectOAuthError(http.StatusUnauthorized, oauth.ErrorInvalidClient)
+			Expect(tokenSsn.DeleteOAuthTokenInvocations).To(Equal(0))
+		})
+
+		It("deletes the token of the client", func() {
+			post(url.Values{"token": []string{token}, "client_id": []string{oauthClient.ID}, "client_secret": []string{secret}})
+			authClient.GetOAuthClientOutputs = []authTest.GetOAuthClientOutput{{OAuthClient: oauthClient, Error: nil}}
+			tokenSsn.DeleteOAuthTokenOutputs = []error{nil}
+			rtr.OAuthRevoke(res, req)
+			Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusOK}))
+			Expect(tokenSsn.DeleteOAuthTokenInputs).To(Equal([]authStoreTest.DeleteOAuthTokenInput{{Context: ctx, ClientID: oauthClient.ID, Token: token}}))
+		})
+	})
+})
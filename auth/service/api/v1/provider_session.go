@@ -13,14 +13,37 @@ import (
 
 func (r *Router) ProviderSessionsRoutes() []*rest.Route {
 	return []*rest.Route{
+		rest.Get("/v1/provider_sessions", api.RequireServer(r.ListProviderSessions)),
 		rest.Get("/v1/users/:userId/provider_sessions", api.RequireServer(r.ListUserProviderSessions)),
 		rest.Post("/v1/users/:userId/provider_sessions", api.RequireServer(r.CreateUserProviderSession)),
 		rest.Get("/v1/provider_sessions/:id", api.RequireServer(r.GetProviderSession)),
 		rest.Put("/v1/provider_sessions/:id", api.RequireServer(r.UpdateProviderSession)),
 		rest.Delete("/v1/provider_sessions/:id", api.RequireServer(r.DeleteProviderSession)),
+		rest.Post("/v1/provider_sessions/:id/locks", api.RequireServer(r.LockProviderSession)),
+		rest.Delete("/v1/provider_sessions/:id/locks/:lockId", api.RequireServer(r.UnlockProviderSession)),
 	}
 }
 
+// Use the unhealthy filter to list provider sessions with recorded refresh failures
+func (r *Router) ListProviderSessions(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	filter := auth.NewProviderSessionFilter()
+	pagination := page.NewPagination()
+	if err := request.DecodeRequestQuery(req.Request, filter, pagination); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	providerSessions, err := r.AuthClient().ListProviderSessions(req.Context(), filter, pagination)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Data(http.StatusOK, providerSessions)
+}
+
 func (r *Router) ListUserProviderSessions(res rest.ResponseWriter, req *rest.Request) {
 	responder := request.MustNewResponder(res, req)
 
@@ -135,3 +158,53 @@ func (r *Router) DeleteProviderSession(res rest.ResponseWriter, req *rest.Reques
 
 	responder.Empty(http.StatusOK)
 }
+
+// Responds not found if the provider session does not exist or is locked by another lock
+func (r *Router) LockProviderSession(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	id := req.PathParam("id")
+	if id == "" {
+		responder.Error(http.StatusBadRequest, request.ErrorParameterMissing("id"))
+		return
+	}
+
+	lock := &auth.ProviderSessionLock{}
+	if err := request.DecodeRequestBody(req.Request, lock); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	providerSession, err := r.AuthClient().LockProviderSession(req.Context(), id, lock)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	} else if providerSession == nil {
+		responder.Error(http.StatusNotFound, request.ErrorResourceNotFoundWithID(id))
+		return
+	}
+
+	responder.Data(http.StatusOK, providerSession)
+}
+
+func (r *Router) UnlockProviderSession(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	id := req.PathParam("id")
+	if id == "" {
+		responder.Error(http.StatusBadRequest, request.ErrorParameterMissing("id"))
+		return
+	}
+	lockID := req.PathParam("lockId")
+	if lockID == "" {
+		responder.Error(http.StatusBadRequest, request.ErrorParameterMissing("lockId"))
+		return
+	}
+
+	if err := r.AuthClient().UnlockProviderSession(req.Context(), id, lockID); err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Empty(http.StatusOK)
+}
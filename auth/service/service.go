@@ -2,6 +2,7 @@ package service
 
 import (
 	"github.com/tidepool-org/platform/auth/store"
+	dataClient "github.com/tidepool-org/platform/data/client"
 	"github.com/tidepool-org/platform/provider"
 	"github.com/tidepool-org/platform/service"
 	"github.com/tidepool-org/platform/task"
@@ -15,6 +16,7 @@ type Service interface {
 
 	ProviderFactory() provider.Factory
 
+	DataClient() dataClient.Client
 	TaskClient() task.Client
 
 	Status() *Status
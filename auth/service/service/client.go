@@ -10,7 +10,9 @@ import (
 	"github.com/tidepool-org/platform/log"
 	"github.com/tidepool-org/platform/page"
 	"github.com/tidepool-org/platform/platform"
+	"github.com/tidepool-org/platform/pointer"
 	"github.com/tidepool-org/platform/provider"
+	"github.com/tidepool-org/platform/request"
 )
 
 type Client struct {
@@ -52,6 +54,13 @@ func NewClient(cfg *client.ExternalConfig, authorizeAs platform.AuthorizeAs, nam
 	}, nil
 }
 
+func (c *Client) ListProviderSessions(ctx context.Context, filter *auth.ProviderSessionFilter, pagination *page.Pagination) (auth.ProviderSessions, error) {
+	ssn := c.authStore.NewProviderSessionSession()
+	defer ssn.Close()
+
+	return ssn.ListProviderSessions(ctx, filter, pagination)
+}
+
 func (c *Client) ListUserProviderSessions(ctx context.Context, userID string, filter *auth.ProviderSessionFilter, pagination *page.Pagination) (auth.ProviderSessions, error) {
 	ssn := c.authStore.NewProviderSessionSession()
 	defer ssn.Close()
@@ -70,12 +79,18 @@ func (c *Client) CreateUserProviderSession(ctx context.Context, userID string, c
 
 	providerSession, err := ssn.CreateUserProviderSession(ctx, userID, create)
 	if err != nil {
+		c.createProviderSessionAuditEntry(ctx, auth.AuditEventProviderSessionCreate, userID, "", create.Name, err)
 		return nil, err
 	}
 
 	if err = prvdr.OnCreate(ctx, providerSession.UserID, providerSession.ID); err != nil {
 		log.LoggerFromContext(ctx).WithError(err).WithField("providerSessionId", providerSession.ID).Error("unable to finalize creation of provider session")
 		ssn.DeleteProviderSession(ctx, providerSession.ID)
+		c.createProviderSessionAuditEntry(ctx, auth.AuditEventProviderSessionCreate, providerSession.UserID, providerSession.ID, providerSession.Name, err)
+		return nil, err
+	}
+
+	if err = c.createProviderSessionAuditEntry(ctx, auth.AuditEventProviderSessionCreate, providerSession.UserID, providerSession.ID, providerSession.Name, nil); err != nil {
 		return nil, err
 	}
 
@@ -93,7 +108,19 @@ func (c *Client) UpdateProviderSession(ctx context.Context, id string, update *a
 	ssn := c.authStore.NewProviderSessionSession()
 	defer ssn.Close()
 
-	return ssn.UpdateProviderSession(ctx, id, update)
+	providerSession, err := ssn.UpdateProviderSession(ctx, id, update)
+	if err != nil {
+		c.createProviderSessionAuditEntry(ctx, auth.AuditEventProviderSessionUpdate, "", id, "", err)
+		return nil, err
+	} else if providerSession == nil {
+		return nil, nil
+	}
+
+	if err = c.createProviderSessionAuditEntry(ctx, auth.AuditEventProviderSessionUpdate, providerSession.UserID, providerSession.ID, providerSession.Name, nil); err != nil {
+		return nil, err
+	}
+
+	return providerSession, nil
 }
 
 func (c *Client) DeleteProviderSession(ctx context.Context, id string) error {
@@ -113,10 +140,30 @@ func (c *Client) DeleteProviderSession(ctx context.Context, id string) error {
 	}
 
 	if err = ssn.DeleteProviderSession(ctx, id); err != nil {
+		c.createProviderSessionAuditEntry(ctx, auth.AuditEventProviderSessionDelete, providerSession.UserID, providerSession.ID, providerSession.Name, err)
 		return err
 	}
 
-	return prvdr.OnDelete(ctx, providerSession.UserID, providerSession.ID)
+	auditErr := c.createProviderSessionAuditEntry(ctx, auth.AuditEventProviderSessionDelete, providerSession.UserID, providerSession.ID, providerSession.Name, nil)
+	if err = prvdr.OnDelete(ctx, providerSession.UserID, providerSession.ID); err != nil {
+		return err
+	}
+
+	return auditErr
+}
+
+func (c *Client) LockProviderSession(ctx context.Context, id string, lock *auth.ProviderSessionLock) (*auth.ProviderSession, error) {
+	ssn := c.authStore.NewProviderSessionSession()
+	defer ssn.Close()
+
+	return ssn.LockProviderSession(ctx, id, lock)
+}
+
+func (c *Client) UnlockProviderSession(ctx context.Context, id string, lockID string) error {
+	ssn := c.authStore.NewProviderSessionSession()
+	defer ssn.Close()
+
+	return ssn.UnlockProviderSession(ctx, id, lockID)
 }
 
 func (c *Client) ListUserRestrictedTokens(ctx context.Context, userID string, filter *auth.RestrictedTokenFilter, pagination *page.Pagination) (auth.RestrictedTokens, error) {
@@ -130,7 +177,17 @@ func (c *Client) CreateUserRestrictedToken(ctx context.Context, userID string, c
 	ssn := c.authStore.NewRestrictedTokenSession()
 	defer ssn.Close()
 
-	return ssn.CreateUserRestrictedToken(ctx, userID, create)
+	restrictedToken, err := ssn.CreateUserRestrictedToken(ctx, userID, create)
+	if err != nil {
+		authStore.CreateAuditEntry(ctx, c.authStore, auth.NewAuditEntry(ctx, auth.AuditEventRestrictedTokenCreate, auth.AuditOutcomeFailure).WithTarget(userID, auth.AuditTargetTypeRestrictedToken, "").WithError(err))
+		return nil, err
+	}
+
+	if err = authStore.CreateAuditEntry(ctx, c.authStore, auth.NewAuditEntry(ctx, auth.AuditEventRestrictedTokenCreate, auth.AuditOutcomeSuccess).WithTarget(restrictedToken.UserID, auth.AuditTargetTypeRestrictedToken, restrictedToken.ID)); err != nil {
+		return nil, err
+	}
+
+	return restrictedToken, nil
 }
 
 func (c *Client) GetRestrictedToken(ctx context.Context, id string) (*auth.RestrictedToken, error) {
@@ -144,12 +201,140 @@ func (c *Client) UpdateRestrictedToken(ctx context.Context, id string, update *a
 	ssn := c.authStore.NewRestrictedTokenSession()
 	defer ssn.Close()
 
-	return ssn.UpdateRestrictedToken(ctx, id, update)
+	restrictedToken, err := ssn.UpdateRestrictedToken(ctx, id, update)
+	if err != nil {
+		authStore.CreateAuditEntry(ctx, c.authStore, auth.NewAuditEntry(ctx, auth.AuditEventRestrictedTokenUpdate, auth.AuditOutcomeFailure).WithTarget("", auth.AuditTargetTypeRestrictedToken, id).WithError(err))
+		return nil, err
+	} else if restrictedToken == nil {
+		return nil, nil
+	}
+
+	if err = authStore.CreateAuditEntry(ctx, c.authStore, auth.NewAuditEntry(ctx, auth.AuditEventRestrictedTokenUpdate, auth.AuditOutcomeSuccess).WithTarget(restrictedToken.UserID, auth.AuditTargetTypeRestrictedToken, restrictedToken.ID)); err != nil {
+		return nil, err
+	}
+
+	return restrictedToken, nil
 }
 
 func (c *Client) DeleteRestrictedToken(ctx context.Context, id string) error {
 	ssn := c.authStore.NewRestrictedTokenSession()
 	defer ssn.Close()
 
-	return ssn.DeleteRestrictedToken(ctx, id)
+	restrictedToken, err := ssn.GetRestrictedToken(ctx, id)
+	if err != nil {
+		return err
+	} else if restrictedToken == nil {
+		return nil
+	}
+
+	if err = ssn.DeleteRestrictedToken(ctx, id); err != nil {
+		authStore.CreateAuditEntry(ctx, c.authStore, auth.NewAuditEntry(ctx, auth.AuditEventRestrictedTokenDelete, auth.AuditOutcomeFailure).WithTarget(restrictedToken.UserID, auth.AuditTargetTypeRestrictedToken, restrictedToken.ID).WithError(err))
+		return err
+	}
+
+	return authStore.CreateAuditEntry(ctx, c.authStore, auth.NewAuditEntry(ctx, auth.AuditEventRestrictedTokenDelete, auth.AuditOutcomeSuccess).WithTarget(restrictedToken.UserID, auth.AuditTargetTypeRestrictedToken, restrictedToken.ID))
+}
+
+func (c *Client) UseRestrictedToken(ctx context.Context, id string, use *auth.RestrictedTokenUse) (*auth.RestrictedToken, error) {
+	ssn := c.authStore.NewRestrictedTokenSession()
+	defer ssn.Close()
+
+	restrictedToken, err := ssn.UseRestrictedToken(ctx, id, use)
+
+	// A missing restricted token, or one that does not authenticate the use, is denied. Successful uses are
+	// sampled, each sample recording the usage count. A failure to audit a use does not fail the request.
+	var auditEntry *auth.AuditEntry
+	if request.IsErrorUnauthorized(err) || (err == nil && restrictedToken == nil) {
+		auditEntry = auth.NewAuditEntry(ctx, auth.AuditEventRestrictedTokenUse, auth.AuditOutcomeDenied).WithTarget("", auth.AuditTargetTypeRestrictedToken, id)
+	} else if err == nil && auth.IsRestrictedTokenUseAudited(restrictedToken) {
+		auditEntry = auth.NewAuditEntry(ctx, auth.AuditEventRestrictedTokenUse, auth.AuditOutcomeSuccess).WithTarget(restrictedToken.UserID, auth.AuditTargetTypeRestrictedToken, restrictedToken.ID)
+		auditEntry.Metadata = map[string]interface{}{"usageCount": restrictedToken.UsageCount}
+	}
+	if auditEntry != nil {
+		if use != nil && use.IPAddress != nil {
+			auditEntry.IPAddress = pointer.CloneString(use.IPAddress)
+		}
+		authStore.CreateAuditEntry(ctx, c.authStore, auditEntry)
+	}
+
+	return restrictedToken, err
+}
+
+func (c *Client) CreateOAuthClient(ctx context.Context, create *auth.OAuthClientCreate) (*auth.OAuthClient, error) {
+	ssn := c.authStore.NewOAuthClientSession()
+	defer ssn.Close()
+
+	return ssn.CreateOAuthClient(ctx, create)
+}
+
+func (c *Client) GetOAuthClient(ctx context.Context, id string) (*auth.OAuthClient, error) {
+	ssn := c.authStore.NewOAuthClientSession()
+	defer ssn.Close()
+
+	return ssn.GetOAuthClient(ctx, id)
+}
+
+func (c *Client) DeleteOAuthClient(ctx context.Context, id string) error {
+	tokenSsn := c.authStore.NewOAuthTokenSession()
+	defer tokenSsn.Close()
+
+	if err := tokenSsn.DeleteOAuthTokensByClientID(ctx, id); err != nil {
+		return err
+	}
+
+	ssn := c.authStore.NewOAuthClientSession()
+	defer ssn.Close()
+
+	return ssn.DeleteOAuthClient(ctx, id)
+}
+
+func (c *Client) ValidateOAuthAccessToken(ctx context.Context, accessToken string) (*auth.OAuthToken, error) {
+	if !auth.IsValidOAuthAccessToken(accessToken) {
+		return nil, nil
+	}
+
+	ssn := c.authStore.NewOAuthTokenSession()
+	defer ssn.Close()
+
+	oauthToken, err := ssn.GetOAuthTokenByAccessToken(ctx, accessToken)
+	if err != nil || oauthToken == nil || oauthToken.IsExpired() {
+		return nil, err
+	}
+
+	return oauthToken, nil
+}
+
+func (c *Client) ListUserOAuthGrants(ctx context.Context, userID string) ([]*auth.OAuthToken, error) {
+	ssn := c.authStore.NewOAuthTokenSession()
+	defer ssn.Close()
+
+	return ssn.ListOAuthTokensByUserID(ctx, userID)
+}
+
+func (c *Client) DeleteUserOAuthGrants(ctx context.Context, userID string) error {
+	codeSsn := c.authStore.NewOAuthAuthorizationCodeSession()
+	defer codeSsn.Close()
+
+	if err := codeSsn.DeleteOAuthAuthorizationCodesByUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	ssn := c.authStore.NewOAuthTokenSession()
+	defer ssn.Close()
+
+	return ssn.DeleteOAuthTokensByUserID(ctx, userID)
+}
+
+func (c *Client) createProviderSessionAuditEntry(ctx context.Context, event string, userID string, id string, providerName string, err error) error {
+	outcome := auth.AuditOutcomeSuccess
+	if err != nil {
+		outcome = auth.AuditOutcomeFailure
+	}
+
+	auditEntry := auth.NewAuditEntry(ctx, event, outcome).WithTarget(userID, auth.AuditTargetTypeProviderSession, id).WithError(err)
+	if providerName != "" {
+		auditEntry.ProviderName = pointer.FromString(providerName)
+	}
+
+	return authStore.CreateAuditEntry(ctx, c.authStore, auditEntry)
 }
@@ -17,7 +17,6 @@ import (
 	"github.com/tidepool-org/platform/provider"
 	providerFactory "github.com/tidepool-org/platform/provider/factory"
 	serviceService "github.com/tidepool-org/platform/service/service"
-	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
 	"github.com/tidepool-org/platform/task"
 	taskClient "github.com/tidepool-org/platform/task/client"
 )
@@ -157,7 +156,7 @@ func (s *Service) terminateRouter() {
 func (s *Service) initializeAuthStore() error {
 	s.Logger().Debug("Loading auth store config")
 
-	cfg := storeStructuredMongo.NewConfig()
+	cfg := authMongo.NewConfig()
 	if err := cfg.Load(s.ConfigReporter().WithScopes("auth", "store")); err != nil {
 		return errors.Wrap(err, "unable to load auth store config")
 	}
@@ -6,6 +6,8 @@ import (
 	"github.com/tidepool-org/platform/auth/service"
 	"github.com/tidepool-org/platform/auth/store"
 	testAuthStore "github.com/tidepool-org/platform/auth/store/test"
+	dataClient "github.com/tidepool-org/platform/data/client"
+	dataClientTest "github.com/tidepool-org/platform/data/client/test"
 	"github.com/tidepool-org/platform/provider"
 	testProvider "github.com/tidepool-org/platform/provider/test"
 	testService "github.com/tidepool-org/platform/service/test"
@@ -21,6 +23,8 @@ type Service struct {
 	AuthStoreImpl              *testAuthStore.Store
 	ProviderFactoryInvocations int
 	ProviderFactoryImpl        *testProvider.Factory
+	DataClientInvocations      int
+	DataClientImpl             *dataClientTest.Client
 	TaskClientInvocations      int
 	TaskClientImpl             *testTask.Client
 	StatusInvocations          int
@@ -32,6 +36,7 @@ func NewService() *Service {
 		Service:             testService.NewService(),
 		AuthStoreImpl:       testAuthStore.NewStore(),
 		ProviderFactoryImpl: testProvider.NewFactory(),
+		DataClientImpl:      dataClientTest.NewClient(),
 		TaskClientImpl:      testTask.NewClient(),
 	}
 }
@@ -58,6 +63,12 @@ func (s *Service) ProviderFactory() provider.Factory {
 	return s.ProviderFactoryImpl
 }
 
+func (s *Service) DataClient() dataClient.Client {
+	s.DataClientInvocations++
+
+	return s.DataClientImpl
+}
+
 func (s *Service) TaskClient() task.Client {
 	s.TaskClientInvocations++
 
@@ -0,0 +1,23 @@
+package store
+
+import (
+	"context"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+)
+
+// Failure to record an audit entry is logged and returned so the caller can fail the audited
+// operation; if the audited operation already failed, then that error takes precedence
+func CreateAuditEntry(ctx context.Context, str Store, auditEntry *auth.AuditEntry) error {
+	ssn := str.NewAuditEntrySession()
+	defer ssn.Close()
+
+	if err := ssn.CreateAuditEntry(ctx, auditEntry); err != nil {
+		log.LoggerFromContext(ctx).WithError(err).WithFields(log.Fields{"event": auditEntry.Event, "outcome": auditEntry.Outcome}).Error("Unable to create audit entry")
+		return errors.Wrap(err, "unable to create audit entry")
+	}
+
+	return nil
+}
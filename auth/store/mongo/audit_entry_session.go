@@ -0,0 +1,113 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/page"
+	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
+type AuditEntrySession struct {
+	*storeStructuredMongo.Session
+}
+
+func (a *AuditEntrySession) EnsureIndexes() error {
+	return a.EnsureAllIndexes([]mgo.Index{
+		{Key: []string{"id"}, Unique: true, Background: true},
+		{Key: []string{"actorUserId", "-createdTime"}, Background: true},
+		{Key: []string{"targetUserId", "-createdTime"}, Background: true},
+		{Key: []string{"createdTime"}, Background: true, ExpireAfter: auth.AuditEntryRetentionDuration},
+	})
+}
+
+func (a *AuditEntrySession) ListUserAuditEntries(ctx context.Context, userID string, filter *auth.AuditEntryFilter, pagination *page.Pagination) (auth.AuditEntries, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+	if filter == nil {
+		filter = auth.NewAuditEntryFilter()
+	} else if err := structureValidator.New().Validate(filter); err != nil {
+		return nil, errors.Wrap(err, "filter is invalid")
+	}
+	if pagination == nil {
+		pagination = page.NewPagination()
+	} else if err := structureValidator.New().Validate(pagination); err != nil {
+		return nil, errors.Wrap(err, "pagination is invalid")
+	}
+
+	if a.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"userId": userID, "filter": filter, "pagination": pagination})
+
+	auditEntries := auth.AuditEntries{}
+	selector := bson.M{
+		"$or": []bson.M{
+			{"actorUserId": userID},
+			{"targetUserId": userID},
+		},
+	}
+	if filter.Event != nil {
+		selector["event"] = *filter.Event
+	}
+	if filter.StartTime != nil || filter.EndTime != nil {
+		createdTimeSelector := bson.M{}
+		if filter.StartTime != nil {
+			createdTimeSelector["$gte"] = *filter.StartTime
+		}
+		if filter.EndTime != nil {
+			createdTimeSelector["$lt"] = *filter.EndTime
+		}
+		selector["createdTime"] = createdTimeSelector
+	}
+	err := a.C().Find(selector).Sort("-createdTime").Skip(pagination.Page * pagination.Size).Limit(pagination.Size).All(&auditEntries)
+	logger.WithFields(log.Fields{"count": len(auditEntries), "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("ListUserAuditEntries")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list user audit entries")
+	}
+
+	if auditEntries == nil {
+		auditEntries = auth.AuditEntries{}
+	}
+
+	return auditEntries, nil
+}
+
+func (a *AuditEntrySession) CreateAuditEntry(ctx context.Context, auditEntry *auth.AuditEntry) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if auditEntry == nil {
+		return errors.New("audit entry is missing")
+	} else if err := structureValidator.New().Validate(auditEntry); err != nil {
+		return errors.Wrap(err, "audit entry is invalid")
+	}
+
+	if a.IsClosed() {
+		return errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"id": auditEntry.ID, "event": auditEntry.Event, "outcome": auditEntry.Outcome})
+
+	err := a.C().Insert(auditEntry)
+	logger.WithField("duration", time.Since(now)/time.Microsecond).WithError(err).Debug("CreateAuditEntry")
+	if err != nil {
+		return errors.Wrap(err, "unable to create audit entry")
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package mongo
+
+import (
+	"github.com/tidepool-org/platform/config"
+	cryptoKey "github.com/tidepool-org/platform/crypto/key"
+	"github.com/tidepool-org/platform/errors"
+	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
+)
+
+type Config struct {
+	*storeStructuredMongo.Config
+	Encryption *cryptoKey.Config
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Config:     storeStructuredMongo.NewConfig(),
+		Encryption: cryptoKey.NewConfig(),
+	}
+}
+
+func (c *Config) Load(configReporter config.Reporter) error {
+	if configReporter == nil {
+		return errors.New("config reporter is missing")
+	}
+	if c.Config == nil {
+		return errors.New("config is missing")
+	}
+	if c.Encryption == nil {
+		return errors.New("encryption config is missing")
+	}
+
+	if err := c.Config.Load(configReporter); err != nil {
+		return err
+	}
+	return c.Encryption.Load(configReporter.WithScopes("encryption"))
+}
+
+func (c *Config) Validate() error {
+	if c.Config == nil {
+		return errors.New("config is missing")
+	}
+	if c.Encryption == nil {
+		return errors.New("encryption config is missing")
+	}
+
+	if err := c.Config.Validate(); err != nil {
+		return err
+	}
+	if err := c.Encryption.Validate(); err != nil {
+		return errors.Wrap(err, "encryption config is invalid")
+	}
+
+	return nil
+}
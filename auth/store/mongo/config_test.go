@@ -0,0 +1,124 @@
+package mongo_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"encoding/base64"
+	"time"
+
+	"github.com/tidepool-org/platform/auth/store/mongo"
+	configTest "github.com/tidepool-org/platform/config/test"
+	cryptoKey "github.com/tidepool-org/platform/crypto/key"
+	"github.com/tidepool-org/platform/pointer"
+	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
+	"github.com/tidepool-org/platform/test"
+)
+
+var _ = Describe("Config", func() {
+	Context("NewConfig", func() {
+		It("returns a new config with default values", func() {
+			config := mongo.NewConfig()
+			Expect(config).ToNot(BeNil())
+			Expect(config.Config).ToNot(BeNil())
+			Expect(config.Encryption).ToNot(BeNil())
+			Expect(config.Encryption.KeyID).To(BeEmpty())
+			Expect(config.Encryption.Keys).To(BeEmpty())
+		})
+	})
+
+	Context("with new config", func() {
+		var key []byte
+		var config *mongo.Config
+
+		BeforeEach(func() {
+			key = test.RandomBytesFromRange(32, 32)
+			config = mongo.NewConfig()
+			Expect(config).ToNot(BeNil())
+		})
+
+		Context("Load", func() {
+			var configReporter *configTest.Reporter
+
+			BeforeEach(func() {
+				configReporter = configTest.NewReporter()
+				configReporter.Config["encryption"] = map[string]interface{}{
+					"key_id": "current",
+					"keys":   "current:" + base64.StdEncoding.EncodeToString(key),
+				}
+			})
+
+			It("returns an error if config reporter is missing", func() {
+				Expect(config.Load(nil)).To(MatchError("config reporter is missing"))
+			})
+
+			It("returns an error if base config is missing", func() {
+				config.Config = nil
+				Expect(config.Load(configReporter)).To(MatchError("config is missing"))
+			})
+
+			It("returns an error if encryption config is missing", func() {
+				config.Encryption = nil
+				Expect(config.Load(configReporter)).To(MatchError("encryption config is missing"))
+			})
+
+			It("returns an error if base config returns an error", func() {
+				configReporter.Config["tls"] = "abc"
+				Expect(config.Load(configReporter)).To(MatchError("tls is invalid"))
+			})
+
+			It("returns an error if encryption config returns an error", func() {
+				configReporter.Config["encryption"] = map[string]interface{}{"keys": "invalid"}
+				Expect(config.Load(configReporter)).To(MatchError("keys is invalid"))
+			})
+
+			It("returns successfully and uses values from config reporter", func() {
+				Expect(config.Load(configReporter)).To(Succeed())
+				Expect(config.Encryption.KeyID).To(Equal("current"))
+				Expect(config.Encryption.Keys).To(Equal(map[string][]byte{"current": key}))
+			})
+		})
+
+		Context("with valid values", func() {
+			BeforeEach(func() {
+				config.Config = storeStructuredMongo.NewConfig()
+				config.Addresses = []string{"1.2.3.4", "5.6.7.8"}
+				config.TLS = false
+				config.Database = "database"
+				config.CollectionPrefix = "collection_prefix"
+				config.Username = pointer.FromString("username")
+				config.Password = pointer.FromString("password")
+				config.Timeout = 5 * time.Second
+				config.Encryption = cryptoKey.NewConfig()
+				config.Encryption.KeyID = "current"
+				config.Encryption.Keys = map[string][]byte{"current": key}
+			})
+
+			Context("Validate", func() {
+				It("return success if all are valid", func() {
+					Expect(config.Validate()).To(Succeed())
+				})
+
+				It("returns an error if the base config is missing", func() {
+					config.Config = nil
+					Expect(config.Validate()).To(MatchError("config is missing"))
+				})
+
+				It("returns an error if the encryption config is missing", func() {
+					config.Encryption = nil
+					Expect(config.Validate()).To(MatchError("encryption config is missing"))
+				})
+
+				It("returns an error if the base config is not valid", func() {
+					config.Addresses = nil
+					Expect(config.Validate()).To(MatchError("addresses is missing"))
+				})
+
+				It("returns an error if the encryption config is not valid", func() {
+					config.Encryption.KeyID = "missing"
+					Expect(config.Validate()).To(MatchError(`encryption config is invalid; key with id "missing" is missing`))
+				})
+			})
+		})
+	})
+})
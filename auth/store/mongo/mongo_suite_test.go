@@ -5,9 +5,25 @@ import (
 	. "github.com/onsi/gomega"
 
 	"testing"
+
+	"github.com/tidepool-org/platform/auth/store/mongo"
+	logNull "github.com/tidepool-org/platform/log/null"
+	storeStructuredMongoTest "github.com/tidepool-org/platform/store/structured/mongo/test"
+	"github.com/tidepool-org/platform/test"
 )
 
 func TestSuite(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "auth/store/mongo")
 }
+
+func NewStore() *mongo.Store {
+	cfg := mongo.NewConfig()
+	cfg.Config = storeStructuredMongoTest.NewConfig()
+	cfg.Encryption.KeyID = "test"
+	cfg.Encryption.Keys = map[string][]byte{"test": test.RandomBytesFromRange(32, 32)}
+	str, err := mongo.NewStore(cfg, logNull.NewLogger())
+	Expect(err).ToNot(HaveOccurred())
+	Expect(str.EnsureIndexes()).To(Succeed())
+	return str
+}
@@ -0,0 +1,138 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/crypto"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
+)
+
+type OAuthAuthorizationCodeSession struct {
+	*storeStructuredMongo.Session
+}
+
+func (o *OAuthAuthorizationCodeSession) EnsureIndexes() error {
+	return o.EnsureAllIndexes([]mgo.Index{
+		{Key: []string{"codeHash"}, Unique: true, Background: true},
+		{Key: []string{"userId"}, Background: true},
+		{Key: []string{"expirationTime"}, Background: true, ExpireAfter: time.Second},
+	})
+}
+
+func (o *OAuthAuthorizationCodeSession) CreateOAuthAuthorizationCode(ctx context.Context, oauthAuthorizationCode *auth.OAuthAuthorizationCode) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if oauthAuthorizationCode == nil {
+		return errors.New("oauth authorization code is missing")
+	} else if oauthAuthorizationCode.CodeHash == "" {
+		return errors.New("oauth authorization code is invalid")
+	}
+
+	if o.IsClosed() {
+		return errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"clientId": oauthAuthorizationCode.ClientID, "userId": oauthAuthorizationCode.UserID})
+
+	err := o.C().Insert(oauthAuthorizationCode)
+	logger.WithField("duration", time.Since(now)/time.Microsecond).WithError(err).Debug("CreateOAuthAuthorizationCode")
+	if err != nil {
+		return errors.Wrap(err, "unable to create oauth authorization code")
+	}
+
+	return nil
+}
+
+func (o *OAuthAuthorizationCodeSession) GetOAuthAuthorizationCode(ctx context.Context, code string) (*auth.OAuthAuthorizationCode, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if code == "" {
+		return nil, errors.New("code is missing")
+	}
+
+	if o.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx)
+
+	oauthAuthorizationCodes := []*auth.OAuthAuthorizationCode{}
+	err := o.C().Find(bson.M{"codeHash": crypto.HexEncodedSHA256Hash(code)}).Limit(2).All(&oauthAuthorizationCodes)
+	logger.WithField("duration", time.Since(now)/time.Microsecond).WithError(err).Debug("GetOAuthAuthorizationCode")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get oauth authorization code")
+	}
+
+	switch count := len(oauthAuthorizationCodes); count {
+	case 0:
+		return nil, nil
+	case 1:
+		return oauthAuthorizationCodes[0], nil
+	default:
+		logger.WithField("count", count).Warn("Multiple oauth authorization codes found for code")
+		return oauthAuthorizationCodes[0], nil
+	}
+}
+
+// Authorization codes are single use, so the code is removed as it is returned
+func (o *OAuthAuthorizationCodeSession) ConsumeOAuthAuthorizationCode(ctx context.Context, code string) (*auth.OAuthAuthorizationCode, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if code == "" {
+		return nil, errors.New("code is missing")
+	}
+
+	if o.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx)
+
+	oauthAuthorizationCode := &auth.OAuthAuthorizationCode{}
+	changeInfo, err := o.C().Find(bson.M{"codeHash": crypto.HexEncodedSHA256Hash(code)}).Apply(mgo.Change{Remove: true}, oauthAuthorizationCode)
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("ConsumeOAuthAuthorizationCode")
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "unable to consume oauth authorization code")
+	}
+
+	return oauthAuthorizationCode, nil
+}
+
+func (o *OAuthAuthorizationCodeSession) DeleteOAuthAuthorizationCodesByUserID(ctx context.Context, userID string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if userID == "" {
+		return errors.New("user id is missing")
+	}
+
+	if o.IsClosed() {
+		return errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("userId", userID)
+
+	changeInfo, err := o.C().RemoveAll(bson.M{"userId": userID})
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("DeleteOAuthAuthorizationCodesByUserID")
+	if err != nil {
+		return errors.Wrap(err, "unable to delete oauth authorization codes by user id")
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package mongo_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"sync"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/auth/store"
+	"github.com/tidepool-org/platform/auth/store/mongo"
+	"github.com/tidepool-org/platform/log"
+	logNull "github.com/tidepool-org/platform/log/null"
+	"github.com/tidepool-org/platform/oauth"
+	"github.com/tidepool-org/platform/test"
+	"github.com/tidepool-org/platform/user"
+)
+
+func NewOAuthAuthorizationCode(userID string) *auth.OAuthAuthorizationCode {
+	oauthAuthorizationCode, err := auth.NewOAuthAuthorizationCode(userID, &auth.OAuthAuthorize{
+		ResponseType:        oauth.ResponseTypeCode,
+		ClientID:            auth.NewOAuthClientID(),
+		RedirectURI:         "https://example.com/callback",
+		Scope:               auth.OAuthScopeDataRead,
+		CodeChallenge:       oauth.CalculateCodeChallengeS256(test.NewString(64, test.CharsetAlphaNumeric)),
+		CodeChallengeMethod: oauth.CodeChallengeMethodS256,
+	})
+	Expect(err).ToNot(HaveOccurred())
+	return oauthAuthorizationCode
+}
+
+var _ = Describe("OAuthAuthorizationCodeSession", func() {
+	var str *mongo.Store
+	var ssn store.OAuthAuthorizationCodeSession
+	var ctx context.Context
+	var userID string
+	var oauthAuthorizationCode *auth.OAuthAuthorizationCode
+
+	BeforeEach(func() {
+		str = NewStore()
+		ssn = str.NewOAuthAuthorizationCodeSession()
+		ctx = log.NewContextWithLogger(context.Background(), logNull.NewLogger())
+		userID = user.NewID()
+		oauthAuthorizationCode = NewOAuthAuthorizationCode(userID)
+		Expect(ssn.CreateOAuthAuthorizationCode(ctx, oauthAuthorizationCode)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if ssn != nil {
+			ssn.Close()
+		}
+		if str != nil {
+			str.Close()
+		}
+	})
+
+	Context("CreateOAuthAuthorizationCode", func() {
+		It("returns an error if the code hash is missing", func() {
+			oauthAuthorizationCode.CodeHash = ""
+			Expect(ssn.CreateOAuthAuthorizationCode(ctx, oauthAuthorizationCode)).To(MatchError("oauth authorization code is invalid"))
+		})
+
+		It("returns an error if the code already exists", func() {
+			Expect(ssn.CreateOAuthAuthorizationCode(ctx, oauthAuthorizationCode)).To(MatchError(HavePrefix("unable to create oauth authorization code")))
+		})
+	})
+
+	Context("GetOAuthAuthorizationCode", func() {
+		It("returns nil if the code does not exist", func() {
+			Expect(ssn.GetOAuthAuthorizationCode(ctx, auth.NewOAuthAuthorizationCodeCode())).To(BeNil())
+		})
+
+		It("returns the code, without the code itself, and does not consume it", func() {
+			result, err := ssn.GetOAuthAuthorizationCode(ctx, oauthAuthorizationCode.Code)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).ToNot(BeNil())
+			Expect(result.Code).To(BeEmpty())
+			Expect(result.CodeHash).To(Equal(oauthAuthorizationCode.CodeHash))
+			Expect(result.UserID).To(Equal(userID))
+			Expect(ssn.GetOAuthAuthorizationCode(ctx, oauthAuthorizationCode.Code)).ToNot(BeNil())
+		})
+	})
+
+	Context("ConsumeOAuthAuthorizationCode", func() {
+		It("returns an error if the code is missing", func() {
+			result, err := ssn.ConsumeOAuthAuthorizationCode(ctx, "")
+			Expect(err).To(MatchError("code is missing"))
+			Expect(result).To(BeNil())
+		})
+
+		It("returns nil if the code does not exist", func() {
+			Expect(ssn.ConsumeOAuthAuthorizationCode(ctx, auth.NewOAuthAuthorizationCodeCode())).To(BeNil())
+		})
+
+		It("returns the code once and removes it", func() {
+			result, err := ssn.ConsumeOAuthAuthorizationCode(ctx, oauthAuthorizationCode.Code)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).ToNot(BeNil())
+			Expect(result.CodeHash).To(Equal(oauthAuthorizationCode.CodeHash))
+			Expect(ssn.ConsumeOAuthAuthorizationCode(ctx, oauthAuthorizationCode.Code)).To(BeNil())
+			Expect(ssn.GetOAuthAuthorizationCode(ctx, oauthAuthorizationCode.Code)).To(BeNil())
+		})
+
+		It("returns the code to exactly one of many concurrent consumers", func() {
+			var waitGroup sync.WaitGroup
+			results := make(chan *auth.OAuthAuthorizationCode, 10)
+			for index := 0; index < 10; index++ {
+				waitGroup.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer waitGroup.Done()
+					concurrentSsn := str.NewOAuthAuthorizationCodeSession()
+					defer concurrentSsn.Close()
+					result, err := concurrentSsn.ConsumeOAuthAuthorizationCode(ctx, oauthAuthorizationCode.Code)
+					Expect(err).ToNot(HaveOccurred())
+					results <- result
+				}()
+			}
+			waitGroup.Wait()
+			close(results)
+
+			consumed := 0
+			for result := range results {
+				if result != nil {
+					consumed++
+				}
+			}
+			Expect(consumed).To(Equal(1))
+		})
+	})
+
+	Context("DeleteOAuthAuthorizationCodesByUserID", func() {
+		It("deletes only the codes of the user", func() {
+			otherOAuthAuthorizationCode := NewOAuthAuthorizationCode(user.NewID())
+			Expect(ssn.CreateOAuthAuthorizationCode(ctx, otherOAuthAuthorizationCode)).To(Succeed())
+			Expect(ssn.DeleteOAuthAuthorizationCodesByUserID(ctx, userID)).To(Succeed())
+			Expect(ssn.GetOAuthAuthorizationCode(ctx, oauthAuthorizationCode.Code)).To(BeNil())
+			Expect(ssn.GetOAuthAuthorizationCode(ctx, otherOAuthAuthorizationCode.Code)).ToNot(BeNil())
+		})
+	})
+})
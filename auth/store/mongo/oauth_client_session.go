@@ -0,0 +1,110 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
+type OAuthClientSession struct {
+	*storeStructuredMongo.Session
+}
+
+func (o *OAuthClientSession) EnsureIndexes() error {
+	return o.EnsureAllIndexes([]mgo.Index{
+		{Key: []string{"id"}, Unique: true, Background: true},
+	})
+}
+
+func (o *OAuthClientSession) CreateOAuthClient(ctx context.Context, create *auth.OAuthClientCreate) (*auth.OAuthClient, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+
+	oauthClient, err := auth.NewOAuthClient(create)
+	if err != nil {
+		return nil, err
+	} else if err = structureValidator.New().Validate(oauthClient); err != nil {
+		return nil, errors.Wrap(err, "oauth client is invalid")
+	}
+
+	if o.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("create", create)
+
+	err = o.C().Insert(oauthClient)
+	logger.WithFields(log.Fields{"id": oauthClient.ID, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("CreateOAuthClient")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create oauth client")
+	}
+
+	return oauthClient, nil
+}
+
+func (o *OAuthClientSession) GetOAuthClient(ctx context.Context, id string) (*auth.OAuthClient, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if id == "" {
+		return nil, errors.New("id is missing")
+	}
+
+	if o.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("id", id)
+
+	oauthClients := []*auth.OAuthClient{}
+	err := o.C().Find(bson.M{"id": id}).Limit(2).All(&oauthClients)
+	logger.WithField("duration", time.Since(now)/time.Microsecond).WithError(err).Debug("GetOAuthClient")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get oauth client")
+	}
+
+	switch count := len(oauthClients); count {
+	case 0:
+		return nil, nil
+	case 1:
+		return oauthClients[0], nil
+	default:
+		logger.WithField("count", count).Warnf("Multiple oauth clients found for id %q", id)
+		return oauthClients[0], nil
+	}
+}
+
+func (o *OAuthClientSession) DeleteOAuthClient(ctx context.Context, id string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if id == "" {
+		return errors.New("id is missing")
+	}
+
+	if o.IsClosed() {
+		return errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("id", id)
+
+	changeInfo, err := o.C().RemoveAll(bson.M{"id": id})
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("DeleteOAuthClient")
+	if err != nil {
+		return errors.Wrap(err, "unable to delete oauth client")
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package mongo_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/auth/store"
+	"github.com/tidepool-org/platform/auth/store/mongo"
+	"github.com/tidepool-org/platform/log"
+	logNull "github.com/tidepool-org/platform/log/null"
+)
+
+var _ = Describe("OAuthClientSession", func() {
+	var str *mongo.Store
+	var ssn store.OAuthClientSession
+	var ctx context.Context
+	var create *auth.OAuthClientCreate
+
+	BeforeEach(func() {
+		str = NewStore()
+		ssn = str.NewOAuthClientSession()
+		ctx = log.NewContextWithLogger(context.Background(), logNull.NewLogger())
+		create = &auth.OAuthClientCreate{
+			Name:         "Test",
+			RedirectURIs: []string{"https://example.com/callback"},
+			Scopes:       []string{auth.OAuthScopeDataRead},
+			Confidential: true,
+		}
+	})
+
+	AfterEach(func() {
+		if ssn != nil {
+			ssn.Close()
+		}
+		if str != nil {
+			str.Close()
+		}
+	})
+
+	Context("CreateOAuthClient", func() {
+		It("returns an error if the create is invalid", func() {
+			create.Name = ""
+			oauthClient, err := ssn.CreateOAuthClient(ctx, create)
+			Expect(err).To(MatchError(HavePrefix("create is invalid")))
+			Expect(oauthClient).To(BeNil())
+		})
+
+		It("returns the client with the secret, persisting only the secret hash", func() {
+			oauthClient, err := ssn.CreateOAuthClient(ctx, create)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(oauthClient.Secret).ToNot(BeNil())
+			result, err := ssn.GetOAuthClient(ctx, oauthClient.ID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).ToNot(BeNil())
+			Expect(result.Secret).To(BeNil())
+			Expect(result.SecretHash).To(Equal(oauthClient.SecretHash))
+			Expect(result.AuthenticatesSecret(*oauthClient.Secret)).To(BeTrue())
+		})
+	})
+
+	Context("GetOAuthClient", func() {
+		It("returns nil if the client does not exist", func() {
+			Expect(ssn.GetOAuthClient(ctx, auth.NewOAuthClientID())).To(BeNil())
+		})
+	})
+
+	Context("DeleteOAuthClient", func() {
+		It("deletes the client", func() {
+			oauthClient, err := ssn.CreateOAuthClient(ctx, create)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ssn.DeleteOAuthClient(ctx, oauthClient.ID)).To(Succeed())
+			Expect(ssn.GetOAuthClient(ctx, oauthClient.ID)).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,260 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/crypto"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
+type OAuthTokenSession struct {
+	*storeStructuredMongo.Session
+}
+
+func (o *OAuthTokenSession) EnsureIndexes() error {
+	return o.EnsureAllIndexes([]mgo.Index{
+		{Key: []string{"accessTokenHash"}, Unique: true, Background: true},
+		{Key: []string{"refreshTokenHash"}, Unique: true, Background: true},
+		{Key: []string{"clientId"}, Background: true},
+		{Key: []string{"userId"}, Background: true},
+		{Key: []string{"refreshExpirationTime"}, Background: true, ExpireAfter: time.Second},
+	})
+}
+
+func (o *OAuthTokenSession) CreateOAuthToken(ctx context.Context, oauthToken *auth.OAuthToken) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if oauthToken == nil {
+		return errors.New("oauth token is missing")
+	} else if err := structureValidator.New().Validate(oauthToken); err != nil {
+		return errors.Wrap(err, "oauth token is invalid")
+	} else if oauthToken.AccessTokenHash == "" || oauthToken.RefreshTokenHash == "" {
+		return errors.New("oauth token is invalid")
+	}
+
+	if o.IsClosed() {
+		return errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"clientId": oauthToken.ClientID, "userId": oauthToken.UserID})
+
+	err := o.C().Insert(oauthToken)
+	logger.WithField("duration", time.Since(now)/time.Microsecond).WithError(err).Debug("CreateOAuthToken")
+	if err != nil {
+		return errors.Wrap(err, "unable to create oauth token")
+	}
+
+	return nil
+}
+
+func (o *OAuthTokenSession) GetOAuthTokenByAccessToken(ctx context.Context, accessToken string) (*auth.OAuthToken, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if accessToken == "" {
+		return nil, errors.New("access token is missing")
+	}
+
+	if o.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx)
+
+	oauthTokens := []*auth.OAuthToken{}
+	err := o.C().Find(bson.M{"accessTokenHash": crypto.HexEncodedSHA256Hash(accessToken)}).Limit(2).All(&oauthTokens)
+	logger.WithField("duration", time.Since(now)/time.Microsecond).WithError(err).Debug("GetOAuthTokenByAccessToken")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get oauth token by access token")
+	}
+
+	switch count := len(oauthTokens); count {
+	case 0:
+		return nil, nil
+	case 1:
+		return oauthTokens[0], nil
+	default:
+		logger.WithField("count", count).Warn("Multiple oauth tokens found for access token")
+		return oauthTokens[0], nil
+	}
+}
+
+func (o *OAuthTokenSession) GetOAuthTokenByRefreshToken(ctx context.Context, refreshToken string) (*auth.OAuthToken, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if refreshToken == "" {
+		return nil, errors.New("refresh token is missing")
+	}
+
+	if o.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx)
+
+	oauthTokens := []*auth.OAuthToken{}
+	err := o.C().Find(bson.M{"refreshTokenHash": crypto.HexEncodedSHA256Hash(refreshToken)}).Limit(2).All(&oauthTokens)
+	logger.WithField("duration", time.Since(now)/time.Microsecond).WithError(err).Debug("GetOAuthTokenByRefreshToken")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get oauth token by refresh token")
+	}
+
+	switch count := len(oauthTokens); count {
+	case 0:
+		return nil, nil
+	case 1:
+		return oauthTokens[0], nil
+	default:
+		logger.WithField("count", count).Warn("Multiple oauth tokens found for refresh token")
+		return oauthTokens[0], nil
+	}
+}
+
+// Refresh tokens are rotated on each use, so the token is removed as it is returned
+func (o *OAuthTokenSession) ConsumeOAuthTokenByRefreshToken(ctx context.Context, refreshToken string) (*auth.OAuthToken, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if refreshToken == "" {
+		return nil, errors.New("refresh token is missing")
+	}
+
+	if o.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx)
+
+	oauthToken := &auth.OAuthToken{}
+	changeInfo, err := o.C().Find(bson.M{"refreshTokenHash": crypto.HexEncodedSHA256Hash(refreshToken)}).Apply(mgo.Change{Remove: true}, oauthToken)
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("ConsumeOAuthTokenByRefreshToken")
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "unable to consume oauth token by refresh token")
+	}
+
+	return oauthToken, nil
+}
+
+func (o *OAuthTokenSession) DeleteOAuthToken(ctx context.Context, clientID string, token string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if clientID == "" {
+		return errors.New("client id is missing")
+	}
+	if token == "" {
+		return errors.New("token is missing")
+	}
+
+	if o.IsClosed() {
+		return errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("clientId", clientID)
+
+	tokenHash := crypto.HexEncodedSHA256Hash(token)
+	selector := bson.M{
+		"clientId": clientID,
+		"$or": []bson.M{
+			{"accessTokenHash": tokenHash},
+			{"refreshTokenHash": tokenHash},
+		},
+	}
+	changeInfo, err := o.C().RemoveAll(selector)
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("DeleteOAuthToken")
+	if err != nil {
+		return errors.Wrap(err, "unable to delete oauth token")
+	}
+
+	return nil
+}
+
+func (o *OAuthTokenSession) DeleteOAuthTokensByClientID(ctx context.Context, clientID string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if clientID == "" {
+		return errors.New("client id is missing")
+	}
+
+	if o.IsClosed() {
+		return errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("clientId", clientID)
+
+	changeInfo, err := o.C().RemoveAll(bson.M{"clientId": clientID})
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("DeleteOAuthTokensByClientID")
+	if err != nil {
+		return errors.Wrap(err, "unable to delete oauth tokens by client id")
+	}
+
+	return nil
+}
+
+func (o *OAuthTokenSession) ListOAuthTokensByUserID(ctx context.Context, userID string) ([]*auth.OAuthToken, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+
+	if o.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("userId", userID)
+
+	oauthTokens := []*auth.OAuthToken{}
+	err := o.C().Find(bson.M{"userId": userID}).Sort("createdTime").All(&oauthTokens)
+	logger.WithFields(log.Fields{"count": len(oauthTokens), "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("ListOAuthTokensByUserID")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list oauth tokens by user id")
+	}
+
+	return oauthTokens, nil
+}
+
+func (o *OAuthTokenSession) DeleteOAuthTokensByUserID(ctx context.Context, userID string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if userID == "" {
+		return errors.New("user id is missing")
+	}
+
+	if o.IsClosed() {
+		return errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("userId", userID)
+
+	changeInfo, err := o.C().RemoveAll(bson.M{"userId": userID})
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("DeleteOAuthTokensByUserID")
+	if err != nil {
+		return errors.Wrap(err, "unable to delete oauth tokens by user id")
+	}
+
+	return nil
+}
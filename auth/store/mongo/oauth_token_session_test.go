@@ -0,0 +1,159 @@
+package mongo_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"sync"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/auth/store"
+	"github.com/tidepool-org/platform/auth/store/mongo"
+	"github.com/tidepool-org/platform/log"
+	logNull "github.com/tidepool-org/platform/log/null"
+	"github.com/tidepool-org/platform/user"
+)
+
+var _ = Describe("OAuthTokenSession", func() {
+	var str *mongo.Store
+	var ssn store.OAuthTokenSession
+	var ctx context.Context
+	var clientID string
+	var userID string
+	var oauthToken *auth.OAuthToken
+
+	BeforeEach(func() {
+		var err error
+		str = NewStore()
+		ssn = str.NewOAuthTokenSession()
+		ctx = log.NewContextWithLogger(context.Background(), logNull.NewLogger())
+		clientID = auth.NewOAuthClientID()
+		userID = user.NewID()
+		oauthToken, err = auth.NewOAuthToken(clientID, userID, []string{auth.OAuthScopeDataRead})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ssn.CreateOAuthToken(ctx, oauthToken)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if ssn != nil {
+			ssn.Close()
+		}
+		if str != nil {
+			str.Close()
+		}
+	})
+
+	Context("GetOAuthTokenByAccessToken", func() {
+		It("returns nil if the access token does not exist", func() {
+			Expect(ssn.GetOAuthTokenByAccessToken(ctx, auth.NewOAuthAccessToken())).To(BeNil())
+		})
+
+		It("returns the token, without the tokens themselves", func() {
+			result, err := ssn.GetOAuthTokenByAccessToken(ctx, oauthToken.AccessToken)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).ToNot(BeNil())
+			Expect(result.AccessToken).To(BeEmpty())
+			Expect(result.RefreshToken).To(BeEmpty())
+			Expect(result.AccessTokenHash).To(Equal(oauthToken.AccessTokenHash))
+		})
+	})
+
+	Context("GetOAuthTokenByRefreshToken", func() {
+		It("returns the token and does not consume it", func() {
+			Expect(ssn.GetOAuthTokenByRefreshToken(ctx, oauthToken.RefreshToken)).ToNot(BeNil())
+			Expect(ssn.GetOAuthTokenByRefreshToken(ctx, oauthToken.RefreshToken)).ToNot(BeNil())
+		})
+	})
+
+	Context("ConsumeOAuthTokenByRefreshToken", func() {
+		It("returns an error if the refresh token is missing", func() {
+			result, err := ssn.ConsumeOAuthTokenByRefreshToken(ctx, "")
+			Expect(err).To(MatchError("refresh token is missing"))
+			Expect(result).To(BeNil())
+		})
+
+		It("returns the token once and removes it, invalidating the access token", func() {
+			result, err := ssn.ConsumeOAuthTokenByRefreshToken(ctx, oauthToken.RefreshToken)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).ToNot(BeNil())
+			Expect(result.RefreshTokenHash).To(Equal(oauthToken.RefreshTokenHash))
+			Expect(ssn.ConsumeOAuthTokenByRefreshToken(ctx, oauthToken.RefreshToken)).To(BeNil())
+			Expect(ssn.GetOAuthTokenByAccessToken(ctx, oauthToken.AccessToken)).To(BeNil())
+		})
+
+		It("returns the token to exactly one of many concurrent consumers", func() {
+			var waitGroup sync.WaitGroup
+			results := make(chan *auth.OAuthToken, 10)
+			for index := 0; index < 10; index++ {
+				waitGroup.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer waitGroup.Done()
+					concurrentSsn := str.NewOAuthTokenSession()
+					defer concurrentSsn.Close()
+					result, err := concurrentSsn.ConsumeOAuthTokenByRefreshToken(ctx, oauthToken.RefreshToken)
+					Expect(err).ToNot(HaveOccurred())
+					results <- result
+				}()
+			}
+			waitGroup.Wait()
+			close(results)
+
+			consumed := 0
+			for result := range results {
+				if result != nil {
+					consumed++
+				}
+			}
+			Expect(consumed).To(Equal(1))
+		})
+	})
+
+	Context("DeleteOAuthToken", func() {
+		It("does not delete the token of another client", func() {
+			Expect(ssn.DeleteOAuthToken(ctx, auth.NewOAuthClientID(), oauthToken.AccessToken)).To(Succeed())
+			Expect(ssn.GetOAuthTokenByAccessToken(ctx, oauthToken.AccessToken)).ToNot(BeNil())
+		})
+
+		It("deletes the token by access token", func() {
+			Expect(ssn.DeleteOAuthToken(ctx, clientID, oauthToken.AccessToken)).To(Succeed())
+			Expect(ssn.GetOAuthTokenByAccessToken(ctx, oauthToken.AccessToken)).To(BeNil())
+		})
+
+		It("deletes the token by refresh token", func() {
+			Expect(ssn.DeleteOAuthToken(ctx, clientID, oauthToken.RefreshToken)).To(Succeed())
+			Expect(ssn.GetOAuthTokenByRefreshToken(ctx, oauthToken.RefreshToken)).To(BeNil())
+		})
+	})
+
+	Context("with a token of another user", func() {
+		var otherOAuthToken *auth.OAuthToken
+
+		BeforeEach(func() {
+			var err error
+			otherOAuthToken, err = auth.NewOAuthToken(clientID, user.NewID(), []string{auth.OAuthScopeDataRead})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ssn.CreateOAuthToken(ctx, otherOAuthToken)).To(Succeed())
+		})
+
+		It("ListOAuthTokensByUserID lists only the tokens of the user", func() {
+			oauthTokens, err := ssn.ListOAuthTokensByUserID(ctx, userID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(oauthTokens).To(HaveLen(1))
+			Expect(oauthTokens[0].AccessTokenHash).To(Equal(oauthToken.AccessTokenHash))
+		})
+
+		It("DeleteOAuthTokensByUserID deletes only the tokens of the user", func() {
+			Expect(ssn.DeleteOAuthTokensByUserID(ctx, userID)).To(Succeed())
+			Expect(ssn.GetOAuthTokenByAccessToken(ctx, oauthToken.AccessToken)).To(BeNil())
+			Expect(ssn.GetOAuthTokenByAccessToken(ctx, otherOAuthToken.AccessToken)).ToNot(BeNil())
+		})
+
+		It("DeleteOAuthTokensByClientID deletes the tokens of the client for all users", func() {
+			Expect(ssn.DeleteOAuthTokensByClientID(ctx, clientID)).To(Succeed())
+			Expect(ssn.GetOAuthTokenByAccessToken(ctx, oauthToken.AccessToken)).To(BeNil())
+			Expect(ssn.GetOAuthTokenByAccessToken(ctx, otherOAuthToken.AccessToken)).To(BeNil())
+		})
+	})
+})
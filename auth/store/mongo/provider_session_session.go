@@ -8,8 +8,10 @@ import (
 	"gopkg.in/mgo.v2/bson"
 
 	"github.com/tidepool-org/platform/auth"
+	cryptoField "github.com/tidepool-org/platform/crypto/field"
 	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/oauth"
 	"github.com/tidepool-org/platform/page"
 	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
 	structureValidator "github.com/tidepool-org/platform/structure/validator"
@@ -17,6 +19,7 @@ import (
 
 type ProviderSessionSession struct {
 	*storeStructuredMongo.Session
+	encrypter *cryptoField.Encrypter
 }
 
 func (p *ProviderSessionSession) EnsureIndexes() error {
@@ -24,9 +27,53 @@ func (p *ProviderSessionSession) EnsureIndexes() error {
 		{Key: []string{"id"}, Unique: true, Background: true},
 		{Key: []string{"userId"}, Background: true},
 		{Key: []string{"userId", "type", "name"}, Unique: true, Background: true},
+		{Key: []string{"oauthToken.expirationTime"}, Background: true},
+		{Key: []string{"refreshFailureCount"}, Background: true, Sparse: true},
 	})
 }
 
+func (p *ProviderSessionSession) ListProviderSessions(ctx context.Context, filter *auth.ProviderSessionFilter, pagination *page.Pagination) (auth.ProviderSessions, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if filter == nil {
+		filter = auth.NewProviderSessionFilter()
+	} else if err := structureValidator.New().Validate(filter); err != nil {
+		return nil, errors.Wrap(err, "filter is invalid")
+	}
+	if pagination == nil {
+		pagination = page.NewPagination()
+	} else if err := structureValidator.New().Validate(pagination); err != nil {
+		return nil, errors.Wrap(err, "pagination is invalid")
+	}
+
+	if p.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"filter": filter, "pagination": pagination})
+
+	providerSessions := auth.ProviderSessions{}
+	selector := bson.M{}
+	p.applyFilter(selector, filter)
+	err := p.C().Find(selector).Sort("-createdTime").Skip(pagination.Page * pagination.Size).Limit(pagination.Size).All(&providerSessions)
+	logger.WithFields(log.Fields{"count": len(providerSessions), "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("ListProviderSessions")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list provider sessions")
+	}
+
+	if err = p.decryptProviderSessions(providerSessions); err != nil {
+		return nil, err
+	}
+
+	if providerSessions == nil {
+		providerSessions = auth.ProviderSessions{}
+	}
+
+	return providerSessions, nil
+}
+
 func (p *ProviderSessionSession) ListUserProviderSessions(ctx context.Context, userID string, filter *auth.ProviderSessionFilter, pagination *page.Pagination) (auth.ProviderSessions, error) {
 	if ctx == nil {
 		return nil, errors.New("context is missing")
@@ -56,18 +103,17 @@ func (p *ProviderSessionSession) ListUserProviderSessions(ctx context.Context, u
 	selector := bson.M{
 		"userId": userID,
 	}
-	if filter.Type != nil {
-		selector["type"] = *filter.Type
-	}
-	if filter.Name != nil {
-		selector["name"] = *filter.Name
-	}
+	p.applyFilter(selector, filter)
 	err := p.C().Find(selector).Sort("-createdTime").Skip(pagination.Page * pagination.Size).Limit(pagination.Size).All(&providerSessions)
 	logger.WithFields(log.Fields{"count": len(providerSessions), "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("ListUserProviderSessions")
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to list user provider sessions")
 	}
 
+	if err = p.decryptProviderSessions(providerSessions); err != nil {
+		return nil, err
+	}
+
 	if providerSessions == nil {
 		providerSessions = auth.ProviderSessions{}
 	}
@@ -94,7 +140,12 @@ func (p *ProviderSessionSession) CreateUserProviderSession(ctx context.Context,
 	now := time.Now()
 	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"userId": userID, "create": create})
 
-	err = p.C().Insert(providerSession)
+	encryptedProviderSession := *providerSession
+	if encryptedProviderSession.OAuthToken, err = EncryptProviderSessionOAuthToken(p.encrypter, providerSession.ID, providerSession.OAuthToken); err != nil {
+		return nil, err
+	}
+
+	err = p.C().Insert(&encryptedProviderSession)
 	logger.WithFields(log.Fields{"id": providerSession.ID, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("CreateUserProviderSession")
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to create user provider session")
@@ -125,15 +176,17 @@ func (p *ProviderSessionSession) GetProviderSession(ctx context.Context, id stri
 		return nil, errors.Wrap(err, "unable to get provider session")
 	}
 
-	switch count := len(providerSessions); count {
-	case 0:
+	if count := len(providerSessions); count == 0 {
 		return nil, nil
-	case 1:
-		return providerSessions[0], nil
-	default:
+	} else if count > 1 {
 		logger.WithField("count", count).Warnf("Multiple provider sessions found for id %q", id)
-		return providerSessions[0], nil
 	}
+
+	if err = p.decryptProviderSession(providerSessions[0]); err != nil {
+		return nil, err
+	}
+
+	return providerSessions[0], nil
 }
 
 func (p *ProviderSessionSession) UpdateProviderSession(ctx context.Context, id string, update *auth.ProviderSessionUpdate) (*auth.ProviderSession, error) {
@@ -161,11 +214,25 @@ func (p *ProviderSessionSession) UpdateProviderSession(ctx context.Context, id s
 	}
 	unset := bson.M{}
 	if update.OAuthToken != nil {
-		set["oauthToken"] = update.OAuthToken
+		encryptedOAuthToken, err := EncryptProviderSessionOAuthToken(p.encrypter, id, update.OAuthToken)
+		if err != nil {
+			return nil, err
+		}
+		set["oauthToken"] = encryptedOAuthToken
+		unset["refreshError"] = true
+		unset["refreshErrorTime"] = true
+		unset["refreshFailureCount"] = true
+	} else if update.RefreshError != nil {
+		set["refreshError"] = update.RefreshError
+		set["refreshErrorTime"] = now.Truncate(time.Second)
 	} else {
 		unset["oauthToken"] = true
 	}
-	changeInfo, err := p.C().UpdateAll(bson.M{"id": id}, p.ConstructUpdate(set, unset))
+	updt := p.ConstructUpdate(set, unset)
+	if update.RefreshError != nil {
+		updt["$inc"] = bson.M{"refreshFailureCount": 1}
+	}
+	changeInfo, err := p.C().UpdateAll(bson.M{"id": id}, updt)
 	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("UpdateProviderSession")
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to update provider session")
@@ -197,3 +264,186 @@ func (p *ProviderSessionSession) DeleteProviderSession(ctx context.Context, id s
 
 	return nil
 }
+
+func (p *ProviderSessionSession) LockProviderSession(ctx context.Context, id string, lock *auth.ProviderSessionLock) (*auth.ProviderSession, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if id == "" {
+		return nil, errors.New("id is missing")
+	}
+	if lock == nil {
+		return nil, errors.New("lock is missing")
+	} else if err := structureValidator.New().Validate(lock); err != nil {
+		return nil, errors.Wrap(err, "lock is invalid")
+	}
+
+	if p.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"id": id, "lockId": lock.ID})
+
+	// The lock may be acquired if not held, if expired, or if already held by the same lock id (to extend it)
+	selector := bson.M{
+		"id": id,
+		"$or": []bson.M{
+			{"lock": bson.M{"$exists": false}},
+			{"lock.expirationTime": bson.M{"$lt": now}},
+			{"lock.id": lock.ID},
+		},
+	}
+	providerSession := &auth.ProviderSession{}
+	changeInfo, err := p.C().Find(selector).Apply(mgo.Change{Update: bson.M{"$set": bson.M{"lock": lock}}, ReturnNew: true}, providerSession)
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("LockProviderSession")
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "unable to lock provider session")
+	}
+
+	if err = p.decryptProviderSession(providerSession); err != nil {
+		return nil, err
+	}
+
+	return providerSession, nil
+}
+
+func (p *ProviderSessionSession) UnlockProviderSession(ctx context.Context, id string, lockID string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if id == "" {
+		return errors.New("id is missing")
+	}
+	if lockID == "" {
+		return errors.New("lock id is missing")
+	}
+
+	if p.IsClosed() {
+		return errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"id": id, "lockId": lockID})
+
+	changeInfo, err := p.C().UpdateAll(bson.M{"id": id, "lock.id": lockID}, bson.M{"$unset": bson.M{"lock": true}})
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("UnlockProviderSession")
+	if err != nil {
+		return errors.Wrap(err, "unable to unlock provider session")
+	}
+
+	return nil
+}
+
+func (p *ProviderSessionSession) applyFilter(selector bson.M, filter *auth.ProviderSessionFilter) {
+	if filter.Type != nil {
+		selector["type"] = *filter.Type
+	}
+	if filter.Name != nil {
+		selector["name"] = *filter.Name
+	}
+	if filter.ExpirationTimeBefore != nil {
+		selector["oauthToken.expirationTime"] = bson.M{"$lt": *filter.ExpirationTimeBefore}
+	}
+	refreshFailureCount := bson.M{}
+	if filter.Unhealthy != nil {
+		if *filter.Unhealthy {
+			refreshFailureCount["$gt"] = 0
+		} else {
+			refreshFailureCount["$exists"] = false
+		}
+	}
+	if filter.RefreshFailureCountBelow != nil {
+		refreshFailureCount["$not"] = bson.M{"$gte": *filter.RefreshFailureCountBelow}
+	}
+	if len(refreshFailureCount) > 0 {
+		selector["refreshFailureCount"] = refreshFailureCount
+	}
+	if filter.RefreshTokenRevoked != nil {
+		if *filter.RefreshTokenRevoked {
+			selector["refreshError.code"] = auth.ErrorCodeProviderSessionRefreshTokenRevoked
+		} else {
+			selector["refreshError.code"] = bson.M{"$ne": auth.ErrorCodeProviderSessionRefreshTokenRevoked}
+		}
+	}
+}
+
+func (p *ProviderSessionSession) decryptProviderSessions(providerSessions auth.ProviderSessions) error {
+	for _, providerSession := range providerSessions {
+		if err := p.decryptProviderSession(providerSession); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProviderSessionSession) decryptProviderSession(providerSession *auth.ProviderSession) error {
+	oauthToken, err := DecryptProviderSessionOAuthToken(p.encrypter, providerSession.ID, providerSession.OAuthToken)
+	if err != nil {
+		return err
+	}
+	providerSession.OAuthToken = oauthToken
+	return nil
+}
+
+// EncryptProviderSessionOAuthToken returns a copy of the OAuth token with the access and refresh tokens
+// encrypted. The provider session id is used as additional data so an encrypted token is only valid
+// for the provider session to which it belongs.
+func EncryptProviderSessionOAuthToken(encrypter *cryptoField.Encrypter, id string, oauthToken *oauth.Token) (*oauth.Token, error) {
+	if encrypter == nil {
+		return nil, errors.New("encrypter is missing")
+	}
+	if oauthToken == nil {
+		return nil, nil
+	}
+
+	var err error
+	encryptedOAuthToken := *oauthToken
+	if encryptedOAuthToken.AccessToken, err = encrypter.Encrypt(oauthToken.AccessToken, providerSessionOAuthTokenAdditionalData(id, "accessToken")); err != nil {
+		return nil, errors.Wrap(err, "unable to encrypt provider session oauth token access token")
+	}
+	if encryptedOAuthToken.RefreshToken, err = encrypter.Encrypt(oauthToken.RefreshToken, providerSessionOAuthTokenAdditionalData(id, "refreshToken")); err != nil {
+		return nil, errors.Wrap(err, "unable to encrypt provider session oauth token refresh token")
+	}
+	return &encryptedOAuthToken, nil
+}
+
+// DecryptProviderSessionOAuthToken returns a copy of the OAuth token with the access and refresh tokens
+// decrypted. Tokens that are not yet encrypted are returned as is.
+func DecryptProviderSessionOAuthToken(encrypter *cryptoField.Encrypter, id string, oauthToken *oauth.Token) (*oauth.Token, error) {
+	if encrypter == nil {
+		return nil, errors.New("encrypter is missing")
+	}
+	if oauthToken == nil {
+		return nil, nil
+	}
+
+	var err error
+	decryptedOAuthToken := *oauthToken
+	if cryptoField.IsEncrypted(oauthToken.AccessToken) {
+		if decryptedOAuthToken.AccessToken, err = encrypter.Decrypt(oauthToken.AccessToken, providerSessionOAuthTokenAdditionalData(id, "accessToken")); err != nil {
+			return nil, errors.Wrap(err, "unable to decrypt provider session oauth token access token")
+		}
+	}
+	if cryptoField.IsEncrypted(oauthToken.RefreshToken) {
+		if decryptedOAuthToken.RefreshToken, err = encrypter.Decrypt(oauthToken.RefreshToken, providerSessionOAuthTokenAdditionalData(id, "refreshToken")); err != nil {
+			return nil, errors.Wrap(err, "unable to decrypt provider session oauth token refresh token")
+		}
+	}
+	return &decryptedOAuthToken, nil
+}
+
+// ProviderSessionOAuthTokenRequiresEncrypt returns true if either the access or refresh token is not
+// encrypted or is encrypted with a key other than the current key.
+func ProviderSessionOAuthTokenRequiresEncrypt(encrypter *cryptoField.Encrypter, oauthToken *oauth.Token) bool {
+	if encrypter == nil || oauthToken == nil {
+		return false
+	}
+	return encrypter.RequiresEncrypt(oauthToken.AccessToken) || encrypter.RequiresEncrypt(oauthToken.RefreshToken)
+}
+
+func providerSessionOAuthTokenAdditionalData(id string, field string) string {
+	return "provider_sessions:" + id + ":oauthToken." + field
+}
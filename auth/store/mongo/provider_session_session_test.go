@@ -1,3 +1,115 @@
 package mongo_test
 
-// TODO
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"time"
+
+	"github.com/tidepool-org/platform/auth/store/mongo"
+	cryptoField "github.com/tidepool-org/platform/crypto/field"
+	cryptoKey "github.com/tidepool-org/platform/crypto/key"
+	"github.com/tidepool-org/platform/oauth"
+	"github.com/tidepool-org/platform/test"
+)
+
+// TODO: ProviderSessionSession
+
+var _ = Describe("ProviderSessionSession", func() {
+	var cfg *cryptoKey.Config
+	var encrypter *cryptoField.Encrypter
+	var id string
+	var oauthToken *oauth.Token
+
+	BeforeEach(func() {
+		var err error
+		cfg = cryptoKey.NewConfig()
+		cfg.KeyID = "current"
+		cfg.Keys = map[string][]byte{"current": test.RandomBytesFromRange(32, 32)}
+		encrypter, err = cryptoField.NewEncrypter(cfg)
+		Expect(err).ToNot(HaveOccurred())
+		id = test.NewString(32, test.CharsetHexidecimalLowercase)
+		oauthToken = &oauth.Token{
+			AccessToken:    test.RandomString(),
+			TokenType:      "Bearer",
+			RefreshToken:   test.RandomString(),
+			ExpirationTime: time.Now().Add(time.Hour).Truncate(time.Second),
+		}
+	})
+
+	Context("EncryptProviderSessionOAuthToken", func() {
+		It("returns an error if the encrypter is missing", func() {
+			encryptedOAuthToken, err := mongo.EncryptProviderSessionOAuthToken(nil, id, oauthToken)
+			Expect(err).To(MatchError("encrypter is missing"))
+			Expect(encryptedOAuthToken).To(BeNil())
+		})
+
+		It("returns nil if the oauth token is missing", func() {
+			Expect(mongo.EncryptProviderSessionOAuthToken(encrypter, id, nil)).To(BeNil())
+		})
+
+		It("returns an encrypted copy of the oauth token", func() {
+			original := *oauthToken
+			encryptedOAuthToken, err := mongo.EncryptProviderSessionOAuthToken(encrypter, id, oauthToken)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(encryptedOAuthToken).ToNot(BeNil())
+			Expect(*oauthToken).To(Equal(original))
+			Expect(cryptoField.IsEncrypted(encryptedOAuthToken.AccessToken)).To(BeTrue())
+			Expect(cryptoField.IsEncrypted(encryptedOAuthToken.RefreshToken)).To(BeTrue())
+			Expect(encryptedOAuthToken.TokenType).To(Equal(oauthToken.TokenType))
+			Expect(encryptedOAuthToken.ExpirationTime).To(Equal(oauthToken.ExpirationTime))
+			Expect(mongo.ProviderSessionOAuthTokenRequiresEncrypt(encrypter, encryptedOAuthToken)).To(BeFalse())
+		})
+
+		It("does not encrypt an empty refresh token", func() {
+			oauthToken.RefreshToken = ""
+			encryptedOAuthToken, err := mongo.EncryptProviderSessionOAuthToken(encrypter, id, oauthToken)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(encryptedOAuthToken.RefreshToken).To(BeEmpty())
+		})
+	})
+
+	Context("DecryptProviderSessionOAuthToken", func() {
+		var encryptedOAuthToken *oauth.Token
+
+		BeforeEach(func() {
+			var err error
+			encryptedOAuthToken, err = mongo.EncryptProviderSessionOAuthToken(encrypter, id, oauthToken)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns an error if the encrypter is missing", func() {
+			decryptedOAuthToken, err := mongo.DecryptProviderSessionOAuthToken(nil, id, encryptedOAuthToken)
+			Expect(err).To(MatchError("encrypter is missing"))
+			Expect(decryptedOAuthToken).To(BeNil())
+		})
+
+		It("returns nil if the oauth token is missing", func() {
+			Expect(mongo.DecryptProviderSessionOAuthToken(encrypter, id, nil)).To(BeNil())
+		})
+
+		It("returns an error if the id does not match", func() {
+			decryptedOAuthToken, err := mongo.DecryptProviderSessionOAuthToken(encrypter, id+"0", encryptedOAuthToken)
+			Expect(err).To(MatchError("unable to decrypt provider session oauth token access token; unable to decrypt value"))
+			Expect(decryptedOAuthToken).To(BeNil())
+		})
+
+		It("returns a decrypted copy of the oauth token", func() {
+			Expect(mongo.DecryptProviderSessionOAuthToken(encrypter, id, encryptedOAuthToken)).To(Equal(oauthToken))
+		})
+
+		It("returns a copy of an oauth token that is not encrypted", func() {
+			Expect(mongo.DecryptProviderSessionOAuthToken(encrypter, id, oauthToken)).To(Equal(oauthToken))
+			Expect(mongo.ProviderSessionOAuthTokenRequiresEncrypt(encrypter, oauthToken)).To(BeTrue())
+		})
+
+		It("decrypts an oauth token encrypted with a previous key", func() {
+			cfg.Keys["next"] = test.RandomBytesFromRange(32, 32)
+			cfg.KeyID = "next"
+			rotatedEncrypter, err := cryptoField.NewEncrypter(cfg)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mongo.ProviderSessionOAuthTokenRequiresEncrypt(rotatedEncrypter, encryptedOAuthToken)).To(BeTrue())
+			Expect(mongo.DecryptProviderSessionOAuthToken(rotatedEncrypter, id, encryptedOAuthToken)).To(Equal(oauthToken))
+		})
+	})
+})
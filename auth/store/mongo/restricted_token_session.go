@@ -11,6 +11,7 @@ import (
 	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/log"
 	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/request"
 	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
 	structureValidator "github.com/tidepool-org/platform/structure/validator"
 )
@@ -154,11 +155,28 @@ func (r *RestrictedTokenSession) UpdateRestrictedToken(ctx context.Context, id s
 	}
 	unset := bson.M{}
 	if update.Paths != nil {
-		set["path"] = *update.Paths
+		set["paths"] = *update.Paths
+	}
+	if update.Methods != nil {
+		set["methods"] = *update.Methods
+	}
+	if update.ReadOnly != nil {
+		set["readOnly"] = *update.ReadOnly
+	}
+	if update.TargetUserID != nil {
+		set["targetUserId"] = *update.TargetUserID
+	}
+	if update.MaximumUses != nil {
+		set["maximumUses"] = *update.MaximumUses
 	}
 	if update.ExpirationTime != nil {
 		set["expirationTime"] = (*update.ExpirationTime).Truncate(time.Second)
 	}
+	if update.Clear != nil {
+		for _, restriction := range *update.Clear {
+			unset[restriction] = true
+		}
+	}
 	changeInfo, err := r.C().UpdateAll(bson.M{"id": id}, r.ConstructUpdate(set, unset))
 	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("UpdateRestrictedToken")
 	if err != nil {
@@ -191,3 +209,59 @@ func (r *RestrictedTokenSession) DeleteRestrictedToken(ctx context.Context, id s
 
 	return nil
 }
+
+// UseRestrictedToken records a use of the restricted token, if it authenticates the use. Returns an unauthorized error
+// if the restricted token does not authenticate the use.
+func (r *RestrictedTokenSession) UseRestrictedToken(ctx context.Context, id string, use *auth.RestrictedTokenUse) (*auth.RestrictedToken, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if id == "" {
+		return nil, errors.New("id is missing")
+	}
+	if use == nil {
+		return nil, errors.New("use is missing")
+	} else if err := structureValidator.New().Validate(use); err != nil {
+		return nil, errors.Wrap(err, "use is invalid")
+	}
+
+	restrictedToken, err := r.GetRestrictedToken(ctx, id)
+	if err != nil || restrictedToken == nil {
+		return nil, err
+	} else if !restrictedToken.AuthenticatesUse(use) {
+		return nil, request.ErrorUnauthorized()
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"id": id, "use": use})
+
+	selector := bson.M{
+		"id": id,
+	}
+	if restrictedToken.MaximumUses != nil {
+		selector["usageCount"] = bson.M{"$lt": *restrictedToken.MaximumUses}
+	}
+	set := bson.M{
+		"lastUsedTime": use.Time.Truncate(time.Second),
+	}
+	unset := bson.M{}
+	if use.IPAddress != nil {
+		set["lastUsedAddress"] = *use.IPAddress
+	} else {
+		unset["lastUsedAddress"] = true
+	}
+	update := r.ConstructUpdate(set, unset)
+	update["$inc"] = bson.M{"usageCount": 1}
+	change := mgo.Change{Update: update, ReturnNew: true}
+
+	restrictedToken = &auth.RestrictedToken{}
+	changeInfo, err := r.C().Find(selector).Apply(change, restrictedToken)
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("UseRestrictedToken")
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "unable to use restricted token")
+	}
+
+	return restrictedToken, nil
+}
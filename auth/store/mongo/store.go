@@ -2,22 +2,36 @@ package mongo
 
 import (
 	"github.com/tidepool-org/platform/auth/store"
+	cryptoField "github.com/tidepool-org/platform/crypto/field"
+	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/log"
 	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
 )
 
 type Store struct {
 	*storeStructuredMongo.Store
+	encrypter *cryptoField.Encrypter
 }
 
-func NewStore(cfg *storeStructuredMongo.Config, lgr log.Logger) (*Store, error) {
-	str, err := storeStructuredMongo.NewStore(cfg, lgr)
+func NewStore(cfg *Config, lgr log.Logger) (*Store, error) {
+	if cfg == nil {
+		return nil, errors.New("config is missing")
+	}
+
+	str, err := storeStructuredMongo.NewStore(cfg.Config, lgr)
 	if err != nil {
 		return nil, err
 	}
 
+	encrypter, err := cryptoField.NewEncrypter(cfg.Encryption)
+	if err != nil {
+		str.Close()
+		return nil, errors.Wrap(err, "unable to create encrypter")
+	}
+
 	return &Store{
-		Store: str,
+		Store:     str,
+		encrypter: encrypter,
 	}, nil
 }
 
@@ -30,7 +44,31 @@ func (s *Store) EnsureIndexes() error {
 
 	restrictedTokenSession := s.restrictedTokenSession()
 	defer restrictedTokenSession.Close()
-	return restrictedTokenSession.EnsureIndexes()
+	if err := restrictedTokenSession.EnsureIndexes(); err != nil {
+		return err
+	}
+
+	oauthClientSession := s.oauthClientSession()
+	defer oauthClientSession.Close()
+	if err := oauthClientSession.EnsureIndexes(); err != nil {
+		return err
+	}
+
+	oauthAuthorizationCodeSession := s.oauthAuthorizationCodeSession()
+	defer oauthAuthorizationCodeSession.Close()
+	if err := oauthAuthorizationCodeSession.EnsureIndexes(); err != nil {
+		return err
+	}
+
+	oauthTokenSession := s.oauthTokenSession()
+	defer oauthTokenSession.Close()
+	if err := oauthTokenSession.EnsureIndexes(); err != nil {
+		return err
+	}
+
+	auditEntrySession := s.auditEntrySession()
+	defer auditEntrySession.Close()
+	return auditEntrySession.EnsureIndexes()
 }
 
 func (s *Store) NewProviderSessionSession() store.ProviderSessionSession {
@@ -41,9 +79,26 @@ func (s *Store) NewRestrictedTokenSession() store.RestrictedTokenSession {
 	return s.restrictedTokenSession()
 }
 
+func (s *Store) NewOAuthClientSession() store.OAuthClientSession {
+	return s.oauthClientSession()
+}
+
+func (s *Store) NewOAuthAuthorizationCodeSession() store.OAuthAuthorizationCodeSession {
+	return s.oauthAuthorizationCodeSession()
+}
+
+func (s *Store) NewOAuthTokenSession() store.OAuthTokenSession {
+	return s.oauthTokenSession()
+}
+
+func (s *Store) NewAuditEntrySession() store.AuditEntrySession {
+	return s.auditEntrySession()
+}
+
 func (s *Store) providerSessionSession() *ProviderSessionSession {
 	return &ProviderSessionSession{
-		Session: s.Store.NewSession("provider_sessions"),
+		Session:   s.Store.NewSession("provider_sessions"),
+		encrypter: s.encrypter,
 	}
 }
 
@@ -52,3 +107,27 @@ func (s *Store) restrictedTokenSession() *RestrictedTokenSession {
 		Session: s.Store.NewSession("restricted_tokens"),
 	}
 }
+
+func (s *Store) oauthClientSession() *OAuthClientSession {
+	return &OAuthClientSession{
+		Session: s.Store.NewSession("oauth_clients"),
+	}
+}
+
+func (s *Store) oauthAuthorizationCodeSession() *OAuthAuthorizationCodeSession {
+	return &OAuthAuthorizationCodeSession{
+		Session: s.Store.NewSession("oauth_authorization_codes"),
+	}
+}
+
+func (s *Store) oauthTokenSession() *OAuthTokenSession {
+	return &OAuthTokenSession{
+		Session: s.Store.NewSession("oauth_tokens"),
+	}
+}
+
+func (s *Store) auditEntrySession() *AuditEntrySession {
+	return &AuditEntrySession{
+		Session: s.Store.NewSession("audit_entries"),
+	}
+}
@@ -7,16 +7,19 @@ import (
 	"github.com/tidepool-org/platform/auth/store"
 	"github.com/tidepool-org/platform/auth/store/mongo"
 	logNull "github.com/tidepool-org/platform/log/null"
-	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
 	storeStructuredMongoTest "github.com/tidepool-org/platform/store/structured/mongo/test"
+	"github.com/tidepool-org/platform/test"
 )
 
 var _ = Describe("Store", func() {
-	var cfg *storeStructuredMongo.Config
+	var cfg *mongo.Config
 	var str *mongo.Store
 
 	BeforeEach(func() {
-		cfg = storeStructuredMongoTest.NewConfig()
+		cfg = mongo.NewConfig()
+		cfg.Config = storeStructuredMongoTest.NewConfig()
+		cfg.Encryption.KeyID = "test"
+		cfg.Encryption.Keys = map[string][]byte{"test": test.RandomBytesFromRange(32, 32)}
 	})
 
 	AfterEach(func() {
@@ -33,6 +36,14 @@ var _ = Describe("Store", func() {
 			Expect(str).To(BeNil())
 		})
 
+		It("returns an error if the encryption config is invalid", func() {
+			var err error
+			cfg.Encryption.KeyID = ""
+			str, err = mongo.NewStore(cfg, logNull.NewLogger())
+			Expect(err).To(MatchError("unable to create encrypter; config is invalid; key id is missing"))
+			Expect(str).To(BeNil())
+		})
+
 		It("returns successfully", func() {
 			var err error
 			str, err = mongo.NewStore(cfg, logNull.NewLogger())
@@ -80,5 +91,50 @@ var _ = Describe("Store", func() {
 				Expect(ssn).ToNot(BeNil())
 			})
 		})
+
+		Context("NewOAuthClientSession", func() {
+			var ssn store.OAuthClientSession
+
+			AfterEach(func() {
+				if ssn != nil {
+					ssn.Close()
+				}
+			})
+
+			It("returns successfully", func() {
+				ssn = str.NewOAuthClientSession()
+				Expect(ssn).ToNot(BeNil())
+			})
+		})
+
+		Context("NewOAuthAuthorizationCodeSession", func() {
+			var ssn store.OAuthAuthorizationCodeSession
+
+			AfterEach(func() {
+				if ssn != nil {
+					ssn.Close()
+				}
+			})
+
+			It("returns successfully", func() {
+				ssn = str.NewOAuthAuthorizationCodeSession()
+				Expect(ssn).ToNot(BeNil())
+			})
+		})
+
+		Context("NewOAuthTokenSession", func() {
+			var ssn store.OAuthTokenSession
+
+			AfterEach(func() {
+				if ssn != nil {
+					ssn.Close()
+				}
+			})
+
+			It("returns successfully", func() {
+				ssn = str.NewOAuthTokenSession()
+				Expect(ssn).ToNot(BeNil())
+			})
+		})
 	})
 })
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"io"
 
 	"github.com/tidepool-org/platform/auth"
@@ -9,6 +10,10 @@ import (
 type Store interface {
 	NewProviderSessionSession() ProviderSessionSession
 	NewRestrictedTokenSession() RestrictedTokenSession
+	NewOAuthClientSession() OAuthClientSession
+	NewOAuthAuthorizationCodeSession() OAuthAuthorizationCodeSession
+	NewOAuthTokenSession() OAuthTokenSession
+	NewAuditEntrySession() AuditEntrySession
 }
 
 type ProviderSessionSession interface {
@@ -20,3 +25,38 @@ type RestrictedTokenSession interface {
 	io.Closer
 	auth.RestrictedTokenAccessor
 }
+
+type OAuthClientSession interface {
+	io.Closer
+
+	CreateOAuthClient(ctx context.Context, create *auth.OAuthClientCreate) (*auth.OAuthClient, error)
+	GetOAuthClient(ctx context.Context, id string) (*auth.OAuthClient, error)
+	DeleteOAuthClient(ctx context.Context, id string) error
+}
+
+type OAuthAuthorizationCodeSession interface {
+	io.Closer
+
+	CreateOAuthAuthorizationCode(ctx context.Context, oauthAuthorizationCode *auth.OAuthAuthorizationCode) error
+	GetOAuthAuthorizationCode(ctx context.Context, code string) (*auth.OAuthAuthorizationCode, error)
+	ConsumeOAuthAuthorizationCode(ctx context.Context, code string) (*auth.OAuthAuthorizationCode, error)
+	DeleteOAuthAuthorizationCodesByUserID(ctx context.Context, userID string) error
+}
+
+type OAuthTokenSession interface {
+	io.Closer
+
+	CreateOAuthToken(ctx context.Context, oauthToken *auth.OAuthToken) error
+	GetOAuthTokenByAccessToken(ctx context.Context, accessToken string) (*auth.OAuthToken, error)
+	GetOAuthTokenByRefreshToken(ctx context.Context, refreshToken string) (*auth.OAuthToken, error)
+	ConsumeOAuthTokenByRefreshToken(ctx context.Context, refreshToken string) (*auth.OAuthToken, error)
+	ListOAuthTokensByUserID(ctx context.Context, userID string) ([]*auth.OAuthToken, error)
+	DeleteOAuthToken(ctx context.Context, clientID string, token string) error
+	DeleteOAuthTokensByClientID(ctx context.Context, clientID string) error
+	DeleteOAuthTokensByUserID(ctx context.Context, userID string) error
+}
+
+type AuditEntrySession interface {
+	io.Closer
+	auth.AuditEntryAccessor
+}
@@ -0,0 +1,23 @@
+package test
+
+import (
+	authTest "github.com/tidepool-org/platform/auth/test"
+	"github.com/tidepool-org/platform/test"
+)
+
+type AuditEntrySession struct {
+	*test.Closer
+	*authTest.AuditEntryAccessor
+}
+
+func NewAuditEntrySession() *AuditEntrySession {
+	return &AuditEntrySession{
+		Closer:             test.NewCloser(),
+		AuditEntryAccessor: authTest.NewAuditEntryAccessor(),
+	}
+}
+
+func (a *AuditEntrySession) Expectations() {
+	a.Closer.AssertOutputsEmpty()
+	a.AuditEntryAccessor.Expectations()
+}
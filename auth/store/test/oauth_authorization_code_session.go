@@ -0,0 +1,118 @@
+package test
+
+import (
+	"context"
+
+	"github.com/onsi/gomega"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/test"
+)
+
+type CreateOAuthAuthorizationCodeInput struct {
+	Context                context.Context
+	OAuthAuthorizationCode *auth.OAuthAuthorizationCode
+}
+
+type GetOAuthAuthorizationCodeInput struct {
+	Context context.Context
+	Code    string
+}
+
+type GetOAuthAuthorizationCodeOutput struct {
+	OAuthAuthorizationCode *auth.OAuthAuthorizationCode
+	Error                  error
+}
+
+type ConsumeOAuthAuthorizationCodeInput struct {
+	Context context.Context
+	Code    string
+}
+
+type ConsumeOAuthAuthorizationCodeOutput struct {
+	OAuthAuthorizationCode *auth.OAuthAuthorizationCode
+	Error                  error
+}
+
+type DeleteOAuthAuthorizationCodesByUserIDInput struct {
+	Context context.Context
+	UserID  string
+}
+
+type OAuthAuthorizationCodeSession struct {
+	*test.Closer
+	CreateOAuthAuthorizationCodeInvocations          int
+	CreateOAuthAuthorizationCodeInputs               []CreateOAuthAuthorizationCodeInput
+	CreateOAuthAuthorizationCodeOutputs              []error
+	GetOAuthAuthorizationCodeInvocations             int
+	GetOAuthAuthorizationCodeInputs                  []GetOAuthAuthorizationCodeInput
+	GetOAuthAuthorizationCodeOutputs                 []GetOAuthAuthorizationCodeOutput
+	ConsumeOAuthAuthorizationCodeInvocations         int
+	ConsumeOAuthAuthorizationCodeInputs              []ConsumeOAuthAuthorizationCodeInput
+	ConsumeOAuthAuthorizationCodeOutputs             []ConsumeOAuthAuthorizationCodeOutput
+	DeleteOAuthAuthorizationCodesByUserIDInvocations int
+	DeleteOAuthAuthorizationCodesByUserIDInputs      []DeleteOAuthAuthorizationCodesByUserIDInput
+	DeleteOAuthAuthorizationCodesByUserIDOutputs     []error
+}
+
+func NewOAuthAuthorizationCodeSession() *OAuthAuthorizationCodeSession {
+	return &OAuthAuthorizationCodeSession{
+		Closer: test.NewCloser(),
+	}
+}
+
+func (o *OAuthAuthorizationCodeSession) CreateOAuthAuthorizationCode(ctx context.Context, oauthAuthorizationCode *auth.OAuthAuthorizationCode) error {
+	o.CreateOAuthAuthorizationCodeInvocations++
+
+	o.CreateOAuthAuthorizationCodeInputs = append(o.CreateOAuthAuthorizationCodeInputs, CreateOAuthAuthorizationCodeInput{Context: ctx, OAuthAuthorizationCode: oauthAuthorizationCode})
+
+	gomega.Expect(o.CreateOAuthAuthorizationCodeOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.CreateOAuthAuthorizationCodeOutputs[0]
+	o.CreateOAuthAuthorizationCodeOutputs = o.CreateOAuthAuthorizationCodeOutputs[1:]
+	return output
+}
+
+func (o *OAuthAuthorizationCodeSession) GetOAuthAuthorizationCode(ctx context.Context, code string) (*auth.OAuthAuthorizationCode, error) {
+	o.GetOAuthAuthorizationCodeInvocations++
+
+	o.GetOAuthAuthorizationCodeInputs = append(o.GetOAuthAuthorizationCodeInputs, GetOAuthAuthorizationCodeInput{Context: ctx, Code: code})
+
+	gomega.Expect(o.GetOAuthAuthorizationCodeOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.GetOAuthAuthorizationCodeOutputs[0]
+	o.GetOAuthAuthorizationCodeOutputs = o.GetOAuthAuthorizationCodeOutputs[1:]
+	return output.OAuthAuthorizationCode, output.Error
+}
+
+func (o *OAuthAuthorizationCodeSession) ConsumeOAuthAuthorizationCode(ctx context.Context, code string) (*auth.OAuthAuthorizationCode, error) {
+	o.ConsumeOAuthAuthorizationCodeInvocations++
+
+	o.ConsumeOAuthAuthorizationCodeInputs = append(o.ConsumeOAuthAuthorizationCodeInputs, ConsumeOAuthAuthorizationCodeInput{Context: ctx, Code: code})
+
+	gomega.Expect(o.ConsumeOAuthAuthorizationCodeOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.ConsumeOAuthAuthorizationCodeOutputs[0]
+	o.ConsumeOAuthAuthorizationCodeOutputs = o.ConsumeOAuthAuthorizationCodeOutputs[1:]
+	return output.OAuthAuthorizationCode, output.Error
+}
+
+func (o *OAuthAuthorizationCodeSession) DeleteOAuthAuthorizationCodesByUserID(ctx context.Context, userID string) error {
+	o.DeleteOAuthAuthorizationCodesByUserIDInvocations++
+
+	o.DeleteOAuthAuthorizationCodesByUserIDInputs = append(o.DeleteOAuthAuthorizationCodesByUserIDInputs, DeleteOAuthAuthorizationCodesByUserIDInput{Context: ctx, UserID: userID})
+
+	gomega.Expect(o.DeleteOAuthAuthorizationCodesByUserIDOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.DeleteOAuthAuthorizationCodesByUserIDOutputs[0]
+	o.DeleteOAuthAuthorizationCodesByUserIDOutputs = o.DeleteOAuthAuthorizationCodesByUserIDOutputs[1:]
+	return output
+}
+
+func (o *OAuthAuthorizationCodeSession) Expectations() {
+	o.Closer.AssertOutputsEmpty()
+	gomega.Expect(o.CreateOAuthAuthorizationCodeOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.GetOAuthAuthorizationCodeOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.ConsumeOAuthAuthorizationCodeOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.DeleteOAuthAuthorizationCodesByUserIDOutputs).To(gomega.BeEmpty())
+}
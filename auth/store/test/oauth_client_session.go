@@ -0,0 +1,97 @@
+package test
+
+import (
+	"context"
+
+	"github.com/onsi/gomega"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/test"
+)
+
+type CreateOAuthClientInput struct {
+	Context context.Context
+	Create  *auth.OAuthClientCreate
+}
+
+type CreateOAuthClientOutput struct {
+	OAuthClient *auth.OAuthClient
+	Error       error
+}
+
+type GetOAuthClientInput struct {
+	Context context.Context
+	ID      string
+}
+
+type GetOAuthClientOutput struct {
+	OAuthClient *auth.OAuthClient
+	Error       error
+}
+
+type DeleteOAuthClientInput struct {
+	Context context.Context
+	ID      string
+}
+
+type OAuthClientSession struct {
+	*test.Closer
+	CreateOAuthClientInvocations int
+	CreateOAuthClientInputs      []CreateOAuthClientInput
+	CreateOAuthClientOutputs     []CreateOAuthClientOutput
+	GetOAuthClientInvocations    int
+	GetOAuthClientInputs         []GetOAuthClientInput
+	GetOAuthClientOutputs        []GetOAuthClientOutput
+	DeleteOAuthClientInvocations int
+	DeleteOAuthClientInputs      []DeleteOAuthClientInput
+	DeleteOAuthClientOutputs     []error
+}
+
+func NewOAuthClientSession() *OAuthClientSession {
+	return &OAuthClientSession{
+		Closer: test.NewCloser(),
+	}
+}
+
+func (o *OAuthClientSession) CreateOAuthClient(ctx context.Context, create *auth.OAuthClientCreate) (*auth.OAuthClient, error) {
+	o.CreateOAuthClientInvocations++
+
+	o.CreateOAuthClientInputs = append(o.CreateOAuthClientInputs, CreateOAuthClientInput{Context: ctx, Create: create})
+
+	gomega.Expect(o.CreateOAuthClientOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.CreateOAuthClientOutputs[0]
+	o.CreateOAuthClientOutputs = o.CreateOAuthClientOutputs[1:]
+	return output.OAuthClient, output.Error
+}
+
+func (o *OAuthClientSession) GetOAuthClient(ctx context.Context, id string) (*auth.OAuthClient, error) {
+	o.GetOAuthClientInvocations++
+
+	o.GetOAuthClientInputs = append(o.GetOAuthClientInputs, GetOAuthClientInput{Context: ctx, ID: id})
+
+	gomega.Expect(o.GetOAuthClientOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.GetOAuthClientOutputs[0]
+	o.GetOAuthClientOutputs = o.GetOAuthClientOutputs[1:]
+	return output.OAuthClient, output.Error
+}
+
+func (o *OAuthClientSession) DeleteOAuthClient(ctx context.Context, id string) error {
+	o.DeleteOAuthClientInvocations++
+
+	o.DeleteOAuthClientInputs = append(o.DeleteOAuthClientInputs, DeleteOAuthClientInput{Context: ctx, ID: id})
+
+	gomega.Expect(o.DeleteOAuthClientOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.DeleteOAuthClientOutputs[0]
+	o.DeleteOAuthClientOutputs = o.DeleteOAuthClientOutputs[1:]
+	return output
+}
+
+func (o *OAuthClientSession) Expectations() {
+	o.Closer.AssertOutputsEmpty()
+	gomega.Expect(o.CreateOAuthClientOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.GetOAuthClientOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.DeleteOAuthClientOutputs).To(gomega.BeEmpty())
+}
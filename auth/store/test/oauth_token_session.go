@@ -0,0 +1,213 @@
+package test
+
+import (
+	"context"
+
+	"github.com/onsi/gomega"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/test"
+)
+
+type CreateOAuthTokenInput struct {
+	Context    context.Context
+	OAuthToken *auth.OAuthToken
+}
+
+type GetOAuthTokenByAccessTokenInput struct {
+	Context     context.Context
+	AccessToken string
+}
+
+type GetOAuthTokenByAccessTokenOutput struct {
+	OAuthToken *auth.OAuthToken
+	Error      error
+}
+
+type GetOAuthTokenByRefreshTokenInput struct {
+	Context      context.Context
+	RefreshToken string
+}
+
+type GetOAuthTokenByRefreshTokenOutput struct {
+	OAuthToken *auth.OAuthToken
+	Error      error
+}
+
+type ConsumeOAuthTokenByRefreshTokenInput struct {
+	Context      context.Context
+	RefreshToken string
+}
+
+type ConsumeOAuthTokenByRefreshTokenOutput struct {
+	OAuthToken *auth.OAuthToken
+	Error      error
+}
+
+type ListOAuthTokensByUserIDInput struct {
+	Context context.Context
+	UserID  string
+}
+
+type ListOAuthTokensByUserIDOutput struct {
+	OAuthTokens []*auth.OAuthToken
+	Error       error
+}
+
+type DeleteOAuthTokenInput struct {
+	Context  context.Context
+	ClientID string
+	Token    string
+}
+
+type DeleteOAuthTokensByClientIDInput struct {
+	Context  context.Context
+	ClientID string
+}
+
+type DeleteOAuthTokensByUserIDInput struct {
+	Context context.Context
+	UserID  string
+}
+
+type OAuthTokenSession struct {
+	*test.Closer
+	CreateOAuthTokenInvocations                int
+	CreateOAuthTokenInputs                     []CreateOAuthTokenInput
+	CreateOAuthTokenOutputs                    []error
+	GetOAuthTokenByAccessTokenInvocations      int
+	GetOAuthTokenByAccessTokenInputs           []GetOAuthTokenByAccessTokenInput
+	GetOAuthTokenByAccessTokenOutputs          []GetOAuthTokenByAccessTokenOutput
+	GetOAuthTokenByRefreshTokenInvocations     int
+	GetOAuthTokenByRefreshTokenInputs          []GetOAuthTokenByRefreshTokenInput
+	GetOAuthTokenByRefreshTokenOutputs         []GetOAuthTokenByRefreshTokenOutput
+	ConsumeOAuthTokenByRefreshTokenInvocations int
+	ConsumeOAuthTokenByRefreshTokenInputs      []ConsumeOAuthTokenByRefreshTokenInput
+	ConsumeOAuthTokenByRefreshTokenOutputs     []ConsumeOAuthTokenByRefreshTokenOutput
+	ListOAuthTokensByUserIDInvocations         int
+	ListOAuthTokensByUserIDInputs              []ListOAuthTokensByUserIDInput
+	ListOAuthTokensByUserIDOutputs             []ListOAuthTokensByUserIDOutput
+	DeleteOAuthTokenInvocations                int
+	DeleteOAuthTokenInputs                     []DeleteOAuthTokenInput
+	DeleteOAuthTokenOutputs                    []error
+	DeleteOAuthTokensByClientIDInvocations     int
+	DeleteOAuthTokensByClientIDInputs          []DeleteOAuthTokensByClientIDInput
+	DeleteOAuthTokensByClientIDOutputs         []error
+	DeleteOAuthTokensByUserIDInvocations       int
+	DeleteOAuthTokensByUserIDInputs            []DeleteOAuthTokensByUserIDInput
+	DeleteOAuthTokensByUserIDOutputs           []error
+}
+
+func NewOAuthTokenSession() *OAuthTokenSession {
+	return &OAuthTokenSession{
+		Closer: test.NewCloser(),
+	}
+}
+
+func (o *OAuthTokenSession) CreateOAuthToken(ctx context.Context, oauthToken *auth.OAuthToken) error {
+	o.CreateOAuthTokenInvocations++
+
+	o.CreateOAuthTokenInputs = append(o.CreateOAuthTokenInputs, CreateOAuthTokenInput{Context: ctx, OAuthToken: oauthToken})
+
+	gomega.Expect(o.CreateOAuthTokenOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.CreateOAuthTokenOutputs[0]
+	o.CreateOAuthTokenOutputs = o.CreateOAuthTokenOutputs[1:]
+	return output
+}
+
+func (o *OAuthTokenSession) GetOAuthTokenByAccessToken(ctx context.Context, accessToken string) (*auth.OAuthToken, error) {
+	o.GetOAuthTokenByAccessTokenInvocations++
+
+	o.GetOAuthTokenByAccessTokenInputs = append(o.GetOAuthTokenByAccessTokenInputs, GetOAuthTokenByAccessTokenInput{Context: ctx, AccessToken: accessToken})
+
+	gomega.Expect(o.GetOAuthTokenByAccessTokenOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.GetOAuthTokenByAccessTokenOutputs[0]
+	o.GetOAuthTokenByAccessTokenOutputs = o.GetOAuthTokenByAccessTokenOutputs[1:]
+	return output.OAuthToken, output.Error
+}
+
+func (o *OAuthTokenSession) GetOAuthTokenByRefreshToken(ctx context.Context, refreshToken string) (*auth.OAuthToken, error) {
+	o.GetOAuthTokenByRefreshTokenInvocations++
+
+	o.GetOAuthTokenByRefreshTokenInputs = append(o.GetOAuthTokenByRefreshTokenInputs, GetOAuthTokenByRefreshTokenInput{Context: ctx, RefreshToken: refreshToken})
+
+	gomega.Expect(o.GetOAuthTokenByRefreshTokenOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.GetOAuthTokenByRefreshTokenOutputs[0]
+	o.GetOAuthTokenByRefreshTokenOutputs = o.GetOAuthTokenByRefreshTokenOutputs[1:]
+	return output.OAuthToken, output.Error
+}
+
+func (o *OAuthTokenSession) ConsumeOAuthTokenByRefreshToken(ctx context.Context, refreshToken string) (*auth.OAuthToken, error) {
+	o.ConsumeOAuthTokenByRefreshTokenInvocations++
+
+	o.ConsumeOAuthTokenByRefreshTokenInputs = append(o.ConsumeOAuthTokenByRefreshTokenInputs, ConsumeOAuthTokenByRefreshTokenInput{Context: ctx, RefreshToken: refreshToken})
+
+	gomega.Expect(o.ConsumeOAuthTokenByRefreshTokenOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.ConsumeOAuthTokenByRefreshTokenOutputs[0]
+	o.ConsumeOAuthTokenByRefreshTokenOutputs = o.ConsumeOAuthTokenByRefreshTokenOutputs[1:]
+	return output.OAuthToken, output.Error
+}
+
+func (o *OAuthTokenSession) ListOAuthTokensByUserID(ctx context.Context, userID string) ([]*auth.OAuthToken, error) {
+	o.ListOAuthTokensByUserIDInvocations++
+
+	o.ListOAuthTokensByUserIDInputs = append(o.ListOAuthTokensByUserIDInputs, ListOAuthTokensByUserIDInput{Context: ctx, UserID: userID})
+
+	gomega.Expect(o.ListOAuthTokensByUserIDOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.ListOAuthTokensByUserIDOutputs[0]
+	o.ListOAuthTokensByUserIDOutputs = o.ListOAuthTokensByUserIDOutputs[1:]
+	return output.OAuthTokens, output.Error
+}
+
+func (o *OAuthTokenSession) DeleteOAuthToken(ctx context.Context, clientID string, token string) error {
+	o.DeleteOAuthTokenInvocations++
+
+	o.DeleteOAuthTokenInputs = append(o.DeleteOAuthTokenInputs, DeleteOAuthTokenInput{Context: ctx, ClientID: clientID, Token: token})
+
+	gomega.Expect(o.DeleteOAuthTokenOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.DeleteOAuthTokenOutputs[0]
+	o.DeleteOAuthTokenOutputs = o.DeleteOAuthTokenOutputs[1:]
+	return output
+}
+
+func (o *OAuthTokenSession) DeleteOAuthTokensByClientID(ctx context.Context, clientID string) error {
+	o.DeleteOAuthTokensByClientIDInvocations++
+
+	o.DeleteOAuthTokensByClientIDInputs = append(o.DeleteOAuthTokensByClientIDInputs, DeleteOAuthTokensByClientIDInput{Context: ctx, ClientID: clientID})
+
+	gomega.Expect(o.DeleteOAuthTokensByClientIDOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.DeleteOAuthTokensByClientIDOutputs[0]
+	o.DeleteOAuthTokensByClientIDOutputs = o.DeleteOAuthTokensByClientIDOutputs[1:]
+	return output
+}
+
+func (o *OAuthTokenSession) DeleteOAuthTokensByUserID(ctx context.Context, userID string) error {
+	o.DeleteOAuthTokensByUserIDInvocations++
+
+	o.DeleteOAuthTokensByUserIDInputs = append(o.DeleteOAuthTokensByUserIDInputs, DeleteOAuthTokensByUserIDInput{Context: ctx, UserID: userID})
+
+	gomega.Expect(o.DeleteOAuthTokensByUserIDOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.DeleteOAuthTokensByUserIDOutputs[0]
+	o.DeleteOAuthTokensByUserIDOutputs = o.DeleteOAuthTokensByUserIDOutputs[1:]
+	return output
+}
+
+func (o *OAuthTokenSession) Expectations() {
+	o.Closer.AssertOutputsEmpty()
+	gomega.Expect(o.CreateOAuthTokenOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.GetOAuthTokenByAccessTokenOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.GetOAuthTokenByRefreshTokenOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.ConsumeOAuthTokenByRefreshTokenOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.ListOAuthTokensByUserIDOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.DeleteOAuthTokenOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.DeleteOAuthTokensByClientIDOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.DeleteOAuthTokensByUserIDOutputs).To(gomega.BeEmpty())
+}
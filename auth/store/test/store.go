@@ -5,16 +5,28 @@ import (
 )
 
 type Store struct {
-	NewProviderSessionSessionInvocations int
-	NewProviderSessionSessionImpl        *ProviderSessionSession
-	NewRestrictedTokenSessionInvocations int
-	NewRestrictedTokenSessionImpl        *RestrictedTokenSession
+	NewProviderSessionSessionInvocations        int
+	NewProviderSessionSessionImpl               *ProviderSessionSession
+	NewRestrictedTokenSessionInvocations        int
+	NewRestrictedTokenSessionImpl               *RestrictedTokenSession
+	NewOAuthClientSessionInvocations            int
+	NewOAuthClientSessionImpl                   *OAuthClientSession
+	NewOAuthAuthorizationCodeSessionInvocations int
+	NewOAuthAuthorizationCodeSessionImpl        *OAuthAuthorizationCodeSession
+	NewOAuthTokenSessionInvocations             int
+	NewOAuthTokenSessionImpl                    *OAuthTokenSession
+	NewAuditEntrySessionInvocations             int
+	NewAuditEntrySessionImpl                    *AuditEntrySession
 }
 
 func NewStore() *Store {
 	return &Store{
-		NewProviderSessionSessionImpl: NewProviderSessionSession(),
-		NewRestrictedTokenSessionImpl: NewRestrictedTokenSession(),
+		NewProviderSessionSessionImpl:        NewProviderSessionSession(),
+		NewRestrictedTokenSessionImpl:        NewRestrictedTokenSession(),
+		NewOAuthClientSessionImpl:            NewOAuthClientSession(),
+		NewOAuthAuthorizationCodeSessionImpl: NewOAuthAuthorizationCodeSession(),
+		NewOAuthTokenSessionImpl:             NewOAuthTokenSession(),
+		NewAuditEntrySessionImpl:             NewAuditEntrySession(),
 	}
 }
 
@@ -28,7 +40,31 @@ func (s *Store) NewRestrictedTokenSession() store.RestrictedTokenSession {
 	return s.NewRestrictedTokenSessionImpl
 }
 
+func (s *Store) NewOAuthClientSession() store.OAuthClientSession {
+	s.NewOAuthClientSessionInvocations++
+	return s.NewOAuthClientSessionImpl
+}
+
+func (s *Store) NewOAuthAuthorizationCodeSession() store.OAuthAuthorizationCodeSession {
+	s.NewOAuthAuthorizationCodeSessionInvocations++
+	return s.NewOAuthAuthorizationCodeSessionImpl
+}
+
+func (s *Store) NewOAuthTokenSession() store.OAuthTokenSession {
+	s.NewOAuthTokenSessionInvocations++
+	return s.NewOAuthTokenSessionImpl
+}
+
+func (s *Store) NewAuditEntrySession() store.AuditEntrySession {
+	s.NewAuditEntrySessionInvocations++
+	return s.NewAuditEntrySessionImpl
+}
+
 func (s *Store) Expectations() {
 	s.NewProviderSessionSessionImpl.Expectations()
 	s.NewRestrictedTokenSessionImpl.Expectations()
+	s.NewOAuthClientSessionImpl.Expectations()
+	s.NewOAuthAuthorizationCodeSessionImpl.Expectations()
+	s.NewOAuthTokenSessionImpl.Expectations()
+	s.NewAuditEntrySessionImpl.Expectations()
 }
@@ -0,0 +1,74 @@
+package test
+
+import (
+	"context"
+
+	"github.com/onsi/gomega"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/test"
+)
+
+type ListUserAuditEntriesInput struct {
+	Context    context.Context
+	UserID     string
+	Filter     *auth.AuditEntryFilter
+	Pagination *page.Pagination
+}
+
+type ListUserAuditEntriesOutput struct {
+	AuditEntries auth.AuditEntries
+	Error        error
+}
+
+type CreateAuditEntryInput struct {
+	Context    context.Context
+	AuditEntry *auth.AuditEntry
+}
+
+type AuditEntryAccessor struct {
+	*test.Mock
+	ListUserAuditEntriesInvocations int
+	ListUserAuditEntriesInputs      []ListUserAuditEntriesInput
+	ListUserAuditEntriesOutputs     []ListUserAuditEntriesOutput
+	CreateAuditEntryInvocations     int
+	CreateAuditEntryInputs          []CreateAuditEntryInput
+	CreateAuditEntryOutputs         []error
+}
+
+func NewAuditEntryAccessor() *AuditEntryAccessor {
+	return &AuditEntryAccessor{
+		Mock: test.NewMock(),
+	}
+}
+
+func (a *AuditEntryAccessor) ListUserAuditEntries(ctx context.Context, userID string, filter *auth.AuditEntryFilter, pagination *page.Pagination) (auth.AuditEntries, error) {
+	a.ListUserAuditEntriesInvocations++
+
+	a.ListUserAuditEntriesInputs = append(a.ListUserAuditEntriesInputs, ListUserAuditEntriesInput{Context: ctx, UserID: userID, Filter: filter, Pagination: pagination})
+
+	gomega.Expect(a.ListUserAuditEntriesOutputs).ToNot(gomega.BeEmpty())
+
+	output := a.ListUserAuditEntriesOutputs[0]
+	a.ListUserAuditEntriesOutputs = a.ListUserAuditEntriesOutputs[1:]
+	return output.AuditEntries, output.Error
+}
+
+func (a *AuditEntryAccessor) CreateAuditEntry(ctx context.Context, auditEntry *auth.AuditEntry) error {
+	a.CreateAuditEntryInvocations++
+
+	a.CreateAuditEntryInputs = append(a.CreateAuditEntryInputs, CreateAuditEntryInput{Context: ctx, AuditEntry: auditEntry})
+
+	gomega.Expect(a.CreateAuditEntryOutputs).ToNot(gomega.BeEmpty())
+
+	output := a.CreateAuditEntryOutputs[0]
+	a.CreateAuditEntryOutputs = a.CreateAuditEntryOutputs[1:]
+	return output
+}
+
+func (a *AuditEntryAccessor) Expectations() {
+	a.Mock.Expectations()
+	gomega.Expect(a.ListUserAuditEntriesOutputs).To(gomega.BeEmpty())
+	gomega.Expect(a.CreateAuditEntryOutputs).To(gomega.BeEmpty())
+}
@@ -3,6 +3,7 @@ package test
 type Client struct {
 	*ProviderSessionAccessor
 	*RestrictedTokenAccessor
+	*OAuthAccessor
 	*ExternalAccessor
 }
 
@@ -10,6 +11,7 @@ func NewClient() *Client {
 	return &Client{
 		ProviderSessionAccessor: NewProviderSessionAccessor(),
 		RestrictedTokenAccessor: NewRestrictedTokenAccessor(),
+		OAuthAccessor:           NewOAuthAccessor(),
 		ExternalAccessor:        NewExternalAccessor(),
 	}
 }
@@ -17,5 +19,6 @@ func NewClient() *Client {
 func (c *Client) Expectations() {
 	c.ProviderSessionAccessor.Expectations()
 	c.RestrictedTokenAccessor.Expectations()
+	c.OAuthAccessor.Expectations()
 	c.ExternalAccessor.Expectations()
 }
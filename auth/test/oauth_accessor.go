@@ -0,0 +1,170 @@
+package test
+
+import (
+	"context"
+
+	"github.com/onsi/gomega"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/test"
+)
+
+type CreateOAuthClientInput struct {
+	Context context.Context
+	Create  *auth.OAuthClientCreate
+}
+
+type CreateOAuthClientOutput struct {
+	OAuthClient *auth.OAuthClient
+	Error       error
+}
+
+type GetOAuthClientInput struct {
+	Context context.Context
+	ID      string
+}
+
+type GetOAuthClientOutput struct {
+	OAuthClient *auth.OAuthClient
+	Error       error
+}
+
+type DeleteOAuthClientInput struct {
+	Context context.Context
+	ID      string
+}
+
+type ValidateOAuthAccessTokenInput struct {
+	Context     context.Context
+	AccessToken string
+}
+
+type ValidateOAuthAccessTokenOutput struct {
+	OAuthToken *auth.OAuthToken
+	Error      error
+}
+
+type ListUserOAuthGrantsInput struct {
+	Context context.Context
+	UserID  string
+}
+
+type ListUserOAuthGrantsOutput struct {
+	OAuthTokens []*auth.OAuthToken
+	Error       error
+}
+
+type DeleteUserOAuthGrantsInput struct {
+	Context context.Context
+	UserID  string
+}
+
+type OAuthAccessor struct {
+	*test.Mock
+	CreateOAuthClientInvocations        int
+	CreateOAuthClientInputs             []CreateOAuthClientInput
+	CreateOAuthClientOutputs            []CreateOAuthClientOutput
+	GetOAuthClientInvocations           int
+	GetOAuthClientInputs                []GetOAuthClientInput
+	GetOAuthClientOutputs               []GetOAuthClientOutput
+	DeleteOAuthClientInvocations        int
+	DeleteOAuthClientInputs             []DeleteOAuthClientInput
+	DeleteOAuthClientOutputs            []error
+	ValidateOAuthAccessTokenInvocations int
+	ValidateOAuthAccessTokenInputs      []ValidateOAuthAccessTokenInput
+	ValidateOAuthAccessTokenOutputs     []ValidateOAuthAccessTokenOutput
+	ListUserOAuthGrantsInvocations      int
+	ListUserOAuthGrantsInputs           []ListUserOAuthGrantsInput
+	ListUserOAuthGrantsOutputs          []ListUserOAuthGrantsOutput
+	DeleteUserOAuthGrantsInvocations    int
+	DeleteUserOAuthGrantsInputs         []DeleteUserOAuthGrantsInput
+	DeleteUserOAuthGrantsOutputs        []error
+}
+
+func NewOAuthAccessor() *OAuthAccessor {
+	return &OAuthAccessor{
+		Mock: test.NewMock(),
+	}
+}
+
+func (o *OAuthAccessor) CreateOAuthClient(ctx context.Context, create *auth.OAuthClientCreate) (*auth.OAuthClient, error) {
+	o.CreateOAuthClientInvocations++
+
+	o.CreateOAuthClientInputs = append(o.CreateOAuthClientInputs, CreateOAuthClientInput{Context: ctx, Create: create})
+
+	gomega.Expect(o.CreateOAuthClientOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.CreateOAuthClientOutputs[0]
+	o.CreateOAuthClientOutputs = o.CreateOAuthClientOutputs[1:]
+	return output.OAuthClient, output.Error
+}
+
+func (o *OAuthAccessor) GetOAuthClient(ctx context.Context, id string) (*auth.OAuthClient, error) {
+	o.GetOAuthClientInvocations++
+
+	o.GetOAuthClientInputs = append(o.GetOAuthClientInputs, GetOAuthClientInput{Context: ctx, ID: id})
+
+	gomega.Expect(o.GetOAuthClientOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.GetOAuthClientOutputs[0]
+	o.GetOAuthClientOutputs = o.GetOAuthClientOutputs[1:]
+	return output.OAuthClient, output.Error
+}
+
+func (o *OAuthAccessor) DeleteOAuthClient(ctx context.Context, id string) error {
+	o.DeleteOAuthClientInvocations++
+
+	o.DeleteOAuthClientInputs = append(o.DeleteOAuthClientInputs, DeleteOAuthClientInput{Context: ctx, ID: id})
+
+	gomega.Expect(o.DeleteOAuthClientOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.DeleteOAuthClientOutputs[0]
+	o.DeleteOAuthClientOutputs = o.DeleteOAuthClientOutputs[1:]
+	return output
+}
+
+func (o *OAuthAccessor) ValidateOAuthAccessToken(ctx context.Context, accessToken string) (*auth.OAuthToken, error) {
+	o.ValidateOAuthAccessTokenInvocations++
+
+	o.ValidateOAuthAccessTokenInputs = append(o.ValidateOAuthAccessTokenInputs, ValidateOAuthAccessTokenInput{Context: ctx, AccessToken: accessToken})
+
+	gomega.Expect(o.ValidateOAuthAccessTokenOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.ValidateOAuthAccessTokenOutputs[0]
+	o.ValidateOAuthAccessTokenOutputs = o.ValidateOAuthAccessTokenOutputs[1:]
+	return output.OAuthToken, output.Error
+}
+
+func (o *OAuthAccessor) ListUserOAuthGrants(ctx context.Context, userID string) ([]*auth.OAuthToken, error) {
+	o.ListUserOAuthGrantsInvocations++
+
+	o.ListUserOAuthGrantsInputs = append(o.ListUserOAuthGrantsInputs, ListUserOAuthGrantsInput{Context: ctx, UserID: userID})
+
+	gomega.Expect(o.ListUserOAuthGrantsOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.ListUserOAuthGrantsOutputs[0]
+	o.ListUserOAuthGrantsOutputs = o.ListUserOAuthGrantsOutputs[1:]
+	return output.OAuthTokens, output.Error
+}
+
+func (o *OAuthAccessor) DeleteUserOAuthGrants(ctx context.Context, userID string) error {
+	o.DeleteUserOAuthGrantsInvocations++
+
+	o.DeleteUserOAuthGrantsInputs = append(o.DeleteUserOAuthGrantsInputs, DeleteUserOAuthGrantsInput{Context: ctx, UserID: userID})
+
+	gomega.Expect(o.DeleteUserOAuthGrantsOutputs).ToNot(gomega.BeEmpty())
+
+	output := o.DeleteUserOAuthGrantsOutputs[0]
+	o.DeleteUserOAuthGrantsOutputs = o.DeleteUserOAuthGrantsOutputs[1:]
+	return output
+}
+
+func (o *OAuthAccessor) Expectations() {
+	o.Mock.Expectations()
+	gomega.Expect(o.CreateOAuthClientOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.GetOAuthClientOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.DeleteOAuthClientOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.ValidateOAuthAccessTokenOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.ListUserOAuthGrantsOutputs).To(gomega.BeEmpty())
+	gomega.Expect(o.DeleteUserOAuthGrantsOutputs).To(gomega.BeEmpty())
+}
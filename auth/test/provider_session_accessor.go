@@ -10,6 +10,17 @@ import (
 	"github.com/tidepool-org/platform/test"
 )
 
+type ListProviderSessionsInput struct {
+	Context    context.Context
+	Filter     *auth.ProviderSessionFilter
+	Pagination *page.Pagination
+}
+
+type ListProviderSessionsOutput struct {
+	ProviderSessions auth.ProviderSessions
+	Error            error
+}
+
 type ListUserProviderSessionsInput struct {
 	Context    context.Context
 	UserID     string
@@ -59,8 +70,28 @@ type DeleteProviderSessionInput struct {
 	ID      string
 }
 
+type LockProviderSessionInput struct {
+	Context context.Context
+	ID      string
+	Lock    *auth.ProviderSessionLock
+}
+
+type LockProviderSessionOutput struct {
+	ProviderSession *auth.ProviderSession
+	Error           error
+}
+
+type UnlockProviderSessionInput struct {
+	Context context.Context
+	ID      string
+	LockID  string
+}
+
 type ProviderSessionAccessor struct {
 	*test.Mock
+	ListProviderSessionsInvocations      int
+	ListProviderSessionsInputs           []ListProviderSessionsInput
+	ListProviderSessionsOutputs          []ListProviderSessionsOutput
 	ListUserProviderSessionsInvocations  int
 	ListUserProviderSessionsInputs       []ListUserProviderSessionsInput
 	ListUserProviderSessionsOutputs      []ListUserProviderSessionsOutput
@@ -76,6 +107,12 @@ type ProviderSessionAccessor struct {
 	DeleteProviderSessionInvocations     int
 	DeleteProviderSessionInputs          []DeleteProviderSessionInput
 	DeleteProviderSessionOutputs         []error
+	LockProviderSessionInvocations       int
+	LockProviderSessionInputs            []LockProviderSessionInput
+	LockProviderSessionOutputs           []LockProviderSessionOutput
+	UnlockProviderSessionInvocations     int
+	UnlockProviderSessionInputs          []UnlockProviderSessionInput
+	UnlockProviderSessionOutputs         []error
 }
 
 func NewProviderSessionAccessor() *ProviderSessionAccessor {
@@ -84,6 +121,18 @@ func NewProviderSessionAccessor() *ProviderSessionAccessor {
 	}
 }
 
+func (p *ProviderSessionAccessor) ListProviderSessions(ctx context.Context, filter *auth.ProviderSessionFilter, pagination *page.Pagination) (auth.ProviderSessions, error) {
+	p.ListProviderSessionsInvocations++
+
+	p.ListProviderSessionsInputs = append(p.ListProviderSessionsInputs, ListProviderSessionsInput{Context: ctx, Filter: filter, Pagination: pagination})
+
+	gomega.Expect(p.ListProviderSessionsOutputs).ToNot(gomega.BeEmpty())
+
+	output := p.ListProviderSessionsOutputs[0]
+	p.ListProviderSessionsOutputs = p.ListProviderSessionsOutputs[1:]
+	return output.ProviderSessions, output.Error
+}
+
 func (p *ProviderSessionAccessor) ListUserProviderSessions(ctx context.Context, userID string, filter *auth.ProviderSessionFilter, pagination *page.Pagination) (auth.ProviderSessions, error) {
 	p.ListUserProviderSessionsInvocations++
 
@@ -144,10 +193,37 @@ func (p *ProviderSessionAccessor) DeleteProviderSession(ctx context.Context, id
 	return output
 }
 
+func (p *ProviderSessionAccessor) LockProviderSession(ctx context.Context, id string, lock *auth.ProviderSessionLock) (*auth.ProviderSession, error) {
+	p.LockProviderSessionInvocations++
+
+	p.LockProviderSessionInputs = append(p.LockProviderSessionInputs, LockProviderSessionInput{Context: ctx, ID: id, Lock: lock})
+
+	gomega.Expect(p.LockProviderSessionOutputs).ToNot(gomega.BeEmpty())
+
+	output := p.LockProviderSessionOutputs[0]
+	p.LockProviderSessionOutputs = p.LockProviderSessionOutputs[1:]
+	return output.ProviderSession, output.Error
+}
+
+func (p *ProviderSessionAccessor) UnlockProviderSession(ctx context.Context, id string, lockID string) error {
+	p.UnlockProviderSessionInvocations++
+
+	p.UnlockProviderSessionInputs = append(p.UnlockProviderSessionInputs, UnlockProviderSessionInput{Context: ctx, ID: id, LockID: lockID})
+
+	gomega.Expect(p.UnlockProviderSessionOutputs).ToNot(gomega.BeEmpty())
+
+	output := p.UnlockProviderSessionOutputs[0]
+	p.UnlockProviderSessionOutputs = p.UnlockProviderSessionOutputs[1:]
+	return output
+}
+
 func (p *ProviderSessionAccessor) Expectations() {
 	p.Mock.Expectations()
+	gomega.Expect(p.ListProviderSessionsOutputs).To(gomega.BeEmpty())
 	gomega.Expect(p.ListUserProviderSessionsOutputs).To(gomega.BeEmpty())
 	gomega.Expect(p.CreateUserProviderSessionOutputs).To(gomega.BeEmpty())
 	gomega.Expect(p.GetProviderSessionOutputs).To(gomega.BeEmpty())
 	gomega.Expect(p.UpdateProviderSessionOutputs).To(gomega.BeEmpty())
+	gomega.Expect(p.LockProviderSessionOutputs).To(gomega.BeEmpty())
+	gomega.Expect(p.UnlockProviderSessionOutputs).To(gomega.BeEmpty())
 }
@@ -59,6 +59,17 @@ type DeleteRestrictedTokenInput struct {
 	ID      string
 }
 
+type UseRestrictedTokenInput struct {
+	Context context.Context
+	ID      string
+	Use     *auth.RestrictedTokenUse
+}
+
+type UseRestrictedTokenOutput struct {
+	RestrictedToken *auth.RestrictedToken
+	Error           error
+}
+
 type RestrictedTokenAccessor struct {
 	*test.Mock
 	ListUserRestrictedTokensInvocations  int
@@ -76,6 +87,9 @@ type RestrictedTokenAccessor struct {
 	DeleteRestrictedTokenInvocations     int
 	DeleteRestrictedTokenInputs          []DeleteRestrictedTokenInput
 	DeleteRestrictedTokenOutputs         []error
+	UseRestrictedTokenInvocations        int
+	UseRestrictedTokenInputs             []UseRestrictedTokenInput
+	UseRestrictedTokenOutputs            []UseRestrictedTokenOutput
 }
 
 func NewRestrictedTokenAccessor() *RestrictedTokenAccessor {
@@ -144,10 +158,24 @@ func (r *RestrictedTokenAccessor) DeleteRestrictedToken(ctx context.Context, id
 	return output
 }
 
+func (r *RestrictedTokenAccessor) UseRestrictedToken(ctx context.Context, id string, use *auth.RestrictedTokenUse) (*auth.RestrictedToken, error) {
+	r.UseRestrictedTokenInvocations++
+
+	r.UseRestrictedTokenInputs = append(r.UseRestrictedTokenInputs, UseRestrictedTokenInput{Context: ctx, ID: id, Use: use})
+
+	gomega.Expect(r.UseRestrictedTokenOutputs).ToNot(gomega.BeEmpty())
+
+	output := r.UseRestrictedTokenOutputs[0]
+	r.UseRestrictedTokenOutputs = r.UseRestrictedTokenOutputs[1:]
+	return output.RestrictedToken, output.Error
+}
+
 func (r *RestrictedTokenAccessor) Expectations() {
 	r.Mock.Expectations()
 	gomega.Expect(r.ListUserRestrictedTokensOutputs).To(gomega.BeEmpty())
 	gomega.Expect(r.CreateUserRestrictedTokenOutputs).To(gomega.BeEmpty())
 	gomega.Expect(r.GetRestrictedTokenOutputs).To(gomega.BeEmpty())
 	gomega.Expect(r.UpdateRestrictedTokenOutputs).To(gomega.BeEmpty())
+	gomega.Expect(r.DeleteRestrictedTokenOutputs).To(gomega.BeEmpty())
+	gomega.Expect(r.UseRestrictedTokenOutputs).To(gomega.BeEmpty())
 }
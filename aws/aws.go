@@ -1,6 +1,8 @@
 package aws
 
 import (
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
@@ -16,6 +18,30 @@ func String(value string) *string {
 	return aws.String(value)
 }
 
+func StringValue(value *string) string {
+	return aws.StringValue(value)
+}
+
+func StringMap(value map[string]string) map[string]*string {
+	return aws.StringMap(value)
+}
+
+func StringValueMap(value map[string]*string) map[string]string {
+	return aws.StringValueMap(value)
+}
+
+func BoolValue(value *bool) bool {
+	return aws.BoolValue(value)
+}
+
+func Int64Value(value *int64) int64 {
+	return aws.Int64Value(value)
+}
+
+func TimeValue(value *time.Time) time.Time {
+	return aws.TimeValue(value)
+}
+
 func NewWriteAtBuffer(bytes []byte) *aws.WriteAtBuffer {
 	return aws.NewWriteAtBuffer(bytes)
 }
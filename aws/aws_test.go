@@ -5,6 +5,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/tidepool-org/platform/aws"
+	"github.com/tidepool-org/platform/pointer"
 	"github.com/tidepool-org/platform/test"
 )
 
@@ -18,6 +19,60 @@ var _ = Describe("AWS", func() {
 		})
 	})
 
+	Context("StringValue", func() {
+		It("returns the empty string if the value is nil", func() {
+			Expect(aws.StringValue(nil)).To(BeEmpty())
+		})
+
+		It("returns the value", func() {
+			value := test.RandomString()
+			Expect(aws.StringValue(&value)).To(Equal(value))
+		})
+	})
+
+	Context("StringMap and StringValueMap", func() {
+		It("omits nil values", func() {
+			Expect(aws.StringValueMap(map[string]*string{"a": nil})).To(BeEmpty())
+		})
+
+		It("returns the map of values", func() {
+			value := map[string]string{test.RandomString(): test.RandomString()}
+			Expect(aws.StringValueMap(aws.StringMap(value))).To(Equal(value))
+		})
+	})
+
+	Context("BoolValue", func() {
+		It("returns false if the value is nil", func() {
+			Expect(aws.BoolValue(nil)).To(BeFalse())
+		})
+
+		It("returns the value", func() {
+			Expect(aws.BoolValue(pointer.FromBool(true))).To(BeTrue())
+		})
+	})
+
+	Context("Int64Value", func() {
+		It("returns zero if the value is nil", func() {
+			Expect(aws.Int64Value(nil)).To(BeZero())
+		})
+
+		It("returns the value", func() {
+			value := int64(test.RandomInt())
+			Expect(aws.Int64Value(&value)).To(Equal(value))
+		})
+	})
+
+	Context("TimeValue", func() {
+		It("returns zero if the value is nil", func() {
+			Expect(aws.TimeValue(nil)).To(BeZero())
+		})
+
+		It("returns the value", func() {
+			value := test.RandomTime()
+			Expect(aws.TimeValue(&value)).To(Equal(value))
+		})
+	})
+
 	Context("NewWriteAtBuffer", func() {
 		It("returns successfully with nil bytes", func() {
 			Expect(aws.NewWriteAtBuffer(nil)).ToNot(BeNil())
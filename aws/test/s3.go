@@ -29,19 +29,61 @@ type DeleteObjectWithContextOutput struct {
 	Error  error
 }
 
+type CopyObjectWithContextInput struct {
+	Context aws.Context
+	Input   *s3.CopyObjectInput
+	Options []request.Option
+}
+
+type CopyObjectWithContextOutput struct {
+	Output *s3.CopyObjectOutput
+	Error  error
+}
+
+type ListObjectsV2WithContextInput struct {
+	Context aws.Context
+	Input   *s3.ListObjectsV2Input
+	Options []request.Option
+}
+
+type ListObjectsV2WithContextOutput struct {
+	Output *s3.ListObjectsV2Output
+	Error  error
+}
+
+type GetObjectRequestOutput struct {
+	Request *request.Request
+	Output  *s3.GetObjectOutput
+}
+
 type S3 struct {
 	s3iface.S3API
 
-	HeadObjectWithContextInvocations   int
-	HeadObjectWithContextInputs        []HeadObjectWithContextInput
-	HeadObjectWithContextStub          func(ctx aws.Context, input *s3.HeadObjectInput, options ...request.Option) (*s3.HeadObjectOutput, error)
-	HeadObjectWithContextOutputs       []HeadObjectWithContextOutput
-	HeadObjectWithContextOutput        *HeadObjectWithContextOutput
-	DeleteObjectWithContextInvocations int
-	DeleteObjectWithContextInputs      []DeleteObjectWithContextInput
-	DeleteObjectWithContextStub        func(ctx aws.Context, input *s3.DeleteObjectInput, options ...request.Option) (*s3.DeleteObjectOutput, error)
-	DeleteObjectWithContextOutputs     []DeleteObjectWithContextOutput
-	DeleteObjectWithContextOutput      *DeleteObjectWithContextOutput
+	HeadObjectWithContextInvocations    int
+	HeadObjectWithContextInputs         []HeadObjectWithContextInput
+	HeadObjectWithContextStub           func(ctx aws.Context, input *s3.HeadObjectInput, options ...request.Option) (*s3.HeadObjectOutput, error)
+	HeadObjectWithContextOutputs        []HeadObjectWithContextOutput
+	HeadObjectWithContextOutput         *HeadObjectWithContextOutput
+	DeleteObjectWithContextInvocations  int
+	DeleteObjectWithContextInputs       []DeleteObjectWithContextInput
+	DeleteObjectWithContextStub         func(ctx aws.Context, input *s3.DeleteObjectInput, options ...request.Option) (*s3.DeleteObjectOutput, error)
+	DeleteObjectWithContextOutputs      []DeleteObjectWithContextOutput
+	DeleteObjectWithContextOutput       *DeleteObjectWithContextOutput
+	CopyObjectWithContextInvocations    int
+	CopyObjectWithContextInputs         []CopyObjectWithContextInput
+	CopyObjectWithContextStub           func(ctx aws.Context, input *s3.CopyObjectInput, options ...request.Option) (*s3.CopyObjectOutput, error)
+	CopyObjectWithContextOutputs        []CopyObjectWithContextOutput
+	CopyObjectWithContextOutput         *CopyObjectWithContextOutput
+	ListObjectsV2WithContextInvocations int
+	ListObjectsV2WithContextInputs      []ListObjectsV2WithContextInput
+	ListObjectsV2WithContextStub        func(ctx aws.Context, input *s3.ListObjectsV2Input, options ...request.Option) (*s3.ListObjectsV2Output, error)
+	ListObjectsV2WithContextOutputs     []ListObjectsV2WithContextOutput
+	ListObjectsV2WithContextOutput      *ListObjectsV2WithContextOutput
+	GetObjectRequestInvocations         int
+	GetObjectRequestInputs              []*s3.GetObjectInput
+	GetObjectRequestStub                func(input *s3.GetObjectInput) (*request.Request, *s3.GetObjectOutput)
+	GetObjectRequestOutputs             []GetObjectRequestOutput
+	GetObjectRequestOutput              *GetObjectRequestOutput
 }
 
 func NewS3() *S3 {
@@ -82,6 +124,57 @@ func (s *S3) DeleteObjectWithContext(ctx aws.Context, input *s3.DeleteObjectInpu
 	panic("DeleteObjectWithContext has no output")
 }
 
+func (s *S3) CopyObjectWithContext(ctx aws.Context, input *s3.CopyObjectInput, options ...request.Option) (*s3.CopyObjectOutput, error) {
+	s.CopyObjectWithContextInvocations++
+	s.CopyObjectWithContextInputs = append(s.CopyObjectWithContextInputs, CopyObjectWithContextInput{Context: ctx, Input: input, Options: options})
+	if s.CopyObjectWithContextStub != nil {
+		return s.CopyObjectWithContextStub(ctx, input, options...)
+	}
+	if len(s.CopyObjectWithContextOutputs) > 0 {
+		output := s.CopyObjectWithContextOutputs[0]
+		s.CopyObjectWithContextOutputs = s.CopyObjectWithContextOutputs[1:]
+		return output.Output, output.Error
+	}
+	if s.CopyObjectWithContextOutput != nil {
+		return s.CopyObjectWithContextOutput.Output, s.CopyObjectWithContextOutput.Error
+	}
+	panic("CopyObjectWithContext has no output")
+}
+
+func (s *S3) ListObjectsV2WithContext(ctx aws.Context, input *s3.ListObjectsV2Input, options ...request.Option) (*s3.ListObjectsV2Output, error) {
+	s.ListObjectsV2WithContextInvocations++
+	s.ListObjectsV2WithContextInputs = append(s.ListObjectsV2WithContextInputs, ListObjectsV2WithContextInput{Context: ctx, Input: input, Options: options})
+	if s.ListObjectsV2WithContextStub != nil {
+		return s.ListObjectsV2WithContextStub(ctx, input, options...)
+	}
+	if len(s.ListObjectsV2WithContextOutputs) > 0 {
+		output := s.ListObjectsV2WithContextOutputs[0]
+		s.ListObjectsV2WithContextOutputs = s.ListObjectsV2WithContextOutputs[1:]
+		return output.Output, output.Error
+	}
+	if s.ListObjectsV2WithContextOutput != nil {
+		return s.ListObjectsV2WithContextOutput.Output, s.ListObjectsV2WithContextOutput.Error
+	}
+	panic("ListObjectsV2WithContext has no output")
+}
+
+func (s *S3) GetObjectRequest(input *s3.GetObjectInput) (*request.Request, *s3.GetObjectOutput) {
+	s.GetObjectRequestInvocations++
+	s.GetObjectRequestInputs = append(s.GetObjectRequestInputs, input)
+	if s.GetObjectRequestStub != nil {
+		return s.GetObjectRequestStub(input)
+	}
+	if len(s.GetObjectRequestOutputs) > 0 {
+		output := s.GetObjectRequestOutputs[0]
+		s.GetObjectRequestOutputs = s.GetObjectRequestOutputs[1:]
+		return output.Request, output.Output
+	}
+	if s.GetObjectRequestOutput != nil {
+		return s.GetObjectRequestOutput.Request, s.GetObjectRequestOutput.Output
+	}
+	panic("GetObjectRequest has no output")
+}
+
 func (s *S3) AssertOutputsEmpty() {
 	if len(s.HeadObjectWithContextOutputs) > 0 {
 		panic("HeadObjectWithContextOutputs is not empty")
@@ -89,4 +182,13 @@ func (s *S3) AssertOutputsEmpty() {
 	if len(s.DeleteObjectWithContextOutputs) > 0 {
 		panic("DeleteObjectWithContextOutputs is not empty")
 	}
+	if len(s.CopyObjectWithContextOutputs) > 0 {
+		panic("CopyObjectWithContextOutputs is not empty")
+	}
+	if len(s.ListObjectsV2WithContextOutputs) > 0 {
+		panic("ListObjectsV2WithContextOutputs is not empty")
+	}
+	if len(s.GetObjectRequestOutputs) > 0 {
+		panic("GetObjectRequestOutputs is not empty")
+	}
 }
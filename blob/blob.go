@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/tidepool-org/platform/crypto"
@@ -20,20 +21,47 @@ import (
 )
 
 const (
-	ErrorCodeDigestsNotEqual = "digests-not-equal"
+	ErrorCodeDigestsNotEqual     = "digests-not-equal"
+	ErrorCodeMediaTypeNotMatched = "media-type-not-matched"
+	ErrorCodeSizeExceedsMaximum  = "size-exceeds-maximum"
+	ErrorCodeStatusNotAvailable  = "status-not-available"
 
-	StatusAvailable = "available"
-	StatusCreated   = "created"
+	HeaderExpirationTime = "X-Tidepool-Expiration-Time"
+	HeaderSkipInspection = "X-Tidepool-Skip-Inspection"
+
+	StatusAvailable   = "available"
+	StatusCreated     = "created"
+	StatusQuarantined = "quarantined"
+
+	StatusCreatedTimeout = time.Hour
+
+	LinkExpirationDurationDefault = 15 * time.Minute
+	LinkExpirationDurationMaximum = 7 * 24 * time.Hour
+
+	SizeMaximum = 100 * 1024 * 1024
 )
 
 func ErrorDigestsNotEqual(value string, calculated string) error {
 	return errors.Preparedf(ErrorCodeDigestsNotEqual, "digests not equal", "digest %q does not equal calculated digest %q", value, calculated)
 }
 
+func ErrorMediaTypeNotMatched(value string, detected string) error {
+	return errors.Preparedf(ErrorCodeMediaTypeNotMatched, "media type not matched", "media type %q does not match detected media type %q", value, detected)
+}
+
+func ErrorSizeExceedsMaximum(maximum int) error {
+	return errors.Preparedf(ErrorCodeSizeExceedsMaximum, "size exceeds maximum", "size exceeds maximum of %d bytes", maximum)
+}
+
+func ErrorStatusNotAvailable(status string) error {
+	return errors.Preparedf(ErrorCodeStatusNotAvailable, "status not available", "status %q is not available", status)
+}
+
 func Statuses() []string {
 	return []string{
 		StatusAvailable,
 		StatusCreated,
+		StatusQuarantined,
 	}
 }
 
@@ -43,6 +71,11 @@ type Client interface {
 	Get(ctx context.Context, id string) (*Blob, error)
 	GetContent(ctx context.Context, id string) (*Content, error)
 	Delete(ctx context.Context, id string) (bool, error)
+
+	ListExpired(ctx context.Context, pagination *page.Pagination) (Blobs, error)
+
+	CreateLink(ctx context.Context, id string, create *LinkCreate) (*Link, error)
+	GetLinkContent(ctx context.Context, id string, query *LinkQuery) (*Content, error)
 }
 
 type Filter struct {
@@ -79,10 +112,14 @@ func (f *Filter) MutateRequest(req *http.Request) error {
 	return request.NewArrayParametersMutator(parameters).MutateRequest(req)
 }
 
+// Create creates a blob. Only a service may skip inspection of the content, such as for content generated by the
+// platform rather than uploaded by a user.
 type Create struct {
-	Body      io.Reader
-	DigestMD5 *string
-	MediaType *string
+	Body           io.Reader
+	DigestMD5      *string
+	MediaType      *string
+	ExpirationTime *time.Time
+	SkipInspection *bool
 }
 
 func NewCreate() *Create {
@@ -95,6 +132,7 @@ func (c *Create) Validate(validator structure.Validator) {
 	}
 	validator.String("digestMD5", c.DigestMD5).Using(crypto.Base64EncodedMD5HashValidator)
 	validator.String("mediaType", c.MediaType).Exists().Using(net.MediaTypeValidator)
+	validator.Time("expirationTime", c.ExpirationTime).AfterNow(time.Second)
 }
 
 type Content struct {
@@ -118,14 +156,15 @@ func (c *Content) Validate(validator structure.Validator) {
 }
 
 type Blob struct {
-	ID           *string    `json:"id,omitempty" bson:"id,omitempty"`
-	UserID       *string    `json:"userId,omitempty" bson:"userId,omitempty"`
-	DigestMD5    *string    `json:"digestMD5,omitempty" bson:"digestMD5,omitempty"`
-	MediaType    *string    `json:"mediaType,omitempty" bson:"mediaType,omitempty"`
-	Size         *int       `json:"size,omitempty" bson:"size,omitempty"`
-	Status       *string    `json:"status,omitempty" bson:"status,omitempty"`
-	CreatedTime  *time.Time `json:"createdTime,omitempty" bson:"createdTime,omitempty"`
-	ModifiedTime *time.Time `json:"modifiedTime,omitempty" bson:"modifiedTime,omitempty"`
+	ID             *string    `json:"id,omitempty" bson:"id,omitempty"`
+	UserID         *string    `json:"userId,omitempty" bson:"userId,omitempty"`
+	DigestMD5      *string    `json:"digestMD5,omitempty" bson:"digestMD5,omitempty"`
+	MediaType      *string    `json:"mediaType,omitempty" bson:"mediaType,omitempty"`
+	Size           *int       `json:"size,omitempty" bson:"size,omitempty"`
+	Status         *string    `json:"status,omitempty" bson:"status,omitempty"`
+	ExpirationTime *time.Time `json:"expirationTime,omitempty" bson:"expirationTime,omitempty"`
+	CreatedTime    *time.Time `json:"createdTime,omitempty" bson:"createdTime,omitempty"`
+	ModifiedTime   *time.Time `json:"modifiedTime,omitempty" bson:"modifiedTime,omitempty"`
 }
 
 func (b *Blob) Parse(parser structure.ObjectParser) {
@@ -135,6 +174,7 @@ func (b *Blob) Parse(parser structure.ObjectParser) {
 	b.MediaType = parser.String("mediaType")
 	b.Size = parser.Int("size")
 	b.Status = parser.String("status")
+	b.ExpirationTime = parser.Time("expirationTime", time.RFC3339)
 	b.CreatedTime = parser.Time("createdTime", time.RFC3339)
 	b.ModifiedTime = parser.Time("modifiedTime", time.RFC3339)
 }
@@ -146,12 +186,106 @@ func (b *Blob) Validate(validator structure.Validator) {
 	validator.String("mediaType", b.MediaType).Exists().Using(net.MediaTypeValidator)
 	validator.Int("size", b.Size).Exists().GreaterThanOrEqualTo(0)
 	validator.String("status", b.Status).Exists().OneOf(Statuses()...)
+	validator.Time("expirationTime", b.ExpirationTime).After(pointer.ToTime(b.CreatedTime))
 	validator.Time("createdTime", b.CreatedTime).Exists().NotZero().BeforeNow(time.Second)
 	validator.Time("modifiedTime", b.ModifiedTime).After(pointer.ToTime(b.CreatedTime)).BeforeNow(time.Second)
 }
 
+func (b *Blob) IsExpired() bool {
+	now := time.Now()
+	if b.ExpirationTime != nil && !b.ExpirationTime.After(now) {
+		return true
+	}
+	if b.Status != nil && *b.Status == StatusCreated && b.CreatedTime != nil && b.CreatedTime.Add(StatusCreatedTimeout).Before(now) {
+		return true
+	}
+	return false
+}
+
+func (b *Blob) IsQuarantined() bool {
+	return b.Status != nil && *b.Status == StatusQuarantined
+}
+
 type Blobs []*Blob
 
+type LinkCreate struct {
+	ExpirationTime *time.Time `json:"expirationTime,omitempty"`
+	SingleUse      *bool      `json:"singleUse,omitempty"`
+}
+
+func NewLinkCreate() *LinkCreate {
+	return &LinkCreate{}
+}
+
+func (l *LinkCreate) Parse(parser structure.ObjectParser) {
+	l.ExpirationTime = parser.Time("expirationTime", time.RFC3339)
+	l.SingleUse = parser.Bool("singleUse")
+}
+
+func (l *LinkCreate) Validate(validator structure.Validator) {
+	validator.Time("expirationTime", l.ExpirationTime).AfterNow(time.Second).Before(time.Now().Add(LinkExpirationDurationMaximum))
+}
+
+type Link struct {
+	URL            *string    `json:"url,omitempty"`
+	ExpirationTime *time.Time `json:"expirationTime,omitempty"`
+	SingleUse      *bool      `json:"singleUse,omitempty"`
+}
+
+func (l *Link) Parse(parser structure.ObjectParser) {
+	l.URL = parser.String("url")
+	l.ExpirationTime = parser.Time("expirationTime", time.RFC3339)
+	l.SingleUse = parser.Bool("singleUse")
+}
+
+func (l *Link) Validate(validator structure.Validator) {
+	validator.String("url", l.URL).Exists().NotEmpty()
+	validator.Time("expirationTime", l.ExpirationTime).Exists().NotZero()
+	validator.Bool("singleUse", l.SingleUse).Exists()
+}
+
+type LinkQuery struct {
+	ExpirationTime *time.Time
+	SingleUse      *bool
+	Nonce          *string
+	Signature      *string
+}
+
+func NewLinkQuery() *LinkQuery {
+	return &LinkQuery{}
+}
+
+func (l *LinkQuery) Parse(parser structure.ObjectParser) {
+	l.ExpirationTime = parser.Time("expirationTime", time.RFC3339)
+	l.SingleUse = parser.Bool("singleUse")
+	l.Nonce = parser.String("nonce")
+	l.Signature = parser.String("signature")
+}
+
+func (l *LinkQuery) Validate(validator structure.Validator) {
+	validator.Time("expirationTime", l.ExpirationTime).Exists().NotZero()
+	validator.Bool("singleUse", l.SingleUse).Exists()
+	validator.String("nonce", l.Nonce).Exists().NotEmpty()
+	validator.String("signature", l.Signature).Exists().NotEmpty()
+}
+
+func (l *LinkQuery) MutateRequest(req *http.Request) error {
+	parameters := map[string]string{}
+	if l.ExpirationTime != nil {
+		parameters["expirationTime"] = l.ExpirationTime.Format(time.RFC3339)
+	}
+	if l.SingleUse != nil {
+		parameters["singleUse"] = strconv.FormatBool(*l.SingleUse)
+	}
+	if l.Nonce != nil {
+		parameters["nonce"] = *l.Nonce
+	}
+	if l.Signature != nil {
+		parameters["signature"] = *l.Signature
+	}
+	return request.NewParametersMutator(parameters).MutateRequest(req)
+}
+
 func NewID() string {
 	return id.Must(id.New(16))
 }
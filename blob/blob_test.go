@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/tidepool-org/platform/blob"
@@ -50,11 +51,14 @@ var _ = Describe("Blob", func() {
 			errorsTest.ExpectErrorDetails,
 			Entry("is ErrorDigestsNotEqual with empty string", blob.ErrorDigestsNotEqual("", ""), "digests-not-equal", "digests not equal", `digest "" does not equal calculated digest ""`),
 			Entry("is ErrorDigestsNotEqual with non-empty string", blob.ErrorDigestsNotEqual("QUJDREVGSElKS0xNTk9QUQ==", "lah2klptWl+IBNSepXlJ9Q=="), "digests-not-equal", "digests not equal", `digest "QUJDREVGSElKS0xNTk9QUQ==" does not equal calculated digest "lah2klptWl+IBNSepXlJ9Q=="`),
+			Entry("is ErrorMediaTypeNotMatched", blob.ErrorMediaTypeNotMatched("image/png", "text/html"), "media-type-not-matched", "media type not matched", `media type "image/png" does not match detected media type "text/html"`),
+			Entry("is ErrorSizeExceedsMaximum", blob.ErrorSizeExceedsMaximum(1024), "size-exceeds-maximum", "size exceeds maximum", "size exceeds maximum of 1024 bytes"),
+			Entry("is ErrorStatusNotAvailable", blob.ErrorStatusNotAvailable("created"), "status-not-available", "status not available", `status "created" is not available`),
 		)
 	})
 
 	It("Statuses returns expected", func() {
-		Expect(blob.Statuses()).To(Equal([]string{"available", "created"}))
+		Expect(blob.Statuses()).To(Equal([]string{"available", "created", "quarantined"}))
 	})
 
 	Context("Filter", func() {
@@ -230,6 +234,9 @@ var _ = Describe("Blob", func() {
 				Entry("status available and created",
 					func(datum *blob.Filter) { datum.Status = pointer.FromStringArray([]string{"available", "created"}) },
 				),
+				Entry("status quarantined",
+					func(datum *blob.Filter) { datum.Status = pointer.FromStringArray([]string{"quarantined"}) },
+				),
 				Entry("multiple errors",
 					func(datum *blob.Filter) {
 						datum.MediaType = pointer.FromStringArray([]string{})
@@ -336,6 +343,16 @@ var _ = Describe("Blob", func() {
 				Entry("media type valid",
 					func(datum *blob.Create) { datum.MediaType = pointer.FromString(netTest.RandomMediaType()) },
 				),
+				Entry("expiration time missing",
+					func(datum *blob.Create) { datum.ExpirationTime = nil },
+				),
+				Entry("expiration time before now",
+					func(datum *blob.Create) { datum.ExpirationTime = pointer.FromTime(nearPastTime) },
+					errorsTest.WithPointerSource(structureValidator.ErrorValueTimeNotAfterNow(nearPastTime), "/expirationTime"),
+				),
+				Entry("expiration time valid",
+					func(datum *blob.Create) { datum.ExpirationTime = pointer.FromTime(futureTime) },
+				),
 				Entry("multiple errors",
 					func(datum *blob.Create) {
 						datum.Body = nil
@@ -615,6 +632,33 @@ var _ = Describe("Blob", func() {
 						expectedDatum.Status = pointer.FromString(valid)
 					},
 				),
+				Entry("expiration time missing",
+					func(object map[string]interface{}, expectedDatum *blob.Blob) {
+						delete(object, "expirationTime")
+						expectedDatum.ExpirationTime = nil
+					},
+				),
+				Entry("expiration time invalid type",
+					func(object map[string]interface{}, expectedDatum *blob.Blob) {
+						object["expirationTime"] = true
+						expectedDatum.ExpirationTime = nil
+					},
+					errorsTest.WithPointerSource(structureParser.ErrorTypeNotTime(true), "/expirationTime"),
+				),
+				Entry("expiration time invalid",
+					func(object map[string]interface{}, expectedDatum *blob.Blob) {
+						object["expirationTime"] = "invalid"
+						expectedDatum.ExpirationTime = nil
+					},
+					errorsTest.WithPointerSource(structureParser.ErrorValueTimeNotParsable("invalid", time.RFC3339), "/expirationTime"),
+				),
+				Entry("expiration time valid",
+					func(object map[string]interface{}, expectedDatum *blob.Blob) {
+						valid := test.RandomTimeFromRange(time.Now(), test.RandomTimeMaximum()).Truncate(time.Second)
+						object["expirationTime"] = valid.Format(time.RFC3339)
+						expectedDatum.ExpirationTime = pointer.FromTime(valid)
+					},
+				),
 				Entry("created time missing",
 					func(object map[string]interface{}, expectedDatum *blob.Blob) {
 						delete(object, "createdTime")
@@ -799,6 +843,20 @@ var _ = Describe("Blob", func() {
 				Entry("status available",
 					func(datum *blob.Blob) { datum.Status = pointer.FromString("available") },
 				),
+				Entry("expiration time missing",
+					func(datum *blob.Blob) { datum.ExpirationTime = nil },
+				),
+				Entry("expiration time before created time",
+					func(datum *blob.Blob) {
+						datum.CreatedTime = pointer.FromTime(nearPastTime)
+						datum.ModifiedTime = nil
+						datum.ExpirationTime = pointer.FromTime(farPastTime)
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueTimeNotAfter(farPastTime, nearPastTime), "/expirationTime"),
+				),
+				Entry("expiration time valid",
+					func(datum *blob.Blob) { datum.ExpirationTime = pointer.FromTime(futureTime) },
+				),
 				Entry("created time missing",
 					func(datum *blob.Blob) { datum.CreatedTime = nil },
 					errorsTest.WithPointerSource(structureValidator.ErrorValueNotExists(), "/createdTime"),
@@ -861,6 +919,146 @@ var _ = Describe("Blob", func() {
 				),
 			)
 		})
+
+		Context("IsExpired", func() {
+			var datum *blob.Blob
+
+			BeforeEach(func() {
+				datum = blobTest.RandomBlob()
+				datum.Status = pointer.FromString(blob.StatusAvailable)
+				datum.ExpirationTime = nil
+			})
+
+			It("returns false if the expiration time is missing", func() {
+				Expect(datum.IsExpired()).To(BeFalse())
+			})
+
+			It("returns false if the expiration time is after now", func() {
+				datum.ExpirationTime = pointer.FromTime(futureTime)
+				Expect(datum.IsExpired()).To(BeFalse())
+			})
+
+			It("returns true if the expiration time is before now", func() {
+				datum.ExpirationTime = pointer.FromTime(nearPastTime)
+				Expect(datum.IsExpired()).To(BeTrue())
+			})
+
+			It("returns false if the status is created and the created time is within the created status timeout", func() {
+				datum.Status = pointer.FromString(blob.StatusCreated)
+				datum.CreatedTime = pointer.FromTime(time.Now().Add(-blob.StatusCreatedTimeout / 2))
+				Expect(datum.IsExpired()).To(BeFalse())
+			})
+
+			It("returns true if the status is created and the created time is beyond the created status timeout", func() {
+				datum.Status = pointer.FromString(blob.StatusCreated)
+				datum.CreatedTime = pointer.FromTime(time.Now().Add(-2 * blob.StatusCreatedTimeout))
+				Expect(datum.IsExpired()).To(BeTrue())
+			})
+
+			It("returns false if the status is available and the created time is beyond the created status timeout", func() {
+				datum.CreatedTime = pointer.FromTime(time.Now().Add(-2 * blob.StatusCreatedTimeout))
+				Expect(datum.IsExpired()).To(BeFalse())
+			})
+		})
+	})
+
+	Context("NewLinkCreate", func() {
+		It("returns successfully with default values", func() {
+			Expect(blob.NewLinkCreate()).To(Equal(&blob.LinkCreate{}))
+		})
+	})
+
+	Context("LinkCreate", func() {
+		Context("Validate", func() {
+			DescribeTable("validates the datum",
+				func(mutator func(datum *blob.LinkCreate), expectedErrors ...error) {
+					datum := blobTest.RandomLinkCreate()
+					mutator(datum)
+					errorsTest.ExpectEqual(structureValidator.New().Validate(datum), expectedErrors...)
+				},
+				Entry("succeeds",
+					func(datum *blob.LinkCreate) {},
+				),
+				Entry("expiration time missing",
+					func(datum *blob.LinkCreate) { datum.ExpirationTime = nil },
+				),
+				Entry("expiration time not after now",
+					func(datum *blob.LinkCreate) { datum.ExpirationTime = pointer.FromTime(nearPastTime) },
+					errorsTest.WithPointerSource(structureValidator.ErrorValueTimeNotAfterNow(nearPastTime), "/expirationTime"),
+				),
+				Entry("single use missing",
+					func(datum *blob.LinkCreate) { datum.SingleUse = nil },
+				),
+			)
+
+			It("reports an error when the expiration time is beyond the maximum link expiration duration", func() {
+				datum := blobTest.RandomLinkCreate()
+				datum.ExpirationTime = pointer.FromTime(futureTime)
+				Expect(structureValidator.New().Validate(datum)).To(HaveOccurred())
+			})
+		})
+	})
+
+	Context("NewLinkQuery", func() {
+		It("returns successfully with default values", func() {
+			Expect(blob.NewLinkQuery()).To(Equal(&blob.LinkQuery{}))
+		})
+	})
+
+	Context("LinkQuery", func() {
+		Context("Validate", func() {
+			DescribeTable("validates the datum",
+				func(mutator func(datum *blob.LinkQuery), expectedErrors ...error) {
+					datum := blobTest.RandomLinkQuery()
+					mutator(datum)
+					errorsTest.ExpectEqual(structureValidator.New().Validate(datum), expectedErrors...)
+				},
+				Entry("succeeds",
+					func(datum *blob.LinkQuery) {},
+				),
+				Entry("multiple errors",
+					func(datum *blob.LinkQuery) {
+						datum.ExpirationTime = nil
+						datum.SingleUse = nil
+						datum.Nonce = nil
+						datum.Signature = pointer.FromString("")
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueNotExists(), "/expirationTime"),
+					errorsTest.WithPointerSource(structureValidator.ErrorValueNotExists(), "/singleUse"),
+					errorsTest.WithPointerSource(structureValidator.ErrorValueNotExists(), "/nonce"),
+					errorsTest.WithPointerSource(structureValidator.ErrorValueEmpty(), "/signature"),
+				),
+			)
+		})
+
+		Context("MutateRequest", func() {
+			var query *blob.LinkQuery
+			var req *http.Request
+
+			BeforeEach(func() {
+				query = blobTest.RandomLinkQuery()
+				req = testHttp.NewRequest()
+			})
+
+			It("returns an error when the request is missing", func() {
+				errorsTest.ExpectEqual(query.MutateRequest(nil), errors.New("request is missing"))
+			})
+
+			It("sets request query as expected", func() {
+				Expect(query.MutateRequest(req)).To(Succeed())
+				Expect(req.URL.Query()).To(Equal(url.Values{
+					"expirationTime": []string{query.ExpirationTime.Format(time.RFC3339)},
+					"singleUse":      []string{strconv.FormatBool(*query.SingleUse)},
+					"nonce":          []string{*query.Nonce},
+					"signature":      []string{*query.Signature},
+				}))
+			})
+
+			It("does not set request query when the query is empty", func() {
+				Expect(blob.NewLinkQuery().MutateRequest(req)).To(Succeed())
+				Expect(req.URL.Query()).To(BeEmpty())
+			})
+		})
 	})
 
 	Context("NewID", func() {
@@ -0,0 +1,3 @@
+package cleanup
+
+const Type = "org.tidepool.blob.cleanup"
@@ -0,0 +1,120 @@
+package cleanup
+
+import (
+	"context"
+	"time"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/blob"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/task"
+)
+
+const (
+	AvailableAfterDuration = 15 * time.Minute
+	PageCountMaximum       = 10
+	PageSize               = 100
+)
+
+type Runner struct {
+	logger     log.Logger
+	authClient auth.Client
+	blobClient blob.Client
+}
+
+func NewRunner(logger log.Logger, authClient auth.Client, blobClient blob.Client) (*Runner, error) {
+	if logger == nil {
+		return nil, errors.New("logger is missing")
+	}
+	if authClient == nil {
+		return nil, errors.New("auth client is missing")
+	}
+	if blobClient == nil {
+		return nil, errors.New("blob client is missing")
+	}
+
+	return &Runner{
+		logger:     logger,
+		authClient: authClient,
+		blobClient: blobClient,
+	}, nil
+}
+
+func (r *Runner) Logger() log.Logger {
+	return r.logger
+}
+
+func (r *Runner) AuthClient() auth.Client {
+	return r.authClient
+}
+
+func (r *Runner) BlobClient() blob.Client {
+	return r.blobClient
+}
+
+func (r *Runner) CanRunTask(tsk *task.Task) bool {
+	return tsk != nil && tsk.Type == Type
+}
+
+func (r *Runner) Run(ctx context.Context, tsk *task.Task) {
+	ctx = log.NewContextWithLogger(ctx, r.Logger())
+
+	tsk.ClearError()
+
+	if serverSessionToken, err := r.AuthClient().ServerSessionToken(); err != nil {
+		tsk.AppendError(errors.Wrap(err, "unable to get server session token"))
+	} else if err = r.cleanup(auth.NewContextWithServerSessionToken(ctx, serverSessionToken)); err != nil {
+		tsk.AppendError(errors.Wrap(err, "unable to cleanup expired blobs"))
+	}
+
+	if !tsk.IsFailed() {
+		tsk.RepeatAvailableAfter(AvailableAfterDuration)
+	}
+}
+
+func (r *Runner) cleanup(ctx context.Context) error {
+	ids, err := r.listExpiredIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	var deleted int
+	for _, id := range ids {
+		if _, err = r.BlobClient().Delete(ctx, id); err != nil {
+			r.Logger().WithError(err).WithField("id", id).Error("Unable to delete expired blob")
+		} else {
+			deleted++
+		}
+	}
+
+	r.Logger().WithFields(log.Fields{"expired": len(ids), "deleted": deleted}).Debug("Cleaned up expired blobs")
+	return nil
+}
+
+// Collect all ids before deleting any blobs as deleting would otherwise shift subsequent pages
+func (r *Runner) listExpiredIDs(ctx context.Context) ([]string, error) {
+	ids := []string{}
+
+	pagination := page.NewPagination()
+	pagination.Size = PageSize
+	for ; pagination.Page < PageCountMaximum; pagination.Page++ {
+		blbs, err := r.BlobClient().ListExpired(ctx, pagination)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, blb := range blbs {
+			if blb.ID != nil {
+				ids = append(ids, *blb.ID)
+			}
+		}
+
+		if len(blbs) < pagination.Size {
+			break
+		}
+	}
+
+	return ids, nil
+}
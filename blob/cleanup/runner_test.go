@@ -0,0 +1,152 @@
+package cleanup_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"time"
+
+	"github.com/tidepool-org/platform/auth"
+	authTest "github.com/tidepool-org/platform/auth/test"
+	"github.com/tidepool-org/platform/blob"
+	blobCleanup "github.com/tidepool-org/platform/blob/cleanup"
+	blobTest "github.com/tidepool-org/platform/blob/test"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	logTest "github.com/tidepool-org/platform/log/test"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/task"
+	"github.com/tidepool-org/platform/test"
+)
+
+var _ = Describe("Runner", func() {
+	var logger *logTest.Logger
+	var authClient *authTest.Client
+	var blobClient *blobTest.Client
+
+	BeforeEach(func() {
+		logger = logTest.NewLogger()
+		authClient = authTest.NewClient()
+		blobClient = blobTest.NewClient()
+	})
+
+	AfterEach(func() {
+		blobClient.AssertOutputsEmpty()
+		authClient.Expectations()
+	})
+
+	Context("NewRunner", func() {
+		It("returns an error if the logger is missing", func() {
+			rnnr, err := blobCleanup.NewRunner(nil, authClient, blobClient)
+			Expect(err).To(MatchError("logger is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the auth client is missing", func() {
+			rnnr, err := blobCleanup.NewRunner(logger, nil, blobClient)
+			Expect(err).To(MatchError("auth client is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the blob client is missing", func() {
+			rnnr, err := blobCleanup.NewRunner(logger, authClient, nil)
+			Expect(err).To(MatchError("blob client is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			Expect(blobCleanup.NewRunner(logger, authClient, blobClient)).ToNot(BeNil())
+		})
+	})
+
+	Context("with new runner", func() {
+		var rnnr *blobCleanup.Runner
+
+		BeforeEach(func() {
+			var err error
+			rnnr, err = blobCleanup.NewRunner(logger, authClient, blobClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rnnr).ToNot(BeNil())
+		})
+
+		Context("CanRunTask", func() {
+			It("returns false if the task is missing", func() {
+				Expect(rnnr.CanRunTask(nil)).To(BeFalse())
+			})
+
+			It("returns false if the task type does not match", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: test.RandomString()})).To(BeFalse())
+			})
+
+			It("returns true if the task type matches", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: blobCleanup.Type})).To(BeTrue())
+			})
+		})
+
+		Context("Run", func() {
+			var ctx context.Context
+			var tsk *task.Task
+			var serverSessionToken string
+
+			BeforeEach(func() {
+				var err error
+				ctx = context.Background()
+				tsk, err = task.NewTask(blobCleanup.NewTaskCreate())
+				Expect(err).ToNot(HaveOccurred())
+				tsk.State = task.TaskStateRunning
+				serverSessionToken = authTest.NewSessionToken()
+			})
+
+			It("records the error if the server session token returns an error", func() {
+				authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: "", Error: errorsTest.NewError()}}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.State).To(Equal(task.TaskStatePending))
+			})
+
+			Context("with server session token", func() {
+				BeforeEach(func() {
+					authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: serverSessionToken, Error: nil}}
+				})
+
+				It("records the error if list expired returns an error", func() {
+					blobClient.ListExpiredOutputs = []blobTest.ListExpiredOutput{{Blobs: nil, Error: errorsTest.NewError()}}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeTrue())
+					Expect(blobClient.DeleteInvocations).To(Equal(0))
+				})
+
+				It("returns successfully when there are no expired blobs", func() {
+					blobClient.ListExpiredOutputs = []blobTest.ListExpiredOutput{{Blobs: blob.Blobs{}, Error: nil}}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeFalse())
+					Expect(tsk.State).To(Equal(task.TaskStatePending))
+					Expect(tsk.AvailableTime).ToNot(BeNil())
+					Expect(*tsk.AvailableTime).To(BeTemporally("~", time.Now().Add(blobCleanup.AvailableAfterDuration), time.Second))
+					Expect(blobClient.ListExpiredInputs).To(HaveLen(1))
+					Expect(auth.ServerSessionTokenFromContext(blobClient.ListExpiredInputs[0].Context)).To(Equal(serverSessionToken))
+					Expect(blobClient.ListExpiredInputs[0].Pagination).To(Equal(&page.Pagination{Page: 0, Size: blobCleanup.PageSize}))
+				})
+
+				It("deletes all expired blobs across multiple pages and continues after a delete error", func() {
+					firstBlobs := blobTest.RandomBlobs(blobCleanup.PageSize, blobCleanup.PageSize)
+					secondBlobs := blobTest.RandomBlobs(1, 3)
+					blobClient.ListExpiredOutputs = []blobTest.ListExpiredOutput{{Blobs: firstBlobs, Error: nil}, {Blobs: secondBlobs, Error: nil}}
+					deleteOutputs := []blobTest.DeleteOutput{{Deleted: false, Error: errorsTest.NewError()}}
+					for index := 1; index < len(firstBlobs)+len(secondBlobs); index++ {
+						deleteOutputs = append(deleteOutputs, blobTest.DeleteOutput{Deleted: true, Error: nil})
+					}
+					blobClient.DeleteOutputs = deleteOutputs
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeFalse())
+					Expect(blobClient.ListExpiredInputs).To(HaveLen(2))
+					Expect(blobClient.ListExpiredInputs[1].Pagination.Page).To(Equal(1))
+					Expect(blobClient.DeleteInputs).To(HaveLen(len(firstBlobs) + len(secondBlobs)))
+					for index, blb := range append(firstBlobs, secondBlobs...) {
+						Expect(blobClient.DeleteInputs[index].ID).To(Equal(*blb.ID))
+					}
+				})
+			})
+		})
+	})
+})
@@ -0,0 +1,17 @@
+package cleanup
+
+import (
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+)
+
+func TaskName() string {
+	return Type
+}
+
+func NewTaskCreate() *task.TaskCreate {
+	return &task.TaskCreate{
+		Name: pointer.FromString(TaskName()),
+		Type: Type,
+	}
+}
@@ -0,0 +1,27 @@
+package cleanup_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	blobCleanup "github.com/tidepool-org/platform/blob/cleanup"
+)
+
+var _ = Describe("Task", func() {
+	Context("TaskName", func() {
+		It("returns the type", func() {
+			Expect(blobCleanup.TaskName()).To(Equal(blobCleanup.Type))
+		})
+	})
+
+	Context("NewTaskCreate", func() {
+		It("returns successfully", func() {
+			taskCreate := blobCleanup.NewTaskCreate()
+			Expect(taskCreate).ToNot(BeNil())
+			Expect(taskCreate.Name).ToNot(BeNil())
+			Expect(*taskCreate.Name).To(Equal(blobCleanup.TaskName()))
+			Expect(taskCreate.Type).To(Equal(blobCleanup.Type))
+			Expect(taskCreate.Data).To(BeEmpty())
+		})
+	})
+})
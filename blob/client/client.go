@@ -3,7 +3,10 @@ package client
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/tidepool-org/platform/blob"
 	"github.com/tidepool-org/platform/errors"
@@ -80,6 +83,12 @@ func (c *Client) Create(ctx context.Context, userID string, create *blob.Create)
 	if create.MediaType != nil {
 		mutators = append(mutators, request.NewHeaderMutator("Content-Type", *create.MediaType))
 	}
+	if create.ExpirationTime != nil {
+		mutators = append(mutators, request.NewHeaderMutator(blob.HeaderExpirationTime, create.ExpirationTime.Format(time.RFC3339)))
+	}
+	if create.SkipInspection != nil {
+		mutators = append(mutators, request.NewHeaderMutator(blob.HeaderSkipInspection, strconv.FormatBool(*create.SkipInspection)))
+	}
 
 	url := c.client.ConstructURL("v1", "users", userID, "blobs")
 	blb := &blob.Blob{}
@@ -132,25 +141,7 @@ func (c *Client) GetContent(ctx context.Context, id string) (*blob.Content, erro
 		return nil, err
 	}
 
-	digestMD5, err := request.ParseDigestMD5Header(headersInspector.Headers, "Digest")
-	if err != nil {
-		return nil, err
-	}
-	mediaType, err := request.ParseMediaTypeHeader(headersInspector.Headers, "Content-Type")
-	if err != nil {
-		return nil, err
-	}
-	size, err := request.ParseIntHeader(headersInspector.Headers, "Content-Length")
-	if err != nil {
-		return nil, err
-	}
-
-	return &blob.Content{
-		Body:      body,
-		DigestMD5: digestMD5,
-		MediaType: mediaType,
-		Size:      size,
-	}, nil
+	return newContent(body, headersInspector.Headers)
 }
 
 func (c *Client) Delete(ctx context.Context, id string) (bool, error) {
@@ -173,3 +164,99 @@ func (c *Client) Delete(ctx context.Context, id string) (bool, error) {
 
 	return true, nil
 }
+
+func (c *Client) ListExpired(ctx context.Context, pagination *page.Pagination) (blob.Blobs, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if pagination == nil {
+		pagination = page.NewPagination()
+	} else if err := structureValidator.New().Validate(pagination); err != nil {
+		return nil, errors.Wrap(err, "pagination is invalid")
+	}
+
+	url := c.client.ConstructURL("v1", "blobs", "expired")
+	blbs := blob.Blobs{}
+	if err := c.client.RequestData(ctx, http.MethodGet, url, []request.RequestMutator{pagination}, nil, &blbs); err != nil {
+		return nil, err
+	}
+
+	return blbs, nil
+}
+
+func (c *Client) CreateLink(ctx context.Context, id string, create *blob.LinkCreate) (*blob.Link, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if id == "" {
+		return nil, errors.New("id is missing")
+	} else if !blob.IsValidID(id) {
+		return nil, errors.New("id is invalid")
+	}
+	if create == nil {
+		create = blob.NewLinkCreate()
+	} else if err := structureValidator.New().Validate(create); err != nil {
+		return nil, errors.Wrap(err, "create is invalid")
+	}
+
+	url := c.client.ConstructURL("v1", "blobs", id, "links")
+	link := &blob.Link{}
+	if err := c.client.RequestData(ctx, http.MethodPost, url, nil, create, link); err != nil {
+		if request.IsErrorResourceNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return link, nil
+}
+
+func (c *Client) GetLinkContent(ctx context.Context, id string, query *blob.LinkQuery) (*blob.Content, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if id == "" {
+		return nil, errors.New("id is missing")
+	} else if !blob.IsValidID(id) {
+		return nil, errors.New("id is invalid")
+	}
+	if query == nil {
+		return nil, errors.New("query is missing")
+	} else if err := structureValidator.New().Validate(query); err != nil {
+		return nil, errors.Wrap(err, "query is invalid")
+	}
+
+	headersInspector := request.NewHeadersInspector()
+	url := c.client.ConstructURL("v1", "blobs", id, "links", "content")
+	body, err := c.client.RequestStream(ctx, http.MethodGet, url, []request.RequestMutator{query}, nil, headersInspector)
+	if err != nil {
+		if request.IsErrorResourceNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return newContent(body, headersInspector.Headers)
+}
+
+func newContent(body io.ReadCloser, headers http.Header) (*blob.Content, error) {
+	digestMD5, err := request.ParseDigestMD5Header(headers, "Digest")
+	if err != nil {
+		return nil, err
+	}
+	mediaType, err := request.ParseMediaTypeHeader(headers, "Content-Type")
+	if err != nil {
+		return nil, err
+	}
+	size, err := request.ParseIntHeader(headers, "Content-Length")
+	if err != nil {
+		return nil, err
+	}
+
+	return &blob.Content{
+		Body:      body,
+		DigestMD5: digestMD5,
+		MediaType: mediaType,
+		Size:      size,
+	}, nil
+}
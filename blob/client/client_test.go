@@ -7,10 +7,13 @@ import (
 
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	authTest "github.com/tidepool-org/platform/auth/test"
 	"github.com/tidepool-org/platform/blob"
@@ -387,6 +390,105 @@ var _ = Describe("Client", func() {
 
 						createAssertions()
 					})
+
+					When("the request has an expiration time header", func() {
+						BeforeEach(func() {
+							create.ExpirationTime = pointer.FromTime(time.Now().Add(time.Hour).Truncate(time.Second))
+							requestHandlers = append(requestHandlers, VerifyHeaderKV(blob.HeaderExpirationTime, create.ExpirationTime.Format(time.RFC3339)))
+						})
+
+						createAssertions()
+					})
+				})
+			})
+
+			Context("ListExpired", func() {
+				var pagination *page.Pagination
+
+				listExpiredAssertions := func() {
+					Context("without server response", func() {
+						AfterEach(func() {
+							Expect(server.ReceivedRequests()).To(BeEmpty())
+						})
+
+						It("returns an error when the context is missing", func() {
+							ctx = nil
+							blbs, err := client.ListExpired(ctx, pagination)
+							errorsTest.ExpectEqual(err, errors.New("context is missing"))
+							Expect(blbs).To(BeNil())
+						})
+
+						It("returns an error when the pagination is invalid", func() {
+							pagination = page.NewPagination()
+							pagination.Page = -1
+							blbs, err := client.ListExpired(ctx, pagination)
+							errorsTest.ExpectEqual(err, errors.New("pagination is invalid"))
+							Expect(blbs).To(BeNil())
+						})
+					})
+
+					Context("with server response", func() {
+						BeforeEach(func() {
+							requestHandlers = append(requestHandlers, VerifyContentType(""), VerifyBody(nil))
+						})
+
+						AfterEach(func() {
+							Expect(server.ReceivedRequests()).To(HaveLen(1))
+						})
+
+						When("the server responds with an unauthorized error", func() {
+							BeforeEach(func() {
+								requestHandlers = append(requestHandlers, RespondWithJSONEncoded(http.StatusForbidden, errors.Serializable{Error: request.ErrorUnauthorized()}, responseHeaders))
+							})
+
+							It("returns an error", func() {
+								blbs, err := client.ListExpired(ctx, pagination)
+								errorsTest.ExpectEqual(err, request.ErrorUnauthorized())
+								Expect(blbs).To(BeNil())
+							})
+						})
+
+						When("the server responds with blobs", func() {
+							var responseBlobs blob.Blobs
+
+							BeforeEach(func() {
+								responseBlobs = blobTest.RandomBlobs(1, 4)
+								requestHandlers = append(requestHandlers, RespondWithJSONEncoded(http.StatusOK, responseBlobs, responseHeaders))
+							})
+
+							It("returns successfully", func() {
+								blbs, err := client.ListExpired(ctx, pagination)
+								Expect(err).ToNot(HaveOccurred())
+								blobTest.ExpectEqualBlobs(blbs, responseBlobs)
+							})
+						})
+					})
+				}
+
+				When("the request has no pagination", func() {
+					BeforeEach(func() {
+						pagination = nil
+						query := url.Values{
+							"page": []string{"0"},
+							"size": []string{"100"},
+						}
+						requestHandlers = append(requestHandlers, VerifyRequest("GET", "/v1/blobs/expired", query.Encode()))
+					})
+
+					listExpiredAssertions()
+				})
+
+				When("the request has a random pagination", func() {
+					BeforeEach(func() {
+						pagination = pageTest.RandomPagination()
+						query := url.Values{
+							"page": []string{strconv.Itoa(pagination.Page)},
+							"size": []string{strconv.Itoa(pagination.Size)},
+						}
+						requestHandlers = append(requestHandlers, VerifyRequest("GET", "/v1/blobs/expired", query.Encode()))
+					})
+
+					listExpiredAssertions()
 				})
 			})
 
@@ -712,6 +814,228 @@ var _ = Describe("Client", func() {
 						})
 					})
 				})
+
+				Context("CreateLink", func() {
+					var create *blob.LinkCreate
+
+					BeforeEach(func() {
+						create = blobTest.RandomLinkCreate()
+					})
+
+					Context("without server response", func() {
+						AfterEach(func() {
+							Expect(server.ReceivedRequests()).To(BeEmpty())
+						})
+
+						It("returns an error when the context is missing", func() {
+							ctx = nil
+							link, err := client.CreateLink(ctx, id, create)
+							errorsTest.ExpectEqual(err, errors.New("context is missing"))
+							Expect(link).To(BeNil())
+						})
+
+						It("returns an error when the id is missing", func() {
+							id = ""
+							link, err := client.CreateLink(ctx, id, create)
+							errorsTest.ExpectEqual(err, errors.New("id is missing"))
+							Expect(link).To(BeNil())
+						})
+
+						It("returns an error when the id is invalid", func() {
+							id = "invalid"
+							link, err := client.CreateLink(ctx, id, create)
+							errorsTest.ExpectEqual(err, errors.New("id is invalid"))
+							Expect(link).To(BeNil())
+						})
+
+						It("returns an error when the create is invalid", func() {
+							create.ExpirationTime = pointer.FromTime(time.Now().Add(-time.Hour))
+							link, err := client.CreateLink(ctx, id, create)
+							errorsTest.ExpectEqual(err, errors.New("create is invalid"))
+							Expect(link).To(BeNil())
+						})
+					})
+
+					Context("with server response", func() {
+						BeforeEach(func() {
+							createJSON, err := json.Marshal(create)
+							Expect(err).ToNot(HaveOccurred())
+							requestHandlers = append(requestHandlers,
+								VerifyRequest("POST", fmt.Sprintf("/v1/blobs/%s/links", id)),
+								VerifyContentType("application/json; charset=utf-8"),
+								func(res http.ResponseWriter, req *http.Request) {
+									body, err := ioutil.ReadAll(req.Body)
+									Expect(err).ToNot(HaveOccurred())
+									Expect(body).To(MatchJSON(createJSON))
+								},
+							)
+						})
+
+						AfterEach(func() {
+							Expect(server.ReceivedRequests()).To(HaveLen(1))
+						})
+
+						When("the server responds with an unauthorized error", func() {
+							BeforeEach(func() {
+								requestHandlers = append(requestHandlers, RespondWithJSONEncoded(http.StatusForbidden, errors.Serializable{Error: request.ErrorUnauthorized()}, responseHeaders))
+							})
+
+							It("returns an error", func() {
+								link, err := client.CreateLink(ctx, id, create)
+								errorsTest.ExpectEqual(err, request.ErrorUnauthorized())
+								Expect(link).To(BeNil())
+							})
+						})
+
+						When("the server responds with a not found error", func() {
+							BeforeEach(func() {
+								requestHandlers = append(requestHandlers, RespondWithJSONEncoded(http.StatusNotFound, errors.Serializable{Error: request.ErrorResourceNotFoundWithID(id)}, responseHeaders))
+							})
+
+							It("returns successfully without link", func() {
+								link, err := client.CreateLink(ctx, id, create)
+								Expect(err).ToNot(HaveOccurred())
+								Expect(link).To(BeNil())
+							})
+						})
+
+						When("the server responds with the link", func() {
+							var responseLink *blob.Link
+
+							BeforeEach(func() {
+								responseLink = blobTest.RandomLink()
+								requestHandlers = append(requestHandlers, RespondWithJSONEncoded(http.StatusCreated, responseLink, responseHeaders))
+							})
+
+							It("returns successfully with link", func() {
+								link, err := client.CreateLink(ctx, id, create)
+								Expect(err).ToNot(HaveOccurred())
+								blobTest.ExpectEqualLink(link, responseLink)
+							})
+						})
+					})
+				})
+
+				Context("GetLinkContent", func() {
+					var query *blob.LinkQuery
+
+					BeforeEach(func() {
+						query = blobTest.RandomLinkQuery()
+					})
+
+					Context("without server response", func() {
+						AfterEach(func() {
+							Expect(server.ReceivedRequests()).To(BeEmpty())
+						})
+
+						It("returns an error when the context is missing", func() {
+							ctx = nil
+							content, err := client.GetLinkContent(ctx, id, query)
+							errorsTest.ExpectEqual(err, errors.New("context is missing"))
+							Expect(content).To(BeNil())
+						})
+
+						It("returns an error when the id is missing", func() {
+							id = ""
+							content, err := client.GetLinkContent(ctx, id, query)
+							errorsTest.ExpectEqual(err, errors.New("id is missing"))
+							Expect(content).To(BeNil())
+						})
+
+						It("returns an error when the id is invalid", func() {
+							id = "invalid"
+							content, err := client.GetLinkContent(ctx, id, query)
+							errorsTest.ExpectEqual(err, errors.New("id is invalid"))
+							Expect(content).To(BeNil())
+						})
+
+						It("returns an error when the query is missing", func() {
+							content, err := client.GetLinkContent(ctx, id, nil)
+							errorsTest.ExpectEqual(err, errors.New("query is missing"))
+							Expect(content).To(BeNil())
+						})
+
+						It("returns an error when the query is invalid", func() {
+							query.Signature = nil
+							content, err := client.GetLinkContent(ctx, id, query)
+							errorsTest.ExpectEqual(err, errors.New("query is invalid"))
+							Expect(content).To(BeNil())
+						})
+					})
+
+					Context("with server response", func() {
+						BeforeEach(func() {
+							requestHandlers = append(requestHandlers,
+								VerifyRequest("GET", fmt.Sprintf("/v1/blobs/%s/links/content", id), url.Values{
+									"expirationTime": []string{query.ExpirationTime.Format(time.RFC3339)},
+									"singleUse":      []string{strconv.FormatBool(*query.SingleUse)},
+									"nonce":          []string{*query.Nonce},
+									"signature":      []string{*query.Signature},
+								}.Encode()),
+								VerifyContentType(""),
+								VerifyBody(nil),
+							)
+						})
+
+						AfterEach(func() {
+							Expect(server.ReceivedRequests()).To(HaveLen(1))
+						})
+
+						When("the server responds with an unauthorized error", func() {
+							BeforeEach(func() {
+								requestHandlers = append(requestHandlers, RespondWithJSONEncoded(http.StatusForbidden, errors.Serializable{Error: request.ErrorUnauthorized()}, responseHeaders))
+							})
+
+							It("returns an error", func() {
+								content, err := client.GetLinkContent(ctx, id, query)
+								errorsTest.ExpectEqual(err, request.ErrorUnauthorized())
+								Expect(content).To(BeNil())
+							})
+						})
+
+						When("the server responds with a not found error", func() {
+							BeforeEach(func() {
+								requestHandlers = append(requestHandlers, RespondWithJSONEncoded(http.StatusNotFound, errors.Serializable{Error: request.ErrorResourceNotFoundWithID(id)}, responseHeaders))
+							})
+
+							It("returns successfully without content", func() {
+								content, err := client.GetLinkContent(ctx, id, query)
+								Expect(err).ToNot(HaveOccurred())
+								Expect(content).To(BeNil())
+							})
+						})
+
+						When("the server responds with the content", func() {
+							var body []byte
+							var digestMD5 string
+							var mediaType string
+							var size int
+
+							BeforeEach(func() {
+								body = test.RandomBytes()
+								digestMD5 = cryptoTest.RandomBase64EncodedMD5Hash()
+								mediaType = netTest.RandomMediaType()
+								size = len(body)
+								responseHeaders = http.Header{
+									"Digest":         []string{fmt.Sprintf("MD5=%s", digestMD5)},
+									"Content-Type":   []string{mediaType},
+									"Content-Length": []string{strconv.Itoa(size)},
+								}
+								requestHandlers = append(requestHandlers, RespondWith(http.StatusOK, body, responseHeaders))
+							})
+
+							It("returns successfully", func() {
+								content, err := client.GetLinkContent(ctx, id, query)
+								Expect(err).ToNot(HaveOccurred())
+								Expect(content).ToNot(BeNil())
+								Expect(content.Body).ToNot(BeNil())
+								Expect(content.DigestMD5).To(Equal(&digestMD5))
+								Expect(content.MediaType).To(Equal(&mediaType))
+								Expect(content.Size).To(Equal(&size))
+							})
+						})
+					})
+				})
 			})
 		}
 
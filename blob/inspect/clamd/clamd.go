@@ -0,0 +1,155 @@
+package clamd
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/pointer"
+)
+
+const (
+	Type = "clamd"
+
+	chunkSizeMaximum = 32 * 1024
+
+	responseOK     = "stream: OK"
+	responsePrefix = "stream: "
+	responseFound  = " FOUND"
+	responseError  = " ERROR"
+)
+
+// Scanner scans content with a ClamAV daemon using the INSTREAM command. The address is either a
+// TCP host and port or, if it begins with a slash, the path of a Unix domain socket.
+type Scanner struct {
+	address string
+	timeout time.Duration
+}
+
+func NewScanner(cfg *Config) (*Scanner, error) {
+	if cfg == nil {
+		return nil, errors.New("config is missing")
+	} else if err := cfg.Validate(); err != nil {
+		return nil, errors.Wrap(err, "config is invalid")
+	}
+
+	return &Scanner{
+		address: cfg.Address,
+		timeout: cfg.Timeout,
+	}, nil
+}
+
+func (s *Scanner) Scan(ctx context.Context, reader io.Reader) (*string, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if reader == nil {
+		return nil, errors.New("reader is missing")
+	}
+
+	network := "tcp"
+	if strings.HasPrefix(s.address, "/") {
+		network = "unix"
+	}
+
+	dialer := &net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, network, s.address)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to connect to clamd")
+	}
+	defer conn.Close()
+
+	contentReader := &contentReader{Reader: reader}
+	if writeErr := s.stream(conn, contentReader); contentReader.err != nil {
+		return nil, errors.Wrap(contentReader.err, "unable to read content")
+	} else if writeErr != nil {
+		// The daemon closes the connection early when the stream exceeds its limits, so report the response if there is one
+		if response, responseErr := s.response(conn); responseErr == nil {
+			return parseResponse(response)
+		}
+		return nil, errors.Wrap(writeErr, "unable to write to clamd")
+	}
+
+	response, err := s.response(conn)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read from clamd")
+	}
+
+	return parseResponse(response)
+}
+
+func (s *Scanner) stream(conn net.Conn, reader io.Reader) error {
+	if err := s.write(conn, []byte("zINSTREAM\x00")); err != nil {
+		return err
+	}
+
+	chunk := make([]byte, 4+chunkSizeMaximum)
+	for {
+		count, err := reader.Read(chunk[4:])
+		if count > 0 {
+			binary.BigEndian.PutUint32(chunk[:4], uint32(count))
+			if writeErr := s.write(conn, chunk[:4+count]); writeErr != nil {
+				return writeErr
+			}
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return s.write(conn, []byte{0, 0, 0, 0})
+}
+
+func (s *Scanner) write(conn net.Conn, bytes []byte) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(s.timeout)); err != nil {
+		return err
+	}
+	_, err := conn.Write(bytes)
+	return err
+}
+
+func (s *Scanner) response(conn net.Conn) (string, error) {
+	if err := conn.SetReadDeadline(time.Now().Add(s.timeout)); err != nil {
+		return "", err
+	}
+	response, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && (err != io.EOF || response == "") {
+		return "", err
+	}
+	return strings.TrimSuffix(response, "\x00"), nil
+}
+
+func parseResponse(response string) (*string, error) {
+	if response == responseOK {
+		return nil, nil
+	} else if strings.HasPrefix(response, responsePrefix) {
+		if strings.HasSuffix(response, responseFound) {
+			return pointer.FromString(strings.TrimSuffix(strings.TrimPrefix(response, responsePrefix), responseFound)), nil
+		} else if strings.HasSuffix(response, responseError) {
+			return nil, errors.Newf("clamd responded with error %q", strings.TrimSuffix(strings.TrimPrefix(response, responsePrefix), responseError))
+		}
+	} else if strings.HasSuffix(response, responseError) {
+		return nil, errors.Newf("clamd responded with error %q", strings.TrimSuffix(response, responseError))
+	}
+	return nil, errors.Newf("clamd response %q is invalid", response)
+}
+
+type contentReader struct {
+	io.Reader
+	err error
+}
+
+func (c *contentReader) Read(bytes []byte) (int, error) {
+	count, err := c.Reader.Read(bytes)
+	if err != nil && err != io.EOF {
+		c.err = err
+	}
+	return count, err
+}
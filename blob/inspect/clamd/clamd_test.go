@@ -0,0 +1,204 @@
+package clamd_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"time"
+
+	blobInspectClamd "github.com/tidepool-org/platform/blob/inspect/clamd"
+	"github.com/tidepool-org/platform/errors"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/test"
+)
+
+type server struct {
+	listener net.Listener
+	response string
+	command  chan string
+	content  chan []byte
+}
+
+func newServer(response string) *server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+	s := &server{
+		listener: listener,
+		response: response,
+		command:  make(chan string, 1),
+		content:  make(chan []byte, 1),
+	}
+	go s.serve()
+	return s
+}
+
+func (s *server) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	command := make([]byte, len("zINSTREAM\x00"))
+	if _, err = io.ReadFull(conn, command); err != nil {
+		return
+	}
+	s.command <- string(command)
+
+	content := &bytes.Buffer{}
+	for {
+		length := make([]byte, 4)
+		if _, err = io.ReadFull(conn, length); err != nil {
+			return
+		}
+		size := binary.BigEndian.Uint32(length)
+		if size == 0 {
+			break
+		}
+		if _, err = io.CopyN(content, conn, int64(size)); err != nil {
+			return
+		}
+	}
+	s.content <- content.Bytes()
+
+	conn.Write([]byte(s.response))
+}
+
+func (s *server) Close() {
+	s.listener.Close()
+}
+
+type errorReader struct {
+	err error
+}
+
+func (e *errorReader) Read(bytes []byte) (int, error) {
+	return 0, e.err
+}
+
+var _ = Describe("Clamd", func() {
+	It("Type is expected", func() {
+		Expect(blobInspectClamd.Type).To(Equal("clamd"))
+	})
+
+	Context("NewScanner", func() {
+		var cfg *blobInspectClamd.Config
+
+		BeforeEach(func() {
+			cfg = blobInspectClamd.NewConfig()
+			cfg.Address = "127.0.0.1:3310"
+		})
+
+		It("returns an error if the config is missing", func() {
+			scanner, err := blobInspectClamd.NewScanner(nil)
+			errorsTest.ExpectEqual(err, errors.New("config is missing"))
+			Expect(scanner).To(BeNil())
+		})
+
+		It("returns an error if the config is invalid", func() {
+			cfg.Address = ""
+			scanner, err := blobInspectClamd.NewScanner(cfg)
+			errorsTest.ExpectEqual(err, errors.Wrap(errors.New("address is missing"), "config is invalid"))
+			Expect(scanner).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			Expect(blobInspectClamd.NewScanner(cfg)).ToNot(BeNil())
+		})
+	})
+
+	Context("Scan", func() {
+		var ctx context.Context
+		var content []byte
+		var response string
+		var srvr *server
+		var scanner *blobInspectClamd.Scanner
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			content = test.RandomBytesFromRange(1, 100*1024)
+			response = "stream: OK\x00"
+		})
+
+		JustBeforeEach(func() {
+			srvr = newServer(response)
+			cfg := blobInspectClamd.NewConfig()
+			cfg.Address = srvr.listener.Addr().String()
+			cfg.Timeout = 5 * time.Second
+			var err error
+			scanner, err = blobInspectClamd.NewScanner(cfg)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(scanner).ToNot(BeNil())
+		})
+
+		AfterEach(func() {
+			srvr.Close()
+		})
+
+		It("returns an error if the context is missing", func() {
+			signature, err := scanner.Scan(nil, bytes.NewReader(content))
+			errorsTest.ExpectEqual(err, errors.New("context is missing"))
+			Expect(signature).To(BeNil())
+		})
+
+		It("returns an error if the reader is missing", func() {
+			signature, err := scanner.Scan(ctx, nil)
+			errorsTest.ExpectEqual(err, errors.New("reader is missing"))
+			Expect(signature).To(BeNil())
+		})
+
+		It("returns an error if the reader returns an error", func() {
+			readerErr := errorsTest.NewError()
+			signature, err := scanner.Scan(ctx, &errorReader{err: readerErr})
+			errorsTest.ExpectEqual(err, errors.Wrap(readerErr, "unable to read content"))
+			Expect(signature).To(BeNil())
+		})
+
+		It("returns successfully with no signature if the content is clean", func() {
+			Expect(scanner.Scan(ctx, bytes.NewReader(content))).To(BeNil())
+			Expect(<-srvr.command).To(Equal("zINSTREAM\x00"))
+			Expect(<-srvr.content).To(Equal(content))
+		})
+
+		When("the content is infected", func() {
+			BeforeEach(func() {
+				response = "stream: Win.Test.EICAR_HDB-1 FOUND\x00"
+			})
+
+			It("returns successfully with the signature", func() {
+				Expect(scanner.Scan(ctx, bytes.NewReader(content))).To(Equal(pointer.FromString("Win.Test.EICAR_HDB-1")))
+				Expect(<-srvr.content).To(Equal(content))
+			})
+		})
+
+		When("the daemon responds with an error", func() {
+			BeforeEach(func() {
+				response = "INSTREAM size limit exceeded. ERROR\x00"
+			})
+
+			It("returns an error", func() {
+				signature, err := scanner.Scan(ctx, bytes.NewReader(content))
+				errorsTest.ExpectEqual(err, errors.New(`clamd responded with error "INSTREAM size limit exceeded."`))
+				Expect(signature).To(BeNil())
+			})
+		})
+
+		When("the daemon responds with an invalid response", func() {
+			BeforeEach(func() {
+				response = "invalid\x00"
+			})
+
+			It("returns an error", func() {
+				signature, err := scanner.Scan(ctx, bytes.NewReader(content))
+				errorsTest.ExpectEqual(err, errors.New(`clamd response "invalid" is invalid`))
+				Expect(signature).To(BeNil())
+			})
+		})
+	})
+})
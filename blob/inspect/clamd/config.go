@@ -0,0 +1,49 @@
+package clamd
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/tidepool-org/platform/config"
+	"github.com/tidepool-org/platform/errors"
+)
+
+type Config struct {
+	Address string
+	Timeout time.Duration
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Timeout: 60 * time.Second,
+	}
+}
+
+func (c *Config) Load(configReporter config.Reporter) error {
+	if configReporter == nil {
+		return errors.New("config reporter is missing")
+	}
+
+	c.Address = configReporter.GetWithDefault("address", c.Address)
+	if timeoutString, err := configReporter.Get("timeout"); err == nil {
+		var timeout int64
+		timeout, err = strconv.ParseInt(timeoutString, 10, 0)
+		if err != nil {
+			return errors.New("timeout is invalid")
+		}
+		c.Timeout = time.Duration(timeout) * time.Second
+	}
+
+	return nil
+}
+
+func (c *Config) Validate() error {
+	if c.Address == "" {
+		return errors.New("address is missing")
+	}
+	if c.Timeout <= 0 {
+		return errors.New("timeout is invalid")
+	}
+
+	return nil
+}
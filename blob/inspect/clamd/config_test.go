@@ -0,0 +1,91 @@
+package clamd_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"strconv"
+	"time"
+
+	blobInspectClamd "github.com/tidepool-org/platform/blob/inspect/clamd"
+	configTest "github.com/tidepool-org/platform/config/test"
+	"github.com/tidepool-org/platform/test"
+)
+
+var _ = Describe("Config", func() {
+	Context("NewConfig", func() {
+		It("returns successfully with default values", func() {
+			cfg := blobInspectClamd.NewConfig()
+			Expect(cfg).ToNot(BeNil())
+			Expect(cfg.Address).To(BeEmpty())
+			Expect(cfg.Timeout).To(Equal(60 * time.Second))
+		})
+	})
+
+	Context("with new config", func() {
+		var address string
+		var timeout int
+		var cfg *blobInspectClamd.Config
+
+		BeforeEach(func() {
+			address = test.RandomStringFromRange(1, 64)
+			timeout = test.RandomIntFromRange(1, 300)
+			cfg = blobInspectClamd.NewConfig()
+			Expect(cfg).ToNot(BeNil())
+		})
+
+		Context("Load", func() {
+			var configReporter *configTest.Reporter
+
+			BeforeEach(func() {
+				configReporter = configTest.NewReporter()
+				configReporter.Config["address"] = address
+				configReporter.Config["timeout"] = strconv.Itoa(timeout)
+			})
+
+			It("returns an error if the config reporter is missing", func() {
+				Expect(cfg.Load(nil)).To(MatchError("config reporter is missing"))
+			})
+
+			It("returns an error if the timeout is invalid", func() {
+				configReporter.Config["timeout"] = "invalid"
+				Expect(cfg.Load(configReporter)).To(MatchError("timeout is invalid"))
+			})
+
+			It("returns successfully and does not set the address or timeout", func() {
+				delete(configReporter.Config, "address")
+				delete(configReporter.Config, "timeout")
+				Expect(cfg.Load(configReporter)).To(Succeed())
+				Expect(cfg.Address).To(BeEmpty())
+				Expect(cfg.Timeout).To(Equal(60 * time.Second))
+			})
+
+			It("returns successfully and sets the address and timeout", func() {
+				Expect(cfg.Load(configReporter)).To(Succeed())
+				Expect(cfg.Address).To(Equal(address))
+				Expect(cfg.Timeout).To(Equal(time.Duration(timeout) * time.Second))
+			})
+		})
+
+		Context("Validate", func() {
+			BeforeEach(func() {
+				cfg.Address = address
+				cfg.Timeout = time.Duration(timeout) * time.Second
+			})
+
+			It("returns an error if the address is missing", func() {
+				cfg.Address = ""
+				Expect(cfg.Validate()).To(MatchError("address is missing"))
+			})
+
+			It("returns an error if the timeout is invalid", func() {
+				cfg.Timeout = 0
+				Expect(cfg.Validate()).To(MatchError("timeout is invalid"))
+			})
+
+			It("returns successfully", func() {
+				Expect(cfg.Validate()).To(Succeed())
+			})
+		})
+	})
+})
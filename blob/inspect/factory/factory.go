@@ -0,0 +1,63 @@
+package factory
+
+import (
+	"strconv"
+
+	"github.com/tidepool-org/platform/blob"
+	blobInspect "github.com/tidepool-org/platform/blob/inspect"
+	blobInspectClamd "github.com/tidepool-org/platform/blob/inspect/clamd"
+	blobInspectLocal "github.com/tidepool-org/platform/blob/inspect/local"
+	"github.com/tidepool-org/platform/config"
+	"github.com/tidepool-org/platform/errors"
+)
+
+func NewInspector(configReporter config.Reporter) (blobInspect.Inspector, error) {
+	if configReporter == nil {
+		return nil, errors.New("config reporter is missing")
+	}
+
+	sizeMaximum := blob.SizeMaximum
+	if sizeMaximumString, err := configReporter.Get("size_maximum"); err == nil {
+		if sizeMaximum, err = strconv.Atoi(sizeMaximumString); err != nil {
+			return nil, errors.New("size maximum is invalid")
+		}
+	}
+
+	sizeInspector, err := blobInspect.NewSizeInspector(sizeMaximum)
+	if err != nil {
+		return nil, err
+	}
+
+	inspectors := []blobInspect.Inspector{sizeInspector, blobInspect.NewMediaTypeInspector()}
+
+	if scannerType := configReporter.GetWithDefault("scanner_type", ""); scannerType != "" {
+		var scanner blobInspect.Scanner
+		switch scannerType {
+		case blobInspectClamd.Type:
+			scanner, err = NewClamdScanner(configReporter.WithScopes(blobInspectClamd.Type))
+		case blobInspectLocal.Type:
+			scanner = blobInspectLocal.NewScanner()
+		default:
+			return nil, errors.New("scanner type is invalid")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		scannerInspector, err := blobInspect.NewScannerInspector(scanner)
+		if err != nil {
+			return nil, err
+		}
+		inspectors = append(inspectors, scannerInspector)
+	}
+
+	return blobInspect.NewPipeline(inspectors...), nil
+}
+
+func NewClamdScanner(configReporter config.Reporter) (blobInspect.Scanner, error) {
+	cfg := blobInspectClamd.NewConfig()
+	if err := cfg.Load(configReporter); err != nil {
+		return nil, errors.Wrap(err, "unable to load config")
+	}
+	return blobInspectClamd.NewScanner(cfg)
+}
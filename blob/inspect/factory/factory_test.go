@@ -0,0 +1,103 @@
+package factory_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	"github.com/tidepool-org/platform/blob"
+	blobInspect "github.com/tidepool-org/platform/blob/inspect"
+	blobInspectFactory "github.com/tidepool-org/platform/blob/inspect/factory"
+	blobInspectLocal "github.com/tidepool-org/platform/blob/inspect/local"
+	configTest "github.com/tidepool-org/platform/config/test"
+	"github.com/tidepool-org/platform/errors"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+)
+
+var _ = Describe("Factory", func() {
+	Context("NewInspector", func() {
+		var configReporter *configTest.Reporter
+
+		BeforeEach(func() {
+			configReporter = configTest.NewReporter()
+		})
+
+		It("returns an error if the config reporter is missing", func() {
+			inspector, err := blobInspectFactory.NewInspector(nil)
+			errorsTest.ExpectEqual(err, errors.New("config reporter is missing"))
+			Expect(inspector).To(BeNil())
+		})
+
+		It("returns an error if the size maximum is invalid", func() {
+			configReporter.Config["size_maximum"] = "invalid"
+			inspector, err := blobInspectFactory.NewInspector(configReporter)
+			errorsTest.ExpectEqual(err, errors.New("size maximum is invalid"))
+			Expect(inspector).To(BeNil())
+		})
+
+		It("returns an error if the size maximum is negative", func() {
+			configReporter.Config["size_maximum"] = "-1"
+			inspector, err := blobInspectFactory.NewInspector(configReporter)
+			errorsTest.ExpectEqual(err, errors.New("maximum is invalid"))
+			Expect(inspector).To(BeNil())
+		})
+
+		It("returns an error if the scanner type is invalid", func() {
+			configReporter.Config["scanner_type"] = "invalid"
+			inspector, err := blobInspectFactory.NewInspector(configReporter)
+			errorsTest.ExpectEqual(err, errors.New("scanner type is invalid"))
+			Expect(inspector).To(BeNil())
+		})
+
+		It("returns an error if the clamd config is invalid", func() {
+			configReporter.Config["scanner_type"] = "clamd"
+			configReporter.Config["clamd"] = map[string]interface{}{"timeout": "invalid"}
+			inspector, err := blobInspectFactory.NewInspector(configReporter)
+			errorsTest.ExpectEqual(err, errors.Wrap(errors.New("timeout is invalid"), "unable to load config"))
+			Expect(inspector).To(BeNil())
+		})
+
+		It("returns an error if the clamd address is missing", func() {
+			configReporter.Config["scanner_type"] = "clamd"
+			inspector, err := blobInspectFactory.NewInspector(configReporter)
+			errorsTest.ExpectEqual(err, errors.Wrap(errors.New("address is missing"), "config is invalid"))
+			Expect(inspector).To(BeNil())
+		})
+
+		It("returns successfully with a clamd scanner", func() {
+			configReporter.Config["scanner_type"] = "clamd"
+			configReporter.Config["clamd"] = map[string]interface{}{"address": "127.0.0.1:3310"}
+			Expect(blobInspectFactory.NewInspector(configReporter)).ToNot(BeNil())
+		})
+
+		It("returns an inspector that enforces the size maximum", func() {
+			configReporter.Config["size_maximum"] = "4"
+			inspector, err := blobInspectFactory.NewInspector(configReporter)
+			Expect(err).ToNot(HaveOccurred())
+			inspection, err := inspector.NewInspection(context.Background(), "text/plain")
+			Expect(err).ToNot(HaveOccurred())
+			inspection.Write([]byte("abcde"))
+			errorsTest.ExpectEqual(inspection.Finish(), blob.ErrorSizeExceedsMaximum(4))
+		})
+
+		It("returns an inspector that verifies the media type", func() {
+			inspector, err := blobInspectFactory.NewInspector(configReporter)
+			Expect(err).ToNot(HaveOccurred())
+			inspection, err := inspector.NewInspection(context.Background(), "image/png")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(inspection.Write([]byte("plain text"))).To(Equal(10))
+			errorsTest.ExpectEqual(inspection.Finish(), blob.ErrorMediaTypeNotMatched("image/png", "text/plain"))
+		})
+
+		It("returns an inspector that scans with the local scanner", func() {
+			configReporter.Config["scanner_type"] = "local"
+			inspector, err := blobInspectFactory.NewInspector(configReporter)
+			Expect(err).ToNot(HaveOccurred())
+			inspection, err := inspector.NewInspection(context.Background(), "text/plain")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(inspection.Write([]byte(blobInspectLocal.EICARTestFile))).To(Equal(len(blobInspectLocal.EICARTestFile)))
+			errorsTest.ExpectEqual(inspection.Finish(), blobInspect.ErrorContentInfected(blobInspectLocal.EICARSignature))
+		})
+	})
+})
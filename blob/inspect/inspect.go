@@ -0,0 +1,102 @@
+package inspect
+
+import (
+	"context"
+	"io"
+
+	"github.com/tidepool-org/platform/errors"
+)
+
+const ErrorCodeContentInfected = "content-infected"
+
+func ErrorContentInfected(signature string) error {
+	return errors.Preparedf(ErrorCodeContentInfected, "content infected", "content infected with %q", signature)
+}
+
+func IsErrorContentInfected(err error) bool {
+	return errors.Code(err) == ErrorCodeContentInfected
+}
+
+// Inspector creates an Inspection for each blob content stream. An Inspection receives the content
+// as it is written and reports the outcome from Finish, which must always be called. A Write error
+// aborts the stream. An infected error from Finish indicates the content should be quarantined
+// rather than rejected.
+type Inspector interface {
+	NewInspection(ctx context.Context, mediaType string) (Inspection, error)
+}
+
+type Inspection interface {
+	io.Writer
+
+	Finish() error
+}
+
+type Pipeline struct {
+	inspectors []Inspector
+}
+
+func NewPipeline(inspectors ...Inspector) *Pipeline {
+	return &Pipeline{
+		inspectors: inspectors,
+	}
+}
+
+func (p *Pipeline) NewInspection(ctx context.Context, mediaType string) (Inspection, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if mediaType == "" {
+		return nil, errors.New("media type is missing")
+	}
+
+	inspections := []Inspection{}
+	for _, inspector := range p.inspectors {
+		inspection, err := inspector.NewInspection(ctx, mediaType)
+		if err != nil {
+			for _, inspection = range inspections {
+				inspection.Finish()
+			}
+			return nil, err
+		}
+		inspections = append(inspections, inspection)
+	}
+
+	return &pipelineInspection{
+		inspections: inspections,
+	}, nil
+}
+
+type pipelineInspection struct {
+	inspections []Inspection
+}
+
+func (p *pipelineInspection) Write(bytes []byte) (int, error) {
+	for _, inspection := range p.inspections {
+		if _, err := inspection.Write(bytes); err != nil {
+			return 0, err
+		}
+	}
+	return len(bytes), nil
+}
+
+// Finish finishes every inspection and returns the first error that rejects the content, otherwise
+// the first infected error, if any.
+func (p *pipelineInspection) Finish() error {
+	var infectedErr error
+	var rejectedErr error
+	for _, inspection := range p.inspections {
+		if err := inspection.Finish(); err != nil {
+			if IsErrorContentInfected(err) {
+				if infectedErr == nil {
+					infectedErr = err
+				}
+			} else if rejectedErr == nil {
+				rejectedErr = err
+			}
+		}
+	}
+	if rejectedErr != nil {
+		return rejectedErr
+	}
+	return infectedErr
+}
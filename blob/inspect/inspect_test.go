@@ -0,0 +1,174 @@
+package inspect_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	blobInspect "github.com/tidepool-org/platform/blob/inspect"
+	blobInspectTest "github.com/tidepool-org/platform/blob/inspect/test"
+	"github.com/tidepool-org/platform/errors"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	netTest "github.com/tidepool-org/platform/net/test"
+	"github.com/tidepool-org/platform/test"
+)
+
+var _ = Describe("Inspect", func() {
+	Context("Errors", func() {
+		DescribeTable("all errors",
+			errorsTest.ExpectErrorDetails,
+			Entry("is ErrorContentInfected", blobInspect.ErrorContentInfected("Win.Test.EICAR_HDB-1"), "content-infected", "content infected", `content infected with "Win.Test.EICAR_HDB-1"`),
+		)
+	})
+
+	Context("IsErrorContentInfected", func() {
+		It("returns true if the error is content infected", func() {
+			Expect(blobInspect.IsErrorContentInfected(blobInspect.ErrorContentInfected("Win.Test.EICAR_HDB-1"))).To(BeTrue())
+		})
+
+		It("returns false if the error is not content infected", func() {
+			Expect(blobInspect.IsErrorContentInfected(errorsTest.NewError())).To(BeFalse())
+		})
+
+		It("returns false if the error is nil", func() {
+			Expect(blobInspect.IsErrorContentInfected(nil)).To(BeFalse())
+		})
+	})
+
+	Context("with context and media type", func() {
+		var ctx context.Context
+		var mediaType string
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			mediaType = netTest.RandomMediaType()
+		})
+
+		Context("NewPipeline", func() {
+			It("returns successfully", func() {
+				Expect(blobInspect.NewPipeline()).ToNot(BeNil())
+			})
+		})
+
+		Context("with new pipeline", func() {
+			var firstInspector *blobInspectTest.Inspector
+			var secondInspector *blobInspectTest.Inspector
+			var pipeline *blobInspect.Pipeline
+
+			BeforeEach(func() {
+				firstInspector = blobInspectTest.NewInspector()
+				secondInspector = blobInspectTest.NewInspector()
+				pipeline = blobInspect.NewPipeline(firstInspector, secondInspector)
+				Expect(pipeline).ToNot(BeNil())
+			})
+
+			AfterEach(func() {
+				secondInspector.AssertOutputsEmpty()
+				firstInspector.AssertOutputsEmpty()
+			})
+
+			Context("NewInspection", func() {
+				It("returns an error if the context is missing", func() {
+					inspection, err := pipeline.NewInspection(nil, mediaType)
+					errorsTest.ExpectEqual(err, errors.New("context is missing"))
+					Expect(inspection).To(BeNil())
+				})
+
+				It("returns an error if the media type is missing", func() {
+					inspection, err := pipeline.NewInspection(ctx, "")
+					errorsTest.ExpectEqual(err, errors.New("media type is missing"))
+					Expect(inspection).To(BeNil())
+				})
+
+				It("returns an error and finishes previous inspections if an inspector returns an error", func() {
+					responseErr := errorsTest.NewError()
+					firstInspection := blobInspectTest.NewInspection()
+					firstInspection.FinishOutputs = []error{nil}
+					firstInspector.NewInspectionOutputs = []blobInspectTest.NewInspectionOutput{{Inspection: firstInspection, Error: nil}}
+					secondInspector.NewInspectionOutputs = []blobInspectTest.NewInspectionOutput{{Inspection: nil, Error: responseErr}}
+					inspection, err := pipeline.NewInspection(ctx, mediaType)
+					errorsTest.ExpectEqual(err, responseErr)
+					Expect(inspection).To(BeNil())
+					Expect(firstInspection.FinishInvocations).To(Equal(1))
+					Expect(secondInspector.NewInspectionInputs).To(Equal([]blobInspectTest.NewInspectionInput{{Context: ctx, MediaType: mediaType}}))
+				})
+
+				When("the inspectors return successfully", func() {
+					var firstInspection *blobInspectTest.Inspection
+					var secondInspection *blobInspectTest.Inspection
+					var inspection blobInspect.Inspection
+
+					BeforeEach(func() {
+						firstInspection = blobInspectTest.NewInspection()
+						secondInspection = blobInspectTest.NewInspection()
+						firstInspector.NewInspectionOutputs = []blobInspectTest.NewInspectionOutput{{Inspection: firstInspection, Error: nil}}
+						secondInspector.NewInspectionOutputs = []blobInspectTest.NewInspectionOutput{{Inspection: secondInspection, Error: nil}}
+						var err error
+						inspection, err = pipeline.NewInspection(ctx, mediaType)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(inspection).ToNot(BeNil())
+					})
+
+					AfterEach(func() {
+						secondInspection.AssertOutputsEmpty()
+						firstInspection.AssertOutputsEmpty()
+					})
+
+					Context("Write", func() {
+						var bytes []byte
+
+						BeforeEach(func() {
+							bytes = test.RandomBytes()
+						})
+
+						It("returns an error if an inspection returns an error", func() {
+							responseErr := errorsTest.NewError()
+							firstInspection.WriteOutputs = []blobInspectTest.WriteOutput{{BytesWritten: 0, Error: responseErr}}
+							count, err := inspection.Write(bytes)
+							errorsTest.ExpectEqual(err, responseErr)
+							Expect(count).To(Equal(0))
+							Expect(secondInspection.WriteInputs).To(BeEmpty())
+						})
+
+						It("writes to all inspections", func() {
+							firstInspection.WriteOutputs = []blobInspectTest.WriteOutput{{BytesWritten: len(bytes), Error: nil}}
+							secondInspection.WriteOutputs = []blobInspectTest.WriteOutput{{BytesWritten: len(bytes), Error: nil}}
+							Expect(inspection.Write(bytes)).To(Equal(len(bytes)))
+							Expect(firstInspection.WriteInputs).To(Equal([][]byte{bytes}))
+							Expect(secondInspection.WriteInputs).To(Equal([][]byte{bytes}))
+						})
+					})
+
+					Context("Finish", func() {
+						AfterEach(func() {
+							Expect(firstInspection.FinishInvocations).To(Equal(1))
+							Expect(secondInspection.FinishInvocations).To(Equal(1))
+						})
+
+						It("returns successfully if all inspections return successfully", func() {
+							firstInspection.FinishOutputs = []error{nil}
+							secondInspection.FinishOutputs = []error{nil}
+							Expect(inspection.Finish()).To(Succeed())
+						})
+
+						It("returns the infected error if an inspection returns an infected error", func() {
+							infectedErr := blobInspect.ErrorContentInfected("Win.Test.EICAR_HDB-1")
+							firstInspection.FinishOutputs = []error{infectedErr}
+							secondInspection.FinishOutputs = []error{nil}
+							errorsTest.ExpectEqual(inspection.Finish(), infectedErr)
+						})
+
+						It("returns the rejected error in preference to an infected error", func() {
+							responseErr := errorsTest.NewError()
+							firstInspection.FinishOutputs = []error{blobInspect.ErrorContentInfected("Win.Test.EICAR_HDB-1")}
+							secondInspection.FinishOutputs = []error{responseErr}
+							errorsTest.ExpectEqual(inspection.Finish(), responseErr)
+						})
+					})
+				})
+			})
+		})
+	})
+})
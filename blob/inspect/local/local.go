@@ -0,0 +1,57 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/pointer"
+)
+
+const (
+	Type = "local"
+
+	EICARSignature = "Win.Test.EICAR_HDB-1"
+	EICARTestFile  = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+	chunkSizeMaximum = 32 * 1024
+)
+
+// Scanner is a local stand-in for a ClamAV daemon for development and testing. It detects only the
+// EICAR anti-virus test file and reports it using the same signature name as ClamAV.
+type Scanner struct{}
+
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+func (s *Scanner) Scan(ctx context.Context, reader io.Reader) (*string, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if reader == nil {
+		return nil, errors.New("reader is missing")
+	}
+
+	pattern := []byte(EICARTestFile)
+	window := make([]byte, 0, len(pattern)-1+chunkSizeMaximum)
+	chunk := make([]byte, chunkSizeMaximum)
+	for {
+		count, err := reader.Read(chunk)
+		if count > 0 {
+			window = append(window, chunk[:count]...)
+			if bytes.Contains(window, pattern) {
+				return pointer.FromString(EICARSignature), nil
+			}
+			if overlap := len(pattern) - 1; len(window) > overlap {
+				window = append(window[:0], window[len(window)-overlap:]...)
+			}
+		}
+		if err == io.EOF {
+			return nil, nil
+		} else if err != nil {
+			return nil, errors.Wrap(err, "unable to read content")
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package local_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"bytes"
+	"context"
+	"io"
+	"testing/iotest"
+
+	blobInspectLocal "github.com/tidepool-org/platform/blob/inspect/local"
+	"github.com/tidepool-org/platform/errors"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/test"
+)
+
+var _ = Describe("Local", func() {
+	It("Type is expected", func() {
+		Expect(blobInspectLocal.Type).To(Equal("local"))
+	})
+
+	It("EICARSignature is expected", func() {
+		Expect(blobInspectLocal.EICARSignature).To(Equal("Win.Test.EICAR_HDB-1"))
+	})
+
+	Context("NewScanner", func() {
+		It("returns successfully", func() {
+			Expect(blobInspectLocal.NewScanner()).ToNot(BeNil())
+		})
+	})
+
+	Context("Scan", func() {
+		var ctx context.Context
+		var scanner *blobInspectLocal.Scanner
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			scanner = blobInspectLocal.NewScanner()
+		})
+
+		It("returns an error if the context is missing", func() {
+			signature, err := scanner.Scan(nil, bytes.NewReader(test.RandomBytes()))
+			errorsTest.ExpectEqual(err, errors.New("context is missing"))
+			Expect(signature).To(BeNil())
+		})
+
+		It("returns an error if the reader is missing", func() {
+			signature, err := scanner.Scan(ctx, nil)
+			errorsTest.ExpectEqual(err, errors.New("reader is missing"))
+			Expect(signature).To(BeNil())
+		})
+
+		It("returns an error if the reader returns an error", func() {
+			readerErr := errorsTest.NewError()
+			signature, err := scanner.Scan(ctx, io.MultiReader(bytes.NewReader(test.RandomBytes()), &errorReader{err: readerErr}))
+			errorsTest.ExpectEqual(err, errors.Wrap(readerErr, "unable to read content"))
+			Expect(signature).To(BeNil())
+		})
+
+		It("returns successfully with no signature if the content is empty", func() {
+			Expect(scanner.Scan(ctx, bytes.NewReader(nil))).To(BeNil())
+		})
+
+		It("returns successfully with no signature if the content is clean", func() {
+			Expect(scanner.Scan(ctx, bytes.NewReader(make([]byte, 100*1024)))).To(BeNil())
+		})
+
+		It("returns successfully with the signature if the content is the test file", func() {
+			Expect(scanner.Scan(ctx, bytes.NewReader([]byte(blobInspectLocal.EICARTestFile)))).To(Equal(pointer.FromString(blobInspectLocal.EICARSignature)))
+		})
+
+		It("returns successfully with the signature if the test file is split across reads", func() {
+			content := append(make([]byte, 32*1024-10), []byte(blobInspectLocal.EICARTestFile)...)
+			content = append(content, make([]byte, 1024)...)
+			Expect(scanner.Scan(ctx, iotest.HalfReader(bytes.NewReader(content)))).To(Equal(pointer.FromString(blobInspectLocal.EICARSignature)))
+		})
+	})
+})
+
+type errorReader struct {
+	err error
+}
+
+func (e *errorReader) Read(bytes []byte) (int, error) {
+	return 0, e.err
+}
@@ -0,0 +1,111 @@
+package inspect
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/tidepool-org/platform/blob"
+	"github.com/tidepool-org/platform/errors"
+)
+
+const (
+	MediaTypeOctetStream = "application/octet-stream"
+	MediaTypeTextPlain   = "text/plain"
+	MediaTypeTextXML     = "text/xml"
+	MediaTypeZip         = "application/zip"
+
+	mediaTypeSniffLength = 512 // Maximum bytes considered by http.DetectContentType
+)
+
+// MediaTypeInspector sniffs the magic bytes at the start of the content and verifies the detected
+// media type is compatible with the declared media type.
+type MediaTypeInspector struct{}
+
+func NewMediaTypeInspector() *MediaTypeInspector {
+	return &MediaTypeInspector{}
+}
+
+func (m *MediaTypeInspector) NewInspection(ctx context.Context, mediaType string) (Inspection, error) {
+	baseMediaType, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return nil, errors.New("media type is invalid")
+	}
+
+	return &mediaTypeInspection{
+		mediaType:     mediaType,
+		baseMediaType: baseMediaType,
+	}, nil
+}
+
+type mediaTypeInspection struct {
+	mediaType     string
+	baseMediaType string
+	header        []byte
+}
+
+func (m *mediaTypeInspection) Write(bytes []byte) (int, error) {
+	if remaining := mediaTypeSniffLength - len(m.header); remaining > 0 {
+		if remaining > len(bytes) {
+			remaining = len(bytes)
+		}
+		m.header = append(m.header, bytes[:remaining]...)
+	}
+	return len(bytes), nil
+}
+
+func (m *mediaTypeInspection) Finish() error {
+	if len(m.header) == 0 {
+		return nil
+	}
+
+	detectedMediaType, _, err := mime.ParseMediaType(http.DetectContentType(m.header))
+	if err != nil {
+		return errors.Wrap(err, "unable to detect media type")
+	}
+
+	if !IsMediaTypeCompatible(m.baseMediaType, detectedMediaType) {
+		return blob.ErrorMediaTypeNotMatched(m.mediaType, detectedMediaType)
+	}
+	return nil
+}
+
+// IsMediaTypeCompatible reports whether content detected as the detected media type may be served as
+// the declared media type. Both media types must not include parameters.
+func IsMediaTypeCompatible(declared string, detected string) bool {
+	switch {
+	case declared == detected:
+		return true
+	case declared == MediaTypeOctetStream:
+		return true
+	case detected == MediaTypeTextPlain:
+		return isTextMediaType(declared) && declared != "text/html"
+	case detected == MediaTypeTextXML:
+		return isXMLMediaType(declared)
+	case detected == MediaTypeOctetStream:
+		return !isTextMediaType(declared)
+	case detected == MediaTypeZip:
+		return isZipMediaType(declared)
+	}
+	return false
+}
+
+func isTextMediaType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") || isJSONMediaType(mediaType) || isXMLMediaType(mediaType)
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// Office Open XML (docx, xlsx, pptx) and OpenDocument files are zip containers and are detected as zip
+func isZipMediaType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "application/vnd.openxmlformats-officedocument.") ||
+		strings.HasPrefix(mediaType, "application/vnd.oasis.opendocument.") ||
+		strings.HasSuffix(mediaType, "+zip")
+}
+
+func isXMLMediaType(mediaType string) bool {
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
@@ -0,0 +1,76 @@
+package inspect_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	"github.com/tidepool-org/platform/blob"
+	blobInspect "github.com/tidepool-org/platform/blob/inspect"
+	"github.com/tidepool-org/platform/errors"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+)
+
+var pngHeader = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+
+var _ = Describe("MediaType", func() {
+	Context("NewMediaTypeInspector", func() {
+		It("returns successfully", func() {
+			Expect(blobInspect.NewMediaTypeInspector()).ToNot(BeNil())
+		})
+	})
+
+	Context("with new inspector", func() {
+		var inspector *blobInspect.MediaTypeInspector
+
+		BeforeEach(func() {
+			inspector = blobInspect.NewMediaTypeInspector()
+		})
+
+		Context("NewInspection", func() {
+			It("returns an error if the media type is invalid", func() {
+				inspection, err := inspector.NewInspection(context.Background(), "/")
+				errorsTest.ExpectEqual(err, errors.New("media type is invalid"))
+				Expect(inspection).To(BeNil())
+			})
+		})
+
+		DescribeTable("inspects the content",
+			func(mediaType string, chunks []string, expectedErr error) {
+				inspection, err := inspector.NewInspection(context.Background(), mediaType)
+				Expect(err).ToNot(HaveOccurred())
+				for _, chunk := range chunks {
+					Expect(inspection.Write([]byte(chunk))).To(Equal(len(chunk)))
+				}
+				if expectedErr != nil {
+					errorsTest.ExpectEqual(inspection.Finish(), expectedErr)
+				} else {
+					Expect(inspection.Finish()).To(Succeed())
+				}
+			},
+			Entry("empty content", "image/png", []string{}, nil),
+			Entry("png declared as png", "image/png", []string{string(pngHeader), "data"}, nil),
+			Entry("png split across writes", "image/png", []string{string(pngHeader[:3]), string(pngHeader[3:])}, nil),
+			Entry("png declared as octet stream", "application/octet-stream", []string{string(pngHeader)}, nil),
+			Entry("png declared as jpeg", "image/jpeg", []string{string(pngHeader)}, blob.ErrorMediaTypeNotMatched("image/jpeg", "image/png")),
+			Entry("html declared as png", "image/png", []string{"<html><script>alert(1)</script></html>"}, blob.ErrorMediaTypeNotMatched("image/png", "text/html")),
+			Entry("html declared as plain text", "text/plain", []string{"<!DOCTYPE html><html></html>"}, blob.ErrorMediaTypeNotMatched("text/plain", "text/html")),
+			Entry("json declared as json", "application/json", []string{`{"key": "value"}`}, nil),
+			Entry("json declared as json with parameters", "application/json; charset=utf-8", []string{`{"key": "value"}`}, nil),
+			Entry("text declared as csv", "text/csv", []string{"a,b,c\n1,2,3\n"}, nil),
+			Entry("text declared as html", "text/html", []string{"plain text"}, blob.ErrorMediaTypeNotMatched("text/html", "text/plain")),
+			Entry("xml declared as xml", "application/xml", []string{`<?xml version="1.0"?><root/>`}, nil),
+			Entry("unknown binary declared as vendor type", "application/vnd.tidepool.log", []string{"\x00\x01\x02\x03"}, nil),
+			Entry("zip declared as zip", "application/zip", []string{"PK\x03\x04"}, nil),
+			Entry("zip declared as docx", "application/vnd.openxmlformats-officedocument.wordprocessingml.document", []string{"PK\x03\x04"}, nil),
+			Entry("zip declared as xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", []string{"PK\x03\x04"}, nil),
+			Entry("zip declared as pptx", "application/vnd.openxmlformats-officedocument.presentationml.presentation", []string{"PK\x03\x04"}, nil),
+			Entry("zip declared as odt", "application/vnd.oasis.opendocument.text", []string{"PK\x03\x04"}, nil),
+			Entry("zip declared as epub", "application/epub+zip", []string{"PK\x03\x04"}, nil),
+			Entry("zip declared as png", "image/png", []string{"PK\x03\x04"}, blob.ErrorMediaTypeNotMatched("image/png", "application/zip")),
+			Entry("unknown binary declared as json", "application/json", []string{"\x00\x01\x02\x03"}, blob.ErrorMediaTypeNotMatched("application/json", "application/octet-stream")),
+		)
+	})
+})
@@ -0,0 +1,73 @@
+package inspect
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+
+	"github.com/tidepool-org/platform/errors"
+)
+
+// Scanner scans content for malware. It returns the signature name of any malware detected, or nil
+// if the content is clean.
+type Scanner interface {
+	Scan(ctx context.Context, reader io.Reader) (*string, error)
+}
+
+type ScannerInspector struct {
+	scanner Scanner
+}
+
+func NewScannerInspector(scanner Scanner) (*ScannerInspector, error) {
+	if scanner == nil {
+		return nil, errors.New("scanner is missing")
+	}
+
+	return &ScannerInspector{
+		scanner: scanner,
+	}, nil
+}
+
+func (s *ScannerInspector) NewInspection(ctx context.Context, mediaType string) (Inspection, error) {
+	reader, writer := io.Pipe()
+	inspection := &scannerInspection{
+		writer: writer,
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(inspection.done)
+		inspection.signature, inspection.err = s.scanner.Scan(ctx, reader)
+		if inspection.err != nil {
+			reader.CloseWithError(errors.Wrap(inspection.err, "unable to scan content"))
+		} else {
+			io.Copy(ioutil.Discard, reader) // Scanner may return a result before consuming all content
+			reader.Close()
+		}
+	}()
+
+	return inspection, nil
+}
+
+type scannerInspection struct {
+	writer    *io.PipeWriter
+	done      chan struct{}
+	signature *string
+	err       error
+}
+
+func (s *scannerInspection) Write(bytes []byte) (int, error) {
+	return s.writer.Write(bytes)
+}
+
+func (s *scannerInspection) Finish() error {
+	s.writer.Close()
+	<-s.done
+
+	if s.err != nil {
+		return errors.Wrap(s.err, "unable to scan content")
+	} else if s.signature != nil {
+		return ErrorContentInfected(*s.signature)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package inspect_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"io"
+	"io/ioutil"
+
+	blobInspect "github.com/tidepool-org/platform/blob/inspect"
+	blobInspectTest "github.com/tidepool-org/platform/blob/inspect/test"
+	"github.com/tidepool-org/platform/errors"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	netTest "github.com/tidepool-org/platform/net/test"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/test"
+)
+
+var _ = Describe("Scanner", func() {
+	Context("NewScannerInspector", func() {
+		It("returns an error if the scanner is missing", func() {
+			inspector, err := blobInspect.NewScannerInspector(nil)
+			errorsTest.ExpectEqual(err, errors.New("scanner is missing"))
+			Expect(inspector).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			Expect(blobInspect.NewScannerInspector(blobInspectTest.NewScanner())).ToNot(BeNil())
+		})
+	})
+
+	Context("with new inspection", func() {
+		var ctx context.Context
+		var scanner *blobInspectTest.Scanner
+		var content []byte
+		var scanned []byte
+		var inspection blobInspect.Inspection
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			scanner = blobInspectTest.NewScanner()
+			content = test.RandomBytes()
+			scanned = nil
+		})
+
+		JustBeforeEach(func() {
+			inspector, err := blobInspect.NewScannerInspector(scanner)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(inspector).ToNot(BeNil())
+			inspection, err = inspector.NewInspection(ctx, netTest.RandomMediaType())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(inspection).ToNot(BeNil())
+		})
+
+		AfterEach(func() {
+			Expect(scanner.ScanInvocations).To(Equal(1))
+			Expect(scanner.ScanInputs[0].Context).To(Equal(ctx))
+		})
+
+		When("the scanner returns an error", func() {
+			var responseErr error
+
+			BeforeEach(func() {
+				responseErr = errorsTest.NewError()
+				scanner.ScanStub = func(ctx context.Context, reader io.Reader) (*string, error) { return nil, responseErr }
+			})
+
+			It("returns an error", func() {
+				inspection.Write(content)
+				errorsTest.ExpectEqual(inspection.Finish(), errors.Wrap(responseErr, "unable to scan content"))
+			})
+		})
+
+		When("the scanner returns a result before reading all content", func() {
+			BeforeEach(func() {
+				scanner.ScanStub = func(ctx context.Context, reader io.Reader) (*string, error) { return nil, nil }
+			})
+
+			It("returns successfully", func() {
+				Expect(inspection.Write(content)).To(Equal(len(content)))
+				Expect(inspection.Finish()).To(Succeed())
+			})
+		})
+
+		When("the scanner returns a signature", func() {
+			BeforeEach(func() {
+				scanner.ScanStub = func(ctx context.Context, reader io.Reader) (*string, error) {
+					scanned, _ = ioutil.ReadAll(reader)
+					return pointer.FromString("Win.Test.EICAR_HDB-1"), nil
+				}
+			})
+
+			It("returns an infected error", func() {
+				Expect(inspection.Write(content)).To(Equal(len(content)))
+				errorsTest.ExpectEqual(inspection.Finish(), blobInspect.ErrorContentInfected("Win.Test.EICAR_HDB-1"))
+				Expect(scanned).To(Equal(content))
+			})
+		})
+
+		When("the scanner returns no signature", func() {
+			BeforeEach(func() {
+				scanner.ScanStub = func(ctx context.Context, reader io.Reader) (*string, error) {
+					scanned, _ = ioutil.ReadAll(reader)
+					return nil, nil
+				}
+			})
+
+			It("returns successfully", func() {
+				Expect(inspection.Write(content)).To(Equal(len(content)))
+				Expect(inspection.Finish()).To(Succeed())
+				Expect(scanned).To(Equal(content))
+			})
+		})
+	})
+})
@@ -0,0 +1,48 @@
+package inspect
+
+import (
+	"context"
+
+	"github.com/tidepool-org/platform/blob"
+	"github.com/tidepool-org/platform/errors"
+)
+
+type SizeInspector struct {
+	maximum int
+}
+
+func NewSizeInspector(maximum int) (*SizeInspector, error) {
+	if maximum < 0 {
+		return nil, errors.New("maximum is invalid")
+	}
+
+	return &SizeInspector{
+		maximum: maximum,
+	}, nil
+}
+
+func (s *SizeInspector) NewInspection(ctx context.Context, mediaType string) (Inspection, error) {
+	return &sizeInspection{
+		maximum: s.maximum,
+	}, nil
+}
+
+type sizeInspection struct {
+	maximum int
+	size    int
+}
+
+func (s *sizeInspection) Write(bytes []byte) (int, error) {
+	s.size += len(bytes)
+	if s.size > s.maximum {
+		return 0, blob.ErrorSizeExceedsMaximum(s.maximum)
+	}
+	return len(bytes), nil
+}
+
+func (s *sizeInspection) Finish() error {
+	if s.size > s.maximum {
+		return blob.ErrorSizeExceedsMaximum(s.maximum)
+	}
+	return nil
+}
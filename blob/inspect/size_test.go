@@ -0,0 +1,62 @@
+package inspect_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	"github.com/tidepool-org/platform/blob"
+	blobInspect "github.com/tidepool-org/platform/blob/inspect"
+	"github.com/tidepool-org/platform/errors"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	netTest "github.com/tidepool-org/platform/net/test"
+	"github.com/tidepool-org/platform/test"
+)
+
+var _ = Describe("Size", func() {
+	Context("NewSizeInspector", func() {
+		It("returns an error if the maximum is invalid", func() {
+			inspector, err := blobInspect.NewSizeInspector(-1)
+			errorsTest.ExpectEqual(err, errors.New("maximum is invalid"))
+			Expect(inspector).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			Expect(blobInspect.NewSizeInspector(0)).ToNot(BeNil())
+		})
+	})
+
+	Context("with new inspection", func() {
+		var maximum int
+		var inspection blobInspect.Inspection
+
+		BeforeEach(func() {
+			maximum = test.RandomIntFromRange(16, 1024)
+			inspector, err := blobInspect.NewSizeInspector(maximum)
+			Expect(err).ToNot(HaveOccurred())
+			inspection, err = inspector.NewInspection(context.Background(), netTest.RandomMediaType())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(inspection).ToNot(BeNil())
+		})
+
+		It("returns successfully if the size is less than the maximum", func() {
+			Expect(inspection.Write(test.RandomBytesFromRange(1, maximum-1))).To(BeNumerically(">", 0))
+			Expect(inspection.Finish()).To(Succeed())
+		})
+
+		It("returns successfully if the size is equal to the maximum across writes", func() {
+			Expect(inspection.Write(make([]byte, maximum-1))).To(Equal(maximum - 1))
+			Expect(inspection.Write(make([]byte, 1))).To(Equal(1))
+			Expect(inspection.Finish()).To(Succeed())
+		})
+
+		It("returns an error if the size exceeds the maximum", func() {
+			Expect(inspection.Write(make([]byte, maximum))).To(Equal(maximum))
+			count, err := inspection.Write(make([]byte, 1))
+			errorsTest.ExpectEqual(err, blob.ErrorSizeExceedsMaximum(maximum))
+			Expect(count).To(Equal(0))
+			errorsTest.ExpectEqual(inspection.Finish(), blob.ErrorSizeExceedsMaximum(maximum))
+		})
+	})
+})
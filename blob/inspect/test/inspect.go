@@ -0,0 +1,161 @@
+package test
+
+import (
+	"context"
+	"io"
+
+	blobInspect "github.com/tidepool-org/platform/blob/inspect"
+)
+
+type NewInspectionInput struct {
+	Context   context.Context
+	MediaType string
+}
+
+type NewInspectionOutput struct {
+	Inspection blobInspect.Inspection
+	Error      error
+}
+
+type Inspector struct {
+	NewInspectionInvocations int
+	NewInspectionInputs      []NewInspectionInput
+	NewInspectionStub        func(ctx context.Context, mediaType string) (blobInspect.Inspection, error)
+	NewInspectionOutputs     []NewInspectionOutput
+	NewInspectionOutput      *NewInspectionOutput
+}
+
+func NewInspector() *Inspector {
+	return &Inspector{}
+}
+
+func (i *Inspector) NewInspection(ctx context.Context, mediaType string) (blobInspect.Inspection, error) {
+	i.NewInspectionInvocations++
+	i.NewInspectionInputs = append(i.NewInspectionInputs, NewInspectionInput{Context: ctx, MediaType: mediaType})
+	if i.NewInspectionStub != nil {
+		return i.NewInspectionStub(ctx, mediaType)
+	}
+	if len(i.NewInspectionOutputs) > 0 {
+		output := i.NewInspectionOutputs[0]
+		i.NewInspectionOutputs = i.NewInspectionOutputs[1:]
+		return output.Inspection, output.Error
+	}
+	if i.NewInspectionOutput != nil {
+		return i.NewInspectionOutput.Inspection, i.NewInspectionOutput.Error
+	}
+	panic("NewInspection has no output")
+}
+
+func (i *Inspector) AssertOutputsEmpty() {
+	if len(i.NewInspectionOutputs) > 0 {
+		panic("NewInspectionOutputs is not empty")
+	}
+}
+
+type WriteOutput struct {
+	BytesWritten int
+	Error        error
+}
+
+type Inspection struct {
+	WriteInvocations  int
+	WriteInputs       [][]byte
+	WriteStub         func(bytes []byte) (int, error)
+	WriteOutputs      []WriteOutput
+	WriteOutput       *WriteOutput
+	FinishInvocations int
+	FinishStub        func() error
+	FinishOutputs     []error
+	FinishOutput      *error
+}
+
+func NewInspection() *Inspection {
+	return &Inspection{}
+}
+
+func (i *Inspection) Write(bytes []byte) (int, error) {
+	i.WriteInvocations++
+	i.WriteInputs = append(i.WriteInputs, append([]byte{}, bytes...))
+	if i.WriteStub != nil {
+		return i.WriteStub(bytes)
+	}
+	if len(i.WriteOutputs) > 0 {
+		output := i.WriteOutputs[0]
+		i.WriteOutputs = i.WriteOutputs[1:]
+		return output.BytesWritten, output.Error
+	}
+	if i.WriteOutput != nil {
+		return i.WriteOutput.BytesWritten, i.WriteOutput.Error
+	}
+	panic("Write has no output")
+}
+
+func (i *Inspection) Finish() error {
+	i.FinishInvocations++
+	if i.FinishStub != nil {
+		return i.FinishStub()
+	}
+	if len(i.FinishOutputs) > 0 {
+		output := i.FinishOutputs[0]
+		i.FinishOutputs = i.FinishOutputs[1:]
+		return output
+	}
+	if i.FinishOutput != nil {
+		return *i.FinishOutput
+	}
+	panic("Finish has no output")
+}
+
+func (i *Inspection) AssertOutputsEmpty() {
+	if len(i.WriteOutputs) > 0 {
+		panic("WriteOutputs is not empty")
+	}
+	if len(i.FinishOutputs) > 0 {
+		panic("FinishOutputs is not empty")
+	}
+}
+
+type ScanInput struct {
+	Context context.Context
+	Reader  io.Reader
+}
+
+type ScanOutput struct {
+	Signature *string
+	Error     error
+}
+
+type Scanner struct {
+	ScanInvocations int
+	ScanInputs      []ScanInput
+	ScanStub        func(ctx context.Context, reader io.Reader) (*string, error)
+	ScanOutputs     []ScanOutput
+	ScanOutput      *ScanOutput
+}
+
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+func (s *Scanner) Scan(ctx context.Context, reader io.Reader) (*string, error) {
+	s.ScanInvocations++
+	s.ScanInputs = append(s.ScanInputs, ScanInput{Context: ctx, Reader: reader})
+	if s.ScanStub != nil {
+		return s.ScanStub(ctx, reader)
+	}
+	if len(s.ScanOutputs) > 0 {
+		output := s.ScanOutputs[0]
+		s.ScanOutputs = s.ScanOutputs[1:]
+		return output.Signature, output.Error
+	}
+	if s.ScanOutput != nil {
+		return s.ScanOutput.Signature, s.ScanOutput.Error
+	}
+	panic("Scan has no output")
+}
+
+func (s *Scanner) AssertOutputsEmpty() {
+	if len(s.ScanOutputs) > 0 {
+		panic("ScanOutputs is not empty")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/ant0ine/go-json-rest/rest"
 
@@ -36,8 +37,11 @@ func (r *Router) Routes() []*rest.Route {
 	return []*rest.Route{
 		rest.Get("/v1/users/:userId/blobs", r.List),
 		rest.Post("/v1/users/:userId/blobs", r.Create),
+		rest.Get("/v1/blobs/expired", r.ListExpired),
 		rest.Get("/v1/blobs/:id", r.Get),
 		rest.Get("/v1/blobs/:id/content", r.GetContent),
+		rest.Post("/v1/blobs/:id/links", r.CreateLink),
+		rest.Get("/v1/blobs/:id/links/content", r.GetLinkContent),
 		rest.Delete("/v1/blobs/:id", r.Delete),
 	}
 }
@@ -93,16 +97,33 @@ func (r *Router) Create(res rest.ResponseWriter, req *rest.Request) {
 		return
 	}
 
+	expirationTime, err := request.ParseTimeHeader(req.Header, blob.HeaderExpirationTime, time.RFC3339)
+	if err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	skipInspection, err := request.ParseBoolHeader(req.Header, blob.HeaderSkipInspection)
+	if err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
 	create := blob.NewCreate()
 	create.Body = req.Body
 	create.DigestMD5 = digestMD5
 	create.MediaType = mediaType
+	create.ExpirationTime = expirationTime
+	create.SkipInspection = skipInspection
 
 	blb, err := r.provider.BlobClient().Create(req.Context(), userID, create)
 	if err != nil {
-		if errors.Code(err) == blob.ErrorCodeDigestsNotEqual {
+		if code := errors.Code(err); code == blob.ErrorCodeDigestsNotEqual || code == blob.ErrorCodeMediaTypeNotMatched {
 			responder.Error(http.StatusBadRequest, err)
 			return
+		} else if code == blob.ErrorCodeSizeExceedsMaximum {
+			responder.Error(http.StatusRequestEntityTooLarge, err)
+			return
 		} else if responder.RespondIfError(err) {
 			return
 		}
@@ -153,20 +174,62 @@ func (r *Router) GetContent(res rest.ResponseWriter, req *rest.Request) {
 		return
 	}
 
-	defer content.Body.Close()
+	respondWithContent(responder, content)
+}
 
-	mutators := []request.ResponseMutator{}
-	if content.DigestMD5 != nil {
-		mutators = append(mutators, request.NewHeaderMutator("Digest", fmt.Sprintf("MD5=%s", *content.DigestMD5)))
+func (r *Router) CreateLink(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	id, err := request.DecodeRequestPathParameter(req, "id", blob.IsValidID)
+	if err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
 	}
-	if content.MediaType != nil {
-		mutators = append(mutators, request.NewHeaderMutator("Content-Type", *content.MediaType))
+
+	create := blob.NewLinkCreate()
+	if err = request.DecodeRequestBody(req.Request, create); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
 	}
-	if content.Size != nil {
-		mutators = append(mutators, request.NewHeaderMutator("Content-Length", strconv.Itoa(*content.Size)))
+
+	link, err := r.provider.BlobClient().CreateLink(req.Context(), id, create)
+	if errors.Code(err) == blob.ErrorCodeStatusNotAvailable {
+		responder.Error(http.StatusConflict, err)
+		return
+	} else if responder.RespondIfError(err) {
+		return
+	} else if link == nil {
+		responder.Error(http.StatusNotFound, request.ErrorResourceNotFoundWithID(id))
+		return
 	}
 
-	responder.Reader(http.StatusOK, content.Body, mutators...)
+	responder.Data(http.StatusCreated, link)
+}
+
+func (r *Router) GetLinkContent(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	id, err := request.DecodeRequestPathParameter(req, "id", blob.IsValidID)
+	if err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	query := blob.NewLinkQuery()
+	if err = request.DecodeRequestQuery(req.Request, query); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	content, err := r.provider.BlobClient().GetLinkContent(req.Context(), id, query)
+	if responder.RespondIfError(err) {
+		return
+	} else if content == nil {
+		responder.Error(http.StatusNotFound, request.ErrorResourceNotFoundWithID(id))
+		return
+	}
+
+	respondWithContent(responder, content)
 }
 
 func (r *Router) Delete(res rest.ResponseWriter, req *rest.Request) {
@@ -190,3 +253,39 @@ func (r *Router) Delete(res rest.ResponseWriter, req *rest.Request) {
 
 	responder.Empty(http.StatusNoContent)
 }
+
+func (r *Router) ListExpired(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	// FUTURE: Validate supplemental request headers
+
+	pagination := page.NewPagination()
+	if err := request.DecodeRequestQuery(req.Request, pagination); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	blbs, err := r.provider.BlobClient().ListExpired(req.Context(), pagination)
+	if responder.RespondIfError(err) {
+		return
+	}
+
+	responder.Data(http.StatusOK, blbs)
+}
+
+func respondWithContent(responder *request.Responder, content *blob.Content) {
+	defer content.Body.Close()
+
+	mutators := []request.ResponseMutator{}
+	if content.DigestMD5 != nil {
+		mutators = append(mutators, request.NewHeaderMutator("Digest", fmt.Sprintf("MD5=%s", *content.DigestMD5)))
+	}
+	if content.MediaType != nil {
+		mutators = append(mutators, request.NewHeaderMutator("Content-Type", *content.MediaType))
+	}
+	if content.Size != nil {
+		mutators = append(mutators, request.NewHeaderMutator("Content-Length", strconv.Itoa(*content.Size)))
+	}
+
+	responder.Reader(http.StatusOK, content.Body, mutators...)
+}
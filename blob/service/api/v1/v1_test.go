@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/ant0ine/go-json-rest/rest"
 
@@ -75,8 +76,11 @@ var _ = Describe("V1", func() {
 				Expect(router.Routes()).To(ConsistOf(
 					PointTo(MatchFields(IgnoreExtras, Fields{"HttpMethod": Equal(http.MethodGet), "PathExp": Equal("/v1/users/:userId/blobs")})),
 					PointTo(MatchFields(IgnoreExtras, Fields{"HttpMethod": Equal(http.MethodPost), "PathExp": Equal("/v1/users/:userId/blobs")})),
+					PointTo(MatchFields(IgnoreExtras, Fields{"HttpMethod": Equal(http.MethodGet), "PathExp": Equal("/v1/blobs/expired")})),
 					PointTo(MatchFields(IgnoreExtras, Fields{"HttpMethod": Equal(http.MethodGet), "PathExp": Equal("/v1/blobs/:id")})),
 					PointTo(MatchFields(IgnoreExtras, Fields{"HttpMethod": Equal(http.MethodGet), "PathExp": Equal("/v1/blobs/:id/content")})),
+					PointTo(MatchFields(IgnoreExtras, Fields{"HttpMethod": Equal(http.MethodPost), "PathExp": Equal("/v1/blobs/:id/links")})),
+					PointTo(MatchFields(IgnoreExtras, Fields{"HttpMethod": Equal(http.MethodGet), "PathExp": Equal("/v1/blobs/:id/links/content")})),
 					PointTo(MatchFields(IgnoreExtras, Fields{"HttpMethod": Equal(http.MethodDelete), "PathExp": Equal("/v1/blobs/:id")})),
 				))
 			})
@@ -107,6 +111,75 @@ var _ = Describe("V1", func() {
 				res.AssertOutputsEmpty()
 			})
 
+			Context("ListExpired", func() {
+				BeforeEach(func() {
+					req.Method = http.MethodGet
+					req.URL.Path = "/v1/blobs/expired"
+				})
+
+				It("panics when the response is missing", func() {
+					Expect(func() { router.ListExpired(nil, req) }).To(Panic())
+				})
+
+				It("panics when the request is missing", func() {
+					Expect(func() { router.ListExpired(res, nil) }).To(Panic())
+				})
+
+				Context("responds with JSON", func() {
+					AfterEach(func() {
+						Expect(res.HeaderOutput).To(Equal(&http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}))
+					})
+
+					When("a pagination query parameter is invalid", func() {
+						BeforeEach(func() {
+							query := url.Values{"size": []string{"0"}}
+							req.URL.RawQuery = query.Encode()
+						})
+
+						It("responds with bad request and expected error in body", func() {
+							res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+							handlerFunc(res, req)
+							Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusBadRequest}))
+							Expect(res.WriteInputs).To(HaveLen(1))
+							errorsTest.ExpectErrorJSON(errorsTest.WithParameterSource(structureValidator.ErrorValueNotInRange(0, 1, 100), "size"), res.WriteInputs[0])
+						})
+					})
+
+					Context("with client", func() {
+						var client *blobTest.Client
+
+						BeforeEach(func() {
+							client = blobTest.NewClient()
+							provider.BlobClientOutputs = []blob.Client{client}
+						})
+
+						AfterEach(func() {
+							Expect(client.ListExpiredInputs).To(Equal([]blobTest.ListExpiredInput{{Context: ctx, Pagination: page.NewPagination()}}))
+							client.AssertOutputsEmpty()
+						})
+
+						It("responds with an unauthorized error when the client returns an unauthorized error", func() {
+							client.ListExpiredOutputs = []blobTest.ListExpiredOutput{{Blobs: nil, Error: request.ErrorUnauthorized()}}
+							res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+							handlerFunc(res, req)
+							Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusForbidden}))
+							Expect(res.WriteInputs).To(HaveLen(1))
+							errorsTest.ExpectErrorJSON(request.ErrorUnauthorized(), res.WriteInputs[0])
+						})
+
+						It("responds successfully when the client returns blobs", func() {
+							blobs := blobTest.RandomBlobs(1, 4)
+							client.ListExpiredOutputs = []blobTest.ListExpiredOutput{{Blobs: blobs, Error: nil}}
+							res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+							handlerFunc(res, req)
+							Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusOK}))
+							Expect(res.WriteInputs).To(HaveLen(1))
+							Expect(json.Marshal(blobs)).To(MatchJSON(res.WriteInputs[0]))
+						})
+					})
+				})
+			})
+
 			Context("with user id", func() {
 				var userID string
 
@@ -373,6 +446,9 @@ var _ = Describe("V1", func() {
 								if create.MediaType != nil {
 									req.Header.Add("Content-Type", *create.MediaType)
 								}
+								if create.ExpirationTime != nil {
+									req.Header.Add(blob.HeaderExpirationTime, create.ExpirationTime.Format(time.RFC3339))
+								}
 							})
 
 							When("the digest header is invalid", func() {
@@ -445,6 +521,20 @@ var _ = Describe("V1", func() {
 								})
 							})
 
+							When("the expiration time header is invalid", func() {
+								BeforeEach(func() {
+									req.Header.Add(blob.HeaderExpirationTime, "invalid")
+								})
+
+								It("responds with bad request and expected error in body", func() {
+									res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+									handlerFunc(res, req)
+									Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusBadRequest}))
+									Expect(res.WriteInputs).To(HaveLen(1))
+									errorsTest.ExpectErrorJSON(request.ErrorHeaderInvalid(blob.HeaderExpirationTime), res.WriteInputs[0])
+								})
+							})
+
 							Context("with client", func() {
 								var client *blobTest.Client
 
@@ -468,6 +558,26 @@ var _ = Describe("V1", func() {
 										errorsTest.ExpectErrorJSON(err, res.WriteInputs[0])
 									})
 
+									It("responds with a bad request error when the client returns a media type not matched error", func() {
+										err := blob.ErrorMediaTypeNotMatched(netTest.RandomMediaType(), netTest.RandomMediaType())
+										client.CreateOutputs = []blobTest.CreateOutput{{Blob: nil, Error: err}}
+										res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+										handlerFunc(res, req)
+										Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusBadRequest}))
+										Expect(res.WriteInputs).To(HaveLen(1))
+										errorsTest.ExpectErrorJSON(err, res.WriteInputs[0])
+									})
+
+									It("responds with a request entity too large error when the client returns a size exceeds maximum error", func() {
+										err := blob.ErrorSizeExceedsMaximum(blob.SizeMaximum)
+										client.CreateOutputs = []blobTest.CreateOutput{{Blob: nil, Error: err}}
+										res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+										handlerFunc(res, req)
+										Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusRequestEntityTooLarge}))
+										Expect(res.WriteInputs).To(HaveLen(1))
+										errorsTest.ExpectErrorJSON(err, res.WriteInputs[0])
+									})
+
 									It("responds with an unauthorized error when the client returns an unauthorized error", func() {
 										client.CreateOutputs = []blobTest.CreateOutput{{Blob: nil, Error: request.ErrorUnauthorized()}}
 										res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
@@ -517,6 +627,20 @@ var _ = Describe("V1", func() {
 									clientAssertions()
 								})
 
+								When("the expiration time header is specified", func() {
+									BeforeEach(func() {
+										create.ExpirationTime = pointer.FromTime(test.RandomTimeFromRange(time.Now().Add(time.Hour), test.RandomTimeMaximum()).Truncate(time.Second))
+									})
+
+									AfterEach(func() {
+										Expect(client.CreateInputs).To(HaveLen(1))
+										Expect(client.CreateInputs[0].Create.ExpirationTime).ToNot(BeNil())
+										Expect(*client.CreateInputs[0].Create.ExpirationTime).To(BeTemporally("==", *create.ExpirationTime))
+									})
+
+									clientAssertions()
+								})
+
 								When("the digest header is specified", func() {
 									AfterEach(func() {
 										Expect(client.CreateInputs).To(Equal([]blobTest.CreateInput{{
@@ -831,6 +955,234 @@ var _ = Describe("V1", func() {
 						})
 					})
 				})
+
+				Context("CreateLink", func() {
+					var create *blob.LinkCreate
+
+					BeforeEach(func() {
+						req.Method = http.MethodPost
+						req.URL.Path = fmt.Sprintf("/v1/blobs/%s/links", id)
+						create = blobTest.RandomLinkCreate()
+						body, err := json.Marshal(blobTest.NewObjectFromLinkCreate(create, test.ObjectFormatJSON))
+						Expect(err).ToNot(HaveOccurred())
+						req.Body = ioutil.NopCloser(bytes.NewReader(body))
+					})
+
+					It("panics when the response is missing", func() {
+						Expect(func() { router.CreateLink(nil, req) }).To(Panic())
+					})
+
+					It("panics when the request is missing", func() {
+						Expect(func() { router.CreateLink(res, nil) }).To(Panic())
+					})
+
+					When("the path contains an invalid id", func() {
+						BeforeEach(func() {
+							req.URL.Path = "/v1/blobs/invalid/links"
+						})
+
+						It("responds with bad request and expected error in body", func() {
+							res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+							handlerFunc(res, req)
+							Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusBadRequest}))
+							Expect(res.HeaderOutput).To(Equal(&http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}))
+							Expect(res.WriteInputs).To(HaveLen(1))
+							errorsTest.ExpectErrorJSON(request.ErrorParameterInvalid("id"), res.WriteInputs[0])
+						})
+					})
+
+					When("the body is invalid", func() {
+						BeforeEach(func() {
+							req.Body = ioutil.NopCloser(bytes.NewReader([]byte(`{"singleUse": "invalid"}`)))
+						})
+
+						It("responds with bad request", func() {
+							res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+							handlerFunc(res, req)
+							Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusBadRequest}))
+							Expect(res.HeaderOutput).To(Equal(&http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}))
+							Expect(res.WriteInputs).To(HaveLen(1))
+						})
+					})
+
+					Context("with client", func() {
+						var client *blobTest.Client
+
+						BeforeEach(func() {
+							client = blobTest.NewClient()
+							provider.BlobClientOutputs = []blob.Client{client}
+						})
+
+						AfterEach(func() {
+							Expect(client.CreateLinkInputs).To(HaveLen(1))
+							Expect(client.CreateLinkInputs[0].Context).To(Equal(ctx))
+							Expect(client.CreateLinkInputs[0].ID).To(Equal(id))
+							Expect(*client.CreateLinkInputs[0].Create.ExpirationTime).To(BeTemporally("==", *create.ExpirationTime))
+							Expect(client.CreateLinkInputs[0].Create.SingleUse).To(Equal(create.SingleUse))
+							client.AssertOutputsEmpty()
+						})
+
+						It("responds with an unauthorized error when the client returns an unauthorized error", func() {
+							client.CreateLinkOutputs = []blobTest.CreateLinkOutput{{Link: nil, Error: request.ErrorUnauthorized()}}
+							res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+							handlerFunc(res, req)
+							Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusForbidden}))
+							Expect(res.HeaderOutput).To(Equal(&http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}))
+							Expect(res.WriteInputs).To(HaveLen(1))
+							errorsTest.ExpectErrorJSON(request.ErrorUnauthorized(), res.WriteInputs[0])
+						})
+
+						It("responds with a conflict error when the client returns a status not available error", func() {
+							client.CreateLinkOutputs = []blobTest.CreateLinkOutput{{Link: nil, Error: blob.ErrorStatusNotAvailable(blob.StatusCreated)}}
+							res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+							handlerFunc(res, req)
+							Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusConflict}))
+							Expect(res.HeaderOutput).To(Equal(&http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}))
+							Expect(res.WriteInputs).To(HaveLen(1))
+							errorsTest.ExpectErrorJSON(blob.ErrorStatusNotAvailable(blob.StatusCreated), res.WriteInputs[0])
+						})
+
+						It("responds with an internal server error when the client returns an unknown error", func() {
+							client.CreateLinkOutputs = []blobTest.CreateLinkOutput{{Link: nil, Error: errorsTest.NewError()}}
+							res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+							handlerFunc(res, req)
+							Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusInternalServerError}))
+							Expect(res.HeaderOutput).To(Equal(&http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}))
+							Expect(res.WriteInputs).To(HaveLen(1))
+							errorsTest.ExpectErrorJSON(request.ErrorInternalServerError(nil), res.WriteInputs[0])
+						})
+
+						It("responds with not found error when the client does not return a link", func() {
+							client.CreateLinkOutputs = []blobTest.CreateLinkOutput{{Link: nil, Error: nil}}
+							res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+							handlerFunc(res, req)
+							Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusNotFound}))
+							Expect(res.HeaderOutput).To(Equal(&http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}))
+							Expect(res.WriteInputs).To(HaveLen(1))
+							errorsTest.ExpectErrorJSON(request.ErrorResourceNotFoundWithID(id), res.WriteInputs[0])
+						})
+
+						It("responds successfully", func() {
+							link := blobTest.RandomLink()
+							client.CreateLinkOutputs = []blobTest.CreateLinkOutput{{Link: link, Error: nil}}
+							res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+							handlerFunc(res, req)
+							Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusCreated}))
+							Expect(res.HeaderOutput).To(Equal(&http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}))
+							Expect(res.WriteInputs).To(HaveLen(1))
+							Expect(json.Marshal(link)).To(MatchJSON(res.WriteInputs[0]))
+						})
+					})
+				})
+
+				Context("GetLinkContent", func() {
+					var query *blob.LinkQuery
+
+					BeforeEach(func() {
+						req.Method = http.MethodGet
+						req.URL.Path = fmt.Sprintf("/v1/blobs/%s/links/content", id)
+						query = blobTest.RandomLinkQuery()
+						Expect(query.MutateRequest(req.Request)).To(Succeed())
+					})
+
+					It("panics when the response is missing", func() {
+						Expect(func() { router.GetLinkContent(nil, req) }).To(Panic())
+					})
+
+					It("panics when the request is missing", func() {
+						Expect(func() { router.GetLinkContent(res, nil) }).To(Panic())
+					})
+
+					When("the path contains an invalid id", func() {
+						BeforeEach(func() {
+							req.URL.Path = "/v1/blobs/invalid/links/content"
+						})
+
+						It("responds with bad request and expected error in body", func() {
+							res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+							handlerFunc(res, req)
+							Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusBadRequest}))
+							Expect(res.HeaderOutput).To(Equal(&http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}))
+							Expect(res.WriteInputs).To(HaveLen(1))
+							errorsTest.ExpectErrorJSON(request.ErrorParameterInvalid("id"), res.WriteInputs[0])
+						})
+					})
+
+					When("a query parameter is missing", func() {
+						BeforeEach(func() {
+							values := req.URL.Query()
+							values.Del("signature")
+							req.URL.RawQuery = values.Encode()
+						})
+
+						It("responds with bad request", func() {
+							res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+							handlerFunc(res, req)
+							Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusBadRequest}))
+							Expect(res.HeaderOutput).To(Equal(&http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}))
+							Expect(res.WriteInputs).To(HaveLen(1))
+						})
+					})
+
+					Context("with client", func() {
+						var client *blobTest.Client
+
+						BeforeEach(func() {
+							client = blobTest.NewClient()
+							provider.BlobClientOutputs = []blob.Client{client}
+						})
+
+						AfterEach(func() {
+							Expect(client.GetLinkContentInputs).To(HaveLen(1))
+							Expect(client.GetLinkContentInputs[0].Context).To(Equal(ctx))
+							Expect(client.GetLinkContentInputs[0].ID).To(Equal(id))
+							Expect(*client.GetLinkContentInputs[0].Query.ExpirationTime).To(BeTemporally("==", *query.ExpirationTime))
+							Expect(client.GetLinkContentInputs[0].Query.SingleUse).To(Equal(query.SingleUse))
+							Expect(client.GetLinkContentInputs[0].Query.Nonce).To(Equal(query.Nonce))
+							Expect(client.GetLinkContentInputs[0].Query.Signature).To(Equal(query.Signature))
+							client.AssertOutputsEmpty()
+						})
+
+						It("responds with an unauthorized error when the client returns an unauthorized error", func() {
+							client.GetLinkContentOutputs = []blobTest.GetLinkContentOutput{{Content: nil, Error: request.ErrorUnauthorized()}}
+							res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+							handlerFunc(res, req)
+							Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusForbidden}))
+							Expect(res.HeaderOutput).To(Equal(&http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}))
+							Expect(res.WriteInputs).To(HaveLen(1))
+							errorsTest.ExpectErrorJSON(request.ErrorUnauthorized(), res.WriteInputs[0])
+						})
+
+						It("responds with not found error when the client does not return content", func() {
+							client.GetLinkContentOutputs = []blobTest.GetLinkContentOutput{{Content: nil, Error: nil}}
+							res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+							handlerFunc(res, req)
+							Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusNotFound}))
+							Expect(res.HeaderOutput).To(Equal(&http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}))
+							Expect(res.WriteInputs).To(HaveLen(1))
+							errorsTest.ExpectErrorJSON(request.ErrorResourceNotFoundWithID(id), res.WriteInputs[0])
+						})
+
+						It("responds successfully with headers", func() {
+							body := test.RandomBytes()
+							content := blob.NewContent()
+							content.Body = ioutil.NopCloser(bytes.NewReader(body))
+							content.DigestMD5 = pointer.FromString(cryptoTest.RandomBase64EncodedMD5Hash())
+							content.MediaType = pointer.FromString(netTest.RandomMediaType())
+							content.Size = pointer.FromInt(test.RandomIntFromRange(1, 100*1024*1024))
+							client.GetLinkContentOutputs = []blobTest.GetLinkContentOutput{{Content: content, Error: nil}}
+							res.WriteOutputs = []testRest.WriteOutput{{BytesWritten: 0, Error: nil}}
+							handlerFunc(res, req)
+							Expect(res.WriteHeaderInputs).To(Equal([]int{http.StatusOK}))
+							Expect(res.WriteInputs).To(Equal([][]byte{body}))
+							Expect(res.HeaderOutput).To(Equal(&http.Header{
+								"Content-Length": []string{strconv.Itoa(*content.Size)},
+								"Content-Type":   []string{*content.MediaType},
+								"Digest":         []string{fmt.Sprintf("MD5=%s", *content.DigestMD5)},
+							}))
+						})
+					})
+				})
 			})
 		})
 	})
@@ -5,14 +5,18 @@ import (
 	"crypto/md5"
 	"encoding/base64"
 	"io"
+	"time"
 
 	"github.com/tidepool-org/platform/blob"
+	blobInspect "github.com/tidepool-org/platform/blob/inspect"
 	blobStoreStructured "github.com/tidepool-org/platform/blob/store/structured"
 	blobStoreUnstructured "github.com/tidepool-org/platform/blob/store/unstructured"
 	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/log"
 	"github.com/tidepool-org/platform/page"
 	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/request"
+	storeUnstructured "github.com/tidepool-org/platform/store/unstructured"
 	"github.com/tidepool-org/platform/structure"
 	structureValidator "github.com/tidepool-org/platform/structure/validator"
 	"github.com/tidepool-org/platform/user"
@@ -21,6 +25,8 @@ import (
 type ClientProvider interface {
 	BlobStructuredStore() blobStoreStructured.Store
 	BlobUnstructuredStore() blobStoreUnstructured.Store
+	BlobLinker() *Linker
+	BlobInspector() blobInspect.Inspector
 	UserClient() user.Client
 }
 
@@ -60,11 +66,17 @@ func (c *Client) Create(ctx context.Context, userID string, create *blob.Create)
 		return nil, errors.Wrap(err, "create is invalid")
 	}
 
+	skipInspection := pointer.ToBool(create.SkipInspection)
+	if skipInspection && !request.DetailsFromContext(ctx).IsService() {
+		return nil, request.ErrorUnauthorized()
+	}
+
 	session := c.BlobStructuredStore().NewSession()
 	defer session.Close()
 
 	structuredCreate := blobStoreStructured.NewCreate()
 	structuredCreate.MediaType = pointer.CloneString(create.MediaType)
+	structuredCreate.ExpirationTime = pointer.CloneTime(create.ExpirationTime)
 	blb, err := session.Create(ctx, userID, structuredCreate)
 	if err != nil {
 		return nil, err
@@ -72,12 +84,37 @@ func (c *Client) Create(ctx context.Context, userID string, create *blob.Create)
 
 	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"userId": userID, "id": *blb.ID})
 
+	// An empty pipeline accepts any content, including content larger than the size maximum
+	var inspector blobInspect.Inspector = blobInspect.NewPipeline()
+	if !skipInspection {
+		inspector = c.BlobInspector()
+	}
+
+	inspection, err := inspector.NewInspection(ctx, *create.MediaType)
+	if err != nil {
+		if _, deleteErr := session.Delete(ctx, *blb.ID); deleteErr != nil {
+			logger.WithError(deleteErr).Error("Unable to delete blob after failure to inspect blob content")
+		}
+		return nil, err
+	}
+
 	hasher := md5.New()
 	sizer := NewSizeWriter()
-	err = c.BlobUnstructuredStore().Put(ctx, userID, *blb.ID, io.TeeReader(io.TeeReader(create.Body, hasher), sizer))
-	if err != nil {
+	options := storeUnstructured.NewOptions()
+	options.MediaType = create.MediaType
+	err = c.BlobUnstructuredStore().Put(ctx, userID, *blb.ID, io.TeeReader(io.TeeReader(io.TeeReader(create.Body, hasher), sizer), inspection), options)
+	inspectionErr := inspection.Finish()
+	infected := inspectionErr != nil && blobInspect.IsErrorContentInfected(inspectionErr)
+	if err != nil || (inspectionErr != nil && !infected) {
+		// Content may have been partially put, so always delete it
+		if _, deleteErr := c.BlobUnstructuredStore().Delete(ctx, userID, *blb.ID); deleteErr != nil {
+			logger.WithError(deleteErr).Error("Unable to delete blob content after failure to put or inspect blob content")
+		}
 		if _, deleteErr := session.Delete(ctx, *blb.ID); deleteErr != nil {
-			logger.WithError(deleteErr).Error("Unable to delete blob after failure to put blob content")
+			logger.WithError(deleteErr).Error("Unable to delete blob after failure to put or inspect blob content")
+		}
+		if inspectionErr != nil && !infected {
+			return nil, inspectionErr
 		}
 		return nil, err
 	}
@@ -99,6 +136,10 @@ func (c *Client) Create(ctx context.Context, userID string, create *blob.Create)
 	update.DigestMD5 = pointer.FromString(digestMD5)
 	update.Size = pointer.FromInt(sizer.Size)
 	update.Status = pointer.FromString(blob.StatusAvailable)
+	if infected {
+		logger.WithError(inspectionErr).Warn("Quarantining blob with infected content")
+		update.Status = pointer.FromString(blob.StatusQuarantined)
+	}
 	return session.Update(ctx, *blb.ID, update)
 }
 
@@ -110,7 +151,14 @@ func (c *Client) Get(ctx context.Context, id string) (*blob.Blob, error) {
 	session := c.BlobStructuredStore().NewSession()
 	defer session.Close()
 
-	return session.Get(ctx, id)
+	blb, err := session.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if blb == nil || blb.IsExpired() {
+		return nil, nil
+	}
+
+	return blb, nil
 }
 
 func (c *Client) GetContent(ctx context.Context, id string) (*blob.Content, error) {
@@ -124,21 +172,11 @@ func (c *Client) GetContent(ctx context.Context, id string) (*blob.Content, erro
 	blb, err := session.Get(ctx, id)
 	if err != nil {
 		return nil, err
-	} else if blb == nil {
+	} else if blb == nil || blb.IsExpired() || blb.IsQuarantined() {
 		return nil, nil
 	}
 
-	reader, err := c.BlobUnstructuredStore().Get(ctx, *blb.UserID, *blb.ID)
-	if err != nil {
-		return nil, err
-	}
-
-	return &blob.Content{
-		Body:      reader,
-		DigestMD5: blb.DigestMD5,
-		MediaType: blb.MediaType,
-		Size:      blb.Size,
-	}, nil
+	return c.getContent(ctx, blb)
 }
 
 func (c *Client) Delete(ctx context.Context, id string) (bool, error) {
@@ -159,13 +197,128 @@ func (c *Client) Delete(ctx context.Context, id string) (bool, error) {
 	exists, err := c.BlobUnstructuredStore().Delete(ctx, *blb.UserID, *blb.ID)
 	if err != nil {
 		return false, err
-	} else if !exists {
+	} else if !exists && *blb.Status != blob.StatusCreated {
 		log.LoggerFromContext(ctx).WithField("id", id).Error("Deleting blob with no content")
 	}
 
 	return session.Delete(ctx, id)
 }
 
+func (c *Client) ListExpired(ctx context.Context, pagination *page.Pagination) (blob.Blobs, error) {
+	if err := c.UserClient().EnsureAuthorizedService(ctx); err != nil {
+		return nil, err
+	}
+
+	session := c.BlobStructuredStore().NewSession()
+	defer session.Close()
+
+	return session.ListExpired(ctx, pagination)
+}
+
+func (c *Client) CreateLink(ctx context.Context, id string, create *blob.LinkCreate) (*blob.Link, error) {
+	if err := c.UserClient().EnsureAuthorizedService(ctx); err != nil {
+		return nil, err
+	}
+
+	if create == nil {
+		create = blob.NewLinkCreate()
+	} else if err := structureValidator.New().Validate(create); err != nil {
+		return nil, errors.Wrap(err, "create is invalid")
+	}
+
+	session := c.BlobStructuredStore().NewSession()
+	defer session.Close()
+
+	blb, err := session.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if blb == nil || blb.IsExpired() || blb.IsQuarantined() {
+		return nil, nil
+	} else if *blb.Status != blob.StatusAvailable {
+		return nil, blob.ErrorStatusNotAvailable(*blb.Status)
+	}
+
+	expirationTime := time.Now().Add(blob.LinkExpirationDurationDefault)
+	if create.ExpirationTime != nil {
+		expirationTime = *create.ExpirationTime
+	}
+	if blb.ExpirationTime != nil && blb.ExpirationTime.Before(expirationTime) {
+		expirationTime = *blb.ExpirationTime
+	}
+	expirationTime = expirationTime.Truncate(time.Second)
+
+	singleUse := create.SingleUse != nil && *create.SingleUse
+
+	linker := c.BlobLinker()
+
+	// Presigned URLs are served directly by the underlying store and so cannot enforce single use
+	if !singleUse && linker.Presign() {
+		url, err := c.BlobUnstructuredStore().PresignGet(ctx, *blb.UserID, *blb.ID, expirationTime)
+		if err != nil {
+			return nil, err
+		} else if url != nil {
+			return &blob.Link{
+				URL:            url,
+				ExpirationTime: pointer.FromTime(expirationTime),
+				SingleUse:      pointer.FromBool(singleUse),
+			}, nil
+		}
+	}
+
+	query := linker.NewQuery(*blb.ID, expirationTime, singleUse)
+	return &blob.Link{
+		URL:            pointer.FromString(linker.URL(*blb.ID, query)),
+		ExpirationTime: pointer.CloneTime(query.ExpirationTime),
+		SingleUse:      pointer.CloneBool(query.SingleUse),
+	}, nil
+}
+
+func (c *Client) GetLinkContent(ctx context.Context, id string, query *blob.LinkQuery) (*blob.Content, error) {
+	if query == nil {
+		return nil, errors.New("query is missing")
+	} else if err := structureValidator.New().Validate(query); err != nil {
+		return nil, errors.Wrap(err, "query is invalid")
+	}
+
+	if !c.BlobLinker().Verify(id, query) || !query.ExpirationTime.After(time.Now()) {
+		return nil, request.ErrorUnauthorized()
+	}
+
+	session := c.BlobStructuredStore().NewSession()
+	defer session.Close()
+
+	blb, err := session.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if blb == nil || blb.IsExpired() || blb.IsQuarantined() {
+		return nil, nil
+	}
+
+	if *query.SingleUse {
+		if used, err := session.UseLinkNonce(ctx, id, *query.Nonce, *query.ExpirationTime); err != nil {
+			return nil, err
+		} else if !used {
+			return nil, request.ErrorUnauthorized()
+		}
+	}
+
+	return c.getContent(ctx, blb)
+}
+
+func (c *Client) getContent(ctx context.Context, blb *blob.Blob) (*blob.Content, error) {
+	reader, err := c.BlobUnstructuredStore().Get(ctx, *blb.UserID, *blb.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &blob.Content{
+		Body:      reader,
+		DigestMD5: blb.DigestMD5,
+		MediaType: blb.MediaType,
+		Size:      blb.Size,
+	}, nil
+}
+
 type SizeWriter struct {
 	Size int
 }
@@ -8,10 +8,14 @@ import (
 	"context"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"net/url"
 	"time"
 
 	authTest "github.com/tidepool-org/platform/auth/test"
 	"github.com/tidepool-org/platform/blob"
+	blobInspect "github.com/tidepool-org/platform/blob/inspect"
+	blobInspectTest "github.com/tidepool-org/platform/blob/inspect/test"
 	blobService "github.com/tidepool-org/platform/blob/service"
 	blobServiceTest "github.com/tidepool-org/platform/blob/service/test"
 	blobStoreStructured "github.com/tidepool-org/platform/blob/store/structured"
@@ -19,6 +23,7 @@ import (
 	blobStoreUnstructured "github.com/tidepool-org/platform/blob/store/unstructured"
 	blobStoreUnstructuredTest "github.com/tidepool-org/platform/blob/store/unstructured/test"
 	blobTest "github.com/tidepool-org/platform/blob/test"
+	"github.com/tidepool-org/platform/crypto"
 	cryptoTest "github.com/tidepool-org/platform/crypto/test"
 	"github.com/tidepool-org/platform/errors"
 	errorsTest "github.com/tidepool-org/platform/errors/test"
@@ -28,7 +33,9 @@ import (
 	pageTest "github.com/tidepool-org/platform/page/test"
 	"github.com/tidepool-org/platform/pointer"
 	"github.com/tidepool-org/platform/request"
+	storeUnstructured "github.com/tidepool-org/platform/store/unstructured"
 	"github.com/tidepool-org/platform/test"
+	testHttp "github.com/tidepool-org/platform/test/http"
 	"github.com/tidepool-org/platform/user"
 	userTest "github.com/tidepool-org/platform/user/test"
 )
@@ -50,6 +57,7 @@ var _ = Describe("Client", func() {
 		clientProvider = blobServiceTest.NewClientProvider()
 		clientProvider.BlobStructuredStoreOutput = func(s blobStoreStructured.Store) *blobStoreStructured.Store { return &s }(blobStructuredStore)
 		clientProvider.BlobUnstructuredStoreOutput = func(s blobStoreUnstructured.Store) *blobStoreUnstructured.Store { return &s }(blobUnstructuredStore)
+		clientProvider.BlobInspectorOutput = func(i blobInspect.Inspector) *blobInspect.Inspector { return &i }(blobInspect.NewPipeline())
 		clientProvider.UserClientOutput = func(u user.Client) *user.Client { return &u }(userClient)
 	})
 
@@ -89,6 +97,52 @@ var _ = Describe("Client", func() {
 			ctx = request.NewContextWithDetails(ctx, details)
 		})
 
+		Context("ListExpired", func() {
+			var pagination *page.Pagination
+
+			BeforeEach(func() {
+				pagination = pageTest.RandomPagination()
+			})
+
+			AfterEach(func() {
+				Expect(userClient.EnsureAuthorizedServiceInputs).To(Equal([]context.Context{ctx}))
+			})
+
+			It("return an error when the user client ensure authorized service returns an error", func() {
+				responseErr := errorsTest.NewError()
+				userClient.EnsureAuthorizedServiceOutputs = []error{responseErr}
+				blbs, err := client.ListExpired(ctx, pagination)
+				errorsTest.ExpectEqual(err, responseErr)
+				Expect(blbs).To(BeNil())
+			})
+
+			When("user client ensure authorized service returns successfully", func() {
+				BeforeEach(func() {
+					userClient.EnsureAuthorizedServiceOutputs = []error{nil}
+				})
+
+				AfterEach(func() {
+					Expect(blobStructuredSession.ListExpiredInputs).To(Equal([]blobStoreStructuredTest.ListExpiredInput{{Context: ctx, Pagination: pagination}}))
+				})
+
+				It("returns an error if the blob structured session list expired returns an error", func() {
+					responseErr := errorsTest.NewError()
+					blobStructuredSession.ListExpiredOutputs = []blobStoreStructuredTest.ListExpiredOutput{{Blobs: nil, Error: responseErr}}
+					blbs, err := client.ListExpired(ctx, pagination)
+					errorsTest.ExpectEqual(err, responseErr)
+					Expect(blbs).To(BeNil())
+				})
+
+				It("returns successfully if the blob structured session list expired returns successfully", func() {
+					responseBlobs := blobTest.RandomBlobs(1, 3)
+					blobStructuredSession.ListExpiredOutputs = []blobStoreStructuredTest.ListExpiredOutput{{Blobs: responseBlobs, Error: nil}}
+					blbs, err := client.ListExpired(ctx, pagination)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(blbs).To(Equal(responseBlobs))
+				})
+			})
+		})
+
 		Context("with user id", func() {
 			var userID string
 
@@ -197,6 +251,141 @@ var _ = Describe("Client", func() {
 							Expect(blb).To(BeNil())
 						})
 
+						When("the blob structured session create returns successfully with inspector", func() {
+							var createBlob *blob.Blob
+							var inspector *blobInspectTest.Inspector
+
+							BeforeEach(func() {
+								createBlob = blobTest.RandomBlob()
+								createBlob.UserID = pointer.FromString(userID)
+								createBlob.DigestMD5 = nil
+								createBlob.MediaType = create.MediaType
+								createBlob.Size = nil
+								createBlob.Status = pointer.FromString(blob.StatusCreated)
+								createBlob.ModifiedTime = nil
+								blobStructuredSession.CreateOutputs = []blobStoreStructuredTest.CreateOutput{{Blob: createBlob, Error: nil}}
+								inspector = blobInspectTest.NewInspector()
+								clientProvider.BlobInspectorOutput = func(i blobInspect.Inspector) *blobInspect.Inspector { return &i }(inspector)
+							})
+
+							AfterEach(func() {
+								Expect(inspector.NewInspectionInputs).To(Equal([]blobInspectTest.NewInspectionInput{{Context: ctx, MediaType: *create.MediaType}}))
+								inspector.AssertOutputsEmpty()
+							})
+
+							It("returns an error if the inspector new inspection returns an error", func() {
+								responseErr := errorsTest.NewError()
+								inspector.NewInspectionOutputs = []blobInspectTest.NewInspectionOutput{{Inspection: nil, Error: responseErr}}
+								blobStructuredSession.DeleteOutputs = []blobStoreStructuredTest.DeleteOutput{{Deleted: true, Error: nil}}
+								blb, err := client.Create(ctx, userID, create)
+								errorsTest.ExpectEqual(err, responseErr)
+								Expect(blb).To(BeNil())
+								Expect(blobUnstructuredStore.PutInputs).To(BeEmpty())
+								Expect(blobStructuredSession.DeleteInputs).To(Equal([]blobStoreStructuredTest.DeleteInput{{Context: ctx, ID: *createBlob.ID}}))
+							})
+
+							When("the inspector new inspection returns successfully", func() {
+								var inspection *blobInspectTest.Inspection
+								var body []byte
+
+								BeforeEach(func() {
+									body = test.RandomBytes()
+									create.Body = bytes.NewReader(body)
+									create.DigestMD5 = pointer.FromString(crypto.Base64EncodedMD5Hash(body))
+									inspection = blobInspectTest.NewInspection()
+									inspection.WriteStub = func(bytes []byte) (int, error) { return len(bytes), nil }
+									inspector.NewInspectionOutputs = []blobInspectTest.NewInspectionOutput{{Inspection: inspection, Error: nil}}
+									blobUnstructuredStore.PutStub = func(ctx context.Context, userID string, id string, reader io.Reader, options *storeUnstructured.Options) error {
+										_, err := io.Copy(ioutil.Discard, reader)
+										return err
+									}
+								})
+
+								AfterEach(func() {
+									Expect(inspection.FinishInvocations).To(Equal(1))
+									inspection.AssertOutputsEmpty()
+								})
+
+								It("returns an error and deletes the blob if the inspection write returns an error", func() {
+									responseErr := blob.ErrorSizeExceedsMaximum(1)
+									inspection.WriteStub = func(bytes []byte) (int, error) { return 0, responseErr }
+									inspection.FinishOutputs = []error{responseErr}
+									blobUnstructuredStore.DeleteOutputs = []blobStoreUnstructuredTest.DeleteOutput{{Deleted: true, Error: nil}}
+									blobStructuredSession.DeleteOutputs = []blobStoreStructuredTest.DeleteOutput{{Deleted: true, Error: nil}}
+									blb, err := client.Create(ctx, userID, create)
+									errorsTest.ExpectEqual(err, responseErr)
+									Expect(blb).To(BeNil())
+									Expect(blobUnstructuredStore.DeleteInputs).To(Equal([]blobStoreUnstructuredTest.DeleteInput{{Context: ctx, UserID: userID, ID: *createBlob.ID}}))
+									Expect(blobStructuredSession.DeleteInputs).To(Equal([]blobStoreStructuredTest.DeleteInput{{Context: ctx, ID: *createBlob.ID}}))
+								})
+
+								It("returns an error and deletes the blob and content if the inspection rejects the content", func() {
+									responseErr := blob.ErrorMediaTypeNotMatched(*create.MediaType, "text/html")
+									inspection.FinishOutputs = []error{responseErr}
+									blobUnstructuredStore.DeleteOutputs = []blobStoreUnstructuredTest.DeleteOutput{{Deleted: true, Error: nil}}
+									blobStructuredSession.DeleteOutputs = []blobStoreStructuredTest.DeleteOutput{{Deleted: true, Error: nil}}
+									blb, err := client.Create(ctx, userID, create)
+									errorsTest.ExpectEqual(err, responseErr)
+									Expect(blb).To(BeNil())
+									Expect(bytes.Join(inspection.WriteInputs, nil)).To(Equal(body))
+									Expect(blobUnstructuredStore.DeleteInputs).To(Equal([]blobStoreUnstructuredTest.DeleteInput{{Context: ctx, UserID: userID, ID: *createBlob.ID}}))
+									Expect(blobStructuredSession.DeleteInputs).To(Equal([]blobStoreStructuredTest.DeleteInput{{Context: ctx, ID: *createBlob.ID}}))
+								})
+
+								It("returns an error and logs errors if the inspection rejects the content and the deletes return errors", func() {
+									responseErr := blob.ErrorMediaTypeNotMatched(*create.MediaType, "text/html")
+									deleteErr := errorsTest.NewError()
+									inspection.FinishOutputs = []error{responseErr}
+									blobUnstructuredStore.DeleteOutputs = []blobStoreUnstructuredTest.DeleteOutput{{Deleted: false, Error: deleteErr}}
+									blobStructuredSession.DeleteOutputs = []blobStoreStructuredTest.DeleteOutput{{Deleted: false, Error: deleteErr}}
+									blb, err := client.Create(ctx, userID, create)
+									errorsTest.ExpectEqual(err, responseErr)
+									Expect(blb).To(BeNil())
+									logger.AssertError("Unable to delete blob content after failure to put or inspect blob content", log.Fields{"userId": userID, "id": *createBlob.ID, "error": &errors.Serializable{Error: deleteErr}})
+									logger.AssertError("Unable to delete blob after failure to put or inspect blob content", log.Fields{"userId": userID, "id": *createBlob.ID, "error": &errors.Serializable{Error: deleteErr}})
+								})
+
+								It("returns an error and deletes the blob and content if the put returns an error and the inspection finds the content infected", func() {
+									responseErr := errorsTest.NewError()
+									blobUnstructuredStore.PutStub = func(ctx context.Context, userID string, id string, reader io.Reader, options *storeUnstructured.Options) error {
+										return responseErr
+									}
+									inspection.FinishOutputs = []error{blobInspect.ErrorContentInfected("Win.Test.EICAR_HDB-1")}
+									blobUnstructuredStore.DeleteOutputs = []blobStoreUnstructuredTest.DeleteOutput{{Deleted: true, Error: nil}}
+									blobStructuredSession.DeleteOutputs = []blobStoreStructuredTest.DeleteOutput{{Deleted: true, Error: nil}}
+									blb, err := client.Create(ctx, userID, create)
+									errorsTest.ExpectEqual(err, responseErr)
+									Expect(blb).To(BeNil())
+									Expect(blobUnstructuredStore.DeleteInputs).To(Equal([]blobStoreUnstructuredTest.DeleteInput{{Context: ctx, UserID: userID, ID: *createBlob.ID}}))
+									Expect(blobStructuredSession.DeleteInputs).To(Equal([]blobStoreStructuredTest.DeleteInput{{Context: ctx, ID: *createBlob.ID}}))
+								})
+
+								It("quarantines the blob if the inspection finds the content infected", func() {
+									inspection.FinishOutputs = []error{blobInspect.ErrorContentInfected("Win.Test.EICAR_HDB-1")}
+									updateBlob := blobTest.CloneBlob(createBlob)
+									updateBlob.Status = pointer.FromString(blob.StatusQuarantined)
+									blobStructuredSession.UpdateOutputs = []blobStoreStructuredTest.UpdateOutput{{Blob: updateBlob, Error: nil}}
+									Expect(client.Create(ctx, userID, create)).To(Equal(updateBlob))
+									update := blobStoreStructured.NewUpdate()
+									update.DigestMD5 = pointer.CloneString(create.DigestMD5)
+									update.Size = pointer.FromInt(len(body))
+									update.Status = pointer.FromString(blob.StatusQuarantined)
+									Expect(blobStructuredSession.UpdateInputs).To(Equal([]blobStoreStructuredTest.UpdateInput{{Context: ctx, ID: *createBlob.ID, Update: update}}))
+								})
+
+								It("returns successfully if the inspection accepts the content", func() {
+									inspection.FinishOutputs = []error{nil}
+									updateBlob := blobTest.CloneBlob(createBlob)
+									updateBlob.Status = pointer.FromString(blob.StatusAvailable)
+									blobStructuredSession.UpdateOutputs = []blobStoreStructuredTest.UpdateOutput{{Blob: updateBlob, Error: nil}}
+									Expect(client.Create(ctx, userID, create)).To(Equal(updateBlob))
+									Expect(bytes.Join(inspection.WriteInputs, nil)).To(Equal(body))
+									Expect(blobStructuredSession.UpdateInputs).To(HaveLen(1))
+									Expect(blobStructuredSession.UpdateInputs[0].Update.Status).To(Equal(pointer.FromString(blob.StatusAvailable)))
+								})
+							})
+						})
+
 						When("the blob structured session create returns successfully", func() {
 							var createBlob *blob.Blob
 
@@ -217,32 +406,36 @@ var _ = Describe("Client", func() {
 								Expect(blobUnstructuredStore.PutInputs[0].UserID).To(Equal(userID))
 								Expect(blobUnstructuredStore.PutInputs[0].ID).To(Equal(*createBlob.ID))
 								Expect(blobUnstructuredStore.PutInputs[0].Reader).ToNot(BeNil())
+								Expect(blobUnstructuredStore.PutInputs[0].Options).To(Equal(&storeUnstructured.Options{MediaType: create.MediaType}))
 							})
 
 							It("returns an error if the blob unstructured store put returns an error", func() {
 								responseErr := errorsTest.NewError()
 								blobUnstructuredStore.PutOutputs = []error{responseErr}
+								blobUnstructuredStore.DeleteOutputs = []blobStoreUnstructuredTest.DeleteOutput{{Deleted: true, Error: nil}}
 								blobStructuredSession.DeleteOutputs = []blobStoreStructuredTest.DeleteOutput{{Deleted: true, Error: nil}}
 								blb, err := client.Create(ctx, userID, create)
 								errorsTest.ExpectEqual(err, responseErr)
 								Expect(blb).To(BeNil())
+								Expect(blobUnstructuredStore.DeleteInputs).To(Equal([]blobStoreUnstructuredTest.DeleteInput{{Context: ctx, UserID: userID, ID: *createBlob.ID}}))
 							})
 
 							It("returns an error if the blob unstructured store put returns an error and logs an error if the blob structured session delete returns error", func() {
 								responseErr := errorsTest.NewError()
 								blobUnstructuredStore.PutOutputs = []error{responseErr}
+								blobUnstructuredStore.DeleteOutputs = []blobStoreUnstructuredTest.DeleteOutput{{Deleted: true, Error: nil}}
 								blobStructuredSession.DeleteOutputs = []blobStoreStructuredTest.DeleteOutput{{Deleted: true, Error: responseErr}}
 								blb, err := client.Create(ctx, userID, create)
 								errorsTest.ExpectEqual(err, responseErr)
 								Expect(blb).To(BeNil())
-								logger.AssertError("Unable to delete blob after failure to put blob content", log.Fields{"userId": userID, "id": *createBlob.ID, "error": &errors.Serializable{Error: responseErr}})
+								logger.AssertError("Unable to delete blob after failure to put or inspect blob content", log.Fields{"userId": userID, "id": *createBlob.ID, "error": &errors.Serializable{Error: responseErr}})
 							})
 
 							When("the blob unstructured store put returns successfully", func() {
 								var size int64
 
 								BeforeEach(func() {
-									blobUnstructuredStore.PutStub = func(ctx context.Context, userID string, id string, reader io.Reader) error {
+									blobUnstructuredStore.PutStub = func(ctx context.Context, userID string, id string, reader io.Reader, options *storeUnstructured.Options) error {
 										size, _ = io.Copy(ioutil.Discard, reader)
 										return nil
 									}
@@ -379,8 +572,25 @@ var _ = Describe("Client", func() {
 						Expect(blbs).To(BeNil())
 					})
 
+					It("returns successfully if the blob structured session get returns nil", func() {
+						blobStructuredSession.GetOutputs = []blobStoreStructuredTest.GetOutput{{Blob: nil, Error: nil}}
+						blbs, err := client.Get(ctx, id)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(blbs).To(BeNil())
+					})
+
+					It("returns successfully if the blob structured session get returns an expired blob", func() {
+						responseBlob := blobTest.RandomBlob()
+						responseBlob.ExpirationTime = pointer.FromTime(time.Now().Add(-time.Second))
+						blobStructuredSession.GetOutputs = []blobStoreStructuredTest.GetOutput{{Blob: responseBlob, Error: nil}}
+						blbs, err := client.Get(ctx, id)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(blbs).To(BeNil())
+					})
+
 					It("returns successfully if the blob structured session get returns successfully", func() {
 						responseBlob := blobTest.RandomBlob()
+						responseBlob.Status = pointer.FromString(blob.StatusAvailable)
 						blobStructuredSession.GetOutputs = []blobStoreStructuredTest.GetOutput{{Blob: responseBlob, Error: nil}}
 						blbs, err := client.Get(ctx, id)
 						Expect(err).ToNot(HaveOccurred())
@@ -426,12 +636,34 @@ var _ = Describe("Client", func() {
 						Expect(content).To(BeNil())
 					})
 
+					It("returns successfully if the blob structured session get returns an expired blob", func() {
+						blb := blobTest.RandomBlob()
+						blb.ID = pointer.FromString(id)
+						blb.Status = pointer.FromString(blob.StatusCreated)
+						blb.CreatedTime = pointer.FromTime(time.Now().Add(-2 * blob.StatusCreatedTimeout))
+						blobStructuredSession.GetOutputs = []blobStoreStructuredTest.GetOutput{{Blob: blb, Error: nil}}
+						content, err := client.GetContent(ctx, id)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(content).To(BeNil())
+					})
+
+					It("returns successfully if the blob structured session get returns a quarantined blob", func() {
+						blb := blobTest.RandomBlob()
+						blb.ID = pointer.FromString(id)
+						blb.Status = pointer.FromString(blob.StatusQuarantined)
+						blobStructuredSession.GetOutputs = []blobStoreStructuredTest.GetOutput{{Blob: blb, Error: nil}}
+						content, err := client.GetContent(ctx, id)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(content).To(BeNil())
+					})
+
 					When("the blob structure session get returns a blob", func() {
 						var blb *blob.Blob
 
 						BeforeEach(func() {
 							blb = blobTest.RandomBlob()
 							blb.ID = pointer.FromString(id)
+							blb.Status = pointer.FromString(blob.StatusAvailable)
 							blobStructuredSession.GetOutputs = []blobStoreStructuredTest.GetOutput{{Blob: blb, Error: nil}}
 						})
 
@@ -554,6 +786,7 @@ var _ = Describe("Client", func() {
 							})
 
 							It("logs a warning if the unstructured store returns false", func() {
+								blb.Status = pointer.FromString(blob.StatusAvailable)
 								blobUnstructuredStore.DeleteOutputs = []blobStoreUnstructuredTest.DeleteOutput{{Deleted: false, Error: nil}}
 								blobStructuredSession.DeleteOutputs = []blobStoreStructuredTest.DeleteOutput{{Deleted: true, Error: nil}}
 								deleted, err := client.Delete(ctx, id)
@@ -561,6 +794,312 @@ var _ = Describe("Client", func() {
 								Expect(deleted).To(BeTrue())
 								logger.AssertError("Deleting blob with no content", log.Fields{"id": id})
 							})
+
+							It("does not log a warning if the unstructured store returns false and the blob status is created", func() {
+								blb.Status = pointer.FromString(blob.StatusCreated)
+								blobUnstructuredStore.DeleteOutputs = []blobStoreUnstructuredTest.DeleteOutput{{Deleted: false, Error: nil}}
+								blobStructuredSession.DeleteOutputs = []blobStoreStructuredTest.DeleteOutput{{Deleted: true, Error: nil}}
+								deleted, err := client.Delete(ctx, id)
+								Expect(err).ToNot(HaveOccurred())
+								Expect(deleted).To(BeTrue())
+								Expect(logger.SerializedFields).To(BeEmpty())
+							})
+						})
+					})
+				})
+			})
+
+			Context("with linker", func() {
+				var linker *blobService.Linker
+
+				BeforeEach(func() {
+					cfg := blobService.NewLinkerConfig()
+					cfg.Address = testHttp.NewAddress()
+					cfg.Secret = authTest.NewServiceSecret()
+					var err error
+					linker, err = blobService.NewLinker(cfg)
+					Expect(err).ToNot(HaveOccurred())
+					clientProvider.BlobLinkerOutput = &linker
+				})
+
+				Context("CreateLink", func() {
+					var create *blob.LinkCreate
+
+					BeforeEach(func() {
+						create = blobTest.RandomLinkCreate()
+					})
+
+					AfterEach(func() {
+						Expect(userClient.EnsureAuthorizedServiceInputs).To(Equal([]context.Context{ctx}))
+					})
+
+					It("returns an error if the user client ensure authorized service returns an error", func() {
+						responseErr := errorsTest.NewError()
+						userClient.EnsureAuthorizedServiceOutputs = []error{responseErr}
+						link, err := client.CreateLink(ctx, id, create)
+						errorsTest.ExpectEqual(err, responseErr)
+						Expect(link).To(BeNil())
+					})
+
+					When("user client ensure authorized service returns successfully", func() {
+						BeforeEach(func() {
+							userClient.EnsureAuthorizedServiceOutputs = []error{nil}
+						})
+
+						It("returns an error if the create is invalid", func() {
+							create.ExpirationTime = pointer.FromTime(time.Now().Add(-time.Hour))
+							link, err := client.CreateLink(ctx, id, create)
+							Expect(err).To(HaveOccurred())
+							Expect(link).To(BeNil())
+						})
+
+						When("the create is valid", func() {
+							AfterEach(func() {
+								Expect(blobStructuredSession.GetInputs).To(Equal([]blobStoreStructuredTest.GetInput{{Context: ctx, ID: id}}))
+							})
+
+							It("returns an error if the blob structured session get returns an error", func() {
+								responseErr := errorsTest.NewError()
+								blobStructuredSession.GetOutputs = []blobStoreStructuredTest.GetOutput{{Blob: nil, Error: responseErr}}
+								link, err := client.CreateLink(ctx, id, create)
+								errorsTest.ExpectEqual(err, responseErr)
+								Expect(link).To(BeNil())
+							})
+
+							It("returns successfully if the blob structured session get returns nil", func() {
+								blobStructuredSession.GetOutputs = []blobStoreStructuredTest.GetOutput{{Blob: nil, Error: nil}}
+								link, err := client.CreateLink(ctx, id, create)
+								Expect(err).ToNot(HaveOccurred())
+								Expect(link).To(BeNil())
+							})
+
+							It("returns successfully if the blob structured session get returns an expired blob", func() {
+								blb := blobTest.RandomBlob()
+								blb.ID = pointer.FromString(id)
+								blb.Status = pointer.FromString(blob.StatusAvailable)
+								blb.ExpirationTime = pointer.FromTime(time.Now().Add(-time.Minute))
+								blobStructuredSession.GetOutputs = []blobStoreStructuredTest.GetOutput{{Blob: blb, Error: nil}}
+								link, err := client.CreateLink(ctx, id, create)
+								Expect(err).ToNot(HaveOccurred())
+								Expect(link).To(BeNil())
+							})
+
+							It("returns an error if the blob structured session get returns a blob that is not available", func() {
+								blb := blobTest.RandomBlob()
+								blb.ID = pointer.FromString(id)
+								blb.Status = pointer.FromString(blob.StatusCreated)
+								blb.CreatedTime = pointer.FromTime(time.Now().Truncate(time.Second))
+								blb.ExpirationTime = nil
+								blobStructuredSession.GetOutputs = []blobStoreStructuredTest.GetOutput{{Blob: blb, Error: nil}}
+								link, err := client.CreateLink(ctx, id, create)
+								errorsTest.ExpectEqual(err, blob.ErrorStatusNotAvailable(blob.StatusCreated))
+								Expect(link).To(BeNil())
+							})
+
+							When("the blob structure session get returns a blob", func() {
+								var blb *blob.Blob
+
+								BeforeEach(func() {
+									blb = blobTest.RandomBlob()
+									blb.ID = pointer.FromString(id)
+									blb.Status = pointer.FromString(blob.StatusAvailable)
+									blobStructuredSession.GetOutputs = []blobStoreStructuredTest.GetOutput{{Blob: blb, Error: nil}}
+								})
+
+								It("returns a signed link with the default expiration time if the create is missing", func() {
+									link, err := client.CreateLink(ctx, id, nil)
+									Expect(err).ToNot(HaveOccurred())
+									Expect(link).ToNot(BeNil())
+									Expect(*link.ExpirationTime).To(BeTemporally("~", time.Now().Add(blob.LinkExpirationDurationDefault), 2*time.Second))
+									Expect(link.SingleUse).To(Equal(pointer.FromBool(false)))
+									Expect(*link.URL).To(ContainSubstring("/v1/blobs/" + id + "/links/content?"))
+								})
+
+								It("returns a signed link that verifies", func() {
+									link, err := client.CreateLink(ctx, id, create)
+									Expect(err).ToNot(HaveOccurred())
+									Expect(link).ToNot(BeNil())
+									Expect(link.ExpirationTime).To(Equal(create.ExpirationTime))
+									Expect(link.SingleUse).To(Equal(create.SingleUse))
+									parsed, err := url.Parse(*link.URL)
+									Expect(err).ToNot(HaveOccurred())
+									query := blob.NewLinkQuery()
+									Expect(request.DecodeRequestQuery(&http.Request{URL: parsed}, query)).To(Succeed())
+									Expect(linker.Verify(id, query)).To(BeTrue())
+								})
+
+								It("returns a link limited to the blob expiration time", func() {
+									blb.ExpirationTime = pointer.FromTime(time.Now().Add(30 * time.Second).Truncate(time.Second))
+									link, err := client.CreateLink(ctx, id, create)
+									Expect(err).ToNot(HaveOccurred())
+									Expect(link).ToNot(BeNil())
+									Expect(link.ExpirationTime).To(Equal(blb.ExpirationTime))
+								})
+
+								When("the linker presigns", func() {
+									BeforeEach(func() {
+										cfg := blobService.NewLinkerConfig()
+										cfg.Address = testHttp.NewAddress()
+										cfg.Secret = authTest.NewServiceSecret()
+										cfg.Presign = true
+										var err error
+										linker, err = blobService.NewLinker(cfg)
+										Expect(err).ToNot(HaveOccurred())
+										create.SingleUse = pointer.FromBool(false)
+									})
+
+									AfterEach(func() {
+										Expect(blobUnstructuredStore.PresignGetInputs).To(Equal([]blobStoreUnstructuredTest.PresignGetInput{{Context: ctx, UserID: *blb.UserID, ID: id, ExpirationTime: *create.ExpirationTime}}))
+									})
+
+									It("returns an error if the blob unstructured store presign get returns an error", func() {
+										responseErr := errorsTest.NewError()
+										blobUnstructuredStore.PresignGetOutputs = []blobStoreUnstructuredTest.PresignGetOutput{{URL: nil, Error: responseErr}}
+										link, err := client.CreateLink(ctx, id, create)
+										errorsTest.ExpectEqual(err, responseErr)
+										Expect(link).To(BeNil())
+									})
+
+									It("returns a signed link if the blob unstructured store does not presign", func() {
+										blobUnstructuredStore.PresignGetOutputs = []blobStoreUnstructuredTest.PresignGetOutput{{URL: nil, Error: nil}}
+										link, err := client.CreateLink(ctx, id, create)
+										Expect(err).ToNot(HaveOccurred())
+										Expect(link).ToNot(BeNil())
+										Expect(*link.URL).To(ContainSubstring("/v1/blobs/" + id + "/links/content?"))
+									})
+
+									It("returns a presigned link if the blob unstructured store presigns", func() {
+										presignedURL := testHttp.NewAddress()
+										blobUnstructuredStore.PresignGetOutputs = []blobStoreUnstructuredTest.PresignGetOutput{{URL: pointer.FromString(presignedURL), Error: nil}}
+										link, err := client.CreateLink(ctx, id, create)
+										Expect(err).ToNot(HaveOccurred())
+										Expect(link).To(Equal(&blob.Link{
+											URL:            pointer.FromString(presignedURL),
+											ExpirationTime: create.ExpirationTime,
+											SingleUse:      pointer.FromBool(false),
+										}))
+									})
+								})
+							})
+						})
+					})
+				})
+
+				Context("GetLinkContent", func() {
+					var query *blob.LinkQuery
+
+					BeforeEach(func() {
+						query = linker.NewQuery(id, time.Now().Add(time.Hour), false)
+					})
+
+					It("returns an error if the query is missing", func() {
+						content, err := client.GetLinkContent(ctx, id, nil)
+						errorsTest.ExpectEqual(err, errors.New("query is missing"))
+						Expect(content).To(BeNil())
+					})
+
+					It("returns an error if the query is invalid", func() {
+						query.Nonce = nil
+						content, err := client.GetLinkContent(ctx, id, query)
+						Expect(err).To(HaveOccurred())
+						Expect(content).To(BeNil())
+					})
+
+					It("returns an error if the signature does not verify", func() {
+						query.Signature = pointer.FromString(*linker.NewQuery(id, time.Now().Add(time.Hour), false).Signature)
+						content, err := client.GetLinkContent(ctx, id, query)
+						errorsTest.ExpectEqual(err, request.ErrorUnauthorized())
+						Expect(content).To(BeNil())
+					})
+
+					It("returns an error if the link is expired", func() {
+						query = linker.NewQuery(id, time.Now().Add(-time.Minute), false)
+						content, err := client.GetLinkContent(ctx, id, query)
+						errorsTest.ExpectEqual(err, request.ErrorUnauthorized())
+						Expect(content).To(BeNil())
+					})
+
+					When("the query verifies", func() {
+						AfterEach(func() {
+							Expect(blobStructuredSession.GetInputs).To(Equal([]blobStoreStructuredTest.GetInput{{Context: ctx, ID: id}}))
+						})
+
+						It("returns an error if the blob structured session get returns an error", func() {
+							responseErr := errorsTest.NewError()
+							blobStructuredSession.GetOutputs = []blobStoreStructuredTest.GetOutput{{Blob: nil, Error: responseErr}}
+							content, err := client.GetLinkContent(ctx, id, query)
+							errorsTest.ExpectEqual(err, responseErr)
+							Expect(content).To(BeNil())
+						})
+
+						It("returns successfully if the blob structured session get returns nil", func() {
+							blobStructuredSession.GetOutputs = []blobStoreStructuredTest.GetOutput{{Blob: nil, Error: nil}}
+							content, err := client.GetLinkContent(ctx, id, query)
+							Expect(err).ToNot(HaveOccurred())
+							Expect(content).To(BeNil())
+						})
+
+						When("the blob structure session get returns a blob", func() {
+							var blb *blob.Blob
+							var body []byte
+							var reader io.ReadCloser
+
+							BeforeEach(func() {
+								blb = blobTest.RandomBlob()
+								blb.ID = pointer.FromString(id)
+								blb.Status = pointer.FromString(blob.StatusAvailable)
+								blobStructuredSession.GetOutputs = []blobStoreStructuredTest.GetOutput{{Blob: blb, Error: nil}}
+								body = test.RandomBytes()
+								reader = ioutil.NopCloser(bytes.NewReader(body))
+							})
+
+							It("returns successfully if the link is not single use", func() {
+								blobUnstructuredStore.GetOutputs = []blobStoreUnstructuredTest.GetOutput{{Reader: reader, Error: nil}}
+								content, err := client.GetLinkContent(ctx, id, query)
+								Expect(err).ToNot(HaveOccurred())
+								Expect(content).To(Equal(&blob.Content{
+									Body:      reader,
+									DigestMD5: blb.DigestMD5,
+									MediaType: blb.MediaType,
+									Size:      blb.Size,
+								}))
+								Expect(blobStructuredSession.UseLinkNonceInputs).To(BeEmpty())
+								Expect(blobUnstructuredStore.GetInputs).To(Equal([]blobStoreUnstructuredTest.GetInput{{Context: ctx, UserID: *blb.UserID, ID: id}}))
+							})
+
+							When("the link is single use", func() {
+								BeforeEach(func() {
+									query = linker.NewQuery(id, time.Now().Add(time.Hour), true)
+								})
+
+								AfterEach(func() {
+									Expect(blobStructuredSession.UseLinkNonceInputs).To(Equal([]blobStoreStructuredTest.UseLinkNonceInput{{Context: ctx, ID: id, Nonce: *query.Nonce, ExpirationTime: *query.ExpirationTime}}))
+								})
+
+								It("returns an error if the blob structured session use link nonce returns an error", func() {
+									responseErr := errorsTest.NewError()
+									blobStructuredSession.UseLinkNonceOutputs = []blobStoreStructuredTest.UseLinkNonceOutput{{Used: false, Error: responseErr}}
+									content, err := client.GetLinkContent(ctx, id, query)
+									errorsTest.ExpectEqual(err, responseErr)
+									Expect(content).To(BeNil())
+								})
+
+								It("returns an error if the link nonce was already used", func() {
+									blobStructuredSession.UseLinkNonceOutputs = []blobStoreStructuredTest.UseLinkNonceOutput{{Used: false, Error: nil}}
+									content, err := client.GetLinkContent(ctx, id, query)
+									errorsTest.ExpectEqual(err, request.ErrorUnauthorized())
+									Expect(content).To(BeNil())
+								})
+
+								It("returns successfully if the link nonce is used", func() {
+									blobStructuredSession.UseLinkNonceOutputs = []blobStoreStructuredTest.UseLinkNonceOutput{{Used: true, Error: nil}}
+									blobUnstructuredStore.GetOutputs = []blobStoreUnstructuredTest.GetOutput{{Reader: reader, Error: nil}}
+									content, err := client.GetLinkContent(ctx, id, query)
+									Expect(err).ToNot(HaveOccurred())
+									Expect(content).ToNot(BeNil())
+									Expect(content.Body).To(Equal(reader))
+								})
+							})
 						})
 					})
 				})
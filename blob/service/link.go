@@ -0,0 +1,117 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidepool-org/platform/blob"
+	"github.com/tidepool-org/platform/config"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/id"
+	"github.com/tidepool-org/platform/pointer"
+)
+
+type LinkerConfig struct {
+	Address string
+	Secret  string
+	Presign bool
+}
+
+func NewLinkerConfig() *LinkerConfig {
+	return &LinkerConfig{}
+}
+
+func (l *LinkerConfig) Load(configReporter config.Reporter) error {
+	if configReporter == nil {
+		return errors.New("config reporter is missing")
+	}
+
+	l.Address = configReporter.GetWithDefault("address", l.Address)
+	l.Secret = configReporter.GetWithDefault("secret", l.Secret)
+	if presignString, err := configReporter.Get("presign"); err == nil {
+		presign, err := strconv.ParseBool(presignString)
+		if err != nil {
+			return errors.New("presign is invalid")
+		}
+		l.Presign = presign
+	}
+
+	return nil
+}
+
+func (l *LinkerConfig) Validate() error {
+	if l.Address == "" {
+		return errors.New("address is missing")
+	} else if _, err := url.Parse(l.Address); err != nil {
+		return errors.New("address is invalid")
+	}
+	if l.Secret == "" {
+		return errors.New("secret is missing")
+	}
+
+	return nil
+}
+
+type Linker struct {
+	address string
+	secret  []byte
+	presign bool
+}
+
+func NewLinker(cfg *LinkerConfig) (*Linker, error) {
+	if cfg == nil {
+		return nil, errors.New("config is missing")
+	} else if err := cfg.Validate(); err != nil {
+		return nil, errors.Wrap(err, "config is invalid")
+	}
+
+	return &Linker{
+		address: strings.TrimRight(cfg.Address, "/"),
+		secret:  []byte(cfg.Secret),
+		presign: cfg.Presign,
+	}, nil
+}
+
+func (l *Linker) Presign() bool {
+	return l.presign
+}
+
+func (l *Linker) NewQuery(id string, expirationTime time.Time, singleUse bool) *blob.LinkQuery {
+	query := blob.NewLinkQuery()
+	query.ExpirationTime = pointer.FromTime(expirationTime.Truncate(time.Second))
+	query.SingleUse = pointer.FromBool(singleUse)
+	query.Nonce = pointer.FromString(NewLinkNonce())
+	query.Signature = pointer.FromString(l.sign(id, query))
+	return query
+}
+
+func (l *Linker) URL(id string, query *blob.LinkQuery) string {
+	values := url.Values{}
+	values.Set("expirationTime", query.ExpirationTime.Format(time.RFC3339))
+	values.Set("singleUse", strconv.FormatBool(*query.SingleUse))
+	values.Set("nonce", *query.Nonce)
+	values.Set("signature", *query.Signature)
+	return l.address + "/v1/blobs/" + url.PathEscape(id) + "/links/content?" + values.Encode()
+}
+
+func (l *Linker) Verify(id string, query *blob.LinkQuery) bool {
+	if query == nil || query.ExpirationTime == nil || query.SingleUse == nil || query.Nonce == nil || query.Signature == nil {
+		return false
+	}
+	return hmac.Equal([]byte(*query.Signature), []byte(l.sign(id, query)))
+}
+
+func (l *Linker) sign(id string, query *blob.LinkQuery) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(strings.Join([]string{id, query.ExpirationTime.UTC().Format(time.RFC3339), strconv.FormatBool(*query.SingleUse), *query.Nonce}, "\n")))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func NewLinkNonce() string {
+	return id.Must(id.New(16))
+}
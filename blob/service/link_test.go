@@ -0,0 +1,223 @@
+package service_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"net/url"
+	"time"
+
+	authTest "github.com/tidepool-org/platform/auth/test"
+	"github.com/tidepool-org/platform/blob"
+	blobService "github.com/tidepool-org/platform/blob/service"
+	configTest "github.com/tidepool-org/platform/config/test"
+	"github.com/tidepool-org/platform/pointer"
+	testHttp "github.com/tidepool-org/platform/test/http"
+)
+
+var _ = Describe("Link", func() {
+	Context("NewLinkerConfig", func() {
+		It("returns successfully with default values", func() {
+			cfg := blobService.NewLinkerConfig()
+			Expect(cfg).ToNot(BeNil())
+			Expect(cfg.Address).To(BeEmpty())
+			Expect(cfg.Secret).To(BeEmpty())
+			Expect(cfg.Presign).To(BeFalse())
+		})
+	})
+
+	Context("with new linker config", func() {
+		var address string
+		var secret string
+		var cfg *blobService.LinkerConfig
+
+		BeforeEach(func() {
+			address = testHttp.NewAddress()
+			secret = authTest.NewServiceSecret()
+			cfg = blobService.NewLinkerConfig()
+			Expect(cfg).ToNot(BeNil())
+		})
+
+		Context("Load", func() {
+			var configReporter *configTest.Reporter
+
+			BeforeEach(func() {
+				configReporter = configTest.NewReporter()
+				configReporter.Config["address"] = address
+				configReporter.Config["secret"] = secret
+				configReporter.Config["presign"] = "true"
+			})
+
+			It("returns an error if the config reporter is missing", func() {
+				Expect(cfg.Load(nil)).To(MatchError("config reporter is missing"))
+			})
+
+			It("returns an error if the presign is invalid", func() {
+				configReporter.Config["presign"] = "invalid"
+				Expect(cfg.Load(configReporter)).To(MatchError("presign is invalid"))
+			})
+
+			It("returns successfully and does not set the presign", func() {
+				delete(configReporter.Config, "presign")
+				Expect(cfg.Load(configReporter)).To(Succeed())
+				Expect(cfg.Address).To(Equal(address))
+				Expect(cfg.Secret).To(Equal(secret))
+				Expect(cfg.Presign).To(BeFalse())
+			})
+
+			It("returns successfully", func() {
+				Expect(cfg.Load(configReporter)).To(Succeed())
+				Expect(cfg.Address).To(Equal(address))
+				Expect(cfg.Secret).To(Equal(secret))
+				Expect(cfg.Presign).To(BeTrue())
+			})
+		})
+
+		Context("Validate", func() {
+			BeforeEach(func() {
+				cfg.Address = address
+				cfg.Secret = secret
+			})
+
+			It("returns an error if the address is missing", func() {
+				cfg.Address = ""
+				Expect(cfg.Validate()).To(MatchError("address is missing"))
+			})
+
+			It("returns an error if the address is invalid", func() {
+				cfg.Address = ":::"
+				Expect(cfg.Validate()).To(MatchError("address is invalid"))
+			})
+
+			It("returns an error if the secret is missing", func() {
+				cfg.Secret = ""
+				Expect(cfg.Validate()).To(MatchError("secret is missing"))
+			})
+
+			It("returns successfully", func() {
+				Expect(cfg.Validate()).To(Succeed())
+			})
+		})
+
+		Context("NewLinker", func() {
+			BeforeEach(func() {
+				cfg.Address = address
+				cfg.Secret = secret
+			})
+
+			It("returns an error if the config is missing", func() {
+				linker, err := blobService.NewLinker(nil)
+				Expect(err).To(MatchError("config is missing"))
+				Expect(linker).To(BeNil())
+			})
+
+			It("returns an error if the config is invalid", func() {
+				cfg.Secret = ""
+				linker, err := blobService.NewLinker(cfg)
+				Expect(err).To(MatchError("config is invalid; secret is missing"))
+				Expect(linker).To(BeNil())
+			})
+
+			It("returns successfully", func() {
+				Expect(blobService.NewLinker(cfg)).ToNot(BeNil())
+			})
+		})
+
+		Context("with new linker", func() {
+			var id string
+			var expirationTime time.Time
+			var linker *blobService.Linker
+
+			BeforeEach(func() {
+				cfg.Address = address
+				cfg.Secret = secret
+				cfg.Presign = true
+				id = blob.NewID()
+				expirationTime = time.Now().Add(time.Hour)
+				var err error
+				linker, err = blobService.NewLinker(cfg)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(linker).ToNot(BeNil())
+			})
+
+			Context("Presign", func() {
+				It("returns the configured presign", func() {
+					Expect(linker.Presign()).To(BeTrue())
+				})
+			})
+
+			Context("NewQuery", func() {
+				It("returns a query with a unique nonce", func() {
+					query := linker.NewQuery(id, expirationTime, true)
+					Expect(query).ToNot(BeNil())
+					Expect(query.ExpirationTime).To(Equal(pointer.FromTime(expirationTime.Truncate(time.Second))))
+					Expect(query.SingleUse).To(Equal(pointer.FromBool(true)))
+					Expect(query.Nonce).ToNot(BeNil())
+					Expect(query.Signature).ToNot(BeNil())
+					Expect(linker.NewQuery(id, expirationTime, true).Nonce).ToNot(Equal(query.Nonce))
+				})
+			})
+
+			Context("URL", func() {
+				It("returns the url with the query parameters", func() {
+					query := linker.NewQuery(id, expirationTime, false)
+					parsed, err := url.Parse(linker.URL(id, query))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(parsed.Path).To(Equal("/v1/blobs/" + id + "/links/content"))
+					values := parsed.Query()
+					Expect(values.Get("expirationTime")).To(Equal(query.ExpirationTime.Format(time.RFC3339)))
+					Expect(values.Get("singleUse")).To(Equal("false"))
+					Expect(values.Get("nonce")).To(Equal(*query.Nonce))
+					Expect(values.Get("signature")).To(Equal(*query.Signature))
+				})
+			})
+
+			Context("Verify", func() {
+				var query *blob.LinkQuery
+
+				BeforeEach(func() {
+					query = linker.NewQuery(id, expirationTime, true)
+				})
+
+				It("returns false if the query is missing", func() {
+					Expect(linker.Verify(id, nil)).To(BeFalse())
+				})
+
+				It("returns false if the signature is missing", func() {
+					query.Signature = nil
+					Expect(linker.Verify(id, query)).To(BeFalse())
+				})
+
+				It("returns false if the id does not match", func() {
+					Expect(linker.Verify(blob.NewID(), query)).To(BeFalse())
+				})
+
+				It("returns false if the expiration time does not match", func() {
+					query.ExpirationTime = pointer.FromTime(query.ExpirationTime.Add(time.Second))
+					Expect(linker.Verify(id, query)).To(BeFalse())
+				})
+
+				It("returns false if the single use does not match", func() {
+					query.SingleUse = pointer.FromBool(false)
+					Expect(linker.Verify(id, query)).To(BeFalse())
+				})
+
+				It("returns false if the nonce does not match", func() {
+					query.Nonce = pointer.FromString(blobService.NewLinkNonce())
+					Expect(linker.Verify(id, query)).To(BeFalse())
+				})
+
+				It("returns false if signed with a different secret", func() {
+					cfg.Secret = authTest.NewServiceSecret()
+					otherLinker, err := blobService.NewLinker(cfg)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(otherLinker.Verify(id, query)).To(BeFalse())
+				})
+
+				It("returns true if the query matches", func() {
+					Expect(linker.Verify(id, query)).To(BeTrue())
+				})
+			})
+		})
+	})
+})
@@ -7,6 +7,8 @@ import (
 	"github.com/tidepool-org/platform/application"
 	awsApi "github.com/tidepool-org/platform/aws/api"
 	"github.com/tidepool-org/platform/blob"
+	blobInspect "github.com/tidepool-org/platform/blob/inspect"
+	blobInspectFactory "github.com/tidepool-org/platform/blob/inspect/factory"
 	blobServiceApiV1 "github.com/tidepool-org/platform/blob/service/api/v1"
 	blobStoreStructured "github.com/tidepool-org/platform/blob/store/structured"
 	blobStoreStructuredMongo "github.com/tidepool-org/platform/blob/store/structured/mongo"
@@ -25,6 +27,8 @@ type Service struct {
 	*serviceService.Authenticated
 	blobStructuredStore   *blobStoreStructuredMongo.Store
 	blobUnstructuredStore *blobStoreUnstructured.StoreImpl
+	blobLinker            *Linker
+	blobInspector         blobInspect.Inspector
 	userClient            *userClient.Client
 	blobClient            *Client
 }
@@ -46,6 +50,12 @@ func (s *Service) Initialize(provider application.Provider) error {
 	if err := s.initializeBlobUnstructuredStore(); err != nil {
 		return err
 	}
+	if err := s.initializeBlobLinker(); err != nil {
+		return err
+	}
+	if err := s.initializeBlobInspector(); err != nil {
+		return err
+	}
 	if err := s.initializeUserClient(); err != nil {
 		return err
 	}
@@ -59,6 +69,8 @@ func (s *Service) Terminate() {
 	s.terminateRouter()
 	s.terminateBlobClient()
 	s.terminateUserClient()
+	s.terminateBlobInspector()
+	s.terminateBlobLinker()
 	s.terminateBlobUnstructuredStore()
 	s.terminateBlobStructuredStore()
 
@@ -81,6 +93,14 @@ func (s *Service) BlobUnstructuredStore() blobStoreUnstructured.Store {
 	return s.blobUnstructuredStore
 }
 
+func (s *Service) BlobLinker() *Linker {
+	return s.blobLinker
+}
+
+func (s *Service) BlobInspector() blobInspect.Inspector {
+	return s.blobInspector
+}
+
 func (s *Service) UserClient() user.Client {
 	return s.userClient
 }
@@ -158,6 +178,51 @@ func (s *Service) terminateBlobUnstructuredStore() {
 	}
 }
 
+func (s *Service) initializeBlobLinker() error {
+	s.Logger().Debug("Loading blob linker config")
+
+	config := NewLinkerConfig()
+	if err := config.Load(s.ConfigReporter().WithScopes("link")); err != nil {
+		return errors.Wrap(err, "unable to load blob linker config")
+	}
+
+	s.Logger().Debug("Creating blob linker")
+
+	linker, err := NewLinker(config)
+	if err != nil {
+		return errors.Wrap(err, "unable to create blob linker")
+	}
+	s.blobLinker = linker
+
+	return nil
+}
+
+func (s *Service) terminateBlobLinker() {
+	if s.blobLinker != nil {
+		s.Logger().Debug("Destroying blob linker")
+		s.blobLinker = nil
+	}
+}
+
+func (s *Service) initializeBlobInspector() error {
+	s.Logger().Debug("Creating blob inspector")
+
+	inspector, err := blobInspectFactory.NewInspector(s.ConfigReporter().WithScopes("inspect"))
+	if err != nil {
+		return errors.Wrap(err, "unable to create blob inspector")
+	}
+	s.blobInspector = inspector
+
+	return nil
+}
+
+func (s *Service) terminateBlobInspector() {
+	if s.blobInspector != nil {
+		s.Logger().Debug("Destroying blob inspector")
+		s.blobInspector = nil
+	}
+}
+
 func (s *Service) initializeUserClient() error {
 	s.Logger().Debug("Loading user client config")
 
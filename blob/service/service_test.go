@@ -33,6 +33,8 @@ var _ = Describe("Service", func() {
 		var authClientConfig map[string]interface{}
 		var blobStructuredStoreConfig map[string]interface{}
 		var blobUnstructuredStoreConfig map[string]interface{}
+		var blobLinkerConfig map[string]interface{}
+		var blobInspectorConfig map[string]interface{}
 		var userClientConfig map[string]interface{}
 		var blobServiceConfig map[string]interface{}
 		var service *blobService.Service
@@ -72,6 +74,13 @@ var _ = Describe("Service", func() {
 					"prefix": test.RandomStringFromRangeAndCharset(4, 8, test.CharsetLowercase),
 				},
 			}
+			blobLinkerConfig = map[string]interface{}{
+				"address": testHttp.NewAddress(),
+				"secret":  authTest.NewServiceSecret(),
+			}
+			blobInspectorConfig = map[string]interface{}{
+				"scanner_type": "local",
+			}
 			userClientConfig = map[string]interface{}{
 				"address": server.URL(),
 			}
@@ -85,7 +94,9 @@ var _ = Describe("Service", func() {
 				"unstructured": map[string]interface{}{
 					"store": blobUnstructuredStoreConfig,
 				},
-				"secret": authTest.NewServiceSecret(),
+				"inspect": blobInspectorConfig,
+				"link":    blobLinkerConfig,
+				"secret":  authTest.NewServiceSecret(),
 				"server": map[string]interface{}{
 					"address": testHttp.NewAddress(),
 					"tls":     "false",
@@ -137,6 +148,21 @@ var _ = Describe("Service", func() {
 					errorsTest.ExpectEqual(service.Initialize(provider), errors.New("unable to create unstructured store"))
 				})
 
+				It("returns an error when the blob linker config load returns an error", func() {
+					blobLinkerConfig["presign"] = "invalid"
+					errorsTest.ExpectEqual(service.Initialize(provider), errors.New("unable to load blob linker config"))
+				})
+
+				It("returns an error when the blob linker returns an error", func() {
+					blobLinkerConfig["secret"] = ""
+					errorsTest.ExpectEqual(service.Initialize(provider), errors.New("unable to create blob linker"))
+				})
+
+				It("returns an error when the blob inspector returns an error", func() {
+					blobInspectorConfig["scanner_type"] = "invalid"
+					errorsTest.ExpectEqual(service.Initialize(provider), errors.New("unable to create blob inspector"))
+				})
+
 				It("returns an error when the user client returns an error", func() {
 					userClientConfig["address"] = ""
 					errorsTest.ExpectEqual(service.Initialize(provider), errors.New("unable to create user client"))
@@ -176,6 +202,18 @@ var _ = Describe("Service", func() {
 					})
 				})
 
+				Context("BlobLinker", func() {
+					It("returns successfully", func() {
+						Expect(service.BlobLinker()).ToNot(BeNil())
+					})
+				})
+
+				Context("BlobInspector", func() {
+					It("returns successfully", func() {
+						Expect(service.BlobInspector()).ToNot(BeNil())
+					})
+				})
+
 				Context("UserClient", func() {
 					It("returns successfully", func() {
 						Expect(service.UserClient()).ToNot(BeNil())
@@ -1,6 +1,8 @@
 package test
 
 import (
+	blobInspect "github.com/tidepool-org/platform/blob/inspect"
+	blobService "github.com/tidepool-org/platform/blob/service"
 	blobStoreStructured "github.com/tidepool-org/platform/blob/store/structured"
 	blobStoreUnstructured "github.com/tidepool-org/platform/blob/store/unstructured"
 	"github.com/tidepool-org/platform/user"
@@ -15,6 +17,14 @@ type ClientProvider struct {
 	BlobUnstructuredStoreStub        func() blobStoreUnstructured.Store
 	BlobUnstructuredStoreOutputs     []blobStoreUnstructured.Store
 	BlobUnstructuredStoreOutput      *blobStoreUnstructured.Store
+	BlobLinkerInvocations            int
+	BlobLinkerStub                   func() *blobService.Linker
+	BlobLinkerOutputs                []*blobService.Linker
+	BlobLinkerOutput                 **blobService.Linker
+	BlobInspectorInvocations         int
+	BlobInspectorStub                func() blobInspect.Inspector
+	BlobInspectorOutputs             []blobInspect.Inspector
+	BlobInspectorOutput              *blobInspect.Inspector
 	UserClientInvocations            int
 	UserClientStub                   func() user.Client
 	UserClientOutputs                []user.Client
@@ -57,6 +67,38 @@ func (c *ClientProvider) BlobUnstructuredStore() blobStoreUnstructured.Store {
 	panic("BlobUnstructuredStore has no output")
 }
 
+func (c *ClientProvider) BlobLinker() *blobService.Linker {
+	c.BlobLinkerInvocations++
+	if c.BlobLinkerStub != nil {
+		return c.BlobLinkerStub()
+	}
+	if len(c.BlobLinkerOutputs) > 0 {
+		output := c.BlobLinkerOutputs[0]
+		c.BlobLinkerOutputs = c.BlobLinkerOutputs[1:]
+		return output
+	}
+	if c.BlobLinkerOutput != nil {
+		return *c.BlobLinkerOutput
+	}
+	panic("BlobLinker has no output")
+}
+
+func (c *ClientProvider) BlobInspector() blobInspect.Inspector {
+	c.BlobInspectorInvocations++
+	if c.BlobInspectorStub != nil {
+		return c.BlobInspectorStub()
+	}
+	if len(c.BlobInspectorOutputs) > 0 {
+		output := c.BlobInspectorOutputs[0]
+		c.BlobInspectorOutputs = c.BlobInspectorOutputs[1:]
+		return output
+	}
+	if c.BlobInspectorOutput != nil {
+		return *c.BlobInspectorOutput
+	}
+	panic("BlobInspector has no output")
+}
+
 func (c *ClientProvider) UserClient() user.Client {
 	c.UserClientInvocations++
 	if c.UserClientStub != nil {
@@ -80,6 +122,12 @@ func (c *ClientProvider) AssertOutputsEmpty() {
 	if len(c.BlobUnstructuredStoreOutputs) > 0 {
 		panic("BlobUnstructuredStoreOutputs is not empty")
 	}
+	if len(c.BlobLinkerOutputs) > 0 {
+		panic("BlobLinkerOutputs is not empty")
+	}
+	if len(c.BlobInspectorOutputs) > 0 {
+		panic("BlobInspectorOutputs is not empty")
+	}
 	if len(c.UserClientOutputs) > 0 {
 		panic("UserClientOutputs is not empty")
 	}
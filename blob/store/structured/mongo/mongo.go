@@ -45,20 +45,35 @@ func (s *Store) NewSession() blobStoreStructured.Session {
 
 func (s *Store) newSession() *Session {
 	return &Session{
-		Session: s.Store.NewSession("blobs"),
+		Session:           s.Store.NewSession("blobs"),
+		linkNoncesSession: s.Store.NewSession("blob_link_nonces"),
 	}
 }
 
+// Used link nonces are kept in a separate collection, rather than with the blob, so they expire with the link
 type Session struct {
 	*storeStructuredMongo.Session
+	linkNoncesSession *storeStructuredMongo.Session
+}
+
+func (s *Session) Close() error {
+	s.linkNoncesSession.Close()
+	return s.Session.Close()
 }
 
 func (s *Session) EnsureIndexes() error {
-	return s.EnsureAllIndexes([]mgo.Index{
+	if err := s.EnsureAllIndexes([]mgo.Index{
 		{Key: []string{"id"}, Background: true, Unique: true},
 		{Key: []string{"userId"}, Background: true},
 		{Key: []string{"mediaType"}, Background: true},
 		{Key: []string{"status"}, Background: true},
+		{Key: []string{"expirationTime"}, Background: true, Sparse: true},
+	}); err != nil {
+		return err
+	}
+	return s.linkNoncesSession.EnsureAllIndexes([]mgo.Index{
+		{Key: []string{"id", "nonce"}, Background: true, Unique: true},
+		{Key: []string{"expirationTime"}, Background: true, ExpireAfter: time.Second},
 	})
 }
 
@@ -105,6 +120,10 @@ func (s *Session) List(ctx context.Context, userID string, filter *blob.Filter,
 	} else {
 		query["status"] = blob.StatusAvailable
 	}
+	query["$or"] = []bson.M{
+		{"expirationTime": bson.M{"$exists": false}},
+		{"expirationTime": bson.M{"$gt": now}},
+	}
 	err := s.C().Find(query).Sort("-createdTime").Skip(pagination.Page * pagination.Size).Limit(pagination.Size).All(&blbs)
 	if err != nil {
 		logger.WithError(err).Error("Unable to list blobs")
@@ -145,6 +164,9 @@ func (s *Session) Create(ctx context.Context, userID string, create *blobStoreSt
 	if create.MediaType != nil {
 		doc["mediaType"] = *create.MediaType
 	}
+	if create.ExpirationTime != nil {
+		doc["expirationTime"] = create.ExpirationTime.Truncate(time.Second)
+	}
 
 	var id string
 	var err error
@@ -282,6 +304,82 @@ func (s *Session) Delete(ctx context.Context, id string) (bool, error) {
 	return changeInfo.Removed > 0, nil
 }
 
+func (s *Session) ListExpired(ctx context.Context, pagination *page.Pagination) (blob.Blobs, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if pagination == nil {
+		pagination = page.NewPagination()
+	} else if err := structureValidator.New().Validate(pagination); err != nil {
+		return nil, errors.Wrap(err, "pagination is invalid")
+	}
+
+	if s.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("pagination", pagination)
+
+	blbs := blob.Blobs{}
+	query := bson.M{
+		"$or": []bson.M{
+			{"status": blob.StatusCreated, "createdTime": bson.M{"$lt": now.Add(-blob.StatusCreatedTimeout)}},
+			{"expirationTime": bson.M{"$lte": now}},
+		},
+	}
+	err := s.C().Find(query).Sort("createdTime").Skip(pagination.Page * pagination.Size).Limit(pagination.Size).All(&blbs)
+	if err != nil {
+		logger.WithError(err).Error("Unable to list expired blobs")
+		return nil, errors.Wrap(err, "unable to list expired blobs")
+	}
+
+	logger.WithFields(log.Fields{"count": len(blbs), "duration": time.Since(now) / time.Microsecond}).Debug("ListExpired")
+	return blbs, nil
+}
+
+// UseLinkNonce records the nonce of a single use link until the link expires. Returns false if the nonce was
+// previously used.
+func (s *Session) UseLinkNonce(ctx context.Context, id string, nonce string, expirationTime time.Time) (bool, error) {
+	if ctx == nil {
+		return false, errors.New("context is missing")
+	}
+	if id == "" {
+		return false, errors.New("id is missing")
+	} else if !blob.IsValidID(id) {
+		return false, errors.New("id is invalid")
+	}
+	if nonce == "" {
+		return false, errors.New("nonce is missing")
+	}
+	if expirationTime.IsZero() {
+		return false, errors.New("expiration time is missing")
+	}
+
+	if s.IsClosed() {
+		return false, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"id": id, "nonce": nonce})
+
+	used := true
+	doc := bson.M{
+		"id":             id,
+		"nonce":          nonce,
+		"expirationTime": expirationTime.Truncate(time.Second),
+	}
+	if err := s.linkNoncesSession.C().Insert(doc); mgo.IsDup(err) {
+		used = false
+	} else if err != nil {
+		logger.WithError(err).Error("Unable to use blob link nonce")
+		return false, errors.Wrap(err, "unable to use blob link nonce")
+	}
+
+	logger.WithFields(log.Fields{"used": used, "duration": time.Since(now) / time.Microsecond}).Debug("UseLinkNonce")
+	return used, nil
+}
+
 func (s *Session) get(logger log.Logger, id string) (*blob.Blob, error) {
 	blbs := blob.Blobs{}
 	err := s.C().Find(bson.M{"id": id}).Limit(2).All(&blbs)
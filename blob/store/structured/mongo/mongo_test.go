@@ -27,6 +27,7 @@ import (
 	"github.com/tidepool-org/platform/pointer"
 	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
 	storeStructuredMongoTest "github.com/tidepool-org/platform/store/structured/mongo/test"
+	"github.com/tidepool-org/platform/test"
 	"github.com/tidepool-org/platform/user"
 )
 
@@ -137,6 +138,14 @@ var _ = Describe("Mongo", func() {
 					MatchFields(IgnoreExtras, Fields{"Key": ConsistOf("userId"), "Background": Equal(true)}),
 					MatchFields(IgnoreExtras, Fields{"Key": ConsistOf("mediaType"), "Background": Equal(true)}),
 					MatchFields(IgnoreExtras, Fields{"Key": ConsistOf("status"), "Background": Equal(true)}),
+					MatchFields(IgnoreExtras, Fields{"Key": ConsistOf("expirationTime"), "Background": Equal(true), "Sparse": Equal(true)}),
+				))
+				indexes, err = mgoSession.DB(config.Database).C(config.CollectionPrefix + "blob_link_nonces").Indexes()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(indexes).To(ConsistOf(
+					MatchFields(IgnoreExtras, Fields{"Key": ConsistOf("_id")}),
+					MatchFields(IgnoreExtras, Fields{"Key": ConsistOf("id", "nonce"), "Background": Equal(true), "Unique": Equal(true)}),
+					MatchFields(IgnoreExtras, Fields{"Key": ConsistOf("expirationTime"), "Background": Equal(true), "ExpireAfter": Equal(time.Second)}),
 				))
 			})
 		})
@@ -612,6 +621,181 @@ var _ = Describe("Mongo", func() {
 				})
 			})
 
+			Context("ListExpired", func() {
+				var pagination *page.Pagination
+
+				BeforeEach(func() {
+					pagination = page.NewPagination()
+				})
+
+				It("returns an error when the context is missing", func() {
+					ctx = nil
+					blbs, err := session.ListExpired(ctx, pagination)
+					errorsTest.ExpectEqual(err, errors.New("context is missing"))
+					Expect(blbs).To(BeNil())
+				})
+
+				It("returns an error when the pagination is invalid", func() {
+					pagination.Page = -1
+					blbs, err := session.ListExpired(ctx, pagination)
+					errorsTest.ExpectEqual(err, errors.New("pagination is invalid"))
+					Expect(blbs).To(BeNil())
+				})
+
+				It("returns an error when the session is closed", func() {
+					session.Close()
+					blbs, err := session.ListExpired(ctx, pagination)
+					errorsTest.ExpectEqual(err, errors.New("session closed"))
+					Expect(blbs).To(BeNil())
+				})
+
+				Context("with data", func() {
+					var expiredBlobs blob.Blobs
+
+					BeforeEach(func() {
+						now := time.Now().Truncate(time.Second)
+						availableBlob := blobTest.RandomBlob()
+						availableBlob.Status = pointer.FromString(blob.StatusAvailable)
+						availableBlob.CreatedTime = pointer.FromTime(now.Add(-3 * blob.StatusCreatedTimeout))
+						availableBlob.ModifiedTime = pointer.FromTime(now.Add(-2 * blob.StatusCreatedTimeout))
+						recentCreatedBlob := blobTest.RandomBlob()
+						recentCreatedBlob.Status = pointer.FromString(blob.StatusCreated)
+						recentCreatedBlob.CreatedTime = pointer.FromTime(now.Add(-blob.StatusCreatedTimeout / 2))
+						recentCreatedBlob.ModifiedTime = nil
+						unexpiredBlob := blobTest.CloneBlob(availableBlob)
+						unexpiredBlob.ID = pointer.FromString(blob.NewID())
+						unexpiredBlob.ExpirationTime = pointer.FromTime(now.Add(time.Hour))
+						orphanedCreatedBlob := blobTest.CloneBlob(recentCreatedBlob)
+						orphanedCreatedBlob.ID = pointer.FromString(blob.NewID())
+						orphanedCreatedBlob.CreatedTime = pointer.FromTime(now.Add(-4 * blob.StatusCreatedTimeout))
+						expiredBlob := blobTest.CloneBlob(availableBlob)
+						expiredBlob.ID = pointer.FromString(blob.NewID())
+						expiredBlob.ExpirationTime = pointer.FromTime(now.Add(-time.Hour))
+						expiredBlobs = blob.Blobs{orphanedCreatedBlob, expiredBlob}
+						Expect(mgoCollection.Insert(availableBlob, recentCreatedBlob, unexpiredBlob, orphanedCreatedBlob, expiredBlob)).To(Succeed())
+					})
+
+					It("returns expected blobs", func() {
+						blbs, err := session.ListExpired(ctx, pagination)
+						Expect(err).ToNot(HaveOccurred())
+						blobTest.ExpectEqualBlobs(blbs, expiredBlobs)
+						logger.AssertDebug("ListExpired", log.Fields{"pagination": pagination, "count": 2})
+					})
+
+					It("returns expected blobs when paginated", func() {
+						pagination.Page = 1
+						pagination.Size = 1
+						blbs, err := session.ListExpired(ctx, pagination)
+						Expect(err).ToNot(HaveOccurred())
+						blobTest.ExpectEqualBlobs(blbs, expiredBlobs[1:])
+						logger.AssertDebug("ListExpired", log.Fields{"pagination": pagination, "count": 1})
+					})
+
+					It("does not return expired blobs from list", func() {
+						filter := blob.NewFilter()
+						filter.Status = pointer.FromStringArray(blob.Statuses())
+						blbs, err := session.List(ctx, *expiredBlobs[1].UserID, filter, pagination)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(blbs).To(HaveLen(2))
+						for _, blb := range blbs {
+							Expect(blb.ID).ToNot(Equal(expiredBlobs[1].ID))
+						}
+					})
+				})
+			})
+
+			Context("UseLinkNonce", func() {
+				var id string
+				var nonce string
+				var expirationTime time.Time
+
+				BeforeEach(func() {
+					id = blob.NewID()
+					nonce = test.RandomStringFromRangeAndCharset(32, 32, test.CharsetAlphaNumeric)
+					expirationTime = time.Now().Add(blob.LinkExpirationDurationDefault)
+				})
+
+				It("returns an error when the context is missing", func() {
+					ctx = nil
+					used, err := session.UseLinkNonce(ctx, id, nonce, expirationTime)
+					errorsTest.ExpectEqual(err, errors.New("context is missing"))
+					Expect(used).To(BeFalse())
+				})
+
+				It("returns an error when the id is missing", func() {
+					id = ""
+					used, err := session.UseLinkNonce(ctx, id, nonce, expirationTime)
+					errorsTest.ExpectEqual(err, errors.New("id is missing"))
+					Expect(used).To(BeFalse())
+				})
+
+				It("returns an error when the id is invalid", func() {
+					id = "invalid"
+					used, err := session.UseLinkNonce(ctx, id, nonce, expirationTime)
+					errorsTest.ExpectEqual(err, errors.New("id is invalid"))
+					Expect(used).To(BeFalse())
+				})
+
+				It("returns an error when the nonce is missing", func() {
+					nonce = ""
+					used, err := session.UseLinkNonce(ctx, id, nonce, expirationTime)
+					errorsTest.ExpectEqual(err, errors.New("nonce is missing"))
+					Expect(used).To(BeFalse())
+				})
+
+				It("returns an error when the expiration time is missing", func() {
+					expirationTime = time.Time{}
+					used, err := session.UseLinkNonce(ctx, id, nonce, expirationTime)
+					errorsTest.ExpectEqual(err, errors.New("expiration time is missing"))
+					Expect(used).To(BeFalse())
+				})
+
+				It("returns an error when the session is closed", func() {
+					session.Close()
+					used, err := session.UseLinkNonce(ctx, id, nonce, expirationTime)
+					errorsTest.ExpectEqual(err, errors.New("session closed"))
+					Expect(used).To(BeFalse())
+				})
+
+				Context("with data", func() {
+					BeforeEach(func() {
+						blb := blobTest.RandomBlob()
+						blb.ID = pointer.FromString(id)
+						Expect(mgoCollection.Insert(blb)).To(Succeed())
+					})
+
+					AfterEach(func() {
+						logger.AssertDebug("UseLinkNonce", log.Fields{"id": id, "nonce": nonce})
+					})
+
+					It("returns true when the nonce is first used", func() {
+						Expect(session.UseLinkNonce(ctx, id, nonce, expirationTime)).To(BeTrue())
+					})
+
+					It("returns false when the nonce was previously used", func() {
+						Expect(session.UseLinkNonce(ctx, id, nonce, expirationTime)).To(BeTrue())
+						Expect(session.UseLinkNonce(ctx, id, nonce, expirationTime)).To(BeFalse())
+					})
+
+					It("returns true when a different nonce is used", func() {
+						Expect(session.UseLinkNonce(ctx, id, nonce, expirationTime)).To(BeTrue())
+						nonce = test.RandomStringFromRangeAndCharset(32, 32, test.CharsetAlphaNumeric)
+						Expect(session.UseLinkNonce(ctx, id, nonce, expirationTime)).To(BeTrue())
+					})
+
+					It("returns true when the same nonce is used for a different id", func() {
+						Expect(session.UseLinkNonce(ctx, id, nonce, expirationTime)).To(BeTrue())
+						id = blob.NewID()
+						Expect(session.UseLinkNonce(ctx, id, nonce, expirationTime)).To(BeTrue())
+					})
+
+					It("does not modify the blob", func() {
+						Expect(session.UseLinkNonce(ctx, id, nonce, expirationTime)).To(BeTrue())
+						Expect(mgoCollection.Find(bson.M{"id": id, "usedLinkNonces": bson.M{"$exists": true}}).Count()).To(Equal(0))
+					})
+				})
+			})
+
 			Context("Delete", func() {
 				var id string
 
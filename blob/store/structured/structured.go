@@ -3,6 +3,7 @@ package structured
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/tidepool-org/platform/blob"
 	"github.com/tidepool-org/platform/crypto"
@@ -23,10 +24,14 @@ type Session interface {
 	Get(ctx context.Context, id string) (*blob.Blob, error)
 	Update(ctx context.Context, id string, update *Update) (*blob.Blob, error)
 	Delete(ctx context.Context, id string) (bool, error)
+
+	ListExpired(ctx context.Context, pagination *page.Pagination) (blob.Blobs, error)
+	UseLinkNonce(ctx context.Context, id string, nonce string, expirationTime time.Time) (bool, error)
 }
 
 type Create struct {
-	MediaType *string
+	MediaType      *string
+	ExpirationTime *time.Time
 }
 
 func NewCreate() *Create {
@@ -35,6 +40,7 @@ func NewCreate() *Create {
 
 func (c *Create) Validate(validator structure.Validator) {
 	validator.String("mediaType", c.MediaType).Using(net.MediaTypeValidator)
+	validator.Time("expirationTime", c.ExpirationTime).AfterNow(time.Second)
 }
 
 type Update struct {
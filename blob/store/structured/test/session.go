@@ -2,6 +2,7 @@ package test
 
 import (
 	"context"
+	"time"
 
 	"github.com/tidepool-org/platform/blob"
 	blobStoreStructured "github.com/tidepool-org/platform/blob/store/structured"
@@ -63,33 +64,65 @@ type DeleteOutput struct {
 	Error   error
 }
 
+type ListExpiredInput struct {
+	Context    context.Context
+	Pagination *page.Pagination
+}
+
+type ListExpiredOutput struct {
+	Blobs blob.Blobs
+	Error error
+}
+
+type UseLinkNonceInput struct {
+	Context        context.Context
+	ID             string
+	Nonce          string
+	ExpirationTime time.Time
+}
+
+type UseLinkNonceOutput struct {
+	Used  bool
+	Error error
+}
+
 type Session struct {
 	*test.Closer
-	ListInvocations   int
-	ListInputs        []ListInput
-	ListStub          func(ctx context.Context, userID string, filter *blob.Filter, pagination *page.Pagination) (blob.Blobs, error)
-	ListOutputs       []ListOutput
-	ListOutput        *ListOutput
-	CreateInvocations int
-	CreateInputs      []CreateInput
-	CreateStub        func(ctx context.Context, userID string, create *blobStoreStructured.Create) (*blob.Blob, error)
-	CreateOutputs     []CreateOutput
-	CreateOutput      *CreateOutput
-	GetInvocations    int
-	GetInputs         []GetInput
-	GetStub           func(ctx context.Context, id string) (*blob.Blob, error)
-	GetOutputs        []GetOutput
-	GetOutput         *GetOutput
-	UpdateInvocations int
-	UpdateInputs      []UpdateInput
-	UpdateStub        func(ctx context.Context, id string, create *blobStoreStructured.Update) (*blob.Blob, error)
-	UpdateOutputs     []UpdateOutput
-	UpdateOutput      *UpdateOutput
-	DeleteInvocations int
-	DeleteInputs      []DeleteInput
-	DeleteStub        func(ctx context.Context, id string) (bool, error)
-	DeleteOutputs     []DeleteOutput
-	DeleteOutput      *DeleteOutput
+	ListInvocations         int
+	ListInputs              []ListInput
+	ListStub                func(ctx context.Context, userID string, filter *blob.Filter, pagination *page.Pagination) (blob.Blobs, error)
+	ListOutputs             []ListOutput
+	ListOutput              *ListOutput
+	CreateInvocations       int
+	CreateInputs            []CreateInput
+	CreateStub              func(ctx context.Context, userID string, create *blobStoreStructured.Create) (*blob.Blob, error)
+	CreateOutputs           []CreateOutput
+	CreateOutput            *CreateOutput
+	GetInvocations          int
+	GetInputs               []GetInput
+	GetStub                 func(ctx context.Context, id string) (*blob.Blob, error)
+	GetOutputs              []GetOutput
+	GetOutput               *GetOutput
+	UpdateInvocations       int
+	UpdateInputs            []UpdateInput
+	UpdateStub              func(ctx context.Context, id string, create *blobStoreStructured.Update) (*blob.Blob, error)
+	UpdateOutputs           []UpdateOutput
+	UpdateOutput            *UpdateOutput
+	DeleteInvocations       int
+	DeleteInputs            []DeleteInput
+	DeleteStub              func(ctx context.Context, id string) (bool, error)
+	DeleteOutputs           []DeleteOutput
+	DeleteOutput            *DeleteOutput
+	ListExpiredInvocations  int
+	ListExpiredInputs       []ListExpiredInput
+	ListExpiredStub         func(ctx context.Context, pagination *page.Pagination) (blob.Blobs, error)
+	ListExpiredOutputs      []ListExpiredOutput
+	ListExpiredOutput       *ListExpiredOutput
+	UseLinkNonceInvocations int
+	UseLinkNonceInputs      []UseLinkNonceInput
+	UseLinkNonceStub        func(ctx context.Context, id string, nonce string, expirationTime time.Time) (bool, error)
+	UseLinkNonceOutputs     []UseLinkNonceOutput
+	UseLinkNonceOutput      *UseLinkNonceOutput
 }
 
 func NewSession() *Session {
@@ -183,6 +216,40 @@ func (s *Session) Delete(ctx context.Context, id string) (bool, error) {
 	panic("Delete has no output")
 }
 
+func (s *Session) ListExpired(ctx context.Context, pagination *page.Pagination) (blob.Blobs, error) {
+	s.ListExpiredInvocations++
+	s.ListExpiredInputs = append(s.ListExpiredInputs, ListExpiredInput{Context: ctx, Pagination: pagination})
+	if s.ListExpiredStub != nil {
+		return s.ListExpiredStub(ctx, pagination)
+	}
+	if len(s.ListExpiredOutputs) > 0 {
+		output := s.ListExpiredOutputs[0]
+		s.ListExpiredOutputs = s.ListExpiredOutputs[1:]
+		return output.Blobs, output.Error
+	}
+	if s.ListExpiredOutput != nil {
+		return s.ListExpiredOutput.Blobs, s.ListExpiredOutput.Error
+	}
+	panic("ListExpired has no output")
+}
+
+func (s *Session) UseLinkNonce(ctx context.Context, id string, nonce string, expirationTime time.Time) (bool, error) {
+	s.UseLinkNonceInvocations++
+	s.UseLinkNonceInputs = append(s.UseLinkNonceInputs, UseLinkNonceInput{Context: ctx, ID: id, Nonce: nonce, ExpirationTime: expirationTime})
+	if s.UseLinkNonceStub != nil {
+		return s.UseLinkNonceStub(ctx, id, nonce, expirationTime)
+	}
+	if len(s.UseLinkNonceOutputs) > 0 {
+		output := s.UseLinkNonceOutputs[0]
+		s.UseLinkNonceOutputs = s.UseLinkNonceOutputs[1:]
+		return output.Used, output.Error
+	}
+	if s.UseLinkNonceOutput != nil {
+		return s.UseLinkNonceOutput.Used, s.UseLinkNonceOutput.Error
+	}
+	panic("UseLinkNonce has no output")
+}
+
 func (s *Session) AssertOutputsEmpty() {
 	s.Closer.AssertOutputsEmpty()
 	if len(s.ListOutputs) > 0 {
@@ -200,4 +267,10 @@ func (s *Session) AssertOutputsEmpty() {
 	if len(s.DeleteOutputs) > 0 {
 		panic("DeleteOutputs is not empty")
 	}
+	if len(s.ListExpiredOutputs) > 0 {
+		panic("ListExpiredOutputs is not empty")
+	}
+	if len(s.UseLinkNonceOutputs) > 0 {
+		panic("UseLinkNonceOutputs is not empty")
+	}
 }
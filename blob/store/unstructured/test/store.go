@@ -3,6 +3,9 @@ package test
 import (
 	"context"
 	"io"
+	"time"
+
+	storeUnstructured "github.com/tidepool-org/platform/store/unstructured"
 )
 
 type ExistsInput struct {
@@ -21,6 +24,7 @@ type PutInput struct {
 	UserID  string
 	ID      string
 	Reader  io.Reader
+	Options *storeUnstructured.Options
 }
 
 type GetInput struct {
@@ -45,27 +49,44 @@ type DeleteOutput struct {
 	Error   error
 }
 
+type PresignGetInput struct {
+	Context        context.Context
+	UserID         string
+	ID             string
+	ExpirationTime time.Time
+}
+
+type PresignGetOutput struct {
+	URL   *string
+	Error error
+}
+
 type Store struct {
-	ExistsInvocations int
-	ExistsInputs      []ExistsInput
-	ExistsStub        func(ctx context.Context, userID string, id string) (bool, error)
-	ExistsOutputs     []ExistsOutput
-	ExistsOutput      *ExistsOutput
-	PutInvocations    int
-	PutInputs         []PutInput
-	PutStub           func(ctx context.Context, userID string, id string, reader io.Reader) error
-	PutOutputs        []error
-	PutOutput         *error
-	GetInvocations    int
-	GetInputs         []GetInput
-	GetStub           func(ctx context.Context, userID string, id string) (io.ReadCloser, error)
-	GetOutputs        []GetOutput
-	GetOutput         *GetOutput
-	DeleteInvocations int
-	DeleteInputs      []DeleteInput
-	DeleteStub        func(ctx context.Context, userID string, id string) (bool, error)
-	DeleteOutputs     []DeleteOutput
-	DeleteOutput      *DeleteOutput
+	ExistsInvocations     int
+	ExistsInputs          []ExistsInput
+	ExistsStub            func(ctx context.Context, userID string, id string) (bool, error)
+	ExistsOutputs         []ExistsOutput
+	ExistsOutput          *ExistsOutput
+	PutInvocations        int
+	PutInputs             []PutInput
+	PutStub               func(ctx context.Context, userID string, id string, reader io.Reader, options *storeUnstructured.Options) error
+	PutOutputs            []error
+	PutOutput             *error
+	GetInvocations        int
+	GetInputs             []GetInput
+	GetStub               func(ctx context.Context, userID string, id string) (io.ReadCloser, error)
+	GetOutputs            []GetOutput
+	GetOutput             *GetOutput
+	DeleteInvocations     int
+	DeleteInputs          []DeleteInput
+	DeleteStub            func(ctx context.Context, userID string, id string) (bool, error)
+	DeleteOutputs         []DeleteOutput
+	DeleteOutput          *DeleteOutput
+	PresignGetInvocations int
+	PresignGetInputs      []PresignGetInput
+	PresignGetStub        func(ctx context.Context, userID string, id string, expirationTime time.Time) (*string, error)
+	PresignGetOutputs     []PresignGetOutput
+	PresignGetOutput      *PresignGetOutput
 }
 
 func NewStore() *Store {
@@ -89,11 +110,11 @@ func (s *Store) Exists(ctx context.Context, userID string, id string) (bool, err
 	panic("Exists has no output")
 }
 
-func (s *Store) Put(ctx context.Context, userID string, id string, reader io.Reader) error {
+func (s *Store) Put(ctx context.Context, userID string, id string, reader io.Reader, options *storeUnstructured.Options) error {
 	s.PutInvocations++
-	s.PutInputs = append(s.PutInputs, PutInput{Context: ctx, UserID: userID, ID: id, Reader: reader})
+	s.PutInputs = append(s.PutInputs, PutInput{Context: ctx, UserID: userID, ID: id, Reader: reader, Options: options})
 	if s.PutStub != nil {
-		return s.PutStub(ctx, userID, id, reader)
+		return s.PutStub(ctx, userID, id, reader, options)
 	}
 	if len(s.PutOutputs) > 0 {
 		output := s.PutOutputs[0]
@@ -140,6 +161,23 @@ func (s *Store) Delete(ctx context.Context, userID string, id string) (bool, err
 	panic("Delete has no output")
 }
 
+func (s *Store) PresignGet(ctx context.Context, userID string, id string, expirationTime time.Time) (*string, error) {
+	s.PresignGetInvocations++
+	s.PresignGetInputs = append(s.PresignGetInputs, PresignGetInput{Context: ctx, UserID: userID, ID: id, ExpirationTime: expirationTime})
+	if s.PresignGetStub != nil {
+		return s.PresignGetStub(ctx, userID, id, expirationTime)
+	}
+	if len(s.PresignGetOutputs) > 0 {
+		output := s.PresignGetOutputs[0]
+		s.PresignGetOutputs = s.PresignGetOutputs[1:]
+		return output.URL, output.Error
+	}
+	if s.PresignGetOutput != nil {
+		return s.PresignGetOutput.URL, s.PresignGetOutput.Error
+	}
+	panic("PresignGet has no output")
+}
+
 func (s *Store) AssertOutputsEmpty() {
 	if len(s.ExistsOutputs) > 0 {
 		panic("ExistsOutputs is not empty")
@@ -153,4 +191,7 @@ func (s *Store) AssertOutputsEmpty() {
 	if len(s.DeleteOutputs) > 0 {
 		panic("DeleteOutputs is not empty")
 	}
+	if len(s.PresignGetOutputs) > 0 {
+		panic("PresignGetOutputs is not empty")
+	}
 }
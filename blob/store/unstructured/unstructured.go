@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/tidepool-org/platform/errors"
 	storeUnstructured "github.com/tidepool-org/platform/store/unstructured"
@@ -11,9 +12,10 @@ import (
 
 type Store interface {
 	Exists(ctx context.Context, userID string, id string) (bool, error)
-	Put(ctx context.Context, userID string, id string, reader io.Reader) error
+	Put(ctx context.Context, userID string, id string, reader io.Reader, options *storeUnstructured.Options) error
 	Get(ctx context.Context, userID string, id string) (io.ReadCloser, error)
 	Delete(ctx context.Context, userID string, id string) (bool, error)
+	PresignGet(ctx context.Context, userID string, id string, expirationTime time.Time) (*string, error)
 }
 
 type StoreImpl struct {
@@ -38,8 +40,8 @@ func (s *StoreImpl) Exists(ctx context.Context, userID string, id string) (bool,
 	return exists, nil
 }
 
-func (s *StoreImpl) Put(ctx context.Context, userID string, id string, reader io.Reader) error {
-	err := s.store.Put(ctx, asKey(userID, id), reader)
+func (s *StoreImpl) Put(ctx context.Context, userID string, id string, reader io.Reader, options *storeUnstructured.Options) error {
+	err := s.store.Put(ctx, asKey(userID, id), reader, options)
 	if err != nil {
 		return errors.Wrap(err, "unable to put blob")
 	}
@@ -62,6 +64,20 @@ func (s *StoreImpl) Delete(ctx context.Context, userID string, id string) (bool,
 	return deleted, nil
 }
 
+// Returns nil if the underlying store does not support presigned urls
+func (s *StoreImpl) PresignGet(ctx context.Context, userID string, id string, expirationTime time.Time) (*string, error) {
+	presigner, ok := s.store.(storeUnstructured.Presigner)
+	if !ok {
+		return nil, nil
+	}
+
+	url, err := presigner.PresignGet(ctx, asKey(userID, id), expirationTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to presign get blob")
+	}
+	return &url, nil
+}
+
 func asKey(userID string, id string) string {
 	return fmt.Sprintf("%s/%s/%s", userID, id, id)
 }
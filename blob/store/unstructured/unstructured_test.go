@@ -9,12 +9,17 @@ import (
 	"io"
 	"io/ioutil"
 	"strings"
+	"time"
 
 	blobStoreUnstructured "github.com/tidepool-org/platform/blob/store/unstructured"
 	"github.com/tidepool-org/platform/errors"
 	errorsTest "github.com/tidepool-org/platform/errors/test"
+	netTest "github.com/tidepool-org/platform/net/test"
+	"github.com/tidepool-org/platform/pointer"
+	storeUnstructured "github.com/tidepool-org/platform/store/unstructured"
 	storeUnstructuredTest "github.com/tidepool-org/platform/store/unstructured/test"
 	"github.com/tidepool-org/platform/test"
+	testHttp "github.com/tidepool-org/platform/test/http"
 )
 
 var _ = Describe("Unstructured", func() {
@@ -84,24 +89,27 @@ var _ = Describe("Unstructured", func() {
 
 		Context("Put", func() {
 			var reader io.Reader
+			var options *storeUnstructured.Options
 
 			BeforeEach(func() {
 				reader = strings.NewReader(test.RandomString())
+				options = storeUnstructured.NewOptions()
+				options.MediaType = pointer.FromString(netTest.RandomMediaType())
 			})
 
 			AfterEach(func() {
-				Expect(underlyingStore.PutInputs).To(Equal([]storeUnstructuredTest.PutInput{{Context: ctx, Key: key, Reader: reader}}))
+				Expect(underlyingStore.PutInputs).To(Equal([]storeUnstructuredTest.PutInput{{Context: ctx, Key: key, Reader: reader, Options: options}}))
 			})
 
 			It("returns an error when the underlying store returns an error", func() {
 				parentErr := errorsTest.NewError()
 				underlyingStore.PutOutputs = []error{parentErr}
-				errorsTest.ExpectEqual(store.Put(ctx, userID, id, reader), errors.New("unable to put blob"))
+				errorsTest.ExpectEqual(store.Put(ctx, userID, id, reader, options), errors.New("unable to put blob"))
 			})
 
 			It("returns successfully when the underlying store returns successfully", func() {
 				underlyingStore.PutOutputs = []error{nil}
-				Expect(store.Put(ctx, userID, id, reader)).ToNot(HaveOccurred())
+				Expect(store.Put(ctx, userID, id, reader, options)).ToNot(HaveOccurred())
 			})
 		})
 
@@ -158,5 +166,47 @@ var _ = Describe("Unstructured", func() {
 				Expect(store.Delete(ctx, userID, id)).To(BeTrue())
 			})
 		})
+
+		Context("PresignGet", func() {
+			var expirationTime time.Time
+
+			BeforeEach(func() {
+				expirationTime = time.Now().Add(time.Hour)
+			})
+
+			It("returns nil when the underlying store does not support presign", func() {
+				Expect(store.PresignGet(ctx, userID, id, expirationTime)).To(BeNil())
+			})
+
+			Context("with presigner store", func() {
+				var presignerStore *storeUnstructuredTest.PresignerStore
+
+				BeforeEach(func() {
+					var err error
+					presignerStore = storeUnstructuredTest.NewPresignerStore()
+					store, err = blobStoreUnstructured.NewStore(presignerStore)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(store).ToNot(BeNil())
+				})
+
+				AfterEach(func() {
+					Expect(presignerStore.PresignGetInputs).To(Equal([]storeUnstructuredTest.PresignGetInput{{Context: ctx, Key: key, ExpirationTime: expirationTime}}))
+					presignerStore.AssertOutputsEmpty()
+				})
+
+				It("returns an error when the underlying store returns an error", func() {
+					presignerStore.PresignGetOutputs = []storeUnstructuredTest.PresignGetOutput{{URL: "", Error: errorsTest.NewError()}}
+					url, err := store.PresignGet(ctx, userID, id, expirationTime)
+					errorsTest.ExpectEqual(err, errors.New("unable to presign get blob"))
+					Expect(url).To(BeNil())
+				})
+
+				It("returns the url when the underlying store returns successfully", func() {
+					presignedURL := testHttp.NewAddress()
+					presignerStore.PresignGetOutputs = []storeUnstructuredTest.PresignGetOutput{{URL: presignedURL, Error: nil}}
+					Expect(store.PresignGet(ctx, userID, id, expirationTime)).To(Equal(&presignedURL))
+				})
+			})
+		})
 	})
 })
@@ -13,11 +13,12 @@ import (
 	netTest "github.com/tidepool-org/platform/net/test"
 	"github.com/tidepool-org/platform/pointer"
 	"github.com/tidepool-org/platform/test"
+	testHttp "github.com/tidepool-org/platform/test/http"
 	"github.com/tidepool-org/platform/user"
 )
 
 func RandomStatuses() []string {
-	return test.RandomStringArrayFromRangeAndArrayWithoutDuplicates(1, 2, blob.Statuses())
+	return test.RandomStringArrayFromRangeAndArrayWithoutDuplicates(1, len(blob.Statuses()), blob.Statuses())
 }
 
 func RandomFilter() *blob.Filter {
@@ -86,6 +87,7 @@ func CloneBlob(datum *blob.Blob) *blob.Blob {
 	clone.MediaType = pointer.CloneString(datum.MediaType)
 	clone.Size = pointer.CloneInt(datum.Size)
 	clone.Status = pointer.CloneString(datum.Status)
+	clone.ExpirationTime = pointer.CloneTime(datum.ExpirationTime)
 	clone.CreatedTime = pointer.CloneTime(datum.CreatedTime)
 	clone.ModifiedTime = pointer.CloneTime(datum.ModifiedTime)
 	return clone
@@ -114,6 +116,9 @@ func NewObjectFromBlob(datum *blob.Blob, objectFormat test.ObjectFormat) map[str
 	if datum.Status != nil {
 		object["status"] = test.NewObjectFromString(*datum.Status, objectFormat)
 	}
+	if datum.ExpirationTime != nil {
+		object["expirationTime"] = test.NewObjectFromTime(*datum.ExpirationTime, objectFormat)
+	}
 	if datum.CreatedTime != nil {
 		object["createdTime"] = test.NewObjectFromTime(*datum.CreatedTime, objectFormat)
 	}
@@ -132,6 +137,11 @@ func ExpectEqualBlob(actualBlob *blob.Blob, expectedBlob *blob.Blob) {
 	gomega.Expect(actualBlob.MediaType).To(gomega.Equal(expectedBlob.MediaType))
 	gomega.Expect(actualBlob.Size).To(gomega.Equal(expectedBlob.Size))
 	gomega.Expect(actualBlob.Status).To(gomega.Equal(expectedBlob.Status))
+	if actualBlob.ExpirationTime != nil && expectedBlob.ExpirationTime != nil {
+		gomega.Expect(actualBlob.ExpirationTime.Local()).To(gomega.Equal(expectedBlob.ExpirationTime.Local()))
+	} else {
+		gomega.Expect(actualBlob.ExpirationTime).To(gomega.Equal(expectedBlob.ExpirationTime))
+	}
 	if actualBlob.CreatedTime != nil && expectedBlob.CreatedTime != nil {
 		gomega.Expect(actualBlob.CreatedTime.Local()).To(gomega.Equal(expectedBlob.CreatedTime.Local()))
 	} else {
@@ -158,3 +168,53 @@ func ExpectEqualBlobs(actualBlobs blob.Blobs, expectedBlobs blob.Blobs) {
 		ExpectEqualBlob(actualBlobs[index], expectedBlobs[index])
 	}
 }
+
+func RandomLinkCreate() *blob.LinkCreate {
+	datum := blob.NewLinkCreate()
+	datum.ExpirationTime = pointer.FromTime(test.RandomTimeFromRange(time.Now().Add(time.Minute), time.Now().Add(blob.LinkExpirationDurationMaximum-time.Minute)).Truncate(time.Second))
+	datum.SingleUse = pointer.FromBool(test.RandomBool())
+	return datum
+}
+
+func NewObjectFromLinkCreate(datum *blob.LinkCreate, objectFormat test.ObjectFormat) map[string]interface{} {
+	if datum == nil {
+		return nil
+	}
+	object := map[string]interface{}{}
+	if datum.ExpirationTime != nil {
+		object["expirationTime"] = test.NewObjectFromTime(*datum.ExpirationTime, objectFormat)
+	}
+	if datum.SingleUse != nil {
+		object["singleUse"] = test.NewObjectFromBool(*datum.SingleUse, objectFormat)
+	}
+	return object
+}
+
+func RandomLink() *blob.Link {
+	datum := &blob.Link{}
+	datum.URL = pointer.FromString(testHttp.NewAddress())
+	datum.ExpirationTime = pointer.FromTime(test.RandomTimeFromRange(time.Now().Add(time.Minute), time.Now().Add(blob.LinkExpirationDurationMaximum)).Truncate(time.Second))
+	datum.SingleUse = pointer.FromBool(test.RandomBool())
+	return datum
+}
+
+func ExpectEqualLink(actualLink *blob.Link, expectedLink *blob.Link) {
+	gomega.Expect(actualLink).ToNot(gomega.BeNil())
+	gomega.Expect(expectedLink).ToNot(gomega.BeNil())
+	gomega.Expect(actualLink.URL).To(gomega.Equal(expectedLink.URL))
+	if actualLink.ExpirationTime != nil && expectedLink.ExpirationTime != nil {
+		gomega.Expect(actualLink.ExpirationTime.Local()).To(gomega.Equal(expectedLink.ExpirationTime.Local()))
+	} else {
+		gomega.Expect(actualLink.ExpirationTime).To(gomega.Equal(expectedLink.ExpirationTime))
+	}
+	gomega.Expect(actualLink.SingleUse).To(gomega.Equal(expectedLink.SingleUse))
+}
+
+func RandomLinkQuery() *blob.LinkQuery {
+	datum := blob.NewLinkQuery()
+	datum.ExpirationTime = pointer.FromTime(test.RandomTimeFromRange(time.Now().Add(time.Minute), time.Now().Add(blob.LinkExpirationDurationMaximum)).Truncate(time.Second))
+	datum.SingleUse = pointer.FromBool(test.RandomBool())
+	datum.Nonce = pointer.FromString(test.RandomStringFromRangeAndCharset(32, 32, test.CharsetAlphaNumeric))
+	datum.Signature = pointer.FromString(test.RandomStringFromRangeAndCharset(43, 43, test.CharsetAlphaNumeric))
+	return datum
+}
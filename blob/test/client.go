@@ -60,32 +60,79 @@ type DeleteOutput struct {
 	Error   error
 }
 
+type ListExpiredInput struct {
+	Context    context.Context
+	Pagination *page.Pagination
+}
+
+type ListExpiredOutput struct {
+	Blobs blob.Blobs
+	Error error
+}
+
+type CreateLinkInput struct {
+	Context context.Context
+	ID      string
+	Create  *blob.LinkCreate
+}
+
+type CreateLinkOutput struct {
+	Link  *blob.Link
+	Error error
+}
+
+type GetLinkContentInput struct {
+	Context context.Context
+	ID      string
+	Query   *blob.LinkQuery
+}
+
+type GetLinkContentOutput struct {
+	Content *blob.Content
+	Error   error
+}
+
 type Client struct {
-	ListInvocations       int
-	ListInputs            []ListInput
-	ListStub              func(ctx context.Context, userID string, filter *blob.Filter, pagination *page.Pagination) (blob.Blobs, error)
-	ListOutputs           []ListOutput
-	ListOutput            *ListOutput
-	CreateInvocations     int
-	CreateInputs          []CreateInput
-	CreateStub            func(ctx context.Context, userID string, create *blob.Create) (*blob.Blob, error)
-	CreateOutputs         []CreateOutput
-	CreateOutput          *CreateOutput
-	GetInvocations        int
-	GetInputs             []GetInput
-	GetStub               func(ctx context.Context, id string) (*blob.Blob, error)
-	GetOutputs            []GetOutput
-	GetOutput             *GetOutput
-	GetContentInvocations int
-	GetContentInputs      []GetContentInput
-	GetContentStub        func(ctx context.Context, id string) (*blob.Content, error)
-	GetContentOutputs     []GetContentOutput
-	GetContentOutput      *GetContentOutput
-	DeleteInvocations     int
-	DeleteInputs          []DeleteInput
-	DeleteStub            func(ctx context.Context, id string) (bool, error)
-	DeleteOutputs         []DeleteOutput
-	DeleteOutput          *DeleteOutput
+	ListInvocations           int
+	ListInputs                []ListInput
+	ListStub                  func(ctx context.Context, userID string, filter *blob.Filter, pagination *page.Pagination) (blob.Blobs, error)
+	ListOutputs               []ListOutput
+	ListOutput                *ListOutput
+	CreateInvocations         int
+	CreateInputs              []CreateInput
+	CreateStub                func(ctx context.Context, userID string, create *blob.Create) (*blob.Blob, error)
+	CreateOutputs             []CreateOutput
+	CreateOutput              *CreateOutput
+	GetInvocations            int
+	GetInputs                 []GetInput
+	GetStub                   func(ctx context.Context, id string) (*blob.Blob, error)
+	GetOutputs                []GetOutput
+	GetOutput                 *GetOutput
+	GetContentInvocations     int
+	GetContentInputs          []GetContentInput
+	GetContentStub            func(ctx context.Context, id string) (*blob.Content, error)
+	GetContentOutputs         []GetContentOutput
+	GetContentOutput          *GetContentOutput
+	DeleteInvocations         int
+	DeleteInputs              []DeleteInput
+	DeleteStub                func(ctx context.Context, id string) (bool, error)
+	DeleteOutputs             []DeleteOutput
+	DeleteOutput              *DeleteOutput
+	ListExpiredInvocations    int
+	ListExpiredInputs         []ListExpiredInput
+	ListExpiredStub           func(ctx context.Context, pagination *page.Pagination) (blob.Blobs, error)
+	ListExpiredOutputs        []ListExpiredOutput
+	ListExpiredOutput         *ListExpiredOutput
+	CreateLinkInvocations     int
+	CreateLinkInputs          []CreateLinkInput
+	CreateLinkStub            func(ctx context.Context, id string, create *blob.LinkCreate) (*blob.Link, error)
+	CreateLinkOutputs         []CreateLinkOutput
+	CreateLinkOutput          *CreateLinkOutput
+	GetLinkContentInvocations int
+	GetLinkContentInputs      []GetLinkContentInput
+	GetLinkContentStub        func(ctx context.Context, id string, query *blob.LinkQuery) (*blob.Content, error)
+	GetLinkContentOutputs     []GetLinkContentOutput
+	GetLinkContentOutput      *GetLinkContentOutput
 }
 
 func NewClient() *Client {
@@ -177,6 +224,57 @@ func (c *Client) Delete(ctx context.Context, id string) (bool, error) {
 	panic("Delete has no output")
 }
 
+func (c *Client) ListExpired(ctx context.Context, pagination *page.Pagination) (blob.Blobs, error) {
+	c.ListExpiredInvocations++
+	c.ListExpiredInputs = append(c.ListExpiredInputs, ListExpiredInput{Context: ctx, Pagination: pagination})
+	if c.ListExpiredStub != nil {
+		return c.ListExpiredStub(ctx, pagination)
+	}
+	if len(c.ListExpiredOutputs) > 0 {
+		output := c.ListExpiredOutputs[0]
+		c.ListExpiredOutputs = c.ListExpiredOutputs[1:]
+		return output.Blobs, output.Error
+	}
+	if c.ListExpiredOutput != nil {
+		return c.ListExpiredOutput.Blobs, c.ListExpiredOutput.Error
+	}
+	panic("ListExpired has no output")
+}
+
+func (c *Client) CreateLink(ctx context.Context, id string, create *blob.LinkCreate) (*blob.Link, error) {
+	c.CreateLinkInvocations++
+	c.CreateLinkInputs = append(c.CreateLinkInputs, CreateLinkInput{Context: ctx, ID: id, Create: create})
+	if c.CreateLinkStub != nil {
+		return c.CreateLinkStub(ctx, id, create)
+	}
+	if len(c.CreateLinkOutputs) > 0 {
+		output := c.CreateLinkOutputs[0]
+		c.CreateLinkOutputs = c.CreateLinkOutputs[1:]
+		return output.Link, output.Error
+	}
+	if c.CreateLinkOutput != nil {
+		return c.CreateLinkOutput.Link, c.CreateLinkOutput.Error
+	}
+	panic("CreateLink has no output")
+}
+
+func (c *Client) GetLinkContent(ctx context.Context, id string, query *blob.LinkQuery) (*blob.Content, error) {
+	c.GetLinkContentInvocations++
+	c.GetLinkContentInputs = append(c.GetLinkContentInputs, GetLinkContentInput{Context: ctx, ID: id, Query: query})
+	if c.GetLinkContentStub != nil {
+		return c.GetLinkContentStub(ctx, id, query)
+	}
+	if len(c.GetLinkContentOutputs) > 0 {
+		output := c.GetLinkContentOutputs[0]
+		c.GetLinkContentOutputs = c.GetLinkContentOutputs[1:]
+		return output.Content, output.Error
+	}
+	if c.GetLinkContentOutput != nil {
+		return c.GetLinkContentOutput.Content, c.GetLinkContentOutput.Error
+	}
+	panic("GetLinkContent has no output")
+}
+
 func (c *Client) AssertOutputsEmpty() {
 	if len(c.ListOutputs) > 0 {
 		panic("ListOutputs is not empty")
@@ -193,4 +291,13 @@ func (c *Client) AssertOutputsEmpty() {
 	if len(c.DeleteOutputs) > 0 {
 		panic("DeleteOutputs is not empty")
 	}
+	if len(c.ListExpiredOutputs) > 0 {
+		panic("ListExpiredOutputs is not empty")
+	}
+	if len(c.CreateLinkOutputs) > 0 {
+		panic("CreateLinkOutputs is not empty")
+	}
+	if len(c.GetLinkContentOutputs) > 0 {
+		panic("GetLinkContentOutputs is not empty")
+	}
 }
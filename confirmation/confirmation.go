@@ -1,7 +1,83 @@
 package confirmation
 
-import "context"
+import (
+	"context"
+
+	"github.com/tidepool-org/platform/id"
+	"github.com/tidepool-org/platform/user"
+)
+
+const (
+	TypeCareteamInvitation = "careteam_invitation"
+	TypePasswordReset      = "password_reset"
+	TypeSignupConfirmation = "signup_confirmation"
+
+	StatusCanceled  = "canceled"
+	StatusCompleted = "completed"
+	StatusDeclined  = "declined"
+	StatusPending   = "pending"
+)
 
 type ConfirmationAccessor interface {
+	ListConfirmations(ctx context.Context, filter *ConfirmationFilter) (Confirmations, error)
+	CreateConfirmation(ctx context.Context, create *ConfirmationCreate) (*Confirmation, error)
+	GetConfirmation(ctx context.Context, key string) (*Confirmation, error)
+	UpdateConfirmation(ctx context.Context, key string, update *ConfirmationUpdate) (*Confirmation, error)
 	DeleteUserConfirmations(ctx context.Context, userID string) error
 }
+
+type ConfirmationFilter struct {
+	Type      *string
+	Status    *string
+	CreatorID *string
+	Emails    []string
+}
+
+func NewConfirmationFilter() *ConfirmationFilter {
+	return &ConfirmationFilter{}
+}
+
+type ConfirmationCreate struct {
+	Type        string
+	Email       string
+	CreatorID   string
+	Permissions user.Permissions
+}
+
+func NewConfirmationCreate() *ConfirmationCreate {
+	return &ConfirmationCreate{}
+}
+
+type ConfirmationUpdate struct {
+	Status *string
+	UserID *string
+}
+
+func NewConfirmationUpdate() *ConfirmationUpdate {
+	return &ConfirmationUpdate{}
+}
+
+// Confirmation is compatible with the legacy confirmations collection where the permissions of an invitation are
+// stored as JSON in the context
+type Confirmation struct {
+	Key          string           `json:"key" bson:"_id"`
+	Type         string           `json:"type" bson:"type"`
+	Status       string           `json:"status" bson:"status"`
+	Email        string           `json:"email,omitempty" bson:"email,omitempty"`
+	CreatorID    string           `json:"creatorId,omitempty" bson:"creatorId,omitempty"`
+	UserID       string           `json:"userId,omitempty" bson:"userId,omitempty"`
+	Context      []byte           `json:"-" bson:"context,omitempty"`
+	Permissions  user.Permissions `json:"permissions,omitempty" bson:"-"`
+	CreatedTime  string           `json:"created,omitempty" bson:"created,omitempty"`
+	ModifiedTime string           `json:"modified,omitempty" bson:"modified,omitempty"`
+}
+
+func (c *Confirmation) IsPending() bool {
+	return c.Status == StatusPending
+}
+
+type Confirmations []*Confirmation
+
+func NewKey() string {
+	return id.Must(id.New(16))
+}
@@ -2,11 +2,13 @@ package mongo
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	mgo "gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 
+	"github.com/tidepool-org/platform/confirmation"
 	"github.com/tidepool-org/platform/confirmation/store"
 	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/log"
@@ -50,6 +52,7 @@ type ConfirmationSession struct {
 
 func (c *ConfirmationSession) EnsureIndexes() error {
 	return c.EnsureAllIndexes([]mgo.Index{
+		{Key: []string{"creatorId"}, Background: true},
 		{Key: []string{"email"}, Background: true},
 		{Key: []string{"status"}, Background: true},
 		{Key: []string{"type"}, Background: true},
@@ -57,6 +60,161 @@ func (c *ConfirmationSession) EnsureIndexes() error {
 	})
 }
 
+func (c *ConfirmationSession) ListConfirmations(ctx context.Context, filter *confirmation.ConfirmationFilter) (confirmation.Confirmations, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if filter == nil {
+		return nil, errors.New("filter is missing")
+	}
+
+	if c.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("filter", filter)
+
+	selector := bson.M{}
+	if filter.Type != nil {
+		selector["type"] = *filter.Type
+	}
+	if filter.Status != nil {
+		selector["status"] = *filter.Status
+	}
+	if filter.CreatorID != nil {
+		selector["creatorId"] = *filter.CreatorID
+	}
+	if filter.Emails != nil {
+		selector["email"] = bson.M{"$in": filter.Emails}
+	}
+
+	confirmations := confirmation.Confirmations{}
+	err := c.C().Find(selector).Sort("-created").All(&confirmations)
+	logger.WithFields(log.Fields{"count": len(confirmations), "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("ListConfirmations")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list confirmations")
+	}
+
+	for _, cnfrmtn := range confirmations {
+		decodeContext(ctx, cnfrmtn)
+	}
+
+	return confirmations, nil
+}
+
+func (c *ConfirmationSession) CreateConfirmation(ctx context.Context, create *confirmation.ConfirmationCreate) (*confirmation.Confirmation, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if create == nil {
+		return nil, errors.New("create is missing")
+	}
+
+	if c.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("create", create)
+
+	cnfrmtn := &confirmation.Confirmation{
+		Key:         confirmation.NewKey(),
+		Type:        create.Type,
+		Status:      confirmation.StatusPending,
+		Email:       create.Email,
+		CreatorID:   create.CreatorID,
+		Permissions: create.Permissions,
+		CreatedTime: now.UTC().Format(time.RFC3339),
+	}
+	if create.Permissions != nil {
+		contextBytes, err := json.Marshal(create.Permissions)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to encode permissions")
+		}
+		cnfrmtn.Context = contextBytes
+	}
+
+	err := c.C().Insert(cnfrmtn)
+	logger.WithFields(log.Fields{"key": cnfrmtn.Key, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("CreateConfirmation")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create confirmation")
+	}
+
+	return cnfrmtn, nil
+}
+
+func (c *ConfirmationSession) GetConfirmation(ctx context.Context, key string) (*confirmation.Confirmation, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if key == "" {
+		return nil, errors.New("key is missing")
+	}
+
+	if c.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("key", key)
+
+	confirmations := confirmation.Confirmations{}
+	err := c.C().Find(bson.M{"_id": key}).Limit(2).All(&confirmations)
+	logger.WithField("duration", time.Since(now)/time.Microsecond).WithError(err).Debug("GetConfirmation")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get confirmation")
+	}
+
+	switch count := len(confirmations); count {
+	case 0:
+		return nil, nil
+	case 1:
+		decodeContext(ctx, confirmations[0])
+		return confirmations[0], nil
+	default:
+		logger.WithField("count", count).Warnf("Multiple confirmations found for key %q", key)
+		decodeContext(ctx, confirmations[0])
+		return confirmations[0], nil
+	}
+}
+
+func (c *ConfirmationSession) UpdateConfirmation(ctx context.Context, key string, update *confirmation.ConfirmationUpdate) (*confirmation.Confirmation, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if key == "" {
+		return nil, errors.New("key is missing")
+	}
+	if update == nil {
+		return nil, errors.New("update is missing")
+	}
+
+	if c.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"key": key, "update": update})
+
+	set := bson.M{
+		"modified": now.UTC().Format(time.RFC3339),
+	}
+	if update.Status != nil {
+		set["status"] = *update.Status
+	}
+	if update.UserID != nil {
+		set["userId"] = *update.UserID
+	}
+	changeInfo, err := c.C().UpdateAll(bson.M{"_id": key}, bson.M{"$set": set})
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("UpdateConfirmation")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to update confirmation")
+	}
+
+	return c.GetConfirmation(ctx, key)
+}
+
 func (c *ConfirmationSession) DeleteUserConfirmations(ctx context.Context, userID string) error {
 	if ctx == nil {
 		return errors.New("context is missing")
@@ -86,3 +244,13 @@ func (c *ConfirmationSession) DeleteUserConfirmations(ctx context.Context, userI
 
 	return nil
 }
+
+// The permissions of an invitation are stored as JSON in the context; other confirmation types may store anything
+func decodeContext(ctx context.Context, cnfrmtn *confirmation.Confirmation) {
+	if cnfrmtn.Type != confirmation.TypeCareteamInvitation || len(cnfrmtn.Context) == 0 {
+		return
+	}
+	if err := json.Unmarshal(cnfrmtn.Context, &cnfrmtn.Permissions); err != nil {
+		log.LoggerFromContext(ctx).WithField("key", cnfrmtn.Key).WithError(err).Warn("Unable to decode confirmation context")
+	}
+}
@@ -11,14 +11,17 @@ import (
 	mgo "gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 
+	"github.com/tidepool-org/platform/confirmation"
 	"github.com/tidepool-org/platform/confirmation/store"
 	"github.com/tidepool-org/platform/confirmation/store/mongo"
 	"github.com/tidepool-org/platform/log"
 	logNull "github.com/tidepool-org/platform/log/null"
+	"github.com/tidepool-org/platform/pointer"
 	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
 	storeStructuredMongoTest "github.com/tidepool-org/platform/store/structured/mongo/test"
 	"github.com/tidepool-org/platform/test"
 	testInternet "github.com/tidepool-org/platform/test/internet"
+	"github.com/tidepool-org/platform/user"
 )
 
 func NewConfirmation(userID string, typ string) bson.M {
@@ -118,6 +121,7 @@ var _ = Describe("Store", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(indexes).To(ConsistOf(
 					MatchFields(IgnoreExtras, Fields{"Key": ConsistOf("_id")}),
+					MatchFields(IgnoreExtras, Fields{"Key": ConsistOf("creatorId")}),
 					MatchFields(IgnoreExtras, Fields{"Key": ConsistOf("email")}),
 					MatchFields(IgnoreExtras, Fields{"Key": ConsistOf("status")}),
 					MatchFields(IgnoreExtras, Fields{"Key": ConsistOf("type")}),
@@ -147,6 +151,90 @@ var _ = Describe("Store", func() {
 					Expect(mgoCollection.Insert(confirmations...)).To(Succeed())
 				})
 
+				Context("CreateConfirmation", func() {
+					var create *confirmation.ConfirmationCreate
+
+					BeforeEach(func() {
+						create = confirmation.NewConfirmationCreate()
+						create.Type = confirmation.TypeCareteamInvitation
+						create.Email = testInternet.NewEmail()
+						create.CreatorID = test.NewString(10, test.CharsetHexidecimalLowercase)
+						create.Permissions = user.Permissions{user.ViewPermission: user.Permission{}}
+					})
+
+					It("returns an error if the context is missing", func() {
+						cnfrmtn, err := ssn.CreateConfirmation(nil, create)
+						Expect(err).To(MatchError("context is missing"))
+						Expect(cnfrmtn).To(BeNil())
+					})
+
+					It("returns an error if the create is missing", func() {
+						cnfrmtn, err := ssn.CreateConfirmation(ctx, nil)
+						Expect(err).To(MatchError("create is missing"))
+						Expect(cnfrmtn).To(BeNil())
+					})
+
+					It("returns an error if the session is closed", func() {
+						ssn.Close()
+						cnfrmtn, err := ssn.CreateConfirmation(ctx, create)
+						Expect(err).To(MatchError("session closed"))
+						Expect(cnfrmtn).To(BeNil())
+					})
+
+					It("returns the pending confirmation and stores the permissions in the context", func() {
+						cnfrmtn, err := ssn.CreateConfirmation(ctx, create)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(cnfrmtn).ToNot(BeNil())
+						Expect(cnfrmtn.Key).ToNot(BeEmpty())
+						Expect(cnfrmtn.Status).To(Equal(confirmation.StatusPending))
+						Expect(cnfrmtn.Permissions).To(Equal(create.Permissions))
+						Expect(mgoCollection.Find(bson.M{"_id": cnfrmtn.Key, "context": []byte(`{"view":{}}`)}).Count()).To(Equal(1))
+					})
+
+					Context("with created confirmation", func() {
+						var created *confirmation.Confirmation
+
+						BeforeEach(func() {
+							var err error
+							created, err = ssn.CreateConfirmation(ctx, create)
+							Expect(err).ToNot(HaveOccurred())
+						})
+
+						It("ListConfirmations returns the confirmations matching the filter", func() {
+							filter := confirmation.NewConfirmationFilter()
+							filter.Type = pointer.FromString(confirmation.TypeCareteamInvitation)
+							filter.Status = pointer.FromString(confirmation.StatusPending)
+							filter.Emails = []string{create.Email}
+							Expect(ssn.ListConfirmations(ctx, filter)).To(Equal(confirmation.Confirmations{created}))
+							filter.Emails = nil
+							filter.CreatorID = pointer.FromString(create.CreatorID)
+							Expect(ssn.ListConfirmations(ctx, filter)).To(Equal(confirmation.Confirmations{created}))
+							filter.Status = pointer.FromString(confirmation.StatusCompleted)
+							Expect(ssn.ListConfirmations(ctx, filter)).To(BeEmpty())
+						})
+
+						It("GetConfirmation returns the confirmation", func() {
+							Expect(ssn.GetConfirmation(ctx, created.Key)).To(Equal(created))
+						})
+
+						It("GetConfirmation returns nil if the confirmation does not exist", func() {
+							Expect(ssn.GetConfirmation(ctx, confirmation.NewKey())).To(BeNil())
+						})
+
+						It("UpdateConfirmation updates the status and user id", func() {
+							update := confirmation.NewConfirmationUpdate()
+							update.Status = pointer.FromString(confirmation.StatusCompleted)
+							update.UserID = pointer.FromString(test.NewString(10, test.CharsetHexidecimalLowercase))
+							updated, err := ssn.UpdateConfirmation(ctx, created.Key, update)
+							Expect(err).ToNot(HaveOccurred())
+							Expect(updated).ToNot(BeNil())
+							Expect(updated.Status).To(Equal(confirmation.StatusCompleted))
+							Expect(updated.UserID).To(Equal(*update.UserID))
+							Expect(updated.ModifiedTime).ToNot(BeEmpty())
+						})
+					})
+				})
+
 				Context("DeleteUserConfirmations", func() {
 					var userID string
 					var userConfirmations []interface{}
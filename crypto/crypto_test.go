@@ -63,6 +63,16 @@ var _ = Describe("Crypto", func() {
 		)
 	})
 
+	Context("HexEncodedSHA256Hash", func() {
+		DescribeTable("returns the expected result when the input",
+			func(value string, expectedResult string) {
+				Expect(crypto.HexEncodedSHA256Hash(value)).To(Equal(expectedResult))
+			},
+			Entry("is empty", "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"),
+			Entry("is not empty", "abcdefghijklmnopqrstuvwxyz", "71c480df93d6ae2f1efad1447c66c9525e316218cf51fc8d9ed832f2daf18b73"),
+		)
+	})
+
 	Context("EncryptWithAES256UsingPassphrase", func() {
 		It("returns an error if the bytes is missing", func() {
 			encrypted, err := crypto.EncryptWithAES256UsingPassphrase(nil, []byte("secret"))
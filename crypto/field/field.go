@@ -0,0 +1,124 @@
+package field
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"strings"
+
+	cryptoKey "github.com/tidepool-org/platform/crypto/key"
+	"github.com/tidepool-org/platform/errors"
+)
+
+// Prefix identifies a value encrypted by an Encrypter. An encrypted value has the form
+// "<Prefix><key id>:<base64 encoded nonce and ciphertext>".
+const Prefix = "encrypted:v1:"
+
+// Encrypter encrypts individual string values, typically document fields, using AES-256-GCM. The
+// id of the key used is embedded in the encrypted value so that keys may be rotated by adding a new
+// key, changing the current key id, and re-encrypting existing values. Additional data (for example,
+// the document id and field name) binds an encrypted value to its location so it cannot be moved.
+type Encrypter struct {
+	keyID string
+	aeads map[string]cipher.AEAD
+}
+
+func NewEncrypter(cfg *cryptoKey.Config) (*Encrypter, error) {
+	if cfg == nil {
+		return nil, errors.New("config is missing")
+	} else if err := cfg.Validate(); err != nil {
+		return nil, errors.Wrap(err, "config is invalid")
+	}
+
+	aeads := map[string]cipher.AEAD{}
+	for keyID, key := range cfg.Keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to create cipher for key with id %q", keyID)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to create cipher for key with id %q", keyID)
+		}
+		aeads[keyID] = aead
+	}
+
+	return &Encrypter{
+		keyID: cfg.KeyID,
+		aeads: aeads,
+	}, nil
+}
+
+func (e *Encrypter) KeyID() string {
+	return e.keyID
+}
+
+func (e *Encrypter) Encrypt(value string, additionalData string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	aead := e.aeads[e.keyID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", errors.Wrap(err, "unable to generate nonce")
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(additionalData))
+	return Prefix + e.keyID + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func (e *Encrypter) Decrypt(value string, additionalData string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	keyID, encoded, err := split(value)
+	if err != nil {
+		return "", err
+	}
+
+	aead, ok := e.aeads[keyID]
+	if !ok {
+		return "", errors.Newf("key with id %q is missing", keyID)
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("value is invalid")
+	}
+
+	opened, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(additionalData))
+	if err != nil {
+		return "", errors.New("unable to decrypt value")
+	}
+
+	return string(opened), nil
+}
+
+// RequiresEncrypt returns true if the value is not empty and either is not encrypted or is encrypted
+// with a key other than the current key.
+func (e *Encrypter) RequiresEncrypt(value string) bool {
+	if value == "" {
+		return false
+	}
+	keyID, _, err := split(value)
+	return err != nil || keyID != e.keyID
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+func split(value string) (string, string, error) {
+	if !IsEncrypted(value) {
+		return "", "", errors.New("value is not encrypted")
+	}
+	parts := strings.SplitN(strings.TrimPrefix(value, Prefix), ":", 2)
+	if len(parts) != 2 || !cryptoKey.IsValidKeyID(parts[0]) {
+		return "", "", errors.New("value is invalid")
+	}
+	return parts[0], parts[1], nil
+}
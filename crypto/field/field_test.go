@@ -0,0 +1,148 @@
+package field_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"strings"
+
+	cryptoField "github.com/tidepool-org/platform/crypto/field"
+	cryptoKey "github.com/tidepool-org/platform/crypto/key"
+	"github.com/tidepool-org/platform/test"
+)
+
+var _ = Describe("Field", func() {
+	var keyID string
+	var cfg *cryptoKey.Config
+
+	BeforeEach(func() {
+		keyID = test.NewVariableString(1, 32, test.CharsetAlphaNumeric)
+		cfg = cryptoKey.NewConfig()
+		cfg.KeyID = keyID
+		cfg.Keys = map[string][]byte{keyID: test.RandomBytesFromRange(32, 32)}
+	})
+
+	Context("NewEncrypter", func() {
+		It("returns an error if the config is missing", func() {
+			encrypter, err := cryptoField.NewEncrypter(nil)
+			Expect(err).To(MatchError("config is missing"))
+			Expect(encrypter).To(BeNil())
+		})
+
+		It("returns an error if the config is invalid", func() {
+			cfg.KeyID = ""
+			encrypter, err := cryptoField.NewEncrypter(cfg)
+			Expect(err).To(MatchError("config is invalid; key id is missing"))
+			Expect(encrypter).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			encrypter, err := cryptoField.NewEncrypter(cfg)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(encrypter).ToNot(BeNil())
+			Expect(encrypter.KeyID()).To(Equal(keyID))
+		})
+	})
+
+	Context("with new encrypter", func() {
+		var encrypter *cryptoField.Encrypter
+		var value string
+		var additionalData string
+
+		BeforeEach(func() {
+			var err error
+			encrypter, err = cryptoField.NewEncrypter(cfg)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(encrypter).ToNot(BeNil())
+			value = test.NewText(1, 128)
+			additionalData = test.NewText(1, 32)
+		})
+
+		It("returns empty when encrypting an empty value", func() {
+			Expect(encrypter.Encrypt("", additionalData)).To(BeEmpty())
+		})
+
+		It("returns empty when decrypting an empty value", func() {
+			Expect(encrypter.Decrypt("", additionalData)).To(BeEmpty())
+		})
+
+		It("encrypts and decrypts a value", func() {
+			encrypted, err := encrypter.Encrypt(value, additionalData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(encrypted).To(HavePrefix(cryptoField.Prefix + keyID + ":"))
+			Expect(encrypted).ToNot(ContainSubstring(value))
+			Expect(cryptoField.IsEncrypted(encrypted)).To(BeTrue())
+			Expect(encrypter.RequiresEncrypt(encrypted)).To(BeFalse())
+			Expect(encrypter.Decrypt(encrypted, additionalData)).To(Equal(value))
+		})
+
+		It("encrypts the same value differently each time", func() {
+			first, err := encrypter.Encrypt(value, additionalData)
+			Expect(err).ToNot(HaveOccurred())
+			second, err := encrypter.Encrypt(value, additionalData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(first).ToNot(Equal(second))
+		})
+
+		It("returns an error if the additional data does not match", func() {
+			encrypted, err := encrypter.Encrypt(value, additionalData)
+			Expect(err).ToNot(HaveOccurred())
+			decrypted, err := encrypter.Decrypt(encrypted, additionalData+"x")
+			Expect(err).To(MatchError("unable to decrypt value"))
+			Expect(decrypted).To(BeEmpty())
+		})
+
+		It("returns an error if the value is not encrypted", func() {
+			decrypted, err := encrypter.Decrypt(value, additionalData)
+			Expect(err).To(HaveOccurred())
+			Expect(decrypted).To(BeEmpty())
+		})
+
+		It("returns an error if the value is invalid", func() {
+			decrypted, err := encrypter.Decrypt(cryptoField.Prefix+keyID+":#invalid#", additionalData)
+			Expect(err).To(MatchError("value is invalid"))
+			Expect(decrypted).To(BeEmpty())
+		})
+
+		It("returns an error if the value was encrypted with an unknown key", func() {
+			decrypted, err := encrypter.Decrypt(cryptoField.Prefix+"unknown:AAAA", additionalData)
+			Expect(err).To(MatchError(`key with id "unknown" is missing`))
+			Expect(decrypted).To(BeEmpty())
+		})
+
+		It("requires encrypt for a value that is not encrypted", func() {
+			Expect(cryptoField.IsEncrypted(value)).To(Equal(strings.HasPrefix(value, cryptoField.Prefix)))
+			Expect(encrypter.RequiresEncrypt(value)).To(BeTrue())
+			Expect(encrypter.RequiresEncrypt("")).To(BeFalse())
+		})
+
+		Context("after key rotation", func() {
+			var encrypted string
+			var rotatedEncrypter *cryptoField.Encrypter
+
+			BeforeEach(func() {
+				var err error
+				encrypted, err = encrypter.Encrypt(value, additionalData)
+				Expect(err).ToNot(HaveOccurred())
+				cfg.Keys["rotated"] = test.RandomBytesFromRange(32, 32)
+				cfg.KeyID = "rotated"
+				rotatedEncrypter, err = cryptoField.NewEncrypter(cfg)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("decrypts a value encrypted with the previous key", func() {
+				Expect(rotatedEncrypter.RequiresEncrypt(encrypted)).To(BeTrue())
+				Expect(rotatedEncrypter.Decrypt(encrypted, additionalData)).To(Equal(value))
+			})
+
+			It("encrypts with the current key", func() {
+				reencrypted, err := rotatedEncrypter.Encrypt(value, additionalData)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reencrypted).To(HavePrefix(cryptoField.Prefix + "rotated:"))
+				Expect(rotatedEncrypter.RequiresEncrypt(reencrypted)).To(BeFalse())
+				_, err = encrypter.Decrypt(reencrypted, additionalData)
+				Expect(err).To(MatchError(`key with id "rotated" is missing`))
+			})
+		})
+	})
+})
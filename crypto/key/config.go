@@ -0,0 +1,77 @@
+package key
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+
+	"github.com/tidepool-org/platform/config"
+	"github.com/tidepool-org/platform/errors"
+)
+
+type Config struct {
+	KeyID string
+	Keys  map[string][]byte
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Keys: map[string][]byte{},
+	}
+}
+
+func (c *Config) Load(configReporter config.Reporter) error {
+	if configReporter == nil {
+		return errors.New("config reporter is missing")
+	}
+
+	c.KeyID = configReporter.GetWithDefault("key_id", c.KeyID)
+	if keysString, err := configReporter.Get("keys"); err == nil {
+		keys := map[string][]byte{}
+		for _, keyString := range config.SplitTrimCompact(keysString) {
+			parts := strings.SplitN(keyString, ":", 2)
+			if len(parts) != 2 {
+				return errors.New("keys is invalid")
+			}
+			key, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return errors.New("keys is invalid")
+			}
+			keys[parts[0]] = key
+		}
+		c.Keys = keys
+	}
+
+	return nil
+}
+
+func (c *Config) Validate() error {
+	if c.KeyID == "" {
+		return errors.New("key id is missing")
+	} else if !IsValidKeyID(c.KeyID) {
+		return errors.New("key id is invalid")
+	}
+	if len(c.Keys) == 0 {
+		return errors.New("keys is missing")
+	}
+	for keyID, key := range c.Keys {
+		if !IsValidKeyID(keyID) {
+			return errors.Newf("key id %q is invalid", keyID)
+		} else if len(key) != KeyLength {
+			return errors.Newf("key with id %q is invalid", keyID)
+		}
+	}
+	if _, ok := c.Keys[c.KeyID]; !ok {
+		return errors.Newf("key with id %q is missing", c.KeyID)
+	}
+
+	return nil
+}
+
+func IsValidKeyID(value string) bool {
+	return keyIDExpression.MatchString(value)
+}
+
+const KeyLength = 32
+
+var keyIDExpression = regexp.MustCompile("^[0-9A-Za-z][0-9A-Za-z._-]{0,63}$")
@@ -0,0 +1,124 @@
+package key_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"encoding/base64"
+	"fmt"
+
+	configTest "github.com/tidepool-org/platform/config/test"
+	cryptoKey "github.com/tidepool-org/platform/crypto/key"
+	"github.com/tidepool-org/platform/test"
+)
+
+var _ = Describe("Config", func() {
+	Context("NewConfig", func() {
+		It("returns successfully with default values", func() {
+			cfg := cryptoKey.NewConfig()
+			Expect(cfg).ToNot(BeNil())
+			Expect(cfg.KeyID).To(BeEmpty())
+			Expect(cfg.Keys).To(BeEmpty())
+		})
+	})
+
+	Context("with new config", func() {
+		var keyID string
+		var key []byte
+		var cfg *cryptoKey.Config
+
+		BeforeEach(func() {
+			keyID = test.NewVariableString(1, 32, test.CharsetAlphaNumeric)
+			key = test.RandomBytesFromRange(32, 32)
+			cfg = cryptoKey.NewConfig()
+			Expect(cfg).ToNot(BeNil())
+		})
+
+		Context("Load", func() {
+			var configReporter *configTest.Reporter
+
+			BeforeEach(func() {
+				configReporter = configTest.NewReporter()
+				configReporter.Config["key_id"] = keyID
+				configReporter.Config["keys"] = fmt.Sprintf("%s:%s", keyID, base64.StdEncoding.EncodeToString(key))
+			})
+
+			It("returns an error if the config reporter is missing", func() {
+				Expect(cfg.Load(nil)).To(MatchError("config reporter is missing"))
+			})
+
+			It("returns an error if the keys is missing a separator", func() {
+				configReporter.Config["keys"] = keyID
+				Expect(cfg.Load(configReporter)).To(MatchError("keys is invalid"))
+			})
+
+			It("returns an error if the keys is not base64 encoded", func() {
+				configReporter.Config["keys"] = fmt.Sprintf("%s:#invalid#", keyID)
+				Expect(cfg.Load(configReporter)).To(MatchError("keys is invalid"))
+			})
+
+			It("returns successfully and does not set the key id or keys", func() {
+				delete(configReporter.Config, "key_id")
+				delete(configReporter.Config, "keys")
+				Expect(cfg.Load(configReporter)).To(Succeed())
+				Expect(cfg.KeyID).To(BeEmpty())
+				Expect(cfg.Keys).To(BeEmpty())
+			})
+
+			It("returns successfully and sets the key id and keys", func() {
+				Expect(cfg.Load(configReporter)).To(Succeed())
+				Expect(cfg.KeyID).To(Equal(keyID))
+				Expect(cfg.Keys).To(Equal(map[string][]byte{keyID: key}))
+			})
+
+			It("returns successfully and sets multiple keys", func() {
+				otherKey := test.RandomBytesFromRange(32, 32)
+				configReporter.Config["keys"] = fmt.Sprintf(" other:%s , %s:%s ", base64.StdEncoding.EncodeToString(otherKey), keyID, base64.StdEncoding.EncodeToString(key))
+				Expect(cfg.Load(configReporter)).To(Succeed())
+				Expect(cfg.KeyID).To(Equal(keyID))
+				Expect(cfg.Keys).To(Equal(map[string][]byte{keyID: key, "other": otherKey}))
+			})
+		})
+
+		Context("Validate", func() {
+			BeforeEach(func() {
+				cfg.KeyID = keyID
+				cfg.Keys = map[string][]byte{keyID: key}
+			})
+
+			It("returns an error if the key id is missing", func() {
+				cfg.KeyID = ""
+				Expect(cfg.Validate()).To(MatchError("key id is missing"))
+			})
+
+			It("returns an error if the key id is invalid", func() {
+				cfg.KeyID = "#invalid#"
+				Expect(cfg.Validate()).To(MatchError("key id is invalid"))
+			})
+
+			It("returns an error if the keys is missing", func() {
+				cfg.Keys = nil
+				Expect(cfg.Validate()).To(MatchError("keys is missing"))
+			})
+
+			It("returns an error if a key id is invalid", func() {
+				cfg.Keys["#invalid#"] = key
+				Expect(cfg.Validate()).To(MatchError(`key id "#invalid#" is invalid`))
+			})
+
+			It("returns an error if a key is invalid", func() {
+				cfg.Keys["other"] = test.RandomBytesFromRange(16, 16)
+				Expect(cfg.Validate()).To(MatchError(`key with id "other" is invalid`))
+			})
+
+			It("returns an error if the key for the key id is missing", func() {
+				cfg.Keys = map[string][]byte{"other": key}
+				Expect(cfg.Validate()).To(MatchError(fmt.Sprintf("key with id %q is missing", keyID)))
+			})
+
+			It("returns successfully", func() {
+				Expect(cfg.Validate()).To(Succeed())
+			})
+		})
+	})
+})
@@ -22,6 +22,7 @@ type Client interface {
 
 	CreateDataSetsData(ctx context.Context, dataSetID string, datumArray []data.Datum) error
 
+	ListUserData(ctx context.Context, userID string, filter *data.DatumFilter, pagination *page.Pagination) ([]data.Blob, error)
 	DestroyDataForUserByID(ctx context.Context, userID string) error
 }
 
@@ -283,6 +284,38 @@ func (c *ClientImpl) CreateDataSetsData(ctx context.Context, dataSetID string, d
 	return c.client.RequestData(ctx, http.MethodPost, url, nil, datumArray, &response)
 }
 
+func (c *ClientImpl) ListUserData(ctx context.Context, userID string, filter *data.DatumFilter, pagination *page.Pagination) ([]data.Blob, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+	if filter == nil {
+		filter = data.NewDatumFilter()
+	} else if err := structureValidator.New().Validate(filter); err != nil {
+		return nil, errors.Wrap(err, "filter is invalid")
+	}
+	if pagination == nil {
+		pagination = page.NewPagination()
+	} else if err := structureValidator.New().Validate(pagination); err != nil {
+		return nil, errors.Wrap(err, "pagination is invalid")
+	}
+
+	url := c.client.ConstructURL("v1", "users", userID, "data")
+	response := struct {
+		Data []data.Blob `json:"data,omitempty"`
+	}{}
+	if err := c.client.RequestData(ctx, http.MethodGet, url, []request.RequestMutator{filter, pagination}, nil, &response); err != nil {
+		return nil, err
+	}
+	if response.Data == nil {
+		response.Data = []data.Blob{}
+	}
+
+	return response.Data, nil
+}
+
 // TODO: Rename for consistency
 
 func (c *ClientImpl) DestroyDataForUserByID(ctx context.Context, userID string) error {
@@ -10,11 +10,14 @@ import (
 	"net/http"
 
 	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/data"
 	dataClient "github.com/tidepool-org/platform/data/client"
 	dataTest "github.com/tidepool-org/platform/data/test"
 	"github.com/tidepool-org/platform/log"
 	logNull "github.com/tidepool-org/platform/log/null"
+	"github.com/tidepool-org/platform/page"
 	"github.com/tidepool-org/platform/platform"
+	"github.com/tidepool-org/platform/pointer"
 	testHTTP "github.com/tidepool-org/platform/test/http"
 	"github.com/tidepool-org/platform/user"
 )
@@ -83,6 +86,103 @@ var _ = Describe("Client", func() {
 			}
 		})
 
+		Context("ListUserData", func() {
+			var userID string
+			var filter *data.DatumFilter
+			var pagination *page.Pagination
+
+			BeforeEach(func() {
+				userID = user.NewID()
+				filter = data.NewDatumFilter()
+				filter.Types = pointer.FromStringArray([]string{"cbg", "smbg"})
+				pagination = page.NewPagination()
+				pagination.Page = 1
+				pagination.Size = 10
+			})
+
+			It("returns error if context is missing", func() {
+				blobs, err := clnt.ListUserData(nil, userID, filter, pagination)
+				Expect(err).To(MatchError("context is missing"))
+				Expect(blobs).To(BeNil())
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+
+			It("returns error if user id is missing", func() {
+				blobs, err := clnt.ListUserData(ctx, "", filter, pagination)
+				Expect(err).To(MatchError("user id is missing"))
+				Expect(blobs).To(BeNil())
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+
+			It("returns error if filter is invalid", func() {
+				filter.Types = pointer.FromStringArray([]string{})
+				blobs, err := clnt.ListUserData(ctx, userID, filter, pagination)
+				Expect(err).To(MatchError("filter is invalid; value is empty"))
+				Expect(blobs).To(BeNil())
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+
+			It("returns error if pagination is invalid", func() {
+				pagination.Size = 0
+				blobs, err := clnt.ListUserData(ctx, userID, filter, pagination)
+				Expect(err).To(MatchError("pagination is invalid; value 0 is not between 1 and 100"))
+				Expect(blobs).To(BeNil())
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+
+			Context("with server token", func() {
+				var token string
+
+				BeforeEach(func() {
+					token = dataTest.NewSessionToken()
+					ctx = auth.NewContextWithServerSessionToken(ctx, token)
+				})
+
+				Context("with an unauthorized response", func() {
+					BeforeEach(func() {
+						server.AppendHandlers(
+							CombineHandlers(
+								VerifyRequest("GET", fmt.Sprintf("/v1/users/%s/data", userID), "type=cbg&type=smbg&page=1&size=10"),
+								VerifyHeaderKV("User-Agent", userAgent),
+								VerifyHeaderKV("X-Tidepool-Session-Token", token),
+								VerifyBody(nil),
+								RespondWith(http.StatusUnauthorized, nil)),
+						)
+					})
+
+					It("returns an error", func() {
+						blobs, err := clnt.ListUserData(ctx, userID, filter, pagination)
+						Expect(err).To(MatchError("authentication token is invalid"))
+						Expect(blobs).To(BeNil())
+						Expect(server.ReceivedRequests()).To(HaveLen(1))
+					})
+				})
+
+				Context("with a successful response", func() {
+					BeforeEach(func() {
+						server.AppendHandlers(
+							CombineHandlers(
+								VerifyRequest("GET", fmt.Sprintf("/v1/users/%s/data", userID), "type=cbg&type=smbg&page=1&size=10"),
+								VerifyHeaderKV("User-Agent", userAgent),
+								VerifyHeaderKV("X-Tidepool-Session-Token", token),
+								VerifyBody(nil),
+								RespondWith(http.StatusOK, `{"data": [{"type": "cbg", "value": 120}, {"type": "smbg", "value": 140}], "meta": {}}`)),
+						)
+					})
+
+					It("returns the data", func() {
+						blobs, err := clnt.ListUserData(ctx, userID, filter, pagination)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(blobs).To(Equal([]data.Blob{
+							{"type": "cbg", "value": float64(120)},
+							{"type": "smbg", "value": float64(140)},
+						}))
+						Expect(server.ReceivedRequests()).To(HaveLen(1))
+					})
+				})
+			})
+		})
+
 		Context("DestroyDataForUserByID", func() {
 			var userID string
 
@@ -0,0 +1,127 @@
+package test
+
+import (
+	"context"
+
+	"github.com/tidepool-org/platform/data"
+	dataTest "github.com/tidepool-org/platform/data/test"
+	"github.com/tidepool-org/platform/page"
+)
+
+type CreateDataSetsDataInput struct {
+	Context    context.Context
+	DataSetID  string
+	DatumArray []data.Datum
+}
+
+type ListUserDataInput struct {
+	Context    context.Context
+	UserID     string
+	Filter     *data.DatumFilter
+	Pagination *page.Pagination
+}
+
+type ListUserDataOutput struct {
+	Data  []data.Blob
+	Error error
+}
+
+type DestroyDataForUserByIDInput struct {
+	Context context.Context
+	UserID  string
+}
+
+type Client struct {
+	*dataTest.DataSourceAccessor
+	*dataTest.DataSetAccessor
+	CreateDataSetsDataInvocations     int
+	CreateDataSetsDataInputs          []CreateDataSetsDataInput
+	CreateDataSetsDataStub            func(ctx context.Context, dataSetID string, datumArray []data.Datum) error
+	CreateDataSetsDataOutputs         []error
+	CreateDataSetsDataOutput          *error
+	ListUserDataInvocations           int
+	ListUserDataInputs                []ListUserDataInput
+	ListUserDataStub                  func(ctx context.Context, userID string, filter *data.DatumFilter, pagination *page.Pagination) ([]data.Blob, error)
+	ListUserDataOutputs               []ListUserDataOutput
+	ListUserDataOutput                *ListUserDataOutput
+	DestroyDataForUserByIDInvocations int
+	DestroyDataForUserByIDInputs      []DestroyDataForUserByIDInput
+	DestroyDataForUserByIDStub        func(ctx context.Context, userID string) error
+	DestroyDataForUserByIDOutputs     []error
+	DestroyDataForUserByIDOutput      *error
+}
+
+func NewClient() *Client {
+	return &Client{
+		DataSourceAccessor: dataTest.NewDataSourceAccessor(),
+		DataSetAccessor:    dataTest.NewDataSetAccessor(),
+	}
+}
+
+func (c *Client) CreateDataSetsData(ctx context.Context, dataSetID string, datumArray []data.Datum) error {
+	c.CreateDataSetsDataInvocations++
+	c.CreateDataSetsDataInputs = append(c.CreateDataSetsDataInputs, CreateDataSetsDataInput{Context: ctx, DataSetID: dataSetID, DatumArray: datumArray})
+	if c.CreateDataSetsDataStub != nil {
+		return c.CreateDataSetsDataStub(ctx, dataSetID, datumArray)
+	}
+	if len(c.CreateDataSetsDataOutputs) > 0 {
+		output := c.CreateDataSetsDataOutputs[0]
+		c.CreateDataSetsDataOutputs = c.CreateDataSetsDataOutputs[1:]
+		return output
+	}
+	if c.CreateDataSetsDataOutput != nil {
+		return *c.CreateDataSetsDataOutput
+	}
+	panic("CreateDataSetsData has no output")
+}
+
+func (c *Client) ListUserData(ctx context.Context, userID string, filter *data.DatumFilter, pagination *page.Pagination) ([]data.Blob, error) {
+	c.ListUserDataInvocations++
+	c.ListUserDataInputs = append(c.ListUserDataInputs, ListUserDataInput{Context: ctx, UserID: userID, Filter: filter, Pagination: pagination})
+	if c.ListUserDataStub != nil {
+		return c.ListUserDataStub(ctx, userID, filter, pagination)
+	}
+	if len(c.ListUserDataOutputs) > 0 {
+		output := c.ListUserDataOutputs[0]
+		c.ListUserDataOutputs = c.ListUserDataOutputs[1:]
+		return output.Data, output.Error
+	}
+	if c.ListUserDataOutput != nil {
+		return c.ListUserDataOutput.Data, c.ListUserDataOutput.Error
+	}
+	panic("ListUserData has no output")
+}
+
+func (c *Client) DestroyDataForUserByID(ctx context.Context, userID string) error {
+	c.DestroyDataForUserByIDInvocations++
+	c.DestroyDataForUserByIDInputs = append(c.DestroyDataForUserByIDInputs, DestroyDataForUserByIDInput{Context: ctx, UserID: userID})
+	if c.DestroyDataForUserByIDStub != nil {
+		return c.DestroyDataForUserByIDStub(ctx, userID)
+	}
+	if len(c.ListUserDataOutputs) > 0 {
+		panic("ListUserDataOutputs is not empty")
+	}
+	if len(c.DestroyDataForUserByIDOutputs) > 0 {
+		output := c.DestroyDataForUserByIDOutputs[0]
+		c.DestroyDataForUserByIDOutputs = c.DestroyDataForUserByIDOutputs[1:]
+		return output
+	}
+	if c.DestroyDataForUserByIDOutput != nil {
+		return *c.DestroyDataForUserByIDOutput
+	}
+	panic("DestroyDataForUserByID has no output")
+}
+
+func (c *Client) AssertOutputsEmpty() {
+	c.DataSourceAccessor.AssertOutputsEmpty()
+	c.DataSetAccessor.AssertOutputsEmpty()
+	if len(c.CreateDataSetsDataOutputs) > 0 {
+		panic("CreateDataSetsDataOutputs is not empty")
+	}
+	if len(c.ListUserDataOutputs) > 0 {
+		panic("ListUserDataOutputs is not empty")
+	}
+	if len(c.DestroyDataForUserByIDOutputs) > 0 {
+		panic("DestroyDataForUserByIDOutputs is not empty")
+	}
+}
@@ -31,6 +31,11 @@ const (
 	DataSourceStateError        = "error"
 )
 
+const (
+	DataSourceBackfillProgressMaximum = 100.0
+	DataSourceBackfillProgressMinimum = 0.0
+)
+
 func DataSourceStates() []string {
 	return []string{
 		DataSourceStateConnected,
@@ -114,13 +119,16 @@ func (d *DataSourceCreate) Validate(validator structure.Validator) {
 	validator.String("state", &d.State).OneOf(DataSourceStates()...)
 }
 
+// Reset clears the data times, last import time, backfill, and error so the data source is fetched from scratch
 type DataSourceUpdate struct {
+	Reset            *bool                `json:"reset,omitempty" bson:"reset,omitempty"`
 	State            *string              `json:"state,omitempty" bson:"state,omitempty"`
 	Error            *errors.Serializable `json:"error,omitempty" bson:"error,omitempty"`
 	DataSetIDs       *[]string            `json:"dataSetIds,omitempty" bson:"dataSetIds,omitempty"`
 	EarliestDataTime *time.Time           `json:"earliestDataTime,omitempty" bson:"earliestDataTime,omitempty"`
 	LatestDataTime   *time.Time           `json:"latestDataTime,omitempty" bson:"latestDataTime,omitempty"`
 	LastImportTime   *time.Time           `json:"lastImportTime,omitempty" bson:"lastImportTime,omitempty"`
+	Backfill         *DataSourceBackfill  `json:"backfill,omitempty" bson:"backfill,omitempty"`
 }
 
 func NewDataSourceUpdate() *DataSourceUpdate {
@@ -128,10 +136,11 @@ func NewDataSourceUpdate() *DataSourceUpdate {
 }
 
 func (d *DataSourceUpdate) HasUpdates() bool {
-	return d.State != nil || d.Error != nil || d.DataSetIDs != nil || d.EarliestDataTime != nil || d.LatestDataTime != nil || d.LastImportTime != nil
+	return d.Reset != nil || d.State != nil || d.Error != nil || d.DataSetIDs != nil || d.EarliestDataTime != nil || d.LatestDataTime != nil || d.LastImportTime != nil || d.Backfill != nil
 }
 
 func (d *DataSourceUpdate) Parse(parser structure.ObjectParser) {
+	d.Reset = parser.Bool("reset")
 	d.State = parser.String("state")
 	if parser.ReferenceExists("error") {
 		d.Error = &errors.Serializable{}
@@ -141,6 +150,11 @@ func (d *DataSourceUpdate) Parse(parser structure.ObjectParser) {
 	d.EarliestDataTime = parser.Time("earliestDataTime", time.RFC3339)
 	d.LatestDataTime = parser.Time("latestDataTime", time.RFC3339)
 	d.LastImportTime = parser.Time("lastImportTime", time.RFC3339)
+	if backfillParser := parser.WithReferenceObjectParser("backfill"); backfillParser.Exists() {
+		d.Backfill = NewDataSourceBackfill()
+		d.Backfill.Parse(backfillParser)
+		backfillParser.NotParsed()
+	}
 }
 
 func (d *DataSourceUpdate) Validate(validator structure.Validator) {
@@ -156,12 +170,35 @@ func (d *DataSourceUpdate) Validate(validator structure.Validator) {
 		validator.Time("latestDataTime", d.LatestDataTime).NotZero().BeforeNow(time.Second)
 	}
 	validator.Time("lastImportTime", d.LastImportTime).NotZero().BeforeNow(time.Second)
+	if d.Backfill != nil {
+		d.Backfill.Validate(validator.WithReference("backfill"))
+	}
+	if d.Reset != nil && *d.Reset {
+		if d.Error != nil {
+			validator.WithReference("error").ReportError(structureValidator.ErrorValueExists())
+		}
+		if d.EarliestDataTime != nil {
+			validator.WithReference("earliestDataTime").ReportError(structureValidator.ErrorValueExists())
+		}
+		if d.LatestDataTime != nil {
+			validator.WithReference("latestDataTime").ReportError(structureValidator.ErrorValueExists())
+		}
+		if d.LastImportTime != nil {
+			validator.WithReference("lastImportTime").ReportError(structureValidator.ErrorValueExists())
+		}
+		if d.Backfill != nil {
+			validator.WithReference("backfill").ReportError(structureValidator.ErrorValueExists())
+		}
+	}
 }
 
 func (d *DataSourceUpdate) Normalize(normalizer structure.Normalizer) {
 	if d.Error != nil {
 		d.Error.Normalize(normalizer.WithReference("error"))
 	}
+	if d.Backfill != nil {
+		d.Backfill.Normalize(normalizer.WithReference("backfill"))
+	}
 	if d.EarliestDataTime != nil {
 		d.EarliestDataTime = pointer.FromTime((*d.EarliestDataTime).Truncate(time.Second))
 	}
@@ -173,6 +210,67 @@ func (d *DataSourceUpdate) Normalize(normalizer structure.Normalizer) {
 	}
 }
 
+// DataSourceBackfill tracks the progress of a historical backfill of a data source. The time range
+// covered so far is from StartTime through LastWindowEndTime and the target is EndTime.
+type DataSourceBackfill struct {
+	StartTime           time.Time  `json:"startTime" bson:"startTime"`
+	EndTime             time.Time  `json:"endTime" bson:"endTime"`
+	Progress            float64    `json:"progress" bson:"progress"`
+	LastWindowStartTime *time.Time `json:"lastWindowStartTime,omitempty" bson:"lastWindowStartTime,omitempty"`
+	LastWindowEndTime   *time.Time `json:"lastWindowEndTime,omitempty" bson:"lastWindowEndTime,omitempty"`
+	CompletedTime       *time.Time `json:"completedTime,omitempty" bson:"completedTime,omitempty"`
+}
+
+func NewDataSourceBackfill() *DataSourceBackfill {
+	return &DataSourceBackfill{}
+}
+
+func (d *DataSourceBackfill) IsCompleted() bool {
+	return d.CompletedTime != nil
+}
+
+func (d *DataSourceBackfill) Parse(parser structure.ObjectParser) {
+	if ptr := parser.Time("startTime", time.RFC3339); ptr != nil {
+		d.StartTime = *ptr
+	}
+	if ptr := parser.Time("endTime", time.RFC3339); ptr != nil {
+		d.EndTime = *ptr
+	}
+	if ptr := parser.Float64("progress"); ptr != nil {
+		d.Progress = *ptr
+	}
+	d.LastWindowStartTime = parser.Time("lastWindowStartTime", time.RFC3339)
+	d.LastWindowEndTime = parser.Time("lastWindowEndTime", time.RFC3339)
+	d.CompletedTime = parser.Time("completedTime", time.RFC3339)
+}
+
+func (d *DataSourceBackfill) Validate(validator structure.Validator) {
+	validator.Time("startTime", &d.StartTime).NotZero()
+	validator.Time("endTime", &d.EndTime).After(d.StartTime)
+	validator.Float64("progress", &d.Progress).InRange(DataSourceBackfillProgressMinimum, DataSourceBackfillProgressMaximum)
+	validator.Time("lastWindowStartTime", d.LastWindowStartTime).NotZero()
+	if d.LastWindowStartTime != nil {
+		validator.Time("lastWindowEndTime", d.LastWindowEndTime).Exists().After(*d.LastWindowStartTime)
+	} else {
+		validator.Time("lastWindowEndTime", d.LastWindowEndTime).NotExists()
+	}
+	validator.Time("completedTime", d.CompletedTime).NotZero().BeforeNow(time.Second)
+}
+
+func (d *DataSourceBackfill) Normalize(normalizer structure.Normalizer) {
+	d.StartTime = d.StartTime.Truncate(time.Second)
+	d.EndTime = d.EndTime.Truncate(time.Second)
+	if d.LastWindowStartTime != nil {
+		d.LastWindowStartTime = pointer.FromTime((*d.LastWindowStartTime).Truncate(time.Second))
+	}
+	if d.LastWindowEndTime != nil {
+		d.LastWindowEndTime = pointer.FromTime((*d.LastWindowEndTime).Truncate(time.Second))
+	}
+	if d.CompletedTime != nil {
+		d.CompletedTime = pointer.FromTime((*d.CompletedTime).Truncate(time.Second))
+	}
+}
+
 func NewSourceID() string {
 	return id.Must(id.New(16))
 }
@@ -212,6 +310,7 @@ type DataSource struct {
 	EarliestDataTime  *time.Time           `json:"earliestDataTime,omitempty" bson:"earliestDataTime,omitempty"`
 	LatestDataTime    *time.Time           `json:"latestDataTime,omitempty" bson:"latestDataTime,omitempty"`
 	LastImportTime    *time.Time           `json:"lastImportTime,omitempty" bson:"lastImportTime,omitempty"`
+	Backfill          *DataSourceBackfill  `json:"backfill,omitempty" bson:"backfill,omitempty"`
 	CreatedTime       time.Time            `json:"createdTime" bson:"createdTime"`
 	ModifiedTime      *time.Time           `json:"modifiedTime,omitempty" bson:"modifiedTime,omitempty"`
 }
@@ -264,6 +363,11 @@ func (d *DataSource) Parse(parser structure.ObjectParser) {
 	d.EarliestDataTime = parser.Time("earliestDataTime", time.RFC3339)
 	d.LatestDataTime = parser.Time("latestDataTime", time.RFC3339)
 	d.LastImportTime = parser.Time("lastImportTime", time.RFC3339)
+	if backfillParser := parser.WithReferenceObjectParser("backfill"); backfillParser.Exists() {
+		d.Backfill = NewDataSourceBackfill()
+		d.Backfill.Parse(backfillParser)
+		backfillParser.NotParsed()
+	}
 	if ptr := parser.Time("createdTime", time.RFC3339); ptr != nil {
 		d.CreatedTime = *ptr
 	}
@@ -288,6 +392,9 @@ func (d *DataSource) Validate(validator structure.Validator) {
 		validator.Time("latestDataTime", d.LatestDataTime).NotZero().BeforeNow(time.Second)
 	}
 	validator.Time("lastImportTime", d.LastImportTime).NotZero().BeforeNow(time.Second)
+	if d.Backfill != nil {
+		d.Backfill.Validate(validator.WithReference("backfill"))
+	}
 	validator.Time("createdTime", &d.CreatedTime).NotZero().BeforeNow(time.Second)
 	validator.Time("modifiedTime", d.ModifiedTime).After(d.CreatedTime).BeforeNow(time.Second)
 }
@@ -296,6 +403,9 @@ func (d *DataSource) Normalize(normalizer structure.Normalizer) {
 	if d.Error != nil {
 		d.Error.Normalize(normalizer.WithReference("error"))
 	}
+	if d.Backfill != nil {
+		d.Backfill.Normalize(normalizer.WithReference("backfill"))
+	}
 }
 
 func (d *DataSource) Sanitize(details request.Details) error {
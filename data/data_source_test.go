@@ -5,14 +5,137 @@ import (
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
+	"time"
+
 	"github.com/tidepool-org/platform/data"
 	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/pointer"
 	structureTest "github.com/tidepool-org/platform/structure/test"
 	structureValidator "github.com/tidepool-org/platform/structure/validator"
 	"github.com/tidepool-org/platform/test"
 )
 
 var _ = Describe("DataSource", func() {
+	Context("DataSourceBackfill", func() {
+		var backfill *data.DataSourceBackfill
+
+		BeforeEach(func() {
+			startTime := time.Now().Add(-30 * 24 * time.Hour).Truncate(time.Second)
+			backfill = data.NewDataSourceBackfill()
+			backfill.StartTime = startTime
+			backfill.EndTime = startTime.Add(20 * 24 * time.Hour)
+			backfill.Progress = 50
+			backfill.LastWindowStartTime = pointer.FromTime(startTime)
+			backfill.LastWindowEndTime = pointer.FromTime(startTime.Add(10 * 24 * time.Hour))
+		})
+
+		Context("IsCompleted", func() {
+			It("returns false if the completed time is missing", func() {
+				Expect(backfill.IsCompleted()).To(BeFalse())
+			})
+
+			It("returns true if the completed time is present", func() {
+				backfill.CompletedTime = pointer.FromTime(time.Now())
+				Expect(backfill.IsCompleted()).To(BeTrue())
+			})
+		})
+
+		Context("Validate", func() {
+			It("returns successfully if valid", func() {
+				Expect(structureValidator.New().Validate(backfill)).ToNot(HaveOccurred())
+			})
+
+			It("returns successfully if valid without last window", func() {
+				backfill.LastWindowStartTime = nil
+				backfill.LastWindowEndTime = nil
+				Expect(structureValidator.New().Validate(backfill)).ToNot(HaveOccurred())
+			})
+
+			It("returns an error if the start time is zero", func() {
+				backfill.StartTime = time.Time{}
+				Expect(structureValidator.New().Validate(backfill)).To(HaveOccurred())
+			})
+
+			It("returns an error if the end time is before the start time", func() {
+				backfill.EndTime = backfill.StartTime.Add(-time.Second)
+				Expect(structureValidator.New().Validate(backfill)).To(HaveOccurred())
+			})
+
+			It("returns an error if the progress is out of range (lower)", func() {
+				backfill.Progress = -0.1
+				Expect(structureValidator.New().Validate(backfill)).To(HaveOccurred())
+			})
+
+			It("returns an error if the progress is out of range (upper)", func() {
+				backfill.Progress = 100.1
+				Expect(structureValidator.New().Validate(backfill)).To(HaveOccurred())
+			})
+
+			It("returns an error if the last window end time is missing", func() {
+				backfill.LastWindowEndTime = nil
+				Expect(structureValidator.New().Validate(backfill)).To(HaveOccurred())
+			})
+
+			It("returns an error if the last window end time is present without the last window start time", func() {
+				backfill.LastWindowStartTime = nil
+				Expect(structureValidator.New().Validate(backfill)).To(HaveOccurred())
+			})
+
+			It("returns an error if the completed time is in the future", func() {
+				backfill.CompletedTime = pointer.FromTime(time.Now().Add(time.Hour))
+				Expect(structureValidator.New().Validate(backfill)).To(HaveOccurred())
+			})
+		})
+	})
+
+	Context("DataSourceUpdate", func() {
+		Context("HasUpdates", func() {
+			It("returns false if there are no updates", func() {
+				Expect(data.NewDataSourceUpdate().HasUpdates()).To(BeFalse())
+			})
+
+			It("returns true if there is a backfill update", func() {
+				dataSourceUpdate := data.NewDataSourceUpdate()
+				dataSourceUpdate.Backfill = data.NewDataSourceBackfill()
+				Expect(dataSourceUpdate.HasUpdates()).To(BeTrue())
+			})
+
+			It("returns true if there is a reset update", func() {
+				dataSourceUpdate := data.NewDataSourceUpdate()
+				dataSourceUpdate.Reset = pointer.FromBool(true)
+				Expect(dataSourceUpdate.HasUpdates()).To(BeTrue())
+			})
+		})
+
+		Context("Validate", func() {
+			var dataSourceUpdate *data.DataSourceUpdate
+
+			BeforeEach(func() {
+				dataSourceUpdate = data.NewDataSourceUpdate()
+				dataSourceUpdate.Reset = pointer.FromBool(true)
+			})
+
+			It("returns successfully if reset is the only update", func() {
+				Expect(structureValidator.New().Validate(dataSourceUpdate)).ToNot(HaveOccurred())
+			})
+
+			It("returns successfully if reset is combined with data set ids", func() {
+				dataSourceUpdate.DataSetIDs = pointer.FromStringArray([]string{data.NewSetID()})
+				Expect(structureValidator.New().Validate(dataSourceUpdate)).ToNot(HaveOccurred())
+			})
+
+			It("returns an error if reset is combined with latest data time", func() {
+				dataSourceUpdate.LatestDataTime = pointer.FromTime(time.Now().Add(-time.Hour))
+				Expect(structureValidator.New().Validate(dataSourceUpdate)).To(HaveOccurred())
+			})
+
+			It("returns an error if reset is combined with last import time", func() {
+				dataSourceUpdate.LastImportTime = pointer.FromTime(time.Now().Add(-time.Hour))
+				Expect(structureValidator.New().Validate(dataSourceUpdate)).To(HaveOccurred())
+			})
+		})
+	})
+
 	Context("NewSourceID", func() {
 		It("returns a string of 32 lowercase hexidecimal characters", func() {
 			Expect(data.NewSourceID()).To(MatchRegexp("^[0-9a-f]{32}$"))
@@ -0,0 +1,98 @@
+package data
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/structure"
+	"github.com/tidepool-org/platform/user"
+)
+
+// DatumFilter filters data by type and by time, where the start time is inclusive and the end time is exclusive
+type DatumFilter struct {
+	Types     *[]string
+	StartTime *time.Time
+	EndTime   *time.Time
+	HasTime   *bool
+}
+
+func NewDatumFilter() *DatumFilter {
+	return &DatumFilter{}
+}
+
+func (d *DatumFilter) Parse(parser structure.ObjectParser) {
+	d.Types = parser.StringArray("type")
+	d.StartTime = parser.Time("startTime", TimeFormat)
+	d.EndTime = parser.Time("endTime", TimeFormat)
+	d.HasTime = parser.Bool("hasTime")
+}
+
+func (d *DatumFilter) Validate(validator structure.Validator) {
+	validator.StringArray("type", d.Types).NotEmpty().EachNotEmpty().EachUnique()
+	if d.StartTime != nil {
+		validator.Time("endTime", d.EndTime).After(*d.StartTime)
+	}
+	if d.HasTime != nil && !*d.HasTime {
+		validator.Time("startTime", d.StartTime).NotExists()
+		validator.Time("endTime", d.EndTime).NotExists()
+	}
+}
+
+func (d *DatumFilter) MutateRequest(req *http.Request) error {
+	parameters := map[string][]string{}
+	if d.Types != nil {
+		parameters["type"] = *d.Types
+	}
+	if d.StartTime != nil {
+		parameters["startTime"] = []string{d.StartTime.Format(TimeFormat)}
+	}
+	if d.EndTime != nil {
+		parameters["endTime"] = []string{d.EndTime.Format(TimeFormat)}
+	}
+	if d.HasTime != nil {
+		parameters["hasTime"] = []string{strconv.FormatBool(*d.HasTime)}
+	}
+	return request.NewArrayParametersMutator(parameters).MutateRequest(req)
+}
+
+// Restrict narrows the filter to the data allowed by the permission restrictions. Returns false if no data is
+// allowed. Data without a time is never within a restricted time window.
+func (d *DatumFilter) Restrict(restrictions *user.PermissionRestrictions) bool {
+	if restrictions == nil {
+		return true
+	}
+
+	if restrictions.DataTypes != nil {
+		types := []string{}
+		if d.Types == nil {
+			types = append(types, *restrictions.DataTypes...)
+		} else {
+			for _, typ := range *d.Types {
+				if restrictions.IsDataTypeAllowed(typ) {
+					types = append(types, typ)
+				}
+			}
+		}
+		if len(types) == 0 {
+			return false
+		}
+		d.Types = &types
+	}
+
+	if restrictions.StartTime != nil && (d.StartTime == nil || d.StartTime.Before(*restrictions.StartTime)) {
+		startTime := *restrictions.StartTime
+		d.StartTime = &startTime
+	}
+	if restrictions.EndTime != nil && (d.EndTime == nil || d.EndTime.After(*restrictions.EndTime)) {
+		endTime := *restrictions.EndTime
+		d.EndTime = &endTime
+	}
+
+	if d.HasTime != nil && !*d.HasTime && (d.StartTime != nil || d.EndTime != nil) {
+		return false
+	}
+
+	return d.StartTime == nil || d.EndTime == nil || d.StartTime.Before(*d.EndTime)
+}
@@ -0,0 +1,181 @@
+package data_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/tidepool-org/platform/data"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/pointer"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+	testHTTP "github.com/tidepool-org/platform/test/http"
+	"github.com/tidepool-org/platform/user"
+)
+
+var _ = Describe("DatumFilter", func() {
+	var startTime time.Time
+
+	BeforeEach(func() {
+		startTime = time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	Context("Validate", func() {
+		DescribeTable("validates the datum filter",
+			func(mutator func(filter *data.DatumFilter), expectedErrors ...error) {
+				filter := data.NewDatumFilter()
+				mutator(filter)
+				errorsTest.ExpectEqual(structureValidator.New().Validate(filter), expectedErrors...)
+			},
+			Entry("succeeds",
+				func(filter *data.DatumFilter) {},
+			),
+			Entry("types valid",
+				func(filter *data.DatumFilter) { filter.Types = pointer.FromStringArray([]string{"cbg", "smbg"}) },
+			),
+			Entry("types empty",
+				func(filter *data.DatumFilter) { filter.Types = pointer.FromStringArray([]string{}) },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueEmpty(), "/type"),
+			),
+			Entry("types element empty",
+				func(filter *data.DatumFilter) { filter.Types = pointer.FromStringArray([]string{"cbg", ""}) },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueEmpty(), "/type/1"),
+			),
+			Entry("types duplicate",
+				func(filter *data.DatumFilter) { filter.Types = pointer.FromStringArray([]string{"cbg", "cbg"}) },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueDuplicate(), "/type/1"),
+			),
+			Entry("end time after start time",
+				func(filter *data.DatumFilter) {
+					filter.StartTime = pointer.FromTime(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+					filter.EndTime = pointer.FromTime(time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC))
+				},
+			),
+			Entry("end time before start time",
+				func(filter *data.DatumFilter) {
+					filter.StartTime = pointer.FromTime(time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC))
+					filter.EndTime = pointer.FromTime(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+				},
+				errorsTest.WithPointerSource(structureValidator.ErrorValueTimeNotAfter(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC)), "/endTime"),
+			),
+			Entry("has time false",
+				func(filter *data.DatumFilter) { filter.HasTime = pointer.FromBool(false) },
+			),
+			Entry("has time false with start time and end time",
+				func(filter *data.DatumFilter) {
+					filter.StartTime = pointer.FromTime(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+					filter.EndTime = pointer.FromTime(time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC))
+					filter.HasTime = pointer.FromBool(false)
+				},
+				errorsTest.WithPointerSource(structureValidator.ErrorValueExists(), "/startTime"),
+				errorsTest.WithPointerSource(structureValidator.ErrorValueExists(), "/endTime"),
+			),
+			Entry("has time true with start time and end time",
+				func(filter *data.DatumFilter) {
+					filter.StartTime = pointer.FromTime(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+					filter.EndTime = pointer.FromTime(time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC))
+					filter.HasTime = pointer.FromBool(true)
+				},
+			),
+		)
+	})
+
+	Context("MutateRequest", func() {
+		var req *http.Request
+
+		BeforeEach(func() {
+			req = testHTTP.NewRequest()
+		})
+
+		It("does not set request query when the filter is empty", func() {
+			Expect(data.NewDatumFilter().MutateRequest(req)).To(Succeed())
+			Expect(req.URL.Query()).To(BeEmpty())
+		})
+
+		It("sets request query from the filter", func() {
+			filter := data.NewDatumFilter()
+			filter.Types = pointer.FromStringArray([]string{"cbg", "smbg"})
+			filter.StartTime = pointer.FromTime(startTime)
+			filter.EndTime = pointer.FromTime(startTime.Add(time.Hour))
+			filter.HasTime = pointer.FromBool(true)
+			Expect(filter.MutateRequest(req)).To(Succeed())
+			Expect(req.URL.Query()).To(Equal(url.Values{
+				"type":      []string{"cbg", "smbg"},
+				"startTime": []string{"2018-01-01T00:00:00Z"},
+				"endTime":   []string{"2018-01-01T01:00:00Z"},
+				"hasTime":   []string{"true"},
+			}))
+		})
+	})
+
+	Context("Restrict", func() {
+		var filter *data.DatumFilter
+		var restrictions *user.PermissionRestrictions
+
+		BeforeEach(func() {
+			filter = data.NewDatumFilter()
+			restrictions = user.NewPermissionRestrictions()
+		})
+
+		It("returns true without changes if the restrictions are missing", func() {
+			Expect(filter.Restrict(nil)).To(BeTrue())
+			Expect(filter).To(Equal(data.NewDatumFilter()))
+		})
+
+		It("returns true without changes if there are no restrictions", func() {
+			Expect(filter.Restrict(restrictions)).To(BeTrue())
+			Expect(filter).To(Equal(data.NewDatumFilter()))
+		})
+
+		It("uses the restricted data types if the filter has no types", func() {
+			restrictions.DataTypes = pointer.FromStringArray([]string{"cbg", "smbg"})
+			Expect(filter.Restrict(restrictions)).To(BeTrue())
+			Expect(filter.Types).To(Equal(pointer.FromStringArray([]string{"cbg", "smbg"})))
+		})
+
+		It("removes types that are not allowed", func() {
+			filter.Types = pointer.FromStringArray([]string{"cbg", "pumpSettings"})
+			restrictions.DataTypes = pointer.FromStringArray([]string{"cbg", "smbg"})
+			Expect(filter.Restrict(restrictions)).To(BeTrue())
+			Expect(filter.Types).To(Equal(pointer.FromStringArray([]string{"cbg"})))
+		})
+
+		It("returns false if no types are allowed", func() {
+			filter.Types = pointer.FromStringArray([]string{"pumpSettings"})
+			restrictions.DataTypes = pointer.FromStringArray([]string{"cbg"})
+			Expect(filter.Restrict(restrictions)).To(BeFalse())
+		})
+
+		It("narrows the time window to the restricted time window", func() {
+			filter.StartTime = pointer.FromTime(startTime)
+			filter.EndTime = pointer.FromTime(startTime.Add(48 * time.Hour))
+			restrictions.StartTime = pointer.FromTime(startTime.Add(24 * time.Hour))
+			restrictions.EndTime = pointer.FromTime(startTime.Add(72 * time.Hour))
+			Expect(filter.Restrict(restrictions)).To(BeTrue())
+			Expect(filter.StartTime).To(Equal(pointer.FromTime(startTime.Add(24 * time.Hour))))
+			Expect(filter.EndTime).To(Equal(pointer.FromTime(startTime.Add(48 * time.Hour))))
+		})
+
+		It("returns false for data without a time if there is a restricted time window", func() {
+			filter.HasTime = pointer.FromBool(false)
+			restrictions.StartTime = pointer.FromTime(startTime)
+			Expect(filter.Restrict(restrictions)).To(BeFalse())
+		})
+
+		It("returns true for data without a time if there is no restricted time window", func() {
+			filter.HasTime = pointer.FromBool(false)
+			restrictions.DataTypes = pointer.FromStringArray([]string{"cbg"})
+			Expect(filter.Restrict(restrictions)).To(BeTrue())
+		})
+
+		It("returns false if the time windows do not overlap", func() {
+			filter.EndTime = pointer.FromTime(startTime)
+			restrictions.StartTime = pointer.FromTime(startTime)
+			Expect(filter.Restrict(restrictions)).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,53 @@
+package notifier
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/tidepool-org/platform/config"
+	"github.com/tidepool-org/platform/errors"
+)
+
+const DebounceDurationDefault = 24 * time.Hour
+
+// Address is the external address of the auth service used to construct the link to reconnect a data source
+type Config struct {
+	Address          string
+	DebounceDuration time.Duration
+}
+
+func NewConfig() *Config {
+	return &Config{
+		DebounceDuration: DebounceDurationDefault,
+	}
+}
+
+func (c *Config) Load(configReporter config.Reporter) error {
+	if configReporter == nil {
+		return errors.New("config reporter is missing")
+	}
+
+	c.Address = configReporter.GetWithDefault("address", c.Address)
+	if debounceDurationString, err := configReporter.Get("debounce_duration"); err == nil {
+		debounceDuration, err := time.ParseDuration(debounceDurationString)
+		if err != nil {
+			return errors.New("debounce duration is invalid")
+		}
+		c.DebounceDuration = debounceDuration
+	}
+
+	return nil
+}
+
+func (c *Config) Validate() error {
+	if c.Address == "" {
+		return errors.New("address is missing")
+	} else if _, err := url.Parse(c.Address); err != nil {
+		return errors.New("address is invalid")
+	}
+	if c.DebounceDuration < 0 {
+		return errors.New("debounce duration is invalid")
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package notifier_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"time"
+
+	configTest "github.com/tidepool-org/platform/config/test"
+	dataNotifier "github.com/tidepool-org/platform/data/notifier"
+)
+
+var _ = Describe("Config", func() {
+	Context("NewConfig", func() {
+		It("returns successfully with default values", func() {
+			cfg := dataNotifier.NewConfig()
+			Expect(cfg).ToNot(BeNil())
+			Expect(cfg.Address).To(BeEmpty())
+			Expect(cfg.DebounceDuration).To(Equal(dataNotifier.DebounceDurationDefault))
+		})
+	})
+
+	Context("with new config", func() {
+		var cfg *dataNotifier.Config
+
+		BeforeEach(func() {
+			cfg = dataNotifier.NewConfig()
+			Expect(cfg).ToNot(BeNil())
+		})
+
+		Context("Load", func() {
+			var configReporter *configTest.Reporter
+
+			BeforeEach(func() {
+				configReporter = configTest.NewReporter()
+				configReporter.Config["address"] = "https://example.com"
+				configReporter.Config["debounce_duration"] = "1h"
+			})
+
+			It("returns an error if the config reporter is missing", func() {
+				Expect(cfg.Load(nil)).To(MatchError("config reporter is missing"))
+			})
+
+			It("returns an error if the debounce duration is invalid", func() {
+				configReporter.Config["debounce_duration"] = "invalid"
+				Expect(cfg.Load(configReporter)).To(MatchError("debounce duration is invalid"))
+			})
+
+			It("returns successfully and does not set the debounce duration", func() {
+				delete(configReporter.Config, "debounce_duration")
+				Expect(cfg.Load(configReporter)).To(Succeed())
+				Expect(cfg.Address).To(Equal("https://example.com"))
+				Expect(cfg.DebounceDuration).To(Equal(dataNotifier.DebounceDurationDefault))
+			})
+
+			It("returns successfully", func() {
+				Expect(cfg.Load(configReporter)).To(Succeed())
+				Expect(cfg.Address).To(Equal("https://example.com"))
+				Expect(cfg.DebounceDuration).To(Equal(time.Hour))
+			})
+		})
+
+		Context("Validate", func() {
+			BeforeEach(func() {
+				cfg.Address = "https://example.com"
+			})
+
+			It("returns an error if the address is missing", func() {
+				cfg.Address = ""
+				Expect(cfg.Validate()).To(MatchError("address is missing"))
+			})
+
+			It("returns an error if the address is invalid", func() {
+				cfg.Address = ":::"
+				Expect(cfg.Validate()).To(MatchError("address is invalid"))
+			})
+
+			It("returns an error if the debounce duration is invalid", func() {
+				cfg.DebounceDuration = -time.Second
+				Expect(cfg.Validate()).To(MatchError("debounce duration is invalid"))
+			})
+
+			It("returns successfully", func() {
+				Expect(cfg.Validate()).To(Succeed())
+			})
+		})
+	})
+})
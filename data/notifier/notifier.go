@@ -0,0 +1,3 @@
+package notifier
+
+const Type = "org.tidepool.data.notifier"
@@ -0,0 +1,181 @@
+package notifier
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/data"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+)
+
+const (
+	AttemptsMaximum = 3
+	RetryDuration   = 5 * time.Minute
+)
+
+var retry = &task.Retry{
+	AttemptsMaximum: AttemptsMaximum,
+	DurationInitial: RetryDuration,
+	DurationMaximum: RetryDuration,
+}
+
+// Runner notifies the user when a data source is in the error state due to a provider-side failure. A notification
+// is not created if the data source is no longer in the error state or if the user was already notified for the
+// data source within the debounce duration.
+type Runner struct {
+	logger             log.Logger
+	authClient         auth.Client
+	dataSourceAccessor data.DataSourceAccessor
+	notificationClient notification.Client
+	address            string
+	debounceDuration   time.Duration
+}
+
+func NewRunner(cfg *Config, logger log.Logger, authClient auth.Client, dataSourceAccessor data.DataSourceAccessor, notificationClient notification.Client) (*Runner, error) {
+	if cfg == nil {
+		return nil, errors.New("config is missing")
+	} else if err := cfg.Validate(); err != nil {
+		return nil, errors.Wrap(err, "config is invalid")
+	}
+	if logger == nil {
+		return nil, errors.New("logger is missing")
+	}
+	if authClient == nil {
+		return nil, errors.New("auth client is missing")
+	}
+	if dataSourceAccessor == nil {
+		return nil, errors.New("data source accessor is missing")
+	}
+	if notificationClient == nil {
+		return nil, errors.New("notification client is missing")
+	}
+
+	return &Runner{
+		logger:             logger,
+		authClient:         authClient,
+		dataSourceAccessor: dataSourceAccessor,
+		notificationClient: notificationClient,
+		address:            strings.TrimRight(cfg.Address, "/"),
+		debounceDuration:   cfg.DebounceDuration,
+	}, nil
+}
+
+func (r *Runner) Logger() log.Logger {
+	return r.logger
+}
+
+func (r *Runner) AuthClient() auth.Client {
+	return r.authClient
+}
+
+func (r *Runner) DataSourceAccessor() data.DataSourceAccessor {
+	return r.dataSourceAccessor
+}
+
+func (r *Runner) NotificationClient() notification.Client {
+	return r.notificationClient
+}
+
+func (r *Runner) CanRunTask(tsk *task.Task) bool {
+	return tsk != nil && tsk.Type == Type
+}
+
+func (r *Runner) Run(ctx context.Context, tsk *task.Task) {
+	logger := r.Logger().WithField("taskId", tsk.ID)
+	ctx = log.NewContextWithLogger(ctx, logger)
+
+	tsk.ClearError()
+
+	dataSourceID, ok := tsk.Data["dataSourceId"].(string)
+	if !ok || dataSourceID == "" {
+		tsk.AppendError(errors.New("data source id is missing"))
+		tsk.SetFailed()
+		return
+	}
+
+	if serverSessionToken, err := r.AuthClient().ServerSessionToken(); err != nil {
+		retry.RepeatOnError(tsk, errors.Wrap(err, "unable to get server session token"))
+	} else if err = r.notify(auth.NewContextWithServerSessionToken(ctx, serverSessionToken), dataSourceID); err != nil {
+		retry.RepeatOnError(tsk, errors.Wrap(err, "unable to notify data source error"))
+	}
+}
+
+func (r *Runner) notify(ctx context.Context, dataSourceID string) error {
+	logger := log.LoggerFromContext(ctx).WithField("dataSourceId", dataSourceID)
+
+	dataSource, err := r.DataSourceAccessor().GetDataSource(ctx, dataSourceID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get data source")
+	} else if dataSource == nil || dataSource.State != data.DataSourceStateError {
+		logger.Debug("Data source no longer in error state")
+		return nil
+	}
+
+	if notified, err := r.recentlyNotified(ctx, dataSource); err != nil {
+		return err
+	} else if notified {
+		logger.Debug("Data source notification debounced")
+		return nil
+	}
+
+	create := notification.NewNotificationCreate()
+	create.Type = notification.TypeDataSourceError
+	create.Payload = map[string]interface{}{
+		"dataSourceId": dataSource.ID,
+		"providerType": dataSource.ProviderType,
+		"providerName": dataSource.ProviderName,
+		"link":         r.reconnectLink(dataSource),
+	}
+	if _, err = r.NotificationClient().CreateUserNotification(ctx, dataSource.UserID, create); err != nil {
+		return errors.Wrap(err, "unable to create user notification")
+	}
+
+	logger.Debug("Data source notification created")
+	return nil
+}
+
+// The link is stable and carries no credentials, as the notification is persisted and delivered through third parties
+// and may be read long after it is created. The user logs in as usual to start the authorization flow.
+func (r *Runner) reconnectLink(dataSource *data.DataSource) string {
+	return r.address + "/v1/oauth/" + url.PathEscape(dataSource.ProviderName) + "/authorize"
+}
+
+func (r *Runner) recentlyNotified(ctx context.Context, dataSource *data.DataSource) (bool, error) {
+	if r.debounceDuration == 0 {
+		return false, nil
+	}
+
+	filter := notification.NewNotificationFilter()
+	filter.Type = pointer.FromString(notification.TypeDataSourceError)
+	pagination := page.NewPagination()
+
+	// Notifications are listed most recent first, so stop at the first page with a notification before the debounce window
+	debounceTime := time.Now().Add(-r.debounceDuration)
+	for {
+		notifications, err := r.NotificationClient().ListUserNotifications(ctx, dataSource.UserID, filter, pagination)
+		if err != nil {
+			return false, errors.Wrap(err, "unable to list user notifications")
+		}
+
+		for _, ntfctn := range notifications {
+			if ntfctn.CreatedTime.Before(debounceTime) {
+				return false, nil
+			} else if dataSourceID, ok := ntfctn.Payload["dataSourceId"].(string); ok && dataSourceID == dataSource.ID {
+				return true, nil
+			}
+		}
+
+		if len(notifications) < pagination.Size {
+			return false, nil
+		}
+		pagination.Page++
+	}
+}
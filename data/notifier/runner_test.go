@@ -0,0 +1,246 @@
+package notifier_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"time"
+
+	"github.com/tidepool-org/platform/auth"
+	authTest "github.com/tidepool-org/platform/auth/test"
+	"github.com/tidepool-org/platform/data"
+	dataNotifier "github.com/tidepool-org/platform/data/notifier"
+	dataTest "github.com/tidepool-org/platform/data/test"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	logTest "github.com/tidepool-org/platform/log/test"
+	"github.com/tidepool-org/platform/notification"
+	notificationTest "github.com/tidepool-org/platform/notification/test"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+	"github.com/tidepool-org/platform/test"
+	"github.com/tidepool-org/platform/user"
+)
+
+var _ = Describe("Runner", func() {
+	var cfg *dataNotifier.Config
+	var logger *logTest.Logger
+	var authClient *authTest.Client
+	var dataSourceAccessor *dataTest.DataSourceAccessor
+	var notificationClient *notificationTest.Client
+
+	BeforeEach(func() {
+		cfg = dataNotifier.NewConfig()
+		cfg.Address = "https://example.com/"
+		logger = logTest.NewLogger()
+		authClient = authTest.NewClient()
+		dataSourceAccessor = dataTest.NewDataSourceAccessor()
+		notificationClient = notificationTest.NewClient()
+	})
+
+	AfterEach(func() {
+		notificationClient.Expectations()
+		authClient.Expectations()
+	})
+
+	Context("NewRunner", func() {
+		It("returns an error if the config is missing", func() {
+			rnnr, err := dataNotifier.NewRunner(nil, logger, authClient, dataSourceAccessor, notificationClient)
+			Expect(err).To(MatchError("config is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the config is invalid", func() {
+			cfg.Address = ""
+			rnnr, err := dataNotifier.NewRunner(cfg, logger, authClient, dataSourceAccessor, notificationClient)
+			Expect(err).To(MatchError("config is invalid; address is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the logger is missing", func() {
+			rnnr, err := dataNotifier.NewRunner(cfg, nil, authClient, dataSourceAccessor, notificationClient)
+			Expect(err).To(MatchError("logger is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the auth client is missing", func() {
+			rnnr, err := dataNotifier.NewRunner(cfg, logger, nil, dataSourceAccessor, notificationClient)
+			Expect(err).To(MatchError("auth client is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the data source accessor is missing", func() {
+			rnnr, err := dataNotifier.NewRunner(cfg, logger, authClient, nil, notificationClient)
+			Expect(err).To(MatchError("data source accessor is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the notification client is missing", func() {
+			rnnr, err := dataNotifier.NewRunner(cfg, logger, authClient, dataSourceAccessor, nil)
+			Expect(err).To(MatchError("notification client is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			rnnr, err := dataNotifier.NewRunner(cfg, logger, authClient, dataSourceAccessor, notificationClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rnnr).ToNot(BeNil())
+		})
+	})
+
+	Context("with new runner", func() {
+		var rnnr *dataNotifier.Runner
+
+		BeforeEach(func() {
+			var err error
+			rnnr, err = dataNotifier.NewRunner(cfg, logger, authClient, dataSourceAccessor, notificationClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rnnr).ToNot(BeNil())
+		})
+
+		Context("CanRunTask", func() {
+			It("returns false if the task is missing", func() {
+				Expect(rnnr.CanRunTask(nil)).To(BeFalse())
+			})
+
+			It("returns false if the task type does not match", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: test.RandomString()})).To(BeFalse())
+			})
+
+			It("returns true if the task type matches", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: dataNotifier.Type})).To(BeTrue())
+			})
+		})
+
+		Context("Run", func() {
+			var ctx context.Context
+			var dataSource *data.DataSource
+			var tsk *task.Task
+			var serverSessionToken string
+
+			BeforeEach(func() {
+				ctx = context.Background()
+				dataSource = &data.DataSource{ID: data.NewSourceID(), UserID: user.NewID(), ProviderType: auth.ProviderTypeOAuth, ProviderName: "dexcom", State: data.DataSourceStateError}
+				taskCreate, err := dataNotifier.NewTaskCreate(dataSource.ID)
+				Expect(err).ToNot(HaveOccurred())
+				tsk, err = task.NewTask(taskCreate)
+				Expect(err).ToNot(HaveOccurred())
+				tsk.State = task.TaskStateRunning
+				serverSessionToken = authTest.NewSessionToken()
+			})
+
+			It("fails if the data source id is missing", func() {
+				delete(tsk.Data, "dataSourceId")
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.IsFailed()).To(BeTrue())
+				Expect(tsk.Error).ToNot(BeNil())
+				Expect(tsk.Error.Error).To(MatchError("data source id is missing"))
+			})
+
+			It("retries if the server session token returns an error", func() {
+				authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: "", Error: errorsTest.NewError()}}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.State).To(Equal(task.TaskStatePending))
+				Expect(tsk.Data["errorCount"]).To(Equal(1))
+				Expect(tsk.Error.Error).To(MatchError(HavePrefix("unable to get server session token")))
+			})
+
+			It("fails once the maximum attempts are reached", func() {
+				tsk.Data["errorCount"] = float64(dataNotifier.AttemptsMaximum - 1)
+				authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: "", Error: errorsTest.NewError()}}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.IsFailed()).To(BeTrue())
+			})
+
+			Context("with server session token", func() {
+				BeforeEach(func() {
+					authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: serverSessionToken, Error: nil}}
+				})
+
+				It("retries if get data source returns an error", func() {
+					dataSourceAccessor.GetDataSourceOutputs = []dataTest.GetDataSourceOutput{{DataSource: nil, Error: errorsTest.NewError()}}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.State).To(Equal(task.TaskStatePending))
+					Expect(tsk.Error.Error).To(MatchError(HavePrefix("unable to notify data source error; unable to get data source")))
+				})
+
+				It("does not notify if the data source is missing", func() {
+					dataSourceAccessor.GetDataSourceOutputs = []dataTest.GetDataSourceOutput{{DataSource: nil, Error: nil}}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.State).To(Equal(task.TaskStateRunning))
+					Expect(tsk.HasError()).To(BeFalse())
+				})
+
+				It("does not notify if the data source is no longer in the error state", func() {
+					dataSource.State = data.DataSourceStateConnected
+					dataSourceAccessor.GetDataSourceOutputs = []dataTest.GetDataSourceOutput{{DataSource: dataSource, Error: nil}}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.State).To(Equal(task.TaskStateRunning))
+					Expect(tsk.HasError()).To(BeFalse())
+				})
+
+				Context("with data source in the error state", func() {
+					BeforeEach(func() {
+						dataSourceAccessor.GetDataSourceOutputs = []dataTest.GetDataSourceOutput{{DataSource: dataSource, Error: nil}}
+					})
+
+					It("retries if list user notifications returns an error", func() {
+						notificationClient.ListUserNotificationsOutputs = []notificationTest.ListUserNotificationsOutput{{Notifications: nil, Error: errorsTest.NewError()}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.State).To(Equal(task.TaskStatePending))
+						Expect(tsk.Error.Error).To(MatchError(HavePrefix("unable to notify data source error; unable to list user notifications")))
+					})
+
+					It("does not notify if the user was recently notified for the data source", func() {
+						notificationClient.ListUserNotificationsOutputs = []notificationTest.ListUserNotificationsOutput{{Notifications: notification.Notifications{
+							{Type: notification.TypeDataSourceError, Payload: map[string]interface{}{"dataSourceId": data.NewSourceID()}, CreatedTime: time.Now().Add(-time.Minute)},
+							{Type: notification.TypeDataSourceError, Payload: map[string]interface{}{"dataSourceId": dataSource.ID}, CreatedTime: time.Now().Add(-time.Hour)},
+						}, Error: nil}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeFalse())
+						Expect(notificationClient.ListUserNotificationsInputs).To(HaveLen(1))
+						Expect(auth.ServerSessionTokenFromContext(notificationClient.ListUserNotificationsInputs[0].Context)).To(Equal(serverSessionToken))
+						Expect(notificationClient.ListUserNotificationsInputs[0].UserID).To(Equal(dataSource.UserID))
+						Expect(notificationClient.ListUserNotificationsInputs[0].Filter).To(Equal(&notification.NotificationFilter{Type: pointer.FromString(notification.TypeDataSourceError)}))
+						Expect(notificationClient.ListUserNotificationsInputs[0].Pagination).To(Equal(page.NewPagination()))
+					})
+
+					Context("without recent notifications", func() {
+						BeforeEach(func() {
+							notificationClient.ListUserNotificationsOutputs = []notificationTest.ListUserNotificationsOutput{{Notifications: notification.Notifications{
+								{Type: notification.TypeDataSourceError, Payload: map[string]interface{}{"dataSourceId": dataSource.ID}, CreatedTime: time.Now().Add(-2 * dataNotifier.DebounceDurationDefault)},
+							}, Error: nil}}
+						})
+
+						It("retries if create user notification returns an error", func() {
+							notificationClient.CreateUserNotificationOutputs = []notificationTest.CreateUserNotificationOutput{{Notification: nil, Error: errorsTest.NewError()}}
+							rnnr.Run(ctx, tsk)
+							Expect(tsk.State).To(Equal(task.TaskStatePending))
+							Expect(tsk.Error.Error).To(MatchError(HavePrefix("unable to notify data source error; unable to create user notification")))
+						})
+
+						It("creates a data source error notification with a reconnect link without credentials", func() {
+							notificationClient.CreateUserNotificationOutputs = []notificationTest.CreateUserNotificationOutput{{Notification: &notification.Notification{}, Error: nil}}
+							rnnr.Run(ctx, tsk)
+							Expect(tsk.State).To(Equal(task.TaskStateRunning))
+							Expect(tsk.HasError()).To(BeFalse())
+							Expect(notificationClient.CreateUserNotificationInputs).To(HaveLen(1))
+							Expect(auth.ServerSessionTokenFromContext(notificationClient.CreateUserNotificationInputs[0].Context)).To(Equal(serverSessionToken))
+							Expect(notificationClient.CreateUserNotificationInputs[0].UserID).To(Equal(dataSource.UserID))
+							Expect(notificationClient.CreateUserNotificationInputs[0].Create).To(Equal(&notification.NotificationCreate{
+								Type: notification.TypeDataSourceError,
+								Payload: map[string]interface{}{
+									"dataSourceId": dataSource.ID,
+									"providerType": auth.ProviderTypeOAuth,
+									"providerName": "dexcom",
+									"link":         "https://example.com/v1/oauth/dexcom/authorize",
+								},
+							}))
+						})
+					})
+				})
+			})
+		})
+	})
+})
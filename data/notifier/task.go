@@ -0,0 +1,20 @@
+package notifier
+
+import (
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/task"
+)
+
+// The task is not named as a data source may transition to the error state again after the task completes
+func NewTaskCreate(dataSourceID string) (*task.TaskCreate, error) {
+	if dataSourceID == "" {
+		return nil, errors.New("data source id is missing")
+	}
+
+	return &task.TaskCreate{
+		Type: Type,
+		Data: map[string]interface{}{
+			"dataSourceId": dataSourceID,
+		},
+	}, nil
+}
@@ -0,0 +1,29 @@
+package notifier_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/tidepool-org/platform/data"
+	dataNotifier "github.com/tidepool-org/platform/data/notifier"
+)
+
+var _ = Describe("Task", func() {
+	Context("NewTaskCreate", func() {
+		It("returns an error if the data source id is missing", func() {
+			taskCreate, err := dataNotifier.NewTaskCreate("")
+			Expect(err).To(MatchError("data source id is missing"))
+			Expect(taskCreate).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			dataSourceID := data.NewSourceID()
+			taskCreate, err := dataNotifier.NewTaskCreate(dataSourceID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(taskCreate).ToNot(BeNil())
+			Expect(taskCreate.Name).To(BeNil())
+			Expect(taskCreate.Type).To(Equal(dataNotifier.Type))
+			Expect(taskCreate.Data).To(Equal(map[string]interface{}{"dataSourceId": dataSourceID}))
+		})
+	})
+})
@@ -3,6 +3,7 @@ package api
 import (
 	"github.com/ant0ine/go-json-rest/rest"
 
+	"github.com/tidepool-org/platform/alert"
 	dataClient "github.com/tidepool-org/platform/data/client"
 	"github.com/tidepool-org/platform/data/deduplicator"
 	dataService "github.com/tidepool-org/platform/data/service"
@@ -21,6 +22,7 @@ type Standard struct {
 	*api.API
 	metricClient            metric.Client
 	userClient              user.Client
+	alertEvaluator          alert.Evaluator
 	dataDeduplicatorFactory deduplicator.Factory
 	dataStore               dataStore.Store
 	dataStoreDEPRECATED     dataStoreDEPRECATED.Store
@@ -28,7 +30,7 @@ type Standard struct {
 	dataClient              dataClient.Client
 }
 
-func NewStandard(svc service.Service, metricClient metric.Client, userClient user.Client,
+func NewStandard(svc service.Service, metricClient metric.Client, userClient user.Client, alertEvaluator alert.Evaluator,
 	dataDeduplicatorFactory deduplicator.Factory, dataStore dataStore.Store,
 	dataStoreDEPRECATED dataStoreDEPRECATED.Store, syncTaskStore syncTaskStore.Store, dataClient dataClient.Client) (*Standard, error) {
 	if metricClient == nil {
@@ -37,6 +39,9 @@ func NewStandard(svc service.Service, metricClient metric.Client, userClient use
 	if userClient == nil {
 		return nil, errors.New("user client is missing")
 	}
+	if alertEvaluator == nil {
+		return nil, errors.New("alert evaluator is missing")
+	}
 	if dataDeduplicatorFactory == nil {
 		return nil, errors.New("data deduplicator factory is missing")
 	}
@@ -62,6 +67,7 @@ func NewStandard(svc service.Service, metricClient metric.Client, userClient use
 		API:                     a,
 		metricClient:            metricClient,
 		userClient:              userClient,
+		alertEvaluator:          alertEvaluator,
 		dataDeduplicatorFactory: dataDeduplicatorFactory,
 		dataStore:               dataStore,
 		dataStoreDEPRECATED:     dataStoreDEPRECATED,
@@ -98,7 +104,7 @@ func (s *Standard) DEPRECATEDInitializeRouter(routes []dataService.Route) error
 }
 
 func (s *Standard) withContext(handler dataService.HandlerFunc) rest.HandlerFunc {
-	return dataContext.WithContext(s.AuthClient(), s.metricClient, s.userClient,
+	return dataContext.WithContext(s.AuthClient(), s.metricClient, s.userClient, s.alertEvaluator,
 		s.dataDeduplicatorFactory, s.dataStore,
 		s.dataStoreDEPRECATED, s.syncTaskStore, s.dataClient, handler)
 }
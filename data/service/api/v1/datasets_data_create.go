@@ -124,6 +124,10 @@ func DataSetsDataCreate(dataServiceContext dataService.Context) {
 		return
 	}
 
+	if err = dataServiceContext.AlertEvaluator().EvaluateUserData(ctx, *dataSet.UserID, datumArray); err != nil {
+		lgr.WithError(err).Error("Unable to enqueue alert rules evaluation")
+	}
+
 	if err = dataServiceContext.MetricClient().RecordMetric(ctx, "data_sets_data_create", map[string]string{"count": strconv.Itoa(len(datumArray))}); err != nil {
 		lgr.WithError(err).Error("Unable to record metric")
 	}
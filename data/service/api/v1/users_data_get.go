@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/tidepool-org/platform/data"
+	dataService "github.com/tidepool-org/platform/data/service"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/service"
+	"github.com/tidepool-org/platform/user"
+)
+
+// UsersDataGet lists the data of the target user. Any other user requires the view permission and only receives the
+// data allowed by the restrictions of that permission.
+func UsersDataGet(dataServiceContext dataService.Context) {
+	ctx := dataServiceContext.Request().Context()
+
+	targetUserID := dataServiceContext.Request().PathParam("userId")
+	if targetUserID == "" {
+		dataServiceContext.RespondWithError(ErrorUserIDMissing())
+		return
+	}
+
+	filter := data.NewDatumFilter()
+	pagination := page.NewPagination()
+	if err := request.DecodeRequestQuery(dataServiceContext.Request().Request, filter, pagination); err != nil {
+		request.MustNewResponder(dataServiceContext.Response(), dataServiceContext.Request()).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	if details := request.DetailsFromContext(ctx); !details.IsService() && details.UserID() != targetUserID {
+		restrictions := getViewPermissionRestrictions(dataServiceContext, details.UserID(), targetUserID)
+		if restrictions == nil {
+			return
+		}
+		if !filter.Restrict(restrictions) {
+			dataServiceContext.RespondWithStatusAndData(http.StatusOK, []data.Blob{})
+			return
+		}
+	}
+
+	blobs, err := dataServiceContext.DataSession().ListUserData(ctx, targetUserID, filter, pagination)
+	if err != nil {
+		dataServiceContext.RespondWithInternalServerFailure("Unable to list user data", err)
+		return
+	}
+
+	dataServiceContext.RespondWithStatusAndData(http.StatusOK, blobs)
+}
+
+// Responds with an error and returns nil if the request user does not have the view permission for the target user,
+// or the permission is expired
+func getViewPermissionRestrictions(dataServiceContext dataService.Context, requestUserID string, targetUserID string) *user.PermissionRestrictions {
+	permissions, err := dataServiceContext.UserClient().GetUserPermissions(dataServiceContext.Request().Context(), requestUserID, targetUserID)
+	if err != nil {
+		if request.IsErrorUnauthorized(err) {
+			dataServiceContext.RespondWithError(service.ErrorUnauthorized())
+		} else {
+			dataServiceContext.RespondWithInternalServerFailure("Unable to get user permissions", err)
+		}
+		return nil
+	}
+
+	permission, ok := permissions[user.ViewPermission]
+	if !ok {
+		dataServiceContext.RespondWithError(service.ErrorUnauthorized())
+		return nil
+	}
+
+	restrictions, err := user.ParsePermissionRestrictions(permission)
+	if err != nil || restrictions.IsExpired(time.Now()) {
+		dataServiceContext.RespondWithError(service.ErrorUnauthorized())
+		return nil
+	}
+
+	return restrictions
+}
@@ -0,0 +1,117 @@
+package v1_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/tidepool-org/platform/data"
+	"github.com/tidepool-org/platform/data/service/api/v1"
+	testDataStoreDEPRECATED "github.com/tidepool-org/platform/data/storeDEPRECATED/test"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/service"
+	"github.com/tidepool-org/platform/user"
+	testUser "github.com/tidepool-org/platform/user/test"
+)
+
+var _ = Describe("UsersDataGet", func() {
+	var requestUserID string
+	var targetUserID string
+	var now time.Time
+	var blobs []data.Blob
+	var testContext *TestContext
+
+	BeforeEach(func() {
+		requestUserID = user.NewID()
+		targetUserID = user.NewID()
+		now = time.Now().UTC().Truncate(time.Second)
+		blobs = []data.Blob{{"type": "cbg", "time": now.Format(data.TimeFormat)}}
+		testContext = NewTestContext()
+		req, err := http.NewRequest(http.MethodGet, "http://localhost/v1/users/"+targetUserID+"/data", nil)
+		Expect(err).ToNot(HaveOccurred())
+		testContext.RequestImpl.Request = req.WithContext(request.NewContextWithDetails(context.Background(), request.NewDetails(request.MethodSessionToken, requestUserID, "token")))
+		testContext.RequestImpl.PathParams["userId"] = targetUserID
+	})
+
+	setQuery := func(query string) {
+		testContext.RequestImpl.Request.URL.RawQuery = query
+	}
+
+	setViewPermission := func(permission user.Permission) {
+		testContext.UserClientImpl.GetUserPermissionsOutputs = []testUser.GetUserPermissionsOutput{{Permissions: user.Permissions{user.ViewPermission: permission}, Error: nil}}
+	}
+
+	It("lists the data of the target user for the target user", func() {
+		testContext.RequestImpl.Request = testContext.RequestImpl.Request.WithContext(request.NewContextWithDetails(context.Background(), request.NewDetails(request.MethodSessionToken, targetUserID, "token")))
+		testContext.DataSessionImpl.ListUserDataOutputs = []testDataStoreDEPRECATED.ListUserDataOutput{{Data: blobs, Error: nil}}
+		v1.UsersDataGet(testContext)
+		Expect(testContext.UserClientImpl.GetUserPermissionsInvocations).To(Equal(0))
+		Expect(testContext.DataSessionImpl.ListUserDataInputs).To(HaveLen(1))
+		Expect(testContext.DataSessionImpl.ListUserDataInputs[0].Filter).To(Equal(data.NewDatumFilter()))
+		Expect(testContext.RespondWithStatusAndDataInputs).To(Equal([]RespondWithStatusAndDataInput{{http.StatusOK, blobs}}))
+	})
+
+	It("responds with unauthorized if the request user does not have the view permission", func() {
+		testContext.UserClientImpl.GetUserPermissionsOutputs = []testUser.GetUserPermissionsOutput{{Permissions: user.Permissions{user.UploadPermission: {}}, Error: nil}}
+		v1.UsersDataGet(testContext)
+		Expect(testContext.RespondWithErrorInputs).To(Equal([]*service.Error{service.ErrorUnauthorized()}))
+		Expect(testContext.DataSessionImpl.ListUserDataInvocations).To(Equal(0))
+	})
+
+	It("responds with unauthorized if the view permission is expired", func() {
+		setViewPermission(user.Permission{"expirationTime": now.Add(-time.Minute).Format(user.PermissionRestrictionsTimeFormat)})
+		v1.UsersDataGet(testContext)
+		Expect(testContext.RespondWithErrorInputs).To(Equal([]*service.Error{service.ErrorUnauthorized()}))
+		Expect(testContext.DataSessionImpl.ListUserDataInvocations).To(Equal(0))
+	})
+
+	It("lists all data if the view permission is not restricted", func() {
+		setViewPermission(user.Permission{})
+		testContext.DataSessionImpl.ListUserDataOutputs = []testDataStoreDEPRECATED.ListUserDataOutput{{Data: blobs, Error: nil}}
+		v1.UsersDataGet(testContext)
+		Expect(testContext.DataSessionImpl.ListUserDataInputs).To(HaveLen(1))
+		Expect(testContext.DataSessionImpl.ListUserDataInputs[0].UserID).To(Equal(targetUserID))
+		Expect(testContext.DataSessionImpl.ListUserDataInputs[0].Filter).To(Equal(data.NewDatumFilter()))
+		Expect(testContext.DataSessionImpl.ListUserDataInputs[0].Pagination).To(Equal(page.NewPagination()))
+		Expect(testContext.RespondWithStatusAndDataInputs).To(Equal([]RespondWithStatusAndDataInput{{http.StatusOK, blobs}}))
+	})
+
+	It("restricts the data to the data types and time window of the view permission", func() {
+		startTime := now.Add(-24 * time.Hour)
+		endTime := now.Add(-time.Hour)
+		setViewPermission(user.Permission{
+			"startTime": startTime.Format(user.PermissionRestrictionsTimeFormat),
+			"endTime":   endTime.Format(user.PermissionRestrictionsTimeFormat),
+			"dataTypes": []interface{}{"cbg", "smbg"},
+		})
+		setQuery("type=cbg&type=bolus&startTime=" + now.Add(-48*time.Hour).Format(data.TimeFormat))
+		testContext.DataSessionImpl.ListUserDataOutputs = []testDataStoreDEPRECATED.ListUserDataOutput{{Data: blobs, Error: nil}}
+		v1.UsersDataGet(testContext)
+		Expect(testContext.DataSessionImpl.ListUserDataInputs).To(HaveLen(1))
+		filter := testContext.DataSessionImpl.ListUserDataInputs[0].Filter
+		Expect(filter.Types).To(Equal(pointer.FromStringArray([]string{"cbg"})))
+		Expect(filter.StartTime).To(Equal(pointer.FromTime(startTime)))
+		Expect(filter.EndTime).To(Equal(pointer.FromTime(endTime)))
+	})
+
+	It("responds with no data if none of the requested data types are allowed", func() {
+		setViewPermission(user.Permission{"dataTypes": []interface{}{"smbg"}})
+		setQuery("type=cbg")
+		v1.UsersDataGet(testContext)
+		Expect(testContext.DataSessionImpl.ListUserDataInvocations).To(Equal(0))
+		Expect(testContext.RespondWithStatusAndDataInputs).To(Equal([]RespondWithStatusAndDataInput{{http.StatusOK, []data.Blob{}}}))
+	})
+
+	It("responds with no data if the requested time is outside of the time window", func() {
+		setViewPermission(user.Permission{"endTime": now.Add(-24 * time.Hour).Format(user.PermissionRestrictionsTimeFormat)})
+		setQuery("startTime=" + now.Add(-time.Hour).Format(data.TimeFormat))
+		v1.UsersDataGet(testContext)
+		Expect(testContext.DataSessionImpl.ListUserDataInvocations).To(Equal(0))
+		Expect(testContext.RespondWithStatusAndDataInputs).To(Equal([]RespondWithStatusAndDataInput{{http.StatusOK, []data.Blob{}}}))
+	})
+})
@@ -8,6 +8,10 @@ import (
 
 	"github.com/ant0ine/go-json-rest/rest"
 
+	"github.com/tidepool-org/platform/alert"
+	alertTest "github.com/tidepool-org/platform/alert/test"
+	"github.com/tidepool-org/platform/auth"
+	dataClient "github.com/tidepool-org/platform/data/client"
 	"github.com/tidepool-org/platform/data/deduplicator"
 	testDataDeduplicator "github.com/tidepool-org/platform/data/deduplicator/test"
 	dataStoreDEPRECATED "github.com/tidepool-org/platform/data/storeDEPRECATED"
@@ -18,6 +22,7 @@ import (
 	syncTaskStore "github.com/tidepool-org/platform/synctask/store"
 	testSyncTaskStore "github.com/tidepool-org/platform/synctask/store/test"
 	"github.com/tidepool-org/platform/test"
+	testRest "github.com/tidepool-org/platform/test/rest"
 	"github.com/tidepool-org/platform/user"
 	testUser "github.com/tidepool-org/platform/user/test"
 )
@@ -44,12 +49,14 @@ type RespondWithStatusAndDataInput struct {
 
 type TestContext struct {
 	*test.Mock
+	RequestImpl                            *rest.Request
 	RespondWithErrorInputs                 []*service.Error
 	RespondWithInternalServerFailureInputs []RespondWithInternalServerFailureInput
 	RespondWithStatusAndErrorsInputs       []RespondWithStatusAndErrorsInput
 	RespondWithStatusAndDataInputs         []RespondWithStatusAndDataInput
 	MetricClientImpl                       *testMetric.Client
 	UserClientImpl                         *testUser.Client
+	AlertEvaluatorImpl                     *alertTest.Evaluator
 	DataDeduplicatorFactoryImpl            *testDataDeduplicator.Factory
 	DataSessionImpl                        *testDataStoreDEPRECATED.DataSession
 	SyncTaskSessionImpl                    *testSyncTaskStore.SyncTaskSession
@@ -57,8 +64,11 @@ type TestContext struct {
 
 func NewTestContext() *TestContext {
 	return &TestContext{
+		Mock:                        test.NewMock(),
+		RequestImpl:                 testRest.NewRequest(),
 		MetricClientImpl:            testMetric.NewClient(),
 		UserClientImpl:              testUser.NewClient(),
+		AlertEvaluatorImpl:          alertTest.NewEvaluator(),
 		DataDeduplicatorFactoryImpl: testDataDeduplicator.NewFactory(),
 		DataSessionImpl:             testDataStoreDEPRECATED.NewDataSession(),
 		SyncTaskSessionImpl:         testSyncTaskStore.NewSyncTaskSession(),
@@ -69,6 +79,10 @@ func (t *TestContext) Response() rest.ResponseWriter {
 	panic("Unexpected invocation of Response on TestContext")
 }
 
+func (t *TestContext) Request() *rest.Request {
+	return t.RequestImpl
+}
+
 func (t *TestContext) RespondWithError(err *service.Error) {
 	t.RespondWithErrorInputs = append(t.RespondWithErrorInputs, err)
 }
@@ -85,6 +99,10 @@ func (t *TestContext) RespondWithStatusAndData(statusCode int, data interface{})
 	t.RespondWithStatusAndDataInputs = append(t.RespondWithStatusAndDataInputs, RespondWithStatusAndDataInput{statusCode, data})
 }
 
+func (t *TestContext) AuthClient() auth.Client {
+	panic("Unexpected invocation of AuthClient on TestContext")
+}
+
 func (t *TestContext) MetricClient() metric.Client {
 	return t.MetricClientImpl
 }
@@ -93,6 +111,10 @@ func (t *TestContext) UserClient() user.Client {
 	return t.UserClientImpl
 }
 
+func (t *TestContext) AlertEvaluator() alert.Evaluator {
+	return t.AlertEvaluatorImpl
+}
+
 func (t *TestContext) DataDeduplicatorFactory() deduplicator.Factory {
 	return t.DataDeduplicatorFactoryImpl
 }
@@ -105,10 +127,15 @@ func (t *TestContext) SyncTaskSession() syncTaskStore.SyncTaskSession {
 	return t.SyncTaskSessionImpl
 }
 
+func (t *TestContext) DataClient() dataClient.Client {
+	panic("Unexpected invocation of DataClient on TestContext")
+}
+
 func (t *TestContext) Expectations() {
 	t.Mock.Expectations()
 	t.MetricClientImpl.Expectations()
 	t.UserClientImpl.AssertOutputsEmpty()
+	t.AlertEvaluatorImpl.Expectations()
 	t.DataDeduplicatorFactoryImpl.Expectations()
 	t.DataSessionImpl.Expectations()
 	t.SyncTaskSessionImpl.Expectations()
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"github.com/tidepool-org/platform/alert"
 	"github.com/tidepool-org/platform/auth"
 	dataClient "github.com/tidepool-org/platform/data/client"
 	"github.com/tidepool-org/platform/data/deduplicator"
@@ -17,6 +18,7 @@ type Context interface {
 	AuthClient() auth.Client
 	MetricClient() metric.Client
 	UserClient() user.Client
+	AlertEvaluator() alert.Evaluator
 
 	DataDeduplicatorFactory() deduplicator.Factory
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/ant0ine/go-json-rest/rest"
 
+	"github.com/tidepool-org/platform/alert"
 	"github.com/tidepool-org/platform/auth"
 	dataClient "github.com/tidepool-org/platform/data/client"
 	"github.com/tidepool-org/platform/data/deduplicator"
@@ -23,6 +24,7 @@ type Standard struct {
 	authClient              auth.Client
 	metricClient            metric.Client
 	userClient              user.Client
+	alertEvaluator          alert.Evaluator
 	dataDeduplicatorFactory deduplicator.Factory
 	dataStore               dataStore.Store
 	dataStoreDEPRECATED     dataStoreDEPRECATED.Store
@@ -32,11 +34,11 @@ type Standard struct {
 	dataClient              dataClient.Client
 }
 
-func WithContext(authClient auth.Client, metricClient metric.Client, userClient user.Client,
+func WithContext(authClient auth.Client, metricClient metric.Client, userClient user.Client, alertEvaluator alert.Evaluator,
 	dataDeduplicatorFactory deduplicator.Factory, dataStore dataStore.Store,
 	dataStoreDEPRECATED dataStoreDEPRECATED.Store, syncTaskStore syncTaskStore.Store, dataClient dataClient.Client, handler dataService.HandlerFunc) rest.HandlerFunc {
 	return func(response rest.ResponseWriter, request *rest.Request) {
-		standard, standardErr := NewStandard(response, request, authClient, metricClient, userClient,
+		standard, standardErr := NewStandard(response, request, authClient, metricClient, userClient, alertEvaluator,
 			dataDeduplicatorFactory, dataStore, dataStoreDEPRECATED, syncTaskStore, dataClient)
 		if standardErr != nil {
 			if responder, responderErr := serviceContext.NewResponder(response, request); responderErr != nil {
@@ -53,7 +55,7 @@ func WithContext(authClient auth.Client, metricClient metric.Client, userClient
 }
 
 func NewStandard(response rest.ResponseWriter, request *rest.Request,
-	authClient auth.Client, metricClient metric.Client, userClient user.Client,
+	authClient auth.Client, metricClient metric.Client, userClient user.Client, alertEvaluator alert.Evaluator,
 	dataDeduplicatorFactory deduplicator.Factory, dataStore dataStore.Store,
 	dataStoreDEPRECATED dataStoreDEPRECATED.Store, syncTaskStore syncTaskStore.Store, dataClient dataClient.Client) (*Standard, error) {
 	if authClient == nil {
@@ -65,6 +67,9 @@ func NewStandard(response rest.ResponseWriter, request *rest.Request,
 	if userClient == nil {
 		return nil, errors.New("user client is missing")
 	}
+	if alertEvaluator == nil {
+		return nil, errors.New("alert evaluator is missing")
+	}
 	if dataDeduplicatorFactory == nil {
 		return nil, errors.New("data deduplicator factory is missing")
 	}
@@ -95,6 +100,7 @@ func NewStandard(response rest.ResponseWriter, request *rest.Request,
 		authClient:              authClient,
 		metricClient:            metricClient,
 		userClient:              userClient,
+		alertEvaluator:          alertEvaluator,
 		dataDeduplicatorFactory: dataDeduplicatorFactory,
 		dataStore:               dataStore,
 		dataStoreDEPRECATED:     dataStoreDEPRECATED,
@@ -126,6 +132,10 @@ func (s *Standard) UserClient() user.Client {
 	return s.userClient
 }
 
+func (s *Standard) AlertEvaluator() alert.Evaluator {
+	return s.alertEvaluator
+}
+
 func (s *Standard) DataDeduplicatorFactory() deduplicator.Factory {
 	return s.dataDeduplicatorFactory
 }
@@ -3,29 +3,43 @@ package service
 import (
 	"context"
 
+	"github.com/tidepool-org/platform/auth"
 	"github.com/tidepool-org/platform/data"
+	dataNotifier "github.com/tidepool-org/platform/data/notifier"
 	dataStore "github.com/tidepool-org/platform/data/store"
 	dataStoreDEPRECATED "github.com/tidepool-org/platform/data/storeDEPRECATED"
 	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
 	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/task"
 )
 
 type Client struct {
 	dataStore           dataStore.Store
 	dataStoreDEPRECATED dataStoreDEPRECATED.Store
+	authClient          auth.Client
+	taskClient          task.Client
 }
 
-func NewClient(str dataStore.Store, strDEPRECATED dataStoreDEPRECATED.Store) (*Client, error) {
+func NewClient(str dataStore.Store, strDEPRECATED dataStoreDEPRECATED.Store, authClient auth.Client, taskClient task.Client) (*Client, error) {
 	if str == nil {
 		return nil, errors.New("data store is missing")
 	}
 	if strDEPRECATED == nil {
 		return nil, errors.New("data store deprecated is missing")
 	}
+	if authClient == nil {
+		return nil, errors.New("auth client is missing")
+	}
+	if taskClient == nil {
+		return nil, errors.New("task client is missing")
+	}
 
 	return &Client{
 		dataStore:           str,
 		dataStoreDEPRECATED: strDEPRECATED,
+		authClient:          authClient,
+		taskClient:          taskClient,
 	}, nil
 }
 
@@ -54,7 +68,42 @@ func (c *Client) UpdateDataSource(ctx context.Context, id string, update *data.D
 	ssn := c.dataStore.NewDataSourceSession()
 	defer ssn.Close()
 
-	return ssn.UpdateDataSource(ctx, id, update)
+	var previousDataSource *data.DataSource
+	if update != nil && update.State != nil {
+		var err error
+		if previousDataSource, err = ssn.GetDataSource(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	dataSource, err := ssn.UpdateDataSource(ctx, id, update)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only provider-side failures move a data source to the error state; a user disconnect is not notified
+	if previousDataSource != nil && dataSource != nil && previousDataSource.State != dataSource.State && dataSource.State == data.DataSourceStateError {
+		if err = c.createNotifierTask(ctx, dataSource.ID); err != nil {
+			log.LoggerFromContext(ctx).WithError(err).WithField("dataSourceId", id).Error("Unable to create data source notifier task")
+		}
+	}
+
+	return dataSource, nil
+}
+
+func (c *Client) createNotifierTask(ctx context.Context, dataSourceID string) error {
+	taskCreate, err := dataNotifier.NewTaskCreate(dataSourceID)
+	if err != nil {
+		return err
+	}
+
+	serverSessionToken, err := c.authClient.ServerSessionToken()
+	if err != nil {
+		return errors.Wrap(err, "unable to get server session token")
+	}
+
+	_, err = c.taskClient.CreateTask(auth.NewContextWithServerSessionToken(ctx, serverSessionToken), taskCreate)
+	return err
 }
 
 func (c *Client) DeleteDataSource(ctx context.Context, id string) error {
@@ -94,6 +143,13 @@ func (c *Client) CreateDataSetsData(ctx context.Context, dataSetID string, datum
 	panic("Not Implemented!")
 }
 
+func (c *Client) ListUserData(ctx context.Context, userID string, filter *data.DatumFilter, pagination *page.Pagination) ([]data.Blob, error) {
+	ssn := c.dataStoreDEPRECATED.NewDataSession()
+	defer ssn.Close()
+
+	return ssn.ListUserData(ctx, userID, filter, pagination)
+}
+
 func (c *Client) DestroyDataForUserByID(ctx context.Context, userID string) error {
 	panic("Not Implemented!")
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	alertUserData "github.com/tidepool-org/platform/alert/userdata"
 	"github.com/tidepool-org/platform/application"
 	"github.com/tidepool-org/platform/data/deduplicator"
 	"github.com/tidepool-org/platform/data/service/api"
@@ -14,6 +15,7 @@ import (
 	"github.com/tidepool-org/platform/service/service"
 	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
 	syncTaskMongo "github.com/tidepool-org/platform/synctask/store/mongo"
+	taskClient "github.com/tidepool-org/platform/task/client"
 	userClient "github.com/tidepool-org/platform/user/client"
 )
 
@@ -21,6 +23,8 @@ type Standard struct {
 	*service.DEPRECATEDService
 	metricClient            *metricClient.Client
 	userClient              *userClient.Client
+	taskClient              *taskClient.Client
+	alertEnqueuer           *alertUserData.Enqueuer
 	dataDeduplicatorFactory deduplicator.Factory
 	dataStoreDEPRECATED     *dataStoreDEPRECATEDMongo.Store
 	dataStore               *dataStoreMongo.Store
@@ -47,6 +51,12 @@ func (s *Standard) Initialize(provider application.Provider) error {
 	if err := s.initializeUserClient(); err != nil {
 		return err
 	}
+	if err := s.initializeTaskClient(); err != nil {
+		return err
+	}
+	if err := s.initializeAlertEnqueuer(); err != nil {
+		return err
+	}
 	if err := s.initializeDataDeduplicatorFactory(); err != nil {
 		return err
 	}
@@ -85,6 +95,8 @@ func (s *Standard) Terminate() {
 		s.dataStoreDEPRECATED = nil
 	}
 	s.dataDeduplicatorFactory = nil
+	s.alertEnqueuer = nil
+	s.taskClient = nil
 	s.userClient = nil
 	s.metricClient = nil
 
@@ -139,6 +151,26 @@ func (s *Standard) initializeUserClient() error {
 	return nil
 }
 
+func (s *Standard) initializeTaskClient() error {
+	s.Logger().Debug("Loading task client config")
+
+	cfg := platform.NewConfig()
+	cfg.UserAgent = s.UserAgent()
+	if err := cfg.Load(s.ConfigReporter().WithScopes("task", "client")); err != nil {
+		return errors.Wrap(err, "unable to load task client config")
+	}
+
+	s.Logger().Debug("Creating task client")
+
+	clnt, err := taskClient.New(cfg, platform.AuthorizeAsService)
+	if err != nil {
+		return errors.Wrap(err, "unable to create task client")
+	}
+	s.taskClient = clnt
+
+	return nil
+}
+
 func (s *Standard) initializeDataDeduplicatorFactory() error {
 	s.Logger().Debug("Creating truncate data deduplicator factory")
 
@@ -235,10 +267,22 @@ func (s *Standard) initializeSyncTaskStore() error {
 	return nil
 }
 
+func (s *Standard) initializeAlertEnqueuer() error {
+	s.Logger().Debug("Creating alert enqueuer")
+
+	enqueuer, err := alertUserData.NewEnqueuer(s.AuthClient(), s.taskClient)
+	if err != nil {
+		return errors.Wrap(err, "unable to create alert enqueuer")
+	}
+	s.alertEnqueuer = enqueuer
+
+	return nil
+}
+
 func (s *Standard) initializeDataClient() error {
 	s.Logger().Debug("Creating data client")
 
-	clnt, err := NewClient(s.dataStore, s.dataStoreDEPRECATED)
+	clnt, err := NewClient(s.dataStore, s.dataStoreDEPRECATED, s.AuthClient(), s.taskClient)
 	if err != nil {
 		return errors.Wrap(err, "unable to create data client")
 	}
@@ -250,7 +294,7 @@ func (s *Standard) initializeDataClient() error {
 func (s *Standard) initializeAPI() error {
 	s.Logger().Debug("Creating api")
 
-	newAPI, err := api.NewStandard(s, s.metricClient, s.userClient,
+	newAPI, err := api.NewStandard(s, s.metricClient, s.userClient, s.alertEnqueuer,
 		s.dataDeduplicatorFactory, s.dataStore,
 		s.dataStoreDEPRECATED, s.syncTaskStore, s.dataClient)
 	if err != nil {
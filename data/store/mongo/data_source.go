@@ -165,6 +165,13 @@ func (d *DataSourceSession) UpdateDataSource(ctx context.Context, id string, upd
 		"modifiedTime": now.Truncate(time.Second),
 	}
 	unset := bson.M{}
+	if update.Reset != nil && *update.Reset {
+		unset["error"] = true
+		unset["earliestDataTime"] = true
+		unset["latestDataTime"] = true
+		unset["lastImportTime"] = true
+		unset["backfill"] = true
+	}
 	if update.State != nil {
 		set["state"] = *update.State
 		switch *update.State {
@@ -190,6 +197,9 @@ func (d *DataSourceSession) UpdateDataSource(ctx context.Context, id string, upd
 	if update.LastImportTime != nil {
 		set["lastImportTime"] = (*update.LastImportTime).Truncate(time.Second)
 	}
+	if update.Backfill != nil {
+		set["backfill"] = *update.Backfill
+	}
 	changeInfo, err := d.C().UpdateAll(bson.M{"id": id}, d.ConstructUpdate(set, unset))
 	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("UpdateDataSource")
 	if err != nil {
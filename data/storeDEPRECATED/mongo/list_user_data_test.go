@@ -0,0 +1,148 @@
+package mongo_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/tidepool-org/platform/data"
+	"github.com/tidepool-org/platform/data/storeDEPRECATED"
+	"github.com/tidepool-org/platform/data/storeDEPRECATED/mongo"
+	"github.com/tidepool-org/platform/log"
+	logNull "github.com/tidepool-org/platform/log/null"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/pointer"
+	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
+	storeStructuredMongoTest "github.com/tidepool-org/platform/store/structured/mongo/test"
+	"github.com/tidepool-org/platform/user"
+)
+
+func NewUserDatum(userID string, typ string, tm time.Time) bson.M {
+	return bson.M{
+		"_active": true,
+		"_userId": userID,
+		"type":    typ,
+		"time":    tm.UTC().Format(data.TimeFormat),
+	}
+}
+
+var _ = Describe("ListUserData", func() {
+	var ctx context.Context
+	var cfg *storeStructuredMongo.Config
+	var str *mongo.Store
+	var ssn storeDEPRECATED.DataSession
+	var mgoSession *mgo.Session
+	var userID string
+	var now time.Time
+
+	BeforeEach(func() {
+		var err error
+		ctx = log.NewContextWithLogger(context.Background(), logNull.NewLogger())
+		cfg = storeStructuredMongoTest.NewConfig()
+		str, err = mongo.NewStore(cfg, logNull.NewLogger())
+		Expect(err).ToNot(HaveOccurred())
+		ssn = str.NewDataSession()
+		mgoSession = storeStructuredMongoTest.Session().Copy()
+		userID = user.NewID()
+		now = time.Now().UTC().Truncate(time.Second)
+
+		otherDatum := NewUserDatum(user.NewID(), "cbg", now)
+		inactiveDatum := NewUserDatum(userID, "cbg", now)
+		inactiveDatum["_active"] = false
+		Expect(mgoSession.DB(cfg.Database).C(cfg.CollectionPrefix+"deviceData").Insert(
+			NewUserDatum(userID, "cbg", now.Add(-3*time.Hour)),
+			NewUserDatum(userID, "cbg", now.Add(-2*time.Hour)),
+			NewUserDatum(userID, "smbg", now.Add(-2*time.Hour)),
+			NewUserDatum(userID, "cbg", now.Add(-time.Hour)),
+			NewUserDatum(userID, "upload", now.Add(-time.Hour)),
+			otherDatum,
+			inactiveDatum,
+		)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if mgoSession != nil {
+			mgoSession.Close()
+		}
+		if ssn != nil {
+			ssn.Close()
+		}
+		if str != nil {
+			str.Close()
+		}
+	})
+
+	times := func(blobs []data.Blob) []interface{} {
+		result := []interface{}{}
+		for _, blob := range blobs {
+			result = append(result, blob["time"])
+		}
+		return result
+	}
+
+	It("returns an error if the user id is missing", func() {
+		blobs, err := ssn.ListUserData(ctx, "", nil, nil)
+		Expect(err).To(MatchError("user id is missing"))
+		Expect(blobs).To(BeNil())
+	})
+
+	It("returns an error if the filter is invalid", func() {
+		filter := data.NewDatumFilter()
+		filter.Types = pointer.FromStringArray([]string{})
+		blobs, err := ssn.ListUserData(ctx, userID, filter, nil)
+		Expect(err).To(MatchError(ContainSubstring("filter is invalid")))
+		Expect(blobs).To(BeNil())
+	})
+
+	It("returns the active data of the user, other than uploads, sorted by time descending", func() {
+		blobs, err := ssn.ListUserData(ctx, userID, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(blobs).To(HaveLen(4))
+		Expect(blobs[0]["time"]).To(Equal(now.Add(-time.Hour).Format(data.TimeFormat)))
+		Expect(blobs[3]["time"]).To(Equal(now.Add(-3 * time.Hour).Format(data.TimeFormat)))
+		for _, blob := range blobs {
+			Expect(blob).ToNot(HaveKey("_userId"))
+			Expect(blob["type"]).ToNot(Equal("upload"))
+		}
+	})
+
+	It("returns only the data of the restricted types", func() {
+		filter := data.NewDatumFilter()
+		filter.Types = pointer.FromStringArray([]string{"smbg", "upload"})
+		blobs, err := ssn.ListUserData(ctx, userID, filter, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(blobs).To(HaveLen(1))
+		Expect(blobs[0]["type"]).To(Equal("smbg"))
+	})
+
+	It("returns only the data within the restricted time window, with the start inclusive and the end exclusive", func() {
+		filter := data.NewDatumFilter()
+		filter.StartTime = pointer.FromTime(now.Add(-3 * time.Hour))
+		filter.EndTime = pointer.FromTime(now.Add(-time.Hour))
+		blobs, err := ssn.ListUserData(ctx, userID, filter, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(times(blobs)).To(ConsistOf(now.Add(-3*time.Hour).Format(data.TimeFormat), now.Add(-2*time.Hour).Format(data.TimeFormat), now.Add(-2*time.Hour).Format(data.TimeFormat)))
+	})
+
+	It("returns no data outside of the restricted time window", func() {
+		filter := data.NewDatumFilter()
+		filter.StartTime = pointer.FromTime(now.Add(time.Hour))
+		blobs, err := ssn.ListUserData(ctx, userID, filter, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(blobs).To(BeEmpty())
+	})
+
+	It("returns the requested page", func() {
+		pagination := page.NewPagination()
+		pagination.Page = 1
+		pagination.Size = 3
+		blobs, err := ssn.ListUserData(ctx, userID, nil, pagination)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(times(blobs)).To(Equal([]interface{}{now.Add(-3 * time.Hour).Format(data.TimeFormat)}))
+	})
+})
@@ -607,6 +607,73 @@ func (d *DataSession) DestroyDataForUserByID(ctx context.Context, userID string)
 	return nil
 }
 
+// ListUserData returns the active data, excluding data sets, sorted by time, most recent first. Internal fields are
+// not returned.
+func (d *DataSession) ListUserData(ctx context.Context, userID string, filter *data.DatumFilter, pagination *page.Pagination) ([]data.Blob, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+	if filter == nil {
+		filter = data.NewDatumFilter()
+	} else if err := structureValidator.New().Validate(filter); err != nil {
+		return nil, errors.Wrap(err, "filter is invalid")
+	}
+	if pagination == nil {
+		pagination = page.NewPagination()
+	} else if err := structureValidator.New().Validate(pagination); err != nil {
+		return nil, errors.Wrap(err, "pagination is invalid")
+	}
+
+	if d.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"userId": userID, "filter": filter, "pagination": pagination})
+
+	selector := bson.M{
+		"_active": true,
+		"_userId": userID,
+		"type":    bson.M{"$ne": "upload"},
+	}
+	if filter.Types != nil {
+		selector["type"] = bson.M{"$in": *filter.Types, "$ne": "upload"}
+	}
+	timeSelector := bson.M{}
+	if filter.StartTime != nil {
+		timeSelector["$gte"] = filter.StartTime.UTC().Format(data.TimeFormat)
+	}
+	if filter.EndTime != nil {
+		timeSelector["$lt"] = filter.EndTime.UTC().Format(data.TimeFormat)
+	}
+	if filter.HasTime != nil {
+		timeSelector["$exists"] = *filter.HasTime
+	}
+	if len(timeSelector) > 0 {
+		selector["time"] = timeSelector
+	}
+	fields := bson.M{
+		"_id":            0,
+		"_active":        0,
+		"_deduplicator":  0,
+		"_schemaVersion": 0,
+		"_userId":        0,
+		"_version":       0,
+	}
+
+	blobs := []data.Blob{}
+	err := d.C().Find(selector).Select(fields).Sort("-time").Skip(pagination.Page * pagination.Size).Limit(pagination.Size).All(&blobs)
+	logger.WithFields(log.Fields{"count": len(blobs), "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("ListUserData")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list user data")
+	}
+
+	return blobs, nil
+}
+
 func (d *DataSession) ListUserDataSets(ctx context.Context, userID string, filter *data.DataSetFilter, pagination *page.Pagination) (data.DataSets, error) {
 	if ctx == nil {
 		return nil, errors.New("context is missing")
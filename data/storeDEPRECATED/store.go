@@ -31,6 +31,7 @@ type DataSession interface {
 	DeleteOtherDataSetData(ctx context.Context, dataSet *upload.Upload) error
 	DestroyDataForUserByID(ctx context.Context, userID string) error
 
+	ListUserData(ctx context.Context, userID string, filter *data.DatumFilter, pagination *page.Pagination) ([]data.Blob, error)
 	ListUserDataSets(ctx context.Context, userID string, filter *data.DataSetFilter, pagination *page.Pagination) (data.DataSets, error)
 	GetDataSet(ctx context.Context, id string) (*data.DataSet, error)
 }
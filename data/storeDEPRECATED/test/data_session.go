@@ -96,6 +96,18 @@ type GetDataSetOutput struct {
 	Error   error
 }
 
+type ListUserDataInput struct {
+	Context    context.Context
+	UserID     string
+	Filter     *data.DatumFilter
+	Pagination *page.Pagination
+}
+
+type ListUserDataOutput struct {
+	Data  []data.Blob
+	Error error
+}
+
 type ListUserDataSetsInput struct {
 	Context    context.Context
 	UserID     string
@@ -148,6 +160,9 @@ type DataSession struct {
 	DestroyDataForUserByIDInvocations                    int
 	DestroyDataForUserByIDInputs                         []DestroyDataForUserByIDInput
 	DestroyDataForUserByIDOutputs                        []error
+	ListUserDataInvocations                              int
+	ListUserDataInputs                                   []ListUserDataInput
+	ListUserDataOutputs                                  []ListUserDataOutput
 	ListUserDataSetsInvocations                          int
 	ListUserDataSetsInputs                               []ListUserDataSetsInput
 	ListUserDataSetsOutputs                              []ListUserDataSetsOutput
@@ -315,6 +330,18 @@ func (d *DataSession) DestroyDataForUserByID(ctx context.Context, userID string)
 	return output
 }
 
+func (d *DataSession) ListUserData(ctx context.Context, userID string, filter *data.DatumFilter, pagination *page.Pagination) ([]data.Blob, error) {
+	d.ListUserDataInvocations++
+
+	d.ListUserDataInputs = append(d.ListUserDataInputs, ListUserDataInput{Context: ctx, UserID: userID, Filter: filter, Pagination: pagination})
+
+	gomega.Expect(d.ListUserDataOutputs).ToNot(gomega.BeEmpty())
+
+	output := d.ListUserDataOutputs[0]
+	d.ListUserDataOutputs = d.ListUserDataOutputs[1:]
+	return output.Data, output.Error
+}
+
 func (d *DataSession) ListUserDataSets(ctx context.Context, userID string, filter *data.DataSetFilter, pagination *page.Pagination) (data.DataSets, error) {
 	d.ListUserDataSetsInvocations++
 
@@ -355,6 +382,7 @@ func (d *DataSession) Expectations() {
 	gomega.Expect(d.UnarchiveDeviceDataUsingHashesFromDataSetOutputs).To(gomega.BeEmpty())
 	gomega.Expect(d.DeleteOtherDataSetDataOutputs).To(gomega.BeEmpty())
 	gomega.Expect(d.DestroyDataForUserByIDOutputs).To(gomega.BeEmpty())
+	gomega.Expect(d.ListUserDataOutputs).To(gomega.BeEmpty())
 	gomega.Expect(d.ListUserDataSetsOutputs).To(gomega.BeEmpty())
 	gomega.Expect(d.GetDataSetOutputs).To(gomega.BeEmpty())
 }
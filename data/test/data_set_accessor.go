@@ -0,0 +1,192 @@
+package test
+
+import (
+	"context"
+
+	"github.com/tidepool-org/platform/data"
+	"github.com/tidepool-org/platform/page"
+)
+
+type ListUserDataSetsInput struct {
+	Context    context.Context
+	UserID     string
+	Filter     *data.DataSetFilter
+	Pagination *page.Pagination
+}
+
+type ListUserDataSetsOutput struct {
+	DataSets data.DataSets
+	Error    error
+}
+
+type CreateUserDataSetInput struct {
+	Context context.Context
+	UserID  string
+	Create  *data.DataSetCreate
+}
+
+type CreateUserDataSetOutput struct {
+	DataSet *data.DataSet
+	Error   error
+}
+
+type GetDataSetInput struct {
+	Context context.Context
+	ID      string
+}
+
+type GetDataSetOutput struct {
+	DataSet *data.DataSet
+	Error   error
+}
+
+type UpdateDataSetInput struct {
+	Context context.Context
+	ID      string
+	Update  *data.DataSetUpdate
+}
+
+type UpdateDataSetOutput struct {
+	DataSet *data.DataSet
+	Error   error
+}
+
+type DeleteDataSetInput struct {
+	Context context.Context
+	ID      string
+}
+
+type DataSetAccessor struct {
+	ListUserDataSetsInvocations  int
+	ListUserDataSetsInputs       []ListUserDataSetsInput
+	ListUserDataSetsStub         func(ctx context.Context, userID string, filter *data.DataSetFilter, pagination *page.Pagination) (data.DataSets, error)
+	ListUserDataSetsOutputs      []ListUserDataSetsOutput
+	ListUserDataSetsOutput       *ListUserDataSetsOutput
+	CreateUserDataSetInvocations int
+	CreateUserDataSetInputs      []CreateUserDataSetInput
+	CreateUserDataSetStub        func(ctx context.Context, userID string, create *data.DataSetCreate) (*data.DataSet, error)
+	CreateUserDataSetOutputs     []CreateUserDataSetOutput
+	CreateUserDataSetOutput      *CreateUserDataSetOutput
+	GetDataSetInvocations        int
+	GetDataSetInputs             []GetDataSetInput
+	GetDataSetStub               func(ctx context.Context, id string) (*data.DataSet, error)
+	GetDataSetOutputs            []GetDataSetOutput
+	GetDataSetOutput             *GetDataSetOutput
+	UpdateDataSetInvocations     int
+	UpdateDataSetInputs          []UpdateDataSetInput
+	UpdateDataSetStub            func(ctx context.Context, id string, update *data.DataSetUpdate) (*data.DataSet, error)
+	UpdateDataSetOutputs         []UpdateDataSetOutput
+	UpdateDataSetOutput          *UpdateDataSetOutput
+	DeleteDataSetInvocations     int
+	DeleteDataSetInputs          []DeleteDataSetInput
+	DeleteDataSetStub            func(ctx context.Context, id string) error
+	DeleteDataSetOutputs         []error
+	DeleteDataSetOutput          *error
+}
+
+func NewDataSetAccessor() *DataSetAccessor {
+	return &DataSetAccessor{}
+}
+
+func (d *DataSetAccessor) ListUserDataSets(ctx context.Context, userID string, filter *data.DataSetFilter, pagination *page.Pagination) (data.DataSets, error) {
+	d.ListUserDataSetsInvocations++
+	d.ListUserDataSetsInputs = append(d.ListUserDataSetsInputs, ListUserDataSetsInput{Context: ctx, UserID: userID, Filter: filter, Pagination: pagination})
+	if d.ListUserDataSetsStub != nil {
+		return d.ListUserDataSetsStub(ctx, userID, filter, pagination)
+	}
+	if len(d.ListUserDataSetsOutputs) > 0 {
+		output := d.ListUserDataSetsOutputs[0]
+		d.ListUserDataSetsOutputs = d.ListUserDataSetsOutputs[1:]
+		return output.DataSets, output.Error
+	}
+	if d.ListUserDataSetsOutput != nil {
+		return d.ListUserDataSetsOutput.DataSets, d.ListUserDataSetsOutput.Error
+	}
+	panic("ListUserDataSets has no output")
+}
+
+func (d *DataSetAccessor) CreateUserDataSet(ctx context.Context, userID string, create *data.DataSetCreate) (*data.DataSet, error) {
+	d.CreateUserDataSetInvocations++
+	d.CreateUserDataSetInputs = append(d.CreateUserDataSetInputs, CreateUserDataSetInput{Context: ctx, UserID: userID, Create: create})
+	if d.CreateUserDataSetStub != nil {
+		return d.CreateUserDataSetStub(ctx, userID, create)
+	}
+	if len(d.CreateUserDataSetOutputs) > 0 {
+		output := d.CreateUserDataSetOutputs[0]
+		d.CreateUserDataSetOutputs = d.CreateUserDataSetOutputs[1:]
+		return output.DataSet, output.Error
+	}
+	if d.CreateUserDataSetOutput != nil {
+		return d.CreateUserDataSetOutput.DataSet, d.CreateUserDataSetOutput.Error
+	}
+	panic("CreateUserDataSet has no output")
+}
+
+func (d *DataSetAccessor) GetDataSet(ctx context.Context, id string) (*data.DataSet, error) {
+	d.GetDataSetInvocations++
+	d.GetDataSetInputs = append(d.GetDataSetInputs, GetDataSetInput{Context: ctx, ID: id})
+	if d.GetDataSetStub != nil {
+		return d.GetDataSetStub(ctx, id)
+	}
+	if len(d.GetDataSetOutputs) > 0 {
+		output := d.GetDataSetOutputs[0]
+		d.GetDataSetOutputs = d.GetDataSetOutputs[1:]
+		return output.DataSet, output.Error
+	}
+	if d.GetDataSetOutput != nil {
+		return d.GetDataSetOutput.DataSet, d.GetDataSetOutput.Error
+	}
+	panic("GetDataSet has no output")
+}
+
+func (d *DataSetAccessor) UpdateDataSet(ctx context.Context, id string, update *data.DataSetUpdate) (*data.DataSet, error) {
+	d.UpdateDataSetInvocations++
+	d.UpdateDataSetInputs = append(d.UpdateDataSetInputs, UpdateDataSetInput{Context: ctx, ID: id, Update: update})
+	if d.UpdateDataSetStub != nil {
+		return d.UpdateDataSetStub(ctx, id, update)
+	}
+	if len(d.UpdateDataSetOutputs) > 0 {
+		output := d.UpdateDataSetOutputs[0]
+		d.UpdateDataSetOutputs = d.UpdateDataSetOutputs[1:]
+		return output.DataSet, output.Error
+	}
+	if d.UpdateDataSetOutput != nil {
+		return d.UpdateDataSetOutput.DataSet, d.UpdateDataSetOutput.Error
+	}
+	panic("UpdateDataSet has no output")
+}
+
+func (d *DataSetAccessor) DeleteDataSet(ctx context.Context, id string) error {
+	d.DeleteDataSetInvocations++
+	d.DeleteDataSetInputs = append(d.DeleteDataSetInputs, DeleteDataSetInput{Context: ctx, ID: id})
+	if d.DeleteDataSetStub != nil {
+		return d.DeleteDataSetStub(ctx, id)
+	}
+	if len(d.DeleteDataSetOutputs) > 0 {
+		output := d.DeleteDataSetOutputs[0]
+		d.DeleteDataSetOutputs = d.DeleteDataSetOutputs[1:]
+		return output
+	}
+	if d.DeleteDataSetOutput != nil {
+		return *d.DeleteDataSetOutput
+	}
+	panic("DeleteDataSet has no output")
+}
+
+func (d *DataSetAccessor) AssertOutputsEmpty() {
+	if len(d.ListUserDataSetsOutputs) > 0 {
+		panic("ListUserDataSetsOutputs is not empty")
+	}
+	if len(d.CreateUserDataSetOutputs) > 0 {
+		panic("CreateUserDataSetOutputs is not empty")
+	}
+	if len(d.GetDataSetOutputs) > 0 {
+		panic("GetDataSetOutputs is not empty")
+	}
+	if len(d.UpdateDataSetOutputs) > 0 {
+		panic("UpdateDataSetOutputs is not empty")
+	}
+	if len(d.DeleteDataSetOutputs) > 0 {
+		panic("DeleteDataSetOutputs is not empty")
+	}
+}
@@ -0,0 +1,192 @@
+package test
+
+import (
+	"context"
+
+	"github.com/tidepool-org/platform/data"
+	"github.com/tidepool-org/platform/page"
+)
+
+type ListUserDataSourcesInput struct {
+	Context    context.Context
+	UserID     string
+	Filter     *data.DataSourceFilter
+	Pagination *page.Pagination
+}
+
+type ListUserDataSourcesOutput struct {
+	DataSources data.DataSources
+	Error       error
+}
+
+type CreateUserDataSourceInput struct {
+	Context context.Context
+	UserID  string
+	Create  *data.DataSourceCreate
+}
+
+type CreateUserDataSourceOutput struct {
+	DataSource *data.DataSource
+	Error      error
+}
+
+type GetDataSourceInput struct {
+	Context context.Context
+	ID      string
+}
+
+type GetDataSourceOutput struct {
+	DataSource *data.DataSource
+	Error      error
+}
+
+type UpdateDataSourceInput struct {
+	Context context.Context
+	ID      string
+	Update  *data.DataSourceUpdate
+}
+
+type UpdateDataSourceOutput struct {
+	DataSource *data.DataSource
+	Error      error
+}
+
+type DeleteDataSourceInput struct {
+	Context context.Context
+	ID      string
+}
+
+type DataSourceAccessor struct {
+	ListUserDataSourcesInvocations  int
+	ListUserDataSourcesInputs       []ListUserDataSourcesInput
+	ListUserDataSourcesStub         func(ctx context.Context, userID string, filter *data.DataSourceFilter, pagination *page.Pagination) (data.DataSources, error)
+	ListUserDataSourcesOutputs      []ListUserDataSourcesOutput
+	ListUserDataSourcesOutput       *ListUserDataSourcesOutput
+	CreateUserDataSourceInvocations int
+	CreateUserDataSourceInputs      []CreateUserDataSourceInput
+	CreateUserDataSourceStub        func(ctx context.Context, userID string, create *data.DataSourceCreate) (*data.DataSource, error)
+	CreateUserDataSourceOutputs     []CreateUserDataSourceOutput
+	CreateUserDataSourceOutput      *CreateUserDataSourceOutput
+	GetDataSourceInvocations        int
+	GetDataSourceInputs             []GetDataSourceInput
+	GetDataSourceStub               func(ctx context.Context, id string) (*data.DataSource, error)
+	GetDataSourceOutputs            []GetDataSourceOutput
+	GetDataSourceOutput             *GetDataSourceOutput
+	UpdateDataSourceInvocations     int
+	UpdateDataSourceInputs          []UpdateDataSourceInput
+	UpdateDataSourceStub            func(ctx context.Context, id string, update *data.DataSourceUpdate) (*data.DataSource, error)
+	UpdateDataSourceOutputs         []UpdateDataSourceOutput
+	UpdateDataSourceOutput          *UpdateDataSourceOutput
+	DeleteDataSourceInvocations     int
+	DeleteDataSourceInputs          []DeleteDataSourceInput
+	DeleteDataSourceStub            func(ctx context.Context, id string) error
+	DeleteDataSourceOutputs         []error
+	DeleteDataSourceOutput          *error
+}
+
+func NewDataSourceAccessor() *DataSourceAccessor {
+	return &DataSourceAccessor{}
+}
+
+func (d *DataSourceAccessor) ListUserDataSources(ctx context.Context, userID string, filter *data.DataSourceFilter, pagination *page.Pagination) (data.DataSources, error) {
+	d.ListUserDataSourcesInvocations++
+	d.ListUserDataSourcesInputs = append(d.ListUserDataSourcesInputs, ListUserDataSourcesInput{Context: ctx, UserID: userID, Filter: filter, Pagination: pagination})
+	if d.ListUserDataSourcesStub != nil {
+		return d.ListUserDataSourcesStub(ctx, userID, filter, pagination)
+	}
+	if len(d.ListUserDataSourcesOutputs) > 0 {
+		output := d.ListUserDataSourcesOutputs[0]
+		d.ListUserDataSourcesOutputs = d.ListUserDataSourcesOutputs[1:]
+		return output.DataSources, output.Error
+	}
+	if d.ListUserDataSourcesOutput != nil {
+		return d.ListUserDataSourcesOutput.DataSources, d.ListUserDataSourcesOutput.Error
+	}
+	panic("ListUserDataSources has no output")
+}
+
+func (d *DataSourceAccessor) CreateUserDataSource(ctx context.Context, userID string, create *data.DataSourceCreate) (*data.DataSource, error) {
+	d.CreateUserDataSourceInvocations++
+	d.CreateUserDataSourceInputs = append(d.CreateUserDataSourceInputs, CreateUserDataSourceInput{Context: ctx, UserID: userID, Create: create})
+	if d.CreateUserDataSourceStub != nil {
+		return d.CreateUserDataSourceStub(ctx, userID, create)
+	}
+	if len(d.CreateUserDataSourceOutputs) > 0 {
+		output := d.CreateUserDataSourceOutputs[0]
+		d.CreateUserDataSourceOutputs = d.CreateUserDataSourceOutputs[1:]
+		return output.DataSource, output.Error
+	}
+	if d.CreateUserDataSourceOutput != nil {
+		return d.CreateUserDataSourceOutput.DataSource, d.CreateUserDataSourceOutput.Error
+	}
+	panic("CreateUserDataSource has no output")
+}
+
+func (d *DataSourceAccessor) GetDataSource(ctx context.Context, id string) (*data.DataSource, error) {
+	d.GetDataSourceInvocations++
+	d.GetDataSourceInputs = append(d.GetDataSourceInputs, GetDataSourceInput{Context: ctx, ID: id})
+	if d.GetDataSourceStub != nil {
+		return d.GetDataSourceStub(ctx, id)
+	}
+	if len(d.GetDataSourceOutputs) > 0 {
+		output := d.GetDataSourceOutputs[0]
+		d.GetDataSourceOutputs = d.GetDataSourceOutputs[1:]
+		return output.DataSource, output.Error
+	}
+	if d.GetDataSourceOutput != nil {
+		return d.GetDataSourceOutput.DataSource, d.GetDataSourceOutput.Error
+	}
+	panic("GetDataSource has no output")
+}
+
+func (d *DataSourceAccessor) UpdateDataSource(ctx context.Context, id string, update *data.DataSourceUpdate) (*data.DataSource, error) {
+	d.UpdateDataSourceInvocations++
+	d.UpdateDataSourceInputs = append(d.UpdateDataSourceInputs, UpdateDataSourceInput{Context: ctx, ID: id, Update: update})
+	if d.UpdateDataSourceStub != nil {
+		return d.UpdateDataSourceStub(ctx, id, update)
+	}
+	if len(d.UpdateDataSourceOutputs) > 0 {
+		output := d.UpdateDataSourceOutputs[0]
+		d.UpdateDataSourceOutputs = d.UpdateDataSourceOutputs[1:]
+		return output.DataSource, output.Error
+	}
+	if d.UpdateDataSourceOutput != nil {
+		return d.UpdateDataSourceOutput.DataSource, d.UpdateDataSourceOutput.Error
+	}
+	panic("UpdateDataSource has no output")
+}
+
+func (d *DataSourceAccessor) DeleteDataSource(ctx context.Context, id string) error {
+	d.DeleteDataSourceInvocations++
+	d.DeleteDataSourceInputs = append(d.DeleteDataSourceInputs, DeleteDataSourceInput{Context: ctx, ID: id})
+	if d.DeleteDataSourceStub != nil {
+		return d.DeleteDataSourceStub(ctx, id)
+	}
+	if len(d.DeleteDataSourceOutputs) > 0 {
+		output := d.DeleteDataSourceOutputs[0]
+		d.DeleteDataSourceOutputs = d.DeleteDataSourceOutputs[1:]
+		return output
+	}
+	if d.DeleteDataSourceOutput != nil {
+		return *d.DeleteDataSourceOutput
+	}
+	panic("DeleteDataSource has no output")
+}
+
+func (d *DataSourceAccessor) AssertOutputsEmpty() {
+	if len(d.ListUserDataSourcesOutputs) > 0 {
+		panic("ListUserDataSourcesOutputs is not empty")
+	}
+	if len(d.CreateUserDataSourceOutputs) > 0 {
+		panic("CreateUserDataSourceOutputs is not empty")
+	}
+	if len(d.GetDataSourceOutputs) > 0 {
+		panic("GetDataSourceOutputs is not empty")
+	}
+	if len(d.UpdateDataSourceOutputs) > 0 {
+		panic("UpdateDataSourceOutputs is not empty")
+	}
+	if len(d.DeleteDataSourceOutputs) > 0 {
+		panic("DeleteDataSourceOutputs is not empty")
+	}
+}
@@ -10,6 +10,20 @@ const (
 	Type = "cbg"
 )
 
+// Trends and trend rate units reported in the payload by continuous glucose monitors
+const (
+	TrendDoubleUp      = "doubleUp"
+	TrendSingleUp      = "singleUp"
+	TrendFortyFiveUp   = "fortyFiveUp"
+	TrendFlat          = "flat"
+	TrendFortyFiveDown = "fortyFiveDown"
+	TrendSingleDown    = "singleDown"
+	TrendDoubleDown    = "doubleDown"
+
+	TrendRateUnitsMgdLMinute  = "mg/dL/min"
+	TrendRateUnitsMmolLMinute = "mmol/L/min"
+)
+
 type Continuous struct {
 	glucose.Glucose `bson:",inline"`
 }
@@ -15,6 +15,7 @@ import (
 const (
 	Type = "cgmSettings"
 
+	ManufacturerDexcom            = "Dexcom"
 	ManufacturerLengthMaximum     = 100
 	ManufacturersLengthMaximum    = 10
 	ModelLengthMaximum            = 100
@@ -29,8 +30,8 @@ type CGM struct {
 	LowLevelAlert   *LowLevelAlert   `json:"lowAlerts,omitempty" bson:"lowAlerts,omitempty"`   // TODO: Rename lowLevelAlert
 	Manufacturers   *[]string        `json:"manufacturers,omitempty" bson:"manufacturers,omitempty"`
 	Model           *string          `json:"model,omitempty" bson:"model,omitempty"`
-	OutOfRangeAlert *OutOfRangeAlert `json:"outOfRangeAlerts,omitempty" bson:"outOfRangeAlerts,omitempty"`   // TODO: Rename outOfRangeAlert
-	RateAlerts      *RateAlerts      `json:"rateOfChangeAlert,omitempty" bson:"rateOfChangeAlert,omitempty"` // TODO: Split into separate fallRateAlert, riseRateAlert
+	OutOfRangeAlert *OutOfRangeAlert `json:"outOfRangeAlerts,omitempty" bson:"outOfRangeAlerts,omitempty"`    // TODO: Rename outOfRangeAlert
+	RateAlerts      *RateAlerts      `json:"rateOfChangeAlerts,omitempty" bson:"rateOfChangeAlert,omitempty"` // TODO: Split into separate fallRateAlert, riseRateAlert
 	SerialNumber    *string          `json:"serialNumber,omitempty" bson:"serialNumber,omitempty"`
 	TransmitterID   *string          `json:"transmitterId,omitempty" bson:"transmitterId,omitempty"`
 	Units           *string          `json:"units,omitempty" bson:"units,omitempty"`
@@ -73,13 +74,14 @@ func (c *CGM) Validate(validator structure.Validator) {
 		validator.String("type", &c.Type).EqualTo(Type)
 	}
 
+	snoozes := LevelAlertSnoozesForManufacturers(c.Manufacturers)
 	if c.HighLevelAlert != nil {
-		c.HighLevelAlert.Validate(validator.WithReference("highAlerts"), c.Units)
+		c.HighLevelAlert.ValidateWithSnoozes(validator.WithReference("highAlerts"), c.Units, snoozes)
 	} else {
 		validator.WithReference("highAlerts").ReportError(structureValidator.ErrorValueNotExists())
 	}
 	if c.LowLevelAlert != nil {
-		c.LowLevelAlert.Validate(validator.WithReference("lowAlerts"), c.Units)
+		c.LowLevelAlert.ValidateWithSnoozes(validator.WithReference("lowAlerts"), c.Units, snoozes)
 	} else {
 		validator.WithReference("lowAlerts").ReportError(structureValidator.ErrorValueNotExists())
 	}
@@ -145,6 +145,21 @@ var _ = Describe("CGM", func() {
 					pointer.FromString("mmol/L"),
 					func(datum *cgm.CGM, units *string) { datum.HighLevelAlert = NewHighLevelAlert(units) },
 				),
+				Entry("high level alert snooze invalid for manufacturer",
+					pointer.FromString("mmol/L"),
+					func(datum *cgm.CGM, units *string) {
+						datum.Manufacturers = pointer.FromStringArray([]string{"Other"})
+						datum.HighLevelAlert.Snooze = pointer.FromInt(1200000)
+					},
+					testErrors.WithPointerSourceAndMeta(structureValidator.ErrorValueIntNotOneOf(1200000, cgm.LevelAlertSnoozes()), "/highAlerts/snooze", NewMeta()),
+				),
+				Entry("high level alert snooze valid for manufacturer Dexcom",
+					pointer.FromString("mmol/L"),
+					func(datum *cgm.CGM, units *string) {
+						datum.Manufacturers = pointer.FromStringArray([]string{"Dexcom"})
+						datum.HighLevelAlert.Snooze = pointer.FromInt(1200000)
+					},
+				),
 				Entry("low level alert missing",
 					pointer.FromString("mmol/L"),
 					func(datum *cgm.CGM, units *string) { datum.LowLevelAlert = nil },
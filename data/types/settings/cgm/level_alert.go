@@ -27,6 +27,30 @@ func LevelAlertSnoozes() []int {
 	}
 }
 
+// Dexcom permits level alert snoozes in 5 minute increments up to 4 hours, in addition to the standard snoozes
+func DexcomLevelAlertSnoozes() []int {
+	return []int{
+		0, 900000, 1200000, 1500000, 1800000, 2100000, 2400000, 2700000, 3000000,
+		3300000, 3600000, 3900000, 4200000, 4500000, 4800000, 5100000, 5400000,
+		5700000, 6000000, 6300000, 6600000, 6900000, 7200000, 7500000, 7800000,
+		8100000, 8400000, 8700000, 9000000, 9300000, 9600000, 9900000, 10200000,
+		10500000, 10800000, 11100000, 11400000, 11700000, 12000000, 12300000,
+		12600000, 12900000, 13200000, 13500000, 13800000, 14100000, 14400000,
+		15300000, 16200000, 17100000, 18000000,
+	}
+}
+
+func LevelAlertSnoozesForManufacturers(manufacturers *[]string) []int {
+	if manufacturers != nil {
+		for _, manufacturer := range *manufacturers {
+			if manufacturer == ManufacturerDexcom {
+				return DexcomLevelAlertSnoozes()
+			}
+		}
+	}
+	return LevelAlertSnoozes()
+}
+
 type LevelAlert struct {
 	Enabled *bool    `json:"enabled,omitempty" bson:"enabled,omitempty"`
 	Level   *float64 `json:"level,omitempty" bson:"level,omitempty"`
@@ -39,10 +63,10 @@ func (l *LevelAlert) Parse(parser data.ObjectParser) {
 	l.Snooze = parser.ParseInteger("snooze")
 }
 
-func (l *LevelAlert) Validate(validator structure.Validator, units *string) {
+func (l *LevelAlert) Validate(validator structure.Validator, units *string, snoozes []int) {
 	validator.Bool("enabled", l.Enabled).Exists()
 	validator.Float64("level", l.Level).Exists()
-	validator.Int("snooze", l.Snooze).Exists().OneOf(LevelAlertSnoozes()...)
+	validator.Int("snooze", l.Snooze).Exists().OneOf(snoozes...)
 }
 
 func (l *LevelAlert) Normalize(normalizer data.Normalizer, units *string) {
@@ -70,7 +94,11 @@ func NewHighLevelAlert() *HighLevelAlert {
 }
 
 func (h *HighLevelAlert) Validate(validator structure.Validator, units *string) {
-	h.LevelAlert.Validate(validator, units)
+	h.ValidateWithSnoozes(validator, units, LevelAlertSnoozes())
+}
+
+func (h *HighLevelAlert) ValidateWithSnoozes(validator structure.Validator, units *string, snoozes []int) {
+	h.LevelAlert.Validate(validator, units, snoozes)
 
 	validator.Float64("level", h.Level).InRange(h.LevelRangeForUnits(units))
 }
@@ -106,7 +134,11 @@ func NewLowLevelAlert() *LowLevelAlert {
 }
 
 func (l *LowLevelAlert) Validate(validator structure.Validator, units *string) {
-	l.LevelAlert.Validate(validator, units)
+	l.ValidateWithSnoozes(validator, units, LevelAlertSnoozes())
+}
+
+func (l *LowLevelAlert) ValidateWithSnoozes(validator structure.Validator, units *string, snoozes []int) {
+	l.LevelAlert.Validate(validator, units, snoozes)
 
 	validator.Float64("level", l.Level).InRange(l.LevelRangeForUnits(units))
 }
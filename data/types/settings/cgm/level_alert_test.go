@@ -94,6 +94,37 @@ var _ = Describe("LevelAlert", func() {
 			13500000, 14400000, 15300000, 16200000, 17100000, 18000000}))
 	})
 
+	It("DexcomLevelAlertSnoozes returns expected", func() {
+		Expect(cgm.DexcomLevelAlertSnoozes()).To(Equal([]int{
+			0, 900000, 1200000, 1500000, 1800000, 2100000, 2400000, 2700000, 3000000,
+			3300000, 3600000, 3900000, 4200000, 4500000, 4800000, 5100000, 5400000,
+			5700000, 6000000, 6300000, 6600000, 6900000, 7200000, 7500000, 7800000,
+			8100000, 8400000, 8700000, 9000000, 9300000, 9600000, 9900000, 10200000,
+			10500000, 10800000, 11100000, 11400000, 11700000, 12000000, 12300000,
+			12600000, 12900000, 13200000, 13500000, 13800000, 14100000, 14400000,
+			15300000, 16200000, 17100000, 18000000}))
+	})
+
+	It("DexcomLevelAlertSnoozes includes all standard snoozes", func() {
+		for _, snooze := range cgm.LevelAlertSnoozes() {
+			Expect(cgm.DexcomLevelAlertSnoozes()).To(ContainElement(snooze))
+		}
+	})
+
+	Context("LevelAlertSnoozesForManufacturers", func() {
+		It("returns the standard snoozes if the manufacturers are missing", func() {
+			Expect(cgm.LevelAlertSnoozesForManufacturers(nil)).To(Equal(cgm.LevelAlertSnoozes()))
+		})
+
+		It("returns the standard snoozes if the manufacturers do not include Dexcom", func() {
+			Expect(cgm.LevelAlertSnoozesForManufacturers(pointer.FromStringArray([]string{"Medtronic"}))).To(Equal(cgm.LevelAlertSnoozes()))
+		})
+
+		It("returns the Dexcom snoozes if the manufacturers include Dexcom", func() {
+			Expect(cgm.LevelAlertSnoozesForManufacturers(pointer.FromStringArray([]string{"Other", "Dexcom"}))).To(Equal(cgm.DexcomLevelAlertSnoozes()))
+		})
+	})
+
 	Context("ParseHighLevelAlert", func() {
 		// TODO
 	})
@@ -2,27 +2,42 @@ package client
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"golang.org/x/oauth2"
 
-	"github.com/tidepool-org/platform/client"
 	"github.com/tidepool-org/platform/dexcom"
 	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/log"
 	"github.com/tidepool-org/platform/oauth"
 	oauthClient "github.com/tidepool-org/platform/oauth/client"
+	"github.com/tidepool-org/platform/rate"
 	"github.com/tidepool-org/platform/request"
 )
 
 const RequestDurationMaximum = 10 * time.Second
 
 type Client struct {
-	client *oauthClient.Client
+	client  *oauthClient.Client
+	limiter *rate.Limiter
 }
 
-func New(cfg *client.Config, tknSrcSrc oauth.TokenSourceSource) (*Client, error) {
-	clnt, err := oauthClient.New(cfg, tknSrcSrc)
+func New(cfg *Config, tknSrcSrc oauth.TokenSourceSource) (*Client, error) {
+	if cfg == nil {
+		return nil, errors.New("config is missing")
+	} else if err := cfg.Validate(); err != nil {
+		return nil, errors.Wrap(err, "config is invalid")
+	}
+
+	clnt, err := oauthClient.New(cfg.Config, tknSrcSrc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Limiter is shared by all workers using this client, but not across processes, so the configured
+	// rate must account for the number of task service instances
+	lmtr, err := rate.NewLimiter(cfg.RateLimit, cfg.RateBurst)
 	if err != nil {
 		return nil, err
 	}
@@ -31,7 +46,8 @@ func New(cfg *client.Config, tknSrcSrc oauth.TokenSourceSource) (*Client, error)
 	oauth2.RegisterBrokenAuthHeaderProvider(cfg.Address)
 
 	return &Client{
-		client: clnt,
+		client:  clnt,
+		limiter: lmtr,
 	}, nil
 }
 
@@ -79,10 +95,10 @@ func (c *Client) sendDexcomRequest(ctx context.Context, startTime time.Time, end
 		"endDate":   endTime.UTC().Format(dexcom.DateTimeFormat),
 	})
 
-	err := c.client.SendOAuthRequest(ctx, method, url, nil, nil, responseBody, tokenSource)
+	err := c.sendRateLimitedRequest(ctx, method, url, responseBody, tokenSource)
 	if oauth.IsAccessTokenError(err) {
 		tokenSource.ExpireToken()
-		err = c.client.SendOAuthRequest(ctx, method, url, nil, nil, responseBody, tokenSource)
+		err = c.sendRateLimitedRequest(ctx, method, url, responseBody, tokenSource)
 	}
 	if oauth.IsRefreshTokenError(err) {
 		err = errors.Wrap(request.ErrorUnauthenticated(), err.Error())
@@ -94,3 +110,47 @@ func (c *Client) sendDexcomRequest(ctx context.Context, startTime time.Time, end
 
 	return err
 }
+
+// If the rate limiter is paused due to a prior Retry-After, then do not send the request and
+// instead return the same error so the task can be rescheduled; if the response indicates
+// Retry-After, then pause the rate limiter so that all workers back off
+func (c *Client) sendRateLimitedRequest(ctx context.Context, method string, url string, responseBody interface{}, tokenSource oauth.TokenSource) error {
+	if retryAfter := c.limiter.PauseRemaining(); retryAfter > 0 {
+		return request.ErrorWithRetryAfter(request.ErrorTooManyRequests(), retryAfter)
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return errors.Wrap(err, "unable to wait for rate limiter")
+	}
+
+	err := c.client.SendOAuthRequest(ctx, method, url, nil, nil, responseBody, []request.ResponseInspector{&retryAfterInspector{}}, tokenSource)
+	if retryAfter := request.RetryAfterFromError(err); retryAfter != nil {
+		log.LoggerFromContext(ctx).WithError(err).WithField("retryAfter", retryAfter.Seconds()).Warn("Pausing requests due to Retry-After")
+		c.limiter.Pause(*retryAfter)
+	}
+	return err
+}
+
+// Dexcom may respond with a body that does not describe a rate limit or outage, so report these
+// responses based upon the status code alone, including any Retry-After
+type retryAfterInspector struct{}
+
+func (r *retryAfterInspector) InspectResponse(res *http.Response) error {
+	if res == nil {
+		return errors.New("response is missing")
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests:
+		return errorWithRetryAfter(request.ErrorTooManyRequests(), res.Header)
+	case http.StatusServiceUnavailable:
+		return errorWithRetryAfter(request.ErrorServiceUnavailable(), res.Header)
+	}
+	return nil
+}
+
+func errorWithRetryAfter(err error, header http.Header) error {
+	if retryAfter, retryAfterErr := request.ParseRetryAfterHeader(header, "Retry-After"); retryAfterErr == nil && retryAfter != nil {
+		return request.ErrorWithRetryAfter(err, *retryAfter)
+	}
+	return err
+}
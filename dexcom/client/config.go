@@ -0,0 +1,69 @@
+package client
+
+import (
+	"strconv"
+
+	"github.com/tidepool-org/platform/client"
+	"github.com/tidepool-org/platform/config"
+	"github.com/tidepool-org/platform/errors"
+)
+
+const (
+	RateBurstDefault = 10
+	RateLimitDefault = 10.0 // Requests per second across all workers in a single process
+)
+
+type Config struct {
+	*client.Config
+	RateLimit float64
+	RateBurst int
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Config:    client.NewConfig(),
+		RateLimit: RateLimitDefault,
+		RateBurst: RateBurstDefault,
+	}
+}
+
+func (c *Config) Load(configReporter config.Reporter) error {
+	if err := c.Config.Load(configReporter); err != nil {
+		return err
+	}
+
+	if rateLimitString, err := configReporter.Get("rate_limit"); err == nil {
+		var rateLimit float64
+		rateLimit, err = strconv.ParseFloat(rateLimitString, 64)
+		if err != nil {
+			return errors.New("rate limit is invalid")
+		}
+		c.RateLimit = rateLimit
+	}
+	if rateBurstString, err := configReporter.Get("rate_burst"); err == nil {
+		var rateBurst int64
+		rateBurst, err = strconv.ParseInt(rateBurstString, 10, 0)
+		if err != nil {
+			return errors.New("rate burst is invalid")
+		}
+		c.RateBurst = int(rateBurst)
+	}
+
+	return nil
+}
+
+func (c *Config) Validate() error {
+	if c.Config == nil {
+		return errors.New("client config is missing")
+	} else if err := c.Config.Validate(); err != nil {
+		return err
+	}
+	if c.RateLimit <= 0 {
+		return errors.New("rate limit is invalid")
+	}
+	if c.RateBurst < 1 {
+		return errors.New("rate burst is invalid")
+	}
+
+	return nil
+}
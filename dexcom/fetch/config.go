@@ -0,0 +1,32 @@
+package fetch
+
+import (
+	"github.com/tidepool-org/platform/config"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/provider"
+)
+
+// Dexcom backups cause intermittent refresh token failures during this window (per Dexcom)
+const MaintenanceWindowsDefault = "America/Los_Angeles 02:45-03:45"
+
+type Config struct {
+	MaintenanceWindows provider.MaintenanceWindows
+}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+func (c *Config) Load(configReporter config.Reporter) error {
+	maintenanceWindows, err := provider.ParseMaintenanceWindows(configReporter.GetWithDefault("maintenance_windows", MaintenanceWindowsDefault))
+	if err != nil {
+		return errors.Wrap(err, "maintenance windows is invalid")
+	}
+	c.MaintenanceWindows = maintenanceWindows
+
+	return nil
+}
+
+func (c *Config) Validate() error {
+	return nil
+}
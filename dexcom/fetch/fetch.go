@@ -1,6 +1,15 @@
 package fetch
 
+import oauthFetch "github.com/tidepool-org/platform/oauth/fetch"
+
+const ProviderName = "dexcom"
+
 const Type = "org.tidepool.oauth.dexcom.fetch"
 
 const DataSetClientName = Type
 const DataSetClientVersion = "1.0.0"
+
+const (
+	TaskModeBackfill    = oauthFetch.TaskModeBackfill
+	TaskModeIncremental = oauthFetch.TaskModeIncremental
+)
@@ -2,485 +2,194 @@ package fetch
 
 import (
 	"context"
-	"math/rand"
-	"sort"
+	"reflect"
 	"time"
 
+	"gopkg.in/mgo.v2/bson"
+
 	"github.com/tidepool-org/platform/auth"
 	"github.com/tidepool-org/platform/data"
 	dataClient "github.com/tidepool-org/platform/data/client"
-	"github.com/tidepool-org/platform/data/types/upload"
+	dataNormalizer "github.com/tidepool-org/platform/data/normalizer"
+	"github.com/tidepool-org/platform/data/types/settings/cgm"
 	"github.com/tidepool-org/platform/dexcom"
 	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/log"
-	"github.com/tidepool-org/platform/oauth"
-	oauthToken "github.com/tidepool-org/platform/oauth/token"
+	oauthFetch "github.com/tidepool-org/platform/oauth/fetch"
+	"github.com/tidepool-org/platform/page"
 	"github.com/tidepool-org/platform/pointer"
-	"github.com/tidepool-org/platform/request"
 	"github.com/tidepool-org/platform/structure"
 	structureValidator "github.com/tidepool-org/platform/structure/validator"
-	"github.com/tidepool-org/platform/task"
-	"github.com/tidepool-org/platform/version"
 )
 
+// Backfill fetches in the maximum time range supported by the Dexcom API, while incremental fetches,
+// which typically only span the time since the previous task, use a smaller range
 const (
-	AvailableAfterDurationMaximum = 75 * time.Minute
-	AvailableAfterDurationMinimum = 45 * time.Minute
-	DataSetSize                   = 2000
-	TaskDurationMaximum           = 5 * time.Minute
+	BackfillFetchDuration = 90 * 24 * time.Hour
+	FetchDuration         = 30 * 24 * time.Hour
 )
 
-var initialDataTime = time.Unix(1420070400, 0) // 2015-01-01T00:00:00Z
-
-type Runner struct {
-	logger          log.Logger
-	versionReporter version.Reporter
-	authClient      auth.Client
-	dataClient      dataClient.Client
-	dexcomClient    dexcom.Client
-}
-
-func NewRunner(logger log.Logger, versionReporter version.Reporter, authClient auth.Client, dataClient dataClient.Client, dexcomClient dexcom.Client) (*Runner, error) {
-	if logger == nil {
-		return nil, errors.New("logger is missing")
-	}
-	if versionReporter == nil {
-		return nil, errors.New("version reporter is missing")
-	}
-	if authClient == nil {
-		return nil, errors.New("auth client is missing")
-	}
-	if dataClient == nil {
-		return nil, errors.New("data client is missing")
-	}
-	if dexcomClient == nil {
-		return nil, errors.New("dexcom client is missing")
-	}
-
-	return &Runner{
-		logger:          logger,
-		versionReporter: versionReporter,
-		authClient:      authClient,
-		dataClient:      dataClient,
-		dexcomClient:    dexcomClient,
-	}, nil
-}
-
-func (r *Runner) Logger() log.Logger {
-	return r.logger
-}
-
-func (r *Runner) VersionReporter() version.Reporter {
-	return r.versionReporter
-}
-
-func (r *Runner) AuthClient() auth.Client {
-	return r.authClient
-}
-
-func (r *Runner) DataClient() dataClient.Client {
-	return r.dataClient
-}
-
-func (r *Runner) DexcomClient() dexcom.Client {
-	return r.dexcomClient
-}
-
-func (r *Runner) CanRunTask(tsk *task.Task) bool {
-	return tsk != nil && tsk.Type == Type
-}
-
-func (r *Runner) Run(ctx context.Context, tsk *task.Task) {
-	taskStartTime := time.Now()
-
-	ctx = log.NewContextWithLogger(ctx, r.Logger())
-
-	// HACK: Dexcom - skip 2:45am - 3:45am PST to avoid intermittent refresh token failure due to Dexcom backups (per Dexcom)
-	var skipToAvoidDexcomBackup bool
-	if location, err := time.LoadLocation("America/Los_Angeles"); err != nil {
-		r.Logger().WithError(err).Warn("Unable to load location to detect Dexcom backup")
-	} else {
-		tm := time.Now().In(location).Format("15:04:05")
-		skipToAvoidDexcomBackup = (tm >= "02:45:00") && (tm < "03:45:00")
-	}
-
-	if !skipToAvoidDexcomBackup {
-		tsk.ClearError()
-
-		if serverSessionToken, sErr := r.AuthClient().ServerSessionToken(); sErr != nil {
-			tsk.AppendError(errors.Wrap(sErr, "unable to get server session token"))
-		} else {
-			ctx = auth.NewContextWithServerSessionToken(ctx, serverSessionToken)
-
-			if taskRunner, tErr := NewTaskRunner(r, tsk); tErr != nil {
-				tsk.AppendError(errors.Wrap(tErr, "unable to create task runner"))
-			} else if tErr = taskRunner.Run(ctx); tErr != nil {
-				tsk.AppendError(errors.Wrap(tErr, "unable to run task runner"))
-			}
-		}
-	}
-
-	if !tsk.IsFailed() {
-		tsk.RepeatAvailableAfter(AvailableAfterDurationMinimum + time.Duration(rand.Int63n(int64(AvailableAfterDurationMaximum-AvailableAfterDurationMinimum+1))))
-	}
-
-	if taskDuration := time.Since(taskStartTime); taskDuration > TaskDurationMaximum {
-		r.Logger().WithField("taskDuration", taskDuration.Truncate(time.Millisecond).Seconds()).Warn("Task duration exceeds maximum")
-	}
-}
-
-type TaskRunner struct {
-	*Runner
-	task             *task.Task
-	context          context.Context
-	validator        structure.Validator
-	providerSession  *auth.ProviderSession
-	dataSource       *data.DataSource
-	tokenSource      oauth.TokenSource
-	dataSet          *data.DataSet
-	dataSetPreloaded bool
-}
-
-func NewTaskRunner(rnnr *Runner, tsk *task.Task) (*TaskRunner, error) {
-	if rnnr == nil {
-		return nil, errors.New("runner is missing")
-	}
-	if tsk == nil {
-		return nil, errors.New("task is missing")
-	}
-
-	return &TaskRunner{
-		Runner: rnnr,
-		task:   tsk,
-	}, nil
-}
-
-func (t *TaskRunner) Run(ctx context.Context) error {
-	if ctx == nil {
-		return errors.New("context is missing")
-	}
-
-	if len(t.task.Data) == 0 {
-		t.task.SetFailed()
-		return errors.New("data is missing")
-	}
-
-	t.context = ctx
-	t.validator = structureValidator.New()
-
-	if err := t.getProviderSession(); err != nil {
-		return err
-	}
-	if err := t.getDataSource(); err != nil {
-		return err
-	}
-	if err := t.createTokenSource(); err != nil {
-		return err
-	}
-	if err := t.fetchSinceLatestDataTime(); err != nil {
-		if request.IsErrorUnauthenticated(errors.Cause(err)) {
-			t.task.SetFailed()
-			if updateErr := t.updateDataSourceWithError(err); updateErr != nil {
-				t.Logger().WithError(updateErr).Error("unable to update data source with error")
-			}
-		}
-		return err
-	}
-	return t.updateDataSourceWithLastImportTime()
-}
-
-func (t *TaskRunner) getProviderSession() error {
-	providerSessionID, ok := t.task.Data["providerSessionId"].(string)
-	if !ok || providerSessionID == "" {
-		t.task.SetFailed()
-		return errors.New("provider session id is missing")
-	}
-
-	providerSession, err := t.AuthClient().GetProviderSession(t.context, providerSessionID)
-	if err != nil {
-		return errors.Wrap(err, "unable to get provider session")
-	} else if providerSession == nil {
-		t.task.SetFailed()
-		return errors.Wrap(err, "provider session is missing")
-	}
-	t.providerSession = providerSession
-
-	return nil
-}
-
-func (t *TaskRunner) updateProviderSession() error {
-	refreshedToken, err := t.tokenSource.RefreshedToken()
-	if err != nil {
-		return errors.Wrap(err, "unable to get refreshed token")
-	} else if refreshedToken == nil {
-		return nil
-	}
-
-	updateProviderSession := auth.NewProviderSessionUpdate()
-	updateProviderSession.OAuthToken = refreshedToken
-	providerSession, err := t.AuthClient().UpdateProviderSession(t.context, t.providerSession.ID, updateProviderSession)
-	if err != nil {
-		return errors.Wrap(err, "unable to update provider session")
-	} else if providerSession == nil {
-		t.task.SetFailed()
-		return errors.Wrap(err, "provider session is missing")
-	}
-	t.providerSession = providerSession
+const StoredCGMSettingsLimit = 10
 
-	return nil
-}
+var InitialDataTime = time.Unix(1420070400, 0) // 2015-01-01T00:00:00Z
 
-func (t *TaskRunner) getDataSource() error {
-	dataSourceID, ok := t.task.Data["dataSourceId"].(string)
-	if !ok || dataSourceID == "" {
-		t.task.SetFailed()
-		return errors.New("data source id is missing")
-	}
-
-	dataSource, err := t.DataClient().GetDataSource(t.context, dataSourceID)
+func NewRunner(cfg *Config, logger log.Logger, authClient auth.Client, dataClient dataClient.Client, dexcomClient dexcom.Client) (*oauthFetch.Runner, error) {
+	definition, err := NewDefinition(cfg, dataClient, dexcomClient)
 	if err != nil {
-		return errors.Wrap(err, "unable to get data source")
-	} else if dataSource == nil {
-		t.task.SetFailed()
-		return errors.Wrap(err, "data source is missing")
-	}
-	t.dataSource = dataSource
-
-	return nil
-}
-
-func (t *TaskRunner) updateDataSourceWithDataSet(dataSet *data.DataSet) error {
-	dataSourceUpdate := data.NewDataSourceUpdate()
-	dataSourceUpdate.DataSetIDs = pointer.FromStringArray(append(t.dataSource.DataSetIDs, *dataSet.UploadID))
-	return t.updateDataSource(dataSourceUpdate)
-}
-
-func (t *TaskRunner) updateDataSourceWithDataTime(earliestDataTime time.Time, latestDataTime time.Time) error {
-	dataSourceUpdate := data.NewDataSourceUpdate()
-
-	if t.beforeEarliestDataTime(earliestDataTime) {
-		dataSourceUpdate.EarliestDataTime = pointer.FromTime(earliestDataTime.Truncate(time.Second))
-	}
-	if t.afterLatestDataTime(latestDataTime) {
-		dataSourceUpdate.LatestDataTime = pointer.FromTime(latestDataTime.Truncate(time.Second))
-	}
-
-	if dataSourceUpdate.EarliestDataTime == nil && dataSourceUpdate.LatestDataTime == nil {
-		return nil
+		return nil, err
 	}
 
-	dataSourceUpdate.LastImportTime = pointer.FromTime(time.Now().Truncate(time.Second))
-	return t.updateDataSource(dataSourceUpdate)
-}
-
-func (t *TaskRunner) updateDataSourceWithLastImportTime() error {
-	dataSourceUpdate := data.NewDataSourceUpdate()
-	dataSourceUpdate.LastImportTime = pointer.FromTime(time.Now().Truncate(time.Second))
-	return t.updateDataSource(dataSourceUpdate)
+	return oauthFetch.NewRunner(logger, authClient, dataClient, definition)
 }
 
-func (t *TaskRunner) updateDataSourceWithError(err error) error {
-	dataSourceUpdate := data.NewDataSourceUpdate()
-	dataSourceUpdate.State = pointer.FromString(data.DataSourceStateError)
-	dataSourceUpdate.Error = &errors.Serializable{Error: err}
-	return t.updateDataSource(dataSourceUpdate)
-}
-
-func (t *TaskRunner) updateDataSource(dataSourceUpdate *data.DataSourceUpdate) error {
-	if !dataSourceUpdate.HasUpdates() {
-		return nil
+func NewDefinition(cfg *Config, dataClient dataClient.Client, dexcomClient dexcom.Client) (*oauthFetch.Definition, error) {
+	if cfg == nil {
+		return nil, errors.New("config is missing")
+	} else if err := cfg.Validate(); err != nil {
+		return nil, errors.Wrap(err, "config is invalid")
 	}
 
-	dataSource, err := t.DataClient().UpdateDataSource(t.context, t.dataSource.ID, dataSourceUpdate)
-	if err != nil {
-		return errors.Wrap(err, "unable to update data source")
-	} else if dataSource == nil {
-		t.task.SetFailed()
-		return errors.Wrap(err, "data source is missing")
-	}
-
-	t.dataSource = dataSource
-	return nil
-}
-
-func (t *TaskRunner) createTokenSource() error {
-	tokenSource, err := oauthToken.NewSourceWithToken(t.providerSession.OAuthToken)
+	fetcher, err := NewFetcher(dataClient, dexcomClient)
 	if err != nil {
-		t.task.SetFailed()
-		return errors.Wrap(err, "unable to create token source")
+		return nil, err
 	}
 
-	t.tokenSource = tokenSource
-	return nil
+	return &oauthFetch.Definition{
+		ProviderName:          ProviderName,
+		TaskType:              Type,
+		DataSetClientName:     DataSetClientName,
+		DataSetClientVersion:  DataSetClientVersion,
+		DeviceManufacturers:   []string{"Dexcom"},
+		DeviceModel:           "Unknown",
+		DeviceTags:            []string{data.DeviceTagCGM},
+		InitialDataTime:       InitialDataTime,
+		FetchDuration:         FetchDuration,
+		BackfillFetchDuration: BackfillFetchDuration,
+		MaintenanceWindows:    cfg.MaintenanceWindows,
+		Fetcher:               fetcher,
+	}, nil
 }
 
-func (t *TaskRunner) updateDataSetWithDeviceInfo(deviceInfo *DeviceInfo) error {
-	dataSetDeviceInfo, err := NewDeviceInfoFromDataSet(t.dataSet)
-	if err != nil {
-		return err
-	}
-	dataSetDeviceInfo, err = dataSetDeviceInfo.Merge(deviceInfo)
-	if err != nil {
-		return err
-	}
-
-	dataSetUpdate := data.NewDataSetUpdate()
-	if t.dataSet.DeviceID == nil || *t.dataSet.DeviceID != dataSetDeviceInfo.DeviceID {
-		dataSetUpdate.DeviceID = pointer.FromString(dataSetDeviceInfo.DeviceID)
-	}
-	if t.dataSet.DeviceModel == nil || *t.dataSet.DeviceModel != dataSetDeviceInfo.DeviceModel {
-		dataSetUpdate.DeviceModel = pointer.FromString(dataSetDeviceInfo.DeviceModel)
-	}
-	if t.dataSet.DeviceSerialNumber == nil || *t.dataSet.DeviceSerialNumber != dataSetDeviceInfo.DeviceSerialNumber {
-		dataSetUpdate.DeviceSerialNumber = pointer.FromString(dataSetDeviceInfo.DeviceSerialNumber)
-	}
-	return t.updateDataSet(dataSetUpdate)
+// Fetcher fetches devices, calibrations, EGVs, and events from Dexcom and derives the data set
+// device details from the devices fetched
+type Fetcher struct {
+	dataClient   dataClient.Client
+	dexcomClient dexcom.Client
 }
 
-func (t *TaskRunner) updateDataSet(dataSetUpdate *data.DataSetUpdate) error {
-	if !dataSetUpdate.HasUpdates() {
-		return nil
+func NewFetcher(dataClient dataClient.Client, dexcomClient dexcom.Client) (*Fetcher, error) {
+	if dataClient == nil {
+		return nil, errors.New("data client is missing")
 	}
-
-	dataSet, err := t.DataClient().UpdateDataSet(t.context, *t.dataSet.UploadID, dataSetUpdate)
-	if err != nil {
-		return errors.Wrap(err, "unable to update data set")
-	} else if dataSet == nil {
-		t.task.SetFailed()
-		return errors.Wrap(err, "data set is missing")
+	if dexcomClient == nil {
+		return nil, errors.New("dexcom client is missing")
 	}
 
-	t.dataSet = dataSet
-	return nil
+	return &Fetcher{
+		dataClient:   dataClient,
+		dexcomClient: dexcomClient,
+	}, nil
 }
 
-func (t *TaskRunner) fetchSinceLatestDataTime() error {
-	startTime := initialDataTime
-	if t.dataSource.LatestDataTime != nil && startTime.Before(*t.dataSource.LatestDataTime) {
-		startTime = *t.dataSource.LatestDataTime
+func (f *Fetcher) Fetch(ctx context.Context, session oauthFetch.Session, startTime time.Time, endTime time.Time) (*oauthFetch.Result, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
 	}
-
-	now := time.Now().Add(-time.Minute).Truncate(time.Second)
-	for startTime.Before(now) {
-		endTime := startTime.AddDate(0, 0, 90)
-		if endTime.After(now) {
-			endTime = now
-		}
-
-		if err := t.fetch(startTime, endTime); err != nil {
-			return err
-		}
-
-		startTime = startTime.AddDate(0, 0, 90)
-		now = time.Now().Add(-time.Minute).Truncate(time.Second)
+	if session == nil {
+		return nil, errors.New("session is missing")
 	}
-	return nil
-}
 
-func (t *TaskRunner) fetch(startTime time.Time, endTime time.Time) error {
-	devices, err := t.fetchDevices(startTime, endTime)
+	devices, err := f.fetchDevices(ctx, session, startTime, endTime)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// HACK: Dexcom - does not guarantee to return a device for G5 Mobile if time range < 24 hours (per Dexcom)
 	var deviceInfo *DeviceInfo
 	if endTime.Sub(startTime) > 24*time.Hour {
 		if len(devices) == 0 {
-			return nil
-		} else if deviceInfo, err = t.calculateDeviceInfo(devices); err != nil {
-			return err
+			return nil, nil
+		} else if deviceInfo, err = calculateDeviceInfo(devices); err != nil {
+			return nil, err
 		}
 	} else {
-		if err = t.preloadDataSet(); err != nil {
-			return err
-		} else if t.dataSet == nil {
-			return nil
-		} else if deviceInfo, err = NewDeviceInfoFromDataSet(t.dataSet); err != nil {
-			return err
+		if dataSet, err := session.DataSet(); err != nil {
+			return nil, err
+		} else if dataSet == nil {
+			return nil, nil
+		} else if deviceInfo, err = NewDeviceInfoFromDataSet(dataSet); err != nil {
+			return nil, err
 		} else if !deviceInfo.IsDeviceModelG5Mobile() && !deviceInfo.IsDeviceModelUnknown() {
 			deviceInfo = NewDeviceInfoFromMultiple()
 		}
 	}
 
-	datumArray, err := t.fetchData(startTime, endTime)
+	datumArray, err := f.fetchData(ctx, session, startTime, endTime)
 	if err != nil {
-		return err
-	} else if len(datumArray) == 0 {
-		return nil
+		return nil, err
 	}
 
-	if err = t.prepareDatumArray(datumArray, deviceInfo); err != nil {
-		return err
+	cgmSettingsDatumArray, err := f.translateDevices(ctx, session, devices, endTime)
+	if err != nil {
+		return nil, err
 	}
 
-	if err = t.prepareDataSet(deviceInfo); err != nil {
-		return err
+	datumArray = append(datumArray, cgmSettingsDatumArray...)
+	if len(datumArray) == 0 {
+		return nil, nil
 	}
 
-	return t.storeDatumArray(datumArray)
-}
+	prepareDatumArray(datumArray, deviceInfo)
 
-func (t *TaskRunner) fetchDevices(startTime time.Time, endTime time.Time) ([]*dexcom.Device, error) {
-	response, err := t.DexcomClient().GetDevices(t.context, startTime, endTime, t.tokenSource)
-	if updateErr := t.updateProviderSession(); updateErr != nil {
-		return nil, updateErr
-	}
+	dataSetDeviceInfo, err := calculateDataSetDeviceInfo(session, deviceInfo)
 	if err != nil {
 		return nil, err
 	}
 
-	t.validator.Validate(response)
-	if err = t.validator.Error(); err != nil {
-		return nil, err
+	result := &oauthFetch.Result{
+		DeviceID:           pointer.FromString(dataSetDeviceInfo.DeviceID),
+		DeviceModel:        pointer.FromString(dataSetDeviceInfo.DeviceModel),
+		DeviceSerialNumber: pointer.FromString(dataSetDeviceInfo.DeviceSerialNumber),
+	}
+	for _, datum := range datumArray {
+		result.Data = append(result.Data, &oauthFetch.Datum{Time: payloadSystemTime(datum), Datum: datum})
 	}
 
-	return response.Devices, nil
+	return result, nil
 }
 
-func (t *TaskRunner) preloadDataSet() error {
-	if t.dataSet != nil || t.dataSetPreloaded {
-		return nil
+func (f *Fetcher) fetchDevices(ctx context.Context, session oauthFetch.Session, startTime time.Time, endTime time.Time) ([]*dexcom.Device, error) {
+	response, err := f.dexcomClient.GetDevices(ctx, startTime, endTime, session.TokenSource())
+	if updateErr := session.UpdateProviderSession(); updateErr != nil {
+		return nil, updateErr
 	}
-
-	dataSet, err := t.findDataSet()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	t.dataSet = dataSet
-	t.dataSetPreloaded = true
-	return nil
-}
-
-func (t *TaskRunner) calculateDeviceInfo(devices []*dexcom.Device) (*DeviceInfo, error) {
-	deviceInfo := NewDeviceInfo()
-	for _, device := range devices {
-		if deviceDeviceInfo, err := NewDeviceInfoFromDevice(device); err != nil {
-			return nil, err
-		} else if deviceInfo, err = deviceInfo.Merge(deviceDeviceInfo); err != nil {
-			return nil, err
-		}
+	if err = structureValidator.New().Validate(response); err != nil {
+		return nil, err
 	}
-	return deviceInfo, nil
+
+	return response.Devices, nil
 }
 
-func (t *TaskRunner) fetchData(startTime time.Time, endTime time.Time) ([]data.Datum, error) {
+func (f *Fetcher) fetchData(ctx context.Context, session oauthFetch.Session, startTime time.Time, endTime time.Time) ([]data.Datum, error) {
 	datumArray := []data.Datum{}
 
-	fetchDatumArray, err := t.fetchCalibrations(startTime, endTime)
+	fetchDatumArray, err := f.fetchCalibrations(ctx, session, startTime, endTime)
 	if err != nil {
 		return nil, err
 	}
 	datumArray = append(datumArray, fetchDatumArray...)
 
-	fetchDatumArray, err = t.fetchEGVs(startTime, endTime)
+	fetchDatumArray, err = f.fetchEGVs(ctx, session, startTime, endTime)
 	if err != nil {
 		return nil, err
 	}
 	datumArray = append(datumArray, fetchDatumArray...)
 
-	fetchDatumArray, err = t.fetchEvents(startTime, endTime)
+	fetchDatumArray, err = f.fetchEvents(ctx, session, startTime, endTime)
 	if err != nil {
 		return nil, err
 	}
@@ -489,186 +198,203 @@ func (t *TaskRunner) fetchData(startTime time.Time, endTime time.Time) ([]data.D
 	return datumArray, nil
 }
 
-func (t *TaskRunner) fetchCalibrations(startTime time.Time, endTime time.Time) ([]data.Datum, error) {
-	response, err := t.DexcomClient().GetCalibrations(t.context, startTime, endTime, t.tokenSource)
-	if updateErr := t.updateProviderSession(); updateErr != nil {
+func (f *Fetcher) fetchCalibrations(ctx context.Context, session oauthFetch.Session, startTime time.Time, endTime time.Time) ([]data.Datum, error) {
+	response, err := f.dexcomClient.GetCalibrations(ctx, startTime, endTime, session.TokenSource())
+	if updateErr := session.UpdateProviderSession(); updateErr != nil {
 		return nil, updateErr
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	t.validator.Validate(response)
-	if err = t.validator.Error(); err != nil {
+	if err = structureValidator.New().Validate(response); err != nil {
 		return nil, err
 	}
 
 	datumArray := []data.Datum{}
 	for _, c := range response.Calibrations {
-		if t.afterLatestDataTime(c.SystemTime) {
-			datumArray = append(datumArray, translateCalibrationToDatum(c))
-		}
+		datumArray = append(datumArray, translateCalibrationToDatum(c))
 	}
 
 	return datumArray, nil
 }
 
-func (t *TaskRunner) fetchEGVs(startTime time.Time, endTime time.Time) ([]data.Datum, error) {
-	response, err := t.DexcomClient().GetEGVs(t.context, startTime, endTime, t.tokenSource)
-	if updateErr := t.updateProviderSession(); updateErr != nil {
+func (f *Fetcher) fetchEGVs(ctx context.Context, session oauthFetch.Session, startTime time.Time, endTime time.Time) ([]data.Datum, error) {
+	response, err := f.dexcomClient.GetEGVs(ctx, startTime, endTime, session.TokenSource())
+	if updateErr := session.UpdateProviderSession(); updateErr != nil {
 		return nil, updateErr
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	t.validator.Validate(response)
-	if err = t.validator.Error(); err != nil {
+	if err = structureValidator.New().Validate(response); err != nil {
 		return nil, err
 	}
 
 	datumArray := []data.Datum{}
 	for _, e := range response.EGVs {
-		if t.afterLatestDataTime(e.SystemTime) {
-			datumArray = append(datumArray, translateEGVToDatum(e, response.Unit, response.RateUnit))
-		}
+		datumArray = append(datumArray, translateEGVToDatum(e, response.Unit, response.RateUnit))
 	}
 
 	return datumArray, nil
 }
 
-func (t *TaskRunner) fetchEvents(startTime time.Time, endTime time.Time) ([]data.Datum, error) {
-	response, err := t.DexcomClient().GetEvents(t.context, startTime, endTime, t.tokenSource)
-	if updateErr := t.updateProviderSession(); updateErr != nil {
+func (f *Fetcher) fetchEvents(ctx context.Context, session oauthFetch.Session, startTime time.Time, endTime time.Time) ([]data.Datum, error) {
+	response, err := f.dexcomClient.GetEvents(ctx, startTime, endTime, session.TokenSource())
+	if updateErr := session.UpdateProviderSession(); updateErr != nil {
 		return nil, updateErr
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	t.validator.Validate(response)
-	if err = t.validator.Error(); err != nil {
+	if err = structureValidator.New().Validate(response); err != nil {
 		return nil, err
 	}
 
 	datumArray := []data.Datum{}
 	for _, e := range response.Events {
-		if t.afterLatestDataTime(e.SystemTime) {
-			switch e.EventType {
-			case dexcom.EventCarbs:
-				datumArray = append(datumArray, translateEventCarbsToDatum(e))
-			case dexcom.EventExercise:
-				datumArray = append(datumArray, translateEventExerciseToDatum(e))
-			case dexcom.EventHealth:
-				datumArray = append(datumArray, translateEventHealthToDatum(e))
-			case dexcom.EventInsulin:
-				datumArray = append(datumArray, translateEventInsulinToDatum(e))
-			}
+		switch e.EventType {
+		case dexcom.EventCarbs:
+			datumArray = append(datumArray, translateEventCarbsToDatum(e))
+		case dexcom.EventExercise:
+			datumArray = append(datumArray, translateEventExerciseToDatum(e))
+		case dexcom.EventHealth:
+			datumArray = append(datumArray, translateEventHealthToDatum(e))
+		case dexcom.EventInsulin:
+			datumArray = append(datumArray, translateEventInsulinToDatum(e))
 		}
 	}
 
 	return datumArray, nil
 }
 
-func (t *TaskRunner) prepareDatumArray(datumArray []data.Datum, deviceInfo *DeviceInfo) error {
-	var datumDeviceID *string
-	if deviceInfo.DeviceID != dexcom.DeviceIDMultiple {
-		datumDeviceID = pointer.FromString(deviceInfo.DeviceID)
-	} else {
-		datumDeviceID = pointer.FromString(dexcom.DeviceIDUnknown)
-	}
-
-	for _, datum := range datumArray {
-		datum.SetDeviceID(datumDeviceID)
-	}
-
-	sort.Sort(BySystemTime(datumArray))
+// Device alert settings include the time each alert was last changed, so only emit CGM settings once
+// the most recent change is within the fetch time range and only if they differ from the CGM settings
+// most recently stored for the same device
+func (f *Fetcher) translateDevices(ctx context.Context, session oauthFetch.Session, devices []*dexcom.Device, endTime time.Time) ([]data.Datum, error) {
+	var storedDatumArray []*cgm.CGM
+	datumArray := []data.Datum{}
+	for _, device := range devices {
+		datum := translateDeviceToCGMSettingsDatum(device)
+		if datum == nil || payloadSystemTime(datum).After(endTime) {
+			continue
+		}
 
-	return nil
-}
+		if storedDatumArray == nil {
+			var err error
+			if storedDatumArray, err = f.listStoredCGMSettings(ctx, session.DataSource().UserID); err != nil {
+				return nil, err
+			}
+		}
 
-func (t *TaskRunner) prepareDataSet(deviceInfo *DeviceInfo) error {
-	if err := t.preloadDataSet(); err != nil {
-		return err
+		if storedDatum := findCGMSettingsForDevice(storedDatumArray, device); storedDatum == nil || !isCGMSettingsEqual(storedDatum, device) {
+			datumArray = append(datumArray, datum)
+		}
 	}
+	return datumArray, nil
+}
 
-	if t.dataSet != nil {
-		return t.updateDataSetWithDeviceInfo(deviceInfo)
-	}
+func (f *Fetcher) listStoredCGMSettings(ctx context.Context, userID string) ([]*cgm.CGM, error) {
+	filter := data.NewDatumFilter()
+	filter.Types = pointer.FromStringArray([]string{cgm.Type})
+	pagination := page.NewPagination()
+	pagination.Size = StoredCGMSettingsLimit
 
-	dataSet, err := t.createDataSet(deviceInfo)
+	blobs, err := f.dataClient.ListUserData(ctx, userID, filter, pagination)
 	if err != nil {
-		return err
+		return nil, errors.Wrap(err, "unable to list stored cgm settings")
 	}
-	t.dataSet = dataSet
-	return nil
-}
 
-func (t *TaskRunner) findDataSet() (*data.DataSet, error) {
-	for index := len(t.dataSource.DataSetIDs) - 1; index >= 0; index-- {
-		if dataSet, err := t.DataClient().GetDataSet(t.context, t.dataSource.DataSetIDs[index]); err != nil {
-			return nil, errors.Wrap(err, "unable to get data set")
-		} else if dataSet != nil {
-			return dataSet, nil
+	datumArray := []*cgm.CGM{}
+	for _, blob := range blobs {
+		datum, err := parseStoredCGMSettings(blob)
+		if err != nil {
+			return nil, err
 		}
+		datumArray = append(datumArray, datum)
 	}
-	return nil, nil
+	return datumArray, nil
 }
 
-func (t *TaskRunner) createDataSet(deviceInfo *DeviceInfo) (*data.DataSet, error) {
-	dataSetCreate := data.NewDataSetCreate()
-	dataSetCreate.Client = &data.DataSetClient{
-		Name:    pointer.FromString(DataSetClientName),
-		Version: pointer.FromString(DataSetClientVersion),
-	}
-	dataSetCreate.DataSetType = pointer.FromString(data.DataSetTypeContinuous)
-	dataSetCreate.DeviceID = pointer.FromString(deviceInfo.DeviceID)
-	dataSetCreate.DeviceManufacturers = pointer.FromStringArray([]string{"Dexcom"})
-	dataSetCreate.DeviceModel = pointer.FromString(deviceInfo.DeviceModel)
-	dataSetCreate.DeviceSerialNumber = pointer.FromString(deviceInfo.DeviceSerialNumber)
-	dataSetCreate.DeviceTags = pointer.FromStringArray([]string{data.DeviceTagCGM})
-	dataSetCreate.Time = pointer.FromTime(time.Now().Truncate(time.Second))
-	dataSetCreate.TimeProcessing = pointer.FromString(upload.TimeProcessingNone)
-
-	dataSet, err := t.DataClient().CreateUserDataSet(t.context, t.providerSession.UserID, dataSetCreate)
+// Stored data uses the store field names, which differ from the JSON field names for some CGM settings
+func parseStoredCGMSettings(blob data.Blob) (*cgm.CGM, error) {
+	bytes, err := bson.Marshal(blob)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to create data set")
-	}
-	if err = t.updateDataSourceWithDataSet(dataSet); err != nil {
-		return nil, err
+		return nil, errors.Wrap(err, "unable to marshal stored cgm settings")
 	}
 
-	return dataSet, nil
+	datum := cgm.New()
+	if err = bson.Unmarshal(bytes, datum); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal stored cgm settings")
+	}
+	return datum, nil
 }
 
-func (t *TaskRunner) storeDatumArray(datumArray []data.Datum) error {
-	length := len(datumArray)
-	for startIndex := 0; startIndex < length; startIndex += DataSetSize {
-		endIndex := startIndex + DataSetSize
-		if endIndex > length {
-			endIndex = length
+// The stored data is sorted by time, most recent first
+func findCGMSettingsForDevice(datumArray []*cgm.CGM, device *dexcom.Device) *cgm.CGM {
+	for _, datum := range datumArray {
+		if datum.Model != nil && *datum.Model == device.Model && reflect.DeepEqual(datum.SerialNumber, device.SerialNumber) {
+			return datum
 		}
+	}
+	return nil
+}
 
-		if err := t.DataClient().CreateDataSetsData(t.context, *t.dataSet.UploadID, datumArray[startIndex:endIndex]); err != nil {
-			return errors.Wrap(err, "unable to create data set data")
-		}
+// The device settings are translated and normalized as they would be when stored before comparing
+func isCGMSettingsEqual(storedDatum *cgm.CGM, device *dexcom.Device) bool {
+	datum := translateDeviceToCGMSettingsDatum(device)
+	datum.Normalize(dataNormalizer.New().WithOrigin(structure.OriginExternal))
 
-		earliestDataTime := payloadSystemTime(datumArray[0])
-		latestDataTime := payloadSystemTime(datumArray[endIndex-1])
-		if err := t.updateDataSourceWithDataTime(earliestDataTime, latestDataTime); err != nil {
-			return err
+	return reflect.DeepEqual(storedDatum.HighLevelAlert, datum.HighLevelAlert) &&
+		reflect.DeepEqual(storedDatum.LowLevelAlert, datum.LowLevelAlert) &&
+		reflect.DeepEqual(storedDatum.OutOfRangeAlert, datum.OutOfRangeAlert) &&
+		reflect.DeepEqual(storedDatum.RateAlerts, datum.RateAlerts) &&
+		reflect.DeepEqual(storedDatum.TransmitterID, datum.TransmitterID) &&
+		reflect.DeepEqual(storedDatum.Units, datum.Units)
+}
+
+func calculateDeviceInfo(devices []*dexcom.Device) (*DeviceInfo, error) {
+	deviceInfo := NewDeviceInfo()
+	for _, device := range devices {
+		if deviceDeviceInfo, err := NewDeviceInfoFromDevice(device); err != nil {
+			return nil, err
+		} else if deviceInfo, err = deviceInfo.Merge(deviceDeviceInfo); err != nil {
+			return nil, err
 		}
 	}
-
-	return nil
+	return deviceInfo, nil
 }
 
-func (t *TaskRunner) beforeEarliestDataTime(earliestDataTime time.Time) bool {
-	return t.dataSource.EarliestDataTime == nil || earliestDataTime.Before(*t.dataSource.EarliestDataTime)
+// The data set device info is the device info of the fetched data merged into that of the existing
+// data set, if any
+func calculateDataSetDeviceInfo(session oauthFetch.Session, deviceInfo *DeviceInfo) (*DeviceInfo, error) {
+	dataSet, err := session.DataSet()
+	if err != nil {
+		return nil, err
+	} else if dataSet == nil {
+		return deviceInfo, nil
+	}
+
+	dataSetDeviceInfo, err := NewDeviceInfoFromDataSet(dataSet)
+	if err != nil {
+		return nil, err
+	}
+	return dataSetDeviceInfo.Merge(deviceInfo)
 }
 
-func (t *TaskRunner) afterLatestDataTime(latestDataTime time.Time) bool {
-	return t.dataSource.LatestDataTime == nil || latestDataTime.After(*t.dataSource.LatestDataTime)
+func prepareDatumArray(datumArray []data.Datum, deviceInfo *DeviceInfo) {
+	var datumDeviceID *string
+	if deviceInfo.DeviceID != dexcom.DeviceIDMultiple {
+		datumDeviceID = pointer.FromString(deviceInfo.DeviceID)
+	} else {
+		datumDeviceID = pointer.FromString(dexcom.DeviceIDUnknown)
+	}
+
+	for _, datum := range datumArray {
+		datum.SetDeviceID(datumDeviceID)
+	}
 }
 
 func payloadSystemTime(datum data.Datum) time.Time {
@@ -687,20 +413,6 @@ func payloadSystemTime(datum data.Datum) time.Time {
 	return systemTime
 }
 
-type BySystemTime []data.Datum
-
-func (b BySystemTime) Len() int {
-	return len(b)
-}
-
-func (b BySystemTime) Swap(left int, right int) {
-	b[left], b[right] = b[right], b[left]
-}
-
-func (b BySystemTime) Less(left int, right int) bool {
-	return payloadSystemTime(b[left]).Before(payloadSystemTime(b[right]))
-}
-
 type DeviceInfo struct {
 	DeviceID           string
 	DeviceModel        string
@@ -1 +1,277 @@
 package fetch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"errors"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/tidepool-org/platform/data"
+	dataClientTest "github.com/tidepool-org/platform/data/client/test"
+	dataNormalizer "github.com/tidepool-org/platform/data/normalizer"
+	"github.com/tidepool-org/platform/data/types/settings/cgm"
+	"github.com/tidepool-org/platform/dexcom"
+	dexcomFetch "github.com/tidepool-org/platform/dexcom/fetch"
+	dexcomTest "github.com/tidepool-org/platform/dexcom/test"
+	"github.com/tidepool-org/platform/oauth"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/structure"
+	"github.com/tidepool-org/platform/test"
+)
+
+type dexcomClient struct {
+	DevicesResponse *dexcom.DevicesResponse
+}
+
+func (d *dexcomClient) GetCalibrations(ctx context.Context, startTime time.Time, endTime time.Time, tokenSource oauth.TokenSource) (*dexcom.CalibrationsResponse, error) {
+	return dexcom.NewCalibrationsResponse(), nil
+}
+
+func (d *dexcomClient) GetDevices(ctx context.Context, startTime time.Time, endTime time.Time, tokenSource oauth.TokenSource) (*dexcom.DevicesResponse, error) {
+	return d.DevicesResponse, nil
+}
+
+func (d *dexcomClient) GetEGVs(ctx context.Context, startTime time.Time, endTime time.Time, tokenSource oauth.TokenSource) (*dexcom.EGVsResponse, error) {
+	response := dexcom.NewEGVsResponse()
+	response.Unit = dexcom.UnitMgdL
+	response.RateUnit = dexcom.UnitMgdLMin
+	return response, nil
+}
+
+func (d *dexcomClient) GetEvents(ctx context.Context, startTime time.Time, endTime time.Time, tokenSource oauth.TokenSource) (*dexcom.EventsResponse, error) {
+	return dexcom.NewEventsResponse(), nil
+}
+
+type session struct {
+	dataSource *data.DataSource
+}
+
+func (s *session) TokenSource() oauth.TokenSource {
+	return nil
+}
+
+func (s *session) UpdateProviderSession() error {
+	return nil
+}
+
+func (s *session) DataSource() *data.DataSource {
+	return s.dataSource
+}
+
+func (s *session) DataSet() (*data.DataSet, error) {
+	return nil, nil
+}
+
+var _ = Describe("Runner", func() {
+	Context("NewFetcher", func() {
+		It("returns an error if the data client is missing", func() {
+			fetcher, err := dexcomFetch.NewFetcher(nil, &dexcomClient{})
+			Expect(err).To(MatchError("data client is missing"))
+			Expect(fetcher).To(BeNil())
+		})
+
+		It("returns an error if the dexcom client is missing", func() {
+			fetcher, err := dexcomFetch.NewFetcher(dataClientTest.NewClient(), nil)
+			Expect(err).To(MatchError("dexcom client is missing"))
+			Expect(fetcher).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			Expect(dexcomFetch.NewFetcher(dataClientTest.NewClient(), &dexcomClient{})).ToNot(BeNil())
+		})
+	})
+
+	Context("with new fetcher", func() {
+		var ctx context.Context
+		var dataClient *dataClientTest.Client
+		var client *dexcomClient
+		var fetcher *dexcomFetch.Fetcher
+		var fetchSession *session
+		var endTime time.Time
+		var startTime time.Time
+		var systemTime time.Time
+		var device *dexcom.Device
+
+		BeforeEach(func() {
+			var err error
+			ctx = context.Background()
+			dataClient = dataClientTest.NewClient()
+			client = &dexcomClient{DevicesResponse: dexcom.NewDevicesResponse()}
+			fetcher, err = dexcomFetch.NewFetcher(dataClient, client)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fetcher).ToNot(BeNil())
+			fetchSession = &session{dataSource: &data.DataSource{ID: test.RandomString(), UserID: test.RandomString()}}
+			endTime = time.Now().Add(-time.Hour).Truncate(time.Second)
+			startTime = endTime.Add(-7 * 24 * time.Hour)
+			systemTime = endTime.Add(-24 * time.Hour)
+			device = dexcom.NewDevice()
+			device.Model = dexcom.ModelG5MobileApp
+			device.LastUploadDate = endTime.Add(-time.Hour)
+			device.SerialNumber = pointer.FromString(test.RandomStringFromRangeAndCharset(10, 10, test.CharsetNumeric))
+			device.TransmitterID = pointer.FromString("ABC123")
+			for index, alertName := range []string{dexcom.AlertNameLow, dexcom.AlertNameHigh, dexcom.AlertNameRise, dexcom.AlertNameFall, dexcom.AlertNameOutOfRange} {
+				alertSetting := dexcomTest.RandomAlertSettingWithAlertName(alertName)
+				alertSetting.SystemTime = systemTime.Add(-time.Duration(index) * time.Hour)
+				alertSetting.DisplayTime = alertSetting.SystemTime
+				device.AlertSettings = append(device.AlertSettings, alertSetting)
+			}
+			client.DevicesResponse.Devices = []*dexcom.Device{device}
+		})
+
+		AfterEach(func() {
+			dataClient.AssertOutputsEmpty()
+		})
+
+		alertSettingForName := func(alertName string) *dexcom.AlertSetting {
+			for _, alertSetting := range device.AlertSettings {
+				if alertSetting.AlertName == alertName {
+					return alertSetting
+				}
+			}
+			return nil
+		}
+
+		storedBlobForDatum := func(datum data.Datum) data.Blob {
+			datum.Normalize(dataNormalizer.New().WithOrigin(structure.OriginExternal))
+			bytes, err := bson.Marshal(datum)
+			Expect(err).ToNot(HaveOccurred())
+			blob := data.Blob{}
+			Expect(bson.Unmarshal(bytes, &blob)).To(Succeed())
+			return blob
+		}
+
+		It("returns an error if the context is missing", func() {
+			result, err := fetcher.Fetch(nil, fetchSession, startTime, endTime)
+			Expect(err).To(MatchError("context is missing"))
+			Expect(result).To(BeNil())
+		})
+
+		It("returns an error if the session is missing", func() {
+			result, err := fetcher.Fetch(ctx, nil, startTime, endTime)
+			Expect(err).To(MatchError("session is missing"))
+			Expect(result).To(BeNil())
+		})
+
+		It("returns no result if there are no devices", func() {
+			client.DevicesResponse.Devices = nil
+			Expect(fetcher.Fetch(ctx, fetchSession, startTime, endTime)).To(BeNil())
+			Expect(dataClient.ListUserDataInvocations).To(Equal(0))
+		})
+
+		It("returns an error if the stored cgm settings cannot be listed", func() {
+			responseErr := errors.New("test error")
+			dataClient.ListUserDataOutputs = []dataClientTest.ListUserDataOutput{{Error: responseErr}}
+			result, err := fetcher.Fetch(ctx, fetchSession, startTime, endTime)
+			Expect(err).To(MatchError("unable to list stored cgm settings; test error"))
+			Expect(result).To(BeNil())
+		})
+
+		It("returns the translated cgm settings if there are no stored cgm settings", func() {
+			dataClient.ListUserDataOutputs = []dataClientTest.ListUserDataOutput{{Data: []data.Blob{}}}
+			result, err := fetcher.Fetch(ctx, fetchSession, startTime, endTime)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).ToNot(BeNil())
+			Expect(result.Data).To(HaveLen(1))
+			Expect(result.Data[0].Time).To(Equal(systemTime))
+			Expect(result.DeviceModel).To(Equal(pointer.FromString("G5Mobile")))
+			Expect(result.DeviceSerialNumber).To(Equal(device.SerialNumber))
+			Expect(dataClient.ListUserDataInputs).To(HaveLen(1))
+			Expect(dataClient.ListUserDataInputs[0].UserID).To(Equal(fetchSession.dataSource.UserID))
+			Expect(dataClient.ListUserDataInputs[0].Filter.Types).To(Equal(pointer.FromStringArray([]string{cgm.Type})))
+			Expect(dataClient.ListUserDataInputs[0].Pagination.Size).To(Equal(dexcomFetch.StoredCGMSettingsLimit))
+
+			datum, ok := result.Data[0].Datum.(*cgm.CGM)
+			Expect(ok).To(BeTrue())
+			highAlertSetting := alertSettingForName(dexcom.AlertNameHigh)
+			lowAlertSetting := alertSettingForName(dexcom.AlertNameLow)
+			riseAlertSetting := alertSettingForName(dexcom.AlertNameRise)
+			fallAlertSetting := alertSettingForName(dexcom.AlertNameFall)
+			outOfRangeAlertSetting := alertSettingForName(dexcom.AlertNameOutOfRange)
+			Expect(datum.HighLevelAlert.Enabled).To(Equal(pointer.FromBool(highAlertSetting.Enabled)))
+			Expect(datum.HighLevelAlert.Level).To(Equal(pointer.FromFloat64(highAlertSetting.Value)))
+			Expect(datum.HighLevelAlert.Snooze).To(Equal(pointer.FromInt(highAlertSetting.Snooze * 60000)))
+			Expect(datum.LowLevelAlert.Enabled).To(Equal(pointer.FromBool(lowAlertSetting.Enabled)))
+			Expect(datum.LowLevelAlert.Level).To(Equal(pointer.FromFloat64(lowAlertSetting.Value)))
+			Expect(datum.LowLevelAlert.Snooze).To(Equal(pointer.FromInt(lowAlertSetting.Snooze * 60000)))
+			Expect(datum.RateAlerts.RiseRateAlert.Rate).To(Equal(pointer.FromFloat64(riseAlertSetting.Value)))
+			Expect(datum.RateAlerts.FallRateAlert.Rate).To(Equal(pointer.FromFloat64(-fallAlertSetting.Value)))
+			Expect(datum.OutOfRangeAlert.Threshold).To(Equal(pointer.FromInt(int(outOfRangeAlertSetting.Value) * 60000)))
+			Expect(datum.Manufacturers).To(Equal(pointer.FromStringArray([]string{cgm.ManufacturerDexcom})))
+			Expect(datum.Model).To(Equal(pointer.FromString(dexcom.ModelG5MobileApp)))
+			Expect(datum.SerialNumber).To(Equal(device.SerialNumber))
+			Expect(datum.TransmitterID).To(Equal(device.TransmitterID))
+			Expect(datum.Units).To(Equal(pointer.FromString(highAlertSetting.Unit)))
+		})
+
+		It("returns no cgm settings if the device does not have a transmitter id", func() {
+			device.TransmitterID = nil
+			Expect(fetcher.Fetch(ctx, fetchSession, startTime, endTime)).To(BeNil())
+			Expect(dataClient.ListUserDataInvocations).To(Equal(0))
+		})
+
+		It("returns no cgm settings if the device is missing an alert setting", func() {
+			device.AlertSettings = device.AlertSettings[1:]
+			Expect(fetcher.Fetch(ctx, fetchSession, startTime, endTime)).To(BeNil())
+			Expect(dataClient.ListUserDataInvocations).To(Equal(0))
+		})
+
+		It("returns no cgm settings if the most recent alert setting change is after the end time", func() {
+			device.AlertSettings[0].SystemTime = endTime.Add(time.Minute)
+			Expect(fetcher.Fetch(ctx, fetchSession, startTime, endTime)).To(BeNil())
+			Expect(dataClient.ListUserDataInvocations).To(Equal(0))
+		})
+
+		Context("with stored cgm settings", func() {
+			var storedBlob data.Blob
+
+			BeforeEach(func() {
+				dataClient.ListUserDataOutputs = []dataClientTest.ListUserDataOutput{{Data: []data.Blob{}}}
+				result, err := fetcher.Fetch(ctx, fetchSession, startTime, endTime)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).ToNot(BeNil())
+				Expect(result.Data).To(HaveLen(1))
+				storedBlob = storedBlobForDatum(result.Data[0].Datum)
+			})
+
+			It("returns no cgm settings if the stored cgm settings are the same", func() {
+				dataClient.ListUserDataOutputs = []dataClientTest.ListUserDataOutput{{Data: []data.Blob{storedBlob}}}
+				Expect(fetcher.Fetch(ctx, fetchSession, startTime, endTime)).To(BeNil())
+			})
+
+			It("returns no cgm settings if the most recent stored cgm settings for the device are the same", func() {
+				otherBlob := data.Blob{}
+				for key, value := range storedBlob {
+					otherBlob[key] = value
+				}
+				otherBlob["serialNumber"] = test.RandomString()
+				dataClient.ListUserDataOutputs = []dataClientTest.ListUserDataOutput{{Data: []data.Blob{otherBlob, storedBlob}}}
+				Expect(fetcher.Fetch(ctx, fetchSession, startTime, endTime)).To(BeNil())
+			})
+
+			It("returns the cgm settings if the stored cgm settings differ", func() {
+				alertSettingForName(dexcom.AlertNameHigh).Enabled = !alertSettingForName(dexcom.AlertNameHigh).Enabled
+				dataClient.ListUserDataOutputs = []dataClientTest.ListUserDataOutput{{Data: []data.Blob{storedBlob}}}
+				result, err := fetcher.Fetch(ctx, fetchSession, startTime, endTime)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).ToNot(BeNil())
+				Expect(result.Data).To(HaveLen(1))
+				datum, ok := result.Data[0].Datum.(*cgm.CGM)
+				Expect(ok).To(BeTrue())
+				Expect(datum.HighLevelAlert.Enabled).To(Equal(pointer.FromBool(alertSettingForName(dexcom.AlertNameHigh).Enabled)))
+			})
+
+			It("returns the cgm settings if the stored cgm settings are for a different device", func() {
+				device.SerialNumber = pointer.FromString(test.RandomStringFromRangeAndCharset(10, 10, test.CharsetNumeric))
+				dataClient.ListUserDataOutputs = []dataClientTest.ListUserDataOutput{{Data: []data.Blob{storedBlob}}}
+				result, err := fetcher.Fetch(ctx, fetchSession, startTime, endTime)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).ToNot(BeNil())
+				Expect(result.Data).To(HaveLen(1))
+			})
+		})
+	})
+})
@@ -10,6 +10,7 @@ import (
 	"github.com/tidepool-org/platform/data/types/device/calibration"
 	"github.com/tidepool-org/platform/data/types/food"
 	"github.com/tidepool-org/platform/data/types/insulin"
+	"github.com/tidepool-org/platform/data/types/settings/cgm"
 	"github.com/tidepool-org/platform/data/types/state/reported"
 	"github.com/tidepool-org/platform/dexcom"
 	"github.com/tidepool-org/platform/pointer"
@@ -71,6 +72,74 @@ func translateTime(systemTime time.Time, displayTime time.Time, datum *types.Bas
 	(*datum.Payload)["systemTime"] = systemTime
 }
 
+// The CGM settings require high, low, rise, fall, and out of range alerts as well as the
+// transmitter id, so if any are missing the device settings are not translated
+func translateDeviceToCGMSettingsDatum(d *dexcom.Device) *cgm.CGM {
+	if d.TransmitterID == nil {
+		return nil
+	}
+
+	var systemTime time.Time
+	var displayTime time.Time
+	alertSettings := map[string]*dexcom.AlertSetting{}
+	for _, alertSetting := range d.AlertSettings {
+		switch alertSetting.AlertName {
+		case dexcom.AlertNameLow, dexcom.AlertNameHigh, dexcom.AlertNameRise, dexcom.AlertNameFall, dexcom.AlertNameOutOfRange:
+			alertSettings[alertSetting.AlertName] = alertSetting
+			if alertSetting.SystemTime.After(systemTime) {
+				systemTime = alertSetting.SystemTime
+				displayTime = alertSetting.DisplayTime
+			}
+		}
+	}
+
+	highAlertSetting := alertSettings[dexcom.AlertNameHigh]
+	lowAlertSetting := alertSettings[dexcom.AlertNameLow]
+	riseAlertSetting := alertSettings[dexcom.AlertNameRise]
+	fallAlertSetting := alertSettings[dexcom.AlertNameFall]
+	outOfRangeAlertSetting := alertSettings[dexcom.AlertNameOutOfRange]
+	if highAlertSetting == nil || lowAlertSetting == nil || riseAlertSetting == nil || fallAlertSetting == nil || outOfRangeAlertSetting == nil {
+		return nil
+	}
+
+	datum := cgm.New()
+
+	// TODO: Refactor so we don't have to clear these here
+	datum.ID = nil
+	datum.GUID = nil
+
+	datum.HighLevelAlert = cgm.NewHighLevelAlert()
+	datum.HighLevelAlert.Enabled = pointer.FromBool(highAlertSetting.Enabled)
+	datum.HighLevelAlert.Level = pointer.FromFloat64(highAlertSetting.Value)
+	datum.HighLevelAlert.Snooze = pointer.FromInt(translateMinutesToMilliseconds(highAlertSetting.Snooze))
+	datum.LowLevelAlert = cgm.NewLowLevelAlert()
+	datum.LowLevelAlert.Enabled = pointer.FromBool(lowAlertSetting.Enabled)
+	datum.LowLevelAlert.Level = pointer.FromFloat64(lowAlertSetting.Value)
+	datum.LowLevelAlert.Snooze = pointer.FromInt(translateMinutesToMilliseconds(lowAlertSetting.Snooze))
+	datum.RateAlerts = cgm.NewRateAlerts()
+	datum.RateAlerts.RiseRateAlert = cgm.NewRiseRateAlert()
+	datum.RateAlerts.RiseRateAlert.Enabled = pointer.FromBool(riseAlertSetting.Enabled)
+	datum.RateAlerts.RiseRateAlert.Rate = pointer.FromFloat64(riseAlertSetting.Value)
+	datum.RateAlerts.FallRateAlert = cgm.NewFallRateAlert()
+	datum.RateAlerts.FallRateAlert.Enabled = pointer.FromBool(fallAlertSetting.Enabled)
+	datum.RateAlerts.FallRateAlert.Rate = pointer.FromFloat64(-fallAlertSetting.Value)
+	datum.OutOfRangeAlert = cgm.NewOutOfRangeAlert()
+	datum.OutOfRangeAlert.Enabled = pointer.FromBool(outOfRangeAlertSetting.Enabled)
+	datum.OutOfRangeAlert.Threshold = pointer.FromInt(translateMinutesToMilliseconds(int(outOfRangeAlertSetting.Value)))
+	datum.Manufacturers = pointer.FromStringArray([]string{cgm.ManufacturerDexcom})
+	datum.Model = pointer.FromString(d.Model)
+	datum.SerialNumber = d.SerialNumber
+	datum.TransmitterID = d.TransmitterID
+	datum.Units = pointer.FromString(highAlertSetting.Unit)
+
+	translateTime(systemTime, displayTime, &datum.Base)
+	return datum
+}
+
+func translateMinutesToMilliseconds(minutes int) int {
+	return int(time.Duration(minutes) * time.Minute / time.Millisecond)
+}
+
 func translateCalibrationToDatum(c *dexcom.Calibration) data.Datum {
 	datum := calibration.New()
 
@@ -23,6 +23,7 @@ export TIDEPOOL_AUTH_CLIENT_ADDRESS="http://localhost:8009"
 export TIDEPOOL_BLOB_CLIENT_ADDRESS="http://localhost:8009"
 export TIDEPOOL_DATA_CLIENT_ADDRESS="http://localhost:8009"
 export TIDEPOOL_METRIC_CLIENT_ADDRESS="http://localhost:8009"
+export TIDEPOOL_NOTIFICATION_CLIENT_ADDRESS="http://localhost:8009"
 export TIDEPOOL_TASK_CLIENT_ADDRESS="http://localhost:8009"
 export TIDEPOOL_USER_CLIENT_ADDRESS="http://localhost:8009"
 
@@ -38,8 +39,25 @@ export TIDEPOOL_USER_SERVICE_SERVER_ADDRESS=":9221"
 
 export TIDEPOOL_AUTH_SERVICE_DOMAIN="localhost"
 
+export TIDEPOOL_AUTH_STORE_ENCRYPTION_KEY_ID="local"
+# LOCAL DEVELOPMENT ONLY: a throwaway key of 32 zero bytes. Never use it elsewhere; generate a real key with
+# "openssl rand -base64 32".
+export TIDEPOOL_AUTH_STORE_ENCRYPTION_KEYS="local:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+
 export TIDEPOOL_BLOB_SERVICE_UNSTRUCTURED_STORE_TYPE="file"
 export TIDEPOOL_BLOB_SERVICE_UNSTRUCTURED_STORE_FILE_DIRECTORY="_data/blobs"
+export TIDEPOOL_BLOB_SERVICE_LINK_ADDRESS="http://localhost:8009"
+export TIDEPOOL_BLOB_SERVICE_LINK_SECRET="Secret used to sign blob download links. Z3Dq8nA0pLxVw4Rk7TfYc2Hm9BsJ6GeU"
+export TIDEPOOL_BLOB_SERVICE_INSPECT_SCANNER_TYPE="local"
+
+export TIDEPOOL_TASK_SERVICE_DATA_SOURCE_NOTIFIER_ADDRESS="http://localhost:8009"
+
+export TIDEPOOL_TASK_SERVICE_NOTIFICATION_DELIVERY_EMAIL_TYPE="local"
+export TIDEPOOL_TASK_SERVICE_NOTIFICATION_DELIVERY_EMAIL_LOCAL_DIRECTORY="_data/notifications"
+export TIDEPOOL_TASK_SERVICE_NOTIFICATION_DELIVERY_PUSH_TYPE="local"
+export TIDEPOOL_TASK_SERVICE_NOTIFICATION_DELIVERY_PUSH_LOCAL_DIRECTORY="_data/notifications"
+export TIDEPOOL_TASK_SERVICE_NOTIFICATION_DELIVERY_SMS_TYPE="local"
+export TIDEPOOL_TASK_SERVICE_NOTIFICATION_DELIVERY_SMS_LOCAL_DIRECTORY="_data/notifications"
 
 export TIDEPOOL_AUTH_SERVICE_SECRET="Service secret used for interservice requests with the auth service"
 export TIDEPOOL_BLOB_SERVICE_SECRET="Service secret used for interservice requests with the blob service"
@@ -196,6 +196,13 @@ func Code(err error) string {
 	return ""
 }
 
+func Meta(err error) interface{} {
+	if objectErr, objectOK := err.(*object); objectOK {
+		return objectErr.Meta
+	}
+	return nil
+}
+
 func Cause(err error) error {
 	if objectErr, objectOK := err.(*object); objectOK && objectErr.Cause != nil && objectErr.Cause.Error != nil {
 		return Cause(objectErr.Cause.Error)
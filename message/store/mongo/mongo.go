@@ -37,6 +37,40 @@ type MessagesSession struct {
 	*storeStructuredMongo.Session
 }
 
+// ListMessagesForUserByID lists the messages written by the user and the messages written for the user by others
+func (m *MessagesSession) ListMessagesForUserByID(ctx context.Context, userID string) ([]store.Message, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+
+	if m.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	startTime := time.Now()
+
+	messages := []store.Message{}
+	selector := bson.M{
+		"$or": []bson.M{
+			{"userid": userID},
+			{"groupid": userID},
+		},
+	}
+	err := m.C().Find(selector).Select(bson.M{"_id": 0}).Sort("timestamp").All(&messages)
+
+	loggerFields := log.Fields{"userId": userID, "count": len(messages), "duration": time.Since(startTime) / time.Microsecond}
+	log.LoggerFromContext(ctx).WithFields(loggerFields).WithError(err).Debug("ListMessagesForUserByID")
+
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list messages for user by id")
+	}
+
+	return messages, nil
+}
+
 func (m *MessagesSession) DeleteMessagesFromUser(ctx context.Context, user *store.User) error {
 	if ctx == nil {
 		return errors.New("context is missing")
@@ -12,10 +12,14 @@ type Store interface {
 type MessagesSession interface {
 	io.Closer
 
+	ListMessagesForUserByID(ctx context.Context, userID string) ([]Message, error)
 	DeleteMessagesFromUser(ctx context.Context, user *User) error
 	DestroyMessagesForUserByID(ctx context.Context, userID string) error
 }
 
+// TODO: Temporary until Message is restructured
+type Message map[string]interface{}
+
 // TODO: Temporary until User is restructured
 
 type User struct {
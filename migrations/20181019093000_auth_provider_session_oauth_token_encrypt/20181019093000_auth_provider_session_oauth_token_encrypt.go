@@ -0,0 +1,164 @@
+package main
+
+import (
+	"time"
+
+	"github.com/urfave/cli"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/tidepool-org/platform/application"
+	"github.com/tidepool-org/platform/auth"
+	authStoreMongo "github.com/tidepool-org/platform/auth/store/mongo"
+	cryptoField "github.com/tidepool-org/platform/crypto/field"
+	cryptoKey "github.com/tidepool-org/platform/crypto/key"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	mongoMigration "github.com/tidepool-org/platform/migration/mongo"
+	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
+)
+
+func main() {
+	application.RunAndExit(NewMigration())
+}
+
+type Migration struct {
+	*mongoMigration.Migration
+}
+
+func NewMigration() *Migration {
+	return &Migration{
+		Migration: mongoMigration.NewMigration(),
+	}
+}
+
+func (m *Migration) Initialize(provider application.Provider) error {
+	if err := m.Migration.Initialize(provider); err != nil {
+		return err
+	}
+
+	m.CLI().Usage = "Encrypt all provider session OAuth tokens with the current auth store encryption key"
+	m.CLI().Description = "Encrypt all provider session OAuth tokens with the current auth store encryption key." +
+		"\n   Access and refresh tokens stored in plaintext are encrypted. Access and refresh tokens" +
+		"\n   encrypted with a previous key are re-encrypted with the current key, allowing keys to be" +
+		"\n   rotated. The previous key must remain configured until this migration completes." +
+		"\n\n   This migration is idempotent." +
+		"\n\n   NOTE: This migration MUST be executed immediately AFTER upgrading the auth service to encrypt" +
+		"\n   provider session OAuth tokens and AFTER each change to the current auth store encryption key."
+	m.CLI().Action = func(context *cli.Context) error {
+		if !m.ParseContext(context) {
+			return nil
+		}
+		return m.execute()
+	}
+
+	return nil
+}
+
+func (m *Migration) execute() error {
+	m.Logger().Debug("Migrating provider session oauth tokens")
+
+	m.Logger().Debug("Creating encrypter")
+
+	encryptionConfig := cryptoKey.NewConfig()
+	if err := encryptionConfig.Load(m.ConfigReporter().WithScopes("auth", "store", "encryption")); err != nil {
+		return errors.Wrap(err, "unable to load encryption config")
+	}
+	encrypter, err := cryptoField.NewEncrypter(encryptionConfig)
+	if err != nil {
+		return errors.Wrap(err, "unable to create encrypter")
+	}
+
+	m.Logger().Debug("Creating auth store")
+
+	mongoConfig := m.NewMongoConfig()
+	mongoConfig.Timeout = 60 * time.Minute
+	authStore, err := storeStructuredMongo.NewStore(mongoConfig, m.Logger())
+	if err != nil {
+		return errors.Wrap(err, "unable to create auth store")
+	}
+	defer authStore.Close()
+
+	m.Logger().Debug("Creating provider sessions session")
+
+	providerSessionsSession := authStore.NewSession("provider_sessions")
+	defer providerSessionsSession.Close()
+
+	count := m.migrateProviderSessionOAuthTokens(providerSessionsSession, encrypter)
+
+	m.Logger().Infof("Migrated %d provider session oauth tokens", count)
+
+	return nil
+}
+
+func (m *Migration) migrateProviderSessionOAuthTokens(providerSessionsSession *storeStructuredMongo.Session, encrypter *cryptoField.Encrypter) int {
+	var count int
+
+	iter := providerSessionsSession.C().Find(bson.M{"oauthToken": bson.M{"$exists": true}}).Iter()
+
+	providerSession := &auth.ProviderSession{}
+	for iter.Next(providerSession) {
+		if authStoreMongo.ProviderSessionOAuthTokenRequiresEncrypt(encrypter, providerSession.OAuthToken) {
+			if m.migrateProviderSessionOAuthToken(providerSessionsSession, encrypter, providerSession) {
+				count++
+			}
+		}
+		providerSession = &auth.ProviderSession{}
+	}
+
+	if err := iter.Close(); err != nil {
+		m.Logger().WithError(err).Error("Unable to iterate provider sessions")
+	}
+
+	return count
+}
+
+func (m *Migration) migrateProviderSessionOAuthToken(providerSessionsSession *storeStructuredMongo.Session, encrypter *cryptoField.Encrypter, providerSession *auth.ProviderSession) bool {
+	logger := m.Logger().WithField("id", providerSession.ID)
+
+	if m.DryRun() {
+		return true
+	}
+
+	decryptedOAuthToken, err := authStoreMongo.DecryptProviderSessionOAuthToken(encrypter, providerSession.ID, providerSession.OAuthToken)
+	if err != nil {
+		logger.WithError(err).Error("Unable to decrypt provider session oauth token")
+		return false
+	}
+	encryptedOAuthToken, err := authStoreMongo.EncryptProviderSessionOAuthToken(encrypter, providerSession.ID, decryptedOAuthToken)
+	if err != nil {
+		logger.WithError(err).Error("Unable to encrypt provider session oauth token")
+		return false
+	}
+
+	// Only update if the token has not changed since it was read (for example, refreshed by the auth service)
+	selector := bson.M{
+		"id":                      providerSession.ID,
+		"oauthToken.accessToken":  providerSession.OAuthToken.AccessToken,
+		"oauthToken.refreshToken": bson.M{"$exists": false},
+	}
+	if providerSession.OAuthToken.RefreshToken != "" {
+		selector["oauthToken.refreshToken"] = providerSession.OAuthToken.RefreshToken
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"oauthToken.accessToken":  encryptedOAuthToken.AccessToken,
+			"oauthToken.refreshToken": encryptedOAuthToken.RefreshToken,
+		},
+	}
+	if encryptedOAuthToken.RefreshToken == "" {
+		update["$set"] = bson.M{"oauthToken.accessToken": encryptedOAuthToken.AccessToken}
+	}
+
+	changeInfo, err := providerSessionsSession.C().UpdateAll(selector, update)
+	if err != nil {
+		logger.WithError(err).Error("Unable to update provider session oauth token")
+		return false
+	} else if changeInfo == nil || changeInfo.Updated == 0 {
+		logger.Warn("Provider session oauth token changed during migration")
+		return false
+	}
+
+	logger.WithFields(log.Fields{"keyId": encrypter.KeyID()}).Debug("Migrated provider session oauth token")
+
+	return true
+}
@@ -1,3 +1,9 @@
 package notification
 
-type Client interface{}
+import "github.com/tidepool-org/platform/alert"
+
+type Client interface {
+	NotificationAccessor
+	PreferencesAccessor
+	alert.RuleAccessor
+}
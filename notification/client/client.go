@@ -1,7 +1,16 @@
 package client
 
 import (
+	"context"
+	"net/http"
+
+	"github.com/tidepool-org/platform/alert"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/page"
 	"github.com/tidepool-org/platform/platform"
+	"github.com/tidepool-org/platform/request"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
 )
 
 type Client struct {
@@ -18,3 +27,266 @@ func New(cfg *platform.Config, authorizeAs platform.AuthorizeAs) (*Client, error
 		client: clnt,
 	}, nil
 }
+
+func (c *Client) ListUserNotifications(ctx context.Context, userID string, filter *notification.NotificationFilter, pagination *page.Pagination) (notification.Notifications, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+	if filter == nil {
+		filter = notification.NewNotificationFilter()
+	} else if err := structureValidator.New().Validate(filter); err != nil {
+		return nil, errors.Wrap(err, "filter is invalid")
+	}
+	if pagination == nil {
+		pagination = page.NewPagination()
+	} else if err := structureValidator.New().Validate(pagination); err != nil {
+		return nil, errors.Wrap(err, "pagination is invalid")
+	}
+
+	url := c.client.ConstructURL("v1", "users", userID, "notifications")
+	notifications := notification.Notifications{}
+	if err := c.client.RequestData(ctx, http.MethodGet, url, []request.RequestMutator{filter, pagination}, nil, &notifications); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (c *Client) CreateUserNotification(ctx context.Context, userID string, create *notification.NotificationCreate) (*notification.Notification, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+	if create == nil {
+		return nil, errors.New("create is missing")
+	} else if err := structureValidator.New().Validate(create); err != nil {
+		return nil, errors.Wrap(err, "create is invalid")
+	}
+
+	url := c.client.ConstructURL("v1", "users", userID, "notifications")
+	ntfctn := &notification.Notification{}
+	if err := c.client.RequestData(ctx, http.MethodPost, url, nil, create, ntfctn); err != nil {
+		return nil, err
+	}
+
+	return ntfctn, nil
+}
+
+func (c *Client) GetNotification(ctx context.Context, id string) (*notification.Notification, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if id == "" {
+		return nil, errors.New("id is missing")
+	}
+
+	url := c.client.ConstructURL("v1", "notifications", id)
+	ntfctn := &notification.Notification{}
+	if err := c.client.RequestData(ctx, http.MethodGet, url, nil, nil, ntfctn); err != nil {
+		if request.IsErrorResourceNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ntfctn, nil
+}
+
+func (c *Client) UpdateNotification(ctx context.Context, id string, update *notification.NotificationUpdate) (*notification.Notification, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if id == "" {
+		return nil, errors.New("id is missing")
+	}
+	if update == nil {
+		return nil, errors.New("update is missing")
+	} else if err := structureValidator.New().Validate(update); err != nil {
+		return nil, errors.Wrap(err, "update is invalid")
+	}
+
+	url := c.client.ConstructURL("v1", "notifications", id)
+	ntfctn := &notification.Notification{}
+	if err := c.client.RequestData(ctx, http.MethodPut, url, nil, update, ntfctn); err != nil {
+		if request.IsErrorResourceNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ntfctn, nil
+}
+
+func (c *Client) DeleteUserNotifications(ctx context.Context, userID string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if userID == "" {
+		return errors.New("user id is missing")
+	}
+
+	url := c.client.ConstructURL("v1", "users", userID, "notifications")
+	return c.client.RequestData(ctx, http.MethodDelete, url, nil, nil, nil)
+}
+
+func (c *Client) GetUserPreferences(ctx context.Context, userID string) (*notification.Preferences, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+
+	url := c.client.ConstructURL("v1", "users", userID, "notification_preferences")
+	preferences := &notification.Preferences{}
+	if err := c.client.RequestData(ctx, http.MethodGet, url, nil, nil, preferences); err != nil {
+		if request.IsErrorResourceNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+func (c *Client) UpdateUserPreferences(ctx context.Context, userID string, update *notification.PreferencesUpdate) (*notification.Preferences, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+	if update == nil {
+		return nil, errors.New("update is missing")
+	} else if err := structureValidator.New().Validate(update); err != nil {
+		return nil, errors.Wrap(err, "update is invalid")
+	}
+
+	url := c.client.ConstructURL("v1", "users", userID, "notification_preferences")
+	preferences := &notification.Preferences{}
+	if err := c.client.RequestData(ctx, http.MethodPut, url, nil, update, preferences); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+func (c *Client) DeleteUserPreferences(ctx context.Context, userID string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if userID == "" {
+		return errors.New("user id is missing")
+	}
+
+	url := c.client.ConstructURL("v1", "users", userID, "notification_preferences")
+	return c.client.RequestData(ctx, http.MethodDelete, url, nil, nil, nil)
+}
+
+func (c *Client) ListRules(ctx context.Context, filter *alert.RuleFilter, pagination *page.Pagination) (alert.Rules, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if filter == nil {
+		filter = alert.NewRuleFilter()
+	} else if err := structureValidator.New().Validate(filter); err != nil {
+		return nil, errors.Wrap(err, "filter is invalid")
+	}
+	if pagination == nil {
+		pagination = page.NewPagination()
+	} else if err := structureValidator.New().Validate(pagination); err != nil {
+		return nil, errors.Wrap(err, "pagination is invalid")
+	}
+
+	url := c.client.ConstructURL("v1", "alert_rules")
+	rules := alert.Rules{}
+	if err := c.client.RequestData(ctx, http.MethodGet, url, []request.RequestMutator{filter, pagination}, nil, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (c *Client) CreateUserRule(ctx context.Context, userID string, create *alert.RuleCreate) (*alert.Rule, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+	if create == nil {
+		return nil, errors.New("create is missing")
+	} else if err := structureValidator.New().Validate(create); err != nil {
+		return nil, errors.Wrap(err, "create is invalid")
+	}
+
+	url := c.client.ConstructURL("v1", "users", userID, "alert_rules")
+	rule := &alert.Rule{}
+	if err := c.client.RequestData(ctx, http.MethodPost, url, nil, create, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (c *Client) GetRule(ctx context.Context, id string) (*alert.Rule, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if id == "" {
+		return nil, errors.New("id is missing")
+	}
+
+	url := c.client.ConstructURL("v1", "alert_rules", id)
+	rule := &alert.Rule{}
+	if err := c.client.RequestData(ctx, http.MethodGet, url, nil, nil, rule); err != nil {
+		if request.IsErrorResourceNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (c *Client) UpdateRule(ctx context.Context, id string, update *alert.RuleUpdate) (*alert.Rule, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if id == "" {
+		return nil, errors.New("id is missing")
+	}
+	if update == nil {
+		return nil, errors.New("update is missing")
+	} else if err := structureValidator.New().Validate(update); err != nil {
+		return nil, errors.Wrap(err, "update is invalid")
+	}
+
+	url := c.client.ConstructURL("v1", "alert_rules", id)
+	rule := &alert.Rule{}
+	if err := c.client.RequestData(ctx, http.MethodPut, url, nil, update, rule); err != nil {
+		if request.IsErrorResourceNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (c *Client) DeleteRule(ctx context.Context, id string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if id == "" {
+		return errors.New("id is missing")
+	}
+
+	url := c.client.ConstructURL("v1", "alert_rules", id)
+	return c.client.RequestData(ctx, http.MethodDelete, url, nil, nil, nil)
+}
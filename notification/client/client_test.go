@@ -5,10 +5,21 @@ import (
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/ghttp"
 
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/tidepool-org/platform/alert"
+	"github.com/tidepool-org/platform/auth"
+	authTest "github.com/tidepool-org/platform/auth/test"
+	"github.com/tidepool-org/platform/log"
+	logNull "github.com/tidepool-org/platform/log/null"
 	"github.com/tidepool-org/platform/notification"
 	notificationClient "github.com/tidepool-org/platform/notification/client"
 	"github.com/tidepool-org/platform/platform"
+	"github.com/tidepool-org/platform/pointer"
 	testHTTP "github.com/tidepool-org/platform/test/http"
+	"github.com/tidepool-org/platform/user"
 )
 
 var _ = Describe("Client", func() {
@@ -60,5 +71,334 @@ var _ = Describe("Client", func() {
 				svr.Close()
 			}
 		})
+
+		Context("with context", func() {
+			var sessionToken string
+			var ctx context.Context
+
+			BeforeEach(func() {
+				sessionToken = authTest.NewSessionToken()
+				ctx = log.NewContextWithLogger(context.Background(), logNull.NewLogger())
+				ctx = auth.NewContextWithServerSessionToken(ctx, sessionToken)
+			})
+
+			Context("CreateUserNotification", func() {
+				var userID string
+				var create *notification.NotificationCreate
+
+				BeforeEach(func() {
+					userID = user.NewID()
+					create = notification.NewNotificationCreate()
+					create.Type = notification.TypeMessage
+				})
+
+				It("returns an error if the user id is missing", func() {
+					ntfctn, err := clnt.CreateUserNotification(ctx, "", create)
+					Expect(err).To(MatchError("user id is missing"))
+					Expect(ntfctn).To(BeNil())
+					Expect(svr.ReceivedRequests()).To(BeEmpty())
+				})
+
+				It("returns an error if the create is invalid", func() {
+					create.Type = "invalid"
+					ntfctn, err := clnt.CreateUserNotification(ctx, userID, create)
+					Expect(err).To(MatchError(ContainSubstring("create is invalid")))
+					Expect(ntfctn).To(BeNil())
+					Expect(svr.ReceivedRequests()).To(BeEmpty())
+				})
+
+				It("returns the notification if successful", func() {
+					responseNotification, err := notification.NewNotification(userID, create)
+					Expect(err).ToNot(HaveOccurred())
+					svr.AppendHandlers(
+						CombineHandlers(
+							VerifyRequest("POST", "/v1/users/"+userID+"/notifications"),
+							VerifyHeaderKV("X-Tidepool-Session-Token", sessionToken),
+							VerifyContentType("application/json; charset=utf-8"),
+							VerifyBody([]byte(`{"type":"message"}`+"\n")),
+							RespondWithJSONEncoded(http.StatusCreated, responseNotification),
+						),
+					)
+					ntfctn, err := clnt.CreateUserNotification(ctx, userID, create)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ntfctn).ToNot(BeNil())
+					Expect(ntfctn.ID).To(Equal(responseNotification.ID))
+					Expect(ntfctn.UserID).To(Equal(userID))
+					Expect(ntfctn.Type).To(Equal(notification.TypeMessage))
+					Expect(svr.ReceivedRequests()).To(HaveLen(1))
+				})
+			})
+
+			Context("GetNotification", func() {
+				It("returns nil if not found", func() {
+					svr.AppendHandlers(
+						CombineHandlers(
+							VerifyRequest("GET", "/v1/notifications/0123456789abcdef0123456789abcdef"),
+							RespondWith(http.StatusNotFound, nil),
+						),
+					)
+					ntfctn, err := clnt.GetNotification(ctx, "0123456789abcdef0123456789abcdef")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ntfctn).To(BeNil())
+					Expect(svr.ReceivedRequests()).To(HaveLen(1))
+				})
+			})
+
+			Context("UpdateNotification", func() {
+				It("sends the update", func() {
+					create := notification.NewNotificationCreate()
+					create.Type = notification.TypeMessage
+					responseNotification, err := notification.NewNotification(user.NewID(), create)
+					Expect(err).ToNot(HaveOccurred())
+					responseNotification.ReadTime = pointer.FromTime(responseNotification.CreatedTime)
+					update := notification.NewNotificationUpdate()
+					update.Read = pointer.FromBool(true)
+					svr.AppendHandlers(
+						CombineHandlers(
+							VerifyRequest("PUT", "/v1/notifications/"+responseNotification.ID),
+							VerifyContentType("application/json; charset=utf-8"),
+							VerifyBody([]byte(`{"read":true}`+"\n")),
+							RespondWithJSONEncoded(http.StatusOK, responseNotification),
+						),
+					)
+					ntfctn, err := clnt.UpdateNotification(ctx, responseNotification.ID, update)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ntfctn).ToNot(BeNil())
+					Expect(ntfctn.IsRead()).To(BeTrue())
+					Expect(svr.ReceivedRequests()).To(HaveLen(1))
+				})
+			})
+
+			Context("DeleteUserNotifications", func() {
+				It("returns an error if the user id is missing", func() {
+					Expect(clnt.DeleteUserNotifications(ctx, "")).To(MatchError("user id is missing"))
+					Expect(svr.ReceivedRequests()).To(BeEmpty())
+				})
+
+				It("deletes the user notifications", func() {
+					userID := user.NewID()
+					svr.AppendHandlers(
+						CombineHandlers(
+							VerifyRequest("DELETE", "/v1/users/"+userID+"/notifications"),
+							RespondWith(http.StatusNoContent, nil),
+						),
+					)
+					Expect(clnt.DeleteUserNotifications(ctx, userID)).To(Succeed())
+					Expect(svr.ReceivedRequests()).To(HaveLen(1))
+				})
+			})
+
+			Context("GetUserPreferences", func() {
+				It("returns an error if the user id is missing", func() {
+					preferences, err := clnt.GetUserPreferences(ctx, "")
+					Expect(err).To(MatchError("user id is missing"))
+					Expect(preferences).To(BeNil())
+				})
+
+				It("returns nil if not found", func() {
+					userID := user.NewID()
+					svr.AppendHandlers(
+						CombineHandlers(
+							VerifyRequest("GET", "/v1/users/"+userID+"/notification_preferences"),
+							RespondWith(http.StatusNotFound, nil),
+						),
+					)
+					preferences, err := clnt.GetUserPreferences(ctx, userID)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(preferences).To(BeNil())
+					Expect(svr.ReceivedRequests()).To(HaveLen(1))
+				})
+			})
+
+			Context("UpdateUserPreferences", func() {
+				It("returns an error if the update is invalid", func() {
+					update := notification.NewPreferencesUpdate()
+					update.Email = &notification.ChannelPreference{Enabled: true}
+					preferences, err := clnt.UpdateUserPreferences(ctx, user.NewID(), update)
+					Expect(err).To(MatchError("update is invalid; value does not exist"))
+					Expect(preferences).To(BeNil())
+				})
+
+				It("sends the update", func() {
+					userID := user.NewID()
+					responsePreferences := &notification.Preferences{
+						UserID:      userID,
+						Email:       &notification.ChannelPreference{Enabled: true, Address: pointer.FromString("user@example.com")},
+						CreatedTime: time.Now().Truncate(time.Second),
+					}
+					update := notification.NewPreferencesUpdate()
+					update.Email = responsePreferences.Email
+					svr.AppendHandlers(
+						CombineHandlers(
+							VerifyRequest("PUT", "/v1/users/"+userID+"/notification_preferences"),
+							VerifyContentType("application/json; charset=utf-8"),
+							VerifyBody([]byte(`{"email":{"enabled":true,"address":"user@example.com"}}`+"\n")),
+							RespondWithJSONEncoded(http.StatusOK, responsePreferences),
+						),
+					)
+					preferences, err := clnt.UpdateUserPreferences(ctx, userID, update)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(preferences).ToNot(BeNil())
+					Expect(preferences.UserID).To(Equal(userID))
+					Expect(preferences.Email).To(Equal(responsePreferences.Email))
+					Expect(svr.ReceivedRequests()).To(HaveLen(1))
+				})
+			})
+
+			Context("DeleteUserPreferences", func() {
+				It("returns an error if the user id is missing", func() {
+					Expect(clnt.DeleteUserPreferences(ctx, "")).To(MatchError("user id is missing"))
+					Expect(svr.ReceivedRequests()).To(BeEmpty())
+				})
+
+				It("deletes the user preferences", func() {
+					userID := user.NewID()
+					svr.AppendHandlers(
+						CombineHandlers(
+							VerifyRequest("DELETE", "/v1/users/"+userID+"/notification_preferences"),
+							RespondWith(http.StatusNoContent, nil),
+						),
+					)
+					Expect(clnt.DeleteUserPreferences(ctx, userID)).To(Succeed())
+					Expect(svr.ReceivedRequests()).To(HaveLen(1))
+				})
+			})
+
+			Context("ListRules", func() {
+				It("returns an error if the filter is invalid", func() {
+					filter := alert.NewRuleFilter()
+					filter.Type = pointer.FromString("invalid")
+					rules, err := clnt.ListRules(ctx, filter, nil)
+					Expect(err).To(MatchError(ContainSubstring("filter is invalid")))
+					Expect(rules).To(BeNil())
+					Expect(svr.ReceivedRequests()).To(BeEmpty())
+				})
+
+				It("returns the rules if successful", func() {
+					filter := alert.NewRuleFilter()
+					filter.Type = pointer.FromString(alert.RuleTypeNoData)
+					svr.AppendHandlers(
+						CombineHandlers(
+							VerifyRequest("GET", "/v1/alert_rules", "page=0&size=100&type=noData"),
+							RespondWithJSONEncoded(http.StatusOK, alert.Rules{}),
+						),
+					)
+					rules, err := clnt.ListRules(ctx, filter, nil)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(rules).To(BeEmpty())
+					Expect(svr.ReceivedRequests()).To(HaveLen(1))
+				})
+			})
+
+			Context("CreateUserRule", func() {
+				var userID string
+				var create *alert.RuleCreate
+
+				BeforeEach(func() {
+					userID = user.NewID()
+					create = alert.NewRuleCreate()
+					create.Type = alert.RuleTypeLow
+					create.Units = pointer.FromString("mg/dL")
+					create.Threshold = pointer.FromFloat64(70)
+				})
+
+				It("returns an error if the user id is missing", func() {
+					rule, err := clnt.CreateUserRule(ctx, "", create)
+					Expect(err).To(MatchError("user id is missing"))
+					Expect(rule).To(BeNil())
+					Expect(svr.ReceivedRequests()).To(BeEmpty())
+				})
+
+				It("returns an error if the create is invalid", func() {
+					create.Threshold = nil
+					rule, err := clnt.CreateUserRule(ctx, userID, create)
+					Expect(err).To(MatchError(ContainSubstring("create is invalid")))
+					Expect(rule).To(BeNil())
+					Expect(svr.ReceivedRequests()).To(BeEmpty())
+				})
+
+				It("returns the rule if successful", func() {
+					responseRule, err := alert.NewRule(userID, create)
+					Expect(err).ToNot(HaveOccurred())
+					svr.AppendHandlers(
+						CombineHandlers(
+							VerifyRequest("POST", "/v1/users/"+userID+"/alert_rules"),
+							VerifyHeaderKV("X-Tidepool-Session-Token", sessionToken),
+							VerifyContentType("application/json; charset=utf-8"),
+							VerifyBody([]byte(`{"type":"low","units":"mg/dL","threshold":70}`+"\n")),
+							RespondWithJSONEncoded(http.StatusCreated, responseRule),
+						),
+					)
+					rule, err := clnt.CreateUserRule(ctx, userID, create)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(rule).ToNot(BeNil())
+					Expect(rule.ID).To(Equal(responseRule.ID))
+					Expect(rule.OwnerID).To(Equal(userID))
+					Expect(rule.Evaluation.State).To(Equal(alert.RuleStateArmed))
+					Expect(svr.ReceivedRequests()).To(HaveLen(1))
+				})
+			})
+
+			Context("GetRule", func() {
+				It("returns nil if not found", func() {
+					svr.AppendHandlers(
+						CombineHandlers(
+							VerifyRequest("GET", "/v1/alert_rules/0123456789abcdef0123456789abcdef"),
+							RespondWith(http.StatusNotFound, nil),
+						),
+					)
+					rule, err := clnt.GetRule(ctx, "0123456789abcdef0123456789abcdef")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(rule).To(BeNil())
+					Expect(svr.ReceivedRequests()).To(HaveLen(1))
+				})
+			})
+
+			Context("UpdateRule", func() {
+				It("returns an error if the update is invalid", func() {
+					update := alert.NewRuleUpdate()
+					update.Snooze = pointer.FromInt(-1)
+					rule, err := clnt.UpdateRule(ctx, "0123456789abcdef0123456789abcdef", update)
+					Expect(err).To(MatchError(ContainSubstring("update is invalid")))
+					Expect(rule).To(BeNil())
+					Expect(svr.ReceivedRequests()).To(BeEmpty())
+				})
+
+				It("sends the update", func() {
+					update := alert.NewRuleUpdate()
+					update.Snooze = pointer.FromInt(30)
+					svr.AppendHandlers(
+						CombineHandlers(
+							VerifyRequest("PUT", "/v1/alert_rules/0123456789abcdef0123456789abcdef"),
+							VerifyContentType("application/json; charset=utf-8"),
+							VerifyBody([]byte(`{"snooze":30}`+"\n")),
+							RespondWith(http.StatusNotFound, nil),
+						),
+					)
+					rule, err := clnt.UpdateRule(ctx, "0123456789abcdef0123456789abcdef", update)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(rule).To(BeNil())
+					Expect(svr.ReceivedRequests()).To(HaveLen(1))
+				})
+			})
+
+			Context("DeleteRule", func() {
+				It("returns an error if the id is missing", func() {
+					Expect(clnt.DeleteRule(ctx, "")).To(MatchError("id is missing"))
+					Expect(svr.ReceivedRequests()).To(BeEmpty())
+				})
+
+				It("deletes the rule", func() {
+					svr.AppendHandlers(
+						CombineHandlers(
+							VerifyRequest("DELETE", "/v1/alert_rules/0123456789abcdef0123456789abcdef"),
+							RespondWith(http.StatusNoContent, nil),
+						),
+					)
+					Expect(clnt.DeleteRule(ctx, "0123456789abcdef0123456789abcdef")).To(Succeed())
+					Expect(svr.ReceivedRequests()).To(HaveLen(1))
+				})
+			})
+		})
 	})
 })
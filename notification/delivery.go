@@ -0,0 +1,122 @@
+package notification
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/structure"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
+const (
+	DeliveryStateDelivered = "delivered"
+	DeliveryStateFailed    = "failed"
+	DeliveryStatePending   = "pending"
+)
+
+func DeliveryStates() []string {
+	return []string{
+		DeliveryStateDelivered,
+		DeliveryStateFailed,
+		DeliveryStatePending,
+	}
+}
+
+// Delivery tracks the status of a notification over a single channel. A pending delivery is retried
+// until it is either delivered or has failed too many times.
+type Delivery struct {
+	Channel         string               `json:"channel" bson:"channel"`
+	Address         string               `json:"address" bson:"address"`
+	State           string               `json:"state" bson:"state"`
+	Attempts        int                  `json:"attempts" bson:"attempts"`
+	LastAttemptTime *time.Time           `json:"lastAttemptTime,omitempty" bson:"lastAttemptTime,omitempty"`
+	DeliveredTime   *time.Time           `json:"deliveredTime,omitempty" bson:"deliveredTime,omitempty"`
+	Error           *errors.Serializable `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+func NewDelivery(channel string, address string) *Delivery {
+	return &Delivery{
+		Channel: channel,
+		Address: address,
+		State:   DeliveryStatePending,
+	}
+}
+
+func (d *Delivery) IsPending() bool {
+	return d.State == DeliveryStatePending
+}
+
+func (d *Delivery) Parse(parser structure.ObjectParser) {
+	if ptr := parser.String("channel"); ptr != nil {
+		d.Channel = *ptr
+	}
+	if ptr := parser.String("address"); ptr != nil {
+		d.Address = *ptr
+	}
+	if ptr := parser.String("state"); ptr != nil {
+		d.State = *ptr
+	}
+	if ptr := parser.Int("attempts"); ptr != nil {
+		d.Attempts = *ptr
+	}
+	d.LastAttemptTime = parser.Time("lastAttemptTime", time.RFC3339)
+	d.DeliveredTime = parser.Time("deliveredTime", time.RFC3339)
+	if parser.ReferenceExists("error") {
+		d.Error = &errors.Serializable{}
+		d.Error.Parse("error", parser)
+	}
+}
+
+func (d *Delivery) Validate(validator structure.Validator) {
+	validator.String("channel", &d.Channel).OneOf(Channels()...)
+	validator.String("address", &d.Address).NotEmpty().LengthLessThanOrEqualTo(ChannelAddressLengthMaximum)
+	validator.String("state", &d.State).OneOf(DeliveryStates()...)
+	validator.Int("attempts", &d.Attempts).GreaterThanOrEqualTo(0)
+	validator.Time("lastAttemptTime", d.LastAttemptTime).NotZero().BeforeNow(time.Second)
+	if d.State == DeliveryStateDelivered {
+		validator.Time("deliveredTime", d.DeliveredTime).Exists().NotZero().BeforeNow(time.Second)
+	} else {
+		validator.Time("deliveredTime", d.DeliveredTime).NotExists()
+	}
+	if d.Error != nil {
+		d.Error.Validate(validator.WithReference("error"))
+	}
+}
+
+type Deliveries []*Delivery
+
+func (d Deliveries) Get(channel string) *Delivery {
+	for _, delivery := range d {
+		if delivery.Channel == channel {
+			return delivery
+		}
+	}
+	return nil
+}
+
+func (d *Deliveries) Parse(parser structure.ArrayParser) {
+	for _, reference := range parser.References() {
+		if deliveryParser := parser.WithReferenceObjectParser(reference); deliveryParser.Exists() {
+			delivery := &Delivery{}
+			delivery.Parse(deliveryParser)
+			deliveryParser.NotParsed()
+			*d = append(*d, delivery)
+		}
+	}
+}
+
+func (d Deliveries) Validate(validator structure.Validator) {
+	channels := map[string]bool{}
+	for index, delivery := range d {
+		if deliveryValidator := validator.WithReference(strconv.Itoa(index)); delivery != nil {
+			delivery.Validate(deliveryValidator)
+			if channels[delivery.Channel] {
+				deliveryValidator.WithReference("channel").ReportError(structureValidator.ErrorValueDuplicate())
+			}
+			channels[delivery.Channel] = true
+		} else {
+			deliveryValidator.ReportError(structureValidator.ErrorValueNotExists())
+		}
+	}
+}
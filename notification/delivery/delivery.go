@@ -0,0 +1,40 @@
+package delivery
+
+import (
+	"context"
+
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/notification/template"
+)
+
+const Type = "org.tidepool.notification.delivery"
+
+// Transport sends a message over a single channel to the address of the message
+type Transport interface {
+	Channel() string
+	Send(ctx context.Context, message *Message) error
+}
+
+type Message struct {
+	NotificationID   string `json:"notificationId"`
+	NotificationType string `json:"notificationType"`
+	Channel          string `json:"channel"`
+	Address          string `json:"address"`
+	Locale           string `json:"locale"`
+	Subject          string `json:"subject"`
+	Text             string `json:"text"`
+	HTML             string `json:"html,omitempty"`
+}
+
+func NewMessage(ntfctn *notification.Notification, dlvry *notification.Delivery, content *template.Content) *Message {
+	return &Message{
+		NotificationID:   ntfctn.ID,
+		NotificationType: ntfctn.Type,
+		Channel:          dlvry.Channel,
+		Address:          dlvry.Address,
+		Locale:           content.Locale,
+		Subject:          content.Subject,
+		Text:             content.Text,
+		HTML:             content.HTML,
+	}
+}
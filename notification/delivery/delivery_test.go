@@ -0,0 +1,45 @@
+package delivery_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/tidepool-org/platform/notification"
+	notificationDelivery "github.com/tidepool-org/platform/notification/delivery"
+	notificationTemplate "github.com/tidepool-org/platform/notification/template"
+	"github.com/tidepool-org/platform/user"
+)
+
+var _ = Describe("Delivery", func() {
+	Context("NewMessage", func() {
+		var ntfctn *notification.Notification
+		var dlvry *notification.Delivery
+
+		BeforeEach(func() {
+			var err error
+			ntfctn, err = notification.NewNotification(user.NewID(), &notification.NotificationCreate{Type: notification.TypeShareInvitation})
+			Expect(err).ToNot(HaveOccurred())
+			dlvry = notification.NewDelivery(notification.ChannelEmail, "user@example.com")
+		})
+
+		It("returns the message with the content", func() {
+			content := &notificationTemplate.Content{
+				Type:    notification.TypeShareInvitation,
+				Locale:  notification.LocaleSpanish,
+				Subject: "Hola",
+				Text:    "Hola, mundo",
+				HTML:    "<p>Hola, mundo</p>",
+			}
+			Expect(notificationDelivery.NewMessage(ntfctn, dlvry, content)).To(Equal(&notificationDelivery.Message{
+				NotificationID:   ntfctn.ID,
+				NotificationType: notification.TypeShareInvitation,
+				Channel:          notification.ChannelEmail,
+				Address:          "user@example.com",
+				Locale:           notification.LocaleSpanish,
+				Subject:          "Hola",
+				Text:             "Hola, mundo",
+				HTML:             "<p>Hola, mundo</p>",
+			}))
+		})
+	})
+})
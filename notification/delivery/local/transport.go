@@ -0,0 +1,87 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/notification/delivery"
+)
+
+// Transport appends each message as a line of JSON to a file per channel in the configured directory
+type Transport struct {
+	channel   string
+	directory string
+	mutex     sync.Mutex
+}
+
+func NewTransport(channel string, cfg *Config) (*Transport, error) {
+	if channel == "" {
+		return nil, errors.New("channel is missing")
+	}
+	if cfg == nil {
+		return nil, errors.New("config is missing")
+	} else if err := cfg.Validate(); err != nil {
+		return nil, errors.Wrap(err, "config is invalid")
+	}
+
+	return &Transport{
+		channel:   channel,
+		directory: cfg.Directory,
+	}, nil
+}
+
+func (t *Transport) Channel() string {
+	return t.channel
+}
+
+func (t *Transport) Path() string {
+	if t.directory == "" {
+		return ""
+	}
+	return filepath.Join(t.directory, t.channel+".jsonl")
+}
+
+func (t *Transport) Send(ctx context.Context, message *delivery.Message) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if message == nil {
+		return errors.New("message is missing")
+	}
+
+	log.LoggerFromContext(ctx).WithField("message", message).Info("Sending notification locally")
+
+	path := t.Path()
+	if path == "" {
+		return nil
+	}
+
+	bytes, err := json.Marshal(message)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal message")
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if err = os.MkdirAll(t.directory, 0755); err != nil {
+		return errors.Wrap(err, "unable to create directory")
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrap(err, "unable to open file")
+	}
+	defer file.Close()
+
+	if _, err = file.Write(append(bytes, '\n')); err != nil {
+		return errors.Wrap(err, "unable to write message")
+	}
+
+	return nil
+}
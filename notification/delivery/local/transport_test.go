@@ -0,0 +1,106 @@
+package local_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	configTest "github.com/tidepool-org/platform/config/test"
+	"github.com/tidepool-org/platform/log"
+	logTest "github.com/tidepool-org/platform/log/test"
+	"github.com/tidepool-org/platform/notification"
+	notificationDelivery "github.com/tidepool-org/platform/notification/delivery"
+	notificationDeliveryLocal "github.com/tidepool-org/platform/notification/delivery/local"
+	"github.com/tidepool-org/platform/test"
+)
+
+var _ = Describe("Transport", func() {
+	Context("Config", func() {
+		It("returns an error if config reporter is missing", func() {
+			Expect(notificationDeliveryLocal.NewConfig().Load(nil)).To(MatchError("config reporter is missing"))
+		})
+
+		It("loads the directory from the config reporter", func() {
+			configReporter := configTest.NewReporter()
+			configReporter.Config["directory"] = "_data/notifications"
+			cfg := notificationDeliveryLocal.NewConfig()
+			Expect(cfg.Load(configReporter)).To(Succeed())
+			Expect(cfg.Directory).To(Equal("_data/notifications"))
+		})
+	})
+
+	Context("NewTransport", func() {
+		It("returns an error if the channel is missing", func() {
+			transport, err := notificationDeliveryLocal.NewTransport("", notificationDeliveryLocal.NewConfig())
+			Expect(err).To(MatchError("channel is missing"))
+			Expect(transport).To(BeNil())
+		})
+
+		It("returns an error if the config is missing", func() {
+			transport, err := notificationDeliveryLocal.NewTransport(notification.ChannelPush, nil)
+			Expect(err).To(MatchError("config is missing"))
+			Expect(transport).To(BeNil())
+		})
+	})
+
+	Context("Send", func() {
+		var ctx context.Context
+		var message *notificationDelivery.Message
+
+		BeforeEach(func() {
+			ctx = log.NewContextWithLogger(context.Background(), logTest.NewLogger())
+			message = &notificationDelivery.Message{
+				NotificationID:   "0123456789abcdef0123456789abcdef",
+				NotificationType: notification.TypeMessage,
+				Channel:          notification.ChannelPush,
+				Address:          "device-token",
+				Locale:           "en",
+				Subject:          "Hello",
+				Text:             "Hello, world",
+			}
+		})
+
+		It("returns an error if the message is missing", func() {
+			transport, err := notificationDeliveryLocal.NewTransport(notification.ChannelPush, notificationDeliveryLocal.NewConfig())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(transport.Send(ctx, nil)).To(MatchError("message is missing"))
+		})
+
+		It("only logs the message if the directory is not specified", func() {
+			transport, err := notificationDeliveryLocal.NewTransport(notification.ChannelPush, notificationDeliveryLocal.NewConfig())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(transport.Path()).To(BeEmpty())
+			Expect(transport.Send(ctx, message)).To(Succeed())
+		})
+
+		Context("with directory", func() {
+			var directory string
+			var transport *notificationDeliveryLocal.Transport
+
+			BeforeEach(func() {
+				directory = test.RandomTemporaryDirectory()
+				var err error
+				transport, err = notificationDeliveryLocal.NewTransport(notification.ChannelPush, &notificationDeliveryLocal.Config{Directory: filepath.Join(directory, "notifications")})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(directory)
+			})
+
+			It("appends each message to the file for the channel", func() {
+				Expect(transport.Path()).To(Equal(filepath.Join(directory, "notifications", "push.jsonl")))
+				Expect(transport.Send(ctx, message)).To(Succeed())
+				Expect(transport.Send(ctx, message)).To(Succeed())
+				bytes, err := ioutil.ReadFile(transport.Path())
+				Expect(err).ToNot(HaveOccurred())
+				line := `{"notificationId":"0123456789abcdef0123456789abcdef","notificationType":"message","channel":"push","address":"device-token","locale":"en","subject":"Hello","text":"Hello, world"}` + "\n"
+				Expect(string(bytes)).To(Equal(line + line))
+			})
+		})
+	})
+})
@@ -0,0 +1,206 @@
+package delivery
+
+import (
+	"context"
+	"time"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/notification/template"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+)
+
+const (
+	AttemptsMaximum      = 5
+	RetryDurationInitial = time.Minute
+	RetryDurationMaximum = time.Hour
+)
+
+// Both the task and each channel delivery are retried with the same backoff
+var retry = &task.Retry{
+	AttemptsMaximum: AttemptsMaximum,
+	DurationInitial: RetryDurationInitial,
+	DurationMaximum: RetryDurationMaximum,
+}
+
+type Runner struct {
+	logger             log.Logger
+	authClient         auth.Client
+	notificationClient notification.Client
+	renderer           *template.Renderer
+	transports         map[string]Transport
+}
+
+func NewRunner(logger log.Logger, authClient auth.Client, notificationClient notification.Client, renderer *template.Renderer, transports []Transport) (*Runner, error) {
+	if logger == nil {
+		return nil, errors.New("logger is missing")
+	}
+	if authClient == nil {
+		return nil, errors.New("auth client is missing")
+	}
+	if notificationClient == nil {
+		return nil, errors.New("notification client is missing")
+	}
+	if renderer == nil {
+		return nil, errors.New("renderer is missing")
+	}
+
+	transportsByChannel := map[string]Transport{}
+	for _, transport := range transports {
+		if transport == nil {
+			return nil, errors.New("transport is missing")
+		}
+		channel := transport.Channel()
+		if _, exists := transportsByChannel[channel]; exists {
+			return nil, errors.Newf("transport for channel %q is duplicate", channel)
+		}
+		transportsByChannel[channel] = transport
+	}
+
+	return &Runner{
+		logger:             logger,
+		authClient:         authClient,
+		notificationClient: notificationClient,
+		renderer:           renderer,
+		transports:         transportsByChannel,
+	}, nil
+}
+
+func (r *Runner) Logger() log.Logger {
+	return r.logger
+}
+
+func (r *Runner) AuthClient() auth.Client {
+	return r.authClient
+}
+
+func (r *Runner) NotificationClient() notification.Client {
+	return r.notificationClient
+}
+
+func (r *Runner) Renderer() *template.Renderer {
+	return r.renderer
+}
+
+func (r *Runner) Transport(channel string) Transport {
+	return r.transports[channel]
+}
+
+func (r *Runner) CanRunTask(tsk *task.Task) bool {
+	return tsk != nil && tsk.Type == Type
+}
+
+// Pending deliveries that fail are retried with exponential backoff; the task completes once
+// there are no more pending deliveries
+func (r *Runner) Run(ctx context.Context, tsk *task.Task) {
+	logger := r.Logger().WithField("taskId", tsk.ID)
+	ctx = log.NewContextWithLogger(ctx, logger)
+
+	tsk.ClearError()
+
+	notificationID, ok := tsk.Data["notificationId"].(string)
+	if !ok || notificationID == "" {
+		tsk.AppendError(errors.New("notification id is missing"))
+		tsk.SetFailed()
+		return
+	}
+
+	if serverSessionToken, err := r.AuthClient().ServerSessionToken(); err != nil {
+		retry.RepeatOnError(tsk, errors.Wrap(err, "unable to get server session token"))
+	} else if retryDuration, err := r.deliver(auth.NewContextWithServerSessionToken(ctx, serverSessionToken), notificationID); err != nil {
+		retry.RepeatOnError(tsk, errors.Wrap(err, "unable to deliver notification"))
+	} else {
+		tsk.ClearErrorCount()
+		if retryDuration != nil {
+			tsk.RepeatAvailableAfter(*retryDuration)
+		}
+	}
+}
+
+func (r *Runner) deliver(ctx context.Context, notificationID string) (*time.Duration, error) {
+	logger := log.LoggerFromContext(ctx).WithField("notificationId", notificationID)
+
+	ntfctn, err := r.NotificationClient().GetNotification(ctx, notificationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get notification")
+	} else if ntfctn == nil {
+		logger.Debug("Notification not found, skipping delivery")
+		return nil, nil
+	} else if ntfctn.IsDismissed() {
+		logger.Debug("Notification dismissed, skipping delivery")
+		return nil, nil
+	}
+
+	preferences, err := r.NotificationClient().GetUserPreferences(ctx, ntfctn.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get user preferences")
+	} else if preferences == nil {
+		logger.Debug("User preferences not found, skipping delivery")
+		return nil, nil
+	}
+
+	deliveries := ntfctn.Deliveries
+	var content *template.Content
+	var retryDuration *time.Duration
+	var attempted bool
+	for _, channel := range notification.Channels() {
+		channelPreference := preferences.Channel(channel)
+		if channelPreference == nil || !channelPreference.AllowsType(ntfctn.Type) {
+			continue
+		}
+
+		transport := r.Transport(channel)
+		if transport == nil {
+			logger.WithField("channel", channel).Warn("Transport not available for channel")
+			continue
+		}
+
+		dlvry := deliveries.Get(channel)
+		if dlvry == nil {
+			dlvry = notification.NewDelivery(channel, *channelPreference.Address)
+			deliveries = append(deliveries, dlvry)
+		} else if !dlvry.IsPending() {
+			continue
+		}
+
+		if content == nil {
+			if content, err = r.Renderer().Render(ntfctn.Type, preferences.LocaleOrDefault(), ntfctn.Payload); err != nil {
+				return nil, errors.Wrap(err, "unable to render notification")
+			}
+		}
+
+		now := time.Now().Truncate(time.Second)
+		dlvry.Attempts++
+		dlvry.LastAttemptTime = pointer.FromTime(now)
+
+		attempted = true
+		if err = transport.Send(ctx, NewMessage(ntfctn, dlvry, content)); err != nil {
+			logger.WithError(err).WithFields(log.Fields{"channel": channel, "attempts": dlvry.Attempts}).Warn("Unable to send notification")
+			dlvry.Error = &errors.Serializable{Error: err}
+			if dlvry.Attempts >= AttemptsMaximum {
+				dlvry.State = notification.DeliveryStateFailed
+			} else if duration := retry.Duration(dlvry.Attempts); retryDuration == nil || duration < *retryDuration {
+				retryDuration = &duration
+			}
+		} else {
+			dlvry.State = notification.DeliveryStateDelivered
+			dlvry.DeliveredTime = pointer.FromTime(now)
+			dlvry.Error = nil
+		}
+	}
+
+	if !attempted {
+		return nil, nil
+	}
+
+	update := notification.NewNotificationUpdate()
+	update.Deliveries = &deliveries
+	if _, err = r.NotificationClient().UpdateNotification(ctx, notificationID, update); err != nil {
+		return nil, errors.Wrap(err, "unable to update notification")
+	}
+
+	return retryDuration, nil
+}
@@ -0,0 +1,340 @@
+package delivery_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"time"
+
+	"github.com/tidepool-org/platform/auth"
+	authTest "github.com/tidepool-org/platform/auth/test"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	logTest "github.com/tidepool-org/platform/log/test"
+	"github.com/tidepool-org/platform/notification"
+	notificationDelivery "github.com/tidepool-org/platform/notification/delivery"
+	notificationDeliveryTest "github.com/tidepool-org/platform/notification/delivery/test"
+	notificationTemplate "github.com/tidepool-org/platform/notification/template"
+	notificationTest "github.com/tidepool-org/platform/notification/test"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+	"github.com/tidepool-org/platform/test"
+	"github.com/tidepool-org/platform/user"
+)
+
+var _ = Describe("Runner", func() {
+	var logger *logTest.Logger
+	var authClient *authTest.Client
+	var notificationClient *notificationTest.Client
+	var renderer *notificationTemplate.Renderer
+	var emailTransport *notificationDeliveryTest.Transport
+	var smsTransport *notificationDeliveryTest.Transport
+
+	BeforeEach(func() {
+		logger = logTest.NewLogger()
+		authClient = authTest.NewClient()
+		notificationClient = notificationTest.NewClient()
+		var err error
+		renderer, err = notificationTemplate.NewDefaultRenderer()
+		Expect(err).ToNot(HaveOccurred())
+		emailTransport = notificationDeliveryTest.NewTransport(notification.ChannelEmail)
+		smsTransport = notificationDeliveryTest.NewTransport(notification.ChannelSMS)
+	})
+
+	AfterEach(func() {
+		smsTransport.Expectations()
+		emailTransport.Expectations()
+		notificationClient.Expectations()
+		authClient.Expectations()
+	})
+
+	Context("NewRunner", func() {
+		It("returns an error if the logger is missing", func() {
+			rnnr, err := notificationDelivery.NewRunner(nil, authClient, notificationClient, renderer, nil)
+			Expect(err).To(MatchError("logger is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the auth client is missing", func() {
+			rnnr, err := notificationDelivery.NewRunner(logger, nil, notificationClient, renderer, nil)
+			Expect(err).To(MatchError("auth client is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the notification client is missing", func() {
+			rnnr, err := notificationDelivery.NewRunner(logger, authClient, nil, renderer, nil)
+			Expect(err).To(MatchError("notification client is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the renderer is missing", func() {
+			rnnr, err := notificationDelivery.NewRunner(logger, authClient, notificationClient, nil, nil)
+			Expect(err).To(MatchError("renderer is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if a transport is missing", func() {
+			rnnr, err := notificationDelivery.NewRunner(logger, authClient, notificationClient, renderer, []notificationDelivery.Transport{nil})
+			Expect(err).To(MatchError("transport is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if a transport channel is duplicate", func() {
+			rnnr, err := notificationDelivery.NewRunner(logger, authClient, notificationClient, renderer, []notificationDelivery.Transport{emailTransport, notificationDeliveryTest.NewTransport(notification.ChannelEmail)})
+			Expect(err).To(MatchError(`transport for channel "email" is duplicate`))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			rnnr, err := notificationDelivery.NewRunner(logger, authClient, notificationClient, renderer, []notificationDelivery.Transport{emailTransport, smsTransport})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rnnr).ToNot(BeNil())
+			Expect(rnnr.Renderer()).To(Equal(renderer))
+			Expect(rnnr.Transport(notification.ChannelEmail)).To(Equal(emailTransport))
+			Expect(rnnr.Transport(notification.ChannelPush)).To(BeNil())
+		})
+	})
+
+	Context("with new runner", func() {
+		var rnnr *notificationDelivery.Runner
+
+		BeforeEach(func() {
+			var err error
+			rnnr, err = notificationDelivery.NewRunner(logger, authClient, notificationClient, renderer, []notificationDelivery.Transport{emailTransport, smsTransport})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rnnr).ToNot(BeNil())
+		})
+
+		Context("CanRunTask", func() {
+			It("returns false if the task is missing", func() {
+				Expect(rnnr.CanRunTask(nil)).To(BeFalse())
+			})
+
+			It("returns false if the task type does not match", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: test.RandomString()})).To(BeFalse())
+			})
+
+			It("returns true if the task type matches", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: notificationDelivery.Type})).To(BeTrue())
+			})
+		})
+
+		Context("Run", func() {
+			var ctx context.Context
+			var ntfctn *notification.Notification
+			var tsk *task.Task
+			var serverSessionToken string
+
+			BeforeEach(func() {
+				var err error
+				ctx = context.Background()
+				ntfctn, err = notification.NewNotification(user.NewID(), &notification.NotificationCreate{Type: notification.TypeMessage})
+				Expect(err).ToNot(HaveOccurred())
+				taskCreate, err := notificationDelivery.NewTaskCreate(ntfctn.ID)
+				Expect(err).ToNot(HaveOccurred())
+				tsk, err = task.NewTask(taskCreate)
+				Expect(err).ToNot(HaveOccurred())
+				tsk.State = task.TaskStateRunning
+				serverSessionToken = authTest.NewSessionToken()
+			})
+
+			It("fails the task if the notification id is missing", func() {
+				tsk.Data = map[string]interface{}{}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.IsFailed()).To(BeTrue())
+			})
+
+			It("records the error and retries if the server session token returns an error", func() {
+				authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: "", Error: errorsTest.NewError()}}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.State).To(Equal(task.TaskStatePending))
+				Expect(tsk.AvailableTime).ToNot(BeNil())
+				Expect(*tsk.AvailableTime).To(BeTemporally("~", time.Now().Add(notificationDelivery.RetryDurationInitial), time.Second))
+				Expect(tsk.Data["errorCount"]).To(Equal(1))
+			})
+
+			It("retries with backoff if the server session token returns an error again", func() {
+				tsk.Data["errorCount"] = 2
+				authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: "", Error: errorsTest.NewError()}}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.State).To(Equal(task.TaskStatePending))
+				Expect(*tsk.AvailableTime).To(BeTemporally("~", time.Now().Add(4*notificationDelivery.RetryDurationInitial), time.Second))
+				Expect(tsk.Data["errorCount"]).To(Equal(3))
+			})
+
+			It("fails the task if the errors reach the maximum attempts", func() {
+				tsk.Data["errorCount"] = float64(notificationDelivery.AttemptsMaximum - 1)
+				authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: "", Error: errorsTest.NewError()}}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.IsFailed()).To(BeTrue())
+			})
+
+			Context("with server session token", func() {
+				BeforeEach(func() {
+					authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: serverSessionToken, Error: nil}}
+				})
+
+				It("records the error and retries if get notification returns an error", func() {
+					notificationClient.GetNotificationOutputs = []notificationTest.GetNotificationOutput{{Notification: nil, Error: errorsTest.NewError()}}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeTrue())
+					Expect(tsk.State).To(Equal(task.TaskStatePending))
+				})
+
+				It("completes if the notification is not found", func() {
+					notificationClient.GetNotificationOutputs = []notificationTest.GetNotificationOutput{{Notification: nil, Error: nil}}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeFalse())
+					Expect(tsk.State).To(Equal(task.TaskStateRunning))
+					Expect(notificationClient.GetNotificationInputs).To(HaveLen(1))
+					Expect(auth.ServerSessionTokenFromContext(notificationClient.GetNotificationInputs[0].Context)).To(Equal(serverSessionToken))
+					Expect(notificationClient.GetNotificationInputs[0].ID).To(Equal(ntfctn.ID))
+				})
+
+				It("completes if the notification is dismissed", func() {
+					ntfctn.DismissedTime = pointer.FromTime(time.Now())
+					notificationClient.GetNotificationOutputs = []notificationTest.GetNotificationOutput{{Notification: ntfctn, Error: nil}}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeFalse())
+					Expect(tsk.State).To(Equal(task.TaskStateRunning))
+				})
+
+				Context("with notification", func() {
+					BeforeEach(func() {
+						notificationClient.GetNotificationOutputs = []notificationTest.GetNotificationOutput{{Notification: ntfctn, Error: nil}}
+					})
+
+					It("records the error and retries if get user preferences returns an error", func() {
+						notificationClient.GetUserPreferencesOutputs = []notificationTest.GetUserPreferencesOutput{{Preferences: nil, Error: errorsTest.NewError()}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeTrue())
+						Expect(tsk.State).To(Equal(task.TaskStatePending))
+					})
+
+					It("completes if the user preferences are not found", func() {
+						notificationClient.GetUserPreferencesOutputs = []notificationTest.GetUserPreferencesOutput{{Preferences: nil, Error: nil}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeFalse())
+						Expect(tsk.State).To(Equal(task.TaskStateRunning))
+						Expect(notificationClient.GetUserPreferencesInputs).To(Equal([]notificationTest.GetUserPreferencesInput{{Context: notificationClient.GetUserPreferencesInputs[0].Context, UserID: ntfctn.UserID}}))
+					})
+
+					It("completes without updating if no channel accepts the notification type", func() {
+						notificationClient.GetUserPreferencesOutputs = []notificationTest.GetUserPreferencesOutput{{Preferences: &notification.Preferences{
+							UserID: ntfctn.UserID,
+							Email:  &notification.ChannelPreference{Enabled: true, Address: pointer.FromString("user@example.com"), Types: pointer.FromStringArray([]string{notification.TypeShareInvitation})},
+							SMS:    &notification.ChannelPreference{Enabled: false, Address: pointer.FromString("+15555550100")},
+						}, Error: nil}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeFalse())
+						Expect(tsk.State).To(Equal(task.TaskStateRunning))
+					})
+
+					It("skips channels without a transport", func() {
+						notificationClient.GetUserPreferencesOutputs = []notificationTest.GetUserPreferencesOutput{{Preferences: &notification.Preferences{
+							UserID: ntfctn.UserID,
+							Push:   &notification.ChannelPreference{Enabled: true, Address: pointer.FromString("device-token")},
+						}, Error: nil}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeFalse())
+						Expect(tsk.State).To(Equal(task.TaskStateRunning))
+					})
+
+					Context("with preferences for email and sms", func() {
+						BeforeEach(func() {
+							notificationClient.GetUserPreferencesOutputs = []notificationTest.GetUserPreferencesOutput{{Preferences: &notification.Preferences{
+								UserID: ntfctn.UserID,
+								Email:  &notification.ChannelPreference{Enabled: true, Address: pointer.FromString("user@example.com")},
+								SMS:    &notification.ChannelPreference{Enabled: true, Address: pointer.FromString("+15555550100")},
+							}, Error: nil}}
+						})
+
+						It("records the delivered deliveries and completes", func() {
+							emailTransport.SendOutputs = []error{nil}
+							smsTransport.SendOutputs = []error{nil}
+							notificationClient.UpdateNotificationOutputs = []notificationTest.UpdateNotificationOutput{{Notification: ntfctn, Error: nil}}
+							rnnr.Run(ctx, tsk)
+							Expect(tsk.HasError()).To(BeFalse())
+							Expect(tsk.State).To(Equal(task.TaskStateRunning))
+							Expect(emailTransport.SendInputs).To(HaveLen(1))
+							Expect(emailTransport.SendInputs[0].Message.Address).To(Equal("user@example.com"))
+							Expect(emailTransport.SendInputs[0].Message.Locale).To(Equal(notification.LocaleEnglish))
+							Expect(emailTransport.SendInputs[0].Message.Subject).To(Equal("You have a new message"))
+							Expect(smsTransport.SendInputs).To(HaveLen(1))
+							Expect(smsTransport.SendInputs[0].Message.Address).To(Equal("+15555550100"))
+							Expect(notificationClient.UpdateNotificationInputs).To(HaveLen(1))
+							Expect(notificationClient.UpdateNotificationInputs[0].ID).To(Equal(ntfctn.ID))
+							deliveries := *notificationClient.UpdateNotificationInputs[0].Update.Deliveries
+							Expect(deliveries).To(HaveLen(2))
+							for _, dlvry := range deliveries {
+								Expect(dlvry.State).To(Equal(notification.DeliveryStateDelivered))
+								Expect(dlvry.Attempts).To(Equal(1))
+								Expect(dlvry.DeliveredTime).ToNot(BeNil())
+								Expect(dlvry.Error).To(BeNil())
+							}
+						})
+
+						It("renders the content in the locale of the user preferences", func() {
+							notificationClient.GetUserPreferencesOutputs[0].Preferences.Locale = pointer.FromString("fr-CA")
+							ntfctn.Payload = map[string]interface{}{"subject": "Bonjour"}
+							emailTransport.SendOutputs = []error{nil}
+							smsTransport.SendOutputs = []error{nil}
+							notificationClient.UpdateNotificationOutputs = []notificationTest.UpdateNotificationOutput{{Notification: ntfctn, Error: nil}}
+							rnnr.Run(ctx, tsk)
+							Expect(tsk.HasError()).To(BeFalse())
+							Expect(emailTransport.SendInputs[0].Message.Locale).To(Equal(notification.LocaleFrench))
+							Expect(emailTransport.SendInputs[0].Message.Subject).To(Equal("Bonjour"))
+							Expect(emailTransport.SendInputs[0].Message.Text).To(Equal("Vous avez un nouveau message sur Tidepool."))
+							Expect(smsTransport.SendInputs[0].Message.Locale).To(Equal(notification.LocaleFrench))
+						})
+
+						It("records the failed delivery and retries after the retry duration", func() {
+							emailTransport.SendOutputs = []error{nil}
+							smsTransport.SendOutputs = []error{errorsTest.NewError()}
+							notificationClient.UpdateNotificationOutputs = []notificationTest.UpdateNotificationOutput{{Notification: ntfctn, Error: nil}}
+							rnnr.Run(ctx, tsk)
+							Expect(tsk.HasError()).To(BeFalse())
+							Expect(tsk.State).To(Equal(task.TaskStatePending))
+							Expect(tsk.AvailableTime).ToNot(BeNil())
+							Expect(*tsk.AvailableTime).To(BeTemporally("~", time.Now().Add(notificationDelivery.RetryDurationInitial), time.Second))
+							deliveries := *notificationClient.UpdateNotificationInputs[0].Update.Deliveries
+							Expect(deliveries.Get(notification.ChannelEmail).State).To(Equal(notification.DeliveryStateDelivered))
+							Expect(deliveries.Get(notification.ChannelSMS).State).To(Equal(notification.DeliveryStatePending))
+							Expect(deliveries.Get(notification.ChannelSMS).Attempts).To(Equal(1))
+							Expect(deliveries.Get(notification.ChannelSMS).Error).ToNot(BeNil())
+						})
+
+						It("only retries pending deliveries and fails the delivery after the maximum attempts", func() {
+							emailDelivery := notification.NewDelivery(notification.ChannelEmail, "user@example.com")
+							emailDelivery.State = notification.DeliveryStateDelivered
+							smsDelivery := notification.NewDelivery(notification.ChannelSMS, "+15555550100")
+							smsDelivery.Attempts = notificationDelivery.AttemptsMaximum - 1
+							ntfctn.Deliveries = notification.Deliveries{emailDelivery, smsDelivery}
+							smsTransport.SendOutputs = []error{errorsTest.NewError()}
+							notificationClient.UpdateNotificationOutputs = []notificationTest.UpdateNotificationOutput{{Notification: ntfctn, Error: nil}}
+							rnnr.Run(ctx, tsk)
+							Expect(tsk.HasError()).To(BeFalse())
+							Expect(tsk.State).To(Equal(task.TaskStateRunning))
+							Expect(emailTransport.SendInvocations).To(Equal(0))
+							deliveries := *notificationClient.UpdateNotificationInputs[0].Update.Deliveries
+							Expect(deliveries.Get(notification.ChannelSMS).State).To(Equal(notification.DeliveryStateFailed))
+							Expect(deliveries.Get(notification.ChannelSMS).Attempts).To(Equal(notificationDelivery.AttemptsMaximum))
+						})
+
+						It("records the error if update notification returns an error", func() {
+							emailTransport.SendOutputs = []error{nil}
+							smsTransport.SendOutputs = []error{nil}
+							notificationClient.UpdateNotificationOutputs = []notificationTest.UpdateNotificationOutput{{Notification: nil, Error: errorsTest.NewError()}}
+							rnnr.Run(ctx, tsk)
+							Expect(tsk.HasError()).To(BeTrue())
+						})
+					})
+				})
+			})
+		})
+	})
+})
@@ -0,0 +1,46 @@
+package smtp
+
+import (
+	"net"
+
+	"github.com/tidepool-org/platform/config"
+	"github.com/tidepool-org/platform/errors"
+)
+
+// If username is not specified, then no authentication is performed
+type Config struct {
+	Address  string
+	Username string
+	Password string
+	From     string
+}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+func (c *Config) Load(configReporter config.Reporter) error {
+	if configReporter == nil {
+		return errors.New("config reporter is missing")
+	}
+
+	c.Address = configReporter.GetWithDefault("address", c.Address)
+	c.Username = configReporter.GetWithDefault("username", c.Username)
+	c.Password = configReporter.GetWithDefault("password", c.Password)
+	c.From = configReporter.GetWithDefault("from", c.From)
+
+	return nil
+}
+
+func (c *Config) Validate() error {
+	if c.Address == "" {
+		return errors.New("address is missing")
+	} else if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return errors.New("address is invalid")
+	}
+	if c.From == "" {
+		return errors.New("from is missing")
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package smtp_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	configTest "github.com/tidepool-org/platform/config/test"
+	notificationDeliverySMTP "github.com/tidepool-org/platform/notification/delivery/smtp"
+)
+
+var _ = Describe("Config", func() {
+	var cfg *notificationDeliverySMTP.Config
+
+	BeforeEach(func() {
+		cfg = notificationDeliverySMTP.NewConfig()
+		Expect(cfg).ToNot(BeNil())
+	})
+
+	Context("Load", func() {
+		It("returns an error if config reporter is missing", func() {
+			Expect(cfg.Load(nil)).To(MatchError("config reporter is missing"))
+		})
+
+		It("returns successfully and uses values from config reporter", func() {
+			configReporter := configTest.NewReporter()
+			configReporter.Config["address"] = "localhost:25"
+			configReporter.Config["username"] = "username"
+			configReporter.Config["password"] = "password"
+			configReporter.Config["from"] = "notifications@example.com"
+			Expect(cfg.Load(configReporter)).To(Succeed())
+			Expect(cfg).To(Equal(&notificationDeliverySMTP.Config{Address: "localhost:25", Username: "username", Password: "password", From: "notifications@example.com"}))
+		})
+	})
+
+	Context("Validate", func() {
+		BeforeEach(func() {
+			cfg.Address = "localhost:25"
+			cfg.From = "notifications@example.com"
+		})
+
+		It("returns an error if the address is missing", func() {
+			cfg.Address = ""
+			Expect(cfg.Validate()).To(MatchError("address is missing"))
+		})
+
+		It("returns an error if the address is invalid", func() {
+			cfg.Address = "localhost"
+			Expect(cfg.Validate()).To(MatchError("address is invalid"))
+		})
+
+		It("returns an error if from is missing", func() {
+			cfg.From = ""
+			Expect(cfg.Validate()).To(MatchError("from is missing"))
+		})
+
+		It("returns successfully", func() {
+			Expect(cfg.Validate()).To(Succeed())
+		})
+	})
+})
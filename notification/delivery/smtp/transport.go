@@ -0,0 +1,109 @@
+package smtp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	netSMTP "net/smtp"
+	"net/textproto"
+	"time"
+
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/notification/delivery"
+)
+
+type Transport struct {
+	config *Config
+}
+
+func NewTransport(cfg *Config) (*Transport, error) {
+	if cfg == nil {
+		return nil, errors.New("config is missing")
+	} else if err := cfg.Validate(); err != nil {
+		return nil, errors.Wrap(err, "config is invalid")
+	}
+
+	return &Transport{
+		config: cfg,
+	}, nil
+}
+
+func (t *Transport) Channel() string {
+	return notification.ChannelEmail
+}
+
+func (t *Transport) Send(ctx context.Context, message *delivery.Message) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if message == nil {
+		return errors.New("message is missing")
+	}
+
+	var auth netSMTP.Auth
+	if t.config.Username != "" {
+		host, _, _ := net.SplitHostPort(t.config.Address)
+		auth = netSMTP.PlainAuth("", t.config.Username, t.config.Password, host)
+	}
+
+	err := netSMTP.SendMail(t.config.Address, auth, t.config.From, []string{message.Address}, NewBody(t.config.From, message, time.Now()))
+	log.LoggerFromContext(ctx).WithFields(log.Fields{"notificationId": message.NotificationID, "address": t.config.Address}).WithError(err).Debug("Send")
+	if err != nil {
+		return errors.Wrap(err, "unable to send mail")
+	}
+
+	return nil
+}
+
+// If the message has HTML, then the body is multipart/alternative with both the text and HTML parts
+func NewBody(from string, message *delivery.Message, date time.Time) []byte {
+	buffer := &bytes.Buffer{}
+	fmt.Fprintf(buffer, "From: %s\r\n", from)
+	fmt.Fprintf(buffer, "To: %s\r\n", message.Address)
+	fmt.Fprintf(buffer, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(buffer, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	if message.Locale != "" {
+		fmt.Fprintf(buffer, "Content-Language: %s\r\n", message.Locale)
+	}
+	fmt.Fprintf(buffer, "MIME-Version: 1.0\r\n")
+
+	if message.HTML == "" {
+		fmt.Fprintf(buffer, "Content-Type: text/plain; charset=utf-8\r\n")
+		fmt.Fprintf(buffer, "Content-Transfer-Encoding: quoted-printable\r\n")
+		fmt.Fprintf(buffer, "\r\n")
+		writeQuotedPrintable(buffer, message.Text)
+		return buffer.Bytes()
+	}
+
+	writer := multipart.NewWriter(buffer)
+	fmt.Fprintf(buffer, "Content-Type: multipart/alternative; boundary=%s\r\n", writer.Boundary())
+	fmt.Fprintf(buffer, "\r\n")
+	writePart(writer, "text/plain; charset=utf-8", message.Text)
+	writePart(writer, "text/html; charset=utf-8", message.HTML)
+	writer.Close()
+	return buffer.Bytes()
+}
+
+func writePart(writer *multipart.Writer, contentType string, content string) {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	if part, err := writer.CreatePart(header); err == nil {
+		writeQuotedPrintable(part, content)
+	}
+}
+
+// Quoted-printable keeps lines within the SMTP line length limit and non-ASCII content 7-bit safe
+func writeQuotedPrintable(writer io.Writer, content string) {
+	quotedPrintableWriter := quotedprintable.NewWriter(writer)
+	quotedPrintableWriter.Write([]byte(content))
+	quotedPrintableWriter.Close()
+	fmt.Fprintf(writer, "\r\n")
+}
@@ -0,0 +1,184 @@
+package smtp_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"bufio"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/tidepool-org/platform/log"
+	logTest "github.com/tidepool-org/platform/log/test"
+	"github.com/tidepool-org/platform/notification"
+	notificationDelivery "github.com/tidepool-org/platform/notification/delivery"
+	notificationDeliverySMTP "github.com/tidepool-org/platform/notification/delivery/smtp"
+)
+
+// Accepts a single connection and records the message data; supports only the commands used without authentication
+func serveSMTP(listener net.Listener, data chan<- string) {
+	defer GinkgoRecover()
+
+	conn, err := listener.Accept()
+	Expect(err).ToNot(HaveOccurred())
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	Expect(text.PrintfLine("220 localhost")).To(Succeed())
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		switch command := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); command {
+		case "EHLO", "HELO", "MAIL", "RCPT", "NOOP", "RSET":
+			Expect(text.PrintfLine("250 OK")).To(Succeed())
+		case "DATA":
+			Expect(text.PrintfLine("354 Go ahead")).To(Succeed())
+			lines, err := text.ReadDotLines()
+			Expect(err).ToNot(HaveOccurred())
+			data <- strings.Join(lines, "\n")
+			Expect(text.PrintfLine("250 OK")).To(Succeed())
+		case "QUIT":
+			Expect(text.PrintfLine("221 Bye")).To(Succeed())
+			return
+		default:
+			Expect(text.PrintfLine("502 Not implemented")).To(Succeed())
+		}
+	}
+}
+
+var _ = Describe("Transport", func() {
+	var message *notificationDelivery.Message
+
+	BeforeEach(func() {
+		message = &notificationDelivery.Message{
+			NotificationID:   notification.NewID(),
+			NotificationType: notification.TypeMessage,
+			Channel:          notification.ChannelEmail,
+			Address:          "user@example.com",
+			Subject:          "Hello",
+			Text:             "Hello, world",
+		}
+	})
+
+	Context("NewTransport", func() {
+		It("returns an error if the config is missing", func() {
+			transport, err := notificationDeliverySMTP.NewTransport(nil)
+			Expect(err).To(MatchError("config is missing"))
+			Expect(transport).To(BeNil())
+		})
+
+		It("returns an error if the config is invalid", func() {
+			transport, err := notificationDeliverySMTP.NewTransport(notificationDeliverySMTP.NewConfig())
+			Expect(err).To(MatchError("config is invalid; address is missing"))
+			Expect(transport).To(BeNil())
+		})
+	})
+
+	Context("with listener", func() {
+		var listener net.Listener
+		var transport *notificationDeliverySMTP.Transport
+		var ctx context.Context
+
+		BeforeEach(func() {
+			var err error
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+			transport, err = notificationDeliverySMTP.NewTransport(&notificationDeliverySMTP.Config{Address: listener.Addr().String(), From: "notifications@example.com"})
+			Expect(err).ToNot(HaveOccurred())
+			ctx = log.NewContextWithLogger(context.Background(), logTest.NewLogger())
+		})
+
+		AfterEach(func() {
+			listener.Close()
+		})
+
+		It("returns the email channel", func() {
+			Expect(transport.Channel()).To(Equal(notification.ChannelEmail))
+		})
+
+		It("returns an error if the message is missing", func() {
+			Expect(transport.Send(ctx, nil)).To(MatchError("message is missing"))
+		})
+
+		It("sends the message", func() {
+			data := make(chan string, 1)
+			go serveSMTP(listener, data)
+			Expect(transport.Send(ctx, message)).To(Succeed())
+			var body string
+			Eventually(data).Should(Receive(&body))
+			Expect(body).To(ContainSubstring("To: user@example.com"))
+			Expect(body).To(ContainSubstring("Subject: Hello"))
+			Expect(body).To(ContainSubstring("Hello, world"))
+		})
+
+		It("returns an error if unable to connect", func() {
+			listener.Close()
+			Expect(transport.Send(ctx, message)).To(MatchError(HavePrefix("unable to send mail")))
+		})
+	})
+
+	Context("NewBody", func() {
+		It("returns the message as plain text with headers", func() {
+			date := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+			reader := textproto.NewReader(bufio.NewReader(strings.NewReader(string(notificationDeliverySMTP.NewBody("notifications@example.com", message, date)))))
+			header, err := reader.ReadMIMEHeader()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(header.Get("From")).To(Equal("notifications@example.com"))
+			Expect(header.Get("To")).To(Equal("user@example.com"))
+			Expect(header.Get("Subject")).To(Equal("Hello"))
+			Expect(header.Get("Date")).To(Equal("Thu, 02 Jan 2020 03:04:05 +0000"))
+			Expect(header.Get("Content-Type")).To(Equal("text/plain; charset=utf-8"))
+			Expect(header.Get("Content-Transfer-Encoding")).To(Equal("quoted-printable"))
+			Expect(ioutil.ReadAll(quotedprintable.NewReader(reader.R))).To(Equal([]byte("Hello, world\r\n")))
+		})
+
+		It("returns the message with lines within the line length limit and only ascii characters", func() {
+			message.Text = strings.Repeat("Glycémie élevée ", 100)
+			body := notificationDeliverySMTP.NewBody("notifications@example.com", message, time.Now())
+			for _, line := range strings.Split(string(body), "\r\n") {
+				Expect(len(line)).To(BeNumerically("<=", 78))
+				for _, character := range line {
+					Expect(character).To(BeNumerically("<", 128))
+				}
+			}
+			reader := textproto.NewReader(bufio.NewReader(bytes.NewReader(body)))
+			_, err := reader.ReadMIMEHeader()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ioutil.ReadAll(quotedprintable.NewReader(reader.R))).To(Equal([]byte(message.Text + "\r\n")))
+		})
+
+		It("returns the message as multipart alternative if the message has html", func() {
+			message.Locale = notification.LocaleFrench
+			message.HTML = "<p>Hello, world</p>"
+			body := notificationDeliverySMTP.NewBody("notifications@example.com", message, time.Now())
+			reader := textproto.NewReader(bufio.NewReader(bytes.NewReader(body)))
+			header, err := reader.ReadMIMEHeader()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(header.Get("Content-Language")).To(Equal("fr"))
+			mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mediaType).To(Equal("multipart/alternative"))
+			multipartReader := multipart.NewReader(reader.R, params["boundary"])
+			part, err := multipartReader.NextRawPart()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(part.Header.Get("Content-Type")).To(Equal("text/plain; charset=utf-8"))
+			Expect(part.Header.Get("Content-Transfer-Encoding")).To(Equal("quoted-printable"))
+			Expect(ioutil.ReadAll(quotedprintable.NewReader(part))).To(Equal([]byte("Hello, world\r\n")))
+			part, err = multipartReader.NextRawPart()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(part.Header.Get("Content-Type")).To(Equal("text/html; charset=utf-8"))
+			Expect(part.Header.Get("Content-Transfer-Encoding")).To(Equal("quoted-printable"))
+			Expect(ioutil.ReadAll(quotedprintable.NewReader(part))).To(Equal([]byte("<p>Hello, world</p>\r\n")))
+		})
+	})
+})
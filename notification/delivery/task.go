@@ -0,0 +1,27 @@
+package delivery
+
+import (
+	"fmt"
+
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+)
+
+func TaskName(notificationID string) string {
+	return fmt.Sprintf("%s:%s", Type, notificationID)
+}
+
+func NewTaskCreate(notificationID string) (*task.TaskCreate, error) {
+	if notificationID == "" {
+		return nil, errors.New("notification id is missing")
+	}
+
+	return &task.TaskCreate{
+		Name: pointer.FromString(TaskName(notificationID)),
+		Type: Type,
+		Data: map[string]interface{}{
+			"notificationId": notificationID,
+		},
+	}, nil
+}
@@ -0,0 +1,41 @@
+package delivery_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/tidepool-org/platform/notification"
+	notificationDelivery "github.com/tidepool-org/platform/notification/delivery"
+)
+
+var _ = Describe("Task", func() {
+	var notificationID string
+
+	BeforeEach(func() {
+		notificationID = notification.NewID()
+	})
+
+	Context("TaskName", func() {
+		It("returns the type with the notification id", func() {
+			Expect(notificationDelivery.TaskName(notificationID)).To(Equal(notificationDelivery.Type + ":" + notificationID))
+		})
+	})
+
+	Context("NewTaskCreate", func() {
+		It("returns an error if the notification id is missing", func() {
+			taskCreate, err := notificationDelivery.NewTaskCreate("")
+			Expect(err).To(MatchError("notification id is missing"))
+			Expect(taskCreate).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			taskCreate, err := notificationDelivery.NewTaskCreate(notificationID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(taskCreate).ToNot(BeNil())
+			Expect(taskCreate.Name).ToNot(BeNil())
+			Expect(*taskCreate.Name).To(Equal(notificationDelivery.TaskName(notificationID)))
+			Expect(taskCreate.Type).To(Equal(notificationDelivery.Type))
+			Expect(taskCreate.Data).To(Equal(map[string]interface{}{"notificationId": notificationID}))
+		})
+	})
+})
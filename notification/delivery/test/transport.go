@@ -0,0 +1,51 @@
+package test
+
+import (
+	"context"
+
+	"github.com/onsi/gomega"
+
+	"github.com/tidepool-org/platform/notification/delivery"
+	"github.com/tidepool-org/platform/test"
+)
+
+type SendInput struct {
+	Context context.Context
+	Message *delivery.Message
+}
+
+type Transport struct {
+	*test.Mock
+	ChannelOutput   string
+	SendInvocations int
+	SendInputs      []SendInput
+	SendOutputs     []error
+}
+
+func NewTransport(channel string) *Transport {
+	return &Transport{
+		Mock:          test.NewMock(),
+		ChannelOutput: channel,
+	}
+}
+
+func (t *Transport) Channel() string {
+	return t.ChannelOutput
+}
+
+func (t *Transport) Send(ctx context.Context, message *delivery.Message) error {
+	t.SendInvocations++
+
+	t.SendInputs = append(t.SendInputs, SendInput{Context: ctx, Message: message})
+
+	gomega.Expect(t.SendOutputs).ToNot(gomega.BeEmpty())
+
+	output := t.SendOutputs[0]
+	t.SendOutputs = t.SendOutputs[1:]
+	return output
+}
+
+func (t *Transport) Expectations() {
+	t.Mock.Expectations()
+	gomega.Expect(t.SendOutputs).To(gomega.BeEmpty())
+}
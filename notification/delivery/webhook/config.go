@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"github.com/tidepool-org/platform/client"
+	"github.com/tidepool-org/platform/config"
+)
+
+// If authorization is specified, then it is sent as the Authorization header of each request
+type Config struct {
+	*client.Config
+	Authorization string
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Config: client.NewConfig(),
+	}
+}
+
+func (c *Config) Load(configReporter config.Reporter) error {
+	if err := c.Config.Load(configReporter); err != nil {
+		return err
+	}
+
+	c.Authorization = configReporter.GetWithDefault("authorization", c.Authorization)
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/tidepool-org/platform/client"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/notification/delivery"
+	"github.com/tidepool-org/platform/request"
+)
+
+const TimeoutDuration = 30 * time.Second
+
+// Transport posts each message as JSON to the configured address; suitable for SMS and push
+// gateways that accept generic webhooks
+type Transport struct {
+	channel       string
+	client        *client.Client
+	address       string
+	authorization string
+	httpClient    *http.Client
+}
+
+func NewTransport(channel string, cfg *Config) (*Transport, error) {
+	if channel == "" {
+		return nil, errors.New("channel is missing")
+	}
+	if cfg == nil {
+		return nil, errors.New("config is missing")
+	}
+
+	clnt, err := client.New(cfg.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Transport{
+		channel:       channel,
+		client:        clnt,
+		address:       cfg.Address,
+		authorization: cfg.Authorization,
+		httpClient: &http.Client{
+			Timeout: TimeoutDuration,
+		},
+	}, nil
+}
+
+func (t *Transport) Channel() string {
+	return t.channel
+}
+
+func (t *Transport) Send(ctx context.Context, message *delivery.Message) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if message == nil {
+		return errors.New("message is missing")
+	}
+
+	mutators := []request.RequestMutator{}
+	if t.authorization != "" {
+		mutators = append(mutators, request.NewHeaderMutator("Authorization", t.authorization))
+	}
+
+	return t.client.RequestDataWithHTTPClient(ctx, http.MethodPost, t.address, mutators, message, nil, nil, t.httpClient)
+}
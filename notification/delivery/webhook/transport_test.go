@@ -0,0 +1,119 @@
+package webhook_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+
+	"context"
+	"net/http"
+
+	configTest "github.com/tidepool-org/platform/config/test"
+	"github.com/tidepool-org/platform/log"
+	logTest "github.com/tidepool-org/platform/log/test"
+	"github.com/tidepool-org/platform/notification"
+	notificationDelivery "github.com/tidepool-org/platform/notification/delivery"
+	notificationDeliveryWebhook "github.com/tidepool-org/platform/notification/delivery/webhook"
+	testHTTP "github.com/tidepool-org/platform/test/http"
+)
+
+var _ = Describe("Transport", func() {
+	Context("Config", func() {
+		It("loads the authorization from the config reporter", func() {
+			configReporter := configTest.NewReporter()
+			configReporter.Config["address"] = "https://sms.example.com/send"
+			configReporter.Config["authorization"] = "Bearer token"
+			cfg := notificationDeliveryWebhook.NewConfig()
+			Expect(cfg.Load(configReporter)).To(Succeed())
+			Expect(cfg.Address).To(Equal("https://sms.example.com/send"))
+			Expect(cfg.Authorization).To(Equal("Bearer token"))
+		})
+	})
+
+	Context("NewTransport", func() {
+		It("returns an error if the channel is missing", func() {
+			transport, err := notificationDeliveryWebhook.NewTransport("", notificationDeliveryWebhook.NewConfig())
+			Expect(err).To(MatchError("channel is missing"))
+			Expect(transport).To(BeNil())
+		})
+
+		It("returns an error if the config is missing", func() {
+			transport, err := notificationDeliveryWebhook.NewTransport(notification.ChannelSMS, nil)
+			Expect(err).To(MatchError("config is missing"))
+			Expect(transport).To(BeNil())
+		})
+
+		It("returns an error if the config is invalid", func() {
+			transport, err := notificationDeliveryWebhook.NewTransport(notification.ChannelSMS, notificationDeliveryWebhook.NewConfig())
+			Expect(err).To(MatchError("config is invalid; address is missing"))
+			Expect(transport).To(BeNil())
+		})
+	})
+
+	Context("with server and new transport", func() {
+		var server *Server
+		var cfg *notificationDeliveryWebhook.Config
+		var transport *notificationDeliveryWebhook.Transport
+		var ctx context.Context
+		var message *notificationDelivery.Message
+
+		BeforeEach(func() {
+			server = NewServer()
+			cfg = notificationDeliveryWebhook.NewConfig()
+			cfg.Address = server.URL() + "/send"
+			cfg.UserAgent = testHTTP.NewUserAgent()
+			cfg.Authorization = "Bearer token"
+			var err error
+			transport, err = notificationDeliveryWebhook.NewTransport(notification.ChannelSMS, cfg)
+			Expect(err).ToNot(HaveOccurred())
+			ctx = log.NewContextWithLogger(context.Background(), logTest.NewLogger())
+			message = &notificationDelivery.Message{
+				NotificationID:   "0123456789abcdef0123456789abcdef",
+				NotificationType: notification.TypeMessage,
+				Channel:          notification.ChannelSMS,
+				Address:          "+15555550100",
+				Locale:           "en",
+				Subject:          "Hello",
+				Text:             "Hello, world",
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("returns the channel", func() {
+			Expect(transport.Channel()).To(Equal(notification.ChannelSMS))
+		})
+
+		It("returns an error if the message is missing", func() {
+			Expect(transport.Send(ctx, nil)).To(MatchError("message is missing"))
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("posts the message", func() {
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodPost, "/send"),
+					VerifyHeaderKV("Authorization", "Bearer token"),
+					VerifyContentType("application/json; charset=utf-8"),
+					VerifyBody([]byte(`{"notificationId":"0123456789abcdef0123456789abcdef","notificationType":"message","channel":"sms","address":"+15555550100","locale":"en","subject":"Hello","text":"Hello, world"}`+"\n")),
+					RespondWith(http.StatusNoContent, nil),
+				),
+			)
+			Expect(transport.Send(ctx, message)).To(Succeed())
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("returns an error if the response is not successful", func() {
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodPost, "/send"),
+					RespondWith(http.StatusBadGateway, nil),
+				),
+			)
+			Expect(transport.Send(ctx, message)).ToNot(Succeed())
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+})
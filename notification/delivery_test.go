@@ -0,0 +1,76 @@
+package notification_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"time"
+
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/pointer"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
+var _ = Describe("Delivery", func() {
+	It("DeliveryStates returns expected", func() {
+		Expect(notification.DeliveryStates()).To(Equal([]string{"delivered", "failed", "pending"}))
+	})
+
+	It("NewDelivery returns a pending delivery", func() {
+		dlvry := notification.NewDelivery(notification.ChannelSMS, "+15555550100")
+		Expect(dlvry.Channel).To(Equal(notification.ChannelSMS))
+		Expect(dlvry.Address).To(Equal("+15555550100"))
+		Expect(dlvry.IsPending()).To(BeTrue())
+		Expect(dlvry.Attempts).To(Equal(0))
+	})
+
+	Context("Validate", func() {
+		DescribeTable("validates the delivery",
+			func(mutator func(dlvry *notification.Delivery), expectedErrors ...error) {
+				dlvry := notification.NewDelivery(notification.ChannelEmail, "user@example.com")
+				mutator(dlvry)
+				errorsTest.ExpectEqual(structureValidator.New().Validate(dlvry), expectedErrors...)
+			},
+			Entry("succeeds",
+				func(dlvry *notification.Delivery) {},
+			),
+			Entry("channel invalid",
+				func(dlvry *notification.Delivery) { dlvry.Channel = "invalid" },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("invalid", notification.Channels()), "/channel"),
+			),
+			Entry("state invalid",
+				func(dlvry *notification.Delivery) { dlvry.State = "invalid" },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("invalid", notification.DeliveryStates()), "/state"),
+			),
+			Entry("delivered with delivered time",
+				func(dlvry *notification.Delivery) {
+					dlvry.State = notification.DeliveryStateDelivered
+					dlvry.DeliveredTime = pointer.FromTime(time.Now())
+				},
+			),
+			Entry("delivered without delivered time",
+				func(dlvry *notification.Delivery) { dlvry.State = notification.DeliveryStateDelivered },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueNotExists(), "/deliveredTime"),
+			),
+			Entry("pending with delivered time",
+				func(dlvry *notification.Delivery) { dlvry.DeliveredTime = pointer.FromTime(time.Now()) },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueExists(), "/deliveredTime"),
+			),
+		)
+	})
+
+	Context("Deliveries", func() {
+		It("Get returns the delivery for the channel", func() {
+			deliveries := notification.Deliveries{notification.NewDelivery(notification.ChannelEmail, "user@example.com"), notification.NewDelivery(notification.ChannelSMS, "+15555550100")}
+			Expect(deliveries.Get(notification.ChannelSMS)).To(BeIdenticalTo(deliveries[1]))
+			Expect(deliveries.Get(notification.ChannelPush)).To(BeNil())
+		})
+
+		It("Validate reports duplicate channels", func() {
+			deliveries := notification.Deliveries{notification.NewDelivery(notification.ChannelEmail, "user@example.com"), notification.NewDelivery(notification.ChannelEmail, "other@example.com")}
+			errorsTest.ExpectEqual(structureValidator.New().Validate(deliveries), errorsTest.WithPointerSource(structureValidator.ErrorValueDuplicate(), "/1/channel"))
+		})
+	})
+})
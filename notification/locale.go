@@ -0,0 +1,59 @@
+package notification
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/structure"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
+const (
+	LocaleEnglish = "en"
+	LocaleFrench  = "fr"
+	LocaleSpanish = "es"
+
+	LocaleDefault = LocaleEnglish
+)
+
+func Locales() []string {
+	return []string{
+		LocaleEnglish,
+		LocaleFrench,
+		LocaleSpanish,
+	}
+}
+
+// NormalizeLocale returns the locale in lowercase with a hyphen separating the language and region (eg. "fr-ca")
+func NormalizeLocale(locale string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(locale), "_", "-", -1))
+}
+
+// LocaleLanguage returns the language of the normalized locale (eg. "fr" for "fr-ca")
+func LocaleLanguage(locale string) string {
+	return strings.SplitN(NormalizeLocale(locale), "-", 2)[0]
+}
+
+func IsValidLocale(value string) bool {
+	return ValidateLocale(value) == nil
+}
+
+func LocaleValidator(value string, errorReporter structure.ErrorReporter) {
+	errorReporter.ReportError(ValidateLocale(value))
+}
+
+func ValidateLocale(value string) error {
+	if value == "" {
+		return structureValidator.ErrorValueEmpty()
+	} else if !localeExpression.MatchString(value) {
+		return ErrorValueStringAsLocaleNotValid(value)
+	}
+	return nil
+}
+
+func ErrorValueStringAsLocaleNotValid(value string) error {
+	return errors.Preparedf(structureValidator.ErrorCodeValueNotValid, "value is not valid", "value %q is not valid as locale", value)
+}
+
+var localeExpression = regexp.MustCompile("^[A-Za-z]{2,3}([-_][A-Za-z0-9]{2,8})*$")
@@ -0,0 +1,40 @@
+package notification_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/notification"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
+var _ = Describe("Locale", func() {
+	It("Locales returns expected", func() {
+		Expect(notification.Locales()).To(Equal([]string{"en", "fr", "es"}))
+	})
+
+	DescribeTable("NormalizeLocale",
+		func(locale string, expectedLocale string, expectedLanguage string) {
+			Expect(notification.NormalizeLocale(locale)).To(Equal(expectedLocale))
+			Expect(notification.LocaleLanguage(locale)).To(Equal(expectedLanguage))
+		},
+		Entry("is empty", "", "", ""),
+		Entry("is language", "FR", "fr", "fr"),
+		Entry("is language and region with hyphen", "fr-CA", "fr-ca", "fr"),
+		Entry("is language and region with underscore", "es_MX", "es-mx", "es"),
+	)
+
+	DescribeTable("ValidateLocale",
+		func(value string, expectedErrors ...error) {
+			errorsTest.ExpectEqual(notification.ValidateLocale(value), expectedErrors...)
+		},
+		Entry("is empty", "", structureValidator.ErrorValueEmpty()),
+		Entry("is language", "en"),
+		Entry("is language and region", "fr-CA"),
+		Entry("is language and region with underscore", "es_419"),
+		Entry("is too short", "e", notification.ErrorValueStringAsLocaleNotValid("e")),
+		Entry("has invalid characters", "en US", notification.ErrorValueStringAsLocaleNotValid("en US")),
+	)
+})
@@ -0,0 +1,280 @@
+package notification
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/id"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/structure"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+	"github.com/tidepool-org/platform/user"
+)
+
+const (
+	TypeDataSourceDisconnected = "data-source-disconnected"
+	TypeDataSourceError        = "data-source-error"
+	TypeGlucoseAlert           = "glucose-alert"
+	TypeMessage                = "message"
+	TypeShareInvitation        = "share-invitation"
+	TypeUserExport             = "user-export"
+
+	RetentionDurationDefault = 90 * 24 * time.Hour
+	RetentionDurationMaximum = 365 * 24 * time.Hour
+)
+
+func Types() []string {
+	return []string{
+		TypeDataSourceDisconnected,
+		TypeDataSourceError,
+		TypeGlucoseAlert,
+		TypeMessage,
+		TypeShareInvitation,
+		TypeUserExport,
+	}
+}
+
+type NotificationAccessor interface {
+	ListUserNotifications(ctx context.Context, userID string, filter *NotificationFilter, pagination *page.Pagination) (Notifications, error)
+	CreateUserNotification(ctx context.Context, userID string, create *NotificationCreate) (*Notification, error)
+	GetNotification(ctx context.Context, id string) (*Notification, error)
+	UpdateNotification(ctx context.Context, id string, update *NotificationUpdate) (*Notification, error)
+	DeleteUserNotifications(ctx context.Context, userID string) error
+}
+
+type NotificationFilter struct {
+	Type      *string `json:"type,omitempty"`
+	Read      *bool   `json:"read,omitempty"`
+	Dismissed *bool   `json:"dismissed,omitempty"`
+}
+
+func NewNotificationFilter() *NotificationFilter {
+	return &NotificationFilter{}
+}
+
+func (n *NotificationFilter) Parse(parser structure.ObjectParser) {
+	n.Type = parser.String("type")
+	n.Read = parser.Bool("read")
+	n.Dismissed = parser.Bool("dismissed")
+}
+
+func (n *NotificationFilter) Validate(validator structure.Validator) {
+	validator.String("type", n.Type).OneOf(Types()...)
+}
+
+func (n *NotificationFilter) MutateRequest(req *http.Request) error {
+	parameters := map[string]string{}
+	if n.Type != nil {
+		parameters["type"] = *n.Type
+	}
+	if n.Read != nil {
+		parameters["read"] = strconv.FormatBool(*n.Read)
+	}
+	if n.Dismissed != nil {
+		parameters["dismissed"] = strconv.FormatBool(*n.Dismissed)
+	}
+	return request.NewParametersMutator(parameters).MutateRequest(req)
+}
+
+// If the expiration time is not specified, then the notification is retained for the default retention duration
+type NotificationCreate struct {
+	Type           string                 `json:"type"`
+	Payload        map[string]interface{} `json:"payload,omitempty"`
+	ExpirationTime *time.Time             `json:"expirationTime,omitempty"`
+}
+
+func NewNotificationCreate() *NotificationCreate {
+	return &NotificationCreate{}
+}
+
+func (n *NotificationCreate) Parse(parser structure.ObjectParser) {
+	if ptr := parser.String("type"); ptr != nil {
+		n.Type = *ptr
+	}
+	if ptr := parser.Object("payload"); ptr != nil {
+		n.Payload = *ptr
+	}
+	n.ExpirationTime = parser.Time("expirationTime", time.RFC3339)
+}
+
+func (n *NotificationCreate) Validate(validator structure.Validator) {
+	validator.String("type", &n.Type).OneOf(Types()...)
+	validator.Time("expirationTime", n.ExpirationTime).AfterNow(time.Second).BeforeNow(RetentionDurationMaximum)
+}
+
+// Read and dismissed record the time of the change when true and clear it when false. Deliveries,
+// if specified, replace the existing deliveries in their entirety and may only be updated by services.
+type NotificationUpdate struct {
+	Read       *bool       `json:"read,omitempty"`
+	Dismissed  *bool       `json:"dismissed,omitempty"`
+	Deliveries *Deliveries `json:"deliveries,omitempty"`
+}
+
+func NewNotificationUpdate() *NotificationUpdate {
+	return &NotificationUpdate{}
+}
+
+func (n *NotificationUpdate) HasUpdates() bool {
+	return n.Read != nil || n.Dismissed != nil || n.Deliveries != nil
+}
+
+func (n *NotificationUpdate) Parse(parser structure.ObjectParser) {
+	n.Read = parser.Bool("read")
+	n.Dismissed = parser.Bool("dismissed")
+	n.Deliveries = parseDeliveries(parser)
+}
+
+func (n *NotificationUpdate) Validate(validator structure.Validator) {
+	if n.Deliveries != nil {
+		n.Deliveries.Validate(validator.WithReference("deliveries"))
+	}
+}
+
+type Notification struct {
+	ID             string                 `json:"id" bson:"id"`
+	UserID         string                 `json:"userId" bson:"userId"`
+	Type           string                 `json:"type" bson:"type"`
+	Payload        map[string]interface{} `json:"payload,omitempty" bson:"payload,omitempty"`
+	ReadTime       *time.Time             `json:"readTime,omitempty" bson:"readTime,omitempty"`
+	DismissedTime  *time.Time             `json:"dismissedTime,omitempty" bson:"dismissedTime,omitempty"`
+	Deliveries     Deliveries             `json:"deliveries,omitempty" bson:"deliveries,omitempty"`
+	ExpirationTime time.Time              `json:"expirationTime" bson:"expirationTime"`
+	CreatedTime    time.Time              `json:"createdTime" bson:"createdTime"`
+	ModifiedTime   *time.Time             `json:"modifiedTime,omitempty" bson:"modifiedTime,omitempty"`
+}
+
+func NewNotification(userID string, create *NotificationCreate) (*Notification, error) {
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+	if create == nil {
+		return nil, errors.New("create is missing")
+	} else if err := structureValidator.New().Validate(create); err != nil {
+		return nil, errors.Wrap(err, "create is invalid")
+	}
+
+	now := time.Now().Truncate(time.Second)
+
+	expirationTime := now.Add(RetentionDurationDefault)
+	if create.ExpirationTime != nil {
+		expirationTime = (*create.ExpirationTime).Truncate(time.Second)
+	}
+
+	return &Notification{
+		ID:             NewID(),
+		UserID:         userID,
+		Type:           create.Type,
+		Payload:        create.Payload,
+		ExpirationTime: expirationTime,
+		CreatedTime:    now,
+	}, nil
+}
+
+func (n *Notification) IsRead() bool {
+	return n.ReadTime != nil
+}
+
+func (n *Notification) IsDismissed() bool {
+	return n.DismissedTime != nil
+}
+
+func (n *Notification) Parse(parser structure.ObjectParser) {
+	if ptr := parser.String("id"); ptr != nil {
+		n.ID = *ptr
+	}
+	if ptr := parser.String("userId"); ptr != nil {
+		n.UserID = *ptr
+	}
+	if ptr := parser.String("type"); ptr != nil {
+		n.Type = *ptr
+	}
+	if ptr := parser.Object("payload"); ptr != nil {
+		n.Payload = *ptr
+	}
+	n.ReadTime = parser.Time("readTime", time.RFC3339)
+	n.DismissedTime = parser.Time("dismissedTime", time.RFC3339)
+	if ptr := parseDeliveries(parser); ptr != nil {
+		n.Deliveries = *ptr
+	}
+	if ptr := parser.Time("expirationTime", time.RFC3339); ptr != nil {
+		n.ExpirationTime = *ptr
+	}
+	if ptr := parser.Time("createdTime", time.RFC3339); ptr != nil {
+		n.CreatedTime = *ptr
+	}
+	n.ModifiedTime = parser.Time("modifiedTime", time.RFC3339)
+}
+
+func (n *Notification) Validate(validator structure.Validator) {
+	validator.String("id", &n.ID).Using(IDValidator)
+	validator.String("userId", &n.UserID).Using(user.IDValidator)
+	validator.String("type", &n.Type).OneOf(Types()...)
+	validator.Time("readTime", n.ReadTime).After(n.CreatedTime).BeforeNow(time.Second)
+	validator.Time("dismissedTime", n.DismissedTime).After(n.CreatedTime).BeforeNow(time.Second)
+	if n.Deliveries != nil {
+		n.Deliveries.Validate(validator.WithReference("deliveries"))
+	}
+	validator.Time("expirationTime", &n.ExpirationTime).After(n.CreatedTime)
+	validator.Time("createdTime", &n.CreatedTime).NotZero().BeforeNow(time.Second)
+	validator.Time("modifiedTime", n.ModifiedTime).After(n.CreatedTime).BeforeNow(time.Second)
+}
+
+func (n *Notification) Sanitize(details request.Details) error {
+	if details == nil {
+		return errors.New("unable to sanitize")
+	}
+	return nil
+}
+
+type Notifications []*Notification
+
+func (n Notifications) Sanitize(details request.Details) error {
+	for _, notification := range n {
+		if err := notification.Sanitize(details); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseDeliveries(parser structure.ObjectParser) *Deliveries {
+	if deliveriesParser := parser.WithReferenceArrayParser("deliveries"); deliveriesParser.Exists() {
+		deliveries := Deliveries{}
+		deliveries.Parse(deliveriesParser)
+		deliveriesParser.NotParsed()
+		return &deliveries
+	}
+	return nil
+}
+
+func NewID() string {
+	return id.Must(id.New(16))
+}
+
+func IsValidID(value string) bool {
+	return ValidateID(value) == nil
+}
+
+func IDValidator(value string, errorReporter structure.ErrorReporter) {
+	errorReporter.ReportError(ValidateID(value))
+}
+
+func ValidateID(value string) error {
+	if value == "" {
+		return structureValidator.ErrorValueEmpty()
+	} else if !idExpression.MatchString(value) {
+		return ErrorValueStringAsIDNotValid(value)
+	}
+	return nil
+}
+
+func ErrorValueStringAsIDNotValid(value string) error {
+	return errors.Preparedf(structureValidator.ErrorCodeValueNotValid, "value is not valid", "value %q is not valid as notification id", value)
+}
+
+var idExpression = regexp.MustCompile("^[0-9a-f]{32}$")
@@ -0,0 +1,157 @@
+package notification_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"net/http/httptest"
+	"time"
+
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/pointer"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+	"github.com/tidepool-org/platform/user"
+)
+
+var _ = Describe("Notification", func() {
+	It("Types returns expected", func() {
+		Expect(notification.Types()).To(Equal([]string{"data-source-disconnected", "data-source-error", "glucose-alert", "message", "share-invitation", "user-export"}))
+	})
+
+	Context("NotificationFilter", func() {
+		Context("Validate", func() {
+			DescribeTable("validates the notification filter",
+				func(mutator func(filter *notification.NotificationFilter), expectedErrors ...error) {
+					filter := notification.NewNotificationFilter()
+					mutator(filter)
+					errorsTest.ExpectEqual(structureValidator.New().Validate(filter), expectedErrors...)
+				},
+				Entry("succeeds",
+					func(filter *notification.NotificationFilter) {},
+				),
+				Entry("type valid",
+					func(filter *notification.NotificationFilter) {
+						filter.Type = pointer.FromString(notification.TypeMessage)
+					},
+				),
+				Entry("type invalid",
+					func(filter *notification.NotificationFilter) { filter.Type = pointer.FromString("invalid") },
+					errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("invalid", notification.Types()), "/type"),
+				),
+			)
+		})
+
+		Context("MutateRequest", func() {
+			It("adds the query parameters", func() {
+				filter := notification.NewNotificationFilter()
+				filter.Type = pointer.FromString(notification.TypeShareInvitation)
+				filter.Read = pointer.FromBool(false)
+				filter.Dismissed = pointer.FromBool(true)
+				req := httptest.NewRequest("GET", "http://localhost/v1/users/1234567890/notifications", nil)
+				Expect(filter.MutateRequest(req)).To(Succeed())
+				Expect(req.URL.Query().Get("type")).To(Equal("share-invitation"))
+				Expect(req.URL.Query().Get("read")).To(Equal("false"))
+				Expect(req.URL.Query().Get("dismissed")).To(Equal("true"))
+			})
+		})
+	})
+
+	Context("NotificationCreate", func() {
+		Context("Validate", func() {
+			DescribeTable("validates the notification create",
+				func(mutator func(create *notification.NotificationCreate), expectedErrors ...error) {
+					create := notification.NewNotificationCreate()
+					create.Type = notification.TypeMessage
+					mutator(create)
+					errorsTest.ExpectEqual(structureValidator.New().Validate(create), expectedErrors...)
+				},
+				Entry("succeeds",
+					func(create *notification.NotificationCreate) {},
+				),
+				Entry("type invalid",
+					func(create *notification.NotificationCreate) { create.Type = "invalid" },
+					errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("invalid", notification.Types()), "/type"),
+				),
+				Entry("payload valid",
+					func(create *notification.NotificationCreate) {
+						create.Payload = map[string]interface{}{"message": "hello"}
+					},
+				),
+				Entry("expiration time valid",
+					func(create *notification.NotificationCreate) {
+						create.ExpirationTime = pointer.FromTime(time.Now().Add(time.Hour))
+					},
+				),
+				Entry("expiration time not after now",
+					func(create *notification.NotificationCreate) {
+						create.ExpirationTime = pointer.FromTime(time.Unix(1500000000, 0))
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueTimeNotAfterNow(time.Unix(1500000000, 0)), "/expirationTime"),
+				),
+			)
+		})
+	})
+
+	Context("NewNotification", func() {
+		var userID string
+		var create *notification.NotificationCreate
+
+		BeforeEach(func() {
+			userID = user.NewID()
+			create = notification.NewNotificationCreate()
+			create.Type = notification.TypeDataSourceError
+			create.Payload = map[string]interface{}{"providerName": "dexcom"}
+		})
+
+		It("returns an error if the user id is missing", func() {
+			ntfctn, err := notification.NewNotification("", create)
+			Expect(err).To(MatchError("user id is missing"))
+			Expect(ntfctn).To(BeNil())
+		})
+
+		It("returns an error if the create is missing", func() {
+			ntfctn, err := notification.NewNotification(userID, nil)
+			Expect(err).To(MatchError("create is missing"))
+			Expect(ntfctn).To(BeNil())
+		})
+
+		It("returns successfully with default retention", func() {
+			ntfctn, err := notification.NewNotification(userID, create)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ntfctn).ToNot(BeNil())
+			Expect(notification.IsValidID(ntfctn.ID)).To(BeTrue())
+			Expect(ntfctn.UserID).To(Equal(userID))
+			Expect(ntfctn.Type).To(Equal(notification.TypeDataSourceError))
+			Expect(ntfctn.Payload).To(Equal(create.Payload))
+			Expect(ntfctn.IsRead()).To(BeFalse())
+			Expect(ntfctn.IsDismissed()).To(BeFalse())
+			Expect(ntfctn.ExpirationTime).To(Equal(ntfctn.CreatedTime.Add(notification.RetentionDurationDefault)))
+			Expect(structureValidator.New().Validate(ntfctn)).To(Succeed())
+		})
+
+		It("returns successfully with specified expiration time", func() {
+			expirationTime := time.Now().Add(time.Hour).Truncate(time.Second)
+			create.ExpirationTime = pointer.FromTime(expirationTime)
+			ntfctn, err := notification.NewNotification(userID, create)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ntfctn.ExpirationTime).To(Equal(expirationTime))
+		})
+	})
+
+	Context("ID", func() {
+		It("NewID returns a valid id", func() {
+			Expect(notification.IsValidID(notification.NewID())).To(BeTrue())
+		})
+
+		DescribeTable("ValidateID",
+			func(value string, expectedErrors ...error) {
+				errorsTest.ExpectEqual(notification.ValidateID(value), expectedErrors...)
+			},
+			Entry("is empty", "", structureValidator.ErrorValueEmpty()),
+			Entry("is valid", notification.NewID()),
+			Entry("has invalid characters", "0123456789ABCDEF0123456789abcdef", notification.ErrorValueStringAsIDNotValid("0123456789ABCDEF0123456789abcdef")),
+		)
+	})
+})
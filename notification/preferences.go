@@ -0,0 +1,233 @@
+package notification
+
+import (
+	"context"
+	"net/mail"
+	"regexp"
+	"time"
+
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/structure"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+	"github.com/tidepool-org/platform/user"
+)
+
+const (
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+	ChannelSMS   = "sms"
+
+	ChannelAddressLengthMaximum = 256
+)
+
+func Channels() []string {
+	return []string{
+		ChannelEmail,
+		ChannelPush,
+		ChannelSMS,
+	}
+}
+
+type PreferencesAccessor interface {
+	GetUserPreferences(ctx context.Context, userID string) (*Preferences, error)
+	UpdateUserPreferences(ctx context.Context, userID string, update *PreferencesUpdate) (*Preferences, error)
+	DeleteUserPreferences(ctx context.Context, userID string) error
+}
+
+// If types is not specified, then all notification types are delivered over the channel
+type ChannelPreference struct {
+	Enabled bool      `json:"enabled" bson:"enabled"`
+	Address *string   `json:"address,omitempty" bson:"address,omitempty"`
+	Types   *[]string `json:"types,omitempty" bson:"types,omitempty"`
+}
+
+func NewChannelPreference() *ChannelPreference {
+	return &ChannelPreference{}
+}
+
+func (c *ChannelPreference) AllowsType(typ string) bool {
+	if !c.Enabled || c.Address == nil {
+		return false
+	}
+	if c.Types == nil {
+		return true
+	}
+	for _, allowedType := range *c.Types {
+		if allowedType == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *ChannelPreference) Parse(parser structure.ObjectParser) {
+	if ptr := parser.Bool("enabled"); ptr != nil {
+		c.Enabled = *ptr
+	}
+	c.Address = parser.String("address")
+	c.Types = parser.StringArray("types")
+}
+
+func (c *ChannelPreference) Validate(validator structure.Validator) {
+	if c.Enabled {
+		validator.String("address", c.Address).Exists().NotEmpty().LengthLessThanOrEqualTo(ChannelAddressLengthMaximum)
+	} else {
+		validator.String("address", c.Address).NotEmpty().LengthLessThanOrEqualTo(ChannelAddressLengthMaximum)
+	}
+	validator.StringArray("types", c.Types).EachOneOf(Types()...).EachUnique()
+}
+
+// Each channel preference, if specified, replaces the existing channel preference in its entirety
+type PreferencesUpdate struct {
+	Locale *string            `json:"locale,omitempty"`
+	Email  *ChannelPreference `json:"email,omitempty"`
+	Push   *ChannelPreference `json:"push,omitempty"`
+	SMS    *ChannelPreference `json:"sms,omitempty"`
+}
+
+func NewPreferencesUpdate() *PreferencesUpdate {
+	return &PreferencesUpdate{}
+}
+
+func (p *PreferencesUpdate) HasUpdates() bool {
+	return p.Locale != nil || p.Email != nil || p.Push != nil || p.SMS != nil
+}
+
+func (p *PreferencesUpdate) Parse(parser structure.ObjectParser) {
+	p.Locale = parser.String("locale")
+	p.Email = parseChannelPreference(parser, ChannelEmail)
+	p.Push = parseChannelPreference(parser, ChannelPush)
+	p.SMS = parseChannelPreference(parser, ChannelSMS)
+}
+
+func (p *PreferencesUpdate) Validate(validator structure.Validator) {
+	validator.String("locale", p.Locale).Using(LocaleValidator)
+	validateChannelPreference(validator, ChannelEmail, p.Email)
+	validateChannelPreference(validator, ChannelPush, p.Push)
+	validateChannelPreference(validator, ChannelSMS, p.SMS)
+}
+
+// If locale is not specified, then notification content uses the default locale
+type Preferences struct {
+	UserID       string             `json:"userId" bson:"userId"`
+	Locale       *string            `json:"locale,omitempty" bson:"locale,omitempty"`
+	Email        *ChannelPreference `json:"email,omitempty" bson:"email,omitempty"`
+	Push         *ChannelPreference `json:"push,omitempty" bson:"push,omitempty"`
+	SMS          *ChannelPreference `json:"sms,omitempty" bson:"sms,omitempty"`
+	CreatedTime  time.Time          `json:"createdTime" bson:"createdTime"`
+	ModifiedTime *time.Time         `json:"modifiedTime,omitempty" bson:"modifiedTime,omitempty"`
+}
+
+func (p *Preferences) LocaleOrDefault() string {
+	if p.Locale != nil {
+		return *p.Locale
+	}
+	return LocaleDefault
+}
+
+func (p *Preferences) Channel(channel string) *ChannelPreference {
+	switch channel {
+	case ChannelEmail:
+		return p.Email
+	case ChannelPush:
+		return p.Push
+	case ChannelSMS:
+		return p.SMS
+	}
+	return nil
+}
+
+func (p *Preferences) Parse(parser structure.ObjectParser) {
+	if ptr := parser.String("userId"); ptr != nil {
+		p.UserID = *ptr
+	}
+	p.Locale = parser.String("locale")
+	p.Email = parseChannelPreference(parser, ChannelEmail)
+	p.Push = parseChannelPreference(parser, ChannelPush)
+	p.SMS = parseChannelPreference(parser, ChannelSMS)
+	if ptr := parser.Time("createdTime", time.RFC3339); ptr != nil {
+		p.CreatedTime = *ptr
+	}
+	p.ModifiedTime = parser.Time("modifiedTime", time.RFC3339)
+}
+
+func (p *Preferences) Validate(validator structure.Validator) {
+	validator.String("userId", &p.UserID).Using(user.IDValidator)
+	validator.String("locale", p.Locale).Using(LocaleValidator)
+	validateChannelPreference(validator, ChannelEmail, p.Email)
+	validateChannelPreference(validator, ChannelPush, p.Push)
+	validateChannelPreference(validator, ChannelSMS, p.SMS)
+	validator.Time("createdTime", &p.CreatedTime).NotZero().BeforeNow(time.Second)
+	validator.Time("modifiedTime", p.ModifiedTime).After(p.CreatedTime).BeforeNow(time.Second)
+}
+
+func (p *Preferences) Sanitize(details request.Details) error {
+	if details == nil {
+		return errors.New("unable to sanitize")
+	}
+	return nil
+}
+
+func parseChannelPreference(parser structure.ObjectParser, reference string) *ChannelPreference {
+	if channelPreferenceParser := parser.WithReferenceObjectParser(reference); channelPreferenceParser.Exists() {
+		channelPreference := NewChannelPreference()
+		channelPreference.Parse(channelPreferenceParser)
+		channelPreferenceParser.NotParsed()
+		return channelPreference
+	}
+	return nil
+}
+
+func validateChannelPreference(validator structure.Validator, reference string, channelPreference *ChannelPreference) {
+	if channelPreference != nil {
+		channelPreferenceValidator := validator.WithReference(reference)
+		channelPreference.Validate(channelPreferenceValidator)
+		if reference == ChannelEmail && channelPreference.Address != nil && *channelPreference.Address != "" {
+			channelPreferenceValidator.String("address", channelPreference.Address).Using(EmailAddressValidator)
+		} else if reference == ChannelSMS && channelPreference.Address != nil && *channelPreference.Address != "" {
+			channelPreferenceValidator.String("address", channelPreference.Address).Using(PhoneNumberValidator)
+		}
+	}
+}
+
+func EmailAddressValidator(value string, errorReporter structure.ErrorReporter) {
+	errorReporter.ReportError(ValidateEmailAddress(value))
+}
+
+func ValidateEmailAddress(value string) error {
+	if value == "" {
+		return structureValidator.ErrorValueEmpty()
+	} else if address, err := mail.ParseAddress(value); err != nil || address.Address != value {
+		return ErrorValueStringAsEmailAddressNotValid(value)
+	}
+	return nil
+}
+
+func PhoneNumberValidator(value string, errorReporter structure.ErrorReporter) {
+	errorReporter.ReportError(ValidatePhoneNumber(value))
+}
+
+// ValidatePhoneNumber requires the E.164 format, for example +14155550100
+func ValidatePhoneNumber(value string) error {
+	if value == "" {
+		return structureValidator.ErrorValueEmpty()
+	} else if !phoneNumberExpression.MatchString(value) {
+		return ErrorValueStringAsPhoneNumberNotValid(value)
+	}
+	return nil
+}
+
+var phoneNumberExpression = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+func ErrorValueStringAsEmailAddressNotValid(value string) error {
+	return errors.Preparedf(structureValidator.ErrorCodeValueNotValid, "value is not valid", "value %q is not valid as email address", value)
+}
+
+func ErrorValueStringAsPhoneNumberNotValid(value string) error {
+	return errors.Preparedf(structureValidator.ErrorCodeValueNotValid, "value is not valid", "value %q is not valid as phone number", value)
+}
+
+func ErrorValueStringAsEmailAddressNotVerified(value string) error {
+	return errors.Preparedf(structureValidator.ErrorCodeValueNotValid, "value is not valid", "value %q is not verified as email address", value)
+}
@@ -0,0 +1,151 @@
+package notification_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/pointer"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
+var _ = Describe("Preferences", func() {
+	It("Channels returns expected", func() {
+		Expect(notification.Channels()).To(Equal([]string{"email", "push", "sms"}))
+	})
+
+	Context("ChannelPreference", func() {
+		Context("AllowsType", func() {
+			It("returns false if not enabled", func() {
+				channelPreference := &notification.ChannelPreference{Enabled: false, Address: pointer.FromString("user@example.com")}
+				Expect(channelPreference.AllowsType(notification.TypeMessage)).To(BeFalse())
+			})
+
+			It("returns false if the address is missing", func() {
+				channelPreference := &notification.ChannelPreference{Enabled: true}
+				Expect(channelPreference.AllowsType(notification.TypeMessage)).To(BeFalse())
+			})
+
+			It("returns true for all types if types is missing", func() {
+				channelPreference := &notification.ChannelPreference{Enabled: true, Address: pointer.FromString("user@example.com")}
+				for _, typ := range notification.Types() {
+					Expect(channelPreference.AllowsType(typ)).To(BeTrue())
+				}
+			})
+
+			It("returns true only for the specified types", func() {
+				channelPreference := &notification.ChannelPreference{Enabled: true, Address: pointer.FromString("user@example.com"), Types: pointer.FromStringArray([]string{notification.TypeDataSourceError})}
+				Expect(channelPreference.AllowsType(notification.TypeDataSourceError)).To(BeTrue())
+				Expect(channelPreference.AllowsType(notification.TypeMessage)).To(BeFalse())
+			})
+		})
+
+		Context("Validate", func() {
+			DescribeTable("validates the channel preference",
+				func(mutator func(channelPreference *notification.ChannelPreference), expectedErrors ...error) {
+					channelPreference := &notification.ChannelPreference{Enabled: true, Address: pointer.FromString("user@example.com")}
+					mutator(channelPreference)
+					errorsTest.ExpectEqual(structureValidator.New().Validate(channelPreference), expectedErrors...)
+				},
+				Entry("succeeds",
+					func(channelPreference *notification.ChannelPreference) {},
+				),
+				Entry("enabled with address missing",
+					func(channelPreference *notification.ChannelPreference) { channelPreference.Address = nil },
+					errorsTest.WithPointerSource(structureValidator.ErrorValueNotExists(), "/address"),
+				),
+				Entry("disabled with address missing",
+					func(channelPreference *notification.ChannelPreference) {
+						channelPreference.Enabled = false
+						channelPreference.Address = nil
+					},
+				),
+				Entry("address empty",
+					func(channelPreference *notification.ChannelPreference) {
+						channelPreference.Address = pointer.FromString("")
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueEmpty(), "/address"),
+				),
+				Entry("types valid",
+					func(channelPreference *notification.ChannelPreference) {
+						channelPreference.Types = pointer.FromStringArray([]string{notification.TypeMessage, notification.TypeShareInvitation})
+					},
+				),
+				Entry("types invalid",
+					func(channelPreference *notification.ChannelPreference) {
+						channelPreference.Types = pointer.FromStringArray([]string{"invalid"})
+					},
+					errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("invalid", notification.Types()), "/types/0"),
+				),
+			)
+		})
+	})
+
+	Context("PreferencesUpdate", func() {
+		It("HasUpdates returns false if there are no updates", func() {
+			Expect(notification.NewPreferencesUpdate().HasUpdates()).To(BeFalse())
+		})
+
+		It("HasUpdates returns true if there is a channel preference", func() {
+			update := notification.NewPreferencesUpdate()
+			update.SMS = &notification.ChannelPreference{}
+			Expect(update.HasUpdates()).To(BeTrue())
+		})
+
+		It("Validate reports errors with the channel reference", func() {
+			update := notification.NewPreferencesUpdate()
+			update.Push = &notification.ChannelPreference{Enabled: true}
+			errorsTest.ExpectEqual(structureValidator.New().Validate(update), errorsTest.WithPointerSource(structureValidator.ErrorValueNotExists(), "/push/address"))
+		})
+
+		It("Validate reports an error if the email address is invalid", func() {
+			update := notification.NewPreferencesUpdate()
+			update.Email = &notification.ChannelPreference{Enabled: true, Address: pointer.FromString("User <user@example.com>")}
+			errorsTest.ExpectEqual(structureValidator.New().Validate(update), errorsTest.WithPointerSource(notification.ErrorValueStringAsEmailAddressNotValid("User <user@example.com>"), "/email/address"))
+		})
+
+		It("Validate does not report an error if the email address is valid", func() {
+			update := notification.NewPreferencesUpdate()
+			update.Email = &notification.ChannelPreference{Enabled: true, Address: pointer.FromString("user@example.com")}
+			Expect(structureValidator.New().Validate(update)).To(Succeed())
+		})
+
+		It("Validate reports an error if the sms phone number is not in E.164 format", func() {
+			update := notification.NewPreferencesUpdate()
+			update.SMS = &notification.ChannelPreference{Enabled: true, Address: pointer.FromString("(415) 555-0100")}
+			errorsTest.ExpectEqual(structureValidator.New().Validate(update), errorsTest.WithPointerSource(notification.ErrorValueStringAsPhoneNumberNotValid("(415) 555-0100"), "/sms/address"))
+		})
+
+		It("Validate does not report an error if the sms phone number is in E.164 format", func() {
+			update := notification.NewPreferencesUpdate()
+			update.SMS = &notification.ChannelPreference{Enabled: true, Address: pointer.FromString("+14155550100")}
+			Expect(structureValidator.New().Validate(update)).To(Succeed())
+		})
+
+		It("Validate reports an error if the locale is invalid", func() {
+			update := notification.NewPreferencesUpdate()
+			update.Locale = pointer.FromString("invalid locale")
+			errorsTest.ExpectEqual(structureValidator.New().Validate(update), errorsTest.WithPointerSource(notification.ErrorValueStringAsLocaleNotValid("invalid locale"), "/locale"))
+		})
+	})
+
+	Context("Preferences", func() {
+		It("Channel returns the channel preference", func() {
+			preferences := &notification.Preferences{Email: &notification.ChannelPreference{}, Push: &notification.ChannelPreference{}, SMS: &notification.ChannelPreference{}}
+			Expect(preferences.Channel(notification.ChannelEmail)).To(BeIdenticalTo(preferences.Email))
+			Expect(preferences.Channel(notification.ChannelPush)).To(BeIdenticalTo(preferences.Push))
+			Expect(preferences.Channel(notification.ChannelSMS)).To(BeIdenticalTo(preferences.SMS))
+			Expect(preferences.Channel("invalid")).To(BeNil())
+		})
+
+		It("LocaleOrDefault returns the default locale if the locale is not specified", func() {
+			Expect((&notification.Preferences{}).LocaleOrDefault()).To(Equal(notification.LocaleDefault))
+		})
+
+		It("LocaleOrDefault returns the locale if specified", func() {
+			Expect((&notification.Preferences{Locale: pointer.FromString("fr-CA")}).LocaleOrDefault()).To(Equal("fr-CA"))
+		})
+	})
+})
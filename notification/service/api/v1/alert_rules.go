@@ -0,0 +1,215 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"github.com/tidepool-org/platform/alert"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/service/api"
+	"github.com/tidepool-org/platform/user"
+)
+
+func (r *Router) AlertRulesRoutes() []*rest.Route {
+	return []*rest.Route{
+		rest.Get("/v1/alert_rules", api.RequireServer(r.ListRules)),
+		rest.Get("/v1/users/:userId/alert_rules", api.Require(r.ListUserRules)),
+		rest.Post("/v1/users/:userId/alert_rules", api.Require(r.CreateUserRule)),
+		rest.Get("/v1/alert_rules/:id", api.Require(r.GetRule)),
+		rest.Put("/v1/alert_rules/:id", api.Require(r.UpdateRule)),
+		rest.Delete("/v1/alert_rules/:id", api.Require(r.DeleteRule)),
+	}
+}
+
+func (r *Router) ListRules(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	filter := alert.NewRuleFilter()
+	pagination := page.NewPagination()
+	if err := request.DecodeRequestQuery(req.Request, filter, pagination); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	rules, err := r.NotificationClient().ListRules(req.Context(), filter, pagination)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Data(http.StatusOK, rules)
+}
+
+// Users, other than services, only list the rules they own
+func (r *Router) ListUserRules(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	userID, requestUserID, ok := r.authorizedRuleUserID(responder, req)
+	if !ok {
+		return
+	}
+
+	filter := alert.NewRuleFilter()
+	pagination := page.NewPagination()
+	if err := request.DecodeRequestQuery(req.Request, filter, pagination); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	filter.UserID = &userID
+	if requestUserID != "" {
+		filter.OwnerID = &requestUserID
+	}
+
+	rules, err := r.NotificationClient().ListRules(req.Context(), filter, pagination)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Data(http.StatusOK, rules)
+}
+
+// Users, other than services, may only create rules they own, either for their own data or for the data of a
+// user who has granted them view permission
+func (r *Router) CreateUserRule(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	userID, requestUserID, ok := r.authorizedRuleUserID(responder, req)
+	if !ok {
+		return
+	}
+
+	create := alert.NewRuleCreate()
+	if err := request.DecodeRequestBody(req.Request, create); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	if requestUserID != "" {
+		if create.OwnerID != nil && *create.OwnerID != requestUserID {
+			responder.Error(http.StatusForbidden, request.ErrorUnauthorized())
+			return
+		}
+		create.OwnerID = &requestUserID
+	}
+
+	rule, err := r.NotificationClient().CreateUserRule(req.Context(), userID, create)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Data(http.StatusCreated, rule)
+}
+
+func (r *Router) GetRule(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	rule := r.authorizedRule(responder, req)
+	if rule == nil {
+		return
+	}
+
+	responder.Data(http.StatusOK, rule)
+}
+
+// Only services may update the evaluation
+func (r *Router) UpdateRule(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+	details := request.DetailsFromContext(req.Context())
+
+	update := alert.NewRuleUpdate()
+	if err := request.DecodeRequestBody(req.Request, update); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	if update.Evaluation != nil && !details.IsService() {
+		responder.Error(http.StatusForbidden, request.ErrorUnauthorized())
+		return
+	}
+
+	rule := r.authorizedRule(responder, req)
+	if rule == nil {
+		return
+	}
+
+	rule, err := r.NotificationClient().UpdateRule(req.Context(), rule.ID, update)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	} else if rule == nil {
+		responder.Error(http.StatusNotFound, request.ErrorResourceNotFoundWithID(req.PathParam("id")))
+		return
+	}
+
+	responder.Data(http.StatusOK, rule)
+}
+
+func (r *Router) DeleteRule(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	rule := r.authorizedRule(responder, req)
+	if rule == nil {
+		return
+	}
+
+	if err := r.NotificationClient().DeleteRule(req.Context(), rule.ID); err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Empty(http.StatusNoContent)
+}
+
+// Returns the user id and the request user id if the requester is a service (empty request user id), the user, or
+// a user with view permission for the user; otherwise responds with an error
+func (r *Router) authorizedRuleUserID(responder *request.Responder, req *rest.Request) (string, string, bool) {
+	userID := req.PathParam("userId")
+	if userID == "" {
+		responder.Error(http.StatusBadRequest, request.ErrorParameterMissing("userId"))
+		return "", "", false
+	}
+
+	requestUserID, err := r.UserClient().EnsureAuthorizedUser(req.Context(), userID, user.ViewPermission)
+	if err != nil {
+		if request.IsErrorUnauthorized(err) {
+			responder.Error(http.StatusForbidden, err)
+		} else {
+			responder.Error(http.StatusInternalServerError, err)
+		}
+		return "", "", false
+	}
+
+	return userID, requestUserID, true
+}
+
+// Returns the rule if it exists and the requester is a service or the owner; otherwise responds with an error
+func (r *Router) authorizedRule(responder *request.Responder, req *rest.Request) *alert.Rule {
+	details := request.DetailsFromContext(req.Context())
+
+	id := req.PathParam("id")
+	if id == "" {
+		responder.Error(http.StatusBadRequest, request.ErrorParameterMissing("id"))
+		return nil
+	}
+
+	rule, err := r.NotificationClient().GetRule(req.Context(), id)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return nil
+	} else if rule == nil {
+		responder.Error(http.StatusNotFound, request.ErrorResourceNotFoundWithID(id))
+		return nil
+	}
+
+	if !details.IsService() && details.UserID() != rule.OwnerID {
+		responder.Error(http.StatusNotFound, request.ErrorResourceNotFoundWithID(id))
+		return nil
+	}
+
+	return rule
+}
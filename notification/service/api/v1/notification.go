@@ -0,0 +1,174 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/service/api"
+)
+
+func (r *Router) NotificationsRoutes() []*rest.Route {
+	return []*rest.Route{
+		rest.Get("/v1/users/:userId/notifications", api.Require(r.ListUserNotifications)),
+		rest.Post("/v1/users/:userId/notifications", api.RequireServer(r.CreateUserNotification)),
+		rest.Delete("/v1/users/:userId/notifications", api.RequireServer(r.DeleteUserNotifications)),
+		rest.Get("/v1/notifications/:id", api.Require(r.GetNotification)),
+		rest.Put("/v1/notifications/:id", api.Require(r.UpdateNotification)),
+		rest.Delete("/v1/notifications/:id", api.Require(r.DismissNotification)),
+	}
+}
+
+func (r *Router) ListUserNotifications(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	userID := r.authorizedUserID(responder, req)
+	if userID == "" {
+		return
+	}
+
+	filter := notification.NewNotificationFilter()
+	pagination := page.NewPagination()
+	if err := request.DecodeRequestQuery(req.Request, filter, pagination); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	notifications, err := r.NotificationClient().ListUserNotifications(req.Context(), userID, filter, pagination)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Data(http.StatusOK, notifications)
+}
+
+func (r *Router) CreateUserNotification(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	userID := req.PathParam("userId")
+	if userID == "" {
+		responder.Error(http.StatusBadRequest, request.ErrorParameterMissing("userId"))
+		return
+	}
+
+	create := notification.NewNotificationCreate()
+	if err := request.DecodeRequestBody(req.Request, create); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	ntfctn, err := r.NotificationClient().CreateUserNotification(req.Context(), userID, create)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Data(http.StatusCreated, ntfctn)
+}
+
+func (r *Router) DeleteUserNotifications(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	userID := req.PathParam("userId")
+	if userID == "" {
+		responder.Error(http.StatusBadRequest, request.ErrorParameterMissing("userId"))
+		return
+	}
+
+	if err := r.NotificationClient().DeleteUserNotifications(req.Context(), userID); err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Empty(http.StatusNoContent)
+}
+
+func (r *Router) GetNotification(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	ntfctn := r.authorizedNotification(responder, req)
+	if ntfctn == nil {
+		return
+	}
+
+	responder.Data(http.StatusOK, ntfctn)
+}
+
+// Only services may update deliveries
+func (r *Router) UpdateNotification(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+	details := request.DetailsFromContext(req.Context())
+
+	update := notification.NewNotificationUpdate()
+	if err := request.DecodeRequestBody(req.Request, update); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	if update.Deliveries != nil && !details.IsService() {
+		responder.Error(http.StatusForbidden, request.ErrorUnauthorized())
+		return
+	}
+
+	r.updateNotification(responder, req, update)
+}
+
+// Dismissed notifications are retained until they expire
+func (r *Router) DismissNotification(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	update := notification.NewNotificationUpdate()
+	update.Dismissed = pointer.FromBool(true)
+
+	r.updateNotification(responder, req, update)
+}
+
+func (r *Router) updateNotification(responder *request.Responder, req *rest.Request, update *notification.NotificationUpdate) {
+	ntfctn := r.authorizedNotification(responder, req)
+	if ntfctn == nil {
+		return
+	}
+
+	ntfctn, err := r.NotificationClient().UpdateNotification(req.Context(), ntfctn.ID, update)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	} else if ntfctn == nil {
+		responder.Error(http.StatusNotFound, request.ErrorResourceNotFoundWithID(req.PathParam("id")))
+		return
+	}
+
+	responder.Data(http.StatusOK, ntfctn)
+}
+
+// Returns the notification if it exists and the requester is a service or the owning user; otherwise responds with an error
+func (r *Router) authorizedNotification(responder *request.Responder, req *rest.Request) *notification.Notification {
+	details := request.DetailsFromContext(req.Context())
+
+	id := req.PathParam("id")
+	if id == "" {
+		responder.Error(http.StatusBadRequest, request.ErrorParameterMissing("id"))
+		return nil
+	}
+
+	ntfctn, err := r.NotificationClient().GetNotification(req.Context(), id)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return nil
+	} else if ntfctn == nil {
+		responder.Error(http.StatusNotFound, request.ErrorResourceNotFoundWithID(id))
+		return nil
+	}
+
+	if !details.IsService() && details.UserID() != ntfctn.UserID {
+		responder.Error(http.StatusNotFound, request.ErrorResourceNotFoundWithID(id))
+		return nil
+	}
+
+	return ntfctn
+}
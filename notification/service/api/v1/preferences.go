@@ -0,0 +1,104 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/service/api"
+)
+
+func (r *Router) PreferencesRoutes() []*rest.Route {
+	return []*rest.Route{
+		rest.Get("/v1/users/:userId/notification_preferences", api.Require(r.GetUserPreferences)),
+		rest.Put("/v1/users/:userId/notification_preferences", api.Require(r.UpdateUserPreferences)),
+		rest.Delete("/v1/users/:userId/notification_preferences", api.RequireServer(r.DeleteUserPreferences)),
+	}
+}
+
+func (r *Router) GetUserPreferences(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	userID := r.authorizedUserID(responder, req)
+	if userID == "" {
+		return
+	}
+
+	preferences, err := r.NotificationClient().GetUserPreferences(req.Context(), userID)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	} else if preferences == nil {
+		responder.Error(http.StatusNotFound, request.ErrorResourceNotFoundWithID(userID))
+		return
+	}
+
+	responder.Data(http.StatusOK, preferences)
+}
+
+func (r *Router) UpdateUserPreferences(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	userID := r.authorizedUserID(responder, req)
+	if userID == "" {
+		return
+	}
+
+	update := notification.NewPreferencesUpdate()
+	if err := request.DecodeRequestBody(req.Request, update); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	if update.Email != nil && update.Email.Enabled && !r.verifiedEmailAddress(responder, req, userID, *update.Email.Address) {
+		return
+	}
+
+	preferences, err := r.NotificationClient().UpdateUserPreferences(req.Context(), userID, update)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Data(http.StatusOK, preferences)
+}
+
+func (r *Router) DeleteUserPreferences(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	userID := req.PathParam("userId")
+	if userID == "" {
+		responder.Error(http.StatusBadRequest, request.ErrorParameterMissing("userId"))
+		return
+	}
+
+	if err := r.NotificationClient().DeleteUserPreferences(req.Context(), userID); err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Empty(http.StatusNoContent)
+}
+
+// Returns true if the address is the verified email of the user; otherwise responds with an error. Notifications are
+// only delivered to the verified account email, as there is no verification of any other address.
+func (r *Router) verifiedEmailAddress(responder *request.Responder, req *rest.Request, userID string, address string) bool {
+	usr, err := r.UserClient().GetUser(req.Context(), userID)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return false
+	} else if usr == nil {
+		responder.Error(http.StatusNotFound, request.ErrorResourceNotFoundWithID(userID))
+		return false
+	}
+
+	if !usr.EmailVerified || !strings.EqualFold(usr.Email, address) {
+		responder.Error(http.StatusBadRequest, notification.ErrorValueStringAsEmailAddressNotVerified(address))
+		return false
+	}
+
+	return true
+}
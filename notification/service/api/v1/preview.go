@@ -0,0 +1,35 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"github.com/tidepool-org/platform/notification/template"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/service/api"
+)
+
+func (r *Router) PreviewRoutes() []*rest.Route {
+	return []*rest.Route{
+		rest.Post("/v1/notifications/preview", api.Require(r.PreviewNotification)),
+	}
+}
+
+func (r *Router) PreviewNotification(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	preview := template.NewPreview()
+	if err := request.DecodeRequestBody(req.Request, preview); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	content, err := r.TemplateRenderer().Render(preview.Type, preview.LocaleOrDefault(), preview.Payload)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Data(http.StatusOK, content)
+}
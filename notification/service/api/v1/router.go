@@ -1,10 +1,13 @@
 package v1
 
 import (
+	"net/http"
+
 	"github.com/ant0ine/go-json-rest/rest"
 
 	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/notification/service"
+	"github.com/tidepool-org/platform/request"
 )
 
 type Router struct {
@@ -22,24 +25,27 @@ func NewRouter(svc service.Service) (*Router, error) {
 }
 
 func (r *Router) Routes() []*rest.Route {
-	return []*rest.Route{}
+	routes := r.NotificationsRoutes()
+	routes = append(routes, r.PreferencesRoutes()...)
+	routes = append(routes, r.PreviewRoutes()...)
+	routes = append(routes, r.AlertRulesRoutes()...)
+	return routes
 }
 
-/*
-POST /v1/users/:userId/notifications
-	request includes info about type of notification
-	web:
-		message:
-		template?:
-	email?:
-	sms?:
-	push?:
+// Returns the user id if the requester is a service or the user; otherwise responds with an error
+func (r *Router) authorizedUserID(responder *request.Responder, req *rest.Request) string {
+	details := request.DetailsFromContext(req.Context())
 
-GET /v1/users/:userId/notifications
+	userID := req.PathParam("userId")
+	if userID == "" {
+		responder.Error(http.StatusBadRequest, request.ErrorParameterMissing("userId"))
+		return ""
+	}
 
-DELETE /v1/notifications/:notificationid
+	if !details.IsService() && details.UserID() != userID {
+		responder.Error(http.StatusForbidden, request.ErrorUnauthorized())
+		return ""
+	}
 
-How to mark as dismissed?
-Do we want to retain after dismissed for tracking purposes?
-Or add to audit log?
-*/
+	return userID
+}
@@ -41,7 +41,7 @@ var _ = Describe("Router", func() {
 
 		Context("Routes", func() {
 			It("returns the expected routes", func() {
-				Expect(rtr.Routes()).To(BeEmpty())
+				Expect(rtr.Routes()).ToNot(BeEmpty())
 			})
 		})
 	})
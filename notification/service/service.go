@@ -1,14 +1,20 @@
 package service
 
 import (
+	"github.com/tidepool-org/platform/notification"
 	"github.com/tidepool-org/platform/notification/store"
+	"github.com/tidepool-org/platform/notification/template"
 	"github.com/tidepool-org/platform/service"
+	"github.com/tidepool-org/platform/user"
 )
 
 type Service interface {
 	service.Service
 
 	NotificationStore() store.Store
+	NotificationClient() notification.Client
+	TemplateRenderer() *template.Renderer
+	UserClient() user.Client
 
 	Status() *Status
 }
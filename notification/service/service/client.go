@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+
+	"github.com/tidepool-org/platform/alert"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/notification"
+	notificationDelivery "github.com/tidepool-org/platform/notification/delivery"
+	notificationStore "github.com/tidepool-org/platform/notification/store"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+)
+
+type Client struct {
+	notificationStore notificationStore.Store
+	taskClient        task.Client
+}
+
+func NewClient(str notificationStore.Store, taskClient task.Client) (*Client, error) {
+	if str == nil {
+		return nil, errors.New("notification store is missing")
+	}
+	if taskClient == nil {
+		return nil, errors.New("task client is missing")
+	}
+
+	return &Client{
+		notificationStore: str,
+		taskClient:        taskClient,
+	}, nil
+}
+
+func (c *Client) ListUserNotifications(ctx context.Context, userID string, filter *notification.NotificationFilter, pagination *page.Pagination) (notification.Notifications, error) {
+	ssn := c.notificationStore.NewNotificationsSession()
+	defer ssn.Close()
+
+	return ssn.ListUserNotifications(ctx, userID, filter, pagination)
+}
+
+const DeliveryTaskAttemptsMaximum = 3
+
+// A delivery task is created if any channel preferred by the user accepts the notification type. Once the
+// notification is created it is always returned, as a caller retrying on error would create a duplicate
+// notification. Instead, only the creation of the delivery task is retried; the task name is derived from the
+// notification id, so a task created by an earlier attempt is never duplicated.
+func (c *Client) CreateUserNotification(ctx context.Context, userID string, create *notification.NotificationCreate) (*notification.Notification, error) {
+	ssn := c.notificationStore.NewNotificationsSession()
+	defer ssn.Close()
+
+	ntfctn, err := ssn.CreateUserNotification(ctx, userID, create)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.createDeliveryTask(ctx, ntfctn); err != nil {
+		log.LoggerFromContext(ctx).WithError(err).WithField("notificationId", ntfctn.ID).Error("Unable to create notification delivery task")
+	}
+
+	return ntfctn, nil
+}
+
+func (c *Client) GetNotification(ctx context.Context, id string) (*notification.Notification, error) {
+	ssn := c.notificationStore.NewNotificationsSession()
+	defer ssn.Close()
+
+	return ssn.GetNotification(ctx, id)
+}
+
+func (c *Client) UpdateNotification(ctx context.Context, id string, update *notification.NotificationUpdate) (*notification.Notification, error) {
+	ssn := c.notificationStore.NewNotificationsSession()
+	defer ssn.Close()
+
+	return ssn.UpdateNotification(ctx, id, update)
+}
+
+func (c *Client) DeleteUserNotifications(ctx context.Context, userID string) error {
+	ssn := c.notificationStore.NewNotificationsSession()
+	defer ssn.Close()
+
+	return ssn.DeleteUserNotifications(ctx, userID)
+}
+
+func (c *Client) GetUserPreferences(ctx context.Context, userID string) (*notification.Preferences, error) {
+	ssn := c.notificationStore.NewPreferencesSession()
+	defer ssn.Close()
+
+	return ssn.GetUserPreferences(ctx, userID)
+}
+
+func (c *Client) UpdateUserPreferences(ctx context.Context, userID string, update *notification.PreferencesUpdate) (*notification.Preferences, error) {
+	ssn := c.notificationStore.NewPreferencesSession()
+	defer ssn.Close()
+
+	return ssn.UpdateUserPreferences(ctx, userID, update)
+}
+
+func (c *Client) DeleteUserPreferences(ctx context.Context, userID string) error {
+	ssn := c.notificationStore.NewPreferencesSession()
+	defer ssn.Close()
+
+	return ssn.DeleteUserPreferences(ctx, userID)
+}
+
+func (c *Client) ListRules(ctx context.Context, filter *alert.RuleFilter, pagination *page.Pagination) (alert.Rules, error) {
+	ssn := c.notificationStore.NewAlertRulesSession()
+	defer ssn.Close()
+
+	return ssn.ListRules(ctx, filter, pagination)
+}
+
+func (c *Client) CreateUserRule(ctx context.Context, userID string, create *alert.RuleCreate) (*alert.Rule, error) {
+	ssn := c.notificationStore.NewAlertRulesSession()
+	defer ssn.Close()
+
+	return ssn.CreateUserRule(ctx, userID, create)
+}
+
+func (c *Client) GetRule(ctx context.Context, id string) (*alert.Rule, error) {
+	ssn := c.notificationStore.NewAlertRulesSession()
+	defer ssn.Close()
+
+	return ssn.GetRule(ctx, id)
+}
+
+func (c *Client) UpdateRule(ctx context.Context, id string, update *alert.RuleUpdate) (*alert.Rule, error) {
+	ssn := c.notificationStore.NewAlertRulesSession()
+	defer ssn.Close()
+
+	return ssn.UpdateRule(ctx, id, update)
+}
+
+func (c *Client) DeleteRule(ctx context.Context, id string) error {
+	ssn := c.notificationStore.NewAlertRulesSession()
+	defer ssn.Close()
+
+	return ssn.DeleteRule(ctx, id)
+}
+
+func (c *Client) createDeliveryTask(ctx context.Context, ntfctn *notification.Notification) error {
+	preferences, err := c.GetUserPreferences(ctx, ntfctn.UserID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get user preferences")
+	} else if preferences == nil {
+		return nil
+	}
+
+	deliverable := false
+	for _, channel := range notification.Channels() {
+		if channelPreference := preferences.Channel(channel); channelPreference != nil && channelPreference.AllowsType(ntfctn.Type) {
+			deliverable = true
+			break
+		}
+	}
+	if !deliverable {
+		return nil
+	}
+
+	taskCreate, err := notificationDelivery.NewTaskCreate(ntfctn.ID)
+	if err != nil {
+		return errors.Wrap(err, "unable to create task create")
+	}
+
+	for attempt := 1; ; attempt++ {
+		if _, err = c.taskClient.CreateTask(ctx, taskCreate); err == nil {
+			return nil
+		} else if exists, existsErr := c.deliveryTaskExists(ctx, ntfctn.ID); existsErr == nil && exists {
+			return nil
+		} else if attempt >= DeliveryTaskAttemptsMaximum {
+			return errors.Wrap(err, "unable to create task")
+		}
+	}
+}
+
+// A failed create may have inserted the task before returning an error
+func (c *Client) deliveryTaskExists(ctx context.Context, notificationID string) (bool, error) {
+	filter := task.NewTaskFilter()
+	filter.Name = pointer.FromString(notificationDelivery.TaskName(notificationID))
+
+	tsks, err := c.taskClient.ListTasks(ctx, filter, nil)
+	if err != nil {
+		return false, err
+	}
+	return len(tsks) > 0, nil
+}
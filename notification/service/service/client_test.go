@@ -0,0 +1,151 @@
+package service_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/log"
+	logNull "github.com/tidepool-org/platform/log/null"
+	"github.com/tidepool-org/platform/notification"
+	notificationDelivery "github.com/tidepool-org/platform/notification/delivery"
+	notificationServiceService "github.com/tidepool-org/platform/notification/service/service"
+	notificationStore "github.com/tidepool-org/platform/notification/store"
+	notificationStoreTest "github.com/tidepool-org/platform/notification/store/test"
+	notificationTest "github.com/tidepool-org/platform/notification/test"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+	taskTest "github.com/tidepool-org/platform/task/test"
+	"github.com/tidepool-org/platform/user"
+)
+
+var _ = Describe("Client", func() {
+	var str *notificationStoreTest.Store
+	var taskClient *taskTest.Client
+
+	BeforeEach(func() {
+		str = notificationStoreTest.NewStore()
+		taskClient = taskTest.NewClient()
+	})
+
+	AfterEach(func() {
+		Expect(str.UnusedOutputsCount()).To(Equal(0))
+		taskClient.Expectations()
+	})
+
+	Context("NewClient", func() {
+		It("returns an error if the notification store is missing", func() {
+			clnt, err := notificationServiceService.NewClient(nil, taskClient)
+			Expect(err).To(MatchError("notification store is missing"))
+			Expect(clnt).To(BeNil())
+		})
+
+		It("returns an error if the task client is missing", func() {
+			clnt, err := notificationServiceService.NewClient(str, nil)
+			Expect(err).To(MatchError("task client is missing"))
+			Expect(clnt).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			Expect(notificationServiceService.NewClient(str, taskClient)).ToNot(BeNil())
+		})
+	})
+
+	Context("CreateUserNotification", func() {
+		var ctx context.Context
+		var userID string
+		var create *notification.NotificationCreate
+		var ntfctn *notification.Notification
+		var notificationsSession *notificationStoreTest.NotificationsSession
+		var preferencesSession *notificationStoreTest.PreferencesSession
+		var clnt *notificationServiceService.Client
+
+		BeforeEach(func() {
+			var err error
+			ctx = log.NewContextWithLogger(context.Background(), logNull.NewLogger())
+			userID = user.NewID()
+			create = &notification.NotificationCreate{Type: notification.TypeMessage}
+			ntfctn, err = notification.NewNotification(userID, create)
+			Expect(err).ToNot(HaveOccurred())
+			notificationsSession = notificationStoreTest.NewNotificationsSession()
+			notificationsSession.CloseOutput = func(err error) *error { return &err }(nil)
+			str.NewNotificationsSessionOutputs = []notificationStore.NotificationsSession{notificationsSession}
+			preferencesSession = notificationStoreTest.NewPreferencesSession()
+			preferencesSession.CloseOutput = func(err error) *error { return &err }(nil)
+			clnt, err = notificationServiceService.NewClient(str, taskClient)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			notificationsSession.AssertOutputsEmpty()
+			preferencesSession.AssertOutputsEmpty()
+		})
+
+		It("returns an error if the notification cannot be created", func() {
+			notificationsSession.CreateUserNotificationOutputs = []notificationTest.CreateUserNotificationOutput{{Notification: nil, Error: errorsTest.NewError()}}
+			result, err := clnt.CreateUserNotification(ctx, userID, create)
+			Expect(err).To(HaveOccurred())
+			Expect(result).To(BeNil())
+		})
+
+		Context("with created notification", func() {
+			BeforeEach(func() {
+				notificationsSession.CreateUserNotificationOutputs = []notificationTest.CreateUserNotificationOutput{{Notification: ntfctn, Error: nil}}
+				str.NewPreferencesSessionOutputs = []notificationStore.PreferencesSession{preferencesSession}
+			})
+
+			It("returns the notification without a delivery task if no channel accepts the type", func() {
+				preferencesSession.GetUserPreferencesOutputs = []notificationTest.GetUserPreferencesOutput{{Preferences: &notification.Preferences{UserID: userID}, Error: nil}}
+				Expect(clnt.CreateUserNotification(ctx, userID, create)).To(Equal(ntfctn))
+				Expect(taskClient.CreateTaskInputs).To(BeEmpty())
+			})
+
+			It("returns the notification if the preferences cannot be retrieved", func() {
+				preferencesSession.GetUserPreferencesOutputs = []notificationTest.GetUserPreferencesOutput{{Preferences: nil, Error: errorsTest.NewError()}}
+				Expect(clnt.CreateUserNotification(ctx, userID, create)).To(Equal(ntfctn))
+				Expect(taskClient.CreateTaskInputs).To(BeEmpty())
+			})
+
+			Context("with a channel accepting the type", func() {
+				BeforeEach(func() {
+					preferencesSession.GetUserPreferencesOutputs = []notificationTest.GetUserPreferencesOutput{{Preferences: &notification.Preferences{
+						UserID: userID,
+						Email:  &notification.ChannelPreference{Enabled: true, Address: pointer.FromString("user@example.com")},
+					}, Error: nil}}
+				})
+
+				It("returns the notification with a delivery task", func() {
+					taskClient.CreateTaskOutputs = []taskTest.CreateTaskOutput{{Task: &task.Task{}, Error: nil}}
+					Expect(clnt.CreateUserNotification(ctx, userID, create)).To(Equal(ntfctn))
+					Expect(taskClient.CreateTaskInputs).To(HaveLen(1))
+					Expect(taskClient.CreateTaskInputs[0].Create.Name).To(Equal(pointer.FromString(notificationDelivery.TaskName(ntfctn.ID))))
+				})
+
+				It("retries only the delivery task creation", func() {
+					taskClient.CreateTaskOutputs = []taskTest.CreateTaskOutput{{Task: nil, Error: errorsTest.NewError()}, {Task: &task.Task{}, Error: nil}}
+					taskClient.ListTasksOutputs = []taskTest.ListTasksOutput{{Tasks: task.Tasks{}, Error: nil}}
+					Expect(clnt.CreateUserNotification(ctx, userID, create)).To(Equal(ntfctn))
+					Expect(taskClient.CreateTaskInputs).To(HaveLen(2))
+					Expect(notificationsSession.CreateUserNotificationInputs).To(HaveLen(1))
+				})
+
+				It("does not retry if the delivery task was created despite the error", func() {
+					taskClient.CreateTaskOutputs = []taskTest.CreateTaskOutput{{Task: nil, Error: errorsTest.NewError()}}
+					taskClient.ListTasksOutputs = []taskTest.ListTasksOutput{{Tasks: task.Tasks{&task.Task{}}, Error: nil}}
+					Expect(clnt.CreateUserNotification(ctx, userID, create)).To(Equal(ntfctn))
+					Expect(taskClient.CreateTaskInputs).To(HaveLen(1))
+					Expect(*taskClient.ListTasksInputs[0].Filter.Name).To(Equal(notificationDelivery.TaskName(ntfctn.ID)))
+				})
+
+				It("returns the notification without an error if the delivery task cannot be created", func() {
+					taskClient.CreateTaskOutputs = []taskTest.CreateTaskOutput{{Task: nil, Error: errorsTest.NewError()}, {Task: nil, Error: errorsTest.NewError()}, {Task: nil, Error: errorsTest.NewError()}}
+					taskClient.ListTasksOutputs = []taskTest.ListTasksOutput{{Tasks: task.Tasks{}, Error: nil}, {Tasks: task.Tasks{}, Error: nil}, {Tasks: task.Tasks{}, Error: nil}}
+					Expect(clnt.CreateUserNotification(ctx, userID, create)).To(Equal(ntfctn))
+					Expect(taskClient.CreateTaskInputs).To(HaveLen(notificationServiceService.DeliveryTaskAttemptsMaximum))
+				})
+			})
+		})
+	})
+})
@@ -5,18 +5,29 @@ import (
 
 	"github.com/tidepool-org/platform/application"
 	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/notification"
 	"github.com/tidepool-org/platform/notification/service"
 	"github.com/tidepool-org/platform/notification/service/api"
 	"github.com/tidepool-org/platform/notification/service/api/v1"
 	"github.com/tidepool-org/platform/notification/store"
 	notificationMongo "github.com/tidepool-org/platform/notification/store/mongo"
+	"github.com/tidepool-org/platform/notification/template"
+	"github.com/tidepool-org/platform/platform"
 	serviceService "github.com/tidepool-org/platform/service/service"
 	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
+	"github.com/tidepool-org/platform/task"
+	taskClient "github.com/tidepool-org/platform/task/client"
+	"github.com/tidepool-org/platform/user"
+	userClient "github.com/tidepool-org/platform/user/client"
 )
 
 type Service struct {
 	*serviceService.Authenticated
-	notificationStore *notificationMongo.Store
+	notificationStore  *notificationMongo.Store
+	taskClient         task.Client
+	userClient         *userClient.Client
+	notificationClient *Client
+	templateRenderer   *template.Renderer
 }
 
 func New() *Service {
@@ -30,15 +41,31 @@ func (s *Service) Initialize(provider application.Provider) error {
 		return err
 	}
 
-	if err := s.initializeRouter(); err != nil {
+	if err := s.initializeNotificationStore(); err != nil {
 		return err
 	}
-	return s.initializeNotificationStore()
+	if err := s.initializeTaskClient(); err != nil {
+		return err
+	}
+	if err := s.initializeUserClient(); err != nil {
+		return err
+	}
+	if err := s.initializeNotificationClient(); err != nil {
+		return err
+	}
+	if err := s.initializeTemplateRenderer(); err != nil {
+		return err
+	}
+	return s.initializeRouter()
 }
 
 func (s *Service) Terminate() {
-	s.terminateNotificationStore()
 	s.terminateRouter()
+	s.terminateTemplateRenderer()
+	s.terminateNotificationClient()
+	s.terminateUserClient()
+	s.terminateTaskClient()
+	s.terminateNotificationStore()
 
 	s.Authenticated.Terminate()
 }
@@ -47,6 +74,22 @@ func (s *Service) NotificationStore() store.Store {
 	return s.notificationStore
 }
 
+func (s *Service) TaskClient() task.Client {
+	return s.taskClient
+}
+
+func (s *Service) UserClient() user.Client {
+	return s.userClient
+}
+
+func (s *Service) NotificationClient() notification.Client {
+	return s.notificationClient
+}
+
+func (s *Service) TemplateRenderer() *template.Renderer {
+	return s.templateRenderer
+}
+
 func (s *Service) Status() *service.Status {
 	return &service.Status{
 		Version:           s.VersionReporter().Long(),
@@ -102,6 +145,13 @@ func (s *Service) initializeNotificationStore() error {
 	}
 	s.notificationStore = str
 
+	s.Logger().Debug("Ensuring notification store indexes")
+
+	err = s.notificationStore.EnsureIndexes()
+	if err != nil {
+		return errors.Wrap(err, "unable to ensure notification store indexes")
+	}
+
 	return nil
 }
 
@@ -114,3 +164,95 @@ func (s *Service) terminateNotificationStore() {
 		s.notificationStore = nil
 	}
 }
+
+func (s *Service) initializeTaskClient() error {
+	s.Logger().Debug("Loading task client config")
+
+	cfg := platform.NewConfig()
+	cfg.UserAgent = s.UserAgent()
+	if err := cfg.Load(s.ConfigReporter().WithScopes("task", "client")); err != nil {
+		return errors.Wrap(err, "unable to load task client config")
+	}
+
+	s.Logger().Debug("Creating task client")
+
+	clnt, err := taskClient.New(cfg, platform.AuthorizeAsService)
+	if err != nil {
+		return errors.Wrap(err, "unable to create task client")
+	}
+	s.taskClient = clnt
+
+	return nil
+}
+
+func (s *Service) terminateTaskClient() {
+	if s.taskClient != nil {
+		s.Logger().Debug("Destroying task client")
+		s.taskClient = nil
+	}
+}
+
+func (s *Service) initializeUserClient() error {
+	s.Logger().Debug("Loading user client config")
+
+	cfg := platform.NewConfig()
+	cfg.UserAgent = s.UserAgent()
+	if err := cfg.Load(s.ConfigReporter().WithScopes("user", "client")); err != nil {
+		return errors.Wrap(err, "unable to load user client config")
+	}
+
+	s.Logger().Debug("Creating user client")
+
+	clnt, err := userClient.New(cfg, platform.AuthorizeAsService)
+	if err != nil {
+		return errors.Wrap(err, "unable to create user client")
+	}
+	s.userClient = clnt
+
+	return nil
+}
+
+func (s *Service) terminateUserClient() {
+	if s.userClient != nil {
+		s.Logger().Debug("Destroying user client")
+		s.userClient = nil
+	}
+}
+
+func (s *Service) initializeNotificationClient() error {
+	s.Logger().Debug("Creating notification client")
+
+	clnt, err := NewClient(s.NotificationStore(), s.TaskClient())
+	if err != nil {
+		return errors.Wrap(err, "unable to create notification client")
+	}
+	s.notificationClient = clnt
+
+	return nil
+}
+
+func (s *Service) terminateNotificationClient() {
+	if s.notificationClient != nil {
+		s.Logger().Debug("Destroying notification client")
+		s.notificationClient = nil
+	}
+}
+
+func (s *Service) initializeTemplateRenderer() error {
+	s.Logger().Debug("Creating template renderer")
+
+	renderer, err := template.NewDefaultRenderer()
+	if err != nil {
+		return errors.Wrap(err, "unable to create template renderer")
+	}
+	s.templateRenderer = renderer
+
+	return nil
+}
+
+func (s *Service) terminateTemplateRenderer() {
+	if s.templateRenderer != nil {
+		s.Logger().Debug("Destroying template renderer")
+		s.templateRenderer = nil
+	}
+}
@@ -1,24 +1,34 @@
 package test
 
 import (
+	"github.com/tidepool-org/platform/notification"
 	"github.com/tidepool-org/platform/notification/service"
 	"github.com/tidepool-org/platform/notification/store"
 	testStore "github.com/tidepool-org/platform/notification/store/test"
+	"github.com/tidepool-org/platform/notification/template"
+	notificationTest "github.com/tidepool-org/platform/notification/test"
 	testService "github.com/tidepool-org/platform/service/test"
+	"github.com/tidepool-org/platform/user"
+	userTest "github.com/tidepool-org/platform/user/test"
 )
 
 type Service struct {
 	*testService.Service
 	NotificationStoreInvocations int
 	NotificationStoreImpl        *testStore.Store
+	NotificationClientImpl       *notificationTest.Client
+	TemplateRendererImpl         *template.Renderer
+	UserClientImpl               *userTest.Client
 	StatusInvocations            int
 	StatusOutputs                []*service.Status
 }
 
 func NewService() *Service {
 	return &Service{
-		Service:               testService.NewService(),
-		NotificationStoreImpl: testStore.NewStore(),
+		Service:                testService.NewService(),
+		NotificationStoreImpl:  testStore.NewStore(),
+		NotificationClientImpl: notificationTest.NewClient(),
+		UserClientImpl:         userTest.NewClient(),
 	}
 }
 
@@ -28,6 +38,18 @@ func (s *Service) NotificationStore() store.Store {
 	return s.NotificationStoreImpl
 }
 
+func (s *Service) NotificationClient() notification.Client {
+	return s.NotificationClientImpl
+}
+
+func (s *Service) TemplateRenderer() *template.Renderer {
+	return s.TemplateRendererImpl
+}
+
+func (s *Service) UserClient() user.Client {
+	return s.UserClientImpl
+}
+
 func (s *Service) Status() *service.Status {
 	s.StatusInvocations++
 
@@ -1,9 +1,20 @@
 package mongo
 
 import (
+	"context"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/tidepool-org/platform/alert"
+	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/notification"
 	"github.com/tidepool-org/platform/notification/store"
+	"github.com/tidepool-org/platform/page"
 	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
 )
 
 type Store struct {
@@ -21,12 +32,549 @@ func NewStore(cfg *storeStructuredMongo.Config, lgr log.Logger) (*Store, error)
 	}, nil
 }
 
+func (s *Store) EnsureIndexes() error {
+	notificationsSsn := s.notificationsSession()
+	defer notificationsSsn.Close()
+	if err := notificationsSsn.EnsureIndexes(); err != nil {
+		return err
+	}
+
+	preferencesSsn := s.preferencesSession()
+	defer preferencesSsn.Close()
+	if err := preferencesSsn.EnsureIndexes(); err != nil {
+		return err
+	}
+
+	alertRulesSsn := s.alertRulesSession()
+	defer alertRulesSsn.Close()
+	return alertRulesSsn.EnsureIndexes()
+}
+
 func (s *Store) NewNotificationsSession() store.NotificationsSession {
+	return s.notificationsSession()
+}
+
+func (s *Store) NewPreferencesSession() store.PreferencesSession {
+	return s.preferencesSession()
+}
+
+func (s *Store) NewAlertRulesSession() store.AlertRulesSession {
+	return s.alertRulesSession()
+}
+
+func (s *Store) notificationsSession() *NotificationsSession {
 	return &NotificationsSession{
 		Session: s.Store.NewSession("notifications"),
 	}
 }
 
+func (s *Store) preferencesSession() *PreferencesSession {
+	return &PreferencesSession{
+		Session: s.Store.NewSession("preferences"),
+	}
+}
+
+func (s *Store) alertRulesSession() *AlertRulesSession {
+	return &AlertRulesSession{
+		Session: s.Store.NewSession("alert_rules"),
+	}
+}
+
 type NotificationsSession struct {
 	*storeStructuredMongo.Session
 }
+
+// Notifications, including those dismissed, are retained until their expiration time
+func (n *NotificationsSession) EnsureIndexes() error {
+	return n.EnsureAllIndexes([]mgo.Index{
+		{Key: []string{"id"}, Unique: true, Background: true},
+		{Key: []string{"userId", "-createdTime"}, Background: true},
+		{Key: []string{"expirationTime"}, Background: true, ExpireAfter: time.Second},
+	})
+}
+
+func (n *NotificationsSession) ListUserNotifications(ctx context.Context, userID string, filter *notification.NotificationFilter, pagination *page.Pagination) (notification.Notifications, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+	if filter == nil {
+		filter = notification.NewNotificationFilter()
+	} else if err := structureValidator.New().Validate(filter); err != nil {
+		return nil, errors.Wrap(err, "filter is invalid")
+	}
+	if pagination == nil {
+		pagination = page.NewPagination()
+	} else if err := structureValidator.New().Validate(pagination); err != nil {
+		return nil, errors.Wrap(err, "pagination is invalid")
+	}
+
+	if n.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"userId": userID, "filter": filter, "pagination": pagination})
+
+	notifications := notification.Notifications{}
+	selector := bson.M{
+		"userId": userID,
+	}
+	if filter.Type != nil {
+		selector["type"] = *filter.Type
+	}
+	if filter.Read != nil {
+		selector["readTime"] = bson.M{"$exists": *filter.Read}
+	}
+	if filter.Dismissed != nil {
+		selector["dismissedTime"] = bson.M{"$exists": *filter.Dismissed}
+	}
+	err := n.C().Find(selector).Sort("-createdTime").Skip(pagination.Page * pagination.Size).Limit(pagination.Size).All(&notifications)
+	logger.WithFields(log.Fields{"count": len(notifications), "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("ListUserNotifications")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list user notifications")
+	}
+
+	if notifications == nil {
+		notifications = notification.Notifications{}
+	}
+
+	return notifications, nil
+}
+
+func (n *NotificationsSession) CreateUserNotification(ctx context.Context, userID string, create *notification.NotificationCreate) (*notification.Notification, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+
+	ntfctn, err := notification.NewNotification(userID, create)
+	if err != nil {
+		return nil, err
+	} else if err = structureValidator.New().Validate(ntfctn); err != nil {
+		return nil, errors.Wrap(err, "notification is invalid")
+	}
+
+	if n.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"userId": userID, "create": create})
+
+	err = n.C().Insert(ntfctn)
+	logger.WithFields(log.Fields{"id": ntfctn.ID, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("CreateUserNotification")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create user notification")
+	}
+
+	return ntfctn, nil
+}
+
+func (n *NotificationsSession) GetNotification(ctx context.Context, id string) (*notification.Notification, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if id == "" {
+		return nil, errors.New("id is missing")
+	}
+
+	if n.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("id", id)
+
+	notifications := notification.Notifications{}
+	err := n.C().Find(bson.M{"id": id}).Limit(2).All(&notifications)
+	logger.WithField("duration", time.Since(now)/time.Microsecond).WithError(err).Debug("GetNotification")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get notification")
+	}
+
+	switch count := len(notifications); count {
+	case 0:
+		return nil, nil
+	case 1:
+		return notifications[0], nil
+	default:
+		logger.WithField("count", count).Warnf("Multiple notifications found for id %q", id)
+		return notifications[0], nil
+	}
+}
+
+func (n *NotificationsSession) UpdateNotification(ctx context.Context, id string, update *notification.NotificationUpdate) (*notification.Notification, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if id == "" {
+		return nil, errors.New("id is missing")
+	}
+	if update == nil {
+		return nil, errors.New("update is missing")
+	} else if err := structureValidator.New().Validate(update); err != nil {
+		return nil, errors.Wrap(err, "update is invalid")
+	}
+
+	if n.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"id": id, "update": update})
+
+	set := bson.M{
+		"modifiedTime": now.Truncate(time.Second),
+	}
+	unset := bson.M{}
+	if update.Read != nil {
+		if *update.Read {
+			set["readTime"] = now.Truncate(time.Second)
+		} else {
+			unset["readTime"] = true
+		}
+	}
+	if update.Dismissed != nil {
+		if *update.Dismissed {
+			set["dismissedTime"] = now.Truncate(time.Second)
+		} else {
+			unset["dismissedTime"] = true
+		}
+	}
+	if update.Deliveries != nil {
+		set["deliveries"] = *update.Deliveries
+	}
+	changeInfo, err := n.C().UpdateAll(bson.M{"id": id}, n.ConstructUpdate(set, unset))
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("UpdateNotification")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to update notification")
+	}
+
+	return n.GetNotification(ctx, id)
+}
+
+func (n *NotificationsSession) DeleteUserNotifications(ctx context.Context, userID string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if userID == "" {
+		return errors.New("user id is missing")
+	}
+
+	if n.IsClosed() {
+		return errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("userId", userID)
+
+	changeInfo, err := n.C().RemoveAll(bson.M{"userId": userID})
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("DeleteUserNotifications")
+	if err != nil {
+		return errors.Wrap(err, "unable to delete user notifications")
+	}
+
+	return nil
+}
+
+type PreferencesSession struct {
+	*storeStructuredMongo.Session
+}
+
+func (p *PreferencesSession) EnsureIndexes() error {
+	return p.EnsureAllIndexes([]mgo.Index{
+		{Key: []string{"userId"}, Unique: true, Background: true},
+	})
+}
+
+func (p *PreferencesSession) GetUserPreferences(ctx context.Context, userID string) (*notification.Preferences, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+
+	if p.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("userId", userID)
+
+	preferences := []*notification.Preferences{}
+	err := p.C().Find(bson.M{"userId": userID}).Limit(1).All(&preferences)
+	logger.WithField("duration", time.Since(now)/time.Microsecond).WithError(err).Debug("GetUserPreferences")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get user preferences")
+	}
+
+	if len(preferences) == 0 {
+		return nil, nil
+	}
+	return preferences[0], nil
+}
+
+// Preferences are created for the user on the first update
+func (p *PreferencesSession) UpdateUserPreferences(ctx context.Context, userID string, update *notification.PreferencesUpdate) (*notification.Preferences, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+	if update == nil {
+		return nil, errors.New("update is missing")
+	} else if err := structureValidator.New().Validate(update); err != nil {
+		return nil, errors.Wrap(err, "update is invalid")
+	}
+
+	if p.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"userId": userID, "update": update})
+
+	set := bson.M{
+		"modifiedTime": now.Truncate(time.Second),
+	}
+	if update.Locale != nil {
+		set["locale"] = *update.Locale
+	}
+	if update.Email != nil {
+		set[notification.ChannelEmail] = update.Email
+	}
+	if update.Push != nil {
+		set[notification.ChannelPush] = update.Push
+	}
+	if update.SMS != nil {
+		set[notification.ChannelSMS] = update.SMS
+	}
+	setOnInsert := bson.M{
+		"userId":      userID,
+		"createdTime": now.Truncate(time.Second),
+	}
+	changeInfo, err := p.C().Upsert(bson.M{"userId": userID}, bson.M{"$set": set, "$setOnInsert": setOnInsert})
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("UpdateUserPreferences")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to update user preferences")
+	}
+
+	return p.GetUserPreferences(ctx, userID)
+}
+
+func (p *PreferencesSession) DeleteUserPreferences(ctx context.Context, userID string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if userID == "" {
+		return errors.New("user id is missing")
+	}
+
+	if p.IsClosed() {
+		return errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("userId", userID)
+
+	changeInfo, err := p.C().RemoveAll(bson.M{"userId": userID})
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("DeleteUserPreferences")
+	if err != nil {
+		return errors.Wrap(err, "unable to delete user preferences")
+	}
+
+	return nil
+}
+
+type AlertRulesSession struct {
+	*storeStructuredMongo.Session
+}
+
+func (a *AlertRulesSession) EnsureIndexes() error {
+	return a.EnsureAllIndexes([]mgo.Index{
+		{Key: []string{"id"}, Unique: true, Background: true},
+		{Key: []string{"userId", "type"}, Background: true},
+		{Key: []string{"ownerId"}, Background: true},
+	})
+}
+
+func (a *AlertRulesSession) ListRules(ctx context.Context, filter *alert.RuleFilter, pagination *page.Pagination) (alert.Rules, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if filter == nil {
+		filter = alert.NewRuleFilter()
+	} else if err := structureValidator.New().Validate(filter); err != nil {
+		return nil, errors.Wrap(err, "filter is invalid")
+	}
+	if pagination == nil {
+		pagination = page.NewPagination()
+	} else if err := structureValidator.New().Validate(pagination); err != nil {
+		return nil, errors.Wrap(err, "pagination is invalid")
+	}
+
+	if a.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"filter": filter, "pagination": pagination})
+
+	rules := alert.Rules{}
+	selector := bson.M{}
+	if filter.UserID != nil {
+		selector["userId"] = *filter.UserID
+	}
+	if filter.OwnerID != nil {
+		selector["ownerId"] = *filter.OwnerID
+	}
+	if filter.Type != nil {
+		selector["type"] = *filter.Type
+	}
+	err := a.C().Find(selector).Sort("createdTime").Skip(pagination.Page * pagination.Size).Limit(pagination.Size).All(&rules)
+	logger.WithFields(log.Fields{"count": len(rules), "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("ListRules")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list rules")
+	}
+
+	if rules == nil {
+		rules = alert.Rules{}
+	}
+
+	return rules, nil
+}
+
+func (a *AlertRulesSession) CreateUserRule(ctx context.Context, userID string, create *alert.RuleCreate) (*alert.Rule, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+
+	rule, err := alert.NewRule(userID, create)
+	if err != nil {
+		return nil, err
+	} else if err = structureValidator.New().Validate(rule); err != nil {
+		return nil, errors.Wrap(err, "rule is invalid")
+	}
+
+	if a.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"userId": userID, "create": create})
+
+	err = a.C().Insert(rule)
+	logger.WithFields(log.Fields{"id": rule.ID, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("CreateUserRule")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create user rule")
+	}
+
+	return rule, nil
+}
+
+func (a *AlertRulesSession) GetRule(ctx context.Context, id string) (*alert.Rule, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if id == "" {
+		return nil, errors.New("id is missing")
+	}
+
+	if a.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("id", id)
+
+	rules := alert.Rules{}
+	err := a.C().Find(bson.M{"id": id}).Limit(2).All(&rules)
+	logger.WithField("duration", time.Since(now)/time.Microsecond).WithError(err).Debug("GetRule")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get rule")
+	}
+
+	switch count := len(rules); count {
+	case 0:
+		return nil, nil
+	case 1:
+		return rules[0], nil
+	default:
+		logger.WithField("count", count).Warnf("Multiple rules found for id %q", id)
+		return rules[0], nil
+	}
+}
+
+func (a *AlertRulesSession) UpdateRule(ctx context.Context, id string, update *alert.RuleUpdate) (*alert.Rule, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if id == "" {
+		return nil, errors.New("id is missing")
+	}
+	if update == nil {
+		return nil, errors.New("update is missing")
+	} else if err := structureValidator.New().Validate(update); err != nil {
+		return nil, errors.Wrap(err, "update is invalid")
+	}
+
+	if a.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"id": id, "update": update})
+
+	set := bson.M{
+		"modifiedTime": now.Truncate(time.Second),
+	}
+	unset := bson.M{}
+	if update.Enabled != nil {
+		set["enabled"] = *update.Enabled
+	}
+	if update.Snooze != nil {
+		if *update.Snooze > 0 {
+			set["snoozedUntilTime"] = now.Add(time.Duration(*update.Snooze) * time.Minute).Truncate(time.Second)
+		} else {
+			unset["snoozedUntilTime"] = true
+		}
+	}
+	if update.Evaluation != nil {
+		set["evaluation"] = *update.Evaluation
+	}
+	changeInfo, err := a.C().UpdateAll(bson.M{"id": id}, a.ConstructUpdate(set, unset))
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("UpdateRule")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to update rule")
+	}
+
+	return a.GetRule(ctx, id)
+}
+
+func (a *AlertRulesSession) DeleteRule(ctx context.Context, id string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if id == "" {
+		return errors.New("id is missing")
+	}
+
+	if a.IsClosed() {
+		return errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithField("id", id)
+
+	changeInfo, err := a.C().RemoveAll(bson.M{"id": id})
+	logger.WithFields(log.Fields{"changeInfo": changeInfo, "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("DeleteRule")
+	if err != nil {
+		return errors.Wrap(err, "unable to delete rule")
+	}
+
+	return nil
+}
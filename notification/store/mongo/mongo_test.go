@@ -15,6 +15,8 @@ var _ = Describe("Mongo", func() {
 	var cfg *storeStructuredMongo.Config
 	var str *mongo.Store
 	var ssn store.NotificationsSession
+	var preferencesSsn store.PreferencesSession
+	var alertRulesSsn store.AlertRulesSession
 
 	BeforeEach(func() {
 		cfg = storeStructuredMongoTest.NewConfig()
@@ -24,6 +26,12 @@ var _ = Describe("Mongo", func() {
 		if ssn != nil {
 			ssn.Close()
 		}
+		if preferencesSsn != nil {
+			preferencesSsn.Close()
+		}
+		if alertRulesSsn != nil {
+			alertRulesSsn.Close()
+		}
 		if str != nil {
 			str.Close()
 		}
@@ -59,5 +67,19 @@ var _ = Describe("Mongo", func() {
 				Expect(ssn).ToNot(BeNil())
 			})
 		})
+
+		Context("NewPreferencesSession", func() {
+			It("returns a new session", func() {
+				preferencesSsn = str.NewPreferencesSession()
+				Expect(preferencesSsn).ToNot(BeNil())
+			})
+		})
+
+		Context("NewAlertRulesSession", func() {
+			It("returns a new session", func() {
+				alertRulesSsn = str.NewAlertRulesSession()
+				Expect(alertRulesSsn).ToNot(BeNil())
+			})
+		})
 	})
 })
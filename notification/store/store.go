@@ -1,11 +1,29 @@
 package store
 
-import "io"
+import (
+	"io"
+
+	"github.com/tidepool-org/platform/alert"
+	"github.com/tidepool-org/platform/notification"
+)
 
 type Store interface {
 	NewNotificationsSession() NotificationsSession
+	NewPreferencesSession() PreferencesSession
+	NewAlertRulesSession() AlertRulesSession
 }
 
 type NotificationsSession interface {
 	io.Closer
+	notification.NotificationAccessor
+}
+
+type PreferencesSession interface {
+	io.Closer
+	notification.PreferencesAccessor
+}
+
+type AlertRulesSession interface {
+	io.Closer
+	alert.RuleAccessor
 }
@@ -0,0 +1,23 @@
+package test
+
+import (
+	alertTest "github.com/tidepool-org/platform/alert/test"
+	"github.com/tidepool-org/platform/test"
+)
+
+type AlertRulesSession struct {
+	*test.Closer
+	*alertTest.RuleAccessor
+}
+
+func NewAlertRulesSession() *AlertRulesSession {
+	return &AlertRulesSession{
+		Closer:       test.NewCloser(),
+		RuleAccessor: alertTest.NewRuleAccessor(),
+	}
+}
+
+func (a *AlertRulesSession) AssertOutputsEmpty() {
+	a.Closer.AssertOutputsEmpty()
+	a.RuleAccessor.Expectations()
+}
@@ -1,17 +1,23 @@
 package test
 
-import "github.com/tidepool-org/platform/test"
+import (
+	notificationTest "github.com/tidepool-org/platform/notification/test"
+	"github.com/tidepool-org/platform/test"
+)
 
 type NotificationsSession struct {
 	*test.Closer
+	*notificationTest.NotificationAccessor
 }
 
 func NewNotificationsSession() *NotificationsSession {
 	return &NotificationsSession{
-		Closer: test.NewCloser(),
+		Closer:               test.NewCloser(),
+		NotificationAccessor: notificationTest.NewNotificationAccessor(),
 	}
 }
 
 func (n *NotificationsSession) AssertOutputsEmpty() {
 	n.Closer.AssertOutputsEmpty()
+	n.NotificationAccessor.Expectations()
 }
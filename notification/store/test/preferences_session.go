@@ -0,0 +1,23 @@
+package test
+
+import (
+	notificationTest "github.com/tidepool-org/platform/notification/test"
+	"github.com/tidepool-org/platform/test"
+)
+
+type PreferencesSession struct {
+	*test.Closer
+	*notificationTest.PreferencesAccessor
+}
+
+func NewPreferencesSession() *PreferencesSession {
+	return &PreferencesSession{
+		Closer:              test.NewCloser(),
+		PreferencesAccessor: notificationTest.NewPreferencesAccessor(),
+	}
+}
+
+func (p *PreferencesSession) AssertOutputsEmpty() {
+	p.Closer.AssertOutputsEmpty()
+	p.PreferencesAccessor.Expectations()
+}
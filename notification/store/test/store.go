@@ -7,6 +7,10 @@ import (
 type Store struct {
 	NewNotificationsSessionInvocations int
 	NewNotificationsSessionOutputs     []store.NotificationsSession
+	NewPreferencesSessionInvocations   int
+	NewPreferencesSessionOutputs       []store.PreferencesSession
+	NewAlertRulesSessionInvocations    int
+	NewAlertRulesSessionOutputs        []store.AlertRulesSession
 }
 
 func NewStore() *Store {
@@ -25,6 +29,32 @@ func (s *Store) NewNotificationsSession() store.NotificationsSession {
 	return output
 }
 
+func (s *Store) NewPreferencesSession() store.PreferencesSession {
+	s.NewPreferencesSessionInvocations++
+
+	if len(s.NewPreferencesSessionOutputs) == 0 {
+		panic("Unexpected invocation of NewPreferencesSession on Store")
+	}
+
+	output := s.NewPreferencesSessionOutputs[0]
+	s.NewPreferencesSessionOutputs = s.NewPreferencesSessionOutputs[1:]
+	return output
+}
+
+func (s *Store) NewAlertRulesSession() store.AlertRulesSession {
+	s.NewAlertRulesSessionInvocations++
+
+	if len(s.NewAlertRulesSessionOutputs) == 0 {
+		panic("Unexpected invocation of NewAlertRulesSession on Store")
+	}
+
+	output := s.NewAlertRulesSessionOutputs[0]
+	s.NewAlertRulesSessionOutputs = s.NewAlertRulesSessionOutputs[1:]
+	return output
+}
+
 func (s *Store) UnusedOutputsCount() int {
-	return len(s.NewNotificationsSessionOutputs)
+	return len(s.NewNotificationsSessionOutputs) +
+		len(s.NewPreferencesSessionOutputs) +
+		len(s.NewAlertRulesSessionOutputs)
 }
@@ -0,0 +1,191 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tidepool-org/platform/alert"
+	"github.com/tidepool-org/platform/notification"
+)
+
+// DefaultTemplates returns the built in templates for all notification types in English, French, and Spanish.
+// Data source templates use the "providerName" and "link" payload values, share invitation templates use the
+// "inviterName" and "link" payload values, message templates use the "subject" and "text" payload values, glucose
+// alert templates use the "ruleType", "value", "units", and "remote" payload values, and user export templates use
+// the "link" payload value.
+func DefaultTemplates() []*Template {
+	return []*Template{
+		{
+			Type:    notification.TypeDataSourceDisconnected,
+			Locale:  notification.LocaleEnglish,
+			Subject: `Your {{with .Payload.providerName}}{{.}} {{end}}data source was disconnected`,
+			Text:    `Your {{with .Payload.providerName}}{{.}} {{end}}account is no longer connected to Tidepool, so new data will not be imported.{{with .Payload.link}} To reconnect, visit {{.}}{{end}}`,
+			HTML:    `<p>Your {{with .Payload.providerName}}{{.}} {{end}}account is no longer connected to Tidepool, so new data will not be imported.</p>{{with .Payload.link}}<p><a href="{{.}}">Reconnect</a></p>{{end}}`,
+		},
+		{
+			Type:    notification.TypeDataSourceDisconnected,
+			Locale:  notification.LocaleFrench,
+			Subject: `Votre source de données {{with .Payload.providerName}}{{.}} {{end}}a été déconnectée`,
+			Text:    `Votre compte {{with .Payload.providerName}}{{.}} {{end}}n'est plus connecté à Tidepool ; les nouvelles données ne seront plus importées.{{with .Payload.link}} Pour le reconnecter, rendez-vous sur {{.}}{{end}}`,
+			HTML:    `<p>Votre compte {{with .Payload.providerName}}{{.}} {{end}}n'est plus connecté à Tidepool ; les nouvelles données ne seront plus importées.</p>{{with .Payload.link}}<p><a href="{{.}}">Reconnecter</a></p>{{end}}`,
+		},
+		{
+			Type:    notification.TypeDataSourceDisconnected,
+			Locale:  notification.LocaleSpanish,
+			Subject: `Su fuente de datos {{with .Payload.providerName}}{{.}} {{end}}se ha desconectado`,
+			Text:    `Su cuenta {{with .Payload.providerName}}de {{.}} {{end}}ya no está conectada a Tidepool, por lo que no se importarán datos nuevos.{{with .Payload.link}} Para volver a conectarla, visite {{.}}{{end}}`,
+			HTML:    `<p>Su cuenta {{with .Payload.providerName}}de {{.}} {{end}}ya no está conectada a Tidepool, por lo que no se importarán datos nuevos.</p>{{with .Payload.link}}<p><a href="{{.}}">Volver a conectar</a></p>{{end}}`,
+		},
+		{
+			Type:    notification.TypeDataSourceError,
+			Locale:  notification.LocaleEnglish,
+			Subject: `There is a problem with your {{with .Payload.providerName}}{{.}} {{end}}data source`,
+			Text:    `We are unable to import data from your {{with .Payload.providerName}}{{.}} {{end}}account.{{with .Payload.link}} To reconnect, visit {{.}}{{end}}`,
+			HTML:    `<p>We are unable to import data from your {{with .Payload.providerName}}{{.}} {{end}}account.</p>{{with .Payload.link}}<p><a href="{{.}}">Reconnect</a></p>{{end}}`,
+		},
+		{
+			Type:    notification.TypeDataSourceError,
+			Locale:  notification.LocaleFrench,
+			Subject: `Un problème est survenu avec votre source de données {{with .Payload.providerName}}{{.}}{{end}}`,
+			Text:    `Nous ne parvenons pas à importer les données de votre compte {{with .Payload.providerName}}{{.}}{{end}}.{{with .Payload.link}} Pour le reconnecter, rendez-vous sur {{.}}{{end}}`,
+			HTML:    `<p>Nous ne parvenons pas à importer les données de votre compte {{with .Payload.providerName}}{{.}}{{end}}.</p>{{with .Payload.link}}<p><a href="{{.}}">Reconnecter</a></p>{{end}}`,
+		},
+		{
+			Type:    notification.TypeDataSourceError,
+			Locale:  notification.LocaleSpanish,
+			Subject: `Hay un problema con su fuente de datos {{with .Payload.providerName}}{{.}}{{end}}`,
+			Text:    `No podemos importar datos de su cuenta{{with .Payload.providerName}} de {{.}}{{end}}.{{with .Payload.link}} Para volver a conectarla, visite {{.}}{{end}}`,
+			HTML:    `<p>No podemos importar datos de su cuenta{{with .Payload.providerName}} de {{.}}{{end}}.</p>{{with .Payload.link}}<p><a href="{{.}}">Volver a conectar</a></p>{{end}}`,
+		},
+		{
+			Type:    notification.TypeMessage,
+			Locale:  notification.LocaleEnglish,
+			Subject: `{{.Payload.subject | default "You have a new message"}}`,
+			Text:    `{{.Payload.text | default "You have a new message in Tidepool."}}`,
+			HTML:    `<p>{{.Payload.text | default "You have a new message in Tidepool."}}</p>`,
+		},
+		{
+			Type:    notification.TypeMessage,
+			Locale:  notification.LocaleFrench,
+			Subject: `{{.Payload.subject | default "Vous avez un nouveau message"}}`,
+			Text:    `{{.Payload.text | default "Vous avez un nouveau message sur Tidepool."}}`,
+			HTML:    `<p>{{.Payload.text | default "Vous avez un nouveau message sur Tidepool."}}</p>`,
+		},
+		{
+			Type:    notification.TypeMessage,
+			Locale:  notification.LocaleSpanish,
+			Subject: `{{.Payload.subject | default "Tiene un mensaje nuevo"}}`,
+			Text:    `{{.Payload.text | default "Tiene un mensaje nuevo en Tidepool."}}`,
+			HTML:    `<p>{{.Payload.text | default "Tiene un mensaje nuevo en Tidepool."}}</p>`,
+		},
+		{
+			Type:    notification.TypeShareInvitation,
+			Locale:  notification.LocaleEnglish,
+			Subject: `{{.Payload.inviterName | default "Someone"}} invited you to share data in Tidepool`,
+			Text:    `{{.Payload.inviterName | default "Someone"}} invited you to view their diabetes data in Tidepool.{{with .Payload.link}} To accept the invitation, visit {{.}}{{end}}`,
+			HTML:    `<p>{{.Payload.inviterName | default "Someone"}} invited you to view their diabetes data in Tidepool.</p>{{with .Payload.link}}<p><a href="{{.}}">Accept invitation</a></p>{{end}}`,
+		},
+		{
+			Type:    notification.TypeShareInvitation,
+			Locale:  notification.LocaleFrench,
+			Subject: `{{.Payload.inviterName | default "Quelqu'un"}} vous invite à partager des données sur Tidepool`,
+			Text:    `{{.Payload.inviterName | default "Quelqu'un"}} vous invite à consulter ses données de diabète sur Tidepool.{{with .Payload.link}} Pour accepter l'invitation, rendez-vous sur {{.}}{{end}}`,
+			HTML:    `<p>{{.Payload.inviterName | default "Quelqu'un"}} vous invite à consulter ses données de diabète sur Tidepool.</p>{{with .Payload.link}}<p><a href="{{.}}">Accepter l'invitation</a></p>{{end}}`,
+		},
+		{
+			Type:    notification.TypeShareInvitation,
+			Locale:  notification.LocaleSpanish,
+			Subject: `{{.Payload.inviterName | default "Alguien"}} le ha invitado a compartir datos en Tidepool`,
+			Text:    `{{.Payload.inviterName | default "Alguien"}} le ha invitado a ver sus datos de diabetes en Tidepool.{{with .Payload.link}} Para aceptar la invitación, visite {{.}}{{end}}`,
+			HTML:    `<p>{{.Payload.inviterName | default "Alguien"}} le ha invitado a ver sus datos de diabetes en Tidepool.</p>{{with .Payload.link}}<p><a href="{{.}}">Aceptar invitación</a></p>{{end}}`,
+		},
+		{
+			Type:    notification.TypeGlucoseAlert,
+			Locale:  notification.LocaleEnglish,
+			Subject: glucoseAlertTitle(glucoseAlertTitlesEnglish, "Glucose alert"),
+			Text:    glucoseAlertTitle(glucoseAlertTitlesEnglish, "Glucose alert") + `{{with .Payload.value}}: {{.}} {{$.Payload.units}}{{end}}.{{if .Payload.remote}} This alert is for someone who shares their data with you.{{end}}`,
+			HTML:    `<p>` + glucoseAlertTitle(glucoseAlertTitlesEnglish, "Glucose alert") + `{{with .Payload.value}}: <strong>{{.}} {{$.Payload.units}}</strong>{{end}}.</p>{{if .Payload.remote}}<p>This alert is for someone who shares their data with you.</p>{{end}}`,
+		},
+		{
+			Type:    notification.TypeGlucoseAlert,
+			Locale:  notification.LocaleFrench,
+			Subject: glucoseAlertTitle(glucoseAlertTitlesFrench, "Alerte glycémique"),
+			Text:    glucoseAlertTitle(glucoseAlertTitlesFrench, "Alerte glycémique") + `{{with .Payload.value}} : {{.}} {{$.Payload.units}}{{end}}.{{if .Payload.remote}} Cette alerte concerne une personne qui partage ses données avec vous.{{end}}`,
+			HTML:    `<p>` + glucoseAlertTitle(glucoseAlertTitlesFrench, "Alerte glycémique") + `{{with .Payload.value}} : <strong>{{.}} {{$.Payload.units}}</strong>{{end}}.</p>{{if .Payload.remote}}<p>Cette alerte concerne une personne qui partage ses données avec vous.</p>{{end}}`,
+		},
+		{
+			Type:    notification.TypeGlucoseAlert,
+			Locale:  notification.LocaleSpanish,
+			Subject: glucoseAlertTitle(glucoseAlertTitlesSpanish, "Alerta de glucosa"),
+			Text:    glucoseAlertTitle(glucoseAlertTitlesSpanish, "Alerta de glucosa") + `{{with .Payload.value}}: {{.}} {{$.Payload.units}}{{end}}.{{if .Payload.remote}} Esta alerta es de una persona que comparte sus datos con usted.{{end}}`,
+			HTML:    `<p>` + glucoseAlertTitle(glucoseAlertTitlesSpanish, "Alerta de glucosa") + `{{with .Payload.value}}: <strong>{{.}} {{$.Payload.units}}</strong>{{end}}.</p>{{if .Payload.remote}}<p>Esta alerta es de una persona que comparte sus datos con usted.</p>{{end}}`,
+		},
+		{
+			Type:    notification.TypeUserExport,
+			Locale:  notification.LocaleEnglish,
+			Subject: `Your Tidepool account export is ready`,
+			Text:    `A copy of all of the information we hold about your Tidepool account is ready to download.{{with .Payload.link}} To download it, visit {{.}}{{end}} The download is only available for a limited time.`,
+			HTML:    `<p>A copy of all of the information we hold about your Tidepool account is ready to download.</p>{{with .Payload.link}}<p><a href="{{.}}">Download</a></p>{{end}}<p>The download is only available for a limited time.</p>`,
+		},
+		{
+			Type:    notification.TypeUserExport,
+			Locale:  notification.LocaleFrench,
+			Subject: `L'export de votre compte Tidepool est prêt`,
+			Text:    `Une copie de toutes les informations que nous détenons sur votre compte Tidepool est prête à être téléchargée.{{with .Payload.link}} Pour la télécharger, rendez-vous sur {{.}}{{end}} Le téléchargement n'est disponible que pendant une durée limitée.`,
+			HTML:    `<p>Une copie de toutes les informations que nous détenons sur votre compte Tidepool est prête à être téléchargée.</p>{{with .Payload.link}}<p><a href="{{.}}">Télécharger</a></p>{{end}}<p>Le téléchargement n'est disponible que pendant une durée limitée.</p>`,
+		},
+		{
+			Type:    notification.TypeUserExport,
+			Locale:  notification.LocaleSpanish,
+			Subject: `La exportación de su cuenta de Tidepool está lista`,
+			Text:    `Una copia de toda la información que tenemos sobre su cuenta de Tidepool está lista para descargar.{{with .Payload.link}} Para descargarla, visite {{.}}{{end}} La descarga solo está disponible durante un tiempo limitado.`,
+			HTML:    `<p>Una copia de toda la información que tenemos sobre su cuenta de Tidepool está lista para descargar.</p>{{with .Payload.link}}<p><a href="{{.}}">Descargar</a></p>{{end}}<p>La descarga solo está disponible durante un tiempo limitado.</p>`,
+		},
+	}
+}
+
+var glucoseAlertTitlesEnglish = map[string]string{
+	alert.RuleTypeHigh:          "High glucose",
+	alert.RuleTypeLow:           "Low glucose",
+	alert.RuleTypeNoData:        "No recent glucose data",
+	alert.RuleTypeRapidFall:     "Glucose falling rapidly",
+	alert.RuleTypeRapidRise:     "Glucose rising rapidly",
+	alert.RuleTypeSustainedHigh: "Sustained high glucose",
+	alert.RuleTypeUrgentLow:     "Urgent low glucose",
+}
+
+var glucoseAlertTitlesFrench = map[string]string{
+	alert.RuleTypeHigh:          "Glycémie élevée",
+	alert.RuleTypeLow:           "Glycémie basse",
+	alert.RuleTypeNoData:        "Aucune donnée glycémique récente",
+	alert.RuleTypeRapidFall:     "Glycémie en baisse rapide",
+	alert.RuleTypeRapidRise:     "Glycémie en hausse rapide",
+	alert.RuleTypeSustainedHigh: "Glycémie élevée prolongée",
+	alert.RuleTypeUrgentLow:     "Glycémie basse urgente",
+}
+
+var glucoseAlertTitlesSpanish = map[string]string{
+	alert.RuleTypeHigh:          "Glucosa alta",
+	alert.RuleTypeLow:           "Glucosa baja",
+	alert.RuleTypeNoData:        "Sin datos de glucosa recientes",
+	alert.RuleTypeRapidFall:     "Glucosa bajando rápidamente",
+	alert.RuleTypeRapidRise:     "Glucosa subiendo rápidamente",
+	alert.RuleTypeSustainedHigh: "Glucosa alta prolongada",
+	alert.RuleTypeUrgentLow:     "Glucosa baja urgente",
+}
+
+// Returns a template fragment that selects the title for the "ruleType" payload value, or the fallback if unknown
+func glucoseAlertTitle(titles map[string]string, fallback string) string {
+	builder := strings.Builder{}
+	builder.WriteString(`{{$ruleType := .Payload.ruleType | default ""}}`)
+	for index, ruleType := range alert.RuleTypes() {
+		if index == 0 {
+			builder.WriteString(fmt.Sprintf(`{{if eq $ruleType %q}}`, ruleType))
+		} else {
+			builder.WriteString(fmt.Sprintf(`{{else if eq $ruleType %q}}`, ruleType))
+		}
+		builder.WriteString(titles[ruleType])
+	}
+	builder.WriteString(`{{else}}` + fallback + `{{end}}`)
+	return builder.String()
+}
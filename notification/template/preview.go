@@ -0,0 +1,39 @@
+package template
+
+import (
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/structure"
+)
+
+// If locale is not specified, then the default locale is used
+type Preview struct {
+	Type    string                 `json:"type"`
+	Locale  *string                `json:"locale,omitempty"`
+	Payload map[string]interface{} `json:"payload,omitempty"`
+}
+
+func NewPreview() *Preview {
+	return &Preview{}
+}
+
+func (p *Preview) LocaleOrDefault() string {
+	if p.Locale != nil {
+		return *p.Locale
+	}
+	return notification.LocaleDefault
+}
+
+func (p *Preview) Parse(parser structure.ObjectParser) {
+	if ptr := parser.String("type"); ptr != nil {
+		p.Type = *ptr
+	}
+	p.Locale = parser.String("locale")
+	if ptr := parser.Object("payload"); ptr != nil {
+		p.Payload = *ptr
+	}
+}
+
+func (p *Preview) Validate(validator structure.Validator) {
+	validator.String("type", &p.Type).OneOf(notification.Types()...)
+	validator.String("locale", p.Locale).Using(notification.LocaleValidator)
+}
@@ -0,0 +1,48 @@
+package template_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/notification"
+	notificationTemplate "github.com/tidepool-org/platform/notification/template"
+	"github.com/tidepool-org/platform/pointer"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
+var _ = Describe("Preview", func() {
+	It("LocaleOrDefault returns the default locale if the locale is not specified", func() {
+		Expect(notificationTemplate.NewPreview().LocaleOrDefault()).To(Equal(notification.LocaleDefault))
+	})
+
+	It("LocaleOrDefault returns the locale if specified", func() {
+		preview := notificationTemplate.NewPreview()
+		preview.Locale = pointer.FromString("es")
+		Expect(preview.LocaleOrDefault()).To(Equal("es"))
+	})
+
+	DescribeTable("Validate",
+		func(mutator func(preview *notificationTemplate.Preview), expectedErrors ...error) {
+			preview := notificationTemplate.NewPreview()
+			preview.Type = notification.TypeMessage
+			mutator(preview)
+			errorsTest.ExpectEqual(structureValidator.New().Validate(preview), expectedErrors...)
+		},
+		Entry("succeeds",
+			func(preview *notificationTemplate.Preview) {},
+		),
+		Entry("type invalid",
+			func(preview *notificationTemplate.Preview) { preview.Type = "invalid" },
+			errorsTest.WithPointerSource(structureValidator.ErrorValueStringNotOneOf("invalid", notification.Types()), "/type"),
+		),
+		Entry("locale valid",
+			func(preview *notificationTemplate.Preview) { preview.Locale = pointer.FromString("fr-CA") },
+		),
+		Entry("locale invalid",
+			func(preview *notificationTemplate.Preview) { preview.Locale = pointer.FromString("invalid locale") },
+			errorsTest.WithPointerSource(notification.ErrorValueStringAsLocaleNotValid("invalid locale"), "/locale"),
+		),
+	)
+})
@@ -0,0 +1,177 @@
+package template
+
+import (
+	"bytes"
+	htmlTemplate "html/template"
+	textTemplate "text/template"
+
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/structure"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
+// Template specifies the content of a notification type in a locale. The subject and text are
+// rendered with text/template and the HTML with html/template. Each is executed with Data.
+type Template struct {
+	Type    string
+	Locale  string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+func (t *Template) Validate(validator structure.Validator) {
+	validator.String("type", &t.Type).OneOf(notification.Types()...)
+	validator.String("locale", &t.Locale).Using(notification.LocaleValidator)
+	validator.String("subject", &t.Subject).NotEmpty()
+	validator.String("text", &t.Text).NotEmpty()
+	validator.String("html", &t.HTML).NotEmpty()
+}
+
+type Data struct {
+	Type    string
+	Locale  string
+	Payload map[string]interface{}
+}
+
+type Content struct {
+	Type    string `json:"type"`
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+type Renderer struct {
+	templates map[string]*compiledTemplate
+}
+
+// Every notification type with a template must also have a template in the default locale so that
+// locale fallback always succeeds
+func NewRenderer(templates []*Template) (*Renderer, error) {
+	compiledTemplates := map[string]*compiledTemplate{}
+	for index, tmpl := range templates {
+		if tmpl == nil {
+			return nil, errors.Newf("template at index %d is missing", index)
+		} else if err := structureValidator.New().Validate(tmpl); err != nil {
+			return nil, errors.Wrapf(err, "template at index %d is invalid", index)
+		}
+
+		key := templateKey(tmpl.Type, notification.NormalizeLocale(tmpl.Locale))
+		if _, exists := compiledTemplates[key]; exists {
+			return nil, errors.Newf("template for type %q and locale %q is duplicate", tmpl.Type, tmpl.Locale)
+		}
+
+		compiled, err := compileTemplate(tmpl)
+		if err != nil {
+			return nil, err
+		}
+		compiledTemplates[key] = compiled
+	}
+
+	for _, tmpl := range templates {
+		if _, exists := compiledTemplates[templateKey(tmpl.Type, notification.LocaleDefault)]; !exists {
+			return nil, errors.Newf("template for type %q and default locale is missing", tmpl.Type)
+		}
+	}
+
+	return &Renderer{
+		templates: compiledTemplates,
+	}, nil
+}
+
+func NewDefaultRenderer() (*Renderer, error) {
+	return NewRenderer(DefaultTemplates())
+}
+
+// ResolveLocale returns the locale of the template used for the notification type and locale. The
+// locale is matched exactly (eg. "fr-ca"), then by language (eg. "fr"), and otherwise falls back to
+// the default locale. Returns an empty string if there is no template for the notification type.
+func (r *Renderer) ResolveLocale(typ string, locale string) string {
+	for _, candidate := range []string{notification.NormalizeLocale(locale), notification.LocaleLanguage(locale), notification.LocaleDefault} {
+		if _, exists := r.templates[templateKey(typ, candidate)]; exists {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func (r *Renderer) Render(typ string, locale string, payload map[string]interface{}) (*Content, error) {
+	resolvedLocale := r.ResolveLocale(typ, locale)
+	if resolvedLocale == "" {
+		return nil, errors.Newf("template for type %q is missing", typ)
+	}
+
+	compiled := r.templates[templateKey(typ, resolvedLocale)]
+	data := &Data{
+		Type:    typ,
+		Locale:  resolvedLocale,
+		Payload: payload,
+	}
+
+	content := &Content{
+		Type:   typ,
+		Locale: resolvedLocale,
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := compiled.subject.Execute(buffer, data); err != nil {
+		return nil, errors.Wrap(err, "unable to render subject")
+	}
+	content.Subject = buffer.String()
+
+	buffer.Reset()
+	if err := compiled.text.Execute(buffer, data); err != nil {
+		return nil, errors.Wrap(err, "unable to render text")
+	}
+	content.Text = buffer.String()
+
+	buffer.Reset()
+	if err := compiled.html.Execute(buffer, data); err != nil {
+		return nil, errors.Wrap(err, "unable to render html")
+	}
+	content.HTML = buffer.String()
+
+	return content, nil
+}
+
+type compiledTemplate struct {
+	subject *textTemplate.Template
+	text    *textTemplate.Template
+	html    *htmlTemplate.Template
+}
+
+func compileTemplate(tmpl *Template) (*compiledTemplate, error) {
+	subject, err := textTemplate.New("subject").Funcs(textTemplate.FuncMap(functions)).Parse(tmpl.Subject)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse subject template for type %q and locale %q", tmpl.Type, tmpl.Locale)
+	}
+	text, err := textTemplate.New("text").Funcs(textTemplate.FuncMap(functions)).Parse(tmpl.Text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse text template for type %q and locale %q", tmpl.Type, tmpl.Locale)
+	}
+	html, err := htmlTemplate.New("html").Funcs(htmlTemplate.FuncMap(functions)).Parse(tmpl.HTML)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse html template for type %q and locale %q", tmpl.Type, tmpl.Locale)
+	}
+
+	return &compiledTemplate{
+		subject: subject,
+		text:    text,
+		html:    html,
+	}, nil
+}
+
+var functions = map[string]interface{}{
+	"default": func(defaultValue interface{}, value interface{}) interface{} {
+		if value == nil || value == "" {
+			return defaultValue
+		}
+		return value
+	},
+}
+
+func templateKey(typ string, locale string) string {
+	return typ + "/" + locale
+}
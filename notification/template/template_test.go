@@ -0,0 +1,154 @@
+package template_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/tidepool-org/platform/notification"
+	notificationTemplate "github.com/tidepool-org/platform/notification/template"
+)
+
+var _ = Describe("Template", func() {
+	Context("NewRenderer", func() {
+		var templates []*notificationTemplate.Template
+
+		BeforeEach(func() {
+			templates = []*notificationTemplate.Template{
+				{Type: notification.TypeMessage, Locale: "en", Subject: "Subject", Text: "Text", HTML: "<p>HTML</p>"},
+				{Type: notification.TypeMessage, Locale: "fr", Subject: "Sujet", Text: "Texte", HTML: "<p>HTML</p>"},
+			}
+		})
+
+		It("returns an error if a template is missing", func() {
+			renderer, err := notificationTemplate.NewRenderer(append(templates, nil))
+			Expect(err).To(MatchError("template at index 2 is missing"))
+			Expect(renderer).To(BeNil())
+		})
+
+		It("returns an error if a template is invalid", func() {
+			templates[1].Subject = ""
+			renderer, err := notificationTemplate.NewRenderer(templates)
+			Expect(err).To(MatchError("template at index 1 is invalid; value is empty"))
+			Expect(renderer).To(BeNil())
+		})
+
+		It("returns an error if a template is duplicate", func() {
+			templates[1].Locale = "EN"
+			renderer, err := notificationTemplate.NewRenderer(templates)
+			Expect(err).To(MatchError(`template for type "message" and locale "EN" is duplicate`))
+			Expect(renderer).To(BeNil())
+		})
+
+		It("returns an error if a template does not parse", func() {
+			templates[1].Text = "{{.Payload"
+			renderer, err := notificationTemplate.NewRenderer(templates)
+			Expect(err).To(MatchError(HavePrefix(`unable to parse text template for type "message" and locale "fr"`)))
+			Expect(renderer).To(BeNil())
+		})
+
+		It("returns an error if the template for the default locale is missing", func() {
+			renderer, err := notificationTemplate.NewRenderer(templates[1:])
+			Expect(err).To(MatchError(`template for type "message" and default locale is missing`))
+			Expect(renderer).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			renderer, err := notificationTemplate.NewRenderer(templates)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renderer).ToNot(BeNil())
+		})
+	})
+
+	Context("with default renderer", func() {
+		var renderer *notificationTemplate.Renderer
+
+		BeforeEach(func() {
+			var err error
+			renderer, err = notificationTemplate.NewDefaultRenderer()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(renderer).ToNot(BeNil())
+		})
+
+		DescribeTable("ResolveLocale",
+			func(typ string, locale string, expectedLocale string) {
+				Expect(renderer.ResolveLocale(typ, locale)).To(Equal(expectedLocale))
+			},
+			Entry("matches exactly", notification.TypeMessage, "fr", "fr"),
+			Entry("matches exactly ignoring case", notification.TypeMessage, "ES", "es"),
+			Entry("falls back to language", notification.TypeMessage, "fr-CA", "fr"),
+			Entry("falls back to language with underscore", notification.TypeMessage, "es_MX", "es"),
+			Entry("falls back to default locale", notification.TypeMessage, "de-DE", "en"),
+			Entry("falls back to default locale if empty", notification.TypeMessage, "", "en"),
+			Entry("returns empty if type has no templates", "invalid", "en", ""),
+		)
+
+		It("renders every notification type in every locale", func() {
+			for _, typ := range notification.Types() {
+				for _, locale := range notification.Locales() {
+					content, err := renderer.Render(typ, locale, nil)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(content.Type).To(Equal(typ))
+					Expect(content.Locale).To(Equal(locale))
+					Expect(content.Subject).ToNot(BeEmpty())
+					Expect(content.Text).ToNot(BeEmpty())
+					Expect(content.HTML).ToNot(BeEmpty())
+					Expect(content.Text).ToNot(ContainSubstring("<no value>"))
+				}
+			}
+		})
+
+		It("returns an error if the type has no templates", func() {
+			content, err := renderer.Render("invalid", "en", nil)
+			Expect(err).To(MatchError(`template for type "invalid" is missing`))
+			Expect(content).To(BeNil())
+		})
+
+		It("renders the data source disconnected notification with the payload", func() {
+			payload := map[string]interface{}{"providerName": "Dexcom", "link": "https://example.com/v1/oauth/dexcom/authorize"}
+			content, err := renderer.Render(notification.TypeDataSourceDisconnected, "fr-CA", payload)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content).To(Equal(&notificationTemplate.Content{
+				Type:    notification.TypeDataSourceDisconnected,
+				Locale:  "fr",
+				Subject: "Votre source de données Dexcom a été déconnectée",
+				Text:    "Votre compte Dexcom n'est plus connecté à Tidepool ; les nouvelles données ne seront plus importées. Pour le reconnecter, rendez-vous sur https://example.com/v1/oauth/dexcom/authorize",
+				HTML:    `<p>Votre compte Dexcom n'est plus connecté à Tidepool ; les nouvelles données ne seront plus importées.</p><p><a href="https://example.com/v1/oauth/dexcom/authorize">Reconnecter</a></p>`,
+			}))
+		})
+
+		It("renders the share invitation notification with defaults", func() {
+			content, err := renderer.Render(notification.TypeShareInvitation, "es", map[string]interface{}{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content.Subject).To(Equal("Alguien le ha invitado a compartir datos en Tidepool"))
+			Expect(content.Text).To(Equal("Alguien le ha invitado a ver sus datos de diabetes en Tidepool."))
+		})
+
+		It("renders the glucose alert notification with the payload", func() {
+			payload := map[string]interface{}{"ruleType": "urgentLow", "value": 52, "units": "mg/dL", "remote": true}
+			content, err := renderer.Render(notification.TypeGlucoseAlert, "en-US", payload)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content).To(Equal(&notificationTemplate.Content{
+				Type:    notification.TypeGlucoseAlert,
+				Locale:  "en",
+				Subject: "Urgent low glucose",
+				Text:    "Urgent low glucose: 52 mg/dL. This alert is for someone who shares their data with you.",
+				HTML:    "<p>Urgent low glucose: <strong>52 mg/dL</strong>.</p><p>This alert is for someone who shares their data with you.</p>",
+			}))
+		})
+
+		It("renders the glucose alert notification without a value", func() {
+			content, err := renderer.Render(notification.TypeGlucoseAlert, "es", map[string]interface{}{"ruleType": "noData"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content.Subject).To(Equal("Sin datos de glucosa recientes"))
+			Expect(content.Text).To(Equal("Sin datos de glucosa recientes."))
+		})
+
+		It("escapes the payload in the html", func() {
+			content, err := renderer.Render(notification.TypeMessage, "en", map[string]interface{}{"text": "<script>alert(1)</script>"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(content.Text).To(Equal("<script>alert(1)</script>"))
+			Expect(content.HTML).To(Equal("<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"))
+		})
+	})
+})
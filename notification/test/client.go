@@ -1,19 +1,25 @@
 package test
 
 import (
-	"github.com/tidepool-org/platform/test"
+	alertTest "github.com/tidepool-org/platform/alert/test"
 )
 
 type Client struct {
-	*test.Mock
+	*NotificationAccessor
+	*PreferencesAccessor
+	*alertTest.RuleAccessor
 }
 
 func NewClient() *Client {
 	return &Client{
-		Mock: test.NewMock(),
+		NotificationAccessor: NewNotificationAccessor(),
+		PreferencesAccessor:  NewPreferencesAccessor(),
+		RuleAccessor:         alertTest.NewRuleAccessor(),
 	}
 }
 
-func (c *Client) UnusedOutputsCount() int {
-	return 0
+func (c *Client) Expectations() {
+	c.NotificationAccessor.Expectations()
+	c.PreferencesAccessor.Expectations()
+	c.RuleAccessor.Expectations()
 }
@@ -0,0 +1,154 @@
+package test
+
+import (
+	"context"
+
+	"github.com/onsi/gomega"
+
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/test"
+)
+
+type ListUserNotificationsInput struct {
+	Context    context.Context
+	UserID     string
+	Filter     *notification.NotificationFilter
+	Pagination *page.Pagination
+}
+
+type ListUserNotificationsOutput struct {
+	Notifications notification.Notifications
+	Error         error
+}
+
+type CreateUserNotificationInput struct {
+	Context context.Context
+	UserID  string
+	Create  *notification.NotificationCreate
+}
+
+type CreateUserNotificationOutput struct {
+	Notification *notification.Notification
+	Error        error
+}
+
+type GetNotificationInput struct {
+	Context context.Context
+	ID      string
+}
+
+type GetNotificationOutput struct {
+	Notification *notification.Notification
+	Error        error
+}
+
+type UpdateNotificationInput struct {
+	Context context.Context
+	ID      string
+	Update  *notification.NotificationUpdate
+}
+
+type UpdateNotificationOutput struct {
+	Notification *notification.Notification
+	Error        error
+}
+
+type DeleteUserNotificationsInput struct {
+	Context context.Context
+	UserID  string
+}
+
+type NotificationAccessor struct {
+	*test.Mock
+	ListUserNotificationsInvocations   int
+	ListUserNotificationsInputs        []ListUserNotificationsInput
+	ListUserNotificationsOutputs       []ListUserNotificationsOutput
+	CreateUserNotificationInvocations  int
+	CreateUserNotificationInputs       []CreateUserNotificationInput
+	CreateUserNotificationOutputs      []CreateUserNotificationOutput
+	GetNotificationInvocations         int
+	GetNotificationInputs              []GetNotificationInput
+	GetNotificationOutputs             []GetNotificationOutput
+	UpdateNotificationInvocations      int
+	UpdateNotificationInputs           []UpdateNotificationInput
+	UpdateNotificationOutputs          []UpdateNotificationOutput
+	DeleteUserNotificationsInvocations int
+	DeleteUserNotificationsInputs      []DeleteUserNotificationsInput
+	DeleteUserNotificationsOutputs     []error
+}
+
+func NewNotificationAccessor() *NotificationAccessor {
+	return &NotificationAccessor{
+		Mock: test.NewMock(),
+	}
+}
+
+func (n *NotificationAccessor) ListUserNotifications(ctx context.Context, userID string, filter *notification.NotificationFilter, pagination *page.Pagination) (notification.Notifications, error) {
+	n.ListUserNotificationsInvocations++
+
+	n.ListUserNotificationsInputs = append(n.ListUserNotificationsInputs, ListUserNotificationsInput{Context: ctx, UserID: userID, Filter: filter, Pagination: pagination})
+
+	gomega.Expect(n.ListUserNotificationsOutputs).ToNot(gomega.BeEmpty())
+
+	output := n.ListUserNotificationsOutputs[0]
+	n.ListUserNotificationsOutputs = n.ListUserNotificationsOutputs[1:]
+	return output.Notifications, output.Error
+}
+
+func (n *NotificationAccessor) CreateUserNotification(ctx context.Context, userID string, create *notification.NotificationCreate) (*notification.Notification, error) {
+	n.CreateUserNotificationInvocations++
+
+	n.CreateUserNotificationInputs = append(n.CreateUserNotificationInputs, CreateUserNotificationInput{Context: ctx, UserID: userID, Create: create})
+
+	gomega.Expect(n.CreateUserNotificationOutputs).ToNot(gomega.BeEmpty())
+
+	output := n.CreateUserNotificationOutputs[0]
+	n.CreateUserNotificationOutputs = n.CreateUserNotificationOutputs[1:]
+	return output.Notification, output.Error
+}
+
+func (n *NotificationAccessor) GetNotification(ctx context.Context, id string) (*notification.Notification, error) {
+	n.GetNotificationInvocations++
+
+	n.GetNotificationInputs = append(n.GetNotificationInputs, GetNotificationInput{Context: ctx, ID: id})
+
+	gomega.Expect(n.GetNotificationOutputs).ToNot(gomega.BeEmpty())
+
+	output := n.GetNotificationOutputs[0]
+	n.GetNotificationOutputs = n.GetNotificationOutputs[1:]
+	return output.Notification, output.Error
+}
+
+func (n *NotificationAccessor) UpdateNotification(ctx context.Context, id string, update *notification.NotificationUpdate) (*notification.Notification, error) {
+	n.UpdateNotificationInvocations++
+
+	n.UpdateNotificationInputs = append(n.UpdateNotificationInputs, UpdateNotificationInput{Context: ctx, ID: id, Update: update})
+
+	gomega.Expect(n.UpdateNotificationOutputs).ToNot(gomega.BeEmpty())
+
+	output := n.UpdateNotificationOutputs[0]
+	n.UpdateNotificationOutputs = n.UpdateNotificationOutputs[1:]
+	return output.Notification, output.Error
+}
+
+func (n *NotificationAccessor) DeleteUserNotifications(ctx context.Context, userID string) error {
+	n.DeleteUserNotificationsInvocations++
+
+	n.DeleteUserNotificationsInputs = append(n.DeleteUserNotificationsInputs, DeleteUserNotificationsInput{Context: ctx, UserID: userID})
+
+	gomega.Expect(n.DeleteUserNotificationsOutputs).ToNot(gomega.BeEmpty())
+
+	output := n.DeleteUserNotificationsOutputs[0]
+	n.DeleteUserNotificationsOutputs = n.DeleteUserNotificationsOutputs[1:]
+	return output
+}
+
+func (n *NotificationAccessor) Expectations() {
+	n.Mock.Expectations()
+	gomega.Expect(n.ListUserNotificationsOutputs).To(gomega.BeEmpty())
+	gomega.Expect(n.CreateUserNotificationOutputs).To(gomega.BeEmpty())
+	gomega.Expect(n.GetNotificationOutputs).To(gomega.BeEmpty())
+	gomega.Expect(n.UpdateNotificationOutputs).To(gomega.BeEmpty())
+	gomega.Expect(n.DeleteUserNotificationsOutputs).To(gomega.BeEmpty())
+}
@@ -0,0 +1,98 @@
+package test
+
+import (
+	"context"
+
+	"github.com/onsi/gomega"
+
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/test"
+)
+
+type GetUserPreferencesInput struct {
+	Context context.Context
+	UserID  string
+}
+
+type GetUserPreferencesOutput struct {
+	Preferences *notification.Preferences
+	Error       error
+}
+
+type UpdateUserPreferencesInput struct {
+	Context context.Context
+	UserID  string
+	Update  *notification.PreferencesUpdate
+}
+
+type UpdateUserPreferencesOutput struct {
+	Preferences *notification.Preferences
+	Error       error
+}
+
+type DeleteUserPreferencesInput struct {
+	Context context.Context
+	UserID  string
+}
+
+type PreferencesAccessor struct {
+	*test.Mock
+	GetUserPreferencesInvocations    int
+	GetUserPreferencesInputs         []GetUserPreferencesInput
+	GetUserPreferencesOutputs        []GetUserPreferencesOutput
+	UpdateUserPreferencesInvocations int
+	UpdateUserPreferencesInputs      []UpdateUserPreferencesInput
+	UpdateUserPreferencesOutputs     []UpdateUserPreferencesOutput
+	DeleteUserPreferencesInvocations int
+	DeleteUserPreferencesInputs      []DeleteUserPreferencesInput
+	DeleteUserPreferencesOutputs     []error
+}
+
+func NewPreferencesAccessor() *PreferencesAccessor {
+	return &PreferencesAccessor{
+		Mock: test.NewMock(),
+	}
+}
+
+func (p *PreferencesAccessor) GetUserPreferences(ctx context.Context, userID string) (*notification.Preferences, error) {
+	p.GetUserPreferencesInvocations++
+
+	p.GetUserPreferencesInputs = append(p.GetUserPreferencesInputs, GetUserPreferencesInput{Context: ctx, UserID: userID})
+
+	gomega.Expect(p.GetUserPreferencesOutputs).ToNot(gomega.BeEmpty())
+
+	output := p.GetUserPreferencesOutputs[0]
+	p.GetUserPreferencesOutputs = p.GetUserPreferencesOutputs[1:]
+	return output.Preferences, output.Error
+}
+
+func (p *PreferencesAccessor) UpdateUserPreferences(ctx context.Context, userID string, update *notification.PreferencesUpdate) (*notification.Preferences, error) {
+	p.UpdateUserPreferencesInvocations++
+
+	p.UpdateUserPreferencesInputs = append(p.UpdateUserPreferencesInputs, UpdateUserPreferencesInput{Context: ctx, UserID: userID, Update: update})
+
+	gomega.Expect(p.UpdateUserPreferencesOutputs).ToNot(gomega.BeEmpty())
+
+	output := p.UpdateUserPreferencesOutputs[0]
+	p.UpdateUserPreferencesOutputs = p.UpdateUserPreferencesOutputs[1:]
+	return output.Preferences, output.Error
+}
+
+func (p *PreferencesAccessor) DeleteUserPreferences(ctx context.Context, userID string) error {
+	p.DeleteUserPreferencesInvocations++
+
+	p.DeleteUserPreferencesInputs = append(p.DeleteUserPreferencesInputs, DeleteUserPreferencesInput{Context: ctx, UserID: userID})
+
+	gomega.Expect(p.DeleteUserPreferencesOutputs).ToNot(gomega.BeEmpty())
+
+	output := p.DeleteUserPreferencesOutputs[0]
+	p.DeleteUserPreferencesOutputs = p.DeleteUserPreferencesOutputs[1:]
+	return output
+}
+
+func (p *PreferencesAccessor) Expectations() {
+	p.Mock.Expectations()
+	gomega.Expect(p.GetUserPreferencesOutputs).To(gomega.BeEmpty())
+	gomega.Expect(p.UpdateUserPreferencesOutputs).To(gomega.BeEmpty())
+	gomega.Expect(p.DeleteUserPreferencesOutputs).To(gomega.BeEmpty())
+}
@@ -0,0 +1,134 @@
+package fetch
+
+import (
+	"context"
+	"time"
+
+	"github.com/tidepool-org/platform/data"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/oauth"
+	"github.com/tidepool-org/platform/provider"
+)
+
+// Record is a single record fetched from a provider that is translated into a datum.
+type Record interface {
+	Time() time.Time
+}
+
+// Client fetches all records for the specified time range from a provider. The token source must be
+// used to authenticate all requests to the provider.
+type Client interface {
+	FetchRecords(ctx context.Context, startTime time.Time, endTime time.Time, tokenSource oauth.TokenSource) ([]Record, error)
+}
+
+// TranslateFunc translates a record fetched from a provider into a datum. It may return a nil datum
+// if the record should be ignored.
+type TranslateFunc func(record Record) (data.Datum, error)
+
+// Fetcher fetches and translates all data for the specified time range from a provider. A fetcher is
+// used in place of a client and translate function by providers that fetch from multiple endpoints or
+// that derive the data set device details from the data fetched.
+type Fetcher interface {
+	Fetch(ctx context.Context, session Session, startTime time.Time, endTime time.Time) (*Result, error)
+}
+
+// Session is the state of a fetch task available to a fetcher. The token source must be used to
+// authenticate all requests to the provider and the provider session must be updated after each
+// request. The data set is the existing data set of the data source, if any.
+type Session interface {
+	TokenSource() oauth.TokenSource
+	UpdateProviderSession() error
+	DataSource() *data.DataSource
+	DataSet() (*data.DataSet, error)
+}
+
+// Result is the data fetched for a time range. The device details, if specified, are used when
+// creating the data set or to update the existing data set.
+type Result struct {
+	Data               []*Datum
+	DeviceID           *string
+	DeviceModel        *string
+	DeviceSerialNumber *string
+}
+
+// Datum is a translated datum and the time of the provider record it was translated from.
+type Datum struct {
+	Time  time.Time
+	Datum data.Datum
+}
+
+// Definition is everything the generic fetch runner requires of a specific provider. Either a client
+// and translate function or a fetcher is required. The maintenance windows and backfill fetch duration
+// are optional; backfill is disabled if the backfill fetch duration is not specified.
+type Definition struct {
+	ProviderName          string
+	TaskType              string
+	DataSetClientName     string
+	DataSetClientVersion  string
+	DeviceManufacturers   []string
+	DeviceModel           string
+	DeviceTags            []string
+	InitialDataTime       time.Time
+	FetchDuration         time.Duration
+	BackfillFetchDuration time.Duration
+	MaintenanceWindows    provider.MaintenanceWindows
+	Client                Client
+	Translate             TranslateFunc
+	Fetcher               Fetcher
+}
+
+func (d *Definition) Validate() error {
+	if d.ProviderName == "" {
+		return errors.New("provider name is missing")
+	}
+	if d.TaskType == "" {
+		return errors.New("task type is missing")
+	}
+	if d.DataSetClientName == "" {
+		return errors.New("data set client name is missing")
+	}
+	if d.DataSetClientVersion == "" {
+		return errors.New("data set client version is missing")
+	}
+	if len(d.DeviceManufacturers) == 0 {
+		return errors.New("device manufacturers is missing")
+	}
+	if d.DeviceModel == "" {
+		return errors.New("device model is missing")
+	}
+	if len(d.DeviceTags) == 0 {
+		return errors.New("device tags is missing")
+	}
+	for _, deviceTag := range d.DeviceTags {
+		if !containsString(data.DeviceTags(), deviceTag) {
+			return errors.New("device tags is invalid")
+		}
+	}
+	if d.InitialDataTime.IsZero() {
+		return errors.New("initial data time is missing")
+	}
+	if d.FetchDuration <= 0 {
+		return errors.New("fetch duration is invalid")
+	}
+	if d.BackfillFetchDuration < 0 {
+		return errors.New("backfill fetch duration is invalid")
+	}
+	if d.Fetcher == nil {
+		if d.Client == nil {
+			return errors.New("client is missing")
+		}
+		if d.Translate == nil {
+			return errors.New("translate is missing")
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
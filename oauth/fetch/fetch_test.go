@@ -0,0 +1,126 @@
+package fetch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/tidepool-org/platform/data"
+	"github.com/tidepool-org/platform/data/types/blood/glucose/continuous"
+	"github.com/tidepool-org/platform/oauth"
+	oauthFetch "github.com/tidepool-org/platform/oauth/fetch"
+	"github.com/tidepool-org/platform/pointer"
+)
+
+type record struct {
+	time  time.Time
+	value float64
+}
+
+func (r *record) Time() time.Time {
+	return r.time
+}
+
+type client struct {
+	FetchRecordsInputs []fetchRecordsInput
+	FetchRecordsStub   func(ctx context.Context, startTime time.Time, endTime time.Time, tokenSource oauth.TokenSource) ([]oauthFetch.Record, error)
+}
+
+type fetchRecordsInput struct {
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+func (c *client) FetchRecords(ctx context.Context, startTime time.Time, endTime time.Time, tokenSource oauth.TokenSource) ([]oauthFetch.Record, error) {
+	c.FetchRecordsInputs = append(c.FetchRecordsInputs, fetchRecordsInput{StartTime: startTime, EndTime: endTime})
+	if _, err := tokenSource.HTTPClient(ctx, &tokenSourceSource{}); err != nil {
+		return nil, err
+	}
+	if c.FetchRecordsStub != nil {
+		return c.FetchRecordsStub(ctx, startTime, endTime, tokenSource)
+	}
+	return nil, nil
+}
+
+type fetcher struct {
+	FetchStub func(ctx context.Context, session oauthFetch.Session, startTime time.Time, endTime time.Time) (*oauthFetch.Result, error)
+}
+
+func (f *fetcher) Fetch(ctx context.Context, session oauthFetch.Session, startTime time.Time, endTime time.Time) (*oauthFetch.Result, error) {
+	if f.FetchStub != nil {
+		return f.FetchStub(ctx, session, startTime, endTime)
+	}
+	return nil, nil
+}
+
+type tokenSourceSource struct{}
+
+func (t *tokenSourceSource) TokenSource(ctx context.Context, tkn *oauth.Token) (oauth2.TokenSource, error) {
+	return oauth2.StaticTokenSource(tkn.RawToken()), nil
+}
+
+func translate(rcrd oauthFetch.Record) (data.Datum, error) {
+	datum := continuous.New()
+	datum.Value = pointer.FromFloat64(rcrd.(*record).value)
+	return datum, nil
+}
+
+func newDefinition() *oauthFetch.Definition {
+	return &oauthFetch.Definition{
+		ProviderName:         "test",
+		TaskType:             "org.tidepool.oauth.test.fetch",
+		DataSetClientName:    "org.tidepool.oauth.test.fetch",
+		DataSetClientVersion: "1.0.0",
+		DeviceManufacturers:  []string{"Test"},
+		DeviceModel:          "TestCGM",
+		DeviceTags:           []string{data.DeviceTagCGM},
+		InitialDataTime:      time.Now().Add(-30 * 24 * time.Hour),
+		FetchDuration:        7 * 24 * time.Hour,
+		Client:               &client{},
+		Translate:            translate,
+	}
+}
+
+var _ = Describe("Fetch", func() {
+	Context("Definition", func() {
+		Context("Validate", func() {
+			DescribeTable("returns an error when",
+				func(mutator func(definition *oauthFetch.Definition), expectedError string) {
+					definition := newDefinition()
+					mutator(definition)
+					Expect(definition.Validate()).To(MatchError(expectedError))
+				},
+				Entry("provider name is missing", func(definition *oauthFetch.Definition) { definition.ProviderName = "" }, "provider name is missing"),
+				Entry("task type is missing", func(definition *oauthFetch.Definition) { definition.TaskType = "" }, "task type is missing"),
+				Entry("data set client name is missing", func(definition *oauthFetch.Definition) { definition.DataSetClientName = "" }, "data set client name is missing"),
+				Entry("data set client version is missing", func(definition *oauthFetch.Definition) { definition.DataSetClientVersion = "" }, "data set client version is missing"),
+				Entry("device manufacturers is missing", func(definition *oauthFetch.Definition) { definition.DeviceManufacturers = nil }, "device manufacturers is missing"),
+				Entry("device model is missing", func(definition *oauthFetch.Definition) { definition.DeviceModel = "" }, "device model is missing"),
+				Entry("device tags is missing", func(definition *oauthFetch.Definition) { definition.DeviceTags = nil }, "device tags is missing"),
+				Entry("device tags is invalid", func(definition *oauthFetch.Definition) { definition.DeviceTags = []string{"invalid"} }, "device tags is invalid"),
+				Entry("initial data time is missing", func(definition *oauthFetch.Definition) { definition.InitialDataTime = time.Time{} }, "initial data time is missing"),
+				Entry("fetch duration is invalid", func(definition *oauthFetch.Definition) { definition.FetchDuration = 0 }, "fetch duration is invalid"),
+				Entry("client is missing", func(definition *oauthFetch.Definition) { definition.Client = nil }, "client is missing"),
+				Entry("translate is missing", func(definition *oauthFetch.Definition) { definition.Translate = nil }, "translate is missing"),
+				Entry("backfill fetch duration is invalid", func(definition *oauthFetch.Definition) { definition.BackfillFetchDuration = -1 }, "backfill fetch duration is invalid"),
+			)
+
+			It("returns successfully", func() {
+				Expect(newDefinition().Validate()).To(Succeed())
+			})
+
+			It("returns successfully with a fetcher in place of a client and translate", func() {
+				definition := newDefinition()
+				definition.Client = nil
+				definition.Translate = nil
+				definition.Fetcher = &fetcher{}
+				Expect(definition.Validate()).To(Succeed())
+			})
+		})
+	})
+})
@@ -0,0 +1,179 @@
+package fetch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	configTest "github.com/tidepool-org/platform/config/test"
+	"github.com/tidepool-org/platform/data"
+	dataClientTest "github.com/tidepool-org/platform/data/client/test"
+	dataTest "github.com/tidepool-org/platform/data/test"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/log"
+	logNull "github.com/tidepool-org/platform/log/null"
+	oauthFetch "github.com/tidepool-org/platform/oauth/fetch"
+	oauthProvider "github.com/tidepool-org/platform/oauth/provider"
+	"github.com/tidepool-org/platform/task"
+	taskTest "github.com/tidepool-org/platform/task/test"
+	"github.com/tidepool-org/platform/test"
+)
+
+var _ = Describe("Provider", func() {
+	var name string
+	var taskType string
+	var configReporter *configTest.Reporter
+	var dataClient *dataClientTest.Client
+	var taskClient *taskTest.Client
+
+	BeforeEach(func() {
+		name = "test"
+		taskType = "org.tidepool.oauth.test.fetch"
+		configReporter = configTest.NewReporter()
+		configReporter.Config[name] = map[string]interface{}{
+			"client_id":     test.RandomString(),
+			"client_secret": test.RandomString(),
+			"authorize_url": "https://test.org/authorize",
+			"token_url":     "https://test.org/token",
+			"redirect_url":  "https://tidepool.org/redirect",
+			"state_salt":    test.RandomString(),
+		}
+		dataClient = dataClientTest.NewClient()
+		taskClient = taskTest.NewClient()
+	})
+
+	AfterEach(func() {
+		taskClient.Expectations()
+		dataClient.AssertOutputsEmpty()
+	})
+
+	Context("NewProvider", func() {
+		It("returns an error if the name is missing", func() {
+			prvdr, err := oauthFetch.NewProvider("", taskType, configReporter, dataClient, taskClient)
+			Expect(err).To(MatchError("name is missing"))
+			Expect(prvdr).To(BeNil())
+		})
+
+		It("returns an error if the task type is missing", func() {
+			prvdr, err := oauthFetch.NewProvider(name, "", configReporter, dataClient, taskClient)
+			Expect(err).To(MatchError("task type is missing"))
+			Expect(prvdr).To(BeNil())
+		})
+
+		It("returns an error if the config reporter is missing", func() {
+			prvdr, err := oauthFetch.NewProvider(name, taskType, nil, dataClient, taskClient)
+			Expect(err).To(MatchError("config reporter is missing"))
+			Expect(prvdr).To(BeNil())
+		})
+
+		It("returns an error if the data client is missing", func() {
+			prvdr, err := oauthFetch.NewProvider(name, taskType, configReporter, nil, taskClient)
+			Expect(err).To(MatchError("data client is missing"))
+			Expect(prvdr).To(BeNil())
+		})
+
+		It("returns an error if the task client is missing", func() {
+			prvdr, err := oauthFetch.NewProvider(name, taskType, configReporter, dataClient, nil)
+			Expect(err).To(MatchError("task client is missing"))
+			Expect(prvdr).To(BeNil())
+		})
+
+		It("returns an error if the provider is not configured", func() {
+			delete(configReporter.Config, name)
+			prvdr, err := oauthFetch.NewProvider(name, taskType, configReporter, dataClient, taskClient)
+			Expect(err).To(MatchError("client id is missing"))
+			Expect(prvdr).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			prvdr, err := oauthFetch.NewProvider(name, taskType, configReporter, dataClient, taskClient)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(prvdr).ToNot(BeNil())
+			Expect(prvdr.Type()).To(Equal(oauthProvider.ProviderType))
+			Expect(prvdr.Name()).To(Equal(name))
+			Expect(prvdr.TaskType()).To(Equal(taskType))
+		})
+	})
+
+	Context("with new provider", func() {
+		var ctx context.Context
+		var userID string
+		var providerSessionID string
+		var prvdr *oauthFetch.Provider
+
+		BeforeEach(func() {
+			var err error
+			ctx = log.NewContextWithLogger(context.Background(), logNull.NewLogger())
+			userID = test.RandomString()
+			providerSessionID = test.RandomString()
+			prvdr, err = oauthFetch.NewProvider(name, taskType, configReporter, dataClient, taskClient)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("OnCreate", func() {
+			It("returns an error if the user id is missing", func() {
+				Expect(prvdr.OnCreate(ctx, "", providerSessionID)).To(MatchError("user id is missing"))
+			})
+
+			It("returns an error if the provider session id is missing", func() {
+				Expect(prvdr.OnCreate(ctx, userID, "")).To(MatchError("provider session id is missing"))
+			})
+
+			It("returns an error if list user data sources returns an error", func() {
+				dataClient.ListUserDataSourcesOutputs = []dataTest.ListUserDataSourcesOutput{{DataSources: nil, Error: errorsTest.NewError()}}
+				Expect(prvdr.OnCreate(ctx, userID, providerSessionID)).To(MatchError(HavePrefix("unable to fetch data sources")))
+			})
+
+			It("creates a data source and task", func() {
+				dataSource := &data.DataSource{ID: test.RandomString()}
+				dataClient.ListUserDataSourcesOutputs = []dataTest.ListUserDataSourcesOutput{{DataSources: data.DataSources{}, Error: nil}}
+				dataClient.CreateUserDataSourceOutputs = []dataTest.CreateUserDataSourceOutput{{DataSource: dataSource, Error: nil}}
+				taskClient.CreateTaskOutputs = []taskTest.CreateTaskOutput{{Task: &task.Task{}, Error: nil}}
+				Expect(prvdr.OnCreate(ctx, userID, providerSessionID)).To(Succeed())
+				Expect(dataClient.CreateUserDataSourceInputs).To(HaveLen(1))
+				Expect(dataClient.CreateUserDataSourceInputs[0].Create.ProviderName).To(Equal(name))
+				Expect(dataClient.CreateUserDataSourceInputs[0].Create.ProviderSessionID).To(Equal(providerSessionID))
+				Expect(dataClient.CreateUserDataSourceInputs[0].Create.State).To(Equal(data.DataSourceStateConnected))
+				Expect(taskClient.CreateTaskInputs).To(HaveLen(1))
+				Expect(taskClient.CreateTaskInputs[0].Create.Type).To(Equal(taskType))
+				Expect(taskClient.CreateTaskInputs[0].Create.Data).To(Equal(map[string]interface{}{"providerSessionId": providerSessionID, "dataSourceId": dataSource.ID}))
+			})
+
+			It("reconnects an existing data source and deletes it if the task cannot be created", func() {
+				dataSource := &data.DataSource{ID: test.RandomString(), State: data.DataSourceStateDisconnected}
+				dataClient.ListUserDataSourcesOutputs = []dataTest.ListUserDataSourcesOutput{{DataSources: data.DataSources{dataSource}, Error: nil}}
+				dataClient.UpdateDataSourceOutputs = []dataTest.UpdateDataSourceOutput{{DataSource: dataSource, Error: nil}}
+				dataClient.DeleteDataSourceOutputs = []error{nil}
+				taskClient.CreateTaskOutputs = []taskTest.CreateTaskOutput{{Task: nil, Error: errorsTest.NewError()}}
+				Expect(prvdr.OnCreate(ctx, userID, providerSessionID)).To(MatchError(HavePrefix("unable to create task")))
+				Expect(*dataClient.UpdateDataSourceInputs[0].Update.State).To(Equal(data.DataSourceStateConnected))
+				Expect(dataClient.DeleteDataSourceInputs).To(HaveLen(1))
+				Expect(dataClient.DeleteDataSourceInputs[0].ID).To(Equal(dataSource.ID))
+			})
+		})
+
+		Context("OnDelete", func() {
+			It("returns an error if the user id is missing", func() {
+				Expect(prvdr.OnDelete(ctx, "", providerSessionID)).To(MatchError("user id is missing"))
+			})
+
+			It("returns an error if the provider session id is missing", func() {
+				Expect(prvdr.OnDelete(ctx, userID, "")).To(MatchError("provider session id is missing"))
+			})
+
+			It("deletes the task and disconnects the data source", func() {
+				dataSourceID := test.RandomString()
+				tsk := &task.Task{ID: test.RandomString(), Data: map[string]interface{}{"dataSourceId": dataSourceID}}
+				taskClient.ListTasksOutputs = []taskTest.ListTasksOutput{{Tasks: task.Tasks{tsk}, Error: nil}}
+				taskClient.DeleteTaskOutputs = []error{nil}
+				dataClient.UpdateDataSourceOutputs = []dataTest.UpdateDataSourceOutput{{DataSource: &data.DataSource{ID: dataSourceID}, Error: nil}}
+				Expect(prvdr.OnDelete(ctx, userID, providerSessionID)).To(Succeed())
+				Expect(*taskClient.ListTasksInputs[0].Filter.Name).To(Equal(oauthFetch.TaskName(taskType, providerSessionID)))
+				Expect(taskClient.DeleteTaskInputs[0].ID).To(Equal(tsk.ID))
+				Expect(dataClient.UpdateDataSourceInputs[0].ID).To(Equal(dataSourceID))
+				Expect(*dataClient.UpdateDataSourceInputs[0].Update.State).To(Equal(data.DataSourceStateDisconnected))
+			})
+		})
+	})
+})
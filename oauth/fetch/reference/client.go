@@ -0,0 +1,67 @@
+package reference
+
+import (
+	"context"
+	"time"
+
+	"github.com/tidepool-org/platform/client"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/oauth"
+	oauthClient "github.com/tidepool-org/platform/oauth/client"
+	oauthFetch "github.com/tidepool-org/platform/oauth/fetch"
+	"github.com/tidepool-org/platform/request"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
+type Client struct {
+	client *oauthClient.Client
+}
+
+func NewClient(cfg *client.Config, tknSrcSrc oauth.TokenSourceSource) (*Client, error) {
+	clnt, err := oauthClient.New(cfg, tknSrcSrc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		client: clnt,
+	}, nil
+}
+
+func (c *Client) FetchRecords(ctx context.Context, startTime time.Time, endTime time.Time, tokenSource oauth.TokenSource) ([]oauthFetch.Record, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if tokenSource == nil {
+		return nil, errors.New("token source is missing")
+	}
+
+	url := c.client.AppendURLQuery(c.client.ConstructURL("v1", "users", "self", "readings"), map[string]string{
+		"start": startTime.UTC().Format(TimeFormat),
+		"end":   endTime.UTC().Format(TimeFormat),
+	})
+
+	readingsResponse := &ReadingsResponse{}
+	err := c.client.SendOAuthRequest(ctx, "GET", url, nil, nil, readingsResponse, nil, tokenSource)
+	if oauth.IsAccessTokenError(err) {
+		tokenSource.ExpireToken()
+		readingsResponse = &ReadingsResponse{}
+		err = c.client.SendOAuthRequest(ctx, "GET", url, nil, nil, readingsResponse, nil, tokenSource)
+	}
+	if oauth.IsRefreshTokenError(err) {
+		err = errors.Wrap(request.ErrorUnauthenticated(), err.Error())
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get readings")
+	}
+
+	if err = structureValidator.New().Validate(readingsResponse); err != nil {
+		return nil, errors.Wrap(err, "readings response is invalid")
+	}
+
+	records := make([]oauthFetch.Record, len(readingsResponse.Readings))
+	for index, reading := range readingsResponse.Readings {
+		records[index] = reading
+	}
+	return records, nil
+}
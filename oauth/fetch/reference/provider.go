@@ -0,0 +1,29 @@
+package reference
+
+import (
+	"github.com/tidepool-org/platform/config"
+	"github.com/tidepool-org/platform/data"
+	dataClient "github.com/tidepool-org/platform/data/client"
+	oauthFetch "github.com/tidepool-org/platform/oauth/fetch"
+	"github.com/tidepool-org/platform/task"
+)
+
+func NewProvider(configReporter config.Reporter, dataClient dataClient.Client, taskClient task.Client) (*oauthFetch.Provider, error) {
+	return oauthFetch.NewProvider(ProviderName, TaskType, configReporter, dataClient, taskClient)
+}
+
+func NewDefinition(client oauthFetch.Client) *oauthFetch.Definition {
+	return &oauthFetch.Definition{
+		ProviderName:         ProviderName,
+		TaskType:             TaskType,
+		DataSetClientName:    DataSetClientName,
+		DataSetClientVersion: DataSetClientVersion,
+		DeviceManufacturers:  []string{DeviceManufacturer},
+		DeviceModel:          DeviceModel,
+		DeviceTags:           []string{data.DeviceTagCGM},
+		InitialDataTime:      InitialDataTime,
+		FetchDuration:        FetchDuration,
+		Client:               client,
+		Translate:            Translate,
+	}
+}
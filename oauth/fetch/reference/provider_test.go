@@ -0,0 +1,33 @@
+package reference_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"time"
+
+	"github.com/tidepool-org/platform/oauth"
+	oauthFetch "github.com/tidepool-org/platform/oauth/fetch"
+	"github.com/tidepool-org/platform/oauth/fetch/reference"
+)
+
+type client struct{}
+
+func (c *client) FetchRecords(ctx context.Context, startTime time.Time, endTime time.Time, tokenSource oauth.TokenSource) ([]oauthFetch.Record, error) {
+	return nil, nil
+}
+
+var _ = Describe("Provider", func() {
+	Context("NewDefinition", func() {
+		It("returns a valid definition", func() {
+			clnt := &client{}
+			definition := reference.NewDefinition(clnt)
+			Expect(definition).ToNot(BeNil())
+			Expect(definition.Validate()).ToNot(HaveOccurred())
+			Expect(definition.ProviderName).To(Equal(reference.ProviderName))
+			Expect(definition.TaskType).To(Equal(reference.TaskType))
+			Expect(definition.Client).To(Equal(clnt))
+		})
+	})
+})
@@ -0,0 +1,68 @@
+// Package reference is a reference implementation of a provider using the generic OAuth fetch
+// framework. It fetches continuous glucose readings from a minimal provider API and serves as a
+// template for adding additional CGM and pump clouds. A new provider requires only a client that
+// fetches records and a function that translates each record into a datum.
+package reference
+
+import (
+	"strconv"
+	"time"
+
+	dataBloodGlucose "github.com/tidepool-org/platform/data/blood/glucose"
+	"github.com/tidepool-org/platform/structure"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
+const (
+	ProviderName = "reference"
+	TaskType     = "org.tidepool.oauth.reference.fetch"
+
+	DataSetClientName    = TaskType
+	DataSetClientVersion = "1.0.0"
+
+	DeviceManufacturer = "Reference"
+	DeviceModel        = "ReferenceCGM"
+	DeviceIDPrefix     = "RefCGM_"
+	DeviceIDUnknown    = "RefCGM_Unknown"
+
+	TimeFormat = time.RFC3339Nano
+)
+
+var InitialDataTime = time.Unix(1420070400, 0) // 2015-01-01T00:00:00Z
+
+const FetchDuration = 30 * 24 * time.Hour
+
+type ReadingsResponse struct {
+	Readings []*Reading `json:"readings,omitempty"`
+}
+
+func (r *ReadingsResponse) Validate(validator structure.Validator) {
+	validator = validator.WithReference("readings")
+	for index, reading := range r.Readings {
+		if readingValidator := validator.WithReference(strconv.Itoa(index)); reading != nil {
+			reading.Validate(readingValidator)
+		} else {
+			readingValidator.ReportError(structureValidator.ErrorValueNotExists())
+		}
+	}
+}
+
+type Reading struct {
+	ID                 string    `json:"id,omitempty"`
+	Timestamp          time.Time `json:"timestamp,omitempty"`
+	Units              string    `json:"units,omitempty"`
+	Value              float64   `json:"value,omitempty"`
+	DeviceSerialNumber *string   `json:"deviceSerialNumber,omitempty"`
+}
+
+func (r *Reading) Time() time.Time {
+	return r.Timestamp
+}
+
+func (r *Reading) Validate(validator structure.Validator) {
+	validator.String("id", &r.ID).NotEmpty()
+	validator.Time("timestamp", &r.Timestamp).NotZero()
+	validator.String("units", &r.Units).OneOf(dataBloodGlucose.MgdL, dataBloodGlucose.MmolL)
+	validator.Float64("value", &r.Value).InRange(dataBloodGlucose.ValueRangeForUnits(&r.Units))
+	validator.String("deviceSerialNumber", r.DeviceSerialNumber).NotEmpty()
+}
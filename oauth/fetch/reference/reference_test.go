@@ -0,0 +1,71 @@
+package reference_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"time"
+
+	dataBloodGlucose "github.com/tidepool-org/platform/data/blood/glucose"
+	"github.com/tidepool-org/platform/oauth/fetch/reference"
+	"github.com/tidepool-org/platform/pointer"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+	"github.com/tidepool-org/platform/test"
+)
+
+func newReading() *reference.Reading {
+	return &reference.Reading{
+		ID:                 test.RandomString(),
+		Timestamp:          time.Now().Add(-time.Hour).Truncate(time.Second).UTC(),
+		Units:              dataBloodGlucose.MgdL,
+		Value:              120,
+		DeviceSerialNumber: pointer.FromString(test.RandomString()),
+	}
+}
+
+var _ = Describe("Reference", func() {
+	Context("Reading", func() {
+		It("returns the timestamp as the time", func() {
+			reading := newReading()
+			Expect(reading.Time()).To(Equal(reading.Timestamp))
+		})
+
+		It("is valid", func() {
+			Expect(structureValidator.New().Validate(newReading())).ToNot(HaveOccurred())
+		})
+
+		It("is invalid if the id is missing", func() {
+			reading := newReading()
+			reading.ID = ""
+			Expect(structureValidator.New().Validate(reading)).To(HaveOccurred())
+		})
+
+		It("is invalid if the timestamp is missing", func() {
+			reading := newReading()
+			reading.Timestamp = time.Time{}
+			Expect(structureValidator.New().Validate(reading)).To(HaveOccurred())
+		})
+
+		It("is invalid if the units are invalid", func() {
+			reading := newReading()
+			reading.Units = "invalid"
+			Expect(structureValidator.New().Validate(reading)).To(HaveOccurred())
+		})
+
+		It("is invalid if the value is out of range", func() {
+			reading := newReading()
+			reading.Value = 1001
+			Expect(structureValidator.New().Validate(reading)).To(HaveOccurred())
+		})
+	})
+
+	Context("ReadingsResponse", func() {
+		It("is valid", func() {
+			Expect(structureValidator.New().Validate(&reference.ReadingsResponse{Readings: []*reference.Reading{newReading(), newReading()}})).ToNot(HaveOccurred())
+		})
+
+		It("is invalid if a reading is missing", func() {
+			Expect(structureValidator.New().Validate(&reference.ReadingsResponse{Readings: []*reference.Reading{newReading(), nil}})).To(HaveOccurred())
+		})
+	})
+})
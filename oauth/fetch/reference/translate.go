@@ -0,0 +1,35 @@
+package reference
+
+import (
+	"github.com/tidepool-org/platform/data"
+	"github.com/tidepool-org/platform/data/types"
+	"github.com/tidepool-org/platform/data/types/blood/glucose/continuous"
+	"github.com/tidepool-org/platform/errors"
+	oauthFetch "github.com/tidepool-org/platform/oauth/fetch"
+	"github.com/tidepool-org/platform/pointer"
+)
+
+func Translate(record oauthFetch.Record) (data.Datum, error) {
+	reading, ok := record.(*Reading)
+	if !ok || reading == nil {
+		return nil, errors.New("record is not a reading")
+	}
+
+	datum := continuous.New()
+
+	// TODO: Refactor so we don't have to clear these here
+	datum.ID = nil
+	datum.GUID = nil
+
+	datum.Time = pointer.FromString(reading.Timestamp.UTC().Format(types.TimeFormat))
+	datum.Units = pointer.FromString(reading.Units)
+	datum.Value = pointer.FromFloat64(reading.Value)
+	if reading.DeviceSerialNumber != nil {
+		datum.DeviceID = pointer.FromString(DeviceIDPrefix + *reading.DeviceSerialNumber)
+	} else {
+		datum.DeviceID = pointer.FromString(DeviceIDUnknown)
+	}
+	datum.Payload = &data.Blob{"readingId": reading.ID}
+
+	return datum, nil
+}
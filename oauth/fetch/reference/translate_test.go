@@ -0,0 +1,49 @@
+package reference_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"time"
+
+	"github.com/tidepool-org/platform/data"
+	"github.com/tidepool-org/platform/data/types"
+	"github.com/tidepool-org/platform/data/types/blood/glucose/continuous"
+	"github.com/tidepool-org/platform/oauth/fetch/reference"
+)
+
+type record struct{}
+
+func (r *record) Time() time.Time {
+	return time.Now()
+}
+
+var _ = Describe("Translate", func() {
+	It("returns an error if the record is not a reading", func() {
+		datum, err := reference.Translate(&record{})
+		Expect(err).To(MatchError("record is not a reading"))
+		Expect(datum).To(BeNil())
+	})
+
+	It("returns a continuous datum", func() {
+		reading := newReading()
+		datum, err := reference.Translate(reading)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(datum).ToNot(BeNil())
+		continuousDatum, ok := datum.(*continuous.Continuous)
+		Expect(ok).To(BeTrue())
+		Expect(*continuousDatum.Time).To(Equal(reading.Timestamp.Format(types.TimeFormat)))
+		Expect(*continuousDatum.Units).To(Equal(reading.Units))
+		Expect(*continuousDatum.Value).To(Equal(reading.Value))
+		Expect(*continuousDatum.DeviceID).To(Equal(reference.DeviceIDPrefix + *reading.DeviceSerialNumber))
+		Expect(continuousDatum.Payload).To(Equal(&data.Blob{"readingId": reading.ID}))
+	})
+
+	It("returns a continuous datum with an unknown device id if the device serial number is missing", func() {
+		reading := newReading()
+		reading.DeviceSerialNumber = nil
+		datum, err := reference.Translate(reading)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*datum.(*continuous.Continuous).DeviceID).To(Equal(reference.DeviceIDUnknown))
+	})
+})
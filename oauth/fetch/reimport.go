@@ -0,0 +1,141 @@
+package fetch
+
+import (
+	"context"
+
+	"github.com/tidepool-org/platform/data"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/structure"
+	"github.com/tidepool-org/platform/task"
+)
+
+type Reimporter interface {
+	Reimport(ctx context.Context, dataSource *data.DataSource, reimport *Reimport) (*ReimportResult, error)
+}
+
+type Reimport struct {
+	DeleteDataSets bool `json:"deleteDataSets,omitempty"`
+	DryRun         bool `json:"dryRun,omitempty"`
+}
+
+func NewReimport() *Reimport {
+	return &Reimport{}
+}
+
+func (r *Reimport) Parse(parser structure.ObjectParser) {
+	if ptr := parser.Bool("deleteDataSets"); ptr != nil {
+		r.DeleteDataSets = *ptr
+	}
+	if ptr := parser.Bool("dryRun"); ptr != nil {
+		r.DryRun = *ptr
+	}
+}
+
+func (r *Reimport) Validate(validator structure.Validator) {}
+
+// ReimportResult reports the data source after reset, the data sets deleted, the fetch tasks
+// deleted, and the fetch task created; if a dry run, then it reports what would have changed
+type ReimportResult struct {
+	DryRun            bool             `json:"dryRun"`
+	DataSource        *data.DataSource `json:"dataSource"`
+	DeletedDataSetIDs []string         `json:"deletedDataSetIds"`
+	DeletedTaskIDs    []string         `json:"deletedTaskIds"`
+	TaskCreate        *task.TaskCreate `json:"taskCreate"`
+	Task              *task.Task       `json:"task,omitempty"`
+}
+
+// Reimport resets the data source so that it is fetched from scratch, optionally deletes the data
+// sets it created, and replaces the fetch task with one in backfill mode that is available now
+func (p *Provider) Reimport(ctx context.Context, dataSource *data.DataSource, reimport *Reimport) (*ReimportResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if dataSource == nil {
+		return nil, errors.New("data source is missing")
+	} else if dataSource.ProviderType != p.Type() || dataSource.ProviderName != p.Name() {
+		return nil, errors.New("data source provider is invalid")
+	} else if dataSource.ProviderSessionID == nil {
+		return nil, errors.New("data source is not connected")
+	}
+	if reimport == nil {
+		reimport = NewReimport()
+	}
+
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"dataSourceId": dataSource.ID, "deleteDataSets": reimport.DeleteDataSets, "dryRun": reimport.DryRun})
+
+	result := &ReimportResult{
+		DryRun:            reimport.DryRun,
+		DeletedDataSetIDs: []string{},
+		DeletedTaskIDs:    []string{},
+	}
+
+	taskFilter := task.NewTaskFilter()
+	taskFilter.Name = pointer.FromString(TaskName(p.taskType, *dataSource.ProviderSessionID))
+	tsks, err := p.taskClient.ListTasks(ctx, taskFilter, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list tasks")
+	}
+	for _, tsk := range tsks {
+		result.DeletedTaskIDs = append(result.DeletedTaskIDs, tsk.ID)
+	}
+
+	if reimport.DeleteDataSets {
+		for _, dataSetID := range dataSource.DataSetIDs {
+			if dataSet, dataSetErr := p.dataClient.GetDataSet(ctx, dataSetID); dataSetErr != nil {
+				return nil, errors.Wrap(dataSetErr, "unable to get data set")
+			} else if dataSet != nil && dataSet.DeletedTime == nil {
+				result.DeletedDataSetIDs = append(result.DeletedDataSetIDs, dataSetID)
+			}
+		}
+	}
+
+	result.TaskCreate, err = NewTaskCreate(p.taskType, *dataSource.ProviderSessionID, dataSource.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create task create")
+	}
+	result.TaskCreate.Data["mode"] = TaskModeBackfill
+
+	if reimport.DryRun {
+		resetDataSource := *dataSource
+		resetDataSource.Error = nil
+		resetDataSource.EarliestDataTime = nil
+		resetDataSource.LatestDataTime = nil
+		resetDataSource.LastImportTime = nil
+		resetDataSource.Backfill = nil
+		result.DataSource = &resetDataSource
+
+		logger.Info("Reimport dry run")
+		return result, nil
+	}
+
+	// Delete the fetch tasks first so that nothing updates the data source while it is reset
+	for _, tskID := range result.DeletedTaskIDs {
+		if err = p.taskClient.DeleteTask(ctx, tskID); err != nil {
+			return nil, errors.Wrap(err, "unable to delete task")
+		}
+	}
+	for _, dataSetID := range result.DeletedDataSetIDs {
+		if err = p.dataClient.DeleteDataSet(ctx, dataSetID); err != nil {
+			return nil, errors.Wrap(err, "unable to delete data set")
+		}
+	}
+
+	dataSourceUpdate := data.NewDataSourceUpdate()
+	dataSourceUpdate.Reset = pointer.FromBool(true)
+	result.DataSource, err = p.dataClient.UpdateDataSource(ctx, dataSource.ID, dataSourceUpdate)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to update data source")
+	} else if result.DataSource == nil {
+		return nil, errors.New("data source is missing")
+	}
+
+	result.Task, err = p.taskClient.CreateTask(ctx, result.TaskCreate)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create task")
+	}
+
+	logger.WithField("taskId", result.Task.ID).Info("Reimport started")
+	return result, nil
+}
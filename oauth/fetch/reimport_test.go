@@ -0,0 +1,181 @@
+package fetch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"time"
+
+	configTest "github.com/tidepool-org/platform/config/test"
+	"github.com/tidepool-org/platform/data"
+	dataClientTest "github.com/tidepool-org/platform/data/client/test"
+	dataTest "github.com/tidepool-org/platform/data/test"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/log"
+	logNull "github.com/tidepool-org/platform/log/null"
+	oauthFetch "github.com/tidepool-org/platform/oauth/fetch"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+	taskTest "github.com/tidepool-org/platform/task/test"
+	"github.com/tidepool-org/platform/test"
+)
+
+var _ = Describe("Reimport", func() {
+	var name string
+	var taskType string
+	var dataClient *dataClientTest.Client
+	var taskClient *taskTest.Client
+	var ctx context.Context
+	var prvdr *oauthFetch.Provider
+	var providerSessionID string
+	var dataSetIDs []string
+	var dataSource *data.DataSource
+	var tsk *task.Task
+
+	BeforeEach(func() {
+		var err error
+		name = "test"
+		taskType = "org.tidepool.oauth.test.fetch"
+		configReporter := configTest.NewReporter()
+		configReporter.Config[name] = map[string]interface{}{
+			"client_id":     test.RandomString(),
+			"client_secret": test.RandomString(),
+			"authorize_url": "https://test.org/authorize",
+			"token_url":     "https://test.org/token",
+			"redirect_url":  "https://tidepool.org/redirect",
+			"state_salt":    test.RandomString(),
+		}
+		dataClient = dataClientTest.NewClient()
+		taskClient = taskTest.NewClient()
+		ctx = log.NewContextWithLogger(context.Background(), logNull.NewLogger())
+		prvdr, err = oauthFetch.NewProvider(name, taskType, configReporter, dataClient, taskClient)
+		Expect(err).ToNot(HaveOccurred())
+		providerSessionID = test.RandomString()
+		dataSetIDs = []string{data.NewSetID(), data.NewSetID(), data.NewSetID()}
+		dataSource = &data.DataSource{
+			ID:                test.RandomString(),
+			ProviderType:      prvdr.Type(),
+			ProviderName:      prvdr.Name(),
+			ProviderSessionID: pointer.FromString(providerSessionID),
+			DataSetIDs:        dataSetIDs,
+			LatestDataTime:    pointer.FromTime(time.Now().Add(-time.Hour)),
+			LastImportTime:    pointer.FromTime(time.Now().Add(-time.Minute)),
+		}
+		tsk = &task.Task{ID: test.RandomString()}
+	})
+
+	AfterEach(func() {
+		taskClient.Expectations()
+		dataClient.AssertOutputsEmpty()
+	})
+
+	It("returns an error if the context is missing", func() {
+		result, err := prvdr.Reimport(nil, dataSource, nil)
+		Expect(err).To(MatchError("context is missing"))
+		Expect(result).To(BeNil())
+	})
+
+	It("returns an error if the data source is missing", func() {
+		result, err := prvdr.Reimport(ctx, nil, nil)
+		Expect(err).To(MatchError("data source is missing"))
+		Expect(result).To(BeNil())
+	})
+
+	It("returns an error if the data source is for another provider", func() {
+		dataSource.ProviderName = "other"
+		result, err := prvdr.Reimport(ctx, dataSource, nil)
+		Expect(err).To(MatchError("data source provider is invalid"))
+		Expect(result).To(BeNil())
+	})
+
+	It("returns an error if the data source is not connected", func() {
+		dataSource.ProviderSessionID = nil
+		result, err := prvdr.Reimport(ctx, dataSource, nil)
+		Expect(err).To(MatchError("data source is not connected"))
+		Expect(result).To(BeNil())
+	})
+
+	It("returns an error if list tasks returns an error", func() {
+		taskClient.ListTasksOutputs = []taskTest.ListTasksOutput{{Tasks: nil, Error: errorsTest.NewError()}}
+		result, err := prvdr.Reimport(ctx, dataSource, nil)
+		Expect(err).To(MatchError(HavePrefix("unable to list tasks")))
+		Expect(result).To(BeNil())
+	})
+
+	It("reports what would change without changing anything if a dry run", func() {
+		taskClient.ListTasksOutputs = []taskTest.ListTasksOutput{{Tasks: task.Tasks{tsk}, Error: nil}}
+		dataClient.GetDataSetOutputs = []dataTest.GetDataSetOutput{
+			{DataSet: &data.DataSet{UploadID: pointer.FromString(dataSetIDs[0])}, Error: nil},
+			{DataSet: &data.DataSet{UploadID: pointer.FromString(dataSetIDs[1]), DeletedTime: pointer.FromString(time.Now().Format(time.RFC3339))}, Error: nil},
+			{DataSet: nil, Error: nil},
+		}
+		result, err := prvdr.Reimport(ctx, dataSource, &oauthFetch.Reimport{DeleteDataSets: true, DryRun: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).ToNot(BeNil())
+		Expect(result.DryRun).To(BeTrue())
+		Expect(result.DataSource.ID).To(Equal(dataSource.ID))
+		Expect(result.DataSource.LatestDataTime).To(BeNil())
+		Expect(result.DataSource.LastImportTime).To(BeNil())
+		Expect(dataSource.LatestDataTime).ToNot(BeNil())
+		Expect(result.DeletedDataSetIDs).To(Equal([]string{dataSetIDs[0]}))
+		Expect(result.DeletedTaskIDs).To(Equal([]string{tsk.ID}))
+		Expect(result.TaskCreate.Data["mode"]).To(Equal(oauthFetch.TaskModeBackfill))
+		Expect(result.Task).To(BeNil())
+		Expect(*taskClient.ListTasksInputs[0].Filter.Name).To(Equal(oauthFetch.TaskName(taskType, providerSessionID)))
+		Expect(taskClient.DeleteTaskInputs).To(BeEmpty())
+		Expect(dataClient.DeleteDataSetInputs).To(BeEmpty())
+		Expect(dataClient.UpdateDataSourceInputs).To(BeEmpty())
+		Expect(taskClient.CreateTaskInputs).To(BeEmpty())
+	})
+
+	It("resets the data source and replaces the task without deleting data sets", func() {
+		resetDataSource := &data.DataSource{ID: dataSource.ID}
+		createdTask := &task.Task{ID: test.RandomString()}
+		taskClient.ListTasksOutputs = []taskTest.ListTasksOutput{{Tasks: task.Tasks{tsk}, Error: nil}}
+		taskClient.DeleteTaskOutputs = []error{nil}
+		dataClient.UpdateDataSourceOutputs = []dataTest.UpdateDataSourceOutput{{DataSource: resetDataSource, Error: nil}}
+		taskClient.CreateTaskOutputs = []taskTest.CreateTaskOutput{{Task: createdTask, Error: nil}}
+		result, err := prvdr.Reimport(ctx, dataSource, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).ToNot(BeNil())
+		Expect(result.DryRun).To(BeFalse())
+		Expect(result.DataSource).To(Equal(resetDataSource))
+		Expect(result.DeletedDataSetIDs).To(BeEmpty())
+		Expect(result.Task).To(Equal(createdTask))
+		Expect(taskClient.DeleteTaskInputs[0].ID).To(Equal(tsk.ID))
+		Expect(dataClient.UpdateDataSourceInputs[0].ID).To(Equal(dataSource.ID))
+		Expect(dataClient.UpdateDataSourceInputs[0].Update.Reset).To(Equal(pointer.FromBool(true)))
+		Expect(taskClient.CreateTaskInputs[0].Create.Type).To(Equal(taskType))
+		Expect(taskClient.CreateTaskInputs[0].Create.Data).To(Equal(map[string]interface{}{"providerSessionId": providerSessionID, "dataSourceId": dataSource.ID, "mode": oauthFetch.TaskModeBackfill}))
+	})
+
+	It("deletes the data sets before resetting the data source", func() {
+		taskClient.ListTasksOutputs = []taskTest.ListTasksOutput{{Tasks: task.Tasks{}, Error: nil}}
+		dataClient.GetDataSetOutputs = []dataTest.GetDataSetOutput{
+			{DataSet: &data.DataSet{UploadID: pointer.FromString(dataSetIDs[0])}, Error: nil},
+			{DataSet: &data.DataSet{UploadID: pointer.FromString(dataSetIDs[1])}, Error: nil},
+			{DataSet: &data.DataSet{UploadID: pointer.FromString(dataSetIDs[2])}, Error: nil},
+		}
+		dataClient.DeleteDataSetOutputs = []error{nil, nil, nil}
+		dataClient.UpdateDataSourceOutputs = []dataTest.UpdateDataSourceOutput{{DataSource: dataSource, Error: nil}}
+		taskClient.CreateTaskOutputs = []taskTest.CreateTaskOutput{{Task: tsk, Error: nil}}
+		result, err := prvdr.Reimport(ctx, dataSource, &oauthFetch.Reimport{DeleteDataSets: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.DeletedDataSetIDs).To(Equal(dataSetIDs))
+		Expect(dataClient.DeleteDataSetInputs).To(HaveLen(3))
+	})
+
+	It("returns an error if deleting a data set returns an error", func() {
+		taskClient.ListTasksOutputs = []taskTest.ListTasksOutput{{Tasks: task.Tasks{}, Error: nil}}
+		dataClient.GetDataSetOutputs = []dataTest.GetDataSetOutput{
+			{DataSet: &data.DataSet{UploadID: pointer.FromString(dataSetIDs[0])}, Error: nil},
+			{DataSet: nil, Error: nil},
+			{DataSet: nil, Error: nil},
+		}
+		dataClient.DeleteDataSetOutputs = []error{errorsTest.NewError()}
+		result, err := prvdr.Reimport(ctx, dataSource, &oauthFetch.Reimport{DeleteDataSets: true})
+		Expect(err).To(MatchError(HavePrefix("unable to delete data set")))
+		Expect(result).To(BeNil())
+	})
+})
@@ -0,0 +1,709 @@
+package fetch
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/data"
+	dataClient "github.com/tidepool-org/platform/data/client"
+	"github.com/tidepool-org/platform/data/types/upload"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/oauth"
+	oauthToken "github.com/tidepool-org/platform/oauth/token"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/task"
+)
+
+const (
+	AvailableAfterDurationMaximum  = 75 * time.Minute
+	AvailableAfterDurationMinimum  = 45 * time.Minute
+	BackfillAvailableAfterDuration = time.Minute
+	BackfillPriority               = 10
+	BackfillTaskDurationMaximum    = 4 * time.Minute
+	BackfillThresholdDuration      = 7 * 24 * time.Hour
+	DataSetSize                    = 2000
+	IncrementalPriority            = 0
+	LockDuration                   = 2 * TaskDurationMaximum
+	LockRetryAfterDuration         = time.Minute
+	RetryAfterDurationDefault      = 15 * time.Minute
+	RetryAfterJitterMaximum        = 5 * time.Minute
+	TaskDurationMaximum            = 5 * time.Minute
+)
+
+// Runner is a generic task runner that fetches data from a provider since the latest data time of
+// the data source, backfilling first if required, and stores the data in the data source's data set.
+type Runner struct {
+	logger     log.Logger
+	authClient auth.Client
+	dataClient dataClient.Client
+	definition *Definition
+}
+
+func NewRunner(logger log.Logger, authClient auth.Client, dataClient dataClient.Client, definition *Definition) (*Runner, error) {
+	if logger == nil {
+		return nil, errors.New("logger is missing")
+	}
+	if authClient == nil {
+		return nil, errors.New("auth client is missing")
+	}
+	if dataClient == nil {
+		return nil, errors.New("data client is missing")
+	}
+	if definition == nil {
+		return nil, errors.New("definition is missing")
+	} else if err := definition.Validate(); err != nil {
+		return nil, errors.Wrap(err, "definition is invalid")
+	}
+
+	return &Runner{
+		logger:     logger,
+		authClient: authClient,
+		dataClient: dataClient,
+		definition: definition,
+	}, nil
+}
+
+func (r *Runner) Logger() log.Logger {
+	return r.logger
+}
+
+func (r *Runner) AuthClient() auth.Client {
+	return r.authClient
+}
+
+func (r *Runner) DataClient() dataClient.Client {
+	return r.dataClient
+}
+
+func (r *Runner) Definition() *Definition {
+	return r.definition
+}
+
+func (r *Runner) CanRunTask(tsk *task.Task) bool {
+	return tsk != nil && tsk.Type == r.definition.TaskType
+}
+
+func (r *Runner) Run(ctx context.Context, tsk *task.Task) {
+	taskStartTime := time.Now()
+
+	logger := r.Logger().WithFields(log.Fields{"taskId": tsk.ID, "providerName": r.definition.ProviderName})
+	ctx = log.NewContextWithLogger(ctx, logger)
+
+	var retryAfter *time.Duration
+	if maintenanceWindow := r.definition.MaintenanceWindows.Active(taskStartTime); maintenanceWindow != nil {
+		logger.WithField("maintenanceWindow", maintenanceWindow.String()).Debug("Skipping task during maintenance window")
+		retryAfter = pointer.FromDuration(maintenanceWindow.EndTime(taskStartTime).Sub(taskStartTime))
+	} else {
+		tsk.ClearError()
+
+		if serverSessionToken, err := r.AuthClient().ServerSessionToken(); err != nil {
+			tsk.AppendError(errors.Wrap(err, "unable to get server session token"))
+		} else {
+			ctx = auth.NewContextWithServerSessionToken(ctx, serverSessionToken)
+
+			taskRunner := newTaskRunner(r, tsk)
+			if err = taskRunner.Run(ctx); err != nil {
+				tsk.AppendError(errors.Wrap(err, "unable to run task runner"))
+				if cause := errors.Cause(err); request.IsErrorTooManyRequests(cause) || request.IsErrorServiceUnavailable(cause) {
+					if retryAfter = request.RetryAfterFromError(cause); retryAfter == nil {
+						retryAfter = pointer.FromDuration(RetryAfterDurationDefault)
+					}
+					logger.WithError(err).WithField("retryAfter", retryAfter.Seconds()).Warn("Rescheduling task after provider unavailable")
+				}
+			} else if taskRunner.locked {
+				logger.Debug("Rescheduling task after provider session locked")
+				retryAfter = pointer.FromDuration(LockRetryAfterDuration)
+			}
+		}
+	}
+
+	if !tsk.IsFailed() {
+		if retryAfter != nil {
+			tsk.RepeatAvailableAfter(*retryAfter + time.Duration(rand.Int63n(int64(RetryAfterJitterMaximum+1))))
+		} else if mode, ok := tsk.Data["mode"].(string); ok && mode == TaskModeBackfill {
+			tsk.RepeatAvailableAfter(BackfillAvailableAfterDuration)
+		} else {
+			tsk.RepeatAvailableAfter(AvailableAfterDurationMinimum + time.Duration(rand.Int63n(int64(AvailableAfterDurationMaximum-AvailableAfterDurationMinimum+1))))
+		}
+	}
+
+	if taskDuration := time.Since(taskStartTime); taskDuration > TaskDurationMaximum {
+		logger.WithField("taskDuration", taskDuration.Truncate(time.Millisecond).Seconds()).Warn("Task duration exceeds maximum")
+	}
+}
+
+type taskRunner struct {
+	*Runner
+	task             *task.Task
+	context          context.Context
+	providerSession  *auth.ProviderSession
+	lock             *auth.ProviderSessionLock
+	locked           bool
+	dataSource       *data.DataSource
+	tokenSource      oauth.TokenSource
+	dataSet          *data.DataSet
+	dataSetPreloaded bool
+}
+
+func newTaskRunner(rnnr *Runner, tsk *task.Task) *taskRunner {
+	return &taskRunner{
+		Runner: rnnr,
+		task:   tsk,
+	}
+}
+
+func (t *taskRunner) Run(ctx context.Context) error {
+	if len(t.task.Data) == 0 {
+		t.task.SetFailed()
+		return errors.New("data is missing")
+	}
+
+	t.context = ctx
+
+	if err := t.getProviderSession(); err != nil {
+		return err
+	}
+	if err := t.lockProviderSession(); err != nil {
+		return err
+	} else if t.locked {
+		return nil
+	}
+	defer t.unlockProviderSession()
+
+	if err := t.getDataSource(); err != nil {
+		return err
+	}
+	if err := t.createTokenSource(); err != nil {
+		return err
+	}
+
+	var err error
+	if t.isBackfillRequired() {
+		err = t.backfill()
+	} else {
+		t.setTaskMode(TaskModeIncremental)
+		err = t.fetchSinceLatestDataTime()
+	}
+	if err != nil {
+		if request.IsErrorUnauthenticated(errors.Cause(err)) {
+			t.task.SetFailed()
+			if updateErr := t.updateDataSourceWithError(err); updateErr != nil {
+				log.LoggerFromContext(t.context).WithError(updateErr).Error("Unable to update data source with error")
+			}
+		}
+		return err
+	}
+	return t.updateDataSourceWithLastImportTime()
+}
+
+func (t *taskRunner) TokenSource() oauth.TokenSource {
+	return t.tokenSource
+}
+
+func (t *taskRunner) DataSource() *data.DataSource {
+	return t.dataSource
+}
+
+func (t *taskRunner) DataSet() (*data.DataSet, error) {
+	if err := t.preloadDataSet(); err != nil {
+		return nil, err
+	}
+	return t.dataSet, nil
+}
+
+func (t *taskRunner) getProviderSession() error {
+	providerSessionID, ok := t.task.Data["providerSessionId"].(string)
+	if !ok || providerSessionID == "" {
+		t.task.SetFailed()
+		return errors.New("provider session id is missing")
+	}
+
+	providerSession, err := t.AuthClient().GetProviderSession(t.context, providerSessionID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get provider session")
+	} else if providerSession == nil {
+		t.task.SetFailed()
+		return errors.New("provider session is missing")
+	}
+	t.providerSession = providerSession
+
+	return nil
+}
+
+// The provider session is locked for the duration of the task so that a token refresh elsewhere does not
+// invalidate the refresh token in use by the task
+func (t *taskRunner) lockProviderSession() error {
+	lock := auth.NewProviderSessionLock(LockDuration)
+	providerSession, err := t.AuthClient().LockProviderSession(t.context, t.providerSession.ID, lock)
+	if err != nil {
+		return errors.Wrap(err, "unable to lock provider session")
+	} else if providerSession == nil {
+		t.locked = true
+		return nil
+	}
+	t.providerSession = providerSession
+	t.lock = lock
+
+	return nil
+}
+
+func (t *taskRunner) unlockProviderSession() {
+	if err := t.AuthClient().UnlockProviderSession(t.context, t.providerSession.ID, t.lock.ID); err != nil {
+		log.LoggerFromContext(t.context).WithError(err).Warn("Unable to unlock provider session")
+	}
+}
+
+func (t *taskRunner) UpdateProviderSession() error {
+	refreshedToken, err := t.tokenSource.RefreshedToken()
+	if err != nil {
+		return errors.Wrap(err, "unable to get refreshed token")
+	} else if refreshedToken == nil {
+		return nil
+	}
+
+	providerSessionUpdate := auth.NewProviderSessionUpdate()
+	providerSessionUpdate.OAuthToken = refreshedToken
+	providerSession, err := t.AuthClient().UpdateProviderSession(t.context, t.providerSession.ID, providerSessionUpdate)
+	if err != nil {
+		return errors.Wrap(err, "unable to update provider session")
+	} else if providerSession == nil {
+		t.task.SetFailed()
+		return errors.New("provider session is missing")
+	}
+	t.providerSession = providerSession
+
+	return nil
+}
+
+func (t *taskRunner) getDataSource() error {
+	dataSourceID, ok := t.task.Data["dataSourceId"].(string)
+	if !ok || dataSourceID == "" {
+		t.task.SetFailed()
+		return errors.New("data source id is missing")
+	}
+
+	dataSource, err := t.DataClient().GetDataSource(t.context, dataSourceID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get data source")
+	} else if dataSource == nil {
+		t.task.SetFailed()
+		return errors.New("data source is missing")
+	}
+	t.dataSource = dataSource
+
+	return nil
+}
+
+func (t *taskRunner) updateDataSourceWithDataSet(dataSet *data.DataSet) error {
+	dataSourceUpdate := data.NewDataSourceUpdate()
+	dataSourceUpdate.DataSetIDs = pointer.FromStringArray(append(t.dataSource.DataSetIDs, *dataSet.UploadID))
+	return t.updateDataSource(dataSourceUpdate)
+}
+
+func (t *taskRunner) updateDataSourceWithDataTime(earliestDataTime time.Time, latestDataTime time.Time) error {
+	dataSourceUpdate := data.NewDataSourceUpdate()
+
+	if t.beforeEarliestDataTime(earliestDataTime) {
+		dataSourceUpdate.EarliestDataTime = pointer.FromTime(earliestDataTime.Truncate(time.Second))
+	}
+	if t.afterLatestDataTime(latestDataTime) {
+		dataSourceUpdate.LatestDataTime = pointer.FromTime(latestDataTime.Truncate(time.Second))
+	}
+
+	if dataSourceUpdate.EarliestDataTime == nil && dataSourceUpdate.LatestDataTime == nil {
+		return nil
+	}
+
+	dataSourceUpdate.LastImportTime = pointer.FromTime(time.Now().Truncate(time.Second))
+	return t.updateDataSource(dataSourceUpdate)
+}
+
+func (t *taskRunner) updateDataSourceWithLastImportTime() error {
+	dataSourceUpdate := data.NewDataSourceUpdate()
+	dataSourceUpdate.LastImportTime = pointer.FromTime(time.Now().Truncate(time.Second))
+	return t.updateDataSource(dataSourceUpdate)
+}
+
+func (t *taskRunner) updateDataSourceWithBackfill(backfill *data.DataSourceBackfill) error {
+	dataSourceUpdate := data.NewDataSourceUpdate()
+	dataSourceUpdate.Backfill = backfill
+	return t.updateDataSource(dataSourceUpdate)
+}
+
+func (t *taskRunner) updateDataSourceWithError(err error) error {
+	dataSourceUpdate := data.NewDataSourceUpdate()
+	dataSourceUpdate.State = pointer.FromString(data.DataSourceStateError)
+	dataSourceUpdate.Error = &errors.Serializable{Error: err}
+	return t.updateDataSource(dataSourceUpdate)
+}
+
+func (t *taskRunner) updateDataSource(dataSourceUpdate *data.DataSourceUpdate) error {
+	if !dataSourceUpdate.HasUpdates() {
+		return nil
+	}
+
+	dataSource, err := t.DataClient().UpdateDataSource(t.context, t.dataSource.ID, dataSourceUpdate)
+	if err != nil {
+		return errors.Wrap(err, "unable to update data source")
+	} else if dataSource == nil {
+		t.task.SetFailed()
+		return errors.New("data source is missing")
+	}
+
+	t.dataSource = dataSource
+	return nil
+}
+
+func (t *taskRunner) createTokenSource() error {
+	tokenSource, err := oauthToken.NewSourceWithToken(t.providerSession.OAuthToken)
+	if err != nil {
+		t.task.SetFailed()
+		return errors.Wrap(err, "unable to create token source")
+	}
+
+	t.tokenSource = tokenSource
+	return nil
+}
+
+func (t *taskRunner) setTaskMode(mode string) {
+	if t.definition.BackfillFetchDuration == 0 {
+		return
+	}
+
+	t.task.Data["mode"] = mode
+	if mode == TaskModeBackfill {
+		t.task.Priority = BackfillPriority
+	} else {
+		t.task.Priority = IncrementalPriority
+	}
+}
+
+// A backfill is required if one is already in progress or if the data source has not recently
+// imported and the time to fetch from is well in the past (e.g. a new connection)
+func (t *taskRunner) isBackfillRequired() bool {
+	if t.definition.BackfillFetchDuration == 0 {
+		return false
+	}
+	if t.dataSource.Backfill != nil && !t.dataSource.Backfill.IsCompleted() {
+		return true
+	}
+	thresholdTime := time.Now().Add(-BackfillThresholdDuration)
+	if t.dataSource.LastImportTime != nil && t.dataSource.LastImportTime.After(thresholdTime) {
+		return false
+	}
+	return t.fetchStartTime().Before(thresholdTime)
+}
+
+// Backfill fetches in the largest windows possible, records progress after each window so that
+// it can resume from the last window even if the window contained no data, and stops after the
+// maximum task duration so the task can be rescheduled promptly at a higher priority
+func (t *taskRunner) backfill() error {
+	now := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	backfill := t.dataSource.Backfill
+	if backfill == nil || backfill.IsCompleted() {
+		backfill = data.NewDataSourceBackfill()
+		backfill.StartTime = t.fetchStartTime()
+		backfill.EndTime = now
+	}
+
+	t.setTaskMode(TaskModeBackfill)
+
+	startTime := backfill.StartTime
+	if backfill.LastWindowEndTime != nil {
+		startTime = *backfill.LastWindowEndTime
+	}
+
+	deadlineTime := time.Now().Add(BackfillTaskDurationMaximum)
+	for startTime.Before(now) {
+		if time.Now().After(deadlineTime) {
+			return nil
+		}
+
+		endTime := startTime.Add(t.definition.BackfillFetchDuration)
+		if endTime.After(now) {
+			endTime = now
+		}
+
+		if err := t.fetch(startTime, endTime); err != nil {
+			return err
+		}
+
+		backfill.EndTime = now
+		backfill.LastWindowStartTime = pointer.FromTime(startTime)
+		backfill.LastWindowEndTime = pointer.FromTime(endTime)
+		backfill.Progress = calculateBackfillProgress(backfill)
+		if err := t.updateDataSourceWithBackfill(backfill); err != nil {
+			return err
+		}
+
+		startTime = endTime
+		now = time.Now().Add(-time.Minute).Truncate(time.Second)
+	}
+
+	backfill.Progress = data.DataSourceBackfillProgressMaximum
+	backfill.CompletedTime = pointer.FromTime(time.Now().Truncate(time.Second))
+	if err := t.updateDataSourceWithBackfill(backfill); err != nil {
+		return err
+	}
+
+	t.setTaskMode(TaskModeIncremental)
+	return nil
+}
+
+func (t *taskRunner) fetchStartTime() time.Time {
+	startTime := t.definition.InitialDataTime
+	if t.dataSource.LatestDataTime != nil && startTime.Before(*t.dataSource.LatestDataTime) {
+		startTime = *t.dataSource.LatestDataTime
+	}
+	return startTime
+}
+
+func (t *taskRunner) fetchSinceLatestDataTime() error {
+	startTime := t.fetchStartTime()
+
+	now := time.Now().Add(-time.Minute).Truncate(time.Second)
+	for startTime.Before(now) {
+		endTime := startTime.Add(t.definition.FetchDuration)
+		if endTime.After(now) {
+			endTime = now
+		}
+
+		if err := t.fetch(startTime, endTime); err != nil {
+			return err
+		}
+
+		startTime = endTime
+		now = time.Now().Add(-time.Minute).Truncate(time.Second)
+	}
+	return nil
+}
+
+func (t *taskRunner) fetch(startTime time.Time, endTime time.Time) error {
+	var result *Result
+	var err error
+	if t.definition.Fetcher != nil {
+		result, err = t.definition.Fetcher.Fetch(t.context, t, startTime, endTime)
+	} else {
+		result, err = t.fetchRecords(startTime, endTime)
+	}
+	if err != nil {
+		return err
+	} else if result == nil {
+		return nil
+	}
+
+	datumArray := []*Datum{}
+	for _, datum := range result.Data {
+		if datum != nil && datum.Datum != nil && t.afterLatestDataTime(datum.Time) {
+			datumArray = append(datumArray, datum)
+		}
+	}
+	if len(datumArray) == 0 {
+		return nil
+	}
+
+	sort.Sort(ByTime(datumArray))
+
+	if err = t.prepareDataSet(result); err != nil {
+		return err
+	}
+
+	return t.storeDatumArray(datumArray)
+}
+
+func (t *taskRunner) fetchRecords(startTime time.Time, endTime time.Time) (*Result, error) {
+	records, err := t.definition.Client.FetchRecords(t.context, startTime, endTime, t.tokenSource)
+	if updateErr := t.UpdateProviderSession(); updateErr != nil {
+		return nil, updateErr
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to fetch records")
+	}
+
+	result := &Result{}
+	for _, record := range records {
+		if record != nil && t.afterLatestDataTime(record.Time()) {
+			if datum, err := t.definition.Translate(record); err != nil {
+				return nil, errors.Wrap(err, "unable to translate record")
+			} else if datum != nil {
+				result.Data = append(result.Data, &Datum{Time: record.Time(), Datum: datum})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (t *taskRunner) preloadDataSet() error {
+	if t.dataSet != nil || t.dataSetPreloaded {
+		return nil
+	}
+
+	dataSet, err := t.findDataSet()
+	if err != nil {
+		return err
+	}
+
+	t.dataSet = dataSet
+	t.dataSetPreloaded = true
+	return nil
+}
+
+func (t *taskRunner) prepareDataSet(result *Result) error {
+	if err := t.preloadDataSet(); err != nil {
+		return err
+	}
+
+	if t.dataSet != nil {
+		return t.updateDataSetWithResult(result)
+	}
+
+	dataSet, err := t.createDataSet(result)
+	if err != nil {
+		return err
+	}
+	t.dataSet = dataSet
+	return nil
+}
+
+func (t *taskRunner) findDataSet() (*data.DataSet, error) {
+	for index := len(t.dataSource.DataSetIDs) - 1; index >= 0; index-- {
+		if dataSet, err := t.DataClient().GetDataSet(t.context, t.dataSource.DataSetIDs[index]); err != nil {
+			return nil, errors.Wrap(err, "unable to get data set")
+		} else if dataSet != nil && dataSet.DeletedTime == nil {
+			return dataSet, nil
+		}
+	}
+	return nil, nil
+}
+
+func (t *taskRunner) createDataSet(result *Result) (*data.DataSet, error) {
+	dataSetCreate := data.NewDataSetCreate()
+	dataSetCreate.Client = &data.DataSetClient{
+		Name:    pointer.FromString(t.definition.DataSetClientName),
+		Version: pointer.FromString(t.definition.DataSetClientVersion),
+	}
+	dataSetCreate.DataSetType = pointer.FromString(data.DataSetTypeContinuous)
+	dataSetCreate.DeviceID = result.DeviceID
+	dataSetCreate.DeviceManufacturers = pointer.FromStringArray(t.definition.DeviceManufacturers)
+	dataSetCreate.DeviceModel = pointer.FromString(t.definition.DeviceModel)
+	if result.DeviceModel != nil {
+		dataSetCreate.DeviceModel = result.DeviceModel
+	}
+	dataSetCreate.DeviceSerialNumber = result.DeviceSerialNumber
+	dataSetCreate.DeviceTags = pointer.FromStringArray(t.definition.DeviceTags)
+	dataSetCreate.Time = pointer.FromTime(time.Now().Truncate(time.Second))
+	dataSetCreate.TimeProcessing = pointer.FromString(upload.TimeProcessingNone)
+
+	dataSet, err := t.DataClient().CreateUserDataSet(t.context, t.providerSession.UserID, dataSetCreate)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create data set")
+	} else if dataSet == nil {
+		return nil, errors.New("data set is missing")
+	}
+	if err = t.updateDataSourceWithDataSet(dataSet); err != nil {
+		return nil, err
+	}
+
+	return dataSet, nil
+}
+
+func (t *taskRunner) updateDataSetWithResult(result *Result) error {
+	dataSetUpdate := data.NewDataSetUpdate()
+	if result.DeviceID != nil && (t.dataSet.DeviceID == nil || *t.dataSet.DeviceID != *result.DeviceID) {
+		dataSetUpdate.DeviceID = result.DeviceID
+	}
+	if result.DeviceModel != nil && (t.dataSet.DeviceModel == nil || *t.dataSet.DeviceModel != *result.DeviceModel) {
+		dataSetUpdate.DeviceModel = result.DeviceModel
+	}
+	if result.DeviceSerialNumber != nil && (t.dataSet.DeviceSerialNumber == nil || *t.dataSet.DeviceSerialNumber != *result.DeviceSerialNumber) {
+		dataSetUpdate.DeviceSerialNumber = result.DeviceSerialNumber
+	}
+	if !dataSetUpdate.HasUpdates() {
+		return nil
+	}
+
+	dataSet, err := t.DataClient().UpdateDataSet(t.context, *t.dataSet.UploadID, dataSetUpdate)
+	if err != nil {
+		return errors.Wrap(err, "unable to update data set")
+	} else if dataSet == nil {
+		t.task.SetFailed()
+		return errors.New("data set is missing")
+	}
+
+	t.dataSet = dataSet
+	return nil
+}
+
+func (t *taskRunner) storeDatumArray(datumArray []*Datum) error {
+	length := len(datumArray)
+	for startIndex := 0; startIndex < length; startIndex += DataSetSize {
+		endIndex := startIndex + DataSetSize
+		if endIndex > length {
+			endIndex = length
+		}
+
+		dataSetData := make([]data.Datum, 0, endIndex-startIndex)
+		for _, datum := range datumArray[startIndex:endIndex] {
+			dataSetData = append(dataSetData, datum.Datum)
+		}
+
+		if err := t.DataClient().CreateDataSetsData(t.context, *t.dataSet.UploadID, dataSetData); err != nil {
+			return errors.Wrap(err, "unable to create data set data")
+		}
+
+		if err := t.updateDataSourceWithDataTime(datumArray[0].Time, datumArray[endIndex-1].Time); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *taskRunner) beforeEarliestDataTime(earliestDataTime time.Time) bool {
+	return t.dataSource.EarliestDataTime == nil || earliestDataTime.Before(*t.dataSource.EarliestDataTime)
+}
+
+func (t *taskRunner) afterLatestDataTime(latestDataTime time.Time) bool {
+	return t.dataSource.LatestDataTime == nil || latestDataTime.After(*t.dataSource.LatestDataTime)
+}
+
+func calculateBackfillProgress(backfill *data.DataSourceBackfill) float64 {
+	if backfill.LastWindowEndTime == nil {
+		return data.DataSourceBackfillProgressMinimum
+	}
+
+	totalDuration := backfill.EndTime.Sub(backfill.StartTime)
+	if totalDuration <= 0 {
+		return data.DataSourceBackfillProgressMaximum
+	}
+
+	progress := data.DataSourceBackfillProgressMaximum * float64(backfill.LastWindowEndTime.Sub(backfill.StartTime)) / float64(totalDuration)
+	if progress < data.DataSourceBackfillProgressMinimum {
+		return data.DataSourceBackfillProgressMinimum
+	} else if progress > data.DataSourceBackfillProgressMaximum {
+		return data.DataSourceBackfillProgressMaximum
+	}
+	return math.Floor(progress*10) / 10
+}
+
+type ByTime []*Datum
+
+func (b ByTime) Len() int {
+	return len(b)
+}
+
+func (b ByTime) Swap(left int, right int) {
+	b[left], b[right] = b[right], b[left]
+}
+
+func (b ByTime) Less(left int, right int) bool {
+	return b[left].Time.Before(b[right].Time)
+}
@@ -0,0 +1,477 @@
+package fetch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"time"
+
+	"github.com/tidepool-org/platform/auth"
+	authTest "github.com/tidepool-org/platform/auth/test"
+	"github.com/tidepool-org/platform/data"
+	dataClientTest "github.com/tidepool-org/platform/data/client/test"
+	dataTest "github.com/tidepool-org/platform/data/test"
+	"github.com/tidepool-org/platform/data/types/blood/glucose/continuous"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	logTest "github.com/tidepool-org/platform/log/test"
+	"github.com/tidepool-org/platform/oauth"
+	oauthFetch "github.com/tidepool-org/platform/oauth/fetch"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/provider"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/task"
+	"github.com/tidepool-org/platform/test"
+)
+
+var _ = Describe("Runner", func() {
+	var logger *logTest.Logger
+	var authClient *authTest.Client
+	var dataClient *dataClientTest.Client
+	var definition *oauthFetch.Definition
+	var fetchClient *client
+
+	BeforeEach(func() {
+		logger = logTest.NewLogger()
+		authClient = authTest.NewClient()
+		dataClient = dataClientTest.NewClient()
+		definition = newDefinition()
+		fetchClient = definition.Client.(*client)
+	})
+
+	AfterEach(func() {
+		dataClient.AssertOutputsEmpty()
+		authClient.Expectations()
+	})
+
+	Context("NewRunner", func() {
+		It("returns an error if the logger is missing", func() {
+			rnnr, err := oauthFetch.NewRunner(nil, authClient, dataClient, definition)
+			Expect(err).To(MatchError("logger is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the auth client is missing", func() {
+			rnnr, err := oauthFetch.NewRunner(logger, nil, dataClient, definition)
+			Expect(err).To(MatchError("auth client is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the data client is missing", func() {
+			rnnr, err := oauthFetch.NewRunner(logger, authClient, nil, definition)
+			Expect(err).To(MatchError("data client is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the definition is missing", func() {
+			rnnr, err := oauthFetch.NewRunner(logger, authClient, dataClient, nil)
+			Expect(err).To(MatchError("definition is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the definition is invalid", func() {
+			definition.Client = nil
+			rnnr, err := oauthFetch.NewRunner(logger, authClient, dataClient, definition)
+			Expect(err).To(MatchError("definition is invalid; client is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			rnnr, err := oauthFetch.NewRunner(logger, authClient, dataClient, definition)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rnnr).ToNot(BeNil())
+			Expect(rnnr.Definition()).To(Equal(definition))
+		})
+	})
+
+	Context("with new runner", func() {
+		var rnnr *oauthFetch.Runner
+
+		BeforeEach(func() {
+			var err error
+			rnnr, err = oauthFetch.NewRunner(logger, authClient, dataClient, definition)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rnnr).ToNot(BeNil())
+		})
+
+		Context("CanRunTask", func() {
+			It("returns false if the task is missing", func() {
+				Expect(rnnr.CanRunTask(nil)).To(BeFalse())
+			})
+
+			It("returns false if the task type does not match", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: test.RandomString()})).To(BeFalse())
+			})
+
+			It("returns true if the task type matches", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: definition.TaskType})).To(BeTrue())
+			})
+		})
+
+		Context("Run", func() {
+			var ctx context.Context
+			var providerSession *auth.ProviderSession
+			var dataSource *data.DataSource
+			var tsk *task.Task
+
+			BeforeEach(func() {
+				var err error
+				ctx = context.Background()
+				providerSession = &auth.ProviderSession{
+					ID:     auth.NewProviderSessionID(),
+					UserID: test.RandomString(),
+					OAuthToken: &oauth.Token{
+						AccessToken:    test.RandomString(),
+						TokenType:      "Bearer",
+						RefreshToken:   test.RandomString(),
+						ExpirationTime: time.Now().Add(time.Hour),
+					},
+				}
+				dataSource = &data.DataSource{
+					ID:             test.RandomString(),
+					UserID:         providerSession.UserID,
+					LatestDataTime: pointerFromTime(time.Now().Add(-3 * time.Hour).Truncate(time.Second)),
+				}
+				tsk, err = task.NewTask(&task.TaskCreate{
+					Type: definition.TaskType,
+					Data: map[string]interface{}{"providerSessionId": providerSession.ID, "dataSourceId": dataSource.ID},
+				})
+				Expect(err).ToNot(HaveOccurred())
+				tsk.State = task.TaskStateRunning
+			})
+
+			It("reschedules the task without running during a maintenance window", func() {
+				hour, minute, _ := time.Now().UTC().Clock()
+				offset := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute
+				maintenanceWindow := &provider.MaintenanceWindow{Location: time.UTC, StartOffset: (offset + 23*time.Hour) % (24 * time.Hour), EndOffset: (offset + time.Hour) % (24 * time.Hour)}
+				definition.MaintenanceWindows = provider.MaintenanceWindows{maintenanceWindow}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeFalse())
+				Expect(tsk.State).To(Equal(task.TaskStatePending))
+				Expect(*tsk.AvailableTime).To(BeTemporally(">=", time.Now().Add(time.Hour).Add(-2*time.Minute)))
+				Expect(authClient.ServerSessionTokenInvocations).To(Equal(0))
+			})
+
+			It("records the error if the server session token returns an error", func() {
+				authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: "", Error: errorsTest.NewError()}}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.State).To(Equal(task.TaskStatePending))
+			})
+
+			Context("with server session token", func() {
+				BeforeEach(func() {
+					authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: authTest.NewSessionToken(), Error: nil}}
+				})
+
+				It("fails the task if the data is missing", func() {
+					tsk.Data = nil
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeTrue())
+					Expect(tsk.State).To(Equal(task.TaskStateFailed))
+				})
+
+				It("fails the task if the provider session is missing", func() {
+					authClient.GetProviderSessionOutputs = []authTest.GetProviderSessionOutput{{ProviderSession: nil, Error: nil}}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeTrue())
+					Expect(tsk.State).To(Equal(task.TaskStateFailed))
+				})
+
+				It("reschedules the task if the provider session is locked", func() {
+					authClient.GetProviderSessionOutputs = []authTest.GetProviderSessionOutput{{ProviderSession: providerSession, Error: nil}}
+					authClient.LockProviderSessionOutputs = []authTest.LockProviderSessionOutput{{ProviderSession: nil, Error: nil}}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeFalse())
+					Expect(tsk.State).To(Equal(task.TaskStatePending))
+					Expect(*tsk.AvailableTime).To(BeTemporally("<=", time.Now().Add(oauthFetch.LockRetryAfterDuration+oauthFetch.RetryAfterJitterMaximum)))
+					Expect(authClient.UnlockProviderSessionInvocations).To(Equal(0))
+				})
+
+				Context("with provider session and data source", func() {
+					BeforeEach(func() {
+						authClient.GetProviderSessionOutputs = []authTest.GetProviderSessionOutput{{ProviderSession: providerSession, Error: nil}}
+						authClient.LockProviderSessionOutputs = []authTest.LockProviderSessionOutput{{ProviderSession: providerSession, Error: nil}}
+						authClient.UnlockProviderSessionOutputs = []error{nil}
+						dataClient.GetDataSourceOutputs = []dataTest.GetDataSourceOutput{{DataSource: dataSource, Error: nil}}
+					})
+
+					AfterEach(func() {
+						Expect(authClient.UnlockProviderSessionInputs).To(HaveLen(1))
+						Expect(authClient.UnlockProviderSessionInputs[0].LockID).To(Equal(authClient.LockProviderSessionInputs[0].Lock.ID))
+					})
+
+					It("reschedules the task after the retry after duration if the provider is unavailable", func() {
+						fetchClient.FetchRecordsStub = func(ctx context.Context, startTime time.Time, endTime time.Time, tokenSource oauth.TokenSource) ([]oauthFetch.Record, error) {
+							return nil, request.ErrorWithRetryAfter(request.ErrorTooManyRequests(), 2*time.Hour)
+						}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeTrue())
+						Expect(tsk.State).To(Equal(task.TaskStatePending))
+						Expect(*tsk.AvailableTime).To(BeTemporally(">=", time.Now().Add(2*time.Hour).Add(-time.Minute)))
+					})
+
+					It("fails the task and updates the data source with the error if unauthenticated", func() {
+						fetchClient.FetchRecordsStub = func(ctx context.Context, startTime time.Time, endTime time.Time, tokenSource oauth.TokenSource) ([]oauthFetch.Record, error) {
+							return nil, request.ErrorUnauthenticated()
+						}
+						dataClient.UpdateDataSourceOutputs = []dataTest.UpdateDataSourceOutput{{DataSource: dataSource, Error: nil}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeTrue())
+						Expect(tsk.State).To(Equal(task.TaskStateFailed))
+						Expect(dataClient.UpdateDataSourceInputs).To(HaveLen(1))
+						Expect(*dataClient.UpdateDataSourceInputs[0].Update.State).To(Equal(data.DataSourceStateError))
+						Expect(dataClient.UpdateDataSourceInputs[0].Update.Error).ToNot(BeNil())
+					})
+
+					It("records the error and does not fail the task if fetch returns an error", func() {
+						fetchClient.FetchRecordsStub = func(ctx context.Context, startTime time.Time, endTime time.Time, tokenSource oauth.TokenSource) ([]oauthFetch.Record, error) {
+							return nil, errorsTest.NewError()
+						}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeTrue())
+						Expect(tsk.State).To(Equal(task.TaskStatePending))
+					})
+
+					It("updates the last import time if there are no new records", func() {
+						dataClient.UpdateDataSourceOutputs = []dataTest.UpdateDataSourceOutput{{DataSource: dataSource, Error: nil}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeFalse())
+						Expect(tsk.State).To(Equal(task.TaskStatePending))
+						Expect(fetchClient.FetchRecordsInputs).To(HaveLen(1))
+						Expect(fetchClient.FetchRecordsInputs[0].StartTime).To(Equal(*dataSource.LatestDataTime))
+						Expect(dataClient.UpdateDataSourceInputs).To(HaveLen(1))
+						Expect(dataClient.UpdateDataSourceInputs[0].Update.LastImportTime).ToNot(BeNil())
+					})
+
+					It("translates and stores new records in a new data set", func() {
+						latestDataTime := *dataSource.LatestDataTime
+						records := []oauthFetch.Record{
+							&record{time: latestDataTime.Add(2 * time.Hour), value: 120},
+							&record{time: latestDataTime.Add(-time.Hour), value: 80},
+							&record{time: latestDataTime.Add(time.Hour), value: 100},
+						}
+						fetchClient.FetchRecordsStub = func(ctx context.Context, startTime time.Time, endTime time.Time, tokenSource oauth.TokenSource) ([]oauthFetch.Record, error) {
+							return records, nil
+						}
+						uploadID := test.RandomString()
+						dataClient.CreateUserDataSetOutputs = []dataTest.CreateUserDataSetOutput{{DataSet: &data.DataSet{UploadID: &uploadID}, Error: nil}}
+						dataClient.UpdateDataSourceOutputs = []dataTest.UpdateDataSourceOutput{
+							{DataSource: dataSource, Error: nil},
+							{DataSource: dataSource, Error: nil},
+							{DataSource: dataSource, Error: nil},
+						}
+						dataClient.CreateDataSetsDataOutputs = []error{nil}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeFalse())
+						Expect(tsk.State).To(Equal(task.TaskStatePending))
+						Expect(dataClient.CreateUserDataSetInputs).To(HaveLen(1))
+						Expect(dataClient.CreateUserDataSetInputs[0].UserID).To(Equal(providerSession.UserID))
+						Expect(*dataClient.CreateUserDataSetInputs[0].Create.Client.Name).To(Equal(definition.DataSetClientName))
+						Expect(*dataClient.CreateUserDataSetInputs[0].Create.DeviceModel).To(Equal(definition.DeviceModel))
+						Expect(*dataClient.UpdateDataSourceInputs[0].Update.DataSetIDs).To(Equal([]string{uploadID}))
+						Expect(dataClient.CreateDataSetsDataInputs).To(HaveLen(1))
+						Expect(dataClient.CreateDataSetsDataInputs[0].DataSetID).To(Equal(uploadID))
+						Expect(dataClient.CreateDataSetsDataInputs[0].DatumArray).To(HaveLen(2))
+						Expect(*dataClient.CreateDataSetsDataInputs[0].DatumArray[0].(*continuous.Continuous).Value).To(Equal(100.0))
+						Expect(*dataClient.CreateDataSetsDataInputs[0].DatumArray[1].(*continuous.Continuous).Value).To(Equal(120.0))
+						Expect(*dataClient.UpdateDataSourceInputs[1].Update.LatestDataTime).To(Equal(latestDataTime.Add(2 * time.Hour)))
+						Expect(dataClient.UpdateDataSourceInputs[2].Update.LastImportTime).ToNot(BeNil())
+					})
+
+					It("stores new records in the existing data set", func() {
+						uploadID := test.RandomString()
+						dataSource.DataSetIDs = []string{uploadID}
+						fetchClient.FetchRecordsStub = func(ctx context.Context, startTime time.Time, endTime time.Time, tokenSource oauth.TokenSource) ([]oauthFetch.Record, error) {
+							return []oauthFetch.Record{&record{time: dataSource.LatestDataTime.Add(time.Hour), value: 100}}, nil
+						}
+						dataClient.GetDataSetOutputs = []dataTest.GetDataSetOutput{{DataSet: &data.DataSet{UploadID: &uploadID}, Error: nil}}
+						dataClient.UpdateDataSourceOutputs = []dataTest.UpdateDataSourceOutput{
+							{DataSource: dataSource, Error: nil},
+							{DataSource: dataSource, Error: nil},
+						}
+						dataClient.CreateDataSetsDataOutputs = []error{nil}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeFalse())
+						Expect(dataClient.CreateUserDataSetInvocations).To(Equal(0))
+						Expect(dataClient.GetDataSetInputs[0].ID).To(Equal(uploadID))
+						Expect(dataClient.CreateDataSetsDataInputs[0].DataSetID).To(Equal(uploadID))
+					})
+
+					It("stores data from the fetcher in a new data set with the fetched device details", func() {
+						latestDataTime := *dataSource.LatestDataTime
+						datum := continuous.New()
+						datum.Value = pointer.FromFloat64(100)
+						definition.Fetcher = &fetcher{
+							FetchStub: func(ctx context.Context, session oauthFetch.Session, startTime time.Time, endTime time.Time) (*oauthFetch.Result, error) {
+								Expect(session.TokenSource()).ToNot(BeNil())
+								return &oauthFetch.Result{
+									Data:               []*oauthFetch.Datum{{Time: latestDataTime.Add(time.Hour), Datum: datum}},
+									DeviceID:           pointer.FromString("TestCGM_123"),
+									DeviceSerialNumber: pointer.FromString("123"),
+								}, nil
+							},
+						}
+						uploadID := test.RandomString()
+						dataClient.CreateUserDataSetOutputs = []dataTest.CreateUserDataSetOutput{{DataSet: &data.DataSet{UploadID: &uploadID}, Error: nil}}
+						dataClient.UpdateDataSourceOutputs = []dataTest.UpdateDataSourceOutput{
+							{DataSource: dataSource, Error: nil},
+							{DataSource: dataSource, Error: nil},
+							{DataSource: dataSource, Error: nil},
+						}
+						dataClient.CreateDataSetsDataOutputs = []error{nil}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeFalse())
+						Expect(fetchClient.FetchRecordsInputs).To(BeEmpty())
+						Expect(*dataClient.CreateUserDataSetInputs[0].Create.DeviceID).To(Equal("TestCGM_123"))
+						Expect(*dataClient.CreateUserDataSetInputs[0].Create.DeviceModel).To(Equal(definition.DeviceModel))
+						Expect(*dataClient.CreateUserDataSetInputs[0].Create.DeviceSerialNumber).To(Equal("123"))
+						Expect(dataClient.CreateDataSetsDataInputs[0].DatumArray).To(Equal([]data.Datum{datum}))
+					})
+
+					It("updates the device details of the existing data set from the fetcher", func() {
+						uploadID := test.RandomString()
+						dataSource.DataSetIDs = []string{uploadID}
+						datum := continuous.New()
+						definition.Fetcher = &fetcher{
+							FetchStub: func(ctx context.Context, session oauthFetch.Session, startTime time.Time, endTime time.Time) (*oauthFetch.Result, error) {
+								dataSet, err := session.DataSet()
+								Expect(err).ToNot(HaveOccurred())
+								Expect(dataSet.UploadID).To(Equal(&uploadID))
+								return &oauthFetch.Result{
+									Data:        []*oauthFetch.Datum{{Time: dataSource.LatestDataTime.Add(time.Hour), Datum: datum}},
+									DeviceModel: pointer.FromString("multiple"),
+								}, nil
+							},
+						}
+						dataClient.GetDataSetOutputs = []dataTest.GetDataSetOutput{{DataSet: &data.DataSet{UploadID: &uploadID, DeviceModel: pointer.FromString("TestCGM")}, Error: nil}}
+						dataClient.UpdateDataSetOutputs = []dataTest.UpdateDataSetOutput{{DataSet: &data.DataSet{UploadID: &uploadID, DeviceModel: pointer.FromString("multiple")}, Error: nil}}
+						dataClient.UpdateDataSourceOutputs = []dataTest.UpdateDataSourceOutput{
+							{DataSource: dataSource, Error: nil},
+							{DataSource: dataSource, Error: nil},
+						}
+						dataClient.CreateDataSetsDataOutputs = []error{nil}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeFalse())
+						Expect(dataClient.GetDataSetInputs).To(HaveLen(1))
+						Expect(dataClient.UpdateDataSetInputs).To(HaveLen(1))
+						Expect(dataClient.UpdateDataSetInputs[0].Update.DeviceID).To(BeNil())
+						Expect(*dataClient.UpdateDataSetInputs[0].Update.DeviceModel).To(Equal("multiple"))
+					})
+
+					Context("with backfill", func() {
+						var backfills []data.DataSourceBackfill
+
+						BeforeEach(func() {
+							definition.BackfillFetchDuration = 10 * 24 * time.Hour
+							backfills = nil
+							dataClient.UpdateDataSourceStub = func(ctx context.Context, id string, update *data.DataSourceUpdate) (*data.DataSource, error) {
+								if update.Backfill != nil {
+									backfills = append(backfills, *update.Backfill)
+								}
+								return dataSource, nil
+							}
+						})
+
+						It("fetches incrementally if the data source recently imported", func() {
+							dataSource.LatestDataTime = nil
+							dataSource.LastImportTime = pointerFromTime(time.Now().Add(-time.Hour))
+							rnnr.Run(ctx, tsk)
+							Expect(tsk.HasError()).To(BeFalse())
+							Expect(tsk.Data["mode"]).To(Equal(oauthFetch.TaskModeIncremental))
+							Expect(tsk.Priority).To(Equal(oauthFetch.IncrementalPriority))
+							Expect(fetchClient.FetchRecordsInputs[0].EndTime).To(Equal(definition.InitialDataTime.Add(definition.FetchDuration)))
+							Expect(backfills).To(BeEmpty())
+						})
+
+						It("fetches incrementally if the latest data time is within the backfill threshold", func() {
+							rnnr.Run(ctx, tsk)
+							Expect(tsk.HasError()).To(BeFalse())
+							Expect(tsk.Data["mode"]).To(Equal(oauthFetch.TaskModeIncremental))
+							Expect(fetchClient.FetchRecordsInputs).To(HaveLen(1))
+							Expect(backfills).To(BeEmpty())
+						})
+
+						It("backfills a new connection in windows of the backfill fetch duration and records progress", func() {
+							dataSource.LatestDataTime = nil
+							rnnr.Run(ctx, tsk)
+							Expect(tsk.HasError()).To(BeFalse())
+							Expect(tsk.Data["mode"]).To(Equal(oauthFetch.TaskModeIncremental))
+							Expect(tsk.Priority).To(Equal(oauthFetch.IncrementalPriority))
+							Expect(*tsk.AvailableTime).To(BeTemporally(">=", time.Now().Add(oauthFetch.AvailableAfterDurationMinimum).Add(-time.Minute)))
+							Expect(fetchClient.FetchRecordsInputs).To(HaveLen(3))
+							Expect(fetchClient.FetchRecordsInputs[0].StartTime).To(Equal(definition.InitialDataTime))
+							Expect(fetchClient.FetchRecordsInputs[0].EndTime).To(Equal(definition.InitialDataTime.Add(definition.BackfillFetchDuration)))
+							Expect(fetchClient.FetchRecordsInputs[1].StartTime).To(Equal(fetchClient.FetchRecordsInputs[0].EndTime))
+							Expect(backfills).To(HaveLen(4))
+							Expect(backfills[0].StartTime).To(Equal(definition.InitialDataTime))
+							Expect(*backfills[0].LastWindowStartTime).To(Equal(definition.InitialDataTime))
+							Expect(*backfills[0].LastWindowEndTime).To(Equal(fetchClient.FetchRecordsInputs[0].EndTime))
+							Expect(backfills[0].Progress).To(Equal(33.3))
+							Expect(backfills[1].Progress).To(Equal(66.6))
+							Expect(backfills[2].Progress).To(Equal(data.DataSourceBackfillProgressMaximum))
+							Expect(backfills[2].CompletedTime).To(BeNil())
+							Expect(backfills[3].Progress).To(Equal(data.DataSourceBackfillProgressMaximum))
+							Expect(backfills[3].CompletedTime).ToNot(BeNil())
+						})
+
+						It("resumes an in progress backfill from the last window even if recently imported", func() {
+							lastWindowEndTime := definition.InitialDataTime.Add(definition.BackfillFetchDuration)
+							dataSource.LatestDataTime = nil
+							dataSource.LastImportTime = pointerFromTime(time.Now().Add(-time.Hour))
+							dataSource.Backfill = &data.DataSourceBackfill{
+								StartTime:           definition.InitialDataTime,
+								EndTime:             time.Now().Add(-time.Hour),
+								LastWindowStartTime: pointerFromTime(definition.InitialDataTime),
+								LastWindowEndTime:   pointerFromTime(lastWindowEndTime),
+								Progress:            33.3,
+							}
+							rnnr.Run(ctx, tsk)
+							Expect(tsk.HasError()).To(BeFalse())
+							Expect(fetchClient.FetchRecordsInputs).To(HaveLen(2))
+							Expect(fetchClient.FetchRecordsInputs[0].StartTime).To(Equal(lastWindowEndTime))
+							Expect(backfills).To(HaveLen(3))
+							Expect(backfills[0].StartTime).To(Equal(definition.InitialDataTime))
+							Expect(backfills[0].Progress).To(Equal(66.6))
+							Expect(backfills[2].CompletedTime).ToNot(BeNil())
+						})
+
+						It("remains in backfill mode and reschedules promptly if a window fails", func() {
+							dataSource.LatestDataTime = nil
+							fetchClient.FetchRecordsStub = func(ctx context.Context, startTime time.Time, endTime time.Time, tokenSource oauth.TokenSource) ([]oauthFetch.Record, error) {
+								if !startTime.Equal(definition.InitialDataTime) {
+									return nil, errorsTest.NewError()
+								}
+								return nil, nil
+							}
+							rnnr.Run(ctx, tsk)
+							Expect(tsk.HasError()).To(BeTrue())
+							Expect(tsk.State).To(Equal(task.TaskStatePending))
+							Expect(tsk.Data["mode"]).To(Equal(oauthFetch.TaskModeBackfill))
+							Expect(tsk.Priority).To(Equal(oauthFetch.BackfillPriority))
+							Expect(*tsk.AvailableTime).To(BeTemporally("<=", time.Now().Add(oauthFetch.BackfillAvailableAfterDuration)))
+							Expect(backfills).To(HaveLen(1))
+							Expect(backfills[0].Progress).To(Equal(33.3))
+							Expect(backfills[0].CompletedTime).To(BeNil())
+						})
+					})
+
+					It("fetches from the initial data time in windows of the fetch duration", func() {
+						dataSource.LatestDataTime = nil
+						dataClient.UpdateDataSourceOutputs = []dataTest.UpdateDataSourceOutput{{DataSource: dataSource, Error: nil}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeFalse())
+						Expect(len(fetchClient.FetchRecordsInputs)).To(BeNumerically(">=", 5))
+						Expect(fetchClient.FetchRecordsInputs[0].StartTime).To(Equal(definition.InitialDataTime))
+						Expect(fetchClient.FetchRecordsInputs[0].EndTime).To(Equal(definition.InitialDataTime.Add(definition.FetchDuration)))
+						Expect(fetchClient.FetchRecordsInputs[1].StartTime).To(Equal(fetchClient.FetchRecordsInputs[0].EndTime))
+					})
+				})
+			})
+		})
+	})
+})
+
+func pointerFromTime(value time.Time) *time.Time {
+	return &value
+}
@@ -0,0 +1,39 @@
+package fetch
+
+import (
+	"fmt"
+
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+)
+
+const (
+	TaskModeBackfill    = "backfill"
+	TaskModeIncremental = "incremental"
+)
+
+func TaskName(typ string, providerSessionID string) string {
+	return fmt.Sprintf("%s:%s", typ, providerSessionID)
+}
+
+func NewTaskCreate(typ string, providerSessionID string, dataSourceID string) (*task.TaskCreate, error) {
+	if typ == "" {
+		return nil, errors.New("type is missing")
+	}
+	if providerSessionID == "" {
+		return nil, errors.New("provider session id is missing")
+	}
+	if dataSourceID == "" {
+		return nil, errors.New("data source id is missing")
+	}
+
+	return &task.TaskCreate{
+		Name: pointer.FromString(TaskName(typ, providerSessionID)),
+		Type: typ,
+		Data: map[string]interface{}{
+			"providerSessionId": providerSessionID,
+			"dataSourceId":      dataSourceID,
+		},
+	}, nil
+}
@@ -0,0 +1,60 @@
+package fetch_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	oauthFetch "github.com/tidepool-org/platform/oauth/fetch"
+	"github.com/tidepool-org/platform/task"
+	"github.com/tidepool-org/platform/test"
+)
+
+var _ = Describe("Task", func() {
+	var typ string
+	var providerSessionID string
+	var dataSourceID string
+
+	BeforeEach(func() {
+		typ = test.RandomString()
+		providerSessionID = test.RandomString()
+		dataSourceID = test.RandomString()
+	})
+
+	Context("TaskName", func() {
+		It("returns the task name", func() {
+			Expect(oauthFetch.TaskName(typ, providerSessionID)).To(Equal(typ + ":" + providerSessionID))
+		})
+	})
+
+	Context("NewTaskCreate", func() {
+		It("returns an error if the type is missing", func() {
+			taskCreate, err := oauthFetch.NewTaskCreate("", providerSessionID, dataSourceID)
+			Expect(err).To(MatchError("type is missing"))
+			Expect(taskCreate).To(BeNil())
+		})
+
+		It("returns an error if the provider session id is missing", func() {
+			taskCreate, err := oauthFetch.NewTaskCreate(typ, "", dataSourceID)
+			Expect(err).To(MatchError("provider session id is missing"))
+			Expect(taskCreate).To(BeNil())
+		})
+
+		It("returns an error if the data source id is missing", func() {
+			taskCreate, err := oauthFetch.NewTaskCreate(typ, providerSessionID, "")
+			Expect(err).To(MatchError("data source id is missing"))
+			Expect(taskCreate).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			name := typ + ":" + providerSessionID
+			Expect(oauthFetch.NewTaskCreate(typ, providerSessionID, dataSourceID)).To(Equal(&task.TaskCreate{
+				Name: &name,
+				Type: typ,
+				Data: map[string]interface{}{
+					"providerSessionId": providerSessionID,
+					"dataSourceId":      dataSourceID,
+				},
+			}))
+		})
+	})
+})
@@ -2,7 +2,11 @@ package oauth
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"net/http"
+	"regexp"
 	"strings"
 	"time"
 
@@ -112,4 +116,65 @@ func IsRefreshTokenError(err error) bool {
 	return err != nil && strings.Contains(errors.Cause(err).Error(), "oauth2: cannot fetch token: 400 Bad Request")
 }
 
-const ErrorAccessDenied = "access_denied"
+const (
+	ErrorAccessDenied            = "access_denied"
+	ErrorInvalidClient           = "invalid_client"
+	ErrorInvalidGrant            = "invalid_grant"
+	ErrorInvalidRequest          = "invalid_request"
+	ErrorInvalidScope            = "invalid_scope"
+	ErrorServerError             = "server_error"
+	ErrorUnauthorizedClient      = "unauthorized_client"
+	ErrorUnsupportedGrantType    = "unsupported_grant_type"
+	ErrorUnsupportedResponseType = "unsupported_response_type"
+)
+
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+)
+
+func GrantTypes() []string {
+	return []string{
+		GrantTypeAuthorizationCode,
+		GrantTypeRefreshToken,
+	}
+}
+
+const ResponseTypeCode = "code"
+
+const TokenTypeBearer = "Bearer"
+
+const CodeChallengeMethodS256 = "S256"
+
+func CodeChallengeMethods() []string {
+	return []string{
+		CodeChallengeMethodS256,
+	}
+}
+
+func CalculateCodeChallengeS256(codeVerifier string) string {
+	sha256Sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sha256Sum[:])
+}
+
+func VerifyCodeChallenge(codeChallenge string, codeChallengeMethod string, codeVerifier string) bool {
+	switch codeChallengeMethod {
+	case CodeChallengeMethodS256:
+		return codeVerifier != "" && subtle.ConstantTimeCompare([]byte(CalculateCodeChallengeS256(codeVerifier)), []byte(codeChallenge)) == 1
+	}
+	return false
+}
+
+func ParseScope(scope string) []string {
+	return strings.Fields(scope)
+}
+
+func FormatScope(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+// RFC 7636, Section 4.1
+var CodeVerifierExpression = regexp.MustCompile("^[A-Za-z0-9._~-]{43,128}$")
+
+// RFC 7636, Section 4.2
+var CodeChallengeExpression = regexp.MustCompile("^[A-Za-z0-9_-]{43}$")
@@ -5,8 +5,36 @@ import (
 
 	"github.com/tidepool-org/platform/crypto"
 	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/user"
 )
 
+// Grant is the permissions a target user has granted to a user for the data of the target user
+type Grant struct {
+	UserID       string           `json:"userId"`
+	TargetUserID string           `json:"targetUserId"`
+	Permissions  user.Permissions `json:"permissions"`
+}
+
+type Grants []*Grant
+
+// GrantablePermissions returns the permissions that may be granted to another user; the owner permission may not
+func GrantablePermissions() []string {
+	return []string{
+		user.CustodianPermission,
+		user.UploadPermission,
+		user.ViewPermission,
+	}
+}
+
+func IsGrantablePermission(permission string) bool {
+	for _, grantablePermission := range GrantablePermissions() {
+		if permission == grantablePermission {
+			return true
+		}
+	}
+	return false
+}
+
 func GroupIDFromUserID(userID string, secret string) (string, error) {
 	if userID == "" {
 		return "", errors.New("user id is missing")
@@ -9,6 +9,22 @@ import (
 )
 
 var _ = Describe("permission", func() {
+	It("GrantablePermissions returns expected", func() {
+		Expect(permission.GrantablePermissions()).To(Equal([]string{"custodian", "upload", "view"}))
+	})
+
+	DescribeTable("IsGrantablePermission",
+		func(value string, expected bool) {
+			Expect(permission.IsGrantablePermission(value)).To(Equal(expected))
+		},
+		Entry("is empty", "", false),
+		Entry("is owner", "root", false),
+		Entry("is custodian", "custodian", true),
+		Entry("is upload", "upload", true),
+		Entry("is view", "view", true),
+		Entry("is unknown", "note", false),
+	)
+
 	Context("GroupIDFromUserID", func() {
 		It("returns an error if the user id is missing", func() {
 			groupID, err := permission.GroupIDFromUserID("", "secret")
@@ -11,6 +11,7 @@ import (
 	"github.com/tidepool-org/platform/permission"
 	"github.com/tidepool-org/platform/permission/store"
 	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
+	"github.com/tidepool-org/platform/user"
 )
 
 func NewStore(cfg *Config, lgr log.Logger) (*Store, error) {
@@ -50,6 +51,227 @@ type PermissionsSession struct {
 	config *Config
 }
 
+// The target user of a permissions document is identified by the group id, which is the encrypted target user id.
+// Every user also has a permissions document granting themselves the owner permission, which is not a grant.
+type permissionsDocument struct {
+	GroupID     string           `bson:"groupId"`
+	UserID      string           `bson:"userId"`
+	Permissions user.Permissions `bson:"permissions"`
+}
+
+func (p *PermissionsSession) ListGrantsForTargetUser(ctx context.Context, targetUserID string) (permission.Grants, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if targetUserID == "" {
+		return nil, errors.New("target user id is missing")
+	}
+
+	if p.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	startTime := time.Now()
+
+	groupID, err := permission.GroupIDFromUserID(targetUserID, p.config.Secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to determine group id from user id")
+	}
+
+	documents := []permissionsDocument{}
+	selector := bson.M{
+		"groupId": groupID,
+		"userId":  bson.M{"$ne": targetUserID},
+	}
+	err = p.C().Find(selector).All(&documents)
+
+	loggerFields := log.Fields{"targetUserId": targetUserID, "count": len(documents), "duration": time.Since(startTime) / time.Microsecond}
+	log.LoggerFromContext(ctx).WithFields(loggerFields).WithError(err).Debug("ListGrantsForTargetUser")
+
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list grants for target user")
+	}
+
+	grants := permission.Grants{}
+	for _, document := range documents {
+		grants = append(grants, &permission.Grant{UserID: document.UserID, TargetUserID: targetUserID, Permissions: document.Permissions})
+	}
+	return grants, nil
+}
+
+func (p *PermissionsSession) ListGrantsForUser(ctx context.Context, userID string) (permission.Grants, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+
+	if p.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	startTime := time.Now()
+
+	documents := []permissionsDocument{}
+	err := p.C().Find(bson.M{"userId": userID}).All(&documents)
+
+	loggerFields := log.Fields{"userId": userID, "count": len(documents), "duration": time.Since(startTime) / time.Microsecond}
+	log.LoggerFromContext(ctx).WithFields(loggerFields).WithError(err).Debug("ListGrantsForUser")
+
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list grants for user")
+	}
+
+	// A malformed group id only affects the one grant, so skip it rather than fail the entire list
+	grants := permission.Grants{}
+	for _, document := range documents {
+		targetUserID, err := permission.UserIDFromGroupID(document.GroupID, p.config.Secret)
+		if err != nil {
+			log.LoggerFromContext(ctx).WithFields(log.Fields{"userId": userID, "groupId": document.GroupID}).WithError(err).Warn("Unable to determine user id from group id")
+			continue
+		}
+		if targetUserID != userID {
+			grants = append(grants, &permission.Grant{UserID: userID, TargetUserID: targetUserID, Permissions: document.Permissions})
+		}
+	}
+	return grants, nil
+}
+
+func (p *PermissionsSession) GetGrant(ctx context.Context, targetUserID string, userID string) (*permission.Grant, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if targetUserID == "" {
+		return nil, errors.New("target user id is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+	if targetUserID == userID {
+		return nil, errors.New("user id is same as target user id")
+	}
+
+	if p.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	startTime := time.Now()
+
+	groupID, err := permission.GroupIDFromUserID(targetUserID, p.config.Secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to determine group id from user id")
+	}
+
+	documents := []permissionsDocument{}
+	selector := bson.M{
+		"groupId": groupID,
+		"userId":  userID,
+	}
+	err = p.C().Find(selector).Limit(2).All(&documents)
+
+	loggerFields := log.Fields{"targetUserId": targetUserID, "userId": userID, "duration": time.Since(startTime) / time.Microsecond}
+	log.LoggerFromContext(ctx).WithFields(loggerFields).WithError(err).Debug("GetGrant")
+
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get grant")
+	}
+
+	if count := len(documents); count == 0 {
+		return nil, nil
+	} else if count > 1 {
+		log.LoggerFromContext(ctx).WithFields(log.Fields{"targetUserId": targetUserID, "userId": userID}).Warn("Multiple grants found for target user id and user id")
+	}
+
+	return &permission.Grant{UserID: userID, TargetUserID: targetUserID, Permissions: documents[0].Permissions}, nil
+}
+
+// UpdateGrant replaces the permissions granted by the target user to the user. Empty permissions destroy the grant.
+func (p *PermissionsSession) UpdateGrant(ctx context.Context, targetUserID string, userID string, permissions user.Permissions) (*permission.Grant, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if targetUserID == "" {
+		return nil, errors.New("target user id is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+	if targetUserID == userID {
+		return nil, errors.New("user id is same as target user id")
+	}
+
+	if len(permissions) == 0 {
+		return nil, p.DestroyGrant(ctx, targetUserID, userID)
+	}
+
+	if p.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	startTime := time.Now()
+
+	groupID, err := permission.GroupIDFromUserID(targetUserID, p.config.Secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to determine group id from user id")
+	}
+
+	selector := bson.M{
+		"groupId": groupID,
+		"userId":  userID,
+	}
+	changeInfo, err := p.C().Upsert(selector, bson.M{"$set": bson.M{"permissions": permissions}})
+
+	loggerFields := log.Fields{"targetUserId": targetUserID, "userId": userID, "permissions": permissions, "changeInfo": changeInfo, "duration": time.Since(startTime) / time.Microsecond}
+	log.LoggerFromContext(ctx).WithFields(loggerFields).WithError(err).Debug("UpdateGrant")
+
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to update grant")
+	}
+
+	return &permission.Grant{UserID: userID, TargetUserID: targetUserID, Permissions: permissions}, nil
+}
+
+func (p *PermissionsSession) DestroyGrant(ctx context.Context, targetUserID string, userID string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if targetUserID == "" {
+		return errors.New("target user id is missing")
+	}
+	if userID == "" {
+		return errors.New("user id is missing")
+	}
+	if targetUserID == userID {
+		return errors.New("user id is same as target user id")
+	}
+
+	if p.IsClosed() {
+		return errors.New("session closed")
+	}
+
+	startTime := time.Now()
+
+	groupID, err := permission.GroupIDFromUserID(targetUserID, p.config.Secret)
+	if err != nil {
+		return errors.Wrap(err, "unable to determine group id from user id")
+	}
+
+	selector := bson.M{
+		"groupId": groupID,
+		"userId":  userID,
+	}
+	removeInfo, err := p.C().RemoveAll(selector)
+
+	loggerFields := log.Fields{"targetUserId": targetUserID, "userId": userID, "removeInfo": removeInfo, "duration": time.Since(startTime) / time.Microsecond}
+	log.LoggerFromContext(ctx).WithFields(loggerFields).WithError(err).Debug("DestroyGrant")
+
+	if err != nil {
+		return errors.Wrap(err, "unable to destroy grant")
+	}
+	return nil
+}
+
 func (p *PermissionsSession) DestroyPermissionsForUserByID(ctx context.Context, userID string) error {
 	if ctx == nil {
 		return errors.New("context is missing")
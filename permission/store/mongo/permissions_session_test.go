@@ -0,0 +1,175 @@
+package mongo_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/tidepool-org/platform/log"
+	logNull "github.com/tidepool-org/platform/log/null"
+	"github.com/tidepool-org/platform/permission"
+	"github.com/tidepool-org/platform/permission/store"
+	"github.com/tidepool-org/platform/permission/store/mongo"
+	storeStructuredMongoTest "github.com/tidepool-org/platform/store/structured/mongo/test"
+	"github.com/tidepool-org/platform/user"
+)
+
+var _ = Describe("PermissionsSession", func() {
+	var cfg *mongo.Config
+	var str *mongo.Store
+	var ssn store.PermissionsSession
+	var testMongoSession *mgo.Session
+	var testMongoCollection *mgo.Collection
+	var ctx context.Context
+	var targetUserID string
+	var userID string
+
+	insertPermissions := func(targetUserID string, userID string, permissions user.Permissions) {
+		groupID, err := permission.GroupIDFromUserID(targetUserID, cfg.Secret)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testMongoCollection.Insert(bson.M{"groupId": groupID, "userId": userID, "permissions": permissions})).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		cfg = mongo.NewConfig()
+		cfg.Config = storeStructuredMongoTest.NewConfig()
+		cfg.Secret = "secret"
+		str, err = mongo.NewStore(cfg, logNull.NewLogger())
+		Expect(err).ToNot(HaveOccurred())
+		ssn = str.NewPermissionsSession()
+		testMongoSession = storeStructuredMongoTest.Session().Copy()
+		testMongoCollection = testMongoSession.DB(cfg.Database).C(cfg.CollectionPrefix + "perms")
+		ctx = log.NewContextWithLogger(context.Background(), logNull.NewLogger())
+		targetUserID = user.NewID()
+		userID = user.NewID()
+		insertPermissions(targetUserID, targetUserID, user.Permissions{user.OwnerPermission: {}})
+		insertPermissions(userID, userID, user.Permissions{user.OwnerPermission: {}})
+		insertPermissions(targetUserID, userID, user.Permissions{user.ViewPermission: {}})
+	})
+
+	AfterEach(func() {
+		if testMongoSession != nil {
+			testMongoSession.Close()
+		}
+		if ssn != nil {
+			ssn.Close()
+		}
+		if str != nil {
+			str.Close()
+		}
+	})
+
+	Context("ListGrantsForTargetUser", func() {
+		It("returns an error if the target user id is missing", func() {
+			grants, err := ssn.ListGrantsForTargetUser(ctx, "")
+			Expect(err).To(MatchError("target user id is missing"))
+			Expect(grants).To(BeNil())
+		})
+
+		It("returns the grants for the target user, excluding the owner permissions", func() {
+			Expect(ssn.ListGrantsForTargetUser(ctx, targetUserID)).To(Equal(permission.Grants{
+				{UserID: userID, TargetUserID: targetUserID, Permissions: user.Permissions{user.ViewPermission: {}}},
+			}))
+		})
+
+		It("returns no grants if the target user has not granted any", func() {
+			Expect(ssn.ListGrantsForTargetUser(ctx, userID)).To(BeEmpty())
+		})
+	})
+
+	Context("ListGrantsForUser", func() {
+		It("returns an error if the user id is missing", func() {
+			grants, err := ssn.ListGrantsForUser(ctx, "")
+			Expect(err).To(MatchError("user id is missing"))
+			Expect(grants).To(BeNil())
+		})
+
+		It("returns the grants for the user, excluding the owner permissions", func() {
+			Expect(ssn.ListGrantsForUser(ctx, userID)).To(Equal(permission.Grants{
+				{UserID: userID, TargetUserID: targetUserID, Permissions: user.Permissions{user.ViewPermission: {}}},
+			}))
+		})
+
+		It("skips grants with a malformed group id", func() {
+			Expect(testMongoCollection.Insert(bson.M{"groupId": "malformed", "userId": userID, "permissions": user.Permissions{user.ViewPermission: {}}})).To(Succeed())
+			Expect(ssn.ListGrantsForUser(ctx, userID)).To(HaveLen(1))
+		})
+	})
+
+	Context("GetGrant", func() {
+		It("returns an error if the target user id is missing", func() {
+			grant, err := ssn.GetGrant(ctx, "", userID)
+			Expect(err).To(MatchError("target user id is missing"))
+			Expect(grant).To(BeNil())
+		})
+
+		It("returns an error if the user id is missing", func() {
+			grant, err := ssn.GetGrant(ctx, targetUserID, "")
+			Expect(err).To(MatchError("user id is missing"))
+			Expect(grant).To(BeNil())
+		})
+
+		It("returns an error if the user id is the target user id", func() {
+			grant, err := ssn.GetGrant(ctx, targetUserID, targetUserID)
+			Expect(err).To(MatchError("user id is same as target user id"))
+			Expect(grant).To(BeNil())
+		})
+
+		It("returns nil if the grant does not exist", func() {
+			Expect(ssn.GetGrant(ctx, userID, targetUserID)).To(BeNil())
+		})
+
+		It("returns the grant", func() {
+			Expect(ssn.GetGrant(ctx, targetUserID, userID)).To(Equal(&permission.Grant{UserID: userID, TargetUserID: targetUserID, Permissions: user.Permissions{user.ViewPermission: {}}}))
+		})
+	})
+
+	Context("UpdateGrant", func() {
+		It("returns an error if the user id is the target user id", func() {
+			grant, err := ssn.UpdateGrant(ctx, targetUserID, targetUserID, user.Permissions{user.ViewPermission: {}})
+			Expect(err).To(MatchError("user id is same as target user id"))
+			Expect(grant).To(BeNil())
+		})
+
+		It("replaces the permissions of an existing grant", func() {
+			permissions := user.Permissions{user.UploadPermission: {}}
+			Expect(ssn.UpdateGrant(ctx, targetUserID, userID, permissions)).To(Equal(&permission.Grant{UserID: userID, TargetUserID: targetUserID, Permissions: permissions}))
+			Expect(ssn.GetGrant(ctx, targetUserID, userID)).To(Equal(&permission.Grant{UserID: userID, TargetUserID: targetUserID, Permissions: permissions}))
+			Expect(testMongoCollection.Count()).To(Equal(3))
+		})
+
+		It("creates a grant if it does not exist", func() {
+			permissions := user.Permissions{user.ViewPermission: {}}
+			Expect(ssn.UpdateGrant(ctx, userID, targetUserID, permissions)).To(Equal(&permission.Grant{UserID: targetUserID, TargetUserID: userID, Permissions: permissions}))
+			Expect(ssn.GetGrant(ctx, userID, targetUserID)).To(Equal(&permission.Grant{UserID: targetUserID, TargetUserID: userID, Permissions: permissions}))
+		})
+
+		It("destroys the grant if the permissions are empty", func() {
+			Expect(ssn.UpdateGrant(ctx, targetUserID, userID, user.Permissions{})).To(BeNil())
+			Expect(ssn.GetGrant(ctx, targetUserID, userID)).To(BeNil())
+			Expect(ssn.ListGrantsForTargetUser(ctx, targetUserID)).To(BeEmpty())
+		})
+	})
+
+	Context("DestroyGrant", func() {
+		It("returns an error if the user id is the target user id", func() {
+			Expect(ssn.DestroyGrant(ctx, targetUserID, targetUserID)).To(MatchError("user id is same as target user id"))
+		})
+
+		It("destroys the grant, but not the owner permissions", func() {
+			Expect(ssn.DestroyGrant(ctx, targetUserID, userID)).To(Succeed())
+			Expect(ssn.GetGrant(ctx, targetUserID, userID)).To(BeNil())
+			Expect(testMongoCollection.Count()).To(Equal(2))
+		})
+
+		It("succeeds if the grant does not exist", func() {
+			Expect(ssn.DestroyGrant(ctx, userID, targetUserID)).To(Succeed())
+			Expect(testMongoCollection.Count()).To(Equal(3))
+		})
+	})
+})
@@ -3,6 +3,9 @@ package store
 import (
 	"context"
 	"io"
+
+	"github.com/tidepool-org/platform/permission"
+	"github.com/tidepool-org/platform/user"
 )
 
 type Store interface {
@@ -12,5 +15,10 @@ type Store interface {
 type PermissionsSession interface {
 	io.Closer
 
+	ListGrantsForTargetUser(ctx context.Context, targetUserID string) (permission.Grants, error)
+	ListGrantsForUser(ctx context.Context, userID string) (permission.Grants, error)
+	GetGrant(ctx context.Context, targetUserID string, userID string) (*permission.Grant, error)
+	UpdateGrant(ctx context.Context, targetUserID string, userID string, permissions user.Permissions) (*permission.Grant, error)
+	DestroyGrant(ctx context.Context, targetUserID string, userID string) error
 	DestroyPermissionsForUserByID(ctx context.Context, userID string) error
 }
@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tidepool-org/platform/errors"
+)
+
+const MaintenanceWindowTimeFormat = "15:04"
+
+// MaintenanceWindow is a daily time range, in a specific location, during which a provider is
+// known to be unavailable (e.g. "America/Los_Angeles 02:45-03:45"). The range may span midnight.
+type MaintenanceWindow struct {
+	Location    *time.Location
+	StartOffset time.Duration
+	EndOffset   time.Duration
+}
+
+func ParseMaintenanceWindow(value string) (*MaintenanceWindow, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return nil, errors.Newf("maintenance window %q is invalid", value)
+	}
+
+	location, err := time.LoadLocation(fields[0])
+	if err != nil {
+		return nil, errors.Wrapf(err, "maintenance window %q location is invalid", value)
+	}
+
+	times := strings.Split(fields[1], "-")
+	if len(times) != 2 {
+		return nil, errors.Newf("maintenance window %q time range is invalid", value)
+	}
+
+	startOffset, err := parseMaintenanceWindowOffset(times[0])
+	if err != nil {
+		return nil, errors.Wrapf(err, "maintenance window %q start time is invalid", value)
+	}
+	endOffset, err := parseMaintenanceWindowOffset(times[1])
+	if err != nil {
+		return nil, errors.Wrapf(err, "maintenance window %q end time is invalid", value)
+	}
+	if startOffset == endOffset {
+		return nil, errors.Newf("maintenance window %q time range is empty", value)
+	}
+
+	return &MaintenanceWindow{
+		Location:    location,
+		StartOffset: startOffset,
+		EndOffset:   endOffset,
+	}, nil
+}
+
+func (m *MaintenanceWindow) String() string {
+	return fmt.Sprintf("%s %s-%s", m.Location.String(), formatMaintenanceWindowOffset(m.StartOffset), formatMaintenanceWindowOffset(m.EndOffset))
+}
+
+func (m *MaintenanceWindow) Contains(tm time.Time) bool {
+	offset := m.offset(tm)
+	if m.StartOffset < m.EndOffset {
+		return offset >= m.StartOffset && offset < m.EndOffset
+	}
+	return offset >= m.StartOffset || offset < m.EndOffset
+}
+
+// EndTime returns the end of the maintenance window that contains the specified time or, if the
+// specified time is not within the maintenance window, the end of the next maintenance window
+func (m *MaintenanceWindow) EndTime(tm time.Time) time.Time {
+	local := tm.In(m.Location)
+	year, month, day := local.Date()
+	endTime := time.Date(year, month, day, 0, 0, 0, 0, m.Location).Add(m.EndOffset)
+	if !endTime.After(local) {
+		endTime = time.Date(year, month, day+1, 0, 0, 0, 0, m.Location).Add(m.EndOffset)
+	}
+	return endTime
+}
+
+func (m *MaintenanceWindow) offset(tm time.Time) time.Duration {
+	hour, minute, second := tm.In(m.Location).Clock()
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(second)*time.Second
+}
+
+type MaintenanceWindows []*MaintenanceWindow
+
+func ParseMaintenanceWindows(value string) (MaintenanceWindows, error) {
+	maintenanceWindows := MaintenanceWindows{}
+	for _, maintenanceWindowValue := range strings.Split(value, ",") {
+		if maintenanceWindowValue = strings.TrimSpace(maintenanceWindowValue); maintenanceWindowValue != "" {
+			maintenanceWindow, err := ParseMaintenanceWindow(maintenanceWindowValue)
+			if err != nil {
+				return nil, err
+			}
+			maintenanceWindows = append(maintenanceWindows, maintenanceWindow)
+		}
+	}
+	return maintenanceWindows, nil
+}
+
+func (m MaintenanceWindows) String() string {
+	values := []string{}
+	for _, maintenanceWindow := range m {
+		values = append(values, maintenanceWindow.String())
+	}
+	return strings.Join(values, ",")
+}
+
+// Active returns the first maintenance window that contains the specified time, if any
+func (m MaintenanceWindows) Active(tm time.Time) *MaintenanceWindow {
+	for _, maintenanceWindow := range m {
+		if maintenanceWindow.Contains(tm) {
+			return maintenanceWindow
+		}
+	}
+	return nil
+}
+
+func parseMaintenanceWindowOffset(value string) (time.Duration, error) {
+	tm, err := time.Parse(MaintenanceWindowTimeFormat, value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(tm.Hour())*time.Hour + time.Duration(tm.Minute())*time.Minute, nil
+}
+
+func formatMaintenanceWindowOffset(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset/time.Hour), int((offset%time.Hour)/time.Minute))
+}
+
+// MaintenanceWindowsReporter is implemented by providers with known maintenance windows during which
+// requests to the provider, including token refreshes, should not be made
+type MaintenanceWindowsReporter interface {
+	MaintenanceWindows() MaintenanceWindows
+}
@@ -0,0 +1,130 @@
+package provider_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"time"
+
+	"github.com/tidepool-org/platform/provider"
+)
+
+var _ = Describe("MaintenanceWindow", func() {
+	var location *time.Location
+
+	BeforeEach(func() {
+		var err error
+		location, err = time.LoadLocation("America/Los_Angeles")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("ParseMaintenanceWindow", func() {
+		DescribeTable("returns an error when the value",
+			func(value string, expectedError string) {
+				maintenanceWindow, err := provider.ParseMaintenanceWindow(value)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(HavePrefix(expectedError))
+				Expect(maintenanceWindow).To(BeNil())
+			},
+			Entry("is empty", "", `maintenance window "" is invalid`),
+			Entry("is missing time range", "America/Los_Angeles", `maintenance window "America/Los_Angeles" is invalid`),
+			Entry("has invalid location", "Invalid/Location 02:45-03:45", `maintenance window "Invalid/Location 02:45-03:45" location is invalid`),
+			Entry("has invalid time range", "UTC 02:45", `maintenance window "UTC 02:45" time range is invalid`),
+			Entry("has invalid start time", "UTC 2:4x-03:45", `maintenance window "UTC 2:4x-03:45" start time is invalid`),
+			Entry("has invalid end time", "UTC 02:45-25:00", `maintenance window "UTC 02:45-25:00" end time is invalid`),
+			Entry("has empty time range", "UTC 02:45-02:45", `maintenance window "UTC 02:45-02:45" time range is empty`),
+		)
+
+		It("returns successfully", func() {
+			maintenanceWindow, err := provider.ParseMaintenanceWindow("America/Los_Angeles 02:45-03:45")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(maintenanceWindow).ToNot(BeNil())
+			Expect(maintenanceWindow.Location.String()).To(Equal("America/Los_Angeles"))
+			Expect(maintenanceWindow.StartOffset).To(Equal(2*time.Hour + 45*time.Minute))
+			Expect(maintenanceWindow.EndOffset).To(Equal(3*time.Hour + 45*time.Minute))
+			Expect(maintenanceWindow.String()).To(Equal("America/Los_Angeles 02:45-03:45"))
+		})
+	})
+
+	Context("with maintenance window", func() {
+		var maintenanceWindow *provider.MaintenanceWindow
+
+		BeforeEach(func() {
+			var err error
+			maintenanceWindow, err = provider.ParseMaintenanceWindow("America/Los_Angeles 02:45-03:45")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		DescribeTable("Contains returns expected result when the time",
+			func(hour int, minute int, expected bool) {
+				Expect(maintenanceWindow.Contains(time.Date(2018, 10, 1, hour, minute, 0, 0, location))).To(Equal(expected))
+			},
+			Entry("is before the start", 2, 44, false),
+			Entry("is at the start", 2, 45, true),
+			Entry("is within", 3, 15, true),
+			Entry("is at the end", 3, 45, false),
+			Entry("is after the end", 4, 0, false),
+		)
+
+		It("Contains returns true for a time in a different location", func() {
+			Expect(maintenanceWindow.Contains(time.Date(2018, 10, 1, 10, 0, 0, 0, time.UTC))).To(BeTrue())
+		})
+
+		It("EndTime returns the end of the containing maintenance window", func() {
+			Expect(maintenanceWindow.EndTime(time.Date(2018, 10, 1, 3, 0, 0, 0, location))).To(Equal(time.Date(2018, 10, 1, 3, 45, 0, 0, location)))
+		})
+
+		It("EndTime returns the end of the next maintenance window if after the end", func() {
+			Expect(maintenanceWindow.EndTime(time.Date(2018, 10, 1, 4, 0, 0, 0, location))).To(Equal(time.Date(2018, 10, 2, 3, 45, 0, 0, location)))
+		})
+	})
+
+	Context("with maintenance window spanning midnight", func() {
+		var maintenanceWindow *provider.MaintenanceWindow
+
+		BeforeEach(func() {
+			var err error
+			maintenanceWindow, err = provider.ParseMaintenanceWindow("UTC 23:30-00:30")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		DescribeTable("Contains returns expected result when the time",
+			func(hour int, minute int, expected bool) {
+				Expect(maintenanceWindow.Contains(time.Date(2018, 10, 1, hour, minute, 0, 0, time.UTC))).To(Equal(expected))
+			},
+			Entry("is before the start", 23, 29, false),
+			Entry("is at the start", 23, 30, true),
+			Entry("is after midnight", 0, 15, true),
+			Entry("is at the end", 0, 30, false),
+		)
+
+		It("EndTime returns the end of the containing maintenance window on the next day", func() {
+			Expect(maintenanceWindow.EndTime(time.Date(2018, 10, 1, 23, 45, 0, 0, time.UTC))).To(Equal(time.Date(2018, 10, 2, 0, 30, 0, 0, time.UTC)))
+		})
+	})
+
+	Context("ParseMaintenanceWindows", func() {
+		It("returns no maintenance windows if the value is empty", func() {
+			maintenanceWindows, err := provider.ParseMaintenanceWindows("")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(maintenanceWindows).To(BeEmpty())
+		})
+
+		It("returns an error if any maintenance window is invalid", func() {
+			maintenanceWindows, err := provider.ParseMaintenanceWindows("UTC 01:00-02:00,invalid")
+			Expect(err).To(MatchError(`maintenance window "invalid" is invalid`))
+			Expect(maintenanceWindows).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			maintenanceWindows, err := provider.ParseMaintenanceWindows("UTC 01:00-02:00, America/Los_Angeles 02:45-03:45")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(maintenanceWindows).To(HaveLen(2))
+			Expect(maintenanceWindows.String()).To(Equal("UTC 01:00-02:00,America/Los_Angeles 02:45-03:45"))
+			Expect(maintenanceWindows.Active(time.Date(2018, 10, 1, 1, 30, 0, 0, time.UTC))).To(Equal(maintenanceWindows[0]))
+			Expect(maintenanceWindows.Active(time.Date(2018, 10, 1, 3, 0, 0, 0, location))).To(Equal(maintenanceWindows[1]))
+			Expect(maintenanceWindows.Active(time.Date(2018, 10, 1, 5, 0, 0, 0, time.UTC))).To(BeNil())
+		})
+	})
+})
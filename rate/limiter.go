@@ -0,0 +1,119 @@
+package rate
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tidepool-org/platform/errors"
+)
+
+// Limiter is a token bucket rate limiter that is safe for concurrent use by multiple workers. The
+// bucket holds at most burst tokens and is refilled at the specified rate of tokens per second.
+// The limiter may also be paused, for example, when a server responds with Retry-After.
+//
+// The limiter state is held in memory and is therefore per-process. Multiple processes sharing
+// an upstream rate limit must each be configured with their proportional share of that limit.
+type Limiter struct {
+	mutex       sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	refillTime  time.Time
+	pausedUntil time.Time
+}
+
+func NewLimiter(rate float64, burst int) (*Limiter, error) {
+	if rate <= 0 {
+		return nil, errors.New("rate is invalid")
+	}
+	if burst < 1 {
+		return nil, errors.New("burst is invalid")
+	}
+
+	return &Limiter{
+		rate:       rate,
+		burst:      float64(burst),
+		tokens:     float64(burst),
+		refillTime: time.Now(),
+	}, nil
+}
+
+func (l *Limiter) Rate() float64 {
+	return l.rate
+}
+
+func (l *Limiter) Burst() int {
+	return int(l.burst)
+}
+
+// Wait blocks until a token is available, the limiter is no longer paused, or the context is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+
+	for {
+		delay := l.reserve()
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Allow returns true and consumes a token if a token is immediately available
+func (l *Limiter) Allow() bool {
+	return l.reserve() <= 0
+}
+
+func (l *Limiter) Pause(duration time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if pausedUntil := time.Now().Add(duration); pausedUntil.After(l.pausedUntil) {
+		l.pausedUntil = pausedUntil
+	}
+}
+
+func (l *Limiter) PauseRemaining() time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if remaining := time.Until(l.pausedUntil); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+func (l *Limiter) reserve() time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+
+	if elapsed := now.Sub(l.refillTime); elapsed > 0 {
+		l.tokens += elapsed.Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.refillTime = now
+	}
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
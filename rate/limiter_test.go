@@ -0,0 +1,108 @@
+package rate_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"time"
+
+	"github.com/tidepool-org/platform/rate"
+)
+
+var _ = Describe("Limiter", func() {
+	Context("NewLimiter", func() {
+		It("returns an error if the rate is invalid", func() {
+			limiter, err := rate.NewLimiter(0, 1)
+			Expect(err).To(MatchError("rate is invalid"))
+			Expect(limiter).To(BeNil())
+		})
+
+		It("returns an error if the burst is invalid", func() {
+			limiter, err := rate.NewLimiter(1, 0)
+			Expect(err).To(MatchError("burst is invalid"))
+			Expect(limiter).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			limiter, err := rate.NewLimiter(2.5, 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(limiter).ToNot(BeNil())
+			Expect(limiter.Rate()).To(Equal(2.5))
+			Expect(limiter.Burst()).To(Equal(3))
+		})
+	})
+
+	Context("with new limiter", func() {
+		var limiter *rate.Limiter
+
+		BeforeEach(func() {
+			var err error
+			limiter, err = rate.NewLimiter(20, 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(limiter).ToNot(BeNil())
+		})
+
+		Context("Allow", func() {
+			It("allows up to the burst and then disallows", func() {
+				Expect(limiter.Allow()).To(BeTrue())
+				Expect(limiter.Allow()).To(BeTrue())
+				Expect(limiter.Allow()).To(BeFalse())
+			})
+
+			It("allows again after the bucket is refilled", func() {
+				Expect(limiter.Allow()).To(BeTrue())
+				Expect(limiter.Allow()).To(BeTrue())
+				Eventually(limiter.Allow, time.Second, 10*time.Millisecond).Should(BeTrue())
+			})
+
+			It("disallows while paused", func() {
+				limiter.Pause(time.Hour)
+				Expect(limiter.Allow()).To(BeFalse())
+			})
+		})
+
+		Context("Wait", func() {
+			It("returns an error if the context is missing", func() {
+				Expect(limiter.Wait(nil)).To(MatchError("context is missing"))
+			})
+
+			It("returns immediately while tokens are available", func() {
+				Expect(limiter.Wait(context.Background())).To(Succeed())
+				Expect(limiter.Wait(context.Background())).To(Succeed())
+			})
+
+			It("waits for the bucket to be refilled", func() {
+				Expect(limiter.Wait(context.Background())).To(Succeed())
+				Expect(limiter.Wait(context.Background())).To(Succeed())
+				startTime := time.Now()
+				Expect(limiter.Wait(context.Background())).To(Succeed())
+				Expect(time.Since(startTime)).To(BeNumerically(">=", 40*time.Millisecond))
+			})
+
+			It("returns an error if the context is done before a token is available", func() {
+				limiter.Pause(time.Hour)
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				defer cancel()
+				Expect(limiter.Wait(ctx)).To(MatchError(context.DeadlineExceeded))
+			})
+		})
+
+		Context("Pause and PauseRemaining", func() {
+			It("returns zero if not paused", func() {
+				Expect(limiter.PauseRemaining()).To(BeZero())
+			})
+
+			It("returns the remaining pause duration", func() {
+				limiter.Pause(time.Minute)
+				Expect(limiter.PauseRemaining()).To(BeNumerically("~", time.Minute, time.Second))
+			})
+
+			It("does not shorten an existing pause", func() {
+				limiter.Pause(time.Hour)
+				limiter.Pause(time.Minute)
+				Expect(limiter.PauseRemaining()).To(BeNumerically("~", time.Hour, time.Second))
+			})
+		})
+	})
+})
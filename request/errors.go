@@ -2,6 +2,7 @@ package request
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/tidepool-org/platform/errors"
 )
@@ -10,6 +11,7 @@ const (
 	ErrorCodeInternalServerError = "internal-server-error"
 	ErrorCodeUnexpectedResponse  = "unexpected-response"
 	ErrorCodeTooManyRequests     = "too-many-requests"
+	ErrorCodeServiceUnavailable  = "service-unavailable"
 	ErrorCodeBadRequest          = "bad-request"
 	ErrorCodeUnauthenticated     = "unauthenticated"
 	ErrorCodeUnauthorized        = "unauthorized"
@@ -33,6 +35,10 @@ func ErrorTooManyRequests() error {
 	return errors.Prepared(ErrorCodeTooManyRequests, "too many requests", "too many requests")
 }
 
+func ErrorServiceUnavailable() error {
+	return errors.Prepared(ErrorCodeServiceUnavailable, "service unavailable", "service unavailable")
+}
+
 func ErrorBadRequest() error {
 	return errors.Prepared(ErrorCodeBadRequest, "bad request", "bad request")
 }
@@ -82,6 +88,8 @@ func StatusCodeForError(err error) int {
 		switch errors.Code(err) {
 		case ErrorCodeTooManyRequests:
 			return http.StatusTooManyRequests
+		case ErrorCodeServiceUnavailable:
+			return http.StatusServiceUnavailable
 		case ErrorCodeBadRequest:
 			return http.StatusBadRequest
 		case ErrorCodeUnauthenticated:
@@ -99,6 +107,14 @@ func IsErrorInternalServerError(err error) bool {
 	return errors.Code(err) == ErrorCodeInternalServerError
 }
 
+func IsErrorTooManyRequests(err error) bool {
+	return errors.Code(err) == ErrorCodeTooManyRequests
+}
+
+func IsErrorServiceUnavailable(err error) bool {
+	return errors.Code(err) == ErrorCodeServiceUnavailable
+}
+
 func IsErrorUnauthenticated(err error) bool {
 	return errors.Code(err) == ErrorCodeUnauthenticated
 }
@@ -110,3 +126,19 @@ func IsErrorUnauthorized(err error) bool {
 func IsErrorResourceNotFound(err error) bool {
 	return errors.Code(err) == ErrorCodeResourceNotFound
 }
+
+type retryAfterMeta struct {
+	RetryAfter int `json:"retryAfter"`
+}
+
+func ErrorWithRetryAfter(err error, retryAfter time.Duration) error {
+	return errors.WithMeta(err, &retryAfterMeta{RetryAfter: int((retryAfter + time.Second - 1) / time.Second)})
+}
+
+func RetryAfterFromError(err error) *time.Duration {
+	if meta, ok := errors.Meta(errors.Cause(err)).(*retryAfterMeta); ok {
+		retryAfter := time.Duration(meta.RetryAfter) * time.Second
+		return &retryAfter
+	}
+	return nil
+}
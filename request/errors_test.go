@@ -4,10 +4,12 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
 
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/tidepool-org/platform/errors"
 	errorsTest "github.com/tidepool-org/platform/errors/test"
@@ -46,6 +48,7 @@ var _ = Describe("Errors", func() {
 	DescribeTable("have expected details when error",
 		errorsTest.ExpectErrorDetails,
 		Entry("is ErrorTooManyRequests", request.ErrorTooManyRequests(), "too-many-requests", "too many requests", "too many requests"),
+		Entry("is ErrorServiceUnavailable", request.ErrorServiceUnavailable(), "service-unavailable", "service unavailable", "service unavailable"),
 		Entry("is ErrorBadRequest", request.ErrorBadRequest(), "bad-request", "bad request", "bad request"),
 		Entry("is ErrorUnauthenticated", request.ErrorUnauthenticated(), "unauthenticated", "authentication token is invalid", "authentication token is invalid"),
 		Entry("is ErrorUnauthorized", request.ErrorUnauthorized(), "unauthorized", "authentication token is not authorized for requested action", "authentication token is not authorized for requested action"),
@@ -65,6 +68,7 @@ var _ = Describe("Errors", func() {
 				Expect(request.StatusCodeForError(err)).To(Equal(expectedStatusCode))
 			},
 			Entry("is ErrorTooManyRequests", request.ErrorTooManyRequests(), 429),
+			Entry("is ErrorServiceUnavailable", request.ErrorServiceUnavailable(), 503),
 			Entry("is ErrorBadRequest", request.ErrorBadRequest(), 400),
 			Entry("is ErrorUnauthenticated", request.ErrorUnauthenticated(), 401),
 			Entry("is ErrorUnauthorized", request.ErrorUnauthorized(), 403),
@@ -132,4 +136,53 @@ var _ = Describe("Errors", func() {
 			Expect(request.IsErrorResourceNotFound(request.ErrorResourceNotFound())).To(BeTrue())
 		})
 	})
+
+	Context("IsErrorTooManyRequests", func() {
+		It("returns false if the error does not have a code", func() {
+			Expect(request.IsErrorTooManyRequests(errors.New("error"))).To(BeFalse())
+		})
+
+		It("returns false if the error code is not ErrorCodeTooManyRequests", func() {
+			Expect(request.IsErrorTooManyRequests(request.ErrorServiceUnavailable())).To(BeFalse())
+		})
+
+		It("returns true if the error code is ErrorCodeTooManyRequests", func() {
+			Expect(request.IsErrorTooManyRequests(request.ErrorTooManyRequests())).To(BeTrue())
+		})
+	})
+
+	Context("IsErrorServiceUnavailable", func() {
+		It("returns false if the error does not have a code", func() {
+			Expect(request.IsErrorServiceUnavailable(errors.New("error"))).To(BeFalse())
+		})
+
+		It("returns false if the error code is not ErrorCodeServiceUnavailable", func() {
+			Expect(request.IsErrorServiceUnavailable(request.ErrorTooManyRequests())).To(BeFalse())
+		})
+
+		It("returns true if the error code is ErrorCodeServiceUnavailable", func() {
+			Expect(request.IsErrorServiceUnavailable(request.ErrorServiceUnavailable())).To(BeTrue())
+		})
+	})
+
+	Context("RetryAfterFromError", func() {
+		It("returns nil if the error is nil", func() {
+			Expect(request.RetryAfterFromError(nil)).To(BeNil())
+		})
+
+		It("returns nil if the error does not have retry after", func() {
+			Expect(request.RetryAfterFromError(request.ErrorTooManyRequests())).To(BeNil())
+		})
+
+		It("returns the retry after rounded up to the second", func() {
+			err := request.ErrorWithRetryAfter(request.ErrorTooManyRequests(), 1500*time.Millisecond)
+			Expect(request.IsErrorTooManyRequests(err)).To(BeTrue())
+			Expect(request.RetryAfterFromError(err)).To(PointTo(Equal(2 * time.Second)))
+		})
+
+		It("returns the retry after from the cause of a wrapped error", func() {
+			err := errors.Wrap(request.ErrorWithRetryAfter(request.ErrorServiceUnavailable(), time.Minute), "unable to get egvs")
+			Expect(request.RetryAfterFromError(err)).To(PointTo(Equal(time.Minute)))
+		})
+	})
 })
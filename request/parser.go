@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/tidepool-org/platform/crypto"
 	"github.com/tidepool-org/platform/errors"
@@ -221,3 +222,55 @@ func ParseIntHeader(header http.Header, key string) (*int, error) {
 	}
 	return nil, nil
 }
+
+func ParseBoolHeader(header http.Header, key string) (*bool, error) {
+	if values, ok := header[key]; ok {
+		switch len(values) {
+		case 0:
+			return nil, nil
+		case 1:
+			if value, err := strconv.ParseBool(values[0]); err == nil {
+				return &value, nil
+			}
+		}
+		return nil, ErrorHeaderInvalid(key)
+	}
+	return nil, nil
+}
+
+func ParseTimeHeader(header http.Header, key string, layout string) (*time.Time, error) {
+	if values, ok := header[key]; ok {
+		switch len(values) {
+		case 0:
+			return nil, nil
+		case 1:
+			if value, err := time.Parse(layout, values[0]); err == nil {
+				return &value, nil
+			}
+		}
+		return nil, ErrorHeaderInvalid(key)
+	}
+	return nil, nil
+}
+
+func ParseRetryAfterHeader(header http.Header, key string) (*time.Duration, error) {
+	if values, ok := header[key]; ok {
+		switch len(values) {
+		case 0:
+			return nil, nil
+		case 1:
+			if seconds, err := strconv.Atoi(values[0]); err == nil && seconds >= 0 {
+				value := time.Duration(seconds) * time.Second
+				return &value, nil
+			} else if tm, err := http.ParseTime(values[0]); err == nil {
+				value := time.Until(tm).Truncate(time.Second)
+				if value < 0 {
+					value = 0
+				}
+				return &value, nil
+			}
+		}
+		return nil, ErrorHeaderInvalid(key)
+	}
+	return nil, nil
+}
@@ -2,7 +2,9 @@ package request
 
 import (
 	"context"
+	"net"
 	"net/http"
+	"strings"
 
 	"github.com/ant0ine/go-json-rest/rest"
 
@@ -12,6 +14,7 @@ import (
 const (
 	HTTPHeaderTraceRequest = "X-Tidepool-Trace-Request"
 	HTTPHeaderTraceSession = "X-Tidepool-Trace-Session"
+	HTTPHeaderForwardedFor = "X-Forwarded-For"
 )
 
 func CopyTrace(ctx context.Context, req *http.Request) error {
@@ -32,6 +35,73 @@ func CopyTrace(ctx context.Context, req *http.Request) error {
 	return nil
 }
 
+// ParseTrustedProxies parses the networks of the proxies in front of the services. Each value is either a CIDR or a
+// single IP address.
+func ParseTrustedProxies(values []string) ([]*net.IPNet, error) {
+	trustedProxies := []*net.IPNet{}
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, errors.Newf("trusted proxy %q is invalid", value)
+			}
+			if ipv4 := ip.To4(); ipv4 != nil {
+				value += "/32"
+			} else {
+				value += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, errors.Newf("trusted proxy %q is invalid", value)
+		}
+		trustedProxies = append(trustedProxies, network)
+	}
+	return trustedProxies, nil
+}
+
+// ClientIPAddress returns the IP address of the client. Every address in the X-Forwarded-For header other than those
+// appended by trusted proxies is controlled by the client, so the header is walked from the remote address of the
+// request towards the client and the first address not of a trusted proxy is returned.
+func ClientIPAddress(req *http.Request, trustedProxies []*net.IPNet) string {
+	if req == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return ""
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ""
+	}
+
+	addresses := []string{}
+	for _, value := range req.Header[HTTPHeaderForwardedFor] {
+		addresses = append(addresses, strings.Split(value, ",")...)
+	}
+
+	for index := len(addresses) - 1; index >= 0 && isTrustedProxy(ip, trustedProxies); index-- {
+		forwardedIP := net.ParseIP(strings.TrimSpace(addresses[index]))
+		if forwardedIP == nil {
+			break
+		}
+		ip = forwardedIP
+	}
+
+	return ip.String()
+}
+
+func isTrustedProxy(ip net.IP, trustedProxies []*net.IPNet) bool {
+	for _, trustedProxy := range trustedProxies {
+		if trustedProxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 func IsStatusCodeSuccess(statusCode int) bool {
 	return statusCode >= 200 && statusCode <= 299
 }
@@ -51,6 +121,7 @@ const (
 	MethodAccessToken     Method = "access token"
 	MethodSessionToken    Method = "session token"
 	MethodRestrictedToken Method = "restricted token"
+	MethodOAuthToken      Method = "oauth token"
 )
 
 type Details interface {
@@ -163,6 +234,21 @@ func TraceSessionFromContext(ctx context.Context) string {
 	return ""
 }
 
+const remoteAddressContextKey contextKey = "remote-address"
+
+func NewContextWithRemoteAddress(ctx context.Context, remoteAddress string) context.Context {
+	return context.WithValue(ctx, remoteAddressContextKey, remoteAddress)
+}
+
+func RemoteAddressFromContext(ctx context.Context) string {
+	if ctx != nil {
+		if remoteAddress, ok := ctx.Value(remoteAddressContextKey).(string); ok {
+			return remoteAddress
+		}
+	}
+	return ""
+}
+
 const contextErrorContextKey contextKey = "context-error"
 
 type ContextError struct {
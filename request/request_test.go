@@ -5,6 +5,9 @@ import (
 	. "github.com/onsi/gomega"
 
 	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
 
 	"github.com/ant0ine/go-json-rest/rest"
 
@@ -68,6 +71,96 @@ var _ = Describe("Request", func() {
 		})
 	})
 
+	Context("ParseTrustedProxies", func() {
+		It("returns successfully if the values are empty", func() {
+			Expect(request.ParseTrustedProxies(nil)).To(BeEmpty())
+		})
+
+		It("returns the networks of cidrs and single addresses", func() {
+			trustedProxies, err := request.ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1", "2001:db8::1"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(trustedProxies).To(HaveLen(3))
+			Expect(trustedProxies[0].String()).To(Equal("10.0.0.0/8"))
+			Expect(trustedProxies[1].String()).To(Equal("192.0.2.1/32"))
+			Expect(trustedProxies[2].String()).To(Equal("2001:db8::1/128"))
+		})
+
+		It("returns an error if a value is invalid", func() {
+			trustedProxies, err := request.ParseTrustedProxies([]string{"10.0.0.0/8", "invalid"})
+			Expect(err).To(MatchError(`trusted proxy "invalid" is invalid`))
+			Expect(trustedProxies).To(BeNil())
+		})
+
+		It("returns an error if a cidr is invalid", func() {
+			trustedProxies, err := request.ParseTrustedProxies([]string{"10.0.0.0/99"})
+			Expect(err).To(MatchError(`trusted proxy "10.0.0.0/99" is invalid`))
+			Expect(trustedProxies).To(BeNil())
+		})
+	})
+
+	Context("ClientIPAddress", func() {
+		var trustedProxies []*net.IPNet
+		var req *http.Request
+
+		BeforeEach(func() {
+			var err error
+			trustedProxies, err = request.ParseTrustedProxies([]string{"10.0.0.0/8"})
+			Expect(err).ToNot(HaveOccurred())
+			req = httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = "10.1.2.3:4567"
+		})
+
+		It("returns empty if the request is missing", func() {
+			Expect(request.ClientIPAddress(nil, trustedProxies)).To(BeEmpty())
+		})
+
+		It("returns empty if the remote address is invalid", func() {
+			req.RemoteAddr = "invalid"
+			Expect(request.ClientIPAddress(req, trustedProxies)).To(BeEmpty())
+		})
+
+		It("returns the remote address if the forwarded for header is missing", func() {
+			Expect(request.ClientIPAddress(req, trustedProxies)).To(Equal("10.1.2.3"))
+		})
+
+		It("returns the remote address if it is not a trusted proxy", func() {
+			req.RemoteAddr = "198.51.100.9:4567"
+			req.Header.Add("X-Forwarded-For", "203.0.113.7")
+			Expect(request.ClientIPAddress(req, trustedProxies)).To(Equal("198.51.100.9"))
+		})
+
+		It("returns the remote address if there are no trusted proxies", func() {
+			req.Header.Add("X-Forwarded-For", "203.0.113.7")
+			Expect(request.ClientIPAddress(req, nil)).To(Equal("10.1.2.3"))
+		})
+
+		It("returns the right-most forwarded for address not of a trusted proxy", func() {
+			req.Header.Add("X-Forwarded-For", "192.0.2.66, 203.0.113.7 , 10.4.5.6")
+			Expect(request.ClientIPAddress(req, trustedProxies)).To(Equal("203.0.113.7"))
+		})
+
+		It("returns the right-most forwarded for address across multiple headers", func() {
+			req.Header.Add("X-Forwarded-For", "192.0.2.66")
+			req.Header.Add("X-Forwarded-For", "203.0.113.7, 10.4.5.6")
+			Expect(request.ClientIPAddress(req, trustedProxies)).To(Equal("203.0.113.7"))
+		})
+
+		It("returns an ipv6 forwarded for address", func() {
+			req.Header.Add("X-Forwarded-For", "2001:db8::1")
+			Expect(request.ClientIPAddress(req, trustedProxies)).To(Equal("2001:db8::1"))
+		})
+
+		It("returns the left-most forwarded for address if every address is of a trusted proxy", func() {
+			req.Header.Add("X-Forwarded-For", "10.7.8.9, 10.4.5.6")
+			Expect(request.ClientIPAddress(req, trustedProxies)).To(Equal("10.7.8.9"))
+		})
+
+		It("returns the last trusted proxy if a forwarded for address is invalid", func() {
+			req.Header.Add("X-Forwarded-For", "203.0.113.7, unknown, 10.4.5.6")
+			Expect(request.ClientIPAddress(req, trustedProxies)).To(Equal("10.4.5.6"))
+		})
+	})
+
 	Context("ContextError", func() {
 		Context("NewContextError", func() {
 			It("return successfully", func() {
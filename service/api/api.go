@@ -5,7 +5,9 @@ import (
 
 	"github.com/ant0ine/go-json-rest/rest"
 
+	"github.com/tidepool-org/platform/config"
 	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/request"
 	"github.com/tidepool-org/platform/service"
 	"github.com/tidepool-org/platform/service/middleware"
 )
@@ -51,7 +53,11 @@ func (a *API) InitializeMiddleware() error {
 	if err != nil {
 		return err
 	}
-	traceMiddleware, err := middleware.NewTrace()
+	trustedProxies, err := request.ParseTrustedProxies(config.SplitTrimCompact(a.ConfigReporter().WithScopes("server").GetWithDefault("trusted_proxies", "")))
+	if err != nil {
+		return errors.Wrap(err, "unable to parse trusted proxies")
+	}
+	traceMiddleware, err := middleware.NewTrace(trustedProxies)
 	if err != nil {
 		return err
 	}
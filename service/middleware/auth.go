@@ -9,6 +9,7 @@ import (
 	"github.com/tidepool-org/platform/crypto"
 	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/pointer"
 	"github.com/tidepool-org/platform/request"
 	"github.com/tidepool-org/platform/service"
 )
@@ -122,6 +123,10 @@ func (a *Auth) authenticateAccessToken(req *rest.Request) (request.Details, erro
 		return nil, request.ErrorUnauthorized()
 	}
 
+	if auth.IsValidOAuthAccessToken(parts[1]) {
+		return a.authenticateOAuthAccessToken(req, parts[1])
+	}
+
 	details, err := a.authClient.ValidateSessionToken(req.Context(), parts[1])
 	if err != nil {
 		return nil, nil
@@ -130,6 +135,18 @@ func (a *Auth) authenticateAccessToken(req *rest.Request) (request.Details, erro
 	return request.NewDetails(request.MethodAccessToken, details.UserID(), details.Token()), nil
 }
 
+func (a *Auth) authenticateOAuthAccessToken(req *rest.Request, accessToken string) (request.Details, error) {
+	oauthToken, err := a.authClient.ValidateOAuthAccessToken(req.Context(), accessToken)
+	if err != nil {
+		log.LoggerFromContext(req.Context()).WithError(err).Warn("Unable to validate oauth access token in auth middleware")
+		return nil, nil
+	} else if oauthToken == nil || !oauthToken.Authenticates(req.Request) {
+		return nil, nil
+	}
+
+	return request.NewDetails(request.MethodOAuthToken, oauthToken.UserID, accessToken), nil
+}
+
 func (a *Auth) authenticateSessionToken(req *rest.Request) (request.Details, error) {
 	values, found := req.Header[auth.TidepoolSessionTokenHeaderKey]
 	if !found {
@@ -154,8 +171,21 @@ func (a *Auth) authenticateRestrictedToken(req *rest.Request) (request.Details,
 		return nil, request.ErrorUnauthorized()
 	}
 
-	restrictedToken, err := a.authClient.GetRestrictedToken(req.Context(), values[0])
-	if err != nil || restrictedToken == nil || !restrictedToken.Authenticates(req.Request) {
+	// The restricted token is authenticated and its use recorded by the auth service in a single request
+	use := auth.NewRestrictedTokenUse()
+	use.Method = pointer.FromString(req.Method)
+	use.Path = pointer.FromString(req.URL.EscapedPath())
+	if ip := request.RemoteAddressFromContext(req.Context()); ip != "" {
+		use.IPAddress = pointer.FromString(ip)
+	}
+
+	restrictedToken, err := a.authClient.UseRestrictedToken(req.Context(), values[0], use)
+	if request.IsErrorUnauthorized(err) {
+		return nil, nil
+	} else if err != nil {
+		log.LoggerFromContext(req.Context()).WithError(err).Warn("Unable to use restricted token in auth middleware")
+		return nil, nil
+	} else if restrictedToken == nil {
 		return nil, nil
 	}
 
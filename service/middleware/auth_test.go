@@ -15,6 +15,7 @@ import (
 	testErrors "github.com/tidepool-org/platform/errors/test"
 	"github.com/tidepool-org/platform/log"
 	logNull "github.com/tidepool-org/platform/log/null"
+	"github.com/tidepool-org/platform/pointer"
 	"github.com/tidepool-org/platform/request"
 	"github.com/tidepool-org/platform/service"
 	"github.com/tidepool-org/platform/service/middleware"
@@ -253,6 +254,81 @@ var _ = Describe("Auth", func() {
 					})
 				})
 
+				Context("with oauth access token", func() {
+					var accessToken string
+					var userID string
+					var oauthToken *auth.OAuthToken
+
+					BeforeEach(func() {
+						accessToken = auth.NewOAuthAccessToken()
+						userID = serviceTest.NewUserID()
+						oauthToken = &auth.OAuthToken{
+							ClientID:       auth.NewOAuthClientID(),
+							UserID:         userID,
+							Scopes:         []string{auth.OAuthScopeDataRead},
+							ExpirationTime: time.Now().Add(time.Hour),
+						}
+						req.Method = http.MethodGet
+						req.URL.Path = fmt.Sprintf("/v1/users/%s/data", userID)
+						req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+					})
+
+					It("returns successfully", func() {
+						authClient.ValidateOAuthAccessTokenOutputs = []testAuth.ValidateOAuthAccessTokenOutput{{OAuthToken: oauthToken, Error: nil}}
+						handlerFunc = func(res rest.ResponseWriter, req *rest.Request) {
+							details := request.DetailsFromContext(req.Context())
+							Expect(details).ToNot(BeNil())
+							Expect(details.Method()).To(Equal(request.MethodOAuthToken))
+							Expect(details.IsUser()).To(BeTrue())
+							Expect(details.UserID()).To(Equal(userID))
+							Expect(details.Token()).To(Equal(accessToken))
+							Expect(service.GetRequestAuthDetails(req)).To(Equal(details))
+						}
+						middlewareFunc(res, req)
+						Expect(authClient.ValidateOAuthAccessTokenInputs).To(HaveLen(1))
+						Expect(authClient.ValidateOAuthAccessTokenInputs[0].AccessToken).To(Equal(accessToken))
+						Expect(authClient.ValidateSessionTokenInputs).To(BeEmpty())
+					})
+
+					It("returns successfully with no details if validate returns an error", func() {
+						authClient.ValidateOAuthAccessTokenOutputs = []testAuth.ValidateOAuthAccessTokenOutput{{OAuthToken: nil, Error: testErrors.NewError()}}
+						handlerFunc = func(res rest.ResponseWriter, req *rest.Request) {
+							Expect(request.DetailsFromContext(req.Context())).To(BeNil())
+						}
+						middlewareFunc(res, req)
+						Expect(authClient.ValidateOAuthAccessTokenInputs).To(HaveLen(1))
+					})
+
+					It("returns successfully with no details if access token is not found", func() {
+						authClient.ValidateOAuthAccessTokenOutputs = []testAuth.ValidateOAuthAccessTokenOutput{{OAuthToken: nil, Error: nil}}
+						handlerFunc = func(res rest.ResponseWriter, req *rest.Request) {
+							Expect(request.DetailsFromContext(req.Context())).To(BeNil())
+						}
+						middlewareFunc(res, req)
+						Expect(authClient.ValidateOAuthAccessTokenInputs).To(HaveLen(1))
+					})
+
+					It("returns successfully with no details if scope does not authorize method", func() {
+						req.Method = http.MethodPost
+						authClient.ValidateOAuthAccessTokenOutputs = []testAuth.ValidateOAuthAccessTokenOutput{{OAuthToken: oauthToken, Error: nil}}
+						handlerFunc = func(res rest.ResponseWriter, req *rest.Request) {
+							Expect(request.DetailsFromContext(req.Context())).To(BeNil())
+						}
+						middlewareFunc(res, req)
+						Expect(authClient.ValidateOAuthAccessTokenInputs).To(HaveLen(1))
+					})
+
+					It("returns successfully with no details if scope does not authorize path", func() {
+						req.URL.Path = fmt.Sprintf("/v1/users/%s/blobs", userID)
+						authClient.ValidateOAuthAccessTokenOutputs = []testAuth.ValidateOAuthAccessTokenOutput{{OAuthToken: oauthToken, Error: nil}}
+						handlerFunc = func(res rest.ResponseWriter, req *rest.Request) {
+							Expect(request.DetailsFromContext(req.Context())).To(BeNil())
+						}
+						middlewareFunc(res, req)
+						Expect(authClient.ValidateOAuthAccessTokenInputs).To(HaveLen(1))
+					})
+				})
+
 				Context("with session token", func() {
 					var sessionToken string
 
@@ -355,7 +431,8 @@ var _ = Describe("Auth", func() {
 							UserID:         userID,
 							ExpirationTime: time.Now().Add(time.Hour),
 						}
-						authClient.GetRestrictedTokenOutputs = []testAuth.GetRestrictedTokenOutput{{RestrictedToken: restrictedTokenObject, Error: nil}}
+						authClient.UseRestrictedTokenOutputs = []testAuth.UseRestrictedTokenOutput{{RestrictedToken: restrictedTokenObject, Error: nil}}
+						req.Request = req.WithContext(request.NewContextWithRemoteAddress(req.Context(), "127.0.0.1"))
 						handlerFunc = func(res rest.ResponseWriter, req *rest.Request) {
 							details := request.DetailsFromContext(req.Context())
 							Expect(details).ToNot(BeNil())
@@ -370,26 +447,46 @@ var _ = Describe("Auth", func() {
 							Expect(service.GetRequestLogger(req)).ToNot(Equal(lgr))
 						}
 						middlewareFunc(res, req)
-						Expect(authClient.GetRestrictedTokenInputs).To(HaveLen(1))
-						Expect(authClient.GetRestrictedTokenInputs[0].ID).To(Equal(restrictedToken))
+						Expect(authClient.GetRestrictedTokenInputs).To(BeEmpty())
+						Expect(authClient.UseRestrictedTokenInputs).To(HaveLen(1))
+						Expect(authClient.UseRestrictedTokenInputs[0].ID).To(Equal(restrictedToken))
+						Expect(authClient.UseRestrictedTokenInputs[0].Use).ToNot(BeNil())
+						Expect(authClient.UseRestrictedTokenInputs[0].Use.Time).To(BeTemporally("~", time.Now(), time.Second))
+						Expect(authClient.UseRestrictedTokenInputs[0].Use.Method).To(Equal(pointer.FromString(req.Method)))
+						Expect(authClient.UseRestrictedTokenInputs[0].Use.Path).To(Equal(pointer.FromString(req.URL.EscapedPath())))
+						Expect(authClient.UseRestrictedTokenInputs[0].Use.IPAddress).To(Equal(pointer.FromString("127.0.0.1")))
 					})
 
-					It("returns successfully with no details if restricted token is not valid", func() {
-						authClient.GetRestrictedTokenOutputs = []testAuth.GetRestrictedTokenOutput{{RestrictedToken: nil, Error: testErrors.NewError()}}
+					It("returns successfully without the untrusted forwarded for address", func() {
+						restrictedTokenObject := &auth.RestrictedToken{
+							ID:             restrictedToken,
+							UserID:         serviceTest.NewUserID(),
+							ExpirationTime: time.Now().Add(time.Hour),
+						}
+						authClient.UseRestrictedTokenOutputs = []testAuth.UseRestrictedTokenOutput{{RestrictedToken: restrictedTokenObject, Error: nil}}
+						req.Request.Header.Set("X-Forwarded-For", "203.0.113.7")
 						handlerFunc = func(res rest.ResponseWriter, req *rest.Request) {
-							details := request.DetailsFromContext(req.Context())
-							Expect(details).To(BeNil())
+							Expect(request.DetailsFromContext(req.Context())).ToNot(BeNil())
+						}
+						middlewareFunc(res, req)
+						Expect(authClient.UseRestrictedTokenInputs).To(HaveLen(1))
+						Expect(authClient.UseRestrictedTokenInputs[0].Use.IPAddress).To(BeNil())
+					})
+
+					It("returns successfully with no details if restricted token use returns an error", func() {
+						authClient.UseRestrictedTokenOutputs = []testAuth.UseRestrictedTokenOutput{{RestrictedToken: nil, Error: testErrors.NewError()}}
+						handlerFunc = func(res rest.ResponseWriter, req *rest.Request) {
+							Expect(request.DetailsFromContext(req.Context())).To(BeNil())
 							Expect(service.GetRequestAuthDetails(req)).To(BeNil())
-							Expect(log.LoggerFromContext(req.Context())).To(Equal(lgr))
-							Expect(service.GetRequestLogger(req)).To(Equal(lgr))
 						}
 						middlewareFunc(res, req)
-						Expect(authClient.GetRestrictedTokenInputs).To(HaveLen(1))
-						Expect(authClient.GetRestrictedTokenInputs[0].ID).To(Equal(restrictedToken))
+						Expect(authClient.UseRestrictedTokenInputs).To(HaveLen(1))
+						Expect(authClient.UseRestrictedTokenInputs[0].ID).To(Equal(restrictedToken))
+						Expect(authClient.UseRestrictedTokenInputs[0].Use.IPAddress).To(BeNil())
 					})
 
-					It("returns successfully with no details if restricted token is missing", func() {
-						authClient.GetRestrictedTokenOutputs = []testAuth.GetRestrictedTokenOutput{{RestrictedToken: nil, Error: nil}}
+					It("returns successfully with no details if restricted token does not authenticate request", func() {
+						authClient.UseRestrictedTokenOutputs = []testAuth.UseRestrictedTokenOutput{{RestrictedToken: nil, Error: request.ErrorUnauthorized()}}
 						handlerFunc = func(res rest.ResponseWriter, req *rest.Request) {
 							details := request.DetailsFromContext(req.Context())
 							Expect(details).To(BeNil())
@@ -398,18 +495,12 @@ var _ = Describe("Auth", func() {
 							Expect(service.GetRequestLogger(req)).To(Equal(lgr))
 						}
 						middlewareFunc(res, req)
-						Expect(authClient.GetRestrictedTokenInputs).To(HaveLen(1))
-						Expect(authClient.GetRestrictedTokenInputs[0].ID).To(Equal(restrictedToken))
+						Expect(authClient.UseRestrictedTokenInputs).To(HaveLen(1))
+						Expect(authClient.UseRestrictedTokenInputs[0].ID).To(Equal(restrictedToken))
 					})
 
-					It("returns successfully with no details if restricted token does not authenticate request", func() {
-						userID := serviceTest.NewUserID()
-						restrictedTokenObject := &auth.RestrictedToken{
-							ID:             restrictedToken,
-							UserID:         userID,
-							ExpirationTime: time.Now().Add(-time.Hour),
-						}
-						authClient.GetRestrictedTokenOutputs = []testAuth.GetRestrictedTokenOutput{{RestrictedToken: restrictedTokenObject, Error: nil}}
+					It("returns successfully with no details if restricted token is missing", func() {
+						authClient.UseRestrictedTokenOutputs = []testAuth.UseRestrictedTokenOutput{{RestrictedToken: nil, Error: nil}}
 						handlerFunc = func(res rest.ResponseWriter, req *rest.Request) {
 							details := request.DetailsFromContext(req.Context())
 							Expect(details).To(BeNil())
@@ -418,8 +509,8 @@ var _ = Describe("Auth", func() {
 							Expect(service.GetRequestLogger(req)).To(Equal(lgr))
 						}
 						middlewareFunc(res, req)
-						Expect(authClient.GetRestrictedTokenInputs).To(HaveLen(1))
-						Expect(authClient.GetRestrictedTokenInputs[0].ID).To(Equal(restrictedToken))
+						Expect(authClient.UseRestrictedTokenInputs).To(HaveLen(1))
+						Expect(authClient.UseRestrictedTokenInputs[0].ID).To(Equal(restrictedToken))
 					})
 				})
 			})
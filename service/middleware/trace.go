@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"net"
+
 	"github.com/ant0ine/go-json-rest/rest"
 
 	"github.com/tidepool-org/platform/id"
@@ -9,7 +11,9 @@ import (
 	"github.com/tidepool-org/platform/service"
 )
 
-type Trace struct{}
+type Trace struct {
+	trustedProxies []*net.IPNet
+}
 
 const (
 	_LogTrace   = "trace"
@@ -19,8 +23,10 @@ const (
 	_TraceMaximumLength = 64
 )
 
-func NewTrace() (*Trace, error) {
-	return &Trace{}, nil
+func NewTrace(trustedProxies []*net.IPNet) (*Trace, error) {
+	return &Trace{
+		trustedProxies: trustedProxies,
+	}, nil
 }
 
 func (t *Trace) MiddlewareFunc(handler rest.HandlerFunc) rest.HandlerFunc {
@@ -65,6 +71,10 @@ func (t *Trace) MiddlewareFunc(handler rest.HandlerFunc) rest.HandlerFunc {
 				trace[_LogSession] = traceSession
 			}
 
+			if remoteAddress := request.ClientIPAddress(req.Request, t.trustedProxies); remoteAddress != "" {
+				req.Request = req.WithContext(request.NewContextWithRemoteAddress(req.Context(), remoteAddress))
+			}
+
 			// DEPRECATED
 			if oldLogger := service.GetRequestLogger(req); oldLogger != nil {
 				defer service.SetRequestLogger(req, oldLogger)
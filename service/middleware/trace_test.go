@@ -20,7 +20,7 @@ import (
 var _ = Describe("Trace", func() {
 	Context("NewTrace", func() {
 		It("returns successfully", func() {
-			Expect(middleware.NewTrace()).ToNot(BeNil())
+			Expect(middleware.NewTrace(nil)).ToNot(BeNil())
 		})
 	})
 
@@ -34,7 +34,9 @@ var _ = Describe("Trace", func() {
 
 		BeforeEach(func() {
 			var err error
-			traceMiddleware, err = middleware.NewTrace()
+			trustedProxies, err := request.ParseTrustedProxies([]string{"127.0.0.1"})
+			Expect(err).ToNot(HaveOccurred())
+			traceMiddleware, err = middleware.NewTrace(trustedProxies)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(traceMiddleware).ToNot(BeNil())
 			req = testRest.NewRequest()
@@ -184,6 +186,32 @@ var _ = Describe("Trace", func() {
 			Expect(res.Header()["X-Tidepool-Trace-Request"]).To(Equal([]string{traceRequest[0:64]}))
 		})
 
+		It("adds remote address if available", func() {
+			req.Request.RemoteAddr = "127.0.0.1:1234"
+			hndlr = func(res rest.ResponseWriter, req *rest.Request) {
+				Expect(request.RemoteAddressFromContext(req.Context())).To(Equal("127.0.0.1"))
+			}
+			traceMiddleware.MiddlewareFunc(hndlr)(res, req)
+		})
+
+		It("adds forwarded client address if available", func() {
+			req.Request.RemoteAddr = "127.0.0.1:1234"
+			req.Request.Header.Set("X-Forwarded-For", "10.1.2.3, 127.0.0.1")
+			hndlr = func(res rest.ResponseWriter, req *rest.Request) {
+				Expect(request.RemoteAddressFromContext(req.Context())).To(Equal("10.1.2.3"))
+			}
+			traceMiddleware.MiddlewareFunc(hndlr)(res, req)
+		})
+
+		It("ignores forwarded client address if the remote address is not a trusted proxy", func() {
+			req.Request.RemoteAddr = "192.0.2.1:1234"
+			req.Request.Header.Set("X-Forwarded-For", "10.1.2.3")
+			hndlr = func(res rest.ResponseWriter, req *rest.Request) {
+				Expect(request.RemoteAddressFromContext(req.Context())).To(Equal("192.0.2.1"))
+			}
+			traceMiddleware.MiddlewareFunc(hndlr)(res, req)
+		})
+
 		It("does not add trace session if not specified", func() {
 			req.Request.Header.Del("X-Tidepool-Trace-Session")
 			hndlr = func(res rest.ResponseWriter, req *rest.Request) {
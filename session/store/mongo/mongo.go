@@ -8,6 +8,7 @@ import (
 
 	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/session"
 	"github.com/tidepool-org/platform/session/store"
 	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
 )
@@ -37,6 +38,36 @@ type SessionsSession struct {
 	*storeStructuredMongo.Session
 }
 
+func (s *SessionsSession) ListSessionsForUserByID(ctx context.Context, userID string) ([]*session.Session, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+
+	if s.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	startTime := time.Now()
+
+	sessions := []*session.Session{}
+	selector := bson.M{
+		"userId": userID,
+	}
+	err := s.C().Find(selector).Sort("createdAt").All(&sessions)
+
+	loggerFields := log.Fields{"userId": userID, "count": len(sessions), "duration": time.Since(startTime) / time.Microsecond}
+	log.LoggerFromContext(ctx).WithFields(loggerFields).WithError(err).Debug("ListSessionsForUserByID")
+
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list sessions for user by id")
+	}
+
+	return sessions, nil
+}
+
 func (s *SessionsSession) DestroySessionsForUserByID(ctx context.Context, userID string) error {
 	if ctx == nil {
 		return errors.New("context is missing")
@@ -3,6 +3,8 @@ package store
 import (
 	"context"
 	"io"
+
+	"github.com/tidepool-org/platform/session"
 )
 
 type Store interface {
@@ -12,5 +14,6 @@ type Store interface {
 type SessionsSession interface {
 	io.Closer
 
+	ListSessionsForUserByID(ctx context.Context, userID string) ([]*session.Session, error)
 	DestroySessionsForUserByID(ctx context.Context, userID string) error
 }
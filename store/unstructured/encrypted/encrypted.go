@@ -0,0 +1,692 @@
+package encrypted
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+
+	cryptoKey "github.com/tidepool-org/platform/crypto/key"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/page"
+	storeUnstructured "github.com/tidepool-org/platform/store/unstructured"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
+const Type = "encrypted"
+
+// MetadataKeyKeyID is the reserved metadata key used to record the id of the master key that wrapped
+// the data key. It is used to determine the size of the header, and thereby the size of the content,
+// without reading the object.
+const MetadataKeyKeyID = "encrypted-key-id"
+
+// Store is an unstructured store that wraps another unstructured store and encrypts all content
+// at rest using envelope encryption. Each object is encrypted with a random, per-object data key
+// using AES-256-GCM in fixed size segments (so content is never buffered in its entirety). The
+// data key is wrapped by a master key and the wrapped data key, along with the id of the master
+// key, is stored in a header preceding the encrypted content. Master keys may be rotated by adding
+// a new key, changing the current key id, and rewrapping existing objects.
+type Store struct {
+	store storeUnstructured.Store
+	keyID string
+	keys  map[string][]byte
+}
+
+func NewStore(cfg *cryptoKey.Config, store storeUnstructured.Store) (*Store, error) {
+	if cfg == nil {
+		return nil, errors.New("config is missing")
+	} else if err := cfg.Validate(); err != nil {
+		return nil, errors.Wrap(err, "config is invalid")
+	}
+	if store == nil {
+		return nil, errors.New("store is missing")
+	}
+
+	keys := map[string][]byte{}
+	for keyID, key := range cfg.Keys {
+		keys[keyID] = key
+	}
+
+	return &Store{
+		store: store,
+		keyID: cfg.KeyID,
+		keys:  keys,
+	}, nil
+}
+
+func (s *Store) Exists(ctx context.Context, key string) (bool, error) {
+	if ctx == nil {
+		return false, errors.New("context is missing")
+	}
+	if key == "" {
+		return false, errors.New("key is missing")
+	} else if !storeUnstructured.IsValidKey(key) {
+		return false, errors.New("key is invalid")
+	}
+
+	return s.store.Exists(ctx, key)
+}
+
+func (s *Store) Put(ctx context.Context, key string, reader io.Reader, options *storeUnstructured.Options) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if key == "" {
+		return errors.New("key is missing")
+	} else if !storeUnstructured.IsValidKey(key) {
+		return errors.New("key is invalid")
+	}
+	if reader == nil {
+		return errors.New("reader is missing")
+	}
+	if options == nil {
+		options = storeUnstructured.NewOptions()
+	} else if err := structureValidator.New().Validate(options); err != nil {
+		return errors.Wrap(err, "options is invalid")
+	} else if _, ok := options.Metadata[MetadataKeyKeyID]; ok {
+		return errors.Wrap(errors.Newf("metadata key %q is reserved", MetadataKeyKeyID), "options is invalid")
+	}
+
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"keyId": s.keyID, "key": key})
+
+	dataKey, err := randomBytes(cryptoKey.KeyLength)
+	if err != nil {
+		logger.WithError(err).Error("Unable to generate data key")
+		return errors.Wrap(err, "unable to generate data key")
+	}
+	noncePrefix, err := randomBytes(noncePrefixLength)
+	if err != nil {
+		logger.WithError(err).Error("Unable to generate nonce prefix")
+		return errors.Wrap(err, "unable to generate nonce prefix")
+	}
+
+	hdr, err := s.newHeader(dataKey, noncePrefix)
+	if err != nil {
+		logger.WithError(err).Error("Unable to create header")
+		return errors.Wrap(err, "unable to create header")
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		logger.WithError(err).Error("Unable to create cipher")
+		return errors.Wrap(err, "unable to create cipher")
+	}
+
+	if err = s.store.Put(ctx, key, io.MultiReader(bytes.NewReader(hdr.Bytes()), newEncryptReader(aead, noncePrefix, reader)), s.newOptions(options)); err != nil {
+		return err
+	}
+
+	logger.Debug("Put")
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if key == "" {
+		return nil, errors.New("key is missing")
+	} else if !storeUnstructured.IsValidKey(key) {
+		return nil, errors.New("key is invalid")
+	}
+
+	logger := log.LoggerFromContext(ctx).WithField("key", key)
+
+	info, err := s.store.Stat(ctx, key)
+	if err != nil || info == nil {
+		return nil, err
+	}
+
+	reader, err := s.store.Get(ctx, key)
+	if err != nil || reader == nil {
+		return nil, err
+	}
+
+	bufferedReader := bufio.NewReaderSize(reader, segmentSize+tagLength)
+
+	hdr, err := readMetadataHeader(info.Metadata, bufferedReader)
+	if err != nil {
+		reader.Close()
+		logger.WithError(err).Error("Unable to read header")
+		return nil, errors.Wrap(err, "unable to read header")
+	} else if hdr == nil {
+		logger.Debug("Get plaintext")
+		return &readCloser{Reader: bufferedReader, Closer: reader}, nil
+	}
+
+	dataKey, err := s.unwrapDataKey(hdr)
+	if err != nil {
+		reader.Close()
+		logger.WithError(err).WithField("keyId", hdr.KeyID).Error("Unable to unwrap data key")
+		return nil, errors.Wrap(err, "unable to unwrap data key")
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		reader.Close()
+		logger.WithError(err).Error("Unable to create cipher")
+		return nil, errors.Wrap(err, "unable to create cipher")
+	}
+
+	logger.WithField("keyId", hdr.KeyID).Debug("Get")
+	return newDecryptReadCloser(aead, hdr.NoncePrefix, bufferedReader, reader), nil
+}
+
+// Stat returns the size of the decrypted content, along with the media type and metadata specified when the
+// object was put. Objects put before encryption was enabled have no key id and are returned as is.
+func (s *Store) Stat(ctx context.Context, key string) (*storeUnstructured.Info, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if key == "" {
+		return nil, errors.New("key is missing")
+	} else if !storeUnstructured.IsValidKey(key) {
+		return nil, errors.New("key is invalid")
+	}
+
+	logger := log.LoggerFromContext(ctx).WithField("key", key)
+
+	info, err := s.store.Stat(ctx, key)
+	if err != nil || info == nil {
+		return nil, err
+	}
+
+	keyID, ok := info.Metadata[MetadataKeyKeyID]
+	if !ok {
+		logger.Debug("Stat plaintext")
+		return info, nil
+	}
+
+	size, err := contentSize(info.Size, keyID)
+	if err != nil {
+		logger.WithError(err).WithField("keyId", keyID).Error("Unable to determine content size")
+		return nil, errors.Wrap(err, "unable to determine content size")
+	}
+
+	info.Size = size
+	info.Metadata = removeKeyID(info.Metadata)
+
+	logger.WithField("keyId", keyID).Debug("Stat")
+	return info, nil
+}
+
+// Copy copies the object, which remains decryptable since the data key is not bound to the object key
+func (s *Store) Copy(ctx context.Context, sourceKey string, destinationKey string) (bool, error) {
+	if ctx == nil {
+		return false, errors.New("context is missing")
+	}
+	if sourceKey == "" {
+		return false, errors.New("source key is missing")
+	} else if !storeUnstructured.IsValidKey(sourceKey) {
+		return false, errors.New("source key is invalid")
+	}
+	if destinationKey == "" {
+		return false, errors.New("destination key is missing")
+	} else if !storeUnstructured.IsValidKey(destinationKey) || destinationKey == sourceKey {
+		return false, errors.New("destination key is invalid")
+	}
+
+	return s.store.Copy(ctx, sourceKey, destinationKey)
+}
+
+func (s *Store) List(ctx context.Context, prefix string, pagination *page.Pagination) ([]string, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if !storeUnstructured.IsValidKeyPrefix(prefix) {
+		return nil, errors.New("prefix is invalid")
+	}
+
+	return s.store.List(ctx, prefix, pagination)
+}
+
+func (s *Store) Delete(ctx context.Context, key string) (bool, error) {
+	if ctx == nil {
+		return false, errors.New("context is missing")
+	}
+	if key == "" {
+		return false, errors.New("key is missing")
+	} else if !storeUnstructured.IsValidKey(key) {
+		return false, errors.New("key is invalid")
+	}
+
+	return s.store.Delete(ctx, key)
+}
+
+// Rewrap rewraps the data key of the object with the specified key using the current master key. The
+// encrypted content itself is unchanged. Objects put before encryption was enabled are encrypted. Returns
+// true if the object was rewrapped or encrypted, false if the object does not exist or is already wrapped
+// with the current master key.
+func (s *Store) Rewrap(ctx context.Context, key string) (bool, error) {
+	if ctx == nil {
+		return false, errors.New("context is missing")
+	}
+	if key == "" {
+		return false, errors.New("key is missing")
+	} else if !storeUnstructured.IsValidKey(key) {
+		return false, errors.New("key is invalid")
+	}
+
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"keyId": s.keyID, "key": key})
+
+	info, err := s.store.Stat(ctx, key)
+	if err != nil || info == nil {
+		return false, err
+	}
+
+	reader, err := s.store.Get(ctx, key)
+	if err != nil || reader == nil {
+		return false, err
+	}
+	defer reader.Close()
+
+	bufferedReader := bufio.NewReader(reader)
+
+	hdr, err := readMetadataHeader(info.Metadata, bufferedReader)
+	if err != nil {
+		logger.WithError(err).Error("Unable to read header")
+		return false, errors.Wrap(err, "unable to read header")
+	} else if hdr == nil {
+		return s.encrypt(ctx, key, info, bufferedReader)
+	} else if hdr.KeyID == s.keyID {
+		logger.WithField("rewrapped", false).Debug("Rewrap")
+		return false, nil
+	}
+
+	dataKey, err := s.unwrapDataKey(hdr)
+	if err != nil {
+		logger.WithError(err).WithField("previousKeyId", hdr.KeyID).Error("Unable to unwrap data key")
+		return false, errors.Wrap(err, "unable to unwrap data key")
+	}
+
+	rewrappedHdr, err := s.newHeader(dataKey, hdr.NoncePrefix)
+	if err != nil {
+		logger.WithError(err).Error("Unable to create header")
+		return false, errors.Wrap(err, "unable to create header")
+	}
+
+	// Preserve the media type and metadata of the existing object
+	options := storeUnstructured.NewOptions()
+	options.MediaType = info.MediaType
+	options.Metadata = removeKeyID(info.Metadata)
+
+	file, err := spool(bufferedReader)
+	if err != nil {
+		logger.WithError(err).Error("Unable to spool content to temporary file")
+		return false, errors.Wrap(err, "unable to spool content to temporary file")
+	}
+	defer removeSpool(file)
+
+	if err = s.store.Put(ctx, key, io.MultiReader(bytes.NewReader(rewrappedHdr.Bytes()), file), s.newOptions(options)); err != nil {
+		return false, err
+	}
+
+	logger.WithFields(log.Fields{"previousKeyId": hdr.KeyID, "rewrapped": true}).Debug("Rewrap")
+	return true, nil
+}
+
+// encrypt encrypts the existing plaintext object, preserving the media type and metadata
+func (s *Store) encrypt(ctx context.Context, key string, info *storeUnstructured.Info, reader io.Reader) (bool, error) {
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"keyId": s.keyID, "key": key})
+
+	options := storeUnstructured.NewOptions()
+	options.MediaType = info.MediaType
+	options.Metadata = removeKeyID(info.Metadata)
+
+	file, err := spool(reader)
+	if err != nil {
+		logger.WithError(err).Error("Unable to spool content to temporary file")
+		return false, errors.Wrap(err, "unable to spool content to temporary file")
+	}
+	defer removeSpool(file)
+
+	if err = s.Put(ctx, key, file, options); err != nil {
+		return false, err
+	}
+
+	logger.WithField("encrypted", true).Debug("Rewrap")
+	return true, nil
+}
+
+// newOptions returns a copy of the options with the reserved metadata key set to the current master key id
+func (s *Store) newOptions(options *storeUnstructured.Options) *storeUnstructured.Options {
+	metadata := map[string]string{MetadataKeyKeyID: s.keyID}
+	for key, value := range options.Metadata {
+		metadata[key] = value
+	}
+	return &storeUnstructured.Options{
+		MediaType: options.MediaType,
+		Metadata:  metadata,
+	}
+}
+
+func (s *Store) newHeader(dataKey []byte, noncePrefix []byte) (*header, error) {
+	aead, err := newAEAD(s.keys[s.keyID])
+	if err != nil {
+		return nil, err
+	}
+
+	wrapNonce, err := randomBytes(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+
+	hdr := &header{
+		KeyID:       s.keyID,
+		WrapNonce:   wrapNonce,
+		NoncePrefix: noncePrefix,
+	}
+	hdr.WrappedDataKey = aead.Seal(nil, wrapNonce, dataKey, hdr.AdditionalData())
+	return hdr, nil
+}
+
+func (s *Store) unwrapDataKey(hdr *header) ([]byte, error) {
+	key, ok := s.keys[hdr.KeyID]
+	if !ok {
+		return nil, errors.Newf("key with id %q is missing", hdr.KeyID)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := aead.Open(nil, hdr.WrapNonce, hdr.WrappedDataKey, hdr.AdditionalData())
+	if err != nil {
+		return nil, errors.New("wrapped data key is invalid")
+	}
+	return dataKey, nil
+}
+
+const (
+	headerVersion     = 1
+	wrapNonceLength   = 12
+	tagLength         = 16
+	noncePrefixLength = 7
+	segmentSize       = 64 * 1024
+)
+
+var headerMagic = []byte("TPUE")
+
+// contentSize returns the size of the decrypted content given the size of the object and the id of the
+// master key that wrapped the data key. Every segment is sealed with a tag, including the final segment,
+// which may be empty.
+func contentSize(size int64, keyID string) (int64, error) {
+	size -= int64(len(headerMagic) + 2 + len(keyID) + wrapNonceLength + cryptoKey.KeyLength + tagLength + noncePrefixLength)
+	if size < tagLength {
+		return 0, errors.New("size is invalid")
+	}
+	segments := (size + segmentSize + tagLength - 1) / (segmentSize + tagLength)
+	return size - segments*tagLength, nil
+}
+
+func removeKeyID(metadata map[string]string) map[string]string {
+	result := map[string]string{}
+	for key, value := range metadata {
+		if key != MetadataKeyKeyID {
+			result[key] = value
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// spool copies the content to a temporary file since some stores (e.g. file) truncate existing content on put
+func spool(reader io.Reader) (*os.File, error) {
+	file, err := ioutil.TempFile("", "encrypted")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(file, reader); err != nil {
+		removeSpool(file)
+		return nil, err
+	} else if _, err = file.Seek(0, io.SeekStart); err != nil {
+		removeSpool(file)
+		return nil, err
+	}
+	return file, nil
+}
+
+func removeSpool(file *os.File) {
+	file.Close()
+	os.Remove(file.Name())
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// readMetadataHeader reads the header if the metadata has a key id. Objects put before encryption was enabled do not
+// and are treated as plaintext. The content must begin with a header if and only if the metadata has a key id, and
+// the key ids must match, otherwise the object was modified outside of this store.
+func readMetadataHeader(metadata map[string]string, reader *bufio.Reader) (*header, error) {
+	keyID, ok := metadata[MetadataKeyKeyID]
+	if !ok {
+		if magic, _ := reader.Peek(len(headerMagic)); bytes.Equal(magic, headerMagic) {
+			return nil, errors.New("header is unexpected")
+		}
+		return nil, nil
+	}
+
+	hdr, err := readHeader(reader)
+	if err != nil {
+		return nil, err
+	} else if hdr.KeyID != keyID {
+		return nil, errors.New("header key id does not match metadata")
+	}
+	return hdr, nil
+}
+
+type header struct {
+	KeyID          string
+	WrapNonce      []byte
+	WrappedDataKey []byte
+	NoncePrefix    []byte
+}
+
+func readHeader(reader io.Reader) (*header, error) {
+	prefix := make([]byte, len(headerMagic)+2)
+	if _, err := io.ReadFull(reader, prefix); err != nil {
+		return nil, errors.New("header is invalid")
+	} else if !bytes.Equal(prefix[:len(headerMagic)], headerMagic) {
+		return nil, errors.New("header is invalid")
+	} else if prefix[len(headerMagic)] != headerVersion {
+		return nil, errors.New("header version is not supported")
+	}
+
+	remaining := make([]byte, int(prefix[len(headerMagic)+1])+wrapNonceLength+cryptoKey.KeyLength+tagLength+noncePrefixLength)
+	if _, err := io.ReadFull(reader, remaining); err != nil {
+		return nil, errors.New("header is invalid")
+	}
+
+	hdr := &header{}
+	hdr.KeyID, remaining = string(remaining[:prefix[len(headerMagic)+1]]), remaining[prefix[len(headerMagic)+1]:]
+	hdr.WrapNonce, remaining = remaining[:wrapNonceLength], remaining[wrapNonceLength:]
+	hdr.WrappedDataKey, remaining = remaining[:cryptoKey.KeyLength+tagLength], remaining[cryptoKey.KeyLength+tagLength:]
+	hdr.NoncePrefix = remaining
+	return hdr, nil
+}
+
+func (h *header) AdditionalData() []byte {
+	return append(append(append([]byte{}, headerMagic...), headerVersion, byte(len(h.KeyID))), h.KeyID...)
+}
+
+func (h *header) Bytes() []byte {
+	return append(append(append(h.AdditionalData(), h.WrapNonce...), h.WrappedDataKey...), h.NoncePrefix...)
+}
+
+type encryptReader struct {
+	aead        cipher.AEAD
+	noncePrefix []byte
+	counter     uint32
+	reader      *bufio.Reader
+	plaintext   []byte
+	ciphertext  []byte
+	pending     []byte
+	err         error
+}
+
+func newEncryptReader(aead cipher.AEAD, noncePrefix []byte, reader io.Reader) *encryptReader {
+	return &encryptReader{
+		aead:        aead,
+		noncePrefix: noncePrefix,
+		reader:      bufio.NewReaderSize(reader, segmentSize),
+		plaintext:   make([]byte, segmentSize),
+		ciphertext:  make([]byte, 0, segmentSize+tagLength),
+	}
+}
+
+func (e *encryptReader) Read(buffer []byte) (int, error) {
+	for len(e.pending) == 0 {
+		if e.err != nil {
+			return 0, e.err
+		}
+		e.err = e.seal()
+	}
+
+	length := copy(buffer, e.pending)
+	e.pending = e.pending[length:]
+	return length, nil
+}
+
+func (e *encryptReader) seal() error {
+	length, final, err := readSegment(e.reader, e.plaintext)
+	if err != nil {
+		return err
+	}
+
+	nonce, err := segmentNonce(e.noncePrefix, e.counter, final)
+	if err != nil {
+		return err
+	}
+
+	e.pending = e.aead.Seal(e.ciphertext[:0], nonce, e.plaintext[:length], nil)
+	e.counter++
+
+	if final {
+		return io.EOF
+	}
+	return nil
+}
+
+type decryptReadCloser struct {
+	aead        cipher.AEAD
+	noncePrefix []byte
+	counter     uint32
+	reader      *bufio.Reader
+	closer      io.Closer
+	ciphertext  []byte
+	plaintext   []byte
+	pending     []byte
+	err         error
+}
+
+func newDecryptReadCloser(aead cipher.AEAD, noncePrefix []byte, reader *bufio.Reader, closer io.Closer) *decryptReadCloser {
+	return &decryptReadCloser{
+		aead:        aead,
+		noncePrefix: noncePrefix,
+		reader:      reader,
+		closer:      closer,
+		ciphertext:  make([]byte, segmentSize+tagLength),
+		plaintext:   make([]byte, 0, segmentSize),
+	}
+}
+
+func (d *decryptReadCloser) Read(buffer []byte) (int, error) {
+	for len(d.pending) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.err = d.open()
+	}
+
+	length := copy(buffer, d.pending)
+	d.pending = d.pending[length:]
+	return length, nil
+}
+
+func (d *decryptReadCloser) Close() error {
+	return d.closer.Close()
+}
+
+func (d *decryptReadCloser) open() error {
+	length, final, err := readSegment(d.reader, d.ciphertext)
+	if err != nil {
+		return err
+	}
+
+	nonce, err := segmentNonce(d.noncePrefix, d.counter, final)
+	if err != nil {
+		return err
+	}
+
+	d.pending, err = d.aead.Open(d.plaintext[:0], nonce, d.ciphertext[:length], nil)
+	if err != nil {
+		return errors.New("content is invalid")
+	}
+	d.counter++
+
+	if final {
+		return io.EOF
+	}
+	return nil
+}
+
+// readSegment reads a full segment into buffer and reports whether it is the final segment
+func readSegment(reader *bufio.Reader, buffer []byte) (int, bool, error) {
+	length, err := io.ReadFull(reader, buffer)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return length, true, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+
+	if _, err = reader.Peek(1); err == io.EOF {
+		return length, true, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+
+	return length, false, nil
+}
+
+func segmentNonce(noncePrefix []byte, counter uint32, final bool) ([]byte, error) {
+	if counter == math.MaxUint32 {
+		return nil, errors.New("content is too large")
+	}
+
+	nonce := make([]byte, noncePrefixLength+5)
+	copy(nonce, noncePrefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixLength:], counter)
+	if final {
+		nonce[noncePrefixLength+4] = 1
+	}
+	return nonce, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func randomBytes(length int) ([]byte, error) {
+	bytes := make([]byte, length)
+	if _, err := io.ReadFull(rand.Reader, bytes); err != nil {
+		return nil, err
+	}
+	return bytes, nil
+}
@@ -0,0 +1,702 @@
+package encrypted_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	cryptoKey "github.com/tidepool-org/platform/crypto/key"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/log"
+	logNull "github.com/tidepool-org/platform/log/null"
+	netTest "github.com/tidepool-org/platform/net/test"
+	"github.com/tidepool-org/platform/pointer"
+	storeUnstructured "github.com/tidepool-org/platform/store/unstructured"
+	storeUnstructuredEncrypted "github.com/tidepool-org/platform/store/unstructured/encrypted"
+	storeUnstructuredFile "github.com/tidepool-org/platform/store/unstructured/file"
+	storeUnstructuredTest "github.com/tidepool-org/platform/store/unstructured/test"
+	"github.com/tidepool-org/platform/test"
+)
+
+var _ = Describe("Encrypted", func() {
+	It("has type encrypted", func() {
+		Expect(storeUnstructuredEncrypted.Type).To(Equal("encrypted"))
+	})
+
+	Context("with config", func() {
+		var directory string
+		var fileStore *storeUnstructuredFile.Store
+		var cfg *cryptoKey.Config
+
+		BeforeEach(func() {
+			var err error
+			directory = test.RandomTemporaryDirectory()
+			fileCfg := storeUnstructuredFile.NewConfig()
+			fileCfg.Directory = directory
+			fileStore, err = storeUnstructuredFile.NewStore(fileCfg)
+			Expect(err).ToNot(HaveOccurred())
+			cfg = cryptoKey.NewConfig()
+			Expect(cfg).ToNot(BeNil())
+			cfg.KeyID = "alpha"
+			cfg.Keys = map[string][]byte{"alpha": test.RandomBytesFromRange(32, 32)}
+		})
+
+		AfterEach(func() {
+			if directory != "" {
+				Expect(os.RemoveAll(directory)).To(Succeed())
+			}
+		})
+
+		Context("NewStore", func() {
+			It("returns an error if the config is missing", func() {
+				str, err := storeUnstructuredEncrypted.NewStore(nil, fileStore)
+				Expect(err).To(MatchError("config is missing"))
+				Expect(str).To(BeNil())
+			})
+
+			It("returns an error if the config is invalid", func() {
+				cfg.KeyID = ""
+				str, err := storeUnstructuredEncrypted.NewStore(cfg, fileStore)
+				Expect(err).To(MatchError("config is invalid; key id is missing"))
+				Expect(str).To(BeNil())
+			})
+
+			It("returns an error if the store is missing", func() {
+				str, err := storeUnstructuredEncrypted.NewStore(cfg, nil)
+				Expect(err).To(MatchError("store is missing"))
+				Expect(str).To(BeNil())
+			})
+
+			It("returns successfully", func() {
+				Expect(storeUnstructuredEncrypted.NewStore(cfg, fileStore)).ToNot(BeNil())
+			})
+		})
+
+		Context("with new store", func() {
+			var str *storeUnstructuredEncrypted.Store
+			var ctx context.Context
+			var key string
+			var keyPath string
+			var contents []byte
+
+			BeforeEach(func() {
+				var err error
+				str, err = storeUnstructuredEncrypted.NewStore(cfg, fileStore)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(str).ToNot(BeNil())
+				ctx = log.NewContextWithLogger(context.Background(), logNull.NewLogger())
+				key = storeUnstructuredTest.RandomKey()
+				keyPath = filepath.Join(directory, filepath.FromSlash(key))
+				contents = test.RandomBytesFromRange(0, 200000)
+			})
+
+			get := func(str *storeUnstructuredEncrypted.Store) ([]byte, error) {
+				reader, err := str.Get(ctx, key)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reader).ToNot(BeNil())
+				defer reader.Close()
+				return ioutil.ReadAll(reader)
+			}
+
+			Context("Exists", func() {
+				It("returns an error if the context is missing", func() {
+					exists, err := str.Exists(nil, key)
+					Expect(err).To(MatchError("context is missing"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns an error if the key is missing", func() {
+					exists, err := str.Exists(ctx, "")
+					Expect(err).To(MatchError("key is missing"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns an error if the key is invalid", func() {
+					exists, err := str.Exists(ctx, "#invalid#")
+					Expect(err).To(MatchError("key is invalid"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns false if the key does not exist", func() {
+					Expect(str.Exists(ctx, key)).To(BeFalse())
+				})
+
+				It("returns true if the key exists", func() {
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					Expect(str.Exists(ctx, key)).To(BeTrue())
+				})
+			})
+
+			Context("Put", func() {
+				It("returns an error if the context is missing", func() {
+					Expect(str.Put(nil, key, bytes.NewReader(contents), nil)).To(MatchError("context is missing"))
+				})
+
+				It("returns an error if the key is missing", func() {
+					Expect(str.Put(ctx, "", bytes.NewReader(contents), nil)).To(MatchError("key is missing"))
+				})
+
+				It("returns an error if the key is invalid", func() {
+					Expect(str.Put(ctx, "#invalid#", bytes.NewReader(contents), nil)).To(MatchError("key is invalid"))
+				})
+
+				It("returns an error if the reader is missing", func() {
+					Expect(str.Put(ctx, key, nil, nil)).To(MatchError("reader is missing"))
+				})
+
+				It("returns an error if the reader returns an error", func() {
+					reader := test.NewReader()
+					reader.ReadOutputs = []test.ReadOutput{{BytesRead: 0, Error: errorsTest.NewError()}}
+					Expect(str.Put(ctx, key, reader, nil)).ToNot(Succeed())
+				})
+
+				It("writes encrypted content", func() {
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					encryptedContents, err := ioutil.ReadFile(keyPath)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(encryptedContents)).To(BeNumerically(">", len(contents)))
+					if len(contents) > 16 {
+						Expect(bytes.Contains(encryptedContents, contents[:16])).To(BeFalse())
+					}
+				})
+
+				It("returns an error if the options is invalid", func() {
+					options := storeUnstructured.NewOptions()
+					options.MediaType = pointer.FromString("/")
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), options)).To(MatchError(`options is invalid; value "/" is not valid as media type`))
+				})
+
+				It("returns an error if the options metadata contains the reserved key", func() {
+					options := storeUnstructured.NewOptions()
+					options.Metadata = map[string]string{storeUnstructuredEncrypted.MetadataKeyKeyID: "alpha"}
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), options)).To(MatchError(`options is invalid; metadata key "encrypted-key-id" is reserved`))
+				})
+
+				It("writes the media type and metadata along with the key id", func() {
+					options := storeUnstructured.NewOptions()
+					options.MediaType = pointer.FromString(netTest.RandomMediaType())
+					options.Metadata = map[string]string{"a": test.RandomString()}
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), options)).To(Succeed())
+					info, err := fileStore.Stat(ctx, key)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(info).ToNot(BeNil())
+					Expect(info.MediaType).To(Equal(options.MediaType))
+					Expect(info.Metadata).To(Equal(map[string]string{"a": options.Metadata["a"], storeUnstructuredEncrypted.MetadataKeyKeyID: "alpha"}))
+				})
+
+				It("writes different encrypted content for the same content", func() {
+					otherKey := storeUnstructuredTest.RandomKey()
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					Expect(str.Put(ctx, otherKey, bytes.NewReader(contents), nil)).To(Succeed())
+					encryptedContents, err := ioutil.ReadFile(keyPath)
+					Expect(err).ToNot(HaveOccurred())
+					otherEncryptedContents, err := ioutil.ReadFile(filepath.Join(directory, filepath.FromSlash(otherKey)))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(encryptedContents).ToNot(Equal(otherEncryptedContents))
+				})
+			})
+
+			Context("Get", func() {
+				It("returns an error if the context is missing", func() {
+					reader, err := str.Get(nil, key)
+					Expect(err).To(MatchError("context is missing"))
+					Expect(reader).To(BeNil())
+				})
+
+				It("returns an error if the key is missing", func() {
+					reader, err := str.Get(ctx, "")
+					Expect(err).To(MatchError("key is missing"))
+					Expect(reader).To(BeNil())
+				})
+
+				It("returns an error if the key is invalid", func() {
+					reader, err := str.Get(ctx, "#invalid#")
+					Expect(err).To(MatchError("key is invalid"))
+					Expect(reader).To(BeNil())
+				})
+
+				It("returns nil if the key does not exist", func() {
+					Expect(str.Get(ctx, key)).To(BeNil())
+				})
+
+				It("returns the content if the content is not encrypted", func() {
+					Expect(fileStore.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					Expect(get(str)).To(Equal(contents))
+				})
+
+				It("returns an error if the header is invalid", func() {
+					options := storeUnstructured.NewOptions()
+					options.Metadata = map[string]string{storeUnstructuredEncrypted.MetadataKeyKeyID: "alpha"}
+					Expect(fileStore.Put(ctx, key, bytes.NewReader([]byte("TPUE")), options)).To(Succeed())
+					reader, err := str.Get(ctx, key)
+					Expect(err).To(MatchError("unable to read header; header is invalid"))
+					Expect(reader).To(BeNil())
+				})
+
+				It("returns an error if the metadata has a key id, but the content is not encrypted", func() {
+					options := storeUnstructured.NewOptions()
+					options.Metadata = map[string]string{storeUnstructuredEncrypted.MetadataKeyKeyID: "alpha"}
+					Expect(fileStore.Put(ctx, key, bytes.NewReader(contents), options)).To(Succeed())
+					reader, err := str.Get(ctx, key)
+					Expect(err).To(MatchError("unable to read header; header is invalid"))
+					Expect(reader).To(BeNil())
+				})
+
+				It("returns an error if the content is encrypted, but the metadata does not have a key id", func() {
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					encryptedContents, err := ioutil.ReadFile(keyPath)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(fileStore.Put(ctx, key, bytes.NewReader(encryptedContents), nil)).To(Succeed())
+					reader, err := str.Get(ctx, key)
+					Expect(err).To(MatchError("unable to read header; header is unexpected"))
+					Expect(reader).To(BeNil())
+				})
+
+				It("returns an error if the header key id does not match the metadata key id", func() {
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					encryptedContents, err := ioutil.ReadFile(keyPath)
+					Expect(err).ToNot(HaveOccurred())
+					options := storeUnstructured.NewOptions()
+					options.Metadata = map[string]string{storeUnstructuredEncrypted.MetadataKeyKeyID: "bravo"}
+					Expect(fileStore.Put(ctx, key, bytes.NewReader(encryptedContents), options)).To(Succeed())
+					reader, err := str.Get(ctx, key)
+					Expect(err).To(MatchError("unable to read header; header key id does not match metadata"))
+					Expect(reader).To(BeNil())
+				})
+
+				It("returns an error if the master key is not known", func() {
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					cfg.KeyID = "bravo"
+					cfg.Keys = map[string][]byte{"bravo": test.RandomBytesFromRange(32, 32)}
+					otherStr, err := storeUnstructuredEncrypted.NewStore(cfg, fileStore)
+					Expect(err).ToNot(HaveOccurred())
+					reader, err := otherStr.Get(ctx, key)
+					Expect(err).To(MatchError(`unable to unwrap data key; key with id "alpha" is missing`))
+					Expect(reader).To(BeNil())
+				})
+
+				It("returns an error if the master key is not correct", func() {
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					cfg.Keys = map[string][]byte{"alpha": test.RandomBytesFromRange(32, 32)}
+					otherStr, err := storeUnstructuredEncrypted.NewStore(cfg, fileStore)
+					Expect(err).ToNot(HaveOccurred())
+					reader, err := otherStr.Get(ctx, key)
+					Expect(err).To(MatchError("unable to unwrap data key; wrapped data key is invalid"))
+					Expect(reader).To(BeNil())
+				})
+
+				It("returns an error while reading if the content was modified", func() {
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					encryptedContents, err := ioutil.ReadFile(keyPath)
+					Expect(err).ToNot(HaveOccurred())
+					encryptedContents[len(encryptedContents)-1] ^= 0xFF
+					Expect(ioutil.WriteFile(keyPath, encryptedContents, 0666)).To(Succeed())
+					_, err = get(str)
+					Expect(err).To(MatchError("content is invalid"))
+				})
+
+				It("returns an error while reading if the content was truncated", func() {
+					contents = test.RandomBytesFromRange(200000, 200000)
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					encryptedContents, err := ioutil.ReadFile(keyPath)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ioutil.WriteFile(keyPath, encryptedContents[:len(encryptedContents)-(200000%65536)-16], 0666)).To(Succeed())
+					_, err = get(str)
+					Expect(err).To(MatchError("content is invalid"))
+				})
+
+				It("returns the decrypted content", func() {
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					Expect(get(str)).To(Equal(contents))
+				})
+
+				It("returns the decrypted content if empty", func() {
+					contents = []byte{}
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					Expect(get(str)).To(BeEmpty())
+				})
+
+				It("returns the decrypted content if an exact multiple of the segment size", func() {
+					contents = test.RandomBytesFromRange(131072, 131072)
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					Expect(get(str)).To(Equal(contents))
+				})
+
+				It("returns the decrypted content with a previous master key", func() {
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					cfg.KeyID = "bravo"
+					cfg.Keys["bravo"] = test.RandomBytesFromRange(32, 32)
+					otherStr, err := storeUnstructuredEncrypted.NewStore(cfg, fileStore)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(get(otherStr)).To(Equal(contents))
+				})
+			})
+
+			Context("Stat", func() {
+				It("returns an error if the context is missing", func() {
+					info, err := str.Stat(nil, key)
+					Expect(err).To(MatchError("context is missing"))
+					Expect(info).To(BeNil())
+				})
+
+				It("returns an error if the key is missing", func() {
+					info, err := str.Stat(ctx, "")
+					Expect(err).To(MatchError("key is missing"))
+					Expect(info).To(BeNil())
+				})
+
+				It("returns an error if the key is invalid", func() {
+					info, err := str.Stat(ctx, "#invalid#")
+					Expect(err).To(MatchError("key is invalid"))
+					Expect(info).To(BeNil())
+				})
+
+				It("returns nil if the key does not exist", func() {
+					Expect(str.Stat(ctx, key)).To(BeNil())
+				})
+
+				It("returns the info as is if the content is not encrypted", func() {
+					Expect(fileStore.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					Expect(str.Stat(ctx, key)).To(PointTo(MatchFields(IgnoreExtras, Fields{"Size": Equal(int64(len(contents)))})))
+				})
+
+				It("returns an error if the size is invalid", func() {
+					options := storeUnstructured.NewOptions()
+					options.Metadata = map[string]string{storeUnstructuredEncrypted.MetadataKeyKeyID: "alpha"}
+					Expect(fileStore.Put(ctx, key, bytes.NewReader(nil), options)).To(Succeed())
+					info, err := str.Stat(ctx, key)
+					Expect(err).To(MatchError("unable to determine content size; size is invalid"))
+					Expect(info).To(BeNil())
+				})
+
+				DescribeTable("returns the size of the decrypted content",
+					func(size int) {
+						contents = test.RandomBytesFromRange(size, size)
+						options := storeUnstructured.NewOptions()
+						options.MediaType = pointer.FromString(netTest.RandomMediaType())
+						options.Metadata = map[string]string{"a": test.RandomString()}
+						Expect(str.Put(ctx, key, bytes.NewReader(contents), options)).To(Succeed())
+						info, err := str.Stat(ctx, key)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(info).ToNot(BeNil())
+						Expect(info.Size).To(Equal(int64(size)))
+						Expect(info.ModifiedTime).ToNot(BeZero())
+						Expect(info.MediaType).To(Equal(options.MediaType))
+						Expect(info.Metadata).To(Equal(options.Metadata))
+					},
+					Entry("is empty", 0),
+					Entry("is less than the segment size", 1),
+					Entry("is one less than the segment size", 64*1024-1),
+					Entry("is the segment size", 64*1024),
+					Entry("is one more than the segment size", 64*1024+1),
+					Entry("is multiple segments", 3*64*1024+12345),
+				)
+
+				It("returns the info without metadata if none was specified", func() {
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					info, err := str.Stat(ctx, key)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(info).ToNot(BeNil())
+					Expect(info.Size).To(Equal(int64(len(contents))))
+					Expect(info.MediaType).To(BeNil())
+					Expect(info.Metadata).To(BeNil())
+				})
+			})
+
+			Context("Copy", func() {
+				var destinationKey string
+
+				BeforeEach(func() {
+					destinationKey = storeUnstructuredTest.RandomKey()
+				})
+
+				It("returns an error if the context is missing", func() {
+					exists, err := str.Copy(nil, key, destinationKey)
+					Expect(err).To(MatchError("context is missing"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns an error if the source key is missing", func() {
+					exists, err := str.Copy(ctx, "", destinationKey)
+					Expect(err).To(MatchError("source key is missing"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns an error if the source key is invalid", func() {
+					exists, err := str.Copy(ctx, "#invalid#", destinationKey)
+					Expect(err).To(MatchError("source key is invalid"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns an error if the destination key is missing", func() {
+					exists, err := str.Copy(ctx, key, "")
+					Expect(err).To(MatchError("destination key is missing"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns an error if the destination key is invalid", func() {
+					exists, err := str.Copy(ctx, key, key)
+					Expect(err).To(MatchError("destination key is invalid"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns false if the source key does not exist", func() {
+					Expect(str.Copy(ctx, key, destinationKey)).To(BeFalse())
+				})
+
+				It("returns true and the copied content is decryptable", func() {
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					Expect(str.Copy(ctx, key, destinationKey)).To(BeTrue())
+					key = destinationKey
+					Expect(get(str)).To(Equal(contents))
+				})
+			})
+
+			Context("List", func() {
+				It("returns an error if the context is missing", func() {
+					keys, err := str.List(nil, "", nil)
+					Expect(err).To(MatchError("context is missing"))
+					Expect(keys).To(BeNil())
+				})
+
+				It("returns an error if the prefix is invalid", func() {
+					keys, err := str.List(ctx, "#invalid#", nil)
+					Expect(err).To(MatchError("prefix is invalid"))
+					Expect(keys).To(BeNil())
+				})
+
+				It("returns the keys", func() {
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					Expect(str.List(ctx, "", nil)).To(Equal([]string{key}))
+				})
+			})
+
+			Context("Delete", func() {
+				It("returns an error if the context is missing", func() {
+					deleted, err := str.Delete(nil, key)
+					Expect(err).To(MatchError("context is missing"))
+					Expect(deleted).To(BeFalse())
+				})
+
+				It("returns an error if the key is missing", func() {
+					deleted, err := str.Delete(ctx, "")
+					Expect(err).To(MatchError("key is missing"))
+					Expect(deleted).To(BeFalse())
+				})
+
+				It("returns an error if the key is invalid", func() {
+					deleted, err := str.Delete(ctx, "#invalid#")
+					Expect(err).To(MatchError("key is invalid"))
+					Expect(deleted).To(BeFalse())
+				})
+
+				It("returns false if the key does not exist", func() {
+					Expect(str.Delete(ctx, key)).To(BeFalse())
+				})
+
+				It("returns true if the key exists", func() {
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					Expect(str.Delete(ctx, key)).To(BeTrue())
+					Expect(str.Exists(ctx, key)).To(BeFalse())
+				})
+			})
+
+			Context("Rewrap", func() {
+				It("returns an error if the context is missing", func() {
+					rewrapped, err := str.Rewrap(nil, key)
+					Expect(err).To(MatchError("context is missing"))
+					Expect(rewrapped).To(BeFalse())
+				})
+
+				It("returns an error if the key is missing", func() {
+					rewrapped, err := str.Rewrap(ctx, "")
+					Expect(err).To(MatchError("key is missing"))
+					Expect(rewrapped).To(BeFalse())
+				})
+
+				It("returns an error if the key is invalid", func() {
+					rewrapped, err := str.Rewrap(ctx, "#invalid#")
+					Expect(err).To(MatchError("key is invalid"))
+					Expect(rewrapped).To(BeFalse())
+				})
+
+				It("returns false if the key does not exist", func() {
+					Expect(str.Rewrap(ctx, key)).To(BeFalse())
+				})
+
+				It("returns false if already wrapped with the current master key", func() {
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					Expect(str.Rewrap(ctx, key)).To(BeFalse())
+				})
+
+				It("returns true and encrypts the content if the content is not encrypted", func() {
+					options := storeUnstructured.NewOptions()
+					options.MediaType = pointer.FromString(netTest.RandomMediaType())
+					options.Metadata = map[string]string{"a": test.RandomString()}
+					Expect(fileStore.Put(ctx, key, bytes.NewReader(contents), options)).To(Succeed())
+					Expect(str.Rewrap(ctx, key)).To(BeTrue())
+					Expect(str.Rewrap(ctx, key)).To(BeFalse())
+					info, err := fileStore.Stat(ctx, key)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(info).ToNot(BeNil())
+					Expect(info.MediaType).To(Equal(options.MediaType))
+					Expect(info.Metadata).To(Equal(map[string]string{"a": options.Metadata["a"], storeUnstructuredEncrypted.MetadataKeyKeyID: "alpha"}))
+					Expect(info.Size).ToNot(Equal(int64(len(contents))))
+					Expect(get(str)).To(Equal(contents))
+				})
+
+				Context("with rotated master key", func() {
+					var rotatedStr *storeUnstructuredEncrypted.Store
+
+					BeforeEach(func() {
+						var err error
+						Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+						cfg.KeyID = "bravo"
+						cfg.Keys["bravo"] = test.RandomBytesFromRange(32, 32)
+						rotatedStr, err = storeUnstructuredEncrypted.NewStore(cfg, fileStore)
+						Expect(err).ToNot(HaveOccurred())
+					})
+
+					It("returns an error if the previous master key is not known", func() {
+						delete(cfg.Keys, "alpha")
+						otherStr, err := storeUnstructuredEncrypted.NewStore(cfg, fileStore)
+						Expect(err).ToNot(HaveOccurred())
+						rewrapped, err := otherStr.Rewrap(ctx, key)
+						Expect(err).To(MatchError(`unable to unwrap data key; key with id "alpha" is missing`))
+						Expect(rewrapped).To(BeFalse())
+					})
+
+					It("returns true and preserves the media type and metadata", func() {
+						options := storeUnstructured.NewOptions()
+						options.MediaType = pointer.FromString(netTest.RandomMediaType())
+						options.Metadata = map[string]string{"a": test.RandomString()}
+						Expect(str.Put(ctx, key, bytes.NewReader(contents), options)).To(Succeed())
+						Expect(rotatedStr.Rewrap(ctx, key)).To(BeTrue())
+						info, err := fileStore.Stat(ctx, key)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(info).ToNot(BeNil())
+						Expect(info.MediaType).To(Equal(options.MediaType))
+						Expect(info.Metadata).To(Equal(map[string]string{"a": options.Metadata["a"], storeUnstructuredEncrypted.MetadataKeyKeyID: "bravo"}))
+						Expect(rotatedStr.Stat(ctx, key)).To(PointTo(MatchFields(IgnoreExtras, Fields{"Size": Equal(int64(len(contents)))})))
+					})
+
+					It("returns true and the content is readable with only the current master key", func() {
+						Expect(rotatedStr.Rewrap(ctx, key)).To(BeTrue())
+						Expect(rotatedStr.Rewrap(ctx, key)).To(BeFalse())
+						delete(cfg.Keys, "alpha")
+						otherStr, err := storeUnstructuredEncrypted.NewStore(cfg, fileStore)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(get(otherStr)).To(Equal(contents))
+					})
+				})
+			})
+		})
+
+		Context("with new store with mock store", func() {
+			var mockStore *storeUnstructuredTest.Store
+			var str *storeUnstructuredEncrypted.Store
+			var ctx context.Context
+			var key string
+
+			BeforeEach(func() {
+				var err error
+				mockStore = storeUnstructuredTest.NewStore()
+				str, err = storeUnstructuredEncrypted.NewStore(cfg, mockStore)
+				Expect(err).ToNot(HaveOccurred())
+				ctx = log.NewContextWithLogger(context.Background(), logNull.NewLogger())
+				key = storeUnstructuredTest.RandomKey()
+			})
+
+			AfterEach(func() {
+				mockStore.AssertOutputsEmpty()
+			})
+
+			It("returns an error if the store exists returns an error", func() {
+				responseErr := errorsTest.NewError()
+				mockStore.ExistsOutputs = []storeUnstructuredTest.ExistsOutput{{Exists: false, Error: responseErr}}
+				exists, err := str.Exists(ctx, key)
+				Expect(err).To(Equal(responseErr))
+				Expect(exists).To(BeFalse())
+			})
+
+			It("returns an error if the store put returns an error", func() {
+				responseErr := errorsTest.NewError()
+				mockStore.PutOutputs = []error{responseErr}
+				Expect(str.Put(ctx, key, bytes.NewReader(test.RandomBytes()), nil)).To(Equal(responseErr))
+			})
+
+			It("returns an error if the store get stat returns an error", func() {
+				responseErr := errorsTest.NewError()
+				mockStore.StatOutputs = []storeUnstructuredTest.StatOutput{{Info: nil, Error: responseErr}}
+				reader, err := str.Get(ctx, key)
+				Expect(err).To(Equal(responseErr))
+				Expect(reader).To(BeNil())
+			})
+
+			It("returns an error if the store get returns an error", func() {
+				responseErr := errorsTest.NewError()
+				mockStore.StatOutputs = []storeUnstructuredTest.StatOutput{{Info: &storeUnstructured.Info{}, Error: nil}}
+				mockStore.GetOutputs = []storeUnstructuredTest.GetOutput{{Reader: nil, Error: responseErr}}
+				reader, err := str.Get(ctx, key)
+				Expect(err).To(Equal(responseErr))
+				Expect(reader).To(BeNil())
+			})
+
+			It("returns an error if the store delete returns an error", func() {
+				responseErr := errorsTest.NewError()
+				mockStore.DeleteOutputs = []storeUnstructuredTest.DeleteOutput{{Deleted: false, Error: responseErr}}
+				deleted, err := str.Delete(ctx, key)
+				Expect(err).To(Equal(responseErr))
+				Expect(deleted).To(BeFalse())
+			})
+
+			It("returns an error if the store stat returns an error", func() {
+				responseErr := errorsTest.NewError()
+				mockStore.StatOutputs = []storeUnstructuredTest.StatOutput{{Info: nil, Error: responseErr}}
+				info, err := str.Stat(ctx, key)
+				Expect(err).To(Equal(responseErr))
+				Expect(info).To(BeNil())
+			})
+
+			It("returns an error if the store copy returns an error", func() {
+				responseErr := errorsTest.NewError()
+				mockStore.CopyOutputs = []storeUnstructuredTest.CopyOutput{{Exists: false, Error: responseErr}}
+				exists, err := str.Copy(ctx, key, storeUnstructuredTest.RandomKey())
+				Expect(err).To(Equal(responseErr))
+				Expect(exists).To(BeFalse())
+			})
+
+			It("returns an error if the store list returns an error", func() {
+				responseErr := errorsTest.NewError()
+				mockStore.ListOutputs = []storeUnstructuredTest.ListOutput{{Keys: nil, Error: responseErr}}
+				keys, err := str.List(ctx, "", nil)
+				Expect(err).To(Equal(responseErr))
+				Expect(keys).To(BeNil())
+			})
+
+			It("returns an error if the store rewrap stat returns an error", func() {
+				responseErr := errorsTest.NewError()
+				mockStore.StatOutputs = []storeUnstructuredTest.StatOutput{{Info: nil, Error: responseErr}}
+				rewrapped, err := str.Rewrap(ctx, key)
+				Expect(err).To(Equal(responseErr))
+				Expect(rewrapped).To(BeFalse())
+			})
+
+			It("returns an error if the store rewrap get returns an error", func() {
+				responseErr := errorsTest.NewError()
+				mockStore.StatOutputs = []storeUnstructuredTest.StatOutput{{Info: &storeUnstructured.Info{}, Error: nil}}
+				mockStore.GetOutputs = []storeUnstructuredTest.GetOutput{{Reader: nil, Error: responseErr}}
+				rewrapped, err := str.Rewrap(ctx, key)
+				Expect(err).To(Equal(responseErr))
+				Expect(rewrapped).To(BeFalse())
+			})
+		})
+	})
+})
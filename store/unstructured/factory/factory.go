@@ -1,10 +1,14 @@
 package factory
 
 import (
+	"strconv"
+
 	"github.com/tidepool-org/platform/aws"
 	"github.com/tidepool-org/platform/config"
+	cryptoKey "github.com/tidepool-org/platform/crypto/key"
 	"github.com/tidepool-org/platform/errors"
 	storeUnstructured "github.com/tidepool-org/platform/store/unstructured"
+	storeUnstructuredEncrypted "github.com/tidepool-org/platform/store/unstructured/encrypted"
 	storeUnstructuredFile "github.com/tidepool-org/platform/store/unstructured/file"
 	storeUnstructuredS3 "github.com/tidepool-org/platform/store/unstructured/s3"
 )
@@ -24,13 +28,29 @@ func NewStore(configReporter config.Reporter, awsAPI aws.API) (storeUnstructured
 		return nil, errors.New("type is empty")
 	}
 
+	var str storeUnstructured.Store
 	switch typ {
 	case storeUnstructuredFile.Type:
-		return NewFileStore(configReporter.WithScopes(storeUnstructuredFile.Type))
+		str, err = NewFileStore(configReporter.WithScopes(storeUnstructuredFile.Type))
 	case storeUnstructuredS3.Type:
-		return NewS3Store(configReporter.WithScopes(storeUnstructuredS3.Type), awsAPI)
+		str, err = NewS3Store(configReporter.WithScopes(storeUnstructuredS3.Type), awsAPI)
+	default:
+		return nil, errors.New("type is invalid")
+	}
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New("type is invalid")
+
+	if encryptedString, encryptedErr := configReporter.Get("encrypted"); encryptedErr == nil {
+		var encrypted bool
+		if encrypted, err = strconv.ParseBool(encryptedString); err != nil {
+			return nil, errors.New("encrypted is invalid")
+		} else if encrypted {
+			return NewEncryptedStore(configReporter.WithScopes("encryption"), str)
+		}
+	}
+
+	return str, nil
 }
 
 func NewFileStore(configReporter config.Reporter) (storeUnstructured.Store, error) {
@@ -48,3 +68,12 @@ func NewS3Store(configReporter config.Reporter, awsAPI aws.API) (storeUnstructur
 	}
 	return storeUnstructuredS3.NewStore(cfg, awsAPI)
 }
+
+func NewEncryptedStore(configReporter config.Reporter, store storeUnstructured.Store) (storeUnstructured.Store, error) {
+	cfg := cryptoKey.NewConfig()
+	if err := cfg.Load(configReporter); err != nil {
+		return nil, errors.Wrap(err, "unable to load config")
+	}
+
+	return storeUnstructuredEncrypted.NewStore(cfg, store)
+}
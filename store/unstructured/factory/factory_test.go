@@ -4,11 +4,16 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"encoding/base64"
+	"fmt"
 	"os"
 
 	awsTest "github.com/tidepool-org/platform/aws/test"
 	configTest "github.com/tidepool-org/platform/config/test"
+	storeUnstructuredEncrypted "github.com/tidepool-org/platform/store/unstructured/encrypted"
 	storeUnstructuredFactory "github.com/tidepool-org/platform/store/unstructured/factory"
+	storeUnstructuredFile "github.com/tidepool-org/platform/store/unstructured/file"
+	storeUnstructuredTest "github.com/tidepool-org/platform/store/unstructured/test"
 	"github.com/tidepool-org/platform/test"
 )
 
@@ -87,6 +92,43 @@ var _ = Describe("Factory", func() {
 			It("returns successfully", func() {
 				Expect(storeUnstructuredFactory.NewStore(configReporter, awsAPI)).ToNot(BeNil())
 			})
+
+			Context("with encrypted", func() {
+				BeforeEach(func() {
+					configReporter.Config["encrypted"] = "true"
+					configReporter.Config["encryption"] = map[string]interface{}{
+						"key_id": "alpha",
+						"keys":   fmt.Sprintf("alpha:%s", base64.StdEncoding.EncodeToString(test.RandomBytesFromRange(32, 32))),
+					}
+				})
+
+				It("returns an error if encrypted is invalid", func() {
+					configReporter.Config["encrypted"] = "invalid"
+					str, err := storeUnstructuredFactory.NewStore(configReporter, awsAPI)
+					Expect(err).To(MatchError("encrypted is invalid"))
+					Expect(str).To(BeNil())
+				})
+
+				It("returns an error if the encryption config is invalid", func() {
+					delete(configReporter.Config, "encryption")
+					str, err := storeUnstructuredFactory.NewStore(configReporter, awsAPI)
+					Expect(err).To(MatchError("config is invalid; key id is missing"))
+					Expect(str).To(BeNil())
+				})
+
+				It("returns successfully with an unencrypted store if encrypted is false", func() {
+					configReporter.Config["encrypted"] = "false"
+					str, err := storeUnstructuredFactory.NewStore(configReporter, awsAPI)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(str).To(BeAssignableToTypeOf(&storeUnstructuredFile.Store{}))
+				})
+
+				It("returns successfully with an encrypted store", func() {
+					str, err := storeUnstructuredFactory.NewStore(configReporter, awsAPI)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(str).To(BeAssignableToTypeOf(&storeUnstructuredEncrypted.Store{}))
+				})
+			})
 		})
 
 		Context("with type s3", func() {
@@ -114,6 +156,17 @@ var _ = Describe("Factory", func() {
 			It("returns successfully", func() {
 				Expect(storeUnstructuredFactory.NewStore(configReporter, awsAPI)).ToNot(BeNil())
 			})
+
+			It("returns successfully with an encrypted store", func() {
+				configReporter.Config["encrypted"] = "true"
+				configReporter.Config["encryption"] = map[string]interface{}{
+					"key_id": "alpha",
+					"keys":   fmt.Sprintf("alpha:%s", base64.StdEncoding.EncodeToString(test.RandomBytesFromRange(32, 32))),
+				}
+				str, err := storeUnstructuredFactory.NewStore(configReporter, awsAPI)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(str).To(BeAssignableToTypeOf(&storeUnstructuredEncrypted.Store{}))
+			})
 		})
 	})
 
@@ -179,3 +232,38 @@ var _ = Describe("Factory", func() {
 		})
 	})
 })
+
+var _ = Describe("NewEncryptedStore", func() {
+	var configReporter *configTest.Reporter
+	var str *storeUnstructuredTest.Store
+
+	BeforeEach(func() {
+		configReporter = configTest.NewReporter()
+		configReporter.Config["key_id"] = "alpha"
+		configReporter.Config["keys"] = fmt.Sprintf("alpha:%s", base64.StdEncoding.EncodeToString(test.RandomBytesFromRange(32, 32)))
+		str = storeUnstructuredTest.NewStore()
+	})
+
+	It("returns an error if the config reporter is missing", func() {
+		encryptedStore, err := storeUnstructuredFactory.NewEncryptedStore(nil, str)
+		Expect(err).To(MatchError("unable to load config; config reporter is missing"))
+		Expect(encryptedStore).To(BeNil())
+	})
+
+	It("returns an error if the store is missing", func() {
+		encryptedStore, err := storeUnstructuredFactory.NewEncryptedStore(configReporter, nil)
+		Expect(err).To(MatchError("store is missing"))
+		Expect(encryptedStore).To(BeNil())
+	})
+
+	It("returns an error if the config is invalid", func() {
+		delete(configReporter.Config, "keys")
+		encryptedStore, err := storeUnstructuredFactory.NewEncryptedStore(configReporter, str)
+		Expect(err).To(MatchError("config is invalid; keys is missing"))
+		Expect(encryptedStore).To(BeNil())
+	})
+
+	It("returns successfully", func() {
+		Expect(storeUnstructuredFactory.NewEncryptedStore(configReporter, str)).ToNot(BeNil())
+	})
+})
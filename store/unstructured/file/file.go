@@ -2,18 +2,27 @@ package file
 
 import (
 	"context"
+	"encoding/json"
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/page"
 	storeUnstructured "github.com/tidepool-org/platform/store/unstructured"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
 )
 
 const Type = "file"
 
+// Media type and metadata are stored in a parallel directory tree that cannot collide with any valid key
+const metadataDirectory = ".metadata"
+
 type Store struct {
 	directory string
 }
@@ -61,7 +70,7 @@ func (s *Store) Exists(ctx context.Context, key string) (bool, error) {
 	return exists, nil
 }
 
-func (s *Store) Put(ctx context.Context, key string, reader io.Reader) error {
+func (s *Store) Put(ctx context.Context, key string, reader io.Reader, options *storeUnstructured.Options) error {
 	if ctx == nil {
 		return errors.New("context is missing")
 	}
@@ -73,30 +82,22 @@ func (s *Store) Put(ctx context.Context, key string, reader io.Reader) error {
 	if reader == nil {
 		return errors.New("reader is missing")
 	}
+	if options == nil {
+		options = storeUnstructured.NewOptions()
+	} else if err := structureValidator.New().Validate(options); err != nil {
+		return errors.Wrap(err, "options is invalid")
+	}
 
 	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"directory": s.directory, "key": key})
-	filePath := s.resolveKey(key)
-	directoryPath := filepath.Dir(filePath)
-
-	err := os.MkdirAll(directoryPath, 0777)
-	if err != nil {
-		logger.WithError(err).Errorf("Unable to create directories at path %q", directoryPath)
-		return errors.Wrapf(err, "unable to create directories at path %q", directoryPath)
-	}
 
-	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
-	if err != nil {
-		logger.WithError(err).Errorf("Unable to create file at path %q", filePath)
-		return errors.Wrapf(err, "unable to create file at path %q", filePath)
+	if err := s.writeFile(s.resolveKey(key), reader); err != nil {
+		logger.WithError(err).Error("Unable to write file")
+		return err
 	}
 
-	_, err = io.Copy(file, reader)
-	if closeErr := file.Close(); err == nil {
-		err = closeErr
-	}
-	if err != nil {
-		logger.WithError(err).Errorf("Unable to write file at path %q", filePath)
-		return errors.Wrapf(err, "unable to write file at path %q", filePath)
+	if err := s.writeMetadata(key, &metadata{MediaType: options.MediaType, Metadata: options.Metadata}); err != nil {
+		logger.WithError(err).Error("Unable to write metadata")
+		return err
 	}
 
 	logger.Debug("Put")
@@ -138,6 +139,152 @@ func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
 	return reader, nil
 }
 
+func (s *Store) Stat(ctx context.Context, key string) (*storeUnstructured.Info, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if key == "" {
+		return nil, errors.New("key is missing")
+	} else if !storeUnstructured.IsValidKey(key) {
+		return nil, errors.New("key is invalid")
+	}
+
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"directory": s.directory, "key": key})
+	filePath := s.resolveKey(key)
+
+	var info *storeUnstructured.Info
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.WithError(err).Errorf("Unable to stat file at path %q", filePath)
+			return nil, errors.Wrapf(err, "unable to stat file at path %q", filePath)
+		}
+	} else if !fileInfo.Mode().IsRegular() {
+		logger.Errorf("Unexpected directory or irregular file at path %q", filePath)
+		return nil, errors.Newf("unexpected directory or irregular file at path %q", filePath)
+	} else if mtdt, mtdtErr := s.readMetadata(key); mtdtErr != nil {
+		logger.WithError(mtdtErr).Error("Unable to read metadata")
+		return nil, mtdtErr
+	} else {
+		info = &storeUnstructured.Info{
+			Size:         fileInfo.Size(),
+			ModifiedTime: fileInfo.ModTime().UTC(),
+			MediaType:    mtdt.MediaType,
+			Metadata:     mtdt.Metadata,
+		}
+	}
+
+	logger.WithField("exists", info != nil).Debug("Stat")
+	return info, nil
+}
+
+func (s *Store) Copy(ctx context.Context, sourceKey string, destinationKey string) (bool, error) {
+	if ctx == nil {
+		return false, errors.New("context is missing")
+	}
+	if sourceKey == "" {
+		return false, errors.New("source key is missing")
+	} else if !storeUnstructured.IsValidKey(sourceKey) {
+		return false, errors.New("source key is invalid")
+	}
+	if destinationKey == "" {
+		return false, errors.New("destination key is missing")
+	} else if !storeUnstructured.IsValidKey(destinationKey) || destinationKey == sourceKey {
+		return false, errors.New("destination key is invalid")
+	}
+
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"directory": s.directory, "sourceKey": sourceKey, "destinationKey": destinationKey})
+
+	reader, err := s.Get(ctx, sourceKey)
+	if err != nil {
+		return false, err
+	} else if reader == nil {
+		logger.WithField("exists", false).Debug("Copy")
+		return false, nil
+	}
+	defer reader.Close()
+
+	mtdt, err := s.readMetadata(sourceKey)
+	if err != nil {
+		logger.WithError(err).Error("Unable to read metadata")
+		return false, err
+	}
+
+	if err = s.writeFile(s.resolveKey(destinationKey), reader); err != nil {
+		logger.WithError(err).Error("Unable to write file")
+		return false, err
+	}
+
+	if err = s.writeMetadata(destinationKey, mtdt); err != nil {
+		logger.WithError(err).Error("Unable to write metadata")
+		return false, err
+	}
+
+	logger.WithField("exists", true).Debug("Copy")
+	return true, nil
+}
+
+func (s *Store) List(ctx context.Context, prefix string, pagination *page.Pagination) ([]string, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if !storeUnstructured.IsValidKeyPrefix(prefix) {
+		return nil, errors.New("prefix is invalid")
+	}
+	if pagination == nil {
+		pagination = page.NewPagination()
+	} else if err := structureValidator.New().Validate(pagination); err != nil {
+		return nil, errors.Wrap(err, "pagination is invalid")
+	}
+
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"directory": s.directory, "prefix": prefix, "pagination": pagination})
+	directoryPath := s.resolveKey(path.Dir(prefix))
+
+	keys := []string{}
+	err := filepath.Walk(directoryPath, func(filePath string, fileInfo os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		relativePath, err := filepath.Rel(s.directory, filePath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(relativePath)
+
+		if fileInfo.IsDir() {
+			if fileInfo.Name() == metadataDirectory {
+				return filepath.SkipDir
+			} else if key != "." && !strings.HasPrefix(key+"/", prefix) && !strings.HasPrefix(prefix, key+"/") {
+				return filepath.SkipDir
+			}
+		} else if fileInfo.Mode().IsRegular() && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.WithError(err).Errorf("Unable to walk directory at path %q", directoryPath)
+		return nil, errors.Wrapf(err, "unable to walk directory at path %q", directoryPath)
+	}
+
+	sort.Strings(keys)
+
+	if start := pagination.Page * pagination.Size; start >= len(keys) {
+		keys = []string{}
+	} else if end := start + pagination.Size; end < len(keys) {
+		keys = keys[start:end]
+	} else {
+		keys = keys[start:]
+	}
+
+	logger.WithField("count", len(keys)).Debug("List")
+	return keys, nil
+}
+
 func (s *Store) Delete(ctx context.Context, key string) (bool, error) {
 	if ctx == nil {
 		return false, errors.New("context is missing")
@@ -168,10 +315,11 @@ func (s *Store) Delete(ctx context.Context, key string) (bool, error) {
 		}
 	} else {
 		exists = true
-		for key = path.Dir(key); key != "."; key = path.Dir(key) {
-			if err = os.Remove(s.resolveKey(key)); err != nil {
-				break
-			}
+		s.removeDirectories(s.directory, key)
+		if removeErr = os.Remove(s.resolveMetadataKey(key)); removeErr == nil {
+			s.removeDirectories(filepath.Join(s.directory, metadataDirectory), key)
+		} else if !os.IsNotExist(removeErr) {
+			logger.WithError(removeErr).Warn("Unable to remove metadata")
 		}
 	}
 
@@ -179,6 +327,80 @@ func (s *Store) Delete(ctx context.Context, key string) (bool, error) {
 	return exists, nil
 }
 
+func (s *Store) writeFile(filePath string, reader io.Reader) error {
+	directoryPath := filepath.Dir(filePath)
+	if err := os.MkdirAll(directoryPath, 0777); err != nil {
+		return errors.Wrapf(err, "unable to create directories at path %q", directoryPath)
+	}
+
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create file at path %q", filePath)
+	}
+
+	_, err = io.Copy(file, reader)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrapf(err, "unable to write file at path %q", filePath)
+	}
+
+	return nil
+}
+
+func (s *Store) readMetadata(key string) (*metadata, error) {
+	filePath := s.resolveMetadataKey(key)
+
+	bytes, err := ioutil.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return &metadata{}, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "unable to read file at path %q", filePath)
+	}
+
+	mtdt := &metadata{}
+	if err = json.Unmarshal(bytes, mtdt); err != nil {
+		return nil, errors.Wrapf(err, "unable to decode file at path %q", filePath)
+	}
+	return mtdt, nil
+}
+
+func (s *Store) writeMetadata(key string, mtdt *metadata) error {
+	filePath := s.resolveMetadataKey(key)
+
+	if mtdt.MediaType == nil && len(mtdt.Metadata) == 0 {
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "unable to remove file at path %q", filePath)
+		}
+		return nil
+	}
+
+	bytes, err := json.Marshal(mtdt)
+	if err != nil {
+		return errors.Wrapf(err, "unable to encode file at path %q", filePath)
+	}
+	return s.writeFile(filePath, strings.NewReader(string(bytes)))
+}
+
+// removeDirectories removes any empty parent directories of the key, stopping at the first that is not empty
+func (s *Store) removeDirectories(directory string, key string) {
+	for key = path.Dir(key); key != "."; key = path.Dir(key) {
+		if err := os.Remove(filepath.Join(directory, filepath.FromSlash(key))); err != nil {
+			break
+		}
+	}
+}
+
 func (s *Store) resolveKey(key string) string {
 	return filepath.Join(s.directory, filepath.FromSlash(key))
 }
+
+func (s *Store) resolveMetadataKey(key string) string {
+	return filepath.Join(s.directory, metadataDirectory, filepath.FromSlash(key)+".json")
+}
+
+type metadata struct {
+	MediaType *string           `json:"mediaType,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
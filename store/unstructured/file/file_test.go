@@ -2,7 +2,9 @@ package file_test
 
 import (
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
 
 	"bytes"
 	"context"
@@ -12,10 +14,15 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"time"
 
 	errorsTest "github.com/tidepool-org/platform/errors/test"
 	"github.com/tidepool-org/platform/log"
 	logNull "github.com/tidepool-org/platform/log/null"
+	netTest "github.com/tidepool-org/platform/net/test"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/pointer"
+	storeUnstructured "github.com/tidepool-org/platform/store/unstructured"
 	storeUnstructuredFile "github.com/tidepool-org/platform/store/unstructured/file"
 	storeUnstructuredTest "github.com/tidepool-org/platform/store/unstructured/test"
 	"github.com/tidepool-org/platform/test"
@@ -67,6 +74,7 @@ var _ = Describe("File", func() {
 			var ctx context.Context
 			var key string
 			var keyPath string
+			var metadataPath string
 			var contents []byte
 
 			BeforeEach(func() {
@@ -77,6 +85,7 @@ var _ = Describe("File", func() {
 				ctx = log.NewContextWithLogger(context.Background(), logNull.NewLogger())
 				key = storeUnstructuredTest.RandomKey()
 				keyPath = filepath.Join(directory, filepath.FromSlash(key))
+				metadataPath = filepath.Join(directory, ".metadata", filepath.FromSlash(key)+".json")
 				contents = []byte(test.RandomString())
 			})
 
@@ -119,50 +128,82 @@ var _ = Describe("File", func() {
 
 			Context("Put", func() {
 				var reader io.Reader
+				var options *storeUnstructured.Options
 
 				BeforeEach(func() {
 					reader = bytes.NewReader(contents)
+					options = nil
 				})
 
 				It("returns an error if the context is missing", func() {
-					Expect(str.Put(nil, key, reader)).To(MatchError("context is missing"))
+					Expect(str.Put(nil, key, reader, options)).To(MatchError("context is missing"))
 				})
 
 				It("returns an error if the key is missing", func() {
-					Expect(str.Put(ctx, "", reader)).To(MatchError("key is missing"))
+					Expect(str.Put(ctx, "", reader, options)).To(MatchError("key is missing"))
 				})
 
 				It("returns an error if the key is invalid", func() {
-					Expect(str.Put(ctx, "#invalid#", reader)).To(MatchError("key is invalid"))
+					Expect(str.Put(ctx, "#invalid#", reader, options)).To(MatchError("key is invalid"))
 				})
 
 				It("returns an error if the reader is missing", func() {
-					Expect(str.Put(ctx, key, nil)).To(MatchError("reader is missing"))
+					Expect(str.Put(ctx, key, nil, options)).To(MatchError("reader is missing"))
+				})
+
+				It("returns an error if the options is invalid", func() {
+					options = storeUnstructured.NewOptions()
+					options.MediaType = pointer.FromString("/")
+					Expect(str.Put(ctx, key, reader, options)).To(MatchError("options is invalid; value \"/\" is not valid as media type"))
 				})
 
 				It("returns an error if it is unable to create the directories", func() {
 					Expect(os.MkdirAll(filepath.Dir(keyPath), 0777)).To(Succeed())
 					Expect(ioutil.WriteFile(keyPath, contents, 0666)).To(Succeed())
 					key = path.Join(key, storeUnstructuredTest.RandomKeySegment())
-					Expect(str.Put(ctx, key, reader)).To(MatchError(fmt.Sprintf("unable to create directories at path %q; mkdir %s: not a directory", keyPath, keyPath)))
+					Expect(str.Put(ctx, key, reader, options)).To(MatchError(fmt.Sprintf("unable to create directories at path %q; mkdir %s: not a directory", keyPath, keyPath)))
 				})
 
 				It("returns an error if it is unable to create the file", func() {
 					Expect(os.MkdirAll(keyPath, 0777)).To(Succeed())
-					Expect(str.Put(ctx, key, reader)).To(MatchError(fmt.Sprintf("unable to create file at path %q; open %s: is a directory", keyPath, keyPath)))
+					Expect(str.Put(ctx, key, reader, options)).To(MatchError(fmt.Sprintf("unable to create file at path %q; open %s: is a directory", keyPath, keyPath)))
 				})
 
 				It("returns an error if it is unable to write the file", func() {
 					err := errorsTest.NewError()
 					rdr := test.NewReader()
 					rdr.ReadOutput = &test.ReadOutput{BytesRead: 0, Error: err}
-					Expect(str.Put(ctx, key, rdr)).To(MatchError(fmt.Sprintf("unable to write file at path %q; %s", keyPath, err)))
+					Expect(str.Put(ctx, key, rdr, options)).To(MatchError(fmt.Sprintf("unable to write file at path %q; %s", keyPath, err)))
 				})
 
 				It("creates file with contents and returns successfully", func() {
-					Expect(str.Put(ctx, key, reader)).To(Succeed())
+					Expect(str.Put(ctx, key, reader, options)).To(Succeed())
 					Expect(keyPath).To(BeARegularFile())
 					Expect(ioutil.ReadFile(keyPath)).To(Equal(contents))
+					Expect(metadataPath).ToNot(BeAnExistingFile())
+				})
+
+				It("creates file with contents and metadata and returns successfully", func() {
+					options = storeUnstructured.NewOptions()
+					options.MediaType = pointer.FromString(netTest.RandomMediaType())
+					options.Metadata = map[string]string{"a-b": test.RandomString()}
+					Expect(str.Put(ctx, key, reader, options)).To(Succeed())
+					Expect(keyPath).To(BeARegularFile())
+					Expect(ioutil.ReadFile(keyPath)).To(Equal(contents))
+					Expect(metadataPath).To(BeARegularFile())
+					Expect(str.Stat(ctx, key)).To(PointTo(MatchFields(IgnoreExtras, Fields{
+						"MediaType": Equal(options.MediaType),
+						"Metadata":  Equal(options.Metadata),
+					})))
+				})
+
+				It("removes existing metadata if the options are not specified", func() {
+					existingOptions := storeUnstructured.NewOptions()
+					existingOptions.MediaType = pointer.FromString(netTest.RandomMediaType())
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), existingOptions)).To(Succeed())
+					Expect(metadataPath).To(BeARegularFile())
+					Expect(str.Put(ctx, key, reader, options)).To(Succeed())
+					Expect(metadataPath).ToNot(BeAnExistingFile())
 				})
 			})
 
@@ -226,6 +267,232 @@ var _ = Describe("File", func() {
 				})
 			})
 
+			Context("Stat", func() {
+				It("returns an error if the context is missing", func() {
+					info, err := str.Stat(nil, key)
+					Expect(err).To(MatchError("context is missing"))
+					Expect(info).To(BeNil())
+				})
+
+				It("returns an error if the key is missing", func() {
+					info, err := str.Stat(ctx, "")
+					Expect(err).To(MatchError("key is missing"))
+					Expect(info).To(BeNil())
+				})
+
+				It("returns an error if the key is invalid", func() {
+					info, err := str.Stat(ctx, "#invalid#")
+					Expect(err).To(MatchError("key is invalid"))
+					Expect(info).To(BeNil())
+				})
+
+				It("returns an error if the key is a directory", func() {
+					Expect(os.MkdirAll(keyPath, 0777)).To(Succeed())
+					info, err := str.Stat(ctx, key)
+					Expect(err).To(MatchError(fmt.Sprintf("unexpected directory or irregular file at path %q", keyPath)))
+					Expect(info).To(BeNil())
+				})
+
+				It("returns an error if the metadata is invalid", func() {
+					Expect(os.MkdirAll(filepath.Dir(keyPath), 0777)).To(Succeed())
+					Expect(ioutil.WriteFile(keyPath, contents, 0666)).To(Succeed())
+					Expect(os.MkdirAll(filepath.Dir(metadataPath), 0777)).To(Succeed())
+					Expect(ioutil.WriteFile(metadataPath, []byte("{"), 0666)).To(Succeed())
+					info, err := str.Stat(ctx, key)
+					Expect(err).To(MatchError(fmt.Sprintf("unable to decode file at path %q; unexpected end of JSON input", metadataPath)))
+					Expect(info).To(BeNil())
+				})
+
+				It("returns nil if the key does not exist", func() {
+					Expect(str.Stat(ctx, key)).To(BeNil())
+				})
+
+				It("returns the info without media type or metadata if the key exists without options", func() {
+					Expect(os.MkdirAll(filepath.Dir(keyPath), 0777)).To(Succeed())
+					Expect(ioutil.WriteFile(keyPath, contents, 0666)).To(Succeed())
+					info, err := str.Stat(ctx, key)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(info).ToNot(BeNil())
+					Expect(info.Size).To(Equal(int64(len(contents))))
+					Expect(info.ModifiedTime).To(BeTemporally("~", time.Now(), time.Minute))
+					Expect(info.ModifiedTime.Location()).To(Equal(time.UTC))
+					Expect(info.MediaType).To(BeNil())
+					Expect(info.Metadata).To(BeNil())
+				})
+
+				It("returns the info with media type and metadata if the key exists with options", func() {
+					options := storeUnstructured.NewOptions()
+					options.MediaType = pointer.FromString(netTest.RandomMediaType())
+					options.Metadata = map[string]string{"a": test.RandomString(), "b": test.RandomString()}
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), options)).To(Succeed())
+					info, err := str.Stat(ctx, key)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(info).ToNot(BeNil())
+					Expect(info.Size).To(Equal(int64(len(contents))))
+					Expect(info.MediaType).To(Equal(options.MediaType))
+					Expect(info.Metadata).To(Equal(options.Metadata))
+				})
+			})
+
+			Context("Copy", func() {
+				var destinationKey string
+				var destinationKeyPath string
+
+				BeforeEach(func() {
+					destinationKey = storeUnstructuredTest.RandomKey()
+					destinationKeyPath = filepath.Join(directory, filepath.FromSlash(destinationKey))
+				})
+
+				It("returns an error if the context is missing", func() {
+					exists, err := str.Copy(nil, key, destinationKey)
+					Expect(err).To(MatchError("context is missing"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns an error if the source key is missing", func() {
+					exists, err := str.Copy(ctx, "", destinationKey)
+					Expect(err).To(MatchError("source key is missing"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns an error if the source key is invalid", func() {
+					exists, err := str.Copy(ctx, "#invalid#", destinationKey)
+					Expect(err).To(MatchError("source key is invalid"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns an error if the destination key is missing", func() {
+					exists, err := str.Copy(ctx, key, "")
+					Expect(err).To(MatchError("destination key is missing"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns an error if the destination key is invalid", func() {
+					exists, err := str.Copy(ctx, key, "#invalid#")
+					Expect(err).To(MatchError("destination key is invalid"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns an error if the destination key is the same as the source key", func() {
+					exists, err := str.Copy(ctx, key, key)
+					Expect(err).To(MatchError("destination key is invalid"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns false if the source key does not exist", func() {
+					Expect(str.Copy(ctx, key, destinationKey)).To(BeFalse())
+					Expect(destinationKeyPath).ToNot(BeAnExistingFile())
+				})
+
+				It("returns an error if it is unable to create the destination file", func() {
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					Expect(os.MkdirAll(destinationKeyPath, 0777)).To(Succeed())
+					exists, err := str.Copy(ctx, key, destinationKey)
+					Expect(err).To(MatchError(fmt.Sprintf("unable to create file at path %q; open %s: is a directory", destinationKeyPath, destinationKeyPath)))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns true and copies the contents and metadata if the source key exists", func() {
+					options := storeUnstructured.NewOptions()
+					options.MediaType = pointer.FromString(netTest.RandomMediaType())
+					options.Metadata = map[string]string{"a": test.RandomString()}
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), options)).To(Succeed())
+					Expect(str.Copy(ctx, key, destinationKey)).To(BeTrue())
+					Expect(ioutil.ReadFile(keyPath)).To(Equal(contents))
+					Expect(ioutil.ReadFile(destinationKeyPath)).To(Equal(contents))
+					info, err := str.Stat(ctx, destinationKey)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(info).ToNot(BeNil())
+					Expect(info.MediaType).To(Equal(options.MediaType))
+					Expect(info.Metadata).To(Equal(options.Metadata))
+				})
+
+				It("returns true and removes existing destination metadata if the source key exists without metadata", func() {
+					options := storeUnstructured.NewOptions()
+					options.MediaType = pointer.FromString(netTest.RandomMediaType())
+					Expect(str.Put(ctx, destinationKey, bytes.NewReader(contents), options)).To(Succeed())
+					Expect(str.Put(ctx, key, bytes.NewReader(contents), nil)).To(Succeed())
+					Expect(str.Copy(ctx, key, destinationKey)).To(BeTrue())
+					Expect(str.Stat(ctx, destinationKey)).To(PointTo(MatchFields(IgnoreExtras, Fields{"MediaType": BeNil()})))
+				})
+			})
+
+			Context("List", func() {
+				var pagination *page.Pagination
+
+				BeforeEach(func() {
+					pagination = nil
+					for _, k := range []string{"a/b/c", "a/b/d", "a/bc", "a.b", "b/a", "ab/c"} {
+						Expect(str.Put(ctx, k, bytes.NewReader(contents), storeUnstructured.NewOptions())).To(Succeed())
+					}
+					options := storeUnstructured.NewOptions()
+					options.MediaType = pointer.FromString(netTest.RandomMediaType())
+					Expect(str.Put(ctx, "a/b/e", bytes.NewReader(contents), options)).To(Succeed())
+				})
+
+				It("returns an error if the context is missing", func() {
+					keys, err := str.List(nil, "", pagination)
+					Expect(err).To(MatchError("context is missing"))
+					Expect(keys).To(BeNil())
+				})
+
+				It("returns an error if the prefix is invalid", func() {
+					keys, err := str.List(ctx, "/a", pagination)
+					Expect(err).To(MatchError("prefix is invalid"))
+					Expect(keys).To(BeNil())
+				})
+
+				It("returns an error if the pagination is invalid", func() {
+					pagination = page.NewPagination()
+					pagination.Page = -1
+					keys, err := str.List(ctx, "", pagination)
+					Expect(err).To(MatchError("pagination is invalid; value -1 is not greater than or equal to 0"))
+					Expect(keys).To(BeNil())
+				})
+
+				It("returns an empty list if the directory does not exist", func() {
+					Expect(os.RemoveAll(directory)).To(Succeed())
+					Expect(str.List(ctx, "", pagination)).To(BeEmpty())
+				})
+
+				It("returns an empty list if no keys match the prefix", func() {
+					Expect(str.List(ctx, "c", pagination)).To(BeEmpty())
+				})
+
+				DescribeTable("returns the sorted keys matching the prefix",
+					func(prefix string, expectedKeys []string) {
+						Expect(str.List(ctx, prefix, pagination)).To(Equal(expectedKeys))
+					},
+					Entry("without prefix", "", []string{"a.b", "a/b/c", "a/b/d", "a/b/e", "a/bc", "ab/c", "b/a"}),
+					Entry("with partial segment prefix", "a", []string{"a.b", "a/b/c", "a/b/d", "a/b/e", "a/bc", "ab/c"}),
+					Entry("with segment prefix", "a/", []string{"a/b/c", "a/b/d", "a/b/e", "a/bc"}),
+					Entry("with nested partial segment prefix", "a/b", []string{"a/b/c", "a/b/d", "a/b/e", "a/bc"}),
+					Entry("with nested segment prefix", "a/b/", []string{"a/b/c", "a/b/d", "a/b/e"}),
+					Entry("with full key prefix", "a/b/c", []string{"a/b/c"}),
+				)
+
+				It("returns the keys for the specified page", func() {
+					pagination = page.NewPagination()
+					pagination.Page = 1
+					pagination.Size = 3
+					Expect(str.List(ctx, "", pagination)).To(Equal([]string{"a/b/e", "a/bc", "ab/c"}))
+				})
+
+				It("returns the keys for the partial last page", func() {
+					pagination = page.NewPagination()
+					pagination.Page = 2
+					pagination.Size = 3
+					Expect(str.List(ctx, "", pagination)).To(Equal([]string{"b/a"}))
+				})
+
+				It("returns an empty list for a page past the last page", func() {
+					pagination = page.NewPagination()
+					pagination.Page = 3
+					pagination.Size = 3
+					Expect(str.List(ctx, "", pagination)).To(BeEmpty())
+				})
+			})
+
 			Context("Delete", func() {
 				It("returns an error if the context is missing", func() {
 					deleted, err := str.Delete(nil, key)
@@ -267,6 +534,19 @@ var _ = Describe("File", func() {
 						Expect(keyPath).ToNot(BeAnExistingFile())
 					})
 
+					It("returns true if the key exists and it deletes the file and metadata and any empty metadata directories", func() {
+						nestedKey := path.Join(key, storeUnstructuredTest.RandomKeySegment())
+						nestedMetadataPath := filepath.Join(directory, ".metadata", filepath.FromSlash(nestedKey)+".json")
+						options := storeUnstructured.NewOptions()
+						options.MediaType = pointer.FromString(netTest.RandomMediaType())
+						Expect(str.Delete(ctx, key)).To(BeTrue())
+						Expect(str.Put(ctx, nestedKey, bytes.NewReader(contents), options)).To(Succeed())
+						Expect(nestedMetadataPath).To(BeARegularFile())
+						Expect(str.Delete(ctx, nestedKey)).To(BeTrue())
+						Expect(nestedMetadataPath).ToNot(BeAnExistingFile())
+						Expect(filepath.Dir(nestedMetadataPath)).ToNot(BeADirectory())
+					})
+
 					Context("with at least one directory", func() {
 						BeforeEach(func() {
 							key = path.Join(key, storeUnstructuredTest.RandomKeySegment())
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -14,7 +16,9 @@ import (
 	"github.com/tidepool-org/platform/aws"
 	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/page"
 	storeUnstructured "github.com/tidepool-org/platform/store/unstructured"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
 )
 
 const Type = "s3"
@@ -73,7 +77,7 @@ func (s *Store) Exists(ctx context.Context, key string) (bool, error) {
 	return exists, nil
 }
 
-func (s *Store) Put(ctx context.Context, key string, reader io.Reader) error {
+func (s *Store) Put(ctx context.Context, key string, reader io.Reader, options *storeUnstructured.Options) error {
 	if ctx == nil {
 		return errors.New("context is missing")
 	}
@@ -85,6 +89,11 @@ func (s *Store) Put(ctx context.Context, key string, reader io.Reader) error {
 	if reader == nil {
 		return errors.New("reader is missing")
 	}
+	if options == nil {
+		options = storeUnstructured.NewOptions()
+	} else if err := structureValidator.New().Validate(options); err != nil {
+		return errors.Wrap(err, "options is invalid")
+	}
 
 	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"bucket": s.bucket, "prefix": s.prefix, "key": key})
 	key = s.resolveKey(key)
@@ -93,8 +102,12 @@ func (s *Store) Put(ctx context.Context, key string, reader io.Reader) error {
 		Body:                 reader,
 		Bucket:               aws.String(s.bucket),
 		Key:                  aws.String(key),
+		ContentType:          options.MediaType,
 		ServerSideEncryption: aws.String("AES256"),
 	}
+	if len(options.Metadata) > 0 {
+		input.Metadata = aws.StringMap(options.Metadata)
+	}
 	if _, err := s.awsAPI.S3ManagerUploader().UploadWithContext(ctx, input); err != nil {
 		logger.WithError(err).Errorf("Unable to upload object with key %q", key)
 		return errors.Wrapf(err, "unable to upload object with key %q", key)
@@ -134,7 +147,135 @@ func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
 
 	logger.WithField("exists", reader != nil).Debug("Get")
 	return reader, nil
+}
+
+func (s *Store) Stat(ctx context.Context, key string) (*storeUnstructured.Info, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if key == "" {
+		return nil, errors.New("key is missing")
+	} else if !storeUnstructured.IsValidKey(key) {
+		return nil, errors.New("key is invalid")
+	}
+
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"bucket": s.bucket, "prefix": s.prefix, "key": key})
+	key = s.resolveKey(key)
+
+	var info *storeUnstructured.Info
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+	if output, err := s.awsAPI.S3().HeadObjectWithContext(ctx, input); err != nil {
+		if awsErr, ok := err.(awserr.Error); !ok || awsErr.Code() != "NotFound" {
+			logger.WithError(err).Errorf("Unable to head object with key %q", key)
+			return nil, errors.Wrapf(err, "unable to head object with key %q", key)
+		}
+	} else {
+		info = &storeUnstructured.Info{
+			Size:         aws.Int64Value(output.ContentLength),
+			ModifiedTime: aws.TimeValue(output.LastModified),
+			MediaType:    output.ContentType,
+		}
+		if len(output.Metadata) > 0 {
+			info.Metadata = map[string]string{}
+			for metadataKey, metadataValue := range aws.StringValueMap(output.Metadata) {
+				info.Metadata[strings.ToLower(metadataKey)] = metadataValue // AWS SDK canonicalizes metadata keys as HTTP headers
+			}
+		}
+	}
 
+	logger.WithField("exists", info != nil).Debug("Stat")
+	return info, nil
+}
+
+func (s *Store) Copy(ctx context.Context, sourceKey string, destinationKey string) (bool, error) {
+	if ctx == nil {
+		return false, errors.New("context is missing")
+	}
+	if sourceKey == "" {
+		return false, errors.New("source key is missing")
+	} else if !storeUnstructured.IsValidKey(sourceKey) {
+		return false, errors.New("source key is invalid")
+	}
+	if destinationKey == "" {
+		return false, errors.New("destination key is missing")
+	} else if !storeUnstructured.IsValidKey(destinationKey) || destinationKey == sourceKey {
+		return false, errors.New("destination key is invalid")
+	}
+
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"bucket": s.bucket, "prefix": s.prefix, "sourceKey": sourceKey, "destinationKey": destinationKey})
+	sourceKey = s.resolveKey(sourceKey)
+	destinationKey = s.resolveKey(destinationKey)
+
+	var exists bool
+	input := &s3.CopyObjectInput{
+		Bucket:               aws.String(s.bucket),
+		CopySource:           aws.String(fmt.Sprintf("%s/%s", s.bucket, sourceKey)), // Valid keys do not require encoding
+		Key:                  aws.String(destinationKey),
+		MetadataDirective:    aws.String(s3.MetadataDirectiveCopy),
+		ServerSideEncryption: aws.String("AES256"),
+	}
+	if _, err := s.awsAPI.S3().CopyObjectWithContext(ctx, input); err != nil { // FUTURE: Use multipart copy for objects larger than 5 GB
+		if awsErr, ok := err.(awserr.Error); !ok || awsErr.Code() != s3.ErrCodeNoSuchKey {
+			logger.WithError(err).Errorf("Unable to copy object with key %q to key %q", sourceKey, destinationKey)
+			return false, errors.Wrapf(err, "unable to copy object with key %q to key %q", sourceKey, destinationKey)
+		}
+	} else {
+		exists = true
+	}
+
+	logger.WithField("exists", exists).Debug("Copy")
+	return exists, nil
+}
+
+func (s *Store) List(ctx context.Context, prefix string, pagination *page.Pagination) ([]string, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if !storeUnstructured.IsValidKeyPrefix(prefix) {
+		return nil, errors.New("prefix is invalid")
+	}
+	if pagination == nil {
+		pagination = page.NewPagination()
+	} else if err := structureValidator.New().Validate(pagination); err != nil {
+		return nil, errors.Wrap(err, "pagination is invalid")
+	}
+
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"bucket": s.bucket, "prefix": s.prefix, "keyPrefix": prefix, "pagination": pagination})
+	resolvedPrefix := s.resolveKey(prefix)
+
+	// S3 only supports continuation tokens, so skip over any keys on preceding pages
+	skip := pagination.Page * pagination.Size
+	keys := []string{}
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(resolvedPrefix),
+	}
+	for {
+		output, err := s.awsAPI.S3().ListObjectsV2WithContext(ctx, input)
+		if err != nil {
+			logger.WithError(err).Errorf("Unable to list objects with prefix %q", resolvedPrefix)
+			return nil, errors.Wrapf(err, "unable to list objects with prefix %q", resolvedPrefix)
+		}
+
+		for _, object := range output.Contents {
+			if skip > 0 {
+				skip--
+			} else if len(keys) < pagination.Size {
+				keys = append(keys, strings.TrimPrefix(aws.StringValue(object.Key), s.resolveKey("")))
+			}
+		}
+
+		if len(keys) >= pagination.Size || !aws.BoolValue(output.IsTruncated) {
+			break
+		}
+		input.ContinuationToken = output.NextContinuationToken
+	}
+
+	logger.WithField("count", len(keys)).Debug("List")
+	return keys, nil
 }
 
 func (s *Store) Delete(ctx context.Context, key string) (bool, error) {
@@ -176,6 +317,38 @@ func (s *Store) Delete(ctx context.Context, key string) (bool, error) {
 	return exists, nil
 }
 
+func (s *Store) PresignGet(ctx context.Context, key string, expirationTime time.Time) (string, error) {
+	if ctx == nil {
+		return "", errors.New("context is missing")
+	}
+	if key == "" {
+		return "", errors.New("key is missing")
+	} else if !storeUnstructured.IsValidKey(key) {
+		return "", errors.New("key is invalid")
+	}
+	expirationDuration := time.Until(expirationTime)
+	if expirationDuration <= 0 {
+		return "", errors.New("expiration time is invalid")
+	}
+
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"bucket": s.bucket, "prefix": s.prefix, "key": key})
+	key = s.resolveKey(key)
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+	req, _ := s.awsAPI.S3().GetObjectRequest(input)
+	url, err := req.Presign(expirationDuration)
+	if err != nil {
+		logger.WithError(err).Errorf("Unable to presign get object with key %q", key)
+		return "", errors.Wrapf(err, "unable to presign get object with key %q", key)
+	}
+
+	logger.Debug("PresignGet")
+	return url, nil
+}
+
 func (s *Store) resolveKey(key string) string {
 	return fmt.Sprintf("%s/%s", s.prefix, key)
 }
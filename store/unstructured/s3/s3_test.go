@@ -9,19 +9,27 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
 
 	awsTest "github.com/tidepool-org/platform/aws/test"
+	"github.com/tidepool-org/platform/errors"
 	errorsTest "github.com/tidepool-org/platform/errors/test"
 	"github.com/tidepool-org/platform/log"
 	logNull "github.com/tidepool-org/platform/log/null"
+	netTest "github.com/tidepool-org/platform/net/test"
+	"github.com/tidepool-org/platform/page"
 	"github.com/tidepool-org/platform/pointer"
+	storeUnstructured "github.com/tidepool-org/platform/store/unstructured"
 	storeUnstructuredS3 "github.com/tidepool-org/platform/store/unstructured/s3"
 	storeUnstructuredTest "github.com/tidepool-org/platform/store/unstructured/test"
 	"github.com/tidepool-org/platform/test"
@@ -164,44 +172,54 @@ var _ = Describe("S3", func() {
 
 			Context("Put", func() {
 				var reader io.Reader
+				var options *storeUnstructured.Options
 
 				BeforeEach(func() {
 					reader = bytes.NewReader(contents)
+					options = nil
 				})
 
 				It("returns an error if the context is missing", func() {
-					Expect(str.Put(nil, key, reader)).To(MatchError("context is missing"))
+					Expect(str.Put(nil, key, reader, options)).To(MatchError("context is missing"))
 				})
 
 				It("returns an error if the key is missing", func() {
-					Expect(str.Put(ctx, "", reader)).To(MatchError("key is missing"))
+					Expect(str.Put(ctx, "", reader, options)).To(MatchError("key is missing"))
 				})
 
 				It("returns an error if the key is invalid", func() {
-					Expect(str.Put(ctx, "#invalid#", reader)).To(MatchError("key is invalid"))
+					Expect(str.Put(ctx, "#invalid#", reader, options)).To(MatchError("key is invalid"))
 				})
 
 				It("returns an error if the reader is missing", func() {
-					Expect(str.Put(ctx, key, nil)).To(MatchError("reader is missing"))
+					Expect(str.Put(ctx, key, nil, options)).To(MatchError("reader is missing"))
+				})
+
+				It("returns an error if the options is invalid", func() {
+					options = storeUnstructured.NewOptions()
+					options.Metadata = map[string]string{"#invalid#": test.RandomString()}
+					Expect(str.Put(ctx, key, reader, options)).To(MatchError(`options is invalid; value "#invalid#" is not valid as unstructured metadata key`))
 				})
 
 				Context("with aws s3 manager upload", func() {
 					var awsS3Manager *awsTest.S3Manager
+					var expectedInput *s3manager.UploadInput
 
 					BeforeEach(func() {
 						awsS3Manager = awsTest.NewS3Manager()
 						awsAPI.S3ManagerUploaderOutputs = []s3manageriface.UploaderAPI{awsS3Manager}
+						expectedInput = &s3manager.UploadInput{
+							Body:                 reader,
+							Bucket:               pointer.FromString(cfg.Bucket),
+							Key:                  pointer.FromString(keyPath),
+							ServerSideEncryption: pointer.FromString("AES256"),
+						}
 					})
 
 					AfterEach(func() {
 						Expect(awsS3Manager.UploadWithContextInputs).To(HaveLen(1))
 						Expect(awsS3Manager.UploadWithContextInputs[0].Context).To(Equal(ctx))
-						Expect(awsS3Manager.UploadWithContextInputs[0].Input).To(Equal(&s3manager.UploadInput{
-							Body:                 reader,
-							Bucket:               pointer.FromString(cfg.Bucket),
-							Key:                  pointer.FromString(keyPath),
-							ServerSideEncryption: pointer.FromString("AES256"),
-						}))
+						Expect(awsS3Manager.UploadWithContextInputs[0].Input).To(Equal(expectedInput))
 						Expect(awsS3Manager.UploadWithContextInputs[0].Options).To(BeEmpty())
 						awsS3Manager.AssertOutputsEmpty()
 					})
@@ -209,12 +227,22 @@ var _ = Describe("S3", func() {
 					It("returns an error if aws returns an error", func() {
 						awsErr := errorsTest.NewError()
 						awsS3Manager.UploadWithContextOutputs = []awsTest.UploadWithContextOutput{{Output: nil, Error: awsErr}}
-						Expect(str.Put(ctx, key, reader)).To(MatchError(fmt.Sprintf("unable to upload object with key %q; %s", keyPath, awsErr)))
+						Expect(str.Put(ctx, key, reader, options)).To(MatchError(fmt.Sprintf("unable to upload object with key %q; %s", keyPath, awsErr)))
 					})
 
 					It("returns successfully", func() {
 						awsS3Manager.UploadWithContextOutputs = []awsTest.UploadWithContextOutput{{Output: nil, Error: nil}}
-						Expect(str.Put(ctx, key, reader)).To(Succeed())
+						Expect(str.Put(ctx, key, reader, options)).To(Succeed())
+					})
+
+					It("returns successfully with media type and metadata", func() {
+						options = storeUnstructured.NewOptions()
+						options.MediaType = pointer.FromString(netTest.RandomMediaType())
+						options.Metadata = map[string]string{"a": test.RandomString()}
+						expectedInput.ContentType = options.MediaType
+						expectedInput.Metadata = map[string]*string{"a": pointer.FromString(options.Metadata["a"])}
+						awsS3Manager.UploadWithContextOutputs = []awsTest.UploadWithContextOutput{{Output: nil, Error: nil}}
+						Expect(str.Put(ctx, key, reader, options)).To(Succeed())
 					})
 				})
 			})
@@ -314,6 +342,265 @@ var _ = Describe("S3", func() {
 				})
 			})
 
+			Context("Stat", func() {
+				It("returns an error if the context is missing", func() {
+					info, err := str.Stat(nil, key)
+					Expect(err).To(MatchError("context is missing"))
+					Expect(info).To(BeNil())
+				})
+
+				It("returns an error if the key is missing", func() {
+					info, err := str.Stat(ctx, "")
+					Expect(err).To(MatchError("key is missing"))
+					Expect(info).To(BeNil())
+				})
+
+				It("returns an error if the key is invalid", func() {
+					info, err := str.Stat(ctx, "#invalid#")
+					Expect(err).To(MatchError("key is invalid"))
+					Expect(info).To(BeNil())
+				})
+
+				Context("with aws s3 head object", func() {
+					var awsS3 *awsTest.S3
+
+					BeforeEach(func() {
+						awsS3 = awsTest.NewS3()
+						awsAPI.S3Outputs = []s3iface.S3API{awsS3}
+					})
+
+					AfterEach(func() {
+						Expect(awsS3.HeadObjectWithContextInputs).To(HaveLen(1))
+						Expect(awsS3.HeadObjectWithContextInputs[0].Context).To(Equal(ctx))
+						Expect(awsS3.HeadObjectWithContextInputs[0].Input).To(Equal(&s3.HeadObjectInput{
+							Bucket: pointer.FromString(cfg.Bucket),
+							Key:    pointer.FromString(keyPath),
+						}))
+						awsS3.AssertOutputsEmpty()
+					})
+
+					It("returns an error if aws returns an error", func() {
+						awsErr := awserr.New(test.RandomString(), "", nil)
+						awsS3.HeadObjectWithContextOutputs = []awsTest.HeadObjectWithContextOutput{{Output: nil, Error: awsErr}}
+						info, err := str.Stat(ctx, key)
+						Expect(err).To(MatchError(fmt.Sprintf("unable to head object with key %q; %s", keyPath, awsErr)))
+						Expect(info).To(BeNil())
+					})
+
+					It("returns nil if the key does not exist", func() {
+						awsS3.HeadObjectWithContextOutputs = []awsTest.HeadObjectWithContextOutput{{Output: nil, Error: awserr.New("NotFound", "", nil)}}
+						Expect(str.Stat(ctx, key)).To(BeNil())
+					})
+
+					It("returns the info if the key exists", func() {
+						size := int64(test.RandomIntFromRange(0, 1024))
+						modifiedTime := test.RandomTimeFromRange(test.RandomTimeMinimum(), time.Now()).Truncate(time.Second)
+						mediaType := netTest.RandomMediaType()
+						value := test.RandomString()
+						awsS3.HeadObjectWithContextOutputs = []awsTest.HeadObjectWithContextOutput{{Output: &s3.HeadObjectOutput{
+							ContentLength: &size,
+							LastModified:  &modifiedTime,
+							ContentType:   pointer.FromString(mediaType),
+							Metadata:      map[string]*string{"A-B": pointer.FromString(value)},
+						}, Error: nil}}
+						Expect(str.Stat(ctx, key)).To(Equal(&storeUnstructured.Info{
+							Size:         size,
+							ModifiedTime: modifiedTime,
+							MediaType:    pointer.FromString(mediaType),
+							Metadata:     map[string]string{"a-b": value},
+						}))
+					})
+
+					It("returns the info without metadata if the key exists without metadata", func() {
+						awsS3.HeadObjectWithContextOutputs = []awsTest.HeadObjectWithContextOutput{{Output: &s3.HeadObjectOutput{}, Error: nil}}
+						Expect(str.Stat(ctx, key)).To(Equal(&storeUnstructured.Info{}))
+					})
+				})
+			})
+
+			Context("Copy", func() {
+				var destinationKey string
+				var destinationKeyPath string
+
+				BeforeEach(func() {
+					destinationKey = storeUnstructuredTest.RandomKey()
+					destinationKeyPath = fmt.Sprintf("%s/%s", cfg.Prefix, destinationKey)
+				})
+
+				It("returns an error if the context is missing", func() {
+					exists, err := str.Copy(nil, key, destinationKey)
+					Expect(err).To(MatchError("context is missing"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns an error if the source key is missing", func() {
+					exists, err := str.Copy(ctx, "", destinationKey)
+					Expect(err).To(MatchError("source key is missing"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns an error if the source key is invalid", func() {
+					exists, err := str.Copy(ctx, "#invalid#", destinationKey)
+					Expect(err).To(MatchError("source key is invalid"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns an error if the destination key is missing", func() {
+					exists, err := str.Copy(ctx, key, "")
+					Expect(err).To(MatchError("destination key is missing"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns an error if the destination key is invalid", func() {
+					exists, err := str.Copy(ctx, key, "#invalid#")
+					Expect(err).To(MatchError("destination key is invalid"))
+					Expect(exists).To(BeFalse())
+				})
+
+				It("returns an error if the destination key is the same as the source key", func() {
+					exists, err := str.Copy(ctx, key, key)
+					Expect(err).To(MatchError("destination key is invalid"))
+					Expect(exists).To(BeFalse())
+				})
+
+				Context("with aws s3 copy object", func() {
+					var awsS3 *awsTest.S3
+
+					BeforeEach(func() {
+						awsS3 = awsTest.NewS3()
+						awsAPI.S3Outputs = []s3iface.S3API{awsS3}
+					})
+
+					AfterEach(func() {
+						Expect(awsS3.CopyObjectWithContextInputs).To(HaveLen(1))
+						Expect(awsS3.CopyObjectWithContextInputs[0].Context).To(Equal(ctx))
+						Expect(awsS3.CopyObjectWithContextInputs[0].Input).To(Equal(&s3.CopyObjectInput{
+							Bucket:               pointer.FromString(cfg.Bucket),
+							CopySource:           pointer.FromString(fmt.Sprintf("%s/%s", cfg.Bucket, keyPath)),
+							Key:                  pointer.FromString(destinationKeyPath),
+							MetadataDirective:    pointer.FromString("COPY"),
+							ServerSideEncryption: pointer.FromString("AES256"),
+						}))
+						awsS3.AssertOutputsEmpty()
+					})
+
+					It("returns an error if aws returns an error", func() {
+						awsErr := awserr.New(test.RandomString(), "", nil)
+						awsS3.CopyObjectWithContextOutputs = []awsTest.CopyObjectWithContextOutput{{Output: nil, Error: awsErr}}
+						exists, err := str.Copy(ctx, key, destinationKey)
+						Expect(err).To(MatchError(fmt.Sprintf("unable to copy object with key %q to key %q; %s", keyPath, destinationKeyPath, awsErr)))
+						Expect(exists).To(BeFalse())
+					})
+
+					It("returns false if the source key does not exist", func() {
+						awsS3.CopyObjectWithContextOutputs = []awsTest.CopyObjectWithContextOutput{{Output: nil, Error: awserr.New("NoSuchKey", "", nil)}}
+						Expect(str.Copy(ctx, key, destinationKey)).To(BeFalse())
+					})
+
+					It("returns true if the source key exists", func() {
+						awsS3.CopyObjectWithContextOutputs = []awsTest.CopyObjectWithContextOutput{{Output: &s3.CopyObjectOutput{}, Error: nil}}
+						Expect(str.Copy(ctx, key, destinationKey)).To(BeTrue())
+					})
+				})
+			})
+
+			Context("List", func() {
+				var prefix string
+				var prefixPath string
+				var pagination *page.Pagination
+
+				BeforeEach(func() {
+					prefix = storeUnstructuredTest.RandomKeySegment() + "/"
+					prefixPath = fmt.Sprintf("%s/%s", cfg.Prefix, prefix)
+					pagination = page.NewPagination()
+					pagination.Size = 2
+				})
+
+				It("returns an error if the context is missing", func() {
+					keys, err := str.List(nil, prefix, pagination)
+					Expect(err).To(MatchError("context is missing"))
+					Expect(keys).To(BeNil())
+				})
+
+				It("returns an error if the prefix is invalid", func() {
+					keys, err := str.List(ctx, "#invalid#", pagination)
+					Expect(err).To(MatchError("prefix is invalid"))
+					Expect(keys).To(BeNil())
+				})
+
+				It("returns an error if the pagination is invalid", func() {
+					pagination.Size = 0
+					keys, err := str.List(ctx, prefix, pagination)
+					Expect(err).To(MatchError("pagination is invalid; value 0 is not between 1 and 100"))
+					Expect(keys).To(BeNil())
+				})
+
+				Context("with aws s3 list objects", func() {
+					var awsS3 *awsTest.S3
+					var continuationToken string
+
+					object := func(key string) *s3.Object {
+						return &s3.Object{Key: pointer.FromString(prefixPath + key)}
+					}
+
+					BeforeEach(func() {
+						awsS3 = awsTest.NewS3()
+						awsAPI.S3Output = awsS3
+						continuationToken = test.RandomString()
+					})
+
+					AfterEach(func() {
+						Expect(awsS3.ListObjectsV2WithContextInputs).ToNot(BeEmpty())
+						for _, input := range awsS3.ListObjectsV2WithContextInputs {
+							Expect(input.Context).To(Equal(ctx))
+							Expect(input.Input.Bucket).To(Equal(pointer.FromString(cfg.Bucket)))
+							Expect(input.Input.Prefix).To(Equal(pointer.FromString(prefixPath)))
+						}
+						awsS3.AssertOutputsEmpty()
+					})
+
+					It("returns an error if aws returns an error", func() {
+						awsErr := errorsTest.NewError()
+						awsS3.ListObjectsV2WithContextOutputs = []awsTest.ListObjectsV2WithContextOutput{{Output: nil, Error: awsErr}}
+						keys, err := str.List(ctx, prefix, pagination)
+						Expect(err).To(MatchError(fmt.Sprintf("unable to list objects with prefix %q; %s", prefixPath, awsErr)))
+						Expect(keys).To(BeNil())
+					})
+
+					It("returns an empty list if there are no objects", func() {
+						awsS3.ListObjectsV2WithContextOutputs = []awsTest.ListObjectsV2WithContextOutput{{Output: &s3.ListObjectsV2Output{}, Error: nil}}
+						Expect(str.List(ctx, prefix, pagination)).To(BeEmpty())
+					})
+
+					It("returns the keys for the first page without requesting more objects", func() {
+						awsS3.ListObjectsV2WithContextOutputs = []awsTest.ListObjectsV2WithContextOutput{
+							{Output: &s3.ListObjectsV2Output{Contents: []*s3.Object{object("a"), object("b"), object("c")}, IsTruncated: pointer.FromBool(true), NextContinuationToken: &continuationToken}, Error: nil},
+						}
+						Expect(str.List(ctx, prefix, pagination)).To(Equal([]string{prefix + "a", prefix + "b"}))
+						Expect(awsS3.ListObjectsV2WithContextInputs[0].Input.ContinuationToken).To(BeNil())
+					})
+
+					It("returns the keys for a later page spanning multiple responses", func() {
+						pagination.Page = 1
+						awsS3.ListObjectsV2WithContextOutputs = []awsTest.ListObjectsV2WithContextOutput{
+							{Output: &s3.ListObjectsV2Output{Contents: []*s3.Object{object("a"), object("b"), object("c")}, IsTruncated: pointer.FromBool(true), NextContinuationToken: &continuationToken}, Error: nil},
+							{Output: &s3.ListObjectsV2Output{Contents: []*s3.Object{object("d"), object("e")}, IsTruncated: pointer.FromBool(false)}, Error: nil},
+						}
+						Expect(str.List(ctx, prefix, pagination)).To(Equal([]string{prefix + "c", prefix + "d"}))
+						Expect(awsS3.ListObjectsV2WithContextInputs).To(HaveLen(2))
+						Expect(awsS3.ListObjectsV2WithContextInputs[1].Input.ContinuationToken).To(Equal(&continuationToken))
+					})
+
+					It("returns an empty list for a page past the last page", func() {
+						pagination.Page = 2
+						awsS3.ListObjectsV2WithContextOutputs = []awsTest.ListObjectsV2WithContextOutput{
+							{Output: &s3.ListObjectsV2Output{Contents: []*s3.Object{object("a"), object("b"), object("c")}, IsTruncated: pointer.FromBool(false)}, Error: nil},
+						}
+						Expect(str.List(ctx, prefix, pagination)).To(BeEmpty())
+					})
+				})
+			})
+
 			Context("Delete", func() {
 				It("returns an error if the context is missing", func() {
 					deleted, err := str.Delete(nil, key)
@@ -407,6 +694,74 @@ var _ = Describe("S3", func() {
 					})
 				})
 			})
+
+			Context("PresignGet", func() {
+				var expirationTime time.Time
+
+				BeforeEach(func() {
+					expirationTime = time.Now().Add(time.Hour)
+				})
+
+				It("returns an error if the context is missing", func() {
+					url, err := str.PresignGet(nil, key, expirationTime)
+					Expect(err).To(MatchError("context is missing"))
+					Expect(url).To(BeEmpty())
+				})
+
+				It("returns an error if the key is missing", func() {
+					url, err := str.PresignGet(ctx, "", expirationTime)
+					Expect(err).To(MatchError("key is missing"))
+					Expect(url).To(BeEmpty())
+				})
+
+				It("returns an error if the key is invalid", func() {
+					url, err := str.PresignGet(ctx, "#invalid#", expirationTime)
+					Expect(err).To(MatchError("key is invalid"))
+					Expect(url).To(BeEmpty())
+				})
+
+				It("returns an error if the expiration time is not in the future", func() {
+					url, err := str.PresignGet(ctx, key, time.Now().Add(-time.Second))
+					Expect(err).To(MatchError("expiration time is invalid"))
+					Expect(url).To(BeEmpty())
+				})
+
+				Context("with aws s3 get object request", func() {
+					var awsS3 *awsTest.S3
+					var req *request.Request
+
+					BeforeEach(func() {
+						awsS3 = awsTest.NewS3()
+						awsAPI.S3Outputs = []s3iface.S3API{awsS3}
+						awsSession := session.Must(session.NewSession(&aws.Config{
+							Region:      aws.String("us-west-2"),
+							Credentials: credentials.NewStaticCredentials(test.RandomStringFromRangeAndCharset(20, 20, test.CharsetUppercase), test.RandomStringFromRangeAndCharset(40, 40, test.CharsetAlphaNumeric), ""),
+						}))
+						req, _ = s3.New(awsSession).GetObjectRequest(&s3.GetObjectInput{Bucket: aws.String(cfg.Bucket), Key: aws.String(keyPath)})
+						awsS3.GetObjectRequestOutputs = []awsTest.GetObjectRequestOutput{{Request: req, Output: nil}}
+					})
+
+					AfterEach(func() {
+						Expect(awsS3.GetObjectRequestInputs).To(Equal([]*s3.GetObjectInput{{Bucket: aws.String(cfg.Bucket), Key: aws.String(keyPath)}}))
+						awsS3.AssertOutputsEmpty()
+					})
+
+					It("returns an error if the presign returns an error", func() {
+						req.Error = errorsTest.NewError()
+						url, err := str.PresignGet(ctx, key, expirationTime)
+						errorsTest.ExpectEqual(err, errors.Wrapf(req.Error, "unable to presign get object with key %q", keyPath))
+						Expect(url).To(BeEmpty())
+					})
+
+					It("returns successfully", func() {
+						url, err := str.PresignGet(ctx, key, expirationTime)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(url).To(ContainSubstring(keyPath))
+						Expect(url).To(ContainSubstring("X-Amz-Signature="))
+						Expect(url).To(ContainSubstring("X-Amz-Expires="))
+					})
+				})
+			})
 		})
 	})
 })
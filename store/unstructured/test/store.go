@@ -3,6 +3,10 @@ package test
 import (
 	"context"
 	"io"
+	"time"
+
+	"github.com/tidepool-org/platform/page"
+	storeUnstructured "github.com/tidepool-org/platform/store/unstructured"
 )
 
 type ExistsInput struct {
@@ -19,6 +23,7 @@ type PutInput struct {
 	Context context.Context
 	Key     string
 	Reader  io.Reader
+	Options *storeUnstructured.Options
 }
 
 type GetInput struct {
@@ -31,6 +36,38 @@ type GetOutput struct {
 	Error  error
 }
 
+type StatInput struct {
+	Context context.Context
+	Key     string
+}
+
+type StatOutput struct {
+	Info  *storeUnstructured.Info
+	Error error
+}
+
+type CopyInput struct {
+	Context        context.Context
+	SourceKey      string
+	DestinationKey string
+}
+
+type CopyOutput struct {
+	Exists bool
+	Error  error
+}
+
+type ListInput struct {
+	Context    context.Context
+	Prefix     string
+	Pagination *page.Pagination
+}
+
+type ListOutput struct {
+	Keys  []string
+	Error error
+}
+
 type DeleteInput struct {
 	Context context.Context
 	Key     string
@@ -49,7 +86,7 @@ type Store struct {
 	ExistsOutput      *ExistsOutput
 	PutInvocations    int
 	PutInputs         []PutInput
-	PutStub           func(ctx context.Context, key string, reader io.Reader) error
+	PutStub           func(ctx context.Context, key string, reader io.Reader, options *storeUnstructured.Options) error
 	PutOutputs        []error
 	PutOutput         *error
 	GetInvocations    int
@@ -57,6 +94,21 @@ type Store struct {
 	GetStub           func(ctx context.Context, key string) (io.ReadCloser, error)
 	GetOutputs        []GetOutput
 	GetOutput         *GetOutput
+	StatInvocations   int
+	StatInputs        []StatInput
+	StatStub          func(ctx context.Context, key string) (*storeUnstructured.Info, error)
+	StatOutputs       []StatOutput
+	StatOutput        *StatOutput
+	CopyInvocations   int
+	CopyInputs        []CopyInput
+	CopyStub          func(ctx context.Context, sourceKey string, destinationKey string) (bool, error)
+	CopyOutputs       []CopyOutput
+	CopyOutput        *CopyOutput
+	ListInvocations   int
+	ListInputs        []ListInput
+	ListStub          func(ctx context.Context, prefix string, pagination *page.Pagination) ([]string, error)
+	ListOutputs       []ListOutput
+	ListOutput        *ListOutput
 	DeleteInvocations int
 	DeleteInputs      []DeleteInput
 	DeleteStub        func(ctx context.Context, key string) (bool, error)
@@ -85,11 +137,11 @@ func (s *Store) Exists(ctx context.Context, key string) (bool, error) {
 	panic("Exists has no output")
 }
 
-func (s *Store) Put(ctx context.Context, key string, reader io.Reader) error {
+func (s *Store) Put(ctx context.Context, key string, reader io.Reader, options *storeUnstructured.Options) error {
 	s.PutInvocations++
-	s.PutInputs = append(s.PutInputs, PutInput{Context: ctx, Key: key, Reader: reader})
+	s.PutInputs = append(s.PutInputs, PutInput{Context: ctx, Key: key, Reader: reader, Options: options})
 	if s.PutStub != nil {
-		return s.PutStub(ctx, key, reader)
+		return s.PutStub(ctx, key, reader, options)
 	}
 	if len(s.PutOutputs) > 0 {
 		output := s.PutOutputs[0]
@@ -119,6 +171,57 @@ func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
 	panic("Get has no output")
 }
 
+func (s *Store) Stat(ctx context.Context, key string) (*storeUnstructured.Info, error) {
+	s.StatInvocations++
+	s.StatInputs = append(s.StatInputs, StatInput{Context: ctx, Key: key})
+	if s.StatStub != nil {
+		return s.StatStub(ctx, key)
+	}
+	if len(s.StatOutputs) > 0 {
+		output := s.StatOutputs[0]
+		s.StatOutputs = s.StatOutputs[1:]
+		return output.Info, output.Error
+	}
+	if s.StatOutput != nil {
+		return s.StatOutput.Info, s.StatOutput.Error
+	}
+	panic("Stat has no output")
+}
+
+func (s *Store) Copy(ctx context.Context, sourceKey string, destinationKey string) (bool, error) {
+	s.CopyInvocations++
+	s.CopyInputs = append(s.CopyInputs, CopyInput{Context: ctx, SourceKey: sourceKey, DestinationKey: destinationKey})
+	if s.CopyStub != nil {
+		return s.CopyStub(ctx, sourceKey, destinationKey)
+	}
+	if len(s.CopyOutputs) > 0 {
+		output := s.CopyOutputs[0]
+		s.CopyOutputs = s.CopyOutputs[1:]
+		return output.Exists, output.Error
+	}
+	if s.CopyOutput != nil {
+		return s.CopyOutput.Exists, s.CopyOutput.Error
+	}
+	panic("Copy has no output")
+}
+
+func (s *Store) List(ctx context.Context, prefix string, pagination *page.Pagination) ([]string, error) {
+	s.ListInvocations++
+	s.ListInputs = append(s.ListInputs, ListInput{Context: ctx, Prefix: prefix, Pagination: pagination})
+	if s.ListStub != nil {
+		return s.ListStub(ctx, prefix, pagination)
+	}
+	if len(s.ListOutputs) > 0 {
+		output := s.ListOutputs[0]
+		s.ListOutputs = s.ListOutputs[1:]
+		return output.Keys, output.Error
+	}
+	if s.ListOutput != nil {
+		return s.ListOutput.Keys, s.ListOutput.Error
+	}
+	panic("List has no output")
+}
+
 func (s *Store) Delete(ctx context.Context, key string) (bool, error) {
 	s.DeleteInvocations++
 	s.DeleteInputs = append(s.DeleteInputs, DeleteInput{Context: ctx, Key: key})
@@ -146,7 +249,66 @@ func (s *Store) AssertOutputsEmpty() {
 	if len(s.GetOutputs) > 0 {
 		panic("GetOutputs is not empty")
 	}
+	if len(s.StatOutputs) > 0 {
+		panic("StatOutputs is not empty")
+	}
+	if len(s.CopyOutputs) > 0 {
+		panic("CopyOutputs is not empty")
+	}
+	if len(s.ListOutputs) > 0 {
+		panic("ListOutputs is not empty")
+	}
 	if len(s.DeleteOutputs) > 0 {
 		panic("DeleteOutputs is not empty")
 	}
 }
+
+type PresignGetInput struct {
+	Context        context.Context
+	Key            string
+	ExpirationTime time.Time
+}
+
+type PresignGetOutput struct {
+	URL   string
+	Error error
+}
+
+type PresignerStore struct {
+	*Store
+	PresignGetInvocations int
+	PresignGetInputs      []PresignGetInput
+	PresignGetStub        func(ctx context.Context, key string, expirationTime time.Time) (string, error)
+	PresignGetOutputs     []PresignGetOutput
+	PresignGetOutput      *PresignGetOutput
+}
+
+func NewPresignerStore() *PresignerStore {
+	return &PresignerStore{
+		Store: NewStore(),
+	}
+}
+
+func (p *PresignerStore) PresignGet(ctx context.Context, key string, expirationTime time.Time) (string, error) {
+	p.PresignGetInvocations++
+	p.PresignGetInputs = append(p.PresignGetInputs, PresignGetInput{Context: ctx, Key: key, ExpirationTime: expirationTime})
+	if p.PresignGetStub != nil {
+		return p.PresignGetStub(ctx, key, expirationTime)
+	}
+	if len(p.PresignGetOutputs) > 0 {
+		output := p.PresignGetOutputs[0]
+		p.PresignGetOutputs = p.PresignGetOutputs[1:]
+		return output.URL, output.Error
+	}
+	if p.PresignGetOutput != nil {
+		return p.PresignGetOutput.URL, p.PresignGetOutput.Error
+	}
+	panic("PresignGet has no output")
+}
+
+func (p *PresignerStore) AssertOutputsEmpty() {
+	p.Store.AssertOutputsEmpty()
+	if len(p.PresignGetOutputs) > 0 {
+		panic("PresignGetOutputs is not empty")
+	}
+}
@@ -4,19 +4,60 @@ import (
 	"context"
 	"io"
 	"regexp"
+	"time"
 
 	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/net"
+	"github.com/tidepool-org/platform/page"
 	"github.com/tidepool-org/platform/structure"
 	structureValidator "github.com/tidepool-org/platform/structure/validator"
 )
 
 type Store interface {
 	Exists(ctx context.Context, key string) (bool, error)
-	Put(ctx context.Context, key string, reader io.Reader) error
+	Put(ctx context.Context, key string, reader io.Reader, options *Options) error
 	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Stat(ctx context.Context, key string) (*Info, error)
+	Copy(ctx context.Context, sourceKey string, destinationKey string) (bool, error)
+	List(ctx context.Context, prefix string, pagination *page.Pagination) ([]string, error)
 	Delete(ctx context.Context, key string) (bool, error)
 }
 
+type Presigner interface {
+	PresignGet(ctx context.Context, key string, expirationTime time.Time) (string, error)
+}
+
+const MetadataValueLengthMaximum = 1024
+
+type Options struct {
+	MediaType *string
+	Metadata  map[string]string
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func (o *Options) Validate(validator structure.Validator) {
+	validator.String("mediaType", o.MediaType).Using(net.MediaTypeValidator)
+
+	metadataValidator := validator.WithReference("metadata")
+	for key, value := range o.Metadata {
+		value := value
+		metadataValidator.WithReference(key).ReportError(ValidateMetadataKey(key))
+		metadataValidator.String(key, &value).LengthLessThanOrEqualTo(MetadataValueLengthMaximum).Matches(metadataValueExpression)
+	}
+}
+
+// Info describes a stored object. The media type and metadata are those specified when the object
+// was put, although a store may report a default media type if none was specified.
+type Info struct {
+	Size         int64
+	ModifiedTime time.Time
+	MediaType    *string
+	Metadata     map[string]string
+}
+
 func IsValidKey(value string) bool {
 	return ValidateKey(value) == nil
 }
@@ -36,10 +77,60 @@ func ValidateKey(value string) error {
 	return nil
 }
 
+// IsValidKeyPrefix reports whether the value is a valid prefix of at least one valid key
+func IsValidKeyPrefix(value string) bool {
+	return ValidateKeyPrefix(value) == nil
+}
+
+func KeyPrefixValidator(value string, errorReporter structure.ErrorReporter) {
+	errorReporter.ReportError(ValidateKeyPrefix(value))
+}
+
+func ValidateKeyPrefix(value string) error {
+	if value == "" {
+		return nil
+	} else if !keyExpression.MatchString(value + "0") {
+		return ErrorValueStringAsKeyPrefixNotValid(value)
+	} else if length := len(value); length >= keyLengthMaximum {
+		return structureValidator.ErrorLengthNotLessThan(length, keyLengthMaximum)
+	}
+	return nil
+}
+
+func IsValidMetadataKey(value string) bool {
+	return ValidateMetadataKey(value) == nil
+}
+
+func MetadataKeyValidator(value string, errorReporter structure.ErrorReporter) {
+	errorReporter.ReportError(ValidateMetadataKey(value))
+}
+
+func ValidateMetadataKey(value string) error {
+	if value == "" {
+		return structureValidator.ErrorValueEmpty()
+	} else if !metadataKeyExpression.MatchString(value) {
+		return ErrorValueStringAsMetadataKeyNotValid(value)
+	} else if length := len(value); length > metadataKeyLengthMaximum {
+		return structureValidator.ErrorLengthNotLessThanOrEqualTo(length, metadataKeyLengthMaximum)
+	}
+	return nil
+}
+
 func ErrorValueStringAsKeyNotValid(value string) error {
 	return errors.Preparedf(structureValidator.ErrorCodeValueNotValid, "value is not valid", "value %q is not valid as unstructured key", value)
 }
 
+func ErrorValueStringAsKeyPrefixNotValid(value string) error {
+	return errors.Preparedf(structureValidator.ErrorCodeValueNotValid, "value is not valid", "value %q is not valid as unstructured key prefix", value)
+}
+
+func ErrorValueStringAsMetadataKeyNotValid(value string) error {
+	return errors.Preparedf(structureValidator.ErrorCodeValueNotValid, "value is not valid", "value %q is not valid as unstructured metadata key", value)
+}
+
 const keyLengthMaximum = 2047
+const metadataKeyLengthMaximum = 64
 
 var keyExpression = regexp.MustCompile("^[0-9A-Za-z][0-9A-Za-z._-]*(/[0-9A-Za-z][0-9A-Za-z._-]*)*$")
+var metadataKeyExpression = regexp.MustCompile("^[a-z][0-9a-z-]*$")
+var metadataValueExpression = regexp.MustCompile("^[\\x20-\\x7E]*$")
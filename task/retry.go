@@ -0,0 +1,62 @@
+package task
+
+import "time"
+
+// ErrorCountDataKey is the task data key of the number of consecutive errors of a retried task
+const ErrorCountDataKey = "errorCount"
+
+// Retry repeats a task after an error until the maximum attempts is reached. The retry duration doubles with each
+// consecutive error, from the initial duration up to the maximum duration.
+type Retry struct {
+	AttemptsMaximum int
+	DurationInitial time.Duration
+	DurationMaximum time.Duration
+}
+
+func (r *Retry) Duration(attempts int) time.Duration {
+	duration := r.DurationInitial
+	for attempt := 1; attempt < attempts && duration < r.DurationMaximum; attempt++ {
+		duration *= 2
+	}
+	if duration > r.DurationMaximum {
+		duration = r.DurationMaximum
+	}
+	return duration
+}
+
+// RepeatOnError appends the error to the task and records the error count, then either repeats the task after the
+// retry duration or, if the maximum attempts is reached, fails the task
+func (r *Retry) RepeatOnError(tsk *Task, err error) {
+	tsk.AppendError(err)
+
+	errorCount := tsk.ErrorCount() + 1
+	if tsk.Data == nil {
+		tsk.Data = map[string]interface{}{}
+	}
+	tsk.Data[ErrorCountDataKey] = errorCount
+
+	if errorCount >= r.AttemptsMaximum {
+		tsk.SetFailed()
+	} else {
+		tsk.RepeatAvailableAfter(r.Duration(errorCount))
+	}
+}
+
+// ErrorCount returns the error count recorded in the task data, which may have been decoded as any numeric type
+func (t *Task) ErrorCount() int {
+	switch value := t.Data[ErrorCountDataKey].(type) {
+	case int:
+		return value
+	case int32:
+		return int(value)
+	case int64:
+		return int(value)
+	case float64:
+		return int(value)
+	}
+	return 0
+}
+
+func (t *Task) ClearErrorCount() {
+	delete(t.Data, ErrorCountDataKey)
+}
@@ -0,0 +1,77 @@
+package task_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"time"
+
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/task"
+)
+
+var _ = Describe("Retry", func() {
+	var retry *task.Retry
+
+	BeforeEach(func() {
+		retry = &task.Retry{AttemptsMaximum: 3, DurationInitial: time.Minute, DurationMaximum: 3 * time.Minute}
+	})
+
+	DescribeTable("Duration",
+		func(attempts int, expectedDuration time.Duration) {
+			Expect(retry.Duration(attempts)).To(Equal(expectedDuration))
+		},
+		Entry("is the initial duration for no attempts", 0, time.Minute),
+		Entry("is the initial duration for the first attempt", 1, time.Minute),
+		Entry("doubles for the second attempt", 2, 2*time.Minute),
+		Entry("is limited to the maximum duration", 3, 3*time.Minute),
+		Entry("is limited to the maximum duration for many attempts", 100, 3*time.Minute),
+	)
+
+	Context("RepeatOnError", func() {
+		var tsk *task.Task
+
+		BeforeEach(func() {
+			tsk = &task.Task{State: task.TaskStateRunning, Data: map[string]interface{}{}}
+		})
+
+		It("appends the error, records the error count and repeats the task after the retry duration", func() {
+			retry.RepeatOnError(tsk, errorsTest.NewError())
+			Expect(tsk.HasError()).To(BeTrue())
+			Expect(tsk.ErrorCount()).To(Equal(1))
+			Expect(tsk.State).To(Equal(task.TaskStatePending))
+			Expect(*tsk.AvailableTime).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+		})
+
+		It("increments an error count decoded as a float", func() {
+			tsk.Data[task.ErrorCountDataKey] = float64(1)
+			retry.RepeatOnError(tsk, errorsTest.NewError())
+			Expect(tsk.Data).To(HaveKeyWithValue(task.ErrorCountDataKey, 2))
+			Expect(*tsk.AvailableTime).To(BeTemporally("~", time.Now().Add(2*time.Minute), time.Second))
+		})
+
+		It("fails the task once the maximum attempts is reached", func() {
+			tsk.Data[task.ErrorCountDataKey] = retry.AttemptsMaximum - 1
+			retry.RepeatOnError(tsk, errorsTest.NewError())
+			Expect(tsk.ErrorCount()).To(Equal(retry.AttemptsMaximum))
+			Expect(tsk.IsFailed()).To(BeTrue())
+			Expect(tsk.AvailableTime).To(BeNil())
+		})
+
+		It("records the error count if the task has no data", func() {
+			tsk.Data = nil
+			retry.RepeatOnError(tsk, errorsTest.NewError())
+			Expect(tsk.ErrorCount()).To(Equal(1))
+		})
+	})
+
+	Context("ClearErrorCount", func() {
+		It("removes the error count", func() {
+			tsk := &task.Task{Data: map[string]interface{}{task.ErrorCountDataKey: 2}}
+			tsk.ClearErrorCount()
+			Expect(tsk.ErrorCount()).To(Equal(0))
+			Expect(tsk.Data).ToNot(HaveKey(task.ErrorCountDataKey))
+		})
+	})
+})
@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"github.com/tidepool-org/platform/errors"
+	oauthFetch "github.com/tidepool-org/platform/oauth/fetch"
+	"github.com/tidepool-org/platform/request"
+)
+
+func (r *Router) ReimportDataSource(res rest.ResponseWriter, req *rest.Request) {
+	responder := request.MustNewResponder(res, req)
+
+	id := req.PathParam("id")
+	if id == "" {
+		responder.Error(http.StatusBadRequest, request.ErrorParameterMissing("id"))
+		return
+	}
+
+	reimport := oauthFetch.NewReimport()
+	if err := request.DecodeRequestQuery(req.Request, reimport); err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+
+	dataSource, err := r.DataClient().GetDataSource(req.Context(), id)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	} else if dataSource == nil {
+		responder.Error(http.StatusNotFound, request.ErrorResourceNotFoundWithID(id))
+		return
+	}
+
+	prvdr, err := r.ProviderFactory().Get(dataSource.ProviderType, dataSource.ProviderName)
+	if err != nil {
+		responder.Error(http.StatusBadRequest, err)
+		return
+	}
+	reimporter, ok := prvdr.(oauthFetch.Reimporter)
+	if !ok {
+		responder.Error(http.StatusBadRequest, errors.Newf("provider %q does not support reimport", dataSource.ProviderName))
+		return
+	}
+
+	result, err := reimporter.Reimport(req.Context(), dataSource, reimport)
+	if err != nil {
+		responder.Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	responder.Data(http.StatusOK, result)
+}
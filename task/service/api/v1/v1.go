@@ -34,6 +34,7 @@ func (r *Router) Routes() []*rest.Route {
 		rest.Get("/v1/tasks/:id", api.RequireServer(r.GetTask)),
 		rest.Put("/v1/tasks/:id", api.RequireServer(r.UpdateTask)),
 		rest.Delete("/v1/tasks/:id", api.RequireServer(r.DeleteTask)),
+		rest.Post("/v1/data_sources/:id/reimport", api.RequireServer(r.ReimportDataSource)),
 	}
 }
 
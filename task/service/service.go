@@ -1,6 +1,8 @@
 package service
 
 import (
+	dataClient "github.com/tidepool-org/platform/data/client"
+	"github.com/tidepool-org/platform/provider"
 	"github.com/tidepool-org/platform/service"
 	"github.com/tidepool-org/platform/task"
 	"github.com/tidepool-org/platform/task/store"
@@ -11,6 +13,8 @@ type Service interface {
 
 	TaskStore() store.Store
 	TaskClient() task.Client
+	DataClient() dataClient.Client
+	ProviderFactory() provider.Factory
 
 	Status() *Status
 }
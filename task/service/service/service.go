@@ -1,18 +1,44 @@
 package service
 
 import (
+	"context"
+
 	"github.com/ant0ine/go-json-rest/rest"
 
+	alertEvaluate "github.com/tidepool-org/platform/alert/evaluate"
+	alertNoData "github.com/tidepool-org/platform/alert/nodata"
+	alertUserData "github.com/tidepool-org/platform/alert/userdata"
 	"github.com/tidepool-org/platform/application"
-	"github.com/tidepool-org/platform/client"
+	authRefresh "github.com/tidepool-org/platform/auth/refresh"
+	"github.com/tidepool-org/platform/blob"
+	blobCleanup "github.com/tidepool-org/platform/blob/cleanup"
+	blobClient "github.com/tidepool-org/platform/blob/client"
+	confirmationMongo "github.com/tidepool-org/platform/confirmation/store/mongo"
 	dataClient "github.com/tidepool-org/platform/data/client"
+	dataNotifier "github.com/tidepool-org/platform/data/notifier"
 	"github.com/tidepool-org/platform/dexcom"
 	dexcomClient "github.com/tidepool-org/platform/dexcom/client"
 	dexcomFetch "github.com/tidepool-org/platform/dexcom/fetch"
 	dexcomProvider "github.com/tidepool-org/platform/dexcom/provider"
 	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	messageMongo "github.com/tidepool-org/platform/message/store/mongo"
+	"github.com/tidepool-org/platform/notification"
+	notificationClient "github.com/tidepool-org/platform/notification/client"
+	notificationDelivery "github.com/tidepool-org/platform/notification/delivery"
+	notificationDeliveryLocal "github.com/tidepool-org/platform/notification/delivery/local"
+	notificationDeliverySMTP "github.com/tidepool-org/platform/notification/delivery/smtp"
+	notificationDeliveryWebhook "github.com/tidepool-org/platform/notification/delivery/webhook"
+	notificationTemplate "github.com/tidepool-org/platform/notification/template"
+	"github.com/tidepool-org/platform/page"
+	permissionMongo "github.com/tidepool-org/platform/permission/store/mongo"
 	"github.com/tidepool-org/platform/platform"
+	"github.com/tidepool-org/platform/pointer"
+	profileMongo "github.com/tidepool-org/platform/profile/store/mongo"
+	"github.com/tidepool-org/platform/provider"
+	providerFactory "github.com/tidepool-org/platform/provider/factory"
 	serviceService "github.com/tidepool-org/platform/service/service"
+	sessionMongo "github.com/tidepool-org/platform/session/store/mongo"
 	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
 	"github.com/tidepool-org/platform/task"
 	"github.com/tidepool-org/platform/task/queue"
@@ -21,15 +47,30 @@ import (
 	"github.com/tidepool-org/platform/task/service/api/v1"
 	"github.com/tidepool-org/platform/task/store"
 	taskMongo "github.com/tidepool-org/platform/task/store/mongo"
+	"github.com/tidepool-org/platform/user"
+	userClient "github.com/tidepool-org/platform/user/client"
+	userDeletion "github.com/tidepool-org/platform/user/deletion"
+	userExport "github.com/tidepool-org/platform/user/export"
+	userMongo "github.com/tidepool-org/platform/user/store/mongo"
 )
 
 type Service struct {
 	*serviceService.Authenticated
-	taskStore    *taskMongo.Store
-	taskClient   *Client
-	dataClient   dataClient.Client
-	dexcomClient dexcom.Client
-	taskQueue    *queue.Queue
+	taskStore          *taskMongo.Store
+	confirmationStore  *confirmationMongo.Store
+	messageStore       *messageMongo.Store
+	permissionStore    *permissionMongo.Store
+	profileStore       *profileMongo.Store
+	sessionStore       *sessionMongo.Store
+	userStore          *userMongo.Store
+	taskClient         *Client
+	dataClient         dataClient.Client
+	blobClient         blob.Client
+	notificationClient notification.Client
+	userClient         user.Client
+	providerFactory    *providerFactory.Factory
+	dexcomClient       dexcom.Client
+	taskQueue          *queue.Queue
 }
 
 func New() *Service {
@@ -46,18 +87,42 @@ func (s *Service) Initialize(provider application.Provider) error {
 	if err := s.initializeTaskStore(); err != nil {
 		return err
 	}
+	if err := s.initializeUserStores(); err != nil {
+		return err
+	}
 	if err := s.initializeTaskClient(); err != nil {
 		return err
 	}
 	if err := s.initializeDataClient(); err != nil {
 		return err
 	}
+	if err := s.initializeBlobClient(); err != nil {
+		return err
+	}
+	if err := s.initializeNotificationClient(); err != nil {
+		return err
+	}
+	if err := s.initializeUserClient(); err != nil {
+		return err
+	}
+	if err := s.initializeProviderFactory(); err != nil {
+		return err
+	}
 	if err := s.initializeDexcomClient(); err != nil {
 		return err
 	}
 	if err := s.initializeTaskQueue(); err != nil {
 		return err
 	}
+	if err := s.initializeBlobCleanupTask(); err != nil {
+		return err
+	}
+	if err := s.initializeProviderSessionRefreshTask(); err != nil {
+		return err
+	}
+	if err := s.initializeAlertNoDataTask(); err != nil {
+		return err
+	}
 	return s.initializeRouter()
 }
 
@@ -65,8 +130,13 @@ func (s *Service) Terminate() {
 	s.terminateRouter()
 	s.terminateTaskQueue()
 	s.terminateDexcomClient()
+	s.terminateProviderFactory()
+	s.terminateUserClient()
+	s.terminateNotificationClient()
+	s.terminateBlobClient()
 	s.terminateDataClient()
 	s.terminateTaskClient()
+	s.terminateUserStores()
 	s.terminateTaskStore()
 
 	s.Authenticated.Terminate()
@@ -80,6 +150,14 @@ func (s *Service) TaskClient() task.Client {
 	return s.taskClient
 }
 
+func (s *Service) DataClient() dataClient.Client {
+	return s.dataClient
+}
+
+func (s *Service) ProviderFactory() provider.Factory {
+	return s.providerFactory
+}
+
 func (s *Service) Status() *service.Status {
 	return &service.Status{
 		Version:   s.VersionReporter().Long(),
@@ -117,6 +195,134 @@ func (s *Service) terminateTaskStore() {
 	}
 }
 
+// The user stores are used to export and delete users
+func (s *Service) initializeUserStores() error {
+	s.Logger().Debug("Loading confirmation store config")
+
+	confirmationStoreConfig := storeStructuredMongo.NewConfig()
+	if err := confirmationStoreConfig.Load(s.ConfigReporter().WithScopes("confirmation", "store")); err != nil {
+		return errors.Wrap(err, "unable to load confirmation store config")
+	}
+
+	s.Logger().Debug("Creating confirmation store")
+
+	confirmationStore, err := confirmationMongo.NewStore(confirmationStoreConfig, s.Logger())
+	if err != nil {
+		return errors.Wrap(err, "unable to create confirmation store")
+	}
+	s.confirmationStore = confirmationStore
+
+	s.Logger().Debug("Loading message store config")
+
+	messageStoreConfig := storeStructuredMongo.NewConfig()
+	if err = messageStoreConfig.Load(s.ConfigReporter().WithScopes("message", "store")); err != nil {
+		return errors.Wrap(err, "unable to load message store config")
+	}
+
+	s.Logger().Debug("Creating message store")
+
+	messageStore, err := messageMongo.NewStore(messageStoreConfig, s.Logger())
+	if err != nil {
+		return errors.Wrap(err, "unable to create message store")
+	}
+	s.messageStore = messageStore
+
+	s.Logger().Debug("Loading permission store config")
+
+	permissionStoreConfig := permissionMongo.NewConfig()
+	if err = permissionStoreConfig.Load(s.ConfigReporter().WithScopes("permission", "store")); err != nil {
+		return errors.Wrap(err, "unable to load permission store config")
+	}
+
+	s.Logger().Debug("Creating permission store")
+
+	permissionStore, err := permissionMongo.NewStore(permissionStoreConfig, s.Logger())
+	if err != nil {
+		return errors.Wrap(err, "unable to create permission store")
+	}
+	s.permissionStore = permissionStore
+
+	s.Logger().Debug("Loading profile store config")
+
+	profileStoreConfig := storeStructuredMongo.NewConfig()
+	if err = profileStoreConfig.Load(s.ConfigReporter().WithScopes("profile", "store")); err != nil {
+		return errors.Wrap(err, "unable to load profile store config")
+	}
+
+	s.Logger().Debug("Creating profile store")
+
+	profileStore, err := profileMongo.NewStore(profileStoreConfig, s.Logger())
+	if err != nil {
+		return errors.Wrap(err, "unable to create profile store")
+	}
+	s.profileStore = profileStore
+
+	s.Logger().Debug("Loading session store config")
+
+	sessionStoreConfig := storeStructuredMongo.NewConfig()
+	if err = sessionStoreConfig.Load(s.ConfigReporter().WithScopes("session", "store")); err != nil {
+		return errors.Wrap(err, "unable to load session store config")
+	}
+
+	s.Logger().Debug("Creating session store")
+
+	sessionStore, err := sessionMongo.NewStore(sessionStoreConfig, s.Logger())
+	if err != nil {
+		return errors.Wrap(err, "unable to create session store")
+	}
+	s.sessionStore = sessionStore
+
+	s.Logger().Debug("Loading user store config")
+
+	userStoreConfig := userMongo.NewConfig()
+	if err = userStoreConfig.Load(s.ConfigReporter().WithScopes("user", "store")); err != nil {
+		return errors.Wrap(err, "unable to load user store config")
+	}
+
+	s.Logger().Debug("Creating user store")
+
+	userStore, err := userMongo.NewStore(userStoreConfig, s.Logger())
+	if err != nil {
+		return errors.Wrap(err, "unable to create user store")
+	}
+	s.userStore = userStore
+
+	return nil
+}
+
+func (s *Service) terminateUserStores() {
+	if s.userStore != nil {
+		s.Logger().Debug("Closing user store")
+		s.userStore.Close()
+		s.userStore = nil
+	}
+	if s.sessionStore != nil {
+		s.Logger().Debug("Closing session store")
+		s.sessionStore.Close()
+		s.sessionStore = nil
+	}
+	if s.profileStore != nil {
+		s.Logger().Debug("Closing profile store")
+		s.profileStore.Close()
+		s.profileStore = nil
+	}
+	if s.permissionStore != nil {
+		s.Logger().Debug("Closing permission store")
+		s.permissionStore.Close()
+		s.permissionStore = nil
+	}
+	if s.messageStore != nil {
+		s.Logger().Debug("Closing message store")
+		s.messageStore.Close()
+		s.messageStore = nil
+	}
+	if s.confirmationStore != nil {
+		s.Logger().Debug("Closing confirmation store")
+		s.confirmationStore.Close()
+		s.confirmationStore = nil
+	}
+}
+
 func (s *Service) initializeTaskClient() error {
 	s.Logger().Debug("Creating task client")
 
@@ -163,6 +369,106 @@ func (s *Service) terminateDataClient() {
 	}
 }
 
+func (s *Service) initializeBlobClient() error {
+	s.Logger().Debug("Loading blob client config")
+
+	cfg := platform.NewConfig()
+	cfg.UserAgent = s.UserAgent()
+	if err := cfg.Load(s.ConfigReporter().WithScopes("blob", "client")); err != nil {
+		return errors.Wrap(err, "unable to load blob client config")
+	}
+
+	s.Logger().Debug("Creating blob client")
+
+	clnt, err := blobClient.New(cfg, platform.AuthorizeAsService)
+	if err != nil {
+		return errors.Wrap(err, "unable to create blob client")
+	}
+	s.blobClient = clnt
+
+	return nil
+}
+
+func (s *Service) terminateBlobClient() {
+	if s.blobClient != nil {
+		s.Logger().Debug("Destroying blob client")
+		s.blobClient = nil
+	}
+}
+
+func (s *Service) initializeNotificationClient() error {
+	s.Logger().Debug("Loading notification client config")
+
+	cfg := platform.NewConfig()
+	cfg.UserAgent = s.UserAgent()
+	if err := cfg.Load(s.ConfigReporter().WithScopes("notification", "client")); err != nil {
+		return errors.Wrap(err, "unable to load notification client config")
+	}
+
+	s.Logger().Debug("Creating notification client")
+
+	clnt, err := notificationClient.New(cfg, platform.AuthorizeAsService)
+	if err != nil {
+		return errors.Wrap(err, "unable to create notification client")
+	}
+	s.notificationClient = clnt
+
+	return nil
+}
+
+func (s *Service) terminateNotificationClient() {
+	if s.notificationClient != nil {
+		s.Logger().Debug("Destroying notification client")
+		s.notificationClient = nil
+	}
+}
+
+func (s *Service) initializeUserClient() error {
+	s.Logger().Debug("Loading user client config")
+
+	cfg := platform.NewConfig()
+	cfg.UserAgent = s.UserAgent()
+	if err := cfg.Load(s.ConfigReporter().WithScopes("user", "client")); err != nil {
+		return errors.Wrap(err, "unable to load user client config")
+	}
+
+	s.Logger().Debug("Creating user client")
+
+	clnt, err := userClient.New(cfg, platform.AuthorizeAsService)
+	if err != nil {
+		return errors.Wrap(err, "unable to create user client")
+	}
+	s.userClient = clnt
+
+	return nil
+}
+
+func (s *Service) terminateUserClient() {
+	if s.userClient != nil {
+		s.Logger().Debug("Destroying user client")
+		s.userClient = nil
+	}
+}
+
+func (s *Service) initializeProviderFactory() error {
+	s.Logger().Debug("Creating provider factory")
+
+	prvdrFctry, err := providerFactory.New()
+	if err != nil {
+		return errors.Wrap(err, "unable to create provider factory")
+	}
+	s.providerFactory = prvdrFctry
+
+	return nil
+}
+
+func (s *Service) terminateProviderFactory() {
+	if s.providerFactory != nil {
+		s.Logger().Debug("Destroying provider factory")
+		s.providerFactory = nil
+	}
+}
+
 func (s *Service) initializeDexcomClient() error {
 	s.Logger().Debug("Loading dexcom provider")
 
@@ -172,7 +478,7 @@ func (s *Service) initializeDexcomClient() error {
 	} else {
 		s.Logger().Debug("Loading dexcom client config")
 
-		cfg := client.NewConfig()
+		cfg := dexcomClient.NewConfig()
 		cfg.UserAgent = s.UserAgent()
 		if err = cfg.Load(s.ConfigReporter().WithScopes("dexcom", "client")); err != nil {
 			return errors.Wrap(err, "unable to load dexcom client config")
@@ -185,6 +491,10 @@ func (s *Service) initializeDexcomClient() error {
 			return errors.Wrap(clntErr, "unable to create dexcom client")
 		}
 		s.dexcomClient = clnt
+
+		if err = s.providerFactory.Add(dxcmPrvdr); err != nil {
+			return errors.Wrap(err, "unable to add dexcom provider")
+		}
 	}
 
 	return nil
@@ -215,9 +525,16 @@ func (s *Service) initializeTaskQueue() error {
 	s.taskQueue = taskQueue
 
 	if s.dexcomClient != nil {
+		s.Logger().Debug("Loading dexcom fetch config")
+
+		dexcomFetchCfg := dexcomFetch.NewConfig()
+		if err = dexcomFetchCfg.Load(s.ConfigReporter().WithScopes("dexcom", "fetch")); err != nil {
+			return errors.Wrap(err, "unable to load dexcom fetch config")
+		}
+
 		s.Logger().Debug("Creating dexcom fetch runner")
 
-		rnnr, rnnrErr := dexcomFetch.NewRunner(s.Logger(), s.VersionReporter(), s.AuthClient(), s.dataClient, s.dexcomClient)
+		rnnr, rnnrErr := dexcomFetch.NewRunner(dexcomFetchCfg, s.Logger(), s.AuthClient(), s.dataClient, s.dexcomClient)
 		if rnnrErr != nil {
 			return errors.Wrap(rnnrErr, "unable to create dexcom fetch runner")
 		}
@@ -225,6 +542,122 @@ func (s *Service) initializeTaskQueue() error {
 		taskQueue.RegisterRunner(rnnr)
 	}
 
+	s.Logger().Debug("Creating blob cleanup runner")
+
+	rnnr, err := blobCleanup.NewRunner(s.Logger(), s.AuthClient(), s.blobClient)
+	if err != nil {
+		return errors.Wrap(err, "unable to create blob cleanup runner")
+	}
+
+	taskQueue.RegisterRunner(rnnr)
+
+	s.Logger().Debug("Creating provider session refresh runner")
+
+	refreshRnnr, err := authRefresh.NewRunner(s.Logger(), s.AuthClient(), s.dataClient, s.providerFactory)
+	if err != nil {
+		return errors.Wrap(err, "unable to create provider session refresh runner")
+	}
+
+	taskQueue.RegisterRunner(refreshRnnr)
+
+	s.Logger().Debug("Loading data source notifier config")
+
+	notifierCfg := dataNotifier.NewConfig()
+	if err = notifierCfg.Load(s.ConfigReporter().WithScopes("data_source", "notifier")); err != nil {
+		return errors.Wrap(err, "unable to load data source notifier config")
+	}
+
+	s.Logger().Debug("Creating data source notifier runner")
+
+	notifierRnnr, err := dataNotifier.NewRunner(notifierCfg, s.Logger(), s.AuthClient(), s.dataClient, s.notificationClient)
+	if err != nil {
+		return errors.Wrap(err, "unable to create data source notifier runner")
+	}
+
+	taskQueue.RegisterRunner(notifierRnnr)
+
+	s.Logger().Debug("Creating notification delivery transports")
+
+	transports, err := s.newNotificationDeliveryTransports()
+	if err != nil {
+		return errors.Wrap(err, "unable to create notification delivery transports")
+	}
+
+	s.Logger().Debug("Creating notification template renderer")
+
+	renderer, err := notificationTemplate.NewDefaultRenderer()
+	if err != nil {
+		return errors.Wrap(err, "unable to create notification template renderer")
+	}
+
+	s.Logger().Debug("Creating notification delivery runner")
+
+	deliveryRnnr, err := notificationDelivery.NewRunner(s.Logger(), s.AuthClient(), s.notificationClient, renderer, transports)
+	if err != nil {
+		return errors.Wrap(err, "unable to create notification delivery runner")
+	}
+
+	taskQueue.RegisterRunner(deliveryRnnr)
+
+	s.Logger().Debug("Creating alert engine")
+
+	alertEngine, err := alertEvaluate.NewEngine(s.AuthClient(), s.notificationClient, s.userClient)
+	if err != nil {
+		return errors.Wrap(err, "unable to create alert engine")
+	}
+
+	s.Logger().Debug("Creating alert no data runner")
+
+	noDataRnnr, err := alertNoData.NewRunner(s.Logger(), alertEngine)
+	if err != nil {
+		return errors.Wrap(err, "unable to create alert no data runner")
+	}
+
+	taskQueue.RegisterRunner(noDataRnnr)
+
+	s.Logger().Debug("Creating alert user data runner")
+
+	userDataRnnr, err := alertUserData.NewRunner(s.Logger(), alertEngine)
+	if err != nil {
+		return errors.Wrap(err, "unable to create alert user data runner")
+	}
+
+	taskQueue.RegisterRunner(userDataRnnr)
+
+	s.Logger().Debug("Creating user export gatherer")
+
+	exportGatherer, err := userExport.NewStoreGatherer(s.AuthClient(), s.dataClient, s.blobClient, s.notificationClient,
+		s.confirmationStore, s.messageStore, s.permissionStore, s.profileStore, s.sessionStore, s.userStore)
+	if err != nil {
+		return errors.Wrap(err, "unable to create user export gatherer")
+	}
+
+	s.Logger().Debug("Creating user export runner")
+
+	exportRnnr, err := userExport.NewRunner(s.Logger(), s.AuthClient(), s.blobClient, s.notificationClient, exportGatherer)
+	if err != nil {
+		return errors.Wrap(err, "unable to create user export runner")
+	}
+
+	taskQueue.RegisterRunner(exportRnnr)
+
+	s.Logger().Debug("Creating user deletion deleter")
+
+	deletionDeleter, err := userDeletion.NewStoreDeleter(s.AuthClient(), s.blobClient, s.dataClient, s.notificationClient, s.taskClient, s.confirmationStore,
+		s.messageStore, s.permissionStore, s.profileStore, s.sessionStore, s.userStore)
+	if err != nil {
+		return errors.Wrap(err, "unable to create user deletion deleter")
+	}
+
+	s.Logger().Debug("Creating user deletion runner")
+
+	deletionRnnr, err := userDeletion.NewRunner(s.Logger(), s.AuthClient(), deletionDeleter)
+	if err != nil {
+		return errors.Wrap(err, "unable to create user deletion runner")
+	}
+
+	taskQueue.RegisterRunner(deletionRnnr)
+
 	s.Logger().Debug("Starting task queue")
 
 	s.taskQueue.Start()
@@ -232,6 +665,61 @@ func (s *Service) initializeTaskQueue() error {
 	return nil
 }
 
+// Each channel uses the transport specified by its config; channels without a transport are not delivered
+func (s *Service) newNotificationDeliveryTransports() ([]notificationDelivery.Transport, error) {
+	transports := []notificationDelivery.Transport{}
+	for _, channel := range notification.Channels() {
+		configReporter := s.ConfigReporter().WithScopes("notification", "delivery", channel)
+
+		var transport notificationDelivery.Transport
+		switch typ := configReporter.GetWithDefault("type", ""); typ {
+		case "":
+			s.Logger().WithField("channel", channel).Warn("Notification delivery transport not configured")
+			continue
+		case "local":
+			cfg := notificationDeliveryLocal.NewConfig()
+			if err := cfg.Load(configReporter.WithScopes("local")); err != nil {
+				return nil, errors.Wrapf(err, "unable to load local transport config for channel %q", channel)
+			}
+			localTransport, err := notificationDeliveryLocal.NewTransport(channel, cfg)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to create local transport for channel %q", channel)
+			}
+			transport = localTransport
+		case "smtp":
+			if channel != notification.ChannelEmail {
+				return nil, errors.Newf("smtp transport is invalid for channel %q", channel)
+			}
+			cfg := notificationDeliverySMTP.NewConfig()
+			if err := cfg.Load(configReporter.WithScopes("smtp")); err != nil {
+				return nil, errors.Wrapf(err, "unable to load smtp transport config for channel %q", channel)
+			}
+			smtpTransport, err := notificationDeliverySMTP.NewTransport(cfg)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to create smtp transport for channel %q", channel)
+			}
+			transport = smtpTransport
+		case "webhook":
+			cfg := notificationDeliveryWebhook.NewConfig()
+			cfg.UserAgent = s.UserAgent()
+			if err := cfg.Load(configReporter.WithScopes("webhook")); err != nil {
+				return nil, errors.Wrapf(err, "unable to load webhook transport config for channel %q", channel)
+			}
+			webhookTransport, err := notificationDeliveryWebhook.NewTransport(channel, cfg)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to create webhook transport for channel %q", channel)
+			}
+			transport = webhookTransport
+		default:
+			return nil, errors.Newf("transport %q is invalid for channel %q", typ, channel)
+		}
+
+		transports = append(transports, transport)
+	}
+
+	return transports, nil
+}
+
 func (s *Service) terminateTaskQueue() {
 	if s.taskQueue != nil {
 		s.Logger().Debug("Stopping task queue")
@@ -242,6 +730,69 @@ func (s *Service) terminateTaskQueue() {
 	}
 }
 
+func (s *Service) initializeBlobCleanupTask() error {
+	s.Logger().Debug("Ensuring blob cleanup task")
+
+	ctx := log.NewContextWithLogger(context.Background(), s.Logger())
+
+	filter := task.NewTaskFilter()
+	filter.Name = pointer.FromString(blobCleanup.TaskName())
+	tsks, err := s.TaskClient().ListTasks(ctx, filter, page.NewPagination())
+	if err != nil {
+		return errors.Wrap(err, "unable to list blob cleanup task")
+	} else if len(tsks) > 0 {
+		return nil
+	}
+
+	if _, err = s.TaskClient().CreateTask(ctx, blobCleanup.NewTaskCreate()); err != nil {
+		return errors.Wrap(err, "unable to create blob cleanup task")
+	}
+
+	return nil
+}
+
+func (s *Service) initializeProviderSessionRefreshTask() error {
+	s.Logger().Debug("Ensuring provider session refresh task")
+
+	ctx := log.NewContextWithLogger(context.Background(), s.Logger())
+
+	filter := task.NewTaskFilter()
+	filter.Name = pointer.FromString(authRefresh.TaskName())
+	tsks, err := s.TaskClient().ListTasks(ctx, filter, page.NewPagination())
+	if err != nil {
+		return errors.Wrap(err, "unable to list provider session refresh task")
+	} else if len(tsks) > 0 {
+		return nil
+	}
+
+	if _, err = s.TaskClient().CreateTask(ctx, authRefresh.NewTaskCreate()); err != nil {
+		return errors.Wrap(err, "unable to create provider session refresh task")
+	}
+
+	return nil
+}
+
+func (s *Service) initializeAlertNoDataTask() error {
+	s.Logger().Debug("Ensuring alert no data task")
+
+	ctx := log.NewContextWithLogger(context.Background(), s.Logger())
+
+	filter := task.NewTaskFilter()
+	filter.Name = pointer.FromString(alertNoData.TaskName())
+	tsks, err := s.TaskClient().ListTasks(ctx, filter, page.NewPagination())
+	if err != nil {
+		return errors.Wrap(err, "unable to list alert no data task")
+	} else if len(tsks) > 0 {
+		return nil
+	}
+
+	if _, err = s.TaskClient().CreateTask(ctx, alertNoData.NewTaskCreate()); err != nil {
+		return errors.Wrap(err, "unable to create alert no data task")
+	}
+
+	return nil
+}
+
 func (s *Service) initializeRouter() error {
 	routes := []*rest.Route{}
 
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/urfave/cli"
+
+	"github.com/tidepool-org/platform/application"
+	awsApi "github.com/tidepool-org/platform/aws/api"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/page"
+	storeUnstructuredFactory "github.com/tidepool-org/platform/store/unstructured/factory"
+	"github.com/tidepool-org/platform/tool"
+)
+
+const (
+	DryRunFlag = "dry-run"
+	PrefixFlag = "prefix"
+)
+
+func main() {
+	application.RunAndExit(NewTool())
+}
+
+type Rewrapper interface {
+	Rewrap(ctx context.Context, key string) (bool, error)
+}
+
+type Tool struct {
+	*tool.Tool
+	dryRun bool
+	prefix string
+}
+
+func NewTool() *Tool {
+	return &Tool{
+		Tool: tool.New(),
+	}
+}
+
+func (t *Tool) Initialize(provider application.Provider) error {
+	if err := t.Tool.Initialize(provider); err != nil {
+		return err
+	}
+
+	t.CLI().Usage = "Encrypt or rewrap all blobs with the current blob store encryption key"
+	t.CLI().Description = "Encrypt or rewrap all blobs with the current blob store encryption key." +
+		"\n   Blobs stored in plaintext are encrypted. Blobs encrypted with a previous key are rewrapped" +
+		"\n   with the current key, allowing keys to be rotated. The previous key must remain configured" +
+		"\n   until this tool completes." +
+		"\n\n   This tool is idempotent."
+	t.CLI().Flags = append(t.CLI().Flags,
+		cli.BoolFlag{
+			Name:  fmt.Sprintf("%s,%s", DryRunFlag, "n"),
+			Usage: "dry run only; do not rewrap",
+		},
+		cli.StringFlag{
+			Name:  PrefixFlag,
+			Usage: "only rewrap blobs with keys with the specified prefix",
+		},
+	)
+
+	t.CLI().Action = func(context *cli.Context) error {
+		if !t.ParseContext(context) {
+			return nil
+		}
+		return t.execute()
+	}
+
+	return nil
+}
+
+func (t *Tool) ParseContext(context *cli.Context) bool {
+	if parsed := t.Tool.ParseContext(context); !parsed {
+		return parsed
+	}
+
+	t.dryRun = context.Bool(DryRunFlag)
+	t.prefix = context.String(PrefixFlag)
+
+	return true
+}
+
+func (t *Tool) execute() error {
+	ctx := log.NewContextWithLogger(context.Background(), t.Logger())
+
+	t.Logger().Debug("Creating unstructured store")
+
+	awsSession, err := session.NewSession()
+	if err != nil {
+		return errors.Wrap(err, "unable to create aws session")
+	}
+	awsEhpi, err := awsApi.New(awsSession)
+	if err != nil {
+		return errors.Wrap(err, "unable to create aws api")
+	}
+	unstructuredStore, err := storeUnstructuredFactory.NewStore(t.ConfigReporter().WithScopes("blob", "service", "unstructured", "store"), awsEhpi)
+	if err != nil {
+		return errors.Wrap(err, "unable to create unstructured store")
+	}
+
+	rewrapper, ok := unstructuredStore.(Rewrapper)
+	if !ok {
+		return errors.New("unstructured store is not encrypted")
+	}
+
+	var count int
+
+	pagination := page.NewPagination()
+	pagination.Size = page.PaginationSizeMaximum
+	for ; ; pagination.Page++ {
+		keys, err := unstructuredStore.List(ctx, t.prefix, pagination)
+		if err != nil {
+			return errors.Wrap(err, "unable to list keys")
+		}
+
+		for _, key := range keys {
+			if t.dryRun {
+				count++
+				continue
+			}
+			if rewrapped, err := rewrapper.Rewrap(ctx, key); err != nil {
+				t.Logger().WithError(err).WithField("key", key).Error("Unable to rewrap blob")
+			} else if rewrapped {
+				count++
+			}
+		}
+
+		if len(keys) < pagination.Size {
+			break
+		}
+	}
+
+	t.Logger().Infof("Rewrapped %d blobs", count)
+
+	return nil
+}
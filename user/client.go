@@ -6,4 +6,5 @@ type Client interface {
 	EnsureAuthorizedService(ctx context.Context) error
 	EnsureAuthorizedUser(ctx context.Context, targetUserID string, permission string) (string, error)
 	GetUserPermissions(ctx context.Context, requestUserID string, targetUserID string) (Permissions, error)
+	GetUser(ctx context.Context, userID string) (*User, error)
 }
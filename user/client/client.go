@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"time"
 
 	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/log"
@@ -100,6 +101,8 @@ func (c *Client) GetUserPermissions(ctx context.Context, requestUserID string, t
 		return nil, err
 	}
 
+	permissions = permissions.Granted(time.Now())
+
 	// Fix missing view and upload permissions for an owner
 	if permission, ok := permissions[user.OwnerPermission]; ok {
 		if _, ok = permissions[user.UploadPermission]; !ok {
@@ -112,3 +115,26 @@ func (c *Client) GetUserPermissions(ctx context.Context, requestUserID string, t
 
 	return permissions, nil
 }
+
+// FUTURE: Move to user service
+
+func (c *Client) GetUser(ctx context.Context, userID string) (*user.User, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+
+	log.LoggerFromContext(ctx).WithField("userId", userID).Debug("Get user")
+
+	usr := &user.User{}
+	if err := c.client.RequestData(ctx, "GET", c.client.ConstructURL("auth", "user", userID), nil, nil, usr); err != nil {
+		if request.IsErrorResourceNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return usr, nil
+}
@@ -390,6 +390,18 @@ var _ = Describe("Client", func() {
 					})
 				})
 
+				Context("with a successful response with an expired upload permission and a restricted view permission", func() {
+					BeforeEach(func() {
+						requestHandlers = append(requestHandlers, RespondWith(http.StatusOK, `{"upload": {"expirationTime": "2018-01-01T00:00:00Z"}, "view": {"expirationTime": "2100-01-01T00:00:00Z", "dataTypes": ["cbg"]}}`, responseHeaders))
+					})
+
+					It("returns successfully without the expired permission", func() {
+						Expect(client.GetUserPermissions(ctx, requestUserID, targetUserID)).To(Equal(user.Permissions{
+							user.ViewPermission: user.Permission{"expirationTime": "2100-01-01T00:00:00Z", "dataTypes": []interface{}{"cbg"}},
+						}))
+					})
+				})
+
 				Context("with a successful response with owner permissions that already includes upload permissions", func() {
 					BeforeEach(func() {
 						requestHandlers = append(requestHandlers, RespondWith(http.StatusOK, `{"root": {"root-inner": "unused"}, "upload": {}}`, responseHeaders))
@@ -433,5 +445,85 @@ var _ = Describe("Client", func() {
 				})
 			})
 		})
+
+		Context("GetUser", func() {
+			var userID string
+
+			BeforeEach(func() {
+				userID = user.NewID()
+			})
+
+			Context("without server response", func() {
+				AfterEach(func() {
+					Expect(server.ReceivedRequests()).To(BeEmpty())
+				})
+
+				It("returns an error when the context is missing", func() {
+					ctx = nil
+					usr, err := client.GetUser(ctx, userID)
+					errorsTest.ExpectEqual(err, errors.New("context is missing"))
+					Expect(usr).To(BeNil())
+				})
+
+				It("returns an error when the user id is missing", func() {
+					userID = ""
+					usr, err := client.GetUser(ctx, userID)
+					errorsTest.ExpectEqual(err, errors.New("user id is missing"))
+					Expect(usr).To(BeNil())
+				})
+			})
+
+			Context("with server response", func() {
+				BeforeEach(func() {
+					requestHandlers = append(requestHandlers,
+						VerifyContentType(""),
+						VerifyHeaderKV("X-Tidepool-Session-Token", sessionToken),
+						VerifyBody(nil),
+						VerifyRequest("GET", "/auth/user/"+userID),
+					)
+				})
+
+				AfterEach(func() {
+					Expect(server.ReceivedRequests()).To(HaveLen(1))
+				})
+
+				Context("with an unauthenticated response", func() {
+					BeforeEach(func() {
+						requestHandlers = append(requestHandlers, RespondWith(http.StatusUnauthorized, nil, responseHeaders))
+					})
+
+					It("returns an error", func() {
+						usr, err := client.GetUser(ctx, userID)
+						errorsTest.ExpectEqual(err, request.ErrorUnauthenticated())
+						Expect(usr).To(BeNil())
+					})
+				})
+
+				Context("with a not found response", func() {
+					BeforeEach(func() {
+						requestHandlers = append(requestHandlers, RespondWith(http.StatusNotFound, nil, responseHeaders))
+					})
+
+					It("returns successfully with no user", func() {
+						Expect(client.GetUser(ctx, userID)).To(BeNil())
+					})
+				})
+
+				Context("with a successful response", func() {
+					BeforeEach(func() {
+						requestHandlers = append(requestHandlers, RespondWith(http.StatusOK, `{"userid": "`+userID+`", "username": "user@example.com", "emails": ["user@example.com"], "emailVerified": true}`, responseHeaders))
+					})
+
+					It("returns successfully with expected user", func() {
+						Expect(client.GetUser(ctx, userID)).To(Equal(&user.User{
+							ID:            userID,
+							Email:         "user@example.com",
+							Emails:        []string{"user@example.com"},
+							EmailVerified: true,
+						}))
+					})
+				})
+			})
+		})
 	})
 })
@@ -0,0 +1,422 @@
+package deletion
+
+import (
+	"context"
+
+	"github.com/tidepool-org/platform/alert"
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/blob"
+	confirmationStore "github.com/tidepool-org/platform/confirmation/store"
+	dataClient "github.com/tidepool-org/platform/data/client"
+	"github.com/tidepool-org/platform/errors"
+	messageStore "github.com/tidepool-org/platform/message/store"
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/page"
+	permissionStore "github.com/tidepool-org/platform/permission/store"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/profile"
+	profileStore "github.com/tidepool-org/platform/profile/store"
+	"github.com/tidepool-org/platform/request"
+	sessionStore "github.com/tidepool-org/platform/session/store"
+	"github.com/tidepool-org/platform/task"
+	"github.com/tidepool-org/platform/user"
+	userExport "github.com/tidepool-org/platform/user/export"
+	userStore "github.com/tidepool-org/platform/user/store"
+)
+
+const PageSize = page.PaginationSizeMaximum
+
+// Deleter deletes the information held about a user for a single step. Each step must be idempotent.
+type Deleter interface {
+	Delete(ctx context.Context, userID string, step string) error
+}
+
+// StoreDeleter deletes from every store holding user information
+type StoreDeleter struct {
+	authClient         auth.Client
+	blobClient         blob.Client
+	dataClient         dataClient.Client
+	notificationClient notification.Client
+	taskClient         task.Client
+	confirmationStore  confirmationStore.Store
+	messageStore       messageStore.Store
+	permissionStore    permissionStore.Store
+	profileStore       profileStore.Store
+	sessionStore       sessionStore.Store
+	userStore          userStore.Store
+}
+
+func NewStoreDeleter(authClient auth.Client, blobClient blob.Client, dataClient dataClient.Client, notificationClient notification.Client, taskClient task.Client, confirmationStore confirmationStore.Store,
+	messageStore messageStore.Store, permissionStore permissionStore.Store, profileStore profileStore.Store, sessionStore sessionStore.Store, userStore userStore.Store) (*StoreDeleter, error) {
+	if authClient == nil {
+		return nil, errors.New("auth client is missing")
+	}
+	if blobClient == nil {
+		return nil, errors.New("blob client is missing")
+	}
+	if dataClient == nil {
+		return nil, errors.New("data client is missing")
+	}
+	if notificationClient == nil {
+		return nil, errors.New("notification client is missing")
+	}
+	if taskClient == nil {
+		return nil, errors.New("task client is missing")
+	}
+	if confirmationStore == nil {
+		return nil, errors.New("confirmation store is missing")
+	}
+	if messageStore == nil {
+		return nil, errors.New("message store is missing")
+	}
+	if permissionStore == nil {
+		return nil, errors.New("permission store is missing")
+	}
+	if profileStore == nil {
+		return nil, errors.New("profile store is missing")
+	}
+	if sessionStore == nil {
+		return nil, errors.New("session store is missing")
+	}
+	if userStore == nil {
+		return nil, errors.New("user store is missing")
+	}
+
+	return &StoreDeleter{
+		authClient:         authClient,
+		blobClient:         blobClient,
+		dataClient:         dataClient,
+		notificationClient: notificationClient,
+		taskClient:         taskClient,
+		confirmationStore:  confirmationStore,
+		messageStore:       messageStore,
+		permissionStore:    permissionStore,
+		profileStore:       profileStore,
+		sessionStore:       sessionStore,
+		userStore:          userStore,
+	}, nil
+}
+
+func (s *StoreDeleter) Delete(ctx context.Context, userID string, step string) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if userID == "" {
+		return errors.New("user id is missing")
+	}
+
+	switch step {
+	case StepSessions:
+		return s.deleteSessions(ctx, userID)
+	case StepProviderSessions:
+		return s.deleteProviderSessions(ctx, userID)
+	case StepOAuthGrants:
+		return s.deleteOAuthGrants(ctx, userID)
+	case StepRestrictedTokens:
+		return s.deleteRestrictedTokens(ctx, userID)
+	case StepDataSources:
+		return s.deleteDataSources(ctx, userID)
+	case StepExportTasks:
+		return s.deleteExportTasks(ctx, userID)
+	case StepData:
+		return s.deleteData(ctx, userID)
+	case StepBlobs:
+		return s.deleteBlobs(ctx, userID)
+	case StepNotifications:
+		return s.deleteNotifications(ctx, userID)
+	case StepNotificationPreferences:
+		return s.deleteNotificationPreferences(ctx, userID)
+	case StepAlertRules:
+		return s.deleteAlertRules(ctx, userID)
+	case StepConfirmations:
+		return s.deleteConfirmations(ctx, userID)
+	case StepPermissions:
+		return s.deletePermissions(ctx, userID)
+	case StepMessages:
+		return s.deleteMessages(ctx, userID)
+	case StepProfile:
+		return s.deleteProfile(ctx, userID)
+	case StepUser:
+		return s.deleteUser(ctx, userID)
+	}
+	return errors.Newf("step %q is unknown", step)
+}
+
+func (s *StoreDeleter) deleteSessions(ctx context.Context, userID string) error {
+	ssn := s.sessionStore.NewSessionsSession()
+	defer ssn.Close()
+
+	return ssn.DestroySessionsForUserByID(ctx, userID)
+}
+
+// Provider sessions are deleted through the auth client so that each provider may clean up after itself
+func (s *StoreDeleter) deleteProviderSessions(ctx context.Context, userID string) error {
+	ids := []string{}
+
+	pagination := page.NewPagination()
+	pagination.Size = PageSize
+	for ; ; pagination.Page++ {
+		providerSessions, err := s.authClient.ListUserProviderSessions(ctx, userID, nil, pagination)
+		if err != nil {
+			return errors.Wrap(err, "unable to list user provider sessions")
+		}
+		for _, providerSession := range providerSessions {
+			ids = append(ids, providerSession.ID)
+		}
+		if len(providerSessions) < pagination.Size {
+			break
+		}
+	}
+
+	for _, id := range ids {
+		if err := s.authClient.DeleteProviderSession(ctx, id); err != nil {
+			return errors.Wrap(err, "unable to delete provider session")
+		}
+	}
+
+	return nil
+}
+
+// OAuth authorization codes and tokens granted by the user to any client
+func (s *StoreDeleter) deleteOAuthGrants(ctx context.Context, userID string) error {
+	return s.authClient.DeleteUserOAuthGrants(ctx, userID)
+}
+
+func (s *StoreDeleter) deleteRestrictedTokens(ctx context.Context, userID string) error {
+	ids := []string{}
+
+	pagination := page.NewPagination()
+	pagination.Size = PageSize
+	for ; ; pagination.Page++ {
+		restrictedTokens, err := s.authClient.ListUserRestrictedTokens(ctx, userID, nil, pagination)
+		if err != nil {
+			return errors.Wrap(err, "unable to list user restricted tokens")
+		}
+		for _, restrictedToken := range restrictedTokens {
+			ids = append(ids, restrictedToken.ID)
+		}
+		if len(restrictedTokens) < pagination.Size {
+			break
+		}
+	}
+
+	for _, id := range ids {
+		if err := s.authClient.DeleteRestrictedToken(ctx, id); err != nil && !request.IsErrorResourceNotFound(err) {
+			return errors.Wrap(err, "unable to delete restricted token")
+		}
+	}
+
+	return nil
+}
+
+func (s *StoreDeleter) deleteDataSources(ctx context.Context, userID string) error {
+	ids := []string{}
+
+	pagination := page.NewPagination()
+	pagination.Size = PageSize
+	for ; ; pagination.Page++ {
+		dataSources, err := s.dataClient.ListUserDataSources(ctx, userID, nil, pagination)
+		if err != nil {
+			return errors.Wrap(err, "unable to list user data sources")
+		}
+		for _, dataSource := range dataSources {
+			ids = append(ids, dataSource.ID)
+		}
+		if len(dataSources) < pagination.Size {
+			break
+		}
+	}
+
+	for _, id := range ids {
+		if err := s.dataClient.DeleteDataSource(ctx, id); err != nil {
+			return errors.Wrap(err, "unable to delete data source")
+		}
+	}
+
+	return nil
+}
+
+// Only a pending export is deleted, as a running export is owned by the task queue
+func (s *StoreDeleter) deleteExportTasks(ctx context.Context, userID string) error {
+	filter := task.NewTaskFilter()
+	filter.Name = pointer.FromString(userExport.TaskName(userID))
+	filter.State = pointer.FromString(task.TaskStatePending)
+
+	tsks, err := s.taskClient.ListTasks(ctx, filter, nil)
+	if err != nil {
+		return errors.Wrap(err, "unable to list tasks")
+	}
+
+	for _, tsk := range tsks {
+		if err = s.taskClient.DeleteTask(ctx, tsk.ID); err != nil {
+			return errors.Wrap(err, "unable to delete task")
+		}
+	}
+
+	return nil
+}
+
+func (s *StoreDeleter) deleteData(ctx context.Context, userID string) error {
+	return s.dataClient.DestroyDataForUserByID(ctx, userID)
+}
+
+func (s *StoreDeleter) deleteBlobs(ctx context.Context, userID string) error {
+	ids := []string{}
+
+	pagination := page.NewPagination()
+	pagination.Size = PageSize
+	for ; ; pagination.Page++ {
+		blbs, err := s.blobClient.List(ctx, userID, nil, pagination)
+		if err != nil {
+			return errors.Wrap(err, "unable to list blobs")
+		}
+		for _, blb := range blbs {
+			if blb.ID != nil {
+				ids = append(ids, *blb.ID)
+			}
+		}
+		if len(blbs) < pagination.Size {
+			break
+		}
+	}
+
+	for _, id := range ids {
+		if _, err := s.blobClient.Delete(ctx, id); err != nil {
+			return errors.Wrap(err, "unable to delete blob")
+		}
+	}
+
+	return nil
+}
+
+func (s *StoreDeleter) deleteNotifications(ctx context.Context, userID string) error {
+	return s.notificationClient.DeleteUserNotifications(ctx, userID)
+}
+
+func (s *StoreDeleter) deleteNotificationPreferences(ctx context.Context, userID string) error {
+	return s.notificationClient.DeleteUserPreferences(ctx, userID)
+}
+
+// Alert rules owned by the user and alert rules of other users targeting the user are both deleted
+func (s *StoreDeleter) deleteAlertRules(ctx context.Context, userID string) error {
+	ids := map[string]bool{}
+
+	ownerFilter := alert.NewRuleFilter()
+	ownerFilter.OwnerID = pointer.FromString(userID)
+	userFilter := alert.NewRuleFilter()
+	userFilter.UserID = pointer.FromString(userID)
+	for _, filter := range []*alert.RuleFilter{ownerFilter, userFilter} {
+		pagination := page.NewPagination()
+		pagination.Size = PageSize
+		for ; ; pagination.Page++ {
+			rules, err := s.notificationClient.ListRules(ctx, filter, pagination)
+			if err != nil {
+				return errors.Wrap(err, "unable to list rules")
+			}
+			for _, rule := range rules {
+				ids[rule.ID] = true
+			}
+			if len(rules) < pagination.Size {
+				break
+			}
+		}
+	}
+
+	for id := range ids {
+		if err := s.notificationClient.DeleteRule(ctx, id); err != nil && !request.IsErrorResourceNotFound(err) {
+			return errors.Wrap(err, "unable to delete rule")
+		}
+	}
+
+	return nil
+}
+
+func (s *StoreDeleter) deleteConfirmations(ctx context.Context, userID string) error {
+	ssn := s.confirmationStore.NewConfirmationSession()
+	defer ssn.Close()
+
+	return ssn.DeleteUserConfirmations(ctx, userID)
+}
+
+func (s *StoreDeleter) deletePermissions(ctx context.Context, userID string) error {
+	ssn := s.permissionStore.NewPermissionsSession()
+	defer ssn.Close()
+
+	return ssn.DestroyPermissionsForUserByID(ctx, userID)
+}
+
+// Messages to the user are destroyed while messages from the user are kept, attributed only by full name
+func (s *StoreDeleter) deleteMessages(ctx context.Context, userID string) error {
+	usr, err := s.getUser(ctx, userID)
+	if err != nil || usr == nil {
+		return err
+	}
+
+	messageUser := &messageStore.User{
+		ID: userID,
+	}
+	if usr.ProfileID != nil {
+		profileSsn := s.profileStore.NewProfilesSession()
+		defer profileSsn.Close()
+
+		var prfl *profile.Profile
+		prfl, err = profileSsn.GetProfileByID(ctx, *usr.ProfileID)
+		if err != nil {
+			return errors.Wrap(err, "unable to get profile by id")
+		}
+		if prfl != nil && prfl.FullName != nil {
+			messageUser.FullName = *prfl.FullName
+		}
+	}
+
+	ssn := s.messageStore.NewMessagesSession()
+	defer ssn.Close()
+
+	if err = ssn.DestroyMessagesForUserByID(ctx, userID); err != nil {
+		return err
+	}
+	return ssn.DeleteMessagesFromUser(ctx, messageUser)
+}
+
+func (s *StoreDeleter) deleteProfile(ctx context.Context, userID string) error {
+	usr, err := s.getUser(ctx, userID)
+	if err != nil || usr == nil || usr.ProfileID == nil {
+		return err
+	}
+
+	ssn := s.profileStore.NewProfilesSession()
+	defer ssn.Close()
+
+	prfl, err := ssn.GetProfileByID(ctx, *usr.ProfileID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get profile by id")
+	} else if prfl == nil {
+		return nil
+	}
+
+	return ssn.DestroyProfileByID(ctx, *usr.ProfileID)
+}
+
+func (s *StoreDeleter) deleteUser(ctx context.Context, userID string) error {
+	usr, err := s.getUser(ctx, userID)
+	if err != nil || usr == nil {
+		return err
+	}
+
+	ssn := s.userStore.NewUsersSession()
+	defer ssn.Close()
+
+	return ssn.DestroyUserByID(ctx, userID)
+}
+
+// The user is destroyed last, so if the user is not found then every other step has already completed
+func (s *StoreDeleter) getUser(ctx context.Context, userID string) (*user.User, error) {
+	ssn := s.userStore.NewUsersSession()
+	defer ssn.Close()
+
+	usr, err := ssn.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get user by id")
+	}
+	return usr, nil
+}
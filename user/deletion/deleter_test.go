@@ -0,0 +1,132 @@
+package deletion_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+
+	"github.com/tidepool-org/platform/alert"
+	alertTest "github.com/tidepool-org/platform/alert/test"
+	"github.com/tidepool-org/platform/auth"
+	authTest "github.com/tidepool-org/platform/auth/test"
+	blobTest "github.com/tidepool-org/platform/blob/test"
+	confirmationStore "github.com/tidepool-org/platform/confirmation/store"
+	dataClientTest "github.com/tidepool-org/platform/data/client/test"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	messageStore "github.com/tidepool-org/platform/message/store"
+	notificationTest "github.com/tidepool-org/platform/notification/test"
+	permissionStore "github.com/tidepool-org/platform/permission/store"
+	profileStore "github.com/tidepool-org/platform/profile/store"
+	"github.com/tidepool-org/platform/request"
+	sessionStore "github.com/tidepool-org/platform/session/store"
+	taskTest "github.com/tidepool-org/platform/task/test"
+	"github.com/tidepool-org/platform/user"
+	userDeletion "github.com/tidepool-org/platform/user/deletion"
+	userStore "github.com/tidepool-org/platform/user/store"
+)
+
+// The stores are not used by the steps under test
+type confirmationStoreFake struct{ confirmationStore.Store }
+type messageStoreFake struct{ messageStore.Store }
+type permissionStoreFake struct{ permissionStore.Store }
+type profileStoreFake struct{ profileStore.Store }
+type sessionStoreFake struct{ sessionStore.Store }
+type userStoreFake struct{ userStore.Store }
+
+var _ = Describe("StoreDeleter", func() {
+	var ctx context.Context
+	var userID string
+	var authClient *authTest.Client
+	var notificationClient *notificationTest.Client
+	var dltr *userDeletion.StoreDeleter
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		userID = user.NewID()
+		authClient = authTest.NewClient()
+		notificationClient = notificationTest.NewClient()
+		dltr, err = userDeletion.NewStoreDeleter(authClient, blobTest.NewClient(), dataClientTest.NewClient(), notificationClient, taskTest.NewClient(), &confirmationStoreFake{},
+			&messageStoreFake{}, &permissionStoreFake{}, &profileStoreFake{}, &sessionStoreFake{}, &userStoreFake{})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		authClient.Expectations()
+		notificationClient.Expectations()
+	})
+
+	It("returns an error if the step is unknown", func() {
+		Expect(dltr.Delete(ctx, userID, "unknown")).To(MatchError(`step "unknown" is unknown`))
+	})
+
+	Context("with oauth grants step", func() {
+		It("deletes the oauth grants of the user", func() {
+			authClient.DeleteUserOAuthGrantsOutputs = []error{nil}
+			Expect(dltr.Delete(ctx, userID, userDeletion.StepOAuthGrants)).To(Succeed())
+			Expect(authClient.DeleteUserOAuthGrantsInputs).To(Equal([]authTest.DeleteUserOAuthGrantsInput{{Context: ctx, UserID: userID}}))
+		})
+
+		It("returns an error if the oauth grants cannot be deleted", func() {
+			responseErr := errorsTest.NewError()
+			authClient.DeleteUserOAuthGrantsOutputs = []error{responseErr}
+			Expect(dltr.Delete(ctx, userID, userDeletion.StepOAuthGrants)).To(Equal(responseErr))
+		})
+	})
+
+	Context("with restricted tokens step", func() {
+		It("deletes each restricted token of the user, ignoring restricted tokens already deleted", func() {
+			authClient.ListUserRestrictedTokensOutputs = []authTest.ListUserRestrictedTokensOutput{{RestrictedTokens: auth.RestrictedTokens{{ID: "one"}, {ID: "two"}}, Error: nil}}
+			authClient.DeleteRestrictedTokenOutputs = []error{nil, request.ErrorResourceNotFoundWithID("two")}
+			Expect(dltr.Delete(ctx, userID, userDeletion.StepRestrictedTokens)).To(Succeed())
+			Expect(authClient.DeleteRestrictedTokenInputs).To(Equal([]authTest.DeleteRestrictedTokenInput{{Context: ctx, ID: "one"}, {Context: ctx, ID: "two"}}))
+		})
+
+		It("returns an error if a restricted token cannot be deleted", func() {
+			authClient.ListUserRestrictedTokensOutputs = []authTest.ListUserRestrictedTokensOutput{{RestrictedTokens: auth.RestrictedTokens{{ID: "one"}}, Error: nil}}
+			authClient.DeleteRestrictedTokenOutputs = []error{errorsTest.NewError()}
+			Expect(dltr.Delete(ctx, userID, userDeletion.StepRestrictedTokens)).To(MatchError(HavePrefix("unable to delete restricted token")))
+		})
+	})
+
+	Context("with notifications step", func() {
+		It("deletes the notifications of the user", func() {
+			notificationClient.DeleteUserNotificationsOutputs = []error{nil}
+			Expect(dltr.Delete(ctx, userID, userDeletion.StepNotifications)).To(Succeed())
+			Expect(notificationClient.DeleteUserNotificationsInputs).To(Equal([]notificationTest.DeleteUserNotificationsInput{{Context: ctx, UserID: userID}}))
+		})
+	})
+
+	Context("with notification preferences step", func() {
+		It("deletes the notification preferences of the user", func() {
+			notificationClient.DeleteUserPreferencesOutputs = []error{nil}
+			Expect(dltr.Delete(ctx, userID, userDeletion.StepNotificationPreferences)).To(Succeed())
+			Expect(notificationClient.DeleteUserPreferencesInputs).To(Equal([]notificationTest.DeleteUserPreferencesInput{{Context: ctx, UserID: userID}}))
+		})
+	})
+
+	Context("with alert rules step", func() {
+		It("deletes the rules owned by the user and the rules targeting the user once each", func() {
+			notificationClient.ListRulesOutputs = []alertTest.ListRulesOutput{
+				{Rules: alert.Rules{{ID: "owned"}, {ID: "both"}}, Error: nil},
+				{Rules: alert.Rules{{ID: "targeting"}, {ID: "both"}}, Error: nil},
+			}
+			notificationClient.DeleteRuleOutputs = []error{nil, nil, request.ErrorResourceNotFoundWithID("both")}
+			Expect(dltr.Delete(ctx, userID, userDeletion.StepAlertRules)).To(Succeed())
+			Expect(notificationClient.ListRulesInputs).To(HaveLen(2))
+			Expect(*notificationClient.ListRulesInputs[0].Filter.OwnerID).To(Equal(userID))
+			Expect(*notificationClient.ListRulesInputs[1].Filter.UserID).To(Equal(userID))
+			ids := []string{}
+			for _, input := range notificationClient.DeleteRuleInputs {
+				ids = append(ids, input.ID)
+			}
+			Expect(ids).To(ConsistOf("owned", "targeting", "both"))
+		})
+
+		It("returns an error if the rules cannot be listed", func() {
+			notificationClient.ListRulesOutputs = []alertTest.ListRulesOutput{{Rules: nil, Error: errorsTest.NewError()}}
+			Expect(dltr.Delete(ctx, userID, userDeletion.StepAlertRules)).To(MatchError(HavePrefix("unable to list rules")))
+		})
+	})
+})
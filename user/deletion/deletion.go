@@ -0,0 +1,122 @@
+package deletion
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+)
+
+const Type = "org.tidepool.user.deletion"
+
+// The steps of a deletion, in the order they are run. The user is destroyed last so that a deletion may always be
+// resumed from the user.
+const (
+	StepSessions                = "sessions"
+	StepProviderSessions        = "providerSessions"
+	StepOAuthGrants             = "oauthGrants"
+	StepRestrictedTokens        = "restrictedTokens"
+	StepDataSources             = "dataSources"
+	StepExportTasks             = "exportTasks"
+	StepData                    = "data"
+	StepBlobs                   = "blobs"
+	StepNotifications           = "notifications"
+	StepNotificationPreferences = "notificationPreferences"
+	StepAlertRules              = "alertRules"
+	StepConfirmations           = "confirmations"
+	StepPermissions             = "permissions"
+	StepMessages                = "messages"
+	StepProfile                 = "profile"
+	StepUser                    = "user"
+)
+
+func Steps() []string {
+	return []string{
+		StepSessions,
+		StepProviderSessions,
+		StepOAuthGrants,
+		StepRestrictedTokens,
+		StepDataSources,
+		StepExportTasks,
+		StepData,
+		StepBlobs,
+		StepNotifications,
+		StepNotificationPreferences,
+		StepAlertRules,
+		StepConfirmations,
+		StepPermissions,
+		StepMessages,
+		StepProfile,
+		StepUser,
+	}
+}
+
+func TaskName(userID string) string {
+	return fmt.Sprintf("%s:%s", Type, userID)
+}
+
+// NewTaskCreate creates a deletion task for the user. Steps completed by a previous deletion are not run again.
+func NewTaskCreate(userID string, completedSteps []string) (*task.TaskCreate, error) {
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+
+	data := map[string]interface{}{
+		"userId": userID,
+	}
+	if len(completedSteps) > 0 {
+		data["completedSteps"] = completedSteps
+	}
+
+	return &task.TaskCreate{
+		Name: pointer.FromString(TaskName(userID)),
+		Type: Type,
+		Data: data,
+	}, nil
+}
+
+// CompletedSteps returns the steps recorded as completed in the task data, which may have been decoded as an array
+// of interfaces
+func CompletedSteps(tsk *task.Task) []string {
+	completedSteps := []string{}
+	if tsk == nil {
+		return completedSteps
+	}
+
+	switch value := tsk.Data["completedSteps"].(type) {
+	case []string:
+		completedSteps = append(completedSteps, value...)
+	case []interface{}:
+		for _, step := range value {
+			if stepString, ok := step.(string); ok {
+				completedSteps = append(completedSteps, stepString)
+			}
+		}
+	}
+	return completedSteps
+}
+
+// Status is the status of a deletion task as reported to the user, without internal task details
+type Status struct {
+	State          string     `json:"state,omitempty"`
+	CompletedSteps []string   `json:"completedSteps"`
+	Attempts       int        `json:"attempts"`
+	CreatedTime    time.Time  `json:"createdTime,omitempty"`
+	ModifiedTime   *time.Time `json:"modifiedTime,omitempty"`
+}
+
+func NewStatus(tsk *task.Task) *Status {
+	if tsk == nil {
+		return nil
+	}
+
+	return &Status{
+		State:          tsk.State,
+		CompletedSteps: CompletedSteps(tsk),
+		Attempts:       tsk.ErrorCount(),
+		CreatedTime:    tsk.CreatedTime,
+		ModifiedTime:   tsk.ModifiedTime,
+	}
+}
@@ -0,0 +1,115 @@
+package deletion_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"time"
+
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+	"github.com/tidepool-org/platform/user"
+	userDeletion "github.com/tidepool-org/platform/user/deletion"
+)
+
+var _ = Describe("Deletion", func() {
+	var userID string
+
+	BeforeEach(func() {
+		userID = user.NewID()
+	})
+
+	It("Type is expected", func() {
+		Expect(userDeletion.Type).To(Equal("org.tidepool.user.deletion"))
+	})
+
+	It("Steps returns expected", func() {
+		Expect(userDeletion.Steps()).To(Equal([]string{"sessions", "providerSessions", "oauthGrants", "restrictedTokens", "dataSources", "exportTasks", "data", "blobs", "notifications", "notificationPreferences", "alertRules", "confirmations", "permissions", "messages", "profile", "user"}))
+	})
+
+	It("Steps destroys the user last", func() {
+		steps := userDeletion.Steps()
+		Expect(steps[len(steps)-1]).To(Equal(userDeletion.StepUser))
+	})
+
+	Context("TaskName", func() {
+		It("returns the type and user id", func() {
+			Expect(userDeletion.TaskName(userID)).To(Equal(userDeletion.Type + ":" + userID))
+		})
+	})
+
+	Context("NewTaskCreate", func() {
+		It("returns an error if the user id is missing", func() {
+			taskCreate, err := userDeletion.NewTaskCreate("", nil)
+			Expect(err).To(MatchError("user id is missing"))
+			Expect(taskCreate).To(BeNil())
+		})
+
+		It("returns successfully without completed steps", func() {
+			taskCreate, err := userDeletion.NewTaskCreate(userID, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(taskCreate).ToNot(BeNil())
+			Expect(taskCreate.Name).To(Equal(pointer.FromString(userDeletion.TaskName(userID))))
+			Expect(taskCreate.Type).To(Equal(userDeletion.Type))
+			Expect(taskCreate.Data).To(Equal(map[string]interface{}{"userId": userID}))
+		})
+
+		It("returns successfully with completed steps", func() {
+			taskCreate, err := userDeletion.NewTaskCreate(userID, []string{userDeletion.StepSessions})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(taskCreate).ToNot(BeNil())
+			Expect(taskCreate.Data).To(Equal(map[string]interface{}{"userId": userID, "completedSteps": []string{userDeletion.StepSessions}}))
+		})
+	})
+
+	Context("with task", func() {
+		var tsk *task.Task
+
+		BeforeEach(func() {
+			taskCreate, err := userDeletion.NewTaskCreate(userID, nil)
+			Expect(err).ToNot(HaveOccurred())
+			tsk, err = task.NewTask(taskCreate)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		Context("CompletedSteps", func() {
+			It("returns empty if the task is missing", func() {
+				Expect(userDeletion.CompletedSteps(nil)).To(BeEmpty())
+			})
+
+			It("returns empty if the task does not have completed steps", func() {
+				Expect(userDeletion.CompletedSteps(tsk)).To(BeEmpty())
+			})
+
+			It("returns the completed steps as strings", func() {
+				tsk.Data["completedSteps"] = []string{userDeletion.StepSessions, userDeletion.StepData}
+				Expect(userDeletion.CompletedSteps(tsk)).To(Equal([]string{userDeletion.StepSessions, userDeletion.StepData}))
+			})
+
+			It("returns the completed steps as interfaces", func() {
+				tsk.Data["completedSteps"] = []interface{}{userDeletion.StepSessions, 1, userDeletion.StepData}
+				Expect(userDeletion.CompletedSteps(tsk)).To(Equal([]string{userDeletion.StepSessions, userDeletion.StepData}))
+			})
+		})
+
+		Context("NewStatus", func() {
+			It("returns nil if the task is missing", func() {
+				Expect(userDeletion.NewStatus(nil)).To(BeNil())
+			})
+
+			It("returns the status", func() {
+				tsk.State = task.TaskStateRunning
+				tsk.ModifiedTime = pointer.FromTime(time.Now())
+				tsk.Data["completedSteps"] = []interface{}{userDeletion.StepSessions}
+				tsk.Data["errorCount"] = 2
+				Expect(userDeletion.NewStatus(tsk)).To(Equal(&userDeletion.Status{
+					State:          task.TaskStateRunning,
+					CompletedSteps: []string{userDeletion.StepSessions},
+					Attempts:       2,
+					CreatedTime:    tsk.CreatedTime,
+					ModifiedTime:   tsk.ModifiedTime,
+				}))
+			})
+		})
+	})
+})
@@ -0,0 +1,109 @@
+package deletion
+
+import (
+	"context"
+	"time"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/task"
+)
+
+const (
+	AttemptsMaximum      = 10
+	RetryDurationInitial = time.Minute
+	RetryDurationMaximum = time.Hour
+)
+
+var retry = &task.Retry{
+	AttemptsMaximum: AttemptsMaximum,
+	DurationInitial: RetryDurationInitial,
+	DurationMaximum: RetryDurationMaximum,
+}
+
+type Runner struct {
+	logger     log.Logger
+	authClient auth.Client
+	deleter    Deleter
+}
+
+func NewRunner(logger log.Logger, authClient auth.Client, deleter Deleter) (*Runner, error) {
+	if logger == nil {
+		return nil, errors.New("logger is missing")
+	}
+	if authClient == nil {
+		return nil, errors.New("auth client is missing")
+	}
+	if deleter == nil {
+		return nil, errors.New("deleter is missing")
+	}
+
+	return &Runner{
+		logger:     logger,
+		authClient: authClient,
+		deleter:    deleter,
+	}, nil
+}
+
+func (r *Runner) Logger() log.Logger {
+	return r.logger
+}
+
+func (r *Runner) AuthClient() auth.Client {
+	return r.authClient
+}
+
+func (r *Runner) Deleter() Deleter {
+	return r.deleter
+}
+
+func (r *Runner) CanRunTask(tsk *task.Task) bool {
+	return tsk != nil && tsk.Type == Type
+}
+
+// Run deletes the user one step at a time, recording each completed step in the task data. If a step fails, the task
+// is repeated from that step until the maximum number of attempts is reached.
+func (r *Runner) Run(ctx context.Context, tsk *task.Task) {
+	ctx = log.NewContextWithLogger(ctx, r.Logger())
+
+	tsk.ClearError()
+
+	userID, ok := tsk.Data["userId"].(string)
+	if !ok || userID == "" {
+		tsk.AppendError(errors.New("user id is missing"))
+		return
+	}
+
+	serverSessionToken, err := r.AuthClient().ServerSessionToken()
+	if err != nil {
+		retry.RepeatOnError(tsk, errors.Wrap(err, "unable to get server session token"))
+		return
+	}
+	ctx = auth.NewContextWithServerSessionToken(ctx, serverSessionToken)
+
+	completedSteps := CompletedSteps(tsk)
+	for _, step := range Steps() {
+		if containsStep(completedSteps, step) {
+			continue
+		}
+
+		if err = r.Deleter().Delete(ctx, userID, step); err != nil {
+			r.Logger().WithError(err).WithFields(log.Fields{"userId": userID, "step": step}).Warn("Unable to delete user step")
+			retry.RepeatOnError(tsk, errors.Wrapf(err, "unable to delete %s", step))
+			return
+		}
+
+		completedSteps = append(completedSteps, step)
+		tsk.Data["completedSteps"] = completedSteps
+	}
+}
+
+func containsStep(steps []string, step string) bool {
+	for _, s := range steps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
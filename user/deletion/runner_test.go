@@ -0,0 +1,190 @@
+package deletion_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"time"
+
+	"github.com/tidepool-org/platform/auth"
+	authTest "github.com/tidepool-org/platform/auth/test"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/log"
+	logTest "github.com/tidepool-org/platform/log/test"
+	"github.com/tidepool-org/platform/task"
+	"github.com/tidepool-org/platform/user"
+	userDeletion "github.com/tidepool-org/platform/user/deletion"
+)
+
+type deleter struct {
+	Contexts []context.Context
+	UserIDs  []string
+	Steps    []string
+	Outputs  []error
+}
+
+func (d *deleter) Delete(ctx context.Context, userID string, step string) error {
+	d.Contexts = append(d.Contexts, ctx)
+	d.UserIDs = append(d.UserIDs, userID)
+	d.Steps = append(d.Steps, step)
+
+	Expect(d.Outputs).ToNot(BeEmpty())
+
+	output := d.Outputs[0]
+	d.Outputs = d.Outputs[1:]
+	return output
+}
+
+var _ = Describe("Runner", func() {
+	var logger *logTest.Logger
+	var authClient *authTest.Client
+	var dltr *deleter
+
+	BeforeEach(func() {
+		logger = logTest.NewLogger()
+		authClient = authTest.NewClient()
+		dltr = &deleter{}
+	})
+
+	AfterEach(func() {
+		Expect(dltr.Outputs).To(BeEmpty())
+		authClient.Expectations()
+	})
+
+	Context("NewRunner", func() {
+		It("returns an error if the logger is missing", func() {
+			rnnr, err := userDeletion.NewRunner(nil, authClient, dltr)
+			Expect(err).To(MatchError("logger is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the auth client is missing", func() {
+			rnnr, err := userDeletion.NewRunner(logger, nil, dltr)
+			Expect(err).To(MatchError("auth client is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the deleter is missing", func() {
+			rnnr, err := userDeletion.NewRunner(logger, authClient, nil)
+			Expect(err).To(MatchError("deleter is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			Expect(userDeletion.NewRunner(logger, authClient, dltr)).ToNot(BeNil())
+		})
+	})
+
+	Context("with new runner", func() {
+		var rnnr *userDeletion.Runner
+
+		BeforeEach(func() {
+			var err error
+			rnnr, err = userDeletion.NewRunner(logger, authClient, dltr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rnnr).ToNot(BeNil())
+		})
+
+		Context("CanRunTask", func() {
+			It("returns false if the task is missing", func() {
+				Expect(rnnr.CanRunTask(nil)).To(BeFalse())
+			})
+
+			It("returns false if the task type does not match", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: "org.tidepool.user.export"})).To(BeFalse())
+			})
+
+			It("returns true if the task type matches", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: userDeletion.Type})).To(BeTrue())
+			})
+		})
+
+		Context("Run", func() {
+			var ctx context.Context
+			var userID string
+			var tsk *task.Task
+			var serverSessionToken string
+
+			BeforeEach(func() {
+				ctx = context.Background()
+				userID = user.NewID()
+				taskCreate, err := userDeletion.NewTaskCreate(userID, nil)
+				Expect(err).ToNot(HaveOccurred())
+				tsk, err = task.NewTask(taskCreate)
+				Expect(err).ToNot(HaveOccurred())
+				tsk.State = task.TaskStateRunning
+				serverSessionToken = authTest.NewSessionToken()
+			})
+
+			It("records the error if the user id is missing", func() {
+				delete(tsk.Data, "userId")
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.Error.Error).To(MatchError("user id is missing"))
+				Expect(tsk.State).To(Equal(task.TaskStateRunning))
+			})
+
+			It("retries if the server session token returns an error", func() {
+				authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: "", Error: errorsTest.NewError()}}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.State).To(Equal(task.TaskStatePending))
+				Expect(tsk.Data).To(HaveKeyWithValue("errorCount", 1))
+			})
+
+			Context("with server session token", func() {
+				BeforeEach(func() {
+					authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: serverSessionToken, Error: nil}}
+				})
+
+				It("runs every step in order and records progress", func() {
+					dltr.Outputs = make([]error, len(userDeletion.Steps()))
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeFalse())
+					Expect(tsk.State).To(Equal(task.TaskStateRunning))
+					Expect(dltr.Steps).To(Equal(userDeletion.Steps()))
+					for _, id := range dltr.UserIDs {
+						Expect(id).To(Equal(userID))
+					}
+					Expect(log.LoggerFromContext(dltr.Contexts[0])).To(Equal(logger))
+					Expect(auth.ServerSessionTokenFromContext(dltr.Contexts[0])).To(Equal(serverSessionToken))
+					Expect(userDeletion.CompletedSteps(tsk)).To(Equal(userDeletion.Steps()))
+				})
+
+				It("records progress and retries if a step returns an error", func() {
+					dltr.Outputs = []error{nil, nil, errorsTest.NewError()}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeTrue())
+					Expect(tsk.State).To(Equal(task.TaskStatePending))
+					Expect(tsk.AvailableTime).ToNot(BeNil())
+					Expect(*tsk.AvailableTime).To(BeTemporally("~", time.Now().Add(userDeletion.RetryDurationInitial), time.Second))
+					Expect(dltr.Steps).To(Equal(userDeletion.Steps()[:3]))
+					Expect(userDeletion.CompletedSteps(tsk)).To(Equal(userDeletion.Steps()[:2]))
+					Expect(tsk.ErrorCount()).To(Equal(1))
+				})
+
+				It("resumes from the first step not completed", func() {
+					tsk.Data["completedSteps"] = []interface{}{userDeletion.StepSessions, userDeletion.StepProviderSessions}
+					tsk.Data["errorCount"] = 1
+					tsk.Error = nil
+					dltr.Outputs = make([]error, len(userDeletion.Steps())-2)
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeFalse())
+					Expect(tsk.State).To(Equal(task.TaskStateRunning))
+					Expect(dltr.Steps).To(Equal(userDeletion.Steps()[2:]))
+					Expect(userDeletion.CompletedSteps(tsk)).To(Equal(userDeletion.Steps()))
+				})
+
+				It("fails if a step returns an error after the maximum attempts", func() {
+					tsk.Data["errorCount"] = userDeletion.AttemptsMaximum - 1
+					dltr.Outputs = []error{errorsTest.NewError()}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeTrue())
+					Expect(tsk.State).To(Equal(task.TaskStateFailed))
+					Expect(tsk.ErrorCount()).To(Equal(userDeletion.AttemptsMaximum))
+				})
+			})
+		})
+	})
+})
@@ -0,0 +1,129 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/tidepool-org/platform/errors"
+)
+
+const Type = "org.tidepool.user.export"
+
+// Writer writes the files of an export to a zip archive
+type Writer struct {
+	writer *zip.Writer
+}
+
+func NewWriter(writer io.Writer) (*Writer, error) {
+	if writer == nil {
+		return nil, errors.New("writer is missing")
+	}
+
+	return &Writer{
+		writer: zip.NewWriter(writer),
+	}, nil
+}
+
+func (w *Writer) WriteJSON(name string, value interface{}) error {
+	fileWriter, err := w.create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(fileWriter)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(value); err != nil {
+		return errors.Wrapf(err, "unable to encode %q", name)
+	}
+
+	return nil
+}
+
+func (w *Writer) WriteContent(name string, reader io.Reader) error {
+	if reader == nil {
+		return errors.New("reader is missing")
+	}
+
+	fileWriter, err := w.create(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(fileWriter, reader); err != nil {
+		return errors.Wrapf(err, "unable to copy %q", name)
+	}
+
+	return nil
+}
+
+// CreateJSONArray creates a file to which a JSON array is written one element at a time. The array must be closed
+// before any other file is created.
+func (w *Writer) CreateJSONArray(name string) (*JSONArrayWriter, error) {
+	fileWriter, err := w.create(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JSONArrayWriter{
+		name:   name,
+		writer: fileWriter,
+	}, nil
+}
+
+func (w *Writer) Close() error {
+	return w.writer.Close()
+}
+
+func (w *Writer) create(name string) (io.Writer, error) {
+	if name == "" {
+		return nil, errors.New("name is missing")
+	}
+
+	fileWriter, err := w.writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to create %q", name)
+	}
+
+	return fileWriter, nil
+}
+
+type JSONArrayWriter struct {
+	name   string
+	writer io.Writer
+	count  int
+}
+
+func (j *JSONArrayWriter) Write(value interface{}) error {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "unable to encode %q", j.name)
+	}
+
+	separator := ",\n  "
+	if j.count == 0 {
+		separator = "[\n  "
+	}
+	if _, err = io.WriteString(j.writer, separator); err != nil {
+		return errors.Wrapf(err, "unable to write %q", j.name)
+	}
+	if _, err = j.writer.Write(bytes); err != nil {
+		return errors.Wrapf(err, "unable to write %q", j.name)
+	}
+
+	j.count++
+	return nil
+}
+
+func (j *JSONArrayWriter) Close() error {
+	terminator := "\n]\n"
+	if j.count == 0 {
+		terminator = "[]\n"
+	}
+	if _, err := io.WriteString(j.writer, terminator); err != nil {
+		return errors.Wrapf(err, "unable to write %q", j.name)
+	}
+
+	return nil
+}
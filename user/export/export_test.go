@@ -0,0 +1,128 @@
+package export_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+
+	userExport "github.com/tidepool-org/platform/user/export"
+)
+
+// Returns the content of each file in the zip archive by name
+func readArchive(archive []byte) map[string]string {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	Expect(err).ToNot(HaveOccurred())
+	files := map[string]string{}
+	for _, file := range reader.File {
+		fileReader, err := file.Open()
+		Expect(err).ToNot(HaveOccurred())
+		content, err := ioutil.ReadAll(fileReader)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fileReader.Close()).To(Succeed())
+		files[file.Name] = string(content)
+	}
+	return files
+}
+
+var _ = Describe("Export", func() {
+	Context("NewWriter", func() {
+		It("returns an error if the writer is missing", func() {
+			writer, err := userExport.NewWriter(nil)
+			Expect(err).To(MatchError("writer is missing"))
+			Expect(writer).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			Expect(userExport.NewWriter(&bytes.Buffer{})).ToNot(BeNil())
+		})
+	})
+
+	Context("with new writer", func() {
+		var buffer *bytes.Buffer
+		var writer *userExport.Writer
+
+		BeforeEach(func() {
+			var err error
+			buffer = &bytes.Buffer{}
+			writer, err = userExport.NewWriter(buffer)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(writer).ToNot(BeNil())
+		})
+
+		Context("WriteJSON", func() {
+			It("returns an error if the name is missing", func() {
+				Expect(writer.WriteJSON("", map[string]string{})).To(MatchError("name is missing"))
+			})
+
+			It("returns an error if the value cannot be encoded", func() {
+				Expect(writer.WriteJSON("invalid.json", func() {})).To(MatchError(ContainSubstring(`unable to encode "invalid.json"`)))
+			})
+
+			It("writes the value as indented JSON", func() {
+				Expect(writer.WriteJSON("directory/value.json", map[string]string{"alpha": "beta"})).To(Succeed())
+				Expect(writer.Close()).To(Succeed())
+				Expect(readArchive(buffer.Bytes())).To(Equal(map[string]string{
+					"directory/value.json": "{\n  \"alpha\": \"beta\"\n}\n",
+				}))
+			})
+		})
+
+		Context("WriteContent", func() {
+			It("returns an error if the reader is missing", func() {
+				Expect(writer.WriteContent("content", nil)).To(MatchError("reader is missing"))
+			})
+
+			It("returns an error if the name is missing", func() {
+				Expect(writer.WriteContent("", strings.NewReader("content"))).To(MatchError("name is missing"))
+			})
+
+			It("writes the content", func() {
+				Expect(writer.WriteContent("content", strings.NewReader("alpha beta"))).To(Succeed())
+				Expect(writer.Close()).To(Succeed())
+				Expect(readArchive(buffer.Bytes())).To(Equal(map[string]string{
+					"content": "alpha beta",
+				}))
+			})
+		})
+
+		Context("CreateJSONArray", func() {
+			It("returns an error if the name is missing", func() {
+				arrayWriter, err := writer.CreateJSONArray("")
+				Expect(err).To(MatchError("name is missing"))
+				Expect(arrayWriter).To(BeNil())
+			})
+
+			It("writes an empty array", func() {
+				arrayWriter, err := writer.CreateJSONArray("array.json")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(arrayWriter.Close()).To(Succeed())
+				Expect(writer.Close()).To(Succeed())
+				Expect(readArchive(buffer.Bytes())).To(Equal(map[string]string{
+					"array.json": "[]\n",
+				}))
+			})
+
+			It("returns an error if an element cannot be encoded", func() {
+				arrayWriter, err := writer.CreateJSONArray("array.json")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(arrayWriter.Write(func() {})).To(MatchError(ContainSubstring(`unable to encode "array.json"`)))
+			})
+
+			It("writes each element of the array", func() {
+				arrayWriter, err := writer.CreateJSONArray("array.json")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(arrayWriter.Write(map[string]int{"alpha": 1})).To(Succeed())
+				Expect(arrayWriter.Write(map[string]int{"beta": 2})).To(Succeed())
+				Expect(arrayWriter.Close()).To(Succeed())
+				Expect(writer.Close()).To(Succeed())
+				Expect(readArchive(buffer.Bytes())).To(Equal(map[string]string{
+					"array.json": "[\n  {\"alpha\":1},\n  {\"beta\":2}\n]\n",
+				}))
+			})
+		})
+	})
+})
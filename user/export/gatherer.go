@@ -0,0 +1,602 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tidepool-org/platform/alert"
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/blob"
+	"github.com/tidepool-org/platform/confirmation"
+	confirmationStore "github.com/tidepool-org/platform/confirmation/store"
+	"github.com/tidepool-org/platform/data"
+	dataClient "github.com/tidepool-org/platform/data/client"
+	"github.com/tidepool-org/platform/errors"
+	messageStore "github.com/tidepool-org/platform/message/store"
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/page"
+	permissionStore "github.com/tidepool-org/platform/permission/store"
+	"github.com/tidepool-org/platform/pointer"
+	profileStore "github.com/tidepool-org/platform/profile/store"
+	sessionStore "github.com/tidepool-org/platform/session/store"
+	"github.com/tidepool-org/platform/user"
+	userStore "github.com/tidepool-org/platform/user/store"
+)
+
+const (
+	DataWindowDuration = 24 * time.Hour
+	PageSize           = page.PaginationSizeMaximum
+)
+
+// Gatherer gathers all of the information held about a user into an export
+type Gatherer interface {
+	Gather(ctx context.Context, userID string, writer *Writer) error
+}
+
+// StoreGatherer gathers from every store holding user information, the same stores from which a user is deleted
+type StoreGatherer struct {
+	authClient         auth.Client
+	dataClient         dataClient.Client
+	blobClient         blob.Client
+	notificationClient notification.Client
+	confirmationStore  confirmationStore.Store
+	messageStore       messageStore.Store
+	permissionStore    permissionStore.Store
+	profileStore       profileStore.Store
+	sessionStore       sessionStore.Store
+	userStore          userStore.Store
+}
+
+func NewStoreGatherer(authClient auth.Client, dataClient dataClient.Client, blobClient blob.Client, notificationClient notification.Client,
+	confirmationStore confirmationStore.Store, messageStore messageStore.Store, permissionStore permissionStore.Store, profileStore profileStore.Store,
+	sessionStore sessionStore.Store, userStore userStore.Store) (*StoreGatherer, error) {
+	if authClient == nil {
+		return nil, errors.New("auth client is missing")
+	}
+	if dataClient == nil {
+		return nil, errors.New("data client is missing")
+	}
+	if blobClient == nil {
+		return nil, errors.New("blob client is missing")
+	}
+	if notificationClient == nil {
+		return nil, errors.New("notification client is missing")
+	}
+	if confirmationStore == nil {
+		return nil, errors.New("confirmation store is missing")
+	}
+	if messageStore == nil {
+		return nil, errors.New("message store is missing")
+	}
+	if permissionStore == nil {
+		return nil, errors.New("permission store is missing")
+	}
+	if profileStore == nil {
+		return nil, errors.New("profile store is missing")
+	}
+	if sessionStore == nil {
+		return nil, errors.New("session store is missing")
+	}
+	if userStore == nil {
+		return nil, errors.New("user store is missing")
+	}
+
+	return &StoreGatherer{
+		authClient:         authClient,
+		dataClient:         dataClient,
+		blobClient:         blobClient,
+		notificationClient: notificationClient,
+		confirmationStore:  confirmationStore,
+		messageStore:       messageStore,
+		permissionStore:    permissionStore,
+		profileStore:       profileStore,
+		sessionStore:       sessionStore,
+		userStore:          userStore,
+	}, nil
+}
+
+func (s *StoreGatherer) Gather(ctx context.Context, userID string, writer *Writer) error {
+	if ctx == nil {
+		return errors.New("context is missing")
+	}
+	if userID == "" {
+		return errors.New("user id is missing")
+	}
+	if writer == nil {
+		return errors.New("writer is missing")
+	}
+
+	usr, err := s.gatherUser(ctx, userID, writer)
+	if err != nil {
+		return err
+	}
+	if err = s.gatherProfile(ctx, usr, writer); err != nil {
+		return err
+	}
+	if err = s.gatherSessions(ctx, userID, writer); err != nil {
+		return err
+	}
+	if err = s.gatherPermissions(ctx, userID, writer); err != nil {
+		return err
+	}
+	if err = s.gatherConfirmations(ctx, usr, writer); err != nil {
+		return err
+	}
+	if err = s.gatherMessages(ctx, userID, writer); err != nil {
+		return err
+	}
+	if err = s.gatherRestrictedTokens(ctx, userID, writer); err != nil {
+		return err
+	}
+	if err = s.gatherOAuthGrants(ctx, userID, writer); err != nil {
+		return err
+	}
+	if err = s.gatherNotifications(ctx, userID, writer); err != nil {
+		return err
+	}
+	if err = s.gatherNotificationPreferences(ctx, userID, writer); err != nil {
+		return err
+	}
+	if err = s.gatherAlertRules(ctx, userID, writer); err != nil {
+		return err
+	}
+	if err = s.gatherDataSources(ctx, userID, writer); err != nil {
+		return err
+	}
+	if err = s.gatherDataSets(ctx, userID, writer); err != nil {
+		return err
+	}
+	if err = s.gatherData(ctx, userID, writer); err != nil {
+		return err
+	}
+	return s.gatherBlobs(ctx, userID, writer)
+}
+
+func (s *StoreGatherer) gatherUser(ctx context.Context, userID string, writer *Writer) (*user.User, error) {
+	ssn := s.userStore.NewUsersSession()
+	defer ssn.Close()
+
+	usr, err := ssn.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get user by id")
+	} else if usr == nil {
+		return nil, errors.New("user not found")
+	}
+
+	return usr, writer.WriteJSON("user.json", usr)
+}
+
+// The profile is exported in its entirety, if valid, rather than only the parsed fields
+func (s *StoreGatherer) gatherProfile(ctx context.Context, usr *user.User, writer *Writer) error {
+	if usr.ProfileID == nil {
+		return nil
+	}
+
+	ssn := s.profileStore.NewProfilesSession()
+	defer ssn.Close()
+
+	prfl, err := ssn.GetProfileByID(ctx, *usr.ProfileID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get profile by id")
+	} else if prfl == nil {
+		return nil
+	}
+
+	if json.Valid([]byte(prfl.Value)) {
+		return writer.WriteContent("profile.json", strings.NewReader(prfl.Value))
+	}
+	return writer.WriteJSON("profile.json", prfl)
+}
+
+type exportSession struct {
+	IsServer       bool       `json:"isServer"`
+	CreatedTime    *time.Time `json:"createdTime,omitempty"`
+	ExpirationTime *time.Time `json:"expirationTime,omitempty"`
+}
+
+// Session tokens are never exported
+func (s *StoreGatherer) gatherSessions(ctx context.Context, userID string, writer *Writer) error {
+	ssn := s.sessionStore.NewSessionsSession()
+	defer ssn.Close()
+
+	sessions, err := ssn.ListSessionsForUserByID(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "unable to list sessions for user by id")
+	}
+
+	exportSessions := []*exportSession{}
+	for _, sssn := range sessions {
+		exportSessions = append(exportSessions, &exportSession{
+			IsServer:       sssn.IsServer,
+			CreatedTime:    unixTime(sssn.CreatedAt),
+			ExpirationTime: unixTime(sssn.ExpiresAt),
+		})
+	}
+
+	return writer.WriteJSON("sessions.json", exportSessions)
+}
+
+func (s *StoreGatherer) gatherPermissions(ctx context.Context, userID string, writer *Writer) error {
+	ssn := s.permissionStore.NewPermissionsSession()
+	defer ssn.Close()
+
+	granted, err := ssn.ListGrantsForTargetUser(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "unable to list grants for target user")
+	}
+	if err = writer.WriteJSON("permissions/granted.json", granted); err != nil {
+		return err
+	}
+
+	received, err := ssn.ListGrantsForUser(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "unable to list grants for user")
+	}
+	return writer.WriteJSON("permissions/received.json", received)
+}
+
+func (s *StoreGatherer) gatherConfirmations(ctx context.Context, usr *user.User, writer *Writer) error {
+	ssn := s.confirmationStore.NewConfirmationSession()
+	defer ssn.Close()
+
+	filter := confirmation.NewConfirmationFilter()
+	filter.CreatorID = pointer.FromString(usr.ID)
+	created, err := ssn.ListConfirmations(ctx, filter)
+	if err != nil {
+		return errors.Wrap(err, "unable to list confirmations created by user")
+	}
+	if err = writer.WriteJSON("confirmations/created.json", created); err != nil {
+		return err
+	}
+
+	received := confirmation.Confirmations{}
+	if emails := usr.AllEmails(); len(emails) > 0 {
+		filter = confirmation.NewConfirmationFilter()
+		filter.Emails = emails
+		if received, err = ssn.ListConfirmations(ctx, filter); err != nil {
+			return errors.Wrap(err, "unable to list confirmations received by user")
+		}
+	}
+	return writer.WriteJSON("confirmations/received.json", received)
+}
+
+func (s *StoreGatherer) gatherMessages(ctx context.Context, userID string, writer *Writer) error {
+	ssn := s.messageStore.NewMessagesSession()
+	defer ssn.Close()
+
+	messages, err := ssn.ListMessagesForUserByID(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "unable to list messages for user by id")
+	}
+
+	return writer.WriteJSON("messages.json", messages)
+}
+
+type exportRestrictedToken struct {
+	Paths           *[]string  `json:"paths,omitempty"`
+	Methods         *[]string  `json:"methods,omitempty"`
+	ReadOnly        *bool      `json:"readOnly,omitempty"`
+	TargetUserID    *string    `json:"targetUserId,omitempty"`
+	MaximumUses     *int       `json:"maximumUses,omitempty"`
+	UsageCount      int        `json:"usageCount"`
+	LastUsedTime    *time.Time `json:"lastUsedTime,omitempty"`
+	LastUsedAddress *string    `json:"lastUsedAddress,omitempty"`
+	ExpirationTime  time.Time  `json:"expirationTime"`
+	CreatedTime     time.Time  `json:"createdTime"`
+	ModifiedTime    *time.Time `json:"modifiedTime,omitempty"`
+}
+
+// The restricted token id is the token itself, so it is never exported
+func (s *StoreGatherer) gatherRestrictedTokens(ctx context.Context, userID string, writer *Writer) error {
+	exportRestrictedTokens := []*exportRestrictedToken{}
+
+	pagination := page.NewPagination()
+	pagination.Size = PageSize
+	for ; ; pagination.Page++ {
+		restrictedTokens, err := s.authClient.ListUserRestrictedTokens(ctx, userID, nil, pagination)
+		if err != nil {
+			return errors.Wrap(err, "unable to list user restricted tokens")
+		}
+		for _, restrictedToken := range restrictedTokens {
+			exportRestrictedTokens = append(exportRestrictedTokens, &exportRestrictedToken{
+				Paths:           restrictedToken.Paths,
+				Methods:         restrictedToken.Methods,
+				ReadOnly:        restrictedToken.ReadOnly,
+				TargetUserID:    restrictedToken.TargetUserID,
+				MaximumUses:     restrictedToken.MaximumUses,
+				UsageCount:      restrictedToken.UsageCount,
+				LastUsedTime:    restrictedToken.LastUsedTime,
+				LastUsedAddress: restrictedToken.LastUsedAddress,
+				ExpirationTime:  restrictedToken.ExpirationTime,
+				CreatedTime:     restrictedToken.CreatedTime,
+				ModifiedTime:    restrictedToken.ModifiedTime,
+			})
+		}
+		if len(restrictedTokens) < pagination.Size {
+			break
+		}
+	}
+
+	return writer.WriteJSON("restricted_tokens.json", exportRestrictedTokens)
+}
+
+// Only the client, scopes and times of each grant are exported, never the tokens or their hashes
+func (s *StoreGatherer) gatherOAuthGrants(ctx context.Context, userID string, writer *Writer) error {
+	oauthTokens, err := s.authClient.ListUserOAuthGrants(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "unable to list user oauth grants")
+	}
+
+	return writer.WriteJSON("oauth/grants.json", oauthTokens)
+}
+
+func (s *StoreGatherer) gatherNotifications(ctx context.Context, userID string, writer *Writer) error {
+	arrayWriter, err := writer.CreateJSONArray("notifications/notifications.json")
+	if err != nil {
+		return err
+	}
+
+	pagination := page.NewPagination()
+	pagination.Size = PageSize
+	for ; ; pagination.Page++ {
+		var notifications notification.Notifications
+		notifications, err = s.notificationClient.ListUserNotifications(ctx, userID, nil, pagination)
+		if err != nil {
+			return errors.Wrap(err, "unable to list user notifications")
+		}
+		for _, ntfctn := range notifications {
+			if err = arrayWriter.Write(ntfctn); err != nil {
+				return err
+			}
+		}
+		if len(notifications) < pagination.Size {
+			break
+		}
+	}
+
+	return arrayWriter.Close()
+}
+
+func (s *StoreGatherer) gatherNotificationPreferences(ctx context.Context, userID string, writer *Writer) error {
+	preferences, err := s.notificationClient.GetUserPreferences(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get user notification preferences")
+	} else if preferences == nil {
+		return nil
+	}
+
+	return writer.WriteJSON("notifications/preferences.json", preferences)
+}
+
+func (s *StoreGatherer) gatherAlertRules(ctx context.Context, userID string, writer *Writer) error {
+	filter := alert.NewRuleFilter()
+	filter.OwnerID = pointer.FromString(userID)
+	owned, err := s.listAlertRules(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if err = writer.WriteJSON("alerts/owned.json", owned); err != nil {
+		return err
+	}
+
+	filter = alert.NewRuleFilter()
+	filter.UserID = pointer.FromString(userID)
+	received, err := s.listAlertRules(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return writer.WriteJSON("alerts/received.json", received)
+}
+
+func (s *StoreGatherer) listAlertRules(ctx context.Context, filter *alert.RuleFilter) (alert.Rules, error) {
+	rules := alert.Rules{}
+
+	pagination := page.NewPagination()
+	pagination.Size = PageSize
+	for ; ; pagination.Page++ {
+		rulesPage, err := s.notificationClient.ListRules(ctx, filter, pagination)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to list alert rules")
+		}
+		rules = append(rules, rulesPage...)
+		if len(rulesPage) < pagination.Size {
+			return rules, nil
+		}
+	}
+}
+
+func (s *StoreGatherer) gatherDataSources(ctx context.Context, userID string, writer *Writer) error {
+	arrayWriter, err := writer.CreateJSONArray("data/sources.json")
+	if err != nil {
+		return err
+	}
+
+	pagination := page.NewPagination()
+	pagination.Size = PageSize
+	for ; ; pagination.Page++ {
+		var dataSources data.DataSources
+		dataSources, err = s.dataClient.ListUserDataSources(ctx, userID, nil, pagination)
+		if err != nil {
+			return errors.Wrap(err, "unable to list user data sources")
+		}
+		for _, dataSource := range dataSources {
+			if err = arrayWriter.Write(dataSource); err != nil {
+				return err
+			}
+		}
+		if len(dataSources) < pagination.Size {
+			break
+		}
+	}
+
+	return arrayWriter.Close()
+}
+
+func (s *StoreGatherer) gatherDataSets(ctx context.Context, userID string, writer *Writer) error {
+	arrayWriter, err := writer.CreateJSONArray("data/sets.json")
+	if err != nil {
+		return err
+	}
+
+	pagination := page.NewPagination()
+	pagination.Size = PageSize
+	for ; ; pagination.Page++ {
+		var dataSets data.DataSets
+		dataSets, err = s.dataClient.ListUserDataSets(ctx, userID, nil, pagination)
+		if err != nil {
+			return errors.Wrap(err, "unable to list user data sets")
+		}
+		for _, dataSet := range dataSets {
+			if err = arrayWriter.Write(dataSet); err != nil {
+				return err
+			}
+		}
+		if len(dataSets) < pagination.Size {
+			break
+		}
+	}
+
+	return arrayWriter.Close()
+}
+
+// Data is gathered in daily windows, from the most recent, rather than paginating through all of the data, which
+// becomes slower with each page and shifts as data is added. Each window starts on the day of the most recent datum
+// not yet gathered, so days without data are skipped. Adjacent windows share a boundary, so no datum is gathered twice.
+// Data without a time is never within a window, so it is gathered separately afterwards.
+func (s *StoreGatherer) gatherData(ctx context.Context, userID string, writer *Writer) error {
+	arrayWriter, err := writer.CreateJSONArray("data/data.json")
+	if err != nil {
+		return err
+	}
+
+	var endTime *time.Time
+	for {
+		filter := data.NewDatumFilter()
+		filter.EndTime = endTime
+		filter.HasTime = pointer.FromBool(true)
+
+		latestPagination := page.NewPagination()
+		latestPagination.Size = 1
+		latest, err := s.dataClient.ListUserData(ctx, userID, filter, latestPagination)
+		if err != nil {
+			return errors.Wrap(err, "unable to list user data")
+		} else if len(latest) == 0 {
+			break
+		}
+
+		// A time that cannot be parsed cannot bound a window, so gather the remainder of the timed data at once
+		latestTime, ok := blobTime(latest[0])
+		if !ok {
+			if err = s.gatherDataWindow(ctx, userID, filter, arrayWriter); err != nil {
+				return err
+			}
+			break
+		}
+
+		startTime := latestTime.Add(-time.Second).Truncate(DataWindowDuration)
+		if endTime != nil && !startTime.Before(*endTime) {
+			startTime = endTime.Add(-DataWindowDuration)
+		}
+		filter.StartTime = &startTime
+		if err = s.gatherDataWindow(ctx, userID, filter, arrayWriter); err != nil {
+			return err
+		}
+		endTime = &startTime
+	}
+
+	filter := data.NewDatumFilter()
+	filter.HasTime = pointer.FromBool(false)
+	if err = s.gatherDataWindow(ctx, userID, filter, arrayWriter); err != nil {
+		return err
+	}
+
+	return arrayWriter.Close()
+}
+
+func (s *StoreGatherer) gatherDataWindow(ctx context.Context, userID string, filter *data.DatumFilter, arrayWriter *JSONArrayWriter) error {
+	pagination := page.NewPagination()
+	pagination.Size = PageSize
+	for ; ; pagination.Page++ {
+		blobs, err := s.dataClient.ListUserData(ctx, userID, filter, pagination)
+		if err != nil {
+			return errors.Wrap(err, "unable to list user data")
+		}
+		for _, blb := range blobs {
+			if err = arrayWriter.Write(blb); err != nil {
+				return err
+			}
+		}
+		if len(blobs) < pagination.Size {
+			return nil
+		}
+	}
+}
+
+// Blobs with an expiration time, such as previous exports, are transient and are not exported. The content of
+// quarantined blobs is not exported.
+func (s *StoreGatherer) gatherBlobs(ctx context.Context, userID string, writer *Writer) error {
+	blbs := blob.Blobs{}
+
+	pagination := page.NewPagination()
+	pagination.Size = PageSize
+	for ; ; pagination.Page++ {
+		blbsPage, err := s.blobClient.List(ctx, userID, nil, pagination)
+		if err != nil {
+			return errors.Wrap(err, "unable to list blobs")
+		}
+		for _, blb := range blbsPage {
+			if blb.ExpirationTime == nil {
+				blbs = append(blbs, blb)
+			}
+		}
+		if len(blbsPage) < pagination.Size {
+			break
+		}
+	}
+
+	if err := writer.WriteJSON("blobs/blobs.json", blbs); err != nil {
+		return err
+	}
+
+	for _, blb := range blbs {
+		if blb.ID == nil || blb.IsQuarantined() {
+			continue
+		}
+		if err := s.gatherBlobContent(ctx, *blb.ID, writer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *StoreGatherer) gatherBlobContent(ctx context.Context, id string, writer *Writer) error {
+	content, err := s.blobClient.GetContent(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "unable to get blob content")
+	} else if content == nil || content.Body == nil {
+		return nil
+	}
+	defer content.Body.Close()
+
+	return writer.WriteContent(fmt.Sprintf("blobs/%s", id), content.Body)
+}
+
+func blobTime(blb data.Blob) (time.Time, bool) {
+	if value, ok := blb["time"].(string); ok {
+		if tm, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return tm.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func unixTime(seconds int64) *time.Time {
+	if seconds <= 0 {
+		return nil
+	}
+	return pointer.FromTime(time.Unix(seconds, 0).UTC())
+}
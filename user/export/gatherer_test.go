@@ -0,0 +1,291 @@
+package export_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/tidepool-org/platform/alert"
+	alertTest "github.com/tidepool-org/platform/alert/test"
+	"github.com/tidepool-org/platform/auth"
+	authTest "github.com/tidepool-org/platform/auth/test"
+	blobTest "github.com/tidepool-org/platform/blob/test"
+	"github.com/tidepool-org/platform/confirmation"
+	confirmationStore "github.com/tidepool-org/platform/confirmation/store"
+	"github.com/tidepool-org/platform/data"
+	dataClientTest "github.com/tidepool-org/platform/data/client/test"
+	dataTest "github.com/tidepool-org/platform/data/test"
+	messageStore "github.com/tidepool-org/platform/message/store"
+	"github.com/tidepool-org/platform/notification"
+	notificationTest "github.com/tidepool-org/platform/notification/test"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/permission"
+	permissionStore "github.com/tidepool-org/platform/permission/store"
+	"github.com/tidepool-org/platform/pointer"
+	profileStore "github.com/tidepool-org/platform/profile/store"
+	"github.com/tidepool-org/platform/session"
+	sessionStore "github.com/tidepool-org/platform/session/store"
+	"github.com/tidepool-org/platform/user"
+	userExport "github.com/tidepool-org/platform/user/export"
+	userStore "github.com/tidepool-org/platform/user/store"
+)
+
+type usersSessionFake struct {
+	userStore.UsersSession
+	user *user.User
+}
+
+func (u *usersSessionFake) Close() error { return nil }
+
+func (u *usersSessionFake) GetUserByID(ctx context.Context, userID string) (*user.User, error) {
+	return u.user, nil
+}
+
+type userStoreFake struct{ user *user.User }
+
+func (u *userStoreFake) NewUsersSession() userStore.UsersSession {
+	return &usersSessionFake{user: u.user}
+}
+
+type sessionsSessionFake struct{ sessionStore.SessionsSession }
+
+func (s *sessionsSessionFake) Close() error { return nil }
+
+func (s *sessionsSessionFake) ListSessionsForUserByID(ctx context.Context, userID string) ([]*session.Session, error) {
+	return nil, nil
+}
+
+type sessionStoreFake struct{}
+
+func (s *sessionStoreFake) NewSessionsSession() sessionStore.SessionsSession {
+	return &sessionsSessionFake{}
+}
+
+type permissionsSessionFake struct {
+	permissionStore.PermissionsSession
+}
+
+func (p *permissionsSessionFake) Close() error { return nil }
+
+func (p *permissionsSessionFake) ListGrantsForTargetUser(ctx context.Context, targetUserID string) (permission.Grants, error) {
+	return permission.Grants{}, nil
+}
+
+func (p *permissionsSessionFake) ListGrantsForUser(ctx context.Context, userID string) (permission.Grants, error) {
+	return permission.Grants{}, nil
+}
+
+type permissionStoreFake struct{}
+
+func (p *permissionStoreFake) NewPermissionsSession() permissionStore.PermissionsSession {
+	return &permissionsSessionFake{}
+}
+
+type confirmationSessionFake struct {
+	confirmationStore.ConfirmationSession
+}
+
+func (c *confirmationSessionFake) Close() error { return nil }
+
+func (c *confirmationSessionFake) ListConfirmations(ctx context.Context, filter *confirmation.ConfirmationFilter) (confirmation.Confirmations, error) {
+	return confirmation.Confirmations{}, nil
+}
+
+type confirmationStoreFake struct{}
+
+func (c *confirmationStoreFake) NewConfirmationSession() confirmationStore.ConfirmationSession {
+	return &confirmationSessionFake{}
+}
+
+type messagesSessionFake struct{ messageStore.MessagesSession }
+
+func (m *messagesSessionFake) Close() error { return nil }
+
+func (m *messagesSessionFake) ListMessagesForUserByID(ctx context.Context, userID string) ([]messageStore.Message, error) {
+	return []messageStore.Message{}, nil
+}
+
+type messageStoreFake struct{}
+
+func (m *messageStoreFake) NewMessagesSession() messageStore.MessagesSession {
+	return &messagesSessionFake{}
+}
+
+// The profile store is not used by a user without a profile
+type profileStoreFake struct{ profileStore.Store }
+
+// listUserData selects, sorts and paginates the data as the data store does, comparing formatted times
+func listUserData(blobs []data.Blob, filter *data.DatumFilter, pagination *page.Pagination) []data.Blob {
+	selected := []data.Blob{}
+	for _, blb := range blobs {
+		tm, hasTime := blb["time"].(string)
+		if filter.HasTime != nil && *filter.HasTime != hasTime {
+			continue
+		}
+		if filter.StartTime != nil && (!hasTime || tm < filter.StartTime.UTC().Format(data.TimeFormat)) {
+			continue
+		}
+		if filter.EndTime != nil && (!hasTime || tm >= filter.EndTime.UTC().Format(data.TimeFormat)) {
+			continue
+		}
+		selected = append(selected, blb)
+	}
+	sort.SliceStable(selected, func(i int, j int) bool {
+		left, _ := selected[i]["time"].(string)
+		right, _ := selected[j]["time"].(string)
+		return left > right
+	})
+
+	start := pagination.Page * pagination.Size
+	if start > len(selected) {
+		start = len(selected)
+	}
+	end := start + pagination.Size
+	if end > len(selected) {
+		end = len(selected)
+	}
+	return selected[start:end]
+}
+
+var _ = Describe("StoreGatherer", func() {
+	Context("NewStoreGatherer", func() {
+		It("returns an error if the auth client is missing", func() {
+			gatherer, err := userExport.NewStoreGatherer(nil, dataClientTest.NewClient(), blobTest.NewClient(), notificationTest.NewClient(),
+				&confirmationStoreFake{}, &messageStoreFake{}, &permissionStoreFake{}, &profileStoreFake{}, &sessionStoreFake{}, &userStoreFake{})
+			Expect(err).To(MatchError("auth client is missing"))
+			Expect(gatherer).To(BeNil())
+		})
+
+		It("returns an error if the notification client is missing", func() {
+			gatherer, err := userExport.NewStoreGatherer(authTest.NewClient(), dataClientTest.NewClient(), blobTest.NewClient(), nil,
+				&confirmationStoreFake{}, &messageStoreFake{}, &permissionStoreFake{}, &profileStoreFake{}, &sessionStoreFake{}, &userStoreFake{})
+			Expect(err).To(MatchError("notification client is missing"))
+			Expect(gatherer).To(BeNil())
+		})
+	})
+
+	Context("Gather", func() {
+		var ctx context.Context
+		var userID string
+		var authClient *authTest.Client
+		var dataClient *dataClientTest.Client
+		var blobClient *blobTest.Client
+		var notificationClient *notificationTest.Client
+		var blobs []data.Blob
+		var gatherer *userExport.StoreGatherer
+		var buffer *bytes.Buffer
+		var writer *userExport.Writer
+
+		BeforeEach(func() {
+			var err error
+			ctx = context.Background()
+			userID = user.NewID()
+			authClient = authTest.NewClient()
+			dataClient = dataClientTest.NewClient()
+			blobClient = blobTest.NewClient()
+			notificationClient = notificationTest.NewClient()
+			blobs = []data.Blob{
+				{"id": "untimed-one"},
+				{"id": "latest", "time": "2018-01-03T12:00:00.000Z"},
+				{"id": "midnight", "time": "2018-01-03T00:00:00.000Z"},
+				{"id": "untimed-two"},
+				{"id": "earliest", "time": "2018-01-01T06:00:00.000Z"},
+			}
+			gatherer, err = userExport.NewStoreGatherer(authClient, dataClient, blobClient, notificationClient, &confirmationStoreFake{},
+				&messageStoreFake{}, &permissionStoreFake{}, &profileStoreFake{}, &sessionStoreFake{}, &userStoreFake{user: &user.User{ID: userID}})
+			Expect(err).ToNot(HaveOccurred())
+			buffer = &bytes.Buffer{}
+			writer, err = userExport.NewWriter(buffer)
+			Expect(err).ToNot(HaveOccurred())
+
+			authClient.ListUserRestrictedTokensOutputs = []authTest.ListUserRestrictedTokensOutput{{RestrictedTokens: auth.RestrictedTokens{{ID: "secret", UserID: userID, UsageCount: 2}}, Error: nil}}
+			authClient.ListUserOAuthGrantsOutputs = []authTest.ListUserOAuthGrantsOutput{{OAuthTokens: []*auth.OAuthToken{{AccessTokenHash: "hash", ClientID: "client", UserID: userID}}, Error: nil}}
+			notificationClient.ListUserNotificationsOutputs = []notificationTest.ListUserNotificationsOutput{{Notifications: notification.Notifications{{ID: "notification"}}, Error: nil}}
+			notificationClient.GetUserPreferencesOutputs = []notificationTest.GetUserPreferencesOutput{{Preferences: nil, Error: nil}}
+			notificationClient.ListRulesOutputs = []alertTest.ListRulesOutput{
+				{Rules: alert.Rules{{ID: "owned"}}, Error: nil},
+				{Rules: alert.Rules{{ID: "received"}}, Error: nil},
+			}
+			dataClient.ListUserDataSourcesOutputs = []dataTest.ListUserDataSourcesOutput{{DataSources: data.DataSources{}, Error: nil}}
+			dataClient.ListUserDataSetsOutputs = []dataTest.ListUserDataSetsOutput{{DataSets: data.DataSets{}, Error: nil}}
+			dataClient.ListUserDataStub = func(ctx context.Context, userID string, filter *data.DatumFilter, pagination *page.Pagination) ([]data.Blob, error) {
+				return listUserData(blobs, filter, pagination), nil
+			}
+			blobClient.ListOutputs = []blobTest.ListOutput{{Blobs: nil, Error: nil}}
+		})
+
+		AfterEach(func() {
+			authClient.Expectations()
+			notificationClient.Expectations()
+			dataClient.AssertOutputsEmpty()
+			blobClient.AssertOutputsEmpty()
+		})
+
+		It("gathers the timed data from the most recent and then the data without a time, each datum once", func() {
+			Expect(gatherer.Gather(ctx, userID, writer)).To(Succeed())
+			Expect(writer.Close()).To(Succeed())
+
+			gathered := []data.Blob{}
+			Expect(json.Unmarshal([]byte(readArchive(buffer.Bytes())["data/data.json"]), &gathered)).To(Succeed())
+			ids := []string{}
+			for _, blb := range gathered {
+				ids = append(ids, blb["id"].(string))
+			}
+			Expect(ids).To(Equal([]string{"latest", "midnight", "earliest", "untimed-one", "untimed-two"}))
+
+			for _, input := range dataClient.ListUserDataInputs {
+				Expect(input.Filter.HasTime).ToNot(BeNil())
+				if !*input.Filter.HasTime {
+					Expect(input.Filter.StartTime).To(BeNil())
+					Expect(input.Filter.EndTime).To(BeNil())
+				}
+			}
+		})
+
+		It("gathers the data without a time if there is no timed data", func() {
+			blobs = []data.Blob{{"id": "untimed"}}
+			Expect(gatherer.Gather(ctx, userID, writer)).To(Succeed())
+			Expect(writer.Close()).To(Succeed())
+
+			gathered := []data.Blob{}
+			Expect(json.Unmarshal([]byte(readArchive(buffer.Bytes())["data/data.json"]), &gathered)).To(Succeed())
+			Expect(gathered).To(Equal([]data.Blob{{"id": "untimed"}}))
+		})
+
+		It("gathers restricted tokens without the token, oauth grants without the token hashes, notifications and alert rules", func() {
+			Expect(gatherer.Gather(ctx, userID, writer)).To(Succeed())
+			Expect(writer.Close()).To(Succeed())
+
+			files := readArchive(buffer.Bytes())
+			Expect(files).To(HaveKey("restricted_tokens.json"))
+			Expect(files["restricted_tokens.json"]).ToNot(ContainSubstring("secret"))
+			Expect(files["restricted_tokens.json"]).To(ContainSubstring(`"usageCount": 2`))
+			Expect(files).To(HaveKey("oauth/grants.json"))
+			Expect(files["oauth/grants.json"]).ToNot(ContainSubstring("hash"))
+			Expect(files["oauth/grants.json"]).To(ContainSubstring(`"clientId": "client"`))
+			Expect(files["notifications/notifications.json"]).To(ContainSubstring(`"notification"`))
+			Expect(files).ToNot(HaveKey("notifications/preferences.json"))
+			Expect(files["alerts/owned.json"]).To(ContainSubstring(`"owned"`))
+			Expect(files["alerts/received.json"]).To(ContainSubstring(`"received"`))
+
+			Expect(notificationClient.ListRulesInputs).To(HaveLen(2))
+			Expect(notificationClient.ListRulesInputs[0].Filter.OwnerID).To(Equal(pointer.FromString(userID)))
+			Expect(notificationClient.ListRulesInputs[1].Filter.UserID).To(Equal(pointer.FromString(userID)))
+		})
+
+		It("returns an error if the oauth grants cannot be listed", func() {
+			authClient.ListUserOAuthGrantsOutputs = []authTest.ListUserOAuthGrantsOutput{{OAuthTokens: nil, Error: context.Canceled}}
+			notificationClient.ListUserNotificationsOutputs = nil
+			notificationClient.GetUserPreferencesOutputs = nil
+			notificationClient.ListRulesOutputs = nil
+			dataClient.ListUserDataSourcesOutputs = nil
+			dataClient.ListUserDataSetsOutputs = nil
+			blobClient.ListOutputs = nil
+			Expect(gatherer.Gather(ctx, userID, writer)).To(MatchError("unable to list user oauth grants; context canceled"))
+		})
+	})
+})
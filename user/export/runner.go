@@ -0,0 +1,204 @@
+package export
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/blob"
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/notification"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+)
+
+const (
+	AttemptsMaximum    = 3
+	ExpirationDuration = 3 * 24 * time.Hour
+	MediaType          = "application/zip"
+	RetryDuration      = 5 * time.Minute
+)
+
+var retry = &task.Retry{
+	AttemptsMaximum: AttemptsMaximum,
+	DurationInitial: RetryDuration,
+	DurationMaximum: RetryDuration,
+}
+
+type Runner struct {
+	logger             log.Logger
+	authClient         auth.Client
+	blobClient         blob.Client
+	notificationClient notification.Client
+	gatherer           Gatherer
+}
+
+func NewRunner(logger log.Logger, authClient auth.Client, blobClient blob.Client, notificationClient notification.Client, gatherer Gatherer) (*Runner, error) {
+	if logger == nil {
+		return nil, errors.New("logger is missing")
+	}
+	if authClient == nil {
+		return nil, errors.New("auth client is missing")
+	}
+	if blobClient == nil {
+		return nil, errors.New("blob client is missing")
+	}
+	if notificationClient == nil {
+		return nil, errors.New("notification client is missing")
+	}
+	if gatherer == nil {
+		return nil, errors.New("gatherer is missing")
+	}
+
+	return &Runner{
+		logger:             logger,
+		authClient:         authClient,
+		blobClient:         blobClient,
+		notificationClient: notificationClient,
+		gatherer:           gatherer,
+	}, nil
+}
+
+func (r *Runner) Logger() log.Logger {
+	return r.logger
+}
+
+func (r *Runner) AuthClient() auth.Client {
+	return r.authClient
+}
+
+func (r *Runner) BlobClient() blob.Client {
+	return r.blobClient
+}
+
+func (r *Runner) NotificationClient() notification.Client {
+	return r.notificationClient
+}
+
+func (r *Runner) Gatherer() Gatherer {
+	return r.gatherer
+}
+
+func (r *Runner) CanRunTask(tsk *task.Task) bool {
+	return tsk != nil && tsk.Type == Type
+}
+
+// Run exports the user to a blob owned by the user and notifies the user with a link to download it. The id of the
+// blob is recorded in the task data. A failure is retried a limited number of times; a retry after the blob is created
+// only notifies the user.
+func (r *Runner) Run(ctx context.Context, tsk *task.Task) {
+	ctx = log.NewContextWithLogger(ctx, r.Logger())
+
+	tsk.ClearError()
+
+	userID, ok := tsk.Data["userId"].(string)
+	if !ok || userID == "" {
+		tsk.AppendError(errors.New("user id is missing"))
+		return
+	}
+
+	serverSessionToken, err := r.AuthClient().ServerSessionToken()
+	if err != nil {
+		retry.RepeatOnError(tsk, errors.Wrap(err, "unable to get server session token"))
+		return
+	}
+	ctx = auth.NewContextWithServerSessionToken(ctx, serverSessionToken)
+
+	var blb *blob.Blob
+	if blobID, ok := tsk.Data["blobId"].(string); ok && blobID != "" {
+		if blb, err = r.BlobClient().Get(ctx, blobID); err != nil {
+			retry.RepeatOnError(tsk, errors.Wrap(err, "unable to get blob"))
+			return
+		}
+	}
+	if blb == nil {
+		if blb, err = r.export(ctx, userID); err != nil {
+			retry.RepeatOnError(tsk, errors.Wrap(err, "unable to export user"))
+			return
+		}
+		tsk.Data["blobId"] = *blb.ID
+	}
+
+	if err = r.notify(ctx, userID, blb); err != nil {
+		retry.RepeatOnError(tsk, errors.Wrap(err, "unable to notify user"))
+		return
+	}
+
+	tsk.ClearErrorCount()
+}
+
+// Spool the export to a temporary file since it may be large and the digest must be known before the blob is created.
+// The export is generated by the platform, so it skips the inspection and size maximum of uploaded content.
+func (r *Runner) export(ctx context.Context, userID string) (*blob.Blob, error) {
+	file, err := ioutil.TempFile("", "export")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create temporary file")
+	}
+	defer func() {
+		file.Close()
+		os.Remove(file.Name())
+	}()
+
+	hash := md5.New()
+	writer, err := NewWriter(io.MultiWriter(file, hash))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create writer")
+	}
+	if err = r.Gatherer().Gather(ctx, userID, writer); err != nil {
+		return nil, errors.Wrap(err, "unable to gather user")
+	}
+	if err = writer.Close(); err != nil {
+		return nil, errors.Wrap(err, "unable to close writer")
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err, "unable to seek temporary file")
+	}
+
+	create := blob.NewCreate()
+	create.Body = file
+	create.DigestMD5 = pointer.FromString(base64.StdEncoding.EncodeToString(hash.Sum(nil)))
+	create.MediaType = pointer.FromString(MediaType)
+	create.ExpirationTime = pointer.FromTime(time.Now().Add(ExpirationDuration))
+	create.SkipInspection = pointer.FromBool(true)
+
+	blb, err := r.BlobClient().Create(ctx, userID, create)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create blob")
+	} else if blb == nil || blb.ID == nil {
+		return nil, errors.New("blob is missing")
+	}
+
+	return blb, nil
+}
+
+func (r *Runner) notify(ctx context.Context, userID string, blb *blob.Blob) error {
+	linkCreate := blob.NewLinkCreate()
+	linkCreate.ExpirationTime = blb.ExpirationTime
+
+	link, err := r.BlobClient().CreateLink(ctx, *blb.ID, linkCreate)
+	if err != nil {
+		return errors.Wrap(err, "unable to create blob link")
+	}
+
+	create := notification.NewNotificationCreate()
+	create.Type = notification.TypeUserExport
+	create.Payload = map[string]interface{}{
+		"blobId": *blb.ID,
+	}
+	if link != nil && link.URL != nil {
+		create.Payload["link"] = *link.URL
+	}
+	create.ExpirationTime = blb.ExpirationTime
+
+	if _, err = r.NotificationClient().CreateUserNotification(ctx, userID, create); err != nil {
+		return errors.Wrap(err, "unable to create user notification")
+	}
+
+	return nil
+}
@@ -0,0 +1,308 @@
+package export_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"io/ioutil"
+	"time"
+
+	"github.com/tidepool-org/platform/auth"
+	authTest "github.com/tidepool-org/platform/auth/test"
+	"github.com/tidepool-org/platform/blob"
+	blobTest "github.com/tidepool-org/platform/blob/test"
+	"github.com/tidepool-org/platform/crypto"
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/log"
+	logTest "github.com/tidepool-org/platform/log/test"
+	"github.com/tidepool-org/platform/notification"
+	notificationTest "github.com/tidepool-org/platform/notification/test"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+	"github.com/tidepool-org/platform/test"
+	"github.com/tidepool-org/platform/user"
+	userExport "github.com/tidepool-org/platform/user/export"
+)
+
+type gatherer struct {
+	Contexts []context.Context
+	UserIDs  []string
+	Outputs  []error
+}
+
+func (g *gatherer) Gather(ctx context.Context, userID string, writer *userExport.Writer) error {
+	g.Contexts = append(g.Contexts, ctx)
+	g.UserIDs = append(g.UserIDs, userID)
+
+	Expect(g.Outputs).ToNot(BeEmpty())
+
+	output := g.Outputs[0]
+	g.Outputs = g.Outputs[1:]
+	if output == nil {
+		Expect(writer.WriteJSON("user.json", map[string]string{"userid": userID})).To(Succeed())
+	}
+	return output
+}
+
+var _ = Describe("Runner", func() {
+	var logger *logTest.Logger
+	var authClient *authTest.Client
+	var blobClient *blobTest.Client
+	var notificationClient *notificationTest.Client
+	var gthrr *gatherer
+
+	BeforeEach(func() {
+		logger = logTest.NewLogger()
+		authClient = authTest.NewClient()
+		blobClient = blobTest.NewClient()
+		notificationClient = notificationTest.NewClient()
+		gthrr = &gatherer{}
+	})
+
+	AfterEach(func() {
+		Expect(gthrr.Outputs).To(BeEmpty())
+		notificationClient.Expectations()
+		blobClient.AssertOutputsEmpty()
+		authClient.Expectations()
+	})
+
+	Context("NewRunner", func() {
+		It("returns an error if the logger is missing", func() {
+			rnnr, err := userExport.NewRunner(nil, authClient, blobClient, notificationClient, gthrr)
+			Expect(err).To(MatchError("logger is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the auth client is missing", func() {
+			rnnr, err := userExport.NewRunner(logger, nil, blobClient, notificationClient, gthrr)
+			Expect(err).To(MatchError("auth client is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the blob client is missing", func() {
+			rnnr, err := userExport.NewRunner(logger, authClient, nil, notificationClient, gthrr)
+			Expect(err).To(MatchError("blob client is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the notification client is missing", func() {
+			rnnr, err := userExport.NewRunner(logger, authClient, blobClient, nil, gthrr)
+			Expect(err).To(MatchError("notification client is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns an error if the gatherer is missing", func() {
+			rnnr, err := userExport.NewRunner(logger, authClient, blobClient, notificationClient, nil)
+			Expect(err).To(MatchError("gatherer is missing"))
+			Expect(rnnr).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			Expect(userExport.NewRunner(logger, authClient, blobClient, notificationClient, gthrr)).ToNot(BeNil())
+		})
+	})
+
+	Context("with new runner", func() {
+		var rnnr *userExport.Runner
+
+		BeforeEach(func() {
+			var err error
+			rnnr, err = userExport.NewRunner(logger, authClient, blobClient, notificationClient, gthrr)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rnnr).ToNot(BeNil())
+		})
+
+		Context("CanRunTask", func() {
+			It("returns false if the task is missing", func() {
+				Expect(rnnr.CanRunTask(nil)).To(BeFalse())
+			})
+
+			It("returns false if the task type does not match", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: test.RandomString()})).To(BeFalse())
+			})
+
+			It("returns true if the task type matches", func() {
+				Expect(rnnr.CanRunTask(&task.Task{Type: userExport.Type})).To(BeTrue())
+			})
+		})
+
+		Context("Run", func() {
+			var ctx context.Context
+			var userID string
+			var tsk *task.Task
+			var serverSessionToken string
+
+			BeforeEach(func() {
+				ctx = context.Background()
+				userID = user.NewID()
+				taskCreate, err := userExport.NewTaskCreate(userID)
+				Expect(err).ToNot(HaveOccurred())
+				tsk, err = task.NewTask(taskCreate)
+				Expect(err).ToNot(HaveOccurred())
+				tsk.State = task.TaskStateRunning
+				serverSessionToken = authTest.NewSessionToken()
+			})
+
+			It("records the error if the user id is missing", func() {
+				delete(tsk.Data, "userId")
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.Error.Error).To(MatchError("user id is missing"))
+				Expect(tsk.State).To(Equal(task.TaskStateRunning))
+			})
+
+			It("records the error and retries if the server session token returns an error", func() {
+				authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: "", Error: errorsTest.NewError()}}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.State).To(Equal(task.TaskStatePending))
+				Expect(tsk.Data).To(HaveKeyWithValue("errorCount", 1))
+			})
+
+			It("records the error and fails after the maximum attempts", func() {
+				tsk.Data["errorCount"] = float64(userExport.AttemptsMaximum - 1)
+				authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: "", Error: errorsTest.NewError()}}
+				rnnr.Run(ctx, tsk)
+				Expect(tsk.HasError()).To(BeTrue())
+				Expect(tsk.State).To(Equal(task.TaskStateFailed))
+			})
+
+			Context("with server session token", func() {
+				BeforeEach(func() {
+					authClient.ServerSessionTokenOutputs = []authTest.ServerSessionTokenOutput{{Token: serverSessionToken, Error: nil}}
+				})
+
+				It("records the error and retries if the gatherer returns an error", func() {
+					gthrr.Outputs = []error{errorsTest.NewError()}
+					rnnr.Run(ctx, tsk)
+					Expect(tsk.HasError()).To(BeTrue())
+					Expect(tsk.State).To(Equal(task.TaskStatePending))
+					Expect(tsk.Data).ToNot(HaveKey("blobId"))
+					Expect(blobClient.CreateInvocations).To(Equal(0))
+				})
+
+				Context("with blob created by a previous attempt", func() {
+					var blb *blob.Blob
+
+					BeforeEach(func() {
+						blb = blobTest.RandomBlob()
+						blb.ExpirationTime = pointer.FromTime(time.Now().Add(userExport.ExpirationDuration))
+						tsk.Data["blobId"] = *blb.ID
+						tsk.Data["errorCount"] = float64(1)
+					})
+
+					It("records the error and retries if the blob client get returns an error", func() {
+						blobClient.GetOutputs = []blobTest.GetOutput{{Blob: nil, Error: errorsTest.NewError()}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeTrue())
+						Expect(tsk.State).To(Equal(task.TaskStatePending))
+						Expect(tsk.Data).To(HaveKeyWithValue("errorCount", 2))
+					})
+
+					It("only notifies the user", func() {
+						blobClient.GetOutputs = []blobTest.GetOutput{{Blob: blb, Error: nil}}
+						blobClient.CreateLinkOutputs = []blobTest.CreateLinkOutput{{Link: blobTest.RandomLink(), Error: nil}}
+						notificationClient.CreateUserNotificationOutputs = []notificationTest.CreateUserNotificationOutput{{Notification: &notification.Notification{}, Error: nil}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeFalse())
+						Expect(tsk.State).To(Equal(task.TaskStateRunning))
+						Expect(tsk.Data).ToNot(HaveKey("errorCount"))
+						Expect(blobClient.GetInputs).To(HaveLen(1))
+						Expect(blobClient.GetInputs[0].ID).To(Equal(*blb.ID))
+						Expect(blobClient.CreateInvocations).To(Equal(0))
+						Expect(notificationClient.CreateUserNotificationInputs).To(HaveLen(1))
+						Expect(notificationClient.CreateUserNotificationInputs[0].Create.Payload["blobId"]).To(Equal(*blb.ID))
+					})
+				})
+
+				Context("with gathered user", func() {
+					var blb *blob.Blob
+					var archive []byte
+
+					BeforeEach(func() {
+						gthrr.Outputs = []error{nil}
+						blb = blobTest.RandomBlob()
+						blb.ExpirationTime = pointer.FromTime(time.Now().Add(userExport.ExpirationDuration))
+						blobClient.CreateStub = func(ctx context.Context, userID string, create *blob.Create) (*blob.Blob, error) {
+							var err error
+							archive, err = ioutil.ReadAll(create.Body)
+							Expect(err).ToNot(HaveOccurred())
+							return blb, nil
+						}
+					})
+
+					It("records the error and retries if the blob client create returns an error", func() {
+						blobClient.CreateStub = nil
+						blobClient.CreateOutputs = []blobTest.CreateOutput{{Blob: nil, Error: errorsTest.NewError()}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeTrue())
+						Expect(tsk.State).To(Equal(task.TaskStatePending))
+						Expect(tsk.Data).ToNot(HaveKey("blobId"))
+					})
+
+					It("records the error and retries if the blob client create link returns an error", func() {
+						blobClient.CreateLinkOutputs = []blobTest.CreateLinkOutput{{Link: nil, Error: errorsTest.NewError()}}
+						rnnr.Run(ctx, tsk)
+						Expect(tsk.HasError()).To(BeTrue())
+						Expect(tsk.State).To(Equal(task.TaskStatePending))
+						Expect(tsk.Data).To(HaveKeyWithValue("blobId", *blb.ID))
+						Expect(notificationClient.CreateUserNotificationInvocations).To(Equal(0))
+					})
+
+					Context("with link", func() {
+						var link *blob.Link
+
+						BeforeEach(func() {
+							link = blobTest.RandomLink()
+							blobClient.CreateLinkOutputs = []blobTest.CreateLinkOutput{{Link: link, Error: nil}}
+						})
+
+						It("records the error if the notification client returns an error", func() {
+							notificationClient.CreateUserNotificationOutputs = []notificationTest.CreateUserNotificationOutput{{Notification: nil, Error: errorsTest.NewError()}}
+							rnnr.Run(ctx, tsk)
+							Expect(tsk.HasError()).To(BeTrue())
+							Expect(tsk.Data).To(HaveKeyWithValue("blobId", *blb.ID))
+						})
+
+						It("creates the export blob and notifies the user", func() {
+							notificationClient.CreateUserNotificationOutputs = []notificationTest.CreateUserNotificationOutput{{Notification: &notification.Notification{}, Error: nil}}
+							rnnr.Run(ctx, tsk)
+							Expect(tsk.HasError()).To(BeFalse())
+							Expect(tsk.State).To(Equal(task.TaskStateRunning))
+							Expect(tsk.Data).To(HaveKeyWithValue("blobId", *blb.ID))
+
+							Expect(gthrr.UserIDs).To(Equal([]string{userID}))
+							Expect(log.LoggerFromContext(gthrr.Contexts[0])).To(Equal(logger))
+							Expect(auth.ServerSessionTokenFromContext(gthrr.Contexts[0])).To(Equal(serverSessionToken))
+
+							Expect(blobClient.CreateInputs).To(HaveLen(1))
+							create := blobClient.CreateInputs[0].Create
+							Expect(blobClient.CreateInputs[0].UserID).To(Equal(userID))
+							Expect(create.MediaType).To(Equal(pointer.FromString(userExport.MediaType)))
+							Expect(create.DigestMD5).To(Equal(pointer.FromString(crypto.Base64EncodedMD5Hash(archive))))
+							Expect(create.SkipInspection).To(Equal(pointer.FromBool(true)))
+							Expect(create.ExpirationTime).ToNot(BeNil())
+							Expect(*create.ExpirationTime).To(BeTemporally("~", time.Now().Add(userExport.ExpirationDuration), time.Minute))
+							Expect(readArchive(archive)).To(Equal(map[string]string{
+								"user.json": "{\n  \"userid\": \"" + userID + "\"\n}\n",
+							}))
+
+							Expect(blobClient.CreateLinkInputs).To(HaveLen(1))
+							Expect(blobClient.CreateLinkInputs[0].ID).To(Equal(*blb.ID))
+							Expect(blobClient.CreateLinkInputs[0].Create.ExpirationTime).To(Equal(blb.ExpirationTime))
+
+							Expect(notificationClient.CreateUserNotificationInputs).To(HaveLen(1))
+							Expect(notificationClient.CreateUserNotificationInputs[0].UserID).To(Equal(userID))
+							Expect(notificationClient.CreateUserNotificationInputs[0].Create).To(Equal(&notification.NotificationCreate{
+								Type:           notification.TypeUserExport,
+								Payload:        map[string]interface{}{"blobId": *blb.ID, "link": *link.URL},
+								ExpirationTime: blb.ExpirationTime,
+							}))
+						})
+					})
+				})
+			})
+		})
+	})
+})
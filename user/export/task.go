@@ -0,0 +1,52 @@
+package export
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tidepool-org/platform/errors"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+)
+
+func TaskName(userID string) string {
+	return fmt.Sprintf("%s:%s", Type, userID)
+}
+
+func NewTaskCreate(userID string) (*task.TaskCreate, error) {
+	if userID == "" {
+		return nil, errors.New("user id is missing")
+	}
+
+	return &task.TaskCreate{
+		Name: pointer.FromString(TaskName(userID)),
+		Type: Type,
+		Data: map[string]interface{}{
+			"userId": userID,
+		},
+	}, nil
+}
+
+// Status is the status of an export task as reported to the user, without internal task details
+type Status struct {
+	State        string     `json:"state,omitempty"`
+	BlobID       *string    `json:"blobId,omitempty"`
+	CreatedTime  time.Time  `json:"createdTime,omitempty"`
+	ModifiedTime *time.Time `json:"modifiedTime,omitempty"`
+}
+
+func NewStatus(tsk *task.Task) *Status {
+	if tsk == nil {
+		return nil
+	}
+
+	status := &Status{
+		State:        tsk.State,
+		CreatedTime:  tsk.CreatedTime,
+		ModifiedTime: tsk.ModifiedTime,
+	}
+	if blobID, ok := tsk.Data["blobId"].(string); ok && blobID != "" {
+		status.BlobID = pointer.FromString(blobID)
+	}
+	return status
+}
@@ -0,0 +1,79 @@
+package export_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"time"
+
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+	"github.com/tidepool-org/platform/user"
+	userExport "github.com/tidepool-org/platform/user/export"
+)
+
+var _ = Describe("Task", func() {
+	var userID string
+
+	BeforeEach(func() {
+		userID = user.NewID()
+	})
+
+	Context("TaskName", func() {
+		It("returns the type and user id", func() {
+			Expect(userExport.TaskName(userID)).To(Equal(userExport.Type + ":" + userID))
+		})
+	})
+
+	Context("NewTaskCreate", func() {
+		It("returns an error if the user id is missing", func() {
+			taskCreate, err := userExport.NewTaskCreate("")
+			Expect(err).To(MatchError("user id is missing"))
+			Expect(taskCreate).To(BeNil())
+		})
+
+		It("returns successfully", func() {
+			taskCreate, err := userExport.NewTaskCreate(userID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(taskCreate).ToNot(BeNil())
+			Expect(taskCreate.Name).ToNot(BeNil())
+			Expect(*taskCreate.Name).To(Equal(userExport.TaskName(userID)))
+			Expect(taskCreate.Type).To(Equal(userExport.Type))
+			Expect(taskCreate.Data).To(Equal(map[string]interface{}{"userId": userID}))
+		})
+	})
+	Context("NewStatus", func() {
+		var tsk *task.Task
+
+		BeforeEach(func() {
+			taskCreate, err := userExport.NewTaskCreate(userID)
+			Expect(err).ToNot(HaveOccurred())
+			tsk, err = task.NewTask(taskCreate)
+			Expect(err).ToNot(HaveOccurred())
+			tsk.ModifiedTime = pointer.FromTime(time.Now())
+		})
+
+		It("returns nil if the task is missing", func() {
+			Expect(userExport.NewStatus(nil)).To(BeNil())
+		})
+
+		It("returns the status without blob id if the task does not have one", func() {
+			Expect(userExport.NewStatus(tsk)).To(Equal(&userExport.Status{
+				State:        task.TaskStatePending,
+				CreatedTime:  tsk.CreatedTime,
+				ModifiedTime: tsk.ModifiedTime,
+			}))
+		})
+
+		It("returns the status with blob id if the task has one", func() {
+			tsk.State = task.TaskStateCompleted
+			tsk.Data["blobId"] = "1234567890abcdef1234567890abcdef"
+			Expect(userExport.NewStatus(tsk)).To(Equal(&userExport.Status{
+				State:        task.TaskStateCompleted,
+				BlobID:       pointer.FromString("1234567890abcdef1234567890abcdef"),
+				CreatedTime:  tsk.CreatedTime,
+				ModifiedTime: tsk.ModifiedTime,
+			}))
+		})
+	})
+})
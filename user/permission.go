@@ -1,5 +1,13 @@
 package user
 
+import (
+	"time"
+
+	"github.com/tidepool-org/platform/structure"
+	structureParser "github.com/tidepool-org/platform/structure/parser"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+)
+
 type Permission map[string]interface{}
 type Permissions map[string]Permission
 
@@ -7,3 +15,94 @@ const OwnerPermission = "root"
 const CustodianPermission = "custodian"
 const UploadPermission = "upload"
 const ViewPermission = "view"
+
+const PermissionRestrictionsTimeFormat = time.RFC3339Nano
+
+// Granted returns the permissions granted at the specified time. A permission is not granted once expired or if the
+// restrictions are not valid.
+func (p Permissions) Granted(now time.Time) Permissions {
+	if p == nil {
+		return nil
+	}
+
+	granted := Permissions{}
+	for key, permission := range p {
+		if restrictions, err := ParsePermissionRestrictions(permission); err == nil && !restrictions.IsExpired(now) {
+			granted[key] = permission
+		}
+	}
+	return granted
+}
+
+// PermissionRestrictions are optionally stored in a permission granted to another user. The permission is not
+// granted after the expiration time. The permission only grants access to data within the start and end time, if
+// specified, and of the data types, if specified.
+type PermissionRestrictions struct {
+	ExpirationTime *time.Time `json:"expirationTime,omitempty"`
+	StartTime      *time.Time `json:"startTime,omitempty"`
+	EndTime        *time.Time `json:"endTime,omitempty"`
+	DataTypes      *[]string  `json:"dataTypes,omitempty"`
+}
+
+func NewPermissionRestrictions() *PermissionRestrictions {
+	return &PermissionRestrictions{}
+}
+
+// ParsePermissionRestrictions parses the restrictions from the permission; any other fields in the permission are
+// ignored for compatibility with legacy permissions
+func ParsePermissionRestrictions(permission Permission) (*PermissionRestrictions, error) {
+	object := map[string]interface{}(permission)
+	if object == nil {
+		object = map[string]interface{}{}
+	}
+
+	restrictions := NewPermissionRestrictions()
+	parser := structureParser.NewObject(&object)
+	restrictions.Parse(parser)
+	if err := parser.Error(); err != nil {
+		return nil, err
+	}
+	if err := structureValidator.New().Validate(restrictions); err != nil {
+		return nil, err
+	}
+
+	return restrictions, nil
+}
+
+func (p *PermissionRestrictions) Parse(parser structure.ObjectParser) {
+	p.ExpirationTime = parser.Time("expirationTime", PermissionRestrictionsTimeFormat)
+	p.StartTime = parser.Time("startTime", PermissionRestrictionsTimeFormat)
+	p.EndTime = parser.Time("endTime", PermissionRestrictionsTimeFormat)
+	p.DataTypes = parser.StringArray("dataTypes")
+}
+
+func (p *PermissionRestrictions) Validate(validator structure.Validator) {
+	if p.StartTime != nil {
+		validator.Time("endTime", p.EndTime).After(*p.StartTime)
+	}
+	validator.StringArray("dataTypes", p.DataTypes).NotEmpty().EachNotEmpty().EachUnique()
+}
+
+func (p *PermissionRestrictions) IsExpired(now time.Time) bool {
+	return p.ExpirationTime != nil && !now.Before(*p.ExpirationTime)
+}
+
+func (p *PermissionRestrictions) IsRestricted() bool {
+	return p.StartTime != nil || p.EndTime != nil || p.DataTypes != nil
+}
+
+func (p *PermissionRestrictions) IsTimeAllowed(tm time.Time) bool {
+	return (p.StartTime == nil || !tm.Before(*p.StartTime)) && (p.EndTime == nil || tm.Before(*p.EndTime))
+}
+
+func (p *PermissionRestrictions) IsDataTypeAllowed(dataType string) bool {
+	if p.DataTypes == nil {
+		return true
+	}
+	for _, allowedDataType := range *p.DataTypes {
+		if dataType == allowedDataType {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,9 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"time"
+
+	"github.com/tidepool-org/platform/pointer"
 	"github.com/tidepool-org/platform/user"
 )
 
@@ -32,3 +35,111 @@ var _ = Describe("Permission", func() {
 		})
 	})
 })
+
+var _ = Describe("Permissions", func() {
+	Context("Granted", func() {
+		var now time.Time
+
+		BeforeEach(func() {
+			now = time.Now().UTC().Truncate(time.Second)
+		})
+
+		It("returns nil if nil", func() {
+			Expect(user.Permissions(nil).Granted(now)).To(BeNil())
+		})
+
+		It("returns the permissions that are not expired and have valid restrictions", func() {
+			permissions := user.Permissions{
+				user.CustodianPermission: user.Permission{"expirationTime": now.Format(time.RFC3339)},
+				user.UploadPermission:    user.Permission{"expirationTime": "invalid"},
+				user.ViewPermission:      user.Permission{"expirationTime": now.Add(time.Second).Format(time.RFC3339), "legacy": true},
+				user.OwnerPermission:     user.Permission{},
+			}
+			Expect(permissions.Granted(now)).To(Equal(user.Permissions{
+				user.ViewPermission:  permissions[user.ViewPermission],
+				user.OwnerPermission: permissions[user.OwnerPermission],
+			}))
+		})
+	})
+})
+
+var _ = Describe("PermissionRestrictions", func() {
+	var now time.Time
+
+	BeforeEach(func() {
+		now = time.Now().UTC().Truncate(time.Second)
+	})
+
+	Context("ParsePermissionRestrictions", func() {
+		It("returns no restrictions if the permission is nil", func() {
+			restrictions, err := user.ParsePermissionRestrictions(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restrictions).To(Equal(user.NewPermissionRestrictions()))
+			Expect(restrictions.IsRestricted()).To(BeFalse())
+		})
+
+		It("returns the restrictions from the permission", func() {
+			restrictions, err := user.ParsePermissionRestrictions(user.Permission{
+				"expirationTime": now.Add(24 * time.Hour).Format(time.RFC3339),
+				"startTime":      "2018-01-01T00:00:00Z",
+				"endTime":        "2018-07-01T00:00:00.000Z",
+				"dataTypes":      []interface{}{"cbg", "smbg"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restrictions.ExpirationTime).To(Equal(pointer.FromTime(now.Add(24 * time.Hour))))
+			Expect(restrictions.StartTime).To(Equal(pointer.FromTime(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))))
+			Expect(restrictions.EndTime).To(Equal(pointer.FromTime(time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC))))
+			Expect(restrictions.DataTypes).To(Equal(pointer.FromStringArray([]string{"cbg", "smbg"})))
+			Expect(restrictions.IsRestricted()).To(BeTrue())
+		})
+
+		It("returns an error if a time is not parsable", func() {
+			restrictions, err := user.ParsePermissionRestrictions(user.Permission{"startTime": "invalid"})
+			Expect(err).To(HaveOccurred())
+			Expect(restrictions).To(BeNil())
+		})
+
+		It("returns an error if the end time is before the start time", func() {
+			restrictions, err := user.ParsePermissionRestrictions(user.Permission{"startTime": "2018-07-01T00:00:00Z", "endTime": "2018-01-01T00:00:00Z"})
+			Expect(err).To(HaveOccurred())
+			Expect(restrictions).To(BeNil())
+		})
+
+		It("returns an error if the data types are empty", func() {
+			restrictions, err := user.ParsePermissionRestrictions(user.Permission{"dataTypes": []interface{}{}})
+			Expect(err).To(HaveOccurred())
+			Expect(restrictions).To(BeNil())
+		})
+	})
+
+	Context("with restrictions", func() {
+		var restrictions *user.PermissionRestrictions
+
+		BeforeEach(func() {
+			restrictions = user.NewPermissionRestrictions()
+			restrictions.ExpirationTime = pointer.FromTime(now)
+			restrictions.StartTime = pointer.FromTime(now.Add(-time.Hour))
+			restrictions.EndTime = pointer.FromTime(now.Add(time.Hour))
+			restrictions.DataTypes = pointer.FromStringArray([]string{"cbg"})
+		})
+
+		It("IsExpired returns true once expired", func() {
+			Expect(restrictions.IsExpired(now.Add(-time.Second))).To(BeFalse())
+			Expect(restrictions.IsExpired(now)).To(BeTrue())
+		})
+
+		It("IsTimeAllowed returns true if within the start and end time", func() {
+			Expect(restrictions.IsTimeAllowed(now.Add(-time.Hour - time.Second))).To(BeFalse())
+			Expect(restrictions.IsTimeAllowed(now.Add(-time.Hour))).To(BeTrue())
+			Expect(restrictions.IsTimeAllowed(now.Add(time.Hour - time.Second))).To(BeTrue())
+			Expect(restrictions.IsTimeAllowed(now.Add(time.Hour))).To(BeFalse())
+		})
+
+		It("IsDataTypeAllowed returns true if one of the data types", func() {
+			Expect(restrictions.IsDataTypeAllowed("cbg")).To(BeTrue())
+			Expect(restrictions.IsDataTypeAllowed("pumpSettings")).To(BeFalse())
+			restrictions.DataTypes = nil
+			Expect(restrictions.IsDataTypeAllowed("pumpSettings")).To(BeTrue())
+		})
+	})
+})
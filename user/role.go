@@ -1,3 +1,8 @@
 package user
 
-const ClinicRole string = "clinic"
+// The admin role is not granted by any user API. It is added by an operator with a server session, for example with
+// `tapi user add-role --role admin`.
+const (
+	AdminRole  string = "admin"
+	ClinicRole string = "clinic"
+)
@@ -8,6 +8,12 @@ import (
 )
 
 var _ = Describe("Role", func() {
+	Context("AdminRole", func() {
+		It("exists", func() {
+			Expect(user.AdminRole).To(Equal("admin"))
+		})
+	})
+
 	Context("ClinicRole", func() {
 		It("exists", func() {
 			Expect(user.ClinicRole).To(Equal("clinic"))
@@ -13,6 +13,7 @@ import (
 	"github.com/tidepool-org/platform/service"
 	"github.com/tidepool-org/platform/service/api"
 	sessionStore "github.com/tidepool-org/platform/session/store"
+	"github.com/tidepool-org/platform/task"
 	"github.com/tidepool-org/platform/user"
 	userService "github.com/tidepool-org/platform/user/service"
 	userContext "github.com/tidepool-org/platform/user/service/context"
@@ -24,6 +25,7 @@ type Standard struct {
 	dataClient        dataClient.Client
 	metricClient      metric.Client
 	userClient        user.Client
+	taskClient        task.Client
 	confirmationStore confirmationStore.Store
 	messageStore      messageStore.Store
 	permissionStore   permissionStore.Store
@@ -32,7 +34,7 @@ type Standard struct {
 	userStore         userStore.Store
 }
 
-func NewStandard(svc service.Service, dataClient dataClient.Client, metricClient metric.Client, userClient user.Client,
+func NewStandard(svc service.Service, dataClient dataClient.Client, metricClient metric.Client, userClient user.Client, taskClient task.Client,
 	confirmationStore confirmationStore.Store, messageStore messageStore.Store, permissionStore permissionStore.Store,
 	profileStore profileStore.Store, sessionStore sessionStore.Store, userStore userStore.Store) (*Standard, error) {
 	if dataClient == nil {
@@ -44,6 +46,9 @@ func NewStandard(svc service.Service, dataClient dataClient.Client, metricClient
 	if userClient == nil {
 		return nil, errors.New("user client is missing")
 	}
+	if taskClient == nil {
+		return nil, errors.New("task client is missing")
+	}
 	if confirmationStore == nil {
 		return nil, errors.New("confirmation store is missing")
 	}
@@ -73,6 +78,7 @@ func NewStandard(svc service.Service, dataClient dataClient.Client, metricClient
 		dataClient:        dataClient,
 		metricClient:      metricClient,
 		userClient:        userClient,
+		taskClient:        taskClient,
 		confirmationStore: confirmationStore,
 		messageStore:      messageStore,
 		permissionStore:   permissionStore,
@@ -110,7 +116,7 @@ func (s *Standard) DEPRECATEDInitializeRouter(routes []userService.Route) error
 }
 
 func (s *Standard) withContext(handler userService.HandlerFunc) rest.HandlerFunc {
-	return userContext.WithContext(s.AuthClient(), s.dataClient, s.metricClient, s.userClient,
+	return userContext.WithContext(s.AuthClient(), s.dataClient, s.metricClient, s.userClient, s.taskClient,
 		s.confirmationStore, s.messageStore, s.permissionStore, s.profileStore,
 		s.sessionStore, s.userStore, handler)
 }
@@ -24,3 +24,57 @@ func ErrorUserIDNotFound(userID string) *service.Error {
 		Detail: fmt.Sprintf("User with id %s not found", userID),
 	}
 }
+
+func ErrorPermissionNotValid(permission string) *service.Error {
+	return &service.Error{
+		Code:   "permission-not-valid",
+		Status: http.StatusBadRequest,
+		Title:  "permission is not valid",
+		Detail: fmt.Sprintf("Permission %q is not valid", permission),
+	}
+}
+
+func ErrorPermissionSelfNotValid() *service.Error {
+	return &service.Error{
+		Code:   "permission-self-not-valid",
+		Status: http.StatusBadRequest,
+		Title:  "cannot grant permissions to self",
+		Detail: "Cannot grant or revoke permissions for a user to themselves",
+	}
+}
+
+func ErrorEmailNotValid(email string) *service.Error {
+	return &service.Error{
+		Code:   "email-not-valid",
+		Status: http.StatusBadRequest,
+		Title:  "email is not valid",
+		Detail: fmt.Sprintf("Email %q is not valid", email),
+	}
+}
+
+func ErrorInvitationKeyNotFound(key string) *service.Error {
+	return &service.Error{
+		Code:   "invitation-key-not-found",
+		Status: http.StatusNotFound,
+		Title:  "invitation with specified key not found",
+		Detail: fmt.Sprintf("Invitation with key %s not found", key),
+	}
+}
+
+func ErrorExportNotFound(userID string) *service.Error {
+	return &service.Error{
+		Code:   "export-not-found",
+		Status: http.StatusNotFound,
+		Title:  "export for specified user not found",
+		Detail: fmt.Sprintf("Export for user with id %s not found", userID),
+	}
+}
+
+func ErrorDeletionNotFound(userID string) *service.Error {
+	return &service.Error{
+		Code:   "deletion-not-found",
+		Status: http.StatusNotFound,
+		Title:  "deletion for specified user not found",
+		Detail: fmt.Sprintf("Deletion for user with id %s not found", userID),
+	}
+}
@@ -32,4 +32,74 @@ var _ = Describe("Errors", func() {
 				}))
 		})
 	})
+
+	Context("ErrorPermissionNotValid", func() {
+		It("matches the expected error", func() {
+			Expect(v1.ErrorPermissionNotValid("root")).To(Equal(
+				&service.Error{
+					Code:   "permission-not-valid",
+					Status: 400,
+					Title:  "permission is not valid",
+					Detail: `Permission "root" is not valid`,
+				}))
+		})
+	})
+
+	Context("ErrorPermissionSelfNotValid", func() {
+		It("matches the expected error", func() {
+			Expect(v1.ErrorPermissionSelfNotValid()).To(Equal(
+				&service.Error{
+					Code:   "permission-self-not-valid",
+					Status: 400,
+					Title:  "cannot grant permissions to self",
+					Detail: "Cannot grant or revoke permissions for a user to themselves",
+				}))
+		})
+	})
+
+	Context("ErrorEmailNotValid", func() {
+		It("matches the expected error", func() {
+			Expect(v1.ErrorEmailNotValid("invalid")).To(Equal(
+				&service.Error{
+					Code:   "email-not-valid",
+					Status: 400,
+					Title:  "email is not valid",
+					Detail: `Email "invalid" is not valid`,
+				}))
+		})
+	})
+
+	Context("ErrorInvitationKeyNotFound", func() {
+		It("matches the expected error", func() {
+			Expect(v1.ErrorInvitationKeyNotFound("1234567890abcdef")).To(Equal(
+				&service.Error{
+					Code:   "invitation-key-not-found",
+					Status: 404,
+					Title:  "invitation with specified key not found",
+					Detail: "Invitation with key 1234567890abcdef not found",
+				}))
+		})
+	})
+	Context("ErrorExportNotFound", func() {
+		It("matches the expected error", func() {
+			Expect(v1.ErrorExportNotFound("1234567890")).To(Equal(
+				&service.Error{
+					Code:   "export-not-found",
+					Status: 404,
+					Title:  "export for specified user not found",
+					Detail: "Export for user with id 1234567890 not found",
+				}))
+		})
+	})
+	Context("ErrorDeletionNotFound", func() {
+		It("matches the expected error", func() {
+			Expect(v1.ErrorDeletionNotFound("1234567890")).To(Equal(
+				&service.Error{
+					Code:   "deletion-not-found",
+					Status: 404,
+					Title:  "deletion for specified user not found",
+					Detail: "Deletion for user with id 1234567890 not found",
+				}))
+		})
+	})
 })
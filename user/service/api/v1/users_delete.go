@@ -6,10 +6,10 @@ import (
 	"github.com/tidepool-org/platform/log"
 	"github.com/tidepool-org/platform/request"
 
-	messageStore "github.com/tidepool-org/platform/message/store"
-	"github.com/tidepool-org/platform/profile"
 	"github.com/tidepool-org/platform/service"
+	"github.com/tidepool-org/platform/task"
 	"github.com/tidepool-org/platform/user"
+	userDeletion "github.com/tidepool-org/platform/user/deletion"
 	userService "github.com/tidepool-org/platform/user/service"
 )
 
@@ -17,6 +17,8 @@ type UsersDeleteParameters struct {
 	Password string `json:"password,omitempty"`
 }
 
+// UsersDelete marks the user deleted and requests the deletion of all information held about the user. A failed
+// deletion is resumed from the steps it completed.
 func UsersDelete(userServiceContext userService.Context) {
 	ctx := userServiceContext.Request().Context()
 	lgr := log.LoggerFromContext(ctx)
@@ -76,72 +78,72 @@ func UsersDelete(userServiceContext userService.Context) {
 		}
 	}
 
-	messageUser := &messageStore.User{
-		ID: targetUserID,
+	if err = userServiceContext.MetricClient().RecordMetric(ctx, "users_delete", map[string]string{"userId": targetUserID}); err != nil {
+		lgr.WithError(err).Error("Unable to record metric")
 	}
 
-	if targetUser.ProfileID != nil {
-		var profile *profile.Profile
-		profile, err = userServiceContext.ProfilesSession().GetProfileByID(ctx, *targetUser.ProfileID)
-		if err != nil {
-			userServiceContext.RespondWithInternalServerFailure("Unable to get profile by id", err)
+	if targetUser.DeletedTime == "" {
+		if err = userServiceContext.UsersSession().DeleteUser(ctx, targetUser); err != nil {
+			userServiceContext.RespondWithInternalServerFailure("Unable to delete user", err)
 			return
 		}
-		if profile != nil && profile.FullName != nil {
-			messageUser.FullName = *profile.FullName
-		}
 	}
 
-	if err = userServiceContext.MetricClient().RecordMetric(ctx, "users_delete", map[string]string{"userId": targetUserID}); err != nil {
-		lgr.WithError(err).Error("Unable to record metric")
-	}
-
-	if err = userServiceContext.UsersSession().DeleteUser(ctx, targetUser); err != nil {
-		userServiceContext.RespondWithInternalServerFailure("Unable to delete user", err)
+	tsk, ok := getTaskByName(userServiceContext, userDeletion.TaskName(targetUserID))
+	if !ok {
 		return
 	}
 
-	if err = userServiceContext.SessionsSession().DestroySessionsForUserByID(ctx, targetUserID); err != nil {
-		userServiceContext.RespondWithInternalServerFailure("Unable to destroy sessions for user by id", err)
-		return
+	var completedSteps []string
+	if tsk != nil {
+		if tsk.State == task.TaskStatePending || tsk.State == task.TaskStateRunning {
+			userServiceContext.RespondWithStatusAndData(http.StatusAccepted, userDeletion.NewStatus(tsk))
+			return
+		}
+		if tsk.State == task.TaskStateFailed {
+			completedSteps = userDeletion.CompletedSteps(tsk)
+		}
+		if err = userServiceContext.TaskClient().DeleteTask(ctx, tsk.ID); err != nil {
+			userServiceContext.RespondWithInternalServerFailure("Unable to delete task", err)
+			return
+		}
 	}
 
-	if err = userServiceContext.PermissionsSession().DestroyPermissionsForUserByID(ctx, targetUserID); err != nil {
-		userServiceContext.RespondWithInternalServerFailure("Unable to destroy permissions for user by id", err)
+	taskCreate, err := userDeletion.NewTaskCreate(targetUserID, completedSteps)
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to create task create", err)
 		return
 	}
 
-	if err = userServiceContext.ConfirmationSession().DeleteUserConfirmations(ctx, targetUserID); err != nil {
-		userServiceContext.RespondWithInternalServerFailure("Unable to destroy confirmations for user by id", err)
+	tsk, err = userServiceContext.TaskClient().CreateTask(ctx, taskCreate)
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to create task", err)
 		return
 	}
 
-	if err = userServiceContext.DataClient().DestroyDataForUserByID(ctx, targetUserID); err != nil {
-		userServiceContext.RespondWithInternalServerFailure("Unable to destroy data for user by id", err)
-		return
-	}
+	userServiceContext.RespondWithStatusAndData(http.StatusAccepted, userDeletion.NewStatus(tsk))
+}
 
-	if err = userServiceContext.MessagesSession().DestroyMessagesForUserByID(ctx, targetUserID); err != nil {
-		userServiceContext.RespondWithInternalServerFailure("Unable to destroy messages for user by id", err)
+// UsersDeletionGet returns the status of the deletion of the user
+func UsersDeletionGet(userServiceContext userService.Context) {
+	targetUserID := userServiceContext.Request().PathParam("userId")
+	if targetUserID == "" {
+		userServiceContext.RespondWithError(ErrorUserIDMissing())
 		return
 	}
 
-	if err = userServiceContext.MessagesSession().DeleteMessagesFromUser(ctx, messageUser); err != nil {
-		userServiceContext.RespondWithInternalServerFailure("Unable to delete messages from user", err)
+	if !authorizeOwnerOrCustodian(userServiceContext, targetUserID) {
 		return
 	}
 
-	if targetUser.ProfileID != nil {
-		if err = userServiceContext.ProfilesSession().DestroyProfileByID(ctx, *targetUser.ProfileID); err != nil {
-			userServiceContext.RespondWithInternalServerFailure("Unable to destroy profile by id", err)
-			return
-		}
+	tsk, ok := getTaskByName(userServiceContext, userDeletion.TaskName(targetUserID))
+	if !ok {
+		return
 	}
-
-	if err = userServiceContext.UsersSession().DestroyUserByID(ctx, targetUserID); err != nil {
-		userServiceContext.RespondWithInternalServerFailure("Unable to destroy user by id", err)
+	if tsk == nil {
+		userServiceContext.RespondWithError(ErrorDeletionNotFound(targetUserID))
 		return
 	}
 
-	userServiceContext.RespondWithStatusAndData(http.StatusOK, struct{}{})
+	userServiceContext.RespondWithStatusAndData(http.StatusOK, userDeletion.NewStatus(tsk))
 }
@@ -0,0 +1,98 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/task"
+	userExport "github.com/tidepool-org/platform/user/export"
+	userService "github.com/tidepool-org/platform/user/service"
+)
+
+// UsersExportCreate requests an export of all information held about the user. An export already pending or running
+// is returned rather than requesting another.
+func UsersExportCreate(userServiceContext userService.Context) {
+	ctx := userServiceContext.Request().Context()
+
+	userID := userServiceContext.Request().PathParam("userId")
+	if userID == "" {
+		userServiceContext.RespondWithError(ErrorUserIDMissing())
+		return
+	}
+
+	if !authorizeOwner(userServiceContext, userID) {
+		return
+	}
+	if usr := getUser(userServiceContext, userID); usr == nil {
+		return
+	}
+
+	tsk, ok := getTaskByName(userServiceContext, userExport.TaskName(userID))
+	if !ok {
+		return
+	}
+	if tsk != nil {
+		if tsk.State == task.TaskStatePending || tsk.State == task.TaskStateRunning {
+			userServiceContext.RespondWithStatusAndData(http.StatusAccepted, userExport.NewStatus(tsk))
+			return
+		}
+		if err := userServiceContext.TaskClient().DeleteTask(ctx, tsk.ID); err != nil {
+			userServiceContext.RespondWithInternalServerFailure("Unable to delete task", err)
+			return
+		}
+	}
+
+	taskCreate, err := userExport.NewTaskCreate(userID)
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to create task create", err)
+		return
+	}
+
+	tsk, err = userServiceContext.TaskClient().CreateTask(ctx, taskCreate)
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to create task", err)
+		return
+	}
+
+	userServiceContext.RespondWithStatusAndData(http.StatusAccepted, userExport.NewStatus(tsk))
+}
+
+// UsersExportGet returns the status of the latest export of the user
+func UsersExportGet(userServiceContext userService.Context) {
+	userID := userServiceContext.Request().PathParam("userId")
+	if userID == "" {
+		userServiceContext.RespondWithError(ErrorUserIDMissing())
+		return
+	}
+
+	if !authorizeOwner(userServiceContext, userID) {
+		return
+	}
+
+	tsk, ok := getTaskByName(userServiceContext, userExport.TaskName(userID))
+	if !ok {
+		return
+	}
+	if tsk == nil {
+		userServiceContext.RespondWithError(ErrorExportNotFound(userID))
+		return
+	}
+
+	userServiceContext.RespondWithStatusAndData(http.StatusOK, userExport.NewStatus(tsk))
+}
+
+// Task names are unique, so there is at most one task with the name
+func getTaskByName(userServiceContext userService.Context, name string) (*task.Task, bool) {
+	filter := task.NewTaskFilter()
+	filter.Name = pointer.FromString(name)
+
+	tsks, err := userServiceContext.TaskClient().ListTasks(userServiceContext.Request().Context(), filter, nil)
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to list tasks", err)
+		return nil, false
+	}
+	if len(tsks) == 0 {
+		return nil, true
+	}
+	return tsks[0], true
+}
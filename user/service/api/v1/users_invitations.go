@@ -0,0 +1,275 @@
+package v1
+
+import (
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/tidepool-org/platform/confirmation"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/service"
+	"github.com/tidepool-org/platform/user"
+	userService "github.com/tidepool-org/platform/user/service"
+)
+
+type UsersInvitationsCreateParameters struct {
+	Email       string           `json:"email,omitempty"`
+	Permissions user.Permissions `json:"permissions,omitempty"`
+}
+
+// UsersInvitationsCreate invites the owner of an email address to be granted permissions for the target user. The
+// invitation is only recorded; the invitation email is still sent by the legacy confirmation service.
+func UsersInvitationsCreate(userServiceContext userService.Context) {
+	ctx := userServiceContext.Request().Context()
+
+	targetUserID := userServiceContext.Request().PathParam("userId")
+	if targetUserID == "" {
+		userServiceContext.RespondWithError(ErrorUserIDMissing())
+		return
+	}
+
+	if !authorizeOwnerOrCustodian(userServiceContext, targetUserID) {
+		return
+	}
+
+	var parameters UsersInvitationsCreateParameters
+	if err := userServiceContext.Request().DecodeJsonPayload(&parameters); err != nil {
+		userServiceContext.RespondWithError(service.ErrorJSONMalformed())
+		return
+	}
+	if address, err := mail.ParseAddress(parameters.Email); err != nil || address.Address != parameters.Email {
+		userServiceContext.RespondWithError(ErrorEmailNotValid(parameters.Email))
+		return
+	}
+	if len(parameters.Permissions) == 0 {
+		userServiceContext.RespondWithError(ErrorPermissionNotValid(""))
+		return
+	}
+	if err := validatePermissions(parameters.Permissions); err != nil {
+		userServiceContext.RespondWithError(err)
+		return
+	}
+	if hasCustodianPermission(parameters.Permissions) && !authorizeOwner(userServiceContext, targetUserID) {
+		return
+	}
+
+	create := confirmation.NewConfirmationCreate()
+	create.Type = confirmation.TypeCareteamInvitation
+	create.Email = strings.ToLower(parameters.Email)
+	create.CreatorID = targetUserID
+	create.Permissions = parameters.Permissions
+
+	invitation, err := userServiceContext.ConfirmationSession().CreateConfirmation(ctx, create)
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to create confirmation", err)
+		return
+	}
+
+	userServiceContext.RespondWithStatusAndData(http.StatusCreated, invitation)
+}
+
+// UsersInvitationsList lists the pending invitations sent for the target user
+func UsersInvitationsList(userServiceContext userService.Context) {
+	targetUserID := userServiceContext.Request().PathParam("userId")
+	if targetUserID == "" {
+		userServiceContext.RespondWithError(ErrorUserIDMissing())
+		return
+	}
+
+	if !authorizeOwnerOrCustodian(userServiceContext, targetUserID) {
+		return
+	}
+
+	filter := confirmation.NewConfirmationFilter()
+	filter.Type = pointer.FromString(confirmation.TypeCareteamInvitation)
+	filter.Status = pointer.FromString(confirmation.StatusPending)
+	filter.CreatorID = pointer.FromString(targetUserID)
+
+	invitations, err := userServiceContext.ConfirmationSession().ListConfirmations(userServiceContext.Request().Context(), filter)
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to list confirmations", err)
+		return
+	}
+
+	userServiceContext.RespondWithStatusAndData(http.StatusOK, invitations)
+}
+
+// UsersInvitationsDelete cancels a pending invitation sent for the target user
+func UsersInvitationsDelete(userServiceContext userService.Context) {
+	ctx := userServiceContext.Request().Context()
+
+	targetUserID := userServiceContext.Request().PathParam("userId")
+	if targetUserID == "" {
+		userServiceContext.RespondWithError(ErrorUserIDMissing())
+		return
+	}
+
+	if !authorizeOwnerOrCustodian(userServiceContext, targetUserID) {
+		return
+	}
+
+	invitation := getPendingInvitation(userServiceContext)
+	if invitation == nil {
+		return
+	} else if invitation.CreatorID != targetUserID {
+		userServiceContext.RespondWithError(ErrorInvitationKeyNotFound(invitation.Key))
+		return
+	}
+
+	update := confirmation.NewConfirmationUpdate()
+	update.Status = pointer.FromString(confirmation.StatusCanceled)
+	if _, err := userServiceContext.ConfirmationSession().UpdateConfirmation(ctx, invitation.Key, update); err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to update confirmation", err)
+		return
+	}
+
+	userServiceContext.RespondWithStatusAndData(http.StatusOK, struct{}{})
+}
+
+// UsersReceivedInvitationsList lists the pending invitations sent to the verified primary email address of the user
+func UsersReceivedInvitationsList(userServiceContext userService.Context) {
+	userID := userServiceContext.Request().PathParam("userId")
+	if userID == "" {
+		userServiceContext.RespondWithError(ErrorUserIDMissing())
+		return
+	}
+
+	if !authorizeOwner(userServiceContext, userID) {
+		return
+	}
+
+	usr := getUser(userServiceContext, userID)
+	if usr == nil {
+		return
+	}
+
+	if !usr.EmailVerified || usr.Email == "" {
+		userServiceContext.RespondWithStatusAndData(http.StatusOK, confirmation.Confirmations{})
+		return
+	}
+
+	filter := confirmation.NewConfirmationFilter()
+	filter.Type = pointer.FromString(confirmation.TypeCareteamInvitation)
+	filter.Status = pointer.FromString(confirmation.StatusPending)
+	filter.Emails = []string{strings.ToLower(usr.Email)}
+
+	invitations, err := userServiceContext.ConfirmationSession().ListConfirmations(userServiceContext.Request().Context(), filter)
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to list confirmations", err)
+		return
+	}
+
+	userServiceContext.RespondWithStatusAndData(http.StatusOK, invitations)
+}
+
+// UsersReceivedInvitationsAccept grants the user the permissions of a pending invitation sent to the verified primary
+// email address of the user
+func UsersReceivedInvitationsAccept(userServiceContext userService.Context) {
+	completeReceivedInvitation(userServiceContext, confirmation.StatusCompleted)
+}
+
+// UsersReceivedInvitationsDecline declines a pending invitation sent to the verified primary email address of the user
+func UsersReceivedInvitationsDecline(userServiceContext userService.Context) {
+	completeReceivedInvitation(userServiceContext, confirmation.StatusDeclined)
+}
+
+func completeReceivedInvitation(userServiceContext userService.Context, status string) {
+	ctx := userServiceContext.Request().Context()
+
+	userID := userServiceContext.Request().PathParam("userId")
+	if userID == "" {
+		userServiceContext.RespondWithError(ErrorUserIDMissing())
+		return
+	}
+
+	if !authorizeOwner(userServiceContext, userID) {
+		return
+	}
+
+	usr := getUser(userServiceContext, userID)
+	if usr == nil {
+		return
+	}
+
+	invitation := getPendingInvitation(userServiceContext)
+	if invitation == nil {
+		return
+	}
+
+	// Only the verified primary email proves ownership of the address the invitation was sent to
+	received := usr.EmailVerified && usr.Email != "" && strings.EqualFold(usr.Email, invitation.Email)
+	if !received || invitation.CreatorID == userID {
+		userServiceContext.RespondWithError(ErrorInvitationKeyNotFound(invitation.Key))
+		return
+	}
+
+	// The invitation permissions are merged into any existing grant so that accepting does not revoke permissions
+	if status == confirmation.StatusCompleted {
+		grant, err := userServiceContext.PermissionsSession().GetGrant(ctx, invitation.CreatorID, userID)
+		if err != nil {
+			userServiceContext.RespondWithInternalServerFailure("Unable to get grant", err)
+			return
+		}
+
+		permissions := user.Permissions{}
+		if grant != nil {
+			for key, prmssn := range grant.Permissions {
+				permissions[key] = prmssn
+			}
+		}
+		for key, prmssn := range invitation.Permissions {
+			permissions[key] = prmssn
+		}
+
+		if _, err = userServiceContext.PermissionsSession().UpdateGrant(ctx, invitation.CreatorID, userID, permissions); err != nil {
+			userServiceContext.RespondWithInternalServerFailure("Unable to update grant", err)
+			return
+		}
+	}
+
+	update := confirmation.NewConfirmationUpdate()
+	update.Status = pointer.FromString(status)
+	update.UserID = pointer.FromString(userID)
+	invitation, err := userServiceContext.ConfirmationSession().UpdateConfirmation(ctx, invitation.Key, update)
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to update confirmation", err)
+		return
+	}
+
+	userServiceContext.RespondWithStatusAndData(http.StatusOK, invitation)
+}
+
+// Responds with an error and returns nil if the invitation does not exist or is not pending
+func getPendingInvitation(userServiceContext userService.Context) *confirmation.Confirmation {
+	key := userServiceContext.Request().PathParam("key")
+	if key == "" {
+		userServiceContext.RespondWithError(ErrorInvitationKeyNotFound(key))
+		return nil
+	}
+
+	invitation, err := userServiceContext.ConfirmationSession().GetConfirmation(userServiceContext.Request().Context(), key)
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to get confirmation", err)
+		return nil
+	}
+	if invitation == nil || invitation.Type != confirmation.TypeCareteamInvitation || !invitation.IsPending() {
+		userServiceContext.RespondWithError(ErrorInvitationKeyNotFound(key))
+		return nil
+	}
+
+	return invitation
+}
+
+// Responds with an error and returns nil if the user does not exist
+func getUser(userServiceContext userService.Context, userID string) *user.User {
+	usr, err := userServiceContext.UsersSession().GetUserByID(userServiceContext.Request().Context(), userID)
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to get user by id", err)
+		return nil
+	}
+	if usr == nil {
+		userServiceContext.RespondWithError(ErrorUserIDNotFound(userID))
+		return nil
+	}
+	return usr
+}
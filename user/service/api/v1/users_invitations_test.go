@@ -0,0 +1,142 @@
+package v1_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"net/http"
+
+	"github.com/tidepool-org/platform/confirmation"
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/service"
+	"github.com/tidepool-org/platform/user"
+	"github.com/tidepool-org/platform/user/service/api/v1"
+	userTest "github.com/tidepool-org/platform/user/test"
+)
+
+var _ = Describe("UsersInvitations", func() {
+	var targetUserID string
+	var userID string
+
+	BeforeEach(func() {
+		targetUserID = user.NewID()
+		userID = user.NewID()
+	})
+
+	Context("UsersInvitationsCreate", func() {
+		var pathParams map[string]string
+
+		BeforeEach(func() {
+			pathParams = map[string]string{"userId": targetUserID}
+		})
+
+		It("allows the owner to invite a custodian with a lowercase email", func() {
+			testContext := NewTestContext(request.NewDetails(request.MethodSessionToken, targetUserID, "token"), pathParams, v1.UsersInvitationsCreateParameters{Email: "Invitee@Example.com", Permissions: user.Permissions{user.CustodianPermission: {}}})
+			v1.UsersInvitationsCreate(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(BeEmpty())
+			Expect(testContext.ConfirmationSessionImpl.Creates).To(HaveLen(1))
+			Expect(testContext.ConfirmationSessionImpl.Creates[0].Email).To(Equal("invitee@example.com"))
+		})
+
+		It("does not allow a custodian to invite a custodian", func() {
+			custodianUserID := user.NewID()
+			testContext := NewTestContext(request.NewDetails(request.MethodSessionToken, custodianUserID, "token"), pathParams, v1.UsersInvitationsCreateParameters{Email: "invitee@example.com", Permissions: user.Permissions{user.CustodianPermission: {}}})
+			testContext.UserClientImpl.GetUserPermissionsOutputs = []userTest.GetUserPermissionsOutput{{Permissions: user.Permissions{user.CustodianPermission: {}}}}
+			v1.UsersInvitationsCreate(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(Equal([]*service.Error{service.ErrorUnauthorized()}))
+			Expect(testContext.ConfirmationSessionImpl.Creates).To(BeEmpty())
+		})
+	})
+
+	Context("UsersReceivedInvitationsList", func() {
+		var invitation *confirmation.Confirmation
+		var testContext *TestContext
+
+		BeforeEach(func() {
+			invitation = &confirmation.Confirmation{
+				Key:       confirmation.NewKey(),
+				Type:      confirmation.TypeCareteamInvitation,
+				Status:    confirmation.StatusPending,
+				Email:     "invitee@example.com",
+				CreatorID: targetUserID,
+			}
+			testContext = NewTestContext(request.NewDetails(request.MethodSessionToken, userID, "token"), map[string]string{"userId": userID}, nil)
+			testContext.ConfirmationSessionImpl.Confirmations[invitation.Key] = invitation
+		})
+
+		It("lists the invitations sent to the verified primary email", func() {
+			testContext.UsersSessionImpl.Users[userID] = &user.User{ID: userID, Email: "Invitee@Example.com", Emails: []string{"alternate@example.com"}, EmailVerified: true}
+			v1.UsersReceivedInvitationsList(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(BeEmpty())
+			Expect(testContext.ConfirmationSessionImpl.Filters).To(HaveLen(1))
+			Expect(testContext.ConfirmationSessionImpl.Filters[0].Emails).To(Equal([]string{"invitee@example.com"}))
+			Expect(testContext.RespondWithStatusAndDataInputs).To(Equal([]RespondWithStatusAndDataInput{{http.StatusOK, confirmation.Confirmations{invitation}}}))
+		})
+
+		It("lists no invitations if the email is not verified", func() {
+			testContext.UsersSessionImpl.Users[userID] = &user.User{ID: userID, Email: "invitee@example.com"}
+			v1.UsersReceivedInvitationsList(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(BeEmpty())
+			Expect(testContext.ConfirmationSessionImpl.Filters).To(BeEmpty())
+			Expect(testContext.RespondWithStatusAndDataInputs).To(Equal([]RespondWithStatusAndDataInput{{http.StatusOK, confirmation.Confirmations{}}}))
+		})
+	})
+
+	Context("UsersReceivedInvitationsAccept", func() {
+		var invitation *confirmation.Confirmation
+		var testContext *TestContext
+
+		BeforeEach(func() {
+			invitation = &confirmation.Confirmation{
+				Key:         confirmation.NewKey(),
+				Type:        confirmation.TypeCareteamInvitation,
+				Status:      confirmation.StatusPending,
+				Email:       "invitee@example.com",
+				CreatorID:   targetUserID,
+				Permissions: user.Permissions{user.UploadPermission: {}},
+			}
+			testContext = NewTestContext(request.NewDetails(request.MethodSessionToken, userID, "token"), map[string]string{"userId": userID, "key": invitation.Key}, nil)
+			testContext.ConfirmationSessionImpl.Confirmations[invitation.Key] = invitation
+			testContext.UsersSessionImpl.Users[userID] = &user.User{ID: userID, Email: "Invitee@Example.com", EmailVerified: true}
+		})
+
+		It("matches the invitation email case-insensitively", func() {
+			v1.UsersReceivedInvitationsAccept(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(BeEmpty())
+			Expect(testContext.ConfirmationSessionImpl.Updates).To(HaveKey(invitation.Key))
+			Expect(testContext.ConfirmationSessionImpl.Updates[invitation.Key].Status).To(Equal(pointer.FromString(confirmation.StatusCompleted)))
+			Expect(testContext.PermissionsSessionImpl.Grants[targetUserID+"/"+userID].Permissions).To(Equal(user.Permissions{user.UploadPermission: {}}))
+		})
+
+		It("merges the invitation permissions into an existing grant", func() {
+			testContext.PermissionsSessionImpl.SetGrant(targetUserID, userID, user.Permissions{user.ViewPermission: {}})
+			v1.UsersReceivedInvitationsAccept(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(BeEmpty())
+			Expect(testContext.PermissionsSessionImpl.Grants[targetUserID+"/"+userID].Permissions).To(Equal(user.Permissions{user.ViewPermission: {}, user.UploadPermission: {}}))
+		})
+
+		It("does not accept an invitation if the email is not verified", func() {
+			testContext.UsersSessionImpl.Users[userID].EmailVerified = false
+			v1.UsersReceivedInvitationsAccept(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(Equal([]*service.Error{v1.ErrorInvitationKeyNotFound(invitation.Key)}))
+			Expect(testContext.PermissionsSessionImpl.Grants).To(BeEmpty())
+			Expect(testContext.ConfirmationSessionImpl.Updates).To(BeEmpty())
+		})
+
+		It("does not accept an invitation sent to an alternate email", func() {
+			testContext.UsersSessionImpl.Users[userID].Email = "primary@example.com"
+			testContext.UsersSessionImpl.Users[userID].Emails = []string{"primary@example.com", "invitee@example.com"}
+			v1.UsersReceivedInvitationsAccept(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(Equal([]*service.Error{v1.ErrorInvitationKeyNotFound(invitation.Key)}))
+			Expect(testContext.PermissionsSessionImpl.Grants).To(BeEmpty())
+		})
+
+		It("does not accept an invitation sent to another email", func() {
+			invitation.Email = "other@example.com"
+			v1.UsersReceivedInvitationsAccept(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(Equal([]*service.Error{v1.ErrorInvitationKeyNotFound(invitation.Key)}))
+			Expect(testContext.PermissionsSessionImpl.Grants).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,210 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/tidepool-org/platform/permission"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/service"
+	"github.com/tidepool-org/platform/user"
+	userService "github.com/tidepool-org/platform/user/service"
+)
+
+// UsersPermissionsList lists the users granted permissions for the target user
+func UsersPermissionsList(userServiceContext userService.Context) {
+	targetUserID := userServiceContext.Request().PathParam("userId")
+	if targetUserID == "" {
+		userServiceContext.RespondWithError(ErrorUserIDMissing())
+		return
+	}
+
+	if !authorizeOwnerOrCustodian(userServiceContext, targetUserID) {
+		return
+	}
+
+	grants, err := userServiceContext.PermissionsSession().ListGrantsForTargetUser(userServiceContext.Request().Context(), targetUserID)
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to list grants for target user", err)
+		return
+	}
+
+	userServiceContext.RespondWithStatusAndData(http.StatusOK, grants)
+}
+
+// UsersPermissionsUpdate replaces the permissions granted for the target user to the user; empty permissions revoke
+// all permissions
+func UsersPermissionsUpdate(userServiceContext userService.Context) {
+	ctx := userServiceContext.Request().Context()
+
+	targetUserID := userServiceContext.Request().PathParam("userId")
+	if targetUserID == "" {
+		userServiceContext.RespondWithError(ErrorUserIDMissing())
+		return
+	}
+	grantUserID := userServiceContext.Request().PathParam("grantUserId")
+	if grantUserID == "" {
+		userServiceContext.RespondWithError(ErrorUserIDMissing())
+		return
+	} else if grantUserID == targetUserID {
+		userServiceContext.RespondWithError(ErrorPermissionSelfNotValid())
+		return
+	}
+
+	if !authorizeOwnerOrCustodian(userServiceContext, targetUserID) {
+		return
+	}
+
+	permissions := user.Permissions{}
+	if err := userServiceContext.Request().DecodeJsonPayload(&permissions); err != nil {
+		userServiceContext.RespondWithError(service.ErrorJSONMalformed())
+		return
+	}
+	if err := validatePermissions(permissions); err != nil {
+		userServiceContext.RespondWithError(err)
+		return
+	}
+
+	if getUser(userServiceContext, grantUserID) == nil {
+		return
+	}
+
+	existingGrant, err := userServiceContext.PermissionsSession().GetGrant(ctx, targetUserID, grantUserID)
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to get grant", err)
+		return
+	}
+
+	// The custodian permission may only be granted or revoked by the target user, as a custodian has the same access
+	if (hasCustodianPermission(permissions) || (existingGrant != nil && hasCustodianPermission(existingGrant.Permissions))) && !authorizeOwner(userServiceContext, targetUserID) {
+		return
+	}
+
+	grant, err := userServiceContext.PermissionsSession().UpdateGrant(ctx, targetUserID, grantUserID, permissions)
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to update grant", err)
+		return
+	}
+	if grant == nil {
+		grant = &permission.Grant{UserID: grantUserID, TargetUserID: targetUserID, Permissions: user.Permissions{}}
+	}
+
+	userServiceContext.RespondWithStatusAndData(http.StatusOK, grant)
+}
+
+// UsersPermissionsDelete revokes all permissions granted for the target user to the user. The user may also revoke
+// their own access.
+func UsersPermissionsDelete(userServiceContext userService.Context) {
+	ctx := userServiceContext.Request().Context()
+
+	targetUserID := userServiceContext.Request().PathParam("userId")
+	if targetUserID == "" {
+		userServiceContext.RespondWithError(ErrorUserIDMissing())
+		return
+	}
+	grantUserID := userServiceContext.Request().PathParam("grantUserId")
+	if grantUserID == "" {
+		userServiceContext.RespondWithError(ErrorUserIDMissing())
+		return
+	} else if grantUserID == targetUserID {
+		userServiceContext.RespondWithError(ErrorPermissionSelfNotValid())
+		return
+	}
+
+	if details := request.DetailsFromContext(ctx); details.IsService() || details.UserID() != grantUserID {
+		if !authorizeOwnerOrCustodian(userServiceContext, targetUserID) {
+			return
+		}
+
+		existingGrant, err := userServiceContext.PermissionsSession().GetGrant(ctx, targetUserID, grantUserID)
+		if err != nil {
+			userServiceContext.RespondWithInternalServerFailure("Unable to get grant", err)
+			return
+		}
+
+		// A custodian may not revoke another custodian, as only the target user may grant or revoke the custodian permission
+		if existingGrant != nil && hasCustodianPermission(existingGrant.Permissions) && !authorizeOwner(userServiceContext, targetUserID) {
+			return
+		}
+	}
+
+	if err := userServiceContext.PermissionsSession().DestroyGrant(ctx, targetUserID, grantUserID); err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to destroy grant", err)
+		return
+	}
+
+	userServiceContext.RespondWithStatusAndData(http.StatusOK, struct{}{})
+}
+
+// UsersAccessList lists the target users that granted permissions to the user
+func UsersAccessList(userServiceContext userService.Context) {
+	userID := userServiceContext.Request().PathParam("userId")
+	if userID == "" {
+		userServiceContext.RespondWithError(ErrorUserIDMissing())
+		return
+	}
+
+	if !authorizeOwner(userServiceContext, userID) {
+		return
+	}
+
+	grants, err := userServiceContext.PermissionsSession().ListGrantsForUser(userServiceContext.Request().Context(), userID)
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to list grants for user", err)
+		return
+	}
+
+	userServiceContext.RespondWithStatusAndData(http.StatusOK, grants)
+}
+
+// Responds with an error and returns false unless the request is from a service or the user
+func authorizeOwner(userServiceContext userService.Context, userID string) bool {
+	if details := request.DetailsFromContext(userServiceContext.Request().Context()); !details.IsService() && details.UserID() != userID {
+		userServiceContext.RespondWithError(service.ErrorUnauthorized())
+		return false
+	}
+	return true
+}
+
+// Responds with an error and returns false unless the request is from a service, the target user, or a custodian of
+// the target user
+func authorizeOwnerOrCustodian(userServiceContext userService.Context, targetUserID string) bool {
+	ctx := userServiceContext.Request().Context()
+
+	details := request.DetailsFromContext(ctx)
+	if details.IsService() || details.UserID() == targetUserID {
+		return true
+	}
+
+	permissions, err := userServiceContext.UserClient().GetUserPermissions(ctx, details.UserID(), targetUserID)
+	if err != nil {
+		if request.IsErrorUnauthorized(err) {
+			userServiceContext.RespondWithError(service.ErrorUnauthorized())
+		} else {
+			userServiceContext.RespondWithInternalServerFailure("Unable to get user permissions", err)
+		}
+		return false
+	}
+	if !hasCustodianPermission(permissions) {
+		userServiceContext.RespondWithError(service.ErrorUnauthorized())
+		return false
+	}
+	return true
+}
+
+func hasCustodianPermission(permissions user.Permissions) bool {
+	_, ok := permissions[user.CustodianPermission]
+	return ok
+}
+
+// Returns an error if any permission may not be granted or has restrictions that are not valid
+func validatePermissions(permissions user.Permissions) *service.Error {
+	for key, prmssn := range permissions {
+		if !permission.IsGrantablePermission(key) {
+			return ErrorPermissionNotValid(key)
+		}
+		if _, err := user.ParsePermissionRestrictions(prmssn); err != nil {
+			return ErrorPermissionNotValid(key)
+		}
+	}
+	return nil
+}
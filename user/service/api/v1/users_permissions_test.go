@@ -0,0 +1,139 @@
+package v1_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"net/http"
+
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/service"
+	"github.com/tidepool-org/platform/user"
+	"github.com/tidepool-org/platform/user/service/api/v1"
+	userTest "github.com/tidepool-org/platform/user/test"
+)
+
+var _ = Describe("UsersPermissions", func() {
+	var targetUserID string
+	var grantUserID string
+	var custodianUserID string
+	var ownerDetails request.Details
+	var custodianDetails request.Details
+
+	BeforeEach(func() {
+		targetUserID = user.NewID()
+		grantUserID = user.NewID()
+		custodianUserID = user.NewID()
+		ownerDetails = request.NewDetails(request.MethodSessionToken, targetUserID, "token")
+		custodianDetails = request.NewDetails(request.MethodSessionToken, custodianUserID, "token")
+	})
+
+	Context("UsersPermissionsUpdate", func() {
+		var pathParams map[string]string
+
+		BeforeEach(func() {
+			pathParams = map[string]string{"userId": targetUserID, "grantUserId": grantUserID}
+		})
+
+		newTestContext := func(details request.Details, permissions user.Permissions) *TestContext {
+			testContext := NewTestContext(details, pathParams, permissions)
+			testContext.UsersSessionImpl.Users[grantUserID] = &user.User{ID: grantUserID}
+			return testContext
+		}
+
+		It("does not allow the owner to grant to themselves", func() {
+			pathParams["grantUserId"] = targetUserID
+			testContext := newTestContext(ownerDetails, user.Permissions{user.ViewPermission: {}})
+			v1.UsersPermissionsUpdate(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(Equal([]*service.Error{v1.ErrorPermissionSelfNotValid()}))
+			Expect(testContext.RespondWithStatusAndDataInputs).To(BeEmpty())
+			Expect(testContext.PermissionsSessionImpl.Grants).To(BeEmpty())
+		})
+
+		It("allows the owner to grant custodian", func() {
+			testContext := newTestContext(ownerDetails, user.Permissions{user.CustodianPermission: {}})
+			v1.UsersPermissionsUpdate(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(BeEmpty())
+			Expect(testContext.RespondWithStatusAndDataInputs).To(HaveLen(1))
+			Expect(testContext.RespondWithStatusAndDataInputs[0].statusCode).To(Equal(http.StatusOK))
+			Expect(testContext.PermissionsSessionImpl.Grants).To(HaveKey(targetUserID + "/" + grantUserID))
+		})
+
+		It("allows a custodian to grant view", func() {
+			testContext := newTestContext(custodianDetails, user.Permissions{user.ViewPermission: {}})
+			testContext.UserClientImpl.GetUserPermissionsOutputs = []userTest.GetUserPermissionsOutput{{Permissions: user.Permissions{user.CustodianPermission: {}}}}
+			v1.UsersPermissionsUpdate(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(BeEmpty())
+			Expect(testContext.RespondWithStatusAndDataInputs).To(HaveLen(1))
+			Expect(testContext.PermissionsSessionImpl.Grants[targetUserID+"/"+grantUserID].Permissions).To(Equal(user.Permissions{user.ViewPermission: {}}))
+		})
+
+		It("does not allow a custodian to grant custodian", func() {
+			testContext := newTestContext(custodianDetails, user.Permissions{user.CustodianPermission: {}})
+			testContext.UserClientImpl.GetUserPermissionsOutputs = []userTest.GetUserPermissionsOutput{{Permissions: user.Permissions{user.CustodianPermission: {}}}}
+			v1.UsersPermissionsUpdate(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(Equal([]*service.Error{service.ErrorUnauthorized()}))
+			Expect(testContext.RespondWithStatusAndDataInputs).To(BeEmpty())
+			Expect(testContext.PermissionsSessionImpl.Grants).To(BeEmpty())
+		})
+
+		It("does not allow a custodian to update the permissions of another custodian", func() {
+			testContext := newTestContext(custodianDetails, user.Permissions{user.ViewPermission: {}})
+			testContext.UserClientImpl.GetUserPermissionsOutputs = []userTest.GetUserPermissionsOutput{{Permissions: user.Permissions{user.CustodianPermission: {}}}}
+			testContext.PermissionsSessionImpl.SetGrant(targetUserID, grantUserID, user.Permissions{user.CustodianPermission: {}})
+			v1.UsersPermissionsUpdate(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(Equal([]*service.Error{service.ErrorUnauthorized()}))
+			Expect(testContext.PermissionsSessionImpl.Grants[targetUserID+"/"+grantUserID].Permissions).To(Equal(user.Permissions{user.CustodianPermission: {}}))
+		})
+	})
+
+	Context("UsersPermissionsDelete", func() {
+		var pathParams map[string]string
+
+		BeforeEach(func() {
+			pathParams = map[string]string{"userId": targetUserID, "grantUserId": grantUserID}
+		})
+
+		It("does not allow the owner to revoke from themselves", func() {
+			pathParams["grantUserId"] = targetUserID
+			testContext := NewTestContext(ownerDetails, pathParams, nil)
+			v1.UsersPermissionsDelete(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(Equal([]*service.Error{v1.ErrorPermissionSelfNotValid()}))
+			Expect(testContext.PermissionsSessionImpl.DestroyGrants).To(BeEmpty())
+		})
+
+		It("allows a custodian to revoke view", func() {
+			testContext := NewTestContext(custodianDetails, pathParams, nil)
+			testContext.UserClientImpl.GetUserPermissionsOutputs = []userTest.GetUserPermissionsOutput{{Permissions: user.Permissions{user.CustodianPermission: {}}}}
+			testContext.PermissionsSessionImpl.SetGrant(targetUserID, grantUserID, user.Permissions{user.ViewPermission: {}})
+			v1.UsersPermissionsDelete(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(BeEmpty())
+			Expect(testContext.PermissionsSessionImpl.DestroyGrants).To(HaveLen(1))
+		})
+
+		It("does not allow a custodian to revoke another custodian", func() {
+			testContext := NewTestContext(custodianDetails, pathParams, nil)
+			testContext.UserClientImpl.GetUserPermissionsOutputs = []userTest.GetUserPermissionsOutput{{Permissions: user.Permissions{user.CustodianPermission: {}}}}
+			testContext.PermissionsSessionImpl.SetGrant(targetUserID, grantUserID, user.Permissions{user.CustodianPermission: {}})
+			v1.UsersPermissionsDelete(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(Equal([]*service.Error{service.ErrorUnauthorized()}))
+			Expect(testContext.PermissionsSessionImpl.DestroyGrants).To(BeEmpty())
+		})
+
+		It("allows the owner to revoke a custodian", func() {
+			testContext := NewTestContext(ownerDetails, pathParams, nil)
+			testContext.PermissionsSessionImpl.SetGrant(targetUserID, grantUserID, user.Permissions{user.CustodianPermission: {}})
+			v1.UsersPermissionsDelete(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(BeEmpty())
+			Expect(testContext.PermissionsSessionImpl.DestroyGrants).To(HaveLen(1))
+		})
+
+		It("allows a custodian to revoke their own access", func() {
+			testContext := NewTestContext(request.NewDetails(request.MethodSessionToken, grantUserID, "token"), pathParams, nil)
+			testContext.PermissionsSessionImpl.SetGrant(targetUserID, grantUserID, user.Permissions{user.CustodianPermission: {}})
+			v1.UsersPermissionsDelete(testContext)
+			Expect(testContext.RespondWithErrorInputs).To(BeEmpty())
+			Expect(testContext.PermissionsSessionImpl.DestroyGrants).To(HaveLen(1))
+		})
+	})
+})
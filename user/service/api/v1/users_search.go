@@ -0,0 +1,60 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/service"
+	"github.com/tidepool-org/platform/user"
+	userService "github.com/tidepool-org/platform/user/service"
+)
+
+// UsersSearch lists the users matching the filter. Only services and administrators may search users; fields are
+// redacted for administrators.
+func UsersSearch(userServiceContext userService.Context) {
+	ctx := userServiceContext.Request().Context()
+
+	if !authorizeAdmin(userServiceContext) {
+		return
+	}
+
+	filter := user.NewUserFilter()
+	pagination := page.NewPagination()
+	if err := request.DecodeRequestQuery(userServiceContext.Request().Request, filter, pagination); err != nil {
+		request.MustNewResponder(userServiceContext.Response(), userServiceContext.Request()).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	users, err := userServiceContext.UsersSession().ListUsers(ctx, filter, pagination)
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to list users", err)
+		return
+	}
+
+	if err = users.Sanitize(request.DetailsFromContext(ctx)); err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to sanitize users", err)
+		return
+	}
+
+	userServiceContext.RespondWithStatusAndData(http.StatusOK, users)
+}
+
+// Responds with an error and returns false unless the request is from a service or a user with the admin role
+func authorizeAdmin(userServiceContext userService.Context) bool {
+	details := request.DetailsFromContext(userServiceContext.Request().Context())
+	if details.IsService() {
+		return true
+	}
+
+	usr, err := userServiceContext.UsersSession().GetUserByID(userServiceContext.Request().Context(), details.UserID())
+	if err != nil {
+		userServiceContext.RespondWithInternalServerFailure("Unable to get user by id", err)
+		return false
+	}
+	if usr == nil || usr.DeletedTime != "" || !usr.HasRole(user.AdminRole) {
+		userServiceContext.RespondWithError(service.ErrorUnauthorized())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package v1_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"net/http"
+
+	"github.com/tidepool-org/platform/pointer"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/service"
+	"github.com/tidepool-org/platform/user"
+	"github.com/tidepool-org/platform/user/service/api/v1"
+)
+
+var _ = Describe("UsersSearch", func() {
+	var adminUserID string
+	var foundUser *user.User
+
+	BeforeEach(func() {
+		adminUserID = user.NewID()
+		foundUser = &user.User{
+			ID:            user.NewID(),
+			Email:         "found@example.com",
+			Emails:        []string{"found@example.com"},
+			CreatedUserID: user.NewID(),
+		}
+	})
+
+	newTestContext := func(details request.Details) *TestContext {
+		testContext := NewTestContext(details, nil, nil)
+		testContext.RequestImpl.Request.URL.RawQuery = "emailPrefix=Found&role=clinic"
+		testContext.UsersSessionImpl.ListUsersOutput = user.Users{foundUser}
+		return testContext
+	}
+
+	It("lists the users matching the filter for a service without redaction", func() {
+		testContext := newTestContext(request.NewDetails(request.MethodServiceSecret, "", "secret"))
+		v1.UsersSearch(testContext)
+		Expect(testContext.RespondWithErrorInputs).To(BeEmpty())
+		Expect(testContext.UsersSessionImpl.ListUsersInputs).To(HaveLen(1))
+		Expect(testContext.UsersSessionImpl.ListUsersInputs[0].Filter.EmailPrefix).To(Equal(pointer.FromString("Found")))
+		Expect(testContext.UsersSessionImpl.ListUsersInputs[0].Filter.Role).To(Equal(pointer.FromString(user.ClinicRole)))
+		Expect(testContext.RespondWithStatusAndDataInputs).To(HaveLen(1))
+		Expect(testContext.RespondWithStatusAndDataInputs[0].statusCode).To(Equal(http.StatusOK))
+		Expect(foundUser.Emails).To(Equal([]string{"found@example.com"}))
+		Expect(foundUser.CreatedUserID).ToNot(BeEmpty())
+	})
+
+	It("lists the users matching the filter for an admin with redaction", func() {
+		testContext := newTestContext(request.NewDetails(request.MethodSessionToken, adminUserID, "token"))
+		testContext.UsersSessionImpl.Users[adminUserID] = &user.User{ID: adminUserID, Roles: []string{user.AdminRole}}
+		v1.UsersSearch(testContext)
+		Expect(testContext.RespondWithErrorInputs).To(BeEmpty())
+		Expect(testContext.UsersSessionImpl.ListUsersInputs).To(HaveLen(1))
+		Expect(testContext.RespondWithStatusAndDataInputs).To(Equal([]RespondWithStatusAndDataInput{{http.StatusOK, user.Users{foundUser}}}))
+		Expect(foundUser.Emails).To(BeNil())
+		Expect(foundUser.CreatedUserID).To(BeEmpty())
+	})
+
+	It("responds with unauthorized for a user without the admin role", func() {
+		testContext := newTestContext(request.NewDetails(request.MethodSessionToken, adminUserID, "token"))
+		testContext.UsersSessionImpl.Users[adminUserID] = &user.User{ID: adminUserID, Roles: []string{user.ClinicRole}}
+		v1.UsersSearch(testContext)
+		Expect(testContext.RespondWithErrorInputs).To(Equal([]*service.Error{service.ErrorUnauthorized()}))
+		Expect(testContext.UsersSessionImpl.ListUsersInputs).To(BeEmpty())
+	})
+
+	It("responds with unauthorized for a deleted admin", func() {
+		testContext := newTestContext(request.NewDetails(request.MethodSessionToken, adminUserID, "token"))
+		testContext.UsersSessionImpl.Users[adminUserID] = &user.User{ID: adminUserID, Roles: []string{user.AdminRole}, DeletedTime: "2018-01-01T00:00:00Z"}
+		v1.UsersSearch(testContext)
+		Expect(testContext.RespondWithErrorInputs).To(Equal([]*service.Error{service.ErrorUnauthorized()}))
+		Expect(testContext.UsersSessionImpl.ListUsersInputs).To(BeEmpty())
+	})
+
+	It("responds with unauthorized for an unknown user", func() {
+		testContext := newTestContext(request.NewDetails(request.MethodSessionToken, adminUserID, "token"))
+		v1.UsersSearch(testContext)
+		Expect(testContext.RespondWithErrorInputs).To(Equal([]*service.Error{service.ErrorUnauthorized()}))
+		Expect(testContext.UsersSessionImpl.ListUsersInputs).To(BeEmpty())
+	})
+})
@@ -4,6 +4,20 @@ import "github.com/tidepool-org/platform/user/service"
 
 func Routes() []service.Route {
 	return []service.Route{
+		service.MakeRoute("GET", "/v1/users", Authenticate(UsersSearch)),
 		service.MakeRoute("DELETE", "/v1/users/:userId", Authenticate(UsersDelete)),
+		service.MakeRoute("GET", "/v1/users/:userId/deletion", Authenticate(UsersDeletionGet)),
+		service.MakeRoute("GET", "/v1/users/:userId/permissions", Authenticate(UsersPermissionsList)),
+		service.MakeRoute("PUT", "/v1/users/:userId/permissions/:grantUserId", Authenticate(UsersPermissionsUpdate)),
+		service.MakeRoute("DELETE", "/v1/users/:userId/permissions/:grantUserId", Authenticate(UsersPermissionsDelete)),
+		service.MakeRoute("GET", "/v1/users/:userId/access", Authenticate(UsersAccessList)),
+		service.MakeRoute("POST", "/v1/users/:userId/export", Authenticate(UsersExportCreate)),
+		service.MakeRoute("GET", "/v1/users/:userId/export", Authenticate(UsersExportGet)),
+		service.MakeRoute("POST", "/v1/users/:userId/invitations", Authenticate(UsersInvitationsCreate)),
+		service.MakeRoute("GET", "/v1/users/:userId/invitations", Authenticate(UsersInvitationsList)),
+		service.MakeRoute("DELETE", "/v1/users/:userId/invitations/:key", Authenticate(UsersInvitationsDelete)),
+		service.MakeRoute("GET", "/v1/users/:userId/received_invitations", Authenticate(UsersReceivedInvitationsList)),
+		service.MakeRoute("PUT", "/v1/users/:userId/received_invitations/:key/accept", Authenticate(UsersReceivedInvitationsAccept)),
+		service.MakeRoute("PUT", "/v1/users/:userId/received_invitations/:key/decline", Authenticate(UsersReceivedInvitationsDecline)),
 	}
 }
@@ -4,7 +4,33 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
 	"testing"
+
+	"github.com/ant0ine/go-json-rest/rest"
+
+	"github.com/tidepool-org/platform/auth"
+	"github.com/tidepool-org/platform/confirmation"
+	confirmationStore "github.com/tidepool-org/platform/confirmation/store"
+	dataClient "github.com/tidepool-org/platform/data/client"
+	messageStore "github.com/tidepool-org/platform/message/store"
+	"github.com/tidepool-org/platform/metric"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/permission"
+	permissionStore "github.com/tidepool-org/platform/permission/store"
+	profileStore "github.com/tidepool-org/platform/profile/store"
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/service"
+	sessionStore "github.com/tidepool-org/platform/session/store"
+	"github.com/tidepool-org/platform/task"
+	taskTest "github.com/tidepool-org/platform/task/test"
+	testRest "github.com/tidepool-org/platform/test/rest"
+	"github.com/tidepool-org/platform/user"
+	userStore "github.com/tidepool-org/platform/user/store"
+	userTest "github.com/tidepool-org/platform/user/test"
 )
 
 func TestSuite(t *testing.T) {
@@ -12,6 +38,217 @@ func TestSuite(t *testing.T) {
 	RunSpecs(t, "user/service/api/v1")
 }
 
+type RespondWithInternalServerFailureInput struct {
+	message string
+	failure []interface{}
+}
+
+type RespondWithStatusAndDataInput struct {
+	statusCode int
+	data       interface{}
+}
+
+type TestContext struct {
+	RequestImpl                            *rest.Request
+	RespondWithErrorInputs                 []*service.Error
+	RespondWithInternalServerFailureInputs []RespondWithInternalServerFailureInput
+	RespondWithStatusAndDataInputs         []RespondWithStatusAndDataInput
+	UserClientImpl                         *userTest.Client
+	TaskClientImpl                         *taskTest.Client
+	ConfirmationSessionImpl                *TestConfirmationSession
+	PermissionsSessionImpl                 *TestPermissionsSession
+	UsersSessionImpl                       *TestUsersSession
+}
+
+func NewTestContext(details request.Details, pathParams map[string]string, payload interface{}) *TestContext {
+	req := testRest.NewRequest()
+	req.Request = req.Request.WithContext(request.NewContextWithDetails(context.Background(), details))
+	req.PathParams = pathParams
+	if payload != nil {
+		body, err := json.Marshal(payload)
+		Expect(err).ToNot(HaveOccurred())
+		req.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	return &TestContext{
+		RequestImpl:             req,
+		UserClientImpl:          userTest.NewClient(),
+		TaskClientImpl:          taskTest.NewClient(),
+		ConfirmationSessionImpl: NewTestConfirmationSession(),
+		PermissionsSessionImpl:  NewTestPermissionsSession(),
+		UsersSessionImpl:        NewTestUsersSession(),
+	}
+}
+
+func (t *TestContext) Response() rest.ResponseWriter {
+	panic("Unexpected invocation of Response on TestContext")
+}
+
+func (t *TestContext) Request() *rest.Request {
+	return t.RequestImpl
+}
+
+func (t *TestContext) RespondWithError(err *service.Error) {
+	t.RespondWithErrorInputs = append(t.RespondWithErrorInputs, err)
+}
+
+func (t *TestContext) RespondWithInternalServerFailure(message string, failure ...interface{}) {
+	t.RespondWithInternalServerFailureInputs = append(t.RespondWithInternalServerFailureInputs, RespondWithInternalServerFailureInput{message, failure})
+}
+
+func (t *TestContext) RespondWithStatusAndErrors(statusCode int, errors []*service.Error) {
+	panic("Unexpected invocation of RespondWithStatusAndErrors on TestContext")
+}
+
+func (t *TestContext) RespondWithStatusAndData(statusCode int, data interface{}) {
+	t.RespondWithStatusAndDataInputs = append(t.RespondWithStatusAndDataInputs, RespondWithStatusAndDataInput{statusCode, data})
+}
+
+func (t *TestContext) AuthClient() auth.Client {
+	panic("Unexpected invocation of AuthClient on TestContext")
+}
+
+func (t *TestContext) MetricClient() metric.Client {
+	panic("Unexpected invocation of MetricClient on TestContext")
+}
+
+func (t *TestContext) UserClient() user.Client {
+	return t.UserClientImpl
+}
+
+func (t *TestContext) DataClient() dataClient.Client {
+	panic("Unexpected invocation of DataClient on TestContext")
+}
+
+func (t *TestContext) TaskClient() task.Client {
+	return t.TaskClientImpl
+}
+
+func (t *TestContext) ConfirmationSession() confirmationStore.ConfirmationSession {
+	return t.ConfirmationSessionImpl
+}
+
+func (t *TestContext) MessagesSession() messageStore.MessagesSession {
+	panic("Unexpected invocation of MessagesSession on TestContext")
+}
+
+func (t *TestContext) PermissionsSession() permissionStore.PermissionsSession {
+	return t.PermissionsSessionImpl
+}
+
+func (t *TestContext) ProfilesSession() profileStore.ProfilesSession {
+	panic("Unexpected invocation of ProfilesSession on TestContext")
+}
+
+func (t *TestContext) SessionsSession() sessionStore.SessionsSession {
+	panic("Unexpected invocation of SessionsSession on TestContext")
+}
+
+func (t *TestContext) UsersSession() userStore.UsersSession {
+	return t.UsersSessionImpl
+}
+
+// Only the session methods used by the handlers under test are implemented; any other invocation panics
+
+type TestConfirmationSession struct {
+	confirmationStore.ConfirmationSession
+	Confirmations map[string]*confirmation.Confirmation
+	Filters       []*confirmation.ConfirmationFilter
+	Creates       []*confirmation.ConfirmationCreate
+	Updates       map[string]*confirmation.ConfirmationUpdate
+}
+
+func NewTestConfirmationSession() *TestConfirmationSession {
+	return &TestConfirmationSession{
+		Confirmations: map[string]*confirmation.Confirmation{},
+		Updates:       map[string]*confirmation.ConfirmationUpdate{},
+	}
+}
+
+func (t *TestConfirmationSession) ListConfirmations(ctx context.Context, filter *confirmation.ConfirmationFilter) (confirmation.Confirmations, error) {
+	t.Filters = append(t.Filters, filter)
+	confirmations := confirmation.Confirmations{}
+	for _, cnfrmtn := range t.Confirmations {
+		for _, email := range filter.Emails {
+			if cnfrmtn.Email == email {
+				confirmations = append(confirmations, cnfrmtn)
+			}
+		}
+	}
+	return confirmations, nil
+}
+
+func (t *TestConfirmationSession) CreateConfirmation(ctx context.Context, create *confirmation.ConfirmationCreate) (*confirmation.Confirmation, error) {
+	t.Creates = append(t.Creates, create)
+	return &confirmation.Confirmation{Key: confirmation.NewKey(), Type: create.Type, Status: confirmation.StatusPending, Email: create.Email, CreatorID: create.CreatorID, Permissions: create.Permissions}, nil
+}
+
+func (t *TestConfirmationSession) GetConfirmation(ctx context.Context, key string) (*confirmation.Confirmation, error) {
+	return t.Confirmations[key], nil
+}
+
+func (t *TestConfirmationSession) UpdateConfirmation(ctx context.Context, key string, update *confirmation.ConfirmationUpdate) (*confirmation.Confirmation, error) {
+	t.Updates[key] = update
+	return t.Confirmations[key], nil
+}
+
+type TestPermissionsSession struct {
+	permissionStore.PermissionsSession
+	Grants        map[string]*permission.Grant
+	DestroyGrants []*permission.Grant
+}
+
+func NewTestPermissionsSession() *TestPermissionsSession {
+	return &TestPermissionsSession{
+		Grants: map[string]*permission.Grant{},
+	}
+}
+
+func (t *TestPermissionsSession) SetGrant(targetUserID string, userID string, permissions user.Permissions) {
+	t.Grants[targetUserID+"/"+userID] = &permission.Grant{UserID: userID, TargetUserID: targetUserID, Permissions: permissions}
+}
+
+func (t *TestPermissionsSession) GetGrant(ctx context.Context, targetUserID string, userID string) (*permission.Grant, error) {
+	return t.Grants[targetUserID+"/"+userID], nil
+}
+
+func (t *TestPermissionsSession) UpdateGrant(ctx context.Context, targetUserID string, userID string, permissions user.Permissions) (*permission.Grant, error) {
+	t.SetGrant(targetUserID, userID, permissions)
+	return t.Grants[targetUserID+"/"+userID], nil
+}
+
+func (t *TestPermissionsSession) DestroyGrant(ctx context.Context, targetUserID string, userID string) error {
+	t.DestroyGrants = append(t.DestroyGrants, &permission.Grant{UserID: userID, TargetUserID: targetUserID})
+	delete(t.Grants, targetUserID+"/"+userID)
+	return nil
+}
+
+type ListUsersInput struct {
+	Filter     *user.UserFilter
+	Pagination *page.Pagination
+}
+
+type TestUsersSession struct {
+	userStore.UsersSession
+	Users           map[string]*user.User
+	ListUsersInputs []ListUsersInput
+	ListUsersOutput user.Users
+}
+
+func NewTestUsersSession() *TestUsersSession {
+	return &TestUsersSession{
+		Users: map[string]*user.User{},
+	}
+}
+
+func (t *TestUsersSession) ListUsers(ctx context.Context, filter *user.UserFilter, pagination *page.Pagination) (user.Users, error) {
+	t.ListUsersInputs = append(t.ListUsersInputs, ListUsersInput{Filter: filter, Pagination: pagination})
+	return t.ListUsersOutput, nil
+}
+
+func (t *TestUsersSession) GetUserByID(ctx context.Context, userID string) (*user.User, error) {
+	return t.Users[userID], nil
+}
+
 // type TestFlags struct {
 // 	flags map[string]bool
 // }
@@ -10,6 +10,7 @@ import (
 	profileStore "github.com/tidepool-org/platform/profile/store"
 	"github.com/tidepool-org/platform/service"
 	sessionStore "github.com/tidepool-org/platform/session/store"
+	"github.com/tidepool-org/platform/task"
 	"github.com/tidepool-org/platform/user"
 	userStore "github.com/tidepool-org/platform/user/store"
 )
@@ -21,6 +22,7 @@ type Context interface {
 	MetricClient() metric.Client
 	UserClient() user.Client
 	DataClient() dataClient.Client
+	TaskClient() task.Client
 
 	ConfirmationSession() confirmationStore.ConfirmationSession
 	MessagesSession() messageStore.MessagesSession
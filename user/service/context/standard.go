@@ -15,6 +15,7 @@ import (
 	profileStore "github.com/tidepool-org/platform/profile/store"
 	serviceContext "github.com/tidepool-org/platform/service/context"
 	sessionStore "github.com/tidepool-org/platform/session/store"
+	"github.com/tidepool-org/platform/task"
 	"github.com/tidepool-org/platform/user"
 	userService "github.com/tidepool-org/platform/user/service"
 	userStore "github.com/tidepool-org/platform/user/store"
@@ -26,6 +27,7 @@ type Standard struct {
 	dataClient           dataClient.Client
 	metricClient         metric.Client
 	userClient           user.Client
+	taskClient           task.Client
 	confirmationStore    confirmationStore.Store
 	confirmationsSession confirmationStore.ConfirmationSession
 	messageStore         messageStore.Store
@@ -40,11 +42,11 @@ type Standard struct {
 	usersSession         userStore.UsersSession
 }
 
-func WithContext(authClient auth.Client, dataClient dataClient.Client, metricClient metric.Client, userClient user.Client,
+func WithContext(authClient auth.Client, dataClient dataClient.Client, metricClient metric.Client, userClient user.Client, taskClient task.Client,
 	confirmationStore confirmationStore.Store, messageStore messageStore.Store, permissionStore permissionStore.Store, profileStore profileStore.Store,
 	sessionStore sessionStore.Store, userStore userStore.Store, handler userService.HandlerFunc) rest.HandlerFunc {
 	return func(response rest.ResponseWriter, request *rest.Request) {
-		standard, standardErr := NewStandard(response, request, authClient, dataClient, metricClient, userClient,
+		standard, standardErr := NewStandard(response, request, authClient, dataClient, metricClient, userClient, taskClient,
 			confirmationStore, messageStore, permissionStore, profileStore, sessionStore, userStore)
 		if standardErr != nil {
 			if responder, responderErr := serviceContext.NewResponder(response, request); responderErr != nil {
@@ -60,7 +62,7 @@ func WithContext(authClient auth.Client, dataClient dataClient.Client, metricCli
 	}
 }
 
-func NewStandard(response rest.ResponseWriter, request *rest.Request, authClient auth.Client, dataClient dataClient.Client, metricClient metric.Client, userClient user.Client,
+func NewStandard(response rest.ResponseWriter, request *rest.Request, authClient auth.Client, dataClient dataClient.Client, metricClient metric.Client, userClient user.Client, taskClient task.Client,
 	confirmationStore confirmationStore.Store, messageStore messageStore.Store, permissionStore permissionStore.Store, profileStore profileStore.Store,
 	sessionStore sessionStore.Store, userStore userStore.Store) (*Standard, error) {
 	if authClient == nil {
@@ -75,6 +77,9 @@ func NewStandard(response rest.ResponseWriter, request *rest.Request, authClient
 	if userClient == nil {
 		return nil, errors.New("user client is missing")
 	}
+	if taskClient == nil {
+		return nil, errors.New("task client is missing")
+	}
 	if confirmationStore == nil {
 		return nil, errors.New("confirmation store is missing")
 	}
@@ -105,6 +110,7 @@ func NewStandard(response rest.ResponseWriter, request *rest.Request, authClient
 		dataClient:        dataClient,
 		metricClient:      metricClient,
 		userClient:        userClient,
+		taskClient:        taskClient,
 		confirmationStore: confirmationStore,
 		messageStore:      messageStore,
 		permissionStore:   permissionStore,
@@ -157,6 +163,10 @@ func (s *Standard) UserClient() user.Client {
 	return s.userClient
 }
 
+func (s *Standard) TaskClient() task.Client {
+	return s.taskClient
+}
+
 func (s *Standard) ConfirmationSession() confirmationStore.ConfirmationSession {
 	if s.confirmationsSession == nil {
 		s.confirmationsSession = s.confirmationStore.NewConfirmationSession()
@@ -15,6 +15,8 @@ import (
 	"github.com/tidepool-org/platform/service/service"
 	sessionMongo "github.com/tidepool-org/platform/session/store/mongo"
 	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
+	"github.com/tidepool-org/platform/task"
+	taskClient "github.com/tidepool-org/platform/task/client"
 	"github.com/tidepool-org/platform/user"
 	userClient "github.com/tidepool-org/platform/user/client"
 	"github.com/tidepool-org/platform/user/service/api"
@@ -27,6 +29,7 @@ type Standard struct {
 	dataClient        dataClient.Client
 	metricClient      metric.Client
 	userClient        user.Client
+	taskClient        task.Client
 	confirmationStore *confirmationMongo.Store
 	messageStore      *messageMongo.Store
 	permissionStore   *permissionMongo.Store
@@ -57,6 +60,9 @@ func (s *Standard) Initialize(provider application.Provider) error {
 	if err := s.initializeUserClient(); err != nil {
 		return err
 	}
+	if err := s.initializeTaskClient(); err != nil {
+		return err
+	}
 	if err := s.initializeConfirmationStore(); err != nil {
 		return err
 	}
@@ -108,6 +114,7 @@ func (s *Standard) Terminate() {
 		s.confirmationStore.Close()
 		s.confirmationStore = nil
 	}
+	s.taskClient = nil
 	s.userClient = nil
 	s.metricClient = nil
 	s.dataClient = nil
@@ -183,6 +190,26 @@ func (s *Standard) initializeUserClient() error {
 	return nil
 }
 
+func (s *Standard) initializeTaskClient() error {
+	s.Logger().Debug("Loading task client config")
+
+	cfg := platform.NewConfig()
+	cfg.UserAgent = s.UserAgent()
+	if err := cfg.Load(s.ConfigReporter().WithScopes("task", "client")); err != nil {
+		return errors.Wrap(err, "unable to load task client config")
+	}
+
+	s.Logger().Debug("Creating task client")
+
+	clnt, err := taskClient.New(cfg, platform.AuthorizeAsService)
+	if err != nil {
+		return errors.Wrap(err, "unable to create task client")
+	}
+	s.taskClient = clnt
+
+	return nil
+}
+
 func (s *Standard) initializeConfirmationStore() error {
 	s.Logger().Debug("Loading confirmation store config")
 
@@ -300,7 +327,7 @@ func (s *Standard) initializeUserStore() error {
 func (s *Standard) initializeAPI() error {
 	s.Logger().Debug("Creating api")
 
-	newAPI, err := api.NewStandard(s, s.dataClient, s.metricClient, s.userClient,
+	newAPI, err := api.NewStandard(s, s.dataClient, s.metricClient, s.userClient, s.taskClient,
 		s.confirmationStore, s.messageStore, s.permissionStore, s.profileStore, s.sessionStore, s.userStore)
 	if err != nil {
 		return errors.Wrap(err, "unable to create api")
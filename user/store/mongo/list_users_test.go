@@ -0,0 +1,167 @@
+package mongo_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"context"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+
+	"github.com/tidepool-org/platform/log"
+	logNull "github.com/tidepool-org/platform/log/null"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/pointer"
+	storeStructuredMongoTest "github.com/tidepool-org/platform/store/structured/mongo/test"
+	"github.com/tidepool-org/platform/user"
+	"github.com/tidepool-org/platform/user/store"
+	"github.com/tidepool-org/platform/user/store/mongo"
+)
+
+func NewListUser(email string, roles []string, emailVerified bool, createdTime time.Time) *user.User {
+	return &user.User{
+		ID:            user.NewID(),
+		Email:         email,
+		Emails:        []string{email},
+		Roles:         roles,
+		EmailVerified: emailVerified,
+		CreatedTime:   createdTime.UTC().Format(time.RFC3339),
+	}
+}
+
+var _ = Describe("ListUsers", func() {
+	var ctx context.Context
+	var cfg *mongo.Config
+	var str *mongo.Store
+	var ssn store.UsersSession
+	var mgoSession *mgo.Session
+	var now time.Time
+	var alpha *user.User
+	var alphaAlternate *user.User
+	var beta *user.User
+	var deleted *user.User
+
+	BeforeEach(func() {
+		var err error
+		ctx = log.NewContextWithLogger(context.Background(), logNull.NewLogger())
+		cfg = &mongo.Config{
+			Config:       storeStructuredMongoTest.NewConfig(),
+			PasswordSalt: "password-salt",
+		}
+		str, err = mongo.NewStore(cfg, logNull.NewLogger())
+		Expect(err).ToNot(HaveOccurred())
+		ssn = str.NewUsersSession()
+		mgoSession = storeStructuredMongoTest.Session().Copy()
+		now = time.Now().UTC().Truncate(time.Second)
+
+		alpha = NewListUser("alpha@example.com", []string{user.AdminRole}, true, now.Add(-3*time.Hour))
+		alphaAlternate = NewListUser("alpha.b@example.com", nil, false, now.Add(-2*time.Hour))
+		alphaAlternate.Emails = append(alphaAlternate.Emails, "alpha+alternate@example.com")
+		beta = NewListUser("beta@example.com", []string{user.ClinicRole}, true, now.Add(-time.Hour))
+		deleted = NewListUser("alpha.deleted@example.com", nil, true, now.Add(-4*time.Hour))
+		deleted.DeletedTime = now.Format(time.RFC3339)
+		Expect(mgoSession.DB(cfg.Database).C(cfg.CollectionPrefix+"users").Insert(alpha, alphaAlternate, beta, deleted)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if mgoSession != nil {
+			mgoSession.Close()
+		}
+		if ssn != nil {
+			ssn.Close()
+		}
+		if str != nil {
+			str.Close()
+		}
+	})
+
+	ids := func(users user.Users) []string {
+		result := []string{}
+		for _, usr := range users {
+			result = append(result, usr.ID)
+		}
+		return result
+	}
+
+	It("returns an error if the context is missing", func() {
+		users, err := ssn.ListUsers(nil, nil, nil)
+		Expect(err).To(MatchError("context is missing"))
+		Expect(users).To(BeNil())
+	})
+
+	It("returns an error if the filter is invalid", func() {
+		filter := user.NewUserFilter()
+		filter.EmailPrefix = pointer.FromString("")
+		users, err := ssn.ListUsers(ctx, filter, nil)
+		Expect(err).To(MatchError(ContainSubstring("filter is invalid")))
+		Expect(users).To(BeNil())
+	})
+
+	It("returns the users matching the email prefix regardless of case", func() {
+		filter := user.NewUserFilter()
+		filter.EmailPrefix = pointer.FromString("ALPHA")
+		filter.Deleted = pointer.FromBool(false)
+		users, err := ssn.ListUsers(ctx, filter, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids(users)).To(Equal([]string{alphaAlternate.ID, alpha.ID}))
+	})
+
+	It("returns the users matching an alternate email prefix", func() {
+		filter := user.NewUserFilter()
+		filter.EmailPrefix = pointer.FromString("alpha+")
+		users, err := ssn.ListUsers(ctx, filter, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids(users)).To(Equal([]string{alphaAlternate.ID}))
+	})
+
+	It("matches the email prefix literally and only at the start", func() {
+		filter := user.NewUserFilter()
+		filter.EmailPrefix = pointer.FromString("alpha.")
+		users, err := ssn.ListUsers(ctx, filter, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids(users)).To(Equal([]string{alphaAlternate.ID, deleted.ID}))
+
+		filter.EmailPrefix = pointer.FromString("example")
+		users, err = ssn.ListUsers(ctx, filter, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(users).To(BeEmpty())
+	})
+
+	It("returns the users matching the role and email verified", func() {
+		filter := user.NewUserFilter()
+		filter.Role = pointer.FromString(user.AdminRole)
+		filter.EmailVerified = pointer.FromBool(true)
+		users, err := ssn.ListUsers(ctx, filter, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids(users)).To(Equal([]string{alpha.ID}))
+	})
+
+	It("returns the users created within the time window, with the start inclusive and the end exclusive", func() {
+		filter := user.NewUserFilter()
+		filter.CreatedTimeStart = pointer.FromTime(now.Add(-3 * time.Hour))
+		filter.CreatedTimeEnd = pointer.FromTime(now.Add(-time.Hour))
+		users, err := ssn.ListUsers(ctx, filter, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids(users)).To(Equal([]string{alphaAlternate.ID, alpha.ID}))
+	})
+
+	It("returns only the deleted users", func() {
+		filter := user.NewUserFilter()
+		filter.Deleted = pointer.FromBool(true)
+		users, err := ssn.ListUsers(ctx, filter, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids(users)).To(Equal([]string{deleted.ID}))
+	})
+
+	It("returns the requested page sorted by created time descending", func() {
+		filter := user.NewUserFilter()
+		filter.Deleted = pointer.FromBool(false)
+		pagination := page.NewPagination()
+		pagination.Page = 1
+		pagination.Size = 2
+		users, err := ssn.ListUsers(ctx, filter, pagination)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ids(users)).To(Equal([]string{alpha.ID}))
+	})
+})
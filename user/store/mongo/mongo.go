@@ -4,13 +4,18 @@ import (
 	"context"
 	"crypto/sha1"
 	"encoding/hex"
+	"regexp"
+	"strings"
 	"time"
 
 	"gopkg.in/mgo.v2/bson"
 
 	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/log"
+	"github.com/tidepool-org/platform/page"
+	"github.com/tidepool-org/platform/pointer"
 	storeStructuredMongo "github.com/tidepool-org/platform/store/structured/mongo"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
 	"github.com/tidepool-org/platform/user"
 	"github.com/tidepool-org/platform/user/store"
 )
@@ -52,6 +57,73 @@ type UsersSession struct {
 	config *Config
 }
 
+// The created time is stored as an RFC 3339 string, so the created time range is compared as strings in UTC
+func (u *UsersSession) ListUsers(ctx context.Context, filter *user.UserFilter, pagination *page.Pagination) (user.Users, error) {
+	if ctx == nil {
+		return nil, errors.New("context is missing")
+	}
+	if filter == nil {
+		filter = user.NewUserFilter()
+	} else if err := structureValidator.New().Validate(filter); err != nil {
+		return nil, errors.Wrap(err, "filter is invalid")
+	}
+	if pagination == nil {
+		pagination = page.NewPagination()
+	} else if err := structureValidator.New().Validate(pagination); err != nil {
+		return nil, errors.Wrap(err, "pagination is invalid")
+	}
+
+	if u.IsClosed() {
+		return nil, errors.New("session closed")
+	}
+
+	now := time.Now()
+	logger := log.LoggerFromContext(ctx).WithFields(log.Fields{"filter": filter, "pagination": pagination})
+
+	users := user.Users{}
+	selector := bson.M{}
+	if filter.EmailPrefix != nil {
+		// Emails are stored lowercase, so a case-sensitive anchored regex is used so that the index may be used
+		emailPrefix := bson.RegEx{Pattern: "^" + regexp.QuoteMeta(strings.ToLower(*filter.EmailPrefix))}
+		selector["$or"] = []bson.M{
+			{"username": emailPrefix},
+			{"emails": emailPrefix},
+		}
+	}
+	if filter.Role != nil {
+		selector["roles"] = *filter.Role
+	}
+	if filter.EmailVerified != nil {
+		selector["authenticated"] = *filter.EmailVerified
+	}
+	if filter.CreatedTimeStart != nil || filter.CreatedTimeEnd != nil {
+		createdTime := bson.M{}
+		if filter.CreatedTimeStart != nil {
+			createdTime["$gte"] = filter.CreatedTimeStart.UTC().Format(time.RFC3339)
+		}
+		if filter.CreatedTimeEnd != nil {
+			createdTime["$lt"] = filter.CreatedTimeEnd.UTC().Format(time.RFC3339)
+		}
+		selector["createdTime"] = createdTime
+	}
+	if filter.Deleted != nil {
+		selector["deletedTime"] = bson.M{"$exists": *filter.Deleted}
+	}
+	err := u.C().Find(selector).Sort("-createdTime", "userid").Skip(pagination.Page * pagination.Size).Limit(pagination.Size).All(&users)
+	logger.WithFields(log.Fields{"count": len(users), "duration": time.Since(now) / time.Microsecond}).WithError(err).Debug("ListUsers")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list users")
+	}
+
+	for _, usr := range users {
+		if meta, ok := usr.Private["meta"]; ok && meta.ID != "" {
+			usr.ProfileID = pointer.FromString(meta.ID)
+		}
+	}
+
+	return users, nil
+}
+
 func (u *UsersSession) GetUserByID(ctx context.Context, userID string) (*user.User, error) {
 	if ctx == nil {
 		return nil, errors.New("context is missing")
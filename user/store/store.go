@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 
+	"github.com/tidepool-org/platform/page"
 	"github.com/tidepool-org/platform/user"
 )
 
@@ -14,6 +15,7 @@ type Store interface {
 type UsersSession interface {
 	io.Closer
 
+	ListUsers(ctx context.Context, filter *user.UserFilter, pagination *page.Pagination) (user.Users, error)
 	GetUserByID(ctx context.Context, userID string) (*user.User, error)
 	DeleteUser(ctx context.Context, user *user.User) error
 	DestroyUserByID(ctx context.Context, userID string) error
@@ -28,6 +28,16 @@ type GetUserPermissionsOutput struct {
 	Error       error
 }
 
+type GetUserInput struct {
+	Context context.Context
+	UserID  string
+}
+
+type GetUserOutput struct {
+	User  *user.User
+	Error error
+}
+
 type Client struct {
 	EnsureAuthorizedServiceInvocations int
 	EnsureAuthorizedServiceInputs      []context.Context
@@ -44,6 +54,11 @@ type Client struct {
 	GetUserPermissionsStub             func(ctx context.Context, requestUserID string, targetUserID string) (user.Permissions, error)
 	GetUserPermissionsOutputs          []GetUserPermissionsOutput
 	GetUserPermissionsOutput           *GetUserPermissionsOutput
+	GetUserInvocations                 int
+	GetUserInputs                      []GetUserInput
+	GetUserStub                        func(ctx context.Context, userID string) (*user.User, error)
+	GetUserOutputs                     []GetUserOutput
+	GetUserOutput                      *GetUserOutput
 }
 
 func NewClient() *Client {
@@ -101,6 +116,23 @@ func (r *Client) GetUserPermissions(ctx context.Context, requestUserID string, t
 	panic("GetUserPermissions has no output")
 }
 
+func (r *Client) GetUser(ctx context.Context, userID string) (*user.User, error) {
+	r.GetUserInvocations++
+	r.GetUserInputs = append(r.GetUserInputs, GetUserInput{Context: ctx, UserID: userID})
+	if r.GetUserStub != nil {
+		return r.GetUserStub(ctx, userID)
+	}
+	if len(r.GetUserOutputs) > 0 {
+		output := r.GetUserOutputs[0]
+		r.GetUserOutputs = r.GetUserOutputs[1:]
+		return output.User, output.Error
+	}
+	if r.GetUserOutput != nil {
+		return r.GetUserOutput.User, r.GetUserOutput.Error
+	}
+	panic("GetUser has no output")
+}
+
 func (r *Client) AssertOutputsEmpty() {
 	if len(r.EnsureAuthorizedServiceOutputs) > 0 {
 		panic("EnsureAuthorizedServiceOutputs is not empty")
@@ -111,4 +143,7 @@ func (r *Client) AssertOutputsEmpty() {
 	if len(r.GetUserPermissionsOutputs) > 0 {
 		panic("GetUserPermissionsOutputs is not empty")
 	}
+	if len(r.GetUserOutputs) > 0 {
+		panic("GetUserOutputs is not empty")
+	}
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/tidepool-org/platform/errors"
 	"github.com/tidepool-org/platform/id"
+	"github.com/tidepool-org/platform/request"
 	"github.com/tidepool-org/platform/structure"
 	structureValidator "github.com/tidepool-org/platform/structure/validator"
 )
@@ -43,6 +44,47 @@ func (u *User) HasRole(role string) bool {
 	return false
 }
 
+// AllEmails returns the primary email followed by any alternate emails that differ from it
+func (u *User) AllEmails() []string {
+	emails := []string{}
+	if u.Email != "" {
+		emails = append(emails, u.Email)
+	}
+	for _, email := range u.Emails {
+		if email != u.Email {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// Sanitize redacts the alternate emails and the users responsible for changes unless the request is from a service
+func (u *User) Sanitize(details request.Details) error {
+	if details == nil {
+		return errors.New("unable to sanitize")
+	}
+
+	if !details.IsService() {
+		u.Emails = nil
+		u.CreatedUserID = ""
+		u.ModifiedUserID = ""
+		u.DeletedUserID = ""
+	}
+
+	return nil
+}
+
+type Users []*User
+
+func (u Users) Sanitize(details request.Details) error {
+	for _, usr := range u {
+		if err := usr.Sanitize(details); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func NewID() string {
 	return id.Must(id.New(5))
 }
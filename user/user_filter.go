@@ -0,0 +1,65 @@
+package user
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tidepool-org/platform/request"
+	"github.com/tidepool-org/platform/structure"
+)
+
+// UserFilter filters users. The created time start is inclusive and the end is exclusive. If deleted is not
+// specified, then both deleted and not deleted users match.
+type UserFilter struct {
+	EmailPrefix      *string
+	Role             *string
+	EmailVerified    *bool
+	CreatedTimeStart *time.Time
+	CreatedTimeEnd   *time.Time
+	Deleted          *bool
+}
+
+func NewUserFilter() *UserFilter {
+	return &UserFilter{}
+}
+
+func (u *UserFilter) Parse(parser structure.ObjectParser) {
+	u.EmailPrefix = parser.String("emailPrefix")
+	u.Role = parser.String("role")
+	u.EmailVerified = parser.Bool("emailVerified")
+	u.CreatedTimeStart = parser.Time("createdTimeStart", time.RFC3339Nano)
+	u.CreatedTimeEnd = parser.Time("createdTimeEnd", time.RFC3339Nano)
+	u.Deleted = parser.Bool("deleted")
+}
+
+func (u *UserFilter) Validate(validator structure.Validator) {
+	validator.String("emailPrefix", u.EmailPrefix).NotEmpty()
+	validator.String("role", u.Role).NotEmpty()
+	if u.CreatedTimeStart != nil {
+		validator.Time("createdTimeEnd", u.CreatedTimeEnd).After(*u.CreatedTimeStart)
+	}
+}
+
+func (u *UserFilter) MutateRequest(req *http.Request) error {
+	parameters := map[string]string{}
+	if u.EmailPrefix != nil {
+		parameters["emailPrefix"] = *u.EmailPrefix
+	}
+	if u.Role != nil {
+		parameters["role"] = *u.Role
+	}
+	if u.EmailVerified != nil {
+		parameters["emailVerified"] = strconv.FormatBool(*u.EmailVerified)
+	}
+	if u.CreatedTimeStart != nil {
+		parameters["createdTimeStart"] = u.CreatedTimeStart.Format(time.RFC3339Nano)
+	}
+	if u.CreatedTimeEnd != nil {
+		parameters["createdTimeEnd"] = u.CreatedTimeEnd.Format(time.RFC3339Nano)
+	}
+	if u.Deleted != nil {
+		parameters["deleted"] = strconv.FormatBool(*u.Deleted)
+	}
+	return request.NewParametersMutator(parameters).MutateRequest(req)
+}
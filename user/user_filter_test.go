@@ -0,0 +1,97 @@
+package user_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"net/http"
+	"net/url"
+	"time"
+
+	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/pointer"
+	structureValidator "github.com/tidepool-org/platform/structure/validator"
+	testHTTP "github.com/tidepool-org/platform/test/http"
+	"github.com/tidepool-org/platform/user"
+)
+
+var _ = Describe("UserFilter", func() {
+	var startTime time.Time
+
+	BeforeEach(func() {
+		startTime = time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	Context("Validate", func() {
+		DescribeTable("validates the user filter",
+			func(mutator func(filter *user.UserFilter), expectedErrors ...error) {
+				filter := user.NewUserFilter()
+				mutator(filter)
+				errorsTest.ExpectEqual(structureValidator.New().Validate(filter), expectedErrors...)
+			},
+			Entry("succeeds",
+				func(filter *user.UserFilter) {},
+			),
+			Entry("email prefix valid",
+				func(filter *user.UserFilter) { filter.EmailPrefix = pointer.FromString("jane") },
+			),
+			Entry("email prefix empty",
+				func(filter *user.UserFilter) { filter.EmailPrefix = pointer.FromString("") },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueEmpty(), "/emailPrefix"),
+			),
+			Entry("role valid",
+				func(filter *user.UserFilter) { filter.Role = pointer.FromString(user.ClinicRole) },
+			),
+			Entry("role empty",
+				func(filter *user.UserFilter) { filter.Role = pointer.FromString("") },
+				errorsTest.WithPointerSource(structureValidator.ErrorValueEmpty(), "/role"),
+			),
+			Entry("created time end after start",
+				func(filter *user.UserFilter) {
+					filter.CreatedTimeStart = pointer.FromTime(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+					filter.CreatedTimeEnd = pointer.FromTime(time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC))
+				},
+			),
+			Entry("created time end before start",
+				func(filter *user.UserFilter) {
+					filter.CreatedTimeStart = pointer.FromTime(time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC))
+					filter.CreatedTimeEnd = pointer.FromTime(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+				},
+				errorsTest.WithPointerSource(structureValidator.ErrorValueTimeNotAfter(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC)), "/createdTimeEnd"),
+			),
+		)
+	})
+
+	Context("MutateRequest", func() {
+		var req *http.Request
+
+		BeforeEach(func() {
+			req = testHTTP.NewRequest()
+		})
+
+		It("does not set request query when the filter is empty", func() {
+			Expect(user.NewUserFilter().MutateRequest(req)).To(Succeed())
+			Expect(req.URL.Query()).To(BeEmpty())
+		})
+
+		It("sets request query from the filter", func() {
+			filter := user.NewUserFilter()
+			filter.EmailPrefix = pointer.FromString("jane")
+			filter.Role = pointer.FromString(user.ClinicRole)
+			filter.EmailVerified = pointer.FromBool(true)
+			filter.CreatedTimeStart = pointer.FromTime(startTime)
+			filter.CreatedTimeEnd = pointer.FromTime(startTime.Add(time.Hour))
+			filter.Deleted = pointer.FromBool(false)
+			Expect(filter.MutateRequest(req)).To(Succeed())
+			Expect(req.URL.Query()).To(Equal(url.Values{
+				"emailPrefix":      []string{"jane"},
+				"role":             []string{"clinic"},
+				"emailVerified":    []string{"true"},
+				"createdTimeStart": []string{"2018-01-01T00:00:00Z"},
+				"createdTimeEnd":   []string{"2018-01-01T01:00:00Z"},
+				"deleted":          []string{"false"},
+			}))
+		})
+	})
+})
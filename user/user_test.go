@@ -6,6 +6,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	errorsTest "github.com/tidepool-org/platform/errors/test"
+	"github.com/tidepool-org/platform/request"
 	structureTest "github.com/tidepool-org/platform/structure/test"
 	structureValidator "github.com/tidepool-org/platform/structure/validator"
 	"github.com/tidepool-org/platform/test"
@@ -32,6 +33,21 @@ var _ = Describe("User", func() {
 		Entry("roles has many, role is specified, in roles", []string{"administrator", user.ClinicRole, "manager"}, user.ClinicRole, true),
 	)
 
+	DescribeTable("AllEmails",
+		func(email string, emails []string, expectedResult []string) {
+			testUser := &user.User{
+				Email:  email,
+				Emails: emails,
+			}
+			Expect(testUser.AllEmails()).To(Equal(expectedResult))
+		},
+		Entry("email is empty, emails is nil", "", nil, []string{}),
+		Entry("email is specified, emails is nil", "a@example.com", nil, []string{"a@example.com"}),
+		Entry("email is empty, emails is specified", "", []string{"a@example.com", "b@example.com"}, []string{"a@example.com", "b@example.com"}),
+		Entry("email is specified, emails includes email", "a@example.com", []string{"a@example.com", "b@example.com"}, []string{"a@example.com", "b@example.com"}),
+		Entry("email is specified, emails excludes email", "a@example.com", []string{"b@example.com"}, []string{"a@example.com", "b@example.com"}),
+	)
+
 	Context("NewID", func() {
 		It("returns a string of 10 lowercase hexidecimal characters", func() {
 			Expect(user.NewID()).To(MatchRegexp("^[0-9a-f]{10}$"))
@@ -42,6 +58,56 @@ var _ = Describe("User", func() {
 		})
 	})
 
+	Context("Sanitize", func() {
+		var usr *user.User
+
+		BeforeEach(func() {
+			usr = &user.User{
+				ID:             user.NewID(),
+				Email:          "jane@example.com",
+				Emails:         []string{"jane@example.com", "jane.doe@example.com"},
+				Roles:          []string{user.ClinicRole},
+				EmailVerified:  true,
+				CreatedTime:    "2018-01-01T00:00:00Z",
+				CreatedUserID:  user.NewID(),
+				ModifiedUserID: user.NewID(),
+				DeletedUserID:  user.NewID(),
+			}
+		})
+
+		It("returns an error if the details are missing", func() {
+			Expect(usr.Sanitize(nil)).To(MatchError("unable to sanitize"))
+		})
+
+		It("does not redact for a service", func() {
+			expected := *usr
+			Expect(usr.Sanitize(request.NewDetails(request.MethodServiceSecret, "", test.RandomString()))).To(Succeed())
+			Expect(*usr).To(Equal(expected))
+		})
+
+		It("redacts for a user", func() {
+			Expect(usr.Sanitize(request.NewDetails(request.MethodSessionToken, user.NewID(), test.RandomString()))).To(Succeed())
+			Expect(usr.Email).To(Equal("jane@example.com"))
+			Expect(usr.Emails).To(BeNil())
+			Expect(usr.Roles).To(Equal([]string{user.ClinicRole}))
+			Expect(usr.EmailVerified).To(BeTrue())
+			Expect(usr.CreatedTime).To(Equal("2018-01-01T00:00:00Z"))
+			Expect(usr.CreatedUserID).To(BeEmpty())
+			Expect(usr.ModifiedUserID).To(BeEmpty())
+			Expect(usr.DeletedUserID).To(BeEmpty())
+		})
+
+		It("redacts each of users", func() {
+			users := user.Users{usr}
+			Expect(users.Sanitize(request.NewDetails(request.MethodSessionToken, user.NewID(), test.RandomString()))).To(Succeed())
+			Expect(usr.Emails).To(BeNil())
+		})
+
+		It("returns an error for users if the details are missing", func() {
+			Expect(user.Users{usr}.Sanitize(nil)).To(MatchError("unable to sanitize"))
+		})
+	})
+
 	Context("IsValidID, IDValidator, and ValidateID", func() {
 		DescribeTable("return the expected results when the input",
 			func(value string, expectedErrors ...error) {